@@ -0,0 +1,77 @@
+// Package bootstrap runs a project's bootstrap script on a host the first
+// time the project syncs there. A marker file on the host records that it
+// ran, so later syncs skip it.
+package bootstrap
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/exec"
+	"github.com/rileyhilliard/rr/internal/host"
+)
+
+// markerDir is where bootstrap markers live on a host. They're kept out of
+// the project's directory so a sync's --delete can't remove them.
+const markerDir = "~/.rr/bootstrap"
+
+// MarkerPath returns the marker recording that the project at remoteDir was
+// bootstrapped: the directory's name followed by a hash of its full path.
+func MarkerPath(remoteDir string) string {
+	dir := strings.TrimSuffix(remoteDir, "/")
+	sum := sha256.Sum256([]byte(dir))
+	return markerDir + "/" + path.Base(dir) + "-" + hex.EncodeToString(sum[:6])
+}
+
+// Script wraps a bootstrap script so it stops at the first failing command,
+// in a subshell so set -e doesn't leak into the rest of the command line.
+func Script(script string) string {
+	return "(set -e\n" + strings.TrimRight(script, "\n") + "\n)"
+}
+
+// Needed reports whether the project at the host's dir hasn't been
+// bootstrapped there yet.
+func Needed(conn *host.Connection) (bool, error) {
+	marker := config.QuoteRemotePath(MarkerPath(config.ExpandRemote(conn.Host.Dir)))
+	_, _, exitCode, err := conn.Client.Exec("test -f " + marker)
+	if err != nil {
+		return false, err
+	}
+	return exitCode != 0, nil
+}
+
+// Run runs script in the project's directory on the host, then writes the
+// marker. Nothing is marked if the script fails, so it runs again on the
+// next sync.
+func Run(conn *host.Connection, script string, stdout, stderr io.Writer) error {
+	exitCode, err := conn.Client.ExecStream(exec.BuildRemoteCommand(Script(script), &conn.Host), stdout, stderr)
+	if err != nil {
+		return errors.WrapWithCode(err, errors.ErrExec,
+			fmt.Sprintf("Couldn't run the bootstrap script on '%s'", conn.Name),
+			"Check your SSH connection.")
+	}
+	if exitCode != 0 {
+		return errors.New(errors.ErrExec,
+			fmt.Sprintf("Bootstrap script failed on '%s' (exit code %d)", conn.Name, exitCode),
+			"Fix the bootstrap script in .rr.yaml. It runs again on the next sync until it succeeds.")
+	}
+
+	marker := MarkerPath(config.ExpandRemote(conn.Host.Dir))
+	markCmd := fmt.Sprintf("mkdir -p %s && date > %s", config.QuoteRemotePath(path.Dir(marker)), config.QuoteRemotePath(marker))
+	if _, stderrOut, exitCode, err := conn.Client.Exec(markCmd); err != nil || exitCode != 0 {
+		detail := strings.TrimSpace(string(stderrOut))
+		if err != nil {
+			detail = err.Error()
+		}
+		return errors.New(errors.ErrExec,
+			fmt.Sprintf("Bootstrap ran on '%s' but its marker couldn't be written: %s", conn.Name, detail),
+			"Check that ~/.rr is writable on the host. Until it is, bootstrap runs on every sync.")
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package bootstrap
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/host"
+	sshtesting "github.com/rileyhilliard/rr/pkg/sshutil/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMockConnection() (*host.Connection, *sshtesting.MockClient) {
+	mock := sshtesting.NewMockClient("testhost")
+	conn := &host.Connection{
+		Name:   "testhost",
+		Alias:  "testhost",
+		Client: mock,
+		Host:   config.Host{Dir: "~/projects/app"},
+	}
+	return conn, mock
+}
+
+func TestMarkerPath(t *testing.T) {
+	marker := MarkerPath("~/projects/app")
+	assert.True(t, strings.HasPrefix(marker, "~/.rr/bootstrap/app-"), marker)
+	assert.Len(t, strings.TrimPrefix(marker, "~/.rr/bootstrap/app-"), 12)
+
+	assert.Equal(t, marker, MarkerPath("~/projects/app/"), "trailing slash doesn't matter")
+	assert.NotEqual(t, marker, MarkerPath("~/work/app"), "same name in another dir gets its own marker")
+}
+
+func TestScript(t *testing.T) {
+	assert.Equal(t, "(set -e\npython -m venv .venv\npip install -r requirements.txt\n)",
+		Script("python -m venv .venv\npip install -r requirements.txt\n"))
+}
+
+func TestNeeded(t *testing.T) {
+	t.Run("no marker", func(t *testing.T) {
+		conn, _ := newMockConnection()
+		needed, err := Needed(conn)
+		require.NoError(t, err)
+		assert.True(t, needed)
+	})
+
+	t.Run("marker present", func(t *testing.T) {
+		conn, mock := newMockConnection()
+		mock.SetCommandResponse("^test -f ", sshtesting.CommandResponse{ExitCode: 0})
+		needed, err := Needed(conn)
+		require.NoError(t, err)
+		assert.False(t, needed)
+	})
+}
+
+func TestRun(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		conn, mock := newMockConnection()
+		mock.SetCommandResponse(`set -e`, sshtesting.CommandResponse{Stdout: []byte("created venv\n")})
+
+		var out bytes.Buffer
+		require.NoError(t, Run(conn, "python -m venv .venv", &out, &out))
+		assert.Equal(t, "created venv\n", out.String())
+	})
+
+	t.Run("script fails", func(t *testing.T) {
+		conn, mock := newMockConnection()
+		mock.SetCommandResponse(`set -e`, sshtesting.CommandResponse{ExitCode: 2})
+
+		var out bytes.Buffer
+		err := Run(conn, "false", &out, &out)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "exit code 2")
+	})
+
+	t.Run("marker can't be written", func(t *testing.T) {
+		conn, mock := newMockConnection()
+		mock.SetCommandResponse(`^mkdir -p `, sshtesting.CommandResponse{ExitCode: 1, Stderr: []byte("permission denied")})
+
+		var out bytes.Buffer
+		err := Run(conn, "true", &out, &out)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "permission denied")
+	})
+}
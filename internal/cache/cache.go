@@ -0,0 +1,127 @@
+// Package cache manages the rr cache directory on remote hosts: the
+// directory exported to tasks as $RR_CACHE, which outlives syncs. It builds
+// the remote commands that measure, clear, and garbage collect it, and
+// decides when garbage collection is due.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/util"
+	"github.com/rileyhilliard/rr/pkg/sshutil"
+)
+
+// GCInterval is how often a host's cache is garbage collected, at most.
+// Collection happens after a task, so without this every task would pay for
+// a walk of the cache.
+const GCInterval = time.Hour
+
+// gcStateDir is the directory under ~/.rr/ holding when each host's cache
+// was last collected.
+const gcStateDir = "cache-gc"
+
+// Usage is how much a host's cache holds.
+type Usage struct {
+	Bytes int64 `json:"bytes"`
+	Files int   `json:"files"`
+}
+
+// GCCommand builds a remote script that deletes files in dir unused (not
+// read or written) for longer than ttl, then, if the directory is still over
+// maxBytes, the least recently used files until it isn't. A maxBytes of 0
+// means no cap. Empty directories left behind are removed.
+func GCCommand(dir string, ttl time.Duration, maxBytes int64) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "d=%s; [ -d \"$d\" ] || exit 0\n", config.QuoteRemotePath(dir))
+	fmt.Fprintf(&b, "find \"$d\" -type f -amin +%d -exec rm -f {} + 2>/dev/null\n", max(int(ttl.Minutes()), 1))
+	if maxBytes > 0 {
+		fmt.Fprintf(&b, "max=%d\n", maxBytes/1024)
+		b.WriteString(`used=$(du -sk "$d" 2>/dev/null | cut -f1)
+if [ "${used:-0}" -gt "$max" ]; then
+  find "$d" -type f -exec sh -c 'for f; do stat -c "%X %s %n" "$f" 2>/dev/null || stat -f "%a %z %N" "$f"; done' sh {} + | sort -n | while read -r t s f; do
+    [ "$used" -le "$max" ] && break
+    rm -f "$f" && used=$((used - s / 1024))
+  done
+fi
+`)
+	}
+	b.WriteString(`find "$d" -mindepth 1 -type d -empty -delete 2>/dev/null; exit 0`)
+	return b.String()
+}
+
+// BackgroundCommand wraps a script so it keeps running on the host after the
+// SSH session that started it returns.
+func BackgroundCommand(script string) string {
+	return fmt.Sprintf("nohup sh -c %s </dev/null >/dev/null 2>&1 &", util.ShellQuote(script))
+}
+
+// UsageCommand builds a remote command that prints "<kilobytes>\t<files>"
+// for dir, or nothing if it doesn't exist.
+func UsageCommand(dir string) string {
+	return fmt.Sprintf(`d=%s; if [ -d "$d" ]; then printf '%%s\t%%s\n' "$(du -sk "$d" 2>/dev/null | cut -f1)" "$(find "$d" -type f | wc -l)"; fi`,
+		config.QuoteRemotePath(dir))
+}
+
+// ParseUsage parses UsageCommand output. Reports false if the directory
+// doesn't exist.
+func ParseUsage(output []byte) (Usage, bool) {
+	kb, files, ok := strings.Cut(strings.TrimSpace(string(output)), "\t")
+	if !ok {
+		return Usage{}, false
+	}
+	var u Usage
+	if n, err := strconv.ParseInt(strings.TrimSpace(kb), 10, 64); err == nil {
+		u.Bytes = n * 1024
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(files)); err == nil {
+		u.Files = n
+	}
+	return u, true
+}
+
+// ClearCommand builds a remote command that deletes dir and everything in
+// it.
+func ClearCommand(dir string) string {
+	return "rm -rf " + config.QuoteRemotePath(dir)
+}
+
+// Collect garbage collects the cache on a host if it hasn't been in the last
+// GCInterval. Collection runs in the background on the host, so this costs
+// one round trip and doesn't wait for it. Errors are ignored: the next task
+// tries again.
+func Collect(client sshutil.SSHClient, hostName string, cfg config.CacheConfig) {
+	dir := cfg.Path()
+	stamp, err := gcStampPath(hostName, dir)
+	if err != nil {
+		return
+	}
+	if info, err := os.Stat(stamp); err == nil && time.Since(info.ModTime()) < GCInterval {
+		return
+	}
+	if _, _, _, err := client.Exec(BackgroundCommand(GCCommand(dir, cfg.TTLDuration(), cfg.MaxSizeBytes()))); err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(stamp), 0755); err != nil {
+		return
+	}
+	_ = os.WriteFile(stamp, nil, 0644)
+}
+
+// gcStampPath returns the file whose modification time records when the
+// cache at dir on a host was last collected.
+func gcStampPath(hostName, dir string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(hostName + "\x00" + dir))
+	return filepath.Join(home, config.GlobalConfigDir, gcStateDir, hex.EncodeToString(sum[:8])), nil
+}
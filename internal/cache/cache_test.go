@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	sshtesting "github.com/rileyhilliard/rr/pkg/sshutil/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingClient counts the commands run on it.
+type countingClient struct {
+	*sshtesting.MockClient
+	cmds []string
+}
+
+func (c *countingClient) Exec(cmd string) ([]byte, []byte, int, error) {
+	c.cmds = append(c.cmds, cmd)
+	return c.MockClient.Exec(cmd)
+}
+
+func TestGCCommand(t *testing.T) {
+	cmd := GCCommand("~/.rr/cache/app", 2*time.Hour, 0)
+	assert.True(t, strings.HasPrefix(cmd, `d="$HOME"/'.rr/cache/app';`))
+	assert.Contains(t, cmd, "-amin +120 ")
+	assert.NotContains(t, cmd, "max=", "no size cap without max_size")
+
+	cmd = GCCommand("/scratch/cache", time.Hour, 10<<20)
+	assert.Contains(t, cmd, "max=10240\n")
+	assert.Contains(t, cmd, "sort -n")
+}
+
+func TestBackgroundCommand(t *testing.T) {
+	assert.Equal(t, `nohup sh -c 'echo '\''hi'\''' </dev/null >/dev/null 2>&1 &`, BackgroundCommand("echo 'hi'"))
+}
+
+func TestParseUsage(t *testing.T) {
+	u, ok := ParseUsage([]byte("2048\t17\n"))
+	require.True(t, ok)
+	assert.Equal(t, Usage{Bytes: 2048 * 1024, Files: 17}, u)
+
+	_, ok = ParseUsage(nil)
+	assert.False(t, ok, "no output means no cache directory")
+}
+
+func TestClearCommand(t *testing.T) {
+	assert.Equal(t, `rm -rf "$HOME"/'.rr/cache/my app'`, ClearCommand("~/.rr/cache/my app"))
+}
+
+func TestCollect_AtMostOncePerInterval(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	client := &countingClient{MockClient: sshtesting.NewMockClient("box")}
+	client.SetCommandResponse("^nohup ", sshtesting.CommandResponse{})
+	cfg := config.CacheConfig{Dir: "/scratch/cache"}
+
+	Collect(client, "box", cfg)
+	Collect(client, "box", cfg)
+	require.Len(t, client.cmds, 1, "second task within the interval shouldn't collect")
+	assert.True(t, strings.HasPrefix(client.cmds[0], "nohup sh -c "))
+
+	// Another host has its own schedule
+	Collect(client, "other", cfg)
+	assert.Len(t, client.cmds, 2)
+
+	// Once the interval passes, it's due again
+	stamp, err := gcStampPath("box", cfg.Path())
+	require.NoError(t, err)
+	old := time.Now().Add(-GCInterval - time.Minute)
+	require.NoError(t, os.Chtimes(stamp, old, old))
+	Collect(client, "box", cfg)
+	assert.Len(t, client.cmds, 3)
+}
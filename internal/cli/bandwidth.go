@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/rileyhilliard/rr/internal/logger"
+	"github.com/rileyhilliard/rr/internal/output"
+	"github.com/rileyhilliard/rr/internal/ui"
+)
+
+// lowBandwidthAuto is whether a slow connection can turn low-bandwidth mode
+// on: it isn't already on, and low_bandwidth isn't "never".
+var lowBandwidthAuto bool
+
+// lowBandwidthOutputInterval is how often a command's output is shown in
+// low-bandwidth mode.
+const lowBandwidthOutputInterval = 500 * time.Millisecond
+
+// lowBandwidthMonitorInterval is how often the monitor refreshes in
+// low-bandwidth mode when --interval isn't given. Each refresh runs a
+// collection script on every host.
+const lowBandwidthMonitorInterval = 10 * time.Second
+
+// applyLowBandwidth turns low-bandwidth mode on for --low-bandwidth,
+// RR_LOW_BANDWIDTH, or low_bandwidth: always, and decides whether a slow
+// connection can turn it on later.
+func applyLowBandwidth(global *config.GlobalConfig) {
+	setting := config.GetLowBandwidth(global)
+	on := lowBandwidthFlag || setting == config.LowBandwidthAlways
+	config.SetLowBandwidth(on)
+	lowBandwidthAuto = !on && setting == config.LowBandwidthAuto
+}
+
+// detectLowBandwidth turns low-bandwidth mode on when connecting to the
+// host took long enough to suggest a slow link, and says so.
+func detectLowBandwidth(conn *host.Connection) {
+	if !lowBandwidthAuto || conn == nil || conn.IsLocal || conn.Latency < config.SlowConnectLatency {
+		return
+	}
+	lowBandwidthAuto = false
+	config.SetLowBandwidth(true)
+
+	logger.Verbosef(logger.LevelPhases, "connect", "connecting to %s took %s: low-bandwidth mode on", conn.Name, conn.Latency.Round(time.Millisecond))
+	if PrettyMode() && !Quiet() {
+		mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
+		fmt.Println(mutedStyle.Render(fmt.Sprintf("  Slow connection (%s to connect), using low-bandwidth mode. Set low_bandwidth: never to turn this off.",
+			conn.Latency.Round(100*time.Millisecond))))
+	}
+}
+
+// commandOutput returns the writers a command's output goes to, and a
+// function to call once the command finishes. In low-bandwidth mode the
+// output is shown in batches rather than line by line.
+func commandOutput() (stdout, stderr io.Writer, flush func()) {
+	if !config.LowBandwidth() {
+		return os.Stdout, os.Stderr, func() {}
+	}
+	out := output.NewBatchWriter(os.Stdout, lowBandwidthOutputInterval)
+	errOut := output.NewBatchWriter(os.Stderr, lowBandwidthOutputInterval)
+	return out, errOut, func() {
+		_ = out.Flush()
+		_ = errOut.Flush()
+	}
+}
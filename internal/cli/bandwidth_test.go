@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/stretchr/testify/assert"
+)
+
+// withLowBandwidth resets low-bandwidth mode after a test.
+func withLowBandwidth(t *testing.T) {
+	t.Helper()
+	t.Setenv("RR_LOW_BANDWIDTH", "")
+	oldFlag, oldAuto := lowBandwidthFlag, lowBandwidthAuto
+	t.Cleanup(func() {
+		lowBandwidthFlag, lowBandwidthAuto = oldFlag, oldAuto
+		config.SetLowBandwidth(false)
+	})
+}
+
+func TestApplyLowBandwidth(t *testing.T) {
+	withLowBandwidth(t)
+
+	applyLowBandwidth(nil)
+	assert.False(t, config.LowBandwidth())
+	assert.True(t, lowBandwidthAuto, "auto is the default")
+
+	applyLowBandwidth(&config.GlobalConfig{LowBandwidth: config.LowBandwidthNever})
+	assert.False(t, lowBandwidthAuto)
+
+	lowBandwidthFlag = true
+	applyLowBandwidth(&config.GlobalConfig{LowBandwidth: config.LowBandwidthNever})
+	assert.True(t, config.LowBandwidth(), "--low-bandwidth wins over the config")
+}
+
+func TestDetectLowBandwidth(t *testing.T) {
+	withLowBandwidth(t)
+	applyLowBandwidth(nil)
+
+	detectLowBandwidth(&host.Connection{Name: "lan", Latency: 80 * time.Millisecond})
+	assert.False(t, config.LowBandwidth())
+	detectLowBandwidth(&host.Connection{Name: "local", IsLocal: true, Latency: 5 * time.Second})
+	assert.False(t, config.LowBandwidth())
+
+	detectLowBandwidth(&host.Connection{Name: "hotel", Latency: 2 * time.Second})
+	assert.True(t, config.LowBandwidth())
+}
+
+func TestCommandOutput(t *testing.T) {
+	withLowBandwidth(t)
+
+	stdout, _, _ := commandOutput()
+	assert.Equal(t, os.Stdout, stdout, "output goes straight through normally")
+
+	config.SetLowBandwidth(true)
+	stdout, _, flush := commandOutput()
+	assert.NotEqual(t, os.Stdout, stdout)
+	flush()
+}
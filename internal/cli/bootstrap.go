@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/bootstrap"
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/rileyhilliard/rr/internal/logger"
+	"github.com/rileyhilliard/rr/internal/ui"
+)
+
+// bootstrapPhase runs the project's bootstrap script after the sync, if the
+// project hasn't been bootstrapped on the host yet or --rebootstrap asked for
+// it again.
+func bootstrapPhase(ctx *WorkflowContext, opts WorkflowOptions) error {
+	if ctx.Conn.IsLocal || opts.SkipSync || ctx.Resolved.Project == nil {
+		return nil
+	}
+	start := time.Now()
+	ran, err := bootstrapHost(ctx.Conn, ctx.Resolved.Project, opts.Rebootstrap, opts.Quiet, ctx.PhaseDisplay, ctx.GetReporter())
+	if ran && err == nil {
+		ctx.recordPhase("bootstrap", time.Since(start))
+	}
+	return err
+}
+
+// bootstrapHost runs project's bootstrap script on the host behind conn when
+// it's needed or force is set. Its output streams to stdout, or is held back
+// and shown only on failure when quiet. Reports whether the script ran.
+func bootstrapHost(conn *host.Connection, project *config.Config, force, quiet bool, pd *ui.PhaseDisplay, reporter PhaseReporter) (bool, error) {
+	if project.Bootstrap == "" {
+		return false, nil
+	}
+	if !force {
+		needed, err := bootstrap.Needed(conn)
+		if err != nil {
+			return false, errors.WrapWithCode(err, errors.ErrSSH,
+				fmt.Sprintf("Couldn't check whether the project is bootstrapped on '%s'", conn.Name),
+				"Check your SSH connection.")
+		}
+		if !needed {
+			logger.Verbosef(logger.LevelPhases, "bootstrap", "skipped: already ran on %s", conn.Name)
+			return false, nil
+		}
+	}
+
+	start := time.Now()
+	var out io.Writer = os.Stdout
+	var held bytes.Buffer
+	if quiet {
+		out = &held
+	}
+
+	if !PrettyMode() {
+		reporter.PhaseStart("bootstrap")
+		err := bootstrap.Run(conn, project.Bootstrap, out, out)
+		if err != nil {
+			os.Stdout.Write(held.Bytes()) //nolint:errcheck // Best-effort output of a failed script
+			reporter.PhaseFailed("bootstrap", err)
+			return true, err
+		}
+		reporter.PhaseComplete("bootstrap", conn.Name, time.Since(start))
+		return true, nil
+	}
+
+	pd.RenderProgress("Running bootstrap script")
+	if !ui.Accessible() {
+		pd.Newline()
+	}
+	err := bootstrap.Run(conn, project.Bootstrap, out, out)
+	if err != nil {
+		os.Stdout.Write(held.Bytes()) //nolint:errcheck // Best-effort output of a failed script
+		pd.RenderFailed("Bootstrap failed", time.Since(start), err)
+		return true, err
+	}
+	pd.RenderSuccess("Bootstrapped "+conn.Name, time.Since(start))
+	return true, nil
+}
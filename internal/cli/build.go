@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/exec"
+	"github.com/rileyhilliard/rr/internal/ui"
+)
+
+// buildOutputLines is how much of a failed build's output is shown.
+const buildOutputLines = 40
+
+// workflowTask returns the task the workflow runs, or nil for ad-hoc commands.
+func workflowTask(ctx *WorkflowContext, opts WorkflowOptions) *config.TaskConfig {
+	if opts.TaskName == "" || ctx.Resolved == nil || ctx.Resolved.Project == nil {
+		return nil
+	}
+	task, ok := ctx.Resolved.Project.Tasks[opts.TaskName]
+	if !ok {
+		return nil
+	}
+	return &task
+}
+
+// buildPhase runs the task's build command locally, before connecting, so a
+// failed build stops the task without touching a host. Its output is only
+// shown when it fails.
+func buildPhase(ctx *WorkflowContext, opts WorkflowOptions) error {
+	task := workflowTask(ctx, opts)
+	if task == nil || task.Build == "" {
+		return nil
+	}
+
+	reporter := ctx.GetReporter()
+	buildStart := time.Now()
+
+	var spinner *ui.Spinner
+	if PrettyMode() {
+		spinner = ui.NewSpinner("Building locally")
+		spinner.Start()
+	} else {
+		reporter.PhaseStart("build")
+	}
+
+	var output bytes.Buffer
+	exitCode, err := exec.ExecuteLocal(task.Build, ctx.WorkDir, &output, &output)
+	if err == nil && exitCode != 0 {
+		err = errors.New(errors.ErrExec,
+			fmt.Sprintf("Local build failed with exit code %d: %s", exitCode, task.Build),
+			"Fix the build and run the task again. Nothing was synced.")
+	}
+	if err != nil {
+		tail := lastLines(output.String(), buildOutputLines)
+		if PrettyMode() {
+			spinner.Fail()
+			if tail != "" {
+				fmt.Fprintln(os.Stderr, tail)
+			}
+		} else {
+			WritePhaseEvent(PhaseEvent{
+				Type:     "phase",
+				Phase:    "build",
+				Status:   "failed",
+				ExitCode: &exitCode,
+				Error:    err.Error(),
+				Details:  map[string]interface{}{"command": task.Build, "output": tail},
+			})
+		}
+		return err
+	}
+
+	if PrettyMode() {
+		spinner.Success()
+		if !opts.Quiet {
+			ctx.PhaseDisplay.RenderSuccess("Built", time.Since(buildStart))
+		}
+	} else {
+		reporter.PhaseComplete("build", "local", time.Since(buildStart))
+	}
+	ctx.recordPhase("build", time.Since(buildStart))
+	return nil
+}
+
+// lastLines returns the last n lines of s, without a trailing newline.
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildTestContext returns a workflow context for a project in dir with the
+// given tasks.
+func buildTestContext(dir string, tasks map[string]config.TaskConfig) *WorkflowContext {
+	return &WorkflowContext{
+		Resolved: &config.ResolvedConfig{
+			Global:  &config.GlobalConfig{},
+			Project: &config.Config{Tasks: tasks},
+		},
+		WorkDir: dir,
+	}
+}
+
+func TestBuildPhase_RunsBuildInProjectRoot(t *testing.T) {
+	dir := t.TempDir()
+	ctx := buildTestContext(dir, map[string]config.TaskConfig{
+		"deploy": {Run: "./dist/app", Build: "mkdir -p dist && echo built > dist/app"},
+	})
+
+	require.NoError(t, buildPhase(ctx, WorkflowOptions{TaskName: "deploy"}))
+
+	data, err := os.ReadFile(filepath.Join(dir, "dist", "app"))
+	require.NoError(t, err)
+	assert.Equal(t, "built\n", string(data))
+	assert.Contains(t, ctx.Phases, "build")
+}
+
+func TestBuildPhase_FailureStopsTask(t *testing.T) {
+	ctx := buildTestContext(t.TempDir(), map[string]config.TaskConfig{
+		"deploy": {Run: "./dist/app", Build: "echo compile error; exit 2"},
+	})
+
+	err := buildPhase(ctx, WorkflowOptions{TaskName: "deploy"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Local build failed with exit code 2")
+	assert.NotContains(t, ctx.Phases, "build")
+}
+
+func TestBuildPhase_NothingToBuild(t *testing.T) {
+	ctx := buildTestContext(t.TempDir(), map[string]config.TaskConfig{
+		"test": {Run: "pytest"},
+	})
+
+	assert.NoError(t, buildPhase(ctx, WorkflowOptions{TaskName: "test"}))
+	assert.NoError(t, buildPhase(ctx, WorkflowOptions{Command: "make"}))
+	assert.Empty(t, ctx.Phases)
+}
+
+func TestSyncPhase_MissingPushPath(t *testing.T) {
+	ctx := buildTestContext(t.TempDir(), map[string]config.TaskConfig{
+		"deploy": {Run: "./dist/app", Push: []string{"dist/"}},
+	})
+	ctx.Conn = &host.Connection{Name: "m1", Alias: "m1"}
+
+	err := syncPhase(ctx, WorkflowOptions{TaskName: "deploy"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Nothing to push at 'dist/'")
+}
+
+func TestResolveSyncConfig_Push(t *testing.T) {
+	ctx := buildTestContext(t.TempDir(), nil)
+	ctx.Resolved.Project.Sync = config.SyncConfig{Exclude: []string{"dist/"}}
+
+	assert.Equal(t, []string{"dist/"}, resolveSyncConfig(ctx).Exclude)
+
+	ctx.push = []string{"dist/"}
+	cfg := resolveSyncConfig(ctx)
+	assert.Empty(t, cfg.Exclude)
+	assert.Equal(t, []string{"--include=/dist", "--exclude=/*"}, cfg.Flags)
+}
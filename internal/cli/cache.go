@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/cache"
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// cacheHost limits rr cache to one host.
+var cacheHost string
+
+// cacheCmd is the parent command for the remote cache
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and clear the remote cache directory",
+	Long: `Tasks get a directory on each host that survives syncs, exported as
+$RR_CACHE, for things like pip and npm caches:
+
+  tasks:
+    deps:
+      run: pip install --cache-dir "$RR_CACHE/pip" -r requirements.txt
+
+Files unused for longer than cache.ttl (default 168h) are removed after tasks,
+at most once an hour per host, and cache.max_size caps how big it gets.
+
+Examples:
+  rr cache ls
+  rr cache clear --host gpu-box`,
+}
+
+// cacheLsCmd shows how much each host's cache holds
+var cacheLsCmd = &cobra.Command{
+	Use:     "ls",
+	Aliases: []string{"list"},
+	Short:   "Show the size of the cache on each host",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cacheCommand(os.Stdout, cacheHost, false)
+	},
+}
+
+// cacheClearCmd deletes each host's cache
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete the cache on each host",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cacheCommand(os.Stdout, cacheHost, true)
+	},
+}
+
+func init() {
+	cacheCmd.PersistentFlags().StringVar(&cacheHost, "host", "", "only this host")
+	cacheCmd.AddCommand(cacheLsCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+// CacheHostOutput is the JSON representation of a host's cache.
+type CacheHostOutput struct {
+	Host    string `json:"host"`
+	Path    string `json:"path"`
+	Exists  bool   `json:"exists"`
+	Bytes   int64  `json:"bytes"`
+	Files   int    `json:"files"`
+	Cleared bool   `json:"cleared,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// cacheCommand measures the cache on each of the project's hosts, or only
+// hostName's, and deletes it if clear is set. Unreachable hosts are reported
+// and skipped.
+func cacheCommand(w io.Writer, hostName string, clear bool) error {
+	resolved, err := loadResolved(Config())
+	if err != nil {
+		return err
+	}
+	hostOrder, hosts, err := config.ResolveHosts(resolved, hostName)
+	if err != nil {
+		return err
+	}
+	if len(hostOrder) == 0 {
+		return errors.New(errors.ErrConfig,
+			"No hosts to check",
+			"Add a host with 'rr host add' first.")
+	}
+
+	var cfg config.CacheConfig
+	if resolved.Project != nil {
+		cfg = resolved.Project.Cache
+	}
+	dir := cfg.Path()
+
+	out := make([]CacheHostOutput, 0, len(hostOrder))
+	for _, name := range hostOrder {
+		out = append(out, cacheOnHost(name, hosts[name], dir, clear))
+	}
+
+	if MachineMode() {
+		return WriteJSONSuccess(w, out)
+	}
+	renderCacheHosts(w, out, clear)
+	return nil
+}
+
+// cacheOnHost measures the cache at dir on one host, then deletes it if
+// clear is set. The size reported is what it held before clearing.
+func cacheOnHost(name string, h config.Host, dir string, clear bool) CacheHostOutput {
+	result := CacheHostOutput{Host: name, Path: dir}
+	if len(h.SSH) == 0 {
+		result.Error = "no SSH aliases configured"
+		return result
+	}
+
+	client, err := connectForCleanup(name, h)
+	if err != nil {
+		result.Error = fmt.Sprintf("unreachable: %v", err)
+		return result
+	}
+	defer client.Close()
+
+	stdout, stderr, exitCode, err := client.Exec(cache.UsageCommand(dir))
+	if err != nil || exitCode != 0 {
+		result.Error = remoteCommandError(err, stderr, exitCode)
+		return result
+	}
+	usage, ok := cache.ParseUsage(stdout)
+	result.Exists = ok
+	result.Bytes, result.Files = usage.Bytes, usage.Files
+
+	if clear && ok {
+		_, stderr, exitCode, err := client.Exec(cache.ClearCommand(dir))
+		if err != nil || exitCode != 0 {
+			result.Error = remoteCommandError(err, stderr, exitCode)
+			return result
+		}
+		result.Cleared = true
+	}
+	return result
+}
+
+// remoteCommandError describes why a remote command failed.
+func remoteCommandError(err error, stderr []byte, exitCode int) string {
+	if err != nil {
+		return err.Error()
+	}
+	if msg := string(stderr); msg != "" {
+		return fmt.Sprintf("exit %d: %s", exitCode, msg)
+	}
+	return fmt.Sprintf("exit %d", exitCode)
+}
+
+// renderCacheHosts prints each host's cache and what happened to it.
+func renderCacheHosts(w io.Writer, out []CacheHostOutput, clear bool) {
+	successStyle := lipgloss.NewStyle().Foreground(ui.ColorSuccess)
+	errorStyle := lipgloss.NewStyle().Foreground(ui.ColorError)
+	mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
+
+	for _, h := range out {
+		switch {
+		case h.Error != "":
+			fmt.Fprintf(w, "  %s %-20s %s\n", errorStyle.Render(ui.SymbolFail), h.Host, mutedStyle.Render(h.Error))
+		case !h.Exists:
+			fmt.Fprintf(w, "  %s %-20s %s\n", mutedStyle.Render(ui.SymbolSkipped), h.Host, mutedStyle.Render("no cache at "+h.Path))
+		case clear:
+			fmt.Fprintf(w, "  %s %-20s cleared %s %s\n", successStyle.Render(ui.SymbolSuccess), h.Host,
+				config.FormatSize(h.Bytes), mutedStyle.Render(fmt.Sprintf("(%d files)", h.Files)))
+		default:
+			fmt.Fprintf(w, "  %s %-20s %s %s\n", successStyle.Render(ui.SymbolSuccess), h.Host,
+				config.FormatSize(h.Bytes), mutedStyle.Render(fmt.Sprintf("%d files in %s", h.Files, h.Path)))
+		}
+	}
+}
+
+// collectCache garbage collects the cache on the run's host, if it's due.
+// Local runs have no remote cache to collect.
+func collectCache(wf *WorkflowContext) {
+	if wf.Conn == nil || wf.Conn.IsLocal || wf.Conn.Client == nil || wf.Resolved == nil || wf.Resolved.Project == nil {
+		return
+	}
+	cache.Collect(wf.Conn.Client, wf.Conn.Name, wf.Resolved.Project.Cache)
+}
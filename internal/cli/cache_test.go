@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/pkg/sshutil"
+	sshtesting "github.com/rileyhilliard/rr/pkg/sshutil/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheOnHost_Clear(t *testing.T) {
+	client := &recordingClient{MockClient: sshtesting.NewMockClient("box")}
+	client.SetCommandResponse("^d=", sshtesting.CommandResponse{Stdout: []byte("4096\t12\n")})
+	client.SetCommandResponse("^rm -rf ", sshtesting.CommandResponse{})
+
+	origConnect := connectForCleanup
+	connectForCleanup = func(string, config.Host) (sshutil.SSHClient, error) { return client, nil }
+	t.Cleanup(func() { connectForCleanup = origConnect })
+
+	result := cacheOnHost("box", config.Host{SSH: []string{"box"}}, "/scratch/cache", true)
+	assert.Equal(t, CacheHostOutput{
+		Host: "box", Path: "/scratch/cache", Exists: true,
+		Bytes: 4096 * 1024, Files: 12, Cleared: true,
+	}, result)
+	require.Len(t, client.cmds, 2)
+	assert.Equal(t, "rm -rf '/scratch/cache'", client.cmds[1])
+}
+
+func TestCacheOnHost_NoCache(t *testing.T) {
+	client := &recordingClient{MockClient: sshtesting.NewMockClient("box")}
+	client.SetCommandResponse("^d=", sshtesting.CommandResponse{})
+
+	origConnect := connectForCleanup
+	connectForCleanup = func(string, config.Host) (sshutil.SSHClient, error) { return client, nil }
+	t.Cleanup(func() { connectForCleanup = origConnect })
+
+	result := cacheOnHost("box", config.Host{SSH: []string{"box"}}, "/scratch/cache", true)
+	assert.False(t, result.Exists)
+	assert.False(t, result.Cleared)
+	require.Len(t, client.cmds, 1, "nothing to clear")
+	assert.True(t, strings.HasPrefix(client.cmds[0], "d='/scratch/cache';"))
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/host"
+	rrsync "github.com/rileyhilliard/rr/internal/sync"
+	"github.com/rileyhilliard/rr/internal/ui"
+)
+
+// checkClockSkew measures how far the host's clock is off from this one and
+// warns if it's past rrsync.ClockSkewWarn: a warning in pretty mode, or a
+// "clock_skew" event in structured mode. Returns the skew, or zero if it's
+// under the threshold or couldn't be measured, which only costs the warning.
+func checkClockSkew(conn *host.Connection) time.Duration {
+	if conn == nil || conn.IsLocal || conn.Client == nil {
+		return 0
+	}
+	skew, err := rrsync.MeasureClockSkew(conn.Client)
+	if err != nil || !rrsync.ExceedsClockSkew(skew, rrsync.ClockSkewWarn) {
+		return 0
+	}
+	checksum := rrsync.ExceedsClockSkew(skew, rrsync.ClockSkewChecksum)
+
+	if !PrettyMode() {
+		WritePhaseEvent(PhaseEvent{
+			Type:   "clock_skew",
+			Status: "warning",
+			Host:   conn.Name,
+			Details: map[string]interface{}{
+				"skew_s":   skew.Seconds(),
+				"checksum": checksum,
+				"hint":     rrsync.ClockSkewHint,
+			},
+		})
+		return skew
+	}
+
+	ui.PrintWarning(fmt.Sprintf("%s's clock is %s of this machine's", conn.Name, rrsync.DescribeClockSkew(skew)))
+	mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
+	if checksum {
+		fmt.Fprintln(os.Stderr, mutedStyle.Render("  Syncing with --checksum, since file times can't be trusted"))
+	}
+	fmt.Fprintln(os.Stderr, mutedStyle.Render("  "+rrsync.ClockSkewHint))
+	return skew
+}
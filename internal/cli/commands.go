@@ -5,6 +5,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/rileyhilliard/rr/internal/config"
 	"github.com/rileyhilliard/rr/internal/errors"
 	"github.com/rileyhilliard/rr/internal/ui"
 	"github.com/spf13/cobra"
@@ -12,45 +13,34 @@ import (
 
 // Command-specific flags
 var (
-	runHostFlag              string
-	runTagFlag               string
-	runProbeTimeoutFlag      string
-	runLocalFlag             bool
-	runSkipRequirementsFlag  bool
-	runRepeatFlag            int
-	runPullFlags             []string
-	runPullDestFlag          string
-	runCwdFlag               string
-	execHostFlag             string
-	execTagFlag              string
-	execProbeTimeoutFlag     string
-	execLocalFlag            bool
-	execSkipRequirementsFlag bool
-	execPullFlags            []string
-	execPullDestFlag         string
-	execCwdFlag              string
-	syncHostFlag             string
-	syncTagFlag              string
-	syncProbeTimeoutFlag     string
-	syncDryRun               bool
-	pullHostFlag             string
-	pullTagFlag              string
-	pullProbeTimeoutFlag     string
-	pullDestFlag             string
-	pullDryRun               bool
-	initHostFlag             string
-	initRemoteDirFlag        string
-	initNameFlag             string
-	initForce                bool
-	initNonInteractive       bool
-	initSkipProbe            bool
-	monitorHostsFlag         string
-	monitorIntervalFlag      string
-	hostAddSkipProbe         bool
-	unlockAllFlag            bool
-	provisionHostFlag        string
-	provisionCheckOnly       bool
-	provisionAutoYes         bool
+	runCmdFlags          runFlags
+	execCmdFlags         execFlags
+	syncCmdFlags         syncFlags
+	pullHostFlag         string
+	pullTagFlag          string
+	pullProbeTimeoutFlag string
+	pullDestFlag         string
+	pullDryRun           bool
+	initHostFlag         string
+	initRemoteDirFlag    string
+	initNameFlag         string
+	initForce            bool
+	initSkipProbe        bool
+	initImportTasks      bool
+	onboardHostFlag      string
+	onboardRemoteDirFlag string
+	onboardNameFlag      string
+	onboardForce         bool
+	onboardSkipSmokeTest bool
+	monitorHostsFlag     string
+	monitorIntervalFlag  string
+	monitorSnapshotFlag  bool
+	monitorFormatFlag    string
+	hostAddSkipProbe     bool
+	unlockAllFlag        bool
+	provisionHostFlag    string
+	provisionCheckOnly   bool
+	provisionAutoYes     bool
 )
 
 // runCmd syncs code and executes a command on the remote host
@@ -65,15 +55,16 @@ Files are synced using rsync, then the command runs in the remote project direct
 Examples:
   rr run "make test"
   rr run "npm run build"
-  rr run --host mini "cargo test"`,
+  rr run --host mini "cargo test"
+  rr run --dir backend "uv run pytest"`,
 	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if runRepeatFlag < 0 {
+		if runCmdFlags.Repeat < 0 {
 			return errors.New(errors.ErrConfig,
-				fmt.Sprintf("--repeat must be >= 0, got %d", runRepeatFlag),
+				fmt.Sprintf("--repeat must be >= 0, got %d", runCmdFlags.Repeat),
 				"Use --repeat with a positive number like --repeat 5")
 		}
-		return runCommand(args, runHostFlag, runTagFlag, runProbeTimeoutFlag, runLocalFlag, runSkipRequirementsFlag, runRepeatFlag, runPullFlags, runPullDestFlag, runCwdFlag)
+		return runCommand(args, runCmdFlags)
 	},
 }
 
@@ -85,30 +76,57 @@ var execCmd = &cobra.Command{
 
 Useful for quick commands, checking status, or when files are already synced.
 
+With --rolling, runs the command on every host (or every host with --tag),
+--batch-size hosts at a time. Once more than --max-failures hosts fail, the
+rest are skipped. A rollout report lists how each host went.
+
 Examples:
   rr exec "ls -la"
   rr exec "git status"
-  rr exec "cat /var/log/app.log"`,
+  rr exec "cat /var/log/app.log"
+  rr exec --dir backend "uv run alembic current"
+  rr exec --rolling --tag web "systemctl restart app"
+  rr exec --rolling --tag web --batch-size 2 --max-failures 1 "systemctl restart app"`,
 	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return execCommand(args, execHostFlag, execTagFlag, execProbeTimeoutFlag, execLocalFlag, execSkipRequirementsFlag, execPullFlags, execPullDestFlag, execCwdFlag)
+		if execCmdFlags.Rolling {
+			return execRollingCommand(args, execCmdFlags)
+		}
+		if cmd.Flags().Changed("batch-size") || cmd.Flags().Changed("max-failures") {
+			return errors.New(errors.ErrConfig,
+				"--batch-size and --max-failures only apply to rolling execs",
+				"Add --rolling to run the command host by host.")
+		}
+		return execCommand(args, execCmdFlags)
 	},
 }
 
 // syncCmd syncs code to the remote host without executing
 var syncCmd = &cobra.Command{
-	Use:   "sync",
+	Use:   "sync [path...]",
 	Short: "Sync code to remote host",
 	Long: `Sync local code to the remote host without running any command.
 
 Uses rsync for efficient incremental file transfer.
 
+Given paths, only those files and directories are synced, which is quicker
+after touching one corner of a large project. Paths are relative to the
+current directory and must be inside the project; sync.exclude and
+.gitignore still apply inside them.
+
+With --daemon, rr keeps running, watches the project, and pushes changes as
+soon as files are saved. While the daemon is running and caught up, rr run
+and tasks targeting the same host skip their sync phase.
+
 Examples:
   rr sync
+  rr sync src/ assets/
   rr sync --dry-run
-  rr sync --host mini`,
+  rr sync --host mini
+  rr sync --profile data
+  rr sync --daemon`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return syncCommand(syncHostFlag, syncTagFlag, syncProbeTimeoutFlag, syncDryRun)
+		return syncCommand(args, syncCmdFlags)
 	},
 }
 
@@ -154,15 +172,53 @@ Examples:
   rr init --host myserver
   rr init --force
   rr init --non-interactive --host user@server --remote-dir ~/projects
-  CI=true rr init --host myserver --skip-probe`,
+  CI=true rr init --host myserver --skip-probe
+  rr init --non-interactive --import-tasks`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return initCommand(InitOptions{
 			Host:           initHostFlag,
 			Name:           initNameFlag,
 			Dir:            initRemoteDirFlag,
 			Overwrite:      initForce,
-			NonInteractive: initNonInteractive,
+			NonInteractive: NonInteractive(),
 			SkipProbe:      initSkipProbe,
+			ImportTasks:    initImportTasks,
+		})
+	},
+}
+
+// onboardCmd is the first-run wizard
+var onboardCmd = &cobra.Command{
+	Use:   "onboard",
+	Short: "Guided first-run setup",
+	Long: `Walk through everything rr needs, start to finish.
+
+Combines init, setup, and doctor:
+  - Pick a host, or import one from ~/.ssh/config
+  - Generate and deploy an SSH key if needed
+  - Write ~/.rr/config.yaml and .rr.yaml
+  - Run the doctor checks
+  - Smoke test with 'rr exec echo ok'
+  - Print a cheat sheet of common commands
+
+An existing .rr.yaml is kept unless you agree to replace it or pass --force.
+
+In non-interactive mode (--non-interactive or CI=true), uses --host, or
+every configured host if --host isn't given. Reads the same environment
+variables as 'rr init'.
+
+Examples:
+  rr onboard
+  rr onboard --non-interactive --host user@server --remote-dir ~/projects
+  rr onboard --skip-smoke-test`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return onboardCommand(OnboardOptions{
+			Host:           onboardHostFlag,
+			Name:           onboardNameFlag,
+			Dir:            onboardRemoteDirFlag,
+			Overwrite:      onboardForce,
+			NonInteractive: NonInteractive(),
+			SkipSmokeTest:  onboardSkipSmokeTest,
 		})
 	},
 }
@@ -191,13 +247,15 @@ Examples:
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show connection and sync status",
-	Long: `Display the current status of remote hosts and sync state.
+	Long: `Display the current status of remote hosts and the project, like
+'git status' for rr. No TUI, so it works in scripts and CI.
 
 Shows:
-  - Host connectivity
-  - Last sync time
-  - Lock status
-  - Configuration summary
+  - Host reachability and which host the next run would use
+  - Last sync to that host and how many local files changed since
+  - Who holds the lock on that host
+  - The last run's result
+  - Background work in progress (a running 'rr sync --daemon')
 
 Examples:
   rr status
@@ -217,28 +275,51 @@ for all configured remote hosts.
 Displays CPU, RAM, GPU (if available), and network metrics with
 color-coded status indicators and responsive layout.
 
+With --snapshot, collects one round of metrics, prints it as a table
+(or JSON/CSV with --format), and exits. Useful for cron jobs and quick
+checks.
+
+With --accessible, prints the same table again every interval (10s
+unless --interval is given) instead of starting the dashboard.
+
 Keyboard shortcuts:
   q / Ctrl+C  Quit
   r           Force refresh
-  s           Cycle sort order (name/CPU/RAM/GPU)
+  o           Cycle sort order (name/CPU/RAM/GPU)
   up/k        Select previous host
   down/j      Select next host
   Enter       Expand selected host details
+  s           SSH into the selected host
   Esc         Collapse / go back
   ?           Show help
 
+In the host details:
+  p           Expand the process table and select a process (up/k, down/j)
+  o           Sort processes by CPU, memory, or PID
+  x / X       Send SIGTERM / SIGKILL to the selected process (asks first)
+
 Examples:
   rr monitor
   rr monitor --hosts mini,workstation
-  rr monitor --interval 5s`,
+  rr monitor --interval 5s
+  rr monitor --snapshot                # One round of metrics as a table
+  rr monitor --snapshot --format csv   # Same, as CSV (or json)`,
 	PreRun: func(cmd *cobra.Command, args []string) {
-		// Monitor is always an interactive TUI, so force colors on
-		// even though the default output mode is machine-readable.
-		if !noColor {
-			ui.EnableColors()
-		}
+		// Monitor is always an interactive TUI, so turn colors on (as
+		// --color allows) even though the default output mode is
+		// machine-readable.
+		ui.EnableColors()
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if monitorSnapshotFlag || cmd.Flags().Changed("format") {
+			if !monitorSnapshotFlag {
+				return errors.New(errors.ErrConfig,
+					"--format only applies to snapshots",
+					"Add --snapshot to print metrics once instead of starting the dashboard.")
+			}
+			return monitorSnapshotCommand(monitorHostsFlag, monitorFormatFlag)
+		}
+
 		// Parse interval
 		interval := 2 * time.Second
 		if monitorIntervalFlag != "" {
@@ -256,6 +337,15 @@ Examples:
 			interval = parsed
 		}
 
+		if config.LowBandwidth() && !cmd.Flags().Changed("interval") {
+			interval = lowBandwidthMonitorInterval
+		}
+		if ui.Accessible() {
+			if !cmd.Flags().Changed("interval") {
+				interval = max(interval, accessibleMonitorInterval)
+			}
+			return monitorTextCommand(monitorHostsFlag, interval)
+		}
 		return monitorCommand(monitorHostsFlag, interval)
 	},
 }
@@ -273,9 +363,16 @@ Checks:
   - Lock file status
   - Network latency
 
+Exit codes:
+  0  no issues (or only issues below --fail-on)
+  1  warnings
+  2  failures
+
 Examples:
   rr doctor
-  rr doctor --fix`,
+  rr doctor --fix
+  rr doctor --category SSH,HOSTS
+  rr doctor --fail-on fail`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return errors.NewNotImplemented("doctor")
 	},
@@ -328,7 +425,8 @@ Each host can have multiple SSH connection fallbacks (e.g., LAN, Tailscale, VPN)
 Examples:
   rr host list              # List all configured hosts
   rr host add               # Add a new host interactively
-  rr host remove myserver   # Remove a host`,
+  rr host remove myserver   # Remove a host
+  rr host test myserver     # Smoke test a host end to end`,
 }
 
 // hostAddCmd adds a new host
@@ -360,10 +458,17 @@ var hostRemoveCmd = &cobra.Command{
 If no name is provided, shows a picker to select the host to remove.
 If you remove the default host, another host will be selected as the new default.
 
+rr then connects to the host, lists the project directories and lock files it
+created there, and offers to delete them. Use --cleanup to delete them without
+asking, --keep-remote to leave the host alone, or --dry-run to see what would
+be removed without changing anything. --yes skips the confirmation.
+
 Examples:
-  rr host remove           # Interactive selection
+  rr host remove                     # Interactive selection
   rr host remove myserver
-  rr host rm old-machine`,
+  rr host remove myserver --dry-run  # Show what would be removed
+  rr host rm old-machine --cleanup
+  rr host rm old-machine --yes --keep-remote`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		name := ""
@@ -381,16 +486,56 @@ var hostListCmd = &cobra.Command{
 	Short:   "List configured hosts",
 	Long: `List all hosts configured in your .rr.yaml file.
 
-Shows each host's name, SSH connections, and whether it's the default.
+Shows each host's name, SSH connections, and whether it's the default,
+along with its platform and hardware: OS/architecture, CPU cores, RAM, GPU
+model, and rsync version.
+
+Hardware is detected over SSH the first time a host is listed and cached in
+~/.rr/hostinfo.json for a week. Use --refresh to detect it again now.
+
+--usage adds who has had each host's time over the last week (--days to
+change it): time holding the host's lock and number of runs per user, from a
+log rr keeps on the host next to its locks. A hint names anyone who's had
+well over their share. For a host that can't be reached, it falls back to
+your own runs in this machine's run history.
 
 Examples:
   rr host list
-  rr host ls`,
+  rr host ls
+  rr host list --refresh
+  rr host list --usage --days 30`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return hostList()
 	},
 }
 
+// hostTestCmd runs the full pipeline against one host
+var hostTestCmd = &cobra.Command{
+	Use:   "test <name>",
+	Short: "Smoke test one host end to end",
+	Long: `Run everything rr does against one host, in isolation, and print a checklist.
+
+Steps, in order (the first failure skips the rest):
+  Reachable   connect through the host's SSH entries in order
+  Key auth    the host accepts your key without a password
+  Shell       detect the login shell commands run under
+  Directory   create the host's dir and check it's writable
+  Sync        rsync one small file into a scratch dir, then remove it
+  Exec        run echo through the host's shell, env and setup commands
+  Lock        take and release the host lock
+
+Use it after 'rr host add', or when a host misbehaves and you want to know
+which part is broken. Exits non-zero if any step fails.
+
+Examples:
+  rr host test gpu-box
+  rr host test gpu-box --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return hostTest(args[0])
+	},
+}
+
 // unlockCmd releases the lock on a remote host
 var unlockCmd = &cobra.Command{
 	Use:   "unlock [host]",
@@ -398,8 +543,9 @@ var unlockCmd = &cobra.Command{
 	Long: `Force-release the project lock on a remote host.
 
 Use this when a lock is stuck due to a crashed process or lost connection.
-The lock is project-specific, so this only releases the lock for the current
-directory's project.
+Which lock that is depends on lock.scope: the host-wide lock (host), the
+current project's lock (project), or every command lock of the current
+project (command).
 
 If no host is specified, uses the default host. With --all, releases locks
 on all configured hosts.
@@ -423,17 +569,27 @@ Examples:
 
 // tasksCmd lists available tasks
 var tasksCmd = &cobra.Command{
-	Use:   "tasks",
+	Use:   "tasks [filter]",
 	Short: "List available tasks",
 	Long: `List all tasks defined in your .rr.yaml configuration.
 
-Shows task names, descriptions, commands, and any host restrictions.
+Shows task names, descriptions, commands, dependencies, any host
+restrictions, and how recent runs went. A filter lists only the tasks
+whose name or description contains it.
 Tasks can be run directly as top-level commands (e.g., 'rr test').
 
 Examples:
-  rr tasks`,
+  rr tasks
+  rr tasks test               # Tasks mentioning "test"
+  rr tasks --type parallel    # Only parallel tasks
+  rr tasks --json             # For scripts and editor integrations`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return ListTasks()
+		opts := TasksOptions{Type: tasksTypeFlag}
+		if len(args) > 0 {
+			opts.Filter = args[0]
+		}
+		return ListTasks(opts)
 	},
 }
 
@@ -464,31 +620,50 @@ Examples:
 
 func init() {
 	// run command flags
-	runCmd.Flags().StringVar(&runHostFlag, "host", "", "target host name")
-	runCmd.Flags().StringVar(&runTagFlag, "tag", "", "select host by tag")
-	runCmd.Flags().StringVar(&runProbeTimeoutFlag, "probe-timeout", "", "SSH probe timeout (e.g., 5s, 2m)")
-	runCmd.Flags().BoolVar(&runLocalFlag, "local", false, "force local execution (skip remote hosts)")
-	runCmd.Flags().BoolVar(&runSkipRequirementsFlag, "skip-requirements", false, "skip requirement checks")
-	runCmd.Flags().IntVar(&runRepeatFlag, "repeat", 0, "run command N times in parallel across available hosts (for flake detection)")
-	runCmd.Flags().StringArrayVar(&runPullFlags, "pull", nil, "pull files from remote after command (can be repeated)")
-	runCmd.Flags().StringVar(&runPullDestFlag, "pull-dest", "", "destination directory for pulled files (default: current directory)")
-	runCmd.Flags().StringVar(&runCwdFlag, "cwd", "", "subdirectory to cd into on remote before running (relative to project root)")
+	runCmd.Flags().StringVar(&runCmdFlags.Host, "host", "", "target host name")
+	runCmd.Flags().StringVar(&runCmdFlags.Tag, "tag", "", "select host by tag")
+	runCmd.Flags().StringVar(&runCmdFlags.ProbeTimeout, "probe-timeout", "", "SSH probe timeout (e.g., 5s, 2m)")
+	runCmd.Flags().BoolVar(&runCmdFlags.Local, "local", false, "force local execution (skip remote hosts)")
+	runCmd.Flags().BoolVar(&runCmdFlags.SkipRequirements, "skip-requirements", false, "skip requirement checks")
+	runCmd.Flags().IntVar(&runCmdFlags.Repeat, "repeat", 0, "run command N times in parallel across available hosts (for flake detection)")
+	runCmd.Flags().StringArrayVar(&runCmdFlags.Pull, "pull", nil, "pull files from remote after command (can be repeated)")
+	runCmd.Flags().StringVar(&runCmdFlags.PullDest, "pull-dest", "", "destination directory for pulled files (default: current directory)")
+	runCmd.Flags().StringVar(&runCmdFlags.Dir, "dir", "", "subdirectory of the project to run the command in, like backend or packages/api")
+	runCmd.Flags().StringVar(&runCmdFlags.Dir, "cwd", "", "old name for --dir")
+	_ = runCmd.Flags().MarkHidden("cwd")
+	runCmd.Flags().BoolVar(&runCmdFlags.NoSummary, "no-summary", false, "don't print the phase timing summary after the run")
+	runCmd.Flags().BoolVar(&runCmdFlags.Diagnostics, "diagnostics", false, diagnosticsFlagUsage)
+	runCmd.Flags().BoolVar(&runCmdFlags.Force, "force", false, forceFlagUsage)
+	runCmd.Flags().BoolVar(&runCmdFlags.Rebootstrap, "rebootstrap", false, rebootstrapFlagUsage)
+	addNotifyWhenFreeFlag(runCmd)
 
 	// exec command flags
-	execCmd.Flags().StringVar(&execHostFlag, "host", "", "target host name")
-	execCmd.Flags().StringVar(&execTagFlag, "tag", "", "select host by tag")
-	execCmd.Flags().StringVar(&execProbeTimeoutFlag, "probe-timeout", "", "SSH probe timeout (e.g., 5s, 2m)")
-	execCmd.Flags().BoolVar(&execSkipRequirementsFlag, "skip-requirements", false, "skip requirement checks")
-	execCmd.Flags().BoolVar(&execLocalFlag, "local", false, "force local execution (skip remote hosts)")
-	execCmd.Flags().StringArrayVar(&execPullFlags, "pull", nil, "pull files from remote after command (can be repeated)")
-	execCmd.Flags().StringVar(&execPullDestFlag, "pull-dest", "", "destination directory for pulled files (default: current directory)")
-	execCmd.Flags().StringVar(&execCwdFlag, "cwd", "", "subdirectory to cd into on remote before running (relative to project root)")
+	execCmd.Flags().StringVar(&execCmdFlags.Host, "host", "", "target host name")
+	execCmd.Flags().StringVar(&execCmdFlags.Tag, "tag", "", "select host by tag")
+	execCmd.Flags().StringVar(&execCmdFlags.ProbeTimeout, "probe-timeout", "", "SSH probe timeout (e.g., 5s, 2m)")
+	execCmd.Flags().BoolVar(&execCmdFlags.SkipRequirements, "skip-requirements", false, "skip requirement checks")
+	execCmd.Flags().BoolVar(&execCmdFlags.Local, "local", false, "force local execution (skip remote hosts)")
+	execCmd.Flags().StringArrayVar(&execCmdFlags.Pull, "pull", nil, "pull files from remote after command (can be repeated)")
+	execCmd.Flags().StringVar(&execCmdFlags.PullDest, "pull-dest", "", "destination directory for pulled files (default: current directory)")
+	execCmd.Flags().StringVar(&execCmdFlags.Dir, "dir", "", "subdirectory of the project to run the command in, like backend or packages/api")
+	execCmd.Flags().StringVar(&execCmdFlags.Dir, "cwd", "", "old name for --dir")
+	_ = execCmd.Flags().MarkHidden("cwd")
+	execCmd.Flags().BoolVar(&execCmdFlags.NoSummary, "no-summary", false, "don't print the phase timing summary after the run")
+	execCmd.Flags().BoolVar(&execCmdFlags.Diagnostics, "diagnostics", false, diagnosticsFlagUsage)
+	execCmd.Flags().BoolVar(&execCmdFlags.Rolling, "rolling", false, "run on every host (or every host with --tag), a batch at a time")
+	execCmd.Flags().IntVar(&execCmdFlags.BatchSize, "batch-size", 1, "hosts to run on at once with --rolling")
+	execCmd.Flags().IntVar(&execCmdFlags.MaxFailures, "max-failures", 0, "failed hosts allowed before --rolling stops")
+	addNotifyWhenFreeFlag(execCmd)
 
 	// sync command flags
-	syncCmd.Flags().StringVar(&syncHostFlag, "host", "", "target host name")
-	syncCmd.Flags().StringVar(&syncTagFlag, "tag", "", "select host by tag")
-	syncCmd.Flags().StringVar(&syncProbeTimeoutFlag, "probe-timeout", "", "SSH probe timeout (e.g., 5s, 2m)")
-	syncCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "show what would be synced without syncing")
+	syncCmd.Flags().StringVar(&syncCmdFlags.Host, "host", "", "target host name")
+	syncCmd.Flags().StringVar(&syncCmdFlags.Tag, "tag", "", "select host by tag")
+	syncCmd.Flags().StringVar(&syncCmdFlags.ProbeTimeout, "probe-timeout", "", "SSH probe timeout (e.g., 5s, 2m)")
+	syncCmd.Flags().BoolVar(&syncCmdFlags.DryRun, "dry-run", false, "show what would be synced without syncing")
+	syncCmd.Flags().BoolVar(&syncCmdFlags.Daemon, "daemon", false, "keep running and push changes as files are saved")
+	syncCmd.Flags().BoolVar(&syncCmdFlags.Force, "force", false, forceFlagUsage)
+	syncCmd.Flags().BoolVar(&syncCmdFlags.Rebootstrap, "rebootstrap", false, rebootstrapFlagUsage)
+	syncCmd.Flags().StringVar(&syncCmdFlags.Profile, "profile", "", "sync with one of the sync.profiles from .rr.yaml")
 
 	// pull command flags
 	pullCmd.Flags().StringVar(&pullHostFlag, "host", "", "target host name")
@@ -502,12 +677,21 @@ func init() {
 	initCmd.Flags().StringVar(&initRemoteDirFlag, "remote-dir", "", "remote directory path (default: ~/rr/${PROJECT})")
 	initCmd.Flags().StringVar(&initNameFlag, "name", "", "friendly name for the host (default: extracted from host)")
 	initCmd.Flags().BoolVarP(&initForce, "force", "f", false, "overwrite existing config without prompting")
-	initCmd.Flags().BoolVar(&initNonInteractive, "non-interactive", false, "skip interactive prompts, use flags and defaults")
 	initCmd.Flags().BoolVar(&initSkipProbe, "skip-probe", false, "skip SSH connection testing")
+	initCmd.Flags().BoolVar(&initImportTasks, "import-tasks", false, "import Makefile targets and package.json/pyproject scripts as tasks without prompting")
+
+	// onboard command flags
+	onboardCmd.Flags().StringVar(&onboardHostFlag, "host", "", "SSH host (user@hostname or SSH config alias)")
+	onboardCmd.Flags().StringVar(&onboardRemoteDirFlag, "remote-dir", "", "remote directory path (default: ~/rr/${PROJECT})")
+	onboardCmd.Flags().StringVar(&onboardNameFlag, "name", "", "friendly name for the host (default: extracted from host)")
+	onboardCmd.Flags().BoolVarP(&onboardForce, "force", "f", false, "replace an existing .rr.yaml without prompting")
+	onboardCmd.Flags().BoolVar(&onboardSkipSmokeTest, "skip-smoke-test", false, "don't run a test command on the host at the end")
 
 	// monitor command flags
 	monitorCmd.Flags().StringVar(&monitorHostsFlag, "hosts", "", "filter to specific hosts (comma-separated)")
 	monitorCmd.Flags().StringVar(&monitorIntervalFlag, "interval", "1s", "refresh interval (e.g., 1s, 2s, 5s)")
+	monitorCmd.Flags().BoolVar(&monitorSnapshotFlag, "snapshot", false, "collect metrics once, print them, and exit")
+	monitorCmd.Flags().StringVar(&monitorFormatFlag, "format", "table", "snapshot output format: table, json, or csv")
 
 	// host command flags
 	hostAddCmd.Flags().BoolVar(&hostAddSkipProbe, "skip-probe", false, "skip SSH connection testing")
@@ -516,15 +700,28 @@ func init() {
 	hostAddCmd.Flags().StringVar(&hostAddDir, "dir", "", "remote directory path (default: ~/rr/${PROJECT})")
 	hostAddCmd.Flags().StringSliceVar(&hostAddTags, "tag", nil, "host tags (can be repeated)")
 	hostAddCmd.Flags().StringSliceVar(&hostAddEnv, "env", nil, "environment variables as KEY=VALUE (can be repeated)")
+	hostAddCmd.Flags().StringVar(&hostAddKey, "identity-file", "", "SSH private key to use for this host (default: agent and ~/.ssh/config)")
 
 	// host list flags
+	hostRemoveCmd.Flags().BoolVar(&hostRemoveCleanup, "cleanup", false, "delete rr project directories and lock files on the host without asking")
+	hostRemoveCmd.Flags().BoolVar(&hostRemoveKeepRemote, "keep-remote", false, "don't connect to the host or delete anything on it")
+	hostRemoveCmd.Flags().BoolVar(&hostRemoveDryRun, "dry-run", false, "list what would be removed without changing anything")
+	hostRemoveCmd.Flags().BoolVarP(&hostRemoveYes, "yes", "y", false, "remove the host without asking for confirmation")
+	hostRemoveCmd.MarkFlagsMutuallyExclusive("cleanup", "keep-remote")
+
 	hostListCmd.Flags().BoolVar(&hostListJSON, "json", false, "output in JSON format")
+	hostListCmd.Flags().BoolVar(&hostListRefresh, "refresh", false, "re-detect platform and hardware for every host, and re-query inventory sources")
+	hostListCmd.Flags().BoolVar(&hostListUsage, "usage", false, "show each user's time on each host and who's had more than their share")
+	hostListCmd.Flags().IntVar(&hostListDays, "days", 7, "with --usage, how many days back to count")
+
+	hostTestCmd.Flags().BoolVar(&hostTestJSON, "json", false, "output in JSON format")
 
 	// unlock command flags
 	unlockCmd.Flags().BoolVarP(&unlockAllFlag, "all", "a", false, "unlock all configured hosts")
 
 	// tasks command flags
 	tasksCmd.Flags().BoolVar(&tasksJSON, "json", false, "output in JSON format")
+	tasksCmd.Flags().StringVar(&tasksTypeFlag, "type", "", "only list tasks of this type: single, multi-step, parallel, or watch")
 
 	// provision command flags
 	provisionCmd.Flags().StringVar(&provisionHostFlag, "host", "", "target specific host (default: all project hosts)")
@@ -535,6 +732,7 @@ func init() {
 	hostCmd.AddCommand(hostAddCmd)
 	hostCmd.AddCommand(hostRemoveCmd)
 	hostCmd.AddCommand(hostListCmd)
+	hostCmd.AddCommand(hostTestCmd)
 
 	// Register all commands
 	rootCmd.AddCommand(runCmd)
@@ -542,6 +740,7 @@ func init() {
 	rootCmd.AddCommand(syncCmd)
 	rootCmd.AddCommand(pullCmd)
 	rootCmd.AddCommand(initCmd)
+	rootCmd.AddCommand(onboardCmd)
 	rootCmd.AddCommand(setupCmd)
 	rootCmd.AddCommand(statusCmd)
 	rootCmd.AddCommand(monitorCmd)
@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var configUndoForce bool
+
+// configCmd is the parent command for config file management
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage rr's config files",
+	Long: `Commands for rr's config files.
+
+Every time rr rewrites ~/.rr/config.yaml or .rr.yaml (rr host add/remove,
+rr init --force, fixes from rr doctor), it shows a diff of the change and
+keeps a copy of the old file under ~/.rr/backups. The last 50 are kept.
+
+Examples:
+  rr config undo            # Revert the last change rr made
+  rr config undo --force    # Revert it even if the file was edited since`,
+}
+
+// configUndoCmd reverts the last config rewrite
+var configUndoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Revert the last change rr made to a config file",
+	Long: `Restore a config file to what it was before rr last rewrote it.
+
+If the rewrite created the file, undo deletes it. Running undo again reverts
+the change before that, and so on. If the file was edited since rr wrote
+it, undo refuses unless --force is passed.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return configUndo(os.Stdout, configUndoForce)
+	},
+}
+
+func init() {
+	configUndoCmd.Flags().BoolVar(&configUndoForce, "force", false, "undo even if the file was edited since rr wrote it")
+	configCmd.AddCommand(configUndoCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// ConfigUndoOutput is the JSON representation of an undone change.
+type ConfigUndoOutput struct {
+	Path    string `json:"path"`
+	Removed bool   `json:"removed,omitempty"`
+	Time    string `json:"changed_at"`
+}
+
+// configUndo reverts the most recent config rewrite.
+func configUndo(w io.Writer, force bool) error {
+	backup, err := config.Undo(force)
+	if err != nil {
+		return err
+	}
+
+	if MachineMode() {
+		return WriteJSONSuccess(w, ConfigUndoOutput{
+			Path:    backup.Path,
+			Removed: backup.File == "",
+			Time:    backup.Time.UTC().Format(time.RFC3339),
+		})
+	}
+
+	if backup.File == "" {
+		fmt.Fprintf(w, "%s Removed %s, which rr created %s\n", ui.SymbolSuccess, backup.Path, formatAge(time.Since(backup.Time)))
+		return nil
+	}
+	fmt.Fprintf(w, "%s Restored %s to before the change rr made %s\n", ui.SymbolSuccess, backup.Path, formatAge(time.Since(backup.Time)))
+	return nil
+}
+
+// showConfigChange prints a colored unified diff of a config rewrite. It
+// goes to stderr, and only where someone will read it: in pretty mode or
+// when stderr is a terminal.
+func showConfigChange(c config.Change) {
+	if !PrettyMode() && !ui.IsTerminal(os.Stderr) {
+		return
+	}
+	diff := config.UnifiedDiff(c)
+	if diff == "" {
+		return
+	}
+	fmt.Fprint(os.Stderr, renderDiff(diff))
+}
+
+// renderDiff colors a unified diff: additions green, removals red, hunk
+// headers muted.
+func renderDiff(diff string) string {
+	addStyle := lipgloss.NewStyle().Foreground(ui.ColorSuccess)
+	removeStyle := lipgloss.NewStyle().Foreground(ui.ColorError)
+	headerStyle := lipgloss.NewStyle().Bold(true)
+	hunkStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
+
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		if line == "" {
+			continue
+		}
+		text := strings.TrimRight(line, "\n")
+		switch {
+		case strings.HasPrefix(text, "+++") || strings.HasPrefix(text, "---"):
+			text = headerStyle.Render(text)
+		case strings.HasPrefix(text, "@@"):
+			text = hunkStyle.Render(text)
+		case strings.HasPrefix(text, "+"):
+			text = addStyle.Render(text)
+		case strings.HasPrefix(text, "-"):
+			text = removeStyle.Render(text)
+		}
+		b.WriteString(text)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
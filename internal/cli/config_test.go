@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderDiff(t *testing.T) {
+	diff := "--- a/.rr.yaml\n+++ b/.rr.yaml\n@@ -1 +1 @@\n-host: mini\n+host: big\n"
+	assert.Equal(t, diff, renderDiff(diff), "without colors the diff is unchanged")
+}
+
+func TestConfigUndo(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), ".rr.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("host: mini\n"), 0644))
+	require.NoError(t, config.WriteFile(path, []byte("host: big\n"), 0644))
+
+	var out bytes.Buffer
+	require.NoError(t, configUndo(&out, false))
+	assert.Contains(t, out.String(), path)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "host: mini\n", string(data))
+
+	err = configUndo(&out, false)
+	assert.ErrorContains(t, err, "No config changes to undo")
+}
@@ -0,0 +1,262 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/rileyhilliard/rr/internal/sync"
+	"github.com/rileyhilliard/rr/internal/ui"
+	"github.com/rileyhilliard/rr/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cpProbeTimeoutFlag string
+	cpDryRun           bool
+)
+
+// cpCmd copies files between this machine and a host
+var cpCmd = &cobra.Command{
+	Use:   "cp <src>... <dest>",
+	Short: "Copy files to or from a host",
+	Long: `Copy files between this machine and a configured host, like scp but with the
+host's SSH settings and paths relative to its dir.
+
+Remote paths are written host:path, using the host's name from
+~/.rr/config.yaml. Leave the name out (:path) to use the default host.
+Relative remote paths start in the host's dir; absolute and ~ paths are
+used as given. Directories are copied whole, and a dest ending in / is a
+directory to copy into.
+
+Examples:
+  rr cp model.bin gpu-box:weights/
+  rr cp gpu-box:results/metrics.json .
+  rr cp :logs/run.log ./logs/
+  rr cp data.csv config.yaml gpu-box:~/scratch/`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cpCommand(os.Stdout, args, cpProbeTimeoutFlag, cpDryRun)
+	},
+}
+
+func init() {
+	cpCmd.Flags().StringVar(&cpProbeTimeoutFlag, "probe-timeout", "", "SSH probe timeout (e.g., 5s, 2m)")
+	cpCmd.Flags().BoolVar(&cpDryRun, "dry-run", false, "show what would be copied without copying")
+	rootCmd.AddCommand(cpCmd)
+}
+
+// CopyOutput is the JSON representation of a finished copy.
+type CopyOutput struct {
+	Host       string   `json:"host"`
+	Direction  string   `json:"direction"`
+	Sources    []string `json:"sources"`
+	Dest       string   `json:"dest"`
+	DryRun     bool     `json:"dry_run,omitempty"`
+	DurationMs int64    `json:"duration_ms"`
+}
+
+// copySpec is an rr cp command line: which host, which way, and what goes
+// where. Host is empty for the default host.
+type copySpec struct {
+	Host    string
+	Upload  bool
+	Sources []string
+	Dest    string
+}
+
+// parseCopyArgs works out the direction of a copy from which side of the
+// arguments is remote. Either the dest is remote and every source is local,
+// or the dest is local and every source is on the same host.
+func parseCopyArgs(args []string, hosts map[string]config.Host) (copySpec, error) {
+	if len(args) < 2 {
+		return copySpec{}, errors.New(errors.ErrExec,
+			"What should I copy, and where?",
+			"Usage: rr cp <src>... <dest>  (e.g., rr cp model.bin gpu-box:weights/)")
+	}
+
+	destHost, dest, destRemote, err := splitRemotePath(args[len(args)-1], hosts)
+	if err != nil {
+		return copySpec{}, err
+	}
+	spec := copySpec{Host: destHost, Upload: destRemote, Dest: dest}
+
+	for i, arg := range args[:len(args)-1] {
+		srcHost, src, remote, err := splitRemotePath(arg, hosts)
+		if err != nil {
+			return copySpec{}, err
+		}
+		switch {
+		case remote && destRemote:
+			return copySpec{}, errors.New(errors.ErrExec,
+				"rr cp can't copy between two remote paths",
+				"Copy to this machine first, then on to the other host.")
+		case !remote && !destRemote:
+			return copySpec{}, errors.New(errors.ErrExec,
+				fmt.Sprintf("Neither %s nor %s is on a host", arg, args[len(args)-1]),
+				"Write remote paths as host:path, or :path for the default host.")
+		case remote && i > 0 && srcHost != spec.Host:
+			return copySpec{}, errors.New(errors.ErrExec,
+				"All remote sources must be on the same host",
+				"Run rr cp once for each host.")
+		case remote:
+			spec.Host = srcHost
+		}
+		spec.Sources = append(spec.Sources, src)
+	}
+	return spec, nil
+}
+
+// splitRemotePath splits a host:path argument. Arguments without a colon,
+// or with a / before it, are local paths. An empty host name means the
+// default host; any other name has to be a configured host.
+func splitRemotePath(arg string, hosts map[string]config.Host) (hostName, p string, remote bool, err error) {
+	i := strings.Index(arg, ":")
+	if i < 0 || strings.Contains(arg[:i], "/") {
+		return "", arg, false, nil
+	}
+	hostName = arg[:i]
+	if hostName != "" {
+		if _, ok := hosts[hostName]; !ok {
+			names := make([]string, 0, len(hosts))
+			for name := range hosts {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return "", "", false, errors.New(errors.ErrConfig,
+				fmt.Sprintf("Host '%s' in %s isn't configured", hostName, arg),
+				fmt.Sprintf("Available hosts: %s. For a local path with a colon in it, write ./%s.", util.JoinOrNone(names), arg))
+		}
+	}
+	return hostName, arg[i+1:], true, nil
+}
+
+// cpCommand copies files to or from one host.
+func cpCommand(w io.Writer, args []string, probeTimeoutFlag string, dryRun bool) error {
+	probeTimeout, err := ParseProbeTimeout(probeTimeoutFlag)
+	if err != nil {
+		return err
+	}
+
+	resolved, err := loadResolved(Config())
+	if err != nil {
+		return err
+	}
+	if err := config.ValidateResolved(resolved); err != nil {
+		return err
+	}
+
+	spec, err := parseCopyArgs(args, resolved.Global.Hosts)
+	if err != nil {
+		return err
+	}
+	hostName, h, err := config.ResolveHost(resolved, spec.Host)
+	if err != nil {
+		return err
+	}
+
+	// Files live on one particular host, so don't fall back to another.
+	selector := host.NewSelector(map[string]config.Host{hostName: *h})
+	selector.SetHostOrder([]string{hostName})
+	defer selector.Close()
+	if probeTimeout == 0 {
+		probeTimeout = resolved.Global.Defaults.ProbeTimeout
+	}
+	if probeTimeout > 0 {
+		selector.SetTimeout(probeTimeout)
+	}
+
+	startTime := time.Now()
+	spinner := ui.NewSpinner("Connecting to " + hostName)
+	spinner.Start()
+	conn, err := selector.Select(hostName)
+	if err != nil {
+		spinner.Fail()
+		return err
+	}
+	if conn.IsLocal {
+		spinner.Fail()
+		return errors.New(errors.ErrSSH,
+			fmt.Sprintf("Couldn't reach %s", hostName),
+			"rr cp needs an SSH connection. Check the host with 'rr host test'.")
+	}
+	spinner.Success()
+
+	opts := sync.CopyOptions{Sources: spec.Sources, Dest: spec.Dest, Upload: spec.Upload}
+	if dryRun {
+		opts.Flags = append(opts.Flags, "--dry-run", "-v")
+	}
+
+	if err := copyWithProgress(conn, opts); err != nil {
+		return err
+	}
+
+	out := CopyOutput{
+		Host:       hostName,
+		Direction:  "download",
+		Sources:    spec.Sources,
+		Dest:       spec.Dest,
+		DryRun:     dryRun,
+		DurationMs: time.Since(startTime).Milliseconds(),
+	}
+	if spec.Upload {
+		out.Direction = "upload"
+	}
+	if MachineMode() {
+		return WriteJSONSuccess(w, out)
+	}
+	renderCopy(w, out)
+	return nil
+}
+
+// copyWithProgress runs a copy, showing rsync's progress as a bar in
+// pretty mode.
+func copyWithProgress(conn *host.Connection, opts sync.CopyOptions) error {
+	if MachineMode() || config.LowBandwidth() {
+		return sync.Copy(conn, opts, nil)
+	}
+
+	label := "Downloading"
+	if opts.Upload {
+		label = "Uploading"
+	}
+	progress := ui.NewInlineProgress(label, os.Stdout)
+	progress.SetUseFakeProgress(false) // Use real rsync progress
+	progressWriter := ui.NewProgressWriter(progress, nil)
+	progress.Start()
+	if err := sync.Copy(conn, opts, progressWriter); err != nil {
+		progress.Fail()
+		return err
+	}
+	progress.Success()
+	return nil
+}
+
+// renderCopy prints a one-line summary of a finished copy.
+func renderCopy(w io.Writer, out CopyOutput) {
+	duration := fmt.Sprintf("%.1fs", float64(out.DurationMs)/1000)
+	if out.DryRun {
+		fmt.Fprintf(w, "%s Dry run completed in %s\n", ui.SymbolComplete, duration)
+		return
+	}
+
+	what := out.Sources[0]
+	if len(out.Sources) > 1 {
+		what = fmt.Sprintf("%d paths", len(out.Sources))
+	}
+	dest := out.Dest
+	if dest == "" {
+		dest = "."
+	}
+	if out.Direction == "upload" {
+		fmt.Fprintf(w, "%s Copied %s to %s:%s in %s\n", ui.SymbolComplete, what, out.Host, dest, duration)
+	} else {
+		fmt.Fprintf(w, "%s Copied %s from %s to %s in %s\n", ui.SymbolComplete, what, out.Host, dest, duration)
+	}
+}
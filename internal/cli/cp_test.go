@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCopyArgs(t *testing.T) {
+	hosts := map[string]config.Host{"gpu-box": {}, "mini": {}}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    copySpec
+		wantErr string
+	}{
+		{
+			name: "upload",
+			args: []string{"model.bin", "data/", "gpu-box:weights/"},
+			want: copySpec{Host: "gpu-box", Upload: true, Sources: []string{"model.bin", "data/"}, Dest: "weights/"},
+		},
+		{
+			name: "download",
+			args: []string{"mini:results/a.json", "mini:results/b.json", "."},
+			want: copySpec{Host: "mini", Sources: []string{"results/a.json", "results/b.json"}, Dest: "."},
+		},
+		{
+			name: "default host",
+			args: []string{":logs/run.log", "logs/"},
+			want: copySpec{Sources: []string{"logs/run.log"}, Dest: "logs/"},
+		},
+		{
+			name: "colon after a slash is local",
+			args: []string{"./a:b", "gpu-box:"},
+			want: copySpec{Host: "gpu-box", Upload: true, Sources: []string{"./a:b"}, Dest: ""},
+		},
+		{name: "both remote", args: []string{"mini:a", "gpu-box:b"}, wantErr: "two remote paths"},
+		{name: "both local", args: []string{"a", "b"}, wantErr: "is on a host"},
+		{name: "sources on two hosts", args: []string{"mini:a", "gpu-box:b", "."}, wantErr: "same host"},
+		{name: "unknown host", args: []string{"a", "nope:b"}, wantErr: "'nope' in nope:b isn't configured"},
+		{name: "missing dest", args: []string{"a"}, wantErr: "What should I copy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCopyArgs(tt.args, hosts)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRenderCopy(t *testing.T) {
+	var buf bytes.Buffer
+	renderCopy(&buf, CopyOutput{Host: "gpu-box", Direction: "upload", Sources: []string{"model.bin"}, Dest: "weights/", DurationMs: 1500})
+	assert.Contains(t, buf.String(), "Copied model.bin to gpu-box:weights/ in 1.5s")
+
+	buf.Reset()
+	renderCopy(&buf, CopyOutput{Host: "mini", Direction: "download", Sources: []string{"a", "b"}, DurationMs: 200})
+	assert.Contains(t, buf.String(), "Copied 2 paths from mini to . in 0.2s")
+}
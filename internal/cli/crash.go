@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/diagnostics"
+	"github.com/rileyhilliard/rr/internal/exec"
+	"github.com/rileyhilliard/rr/internal/logger"
+	"github.com/rileyhilliard/rr/internal/results"
+	"github.com/rileyhilliard/rr/internal/ui"
+)
+
+const (
+	// crashDirName is the directory under the project's .rr directory that
+	// holds crash evidence, one directory per run named after its run ID
+	// (the same ID 'rr results' uses).
+	crashDirName = "crash"
+
+	// maxCrashRuns caps how many runs' crash evidence is kept per project.
+	// Older ones are deleted when a new one is saved.
+	maxCrashRuns = 20
+
+	// maxCrashHighlights is how many kernel log lines about the crash are
+	// shown under the pointer to the evidence.
+	maxCrashHighlights = 3
+)
+
+// collectCrashEvidence saves what the host recorded when a remote command
+// crashed or was killed (a segfault, an abort, an OOM kill) to
+// .rr/crash/<run-id>/ and points at it: below the output in pretty mode,
+// or as a "diagnosis" event in structured mode. The host is only asked
+// when the exit code or output looks like a crash. Errors are logged; they
+// never fail a run.
+func collectCrashEvidence(wf *WorkflowContext, exitCode int) {
+	if exitCode == 0 || exitCode == 130 || wf.Conn == nil || wf.Conn.IsLocal || wf.Conn.Client == nil {
+		return
+	}
+	var output []byte
+	if wf.Output != nil {
+		output = wf.Output.Bytes()
+	}
+	if !exec.LooksLikeCrash(exitCode, string(output)) {
+		return
+	}
+
+	evidence, err := exec.CollectCrashEvidence(wf.Conn.Client, &wf.Conn.Host, time.Since(wf.StartTime))
+	if err != nil {
+		logger.Verbosef(logger.LevelPhases, "crash", "couldn't collect evidence from %s: %v", wf.Conn.Name, err)
+		return
+	}
+	dir, err := saveCrashEvidence(wf.WorkDir, results.RunID(wf.StartTime), wf.Conn.Name, exitCode, output, evidence)
+	if err != nil {
+		logger.Verbosef(logger.LevelPhases, "crash", "evidence not saved: %v", err)
+		return
+	}
+
+	if PrettyMode() {
+		renderCrashEvidence(wf.Conn.Name, dir, evidence)
+		return
+	}
+	files := make([]string, 0, len(evidence.Files))
+	for _, f := range evidence.Files {
+		files = append(files, f.Name)
+	}
+	WritePhaseEvent(PhaseEvent{
+		Type:     "diagnosis",
+		Status:   "crash",
+		Host:     wf.Conn.Name,
+		ExitCode: &exitCode,
+		Details: map[string]interface{}{
+			"signal":     exec.CrashSignal(exitCode),
+			"dir":        dir,
+			"files":      files,
+			"highlights": evidence.Highlights,
+		},
+	})
+}
+
+// saveCrashEvidence writes a run's crash evidence to .rr/crash/<runID>/ in
+// the project: a run.txt describing the crash, the tail of the command's
+// output, and each file the host gave, then deletes the oldest runs past
+// maxCrashRuns. Returns the directory. The output and kernel log can hold
+// secrets, so only the user can read them.
+func saveCrashEvidence(projectRoot, runID, hostName string, exitCode int, output []byte, evidence *exec.CrashEvidence) (string, error) {
+	if projectRoot == "" {
+		if wd, err := os.Getwd(); err == nil {
+			projectRoot = wd
+		}
+	}
+	rrDir, err := diagnostics.EnsureDir(projectRoot)
+	if err != nil {
+		return "", err
+	}
+	root := filepath.Join(rrDir, crashDirName)
+	dir := filepath.Join(root, runID)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+
+	summary := fmt.Sprintf("host: %s\nos: %s\nexit code: %d\n", hostName, evidence.OS, exitCode)
+	if signal := exec.CrashSignal(exitCode); signal != "" {
+		summary += "signal: " + signal + "\n"
+	}
+	summary += "collected: " + time.Now().Format(time.RFC3339) + "\n"
+	files := []exec.CrashFile{{Name: "run.txt", Content: summary}}
+	if len(output) > 0 {
+		files = append(files, exec.CrashFile{Name: "output.txt", Content: string(output)})
+	}
+	for _, f := range append(files, evidence.Files...) {
+		if err := os.WriteFile(filepath.Join(dir, f.Name), []byte(f.Content), 0600); err != nil {
+			return "", err
+		}
+	}
+
+	pruneCrashEvidence(root)
+	return dir, nil
+}
+
+// pruneCrashEvidence deletes the oldest runs' crash evidence past
+// maxCrashRuns. Run IDs sort by start time.
+func pruneCrashEvidence(root string) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return
+	}
+	var runs []string
+	for _, e := range entries {
+		if e.IsDir() {
+			runs = append(runs, e.Name())
+		}
+	}
+	if len(runs) <= maxCrashRuns {
+		return
+	}
+	sort.Strings(runs)
+	for _, run := range runs[:len(runs)-maxCrashRuns] {
+		_ = os.RemoveAll(filepath.Join(root, run))
+	}
+}
+
+// renderCrashEvidence prints where a crash's evidence was saved after the
+// output, with the kernel's own account of the crash when it has one.
+func renderCrashEvidence(hostName, dir string, evidence *exec.CrashEvidence) {
+	mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
+
+	fmt.Println()
+	fmt.Printf("%s Crash evidence from %s saved to %s\n", ui.SymbolWarning, hostName, dir)
+	highlights := evidence.Highlights
+	if len(highlights) > maxCrashHighlights {
+		highlights = highlights[len(highlights)-maxCrashHighlights:]
+	}
+	for _, line := range highlights {
+		fmt.Println(mutedStyle.Render("  " + line))
+	}
+	if len(evidence.Files) > 0 {
+		names := make([]string, 0, len(evidence.Files))
+		for _, f := range evidence.Files {
+			names = append(names, f.Name)
+		}
+		fmt.Println(mutedStyle.Render("  " + strings.Join(names, ", ")))
+	}
+}
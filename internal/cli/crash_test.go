@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/exec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveCrashEvidence(t *testing.T) {
+	root := t.TempDir()
+	evidence := &exec.CrashEvidence{
+		OS:    "Linux",
+		Files: []exec.CrashFile{{Name: "dmesg.txt", Content: "python3[4121]: segfault at 0\n"}},
+	}
+
+	dir, err := saveCrashEvidence(root, "20261016-153045-123", "mini", 139, []byte("Segmentation fault\n"), evidence)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, ".rr", "crash", "20261016-153045-123"), dir)
+
+	summary, err := os.ReadFile(filepath.Join(dir, "run.txt"))
+	require.NoError(t, err)
+	assert.Contains(t, string(summary), "host: mini\nos: Linux\nexit code: 139\nsignal: SIGSEGV\n")
+	assert.FileExists(t, filepath.Join(dir, "output.txt"))
+	assert.FileExists(t, filepath.Join(dir, "dmesg.txt"))
+	info, err := os.Stat(filepath.Join(dir, "output.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "output can hold secrets")
+	assert.FileExists(t, filepath.Join(root, ".rr", ".gitignore"), "evidence stays out of git and syncs")
+}
+
+func TestSaveCrashEvidence_PrunesOldRuns(t *testing.T) {
+	root := t.TempDir()
+	for i := 0; i < maxCrashRuns+2; i++ {
+		_, err := saveCrashEvidence(root, fmt.Sprintf("20261016-1530%02d-000", i), "mini", 137, nil, &exec.CrashEvidence{})
+		require.NoError(t, err)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(root, ".rr", "crash"))
+	require.NoError(t, err)
+	assert.Len(t, entries, maxCrashRuns)
+	assert.Equal(t, "20261016-153002-000", entries[0].Name(), "the oldest runs go first")
+}
+
+func TestRenderCrashEvidence(t *testing.T) {
+	out := captureStdout(t, func() {
+		renderCrashEvidence("mini", "/proj/.rr/crash/20261016-153045-123", &exec.CrashEvidence{
+			Files:      []exec.CrashFile{{Name: "dmesg.txt"}, {Name: "core_pattern.txt"}},
+			Highlights: []string{"a", "b", "c", "python3[4121]: segfault at 0"},
+		})
+	})
+	assert.Contains(t, out, "Crash evidence from mini saved to /proj/.rr/crash/20261016-153045-123")
+	assert.Contains(t, out, "python3[4121]: segfault at 0")
+	assert.NotContains(t, out, "  a\n", "only the last few kernel log lines are shown")
+	assert.Contains(t, out, "dmesg.txt, core_pattern.txt")
+}
@@ -0,0 +1,7 @@
+//go:build windows
+
+package cli
+
+import "os/exec"
+
+func detachProcess(cmd *exec.Cmd) {}
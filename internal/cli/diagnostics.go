@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/diagnostics"
+	"github.com/rileyhilliard/rr/internal/parallel"
+)
+
+// diagnosticsFlagUsage is the help text for --diagnostics on every command
+// that supports it.
+const diagnosticsFlagUsage = "write test failure locations to .rr/diagnostics.json for editor plugins"
+
+// writeRunDiagnostics writes the failures found in a single-host run's
+// captured output to the project's diagnostics file. name is what ran (the
+// command or task name) and command is used to pick the output parser.
+// Errors are reported as warnings; they never fail a run.
+func writeRunDiagnostics(wf *WorkflowContext, name, command string, exitCode int) {
+	if wf.Conn == nil {
+		return
+	}
+
+	report := diagnostics.NewReport(name, exitCode, time.Now())
+	if wf.Output != nil {
+		report.AddOutput(command, wf.Output.Bytes(), wf.Conn.Name, diagnosticsDir(wf.Conn.IsLocal, wf.WorkDir, wf.Conn.Host), "")
+	}
+	saveDiagnostics(wf.WorkDir, report)
+}
+
+// writeParallelDiagnostics writes the failures of every task in a parallel
+// run to the project's diagnostics file.
+func writeParallelDiagnostics(projectRoot, name string, hosts map[string]config.Host, result *parallel.Result) {
+	exitCode := 0
+	if result.Failed > 0 {
+		exitCode = 1
+	}
+	report := diagnostics.NewReport(name, exitCode, time.Now())
+	for i := range result.TaskResults {
+		tr := &result.TaskResults[i]
+		if tr.Success() {
+			continue
+		}
+		h := hosts[tr.Host]
+		report.AddOutput(tr.Command, tr.Output, tr.Host, diagnosticsDir(tr.Host == "local", projectRoot, h), tr.TaskName)
+	}
+	saveDiagnostics(projectRoot, report)
+}
+
+// writeSpeculativeDiagnostics writes the failures of the attempt that
+// decided a speculative run. A run that succeeded anywhere has none.
+func writeSpeculativeDiagnostics(projectRoot, name string, hosts map[string]config.Host, result *parallel.SpeculativeResult) {
+	report := diagnostics.NewReport(name, speculativeExitCode(result), time.Now())
+	if outcome := result.Outcome(); outcome != nil && !result.Success() {
+		report.AddOutput(outcome.Command, outcome.Output, outcome.Host, diagnosticsDir(false, projectRoot, hosts[outcome.Host]), "")
+	}
+	saveDiagnostics(projectRoot, report)
+}
+
+// diagnosticsDir returns the directory a command ran in, for turning the
+// absolute paths in its output into project-relative ones.
+func diagnosticsDir(local bool, workDir string, h config.Host) string {
+	if local {
+		return workDir
+	}
+	return config.ExpandRemote(h.Dir)
+}
+
+// saveDiagnostics writes a report, warning on failure.
+func saveDiagnostics(projectRoot string, report *diagnostics.Report) {
+	if projectRoot == "" {
+		if wd, err := os.Getwd(); err == nil {
+			projectRoot = wd
+		}
+	}
+	if err := diagnostics.Write(projectRoot, report); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
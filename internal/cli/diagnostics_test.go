@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/diagnostics"
+	"github.com/rileyhilliard/rr/internal/parallel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteParallelDiagnostics(t *testing.T) {
+	root := t.TempDir()
+	hosts := map[string]config.Host{"mini": {Dir: "/srv/app"}}
+	result := &parallel.Result{
+		Passed: 1,
+		Failed: 1,
+		TaskResults: []parallel.TaskResult{
+			{TaskName: "lint", Command: "make lint", Host: "mini", ExitCode: 0},
+			{
+				TaskName: "test-go",
+				Command:  "go test ./...",
+				Host:     "mini",
+				ExitCode: 1,
+				Output: []byte("=== RUN   TestFail\n" +
+					"    /srv/app/pkg/x_test.go:15: Expected 1, got 2\n" +
+					"--- FAIL: TestFail (0.00s)\nFAIL\nFAIL\texample\t0.005s\n"),
+			},
+		},
+	}
+
+	writeParallelDiagnostics(root, "ci", hosts, result)
+
+	report, err := diagnostics.Load(root)
+	require.NoError(t, err)
+	assert.Equal(t, "ci", report.Command)
+	assert.Equal(t, 1, report.ExitCode)
+	require.Len(t, report.Diagnostics, 1)
+	d := report.Diagnostics[0]
+	assert.Equal(t, "pkg/x_test.go", d.File)
+	assert.Equal(t, 15, d.Line)
+	assert.Equal(t, "TestFail", d.Test)
+	assert.Equal(t, "test-go", d.Task)
+	assert.Equal(t, "mini", d.Host)
+	assert.Equal(t, "gotest", d.Source)
+}
+
+func TestWriteParallelDiagnostics_AllPassedWritesEmptyList(t *testing.T) {
+	root := t.TempDir()
+	result := &parallel.Result{
+		Passed:      1,
+		TaskResults: []parallel.TaskResult{{TaskName: "lint", Command: "make lint", Host: "mini"}},
+	}
+
+	writeParallelDiagnostics(root, "ci", nil, result)
+
+	report, err := diagnostics.Load(root)
+	require.NoError(t, err)
+	assert.Equal(t, 0, report.ExitCode)
+	assert.Empty(t, report.Diagnostics)
+}
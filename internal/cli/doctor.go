@@ -2,15 +2,17 @@ package cli
 
 import (
 	"encoding/json"
-	"errors"
+	stderrors "errors"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/rileyhilliard/rr/internal/config"
 	"github.com/rileyhilliard/rr/internal/doctor"
+	"github.com/rileyhilliard/rr/internal/errors"
 	"github.com/rileyhilliard/rr/internal/host"
 	"github.com/rileyhilliard/rr/internal/ui"
 	"github.com/rileyhilliard/rr/pkg/sshutil"
@@ -21,14 +23,20 @@ var (
 	doctorJSON         bool
 	doctorFix          bool
 	doctorPath         bool
+	doctorEnv          bool
 	doctorRequirements bool
+	doctorFailOn       string
+	doctorCategories   []string
 )
 
 func init() {
 	doctorCmd.Flags().BoolVar(&doctorJSON, "json", false, "output in JSON format")
 	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "attempt automatic fixes where possible")
 	doctorCmd.Flags().BoolVar(&doctorPath, "path", false, "check PATH differences between login and interactive shells")
+	doctorCmd.Flags().BoolVar(&doctorEnv, "env", false, "compare tool versions and locale between local and each host")
 	doctorCmd.Flags().BoolVar(&doctorRequirements, "requirements", false, "check that required tools are available on remote hosts")
+	doctorCmd.Flags().StringVar(&doctorFailOn, "fail-on", "warn", "lowest severity that causes a non-zero exit: warn or fail")
+	doctorCmd.Flags().StringSliceVar(&doctorCategories, "category", nil, "only run checks in these categories (e.g., SSH,HOSTS)")
 }
 
 // DoctorOutput represents the JSON output for doctor command.
@@ -53,7 +61,17 @@ type SummaryOutput struct {
 }
 
 // doctorCommand implements the doctor command logic.
+// Exits 0 when clear, 1 when the worst issue is a warning, and 2 on failures.
+// Issues below the --fail-on threshold don't affect the exit code.
 func doctorCommand() error {
+	failOn, err := parseDoctorFailOn(doctorFailOn)
+	if err != nil {
+		return err
+	}
+	if err := validateDoctorCategories(doctorCategories); err != nil {
+		return err
+	}
+
 	// Load project config (if it exists)
 	cfgPath, err := config.Find(Config())
 	var projectCfg *config.Config
@@ -70,7 +88,7 @@ func doctorCommand() error {
 
 	// If --path or --requirements flag, establish connections
 	var pathClients map[string]sshutil.SSHClient
-	needConnections := (doctorPath || doctorRequirements) && globalCfg != nil && len(globalCfg.Hosts) > 0
+	needConnections := (doctorPath || doctorEnv || doctorRequirements) && globalCfg != nil && len(globalCfg.Hosts) > 0
 	if needConnections {
 		pathClients = establishPathConnections(globalCfg)
 		defer closePathConnections(pathClients)
@@ -79,14 +97,20 @@ func doctorCommand() error {
 			if doctorPath {
 				checks = append(checks, doctor.NewPathChecks(pathClients)...)
 			}
+			if doctorEnv {
+				checks = append(checks, doctor.NewEnvChecks(globalCfg.Hosts, pathClients, "")...)
+			}
 			if doctorRequirements {
 				// Create host.Connection wrappers for requirements checks
 				connections := make(map[string]*host.Connection)
 				for name, client := range pathClients {
+					// establishPathConnections only connected hosts whose
+					// secrets resolve
+					hostCfg, _ := config.ResolveHostSecrets(name, globalCfg.Hosts[name])
 					connections[name] = &host.Connection{
 						Name:   name,
 						Client: client,
-						Host:   globalCfg.Hosts[name],
+						Host:   hostCfg,
 					}
 				}
 				checks = append(checks, doctor.NewRequirementsChecks(globalCfg.Hosts, connections, projectCfg)...)
@@ -94,24 +118,61 @@ func doctorCommand() error {
 		}
 	}
 
+	checks = doctor.FilterByCategory(checks, doctorCategories)
+
+	var results []doctor.CheckResult
+
 	// Machine mode implies JSON output - run checks without progress display
 	if doctorJSON || MachineMode() {
-		results := doctor.RunAll(checks)
+		results = doctor.RunAll(checks)
 		if doctorFix {
 			results = attemptFixes(checks, results)
 		}
-		return outputDoctorJSON(checks, results)
+		if err := outputDoctorJSON(checks, results); err != nil {
+			return err
+		}
+	} else {
+		// Run checks with progressive output (shows spinner per category)
+		results = runChecksWithProgress(checks)
+
+		// Try to fix issues if requested
+		if doctorFix {
+			results = attemptFixes(checks, results)
+		}
+
+		if err := outputDoctorTextResults(checks, results); err != nil {
+			return err
+		}
 	}
 
-	// Run checks with progressive output (shows spinner per category)
-	results := runChecksWithProgress(checks)
+	if code := doctor.ExitCode(results, failOn); code != doctor.ExitClear {
+		return errors.NewExitError(code)
+	}
+	return nil
+}
 
-	// Try to fix issues if requested
-	if doctorFix {
-		results = attemptFixes(checks, results)
+// parseDoctorFailOn validates the --fail-on threshold.
+func parseDoctorFailOn(value string) (doctor.CheckStatus, error) {
+	status, ok := doctor.ParseStatus(value)
+	if !ok || status == doctor.StatusPass {
+		return doctor.StatusWarn, errors.New(errors.ErrConfig,
+			fmt.Sprintf("--fail-on '%s' isn't a valid severity", value),
+			"Use --fail-on warn (exit non-zero on warnings or failures) or --fail-on fail (failures only).")
 	}
+	return status, nil
+}
 
-	return outputDoctorTextResults(checks, results)
+// validateDoctorCategories rejects unknown --category values so a typo
+// doesn't silently run zero checks and exit clean.
+func validateDoctorCategories(categories []string) error {
+	for _, c := range categories {
+		if !slices.Contains(doctor.Categories, strings.ToUpper(strings.TrimSpace(c))) {
+			return errors.New(errors.ErrConfig,
+				fmt.Sprintf("Unknown doctor category '%s'", c),
+				"Valid categories: "+strings.Join(doctor.Categories, ", "))
+		}
+	}
+	return nil
 }
 
 // runChecksWithProgress runs checks with spinner feedback, showing progress by category.
@@ -119,7 +180,7 @@ func runChecksWithProgress(checks []doctor.Check) []doctor.CheckResult {
 	results := make([]doctor.CheckResult, len(checks))
 
 	// Group checks by category while preserving order
-	categoryOrder := []string{"CONFIG", "SSH", "HOSTS", "DEPENDENCIES", "PATH", "REMOTE", "REQUIREMENTS"}
+	categoryOrder := doctor.Categories
 	grouped := make(map[string][]int) // category -> indices
 
 	for i, check := range checks {
@@ -148,9 +209,7 @@ func runChecksWithProgress(checks []doctor.Check) []doctor.CheckResult {
 			results[idx] = checks[idx].Run()
 		}
 
-		spinner.Stop()
-		// Clear spinner line
-		fmt.Print("\r\033[K")
+		spinner.Dismiss()
 
 		// Render the category results immediately
 		renderCategoryResults(category, checks, results, indices)
@@ -189,13 +248,13 @@ func establishPathConnections(globalCfg *config.GlobalConfig) map[string]sshutil
 	clients := make(map[string]sshutil.SSHClient)
 
 	for name := range globalCfg.Hosts {
-		hostCfg := globalCfg.Hosts[name]
-		if len(hostCfg.SSH) == 0 {
+		hostCfg, err := config.ResolveHostSecrets(name, globalCfg.Hosts[name])
+		if err != nil || len(hostCfg.SSH) == 0 {
 			continue
 		}
 
 		// Try first SSH alias
-		client, err := sshutil.Dial(hostCfg.SSH[0], 10*time.Second)
+		client, _, err := host.Connect(hostCfg.SSH[0], 10*time.Second, hostCfg)
 		if err == nil {
 			clients[name] = client
 		}
@@ -221,8 +280,9 @@ func collectChecks(cfgPath string, _ *config.Config, globalCfg *config.GlobalCon
 	// SSH checks (always run)
 	checks = append(checks, doctor.NewSSHChecks()...)
 
-	// Host connectivity checks (if global config with hosts exists)
+	// SSH config and host connectivity checks (if global config with hosts exists)
 	if globalCfg != nil && len(globalCfg.Hosts) > 0 {
+		checks = append(checks, doctor.NewSSHConfigChecks(globalCfg.Hosts)...)
 		checks = append(checks, doctor.NewHostsChecks(globalCfg.Hosts)...)
 	}
 
@@ -347,7 +407,7 @@ func outputDoctorText(checks []doctor.Check, results []doctor.CheckResult) error
 	fmt.Println()
 
 	// Group checks by category
-	categoryOrder := []string{"CONFIG", "SSH", "HOSTS", "DEPENDENCIES", "PATH", "REMOTE", "REQUIREMENTS"}
+	categoryOrder := doctor.Categories
 	grouped := make(map[string][]int) // category -> indices
 
 	for i, check := range checks {
@@ -566,7 +626,7 @@ func formatProbeError(err error) string {
 	// For host key errors, try to extract more detail
 	if probeErr.Reason == host.ProbeFailHostKey {
 		var hostKeyErr *sshutil.HostKeyMismatchError
-		if errors.As(probeErr.Cause, &hostKeyErr) {
+		if stderrors.As(probeErr.Cause, &hostKeyErr) {
 			// Show which key type was expected vs received
 			return fmt.Sprintf("Host key mismatch (got %s, expected different type)", hostKeyErr.ReceivedType)
 		}
@@ -613,7 +673,7 @@ func getSSHErrorSuggestion(err error, alias string) string {
 	case host.ProbeFailHostKey:
 		// Check if we have a detailed HostKeyMismatchError with specific suggestion
 		var hostKeyErr *sshutil.HostKeyMismatchError
-		if errors.As(probeErr.Cause, &hostKeyErr) {
+		if stderrors.As(probeErr.Cause, &hostKeyErr) {
 			return hostKeyErr.Suggestion()
 		}
 		return fmt.Sprintf("Update known_hosts: ssh -o StrictHostKeyChecking=accept-new %s exit", alias)
@@ -517,6 +517,12 @@ func TestCollectChecks_WithGlobalConfig(t *testing.T) {
 	}
 
 	assert.True(t, categories["HOSTS"], "should have HOSTS checks when global config has hosts")
+
+	names := make(map[string]bool)
+	for _, check := range checks {
+		names[check.Name()] = true
+	}
+	assert.True(t, names["ssh_config"], "should check the SSH config for the hosts' aliases")
 }
 
 func TestCollectChecks_EmptyHosts(t *testing.T) {
@@ -1789,3 +1795,28 @@ func TestRenderHostsCategory_MultipleHosts(t *testing.T) {
 	assert.Contains(t, output, "dev-host")
 	assert.Contains(t, output, "prod-host")
 }
+
+func TestParseDoctorFailOn(t *testing.T) {
+	status, err := parseDoctorFailOn("warn")
+	require.NoError(t, err)
+	assert.Equal(t, doctor.StatusWarn, status)
+
+	status, err = parseDoctorFailOn("fail")
+	require.NoError(t, err)
+	assert.Equal(t, doctor.StatusFail, status)
+
+	for _, bad := range []string{"pass", "error", ""} {
+		_, err := parseDoctorFailOn(bad)
+		require.Error(t, err, "expected %q to be rejected", bad)
+		assert.Contains(t, err.Error(), "isn't a valid severity")
+	}
+}
+
+func TestValidateDoctorCategories(t *testing.T) {
+	assert.NoError(t, validateDoctorCategories(nil))
+	assert.NoError(t, validateDoctorCategories([]string{"SSH", "hosts"}))
+
+	err := validateDoctorCategories([]string{"SSH", "NETWORK"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Unknown doctor category 'NETWORK'")
+}
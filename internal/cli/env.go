@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/exec"
+	"github.com/rileyhilliard/rr/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	envHost string
+	envVars []string
+)
+
+// envCmd is the parent command for comparing environments
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Compare the local and remote environments",
+}
+
+// envDiffCmd shows how each host's environment differs from the local one
+var envDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show how each host's environment differs from the local one",
+	Long: `Compare what commands see on each host with your local shell: the versions
+of common tools (python, node, go, rust, java, and others), locale and
+timezone variables, and PATH directories the host's interactive shell has but
+rr's commands don't. Each difference comes with what to change.
+
+Remote values come from the shell, profile files, env, and setup_commands rr
+runs commands with, so a fix in the config shows up on the next diff.
+
+Examples:
+  rr env diff
+  rr env diff --host gpu-box
+  rr env diff --var DATABASE_URL --var CUDA_HOME`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return envDiffCommand(os.Stdout, envHost, envVars)
+	},
+}
+
+func init() {
+	envDiffCmd.Flags().StringVar(&envHost, "host", "", "only this host")
+	envDiffCmd.Flags().StringSliceVar(&envVars, "var", nil, "also compare these environment variables")
+	envCmd.AddCommand(envDiffCmd)
+	rootCmd.AddCommand(envCmd)
+}
+
+// EnvDiffOutput is the JSON representation of one host's differences.
+type EnvDiffOutput struct {
+	Host        string          `json:"host"`
+	Differences []exec.EnvDelta `json:"differences"`
+	Error       string          `json:"error,omitempty"`
+}
+
+// envDiffCommand compares the local environment with each of the project's
+// hosts, or only hostName. Unreachable hosts are reported and skipped.
+func envDiffCommand(w io.Writer, hostName string, extraVars []string) error {
+	resolved, err := loadResolved(Config())
+	if err != nil {
+		return err
+	}
+	hostOrder, hosts, err := config.ResolveHosts(resolved, hostName)
+	if err != nil {
+		return err
+	}
+	if len(hostOrder) == 0 {
+		return errors.New(errors.ErrConfig,
+			"No hosts to compare with",
+			"Add a host with 'rr host add' first.")
+	}
+
+	local, err := exec.SnapshotLocal(resolved.ProjectRoot, extraVars)
+	if err != nil {
+		return errors.WrapWithCode(err, errors.ErrExec,
+			"Couldn't read the local environment",
+			"Check that $SHELL points to a working shell.")
+	}
+
+	out := make([]EnvDiffOutput, 0, len(hostOrder))
+	for _, name := range hostOrder {
+		out = append(out, envDiffHost(name, hosts[name], local, extraVars))
+	}
+
+	if MachineMode() {
+		return WriteJSONSuccess(w, out)
+	}
+	renderEnvDiff(w, out)
+	return nil
+}
+
+// envDiffHost compares one host's environment with local.
+func envDiffHost(name string, h config.Host, local *exec.EnvSnapshot, extraVars []string) EnvDiffOutput {
+	result := EnvDiffOutput{Host: name, Differences: []exec.EnvDelta{}}
+	if len(h.SSH) == 0 {
+		result.Error = "no SSH aliases configured"
+		return result
+	}
+
+	h, err := config.ResolveHostSecrets(name, h)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	client, err := connectForCleanup(name, h)
+	if err != nil {
+		result.Error = fmt.Sprintf("unreachable: %v", err)
+		return result
+	}
+	defer client.Close()
+
+	remote, err := exec.SnapshotRemote(client, h, extraVars)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Differences = append(result.Differences, exec.CompareEnv(local, remote, name)...)
+
+	// A PATH that can't be compared isn't worth failing the diff over
+	if diff, err := exec.GetPATHDifference(client); err == nil {
+		result.Differences = append(result.Differences, exec.PathDeltas(diff, name)...)
+	}
+	return result
+}
+
+// renderEnvDiff prints each host's differences with what to change.
+func renderEnvDiff(w io.Writer, out []EnvDiffOutput) {
+	successStyle := lipgloss.NewStyle().Foreground(ui.ColorSuccess)
+	warningStyle := lipgloss.NewStyle().Foreground(ui.ColorWarning)
+	errorStyle := lipgloss.NewStyle().Foreground(ui.ColorError)
+	mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
+
+	for i, h := range out {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		switch {
+		case h.Error != "":
+			fmt.Fprintf(w, "%s %s %s\n", errorStyle.Render(ui.SymbolFail), h.Host, mutedStyle.Render(h.Error))
+			continue
+		case len(h.Differences) == 0:
+			fmt.Fprintf(w, "%s %s %s\n", successStyle.Render(ui.SymbolSuccess), h.Host, mutedStyle.Render("matches local"))
+			continue
+		}
+
+		fmt.Fprintf(w, "%s %s %s\n", warningStyle.Render(ui.SymbolWarning), h.Host,
+			mutedStyle.Render(fmt.Sprintf("%d differences from local", len(h.Differences))))
+		for _, d := range h.Differences {
+			if d.Kind == exec.EnvDiffPath {
+				fmt.Fprintf(w, "  %-5s %-24s %s\n", d.Kind, d.Name, d.Remote)
+			} else {
+				fmt.Fprintf(w, "  %-5s %-12s local %-16s remote %s\n", d.Kind, d.Name, envSide(d.Local), envSide(d.Remote))
+			}
+			fmt.Fprintf(w, "        %s\n", mutedStyle.Render(d.Suggestion))
+		}
+	}
+}
+
+// envSide shows a missing value in a difference.
+func envSide(v string) string {
+	if v == "" {
+		return "(none)"
+	}
+	return v
+}
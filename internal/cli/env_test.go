@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/exec"
+	"github.com/rileyhilliard/rr/pkg/sshutil"
+	sshtesting "github.com/rileyhilliard/rr/pkg/sshutil/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvDiffHost(t *testing.T) {
+	client := sshtesting.NewMockClient("box")
+	client.SetCommandResponse("command -v python3", sshtesting.CommandResponse{
+		Stdout: []byte("tool\tpython3\tPython 3.10.12\nvar\tLANG\tC\n"),
+	})
+	client.SetCommandResponse(`-l -c 'echo \$PATH'`, sshtesting.CommandResponse{Stdout: []byte("/usr/bin\n")})
+	client.SetCommandResponse(`-i -c 'echo \$PATH'`, sshtesting.CommandResponse{Stdout: []byte("/home/me/.cargo/bin:/usr/bin\n")})
+
+	origConnect := connectForCleanup
+	connectForCleanup = func(string, config.Host) (sshutil.SSHClient, error) { return client, nil }
+	t.Cleanup(func() { connectForCleanup = origConnect })
+
+	local := &exec.EnvSnapshot{
+		Tools: map[string]string{"python3": "Python 3.12.1"},
+		Vars:  map[string]string{"LANG": "en_US.UTF-8"},
+	}
+	result := envDiffHost("box", config.Host{SSH: []string{"box"}}, local, nil)
+	require.Empty(t, result.Error)
+	require.Len(t, result.Differences, 3)
+	assert.Equal(t, "python3", result.Differences[0].Name)
+	assert.Equal(t, "LANG", result.Differences[1].Name)
+	assert.Equal(t, exec.EnvDiffPath, result.Differences[2].Kind)
+
+	var buf bytes.Buffer
+	renderEnvDiff(&buf, []EnvDiffOutput{result, {Host: "down", Error: "unreachable"}})
+	assert.Contains(t, buf.String(), "3 differences from local")
+	assert.Contains(t, buf.String(), "remote 3.10.12")
+	assert.Contains(t, buf.String(), "unreachable")
+}
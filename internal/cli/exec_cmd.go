@@ -6,16 +6,33 @@ import (
 	"github.com/rileyhilliard/rr/internal/errors"
 )
 
+// execFlags are the flags of rr exec, as given on the command line.
+type execFlags struct {
+	Host             string
+	Tag              string
+	ProbeTimeout     string
+	Local            bool
+	SkipRequirements bool
+	Pull             []string // Patterns to pull back after the command
+	PullDest         string
+	Dir              string // Project subdirectory to run the command in (--dir)
+	NoSummary        bool
+	Diagnostics      bool
+	Rolling          bool // Run host by host across a group of hosts (--rolling)
+	BatchSize        int  // Hosts to run on at once with --rolling
+	MaxFailures      int  // Failed hosts allowed before --rolling stops
+}
+
 // execCommand executes a command without syncing files first.
 // This shares the core logic with run but skips the sync phase.
-func execCommand(args []string, hostFlag, tagFlag, probeTimeoutFlag string, localFlag, skipRequirementsFlag bool, pullPatterns []string, pullDest, remoteCWD string) error {
+func execCommand(args []string, flags execFlags) error {
 	if len(args) == 0 {
 		return errors.New(errors.ErrExec,
 			"What should I run?",
 			"Usage: rr exec <command>  (e.g., rr exec \"ls -la\")")
 	}
 
-	probeTimeout, err := ParseProbeTimeout(probeTimeoutFlag)
+	probeTimeout, err := ParseProbeTimeout(flags.ProbeTimeout)
 	if err != nil {
 		return err
 	}
@@ -25,16 +42,18 @@ func execCommand(args []string, hostFlag, tagFlag, probeTimeoutFlag string, loca
 
 	exitCode, err := Run(RunOptions{
 		Command:          cmd,
-		Host:             hostFlag,
-		Tag:              tagFlag,
+		Host:             flags.Host,
+		Tag:              flags.Tag,
 		ProbeTimeout:     probeTimeout,
 		SkipSync:         true, // Key difference from run
-		SkipRequirements: skipRequirementsFlag,
+		SkipRequirements: flags.SkipRequirements,
 		Quiet:            Quiet(),
-		Local:            localFlag,
-		Pull:             pullPatterns,
-		PullDest:         pullDest,
-		RemoteCWD:        remoteCWD,
+		Local:            flags.Local,
+		Pull:             flags.Pull,
+		PullDest:         flags.PullDest,
+		RemoteCWD:        flags.Dir,
+		NoSummary:        flags.NoSummary,
+		Diagnostics:      flags.Diagnostics,
 	})
 
 	if err != nil {
@@ -47,3 +66,28 @@ func execCommand(args []string, hostFlag, tagFlag, probeTimeoutFlag string, loca
 
 	return nil
 }
+
+// execRollingCommand runs a command host by host across a group of hosts
+// (rr exec --rolling).
+func execRollingCommand(args []string, flags execFlags) error {
+	if err := validateRollingFlags(flags.Host, flags.Local, flags.Pull); err != nil {
+		return err
+	}
+	if err := checkRunDir(flags.Dir); err != nil {
+		return err
+	}
+
+	probeTimeout, err := ParseProbeTimeout(flags.ProbeTimeout)
+	if err != nil {
+		return err
+	}
+
+	return execRolling(RollingOptions{
+		Command:      strings.Join(args, " "),
+		Tag:          flags.Tag,
+		ProbeTimeout: probeTimeout,
+		BatchSize:    flags.BatchSize,
+		MaxFailures:  flags.MaxFailures,
+		RemoteCWD:    flags.Dir,
+	})
+}
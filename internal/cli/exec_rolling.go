@@ -0,0 +1,386 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/exec"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/rileyhilliard/rr/internal/lock"
+	"github.com/rileyhilliard/rr/internal/ui"
+)
+
+// RollingOptions configures a rolling exec: one command run host by host
+// across a group of hosts.
+type RollingOptions struct {
+	Command      string
+	Tag          string        // Only hosts with this tag (all project hosts if empty)
+	ProbeTimeout time.Duration // Override SSH probe timeout (0 means use config default)
+	BatchSize    int           // Hosts run at once
+	MaxFailures  int           // Failed hosts allowed before the rollout stops
+	RemoteCWD    string        // Project subdirectory to run the command in on each host (--dir)
+}
+
+// Rollout statuses for each host in the report.
+const (
+	rolloutPassed  = "passed"
+	rolloutFailed  = "failed"
+	rolloutSkipped = "skipped"
+)
+
+// rolloutHost is one host's line in the rollout report.
+type rolloutHost struct {
+	Host     string        `json:"host"`
+	Batch    int           `json:"batch"`
+	Status   string        `json:"status"`
+	ExitCode int           `json:"exit_code"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"-"`
+}
+
+// validateRollingFlags rejects exec flags that don't make sense when the
+// command runs on a whole group of hosts.
+func validateRollingFlags(hostFlag string, localFlag bool, pullPatterns []string) error {
+	switch {
+	case hostFlag != "":
+		return errors.New(errors.ErrConfig,
+			"--rolling runs on a group of hosts, not one",
+			"Drop --host, and use --tag to pick the hosts.")
+	case localFlag:
+		return errors.New(errors.ErrConfig,
+			"--rolling runs on remote hosts, so it can't be combined with --local",
+			"Drop --local, or drop --rolling to run once locally.")
+	case len(pullPatterns) > 0:
+		return errors.New(errors.ErrConfig,
+			"--pull can't be used with --rolling",
+			"Pull from each host separately with 'rr pull --host <name>'.")
+	}
+	return nil
+}
+
+// rolloutBatches splits hosts into batches of size, keeping their order.
+func rolloutBatches(hosts []string, size int) [][]string {
+	if size < 1 {
+		size = 1
+	}
+	var batches [][]string
+	for start := 0; start < len(hosts); start += size {
+		end := min(start+size, len(hosts))
+		batches = append(batches, hosts[start:end])
+	}
+	return batches
+}
+
+// execRolling runs opts.Command on each host in the group, BatchSize hosts
+// at a time. Once more than MaxFailures hosts have failed, the hosts that
+// haven't started are skipped. Prints a rollout report either way.
+func execRolling(opts RollingOptions) error {
+	if opts.BatchSize < 1 {
+		return errors.New(errors.ErrConfig,
+			"--batch-size needs to be at least 1",
+			"Use --batch-size 1 to go one host at a time.")
+	}
+	if opts.MaxFailures < 0 {
+		return errors.New(errors.ErrConfig,
+			"--max-failures can't be negative",
+			"Use --max-failures 0 to stop at the first failure.")
+	}
+
+	resolved, err := loadResolved(Config())
+	if err != nil {
+		return err
+	}
+	if err := config.ValidateResolved(resolved); err != nil {
+		return err
+	}
+
+	hostOrder, hosts, err := config.ResolveHosts(resolved, "")
+	if err != nil {
+		return err
+	}
+	if opts.Tag != "" {
+		hosts, hostOrder = filterHostsByTag(hosts, hostOrder, opts.Tag)
+		if len(hosts) == 0 {
+			return errors.New(errors.ErrConfig,
+				fmt.Sprintf("No hosts found with tag '%s'", opts.Tag),
+				"Check your host tags in ~/.rr/config.yaml.")
+		}
+	}
+	if len(hostOrder) == 0 {
+		return errors.New(errors.ErrConfig,
+			"No hosts to roll out to",
+			"Add a host with 'rr host add' first.")
+	}
+
+	probeTimeout := resolved.Global.Defaults.ProbeTimeout
+	if opts.ProbeTimeout > 0 {
+		probeTimeout = opts.ProbeTimeout
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+	go func() {
+		select {
+		case <-sigChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	out := &rolloutOutput{stdout: os.Stdout, stderr: os.Stderr}
+	run := func(ctx context.Context, name string, h config.Host) rolloutHost {
+		return runRolloutHost(ctx, resolved, name, h, probeTimeout, opts, out)
+	}
+	report := rollout(ctx, hostOrder, hosts, opts, run)
+
+	exitCode := 0
+	failed := countRollout(report, rolloutFailed)
+	if failed > 0 || ctx.Err() != nil {
+		exitCode = 1
+	}
+	stopped := countRollout(report, rolloutSkipped) > 0
+
+	if PrettyMode() {
+		renderRolloutReport(os.Stdout, report, opts.MaxFailures, stopped)
+	} else {
+		WritePhaseEvent(PhaseEvent{
+			Type:     "result",
+			Phase:    "rollout",
+			Status:   map[bool]string{true: "success", false: "failed"}[exitCode == 0],
+			ExitCode: &exitCode,
+			Details: map[string]interface{}{
+				"hosts":        report,
+				"passed":       countRollout(report, rolloutPassed),
+				"failed":       failed,
+				"skipped":      countRollout(report, rolloutSkipped),
+				"max_failures": opts.MaxFailures,
+				"stopped":      stopped,
+			},
+		})
+	}
+
+	if exitCode != 0 {
+		return errors.NewExitError(exitCode)
+	}
+	return nil
+}
+
+// rollout runs each batch of hosts in turn, with run doing the work on one
+// host. It stops starting batches once more than opts.MaxFailures hosts have
+// failed or ctx is canceled; hosts that never started are reported skipped.
+func rollout(ctx context.Context, hostOrder []string, hosts map[string]config.Host, opts RollingOptions, run func(context.Context, string, config.Host) rolloutHost) []rolloutHost {
+	var report []rolloutHost
+	failed := 0
+
+	for i, batch := range rolloutBatches(hostOrder, opts.BatchSize) {
+		if failed > opts.MaxFailures || ctx.Err() != nil {
+			for _, name := range batch {
+				report = append(report, rolloutHost{Host: name, Batch: i + 1, Status: rolloutSkipped})
+			}
+			continue
+		}
+
+		results := make([]rolloutHost, len(batch))
+		var wg sync.WaitGroup
+		for j, name := range batch {
+			wg.Add(1)
+			go func(j int, name string) {
+				defer wg.Done()
+				results[j] = run(ctx, name, hosts[name])
+				results[j].Host = name
+				results[j].Batch = i + 1
+			}(j, name)
+		}
+		wg.Wait()
+
+		for _, r := range results {
+			if r.Status == rolloutFailed {
+				failed++
+			}
+		}
+		report = append(report, results...)
+	}
+	return report
+}
+
+// runRolloutHost connects to one host and runs the command there, holding
+// the host's lock like rr exec does.
+func runRolloutHost(ctx context.Context, resolved *config.ResolvedConfig, name string, h config.Host, probeTimeout time.Duration, opts RollingOptions, out *rolloutOutput) rolloutHost {
+	start := time.Now()
+	fail := func(err error) rolloutHost {
+		return rolloutHost{Status: rolloutFailed, ExitCode: 1, Error: err.Error(), Duration: time.Since(start)}
+	}
+
+	selector := host.NewSelector(map[string]config.Host{name: h})
+	defer selector.Close()
+	if probeTimeout > 0 {
+		selector.SetTimeout(probeTimeout)
+	}
+	conn, err := selector.Select(name)
+	if err != nil {
+		return fail(err)
+	}
+
+	lockCfg := config.DefaultConfig().Lock
+	cmd := opts.Command
+	if resolved.Project != nil {
+		lockCfg = resolved.Project.Lock
+		if len(resolved.Project.Defaults.Setup) > 0 {
+			cmd = strings.Join(resolved.Project.Defaults.Setup, " && ") + " && " + cmd
+		}
+	}
+	if cmd, err = inRemoteCWD(cmd, conn.Host.Dir, opts.RemoteCWD); err != nil {
+		return fail(err)
+	}
+
+	if lockCfg.Enabled {
+		lck, err := lock.Acquire(conn, lockCfg, opts.Command)
+		if err != nil {
+			return fail(err)
+		}
+		defer lck.Release() //nolint:errcheck // Lock release errors are non-fatal
+	}
+
+	stdout, stderr := out.writers(name)
+	exitCode, err := conn.Client.ExecStreamContext(ctx, exec.BuildRemoteCommand(cmd, &conn.Host), stdout, stderr)
+	stdout.Flush()
+	stderr.Flush()
+
+	result := rolloutHost{Status: rolloutPassed, ExitCode: exitCode, Duration: time.Since(start)}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	if exitCode != 0 || err != nil {
+		result.Status = rolloutFailed
+		if result.ExitCode == 0 {
+			result.ExitCode = 1
+		}
+	}
+	return result
+}
+
+// countRollout counts the hosts in report with status.
+func countRollout(report []rolloutHost, status string) int {
+	n := 0
+	for _, r := range report {
+		if r.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// renderRolloutReport prints which hosts passed, failed, and were skipped.
+func renderRolloutReport(w io.Writer, report []rolloutHost, maxFailures int, stopped bool) {
+	successStyle := lipgloss.NewStyle().Foreground(ui.ColorSuccess)
+	errorStyle := lipgloss.NewStyle().Foreground(ui.ColorError)
+	mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, mutedStyle.Render(strings.Repeat("─", 60)))
+	fmt.Fprintln(w, "Rollout report")
+	fmt.Fprintln(w)
+
+	for _, r := range report {
+		switch r.Status {
+		case rolloutPassed:
+			fmt.Fprintf(w, "  %s %-20s %s\n", successStyle.Render(ui.SymbolSuccess), r.Host,
+				mutedStyle.Render(fmt.Sprintf("batch %d, %s", r.Batch, formatRolloutDuration(r.Duration))))
+		case rolloutFailed:
+			detail := fmt.Sprintf("exit %d", r.ExitCode)
+			if r.Error != "" {
+				detail = r.Error
+			}
+			fmt.Fprintf(w, "  %s %-20s %s\n", errorStyle.Render(ui.SymbolFail), r.Host,
+				mutedStyle.Render(fmt.Sprintf("batch %d, %s, %s", r.Batch, formatRolloutDuration(r.Duration), detail)))
+		default:
+			fmt.Fprintf(w, "  %s %-20s %s\n", mutedStyle.Render(ui.SymbolSkipped), r.Host,
+				mutedStyle.Render(fmt.Sprintf("batch %d, skipped", r.Batch)))
+		}
+	}
+
+	passed := countRollout(report, rolloutPassed)
+	failed := countRollout(report, rolloutFailed)
+	skipped := countRollout(report, rolloutSkipped)
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%d passed, %d failed, %d skipped\n", passed, failed, skipped)
+	if stopped {
+		fmt.Fprintln(w, errorStyle.Render(fmt.Sprintf("Stopped: %d failed, more than the %d allowed by --max-failures", failed, maxFailures)))
+	}
+}
+
+// formatRolloutDuration formats how long a host took, to a tenth of a second.
+func formatRolloutDuration(d time.Duration) string {
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
+
+// rolloutOutput prefixes each host's output lines with the host's name, so
+// hosts in the same batch can share the terminal.
+type rolloutOutput struct {
+	mu     sync.Mutex
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// writers returns line-prefixing stdout and stderr writers for a host.
+func (o *rolloutOutput) writers(name string) (*rolloutLineWriter, *rolloutLineWriter) {
+	prefix := "[" + name + "] "
+	return &rolloutLineWriter{out: o, w: o.stdout, prefix: prefix},
+		&rolloutLineWriter{out: o, w: o.stderr, prefix: prefix}
+}
+
+// rolloutLineWriter writes whole lines with a prefix, holding a partial line
+// until it's finished or flushed.
+type rolloutLineWriter struct {
+	out     *rolloutOutput
+	w       io.Writer
+	prefix  string
+	partial []byte
+}
+
+// Write implements io.Writer.
+func (l *rolloutLineWriter) Write(p []byte) (int, error) {
+	data := append(l.partial, p...)
+	l.partial = nil
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		l.writeLine(data[:i+1])
+		data = data[i+1:]
+	}
+	if len(data) > 0 {
+		l.partial = append([]byte(nil), data...)
+	}
+	return len(p), nil
+}
+
+// Flush writes out a trailing partial line.
+func (l *rolloutLineWriter) Flush() {
+	if len(l.partial) > 0 {
+		l.writeLine(append(l.partial, '\n'))
+		l.partial = nil
+	}
+}
+
+func (l *rolloutLineWriter) writeLine(line []byte) {
+	l.out.mu.Lock()
+	defer l.out.mu.Unlock()
+	_, _ = io.WriteString(l.w, l.prefix)
+	_, _ = l.w.Write(line)
+}
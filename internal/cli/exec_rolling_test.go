@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRolloutBatches(t *testing.T) {
+	hosts := []string{"a", "b", "c", "d", "e"}
+	assert.Equal(t, [][]string{{"a"}, {"b"}, {"c"}, {"d"}, {"e"}}, rolloutBatches(hosts, 1))
+	assert.Equal(t, [][]string{{"a", "b"}, {"c", "d"}, {"e"}}, rolloutBatches(hosts, 2))
+	assert.Equal(t, [][]string{hosts}, rolloutBatches(hosts, 10))
+	assert.Nil(t, rolloutBatches(nil, 2))
+}
+
+// fakeRollout returns a run func that fails the hosts in failing and records
+// the order hosts ran in.
+func fakeRollout(failing ...string) (func(context.Context, string, config.Host) rolloutHost, *[]string) {
+	var mu sync.Mutex
+	var ran []string
+	return func(_ context.Context, name string, _ config.Host) rolloutHost {
+		mu.Lock()
+		ran = append(ran, name)
+		mu.Unlock()
+		for _, f := range failing {
+			if f == name {
+				return rolloutHost{Status: rolloutFailed, ExitCode: 3}
+			}
+		}
+		return rolloutHost{Status: rolloutPassed}
+	}, &ran
+}
+
+func TestRollout(t *testing.T) {
+	order := []string{"web1", "web2", "web3", "web4", "web5"}
+	hosts := map[string]config.Host{}
+	for _, h := range order {
+		hosts[h] = config.Host{}
+	}
+
+	statuses := func(report []rolloutHost) map[string]string {
+		out := map[string]string{}
+		for _, r := range report {
+			out[r.Host] = r.Status
+		}
+		return out
+	}
+
+	t.Run("all pass", func(t *testing.T) {
+		run, ran := fakeRollout()
+		report := rollout(context.Background(), order, hosts, RollingOptions{BatchSize: 2}, run)
+		require.Len(t, report, 5)
+		assert.Len(t, *ran, 5)
+		assert.Equal(t, 5, countRollout(report, rolloutPassed))
+		assert.Equal(t, 3, report[4].Batch)
+	})
+
+	t.Run("stops at the first failure by default", func(t *testing.T) {
+		run, ran := fakeRollout("web2")
+		report := rollout(context.Background(), order, hosts, RollingOptions{BatchSize: 1}, run)
+		assert.Equal(t, []string{"web1", "web2"}, *ran)
+		assert.Equal(t, map[string]string{
+			"web1": rolloutPassed, "web2": rolloutFailed,
+			"web3": rolloutSkipped, "web4": rolloutSkipped, "web5": rolloutSkipped,
+		}, statuses(report))
+		assert.Equal(t, 3, report[1].ExitCode)
+	})
+
+	t.Run("keeps going within the failure budget", func(t *testing.T) {
+		run, ran := fakeRollout("web1", "web4")
+		report := rollout(context.Background(), order, hosts, RollingOptions{BatchSize: 1, MaxFailures: 1}, run)
+		assert.Equal(t, []string{"web1", "web2", "web3", "web4"}, *ran)
+		assert.Equal(t, rolloutSkipped, statuses(report)["web5"])
+	})
+
+	t.Run("finishes the batch a failure happens in", func(t *testing.T) {
+		run, ran := fakeRollout("web1")
+		report := rollout(context.Background(), order, hosts, RollingOptions{BatchSize: 2}, run)
+		assert.ElementsMatch(t, []string{"web1", "web2"}, *ran)
+		assert.Equal(t, rolloutPassed, statuses(report)["web2"])
+		assert.Equal(t, 3, countRollout(report, rolloutSkipped))
+	})
+
+	t.Run("canceled skips the rest", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		run, ran := fakeRollout()
+		report := rollout(ctx, order, hosts, RollingOptions{BatchSize: 1}, run)
+		assert.Empty(t, *ran)
+		assert.Equal(t, 5, countRollout(report, rolloutSkipped))
+	})
+}
+
+func TestValidateRollingFlags(t *testing.T) {
+	assert.NoError(t, validateRollingFlags("", false, nil))
+	assert.Error(t, validateRollingFlags("mini", false, nil))
+	assert.Error(t, validateRollingFlags("", true, nil))
+	assert.Error(t, validateRollingFlags("", false, []string{"out/"}))
+}
+
+func TestRolloutLineWriter(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	out := &rolloutOutput{stdout: &stdout, stderr: &stderr}
+	w, errW := out.writers("web1")
+
+	_, _ = w.Write([]byte("restarting"))
+	_, _ = w.Write([]byte(" app\ndone\npart"))
+	_, _ = errW.Write([]byte("warning\n"))
+	assert.Equal(t, "[web1] restarting app\n[web1] done\n", stdout.String())
+
+	w.Flush()
+	assert.Equal(t, "[web1] restarting app\n[web1] done\n[web1] part\n", stdout.String())
+	assert.Equal(t, "[web1] warning\n", stderr.String())
+}
+
+func TestRenderRolloutReport(t *testing.T) {
+	report := []rolloutHost{
+		{Host: "web1", Batch: 1, Status: rolloutPassed, Duration: 1500 * time.Millisecond},
+		{Host: "web2", Batch: 2, Status: rolloutFailed, ExitCode: 3, Duration: time.Second},
+		{Host: "web3", Batch: 3, Status: rolloutSkipped},
+	}
+
+	var buf bytes.Buffer
+	renderRolloutReport(&buf, report, 0, true)
+	out := buf.String()
+	assert.Contains(t, out, "Rollout report")
+	assert.Contains(t, out, "batch 1, 1.5s")
+	assert.Contains(t, out, "exit 3")
+	assert.Contains(t, out, "batch 3, skipped")
+	assert.Contains(t, out, "1 passed, 1 failed, 1 skipped")
+	assert.Contains(t, out, "more than the 0 allowed by --max-failures")
+}
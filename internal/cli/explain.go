@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// explainCmd prints the long-form explanation for a stable error ID
+var explainCmd = &cobra.Command{
+	Use:   "explain [code]",
+	Short: "Explain an error code",
+	Long: `Print the long-form explanation and remediation steps for an error code.
+
+Every rr failure carries a stable code like RR-SSH-003. It's shown under the
+error message in --pretty mode and in the "id" field of JSON errors.
+
+With no code, lists every known code.
+
+Examples:
+  rr explain RR-SSH-003
+  rr explain rr-config-002
+  rr explain`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return explainList(os.Stdout)
+		}
+		return explainCommand(os.Stdout, args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+}
+
+// ExplainOutput is the JSON representation of an error code explanation.
+type ExplainOutput struct {
+	ID          string   `json:"id"`
+	Category    string   `json:"category"`
+	Title       string   `json:"title"`
+	Explanation string   `json:"explanation"`
+	Remediation []string `json:"remediation"`
+}
+
+func newExplainOutput(info errors.CodeInfo) ExplainOutput {
+	return ExplainOutput{
+		ID:          info.ID,
+		Category:    info.Category,
+		Title:       info.Title,
+		Explanation: info.Explanation,
+		Remediation: info.Remediation,
+	}
+}
+
+// explainCommand prints the explanation for a single error code.
+func explainCommand(w io.Writer, code string) error {
+	info, ok := errors.Lookup(code)
+	if !ok {
+		return errors.New(errors.ErrConfig,
+			fmt.Sprintf("Unknown error code '%s'", code),
+			"Run 'rr explain' with no arguments to list every code.")
+	}
+
+	if MachineMode() {
+		return WriteJSONSuccess(w, newExplainOutput(info))
+	}
+
+	renderExplanation(w, info)
+	return nil
+}
+
+// explainList prints a one-line summary for every known error code.
+func explainList(w io.Writer) error {
+	infos := errors.Catalog()
+
+	if MachineMode() {
+		out := make([]ExplainOutput, len(infos))
+		for i, info := range infos {
+			out[i] = newExplainOutput(info)
+		}
+		return WriteJSONSuccess(w, out)
+	}
+
+	idStyle := lipgloss.NewStyle().Bold(true)
+	for _, info := range infos {
+		fmt.Fprintf(w, "  %s  %s\n", idStyle.Render(fmt.Sprintf("%-17s", info.ID)), info.Title)
+	}
+	return nil
+}
+
+// renderExplanation writes the human-readable explanation for an error code.
+func renderExplanation(w io.Writer, info errors.CodeInfo) {
+	titleStyle := lipgloss.NewStyle().Bold(true)
+	mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
+
+	fmt.Fprintf(w, "%s %s\n\n", titleStyle.Render(info.ID), info.Title)
+	fmt.Fprintf(w, "  %s\n", info.Explanation)
+
+	if len(info.Remediation) > 0 {
+		fmt.Fprintf(w, "\n  %s\n", mutedStyle.Render("How to fix:"))
+		for _, step := range info.Remediation {
+			fmt.Fprintf(w, "    - %s\n", step)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplainCommand_Pretty(t *testing.T) {
+	oldPretty := prettyMode
+	defer func() { prettyMode = oldPretty }()
+	prettyMode = true
+
+	var buf bytes.Buffer
+	err := explainCommand(&buf, "rr-ssh-003")
+	require.NoError(t, err)
+
+	out := buf.String()
+	assert.Contains(t, out, errors.IDSSHAuth)
+	assert.Contains(t, out, "SSH authentication failed")
+	assert.Contains(t, out, "How to fix:")
+	assert.Contains(t, out, "ssh-copy-id")
+}
+
+func TestExplainCommand_JSON(t *testing.T) {
+	oldPretty := prettyMode
+	defer func() { prettyMode = oldPretty }()
+	prettyMode = false
+
+	var buf bytes.Buffer
+	err := explainCommand(&buf, errors.IDConfigNotFound)
+	require.NoError(t, err)
+
+	var env struct {
+		Success bool          `json:"success"`
+		Data    ExplainOutput `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &env))
+	assert.True(t, env.Success)
+	assert.Equal(t, errors.IDConfigNotFound, env.Data.ID)
+	assert.Equal(t, errors.ErrConfig, env.Data.Category)
+	assert.NotEmpty(t, env.Data.Remediation)
+}
+
+func TestExplainCommand_UnknownCode(t *testing.T) {
+	var buf bytes.Buffer
+	err := explainCommand(&buf, "RR-NOPE-999")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Unknown error code")
+	assert.Empty(t, buf.String())
+}
+
+func TestExplainList(t *testing.T) {
+	oldPretty := prettyMode
+	defer func() { prettyMode = oldPretty }()
+	prettyMode = true
+
+	var buf bytes.Buffer
+	require.NoError(t, explainList(&buf))
+
+	out := buf.String()
+	for _, info := range errors.Catalog() {
+		assert.Contains(t, out, info.ID)
+	}
+}
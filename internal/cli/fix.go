@@ -8,7 +8,6 @@ import (
 	"github.com/rileyhilliard/rr/internal/config"
 	"github.com/rileyhilliard/rr/internal/exec"
 	"github.com/rileyhilliard/rr/internal/ui"
-	"golang.org/x/term"
 )
 
 // FixOption represents a choice in the fix menu.
@@ -34,8 +33,8 @@ func HandleMissingTool(
 	sshClient exec.SSHStreamer,
 	configPath string,
 ) (*FixResult, error) {
-	// Can't show interactive prompts without a terminal
-	if !term.IsTerminal(int(os.Stdin.Fd())) {
+	// Can't show interactive prompts without a terminal, or with --non-interactive
+	if !canPrompt() {
 		return &FixResult{Fixed: false, ShouldRetry: false}, nil
 	}
 
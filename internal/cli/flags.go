@@ -25,6 +25,19 @@ func AddCommonFlags(cmd *cobra.Command, flags *CommonFlags) {
 	cmd.Flags().BoolVar(&flags.Local, "local", false, "force local execution (skip remote hosts)")
 }
 
+// forceFlagUsage is the help text for --force on every command that syncs.
+const forceFlagUsage = "sync even if the host's dir is a git checkout with uncommitted changes"
+
+// addNotifyWhenFreeFlag registers --notify-when-free on a command that
+// waits for the host lock. Every such command shares the one setting.
+func addNotifyWhenFreeFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&notifyWhenFree, "notify-when-free", false,
+		"when another run holds the lock, don't wait: get a desktop notification when it frees instead")
+}
+
+// rebootstrapFlagUsage is the help text for --rebootstrap.
+const rebootstrapFlagUsage = "run the project's bootstrap script again, even if it already ran on the host"
+
 // ValidateLocalAndTag checks that --local and --tag are not used together.
 // These flags are mutually exclusive: --local forces local execution while
 // --tag selects remote hosts by tag.
@@ -13,20 +13,30 @@ import (
 	"github.com/rileyhilliard/rr/internal/config"
 	"github.com/rileyhilliard/rr/internal/errors"
 	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/rileyhilliard/rr/internal/hostinfo"
 	"github.com/rileyhilliard/rr/internal/ui"
-	"github.com/rileyhilliard/rr/internal/util"
 	"github.com/rileyhilliard/rr/pkg/sshutil"
 )
 
 // Host command flags
 var (
-	hostListJSON bool
+	hostListJSON    bool
+	hostListRefresh bool
+	hostListUsage   bool
+	hostListDays    int
+	hostTestJSON    bool
 	// Non-interactive host add flags
 	hostAddName string
 	hostAddSSH  string
 	hostAddDir  string
 	hostAddTags []string
 	hostAddEnv  []string // KEY=VALUE pairs
+	hostAddKey  string   // identity_file for the host
+	// Host remove flags
+	hostRemoveCleanup    bool // delete remote files without asking
+	hostRemoveKeepRemote bool // never touch the remote host
+	hostRemoveDryRun     bool // list what would be removed, change nothing
+	hostRemoveYes        bool // skip the confirmation
 )
 
 // HostListOutput represents the JSON output for host list command.
@@ -42,7 +52,20 @@ type HostConfigInfo struct {
 	Dir        string            `json:"dir"`
 	Tags       []string          `json:"tags,omitempty"`
 	Env        map[string]string `json:"env,omitempty"`
-	IsDefault  bool              `json:"is_default"`
+	// ProfileFiles are sourced before every command on the host.
+	ProfileFiles []string `json:"profile_files,omitempty"`
+	// IdentityFile is the key rr authenticates with, from identity_file in rr
+	// config or IdentityFile in ~/.ssh/config (see IdentitySource).
+	IdentityFile   string `json:"identity_file,omitempty"`
+	IdentitySource string `json:"identity_source,omitempty"` // "config" or "ssh_config"
+	IsDefault      bool   `json:"is_default"`
+	// DiscoveredBy is the inventory provider that found the host, for hosts
+	// that aren't in the config file.
+	DiscoveredBy string `json:"discovered_by,omitempty"`
+	// Info is the host's detected platform and hardware, if it's been reached.
+	Info *hostinfo.Info `json:"info,omitempty"`
+	// Usage is who has had the host's time lately, with --usage.
+	Usage *HostUsage `json:"usage,omitempty"`
 }
 
 // HostAddOptions holds options for the host add command.
@@ -64,6 +87,10 @@ func hostAdd(opts HostAddOptions) error {
 	if hostAddName != "" && hostAddSSH != "" {
 		return hostAddNonInteractive(cfg, opts.SkipProbe)
 	}
+	if NonInteractive() {
+		return needsInput("Adding a host needs its name and SSH connection",
+			"Pass both: rr host add --name <name> --ssh <user@host>")
+	}
 
 	// Get list of existing SSH hosts to exclude from picker
 	var existingSSHHosts []string
@@ -175,7 +202,11 @@ func hostAddNonInteractive(cfg *config.GlobalConfig, skipProbe bool) error {
 
 	// Test connection (unless --skip-probe)
 	if !skipProbe {
-		_, err := host.Probe(sshAliases[0], 10*time.Second)
+		client, _, err := host.ProbeAndConnectWithOptions(sshAliases[0], 10*time.Second,
+			sshutil.DialOptions{IdentityFile: hostAddKey})
+		if client != nil {
+			client.Close()
+		}
 		if err != nil {
 			return errors.WrapWithCode(err, errors.ErrSSH,
 				fmt.Sprintf("Can't reach %s", sshAliases[0]),
@@ -197,10 +228,11 @@ func hostAddNonInteractive(cfg *config.GlobalConfig, skipProbe bool) error {
 
 	// Build host config
 	hostConfig := config.Host{
-		SSH:  sshAliases,
-		Dir:  remoteDir,
-		Tags: hostAddTags,
-		Env:  envMap,
+		SSH:          sshAliases,
+		Dir:          remoteDir,
+		Tags:         hostAddTags,
+		Env:          envMap,
+		IdentityFile: hostAddKey,
 	}
 
 	// Add to config
@@ -214,11 +246,12 @@ func hostAddNonInteractive(cfg *config.GlobalConfig, skipProbe bool) error {
 	// Output result
 	if MachineMode() {
 		return WriteJSONSuccess(os.Stdout, map[string]interface{}{
-			"name":        hostAddName,
-			"ssh_aliases": sshAliases,
-			"dir":         remoteDir,
-			"tags":        hostAddTags,
-			"env":         envMap,
+			"name":          hostAddName,
+			"ssh_aliases":   sshAliases,
+			"dir":           remoteDir,
+			"tags":          hostAddTags,
+			"env":           envMap,
+			"identity_file": hostAddKey,
 		})
 	}
 
@@ -240,6 +273,10 @@ func hostRemove(name string) error {
 				"No hosts configured",
 				"Nothing to remove.")
 		}
+		if !canPrompt() {
+			return needsInput("Which host should I remove?",
+				"Pass it by name: rr host remove <name> --yes")
+		}
 
 		// Build sorted list of host names
 		var hostNames []string
@@ -288,28 +325,40 @@ func hostRemove(name string) error {
 			fmt.Sprintf("Available hosts: %s", strings.Join(available, ", ")))
 	}
 
-	// Confirm removal
-	var confirm bool
-	form := huh.NewForm(
-		huh.NewGroup(
-			huh.NewConfirm().
-				Title(fmt.Sprintf("Remove host '%s'?", name)).
-				Description("This cannot be undone").
-				Value(&confirm),
-		),
-	)
-	if err := form.Run(); err != nil {
-		return errors.WrapWithCode(err, errors.ErrConfig,
-			"Couldn't get your input",
-			"Try again or edit ~/.rr/config.yaml manually.")
+	if hostRemoveDryRun {
+		return hostRemoveDryRunReport(name, hostConfig)
 	}
-	if !confirm {
-		fmt.Println("Cancelled.")
-		return nil
+
+	// Confirm removal
+	if !hostRemoveYes {
+		if NonInteractive() {
+			return needsInput(fmt.Sprintf("Removing host '%s' needs confirmation", name),
+				fmt.Sprintf("Pass --yes to remove it without asking: rr host remove %s --yes", name))
+		}
+		var confirm bool
+		form := huh.NewForm(
+			huh.NewGroup(
+				huh.NewConfirm().
+					Title(fmt.Sprintf("Remove host '%s'?", name)).
+					Description("'rr config undo' puts it back").
+					Value(&confirm),
+			),
+		)
+		if err := form.Run(); err != nil {
+			return errors.WrapWithCode(err, errors.ErrConfig,
+				"Couldn't get your input",
+				"Try again, pass --yes, or edit ~/.rr/config.yaml manually.")
+		}
+		if !confirm {
+			fmt.Println("Cancelled.")
+			return nil
+		}
 	}
 
-	// Try to clean up remote artifacts before removing from config
-	cleanupRemoteArtifacts(name, hostConfig)
+	// Offer to clean up remote files before removing from config
+	if !hostRemoveKeepRemote {
+		offerRemoteCleanup(name, hostConfig)
+	}
 
 	// Remove the host
 	delete(cfg.Hosts, name)
@@ -325,6 +374,12 @@ func hostRemove(name string) error {
 
 // hostList lists all configured hosts from global config.
 func hostList() error {
+	if hostListUsage && hostListDays < 1 {
+		return errors.New(errors.ErrConfig,
+			fmt.Sprintf("--days must be at least 1, got %d", hostListDays),
+			"Pass how many days of usage to count, e.g. --days 30.")
+	}
+
 	cfg, globalPath, err := loadGlobalConfig()
 	if err != nil {
 		if hostListJSON || MachineMode() {
@@ -332,9 +387,12 @@ func hostList() error {
 		}
 		return err
 	}
+	mergeInventory(cfg, hostListRefresh)
 
-	// Try to load project config to get host order for determining default
+	// Try to load project config to get host order for determining default,
+	// and where its locks (and so the usage logs) live
 	var hostOrder []string
+	lockCfg := config.DefaultConfig().Lock
 	if projectPath, findErr := config.Find(""); findErr == nil && projectPath != "" {
 		if projectCfg, loadErr := config.Load(projectPath); loadErr == nil {
 			// Use project's host order: Hosts (plural) takes precedence over Host (singular)
@@ -343,22 +401,35 @@ func hostList() error {
 			} else if projectCfg.Host != "" {
 				hostOrder = []string{projectCfg.Host}
 			}
+			lockCfg = projectCfg.Lock
 		}
 	}
 
 	// JSON/machine mode output
 	if hostListJSON || MachineMode() {
-		return outputHostListJSON(cfg, hostOrder)
+		infos := gatherHostInfo(cfg.Hosts, hostListRefresh, false)
+		var usage map[string]HostUsage
+		if hostListUsage {
+			usage = gatherHostUsage(cfg.Hosts, lockCfg, hostListDays, false)
+		}
+		return outputHostListJSON(cfg, hostOrder, infos, usage)
 	}
 
 	// Human-readable output
-	return outputHostListText(cfg, globalPath)
+	infos := gatherHostInfo(cfg.Hosts, hostListRefresh, true)
+	var usage map[string]HostUsage
+	if hostListUsage {
+		usage = gatherHostUsage(cfg.Hosts, lockCfg, hostListDays, true)
+	}
+	return outputHostListText(cfg, globalPath, infos, usage)
 }
 
 // outputHostListJSON outputs hosts in JSON format with envelope.
 // hostOrder specifies the priority order from project config (if available).
 // The default host is the first valid host from hostOrder, falling back to alphabetical.
-func outputHostListJSON(cfg *config.GlobalConfig, hostOrder []string) error {
+// infos holds detected host info by host name; hosts without an entry are listed without it.
+// usage holds each host's usage breakdown, when --usage asked for it.
+func outputHostListJSON(cfg *config.GlobalConfig, hostOrder []string, infos map[string]hostinfo.Info, usage map[string]HostUsage) error {
 	output := HostListOutput{
 		Hosts: make([]HostConfigInfo, 0, len(cfg.Hosts)),
 	}
@@ -387,13 +458,24 @@ func outputHostListJSON(cfg *config.GlobalConfig, hostOrder []string) error {
 
 	for _, name := range names {
 		h := cfg.Hosts[name]
+		identityFile, identitySource := hostIdentity(h)
 		info := HostConfigInfo{
-			Name:       name,
-			SSHAliases: h.SSH,
-			Dir:        h.Dir,
-			Tags:       h.Tags,
-			Env:        h.Env,
-			IsDefault:  name == output.DefaultHost,
+			Name:           name,
+			SSHAliases:     h.SSH,
+			Dir:            h.Dir,
+			Tags:           h.Tags,
+			Env:            h.Env,
+			ProfileFiles:   h.ProfileFiles,
+			IdentityFile:   identityFile,
+			IdentitySource: identitySource,
+			IsDefault:      name == output.DefaultHost,
+			DiscoveredBy:   cfg.DiscoveredBy(name),
+		}
+		if detected, ok := infos[name]; ok {
+			info.Info = &detected
+		}
+		if u, ok := usage[name]; ok {
+			info.Usage = &u
 		}
 		output.Hosts = append(output.Hosts, info)
 	}
@@ -410,7 +492,7 @@ func outputHostListJSON(cfg *config.GlobalConfig, hostOrder []string) error {
 }
 
 // outputHostListText outputs hosts in human-readable format.
-func outputHostListText(cfg *config.GlobalConfig, globalPath string) error {
+func outputHostListText(cfg *config.GlobalConfig, globalPath string, infos map[string]hostinfo.Info, usage map[string]HostUsage) error {
 	if len(cfg.Hosts) == 0 {
 		fmt.Println("No hosts configured.")
 		fmt.Println("\nAdd one with: rr host add")
@@ -426,7 +508,7 @@ func outputHostListText(cfg *config.GlobalConfig, globalPath string) error {
 
 	// Styles
 	nameStyle := lipgloss.NewStyle().Bold(true)
-	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	dimStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
 
 	// Show config location
 	fmt.Printf("%s\n\n", dimStyle.Render("Config: "+globalPath))
@@ -435,7 +517,11 @@ func outputHostListText(cfg *config.GlobalConfig, globalPath string) error {
 		h := cfg.Hosts[name]
 
 		// Name
-		fmt.Println(nameStyle.Render(name))
+		if provider := cfg.DiscoveredBy(name); provider != "" {
+			fmt.Println(nameStyle.Render(name) + dimStyle.Render(" (from "+provider+" inventory)"))
+		} else {
+			fmt.Println(nameStyle.Render(name))
+		}
 
 		// SSH connections
 		for i, ssh := range h.SSH {
@@ -450,12 +536,59 @@ func outputHostListText(cfg *config.GlobalConfig, globalPath string) error {
 		if h.Dir != "" {
 			fmt.Printf("  %s\n", dimStyle.Render("dir: "+h.Dir))
 		}
+
+		// Identity file
+		if identityFile, source := hostIdentity(h); identityFile != "" {
+			label := "key: " + identityFile
+			if source == identitySourceSSHConfig {
+				label += " (from ~/.ssh/config)"
+			}
+			fmt.Printf("  %s\n", dimStyle.Render(label))
+		}
+
+		// Detected platform and hardware
+		if info, ok := infos[name]; ok {
+			label := info.Summary()
+			if info.Stale(time.Now()) {
+				label += " (as of " + info.CheckedAt.Format("Jan 2") + ", host unreachable)"
+			}
+			fmt.Printf("  %s\n", dimStyle.Render(label))
+		} else if len(h.SSH) > 0 {
+			fmt.Printf("  %s\n", dimStyle.Render("hardware unknown (host unreachable)"))
+		}
+
+		// Who has had the host's time
+		if u, ok := usage[name]; ok {
+			printHostUsage(u, dimStyle)
+		}
 		fmt.Println()
 	}
 
 	return nil
 }
 
+// Identity sources reported by hostIdentity.
+const (
+	identitySourceConfig    = "config"
+	identitySourceSSHConfig = "ssh_config"
+)
+
+// hostIdentity returns the key rr will try first for a host and where that
+// choice came from. The host's identity_file wins; otherwise the IdentityFile
+// ~/.ssh/config sets for the first SSH alias. Returns empty strings when
+// neither is set and the agent and default keys are used.
+func hostIdentity(h config.Host) (path, source string) {
+	if h.IdentityFile != "" {
+		return h.IdentityFile, identitySourceConfig
+	}
+	if len(h.SSH) > 0 {
+		if path := sshutil.SSHConfigIdentityFile(h.SSH[0]); path != "" {
+			return path, identitySourceSSHConfig
+		}
+	}
+	return "", ""
+}
+
 // loadGlobalConfig loads the global config from ~/.rr/config.yaml.
 // Returns the config, the path to the config file, and any error.
 func loadGlobalConfig() (*config.GlobalConfig, string, error) {
@@ -510,54 +643,3 @@ func testConnectionForAdd(sshHost string) error {
 	}
 	return nil
 }
-
-// cleanupRemoteArtifacts attempts to remove synced files from the remote host.
-// If the host is unreachable, it logs a warning but doesn't fail.
-func cleanupRemoteArtifacts(hostName string, hostConfig config.Host) {
-	if len(hostConfig.SSH) == 0 || hostConfig.Dir == "" {
-		return
-	}
-
-	// Try each SSH alias until one works
-	var client *sshutil.Client
-	var connErr error
-	for _, sshAlias := range hostConfig.SSH {
-		client, _, connErr = host.ProbeAndConnect(sshAlias, 10*time.Second)
-		if connErr == nil {
-			break
-		}
-	}
-
-	if connErr != nil {
-		remoteDir := config.ExpandRemote(hostConfig.Dir)
-		fmt.Printf("  %s Host '%s' is unreachable, skipping remote cleanup\n", ui.SymbolWarning, hostName)
-		fmt.Printf("    Synced files remain at: %s\n", remoteDir)
-		fmt.Printf("    To remove manually: ssh %s 'rm -rf %s'\n", hostConfig.SSH[0], remoteDir)
-		return
-	}
-	defer client.Close()
-
-	// Expand the remote directory path
-	remoteDir := config.ExpandRemote(hostConfig.Dir)
-
-	// Build rm command with proper quoting for tilde expansion
-	rmCmd := fmt.Sprintf("rm -rf %s", util.ShellQuotePreserveTilde(remoteDir))
-
-	spinner := ui.NewSpinner("Cleaning up remote files")
-	spinner.Start()
-
-	_, stderr, exitCode, err := client.Exec(rmCmd)
-	if err != nil || exitCode != 0 {
-		spinner.Fail()
-		errMsg := strings.TrimSpace(string(stderr))
-		if err != nil {
-			errMsg = err.Error()
-		}
-		fmt.Printf("  %s Remote cleanup failed: %s\n", ui.SymbolWarning, errMsg)
-		fmt.Printf("    Synced files remain at: %s\n", remoteDir)
-		fmt.Printf("    To remove manually: ssh %s 'rm -rf %s'\n", hostConfig.SSH[0], remoteDir)
-		return
-	}
-
-	spinner.Success()
-}
@@ -0,0 +1,286 @@
+package cli
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/rileyhilliard/rr/internal/ui"
+	"github.com/rileyhilliard/rr/internal/util"
+	"github.com/rileyhilliard/rr/pkg/sshutil"
+)
+
+// remoteArtifact is an rr-managed path found on a remote host.
+type remoteArtifact struct {
+	Path  string
+	Bytes int64 // -1 if the size couldn't be measured
+}
+
+// connectForCleanup connects to a host for remote cleanup, trying each SSH
+// alias in order. Swappable for tests.
+var connectForCleanup = func(name string, h config.Host) (sshutil.SSHClient, error) {
+	h, err := config.ResolveHostSecrets(name, h)
+	if err != nil {
+		return nil, err
+	}
+	var lastErr error
+	for _, alias := range h.SSH {
+		client, _, err := host.Connect(alias, 10*time.Second, h)
+		if err == nil {
+			return client, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// remoteCleanupPatterns returns shell patterns, ready to paste into a remote
+// command, for everything rr manages on a host: every project directory its
+// dir template can produce, and the locks of every scope in each lock
+// directory (rr.lock and the rr.lock.<...> project and command locks).
+//
+// ${PROJECT} becomes a wildcard so directories synced from other projects
+// are found too, unless that would match everything in the home or root
+// directory, in which case only the current project's directory is used.
+func remoteCleanupPatterns(h config.Host, lockDirs []string) []string {
+	var patterns []string
+
+	if h.Dir != "" {
+		dir := config.ExpandRemote(strings.ReplaceAll(h.Dir, "${PROJECT}", "*"))
+		if i := strings.Index(dir, "*"); i >= 0 && isCleanupRoot(path.Dir(dir[:i]+"x")) {
+			dir = config.ExpandRemote(h.Dir)
+		}
+		if !isCleanupRoot(dir) {
+			patterns = append(patterns, quoteGlob(strings.TrimSuffix(dir, "/")))
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, dir := range lockDirs {
+		if dir == "" || seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		patterns = append(patterns, quoteGlob(path.Join(dir, "rr.lock")), quoteGlob(path.Join(dir, "rr.lock.*")))
+	}
+
+	return patterns
+}
+
+// isCleanupRoot reports whether dir is a directory that must never be
+// deleted or globbed wholesale.
+func isCleanupRoot(dir string) bool {
+	switch strings.TrimSuffix(dir, "/") {
+	case "", ".", "~", "/":
+		return true
+	}
+	return false
+}
+
+// quoteGlob shell-quotes a path, leaving * wildcards and a leading ~
+// unquoted so the remote shell expands them.
+func quoteGlob(pattern string) string {
+	parts := strings.Split(pattern, "*")
+	for i, p := range parts {
+		switch {
+		case p == "":
+		case i == 0:
+			parts[i] = util.ShellQuotePreserveTilde(p)
+		default:
+			parts[i] = util.ShellQuote(p)
+		}
+	}
+	return strings.Join(parts, "*")
+}
+
+// buildCleanupListCmd builds a remote script that prints "<kilobytes>\t<path>"
+// for each existing path matching the patterns.
+func buildCleanupListCmd(patterns []string) string {
+	return fmt.Sprintf(`for p in %s; do if [ -e "$p" ]; then printf '%%s\t%%s\n' "$(du -sk "$p" 2>/dev/null | cut -f1)" "$p"; fi; done`,
+		strings.Join(patterns, " "))
+}
+
+// parseCleanupList parses buildCleanupListCmd output.
+func parseCleanupList(output []byte) []remoteArtifact {
+	var artifacts []remoteArtifact
+	for _, line := range strings.Split(string(output), "\n") {
+		size, p, ok := strings.Cut(line, "\t")
+		if !ok || p == "" {
+			continue
+		}
+		bytes := int64(-1)
+		if kb, err := strconv.ParseInt(strings.TrimSpace(size), 10, 64); err == nil {
+			bytes = kb * 1024
+		}
+		artifacts = append(artifacts, remoteArtifact{Path: p, Bytes: bytes})
+	}
+	return artifacts
+}
+
+// buildCleanupRemoveCmd builds the command that deletes the given paths.
+func buildCleanupRemoveCmd(artifacts []remoteArtifact) string {
+	quoted := make([]string, len(artifacts))
+	for i, a := range artifacts {
+		quoted[i] = util.ShellQuote(a.Path)
+	}
+	return "rm -rf -- " + strings.Join(quoted, " ")
+}
+
+// listRemoteArtifacts finds the rr-managed paths on a host.
+func listRemoteArtifacts(client sshutil.SSHClient, h config.Host) ([]remoteArtifact, error) {
+	patterns := remoteCleanupPatterns(h, cleanupLockDirs())
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	stdout, stderr, exitCode, err := client.Exec(buildCleanupListCmd(patterns))
+	if err != nil {
+		return nil, err
+	}
+	if exitCode != 0 {
+		return nil, fmt.Errorf("%s", strings.TrimSpace(string(stderr)))
+	}
+	return parseCleanupList(stdout), nil
+}
+
+// cleanupLockDirs returns the remote lock directories to check: the default
+// one, plus the current project's if it sets lock.dir.
+func cleanupLockDirs() []string {
+	dirs := []string{config.DefaultConfig().Lock.Dir}
+	if cfgPath, err := config.Find(Config()); err == nil && cfgPath != "" {
+		if cfg, err := config.Load(cfgPath); err == nil && cfg.Lock.Dir != "" {
+			dirs = append(dirs, cfg.Lock.Dir)
+		}
+	}
+	return dirs
+}
+
+// printRemoteArtifacts lists artifacts with their sizes.
+func printRemoteArtifacts(artifacts []remoteArtifact) {
+	mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
+	for _, a := range artifacts {
+		size := ""
+		if a.Bytes >= 0 {
+			size = " " + mutedStyle.Render(fmt.Sprintf("(%s)", config.FormatSize(a.Bytes)))
+		}
+		fmt.Printf("    %s%s\n", a.Path, size)
+	}
+}
+
+// printManualCleanup tells the user how to clean up a host by hand.
+func printManualCleanup(h config.Host) {
+	remoteDir := config.ExpandRemote(h.Dir)
+	fmt.Printf("    Synced files remain at: %s\n", remoteDir)
+	fmt.Printf("    To remove manually: ssh %s 'rm -rf %s'\n", h.SSH[0], remoteDir)
+}
+
+// hostRemoveDryRunReport shows what removing a host would do without
+// changing anything.
+func hostRemoveDryRunReport(name string, h config.Host) error {
+	fmt.Println("Dry run: nothing will be changed.")
+	fmt.Printf("Would remove host '%s' from the global config\n", name)
+
+	if len(h.SSH) == 0 || hostRemoveKeepRemote {
+		fmt.Println("Would leave the remote host untouched")
+		return nil
+	}
+
+	client, err := connectForCleanup(name, h)
+	if err != nil {
+		fmt.Printf("  %s Host '%s' is unreachable, can't list remote files\n", ui.SymbolWarning, name)
+		return nil
+	}
+	defer client.Close()
+
+	artifacts, err := listRemoteArtifacts(client, h)
+	if err != nil {
+		return errors.WrapWithCode(err, errors.ErrSSH,
+			fmt.Sprintf("Couldn't list rr files on '%s'", name),
+			"Check that the remote shell works: rr doctor")
+	}
+	if len(artifacts) == 0 {
+		fmt.Printf("Nothing rr-managed found on '%s'\n", name)
+		return nil
+	}
+
+	if hostRemoveCleanup {
+		fmt.Printf("Would delete on '%s':\n", name)
+	} else {
+		fmt.Printf("Would offer to delete on '%s':\n", name)
+	}
+	printRemoteArtifacts(artifacts)
+	return nil
+}
+
+// offerRemoteCleanup connects to a host being removed, lists the project
+// directories and lock files rr left there, and deletes them if the user
+// agrees (or passed --cleanup). An unreachable host only gets a warning.
+func offerRemoteCleanup(name string, h config.Host) {
+	if len(h.SSH) == 0 || h.Dir == "" {
+		return
+	}
+
+	client, err := connectForCleanup(name, h)
+	if err != nil {
+		fmt.Printf("  %s Host '%s' is unreachable, skipping remote cleanup\n", ui.SymbolWarning, name)
+		printManualCleanup(h)
+		return
+	}
+	defer client.Close()
+
+	artifacts, err := listRemoteArtifacts(client, h)
+	if err != nil {
+		fmt.Printf("  %s Couldn't list rr files on '%s': %v\n", ui.SymbolWarning, name, err)
+		printManualCleanup(h)
+		return
+	}
+	if len(artifacts) == 0 {
+		return
+	}
+
+	fmt.Printf("rr files on '%s':\n", name)
+	printRemoteArtifacts(artifacts)
+
+	if !hostRemoveCleanup {
+		if NonInteractive() {
+			fmt.Println("  Left remote files in place (pass --cleanup to delete them without asking).")
+			return
+		}
+		var confirm bool
+		form := huh.NewForm(
+			huh.NewGroup(
+				huh.NewConfirm().
+					Title(fmt.Sprintf("Delete these from '%s'?", name)).
+					Description("Project directories and lock files rr created. Anything else on the host is left alone.").
+					Value(&confirm),
+			),
+		)
+		if err := form.Run(); err != nil || !confirm {
+			fmt.Println("  Left remote files in place.")
+			return
+		}
+	}
+
+	spinner := ui.NewSpinner("Cleaning up remote files")
+	spinner.Start()
+
+	_, stderr, exitCode, err := client.Exec(buildCleanupRemoveCmd(artifacts))
+	if err != nil || exitCode != 0 {
+		spinner.Fail()
+		errMsg := strings.TrimSpace(string(stderr))
+		if err != nil {
+			errMsg = err.Error()
+		}
+		fmt.Printf("  %s Remote cleanup failed: %s\n", ui.SymbolWarning, errMsg)
+		printManualCleanup(h)
+		return
+	}
+
+	spinner.Success()
+}
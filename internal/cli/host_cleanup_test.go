@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/pkg/sshutil"
+	sshtesting "github.com/rileyhilliard/rr/pkg/sshutil/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingClient is a mock SSH client that records the commands it runs.
+type recordingClient struct {
+	*sshtesting.MockClient
+	cmds []string
+}
+
+func (c *recordingClient) Exec(cmd string) ([]byte, []byte, int, error) {
+	c.cmds = append(c.cmds, cmd)
+	return c.MockClient.Exec(cmd)
+}
+
+func TestRemoteCleanupPatterns(t *testing.T) {
+	t.Setenv("USER", "alice")
+
+	tests := []struct {
+		name string
+		dir  string
+		want []string
+	}{
+		{
+			name: "project template becomes a wildcard",
+			dir:  "~/rr/${PROJECT}",
+			want: []string{"~/'rr/'*", "'/tmp/rr-locks/rr.lock'", "'/tmp/rr-locks/rr.lock.'*"},
+		},
+		{
+			name: "fixed dir",
+			dir:  "/srv/work dir",
+			want: []string{"'/srv/work dir'", "'/tmp/rr-locks/rr.lock'", "'/tmp/rr-locks/rr.lock.'*"},
+		},
+		{
+			name: "user expanded",
+			dir:  "/scratch/${USER}/${PROJECT}",
+			want: []string{"'/scratch/alice/'*", "'/tmp/rr-locks/rr.lock'", "'/tmp/rr-locks/rr.lock.'*"},
+		},
+		{
+			name: "home dir is never removed",
+			dir:  "~",
+			want: []string{"'/tmp/rr-locks/rr.lock'", "'/tmp/rr-locks/rr.lock.'*"},
+		},
+		{
+			name: "no dir",
+			dir:  "",
+			want: []string{"'/tmp/rr-locks/rr.lock'", "'/tmp/rr-locks/rr.lock.'*"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := remoteCleanupPatterns(config.Host{Dir: tt.dir}, []string{"/tmp/rr-locks", "/tmp/rr-locks", ""})
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRemoteCleanupPatterns_NoWildcardOverHome(t *testing.T) {
+	// ~/${PROJECT} as a wildcard would match everything in the home
+	// directory, so only the current project's directory is used
+	patterns := remoteCleanupPatterns(config.Host{Dir: "~/${PROJECT}"}, nil)
+	require.Len(t, patterns, 1)
+	assert.NotContains(t, patterns[0], "*")
+	assert.True(t, strings.HasPrefix(patterns[0], "~/'"))
+}
+
+func TestParseCleanupList(t *testing.T) {
+	output := "1024\t/home/u/rr/app\n\t/tmp/rr-locks/rr.lock\nnot a match\n"
+	assert.Equal(t, []remoteArtifact{
+		{Path: "/home/u/rr/app", Bytes: 1024 * 1024},
+		{Path: "/tmp/rr-locks/rr.lock", Bytes: -1},
+	}, parseCleanupList([]byte(output)))
+}
+
+func TestBuildCleanupRemoveCmd(t *testing.T) {
+	cmd := buildCleanupRemoveCmd([]remoteArtifact{{Path: "/home/u/rr/my app"}, {Path: "/tmp/rr-locks/rr.lock"}})
+	assert.Equal(t, "rm -rf -- '/home/u/rr/my app' '/tmp/rr-locks/rr.lock'", cmd)
+}
+
+func TestOfferRemoteCleanup_WithCleanupFlag(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	client := &recordingClient{MockClient: sshtesting.NewMockClient("box")}
+	client.SetCommandResponse("^for p in", sshtesting.CommandResponse{
+		Stdout: []byte("8\t/home/u/rr/app\n"),
+	})
+	client.SetCommandResponse("^rm -rf -- ", sshtesting.CommandResponse{})
+
+	origConnect := connectForCleanup
+	connectForCleanup = func(string, config.Host) (sshutil.SSHClient, error) { return client, nil }
+	t.Cleanup(func() { connectForCleanup = origConnect })
+
+	hostRemoveCleanup = true
+	t.Cleanup(func() { hostRemoveCleanup = false })
+
+	offerRemoteCleanup("box", config.Host{SSH: []string{"box"}, Dir: "~/rr/${PROJECT}"})
+
+	require.Len(t, client.cmds, 2)
+	assert.Equal(t, "rm -rf -- '/home/u/rr/app'", client.cmds[1])
+}
+
+func TestHostRemoveDryRunReport_ChangesNothing(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	client := &recordingClient{MockClient: sshtesting.NewMockClient("box")}
+	client.SetCommandResponse("^for p in", sshtesting.CommandResponse{
+		Stdout: []byte("8\t/home/u/rr/app\n"),
+	})
+
+	origConnect := connectForCleanup
+	connectForCleanup = func(string, config.Host) (sshutil.SSHClient, error) { return client, nil }
+	t.Cleanup(func() { connectForCleanup = origConnect })
+
+	err := hostRemoveDryRunReport("box", config.Host{SSH: []string{"box"}, Dir: "~/rr/${PROJECT}"})
+	require.NoError(t, err)
+
+	require.Len(t, client.cmds, 1)
+	assert.True(t, strings.HasPrefix(client.cmds[0], "for p in"))
+}
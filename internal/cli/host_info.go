@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/rileyhilliard/rr/internal/hostinfo"
+	"github.com/rileyhilliard/rr/internal/ui"
+)
+
+// hostInfoTimeout bounds each connection attempt when detecting host info,
+// so an unreachable host doesn't hold up 'rr host list' for long.
+const hostInfoTimeout = 5 * time.Second
+
+// detectHostInfo connects to a host and detects its platform and hardware.
+// Tries each SSH alias in order. Swappable for tests.
+var detectHostInfo = func(h config.Host) (hostinfo.Info, error) {
+	var lastErr error
+	for _, alias := range h.SSH {
+		client, _, err := host.Connect(alias, hostInfoTimeout, h)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		info, err := hostinfo.Detect(client)
+		client.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return info, nil
+	}
+	return hostinfo.Info{}, lastErr
+}
+
+// hostsNeedingInfo returns the hosts whose cached info is missing or stale
+// and that haven't just failed detection, or every host when refresh is set.
+func hostsNeedingInfo(hosts map[string]config.Host, cache map[string]hostinfo.Info, refresh bool, now time.Time) []string {
+	var names []string
+	for name, h := range hosts {
+		if len(h.SSH) == 0 {
+			continue
+		}
+		if info, ok := cache[name]; ok && !refresh && !info.NeedsDetection(now) {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// gatherHostInfo returns detected info for the configured hosts, detecting
+// (in parallel) only what isn't already cached. Hosts that can't be reached
+// keep whatever was cached before, or are left out, and aren't tried again
+// for hostinfo.FailureTTL unless refresh is set. Cache errors are not
+// fatal: host info is a convenience, not something to fail 'host list' over.
+// With showProgress, a spinner runs while hosts are being contacted.
+func gatherHostInfo(hosts map[string]config.Host, refresh, showProgress bool) map[string]hostinfo.Info {
+	cache, err := hostinfo.Load()
+	if err != nil {
+		cache = make(map[string]hostinfo.Info)
+	}
+
+	names := hostsNeedingInfo(hosts, cache, refresh, time.Now())
+	if len(names) > 0 {
+		var spinner *ui.Spinner
+		if showProgress && ui.IsTerminal(os.Stdout) {
+			spinner = ui.NewSpinner("Detecting host hardware")
+			spinner.Start()
+		}
+
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for _, name := range names {
+			wg.Add(1)
+			go func(hostName string, hostCfg config.Host) {
+				defer wg.Done()
+				hostCfg, err := config.ResolveHostSecrets(hostName, hostCfg)
+				var info hostinfo.Info
+				if err == nil {
+					info, err = detectHostInfo(hostCfg)
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					cache[hostName] = cache[hostName].WithFailure(err, time.Now())
+					return
+				}
+				cache[hostName] = info
+			}(name, hosts[name])
+		}
+		wg.Wait()
+
+		if spinner != nil {
+			spinner.Success()
+			fmt.Println()
+		}
+	}
+
+	// Forget hosts that have been removed from the config
+	changed := len(names) > 0
+	for name := range cache {
+		if _, ok := hosts[name]; !ok {
+			delete(cache, name)
+			changed = true
+		}
+	}
+	if changed {
+		_ = hostinfo.Save(cache)
+	}
+
+	infos := make(map[string]hostinfo.Info, len(cache))
+	for name, info := range cache {
+		if info.Detected() {
+			infos[name] = info
+		}
+	}
+	return infos
+}
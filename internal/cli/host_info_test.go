@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/hostinfo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostsNeedingInfo(t *testing.T) {
+	now := time.Now()
+	hosts := map[string]config.Host{
+		"fresh":   {SSH: []string{"fresh.local"}},
+		"stale":   {SSH: []string{"stale.local"}},
+		"missing": {SSH: []string{"missing.local"}},
+		"failed":  {SSH: []string{"failed.local"}},
+		"retry":   {SSH: []string{"retry.local"}},
+		"no-ssh":  {},
+	}
+	cache := map[string]hostinfo.Info{
+		"fresh":  {OS: "linux", CheckedAt: now.Add(-time.Hour)},
+		"stale":  {OS: "linux", CheckedAt: now.Add(-hostinfo.MaxAge - time.Hour)},
+		"failed": {FailedAt: now.Add(-time.Minute), Err: "unreachable"},
+		"retry":  {FailedAt: now.Add(-hostinfo.FailureTTL - time.Minute), Err: "unreachable"},
+	}
+
+	assert.ElementsMatch(t, []string{"stale", "missing", "retry"}, hostsNeedingInfo(hosts, cache, false, now))
+	assert.ElementsMatch(t, []string{"fresh", "stale", "missing", "failed", "retry"}, hostsNeedingInfo(hosts, cache, true, now))
+}
+
+func TestGatherHostInfo(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	old := hostinfo.Info{OS: "linux", Arch: "amd64", CheckedAt: time.Now().Add(-hostinfo.MaxAge - time.Hour)}
+	require.NoError(t, hostinfo.Save(map[string]hostinfo.Info{
+		"down":    old,
+		"removed": {OS: "linux", CheckedAt: time.Now()},
+	}))
+
+	origDetect := detectHostInfo
+	defer func() { detectHostInfo = origDetect }()
+	var mu sync.Mutex
+	var detected []string
+	detectHostInfo = func(h config.Host) (hostinfo.Info, error) {
+		mu.Lock()
+		detected = append(detected, h.SSH[0])
+		mu.Unlock()
+		if h.SSH[0] == "down.local" {
+			return hostinfo.Info{}, errors.New("unreachable")
+		}
+		return hostinfo.Info{OS: "darwin", Arch: "arm64", CPUCores: 10, CheckedAt: time.Now()}, nil
+	}
+
+	hosts := map[string]config.Host{
+		"mini": {SSH: []string{"mini.local"}},
+		"down": {SSH: []string{"down.local"}},
+	}
+	infos := gatherHostInfo(hosts, false, false)
+
+	assert.ElementsMatch(t, []string{"mini.local", "down.local"}, detected)
+	assert.Equal(t, "darwin/arm64", infos["mini"].Platform())
+	assert.Equal(t, old.Platform(), infos["down"].Platform(), "unreachable host keeps its stale info")
+	assert.NotContains(t, infos, "removed")
+
+	cached, err := hostinfo.Load()
+	require.NoError(t, err)
+	assert.Equal(t, 10, cached["mini"].CPUCores)
+	assert.NotContains(t, cached, "removed")
+	assert.Equal(t, "unreachable", cached["down"].Err, "the failure is cached")
+
+	// Fresh info, and a host that just failed, aren't detected again
+	detected = nil
+	gatherHostInfo(hosts, false, false)
+	assert.Empty(t, detected)
+
+	// --refresh retries the failed host right away
+	gatherHostInfo(hosts, true, false)
+	assert.ElementsMatch(t, []string{"mini.local", "down.local"}, detected)
+}
+
+func TestGatherHostInfo_LeavesOutHostsNeverDetected(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	origDetect := detectHostInfo
+	defer func() { detectHostInfo = origDetect }()
+	detectHostInfo = func(h config.Host) (hostinfo.Info, error) {
+		return hostinfo.Info{}, errors.New("unreachable")
+	}
+
+	infos := gatherHostInfo(map[string]config.Host{"down": {SSH: []string{"down.local"}}}, false, false)
+	assert.Empty(t, infos)
+
+	cached, err := hostinfo.Load()
+	require.NoError(t, err)
+	assert.False(t, cached["down"].FailedAt.IsZero())
+}
@@ -0,0 +1,389 @@
+package cli
+
+import (
+	stderrors "errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	rrexec "github.com/rileyhilliard/rr/internal/exec"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/rileyhilliard/rr/internal/lock"
+	rrsync "github.com/rileyhilliard/rr/internal/sync"
+	"github.com/rileyhilliard/rr/internal/ui"
+	"github.com/rileyhilliard/rr/internal/util"
+	"github.com/rileyhilliard/rr/pkg/sshutil"
+)
+
+// hostTestScratchDir is the directory 'rr host test' syncs into, under the
+// host's dir. It's removed once the sync has been checked.
+const hostTestScratchDir = ".rr-host-test"
+
+// hostTestMarker is the file synced, and the text echoed, by 'rr host test'.
+const hostTestMarker = "rr-host-test"
+
+// Step statuses in 'rr host test' output.
+const (
+	hostTestPass = "pass"
+	hostTestFail = "fail"
+	hostTestSkip = "skip"
+)
+
+// HostTestStepResult is the outcome of one step of 'rr host test'.
+type HostTestStepResult struct {
+	Name     string     `json:"name"`
+	Status   string     `json:"status"` // pass, fail or skip
+	Detail   string     `json:"detail,omitempty"`
+	Error    *JSONError `json:"error,omitempty"`
+	Duration float64    `json:"duration_s"`
+}
+
+// HostTestOutput is the JSON output of 'rr host test'.
+type HostTestOutput struct {
+	Host   string               `json:"host"`
+	Alias  string               `json:"alias,omitempty"` // The SSH entry that connected
+	Passed bool                 `json:"passed"`
+	Steps  []HostTestStepResult `json:"steps"`
+}
+
+// hostTestState is what the steps of 'rr host test' share: the host under
+// test and, once connected, the connection to it.
+type hostTestState struct {
+	name    string
+	host    config.Host
+	conn    *host.Connection
+	authErr error // Set by the probe step when the host answered but refused the key
+}
+
+// hostTestStep is one check in 'rr host test'. It returns a short detail to
+// show next to the step, or an error if the step failed.
+type hostTestStep struct {
+	Name string
+	Run  func(s *hostTestState) (string, error)
+}
+
+// connectForHostTest dials one SSH alias of a host. Swappable for tests.
+var connectForHostTest = func(alias string, h config.Host) (sshutil.SSHClient, time.Duration, error) {
+	client, latency, err := host.Connect(alias, 10*time.Second, h)
+	if err != nil {
+		return nil, 0, err
+	}
+	return client, latency, nil
+}
+
+// hostTestSteps are the checks 'rr host test' runs, in the order a real run
+// needs them. Each step relies on the ones before it, so the first failure
+// skips the rest.
+var hostTestSteps = []hostTestStep{
+	{Name: "Reachable", Run: hostTestProbe},
+	{Name: "Key auth", Run: hostTestAuth},
+	{Name: "Shell", Run: hostTestShell},
+	{Name: "Directory", Run: hostTestDir},
+	{Name: "Sync", Run: hostTestSync},
+	{Name: "Exec", Run: hostTestExec},
+	{Name: "Lock", Run: hostTestLock},
+}
+
+// hostTest runs the full rr pipeline against one host in isolation and
+// prints a checklist of what worked.
+func hostTest(name string) error {
+	machine := hostTestJSON || MachineMode()
+
+	cfg, _, err := loadGlobalConfig()
+	if err != nil {
+		if machine {
+			return WriteJSONFromError(os.Stdout, err)
+		}
+		return err
+	}
+	h, ok := cfg.Hosts[name]
+	if !ok {
+		err := errors.New(errors.ErrConfig,
+			fmt.Sprintf("Host '%s' not found", name),
+			"Run 'rr host list' to see configured hosts.")
+		if machine {
+			return WriteJSONFromError(os.Stdout, err)
+		}
+		return err
+	}
+
+	state := &hostTestState{name: name, host: h}
+	defer func() {
+		if state.conn != nil {
+			_ = state.conn.Close()
+		}
+	}()
+
+	var output HostTestOutput
+	if machine {
+		output = runHostTest(state, hostTestSteps, nil)
+		if err := WriteJSONSuccess(os.Stdout, output); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("Testing %s\n\n", name)
+		output = runHostTest(state, hostTestSteps, printHostTestStep)
+		fmt.Println()
+		printHostTestSummary(output)
+	}
+
+	if !output.Passed {
+		return errors.NewExitError(1)
+	}
+	return nil
+}
+
+// runHostTest runs steps in order, skipping the rest after the first
+// failure. report, if set, is called as each step finishes.
+func runHostTest(state *hostTestState, steps []hostTestStep, report func(HostTestStepResult)) HostTestOutput {
+	output := HostTestOutput{
+		Host:   state.name,
+		Passed: true,
+		Steps:  make([]HostTestStepResult, 0, len(steps)),
+	}
+
+	for _, step := range steps {
+		result := HostTestStepResult{Name: step.Name, Status: hostTestSkip}
+		if output.Passed {
+			start := time.Now()
+			detail, err := step.Run(state)
+			result.Duration = time.Since(start).Seconds()
+			result.Detail = detail
+			result.Status = hostTestPass
+			if err != nil {
+				result.Status = hostTestFail
+				result.Error = ErrorToJSON(err)
+				output.Passed = false
+			}
+		}
+		output.Steps = append(output.Steps, result)
+		if report != nil {
+			report(result)
+		}
+	}
+
+	if state.conn != nil {
+		output.Alias = state.conn.Alias
+	}
+	return output
+}
+
+// printHostTestStep prints one checklist line, with the error and its fix
+// under a failed step.
+func printHostTestStep(r HostTestStepResult) {
+	mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
+
+	symbol := ui.SymbolSuccess
+	switch r.Status {
+	case hostTestFail:
+		symbol = ui.SymbolFail
+	case hostTestSkip:
+		symbol = ui.SymbolSkipped
+	}
+
+	line := fmt.Sprintf("  %s %-10s", symbol, r.Name)
+	if r.Detail != "" {
+		line += " " + mutedStyle.Render(r.Detail)
+	}
+	fmt.Println(line)
+
+	if r.Error != nil {
+		fmt.Printf("      %s\n", r.Error.Message)
+		if r.Error.Suggestion != "" {
+			fmt.Println(mutedStyle.Render("      " + r.Error.Suggestion))
+		}
+	}
+}
+
+// printHostTestSummary prints the verdict under the checklist.
+func printHostTestSummary(output HostTestOutput) {
+	passed := 0
+	for _, r := range output.Steps {
+		if r.Status == hostTestPass {
+			passed++
+		}
+	}
+	if output.Passed {
+		fmt.Printf("%s All %d checks passed. %s is ready for rr.\n", ui.SymbolSuccess, passed, output.Host)
+		return
+	}
+	fmt.Printf("%s %d/%d checks passed\n", ui.SymbolFail, passed, len(output.Steps))
+}
+
+// hostTestProbe connects through the host's SSH entries in order. A host
+// that answers but refuses the key counts as reachable: that's reported by
+// the key auth step.
+func hostTestProbe(s *hostTestState) (string, error) {
+	if len(s.host.SSH) == 0 {
+		return "", errors.New(errors.ErrConfig,
+			fmt.Sprintf("Host '%s' has no SSH entries", s.name),
+			"Add one with 'rr host add', or edit ~/.rr/config.yaml.")
+	}
+	resolved, err := config.ResolveHostSecrets(s.name, s.host)
+	if err != nil {
+		return "", err
+	}
+	s.host = resolved
+
+	var lastErr error
+	var answered string
+	for _, alias := range s.host.SSH {
+		client, latency, err := connectForHostTest(alias, s.host)
+		if err == nil {
+			s.conn = &host.Connection{Name: s.name, Alias: alias, Client: client, Host: s.host, Latency: latency}
+			s.authErr = nil
+			if s.host.Transport == config.TransportSystemSSH {
+				return fmt.Sprintf("%s (%s, system ssh)", alias, latency.Round(time.Millisecond)), nil
+			}
+			return fmt.Sprintf("%s (%s)", alias, latency.Round(time.Millisecond)), nil
+		}
+		var probeErr *host.ProbeError
+		if s.authErr == nil && stderrors.As(err, &probeErr) &&
+			(probeErr.Reason == host.ProbeFailAuth || probeErr.Reason == host.ProbeFailHostKey) {
+			s.authErr = err
+			answered = alias
+		}
+		lastErr = err
+	}
+	if s.authErr != nil {
+		return answered, nil
+	}
+	return "", lastErr
+}
+
+// hostTestAuth reports whether the host accepted our key.
+func hostTestAuth(s *hostTestState) (string, error) {
+	if s.authErr != nil {
+		return "", s.authErr
+	}
+	if s.host.IdentityFile != "" {
+		return "accepted " + s.host.IdentityFile, nil
+	}
+	return "key accepted", nil
+}
+
+// hostTestShell detects the login shell commands run under.
+func hostTestShell(s *hostTestState) (string, error) {
+	stdout, stderr, exitCode, err := s.conn.Client.Exec("echo $SHELL")
+	if err != nil {
+		return "", errors.WrapWithCode(err, errors.ErrSSH,
+			"Couldn't run a command over SSH",
+			"Check that the SSH server allows running commands (not just port forwarding).")
+	}
+	if exitCode != 0 {
+		return "", errors.New(errors.ErrExec,
+			"Couldn't detect the login shell",
+			fmt.Sprintf("Remote error: %s", strings.TrimSpace(string(stderr))))
+	}
+
+	shell := strings.TrimSpace(string(stdout))
+	if shell == "" {
+		shell = "$SHELL unset, using /bin/bash"
+	}
+	if s.host.Shell != "" {
+		shell += ", configured: " + s.host.Shell
+	}
+	return shell, nil
+}
+
+// hostTestDir creates the host's dir and checks it's writable.
+func hostTestDir(s *hostTestState) (string, error) {
+	dir := config.ExpandRemote(s.host.Dir)
+	if dir == "" {
+		return "", errors.New(errors.ErrConfig,
+			fmt.Sprintf("Host '%s' has no dir", s.name),
+			"Set dir for the host in ~/.rr/config.yaml (e.g., ~/rr/${PROJECT}).")
+	}
+
+	quoted := util.ShellQuotePreserveTilde(dir)
+	_, stderr, exitCode, err := s.conn.Client.Exec(fmt.Sprintf("mkdir -p %s && test -w %s", quoted, quoted))
+	if err != nil {
+		return "", errors.WrapWithCode(err, errors.ErrSSH,
+			"Couldn't create "+dir,
+			"Check your SSH connection.")
+	}
+	if exitCode != 0 {
+		return "", errors.New(errors.ErrSync,
+			fmt.Sprintf("%s can't be created or isn't writable", dir),
+			fmt.Sprintf("Check permissions on the host, or pick another dir. Remote error: %s", strings.TrimSpace(string(stderr))))
+	}
+	return dir, nil
+}
+
+// hostTestSync syncs one small file into a scratch directory under the
+// host's dir, checks it arrived, and removes the scratch directory.
+func hostTestSync(s *hostTestState) (string, error) {
+	localDir, err := os.MkdirTemp("", "rr-host-test-")
+	if err != nil {
+		return "", errors.WrapWithCode(err, errors.ErrSync,
+			"Couldn't create a temporary directory to sync",
+			"Check that your temp directory is writable.")
+	}
+	defer os.RemoveAll(localDir)
+	if err := os.WriteFile(filepath.Join(localDir, hostTestMarker), []byte(hostTestMarker+"\n"), 0644); err != nil {
+		return "", errors.WrapWithCode(err, errors.ErrSync,
+			"Couldn't write the file to sync",
+			"Check that your temp directory is writable.")
+	}
+
+	scratch := *s.conn
+	scratch.Host.Dir = path.Join(config.ExpandRemote(s.host.Dir), hostTestScratchDir)
+	quoted := util.ShellQuotePreserveTilde(scratch.Host.Dir)
+	defer func() {
+		_, _, _, _ = s.conn.Client.Exec("rm -rf " + quoted)
+		rrsync.ForgetSyncRecord(localDir, s.name)
+	}()
+
+	start := time.Now()
+	if err := rrsync.Sync(&scratch, localDir, config.SyncConfig{}, nil); err != nil {
+		return "", err
+	}
+	stdout, _, exitCode, err := s.conn.Client.Exec("cat " + quoted + "/" + hostTestMarker)
+	if err != nil || exitCode != 0 || strings.TrimSpace(string(stdout)) != hostTestMarker {
+		return "", errors.New(errors.ErrSync,
+			"rsync finished but the file isn't on the host",
+			"Check that rsync on the host writes to the same filesystem rr's SSH sessions see.")
+	}
+	return fmt.Sprintf("rsync ok (%s)", time.Since(start).Round(time.Millisecond)), nil
+}
+
+// hostTestExec runs echo the way 'rr exec' does: in the host's dir, through
+// its shell, profile files, env and setup commands.
+func hostTestExec(s *hostTestState) (string, error) {
+	cmd := rrexec.BuildRemoteCommand("echo "+hostTestMarker, &s.host)
+	stdout, stderr, exitCode, err := s.conn.Client.Exec(cmd)
+	if err != nil {
+		return "", errors.WrapWithCode(err, errors.ErrExec,
+			"Couldn't run 'echo' on the host",
+			"Check your SSH connection.")
+	}
+	if exitCode != 0 || !strings.Contains(string(stdout), hostTestMarker) {
+		return "", errors.New(errors.ErrExec,
+			fmt.Sprintf("'echo %s' exited %d", hostTestMarker, exitCode),
+			fmt.Sprintf("Check the host's shell, setup_commands and profile_files. Remote error: %s", strings.TrimSpace(string(stderr))))
+	}
+	return "echo ok", nil
+}
+
+// hostTestLock takes and releases the host lock. A lock held by another run
+// still proves locking works, so it passes with the holder shown.
+func hostTestLock(s *hostTestState) (string, error) {
+	cfg := config.DefaultConfig().Lock
+	lck, err := lock.TryAcquire(s.conn, cfg, "rr host test")
+	if stderrors.Is(err, lock.ErrLocked) {
+		return "held by " + lock.Holder(s.conn, lock.LockDir(cfg)), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := lck.Release(); err != nil {
+		return "", err
+	}
+	return "acquired and released", nil
+}
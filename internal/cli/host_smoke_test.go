@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/rileyhilliard/rr/pkg/sshutil"
+	sshtesting "github.com/rileyhilliard/rr/pkg/sshutil/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunHostTest_SkipsAfterFirstFailure(t *testing.T) {
+	var ran []string
+	step := func(name string, err error) hostTestStep {
+		return hostTestStep{Name: name, Run: func(*hostTestState) (string, error) {
+			ran = append(ran, name)
+			return name + " detail", err
+		}}
+	}
+	steps := []hostTestStep{
+		step("one", nil),
+		step("two", fmt.Errorf("broken")),
+		step("three", nil),
+	}
+
+	var reported []string
+	output := runHostTest(&hostTestState{name: "box"}, steps, func(r HostTestStepResult) {
+		reported = append(reported, r.Name+":"+r.Status)
+	})
+
+	assert.False(t, output.Passed)
+	assert.Equal(t, []string{"one", "two"}, ran)
+	assert.Equal(t, []string{"one:pass", "two:fail", "three:skip"}, reported)
+	require.Len(t, output.Steps, 3)
+	require.NotNil(t, output.Steps[1].Error)
+	assert.Equal(t, "broken", output.Steps[1].Error.Message)
+	assert.Empty(t, output.Steps[2].Detail)
+}
+
+func TestRunHostTest_AllPass(t *testing.T) {
+	state := &hostTestState{name: "box"}
+	steps := []hostTestStep{{Name: "connect", Run: func(s *hostTestState) (string, error) {
+		s.conn = &host.Connection{Name: "box", Alias: "box-lan"}
+		return "", nil
+	}}}
+
+	output := runHostTest(state, steps, nil)
+
+	assert.True(t, output.Passed)
+	assert.Equal(t, "box-lan", output.Alias)
+}
+
+func TestHostTestProbe(t *testing.T) {
+	authErr := &host.ProbeError{SSHAlias: "box-vpn", Reason: host.ProbeFailAuth}
+	timeoutErr := &host.ProbeError{SSHAlias: "box-lan", Reason: host.ProbeFailTimeout}
+
+	tests := []struct {
+		name       string
+		results    map[string]error
+		wantErr    bool
+		wantAuth   bool
+		wantAlias  string
+		wantDetail string
+	}{
+		{
+			name:      "falls back to the next entry",
+			results:   map[string]error{"box-lan": timeoutErr, "box-vpn": nil},
+			wantAlias: "box-vpn",
+		},
+		{
+			name:       "refused key is reachable but fails auth",
+			results:    map[string]error{"box-lan": timeoutErr, "box-vpn": authErr},
+			wantAuth:   true,
+			wantDetail: "box-vpn",
+		},
+		{
+			name:    "nothing answers",
+			results: map[string]error{"box-lan": timeoutErr, "box-vpn": timeoutErr},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orig := connectForHostTest
+			defer func() { connectForHostTest = orig }()
+			connectForHostTest = func(alias string, _ config.Host) (sshutil.SSHClient, time.Duration, error) {
+				if err := tt.results[alias]; err != nil {
+					return nil, 0, err
+				}
+				return sshtesting.NewMockClient(alias), 5 * time.Millisecond, nil
+			}
+
+			s := &hostTestState{name: "box", host: config.Host{SSH: []string{"box-lan", "box-vpn"}}}
+			detail, err := hostTestProbe(s)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, s.conn)
+				return
+			}
+			require.NoError(t, err)
+
+			_, authErr := hostTestAuth(s)
+			assert.Equal(t, tt.wantAuth, authErr != nil)
+			if tt.wantAlias != "" {
+				require.NotNil(t, s.conn)
+				assert.Equal(t, tt.wantAlias, s.conn.Alias)
+			}
+			if tt.wantDetail != "" {
+				assert.Equal(t, tt.wantDetail, detail)
+			}
+		})
+	}
+}
+
+func TestHostTestProbe_NoSSHEntries(t *testing.T) {
+	_, err := hostTestProbe(&hostTestState{name: "box"})
+	assert.Error(t, err)
+}
+
+func TestHostTestShellAndExec(t *testing.T) {
+	client := sshtesting.NewMockClient("box")
+	client.SetCommandResponse("echo $SHELL", sshtesting.CommandResponse{Stdout: []byte("/bin/zsh\n")})
+	client.SetCommandResponse(`echo rr-host-test"$`, sshtesting.CommandResponse{Stdout: []byte("motd\nrr-host-test\n")})
+
+	s := &hostTestState{
+		name: "box",
+		host: config.Host{Dir: "/srv/rr", Shell: "bash -c"},
+		conn: &host.Connection{Name: "box", Client: client},
+	}
+
+	detail, err := hostTestShell(s)
+	require.NoError(t, err)
+	assert.Equal(t, "/bin/zsh, configured: bash -c", detail)
+
+	_, err = hostTestExec(s)
+	assert.NoError(t, err)
+
+	client.SetCommandResponse(`echo rr-host-test"$`, sshtesting.CommandResponse{ExitCode: 127, Stderr: []byte("bash: not found")})
+	_, err = hostTestExec(s)
+	assert.Error(t, err)
+}
+
+func TestHostTestDir(t *testing.T) {
+	client := sshtesting.NewMockClient("box")
+	s := &hostTestState{name: "box", host: config.Host{Dir: "/srv/rr"}, conn: &host.Connection{Name: "box", Client: client}}
+
+	detail, err := hostTestDir(s)
+	require.NoError(t, err)
+	assert.Equal(t, "/srv/rr", detail)
+
+	client.SetCommandResponse("^mkdir -p", sshtesting.CommandResponse{ExitCode: 1, Stderr: []byte("Permission denied")})
+	_, err = hostTestDir(s)
+	assert.Error(t, err)
+
+	s.host.Dir = ""
+	_, err = hostTestDir(s)
+	assert.Error(t, err)
+}
+
+func TestHostTestLock(t *testing.T) {
+	client := sshtesting.NewMockClient("box")
+	s := &hostTestState{name: "box", conn: &host.Connection{Name: "box", Client: client}}
+
+	detail, err := hostTestLock(s)
+	require.NoError(t, err)
+	assert.Equal(t, "acquired and released", detail)
+	assert.False(t, client.GetFS().Exists("/tmp/rr-locks/rr.lock"), "lock is released")
+}
@@ -451,7 +451,7 @@ func testHost() config.Host {
 // Note: Shell quoting functions (ShellQuote, ShellQuotePreserveTilde) were moved
 // to internal/util/shell.go. Tests are now in internal/util/shell_test.go.
 
-func TestCleanupRemoteArtifacts_NoSSHAliases(t *testing.T) {
+func TestOfferRemoteCleanup_NoSSHAliases(t *testing.T) {
 	// Should return early without error if no SSH aliases configured
 	hostConfig := config.Host{
 		SSH: []string{},
@@ -459,10 +459,10 @@ func TestCleanupRemoteArtifacts_NoSSHAliases(t *testing.T) {
 	}
 
 	// This should not panic or error - it just returns early
-	cleanupRemoteArtifacts("test", hostConfig)
+	offerRemoteCleanup("test", hostConfig)
 }
 
-func TestCleanupRemoteArtifacts_NoDir(t *testing.T) {
+func TestOfferRemoteCleanup_NoDir(t *testing.T) {
 	// Should return early without error if no Dir configured
 	hostConfig := config.Host{
 		SSH: []string{"example.com"},
@@ -470,5 +470,64 @@ func TestCleanupRemoteArtifacts_NoDir(t *testing.T) {
 	}
 
 	// This should not panic or error - it just returns early
-	cleanupRemoteArtifacts("test", hostConfig)
+	offerRemoteCleanup("test", hostConfig)
+}
+
+func TestHostIdentity(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	sshDir := filepath.Join(tmpDir, ".ssh")
+	require.NoError(t, os.MkdirAll(sshDir, 0700))
+	sshConfig := `
+Host work-box
+    HostName work.example.com
+    IdentityFile ~/.ssh/work_ed25519
+`
+	require.NoError(t, os.WriteFile(filepath.Join(sshDir, "config"), []byte(sshConfig), 0600))
+
+	t.Run("identity_file wins", func(t *testing.T) {
+		path, source := hostIdentity(config.Host{SSH: []string{"work-box"}, IdentityFile: "~/.ssh/rr_key"})
+		assert.Equal(t, "~/.ssh/rr_key", path)
+		assert.Equal(t, identitySourceConfig, source)
+	})
+
+	t.Run("falls back to ssh config", func(t *testing.T) {
+		path, source := hostIdentity(config.Host{SSH: []string{"work-box"}})
+		assert.Equal(t, filepath.Join(sshDir, "work_ed25519"), path)
+		assert.Equal(t, identitySourceSSHConfig, source)
+	})
+
+	t.Run("nothing configured", func(t *testing.T) {
+		path, source := hostIdentity(config.Host{SSH: []string{"other.example.com"}})
+		assert.Empty(t, path)
+		assert.Empty(t, source)
+	})
+}
+
+func TestConfiguredIdentityFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	rrDir := filepath.Join(tmpDir, ".rr")
+	require.NoError(t, os.MkdirAll(rrDir, 0755))
+	configContent := `
+hosts:
+  gpu:
+    ssh:
+      - gpu-lan
+      - gpu-vpn
+    dir: ~/rr/project
+    identity_file: ~/.ssh/gpu_ed25519
+  dev:
+    ssh:
+      - dev.example.com
+    dir: ~/rr/project
+`
+	require.NoError(t, os.WriteFile(filepath.Join(rrDir, "config.yaml"), []byte(configContent), 0644))
+
+	assert.Equal(t, "~/.ssh/gpu_ed25519", configuredIdentityFile("gpu"))
+	assert.Equal(t, "~/.ssh/gpu_ed25519", configuredIdentityFile("gpu-vpn"))
+	assert.Empty(t, configuredIdentityFile("dev.example.com"))
+	assert.Empty(t, configuredIdentityFile("unknown"))
 }
@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/history"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/rileyhilliard/rr/internal/lock"
+	"github.com/rileyhilliard/rr/internal/ui"
+)
+
+// Usage sources reported in HostUsage.
+const (
+	usageSourceHost    = "host"
+	usageSourceHistory = "local_history"
+)
+
+// HostUsage is who has had a host's time, for 'rr host list --usage'.
+type HostUsage struct {
+	Days int `json:"days"`
+	// Source is "host" for the usage log on the host, which covers everyone,
+	// or "local_history" for this machine's own runs when the host can't be
+	// reached.
+	Source string           `json:"source"`
+	Users  []lock.UserUsage `json:"users"`
+	Hint   string           `json:"hint,omitempty"`
+}
+
+// readHostUsage reads a host's usage log, trying each SSH alias in order.
+// Swappable for tests.
+var readHostUsage = func(name string, h config.Host, cfg config.LockConfig) ([]lock.UsageRecord, error) {
+	h, err := config.ResolveHostSecrets(name, h)
+	if err != nil {
+		return nil, err
+	}
+	var lastErr error
+	for _, alias := range h.SSH {
+		client, _, err := host.Connect(alias, hostInfoTimeout, h)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		records, err := lock.ReadUsage(&host.Connection{Name: name, Alias: alias, Client: client, Host: h}, cfg)
+		client.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return records, nil
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("host '%s' has no SSH aliases", name)
+	}
+	return nil, lastErr
+}
+
+// historyUsage turns this machine's run history on hostName into usage
+// records for the current user, for when the host's own log can't be read.
+func historyUsage(entries []history.Entry, hostName string) []lock.UsageRecord {
+	user := "unknown"
+	if info, err := lock.NewLockInfo(""); err == nil {
+		user = info.User
+	}
+	var records []lock.UsageRecord
+	for _, e := range entries {
+		if e.Host != hostName {
+			continue
+		}
+		records = append(records, lock.UsageRecord{User: user, Started: e.Time.Add(-e.Total), Duration: e.Total})
+	}
+	return records
+}
+
+// gatherHostUsage returns each host's usage over the last days days, read
+// from the hosts in parallel. A host that can't be reached falls back to
+// this machine's run history, which only knows about our own runs.
+func gatherHostUsage(hosts map[string]config.Host, cfg config.LockConfig, days int, showProgress bool) map[string]HostUsage {
+	var spinner *ui.Spinner
+	if showProgress && ui.IsTerminal(os.Stdout) {
+		spinner = ui.NewSpinner("Reading host usage")
+		spinner.Start()
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	remote := make(map[string][]lock.UsageRecord)
+	for name, h := range hosts {
+		wg.Add(1)
+		go func(hostName string, hostCfg config.Host) {
+			defer wg.Done()
+			records, err := readHostUsage(hostName, hostCfg, cfg)
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			remote[hostName] = records
+			mu.Unlock()
+		}(name, h)
+	}
+	wg.Wait()
+
+	if spinner != nil {
+		spinner.Success()
+		fmt.Println()
+	}
+
+	var entries []history.Entry
+	if len(remote) < len(hosts) {
+		entries, _ = history.LoadAll()
+	}
+
+	since := time.Now().AddDate(0, 0, -days)
+	usage := make(map[string]HostUsage, len(hosts))
+	for name := range hosts {
+		u := HostUsage{Days: days, Source: usageSourceHost}
+		records, ok := remote[name]
+		if !ok {
+			u.Source = usageSourceHistory
+			records = historyUsage(entries, name)
+		}
+		u.Users = lock.SummarizeUsage(records, since)
+		u.Hint = lock.FairShareHint(u.Users)
+		usage[name] = u
+	}
+	return usage
+}
+
+// printHostUsage prints a host's usage breakdown under its entry in
+// 'rr host list'.
+func printHostUsage(u HostUsage, dimStyle lipgloss.Style) {
+	label := fmt.Sprintf("usage, last %d days", u.Days)
+	if u.Source == usageSourceHistory {
+		label += " (your runs from this machine - host unreachable)"
+	}
+	if len(u.Users) == 0 {
+		fmt.Printf("  %s\n", dimStyle.Render(label+": no runs"))
+		return
+	}
+	fmt.Printf("  %s\n", dimStyle.Render(label+":"))
+
+	width := 0
+	for _, user := range u.Users {
+		width = max(width, len(user.User))
+	}
+	for _, user := range u.Users {
+		runs := "runs"
+		if user.Runs == 1 {
+			runs = "run"
+		}
+		fmt.Printf("    %-*s  %8s  %3.0f%%  %s\n", width, user.User,
+			formatUsageTime(user.Total), user.Share*100, dimStyle.Render(fmt.Sprintf("%d %s", user.Runs, runs)))
+	}
+	if u.Hint != "" {
+		fmt.Printf("  %s %s\n", ui.SymbolWarning, u.Hint)
+	}
+}
+
+// formatUsageTime formats time on a host in hours and minutes, e.g. "3h05m",
+// or seconds under a minute.
+func formatUsageTime(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+	d = d.Round(time.Minute)
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/history"
+	"github.com/rileyhilliard/rr/internal/lock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGatherHostUsage(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USER", "carol")
+	now := time.Now()
+	require.NoError(t, history.Append(t.TempDir(), history.Entry{Host: "mini", Time: now, Total: 10 * time.Minute}))
+	require.NoError(t, history.Append(t.TempDir(), history.Entry{Host: "gpu-box", Time: now, Total: time.Hour}))
+
+	orig := readHostUsage
+	t.Cleanup(func() { readHostUsage = orig })
+	readHostUsage = func(name string, h config.Host, cfg config.LockConfig) ([]lock.UsageRecord, error) {
+		assert.Equal(t, "/var/rr", cfg.Dir)
+		if name == "mini" {
+			return nil, fmt.Errorf("unreachable")
+		}
+		return []lock.UsageRecord{
+			{User: "alice", Started: now.Add(-time.Hour), Duration: 3 * time.Hour},
+			{User: "bob", Started: now.Add(-2 * time.Hour), Duration: 30 * time.Minute},
+			{User: "bob", Started: now.AddDate(0, 0, -30), Duration: 10 * time.Hour},
+		}, nil
+	}
+
+	hosts := map[string]config.Host{"gpu-box": {SSH: []string{"gpu.local"}}, "mini": {SSH: []string{"mini.local"}}}
+	usage := gatherHostUsage(hosts, config.LockConfig{Dir: "/var/rr"}, 7, false)
+
+	gpu := usage["gpu-box"]
+	assert.Equal(t, usageSourceHost, gpu.Source, "the host's log covers everyone, so history isn't used")
+	require.Len(t, gpu.Users, 2)
+	assert.Equal(t, "alice", gpu.Users[0].User)
+	assert.Equal(t, 180.0, gpu.Users[0].Minutes)
+	assert.Equal(t, 1, gpu.Users[1].Runs, "bob's older run is outside the window")
+	assert.Contains(t, gpu.Hint, "alice had 86% of the time across 2 users")
+
+	mini := usage["mini"]
+	assert.Equal(t, usageSourceHistory, mini.Source)
+	require.Len(t, mini.Users, 1)
+	assert.Equal(t, "carol", mini.Users[0].User)
+	assert.Equal(t, 10.0, mini.Users[0].Minutes)
+	assert.Empty(t, mini.Hint)
+}
+
+func TestPrintHostUsage(t *testing.T) {
+	out := captureStdout(t, func() {
+		printHostUsage(HostUsage{
+			Days:   7,
+			Source: usageSourceHost,
+			Users: []lock.UserUsage{
+				{User: "alice", Runs: 12, Total: 3*time.Hour + 5*time.Minute, Share: 0.86},
+				{User: "bob", Runs: 1, Total: 30 * time.Minute, Share: 0.14},
+			},
+			Hint: "alice had 86% of the time across 2 users (a fair share is 50%)",
+		}, lipgloss.NewStyle())
+	})
+	assert.Contains(t, out, "usage, last 7 days:")
+	assert.Contains(t, out, "alice     3h05m   86%  12 runs")
+	assert.Contains(t, out, "bob         30m   14%  1 run")
+	assert.Contains(t, out, "alice had 86% of the time")
+
+	out = captureStdout(t, func() {
+		printHostUsage(HostUsage{Days: 30, Source: usageSourceHistory}, lipgloss.NewStyle())
+	})
+	assert.Contains(t, out, "usage, last 30 days (your runs from this machine - host unreachable): no runs")
+}
+
+func TestFormatUsageTime(t *testing.T) {
+	assert.Equal(t, "45s", formatUsageTime(45*time.Second))
+	assert.Equal(t, "12m", formatUsageTime(12*time.Minute+20*time.Second))
+	assert.Equal(t, "3h05m", formatUsageTime(3*time.Hour+5*time.Minute))
+	assert.Equal(t, "26h00m", formatUsageTime(26*time.Hour))
+}
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/diagnostics"
+	"github.com/rileyhilliard/rr/internal/exec"
+	"github.com/rileyhilliard/rr/internal/ui"
+)
+
+// idleSnapshotFile is the file in the project's .rr directory that holds the
+// process snapshot taken when a command last went quiet.
+const idleSnapshotFile = "idle-snapshot.txt"
+
+// idleOptions returns idle detection for a command, from task's
+// idle_timeout or the project default (task is nil for 'rr run'). Returns
+// nil when there's no limit.
+func idleOptions(wf *WorkflowContext, task *config.TaskConfig) *exec.IdleOptions {
+	if wf.Resolved == nil {
+		return nil
+	}
+	timeout, kill := config.GetIdleTimeout(wf.Resolved.Project, task)
+	if timeout <= 0 {
+		return nil
+	}
+	return &exec.IdleOptions{
+		Timeout: timeout,
+		Kill:    kill,
+		OnIdle: func(event exec.IdleEvent) {
+			reportIdle(wf, event)
+		},
+	}
+}
+
+// reportIdle warns that a command went quiet, saving the process snapshot
+// to the project's .rr directory: a warning in pretty mode, or an "idle"
+// event in structured mode.
+func reportIdle(wf *WorkflowContext, event exec.IdleEvent) {
+	snapshotPath, snapshotErr := "", event.SnapshotErr
+	if snapshotErr == nil {
+		snapshotPath, snapshotErr = saveIdleSnapshot(wf.WorkDir, event.Snapshot)
+	}
+	hostName := "local"
+	if wf.Conn != nil {
+		hostName = wf.Conn.Name
+	}
+
+	if !PrettyMode() {
+		details := map[string]interface{}{
+			"idle_for_s": event.IdleFor.Seconds(),
+			"killing":    event.Killing,
+		}
+		if snapshotPath != "" {
+			details["snapshot"] = snapshotPath
+		} else if snapshotErr != nil {
+			details["snapshot_error"] = snapshotErr.Error()
+		}
+		status := "warning"
+		if event.Killing {
+			status = "killing"
+		}
+		WritePhaseEvent(PhaseEvent{
+			Type:    "idle",
+			Status:  status,
+			Host:    hostName,
+			Details: details,
+		})
+		return
+	}
+
+	msg := fmt.Sprintf("No output for %s - the command may be hung", ui.FormatElapsed(event.IdleFor))
+	if event.Killing {
+		msg = fmt.Sprintf("No output for %s - stopping the command (idle_kill)", ui.FormatElapsed(event.IdleFor))
+	}
+	ui.PrintWarning(msg)
+	mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
+	if snapshotPath != "" {
+		fmt.Fprintln(os.Stderr, mutedStyle.Render(fmt.Sprintf("  Processes on %s saved to %s", hostName, snapshotPath)))
+	} else if snapshotErr != nil {
+		fmt.Fprintln(os.Stderr, mutedStyle.Render(fmt.Sprintf("  Couldn't list processes on %s: %v", hostName, snapshotErr)))
+	}
+}
+
+// saveIdleSnapshot writes a process snapshot to the project's .rr directory
+// and returns its path.
+func saveIdleSnapshot(projectRoot string, snapshot []byte) (string, error) {
+	if projectRoot == "" {
+		if wd, err := os.Getwd(); err == nil {
+			projectRoot = wd
+		}
+	}
+	dir, err := diagnostics.EnsureDir(projectRoot)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, idleSnapshotFile)
+	if err := os.WriteFile(path, snapshot, 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
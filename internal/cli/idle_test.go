@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdleOptions(t *testing.T) {
+	wf := &WorkflowContext{Resolved: &config.ResolvedConfig{Project: &config.Config{
+		Defaults: config.ProjectDefaults{IdleTimeout: "15m"},
+	}}}
+
+	opts := idleOptions(wf, nil)
+	require.NotNil(t, opts)
+	assert.Equal(t, 15*time.Minute, opts.Timeout)
+	assert.False(t, opts.Kill)
+	assert.NotNil(t, opts.OnIdle)
+
+	opts = idleOptions(wf, &config.TaskConfig{Run: "make e2e", IdleTimeout: "5m", IdleKill: true})
+	require.NotNil(t, opts)
+	assert.Equal(t, 5*time.Minute, opts.Timeout)
+	assert.True(t, opts.Kill)
+
+	wf.Resolved.Project.Defaults = config.ProjectDefaults{}
+	assert.Nil(t, idleOptions(wf, &config.TaskConfig{Run: "make"}))
+	assert.Nil(t, idleOptions(&WorkflowContext{}, nil))
+}
+
+func TestSaveIdleSnapshot(t *testing.T) {
+	root := t.TempDir()
+
+	path, err := saveIdleSnapshot(root, []byte("PID COMMAND\n42 make e2e\n"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, ".rr", idleSnapshotFile), path)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "make e2e")
+	assert.FileExists(t, filepath.Join(root, ".rr", ".gitignore"), "the snapshot stays out of git and syncs")
+}
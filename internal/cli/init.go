@@ -28,6 +28,7 @@ type InitOptions struct {
 	Overwrite      bool   // Overwrite existing config without asking
 	NonInteractive bool   // Skip prompts, use defaults
 	SkipProbe      bool   // Skip connection testing
+	ImportTasks    bool   // Import detected Makefile/package.json/pyproject tasks (for non-interactive)
 }
 
 // getInitDefaults returns InitOptions populated from environment variables.
@@ -84,7 +85,8 @@ type machineConfig struct {
 
 // projectConfigValues holds the collected project configuration values.
 type projectConfigValues struct {
-	hostRefs []string // References to hosts in global config (empty = use all)
+	hostRefs []string       // References to hosts in global config (empty = use all)
+	tasks    []detectedTask // Tasks imported from the project's build files
 }
 
 // checkExistingConfig checks for existing config and prompts for overwrite.
@@ -603,7 +605,27 @@ func generateProjectConfigContent(vals *projectConfigValues) string {
 	sb.WriteString("  # Where lock files are stored on remote\n")
 	sb.WriteString("  # dir: /tmp/rr-locks\n\n")
 
-	// Tasks section (commented out example)
+	// Tasks section: imported ones, or a commented out example
+	if len(vals.tasks) > 0 {
+		sb.WriteString("# Named tasks for common commands (run with 'rr <name>')\n")
+		sb.WriteString("tasks:\n")
+		for _, t := range vals.tasks {
+			sb.WriteString(fmt.Sprintf("  %s:\n", yamlValue(t.Name)))
+			sb.WriteString(fmt.Sprintf("    description: %s\n", yamlValue(t.Description)))
+			sb.WriteString(fmt.Sprintf("    run: %s\n", yamlValue(t.Run)))
+		}
+		sb.WriteString("\n")
+	} else {
+		writeExampleTasks(&sb)
+	}
+
+	writeOutputAndMonitorExamples(&sb)
+
+	return sb.String()
+}
+
+// writeExampleTasks writes a commented out tasks section.
+func writeExampleTasks(sb *strings.Builder) {
 	sb.WriteString("# Named tasks for common commands\n")
 	sb.WriteString("# tasks:\n")
 	sb.WriteString("#   test:\n")
@@ -617,7 +639,11 @@ func generateProjectConfigContent(vals *projectConfigValues) string {
 	sb.WriteString("#         run: pip install -e .\n")
 	sb.WriteString("#       - name: Run build\n")
 	sb.WriteString("#         run: python setup.py build\n\n")
+}
 
+// writeOutputAndMonitorExamples writes the commented out output and monitor
+// sections.
+func writeOutputAndMonitorExamples(sb *strings.Builder) {
 	// Output section (commented out)
 	sb.WriteString("# Output formatting\n")
 	sb.WriteString("# output:\n")
@@ -641,12 +667,30 @@ func generateProjectConfigContent(vals *projectConfigValues) string {
 	sb.WriteString("#     gpu:\n")
 	sb.WriteString("#       warning: 70\n")
 	sb.WriteString("#       critical: 90\n")
-
-	return sb.String()
 }
 
-// writeProjectConfig writes the project configuration file.
+// writeProjectConfig writes the project configuration file and prints next steps.
 func writeProjectConfig(configPath string, vals *projectConfigValues) error {
+	if err := saveProjectConfig(configPath, vals); err != nil {
+		return err
+	}
+
+	fmt.Println("Next steps:")
+	if len(vals.hostRefs) == 0 {
+		fmt.Println("  rr host add   - Add a host to your global config")
+	}
+	if len(vals.tasks) > 0 {
+		fmt.Printf("  rr tasks      - List the %d imported tasks\n", len(vals.tasks))
+	}
+	fmt.Println("  rr sync       - Sync files to remote")
+	fmt.Println("  rr run <cmd>  - Sync and run a command")
+	fmt.Println("  rr doctor     - Check configuration")
+
+	return nil
+}
+
+// saveProjectConfig generates, validates, and writes the project configuration file.
+func saveProjectConfig(configPath string, vals *projectConfigValues) error {
 	content := generateProjectConfigContent(vals)
 
 	// Validate the generated YAML is parseable
@@ -657,22 +701,13 @@ func writeProjectConfig(configPath string, vals *projectConfigValues) error {
 			"This is unexpected - please report this bug!")
 	}
 
-	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+	if err := config.WriteFile(configPath, []byte(content), 0644); err != nil {
 		return errors.WrapWithCode(err, errors.ErrConfig,
 			fmt.Sprintf("Couldn't write the config file to %s", configPath),
 			"Check that you have write permissions in this directory.")
 	}
 
 	fmt.Printf("%s Created %s\n\n", ui.SymbolSuccess, configPath)
-
-	fmt.Println("Next steps:")
-	if len(vals.hostRefs) == 0 {
-		fmt.Println("  rr host add   - Add a host to your global config")
-	}
-	fmt.Println("  rr sync       - Sync files to remote")
-	fmt.Println("  rr run <cmd>  - Sync and run a command")
-	fmt.Println("  rr doctor     - Check configuration")
-
 	return nil
 }
 
@@ -847,6 +882,15 @@ func collectInteractiveValues(globalCfg *config.GlobalConfig, skipProbe bool) (*
 		}
 	}
 
+	// Offer to import tasks from the project's Makefile, package.json, etc.
+	if detected := detectProjectTasks("."); len(detected) > 0 {
+		imported, err := promptTaskImport(detected)
+		if err != nil {
+			return nil, err
+		}
+		vals.tasks = imported
+	}
+
 	// Only offer to add more hosts if there are SSH hosts not yet added
 	for hasUnaddedSSHHosts(globalCfg) {
 		addMore, err := promptAddMoreHosts()
@@ -923,6 +967,10 @@ func collectNonInteractiveValues(opts InitOptions, globalCfg *config.GlobalConfi
 	}
 	// If no hosts specified, hostRefs stays empty = use all global hosts
 
+	if opts.ImportTasks {
+		vals.tasks = detectProjectTasks(".")
+	}
+
 	return vals, nil
 }
 
@@ -0,0 +1,290 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+)
+
+// detectedTask is a task found in a project's existing build files, which
+// init can import into .rr.yaml.
+type detectedTask struct {
+	Name        string // Task name, e.g. "test"
+	Run         string // Command to run, e.g. "make test"
+	Description string
+	Source      string // File it came from, e.g. "Makefile"
+}
+
+// maxDetectedDescription caps descriptions taken from script bodies.
+const maxDetectedDescription = 60
+
+// makeTargetPattern matches a Makefile rule line like "test: build ## Run
+// the tests". Variable assignments (":=", "::=") and file targets (with a
+// "/" or ".") don't match.
+var makeTargetPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9_-]*)\s*:([^=:].*|)$`)
+
+// detectProjectTasks scans dir's Makefile, package.json and pyproject.toml
+// for commands to import as tasks. When two files define the same name, the
+// first one in that order wins. Names that are built-in commands are skipped.
+func detectProjectTasks(dir string) []detectedTask {
+	var all []detectedTask
+	all = append(all, detectMakeTasks(dir)...)
+	all = append(all, detectPackageJSONTasks(dir)...)
+	all = append(all, detectPyprojectTasks(dir)...)
+
+	seen := make(map[string]bool)
+	var tasks []detectedTask
+	for _, t := range all {
+		if seen[t.Name] || config.IsReservedTaskName(t.Name) {
+			continue
+		}
+		seen[t.Name] = true
+		tasks = append(tasks, t)
+	}
+	return tasks
+}
+
+// detectMakeTasks returns a task per Makefile target, in file order. A
+// target's description comes from a trailing "## comment" on its rule line,
+// or from the comment line just above it.
+func detectMakeTasks(dir string) []detectedTask {
+	var name string
+	for _, candidate := range []string{"GNUmakefile", "makefile", "Makefile"} {
+		if _, err := os.Stat(filepath.Join(dir, candidate)); err == nil {
+			name = candidate
+			break
+		}
+	}
+	if name == "" {
+		return nil
+	}
+	f, err := os.Open(filepath.Join(dir, name))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var tasks []detectedTask
+	seen := make(map[string]bool)
+	lastComment := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") {
+			lastComment = strings.TrimSpace(strings.TrimLeft(line, "#"))
+			continue
+		}
+		// A comment above ".PHONY: test" still describes the test rule below it
+		if strings.HasPrefix(line, ".PHONY") {
+			continue
+		}
+
+		m := makeTargetPattern.FindStringSubmatch(line)
+		comment := lastComment
+		lastComment = ""
+		if m == nil || seen[m[1]] {
+			continue
+		}
+		seen[m[1]] = true
+
+		if _, trailing, ok := strings.Cut(m[2], "##"); ok {
+			comment = strings.TrimSpace(trailing)
+		}
+		if comment == "" {
+			comment = "make " + m[1]
+		}
+		tasks = append(tasks, detectedTask{
+			Name:        m[1],
+			Run:         "make " + m[1],
+			Description: comment,
+			Source:      name,
+		})
+	}
+	return tasks
+}
+
+// packageLifecycleScripts are package.json scripts the package manager runs
+// on its own around install and publish.
+var packageLifecycleScripts = map[string]bool{
+	"install":        true,
+	"preinstall":     true,
+	"postinstall":    true,
+	"prepare":        true,
+	"prepublish":     true,
+	"prepublishOnly": true,
+	"prepack":        true,
+	"postpack":       true,
+}
+
+// detectPackageJSONTasks returns a task per package.json script, sorted by
+// name, run through the package manager whose lockfile is present. Lifecycle
+// scripts and pre/post hooks of other scripts are skipped.
+func detectPackageJSONTasks(dir string) []detectedTask {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return nil
+	}
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil
+	}
+
+	runner := packageRunner(dir)
+	var tasks []detectedTask
+	for name, script := range pkg.Scripts {
+		if packageLifecycleScripts[name] || isScriptHook(name, pkg.Scripts) {
+			continue
+		}
+		tasks = append(tasks, detectedTask{
+			Name:        name,
+			Run:         runner + " " + name,
+			Description: truncateDescription(script),
+			Source:      "package.json",
+		})
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].Name < tasks[j].Name })
+	return tasks
+}
+
+// isScriptHook reports whether name is a "pre" or "post" hook of another
+// script, like "pretest" for "test".
+func isScriptHook(name string, scripts map[string]string) bool {
+	for _, prefix := range []string{"pre", "post"} {
+		if base, ok := strings.CutPrefix(name, prefix); ok && base != "" {
+			if _, exists := scripts[base]; exists {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// packageRunner picks the command that runs package.json scripts from the
+// lockfile in dir, defaulting to npm.
+func packageRunner(dir string) string {
+	for _, lf := range []struct{ file, runner string }{
+		{"bun.lock", "bun run"},
+		{"bun.lockb", "bun run"},
+		{"pnpm-lock.yaml", "pnpm run"},
+		{"yarn.lock", "yarn"},
+	} {
+		if _, err := os.Stat(filepath.Join(dir, lf.file)); err == nil {
+			return lf.runner
+		}
+	}
+	return "npm run"
+}
+
+// detectPyprojectTasks returns a task per console script in pyproject.toml
+// ([project.scripts] and [tool.poetry.scripts]), sorted by name, run through
+// uv or poetry when their lockfile is present.
+func detectPyprojectTasks(dir string) []detectedTask {
+	data, err := os.ReadFile(filepath.Join(dir, "pyproject.toml"))
+	if err != nil {
+		return nil
+	}
+	var pyproject struct {
+		Project struct {
+			Scripts map[string]string `toml:"scripts"`
+		} `toml:"project"`
+		Tool struct {
+			Poetry struct {
+				Scripts map[string]any `toml:"scripts"`
+			} `toml:"poetry"`
+		} `toml:"tool"`
+	}
+	if err := toml.Unmarshal(data, &pyproject); err != nil {
+		return nil
+	}
+
+	scripts := make(map[string]string)
+	for name, target := range pyproject.Tool.Poetry.Scripts {
+		// Poetry also allows tables like { reference = "...", type = "file" }
+		if s, ok := target.(string); ok {
+			scripts[name] = s
+		}
+	}
+	for name, target := range pyproject.Project.Scripts {
+		scripts[name] = target
+	}
+
+	prefix := ""
+	if _, err := os.Stat(filepath.Join(dir, "uv.lock")); err == nil {
+		prefix = "uv run "
+	} else if _, err := os.Stat(filepath.Join(dir, "poetry.lock")); err == nil {
+		prefix = "poetry run "
+	}
+
+	var tasks []detectedTask
+	for name, target := range scripts {
+		tasks = append(tasks, detectedTask{
+			Name:        name,
+			Run:         prefix + name,
+			Description: truncateDescription(target),
+			Source:      "pyproject.toml",
+		})
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].Name < tasks[j].Name })
+	return tasks
+}
+
+// truncateDescription shortens a script body to fit on one line of a
+// description.
+func truncateDescription(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) > maxDetectedDescription {
+		return s[:maxDetectedDescription-3] + "..."
+	}
+	return s
+}
+
+// promptTaskImport shows a multi-select of the detected tasks to import.
+// All are selected to start.
+func promptTaskImport(tasks []detectedTask) ([]detectedTask, error) {
+	options := make([]huh.Option[string], 0, len(tasks))
+	selected := make([]string, 0, len(tasks))
+	for _, t := range tasks {
+		options = append(options, huh.NewOption(fmt.Sprintf("%s - %s (%s)", t.Name, t.Run, t.Source), t.Name))
+		selected = append(selected, t.Name)
+	}
+
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewMultiSelect[string]().
+				Title("Import these as rr tasks?").
+				Description("Found in your project's build files. Each becomes 'rr <name>'.").
+				Options(options...).
+				Value(&selected),
+		),
+	)
+
+	if err := form.Run(); err != nil {
+		return nil, errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't get your selection",
+			"Try --non-interactive --import-tasks to import them all.")
+	}
+
+	keep := make(map[string]bool, len(selected))
+	for _, name := range selected {
+		keep[name] = true
+	}
+	var imported []detectedTask
+	for _, t := range tasks {
+		if keep[t.Name] {
+			imported = append(imported, t)
+		}
+	}
+	return imported, nil
+}
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func writeProjectFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+}
+
+func TestDetectMakeTasks(t *testing.T) {
+	dir := t.TempDir()
+	writeProjectFile(t, dir, "Makefile", `CC := gcc
+VERSION ::= 1.0
+
+# Run the tests
+.PHONY: test
+test: build
+	go test ./...
+
+build: ## Build the binary
+	go build ./...
+
+lint:
+	golangci-lint run
+
+bin/rr: main.go
+	go build -o $@
+
+.DEFAULT_GOAL := build
+test:
+	echo duplicate rule
+`)
+
+	tasks := detectMakeTasks(dir)
+	require.Len(t, tasks, 3)
+
+	assert.Equal(t, detectedTask{Name: "test", Run: "make test", Description: "Run the tests", Source: "Makefile"}, tasks[0])
+	assert.Equal(t, detectedTask{Name: "build", Run: "make build", Description: "Build the binary", Source: "Makefile"}, tasks[1])
+	assert.Equal(t, "lint", tasks[2].Name)
+	assert.Equal(t, "make lint", tasks[2].Description)
+}
+
+func TestDetectMakeTasks_NoMakefile(t *testing.T) {
+	assert.Empty(t, detectMakeTasks(t.TempDir()))
+}
+
+func TestDetectPackageJSONTasks(t *testing.T) {
+	dir := t.TempDir()
+	writeProjectFile(t, dir, "package.json", `{
+  "name": "app",
+  "scripts": {
+    "test": "vitest run",
+    "pretest": "tsc --noEmit",
+    "build": "vite build",
+    "postinstall": "patch-package",
+    "preview": "vite preview"
+  }
+}`)
+
+	tasks := detectPackageJSONTasks(dir)
+	require.Len(t, tasks, 3)
+	assert.Equal(t, detectedTask{Name: "build", Run: "npm run build", Description: "vite build", Source: "package.json"}, tasks[0])
+	// "preview" isn't a hook: there's no "view" script
+	assert.Equal(t, "preview", tasks[1].Name)
+	assert.Equal(t, "test", tasks[2].Name)
+
+	t.Run("runner follows the lockfile", func(t *testing.T) {
+		writeProjectFile(t, dir, "pnpm-lock.yaml", "")
+		assert.Equal(t, "pnpm run build", detectPackageJSONTasks(dir)[0].Run)
+
+		writeProjectFile(t, dir, "bun.lock", "")
+		assert.Equal(t, "bun run build", detectPackageJSONTasks(dir)[0].Run)
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		bad := t.TempDir()
+		writeProjectFile(t, bad, "package.json", `{"scripts": `)
+		assert.Empty(t, detectPackageJSONTasks(bad))
+	})
+}
+
+func TestDetectPyprojectTasks(t *testing.T) {
+	dir := t.TempDir()
+	writeProjectFile(t, dir, "pyproject.toml", `[project]
+name = "app"
+
+[project.scripts]
+serve = "app.server:main"
+
+[tool.poetry.scripts]
+migrate = "app.db:migrate"
+docs = { reference = "docs/build.sh", type = "file" }
+`)
+
+	tasks := detectPyprojectTasks(dir)
+	require.Len(t, tasks, 2)
+	assert.Equal(t, detectedTask{Name: "migrate", Run: "migrate", Description: "app.db:migrate", Source: "pyproject.toml"}, tasks[0])
+	assert.Equal(t, "serve", tasks[1].Name)
+
+	writeProjectFile(t, dir, "uv.lock", "")
+	assert.Equal(t, "uv run migrate", detectPyprojectTasks(dir)[0].Run)
+}
+
+func TestDetectProjectTasks_FirstSourceWinsAndReservedSkipped(t *testing.T) {
+	dir := t.TempDir()
+	writeProjectFile(t, dir, "Makefile", "test:\n\tgo test ./...\nrun:\n\tgo run .\n")
+	writeProjectFile(t, dir, "package.json", `{"scripts": {"test": "jest", "lint": "eslint ."}}`)
+
+	tasks := detectProjectTasks(dir)
+	require.Len(t, tasks, 2)
+	assert.Equal(t, "make test", tasks[0].Run)
+	assert.Equal(t, "npm run lint", tasks[1].Run)
+}
+
+func TestTruncateDescription(t *testing.T) {
+	assert.Equal(t, "vite build --mode production", truncateDescription("vite   build\n --mode production"))
+
+	long := truncateDescription("node scripts/build.js --really-long-flag --another-really-long-flag --and-more")
+	assert.Len(t, long, maxDetectedDescription)
+	assert.Contains(t, long, "...")
+}
+
+func TestGenerateProjectConfigContent_ImportedTasks(t *testing.T) {
+	content := generateProjectConfigContent(&projectConfigValues{
+		tasks: []detectedTask{
+			{Name: "test", Run: "make test", Description: "Run the tests"},
+			{Name: "test:unit", Run: "npm run test:unit", Description: "jest --selectProjects unit"},
+		},
+	})
+
+	var cfg config.Config
+	require.NoError(t, yaml.Unmarshal([]byte(content), &cfg))
+	require.Len(t, cfg.Tasks, 2)
+	assert.Equal(t, "make test", cfg.Tasks["test"].Run)
+	assert.Equal(t, "Run the tests", cfg.Tasks["test"].Description)
+	assert.Equal(t, "npm run test:unit", cfg.Tasks["test:unit"].Run)
+
+	// Without imported tasks, the section stays a commented out example
+	content = generateProjectConfigContent(&projectConfigValues{})
+	assert.Contains(t, content, "# tasks:\n")
+	assert.NotContains(t, content, "\ntasks:\n")
+}
+
+func TestInit_NonInteractive_ImportTasks(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	require.NoError(t, os.Chdir(tmpDir))
+	t.Setenv("HOME", tmpDir)
+
+	writeProjectFile(t, tmpDir, "Makefile", "test: ## Run the tests\n\tgo test ./...\n")
+
+	require.NoError(t, Init(InitOptions{NonInteractive: true, ImportTasks: true}))
+
+	cfg, err := config.Load(filepath.Join(tmpDir, ".rr.yaml"))
+	require.NoError(t, err)
+	require.Contains(t, cfg.Tasks, "test")
+	assert.Equal(t, "make test", cfg.Tasks["test"].Run)
+}
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rileyhilliard/rr/internal/errors"
+	"golang.org/x/term"
+)
+
+// nonInteractiveEnv turns on --non-interactive for every command when set
+// to "true".
+const nonInteractiveEnv = "RR_NON_INTERACTIVE"
+
+// NonInteractive reports whether prompts and pickers are off, with
+// --non-interactive or RR_NON_INTERACTIVE=true. rr then never waits on
+// input: anything it would have asked for is a default or an error saying
+// which flag to pass.
+func NonInteractive() bool {
+	return nonInteractive || os.Getenv(nonInteractiveEnv) == "true"
+}
+
+// stdinIsTerminal reports whether stdin is a terminal. Swappable for tests.
+var stdinIsTerminal = func() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// canPrompt reports whether rr can ask the user something: prompts aren't
+// turned off and there's a terminal to ask on.
+func canPrompt() bool {
+	return !NonInteractive() && stdinIsTerminal()
+}
+
+// needsInput is the error for a question rr can't ask. what says what it
+// needed; answer says how to give it up front.
+func needsInput(what, answer string) error {
+	why := "there's no terminal to ask on"
+	if NonInteractive() {
+		why = "prompts are off (--non-interactive)"
+	}
+	return errors.New(errors.ErrConfig, fmt.Sprintf("%s, and %s", what, why), answer).
+		WithID(errors.IDConfigNeedsInput)
+}
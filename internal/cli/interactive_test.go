@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubNonInteractive runs the test as if --non-interactive were passed
+// from a terminal, so only the flag keeps rr from prompting.
+func stubNonInteractive(t *testing.T) {
+	t.Helper()
+	origFlag, origTerminal := nonInteractive, stdinIsTerminal
+	t.Cleanup(func() { nonInteractive, stdinIsTerminal = origFlag, origTerminal })
+	nonInteractive = true
+	stdinIsTerminal = func() bool { return true }
+}
+
+func TestNonInteractive(t *testing.T) {
+	origFlag, origTerminal := nonInteractive, stdinIsTerminal
+	t.Cleanup(func() { nonInteractive, stdinIsTerminal = origFlag, origTerminal })
+	stdinIsTerminal = func() bool { return true }
+	nonInteractive = false
+	t.Setenv("RR_NON_INTERACTIVE", "")
+
+	assert.False(t, NonInteractive())
+	assert.True(t, canPrompt())
+
+	t.Setenv("RR_NON_INTERACTIVE", "true")
+	assert.True(t, NonInteractive(), "the env var turns it on")
+	assert.False(t, canPrompt())
+
+	t.Setenv("RR_NON_INTERACTIVE", "")
+	nonInteractive = true
+	assert.True(t, NonInteractive(), "so does the flag")
+	assert.False(t, canPrompt())
+
+	nonInteractive = false
+	stdinIsTerminal = func() bool { return false }
+	assert.False(t, NonInteractive())
+	assert.False(t, canPrompt(), "nothing to ask on without a terminal")
+}
+
+func TestNeedsInput(t *testing.T) {
+	stubNonInteractive(t)
+
+	err := needsInput("Which host should I unlock?", "Pass the host.")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Which host should I unlock?, and prompts are off (--non-interactive)")
+	assert.Contains(t, err.Error(), "Pass the host.")
+	assert.Equal(t, errors.IDConfigNeedsInput, errors.IDOf(err))
+}
+
+func TestNonInteractive_TaskParams(t *testing.T) {
+	stubNonInteractive(t)
+	task := config.TaskConfig{Params: []config.TaskParam{{Name: "env"}, {Name: "tag", Default: "latest"}}}
+
+	values, err := taskParamValues("deploy", task, []string{"env=prod"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "prod", "tag": "latest"}, values, "defaults fill in, nothing is asked")
+
+	_, err = taskParamValues("deploy", task, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "prompts are off")
+	assert.Contains(t, err.Error(), "--param env=<value>")
+}
+
+func TestNonInteractive_HostCommands(t *testing.T) {
+	stubNonInteractive(t)
+	t.Setenv("HOME", t.TempDir())
+	require.NoError(t, config.SaveGlobal(&config.GlobalConfig{
+		Hosts: map[string]config.Host{
+			"mini":    {SSH: []string{"mini.local"}},
+			"gpu-box": {SSH: []string{"gpu.local"}},
+		},
+	}))
+
+	err := unlockCommand(UnlockOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--all")
+	assert.Equal(t, errors.IDConfigNeedsInput, errors.IDOf(err))
+
+	err = hostRemove("")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rr host remove <name> --yes")
+
+	err = hostRemove("mini")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Pass --yes")
+
+	err = hostAdd(HostAddOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--name <name> --ssh <user@host>")
+
+	global, err := config.LoadGlobal()
+	require.NoError(t, err)
+	assert.Len(t, global.Hosts, 2, "nothing changed")
+}
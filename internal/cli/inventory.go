@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/inventory"
+	"github.com/rileyhilliard/rr/internal/ui"
+)
+
+// loadResolved loads the config like config.LoadResolved, then adds the
+// hosts the global config's inventory sources discover, so every command
+// that picks hosts sees them.
+func loadResolved(explicitPath string) (*config.ResolvedConfig, error) {
+	resolved, err := config.LoadResolved(explicitPath)
+	if err != nil {
+		return nil, err
+	}
+	mergeInventory(resolved.Global, false)
+	return resolved, nil
+}
+
+// mergeInventory adds discovered hosts to cfg. Sources that can't be
+// queried are warned about, not fatal: the run goes ahead with the hosts
+// rr does know about. A global config that doesn't validate is left for
+// the caller's validation to report.
+func mergeInventory(cfg *config.GlobalConfig, refresh bool) {
+	if len(cfg.Inventory) == 0 || config.ValidateGlobal(cfg) != nil {
+		return
+	}
+	for _, err := range inventory.Merge(cfg, refresh) {
+		if PrettyMode() {
+			ui.PrintWarning(err.Error())
+			continue
+		}
+		WritePhaseEvent(PhaseEvent{
+			Type:   "inventory",
+			Status: "warning",
+			Error:  err.Error(),
+		})
+	}
+}
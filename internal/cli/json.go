@@ -72,6 +72,7 @@ type JSONEnvelope struct {
 // JSONError provides structured error information for machine parsing.
 type JSONError struct {
 	Code       string      `json:"code"`
+	ID         string      `json:"id,omitempty"` // Stable ID for 'rr explain' (e.g., RR-SSH-003)
 	Message    string      `json:"message"`
 	Suggestion string      `json:"suggestion,omitempty"`
 	Details    interface{} `json:"details,omitempty"`
@@ -146,6 +147,7 @@ func ErrorToJSON(err error) *JSONError {
 	if stderrors.As(err, &rrErr) {
 		return &JSONError{
 			Code:       mapErrorCode(rrErr.Code, rrErr.Message),
+			ID:         errors.IDOf(err),
 			Message:    rrErr.Message,
 			Suggestion: rrErr.Suggestion,
 		}
@@ -160,6 +162,7 @@ func ErrorToJSON(err error) *JSONError {
 	// Generic error
 	return &JSONError{
 		Code:    ErrCodeUnknown,
+		ID:      errors.IDOf(err),
 		Message: err.Error(),
 	}
 }
@@ -215,6 +218,7 @@ func probeErrorToJSON(probeErr *host.ProbeError) *JSONError {
 
 	return &JSONError{
 		Code:       code,
+		ID:         probeErr.ErrorID(),
 		Message:    probeErr.Error(),
 		Suggestion: suggestion,
 		Details: map[string]interface{}{
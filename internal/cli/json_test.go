@@ -307,6 +307,7 @@ func TestErrorToJSON_ProbeError(t *testing.T) {
 
 	require.NotNil(t, result)
 	assert.Equal(t, ErrCodeSSHTimeout, result.Code)
+	assert.Equal(t, errors.IDSSHTimeout, result.ID)
 	assert.NotEmpty(t, result.Suggestion)
 	assert.NotNil(t, result.Details)
 
@@ -327,6 +328,44 @@ func TestErrorToJSON_WrappedProbeError(t *testing.T) {
 
 	require.NotNil(t, result)
 	assert.Equal(t, ErrCodeSSHAuthFailed, result.Code)
+	assert.Equal(t, errors.IDSSHAuth, result.ID)
+}
+
+func TestErrorToJSON_IncludesStableID(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		wantID string
+	}{
+		{
+			name:   "category default",
+			err:    errors.New(errors.ErrSync, "rsync failed", ""),
+			wantID: errors.IDSyncGeneric,
+		},
+		{
+			name:   "explicit ID",
+			err:    errors.New(errors.ErrLock, "timed out", "").WithID(errors.IDLockTimeout),
+			wantID: errors.IDLockTimeout,
+		},
+		{
+			name:   "structured error wrapping probe error",
+			err:    errors.WrapWithCode(&host.ProbeError{SSHAlias: "h", Reason: host.ProbeFailHostKey}, errors.ErrSSH, "Connect failed", ""),
+			wantID: errors.IDSSHHostKey,
+		},
+		{
+			name:   "generic error",
+			err:    fmt.Errorf("boom"),
+			wantID: errors.IDInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ErrorToJSON(tt.err)
+			require.NotNil(t, result)
+			assert.Equal(t, tt.wantID, result.ID)
+		})
+	}
 }
 
 func TestProbeErrorToJSON_AllReasons(t *testing.T) {
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/exec"
+	"github.com/rileyhilliard/rr/internal/ui"
+)
+
+// explainResourceLimits checks whether a failed remote run hit a memory,
+// open file, or process limit and, if so, explains it with the limits the
+// host enforces: below the output in pretty mode, or as a "diagnosis" event
+// in structured mode. The host is only probed when the exit code or output
+// points at a limit. Returns true if the failure was explained.
+func explainResourceLimits(wf *WorkflowContext, exitCode int) bool {
+	if exitCode == 0 || exitCode == 130 || wf.Conn == nil || wf.Conn.IsLocal || wf.Conn.Client == nil {
+		return false
+	}
+	var output string
+	if wf.Output != nil {
+		output = string(wf.Output.Bytes())
+	}
+	if !exec.LooksLimitRelated(exitCode, output) {
+		return false
+	}
+
+	limits, err := exec.ProbeResourceLimits(wf.Conn.Client, &wf.Conn.Host)
+	if err != nil {
+		return false
+	}
+	diagnosis := exec.DiagnoseResourceLimits(exitCode, output, limits)
+	if diagnosis == nil {
+		return false
+	}
+
+	if PrettyMode() {
+		renderLimitDiagnosis(diagnosis)
+	} else {
+		WritePhaseEvent(PhaseEvent{
+			Type:     "diagnosis",
+			Status:   "resource_limit",
+			Host:     wf.Conn.Name,
+			ExitCode: &exitCode,
+			Details: map[string]interface{}{
+				"reason":     diagnosis.Reason,
+				"limits":     diagnosis.Details,
+				"suggestion": diagnosis.Suggestion,
+			},
+		})
+	}
+	return true
+}
+
+// renderLimitDiagnosis prints a resource limit diagnosis after the output.
+func renderLimitDiagnosis(d *exec.LimitDiagnosis) {
+	mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
+
+	fmt.Println()
+	fmt.Printf("%s %s\n", ui.SymbolFail, d.Reason)
+	for _, detail := range d.Details {
+		fmt.Println(mutedStyle.Render("  " + detail))
+	}
+	if d.Suggestion != "" {
+		fmt.Printf("\n%s\n", d.Suggestion)
+	}
+}
@@ -18,7 +18,9 @@ type hostAttempt struct {
 	hostName   string
 	conn       *host.Connection
 	connErr    error
-	lockHolder string // Who holds the lock (if locked)
+	lockHolder string         // Who holds the lock (if locked)
+	lockInfo   *lock.LockInfo // The holder's lock info, if it could be read
+	capacity   string         // What's free, if the host is too reserved for the task
 }
 
 // findAvailableHostResult contains the result of finding an available host.
@@ -60,7 +62,8 @@ func findAvailableHost(ctx *WorkflowContext, opts WorkflowOptions) (*findAvailab
 
 	// Track state for each host
 	attempts := make([]hostAttempt, 0, len(hostNames))
-	var lockedHosts []hostAttempt
+	var lockedHosts, fullHosts []hostAttempt
+	reserve := workflowReserve(ctx, opts)
 
 	// Phase 1: Try each host with non-blocking lock
 	for _, hostName := range hostNames {
@@ -85,6 +88,17 @@ func findAvailableHost(ctx *WorkflowContext, opts WorkflowOptions) (*findAvailab
 			}, nil
 		}
 
+		// Skip hosts other runs have reserved too much of for this task
+		if !reserve.IsZero() {
+			if ok, free := hostHasRoom(conn, lockCfg, reserve); !ok {
+				conn.Close()
+				attempt.capacity = free
+				fullHosts = append(fullHosts, attempt)
+				attempts = append(attempts, attempt)
+				continue
+			}
+		}
+
 		// Skip lock if disabled
 		if !lockCfg.Enabled || opts.SkipLock {
 			return &findAvailableHostResult{
@@ -108,7 +122,12 @@ func findAvailableHost(ctx *WorkflowContext, opts WorkflowOptions) (*findAvailab
 
 		if errors.Is(err, lock.ErrLocked) {
 			// Host is locked, record who holds it and try next
-			attempt.lockHolder = lock.GetLockHolder(conn, lockCfg)
+			if info := lock.GetLockInfo(conn, lockCfg, opts.Command); info != nil {
+				attempt.lockInfo = info
+				attempt.lockHolder = info.String()
+			} else {
+				attempt.lockHolder = lock.GetLockHolder(conn, lockCfg, opts.Command)
+			}
 			lockedHosts = append(lockedHosts, attempt)
 			attempts = append(attempts, attempt)
 			// Keep connection open for potential round-robin
@@ -122,7 +141,7 @@ func findAvailableHost(ctx *WorkflowContext, opts WorkflowOptions) (*findAvailab
 	}
 
 	// Phase 2: All hosts tried - handle "all locked" scenario
-	if len(lockedHosts) > 0 {
+	if len(lockedHosts) > 0 || len(fullHosts) > 0 {
 		// If local_fallback is enabled, go local immediately
 		if ctx.Resolved.Global.Defaults.LocalFallback {
 			// Close all locked host connections
@@ -143,6 +162,12 @@ func findAvailableHost(ctx *WorkflowContext, opts WorkflowOptions) (*findAvailab
 			}, nil
 		}
 
+		// Reservations last as long as their runs, so only locked hosts are
+		// worth waiting on
+		if len(lockedHosts) == 0 {
+			return nil, buildAllHostsFullError(fullHosts, reserve)
+		}
+
 		// Otherwise, round-robin wait for a host to become available
 		return roundRobinWait(ctx, lockedHosts, lockCfg, opts.Command, attempts)
 	}
@@ -153,6 +178,10 @@ func findAvailableHost(ctx *WorkflowContext, opts WorkflowOptions) (*findAvailab
 
 // roundRobinWait cycles through locked hosts until one becomes available or timeout.
 func roundRobinWait(_ *WorkflowContext, lockedHosts []hostAttempt, lockCfg config.LockConfig, command string, allAttempts []hostAttempt) (*findAvailableHostResult, error) {
+	if notifyWhenFree {
+		return nil, watchLockedHosts(lockedHosts, lockCfg, command)
+	}
+
 	waitTimeout := lockCfg.WaitTimeout
 	if waitTimeout <= 0 {
 		waitTimeout = 1 * time.Minute // Default
@@ -167,6 +196,11 @@ func roundRobinWait(_ *WorkflowContext, lockedHosts []hostAttempt, lockCfg confi
 
 	for {
 		elapsed := time.Since(startTime)
+		if spinner != nil {
+			spinner.SetLabel(withCountdown(hostWaitLabel(lockedHosts), waitTimeout-elapsed))
+			spinner.SetDetail(hostWaitOutput(lockedHosts, lockCfg, command))
+		}
+
 		if elapsed >= waitTimeout {
 			if spinner != nil {
 				spinner.Fail()
@@ -227,6 +261,62 @@ func roundRobinWait(_ *WorkflowContext, lockedHosts []hostAttempt, lockCfg confi
 	}
 }
 
+// watchLockedHosts is --notify-when-free when every host is locked: it
+// leaves a watcher for all of them in the background instead of waiting.
+func watchLockedHosts(lockedHosts []hostAttempt, lockCfg config.LockConfig, command string) error {
+	var watches []lockWatch
+	var holders []string
+	for _, a := range lockedHosts {
+		if a.conn == nil {
+			continue
+		}
+		watches = append(watches, lockWatch{host: a.hostName, dir: lock.Dir(lockCfg, a.conn, command)})
+		if a.lockInfo != nil {
+			holders = append(holders, a.hostName+": "+a.lockInfo.Badge())
+		}
+		a.conn.Close()
+	}
+	if len(watches) == 0 {
+		return rrerrors.New(rrerrors.ErrSSH,
+			"All host connections lost while waiting",
+			"Check network connectivity and try again.")
+	}
+	return watchLocksInBackground(watches, lockCfg.Stale, holders)
+}
+
+// hostWaitLabel is the spinner's label while waiting for a locked host,
+// naming who's on the first one: "Waiting for available host (gpu-box:
+// alice is running pytest on project app, 12m elapsed, +1 more busy)".
+func hostWaitLabel(lockedHosts []hostAttempt) string {
+	label := "Waiting for available host"
+	for _, a := range lockedHosts {
+		if a.lockInfo == nil {
+			continue
+		}
+		detail := a.hostName + ": " + a.lockInfo.Badge()
+		if others := len(lockedHosts) - 1; others > 0 {
+			detail += fmt.Sprintf(", +%d more busy", others)
+		}
+		return label + " (" + detail + ")"
+	}
+	return label
+}
+
+// hostWaitOutput is the last line of output shared by the run hostWaitLabel
+// names, or "" if it doesn't share any.
+func hostWaitOutput(lockedHosts []hostAttempt, lockCfg config.LockConfig, command string) string {
+	for _, a := range lockedHosts {
+		if a.lockInfo == nil {
+			continue
+		}
+		if a.conn == nil {
+			return ""
+		}
+		return lock.GetLastOutput(a.conn, lockCfg, command)
+	}
+	return ""
+}
+
 // buildConnectionError builds an error message for when no hosts could connect.
 func buildConnectionError(attempts []hostAttempt) error {
 	if len(attempts) == 0 {
@@ -258,6 +348,10 @@ func buildConnectionError(attempts []hostAttempt) error {
 func buildAllHostsLockedError(lockedHosts []hostAttempt, timeout time.Duration) error {
 	holders := make([]string, 0, len(lockedHosts))
 	for _, a := range lockedHosts {
+		if a.lockInfo != nil {
+			holders = append(holders, fmt.Sprintf("%s (%s)", a.hostName, a.lockInfo.Badge()))
+			continue
+		}
 		holder := a.lockHolder
 		if holder == "" {
 			holder = "unknown"
@@ -267,7 +361,8 @@ func buildAllHostsLockedError(lockedHosts []hostAttempt, timeout time.Duration)
 
 	return rrerrors.New(rrerrors.ErrLock,
 		fmt.Sprintf("All hosts are locked - timed out after %s", timeout),
-		fmt.Sprintf("Locked hosts: %v. Wait for them to finish or use --force-unlock if stale.", holders))
+		fmt.Sprintf("Locked hosts: %v. Wait for them to finish or use --force-unlock if stale.", holders)).
+		WithID(rrerrors.IDLockTimeout)
 }
 
 // setupWorkflowLoadBalanced performs workflow setup with load balancing.
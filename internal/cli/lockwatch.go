@@ -0,0 +1,213 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/rileyhilliard/rr/internal/lock"
+	"github.com/spf13/cobra"
+)
+
+// lockWatchInterval is how often the background watcher checks the locks.
+const lockWatchInterval = 10 * time.Second
+
+// lockWatchLimit is how long the background watcher waits for a lock to
+// free before it gives up quietly.
+const lockWatchLimit = 12 * time.Hour
+
+var lockWatchStale time.Duration
+
+// lockWatchCmd is what --notify-when-free leaves running in the background.
+// It isn't meant to be run by hand, so it's hidden.
+var lockWatchCmd = &cobra.Command{
+	Use:    "lock-watch <host> <lock-dir> [<host> <lock-dir>...]",
+	Short:  "Notify when one of the locks frees (run by --notify-when-free)",
+	Hidden: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 || len(args)%2 != 0 {
+			return fmt.Errorf("expected <host> <lock-dir> pairs, got %d args", len(args))
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return lockWatchCommand(args, lockWatchStale)
+	},
+}
+
+func init() {
+	lockWatchCmd.Flags().DurationVar(&lockWatchStale, "stale", config.DefaultConfig().Lock.Stale,
+		"treat a lock whose holder stopped heartbeating this long ago as free")
+	rootCmd.AddCommand(lockWatchCmd)
+}
+
+// lockWatch is one lock the background watcher waits on.
+type lockWatch struct {
+	host string
+	dir  string
+}
+
+// watchLocksInBackground is --notify-when-free: rather than wait on locks
+// other runs hold, rr leaves a watcher in the background that sends a
+// desktop notification when the first of them frees, and returns an error
+// naming who holds them.
+func watchLocksInBackground(watches []lockWatch, stale time.Duration, holders []string) error {
+	if !canNotifyDesktop() {
+		return errors.New(errors.ErrLock,
+			"Can't send desktop notifications from here, so --notify-when-free can't tell you when the lock frees",
+			notifierHint())
+	}
+
+	args := []string{"lock-watch", "--stale", stale.String()}
+	for _, w := range watches {
+		args = append(args, w.host, w.dir)
+	}
+	if err := startInBackground(args); err != nil {
+		return errors.WrapWithCode(err, errors.ErrLock,
+			"Couldn't start watching the lock in the background",
+			"Run without --notify-when-free to wait for it instead.")
+	}
+
+	names := make([]string, len(watches))
+	for i, w := range watches {
+		names[i] = w.host
+	}
+	msg := fmt.Sprintf("%s is locked", names[0])
+	if len(names) > 1 {
+		msg = fmt.Sprintf("All hosts are locked (%s)", strings.Join(names, ", "))
+	}
+	if len(holders) > 0 {
+		msg += ": " + strings.Join(holders, "; ")
+	}
+	return errors.New(errors.ErrLock, msg,
+		"You'll get a desktop notification when it frees - run this again then.")
+}
+
+// startInBackground starts rr with args, detached so it outlives this run
+// and the terminal. Swappable for tests.
+var startInBackground = func(args []string) error {
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(self, args...)
+	detachProcess(cmd)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	return cmd.Process.Release()
+}
+
+// connectForLockWatch connects to a host for the lock watcher, trying each
+// SSH alias in order. Swappable for tests.
+var connectForLockWatch = func(name string, h config.Host) (*host.Connection, error) {
+	h, err := config.ResolveHostSecrets(name, h)
+	if err != nil {
+		return nil, err
+	}
+	var lastErr error
+	for _, alias := range h.SSH {
+		client, _, err := host.Connect(alias, 10*time.Second, h)
+		if err == nil {
+			return &host.Connection{Name: name, Alias: alias, Client: client, Host: h}, nil
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("host '%s' has no SSH aliases", name)
+	}
+	return nil, lastErr
+}
+
+// lockWatchCommand waits for the first of the locks named in args, as host
+// and lock dir pairs, to free, then sends a desktop notification. A host it
+// can't reach is retried each time round rather than taken for free.
+func lockWatchCommand(args []string, stale time.Duration) error {
+	global, err := config.LoadGlobal()
+	if err != nil {
+		return err
+	}
+
+	conns := make(map[string]*host.Connection)
+	defer func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}()
+
+	deadline := time.Now().Add(lockWatchLimit)
+	for time.Now().Before(deadline) {
+		for i := 0; i+1 < len(args); i += 2 {
+			name, dir := args[i], args[i+1]
+			conn := conns[name]
+			if conn == nil {
+				h, ok := global.Hosts[name]
+				if !ok {
+					return fmt.Errorf("host '%s' not found", name)
+				}
+				if conn, err = connectForLockWatch(name, h); err != nil {
+					continue
+				}
+				conns[name] = conn
+			}
+
+			held, err := lock.CheckHeld(conn, dir, stale)
+			if err != nil {
+				conn.Close()
+				delete(conns, name)
+				continue
+			}
+			if !held {
+				return notifyDesktop("rr: "+name+" is free", "The lock you were waiting on was released. Run your command again.")
+			}
+		}
+		lockWatchSleep(lockWatchInterval)
+	}
+	return nil
+}
+
+// lockWatchSleep waits between checks. Swappable for tests.
+var lockWatchSleep = time.Sleep
+
+// canNotifyDesktop reports whether notifyDesktop has something to notify
+// with: osascript on macOS, notify-send elsewhere. Swappable for tests.
+var canNotifyDesktop = func() bool {
+	_, err := exec.LookPath(notifierCommand())
+	return err == nil
+}
+
+// notifierCommand is what sends desktop notifications on this OS.
+func notifierCommand() string {
+	if runtime.GOOS == "darwin" {
+		return "osascript"
+	}
+	return "notify-send"
+}
+
+// notifierHint says how to get desktop notifications working.
+func notifierHint() string {
+	if runtime.GOOS == "darwin" {
+		return "rr uses osascript for notifications, which should be on every Mac - check your PATH."
+	}
+	return "Install notify-send (libnotify-bin on Debian and Ubuntu, libnotify on Fedora and Arch), or run without --notify-when-free to wait for the lock."
+}
+
+// notifyDesktop shows a desktop notification. Swappable for tests.
+var notifyDesktop = func(title, message string) error {
+	if runtime.GOOS == "darwin" {
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+		return exec.Command("osascript", "-e", script).Run()
+	}
+	return exec.Command("notify-send", "--app-name=rr", title, message).Run()
+}
+
+// appleScriptString quotes s as an AppleScript string literal.
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
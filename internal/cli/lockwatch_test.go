@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/rileyhilliard/rr/internal/lock"
+	sshtesting "github.com/rileyhilliard/rr/pkg/sshutil/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stubLockWatch(t *testing.T, canNotify bool) *[]string {
+	origCan, origStart := canNotifyDesktop, startInBackground
+	t.Cleanup(func() { canNotifyDesktop, startInBackground = origCan, origStart })
+
+	var started []string
+	canNotifyDesktop = func() bool { return canNotify }
+	startInBackground = func(args []string) error {
+		started = args
+		return nil
+	}
+	return &started
+}
+
+func TestWatchLocksInBackground(t *testing.T) {
+	started := stubLockWatch(t, true)
+
+	err := watchLocksInBackground([]lockWatch{{host: "gpu-box", dir: "/tmp/rr.lock"}}, 90*time.Second,
+		[]string{"alice is running pytest, 12m elapsed"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "gpu-box is locked: alice is running pytest, 12m elapsed")
+	assert.Contains(t, err.Error(), "desktop notification")
+	assert.Equal(t, []string{"lock-watch", "--stale", "1m30s", "gpu-box", "/tmp/rr.lock"}, *started)
+}
+
+func TestWatchLocksInBackground_AllHosts(t *testing.T) {
+	started := stubLockWatch(t, true)
+
+	err := watchLocksInBackground([]lockWatch{{"mini", "/tmp/rr.lock"}, {"gpu-box", "/tmp/rr.lock"}}, time.Minute, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "All hosts are locked (mini, gpu-box)")
+	assert.Equal(t, []string{"lock-watch", "--stale", "1m0s", "mini", "/tmp/rr.lock", "gpu-box", "/tmp/rr.lock"}, *started)
+}
+
+func TestWatchLocksInBackground_NoNotifier(t *testing.T) {
+	started := stubLockWatch(t, false)
+
+	err := watchLocksInBackground([]lockWatch{{host: "gpu-box", dir: "/tmp/rr.lock"}}, time.Minute, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Can't send desktop notifications")
+	assert.Nil(t, *started, "no watcher that can't notify")
+}
+
+func TestLockWatchCommand(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	require.NoError(t, config.SaveGlobal(&config.GlobalConfig{
+		Hosts: map[string]config.Host{"gpu-box": {SSH: []string{"gpu.local"}}},
+	}))
+
+	mock := sshtesting.NewMockClient("gpu-box")
+	mock.GetFS().Mkdir("/tmp/rr.lock")
+	infoJSON, _ := (&lock.LockInfo{User: "alice", Started: time.Now()}).Marshal()
+	mock.GetFS().WriteFile("/tmp/rr.lock/info.json", infoJSON)
+
+	origConnect, origSleep, origNotify := connectForLockWatch, lockWatchSleep, notifyDesktop
+	t.Cleanup(func() { connectForLockWatch, lockWatchSleep, notifyDesktop = origConnect, origSleep, origNotify })
+	connectForLockWatch = func(name string, h config.Host) (*host.Connection, error) {
+		return &host.Connection{Name: name, Client: mock, Host: h}, nil
+	}
+	checks := 0
+	lockWatchSleep = func(time.Duration) {
+		checks++
+		if checks == 2 {
+			mock.GetFS().Remove("/tmp/rr.lock")
+		}
+	}
+	var title string
+	notifyDesktop = func(t, _ string) error {
+		title = t
+		return nil
+	}
+
+	require.NoError(t, lockWatchCommand([]string{"gpu-box", "/tmp/rr.lock"}, time.Minute))
+	assert.Equal(t, 2, checks, "waits while the lock is held")
+	assert.Equal(t, "rr: gpu-box is free", title)
+}
+
+func TestAppleScriptString(t *testing.T) {
+	assert.Equal(t, `"say \"hi\" \\ bye"`, appleScriptString(`say "hi" \ bye`))
+}
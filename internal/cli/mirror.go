@@ -0,0 +1,147 @@
+package cli
+
+import (
+	stderrors "errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/rileyhilliard/rr/internal/lock"
+	"github.com/rileyhilliard/rr/internal/logger"
+	rrsync "github.com/rileyhilliard/rr/internal/sync"
+	"github.com/rileyhilliard/rr/internal/ui"
+)
+
+// mirrorRun copies a sync to the project's mirror_to host in the background.
+type mirrorRun struct {
+	Host     string
+	done     chan struct{}
+	skipped  string // Why the mirror was skipped, if it was
+	err      error
+	duration time.Duration
+}
+
+// connectMirror connects to the mirror host. Swappable for tests.
+var connectMirror = func(name string, h config.Host) (*host.Connection, error) {
+	return host.QuickSelect(map[string]config.Host{name: h}, name)
+}
+
+// mirrorTarget returns the host a sync to synced is mirrored to, or "" if
+// the project doesn't mirror, or mirrors to the host it just synced to.
+func mirrorTarget(resolved *config.ResolvedConfig, synced string) string {
+	if resolved == nil || resolved.Project == nil || resolved.Global == nil {
+		return ""
+	}
+	name := resolved.Project.MirrorTo
+	if name == "" || name == synced {
+		return ""
+	}
+	if _, ok := resolved.Global.Hosts[name]; !ok {
+		return ""
+	}
+	return name
+}
+
+// startMirror starts copying workDir to the project's mirror_to host with
+// cfg, the settings the sync to synced used. Returns nil if there's no
+// mirror to sync.
+func startMirror(resolved *config.ResolvedConfig, synced, workDir string, cfg config.SyncConfig, force bool) *mirrorRun {
+	name := mirrorTarget(resolved, synced)
+	if name == "" {
+		return nil
+	}
+	lockCfg := resolved.Project.Lock
+
+	m := &mirrorRun{Host: name, done: make(chan struct{})}
+	logger.Verbosef(logger.LevelPhases, "mirror", "mirroring the sync to %s in the background", name)
+	go func() {
+		defer close(m.done)
+		start := time.Now()
+		m.skipped, m.err = mirrorSync(name, resolved.Global.Hosts[name], workDir, cfg, lockCfg, force)
+		m.duration = time.Since(start)
+	}()
+	return m
+}
+
+// Wait blocks until the mirror finishes.
+func (m *mirrorRun) Wait() {
+	<-m.done
+}
+
+// mirrorSync syncs workDir to the mirror host, the way the sync to the
+// primary did. A mirror someone else holds the lock on is skipped rather
+// than waited for, since it'll get the next sync. Returns why it skipped,
+// if it did.
+func mirrorSync(name string, h config.Host, workDir string, cfg config.SyncConfig, lockCfg config.LockConfig, force bool) (string, error) {
+	conn, err := connectMirror(name, h)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	if lockCfg.Enabled {
+		lck, err := lock.TryAcquire(conn, lockCfg, "sync")
+		if stderrors.Is(err, lock.ErrLocked) {
+			return "busy", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		defer lck.Release() //nolint:errcheck // Lock release errors are non-fatal
+	}
+
+	// Don't overwrite edits made directly in a git checkout on the mirror
+	if !force {
+		if err := rrsync.CheckRemoteEdits(conn, workDir, cfg); err != nil {
+			return "", err
+		}
+	}
+
+	// The mirror's clock can be off even when the primary's isn't
+	if skew, err := rrsync.MeasureClockSkew(conn.Client); err == nil {
+		cfg = rrsync.ChecksumForSkew(cfg, skew)
+	}
+
+	return "", rrsync.Sync(conn, workDir, cfg, nil)
+}
+
+// reportMirror waits for a mirror and reports how it went: a line on
+// stderr in pretty mode, a "mirror" phase event in structured mode. A
+// failed mirror never fails the run; the next sync catches it up.
+func reportMirror(m *mirrorRun) {
+	if m == nil {
+		return
+	}
+	m.Wait()
+
+	if !PrettyMode() {
+		event := PhaseEvent{Type: "phase", Phase: "mirror", Host: m.Host}
+		switch {
+		case m.err != nil:
+			event.Status = "failed"
+			event.Error = m.err.Error()
+		case m.skipped != "":
+			event.Status = "skipped"
+			event.Details = map[string]interface{}{"reason": m.skipped}
+		default:
+			event.Status = "complete"
+			event.Duration = m.duration.Seconds()
+		}
+		WritePhaseEvent(event)
+		return
+	}
+
+	mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
+	switch {
+	case m.err != nil:
+		ui.PrintWarning(fmt.Sprintf("Couldn't mirror to %s: %v", m.Host, m.err))
+	case m.skipped == "busy":
+		fmt.Fprintln(os.Stderr, mutedStyle.Render(fmt.Sprintf("  Skipped mirroring to %s: another run holds its lock", m.Host)))
+	default:
+		fmt.Fprintf(os.Stderr, "%s Mirrored to %s %s\n", ui.SymbolSuccess, m.Host,
+			mutedStyle.Render(fmt.Sprintf("(%.1fs)", m.duration.Seconds())))
+	}
+}
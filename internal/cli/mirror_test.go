@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMirrorTarget(t *testing.T) {
+	resolved := &config.ResolvedConfig{
+		Global: &config.GlobalConfig{Hosts: map[string]config.Host{
+			"mini":  {SSH: []string{"mini"}},
+			"spare": {SSH: []string{"spare"}},
+		}},
+		Project: &config.Config{MirrorTo: "spare"},
+	}
+
+	assert.Equal(t, "spare", mirrorTarget(resolved, "mini"))
+	assert.Empty(t, mirrorTarget(resolved, "spare"), "doesn't mirror a host to itself")
+
+	resolved.Project.MirrorTo = ""
+	assert.Empty(t, mirrorTarget(resolved, "mini"))
+	assert.Empty(t, mirrorTarget(&config.ResolvedConfig{Global: resolved.Global}, "mini"))
+}
+
+func TestStartMirror_ConnectFails(t *testing.T) {
+	orig := connectMirror
+	t.Cleanup(func() { connectMirror = orig })
+	connectMirror = func(name string, _ config.Host) (*host.Connection, error) {
+		return nil, fmt.Errorf("%s is down", name)
+	}
+
+	resolved := &config.ResolvedConfig{
+		Global: &config.GlobalConfig{Hosts: map[string]config.Host{
+			"mini":  {SSH: []string{"mini"}},
+			"spare": {SSH: []string{"spare"}},
+		}},
+		Project: &config.Config{MirrorTo: "spare"},
+	}
+
+	m := startMirror(resolved, "mini", t.TempDir(), config.SyncConfig{}, false)
+	require.NotNil(t, m)
+	m.Wait()
+	assert.Equal(t, "spare", m.Host)
+	assert.EqualError(t, m.err, "spare is down")
+	assert.Empty(t, m.skipped)
+
+	assert.Nil(t, startMirror(resolved, "spare", t.TempDir(), config.SyncConfig{}, false))
+}
@@ -1,31 +1,174 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"os"
+	"os/signal"
 	"sort"
 	"strings"
+	"syscall"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/rileyhilliard/rr/internal/config"
 	"github.com/rileyhilliard/rr/internal/errors"
 	"github.com/rileyhilliard/rr/internal/monitor"
+	"github.com/rileyhilliard/rr/internal/ui"
 )
 
 // monitorCommand starts the TUI monitoring dashboard.
 func monitorCommand(hostsFilter string, interval time.Duration) error {
-	// Load resolved config to get proper host ordering
-	resolved, err := config.LoadResolved("")
+	collector, hostOrder, timeout, err := newMonitorCollector(hostsFilter)
+	if err != nil {
+		return err
+	}
+
+	// Create Bubble Tea model with host order for default sorting
+	model := monitor.NewModel(collector, interval, timeout, hostOrder)
+	cards, configPath := monitorCardsConfig()
+	model.SetCards(cards)
+
+	// Verbose lines on stderr would draw over the dashboard
+	verbosePath, restoreVerbose := verboseToFile("monitor")
+
+	// Run the TUI program with mouse support for scrolling
+	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	final, err := p.Run()
+
+	// Graceful shutdown: close all SSH connections
+	collector.Close()
+	restoreVerbose()
+	if verbosePath != "" {
+		fmt.Fprintf(os.Stderr, "Verbose output written to %s\n", verbosePath)
+	}
+
+	// Saved once the dashboard is gone, so the config diff doesn't draw over it
+	if m, ok := final.(monitor.Model); ok {
+		if cards, changed := m.Cards(); changed {
+			saveMonitorCards(os.Stderr, cards, configPath)
+		}
+	}
+
+	return err
+}
+
+// monitorCardsConfig returns the project config's monitor.cards and the
+// path to save a changed card layout to, which is empty without a project
+// config.
+func monitorCardsConfig() ([]string, string) {
+	path, err := config.Find("")
+	if err != nil || path == "" {
+		return nil, ""
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, path
+	}
+	return cfg.Monitor.Cards, path
+}
+
+// saveMonitorCards writes a card layout changed in the dashboard to
+// monitor.cards in the project config at configPath, or says how to
+// without one.
+func saveMonitorCards(w io.Writer, cards []string, configPath string) {
+	list := "[" + strings.Join(cards, ", ") + "]"
+	if configPath == "" {
+		fmt.Fprintf(w, "%s No .rr.yaml to save the card layout in. To keep it, add to one:\n  monitor:\n    cards: %s\n", ui.SymbolWarning, list)
+		return
+	}
+	if err := config.SetMonitorCards(configPath, cards); err != nil {
+		fmt.Fprintf(w, "%s Couldn't save the card layout to %s: %v\n", ui.SymbolWarning, configPath, err)
+		return
+	}
+	fmt.Fprintf(w, "%s Saved the card layout to %s (monitor.cards: %s)\n", ui.SymbolSuccess, configPath, list)
+}
+
+// accessibleMonitorInterval is how often the accessible monitor prints a
+// new table when --interval isn't given. Each refresh is a whole table of
+// new lines, so it's slower than the dashboard's.
+const accessibleMonitorInterval = 10 * time.Second
+
+// monitorTextCommand is the monitor for accessible mode: instead of the
+// dashboard, it prints a plain text table of every host each interval until
+// interrupted.
+func monitorTextCommand(hostsFilter string, interval time.Duration) error {
+	collector, hostOrder, timeout, err := newMonitorCollector(hostsFilter)
+	if err != nil {
+		return err
+	}
+	defer collector.Close()
+	collector.SetTimeout(timeout)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	for {
+		rows := collector.Snapshot(ctx, hostOrder)
+		if ctx.Err() != nil {
+			return nil
+		}
+		fmt.Printf("Hosts at %s\n", time.Now().Format("15:04:05"))
+		if err := monitor.WriteSnapshotTable(os.Stdout, rows); err != nil {
+			return err
+		}
+		fmt.Println()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// monitorSnapshotCommand collects one round of metrics and prints it in
+// format: table, json, or csv.
+func monitorSnapshotCommand(hostsFilter, format string) error {
+	var write func(io.Writer, []monitor.SnapshotHost) error
+	switch format {
+	case "table", "":
+		write = monitor.WriteSnapshotTable
+	case "csv":
+		write = monitor.WriteSnapshotCSV
+	case "json":
+		write = func(w io.Writer, rows []monitor.SnapshotHost) error {
+			return WriteJSONSuccess(w, rows)
+		}
+	default:
+		return errors.New(errors.ErrConfig,
+			fmt.Sprintf("'%s' isn't a snapshot format", format),
+			"Use --format table, json, or csv.")
+	}
+
+	collector, hostOrder, timeout, err := newMonitorCollector(hostsFilter)
 	if err != nil {
 		return err
 	}
+	defer collector.Close()
+	collector.SetTimeout(timeout)
+
+	rows := collector.Snapshot(context.Background(), hostOrder)
+	return write(os.Stdout, rows)
+}
+
+// newMonitorCollector resolves the hosts to monitor, filtered by hostsFilter,
+// and returns a collector for them, the hosts in display order, and the
+// per-host collection timeout.
+func newMonitorCollector(hostsFilter string) (*monitor.Collector, []string, time.Duration, error) {
+	// Load resolved config to get proper host ordering
+	resolved, err := loadResolved("")
+	if err != nil {
+		return nil, nil, 0, err
+	}
 
 	// Get hosts with proper priority order (project hosts list order, or alphabetical for global)
 	hostOrder, hosts, err := config.ResolveHosts(resolved, "")
 	if err != nil {
 		// Fall back to just global hosts if resolution fails
 		if len(resolved.Global.Hosts) == 0 {
-			return errors.New(errors.ErrConfig,
+			return nil, nil, 0, errors.New(errors.ErrConfig,
 				"No hosts configured",
 				"Add a host with 'rr host add' first.")
 		}
@@ -41,7 +184,7 @@ func monitorCommand(hostsFilter string, interval time.Duration) error {
 	if hostsFilter != "" {
 		hosts = filterHosts(hosts, hostsFilter)
 		if len(hosts) == 0 {
-			return errors.New(errors.ErrConfig,
+			return nil, nil, 0, errors.New(errors.ErrConfig,
 				fmt.Sprintf("No hosts match '%s'", hostsFilter),
 				"Double-check your host names or try without the --hosts filter.")
 		}
@@ -50,7 +193,7 @@ func monitorCommand(hostsFilter string, interval time.Duration) error {
 	}
 
 	if len(hosts) == 0 {
-		return errors.New(errors.ErrConfig,
+		return nil, nil, 0, errors.New(errors.ErrConfig,
 			"No hosts configured",
 			"Add a host with 'rr host add' first.")
 	}
@@ -70,18 +213,11 @@ func monitorCommand(hostsFilter string, interval time.Duration) error {
 	if resolved.Project != nil && resolved.Project.Lock.Enabled {
 		collector.SetLockConfig(resolved.Project.Lock)
 	}
+	if resolved.Project != nil {
+		collector.SetConcurrency(resolved.Project.Monitor.Concurrency)
+	}
 
-	// Create Bubble Tea model with host order for default sorting
-	model := monitor.NewModel(collector, interval, timeout, hostOrder)
-
-	// Run the TUI program with mouse support for scrolling
-	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
-	_, err = p.Run()
-
-	// Graceful shutdown: close all SSH connections
-	collector.Close()
-
-	return err
+	return collector, hostOrder, timeout, nil
 }
 
 // filterHostOrder filters the host order list to only include hosts that exist in the hosts map.
@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveMonitorCards(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), ".rr.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("version: 1\nmonitor:\n  interval: 2s\n"), 0644))
+
+	var out bytes.Buffer
+	saveMonitorCards(&out, []string{"gpu", "cpu"}, path)
+	assert.Contains(t, out.String(), "Saved the card layout")
+
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gpu", "cpu"}, cfg.Monitor.Cards)
+	assert.Equal(t, "2s", cfg.Monitor.Interval)
+}
+
+func TestSaveMonitorCards_NoProjectConfig(t *testing.T) {
+	var out bytes.Buffer
+	saveMonitorCards(&out, []string{"gpu", "cpu"}, "")
+	assert.Contains(t, out.String(), "No .rr.yaml")
+	assert.Contains(t, out.String(), "cards: [gpu, cpu]")
+}
@@ -0,0 +1,349 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/doctor"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/ui"
+)
+
+// onboardSteps is the number of numbered steps the wizard walks through.
+const onboardSteps = 5
+
+// OnboardOptions holds options for the onboard command.
+type OnboardOptions struct {
+	Host           string // Pre-specified SSH host/alias (for non-interactive)
+	Name           string // Friendly name for the host (for non-interactive)
+	Dir            string // Pre-specified remote directory (for non-interactive)
+	Overwrite      bool   // Overwrite an existing .rr.yaml without asking
+	NonInteractive bool   // Skip prompts, use flags and defaults
+	SkipSmokeTest  bool   // Don't run 'echo ok' on the host at the end
+}
+
+// Onboard is the first-run wizard. It combines init, setup, and doctor:
+// pick or import a host, get passwordless SSH working, write the global and
+// project configs, check everything, run a test command, and print a cheat
+// sheet of the commands worth knowing.
+func Onboard(opts OnboardOptions) error {
+	fmt.Println("Welcome to rr! This gets you from nothing to running commands on a remote machine.")
+	fmt.Println()
+
+	globalCfg, err := config.LoadGlobal()
+	if err != nil {
+		return err
+	}
+
+	// Step 1: choose existing hosts or describe a new one
+	printOnboardStep(1, "Pick a host")
+	hostRefs, machine, err := onboardHosts(opts, globalCfg)
+	if err != nil {
+		return err
+	}
+	if hostRefs == nil && machine == nil {
+		fmt.Println("Cancelled. Run 'rr onboard' again when you're ready.")
+		return nil
+	}
+
+	// Step 2: make sure each host takes our key without a password
+	printOnboardStep(2, "Set up SSH keys")
+	for _, alias := range onboardAliases(globalCfg, hostRefs, machine) {
+		if err := Setup(SetupOptions{Host: alias, NonInteractive: opts.NonInteractive, Embedded: true}); err != nil {
+			return err
+		}
+	}
+	if machine != nil {
+		// Probe again now that keys are in place, and pick up PATH fixes
+		test := testConnectionInteractive
+		if opts.NonInteractive {
+			test = testConnectionNonInteractive
+		}
+		setupCommands, err := test(machine.sshHosts[0])
+		if err != nil {
+			return err
+		}
+		machine.setupCommands = setupCommands
+	}
+
+	// Step 3: write ~/.rr/config.yaml and .rr.yaml
+	printOnboardStep(3, "Write configs")
+	if machine != nil {
+		name, err := addHostToGlobal(globalCfg, machine)
+		if err != nil {
+			return err
+		}
+		hostRefs = append(hostRefs, name)
+	}
+	configPath := filepath.Join(".", config.ConfigFileName)
+	if err := onboardProjectConfig(configPath, hostRefs, opts); err != nil {
+		return err
+	}
+
+	// Step 4: the same checks 'rr doctor' runs
+	printOnboardStep(4, "Check everything")
+	onboardDoctor(configPath, globalCfg)
+
+	// Step 5: prove a command runs end to end
+	printOnboardStep(5, "Smoke test")
+	if opts.SkipSmokeTest {
+		fmt.Printf("%s Skipped\n\n", ui.SymbolSkipped)
+	} else if err := onboardSmokeTest(hostRefs); err != nil {
+		return err
+	}
+
+	fmt.Print(onboardCheatSheet())
+	return nil
+}
+
+// printOnboardStep prints a numbered step header.
+func printOnboardStep(n int, title string) {
+	style := lipgloss.NewStyle().Bold(true).Foreground(ui.ColorPrimary)
+	fmt.Println(style.Render(fmt.Sprintf("Step %d/%d: %s", n, onboardSteps, title)))
+	fmt.Println()
+}
+
+// onboardHosts picks the hosts the project will use. It returns the names of
+// existing global hosts to use, and a new host to add (not yet saved, since
+// its keys haven't been checked). Both are nil if the user cancelled.
+func onboardHosts(opts OnboardOptions, globalCfg *config.GlobalConfig) ([]string, *machineConfig, error) {
+	if opts.NonInteractive {
+		return onboardHostsNonInteractive(opts, globalCfg)
+	}
+
+	if len(globalCfg.Hosts) > 0 {
+		selected, err := promptHostsSelection(globalCfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(selected) > 0 {
+			if !hasUnaddedSSHHosts(globalCfg) {
+				return selected, nil, nil
+			}
+			addMore, err := promptAddMoreHosts()
+			if err != nil {
+				return nil, nil, err
+			}
+			if !addMore {
+				return selected, nil, nil
+			}
+		}
+
+		machine, cancelled, err := collectMachineConfig(getExistingGlobalHostSSHAliases(globalCfg), true)
+		if err != nil {
+			return nil, nil, err
+		}
+		if cancelled {
+			if len(selected) == 0 {
+				return nil, nil, nil
+			}
+			return selected, nil, nil
+		}
+		return selected, machine, nil
+	}
+
+	// The picker lists hosts from ~/.ssh/config, so picking one imports it.
+	// The connection test is left to the SSH keys step.
+	machine, cancelled, err := collectMachineConfig(nil, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cancelled {
+		return nil, nil, nil
+	}
+	return []string{}, machine, nil
+}
+
+// onboardHostsNonInteractive is onboardHosts driven by --host and friends.
+// Without --host, every host in the global config is used.
+func onboardHostsNonInteractive(opts OnboardOptions, globalCfg *config.GlobalConfig) ([]string, *machineConfig, error) {
+	if opts.Host == "" {
+		if len(globalCfg.Hosts) == 0 {
+			return nil, nil, errors.New(errors.ErrConfig,
+				"Which host should rr use?",
+				"Pass one with --host (e.g., rr onboard --non-interactive --host user@myserver), or set RR_HOST.")
+		}
+		names := make([]string, 0, len(globalCfg.Hosts))
+		for name := range globalCfg.Hosts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names, nil, nil
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = extractHostname(opts.Host)
+	}
+	if _, exists := globalCfg.Hosts[name]; exists {
+		return []string{name}, nil, nil
+	}
+
+	machine := &machineConfig{
+		name:      name,
+		sshHosts:  []string{opts.Host},
+		remoteDir: opts.Dir,
+	}
+	if machine.remoteDir == "" {
+		machine.remoteDir = "~/rr/${PROJECT}"
+	}
+	return []string{}, machine, nil
+}
+
+// onboardAliases returns the SSH alias to set up keys for on each host: the
+// first connection of each existing host, then the new host's.
+func onboardAliases(globalCfg *config.GlobalConfig, hostRefs []string, machine *machineConfig) []string {
+	var aliases []string
+	for _, name := range hostRefs {
+		if h, ok := globalCfg.Hosts[name]; ok && len(h.SSH) > 0 {
+			aliases = append(aliases, h.SSH[0])
+		}
+	}
+	if machine != nil {
+		aliases = append(aliases, machine.sshHosts[0])
+	}
+	return aliases
+}
+
+// onboardProjectConfig writes .rr.yaml for the chosen hosts. An existing file
+// is kept unless the user agrees to replace it (or --force was given), since
+// onboarding may be re-run in a project that's already set up.
+func onboardProjectConfig(configPath string, hostRefs []string, opts OnboardOptions) error {
+	if _, err := os.Stat(configPath); err == nil && !opts.Overwrite {
+		replace := false
+		if !opts.NonInteractive {
+			form := huh.NewForm(
+				huh.NewGroup(
+					huh.NewConfirm().
+						Title(fmt.Sprintf("'%s' already exists. Replace it?", config.ConfigFileName)).
+						Description("Say no to keep your current project settings").
+						Value(&replace),
+				),
+			)
+			if err := form.Run(); err != nil {
+				return errors.WrapWithCode(err, errors.ErrConfig,
+					"Couldn't get your input",
+					"Your terminal might not support the prompts. Try --non-interactive mode instead.")
+			}
+		}
+		if !replace {
+			fmt.Printf("%s Keeping existing %s\n\n", ui.SymbolSuccess, configPath)
+			return nil
+		}
+	}
+
+	return saveProjectConfig(configPath, &projectConfigValues{hostRefs: hostRefs})
+}
+
+// onboardDoctor runs the standard doctor checks and prints only what needs
+// attention. Problems are reported, not fatal: the smoke test is the real
+// verdict, and 'rr doctor' has the full picture.
+func onboardDoctor(configPath string, globalCfg *config.GlobalConfig) {
+	spinner := ui.NewSpinner("Running checks")
+	spinner.Start()
+	results := doctor.RunAll(collectChecks(configPath, nil, globalCfg))
+
+	var issues []doctor.CheckResult
+	for _, r := range results {
+		if r.Status != doctor.StatusPass {
+			issues = append(issues, r)
+		}
+	}
+	if len(issues) == 0 {
+		spinner.Success()
+		fmt.Printf("%s All %d checks passed\n\n", ui.SymbolSuccess, len(results))
+		return
+	}
+	spinner.Fail()
+
+	mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
+	for _, r := range issues {
+		symbol := ui.SymbolWarning
+		if r.Status == doctor.StatusFail {
+			symbol = ui.SymbolFail
+		}
+		fmt.Printf("%s %s\n", symbol, r.Message)
+		if r.Suggestion != "" {
+			fmt.Println(mutedStyle.Render("  " + r.Suggestion))
+		}
+	}
+	fmt.Println()
+	fmt.Println(mutedStyle.Render("Run 'rr doctor' for details, or 'rr doctor --fix' to fix what can be fixed."))
+	fmt.Println()
+}
+
+// onboardSmokeTest runs 'echo ok' on the first project host, like
+// 'rr exec echo ok' would. It doesn't sync or take the lock, so a busy or
+// large project doesn't slow it down.
+func onboardSmokeTest(hostRefs []string) error {
+	var preferred string
+	if len(hostRefs) > 0 {
+		preferred = hostRefs[0]
+	}
+
+	exitCode, err := Run(RunOptions{
+		Command:          "echo ok",
+		Host:             preferred,
+		SkipSync:         true,
+		SkipLock:         true,
+		SkipRequirements: true,
+		Quiet:            true,
+		NoSummary:        true,
+	})
+	if err == nil && exitCode != 0 {
+		err = errors.NewExitError(exitCode)
+	}
+	if err != nil {
+		fmt.Printf("\n%s 'rr exec echo ok' didn't work\n", ui.SymbolFail)
+		fmt.Println("Your configs are saved. Run 'rr doctor' to see what's wrong, then try 'rr exec echo ok' again.")
+		fmt.Println()
+		return err
+	}
+
+	fmt.Printf("\n%s Commands run on your host. You're all set.\n\n", ui.SymbolSuccess)
+	return nil
+}
+
+// onboardCheatSheet returns the commands a new user reaches for first.
+func onboardCheatSheet() string {
+	commands := [][2]string{
+		{"rr run <cmd>", "Sync files, then run a command remotely"},
+		{"rr exec <cmd>", "Run a command without syncing"},
+		{"rr sync", "Just sync files"},
+		{"rr <task>", "Run a task from .rr.yaml (see 'rr tasks')"},
+		{"rr status", "Show hosts, sync and lock state"},
+		{"rr monitor", "Live CPU/RAM/GPU dashboard for your hosts"},
+		{"rr host add", "Add another machine"},
+		{"rr doctor", "Diagnose connection and config problems"},
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Cheat sheet:\n")
+	for _, c := range commands {
+		fmt.Fprintf(&sb, "  %-15s %s\n", c[0], c[1])
+	}
+	sb.WriteString("\nAdd tasks to .rr.yaml to save commands you run often. Docs: https://github.com/rileyhilliard/rr\n")
+	return sb.String()
+}
+
+// onboardCommand is the implementation called by the cobra command.
+func onboardCommand(opts OnboardOptions) error {
+	// Same environment variables as 'rr init'
+	merged := mergeInitOptions(InitOptions{
+		Host:           opts.Host,
+		Name:           opts.Name,
+		Dir:            opts.Dir,
+		NonInteractive: opts.NonInteractive,
+	})
+	opts.Host = merged.Host
+	opts.Name = merged.Name
+	opts.Dir = merged.Dir
+	opts.NonInteractive = merged.NonInteractive
+	return Onboard(opts)
+}
@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnboardHostsNonInteractive(t *testing.T) {
+	globalCfg := &config.GlobalConfig{Hosts: map[string]config.Host{
+		"mini":   {SSH: []string{"mini-local", "mini-ts"}},
+		"server": {SSH: []string{"user@server"}},
+	}}
+
+	t.Run("no host and no global hosts", func(t *testing.T) {
+		_, _, err := onboardHostsNonInteractive(OnboardOptions{NonInteractive: true}, &config.GlobalConfig{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Which host should rr use?")
+	})
+
+	t.Run("no host uses every global host", func(t *testing.T) {
+		refs, machine, err := onboardHostsNonInteractive(OnboardOptions{}, globalCfg)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"mini", "server"}, refs)
+		assert.Nil(t, machine)
+	})
+
+	t.Run("host already in global config", func(t *testing.T) {
+		refs, machine, err := onboardHostsNonInteractive(OnboardOptions{Host: "user@server"}, globalCfg)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"server"}, refs)
+		assert.Nil(t, machine)
+	})
+
+	t.Run("new host", func(t *testing.T) {
+		refs, machine, err := onboardHostsNonInteractive(OnboardOptions{Host: "me@gpu.lan", Name: "gpu"}, globalCfg)
+		require.NoError(t, err)
+		assert.Empty(t, refs)
+		require.NotNil(t, machine)
+		assert.Equal(t, "gpu", machine.name)
+		assert.Equal(t, []string{"me@gpu.lan"}, machine.sshHosts)
+		assert.Equal(t, "~/rr/${PROJECT}", machine.remoteDir)
+	})
+}
+
+func TestOnboardAliases(t *testing.T) {
+	globalCfg := &config.GlobalConfig{Hosts: map[string]config.Host{
+		"mini":   {SSH: []string{"mini-local", "mini-ts"}},
+		"server": {SSH: []string{"user@server"}},
+	}}
+
+	assert.Equal(t, []string{"mini-local"}, onboardAliases(globalCfg, []string{"mini"}, nil))
+	assert.Equal(t, []string{"mini-local", "user@server", "gpu"},
+		onboardAliases(globalCfg, []string{"mini", "server"}, &machineConfig{sshHosts: []string{"gpu"}}))
+}
+
+func TestOnboardProjectConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // Keep the config backups out of the real ~/.rr
+	t.Run("writes a new config", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), ".rr.yaml")
+		require.NoError(t, onboardProjectConfig(configPath, []string{"mini"}, OnboardOptions{NonInteractive: true}))
+
+		content, err := os.ReadFile(configPath)
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "- mini")
+	})
+
+	t.Run("keeps an existing config", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), ".rr.yaml")
+		require.NoError(t, os.WriteFile(configPath, []byte("version: 1\n"), 0644))
+
+		require.NoError(t, onboardProjectConfig(configPath, []string{"mini"}, OnboardOptions{NonInteractive: true}))
+
+		content, err := os.ReadFile(configPath)
+		require.NoError(t, err)
+		assert.Equal(t, "version: 1\n", string(content))
+	})
+
+	t.Run("replaces an existing config with force", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), ".rr.yaml")
+		require.NoError(t, os.WriteFile(configPath, []byte("version: 1\n"), 0644))
+
+		require.NoError(t, onboardProjectConfig(configPath, []string{"mini"}, OnboardOptions{NonInteractive: true, Overwrite: true}))
+
+		content, err := os.ReadFile(configPath)
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "- mini")
+	})
+}
+
+func TestOnboard_NonInteractive_NoHosts(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	require.NoError(t, os.Chdir(tmpDir))
+	t.Setenv("HOME", tmpDir)
+
+	err := Onboard(OnboardOptions{NonInteractive: true})
+	require.Error(t, err)
+
+	// Nothing is written before a host is known
+	_, statErr := os.Stat(filepath.Join(tmpDir, ".rr.yaml"))
+	assert.True(t, os.IsNotExist(statErr))
+}
+
+func TestOnboardCheatSheet(t *testing.T) {
+	sheet := onboardCheatSheet()
+	for _, cmd := range []string{"rr run <cmd>", "rr exec <cmd>", "rr sync", "rr doctor"} {
+		assert.Contains(t, sheet, cmd)
+	}
+}
@@ -9,11 +9,15 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/charmbracelet/lipgloss"
 	"github.com/rileyhilliard/rr/internal/config"
 	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/exec"
 	"github.com/rileyhilliard/rr/internal/output/formatters"
 	"github.com/rileyhilliard/rr/internal/parallel"
 	"github.com/rileyhilliard/rr/internal/parallel/logs"
+	"github.com/rileyhilliard/rr/internal/results"
+	"github.com/rileyhilliard/rr/internal/ui"
 	"github.com/rileyhilliard/rr/internal/util"
 )
 
@@ -32,13 +36,18 @@ type ParallelTaskOptions struct {
 	Local       bool          // Force local execution
 	Timeout     time.Duration // Per-task timeout
 	Args        []string      // Extra args forwarded to subtasks when forward_args is true
+	Diagnostics bool          // Write failure locations to .rr/diagnostics.json
+	Force       bool          // Sync even into a git checkout with uncommitted changes
+	Rebootstrap bool          // Run the project's bootstrap script even if it already ran on the host
+	Cold        bool          // Ignore the task's warm state: sync and run setup again
+	Shard       int           // Run only this shard of a sharded task (1-based)
 }
 
 // RunParallelTask executes a parallel task group.
 // Returns the aggregate exit code and any error.
 func RunParallelTask(opts ParallelTaskOptions) (int, error) {
 	// Load and validate config
-	resolved, err := config.LoadResolved(Config())
+	resolved, err := loadResolved(Config())
 	if err != nil {
 		return 1, err
 	}
@@ -53,24 +62,25 @@ func RunParallelTask(opts ParallelTaskOptions) (int, error) {
 		return 1, err
 	}
 
-	if !config.IsParallelTask(task) {
+	if !config.IsParallelTask(task) && !config.IsShardedTask(task) {
 		return 1, errors.New(errors.ErrConfig,
 			fmt.Sprintf("Task '%s' is not a parallel task", opts.TaskName),
 			"Parallel tasks must have a 'parallel' field with subtask names.")
 	}
 
-	// Flatten nested parallel references into a list of executable tasks
-	flattenedNames, err := config.FlattenParallelTasks(opts.TaskName, resolved.Project.Tasks)
+	flattenedNames, tasks, err := parallelTaskInfos(resolved.Project, opts.TaskName, task, opts.Args)
 	if err != nil {
-		return 1, errors.WrapWithCode(err, errors.ErrConfig,
-			"Failed to flatten parallel tasks",
-			"Check for circular references or missing tasks.")
+		return 1, err
 	}
 
-	// Build TaskInfo for each flattened subtask
-	tasks, err := buildSubtaskInfos(resolved.Project, task, flattenedNames, opts.Args)
-	if err != nil {
-		return 1, err
+	// Noted before --shard narrows the run, to compare a reloaded config with
+	allNames := taskInfoNames(tasks)
+
+	if config.IsShardedTask(task) {
+		tasks, err = selectShards(resolved.ProjectRoot, opts.TaskName, task, tasks, opts.Shard)
+		if err != nil {
+			return 1, err
+		}
 	}
 
 	// Resolve hosts - hostOrder preserves priority from config
@@ -101,7 +111,11 @@ func RunParallelTask(opts ParallelTaskOptions) (int, error) {
 
 	// If dry run, just show the plan
 	if opts.DryRun {
-		renderDryRunPlan(opts.TaskName, task.Parallel, flattenedNames, tasks, hosts, task.Setup, resolved.Project.Tasks)
+		originalRefs := task.Parallel
+		if config.IsShardedTask(task) {
+			originalRefs = flattenedNames
+		}
+		renderDryRunPlan(opts.TaskName, originalRefs, flattenedNames, tasks, hosts, task.Setup, resolved.Project.Tasks)
 		return 0, nil
 	}
 
@@ -118,6 +132,12 @@ func RunParallelTask(opts ParallelTaskOptions) (int, error) {
 		OutputMode:  outputMode,
 		SaveLogs:    !opts.NoLogs,
 		Setup:       task.Setup,
+		Force:       opts.Force,
+		Rebootstrap: opts.Rebootstrap,
+		SyncProfile: task.SyncProfile,
+	}
+	if !opts.Cold {
+		parallelCfg.Warm = config.GetTaskWarm(task)
 	}
 
 	// Apply CLI overrides
@@ -163,8 +183,18 @@ func RunParallelTask(opts ParallelTaskOptions) (int, error) {
 		_ = logs.Cleanup(resolved.Global.Logs)
 	}
 
+	// Notice config edits made while the run goes
+	var orchestrator *parallel.Orchestrator
+	configPath, _ := config.Find(Config())
+	reloader := newConfigReloader(configPath, opts.TaskName, task, allNames, opts.Args)
+	if reloader != nil {
+		parallelCfg.Refresh = func(t parallel.TaskInfo) parallel.TaskInfo {
+			return reloader.Refresh(t, orchestrator.GetOutputManager().Warn)
+		}
+	}
+
 	// Create orchestrator with host priority order preserved
-	orchestrator := parallel.NewOrchestrator(tasks, hosts, hostOrder, resolved, parallelCfg)
+	orchestrator = parallel.NewOrchestrator(tasks, hosts, hostOrder, resolved, parallelCfg)
 
 	// Create context with signal handling for graceful cancellation
 	ctx, cancel := context.WithCancel(context.Background())
@@ -183,21 +213,42 @@ func RunParallelTask(opts ParallelTaskOptions) (int, error) {
 	if err != nil {
 		return 1, err
 	}
+	if reloader != nil {
+		if msg := reloader.Check(); msg != "" {
+			fmt.Fprintf(os.Stderr, "%s %s\n", ui.SymbolWarning, msg)
+		}
+	}
+
+	if opts.Diagnostics {
+		writeParallelDiagnostics(resolved.ProjectRoot, opts.TaskName, hosts, result)
+	}
+	saveParallelResults(resolved.ProjectRoot, opts.TaskName, result, logWriter)
 
-	return renderParallelResult(result, logWriter, opts.TaskName), nil
+	return renderParallelResult(result, logWriter, opts.TaskName, config.IsShardedTask(task)), nil
 }
 
-func renderParallelResult(result *parallel.Result, logWriter *logs.LogWriter, taskName string) int {
+func renderParallelResult(result *parallel.Result, logWriter *logs.LogWriter, taskName string, sharded bool) int {
 	if logWriter != nil {
 		writeTaskLogs(logWriter, result, taskName)
 	}
 
+	// Shards each run part of one suite, so their test counts add up to it
+	var tests *results.TestCounts
+	if sharded {
+		tests = mergedTestCounts(result)
+	}
+
 	if PrettyMode() {
 		logDir := ""
 		if logWriter != nil {
 			logDir = logWriter.Dir()
 		}
 		parallel.RenderSummary(result, logDir)
+		if tests != nil {
+			mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
+			fmt.Printf("Tests: %d passed, %d failed, %d skipped %s\n", tests.Passed, tests.Failed+tests.Errors, tests.Skipped,
+				mutedStyle.Render(fmt.Sprintf("across %d shards", len(result.TaskResults))))
+		}
 	} else {
 		exitCode := 0
 		if result.Failed > 0 {
@@ -211,6 +262,9 @@ func renderParallelResult(result *parallel.Result, logWriter *logs.LogWriter, ta
 		if result.Failed > 0 {
 			details["failures"] = extractTaskFailures(result)
 		}
+		if tests != nil {
+			details["tests"] = tests
+		}
 		WritePhaseEvent(PhaseEvent{
 			Type:     "result",
 			Status:   map[bool]string{true: "success", false: "failed"}[result.Failed == 0],
@@ -244,6 +298,9 @@ func extractTaskFailures(result *parallel.Result) []map[string]interface{} {
 		if tr.Error != nil {
 			entry["error"] = tr.Error.Error()
 		}
+		if tr.OutputFile != "" {
+			entry["log_file"] = tr.OutputFile
+		}
 
 		parsed := formatters.ExtractFailures(tr.Command, tr.Output)
 		if len(parsed) > 0 {
@@ -280,23 +337,103 @@ func extractTaskFailures(result *parallel.Result) []map[string]interface{} {
 	return failures
 }
 
+// mergedTestCounts adds up the test counts parsed from each task's output.
+// Returns nil if no task's output had any.
+func mergedTestCounts(result *parallel.Result) *results.TestCounts {
+	var merged *results.TestCounts
+	for i := range result.TaskResults {
+		tr := &result.TaskResults[i]
+		counts := results.CountTests(tr.Command, tr.Output)
+		if counts == nil {
+			continue
+		}
+		if merged == nil {
+			merged = &results.TestCounts{Source: counts.Source}
+		}
+		merged.Passed += counts.Passed
+		merged.Failed += counts.Failed
+		merged.Skipped += counts.Skipped
+		merged.Errors += counts.Errors
+	}
+	return merged
+}
+
+// parallelTaskInfos flattens a parallel or sharded task into the names of
+// the tasks it runs and the TaskInfo of each one to schedule.
+func parallelTaskInfos(proj *config.Config, name string, task *config.TaskConfig, args []string) ([]string, []parallel.TaskInfo, error) {
+	names := []string{name}
+	if config.IsParallelTask(task) {
+		// Flatten nested parallel references into a list of executable tasks
+		var err error
+		names, err = config.FlattenParallelTasks(name, proj.Tasks)
+		if err != nil {
+			return nil, nil, errors.WrapWithCode(err, errors.ErrConfig,
+				"Failed to flatten parallel tasks",
+				"Check for circular references or missing tasks.")
+		}
+	}
+	tasks, err := buildSubtaskInfos(proj, task, names, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	return names, tasks, nil
+}
+
+// selectShards narrows a sharded task's shards to the one --shard picked,
+// or orders them for the duration strategy so the slowest start first.
+func selectShards(projectRoot, name string, task *config.TaskConfig, tasks []parallel.TaskInfo, shard int) ([]parallel.TaskInfo, error) {
+	if shard != 0 {
+		if shard < 1 || shard > len(tasks) {
+			return nil, errors.New(errors.ErrConfig,
+				fmt.Sprintf("Task '%s' has no shard %d", name, shard),
+				fmt.Sprintf("Pick a shard from 1 to %d.", len(tasks)))
+		}
+		return tasks[shard-1 : shard], nil
+	}
+	if config.GetShardStrategy(task) == config.ShardDuration {
+		// With no history yet the shards keep their order
+		runs, _ := results.List(projectRoot)
+		parallel.OrderByDuration(tasks, results.TaskDurations(runs, name, shardDurationSamples))
+	}
+	return tasks, nil
+}
+
+// shardDurationSamples is how many past runs of a sharded task are averaged
+// to estimate each shard's duration.
+const shardDurationSamples = 5
+
 // buildSubtaskInfos constructs the TaskInfo list for each flattened subtask name.
-// When forwardTask.ForwardArgs is true and args are provided, they are appended to
-// each subtask's run command. Multi-step subtasks cannot accept forwarded args.
+// When forwardTask.ForwardArgs is true, or forwardTask is itself sharded, and
+// args are provided, they are appended to each subtask's run command.
+// Multi-step subtasks cannot accept forwarded args. A sharded subtask becomes
+// one TaskInfo per shard. Subtasks without a priority of their own run at
+// forwardTask's.
 func buildSubtaskInfos(proj *config.Config, forwardTask *config.TaskConfig, flattenedNames []string, args []string) ([]parallel.TaskInfo, error) {
+	forward := forwardTask.ForwardArgs || config.IsShardedTask(forwardTask)
 	tasks := make([]parallel.TaskInfo, 0, len(flattenedNames))
-	for i, subtaskName := range flattenedNames {
+	for _, subtaskName := range flattenedNames {
 		subtask, err := config.GetTask(proj, subtaskName)
 		if err != nil {
 			return nil, err
 		}
+		env, err := config.ResolveEnvSecrets(subtask.Env, fmt.Sprintf("task '%s'", subtaskName))
+		if err != nil {
+			return nil, err
+		}
 
 		cmd := subtask.Run
 		if cmd == "" && len(subtask.Steps) > 0 {
-			cmd = buildStepsCommand(subtask.Steps)
+			steps, err := runnableSteps(subtask.Steps, env)
+			if err != nil {
+				return nil, err
+			}
+			if steps, err = exec.TaskSteps(subtask, steps); err != nil {
+				return nil, err
+			}
+			cmd = buildStepsCommand(steps)
 		}
 
-		if forwardTask.ForwardArgs && len(args) > 0 {
+		if forward && len(args) > 0 {
 			if len(subtask.Steps) > 0 {
 				return nil, errors.New(errors.ErrConfig,
 					fmt.Sprintf("subtask '%s' uses steps and cannot accept forwarded args", subtaskName),
@@ -308,13 +445,31 @@ func buildSubtaskInfos(proj *config.Config, forwardTask *config.TaskConfig, flat
 			}
 			cmd = cmd + " " + strings.Join(quoted, " ")
 		}
+		if subtask.Run != "" {
+			if cmd, err = exec.TaskCommand(subtask, cmd); err != nil {
+				return nil, err
+			}
+		}
+		priority := subtask.Priority
+		if priority == "" {
+			priority = forwardTask.Priority
+		}
+		cmd = exec.WithPriority(priority, cmd)
 
-		tasks = append(tasks, parallel.TaskInfo{
+		info := parallel.TaskInfo{
 			Name:    subtaskName,
-			Index:   i,
+			Index:   len(tasks),
 			Command: cmd,
-			Env:     subtask.Env,
-		})
+			Env:     env,
+		}
+		if !config.IsShardedTask(subtask) {
+			tasks = append(tasks, info)
+			continue
+		}
+		for _, shard := range parallel.ShardTasks(info, subtask.Shard.Count) {
+			shard.Index = len(tasks)
+			tasks = append(tasks, shard)
+		}
 	}
 	return tasks, nil
 }
@@ -325,6 +480,16 @@ func writeTaskLogs(logWriter *logs.LogWriter, result *parallel.Result, taskName
 	var logErrors []error
 	for i := range result.TaskResults {
 		tr := &result.TaskResults[i]
+		if tr.OutputFile != "" {
+			// Too much output to keep in memory: the full log is already on disk
+			path, err := logWriter.MoveTask(tr.TaskName, tr.TaskIndex, tr.OutputFile)
+			if err != nil {
+				logErrors = append(logErrors, err)
+			} else {
+				tr.OutputFile = path
+			}
+			continue
+		}
 		if err := logWriter.WriteTask(tr.TaskName, tr.TaskIndex, tr.Output); err != nil {
 			logErrors = append(logErrors, err)
 		}
@@ -355,8 +520,8 @@ func determineOutputMode(opts ParallelTaskOptions, task *config.TaskConfig) para
 	}
 
 	// Task-level output config
-	if task.Output != "" {
-		switch task.Output {
+	if task.Output.Mode != "" {
+		switch task.Output.Mode {
 		case "stream":
 			return parallel.OutputStream
 		case "verbose":
@@ -372,20 +537,40 @@ func determineOutputMode(opts ParallelTaskOptions, task *config.TaskConfig) para
 	return parallel.OutputProgress
 }
 
+// runnableSteps drops the steps whose if: is false. A subtask whose steps
+// are all skipped runs "true", so it passes without doing anything.
+func runnableSteps(steps []config.TaskStep, env map[string]string) ([]config.TaskStep, error) {
+	skip, err := exec.SkippedSteps(steps, env, "")
+	if err != nil {
+		return nil, err
+	}
+	runnable := make([]config.TaskStep, 0, len(steps))
+	for i, step := range steps {
+		if !skip[i] {
+			runnable = append(runnable, step)
+		}
+	}
+	if len(runnable) == 0 {
+		runnable = append(runnable, config.TaskStep{Run: "true"})
+	}
+	return runnable, nil
+}
+
 // buildStepsCommand builds a command that runs all steps in sequence.
 func buildStepsCommand(steps []config.TaskStep) string {
 	if len(steps) == 0 {
 		return ""
 	}
 	if len(steps) == 1 {
-		return steps[0].Run
+		return exec.StepCommand(steps[0])
 	}
 
 	// Wrap each step in a subshell to isolate failures and prevent
-	// shell metacharacters from breaking the command chain
+	// shell metacharacters from breaking the command chain. The subshell
+	// also scopes each step's dir so the next step starts from the root again.
 	parts := make([]string, len(steps))
 	for i, step := range steps {
-		parts[i] = fmt.Sprintf("(%s)", step.Run)
+		parts[i] = fmt.Sprintf("(%s)", exec.StepCommand(step))
 	}
 	return strings.Join(parts, " && ")
 }
@@ -494,6 +679,7 @@ func PrintParallelHelp() {
   --max-parallel  Limit concurrent task execution (default: unlimited)
   --no-logs       Don't save output to log files
   --dry-run       Show execution plan without running
+  --cold          Sync and run setup even if the task's hosts are warm
 
 Parallel tasks run multiple subtasks concurrently across available hosts.
 Each subtask is assigned to a host using work-stealing for optimal load balancing.
@@ -511,6 +697,9 @@ Use 'rr logs' to view recent logs or 'rr logs clean' to remove old ones.`)
 
 // FormatParallelTaskHelp returns a formatted description for parallel task help.
 func FormatParallelTaskHelp(task *config.TaskConfig, cfg *config.Config) string {
+	if config.IsShardedTask(task) {
+		return fmt.Sprintf("Runs %d shards of: %s\n", task.Shard.Count, shardedCommand(task))
+	}
 	if !config.IsParallelTask(task) {
 		return ""
 	}
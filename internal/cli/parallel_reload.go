@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/parallel"
+)
+
+// configReloader notices when .rr.yaml changes while a parallel task runs.
+// It's checked as each subtask starts. The first change gets a warning; with
+// on_config_change: apply, subtasks that haven't started yet run with their
+// definitions from the changed config.
+type configReloader struct {
+	path     string
+	taskName string
+	args     []string
+	names    []string // Subtask names the run started with, one per shard of sharded tasks
+	apply    bool
+
+	mu      sync.Mutex
+	modTime time.Time
+	content []byte
+	changed bool                      // The config changed since the run started
+	tasks   map[int]parallel.TaskInfo // Subtasks rebuilt from the changed config, by index
+}
+
+// newConfigReloader snapshots the project config at path. Returns nil if
+// there's no config file to watch.
+func newConfigReloader(path, taskName string, task *config.TaskConfig, names, args []string) *configReloader {
+	if path == "" {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	return &configReloader{
+		path:     path,
+		taskName: taskName,
+		args:     args,
+		names:    names,
+		apply:    config.GetTaskOnConfigChange(task) == config.OnConfigChangeApply,
+		modTime:  info.ModTime(),
+		content:  content,
+	}
+}
+
+// Refresh returns task as it should run now: rebuilt from the changed config
+// when applying changes, or unchanged. warn shows a message about the run.
+func (r *configReloader) Refresh(task parallel.TaskInfo, warn func(string)) parallel.TaskInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if changed, first := r.changedLocked(); changed {
+		if msg := r.updateLocked(first); msg != "" {
+			warn(msg)
+		}
+	}
+	if rebuilt, ok := r.tasks[task.Index]; ok {
+		rebuilt.WorkDir = task.WorkDir
+		return rebuilt
+	}
+	return task
+}
+
+// Check looks for a change one last time, after the run, so an edit made
+// once every subtask had started still gets a warning. Returns the warning,
+// or "".
+func (r *configReloader) Check() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if changed, first := r.changedLocked(); !changed || !first {
+		return ""
+	}
+	return fmt.Sprintf("%s changed during this run; subtasks ran with the definitions they started with", r.path)
+}
+
+// changedLocked rereads the config if its file changed since the last
+// check. Reports whether its content changed, and whether that's the first
+// change this run. Must be called with r.mu held.
+func (r *configReloader) changedLocked() (changed, first bool) {
+	info, err := os.Stat(r.path)
+	if err != nil || info.ModTime().Equal(r.modTime) {
+		return false, false
+	}
+	r.modTime = info.ModTime()
+	content, err := os.ReadFile(r.path)
+	if err != nil || bytes.Equal(content, r.content) {
+		return false, false
+	}
+	r.content = content
+	first = !r.changed
+	r.changed = true
+	return true, first
+}
+
+// updateLocked handles a change to the config: it rebuilds the subtasks
+// when applying changes. Returns a warning to show, or "". Must be called
+// with r.mu held.
+func (r *configReloader) updateLocked(first bool) string {
+	if !r.apply {
+		if !first {
+			return ""
+		}
+		return fmt.Sprintf("%s changed during this run; subtasks keep the definitions it started with (set on_config_change: apply to pick up changes)", r.path)
+	}
+
+	tasks, err := r.rebuild()
+	if err != nil {
+		return fmt.Sprintf("%s changed, but the change can't be applied (%v); subtasks that haven't started use the last working version", r.path, err)
+	}
+	r.tasks = tasks
+	return fmt.Sprintf("%s changed; subtasks that haven't started will use the new definitions", r.path)
+}
+
+// rebuild loads the changed config and rebuilds the subtasks from it, keyed
+// by index. The parallel task has to flatten to the same subtasks, with the
+// same shards, since the run is already scheduling them.
+func (r *configReloader) rebuild() (map[int]parallel.TaskInfo, error) {
+	cfg, err := config.Load(r.path)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.Validate(cfg); err != nil {
+		return nil, err
+	}
+	task, err := config.GetTask(cfg, r.taskName)
+	if err != nil {
+		return nil, err
+	}
+	_, infos, err := parallelTaskInfos(cfg, r.taskName, task, r.args)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Equal(taskInfoNames(infos), r.names) {
+		return nil, fmt.Errorf("the list of subtasks changed")
+	}
+	tasks := make(map[int]parallel.TaskInfo, len(infos))
+	for _, info := range infos {
+		tasks[info.Index] = info
+	}
+	return tasks, nil
+}
+
+// taskInfoNames returns the name of each task.
+func taskInfoNames(tasks []parallel.TaskInfo) []string {
+	names := make([]string, len(tasks))
+	for i, t := range tasks {
+		names[i] = t.Name
+	}
+	return names
+}
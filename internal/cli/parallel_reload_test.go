@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/parallel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const reloadTestConfig = `version: 1
+tasks:
+  lint:
+    run: make lint
+  test:
+    run: make test
+  ci:
+    parallel: [lint, test]
+`
+
+// newTestReloader writes content to a config file and returns a reloader
+// for its ci task.
+func newTestReloader(t *testing.T, content string) (*configReloader, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".rr.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	task, err := config.GetTask(cfg, "ci")
+	require.NoError(t, err)
+	names, err := config.FlattenParallelTasks("ci", cfg.Tasks)
+	require.NoError(t, err)
+
+	r := newConfigReloader(path, "ci", task, names, nil)
+	require.NotNil(t, r)
+	return r, path
+}
+
+// editConfig rewrites the config with a new mtime, so the change is seen
+// even on filesystems with coarse timestamps.
+func editConfig(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+}
+
+func TestConfigReloader_Warn(t *testing.T) {
+	r, path := newTestReloader(t, reloadTestConfig)
+	lint := parallel.TaskInfo{Name: "lint", Index: 0, Command: "make lint"}
+
+	var warnings []string
+	warn := func(msg string) { warnings = append(warnings, msg) }
+
+	assert.Equal(t, lint, r.Refresh(lint, warn))
+	assert.Empty(t, warnings, "nothing changed")
+
+	editConfig(t, path, reloadTestConfig+"\n# touched\n")
+	assert.Equal(t, lint, r.Refresh(lint, warn))
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "on_config_change: apply")
+
+	// Only the first change warns
+	editConfig(t, path, reloadTestConfig+"\n# touched again\n")
+	r.Refresh(lint, warn)
+	assert.Len(t, warnings, 1)
+	assert.Empty(t, r.Check())
+}
+
+func TestConfigReloader_Apply(t *testing.T) {
+	applied := reloadTestConfig + "    on_config_change: apply\n"
+	r, path := newTestReloader(t, applied)
+	test := parallel.TaskInfo{Name: "test", Index: 1, Command: "make test", WorkDir: "~/app"}
+
+	var warnings []string
+	warn := func(msg string) { warnings = append(warnings, msg) }
+
+	editConfig(t, path, `version: 1
+tasks:
+  lint:
+    run: make lint
+  test:
+    run: make test-fast
+    env: {level: debug}
+  ci:
+    parallel: [lint, test]
+    on_config_change: apply
+`)
+	got := r.Refresh(test, warn)
+	assert.Equal(t, "make test-fast", got.Command)
+	assert.Equal(t, map[string]string{"level": "debug"}, got.Env)
+	assert.Equal(t, "~/app", got.WorkDir)
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "will use the new definitions")
+
+	t.Run("subtask list changed", func(t *testing.T) {
+		editConfig(t, path, `version: 1
+tasks:
+  lint:
+    run: make lint
+  ci:
+    parallel: [lint]
+    on_config_change: apply
+`)
+		got := r.Refresh(test, warn)
+		assert.Equal(t, "make test-fast", got.Command, "keeps the last version that applied")
+		assert.Contains(t, warnings[len(warnings)-1], "the list of subtasks changed")
+	})
+}
+
+func TestConfigReloader_CheckAfterRun(t *testing.T) {
+	r, path := newTestReloader(t, reloadTestConfig)
+	assert.Empty(t, r.Check())
+
+	editConfig(t, path, reloadTestConfig+"\n# touched\n")
+	assert.Contains(t, r.Check(), "changed during this run")
+}
+
+func TestNewConfigReloader_NoConfig(t *testing.T) {
+	assert.Nil(t, newConfigReloader("", "ci", nil, nil, nil))
+	assert.Nil(t, newConfigReloader(filepath.Join(t.TempDir(), "missing.yaml"), "ci", nil, nil, nil))
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/exec"
 	"github.com/rileyhilliard/rr/internal/parallel"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -237,6 +238,88 @@ func TestBuildSubtaskInfos_NoForwardArgsIgnoresArgs(t *testing.T) {
 	assert.Equal(t, "pytest tests/a", infos[0].Command, "args should not be appended when forward_args is false")
 }
 
+func TestBuildSubtaskInfos_Priority(t *testing.T) {
+	proj := &config.Config{
+		Tasks: map[string]config.TaskConfig{
+			"lint": {Run: "make lint"},
+			"test": {Run: "make test", Priority: config.PriorityHigh},
+		},
+	}
+	parentTask := &config.TaskConfig{Parallel: []string{"lint", "test"}, Priority: config.PriorityLow}
+
+	infos, err := buildSubtaskInfos(proj, parentTask, []string{"lint", "test"}, nil)
+	require.NoError(t, err)
+	require.Len(t, infos, 2)
+	assert.Equal(t, exec.WithPriority(config.PriorityLow, "make lint"), infos[0].Command, "subtasks default to the parent's priority")
+	assert.Equal(t, exec.WithPriority(config.PriorityHigh, "make test"), infos[1].Command)
+}
+
+func TestBuildSubtaskInfos_ExpandsShards(t *testing.T) {
+	proj := &config.Config{
+		Tasks: map[string]config.TaskConfig{
+			"lint": {Run: "make lint"},
+			"test": {Run: "pytest", Env: map[string]string{"CI": "1"}, Shard: config.ShardConfig{Count: 3}},
+		},
+	}
+	parentTask := &config.TaskConfig{Parallel: []string{"lint", "test"}}
+
+	infos, err := buildSubtaskInfos(proj, parentTask, []string{"lint", "test"}, nil)
+	require.NoError(t, err)
+	require.Len(t, infos, 4)
+	assert.Equal(t, "lint", infos[0].Name)
+	assert.Equal(t, "test[2/3]", infos[2].Name)
+	assert.Equal(t, 2, infos[2].Index)
+	assert.Equal(t, map[string]string{"CI": "1", "SHARD_INDEX": "2", "SHARD_TOTAL": "3"}, infos[2].Env)
+	assert.Nil(t, infos[0].Env, "unsharded subtasks run as-is")
+}
+
+func TestParallelTaskInfos_ShardedTaskTakesArgs(t *testing.T) {
+	proj := &config.Config{
+		Tasks: map[string]config.TaskConfig{
+			"test": {Run: "pytest", Shard: config.ShardConfig{Count: 2}},
+		},
+	}
+	task := proj.Tasks["test"]
+
+	names, infos, err := parallelTaskInfos(proj, "test", &task, []string{"-k", "api"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"test"}, names)
+	require.Len(t, infos, 2)
+	assert.Equal(t, "pytest '-k' 'api'", infos[1].Command)
+}
+
+func TestSelectShards(t *testing.T) {
+	task := &config.TaskConfig{Run: "pytest", Shard: config.ShardConfig{Count: 3}}
+	tasks := parallel.ShardTasks(parallel.TaskInfo{Name: "test", Command: "pytest"}, 3)
+
+	picked, err := selectShards(t.TempDir(), "test", task, tasks, 2)
+	require.NoError(t, err)
+	require.Len(t, picked, 1)
+	assert.Equal(t, "test[2/3]", picked[0].Name)
+
+	_, err = selectShards(t.TempDir(), "test", task, tasks, 4)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no shard 4")
+
+	all, err := selectShards(t.TempDir(), "test", task, tasks, 0)
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+}
+
+func TestMergedTestCounts(t *testing.T) {
+	result := &parallel.Result{TaskResults: []parallel.TaskResult{
+		{TaskName: "test[1/2]", Command: "go test ./...", Output: []byte("--- PASS: TestA (0.00s)\n--- FAIL: TestB (0.00s)\nFAIL\n")},
+		{TaskName: "test[2/2]", Command: "go test ./...", Output: []byte("--- PASS: TestC (0.00s)\n--- PASS: TestD (0.00s)\nok\n")},
+	}}
+
+	counts := mergedTestCounts(result)
+	require.NotNil(t, counts)
+	assert.Equal(t, 3, counts.Passed)
+	assert.Equal(t, 1, counts.Failed)
+
+	assert.Nil(t, mergedTestCounts(&parallel.Result{TaskResults: []parallel.TaskResult{{Output: []byte("done\n")}}}))
+}
+
 // pytestFailureOutput returns realistic pytest output containing a failure.
 func pytestFailureOutput(testName, file string, line int, message string) []byte {
 	return []byte(fmt.Sprintf(`=================================== FAILURES ===================================
@@ -437,7 +520,7 @@ func TestRenderParallelResult_MachineMode_IncludesFailures(t *testing.T) {
 	}
 
 	output := captureStderr(t, func() {
-		renderParallelResult(result, nil, "test")
+		renderParallelResult(result, nil, "test", false)
 	})
 
 	var event PhaseEvent
@@ -477,7 +560,7 @@ func TestRenderParallelResult_MachineMode_SuccessHasNoFailures(t *testing.T) {
 	}
 
 	output := captureStderr(t, func() {
-		renderParallelResult(result, nil, "test")
+		renderParallelResult(result, nil, "test", false)
 	})
 
 	var event PhaseEvent
@@ -488,3 +571,14 @@ func TestRenderParallelResult_MachineMode_SuccessHasNoFailures(t *testing.T) {
 	_, hasFailures := event.Details["failures"]
 	assert.False(t, hasFailures, "successful result should not include failures key")
 }
+
+func TestBuildStepsCommand_StepDir(t *testing.T) {
+	steps := []config.TaskStep{
+		{Run: "go test ./...", Dir: "services/api"},
+		{Run: "npm test"},
+	}
+	assert.Equal(t, "(cd 'services/api' && go test ./...) && (npm test)", buildStepsCommand(steps))
+
+	single := []config.TaskStep{{Run: "cargo test", Dir: "crates/core"}}
+	assert.Equal(t, "cd 'crates/core' && cargo test", buildStepsCommand(single))
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+)
+
+// paramsInteractive reports whether missing params can be asked for.
+// Swappable for tests.
+var paramsInteractive = func() bool {
+	return canPrompt()
+}
+
+// promptParam asks for a param's value, starting from its default.
+// Swappable for tests.
+var promptParam = func(p config.TaskParam) (string, error) {
+	title := p.Prompt
+	if title == "" {
+		title = p.Name
+	}
+	value := p.Default
+
+	var field huh.Field
+	if len(p.Choices) > 0 {
+		options := make([]huh.Option[string], len(p.Choices))
+		for i, c := range p.Choices {
+			options[i] = huh.NewOption(c, c)
+		}
+		field = huh.NewSelect[string]().Title(title).Options(options...).Value(&value)
+	} else {
+		field = huh.NewInput().Title(title).Value(&value).Validate(func(s string) error {
+			if s == "" && p.Default == "" {
+				return fmt.Errorf("%s needs a value", p.Name)
+			}
+			return nil
+		})
+	}
+	if err := huh.NewForm(huh.NewGroup(field)).Run(); err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// taskParamValues works out a value for each of a task's params: the one
+// passed with --param, or else the one typed at the prompt, or with no
+// terminal to ask on or --non-interactive, the default.
+func taskParamValues(name string, task config.TaskConfig, flags []string) (map[string]string, error) {
+	if len(task.Params) == 0 {
+		return nil, nil
+	}
+	values, err := config.ParseParamFlags(flags)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.CheckParamValues(name, task.Params, values); err != nil {
+		return nil, err
+	}
+
+	interactive := paramsInteractive()
+	var missing []string
+	for _, p := range task.Params {
+		if _, ok := values[p.Name]; ok {
+			continue
+		}
+		if interactive {
+			v, err := promptParam(p)
+			if err != nil {
+				return nil, errors.WrapWithCode(err, errors.ErrConfig,
+					fmt.Sprintf("Didn't get a value for param '%s'", p.Name),
+					fmt.Sprintf("Pass it with --param %s=<value> instead.", p.Name))
+			}
+			values[p.Name] = v
+			continue
+		}
+		if p.Default == "" {
+			missing = append(missing, p.Name)
+			continue
+		}
+		values[p.Name] = p.Default
+	}
+
+	if len(missing) > 0 {
+		flags := make([]string, len(missing))
+		for i, m := range missing {
+			flags[i] = fmt.Sprintf("--param %s=<value>", m)
+		}
+		return nil, needsInput(
+			fmt.Sprintf("Task '%s' needs a value for %s", name, strings.Join(missing, ", ")),
+			fmt.Sprintf("Pass %s.", strings.Join(flags, " ")))
+	}
+	return values, nil
+}
+
+// applyTaskParams fills in a task's params in the project config, so every
+// later lookup of the task gets the values.
+func applyTaskParams(project *config.Config, name string, values map[string]string) {
+	if project == nil || len(values) == 0 {
+		return
+	}
+	if task, ok := project.Tasks[name]; ok {
+		project.Tasks[name] = config.ApplyTaskParams(task, values)
+	}
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubParamPrompt makes taskParamValues ask prompt instead of a terminal,
+// or see no terminal at all when prompt is nil.
+func stubParamPrompt(t *testing.T, prompt func(config.TaskParam) (string, error)) {
+	t.Helper()
+	origInteractive, origPrompt := paramsInteractive, promptParam
+	t.Cleanup(func() { paramsInteractive, promptParam = origInteractive, origPrompt })
+	paramsInteractive = func() bool { return prompt != nil }
+	promptParam = prompt
+}
+
+func TestTaskParamValues_FlagsSkipThePrompt(t *testing.T) {
+	var asked []string
+	stubParamPrompt(t, func(p config.TaskParam) (string, error) {
+		asked = append(asked, p.Name)
+		return "typed", nil
+	})
+	task := config.TaskConfig{Params: []config.TaskParam{{Name: "env"}, {Name: "tag", Default: "latest"}}}
+
+	values, err := taskParamValues("deploy", task, []string{"env=prod"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "prod", "tag": "typed"}, values)
+	assert.Equal(t, []string{"tag"}, asked, "only the missing param is asked for")
+}
+
+func TestTaskParamValues_NoTerminal(t *testing.T) {
+	stubParamPrompt(t, nil)
+	task := config.TaskConfig{Params: []config.TaskParam{{Name: "env"}, {Name: "tag", Default: "latest"}}}
+
+	values, err := taskParamValues("deploy", task, []string{"env=prod"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "prod", "tag": "latest"}, values)
+
+	_, err = taskParamValues("deploy", task, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "needs a value for env")
+}
+
+func TestTaskParamValues_RejectsBadValues(t *testing.T) {
+	stubParamPrompt(t, nil)
+	task := config.TaskConfig{Params: []config.TaskParam{{Name: "env", Choices: []string{"staging", "prod"}, Default: "staging"}}}
+
+	_, err := taskParamValues("deploy", task, []string{"env=dev"})
+	assert.ErrorContains(t, err, "isn't one of the choices")
+
+	_, err = taskParamValues("deploy", task, []string{"region=us"})
+	assert.ErrorContains(t, err, "no param named 'region'")
+}
+
+func TestApplyTaskParams(t *testing.T) {
+	project := &config.Config{Tasks: map[string]config.TaskConfig{
+		"deploy": {Run: "./deploy.sh {{env}}", Params: []config.TaskParam{{Name: "env"}}},
+	}}
+
+	applyTaskParams(project, "deploy", map[string]string{"env": "prod"})
+	assert.Equal(t, "./deploy.sh 'prod'", project.Tasks["deploy"].Run)
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	rrsync "github.com/rileyhilliard/rr/internal/sync"
+	"github.com/rileyhilliard/rr/internal/ui"
+)
+
+// runTaskInputs runs the tasks a task takes inputs from, each on its own
+// hosts, and stages their outputs locally for sendTaskInputs. It returns the
+// first non-zero exit code, and runs each producer once even if several
+// tasks in the pipeline take inputs from it.
+func runTaskInputs(opts TaskOptions) (int, error) {
+	resolved, err := loadResolved(Config())
+	if err != nil || resolved.Project == nil {
+		return 0, nil // Reported by SetupWorkflow
+	}
+	task, ok := resolved.Project.Tasks[opts.TaskName]
+	if !ok || len(task.Inputs) == 0 {
+		return 0, nil
+	}
+
+	if opts.inputsRun == nil {
+		opts.inputsRun = make(map[string]bool)
+	}
+	for _, input := range task.Inputs {
+		if opts.inputsRun[input] {
+			continue
+		}
+		opts.inputsRun[input] = true
+
+		if PrettyMode() && !opts.Quiet {
+			fmt.Printf("%s Running '%s' for the inputs of '%s'\n\n", ui.SymbolPending, input, opts.TaskName)
+		}
+		exitCode, err := RunTask(TaskOptions{
+			TaskName:     input,
+			ProbeTimeout: opts.ProbeTimeout,
+			WorkingDir:   opts.WorkingDir,
+			Quiet:        opts.Quiet,
+			Local:        opts.Local,
+			NoSummary:    opts.NoSummary,
+			Diagnostics:  opts.Diagnostics,
+			Force:        opts.Force,
+			StageOutputs: true,
+			inputsRun:    opts.inputsRun,
+		})
+		if err != nil || exitCode != 0 {
+			return exitCode, err
+		}
+		if PrettyMode() && !opts.Quiet {
+			fmt.Println()
+		}
+	}
+	return 0, nil
+}
+
+// stageTaskOutputs keeps a finished task's outputs locally, for the tasks
+// that take inputs from it.
+func stageTaskOutputs(wf *WorkflowContext, taskName string, outputs []string) error {
+	stageDir, err := rrsync.StageDir(wf.WorkDir, taskName)
+	if err != nil {
+		return err
+	}
+	return runTransferPhase(wf, "stage", "Staging outputs of "+taskName, "Outputs staged", func() error {
+		return rrsync.StageOutputs(wf.Conn, wf.WorkDir, stageDir, outputs)
+	})
+}
+
+// sendTaskInputs copies the staged outputs of each task a task takes inputs
+// from into the connected host's dir, at the paths they were produced at.
+func sendTaskInputs(wf *WorkflowContext, task *config.TaskConfig) error {
+	for _, input := range task.Inputs {
+		producer, ok := wf.Resolved.Project.Tasks[input]
+		if !ok {
+			continue // Caught by config validation
+		}
+		stageDir, err := rrsync.StageDir(wf.WorkDir, input)
+		if err != nil {
+			return err
+		}
+		err = runTransferPhase(wf, "inputs", "Sending outputs of "+input, "Inputs sent", func() error {
+			return rrsync.SendStaged(wf.Conn, wf.WorkDir, stageDir, producer.Outputs)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runTransferPhase runs one pipeline file transfer with a spinner, or phase
+// events in structured mode.
+func runTransferPhase(wf *WorkflowContext, phase, label, done string, transfer func() error) error {
+	start := time.Now()
+
+	if !PrettyMode() {
+		reporter := wf.GetReporter()
+		reporter.PhaseStart(phase)
+		if err := transfer(); err != nil {
+			reporter.PhaseFailed(phase, err)
+			return err
+		}
+		reporter.PhaseComplete(phase, wf.Conn.Name, time.Since(start))
+		wf.recordPhase(phase, time.Since(start))
+		return nil
+	}
+
+	spinner := ui.NewSpinner(label)
+	spinner.Start()
+	if err := transfer(); err != nil {
+		spinner.Fail()
+		return err
+	}
+	spinner.Success()
+	wf.PhaseDisplay.RenderSuccess(done, time.Since(start))
+	wf.recordPhase(phase, time.Since(start))
+	return nil
+}
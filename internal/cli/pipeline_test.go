@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestrictToTaskHosts(t *testing.T) {
+	order := []string{"mac", "linux", "gpu"}
+	hosts := map[string]config.Host{
+		"mac":   {Dir: "~/mac"},
+		"linux": {Dir: "~/linux"},
+		"gpu":   {Dir: "~/gpu"},
+	}
+
+	gotOrder, gotHosts := restrictToTaskHosts(order, hosts, []string{"gpu", "linux"})
+	assert.Equal(t, []string{"linux", "gpu"}, gotOrder, "the project's order wins")
+	assert.Equal(t, map[string]config.Host{"linux": {Dir: "~/linux"}, "gpu": {Dir: "~/gpu"}}, gotHosts)
+
+	gotOrder, gotHosts = restrictToTaskHosts(order, hosts, nil)
+	assert.Equal(t, order, gotOrder)
+	assert.Equal(t, hosts, gotHosts)
+
+	gotOrder, gotHosts = restrictToTaskHosts(order, hosts, []string{"elsewhere"})
+	assert.Equal(t, order, gotOrder, "left alone when none of the task's hosts are available")
+	assert.Equal(t, hosts, gotHosts)
+}
+
+func TestSendTaskInputs_NothingStaged(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := buildTestContext(t.TempDir(), map[string]config.TaskConfig{
+		"build": {Run: "make", Outputs: []string{"dist/"}},
+		"e2e":   {Run: "./e2e.sh", Inputs: []string{"build"}},
+	})
+	ctx.Conn = &host.Connection{Name: "m1", Alias: "m1"}
+
+	task := ctx.Resolved.Project.Tasks["e2e"]
+	err := sendTaskInputs(ctx, &task)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Nothing staged in")
+	assert.NotContains(t, ctx.Phases, "inputs")
+}
@@ -0,0 +1,350 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/logger"
+	"github.com/rileyhilliard/rr/internal/output"
+	"github.com/rileyhilliard/rr/internal/output/formatters"
+	"github.com/rileyhilliard/rr/internal/plugin"
+	"github.com/rileyhilliard/rr/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// hookPlugins are the hook plugins from the global config, sent an event
+// as each phase of a run completes.
+var hookPlugins []string
+
+// hookFlushTimeout is how long rr waits, once a command is done, for the
+// hooks still running to finish. It's one budget for all of them, however
+// many events are queued.
+const hookFlushTimeout = 10 * time.Second
+
+// pluginWarned remembers which plugins already printed a failure, so a
+// broken hook warns once instead of once per phase. Hooks warn from their
+// own goroutine, so it's guarded by pluginWarnedMu.
+var (
+	pluginWarned   = make(map[string]bool)
+	pluginWarnedMu sync.Mutex
+)
+
+// hookQueue holds the events waiting for the hook plugins. One goroutine
+// sends them in order; done is open while it runs and closed when the
+// queue is empty.
+var hookQueue struct {
+	mu      sync.Mutex
+	pending []PhaseEvent
+	done    chan struct{}
+}
+
+// pluginCmd is the parent command for plugin management
+var pluginCmd = &cobra.Command{
+	Use:   "plugin",
+	Short: "List installed plugins",
+	Long: `Plugins are executables named rr-<name> on your PATH.
+
+Any plugin runs as 'rr <name> [args...]', with stdin, stdout, and stderr
+passed through and RR_BIN set to the path of rr. Built-in commands and
+tasks take precedence over plugins of the same name.
+
+Plugins listed in ~/.rr/config.yaml also extend runs:
+
+  plugins:
+    parsers: [junit]    # parse test output rr doesn't recognize
+    hooks: [notify]     # get an event as each phase completes
+
+See the configuration docs for the parser and hook protocols.
+
+Examples:
+  rr plugin list`,
+}
+
+// pluginListCmd lists the plugins on PATH
+var pluginListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the rr-<name> plugins on PATH",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return pluginList(os.Stdout)
+	},
+}
+
+func init() {
+	pluginCmd.AddCommand(pluginListCmd)
+	rootCmd.AddCommand(pluginCmd)
+}
+
+// PluginListOutput is the JSON representation of an installed plugin.
+type PluginListOutput struct {
+	Name    string   `json:"name"`
+	Path    string   `json:"path"`
+	Enabled []string `json:"enabled,omitempty"`
+	Shadow  string   `json:"shadowed_by,omitempty"`
+}
+
+// pluginList prints the plugins on PATH, what the global config enables
+// each one as, and which ones a built-in command or task hides.
+func pluginList(w io.Writer) error {
+	var pluginsCfg config.PluginsConfig
+	if global, err := config.LoadGlobal(); err == nil {
+		pluginsCfg = global.Plugins
+	}
+
+	plugins := plugin.List()
+	out := make([]PluginListOutput, 0, len(plugins))
+	for _, p := range plugins {
+		entry := PluginListOutput{Name: p.Name, Path: p.Path, Shadow: shadowingCommand(p.Name)}
+		if containsString(pluginsCfg.Parsers, p.Name) {
+			entry.Enabled = append(entry.Enabled, "parser")
+		}
+		if containsString(pluginsCfg.Hooks, p.Name) {
+			entry.Enabled = append(entry.Enabled, "hook")
+		}
+		out = append(out, entry)
+	}
+
+	if MachineMode() {
+		return WriteJSONSuccess(w, out)
+	}
+
+	if len(out) == 0 {
+		fmt.Fprintln(w, "No plugins found.")
+		fmt.Fprintln(w, "Plugins are executables named rr-<name> on your PATH.")
+		return nil
+	}
+
+	nameStyle := lipgloss.NewStyle().Bold(true)
+	mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
+	for _, p := range out {
+		line := fmt.Sprintf("  %s  %s", nameStyle.Render(p.Name), mutedStyle.Render(p.Path))
+		if len(p.Enabled) > 0 {
+			line += "  " + strings.Join(p.Enabled, ", ")
+		}
+		if p.Shadow != "" {
+			line += "  " + mutedStyle.Render(fmt.Sprintf("(hidden by the %s)", p.Shadow))
+		}
+		fmt.Fprintln(w, line)
+	}
+	return nil
+}
+
+// containsString reports whether list holds s.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// shadowingCommand names what 'rr <name>' runs instead of a plugin, or ""
+// if it runs the plugin.
+func shadowingCommand(name string) string {
+	if discoveryState != nil && containsString(discoveryState.TasksAvailable, name) {
+		return "task"
+	}
+	if name == "help" || strings.HasPrefix(name, "__") {
+		return "built-in command"
+	}
+	for _, c := range rootCmd.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return "built-in command"
+		}
+	}
+	return ""
+}
+
+// findPluginCommand returns the plugin that 'rr <args>' runs and the
+// arguments to pass it, if the first argument after rr's global flags is a
+// name no command or task has and an rr-<name> plugin is on PATH. The
+// global flags themselves aren't passed on.
+func findPluginCommand(args []string) (string, []string, bool) {
+	i := skipGlobalFlags(args)
+	if i < 0 || i >= len(args) || args[i] == "" {
+		return "", nil, false
+	}
+	name := args[i]
+	if shadowingCommand(name) != "" {
+		return "", nil, false
+	}
+	path, err := plugin.Find(name)
+	if err != nil {
+		return "", nil, false
+	}
+	return path, args[i+1:], true
+}
+
+// skipGlobalFlags returns the index of the first argument that isn't one of
+// rr's global flags or a flag's value, or -1 if it meets a flag rr doesn't
+// have globally, which only a command could take.
+func skipGlobalFlags(args []string) int {
+	flags := rootCmd.PersistentFlags()
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return -1
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			return i
+		}
+		if name, ok := strings.CutPrefix(arg, "--"); ok {
+			name, _, hasValue := strings.Cut(name, "=")
+			f := flags.Lookup(name)
+			if f == nil {
+				return -1
+			}
+			if !hasValue && f.NoOptDefVal == "" {
+				i++ // The flag's value is the next argument
+			}
+			continue
+		}
+		// One or more shorthands, like -v, -vv, or -pv
+		shorthands := arg[1:]
+		for j := 0; j < len(shorthands); j++ {
+			f := flags.ShorthandLookup(shorthands[j : j+1])
+			if f == nil {
+				return -1
+			}
+			if f.NoOptDefVal == "" {
+				if j == len(shorthands)-1 {
+					i++ // The flag's value is the next argument
+				}
+				break // The rest of the argument is the flag's value
+			}
+		}
+	}
+	return len(args)
+}
+
+// runPluginCommand runs a plugin as a subcommand, passing the terminal
+// through. Interrupts go to the plugin, which shares rr's process group;
+// rr waits for it to exit rather than exiting first.
+func runPluginCommand(path string, args []string) error {
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = plugin.Env()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	defer signal.Stop(signals)
+
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return errors.NewExitError(exitErr.ExitCode())
+		}
+		return errors.WrapWithCode(err, errors.ErrExec,
+			fmt.Sprintf("Can't run plugin %s", path),
+			"Check that the file is executable and its interpreter (the #! line) exists.")
+	}
+	return nil
+}
+
+// activatePlugins wires the parser and hook plugins from the global config
+// into this process. A global config that doesn't load or validate is left
+// for the command itself to report.
+func activatePlugins() {
+	global, err := config.LoadGlobal()
+	if err != nil || config.ValidateGlobal(global) != nil {
+		return
+	}
+	for _, name := range global.Plugins.Parsers {
+		formatters.RegisterExtractor(name, parserPlugin(name))
+	}
+	hookPlugins = global.Plugins.Hooks
+}
+
+// parserPlugin adapts a parser plugin to a formatters.Extractor. A parser
+// that fails is treated as not recognizing the output.
+func parserPlugin(name string) formatters.Extractor {
+	return func(command string, rawOutput []byte) []output.TestFailure {
+		failures, err := plugin.Parse(name, command, rawOutput)
+		if err != nil {
+			warnPlugin(name, err)
+			return nil
+		}
+		return failures
+	}
+}
+
+// runHooks queues an event for every hook plugin and returns without
+// waiting. Events are sent in order, one hook at a time, in the background,
+// so a slow hook never holds up the run; flushHooks waits for them at exit.
+// Their failures never fail the run.
+func runHooks(event PhaseEvent) {
+	if len(hookPlugins) == 0 {
+		return
+	}
+	event.TS = time.Now().UTC().Format(time.RFC3339)
+
+	hookQueue.mu.Lock()
+	defer hookQueue.mu.Unlock()
+	hookQueue.pending = append(hookQueue.pending, event)
+	if hookQueue.done == nil {
+		hookQueue.done = make(chan struct{})
+		go sendHookEvents(hookQueue.done)
+	}
+}
+
+// sendHookEvents sends the queued events to the hook plugins until the
+// queue is empty, then closes done.
+func sendHookEvents(done chan struct{}) {
+	for {
+		hookQueue.mu.Lock()
+		if len(hookQueue.pending) == 0 {
+			hookQueue.done = nil
+			hookQueue.mu.Unlock()
+			close(done)
+			return
+		}
+		event := hookQueue.pending[0]
+		hookQueue.pending = hookQueue.pending[1:]
+		hookQueue.mu.Unlock()
+
+		for _, name := range hookPlugins {
+			if err := plugin.Hook(name, event); err != nil {
+				warnPlugin(name, err)
+			}
+		}
+	}
+}
+
+// flushHooks waits up to hookFlushTimeout for the queued hook events to be
+// sent. Whatever hasn't been sent by then is dropped when rr exits.
+func flushHooks() {
+	hookQueue.mu.Lock()
+	done := hookQueue.done
+	hookQueue.mu.Unlock()
+	if done == nil {
+		return
+	}
+	select {
+	case <-done:
+	case <-time.After(hookFlushTimeout):
+		logger.Verbosef(logger.LevelPhases, "plugin", "hooks still running after %s, not waiting for them", hookFlushTimeout)
+	}
+}
+
+// warnPlugin prints a plugin's first failure in pretty mode. Structured
+// output has no place for it on stderr, which carries phase events.
+func warnPlugin(name string, err error) {
+	pluginWarnedMu.Lock()
+	defer pluginWarnedMu.Unlock()
+	if pluginWarned[name] || !PrettyMode() {
+		return
+	}
+	pluginWarned[name] = true
+	fmt.Fprintf(os.Stderr, "Warning: plugin %s: %v\n", name, err)
+}
@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// installPlugin writes an executable rr-<name> script into a temp dir at
+// the front of PATH and returns its path.
+func installPlugin(t *testing.T, name, script string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "rr-"+name)
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return path
+}
+
+func TestFindPluginCommand(t *testing.T) {
+	path := installPlugin(t, "hello", "exit 0")
+	installPlugin(t, "sync", "exit 0")
+
+	got, args, ok := findPluginCommand([]string{"hello", "--loud"})
+	assert.True(t, ok)
+	assert.Equal(t, path, got)
+	assert.Equal(t, []string{"--loud"}, args)
+
+	_, _, ok = findPluginCommand([]string{"sync"})
+	assert.False(t, ok, "built-in commands take precedence over plugins")
+
+	_, _, ok = findPluginCommand([]string{"nope"})
+	assert.False(t, ok)
+
+	_, _, ok = findPluginCommand(nil)
+	assert.False(t, ok)
+}
+
+func TestFindPluginCommand_SkipsGlobalFlags(t *testing.T) {
+	path := installPlugin(t, "hello", "exit 0")
+
+	tests := []struct {
+		name string
+		args []string
+		want []string // The plugin's arguments, nil if no plugin runs
+	}{
+		{name: "count flag", args: []string{"-v", "hello", "a"}, want: []string{"a"}},
+		{name: "combined shorthands", args: []string{"-vvp", "hello"}, want: []string{}},
+		{name: "long bool flag", args: []string{"--no-color", "hello"}, want: []string{}},
+		{name: "flag with a separate value", args: []string{"--config", "hello", "hello", "x"}, want: []string{"x"}},
+		{name: "flag with an inline value", args: []string{"--config=.rr.yaml", "hello"}, want: []string{}},
+		{name: "flag value isn't the name", args: []string{"--config", "hello"}},
+		{name: "unknown flag", args: []string{"--loud", "hello"}},
+		{name: "end of flags", args: []string{"--", "hello"}},
+		{name: "only flags", args: []string{"-v"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, args, ok := findPluginCommand(tt.args)
+			if tt.want == nil {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			assert.Equal(t, path, got)
+			assert.Equal(t, tt.want, args)
+		})
+	}
+}
+
+func TestShadowingCommand(t *testing.T) {
+	saved := discoveryState
+	t.Cleanup(func() { discoveryState = saved })
+	discoveryState = &configDiscoveryState{TasksAvailable: []string{"deploy"}}
+
+	assert.Equal(t, "task", shadowingCommand("deploy"))
+	assert.Equal(t, "built-in command", shadowingCommand("run"))
+	assert.Equal(t, "built-in command", shadowingCommand("help"))
+	assert.Equal(t, "", shadowingCommand("hello"))
+}
+
+func TestRunPluginCommand(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "args")
+	path := installPlugin(t, "hello", `echo "$@" > "`+out+`"; [ -n "$RR_BIN" ] || exit 9; exit 3`)
+
+	err := runPluginCommand(path, []string{"a", "b"})
+	code, ok := errors.GetExitCode(err)
+	require.True(t, ok)
+	assert.Equal(t, 3, code)
+
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "a b\n", string(data))
+}
+
+func TestRunHooks(t *testing.T) {
+	events := filepath.Join(t.TempDir(), "events")
+	installPlugin(t, "notify", `cat >> "`+events+`"; echo >> "`+events+`"`)
+
+	saved := hookPlugins
+	t.Cleanup(func() { hookPlugins = saved })
+	hookPlugins = []string{"notify"}
+
+	wf := &WorkflowContext{}
+	wf.recordPhase("sync", 0)
+	flushHooks()
+
+	data, err := os.ReadFile(events)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"type":"phase"`)
+	assert.Contains(t, string(data), `"phase":"sync"`)
+	assert.Contains(t, string(data), `"status":"complete"`)
+}
+
+func TestRunHooks_DoesNotWait(t *testing.T) {
+	events := filepath.Join(t.TempDir(), "events")
+	installPlugin(t, "slow", `sleep 1; cat >> "`+events+`"; echo >> "`+events+`"`)
+
+	saved := hookPlugins
+	t.Cleanup(func() { hookPlugins = saved })
+	hookPlugins = []string{"slow"}
+
+	start := time.Now()
+	runHooks(PhaseEvent{Type: "phase", Phase: "connect", Status: "complete"})
+	runHooks(PhaseEvent{Type: "phase", Phase: "sync", Status: "complete"})
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "hooks run in the background")
+
+	flushHooks()
+	data, err := os.ReadFile(events)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"phase":"connect"`, "events are sent in order")
+	assert.Contains(t, lines[1], `"phase":"sync"`)
+}
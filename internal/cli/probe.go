@@ -0,0 +1,247 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	gosync "sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/rileyhilliard/rr/internal/hostinfo"
+	"github.com/rileyhilliard/rr/internal/ui"
+	"github.com/rileyhilliard/rr/pkg/sshutil"
+	"github.com/spf13/cobra"
+)
+
+var (
+	probeJSON    bool
+	probeTimeout string
+)
+
+// probePlatformCommand prints the facts hostinfo.Parse needs for a platform.
+const probePlatformCommand = `echo "os=$(uname -s 2>/dev/null)"
+echo "arch=$(uname -m 2>/dev/null)"`
+
+// probeCmd probes every SSH alias of the configured hosts
+var probeCmd = &cobra.Command{
+	Use:   "probe [host...]",
+	Short: "Check which host aliases are reachable, for scripts and CI",
+	Long: `Connect to every SSH alias of the given hosts (all configured hosts if none
+are given) and report, for each alias, whether it's reachable, the connection
+latency, how SSH authenticated (agent, key, or certificate), and the host's
+platform.
+
+Aliases are probed in parallel. rr probe exits 1 if any host has no reachable
+alias, so it works as a preflight check before a CI job.
+
+Examples:
+  rr probe                   # Probe every configured host
+  rr probe mini gpu-box      # Probe just these hosts
+  rr probe --json | jq '.data.aliases[] | select(.reachable)'
+  rr probe --probe-timeout 2s`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return probeCommand(os.Stdout, args, probeTimeout, probeJSON)
+	},
+}
+
+func init() {
+	probeCmd.Flags().BoolVar(&probeJSON, "json", false, "output in JSON format")
+	probeCmd.Flags().StringVar(&probeTimeout, "probe-timeout", "", "SSH probe timeout per alias (e.g., 2s; default 5s)")
+	rootCmd.AddCommand(probeCmd)
+}
+
+// ProbeAliasOutput is the JSON representation of one probed SSH alias.
+type ProbeAliasOutput struct {
+	Host      string     `json:"host"`
+	Alias     string     `json:"alias"`
+	Reachable bool       `json:"reachable"`
+	LatencyMs int64      `json:"latency_ms"`
+	Auth      string     `json:"auth,omitempty"`      // agent, key or certificate
+	AuthFile  string     `json:"auth_file,omitempty"` // The key or certificate file
+	Platform  string     `json:"platform,omitempty"`  // os/arch, e.g. linux/amd64
+	Error     *JSONError `json:"error,omitempty"`
+}
+
+// ProbeOutput is the JSON representation of rr probe.
+type ProbeOutput struct {
+	Aliases     []ProbeAliasOutput `json:"aliases"`
+	Unreachable []string           `json:"unreachable"` // Hosts with no reachable alias
+}
+
+// connectForProbe dials one SSH alias of a host. Swappable for tests.
+var connectForProbe = func(alias string, h config.Host, timeout time.Duration) (sshutil.SSHClient, time.Duration, sshutil.Auth, error) {
+	client, latency, err := host.Connect(alias, timeout, h)
+	if err != nil {
+		return nil, 0, sshutil.Auth{}, err
+	}
+	auth := sshutil.Auth{Method: sshutil.AuthSystemSSH}
+	if c, ok := client.(*sshutil.Client); ok {
+		auth = c.Auth
+	}
+	return client, latency, auth, nil
+}
+
+// probeCommand probes the named hosts, or every configured host.
+func probeCommand(w io.Writer, names []string, timeoutFlag string, jsonFlag bool) error {
+	machine := jsonFlag || MachineMode()
+
+	hosts, names, timeout, err := probeTargets(names, timeoutFlag)
+	if err != nil {
+		if machine {
+			return WriteJSONFromError(w, err)
+		}
+		return err
+	}
+
+	out := probeHosts(hosts, names, timeout)
+	if machine {
+		if err := WriteJSONSuccess(w, out); err != nil {
+			return err
+		}
+	} else {
+		renderProbe(w, out)
+	}
+
+	if len(out.Unreachable) > 0 {
+		return errors.NewExitError(1)
+	}
+	return nil
+}
+
+// probeTargets resolves what to probe: the configured hosts, the names of
+// the ones to probe (all of them, sorted, if none are given), and the timeout.
+func probeTargets(names []string, timeoutFlag string) (map[string]config.Host, []string, time.Duration, error) {
+	timeout, err := ParseProbeTimeout(timeoutFlag)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if timeout == 0 {
+		timeout = host.DefaultProbeTimeout
+	}
+
+	cfg, _, err := loadGlobalConfig()
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if len(cfg.Hosts) == 0 {
+		return nil, nil, 0, errors.New(errors.ErrConfig,
+			"No hosts configured",
+			"Add a host with 'rr host add' first.")
+	}
+	if len(names) == 0 {
+		for name := range cfg.Hosts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	for _, name := range names {
+		if _, ok := cfg.Hosts[name]; !ok {
+			return nil, nil, 0, errors.New(errors.ErrConfig,
+				fmt.Sprintf("Host '%s' not found", name),
+				"Run 'rr host list' to see configured hosts.")
+		}
+	}
+	return cfg.Hosts, names, timeout, nil
+}
+
+// probeHosts probes every alias of the named hosts in parallel. Results keep
+// the order of names, and of each host's aliases.
+func probeHosts(hosts map[string]config.Host, names []string, timeout time.Duration) ProbeOutput {
+	var aliases []ProbeAliasOutput
+	for _, name := range names {
+		for _, alias := range hosts[name].SSH {
+			aliases = append(aliases, ProbeAliasOutput{Host: name, Alias: alias})
+		}
+	}
+
+	var wg gosync.WaitGroup
+	for i := range aliases {
+		wg.Add(1)
+		go func(r *ProbeAliasOutput) {
+			defer wg.Done()
+			probeAlias(r, hosts[r.Host], timeout)
+		}(&aliases[i])
+	}
+	wg.Wait()
+
+	out := ProbeOutput{Aliases: aliases, Unreachable: []string{}}
+	if out.Aliases == nil {
+		out.Aliases = []ProbeAliasOutput{}
+	}
+	for _, name := range names {
+		reachable := false
+		for _, r := range aliases {
+			reachable = reachable || (r.Host == name && r.Reachable)
+		}
+		if !reachable {
+			out.Unreachable = append(out.Unreachable, name)
+		}
+	}
+	return out
+}
+
+// probeAlias connects to one alias and fills in what it found. The platform
+// is best effort: a host that connects but can't run uname is still reachable.
+func probeAlias(r *ProbeAliasOutput, h config.Host, timeout time.Duration) {
+	h, err := config.ResolveHostSecrets(r.Host, h)
+	if err != nil {
+		r.Error = ErrorToJSON(err)
+		return
+	}
+	client, latency, auth, err := connectForProbe(r.Alias, h, timeout)
+	if err != nil {
+		r.Error = ErrorToJSON(err)
+		return
+	}
+	defer client.Close()
+
+	r.Reachable = true
+	r.LatencyMs = latency.Milliseconds()
+	r.Auth = auth.Method
+	r.AuthFile = auth.File
+	if stdout, _, exitCode, err := client.Exec(probePlatformCommand); err == nil && exitCode == 0 {
+		r.Platform = hostinfo.Parse(string(stdout), time.Now()).Platform()
+	}
+}
+
+// renderProbe prints one line per alias, with why an unreachable one
+// couldn't be reached, then which hosts have no reachable alias.
+func renderProbe(w io.Writer, out ProbeOutput) {
+	mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
+
+	for _, r := range out.Aliases {
+		if !r.Reachable {
+			reason := "unreachable"
+			if r.Error != nil {
+				reason = r.Error.Message
+			}
+			fmt.Fprintf(w, "  %s %-12s %-20s %s\n", ui.SymbolFail, r.Host, r.Alias, reason)
+			continue
+		}
+		var details []string
+		if r.Platform != "" {
+			details = append(details, r.Platform)
+		}
+		if r.Auth != "" {
+			details = append(details, strings.TrimSpace(r.Auth+" "+r.AuthFile))
+		}
+		fmt.Fprintf(w, "  %s %-12s %-20s %6s  %s\n", ui.SymbolSuccess, r.Host, r.Alias,
+			formatLatency(time.Duration(r.LatencyMs)*time.Millisecond),
+			mutedStyle.Render(strings.Join(details, ", ")))
+	}
+
+	fmt.Fprintln(w)
+	if len(out.Unreachable) == 0 {
+		fmt.Fprintf(w, "%s Every host is reachable\n", ui.SymbolSuccess)
+		return
+	}
+	for _, name := range out.Unreachable {
+		fmt.Fprintf(w, "%s %s has no reachable alias\n", ui.SymbolFail, name)
+	}
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/rileyhilliard/rr/pkg/sshutil"
+	sshtesting "github.com/rileyhilliard/rr/pkg/sshutil/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withProbeConnect makes every alias in up connect, and every other alias
+// time out.
+func withProbeConnect(t *testing.T, up ...string) {
+	t.Helper()
+	orig := connectForProbe
+	t.Cleanup(func() { connectForProbe = orig })
+	connectForProbe = func(alias string, _ config.Host, _ time.Duration) (sshutil.SSHClient, time.Duration, sshutil.Auth, error) {
+		for _, a := range up {
+			if a == alias {
+				client := sshtesting.NewMockClient(alias)
+				client.SetCommandResponse(`uname`, sshtesting.CommandResponse{Stdout: []byte("os=Linux\narch=x86_64\n")})
+				return client, 12 * time.Millisecond, sshutil.Auth{Method: sshutil.AuthKey, File: "/home/me/.ssh/id_ed25519"}, nil
+			}
+		}
+		return nil, 0, sshutil.Auth{}, &host.ProbeError{SSHAlias: alias, Reason: host.ProbeFailTimeout}
+	}
+}
+
+func TestProbeHosts(t *testing.T) {
+	withProbeConnect(t, "mini-lan", "gpu-vpn")
+	hosts := map[string]config.Host{
+		"mini":  {SSH: []string{"mini-lan", "mini-ts"}},
+		"gpu":   {SSH: []string{"gpu-lan", "gpu-vpn"}},
+		"spare": {SSH: []string{"spare-lan"}},
+	}
+
+	out := probeHosts(hosts, []string{"gpu", "mini", "spare"}, time.Second)
+
+	require.Len(t, out.Aliases, 5)
+	assert.Equal(t, []string{"gpu-lan", "gpu-vpn", "mini-lan", "mini-ts", "spare-lan"},
+		[]string{out.Aliases[0].Alias, out.Aliases[1].Alias, out.Aliases[2].Alias, out.Aliases[3].Alias, out.Aliases[4].Alias})
+
+	lan := out.Aliases[2]
+	assert.True(t, lan.Reachable)
+	assert.Equal(t, "mini", lan.Host)
+	assert.Equal(t, int64(12), lan.LatencyMs)
+	assert.Equal(t, "key", lan.Auth)
+	assert.Equal(t, "/home/me/.ssh/id_ed25519", lan.AuthFile)
+	assert.Equal(t, "linux/amd64", lan.Platform)
+	assert.Nil(t, lan.Error)
+
+	ts := out.Aliases[3]
+	assert.False(t, ts.Reachable)
+	require.NotNil(t, ts.Error)
+	assert.Equal(t, ErrCodeSSHTimeout, ts.Error.Code)
+
+	assert.Equal(t, []string{"spare"}, out.Unreachable)
+}
+
+func TestProbeHosts_AllReachable(t *testing.T) {
+	withProbeConnect(t, "mini-lan")
+
+	out := probeHosts(map[string]config.Host{"mini": {SSH: []string{"mini-lan"}}}, []string{"mini"}, time.Second)
+
+	assert.Empty(t, out.Unreachable)
+	assert.NotNil(t, out.Unreachable, "encodes as [] rather than null")
+}
+
+func TestRenderProbe(t *testing.T) {
+	withProbeConnect(t, "mini-lan")
+	out := probeHosts(map[string]config.Host{
+		"mini": {SSH: []string{"mini-lan"}},
+		"gpu":  {SSH: []string{"gpu-lan"}},
+	}, []string{"gpu", "mini"}, time.Second)
+
+	var buf bytes.Buffer
+	renderProbe(&buf, out)
+	text := buf.String()
+
+	assert.Contains(t, text, "mini-lan")
+	assert.Contains(t, text, "12ms")
+	assert.Contains(t, text, "linux/amd64, key /home/me/.ssh/id_ed25519")
+	assert.Contains(t, text, "gpu has no reachable alias")
+	assert.NotContains(t, text, "Every host is reachable")
+}
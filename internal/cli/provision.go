@@ -63,7 +63,7 @@ type ProvisionSummary struct {
 // provisionCommand implements the provision command logic.
 func provisionCommand(opts ProvisionOptions) error {
 	// Load resolved config (global + project)
-	resolved, err := config.LoadResolved(Config())
+	resolved, err := loadResolved(Config())
 	if err != nil {
 		return err
 	}
@@ -253,6 +253,11 @@ func handleInstallations(results []hostCheckResult, opts ProvisionOptions) []hos
 	}
 
 	// Confirm installation
+	if !opts.AutoYes && NonInteractive() {
+		fmt.Printf("\n%s Not installing %d missing tool(s) without confirmation: pass --yes to install them\n",
+			ui.SymbolWarning, len(candidates))
+		return results
+	}
 	if !opts.AutoYes && term.IsTerminal(int(os.Stdin.Fd())) {
 		var proceed bool
 		toolList := formatInstallCandidates(results, candidates)
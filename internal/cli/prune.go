@@ -0,0 +1,247 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pruneHost   string
+	pruneDryRun bool
+)
+
+// pruneCmd removes remote branch directories whose branch is gone
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete remote directories of branches that no longer exist",
+	Long: `Hosts whose dir uses ${BRANCH} get a separate remote directory for each
+git branch you sync from, so branches don't share build caches:
+
+  hosts:
+    gpu-box:
+      dir: ~/rr/${PROJECT}/${BRANCH}
+
+Those directories stay behind after a branch is merged and deleted. rr prune
+finds every directory the template has made on each host and deletes the ones
+whose branch no longer exists locally. The current branch is always kept.
+Hosts without ${BRANCH} in their dir are skipped.
+
+Examples:
+  rr prune --dry-run
+  rr prune --host gpu-box`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return pruneCommand(os.Stdout, pruneHost, pruneDryRun)
+	},
+}
+
+func init() {
+	pruneCmd.Flags().StringVar(&pruneHost, "host", "", "only this host")
+	pruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "list what would be deleted without deleting it")
+	rootCmd.AddCommand(pruneCmd)
+}
+
+// PrunedDirOutput is a branch directory found on a host.
+type PrunedDirOutput struct {
+	Branch string `json:"branch"`
+	Path   string `json:"path"`
+	Bytes  int64  `json:"bytes"`
+}
+
+// PruneHostOutput is the JSON representation of pruning one host.
+type PruneHostOutput struct {
+	Host    string            `json:"host"`
+	Dir     string            `json:"dir"`
+	Skipped string            `json:"skipped,omitempty"`
+	Removed []PrunedDirOutput `json:"removed"`
+	Kept    []PrunedDirOutput `json:"kept"`
+	DryRun  bool              `json:"dry_run,omitempty"`
+	Error   string            `json:"error,omitempty"`
+}
+
+// pruneCommand deletes the branch directories of deleted branches on each of
+// the project's hosts, or only hostName's. Unreachable hosts are reported and
+// skipped.
+func pruneCommand(w io.Writer, hostName string, dryRun bool) error {
+	resolved, err := loadResolved(Config())
+	if err != nil {
+		return err
+	}
+	hostOrder, hosts, err := config.ResolveHosts(resolved, hostName)
+	if err != nil {
+		return err
+	}
+	if len(hostOrder) == 0 {
+		return errors.New(errors.ErrConfig,
+			"No hosts to prune",
+			"Add a host with 'rr host add' first.")
+	}
+
+	branches, err := config.LocalBranches()
+	if err != nil {
+		return errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't list local git branches",
+			"Run rr prune from inside the git repository whose branches you sync.")
+	}
+	keep := map[string]bool{config.BranchName(): true}
+	for _, b := range branches {
+		keep[b] = true
+	}
+
+	out := make([]PruneHostOutput, 0, len(hostOrder))
+	for _, name := range hostOrder {
+		out = append(out, pruneHostDirs(name, hosts[name], resolved.Global.DirTemplate(name), keep, dryRun))
+	}
+
+	if MachineMode() {
+		return WriteJSONSuccess(w, out)
+	}
+	renderPrunedHosts(w, out, dryRun)
+	return nil
+}
+
+// pruneHostDirs lists the directories tmpl has made on a host for every
+// branch and deletes those whose branch isn't in keep.
+func pruneHostDirs(name string, h config.Host, tmpl string, keep map[string]bool, dryRun bool) PruneHostOutput {
+	result := PruneHostOutput{Host: name, Dir: tmpl, DryRun: dryRun, Removed: []PrunedDirOutput{}, Kept: []PrunedDirOutput{}}
+	glob, match, ok := branchDirPattern(tmpl)
+	if !ok {
+		result.Skipped = "dir doesn't use ${BRANCH}"
+		if strings.Contains(tmpl, "${BRANCH}") {
+			result.Skipped = "dir is too close to the home or root directory to prune safely"
+		}
+		return result
+	}
+	if len(h.SSH) == 0 {
+		result.Error = "no SSH aliases configured"
+		return result
+	}
+
+	client, err := connectForCleanup(name, h)
+	if err != nil {
+		result.Error = fmt.Sprintf("unreachable: %v", err)
+		return result
+	}
+	defer client.Close()
+
+	stdout, stderr, exitCode, err := client.Exec(buildCleanupListCmd([]string{glob}))
+	if err != nil || exitCode != 0 {
+		result.Error = remoteCommandError(err, stderr, exitCode)
+		return result
+	}
+
+	var stale []remoteArtifact
+	for _, a := range parseCleanupList(stdout) {
+		m := match.FindStringSubmatch(a.Path)
+		if m == nil {
+			continue
+		}
+		dir := PrunedDirOutput{Branch: m[1], Path: a.Path, Bytes: a.Bytes}
+		if keep[dir.Branch] {
+			result.Kept = append(result.Kept, dir)
+			continue
+		}
+		result.Removed = append(result.Removed, dir)
+		stale = append(stale, a)
+	}
+	sort.Slice(result.Removed, func(i, j int) bool { return result.Removed[i].Branch < result.Removed[j].Branch })
+	sort.Slice(result.Kept, func(i, j int) bool { return result.Kept[i].Branch < result.Kept[j].Branch })
+
+	if dryRun || len(stale) == 0 {
+		return result
+	}
+	_, stderr, exitCode, err = client.Exec(buildCleanupRemoveCmd(stale))
+	if err != nil || exitCode != 0 {
+		result.Error = remoteCommandError(err, stderr, exitCode)
+	}
+	return result
+}
+
+// branchDirPattern turns a dir template using ${BRANCH} into a remote glob
+// matching the directory of every branch, and a pattern that picks the
+// branch name back out of a path the glob found. ok is false if the template
+// doesn't use ${BRANCH}, or if the glob would reach into the home or root
+// directory itself.
+func branchDirPattern(tmpl string) (glob string, match *regexp.Regexp, ok bool) {
+	if !strings.Contains(tmpl, "${BRANCH}") {
+		return "", nil, false
+	}
+	dir := strings.TrimSuffix(config.ExpandRemote(strings.ReplaceAll(tmpl, "${BRANCH}", "*")), "/")
+	i := strings.Index(dir, "*")
+	if isCleanupRoot(path.Dir(dir[:i] + "x")) {
+		return "", nil, false
+	}
+
+	// The remote shell expands a leading ~, so only match what follows it.
+	expr := ""
+	rest := dir
+	if strings.HasPrefix(rest, "~") {
+		rest = rest[1:]
+	} else {
+		expr = "^"
+	}
+	// The first ${BRANCH} captures the branch name. Go's regexp has no
+	// backreferences, so any repeats only need to look like a path segment.
+	for j, p := range strings.Split(rest, "*") {
+		switch j {
+		case 0:
+		case 1:
+			expr += "([^/]+)"
+		default:
+			expr += "[^/]+"
+		}
+		expr += regexp.QuoteMeta(p)
+	}
+	expr += "$"
+
+	return quoteGlob(dir), regexp.MustCompile(expr), true
+}
+
+// renderPrunedHosts prints what was deleted from, or kept on, each host.
+func renderPrunedHosts(w io.Writer, out []PruneHostOutput, dryRun bool) {
+	successStyle := lipgloss.NewStyle().Foreground(ui.ColorSuccess)
+	errorStyle := lipgloss.NewStyle().Foreground(ui.ColorError)
+	mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
+
+	verb := "deleted"
+	if dryRun {
+		verb = "would delete"
+	}
+	for _, h := range out {
+		switch {
+		case h.Error != "":
+			fmt.Fprintf(w, "  %s %-20s %s\n", errorStyle.Render(ui.SymbolFail), h.Host, mutedStyle.Render(h.Error))
+			continue
+		case h.Skipped != "":
+			fmt.Fprintf(w, "  %s %-20s %s\n", mutedStyle.Render(ui.SymbolSkipped), h.Host, mutedStyle.Render(h.Skipped))
+			continue
+		case len(h.Removed) == 0:
+			fmt.Fprintf(w, "  %s %-20s %s\n", successStyle.Render(ui.SymbolSuccess), h.Host,
+				mutedStyle.Render(fmt.Sprintf("nothing to prune (%d branch dirs kept)", len(h.Kept))))
+			continue
+		}
+
+		var total int64
+		for _, d := range h.Removed {
+			if d.Bytes > 0 {
+				total += d.Bytes
+			}
+		}
+		fmt.Fprintf(w, "  %s %-20s %s %d branch dirs %s\n", successStyle.Render(ui.SymbolSuccess), h.Host, verb,
+			len(h.Removed), mutedStyle.Render(fmt.Sprintf("(%s, %d kept)", config.FormatSize(total), len(h.Kept))))
+		for _, d := range h.Removed {
+			fmt.Fprintf(w, "      %-24s %s\n", d.Branch, mutedStyle.Render(d.Path))
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/pkg/sshutil"
+	sshtesting "github.com/rileyhilliard/rr/pkg/sshutil/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBranchDirPattern(t *testing.T) {
+	glob, match, ok := branchDirPattern("~/rr/app/${BRANCH}/")
+	require.True(t, ok)
+	assert.Equal(t, "~/'rr/app/'*", glob)
+	assert.Equal(t, "feature-x", match.FindStringSubmatch("/home/a/rr/app/feature-x")[1])
+	assert.Nil(t, match.FindStringSubmatch("/home/a/rr/app/feature-x/nested"))
+	assert.Nil(t, match.FindStringSubmatch("/home/a/rr/other/main"))
+
+	_, match, ok = branchDirPattern("/scratch/${BRANCH}-build")
+	require.True(t, ok)
+	assert.Equal(t, "main", match.FindStringSubmatch("/scratch/main-build")[1])
+	assert.Nil(t, match.FindStringSubmatch("/other/scratch/main-build"), "absolute paths match from the root")
+
+	_, _, ok = branchDirPattern("~/rr/app")
+	assert.False(t, ok, "no ${BRANCH}")
+	_, _, ok = branchDirPattern("~/${BRANCH}")
+	assert.False(t, ok, "would glob the whole home directory")
+}
+
+// pruneClient returns a mock host holding main, feature-old, and current
+// branch dirs, and records the commands run against it.
+func pruneClient(t *testing.T) *recordingClient {
+	t.Helper()
+	client := &recordingClient{MockClient: sshtesting.NewMockClient("box")}
+	client.SetCommandResponse("^for p in", sshtesting.CommandResponse{Stdout: []byte(
+		"8\t/home/a/rr/app/main\n" +
+			"16\t/home/a/rr/app/feature-old\n" +
+			"4\t/home/a/rr/app/current\n")})
+	client.SetCommandResponse("^rm -rf ", sshtesting.CommandResponse{})
+
+	origConnect := connectForCleanup
+	connectForCleanup = func(string, config.Host) (sshutil.SSHClient, error) { return client, nil }
+	t.Cleanup(func() { connectForCleanup = origConnect })
+	return client
+}
+
+func TestPruneHostDirs(t *testing.T) {
+	keep := map[string]bool{"main": true, "current": true}
+	h := config.Host{SSH: []string{"box"}}
+
+	client := pruneClient(t)
+	result := pruneHostDirs("box", h, "~/rr/app/${BRANCH}", keep, true)
+	assert.Empty(t, result.Error)
+	assert.Equal(t, []PrunedDirOutput{{Branch: "feature-old", Path: "/home/a/rr/app/feature-old", Bytes: 16 * 1024}}, result.Removed)
+	assert.Len(t, result.Kept, 2)
+	require.Len(t, client.cmds, 1, "dry run deletes nothing")
+
+	client = pruneClient(t)
+	result = pruneHostDirs("box", h, "~/rr/app/${BRANCH}", keep, false)
+	assert.Empty(t, result.Error)
+	require.Len(t, client.cmds, 2)
+	assert.Equal(t, "rm -rf -- '/home/a/rr/app/feature-old'", client.cmds[1])
+
+	client = pruneClient(t)
+	result = pruneHostDirs("box", h, "~/rr/app", keep, false)
+	assert.NotEmpty(t, result.Skipped)
+	assert.Empty(t, client.cmds, "hosts without ${BRANCH} aren't contacted")
+}
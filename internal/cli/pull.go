@@ -28,7 +28,7 @@ func Pull(opts PullOptions) error {
 	phaseDisplay := ui.NewPhaseDisplay(os.Stdout)
 
 	// Load resolved config (global + project)
-	resolved, err := config.LoadResolved(Config())
+	resolved, err := loadResolved(Config())
 	if err != nil {
 		return err
 	}
@@ -93,9 +93,11 @@ func Pull(opts PullOptions) error {
 	}
 
 	// Build pull options
+	var warnings []string
 	pullOpts := sync.PullOptions{
 		Patterns:    pullItems,
 		DefaultDest: opts.Dest,
+		Warn:        func(msg string) { warnings = append(warnings, msg) },
 	}
 
 	// Add dry-run flag if requested
@@ -111,6 +113,7 @@ func Pull(opts PullOptions) error {
 
 	pullDuration := time.Since(pullStart)
 	spinner.Success()
+	showPullWarnings(warnings)
 
 	totalDuration := time.Since(startTime)
 
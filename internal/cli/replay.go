@@ -0,0 +1,214 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/history"
+	"github.com/rileyhilliard/rr/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	replayCastFlag      string
+	replaySpeedFlag     float64
+	replayIdleLimitFlag string
+	replayListFlag      bool
+)
+
+// replayCmd plays back the recorded output of an earlier run
+var replayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Replay the recorded output of a run",
+	Long: `Play back a run's output with its original timing.
+
+'rr run', 'rr exec', and tasks record their output with timestamps. The last
+20 recordings per project are kept under ~/.rr/history/. Replaying one shows
+the output exactly as it arrived, which helps when demoing a failure or
+chasing output that depends on timing.
+
+Recordings are asciicast v2 files, so 'asciinema play' can play them too.
+--cast also accepts the path to a .cast file.
+
+Examples:
+  rr replay                        # Replay the latest run
+  rr replay --list
+  rr replay --cast 20261016-153045
+  rr replay --cast 20261016-153045 --speed 4 --idle-limit 1s`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if replayListFlag {
+			return replayList(os.Stdout)
+		}
+		return replayCommand(os.Stdout, replayCastFlag, replaySpeedFlag, replayIdleLimitFlag)
+	},
+}
+
+func init() {
+	replayCmd.Flags().StringVar(&replayCastFlag, "cast", "", "recording to replay, by ID or .cast path (default: the latest)")
+	replayCmd.Flags().Float64Var(&replaySpeedFlag, "speed", 1, "playback speed multiplier (e.g., 2 for twice as fast)")
+	replayCmd.Flags().StringVar(&replayIdleLimitFlag, "idle-limit", "", "cut pauses longer than this (e.g., 1s)")
+	replayCmd.Flags().BoolVar(&replayListFlag, "list", false, "list this project's recordings")
+	rootCmd.AddCommand(replayCmd)
+}
+
+// ReplayCastOutput is the JSON representation of a recording.
+type ReplayCastOutput struct {
+	ID        string  `json:"id"`
+	Title     string  `json:"title"`
+	Host      string  `json:"host,omitempty"`
+	StartedAt string  `json:"started_at"`
+	Duration  float64 `json:"duration_s"`
+	ExitCode  *int    `json:"exit_code,omitempty"`
+}
+
+// replayProjectDir returns the project directory recordings are kept for:
+// where .rr.yaml is, or the current directory without one.
+func replayProjectDir() (string, error) {
+	if cfgPath, _ := config.Find(Config()); cfgPath != "" {
+		return filepath.Dir(cfgPath), nil
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", errors.WrapWithCode(err, errors.ErrConfig,
+			"Can't figure out what directory you're in",
+			"This is unusual - check your directory permissions.")
+	}
+	return wd, nil
+}
+
+// replayCommand plays back a recording, by ID or path, or the latest one.
+func replayCommand(w io.Writer, id string, speed float64, idleLimitFlag string) error {
+	if speed <= 0 {
+		return errors.New(errors.ErrConfig,
+			fmt.Sprintf("--speed must be more than 0, got %g", speed),
+			"Use 1 for real time, 2 for twice as fast, 0.5 for half speed.")
+	}
+	var idleLimit time.Duration
+	if idleLimitFlag != "" {
+		d, err := time.ParseDuration(idleLimitFlag)
+		if err != nil || d < 0 {
+			return errors.New(errors.ErrConfig,
+				fmt.Sprintf("Invalid --idle-limit '%s'", idleLimitFlag),
+				"Use a duration like 500ms or 2s.")
+		}
+		idleLimit = d
+	}
+
+	projectDir, err := replayProjectDir()
+	if err != nil {
+		return err
+	}
+	cast, err := history.LoadCast(projectDir, id)
+	if err != nil {
+		return err
+	}
+
+	// Ctrl+C stops playback cleanly
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if PrettyMode() {
+		mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
+		fmt.Fprintln(w, mutedStyle.Render(fmt.Sprintf("Replaying %s: %s", cast.ID, describeCast(cast.Header))))
+		fmt.Fprintln(w)
+	}
+
+	if err := playCast(ctx, w, cast, speed, idleLimit); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't write the replay",
+			"Check that the output isn't closed early (e.g., by a pipe).")
+	}
+	return nil
+}
+
+// playCast writes each event of a recording to w after its delay.
+// Returns ctx.Err() if playback is cancelled.
+func playCast(ctx context.Context, w io.Writer, cast *history.Cast, speed float64, idleLimit time.Duration) error {
+	delays := cast.Delays(speed, idleLimit)
+	for i, e := range cast.Events {
+		if d := delays[i]; d > 0 {
+			timer := time.NewTimer(d)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+		if _, err := io.WriteString(w, e.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replayList prints this project's recordings, newest first.
+func replayList(w io.Writer) error {
+	projectDir, err := replayProjectDir()
+	if err != nil {
+		return err
+	}
+	casts, err := history.ListCasts(projectDir)
+	if err != nil {
+		return err
+	}
+
+	if MachineMode() {
+		out := make([]ReplayCastOutput, 0, len(casts))
+		for i := len(casts) - 1; i >= 0; i-- {
+			h := casts[i].Header
+			out = append(out, ReplayCastOutput{
+				ID:        casts[i].ID,
+				Title:     h.Title,
+				Host:      h.Host,
+				StartedAt: time.Unix(h.Timestamp, 0).UTC().Format(time.RFC3339),
+				Duration:  h.Duration,
+				ExitCode:  h.ExitCode,
+			})
+		}
+		return WriteJSONSuccess(w, out)
+	}
+
+	if len(casts) == 0 {
+		fmt.Fprintln(w, "No recordings for this project yet.")
+		fmt.Fprintln(w, "Recordings are made by 'rr run', 'rr exec', and tasks.")
+		return nil
+	}
+
+	idStyle := lipgloss.NewStyle().Bold(true)
+	mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
+	for i := len(casts) - 1; i >= 0; i-- {
+		c := casts[i]
+		age := formatAge(time.Since(time.Unix(c.Header.Timestamp, 0)))
+		fmt.Fprintf(w, "  %s  %s  %s\n",
+			idStyle.Render(c.ID),
+			describeCast(c.Header),
+			mutedStyle.Render(age))
+	}
+	return nil
+}
+
+// describeCast summarizes a recording, e.g. "make test on mini (12.3s, exit 1)".
+func describeCast(h history.CastHeader) string {
+	s := h.Title
+	if h.Host != "" {
+		s += " on " + h.Host
+	}
+	s += fmt.Sprintf(" (%.1fs", h.Duration)
+	if h.ExitCode != nil {
+		s += fmt.Sprintf(", exit %d", *h.ExitCode)
+	}
+	return s + ")"
+}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/history"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlayCast(t *testing.T) {
+	cast := &history.Cast{Events: []history.CastEvent{
+		{Time: 0, Data: "one\n"},
+		{Time: time.Second, Data: "two\n"},
+		{Time: time.Hour, Data: "three\n"},
+	}}
+
+	var out bytes.Buffer
+	start := time.Now()
+	require.NoError(t, playCast(context.Background(), &out, cast, 100, 20*time.Millisecond))
+	assert.Equal(t, "one\ntwo\nthree\n", out.String())
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestPlayCast_Cancelled(t *testing.T) {
+	cast := &history.Cast{Events: []history.CastEvent{
+		{Time: 0, Data: "one\n"},
+		{Time: time.Hour, Data: "two\n"},
+	}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	var out bytes.Buffer
+	err := playCast(ctx, &out, cast, 1, 0)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, "one\n", out.String())
+}
+
+func TestReplayCommand_InvalidFlags(t *testing.T) {
+	err := replayCommand(&bytes.Buffer{}, "", 0, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--speed must be more than 0")
+
+	err = replayCommand(&bytes.Buffer{}, "", 1, "soon")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Invalid --idle-limit 'soon'")
+}
+
+func TestDescribeCast(t *testing.T) {
+	exitCode := 1
+	assert.Equal(t, "make test on mini (12.3s, exit 1)",
+		describeCast(history.CastHeader{Title: "make test", Host: "mini", Duration: 12.34, ExitCode: &exitCode}))
+	assert.Equal(t, "demo (0.5s)", describeCast(history.CastHeader{Title: "demo", Duration: 0.5}))
+}
@@ -0,0 +1,366 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/doctor"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/history"
+	"github.com/rileyhilliard/rr/internal/parallel/logs"
+	"github.com/rileyhilliard/rr/internal/report"
+	"github.com/rileyhilliard/rr/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// reportLogFileBytes caps each parallel log file in a report. Like run
+// output, only the tail is kept since that's where failures show up.
+const reportLogFileBytes = 64 * 1024
+
+// reportCmd implements `rr report` for gathering failure context.
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Bundle the last run's context for a bug report",
+	Long: `Gather everything needed to debug a failed run into one bundle:
+
+  - Output and timing of the last run in this project
+  - Project and global config, with secrets redacted
+  - Doctor results
+  - Environment info (rr version, OS, shell, local rsync and ssh)
+  - Logs from the most recent parallel run
+
+The bundle is written as a tarball (./rr-report-<timestamp>.tar.gz by
+default) containing each file plus a report.md with everything in one place,
+ready to paste into an issue.
+
+Env values, secret-looking keys, URL passwords, tokens, and private keys are
+redacted automatically, but review the bundle before sharing it.
+
+With --upload, report.md is uploaded as a secret GitHub gist using the gh CLI,
+after confirmation.
+
+Examples:
+  rr report                       # Write ./rr-report-<timestamp>.tar.gz
+  rr report -o /tmp/report.tar.gz # Choose where to write it
+  rr report --upload              # Also upload as a secret gist`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return reportCommand()
+	},
+}
+
+var (
+	reportOutput     string
+	reportUpload     bool
+	reportYes        bool
+	reportSkipDoctor bool
+)
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+
+	reportCmd.Flags().StringVarP(&reportOutput, "output", "o", "", "where to write the tarball (default ./rr-report-<timestamp>.tar.gz)")
+	reportCmd.Flags().BoolVar(&reportUpload, "upload", false, "upload report.md as a secret GitHub gist (requires gh)")
+	reportCmd.Flags().BoolVarP(&reportYes, "yes", "y", false, "upload without asking for confirmation")
+	reportCmd.Flags().BoolVar(&reportSkipDoctor, "skip-doctor", false, "don't run doctor checks (they contact every host)")
+}
+
+// reportResult is the JSON output of `rr report`.
+type reportResult struct {
+	Path    string   `json:"path"`
+	Files   []string `json:"files"`
+	GistURL string   `json:"gist_url,omitempty"`
+}
+
+// uploadGist uploads a file as a secret gist and returns its URL.
+// Swappable for tests.
+var uploadGist = func(path, description string) (string, error) {
+	if _, err := exec.LookPath("gh"); err != nil {
+		return "", errors.New(errors.ErrConfig,
+			"Uploading needs the GitHub CLI (gh), which isn't installed",
+			"Install it from https://cli.github.com, or attach the tarball to your issue by hand.")
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("gh", "gist", "create", "--desc", description, path)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errors.WrapWithCode(err, errors.ErrExec,
+			"Couldn't upload the report: "+strings.TrimSpace(stderr.String()),
+			"Check 'gh auth status', or attach the tarball to your issue by hand.")
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func reportCommand() error {
+	home, _ := os.UserHomeDir()
+	bundle := report.NewBundle(time.Now(), home)
+
+	var spinner *ui.Spinner
+	if PrettyMode() && ui.IsTerminal(os.Stdout) {
+		spinner = ui.NewSpinner("Gathering report")
+		spinner.Start()
+	}
+	gatherReport(bundle, !reportSkipDoctor)
+	if spinner != nil {
+		spinner.Success()
+		fmt.Println()
+	}
+
+	dest := reportOutput
+	if dest == "" {
+		dest = bundle.Name() + ".tar.gz"
+	}
+	if err := bundle.WriteTarball(dest); err != nil {
+		return err
+	}
+
+	result := reportResult{Path: dest}
+	for _, f := range bundle.Files {
+		result.Files = append(result.Files, f.Name)
+	}
+
+	if PrettyMode() {
+		printReportSummary(result)
+	}
+
+	if reportUpload {
+		url, err := uploadReport(bundle)
+		if err != nil {
+			return err
+		}
+		result.GistURL = url
+		if PrettyMode() && url != "" {
+			fmt.Printf("\nUploaded: %s\n", url)
+		}
+	}
+
+	if MachineMode() {
+		return WriteJSONSuccess(os.Stdout, result)
+	}
+	return nil
+}
+
+// uploadReport confirms with the user, then uploads the bundle's report.md.
+// Returns an empty URL if the user declines.
+func uploadReport(bundle *report.Bundle) (string, error) {
+	if !reportYes {
+		if !canPrompt() {
+			return "", needsInput("Not uploading without confirmation",
+				"Pass --yes to upload non-interactively.")
+		}
+		var confirm bool
+		form := huh.NewForm(
+			huh.NewGroup(
+				huh.NewConfirm().
+					Title("Upload report.md as a secret gist?").
+					Description("Anyone with the link can read it. Review the tarball first if you haven't.").
+					Value(&confirm),
+			),
+		)
+		if err := form.Run(); err != nil {
+			return "", errors.WrapWithCode(err, errors.ErrConfig,
+				"Couldn't get your input",
+				"Pass --yes to skip the prompt.")
+		}
+		if !confirm {
+			if PrettyMode() {
+				fmt.Println("Not uploaded.")
+			}
+			return "", nil
+		}
+	}
+
+	tmpDir, err := os.MkdirTemp("", "rr-report-")
+	if err != nil {
+		return "", errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't create a temp file for the upload",
+			"Check that your temp directory is writable.")
+	}
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, bundle.Name()+".md")
+	if err := os.WriteFile(path, bundle.Markdown(), 0600); err != nil {
+		return "", errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't create a temp file for the upload",
+			"Check that your temp directory is writable.")
+	}
+	return uploadGist(path, "rr report "+bundle.Created.Format("2006-01-02 15:04"))
+}
+
+func printReportSummary(result reportResult) {
+	mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
+
+	fmt.Printf("Wrote %s\n\n", result.Path)
+	for _, f := range result.Files {
+		fmt.Printf("  %s\n", f)
+	}
+	fmt.Println()
+	fmt.Println(mutedStyle.Render("Secrets were redacted automatically, but review the bundle before sharing it."))
+	if !reportUpload {
+		fmt.Println(mutedStyle.Render("Run 'rr report --upload' to share it as a secret gist."))
+	}
+}
+
+// gatherReport adds everything rr knows about the current project to the
+// bundle. Each part is best effort: a missing config or unreadable log is
+// left out rather than failing the whole report, since reports are most
+// needed when things are broken.
+func gatherReport(bundle *report.Bundle, runDoctor bool) {
+	cfgPath, _ := config.Find(Config())
+	projectDir := ""
+	if cfgPath != "" {
+		projectDir = filepath.Dir(cfgPath)
+	} else if wd, err := os.Getwd(); err == nil {
+		projectDir = wd
+	}
+	globalPath, _ := config.GlobalConfigPath()
+
+	bundle.Add("environment.txt", []byte(reportEnvironment(cfgPath, globalPath)))
+
+	if projectDir != "" {
+		bundle.Add("last-run.txt", []byte(reportLastRun(projectDir)))
+	}
+
+	if cfgPath != "" {
+		if data, err := os.ReadFile(cfgPath); err == nil {
+			bundle.AddConfig("config/project.yaml", data)
+		}
+	}
+	if globalPath != "" {
+		if data, err := os.ReadFile(globalPath); err == nil {
+			bundle.AddConfig("config/global.yaml", data)
+		}
+	}
+
+	if runDoctor {
+		globalCfg, _ := config.LoadGlobal()
+		results := doctor.RunAll(collectChecks(cfgPath, nil, globalCfg))
+		bundle.Add("doctor.txt", []byte(formatDoctorPlain(results)))
+	}
+
+	addLatestLogs(bundle)
+}
+
+// reportEnvironment describes the local machine and rr build.
+func reportEnvironment(cfgPath, globalPath string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "rr:           %s (commit %s, built %s)\n", version, commit, date)
+	fmt.Fprintf(&b, "go:           %s\n", runtime.Version())
+	fmt.Fprintf(&b, "os/arch:      %s/%s\n", runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "shell:        %s\n", valueOr(os.Getenv("SHELL"), "unknown"))
+	fmt.Fprintf(&b, "term:         %s\n", valueOr(os.Getenv("TERM"), "unknown"))
+	fmt.Fprintf(&b, "ci:           %t\n", os.Getenv("CI") != "")
+	fmt.Fprintf(&b, "rsync:        %s\n", commandFirstLine("rsync", "--version"))
+	fmt.Fprintf(&b, "ssh:          %s\n", commandFirstLine("ssh", "-V"))
+	fmt.Fprintf(&b, "project cfg:  %s\n", valueOr(cfgPath, "none found"))
+	fmt.Fprintf(&b, "global cfg:   %s\n", valueOr(globalPath, "unknown"))
+	return b.String()
+}
+
+// commandFirstLine runs a local command and returns the first line it prints
+// on stdout or stderr (ssh -V prints to stderr).
+func commandFirstLine(name string, args ...string) string {
+	if _, err := exec.LookPath(name); err != nil {
+		return "not installed"
+	}
+	out, _ := exec.Command(name, args...).CombinedOutput()
+	line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return valueOr(strings.TrimSpace(line), "unknown")
+}
+
+// reportLastRun describes the most recent run in the project and its output.
+func reportLastRun(projectDir string) string {
+	var b strings.Builder
+
+	entries, _ := history.Load(projectDir)
+	if len(entries) == 0 {
+		b.WriteString("No runs recorded for this project.\n")
+	} else {
+		last := entries[len(entries)-1]
+		fmt.Fprintf(&b, "what:   %s\n", last.Key)
+		fmt.Fprintf(&b, "host:   %s\n", last.Host)
+		fmt.Fprintf(&b, "when:   %s\n", last.Time.Format(time.RFC3339))
+		fmt.Fprintf(&b, "exit:   %d\n", last.ExitCode)
+		fmt.Fprintf(&b, "phases: %s\n", formatRunSummary(last.Phases, last.Total, nil))
+		if last.ExitCode == 0 {
+			b.WriteString("\nNote: the last run succeeded.\n")
+		}
+	}
+
+	output, _ := history.LoadOutput(projectDir)
+	if len(output) > 0 {
+		b.WriteString("\n--- output ---\n")
+		b.Write(output)
+	}
+	return b.String()
+}
+
+// formatDoctorPlain renders doctor results without colors or symbols, e.g.
+// "[fail] ssh_agent: No SSH agent running (Start one with: eval $(ssh-agent))".
+func formatDoctorPlain(results []doctor.CheckResult) string {
+	var b strings.Builder
+	for _, r := range results {
+		fmt.Fprintf(&b, "[%s] %s: %s", r.Status, r.Name, r.Message)
+		if r.Suggestion != "" && r.Status != doctor.StatusPass {
+			fmt.Fprintf(&b, " (%s)", r.Suggestion)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// addLatestLogs adds the files of the most recent parallel run's log
+// directory, keeping the tail of each.
+func addLatestLogs(bundle *report.Bundle) {
+	baseDir := GetGlobalLogsConfig().Dir
+	if baseDir == "" {
+		baseDir = "~/.rr/logs"
+	}
+	dirs, err := logs.ListLogDirs(baseDir)
+	if err != nil || len(dirs) == 0 {
+		return
+	}
+	latest := dirs[0]
+
+	entries, err := os.ReadDir(latest.Path)
+	if err != nil {
+		return
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(latest.Path, name))
+		if err != nil {
+			continue
+		}
+		if over := len(data) - reportLogFileBytes; over > 0 {
+			data = append([]byte("[... earlier output truncated ...]\n"), data[over:]...)
+		}
+		bundle.Add("logs/"+latest.Name+"/"+name, data)
+	}
+}
+
+// valueOr returns s, or fallback if s is empty.
+func valueOr(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/doctor"
+	"github.com/rileyhilliard/rr/internal/history"
+	"github.com/rileyhilliard/rr/internal/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportLastRun(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	project := t.TempDir()
+
+	assert.Equal(t, "No runs recorded for this project.\n", reportLastRun(project))
+
+	require.NoError(t, history.Append(project, history.Entry{
+		Key:      history.TaskKey("test"),
+		Host:     "mini",
+		Time:     time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC),
+		ExitCode: 1,
+		Phases:   map[string]time.Duration{"exec": 2 * time.Second},
+		Total:    2 * time.Second,
+	}))
+	require.NoError(t, history.SaveOutput(project, []byte("FAIL: TestThing\n")))
+
+	got := reportLastRun(project)
+	assert.Contains(t, got, "what:   task:test")
+	assert.Contains(t, got, "exit:   1")
+	assert.Contains(t, got, "phases: exec 2.0s · total 2.0s")
+	assert.Contains(t, got, "--- output ---\nFAIL: TestThing\n")
+	assert.NotContains(t, got, "succeeded")
+}
+
+func TestFormatDoctorPlain(t *testing.T) {
+	got := formatDoctorPlain([]doctor.CheckResult{
+		{Name: "config_file", Status: doctor.StatusPass, Message: "Found .rr.yaml", Suggestion: "ignored"},
+		{Name: "ssh_agent", Status: doctor.StatusFail, Message: "No SSH agent running", Suggestion: "Start one"},
+	})
+	assert.Equal(t, "[pass] config_file: Found .rr.yaml\n[fail] ssh_agent: No SSH agent running (Start one)\n", got)
+}
+
+func TestGatherReport(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	project := t.TempDir()
+	t.Chdir(project)
+
+	require.NoError(t, os.WriteFile(filepath.Join(project, ".rr.yaml"),
+		[]byte("version: 1\nenv:\n  DATABASE_URL: postgres://app:pw@db/app\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".rr"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".rr", "config.yaml"), []byte("version: 1\n"), 0644))
+
+	bundle := report.NewBundle(time.Now(), home)
+	gatherReport(bundle, false)
+
+	files := make(map[string]string)
+	for _, f := range bundle.Files {
+		files[f.Name] = string(f.Content)
+	}
+	assert.Contains(t, files, "environment.txt")
+	assert.Contains(t, files, "last-run.txt")
+	assert.Contains(t, files, "config/global.yaml")
+	assert.NotContains(t, files, "doctor.txt")
+	require.Contains(t, files, "config/project.yaml")
+	assert.NotContains(t, files["config/project.yaml"], "postgres://")
+}
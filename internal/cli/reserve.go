@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	rrerrors "github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/rileyhilliard/rr/internal/lock"
+)
+
+// workflowReserve returns what the workflow's task reserves on its host.
+func workflowReserve(ctx *WorkflowContext, opts WorkflowOptions) config.ReserveConfig {
+	if task := workflowTask(ctx, opts); task != nil {
+		return task.Reserve
+	}
+	return config.ReserveConfig{}
+}
+
+// workflowLockConfig returns the project's lock config, or the defaults.
+// Reservations live next to the locks and expire after the same lock.stale.
+func workflowLockConfig(ctx *WorkflowContext) config.LockConfig {
+	if ctx.Resolved != nil && ctx.Resolved.Project != nil {
+		return ctx.Resolved.Project.Lock
+	}
+	return config.DefaultConfig().Lock
+}
+
+// describeReserve formats a reservation, e.g. "8 CPUs and 16GB".
+func describeReserve(r config.ReserveConfig) string {
+	var parts []string
+	if r.CPUs > 0 {
+		unit := "CPUs"
+		if r.CPUs == 1 {
+			unit = "CPU"
+		}
+		parts = append(parts, fmt.Sprintf("%g %s", r.CPUs, unit))
+	}
+	if r.Memory != "" {
+		parts = append(parts, r.Memory)
+	}
+	return strings.Join(parts, " and ")
+}
+
+// hostHasRoom reports whether the task's reservation fits on conn, and if
+// not, what's free there. Hosts whose capacity can't be read are given the
+// benefit of the doubt.
+func hostHasRoom(conn *host.Connection, lockCfg config.LockConfig, r config.ReserveConfig) (bool, string) {
+	capacity, err := lock.HostCapacity(conn, lockCfg)
+	if err != nil {
+		return true, ""
+	}
+	if capacity.Fits(r.CPUs, r.MemoryBytes()) {
+		return true, ""
+	}
+	return false, capacity.String()
+}
+
+// reservePhase claims the task's CPUs and memory on the connected host. It
+// does nothing for tasks that don't reserve or for local runs.
+func reservePhase(ctx *WorkflowContext, opts WorkflowOptions) error {
+	r := workflowReserve(ctx, opts)
+	if r.IsZero() || ctx.Conn.IsLocal {
+		return nil
+	}
+
+	start := time.Now()
+	reporter := ctx.GetReporter()
+	if !PrettyMode() {
+		reporter.PhaseStart("reserve")
+	}
+
+	reservation, err := lock.Reserve(ctx.Conn, workflowLockConfig(ctx), opts.Command, r.CPUs, r.MemoryBytes())
+	if err != nil {
+		err = reserveError(ctx.Conn.Name, r, err)
+		if PrettyMode() {
+			ctx.PhaseDisplay.RenderFailed("Reserve "+describeReserve(r), time.Since(start), err)
+		} else {
+			reporter.PhaseFailed("reserve", err)
+		}
+		return err
+	}
+	reservation.StartHeartbeat()
+	ctx.Reservation = reservation
+
+	if PrettyMode() {
+		ctx.PhaseDisplay.RenderSuccess("Reserved "+describeReserve(r), time.Since(start))
+	} else {
+		reporter.PhaseComplete("reserve", ctx.Conn.Name, time.Since(start))
+	}
+	ctx.recordPhase("reserve", time.Since(start))
+	return nil
+}
+
+// reserveError turns a failed lock.Reserve into a user-facing error.
+func reserveError(hostName string, r config.ReserveConfig, err error) error {
+	if errors.Is(err, lock.ErrNoCapacity) {
+		return rrerrors.New(rrerrors.ErrLock,
+			fmt.Sprintf("Host '%s' doesn't have %s unreserved (%s)", hostName, describeReserve(r), strings.TrimPrefix(err.Error(), lock.ErrNoCapacity.Error()+": ")),
+			"Wait for other runs to release their reservations, or lower the task's reserve.").
+			WithID(rrerrors.IDLockNoCapacity)
+	}
+	return rrerrors.WrapWithCode(err, rrerrors.ErrLock,
+		fmt.Sprintf("Couldn't reserve %s on '%s'", describeReserve(r), hostName),
+		"Check your SSH connection and the permissions on lock.dir.")
+}
+
+// buildAllHostsFullError builds the error for when every host that could
+// be reached is too reserved for the task.
+func buildAllHostsFullError(fullHosts []hostAttempt, r config.ReserveConfig) error {
+	hosts := make([]string, 0, len(fullHosts))
+	for _, a := range fullHosts {
+		hosts = append(hosts, fmt.Sprintf("%s (%s)", a.hostName, a.capacity))
+	}
+	return rrerrors.New(rrerrors.ErrLock,
+		fmt.Sprintf("No host has %s unreserved", describeReserve(r)),
+		fmt.Sprintf("Hosts: %s. Wait for other runs to release their reservations, or lower the task's reserve.", strings.Join(hosts, ", "))).
+		WithID(rrerrors.IDLockNoCapacity)
+}
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	rrerrors "github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/lock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribeReserve(t *testing.T) {
+	assert.Equal(t, "8 CPUs and 16GB", describeReserve(config.ReserveConfig{CPUs: 8, Memory: "16GB"}))
+	assert.Equal(t, "1 CPU", describeReserve(config.ReserveConfig{CPUs: 1}))
+	assert.Equal(t, "512MB", describeReserve(config.ReserveConfig{Memory: "512MB"}))
+}
+
+func TestWorkflowReserve(t *testing.T) {
+	ctx := &WorkflowContext{Resolved: &config.ResolvedConfig{Project: &config.Config{
+		Tasks: map[string]config.TaskConfig{
+			"bench": {Run: "make bench", Reserve: config.ReserveConfig{CPUs: 4}},
+		},
+	}}}
+
+	assert.Equal(t, 4.0, workflowReserve(ctx, WorkflowOptions{TaskName: "bench"}).CPUs)
+	assert.True(t, workflowReserve(ctx, WorkflowOptions{Command: "make bench"}).IsZero())
+}
+
+func TestReserveError(t *testing.T) {
+	r := config.ReserveConfig{CPUs: 8}
+
+	err := reserveError("gpu-box", r, fmt.Errorf("%w: 4 of 16 CPUs free", lock.ErrNoCapacity))
+	var rrErr *rrerrors.Error
+	require.ErrorAs(t, err, &rrErr)
+	assert.Equal(t, rrerrors.IDLockNoCapacity, rrErr.ID)
+	assert.Contains(t, rrErr.Message, "Host 'gpu-box' doesn't have 8 CPUs unreserved (4 of 16 CPUs free)")
+
+	err = reserveError("gpu-box", r, fmt.Errorf("connection reset"))
+	assert.Contains(t, err.Error(), "Couldn't reserve 8 CPUs on 'gpu-box'")
+}
+
+func TestBuildAllHostsFullError(t *testing.T) {
+	err := buildAllHostsFullError([]hostAttempt{
+		{hostName: "a", capacity: "2 of 8 CPUs free"},
+		{hostName: "b", capacity: "0 of 16 CPUs free"},
+	}, config.ReserveConfig{CPUs: 4})
+
+	var rrErr *rrerrors.Error
+	require.ErrorAs(t, err, &rrErr)
+	assert.Equal(t, rrerrors.IDLockNoCapacity, rrErr.ID)
+	assert.Equal(t, "No host has 4 CPUs unreserved", rrErr.Message)
+	assert.Contains(t, rrErr.Suggestion, "a (2 of 8 CPUs free), b (0 of 16 CPUs free)")
+}
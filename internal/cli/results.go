@@ -0,0 +1,304 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/deps"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/logger"
+	"github.com/rileyhilliard/rr/internal/parallel"
+	"github.com/rileyhilliard/rr/internal/parallel/logs"
+	"github.com/rileyhilliard/rr/internal/results"
+	"github.com/rileyhilliard/rr/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	resultsLastFlag  bool
+	resultsTaskFlag  string
+	resultsLimitFlag int
+)
+
+// resultsCmd shows the recorded results of earlier runs
+var resultsCmd = &cobra.Command{
+	Use:   "results [run-id]",
+	Short: "Show the results of earlier runs",
+	Long: `Show what earlier runs did: each task's status, host, duration, test
+counts, and the files it pulled back.
+
+Every 'rr run' and task writes its results to .rr/results/<run-id>.json in
+the project, and the last 100 runs are kept. With no arguments, lists recent
+runs; pass a run ID or --last to see one run task by task. With structured
+output (the default), the runs are printed as JSON for CI and trend reports.
+
+Examples:
+  rr results                 # Recent runs, newest first
+  rr results --last          # The latest run, task by task
+  rr results --task test     # Recent runs of the test task
+  rr results 20261016-153045-123`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id := ""
+		if len(args) == 1 {
+			id = args[0]
+		}
+		return resultsCommand(os.Stdout, id, resultsLastFlag, resultsTaskFlag, resultsLimitFlag)
+	},
+}
+
+func init() {
+	resultsCmd.Flags().BoolVar(&resultsLastFlag, "last", false, "show the latest run in detail")
+	resultsCmd.Flags().StringVar(&resultsTaskFlag, "task", "", "only runs of this task (or rr run command)")
+	resultsCmd.Flags().IntVarP(&resultsLimitFlag, "limit", "n", 20, "how many runs to list (0 for all)")
+	rootCmd.AddCommand(resultsCmd)
+}
+
+// resultsCommand lists the project's runs, or shows one: by ID, or the
+// latest with last.
+func resultsCommand(w io.Writer, id string, last bool, task string, limit int) error {
+	projectDir, err := replayProjectDir()
+	if err != nil {
+		return err
+	}
+
+	if id != "" {
+		run, err := results.Load(projectDir, id)
+		if err != nil {
+			return err
+		}
+		return writeRunResults(w, run)
+	}
+
+	runs, err := results.List(projectDir)
+	if err != nil {
+		return err
+	}
+	// Newest first
+	filtered := make([]*results.Run, 0, len(runs))
+	for i := len(runs) - 1; i >= 0; i-- {
+		if task == "" || runs[i].Name == task {
+			filtered = append(filtered, runs[i])
+		}
+	}
+
+	if last {
+		if len(filtered) == 0 {
+			return errors.New(errors.ErrConfig,
+				"No results for this project yet",
+				"Results are recorded by 'rr run' and tasks.")
+		}
+		return writeRunResults(w, filtered[0])
+	}
+
+	if limit > 0 && len(filtered) > limit {
+		filtered = filtered[:limit]
+	}
+	if MachineMode() {
+		return WriteJSONSuccess(w, filtered)
+	}
+	if len(filtered) == 0 {
+		fmt.Fprintln(w, "No results for this project yet.")
+		fmt.Fprintln(w, "Results are recorded by 'rr run' and tasks.")
+		return nil
+	}
+
+	idStyle := lipgloss.NewStyle().Bold(true)
+	mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
+	for _, run := range filtered {
+		line := fmt.Sprintf("  %s  %s %s  %s", idStyle.Render(run.ID), statusSymbol(run.Status), run.Name, ui.FormatElapsed(secondsDuration(run.Duration)))
+		if tests := describeTests(totalTests(run.Tasks)); tests != "" {
+			line += "  " + tests
+		}
+		fmt.Fprintln(w, line+"  "+mutedStyle.Render(formatAge(time.Since(run.StartedAt))))
+	}
+	return nil
+}
+
+// writeRunResults prints one run task by task, or as JSON.
+func writeRunResults(w io.Writer, run *results.Run) error {
+	if MachineMode() {
+		return WriteJSONSuccess(w, run)
+	}
+
+	mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
+	fmt.Fprintf(w, "%s %s\n", lipgloss.NewStyle().Bold(true).Render(run.Name),
+		mutedStyle.Render(fmt.Sprintf("(%s %s, %s, %s)", run.Kind, run.ID, run.Status, formatAge(time.Since(run.StartedAt)))))
+	for _, t := range run.Tasks {
+		line := fmt.Sprintf("  %s %s", statusSymbol(t.Status), t.Name)
+		if t.Host != "" {
+			line += " on " + t.Host
+		}
+		line += "  " + ui.FormatElapsed(secondsDuration(t.Duration))
+		if t.ExitCode != 0 {
+			line += fmt.Sprintf("  exit %d", t.ExitCode)
+		}
+		fmt.Fprintln(w, line)
+		if t.Error != "" {
+			fmt.Fprintln(w, mutedStyle.Render("      "+t.Error))
+		}
+		if t.Tests != nil {
+			fmt.Fprintln(w, mutedStyle.Render(fmt.Sprintf("      %s (%s)", describeTests(t.Tests), t.Tests.Source)))
+		}
+		for _, a := range t.Artifacts {
+			if a.Kind == results.ArtifactPull {
+				fmt.Fprintln(w, mutedStyle.Render(fmt.Sprintf("      pulled %s → %s", a.Src, a.Path)))
+			} else {
+				fmt.Fprintln(w, mutedStyle.Render(fmt.Sprintf("      %s: %s", a.Kind, a.Path)))
+			}
+		}
+	}
+	return nil
+}
+
+// statusSymbol returns the symbol for a passed or failed status.
+func statusSymbol(status string) string {
+	if status == results.StatusPassed {
+		return lipgloss.NewStyle().Foreground(ui.ColorSuccess).Render(ui.SymbolSuccess)
+	}
+	return lipgloss.NewStyle().Foreground(ui.ColorError).Render(ui.SymbolFail)
+}
+
+// totalTests adds up the test counts of a run's tasks. Returns nil if none
+// of them had any.
+func totalTests(tasks []results.Task) *results.TestCounts {
+	var total *results.TestCounts
+	for _, t := range tasks {
+		if t.Tests == nil {
+			continue
+		}
+		if total == nil {
+			total = &results.TestCounts{}
+		}
+		total.Passed += t.Tests.Passed
+		total.Failed += t.Tests.Failed
+		total.Skipped += t.Tests.Skipped
+		total.Errors += t.Tests.Errors
+	}
+	return total
+}
+
+// describeTests renders test counts like "120 passed, 2 failed", leaving
+// out the zero ones. Returns "" for nil.
+func describeTests(c *results.TestCounts) string {
+	if c == nil {
+		return ""
+	}
+	var parts []string
+	for _, part := range []struct {
+		n     int
+		label string
+	}{{c.Passed, "passed"}, {c.Failed, "failed"}, {c.Skipped, "skipped"}, {c.Errors, "errors"}} {
+		if part.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", part.n, part.label))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// secondsDuration converts seconds from a results file to a duration.
+func secondsDuration(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// saveRunResults records a single-host run for 'rr results'. key is the
+// run's history key. Like history, failures are ignored; they never fail
+// a run.
+func saveRunResults(wf *WorkflowContext, key string, exitCode int) {
+	name, kind, command := key, results.KindRun, ""
+	if cmd, ok := strings.CutPrefix(key, "run:"); ok {
+		name, command = cmd, cmd
+	} else if task, ok := strings.CutPrefix(key, "task:"); ok {
+		name, kind = task, results.KindTask
+		if wf.Resolved != nil && wf.Resolved.Project != nil {
+			command = wf.Resolved.Project.Tasks[task].Run
+		}
+	}
+
+	run := results.NewRun(name, kind, wf.StartTime)
+	if len(wf.depTasks) > 0 {
+		run.Tasks = append(run.Tasks, wf.depTasks...)
+		// Pulls happen after the whole run, so they belong to the task asked for
+		for i := range run.Tasks {
+			if run.Tasks[i].Name == name {
+				run.Tasks[i].Artifacts = wf.Pulled
+			}
+		}
+	} else {
+		var out []byte
+		if wf.Output != nil {
+			out = wf.Output.Bytes()
+		}
+		task := results.NewTask(name, command, wf.Conn.Name, exitCode, wf.Phases["exec"], out)
+		task.Artifacts = wf.Pulled
+		run.Tasks = append(run.Tasks, task)
+	}
+	run.Finish(exitCode, time.Now())
+	writeResults(wf.WorkDir, run)
+}
+
+// depResultTasks returns the record of each task a run with dependencies
+// ran, in the order they ran. Their output is interleaved, so they have no
+// test counts.
+func depResultTasks(hostName string, result *deps.ExecutionResult, taskCfgs map[string]config.TaskConfig) []results.Task {
+	var tasks []results.Task
+	for _, stage := range result.StageResults {
+		for _, name := range stage.Stage.Tasks {
+			tr, ok := stage.TaskResults[name]
+			if !ok {
+				continue
+			}
+			task := results.NewTask(name, taskCfgs[name].Run, hostName, tr.ExitCode, tr.Duration, nil)
+			if tr.Error != nil {
+				task.Error = tr.Error.Error()
+				task.Status = results.StatusFailed
+			}
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks
+}
+
+// saveParallelResults records a parallel run for 'rr results', one entry
+// per subtask, with its saved log when there is one.
+func saveParallelResults(projectRoot, name string, result *parallel.Result, logWriter *logs.LogWriter) {
+	run := results.NewRun(name, results.KindParallel, time.Now().Add(-result.Duration))
+	for i := range result.TaskResults {
+		tr := &result.TaskResults[i]
+		task := results.NewTask(tr.TaskName, tr.Command, tr.Host, tr.ExitCode, tr.Duration, tr.Output)
+		if tr.Error != nil {
+			task.Error = tr.Error.Error()
+			task.Status = results.StatusFailed
+		}
+		if logWriter != nil {
+			task.Artifacts = append(task.Artifacts, results.Artifact{Kind: results.ArtifactLog, Path: logWriter.TaskPath(tr.TaskName, tr.TaskIndex)})
+		}
+		run.Tasks = append(run.Tasks, task)
+	}
+	exitCode := 0
+	if result.Failed > 0 {
+		exitCode = 1
+	}
+	run.Finish(exitCode, time.Now())
+	writeResults(projectRoot, run)
+}
+
+// writeResults saves a run's results in the project, or the current
+// directory without one.
+func writeResults(projectRoot string, run *results.Run) {
+	if projectRoot == "" {
+		if wd, err := os.Getwd(); err == nil {
+			projectRoot = wd
+		}
+	}
+	if err := results.Write(projectRoot, run); err != nil {
+		logger.Verbosef(logger.LevelPhases, "results", "not saved: %v", err)
+		return
+	}
+	logger.Verbosef(logger.LevelPhases, "results", "saved run %s", run.ID)
+}
@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/parallel"
+	"github.com/rileyhilliard/rr/internal/results"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// inTempProject runs the rest of the test from an empty project directory.
+func inTempProject(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(root))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+	return root
+}
+
+func TestSaveParallelResults(t *testing.T) {
+	root := t.TempDir()
+	result := &parallel.Result{
+		Duration: 4 * time.Second,
+		Passed:   1,
+		Failed:   1,
+		TaskResults: []parallel.TaskResult{
+			{TaskName: "lint", Command: "make lint", Host: "mini", Duration: time.Second},
+			{
+				TaskName: "test-go",
+				Command:  "go test ./...",
+				Host:     "gpu",
+				ExitCode: 1,
+				Duration: 3 * time.Second,
+				Output:   []byte("=== RUN   TestFail\n--- FAIL: TestFail (0.00s)\nFAIL\nFAIL\texample\t0.005s\n"),
+			},
+		},
+	}
+
+	saveParallelResults(root, "ci", result, nil)
+
+	runs, err := results.List(root)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	run := runs[0]
+	assert.Equal(t, "ci", run.Name)
+	assert.Equal(t, results.KindParallel, run.Kind)
+	assert.Equal(t, results.StatusFailed, run.Status)
+	require.Len(t, run.Tasks, 2)
+	assert.Equal(t, results.StatusPassed, run.Tasks[0].Status)
+	assert.Equal(t, "gpu", run.Tasks[1].Host)
+	assert.Equal(t, &results.TestCounts{Failed: 1, Source: "gotest"}, run.Tasks[1].Tests)
+}
+
+func TestResultsCommand(t *testing.T) {
+	root := inTempProject(t)
+	start := time.Now().Add(-time.Hour)
+	for i, name := range []string{"test", "lint", "test"} {
+		run := results.NewRun(name, results.KindTask, start.Add(time.Duration(i)*time.Minute))
+		run.Tasks = append(run.Tasks, results.Task{Name: name, Host: "mini", Status: results.StatusPassed,
+			Tests: &results.TestCounts{Passed: 10 + i, Source: "pytest"}})
+		run.Finish(0, start.Add(time.Duration(i)*time.Minute+time.Second))
+		require.NoError(t, results.Write(root, run))
+	}
+
+	t.Run("lists newest first as JSON", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, resultsCommand(&out, "", false, "test", 0))
+		var envelope struct {
+			Data []results.Run `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(out.Bytes(), &envelope))
+		require.Len(t, envelope.Data, 2)
+		assert.Equal(t, 12, envelope.Data[0].Tasks[0].Tests.Passed)
+	})
+
+	t.Run("last in pretty mode", func(t *testing.T) {
+		oldPretty := prettyMode
+		prettyMode = true
+		t.Cleanup(func() { prettyMode = oldPretty })
+
+		var out bytes.Buffer
+		require.NoError(t, resultsCommand(&out, "", true, "lint", 20))
+		assert.Contains(t, out.String(), "lint on mini")
+		assert.Contains(t, out.String(), "11 passed (pytest)")
+	})
+
+	t.Run("unknown run", func(t *testing.T) {
+		err := resultsCommand(&bytes.Buffer{}, "nope", false, "", 20)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "No results for run 'nope'")
+	})
+}
+
+func TestDescribeTests(t *testing.T) {
+	assert.Equal(t, "120 passed, 2 failed", describeTests(&results.TestCounts{Passed: 120, Failed: 2}))
+	assert.Empty(t, describeTests(nil))
+}
@@ -0,0 +1,244 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/history"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/rileyhilliard/rr/internal/lock"
+	"github.com/rileyhilliard/rr/internal/logger"
+	"github.com/rileyhilliard/rr/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	resumeSync    bool
+	resumeDiscard bool
+)
+
+// resumeCmd picks up a task run that was interrupted
+var resumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "Finish a task run that was interrupted",
+	Long: `Pick up the last task run that didn't finish, because rr was interrupted,
+crashed, or the machine went to sleep or lost power mid-run.
+
+As a task runs, rr saves how far it got: which host it ran on, whether the
+project was synced, the lock it held, and which steps finished. rr resume
+runs the same task on the same host again, starting at the first step that
+didn't finish. A single-command task runs its command again.
+
+The lock the interrupted run left on the host is released first, but only if
+it's still the one that run took. The project isn't synced again if the
+interrupted run had already synced it; pass --sync to sync anyway.
+
+Examples:
+  rr resume              # Run the steps that didn't finish
+  rr resume --sync       # Sync first, then run them
+  rr resume --discard    # Forget the interrupted run and release its lock`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return resumeCommand(os.Stdout, resumeSync, resumeDiscard)
+	},
+}
+
+func init() {
+	resumeCmd.Flags().BoolVar(&resumeSync, "sync", false, "sync the project again before running the remaining steps")
+	resumeCmd.Flags().BoolVar(&resumeDiscard, "discard", false, "forget the interrupted run, releasing the lock it left, without running anything")
+	resumeCmd.MarkFlagsMutuallyExclusive("sync", "discard")
+	rootCmd.AddCommand(resumeCmd)
+}
+
+// ResumeDiscardOutput is the JSON representation of a discarded run.
+type ResumeDiscardOutput struct {
+	Task         string `json:"task"`
+	Host         string `json:"host"`
+	Done         int    `json:"done"`
+	Steps        int    `json:"steps"`
+	LockReleased bool   `json:"lock_released"`
+}
+
+// resumeCommand runs the steps of the project's interrupted task run that
+// didn't finish, or with discard, forgets the run.
+func resumeCommand(w io.Writer, sync, discard bool) error {
+	resolved, err := loadResolved(Config())
+	if err != nil {
+		return err
+	}
+	root := resolved.ProjectRoot
+	if root == "" {
+		if root, err = os.Getwd(); err != nil {
+			return errors.WrapWithCode(err, errors.ErrExec,
+				"Can't figure out what directory you're in",
+				"This is unusual - check your directory permissions.")
+		}
+	}
+
+	cp, err := history.LoadCheckpoint(root)
+	if err != nil {
+		return err
+	}
+	if cp == nil {
+		return errors.New(errors.ErrConfig,
+			"Nothing to resume",
+			"rr resume picks up a task run that stopped before it finished. No run in this project did.")
+	}
+	if cp.Running() {
+		return errors.New(errors.ErrConfig,
+			fmt.Sprintf("Task '%s' is still running (pid %d)", cp.Task, cp.PID),
+			"Wait for it to finish, or stop it first.")
+	}
+
+	if discard {
+		return discardCheckpoint(w, root, cp, resolved.Global)
+	}
+
+	hostName := cp.Host
+	if cp.Local {
+		hostName = ""
+	}
+	exitCode, err := RunTask(TaskOptions{
+		TaskName: cp.Task,
+		Args:     cp.Args,
+		Params:   cp.Params,
+		Host:     hostName,
+		Local:    cp.Local,
+		Quiet:    Quiet(),
+		SkipSync: cp.Synced && !sync,
+		SkipDeps: true, // They ran before the interrupted run started
+		Resume:   cp,
+	})
+	if err != nil {
+		return err
+	}
+	if exitCode != 0 {
+		return errors.NewExitError(exitCode)
+	}
+	return nil
+}
+
+// discardCheckpoint deletes an interrupted run's checkpoint, releasing the
+// lock it left behind if that's still the run's lock.
+func discardCheckpoint(w io.Writer, root string, cp *history.Checkpoint, global *config.GlobalConfig) error {
+	out := ResumeDiscardOutput{Task: cp.Task, Host: cp.Host, Done: cp.Done, Steps: cp.Steps}
+	if cp.LockDir != "" && global != nil {
+		if h, ok := global.Hosts[cp.Host]; ok && len(h.SSH) > 0 {
+			h, err := config.ResolveHostSecrets(cp.Host, h)
+			if err != nil {
+				return err
+			}
+			client, err := connectForCleanup(cp.Host, h)
+			if err != nil {
+				return errors.WrapWithCode(err, errors.ErrSSH,
+					fmt.Sprintf("Couldn't connect to %s to release the interrupted run's lock", cp.Host),
+					"Try again when the host is reachable, or release it with 'rr unlock'.")
+			}
+			defer client.Close()
+			conn := &host.Connection{Name: cp.Host, Client: client, Host: h}
+			if out.LockReleased, err = lock.ReleaseAbandoned(conn, cp.LockDir, cp.PID); err != nil {
+				return err
+			}
+		}
+	}
+	if err := history.ClearCheckpoint(root); err != nil {
+		return err
+	}
+
+	if MachineMode() {
+		return WriteJSONSuccess(w, out)
+	}
+	fmt.Fprintf(w, "%s Forgot the interrupted run of '%s' on %s\n", ui.SymbolSuccess, cp.Task, cp.Host)
+	if out.LockReleased {
+		fmt.Fprintf(w, "%s Released the lock it left on %s\n", ui.SymbolSuccess, cp.Host)
+	}
+	return nil
+}
+
+// startCheckpoint saves the first checkpoint of a task run that's about to
+// execute, and keeps it on the workflow for checkpointStep to update. The
+// tasks a pipeline runs for their outputs aren't checkpointed; they leave
+// the checkpoint of the task that takes their inputs alone.
+func startCheckpoint(wf *WorkflowContext, opts TaskOptions, task *config.TaskConfig) {
+	if opts.StageOutputs {
+		return
+	}
+	_, synced := wf.Phases["sync"]
+	cp := &history.Checkpoint{
+		Task:    opts.TaskName,
+		Args:    opts.Args,
+		Params:  opts.Params,
+		Host:    wf.Conn.Name,
+		Local:   wf.Conn.IsLocal,
+		PID:     os.Getpid(),
+		Started: time.Now(),
+		Synced:  synced || (opts.Resume != nil && opts.Resume.Synced),
+		Steps:   len(task.Steps),
+	}
+	if wf.Lock != nil {
+		cp.LockDir = wf.Lock.Dir
+	}
+	if opts.Resume != nil {
+		cp.Done = opts.Resume.Done
+	}
+	if err := history.SaveCheckpoint(wf.WorkDir, cp); err != nil {
+		logger.Verbosef(logger.LevelPhases, "exec", "can't save a checkpoint, so this run can't be resumed: %v", err)
+		return
+	}
+	wf.checkpoint = cp
+}
+
+// checkpointStep records that a step finished (or was skipped), as long as
+// every step before it did too.
+func (w *WorkflowContext) checkpointStep(stepNum int) {
+	if w.checkpoint == nil || stepNum != w.checkpoint.Done+1 {
+		return
+	}
+	w.checkpoint.Done = stepNum
+	_ = history.SaveCheckpoint(w.WorkDir, w.checkpoint)
+}
+
+// clearCheckpoint deletes the run's checkpoint once the task has finished.
+func (w *WorkflowContext) clearCheckpoint() {
+	if w.checkpoint == nil {
+		return
+	}
+	_ = history.ClearCheckpoint(w.WorkDir)
+	w.checkpoint = nil
+}
+
+// renderResumePoint says which step a resumed run starts at.
+func renderResumePoint(cp *history.Checkpoint, task *config.TaskConfig) {
+	if cp == nil {
+		return
+	}
+	mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
+	switch {
+	case cp.Done == 0:
+		fmt.Println(mutedStyle.Render("Resuming from the start"))
+		return
+	case cp.Done >= len(task.Steps):
+		fmt.Println(mutedStyle.Render("Every step finished in the interrupted run"))
+		return
+	}
+	finished := "step 1"
+	if cp.Done > 1 {
+		finished = fmt.Sprintf("steps 1-%d", cp.Done)
+	}
+	fmt.Println(mutedStyle.Render(fmt.Sprintf("Resuming at step %d/%d (%s finished in the interrupted run)",
+		cp.Done+1, len(task.Steps), finished)))
+}
+
+// showResumeHint tells an interrupted run how to pick up where it stopped.
+func showResumeHint(wf *WorkflowContext) {
+	if wf.checkpoint == nil || !PrettyMode() {
+		return
+	}
+	mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
+	fmt.Println(mutedStyle.Render("Run 'rr resume' to pick up where it stopped."))
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/history"
+	"github.com/rileyhilliard/rr/internal/lock"
+	"github.com/rileyhilliard/rr/pkg/sshutil"
+	sshtesting "github.com/rileyhilliard/rr/pkg/sshutil/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckpointStep(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	wf := &WorkflowContext{WorkDir: t.TempDir(), checkpoint: &history.Checkpoint{Task: "ci", Steps: 3}}
+
+	wf.checkpointStep(1)
+	wf.checkpointStep(3) // Step 2 didn't finish, so step 3 doesn't count
+	saved, err := history.LoadCheckpoint(wf.WorkDir)
+	require.NoError(t, err)
+	require.NotNil(t, saved)
+	assert.Equal(t, 1, saved.Done)
+
+	wf.clearCheckpoint()
+	saved, err = history.LoadCheckpoint(wf.WorkDir)
+	require.NoError(t, err)
+	assert.Nil(t, saved)
+
+	wf.checkpointStep(2) // No checkpoint anymore: nothing is saved
+	saved, err = history.LoadCheckpoint(wf.WorkDir)
+	require.NoError(t, err)
+	assert.Nil(t, saved)
+}
+
+func TestDiscardCheckpoint_ReleasesOwnLock(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	withVerbosity(t, 0, false)
+	root := t.TempDir()
+
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+	info, err := (&lock.LockInfo{User: "me", Hostname: hostname, PID: 4242}).Marshal()
+	require.NoError(t, err)
+
+	client := &recordingClient{MockClient: sshtesting.NewMockClient("box")}
+	client.SetCommandResponse("^cat ", sshtesting.CommandResponse{Stdout: info})
+	client.SetCommandResponse("^rm -rf ", sshtesting.CommandResponse{})
+	origConnect := connectForCleanup
+	connectForCleanup = func(string, config.Host) (sshutil.SSHClient, error) { return client, nil }
+	t.Cleanup(func() { connectForCleanup = origConnect })
+
+	cp := &history.Checkpoint{Task: "ci", Host: "box", PID: 4242, LockDir: "/tmp/rr.lock", Steps: 3, Done: 2}
+	require.NoError(t, history.SaveCheckpoint(root, cp))
+	global := &config.GlobalConfig{Hosts: map[string]config.Host{"box": {SSH: []string{"box"}}}}
+
+	var out bytes.Buffer
+	require.NoError(t, discardCheckpoint(&out, root, cp, global))
+
+	var resp struct {
+		Data ResumeDiscardOutput `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &resp))
+	assert.True(t, resp.Data.LockReleased)
+	assert.Equal(t, 2, resp.Data.Done)
+	assert.Contains(t, client.cmds[len(client.cmds)-1], "rm -rf")
+
+	saved, err := history.LoadCheckpoint(root)
+	require.NoError(t, err)
+	assert.Nil(t, saved)
+}
+
+func TestResumeCommand_NothingToResume(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(dir+"/.rr.yaml", []byte("version: 1\n"), 0644))
+	t.Chdir(dir)
+
+	err := resumeCommand(&bytes.Buffer{}, false, false)
+	assert.ErrorContains(t, err, "Nothing to resume")
+
+	resolved, err := loadResolved(Config())
+	require.NoError(t, err)
+	require.NoError(t, history.SaveCheckpoint(resolved.ProjectRoot, &history.Checkpoint{Task: "ci", PID: os.Getppid()}))
+	err = resumeCommand(&bytes.Buffer{}, false, false)
+	assert.ErrorContains(t, err, "still running")
+}
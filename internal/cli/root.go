@@ -7,6 +7,8 @@ import (
 
 	"github.com/rileyhilliard/rr/internal/config"
 	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/rileyhilliard/rr/internal/stats"
 	"github.com/rileyhilliard/rr/internal/ui"
 	"github.com/rileyhilliard/rr/pkg/sshutil"
 	"github.com/spf13/cobra"
@@ -15,10 +17,17 @@ import (
 // Global flags
 var (
 	cfgFile              string
-	verbose              bool
+	verbosity            int
 	quiet                bool
 	noColor              bool
+	colorFlag            colorMode
+	accessibleMode       bool
 	noStrictHostKeyCheck bool
+	fresh                bool
+	lowBandwidthFlag     bool
+	renderRateFlag       int
+	notifyWhenFree       bool
+	nonInteractive       bool
 	// machineMode is defined in json.go
 )
 
@@ -34,6 +43,7 @@ type configDiscoveryState struct {
 	LoadErr        error    // Error loading/parsing config (nil if loaded)
 	ValidateErr    error    // Error validating config (nil if valid)
 	TasksAvailable []string // Available task names for suggestions
+	OutputColor    string   // The project's output.color (empty if unset or invalid)
 }
 
 // discoveryState stores the result of config discovery for error reporting.
@@ -53,6 +63,7 @@ Common workflows:
   rr exec "ls -la"          Run command without syncing
   rr sync                   Just sync files, don't run anything
   rr status                 Show connection and sync status
+  rr                        Pick a task to run (when .rr.yaml defines tasks)
 
 Get started:
   rr init                   Create .rr.yaml configuration
@@ -60,11 +71,23 @@ Get started:
   rr doctor                 Diagnose connection issues`,
 	SilenceUsage:  true,
 	SilenceErrors: true,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		// The task picker is an interactive TUI, so turn colors on (as
+		// --color allows) even though the default output mode is
+		// machine-readable.
+		if shouldPickTask() {
+			ui.EnableColors()
+		}
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTaskPicker(cmd)
+	},
 }
 
 // Execute runs the root command and handles errors with structured output.
 func Execute() {
 	code := run()
+	flushHooks()
 	sshutil.CloseAgent()
 	if code != 0 {
 		os.Exit(code)
@@ -77,8 +100,15 @@ func run() int {
 	// We need to check for --config flag manually since Cobra hasn't parsed flags yet.
 	explicitConfig := findConfigFlag()
 	registerTasksFromConfig(explicitConfig)
+	activatePlugins()
 
-	if err := rootCmd.Execute(); err != nil {
+	var err error
+	if path, args, ok := findPluginCommand(os.Args[1:]); ok {
+		err = runPluginCommand(path, args)
+	} else {
+		err = rootCmd.Execute()
+	}
+	if err != nil {
 		// Check if it's an exit code error (command ran but returned non-zero)
 		if code, ok := errors.GetExitCode(err); ok {
 			return code
@@ -89,6 +119,8 @@ func run() int {
 			return handleUnknownCommand(err)
 		}
 
+		_ = stats.RecordError(errors.IDOf(err))
+
 		// In structured mode, emit JSON error to stderr
 		if !PrettyMode() {
 			emitStructuredError(err)
@@ -109,11 +141,23 @@ func run() int {
 			wrapped := errors.Wrap(err, err.Error())
 			fmt.Fprintln(os.Stderr, wrapped.Error())
 		}
+		printErrorID(err)
 		return 1
 	}
 	return 0
 }
 
+// printErrorID prints the stable error ID under a pretty-mode error so users
+// can look up the long-form explanation.
+func printErrorID(err error) {
+	id := errors.IDOf(err)
+	if id == "" {
+		return
+	}
+	mutedStyle := ui.StderrStyle().Foreground(ui.ColorMuted)
+	fmt.Fprintln(os.Stderr, mutedStyle.Render(fmt.Sprintf("  %s · run 'rr explain %s' for details", id, id)))
+}
+
 // registerTasksFromConfig attempts to load config and register task commands.
 // This runs before command execution to make tasks available as first-class commands.
 // Errors are tracked in discoveryState for later error reporting.
@@ -136,7 +180,8 @@ func registerTasksFromConfig(explicit string) {
 	if cfgPath == "" {
 		discoveryState.ProjectErr = errors.New(errors.ErrConfig,
 			"No .rr.yaml found in this directory or parent directories",
-			"Run 'rr init' to create one, or check you're in the right directory.")
+			"Run 'rr init' to create one, or check you're in the right directory.").
+			WithID(errors.IDConfigNotFound)
 		return
 	}
 
@@ -155,6 +200,8 @@ func registerTasksFromConfig(explicit string) {
 		return
 	}
 
+	discoveryState.OutputColor = cfg.Output.Color
+
 	// Collect available task names for suggestions
 	for name := range cfg.Tasks {
 		discoveryState.TasksAvailable = append(discoveryState.TasksAvailable, name)
@@ -218,6 +265,7 @@ func handleUnknownCommand(err error) int {
 				fmt.Sprintf("Unknown command '%s' (config failed to load)", unknownCmd),
 				"Fix the config error above, then try again.")
 			fmt.Fprintln(os.Stderr, rrErr.Error())
+			printErrorID(rrErr)
 			return 1
 		}
 
@@ -227,12 +275,14 @@ func handleUnknownCommand(err error) int {
 				fmt.Sprintf("Unknown command '%s' (config is invalid)", unknownCmd),
 				"Fix the validation error above, then try again.")
 			fmt.Fprintln(os.Stderr, rrErr.Error())
+			printErrorID(rrErr)
 			return 1
 		}
 
 		// Case 3: No project config found - preserve the original error details
 		if discoveryState.ProjectErr != nil {
 			fmt.Fprintln(os.Stderr, discoveryState.ProjectErr.Error())
+			printErrorID(discoveryState.ProjectErr)
 			return 1
 		}
 	}
@@ -249,19 +299,34 @@ func handleUnknownCommand(err error) int {
 
 	rrErr := errors.New(errors.ErrExec,
 		fmt.Sprintf("Unknown command '%s'", unknownCmd),
-		suggestion)
+		suggestion).
+		WithID(errors.IDExecUnknownCommand)
 	fmt.Fprintln(os.Stderr, rrErr.Error())
+	printErrorID(rrErr)
 	return 1
 }
 
 func init() {
 	// Global flags available to all commands
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is .rr.yaml)")
-	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().CountVarP(&verbosity, "verbose", "v",
+		"verbose output: -v for phase details, -vv for ssh and rsync commands, -vvv for SSH round-trip timings")
 	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "suppress non-essential output")
-	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "disable colored output")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "disable colored output (same as --color=never)")
+	rootCmd.PersistentFlags().Var(&colorFlag, "color",
+		"when to color output: auto (on a terminal, honoring NO_COLOR and CLICOLOR_FORCE), always, or never")
+	rootCmd.PersistentFlags().BoolVar(&accessibleMode, "accessible", false,
+		"screen reader friendly output: plain ASCII symbols, no spinners or animations, line-by-line status")
 	rootCmd.PersistentFlags().BoolVar(&noStrictHostKeyCheck, "no-strict-host-key-checking", false,
 		"disable SSH host key verification (insecure, for CI/automation only)")
+	rootCmd.PersistentFlags().BoolVar(&fresh, "fresh", false,
+		"try each host's SSH aliases in configured order instead of the one that worked last time")
+	rootCmd.PersistentFlags().BoolVar(&lowBandwidthFlag, "low-bandwidth", false,
+		"for slow or metered links: compress SSH traffic, skip the sync progress bar, and show output and monitor updates less often")
+	rootCmd.PersistentFlags().IntVar(&renderRateFlag, "render-rate", 0,
+		"redraw spinners and progress bars at most this many times a second (default adapts to how fast the terminal keeps up)")
+	rootCmd.PersistentFlags().BoolVar(&nonInteractive, "non-interactive", false,
+		"never prompt or show a picker: use defaults, or fail saying which flag to pass (for CI; also RR_NON_INTERACTIVE=true)")
 	rootCmd.PersistentFlags().BoolVarP(&prettyMode, "pretty", "p", false,
 		"human-readable output with spinners and colors (default is structured JSON)")
 	rootCmd.PersistentFlags().BoolVarP(&machineMode, "machine", "m", false,
@@ -272,17 +337,32 @@ func init() {
 	// Set up styled warning handler for sshutil package
 	sshutil.WarningHandler = ui.PrintWarning
 
+	// Show what changed whenever rr rewrites a config file
+	config.OnWrite = showConfigChange
+
 	// Set up a pre-run hook to apply global flags
 	originalPreRun := rootCmd.PersistentPreRun
 	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
-		// Disable colors when not in pretty mode or when explicitly disabled
-		if noColor || !prettyMode {
-			ui.DisableColors()
+		// Disable colors when not in pretty mode, otherwise follow --color
+		applyColorMode()
+		global, err := config.LoadGlobal()
+		if err != nil {
+			global = nil
 		}
+		applyConfiguredTheme(global)
+		applyAccessibleMode(global)
+		ui.SetRenderRate(renderRateFlag)
+		applyVerbosity()
+		applyLowBandwidth(global)
+		stats.Enabled = global != nil && global.Stats
 		// Apply SSH host key checking setting
 		if noStrictHostKeyCheck {
 			sshutil.StrictHostKeyChecking = false
 		}
+		// Probe aliases in configured order, ignoring the remembered one
+		if fresh {
+			host.RememberAliases = false
+		}
 		// Call original pre-run if it exists
 		if originalPreRun != nil {
 			originalPreRun(cmd, args)
@@ -300,9 +380,15 @@ func Config() string {
 	return cfgFile
 }
 
-// Verbose returns the verbose flag value.
+// Verbose reports whether any level of verbose output was asked for.
 func Verbose() bool {
-	return verbose
+	return verbosity > 0
+}
+
+// Verbosity returns how many times -v was given: 0 for none, up to
+// logger.LevelWire for the most detail.
+func Verbosity() int {
+	return verbosity
 }
 
 // Quiet returns the quiet flag value.
@@ -310,7 +396,8 @@ func Quiet() bool {
 	return quiet
 }
 
-// NoColor returns the no-color flag value.
+// NoColor reports whether --no-color or --color=never was passed. The
+// project's output.color isn't considered.
 func NoColor() bool {
-	return noColor
+	return noColor || colorFlag == ui.ColorNever
 }
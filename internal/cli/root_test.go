@@ -2,9 +2,12 @@ package cli
 
 import (
 	"errors"
+	"os"
 	"testing"
 
+	"github.com/rileyhilliard/rr/internal/ui"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestIsUnknownCommandError(t *testing.T) {
@@ -100,3 +103,54 @@ func TestConfigDiscoveryState(t *testing.T) {
 	assert.Contains(t, state.LoadErr.Error(), "invalid YAML")
 	assert.Equal(t, []string{"test", "build"}, state.TasksAvailable)
 }
+
+func TestColorModeFlag(t *testing.T) {
+	t.Cleanup(func() {
+		colorFlag = ""
+		noColor = false
+	})
+
+	var m colorMode
+	assert.Equal(t, "auto", m.String(), "unset reads as auto")
+	assert.NoError(t, m.Set("always"))
+	assert.Equal(t, "always", m.String())
+	assert.Error(t, m.Set("sometimes"))
+	assert.Equal(t, "always", m.String(), "a bad value leaves the mode alone")
+
+	colorFlag = colorMode(ui.ColorNever)
+	assert.True(t, NoColor())
+	colorFlag = colorMode(ui.ColorAuto)
+	assert.False(t, NoColor())
+	noColor = true
+	assert.True(t, NoColor())
+}
+
+func TestApplyColorMode_ProjectOutputColor(t *testing.T) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	origState := discoveryState
+	t.Cleanup(func() {
+		r.Close()
+		w.Close()
+		discoveryState = origState
+		colorFlag = ""
+		noColor = false
+		ui.SetColorMode(ui.ColorAuto)
+		ui.DisableColors()
+	})
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("CLICOLOR_FORCE", "")
+
+	discoveryState = &configDiscoveryState{OutputColor: ui.ColorAlways}
+	applyColorMode()
+	assert.True(t, ui.ColorsWanted(w), "output.color: always colors a pipe")
+
+	colorFlag = colorMode(ui.ColorNever)
+	applyColorMode()
+	assert.False(t, ui.ColorsWanted(w), "--color wins over output.color")
+
+	colorFlag = colorMode(ui.ColorAlways)
+	noColor = true
+	applyColorMode()
+	assert.False(t, ui.ColorsWanted(w), "--no-color wins over everything")
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/signal"
 	"path"
+	"path/filepath"
 	"strings"
 	"syscall"
 	"time"
@@ -14,7 +15,9 @@ import (
 	"github.com/rileyhilliard/rr/internal/config"
 	"github.com/rileyhilliard/rr/internal/errors"
 	"github.com/rileyhilliard/rr/internal/exec"
+	"github.com/rileyhilliard/rr/internal/history"
 	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/rileyhilliard/rr/internal/logger"
 	"github.com/rileyhilliard/rr/internal/output"
 	"github.com/rileyhilliard/rr/internal/parallel"
 	"github.com/rileyhilliard/rr/internal/parallel/logs"
@@ -33,16 +36,24 @@ type RunOptions struct {
 	SkipRequirements bool          // If true, skip requirement checks
 	DryRun           bool          // If true, show what would be done without doing it
 	WorkingDir       string        // Override local working directory
-	RemoteCWD        string        // Subdirectory to cd into on remote before running (relative to host.Dir)
+	RemoteCWD        string        // Project subdirectory to run the command in (--dir), on the host or locally
 	Quiet            bool          // If true, minimize output (no individual connection attempts)
 	Local            bool          // If true, force local execution (skip remote hosts)
 	Pull             []string      // Patterns to pull from remote after command completes
 	PullDest         string        // Destination directory for pulled files
+	NoSummary        bool          // If true, skip the phase breakdown footer
+	Diagnostics      bool          // If true, write failure locations to .rr/diagnostics.json
+	Force            bool          // If true, sync even into a git checkout with uncommitted changes
+	Rebootstrap      bool          // If true, run the project's bootstrap script even if it already ran on the host
 }
 
 // Run syncs files and executes a command on the remote host.
 // This is the main workflow that ties together all subsystems.
 func Run(opts RunOptions) (int, error) {
+	if err := checkRunDir(opts.RemoteCWD); err != nil {
+		return 1, err
+	}
+
 	// Setup common workflow phases (config, connect, sync, lock)
 	wf, err := SetupWorkflow(WorkflowOptions{
 		Host:             opts.Host,
@@ -55,6 +66,8 @@ func Run(opts RunOptions) (int, error) {
 		Quiet:            opts.Quiet,
 		Local:            opts.Local,
 		Command:          opts.Command,
+		Force:            opts.Force,
+		Rebootstrap:      opts.Rebootstrap,
 	})
 	if err != nil {
 		return 1, err
@@ -69,46 +82,61 @@ func Run(opts RunOptions) (int, error) {
 	}
 
 	// Set up output streaming - in structured mode, pass raw stdout/stderr
-	streamHandler := output.NewStreamHandler(os.Stdout, os.Stderr)
+	outWriter, errWriter, flushOutput := commandOutput()
+	streamHandler := output.NewStreamHandler(outWriter, errWriter)
 	if PrettyMode() {
 		streamHandler.SetFormatter(output.NewGenericFormatter())
 	}
 
+	// Keep the tail of the output for 'rr report', and a recording for 'rr replay'
+	stdout, stderr := wf.captureOutput(streamHandler.Stdout(), streamHandler.Stderr())
+
+	// Warn about (and with idle_kill, stop) a command that goes quiet
+	execCtx, stdout, stderr, stopIdle := exec.WatchIdle(wf.Context(), wf.Conn, idleOptions(wf, nil), stdout, stderr)
+
 	execStart := time.Now()
 	var exitCode int
 
 	if wf.Conn.IsLocal {
-		exitCode, err = exec.ExecuteLocal(opts.Command, wf.WorkDir, streamHandler.Stdout(), streamHandler.Stderr())
+		dir, dirErr := inLocalDir(wf.WorkDir, opts.RemoteCWD)
+		if dirErr != nil {
+			return 1, dirErr
+		}
+		exitCode, err = exec.ExecuteLocal(opts.Command, dir, stdout, stderr)
 	} else {
 		cmd := opts.Command
 		if len(wf.Resolved.Project.Defaults.Setup) > 0 {
 			cmd = strings.Join(wf.Resolved.Project.Defaults.Setup, " && ") + " && " + cmd
 		}
-		// --cwd prepends a cd into a subdirectory of the remote project root.
-		// Reject paths that escape the project root via ../ traversal.
-		if opts.RemoteCWD != "" {
-			remoteProjectDir := config.ExpandRemote(wf.Conn.Host.Dir)
-			resolved := path.Join(remoteProjectDir, opts.RemoteCWD)
-			if !strings.HasPrefix(resolved+"/", remoteProjectDir+"/") {
-				return 1, errors.New(errors.ErrConfig,
-					fmt.Sprintf("--cwd '%s' escapes the remote project root", opts.RemoteCWD),
-					"use a path relative to the project root without '..' components")
-			}
-			subdir := util.ShellQuotePreserveTilde(resolved)
-			cmd = fmt.Sprintf("cd %s && %s", subdir, cmd)
+		if cmd, err = inRemoteCWD(cmd, wf.Conn.Host.Dir, opts.RemoteCWD); err != nil {
+			return 1, err
 		}
 		fullCmd := exec.BuildRemoteCommand(cmd, &wf.Conn.Host)
-		exitCode, err = wf.Conn.Client.ExecStreamContext(wf.Context(), fullCmd, streamHandler.Stdout(), streamHandler.Stderr())
+		logger.Verbosef(logger.LevelPhases, "exec", "running %q on %s in %s", opts.Command, wf.Conn.Name, wf.Conn.Host.Dir)
+		exitCode, err = wf.Conn.Client.ExecStreamContext(execCtx, fullCmd, stdout, stderr)
 	}
 	execDuration := time.Since(execStart)
+	idleKilled := stopIdle()
+	flushOutput()
+
+	pullItems := make([]config.PullItem, len(opts.Pull))
+	for i, p := range opts.Pull {
+		pullItems[i] = config.PullItem{Src: p}
+	}
 
 	if wf.Context().Err() != nil {
+		// Interrupted: still pull the requested files, as after a failure
+		ExecutePullPhase(wf, pullItems, opts.PullDest)
 		return 130, nil
 	}
+	if idleKilled {
+		exitCode, err = exec.IdleExitCode, nil
+	}
 
 	if err != nil {
 		return 1, err
 	}
+	wf.recordPhase("exec", execDuration)
 
 	// Release lock early
 	if wf.Lock != nil {
@@ -116,17 +144,18 @@ func Run(opts RunOptions) (int, error) {
 	}
 
 	// Phase 5: Pull files (if requested)
-	if len(opts.Pull) > 0 {
-		pullItems := make([]config.PullItem, len(opts.Pull))
-		for i, p := range opts.Pull {
-			pullItems[i] = config.PullItem{Src: p}
-		}
-		ExecutePullPhase(wf, pullItems, opts.PullDest)
+	ExecutePullPhase(wf, pullItems, opts.PullDest)
+
+	if opts.Diagnostics {
+		writeRunDiagnostics(wf, opts.Command, opts.Command, exitCode)
 	}
 
 	// In structured mode, emit result and return - no decorations
 	if !PrettyMode() {
+		explainResourceLimits(wf, exitCode)
+		collectCrashEvidence(wf, exitCode)
 		wf.Reporter.CommandComplete(exitCode, wf.Conn.Name, time.Since(wf.StartTime), execDuration)
+		finishRun(wf, history.RunKey(opts.Command), exitCode, false)
 		return exitCode, nil
 	}
 
@@ -158,6 +187,8 @@ func Run(opts RunOptions) (int, error) {
 					}
 				}
 			}
+		} else if explainResourceLimits(wf, exitCode) {
+			failureExplained = true
 		} else if provider, ok := streamHandler.GetFormatter().(output.TestSummaryProvider); ok {
 			failures := provider.GetTestFailures()
 			if len(failures) > 0 {
@@ -184,10 +215,12 @@ func Run(opts RunOptions) (int, error) {
 				fmt.Print(ui.RenderSummary(summary, exitCode))
 			}
 		}
+		collectCrashEvidence(wf, exitCode)
 	}
 
 	wf.PhaseDisplay.ThinDivider()
 	renderFinalStatus(wf.PhaseDisplay, exitCode, time.Since(wf.StartTime), execDuration, wf.Conn.Name)
+	finishRun(wf, history.RunKey(opts.Command), exitCode, !opts.NoSummary && !opts.Quiet)
 
 	if exitCode != 0 && !failureExplained {
 		renderFailureHelp(exitCode, opts.Command, wf.Conn.Name)
@@ -196,6 +229,59 @@ func Run(opts RunOptions) (int, error) {
 	return exitCode, nil
 }
 
+// checkRunDir rejects a --dir that isn't a path relative to the project
+// root. Whether it stays inside the root is checked once the root is known.
+func checkRunDir(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	if path.IsAbs(dir) || filepath.IsAbs(dir) || dir == "~" || strings.HasPrefix(dir, "~/") {
+		return errors.New(errors.ErrConfig,
+			fmt.Sprintf("--dir '%s' isn't relative to the project root", dir),
+			"Use a path inside the project, like --dir backend or --dir packages/api.")
+	}
+	return nil
+}
+
+// inLocalDir returns the directory a local run executes in: dir, a
+// subdirectory of the project root workDir, for --dir. Like inRemoteCWD, it
+// rejects paths that escape the project root. An empty dir is workDir.
+func inLocalDir(workDir, dir string) (string, error) {
+	if dir == "" {
+		return workDir, nil
+	}
+	resolved := filepath.Join(workDir, dir)
+	if rel, err := filepath.Rel(workDir, resolved); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.New(errors.ErrConfig,
+			fmt.Sprintf("--dir '%s' escapes the project root", dir),
+			"Use a path relative to the project root without '..' components.")
+	}
+	if info, err := os.Stat(resolved); err != nil || !info.IsDir() {
+		return "", errors.New(errors.ErrConfig,
+			fmt.Sprintf("--dir '%s' isn't a directory in the project", dir),
+			"Check the path - it's relative to "+workDir+".")
+	}
+	return resolved, nil
+}
+
+// inRemoteCWD prepends a cd into remoteCWD, a subdirectory of the remote
+// project root hostDir, for --dir. Paths that escape the project root via
+// ../ traversal are rejected. An empty remoteCWD leaves cmd alone.
+func inRemoteCWD(cmd, hostDir, remoteCWD string) (string, error) {
+	if remoteCWD == "" {
+		return cmd, nil
+	}
+	remoteProjectDir := config.ExpandRemote(hostDir)
+	resolved := path.Join(remoteProjectDir, remoteCWD)
+	if !strings.HasPrefix(resolved+"/", remoteProjectDir+"/") {
+		return "", errors.New(errors.ErrConfig,
+			fmt.Sprintf("--dir '%s' escapes the remote project root", remoteCWD),
+			"Use a path relative to the project root without '..' components.")
+	}
+	subdir := util.ShellQuotePreserveTilde(resolved)
+	return fmt.Sprintf("cd %s && %s", subdir, cmd), nil
+}
+
 // renderFinalStatus displays the final execution status line.
 func renderFinalStatus(_ *ui.PhaseDisplay, exitCode int, totalTime, execTime time.Duration, host string) {
 	var symbol string
@@ -300,15 +386,32 @@ func mapProbeErrorToStatus(err error) ui.ConnectionStatus {
 	return ui.StatusFailed
 }
 
+// runFlags are the flags of rr run, as given on the command line.
+type runFlags struct {
+	Host             string
+	Tag              string
+	ProbeTimeout     string
+	Local            bool
+	SkipRequirements bool
+	Repeat           int      // Run the command this many times in parallel (--repeat)
+	Pull             []string // Patterns to pull back after the command
+	PullDest         string
+	Dir              string // Project subdirectory to run the command in (--dir)
+	NoSummary        bool
+	Diagnostics      bool
+	Force            bool
+	Rebootstrap      bool
+}
+
 // runCommand is the actual implementation called by the cobra command.
-func runCommand(args []string, hostFlag, tagFlag, probeTimeoutFlag string, localFlag, skipRequirementsFlag bool, repeatCount int, pullPatterns []string, pullDest, remoteCWD string) error {
+func runCommand(args []string, flags runFlags) error {
 	if len(args) == 0 {
 		return errors.New(errors.ErrExec,
 			"What should I run?",
 			"Usage: rr run <command>  (e.g., rr run \"make test\")")
 	}
 
-	probeTimeout, err := ParseProbeTimeout(probeTimeoutFlag)
+	probeTimeout, err := ParseProbeTimeout(flags.ProbeTimeout)
 	if err != nil {
 		return err
 	}
@@ -317,8 +420,8 @@ func runCommand(args []string, hostFlag, tagFlag, probeTimeoutFlag string, local
 	cmd := strings.Join(args, " ")
 
 	// If --repeat is specified, use parallel execution
-	if repeatCount > 1 {
-		exitCode, err := runRepeated(cmd, repeatCount, hostFlag, tagFlag, localFlag)
+	if flags.Repeat > 1 {
+		exitCode, err := runRepeated(cmd, flags)
 		if err != nil {
 			return err
 		}
@@ -330,15 +433,19 @@ func runCommand(args []string, hostFlag, tagFlag, probeTimeoutFlag string, local
 
 	exitCode, err := Run(RunOptions{
 		Command:          cmd,
-		Host:             hostFlag,
-		Tag:              tagFlag,
+		Host:             flags.Host,
+		Tag:              flags.Tag,
 		ProbeTimeout:     probeTimeout,
-		SkipRequirements: skipRequirementsFlag,
+		SkipRequirements: flags.SkipRequirements,
 		Quiet:            Quiet(),
-		Local:            localFlag,
-		Pull:             pullPatterns,
-		PullDest:         pullDest,
-		RemoteCWD:        remoteCWD,
+		Local:            flags.Local,
+		Pull:             flags.Pull,
+		PullDest:         flags.PullDest,
+		RemoteCWD:        flags.Dir,
+		NoSummary:        flags.NoSummary,
+		Diagnostics:      flags.Diagnostics,
+		Force:            flags.Force,
+		Rebootstrap:      flags.Rebootstrap,
 	})
 
 	if err != nil {
@@ -354,9 +461,9 @@ func runCommand(args []string, hostFlag, tagFlag, probeTimeoutFlag string, local
 
 // runRepeated runs a command N times in parallel across available hosts.
 // Used for flake detection - run the same test multiple times to surface intermittent failures.
-func runRepeated(cmd string, repeatCount int, hostFlag, tagFlag string, localFlag bool) (int, error) {
+func runRepeated(cmd string, flags runFlags) (int, error) {
 	// Load and validate config
-	resolved, err := config.LoadResolved(Config())
+	resolved, err := loadResolved(Config())
 	if err != nil {
 		return 1, err
 	}
@@ -366,8 +473,8 @@ func runRepeated(cmd string, repeatCount int, hostFlag, tagFlag string, localFla
 	}
 
 	// Create N synthetic tasks with the same command
-	tasks := make([]parallel.TaskInfo, repeatCount)
-	for i := 0; i < repeatCount; i++ {
+	tasks := make([]parallel.TaskInfo, flags.Repeat)
+	for i := 0; i < flags.Repeat; i++ {
 		tasks[i] = parallel.TaskInfo{
 			Name:    fmt.Sprintf("run-%d", i+1),
 			Index:   i,
@@ -376,15 +483,15 @@ func runRepeated(cmd string, repeatCount int, hostFlag, tagFlag string, localFla
 	}
 
 	// Resolve hosts
-	hostOrder, hosts, err := config.ResolveHosts(resolved, hostFlag)
+	hostOrder, hosts, err := config.ResolveHosts(resolved, flags.Host)
 	if err != nil {
 		return 1, err
 	}
 
 	// Handle --local flag
-	if localFlag {
+	if flags.Local {
 		// --local and --tag are mutually exclusive
-		if err := ValidateLocalAndTag(localFlag, tagFlag); err != nil {
+		if err := ValidateLocalAndTag(flags.Local, flags.Tag); err != nil {
 			return 1, err
 		}
 		hosts = make(map[string]config.Host)
@@ -392,19 +499,21 @@ func runRepeated(cmd string, repeatCount int, hostFlag, tagFlag string, localFla
 	}
 
 	// Filter by tag if specified
-	if tagFlag != "" {
-		hosts, hostOrder = filterHostsByTag(hosts, hostOrder, tagFlag)
+	if flags.Tag != "" {
+		hosts, hostOrder = filterHostsByTag(hosts, hostOrder, flags.Tag)
 		if len(hosts) == 0 {
 			return 1, errors.New(errors.ErrConfig,
-				fmt.Sprintf("No hosts found with tag '%s'", tagFlag),
+				fmt.Sprintf("No hosts found with tag '%s'", flags.Tag),
 				"Check your host tags in ~/.rr/config.yaml.")
 		}
 	}
 
 	// Build parallel config
 	parallelCfg := parallel.Config{
-		OutputMode: parallel.OutputProgress,
-		SaveLogs:   true,
+		OutputMode:  parallel.OutputProgress,
+		SaveLogs:    true,
+		Force:       flags.Force,
+		Rebootstrap: flags.Rebootstrap,
 	}
 
 	// Set up log writer
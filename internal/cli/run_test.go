@@ -3,6 +3,7 @@ package cli
 import (
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -126,13 +127,13 @@ func TestRunOptions_WithValues(t *testing.T) {
 }
 
 func TestRunCommand_NoArgs(t *testing.T) {
-	err := runCommand([]string{}, "", "", "", false, false, 0, nil, "", "")
+	err := runCommand([]string{}, runFlags{})
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "What should I run?")
 }
 
 func TestRunCommand_InvalidProbeTimeout(t *testing.T) {
-	err := runCommand([]string{"echo hello"}, "", "", "invalid-timeout", false, false, 0, nil, "", "")
+	err := runCommand([]string{"echo hello"}, runFlags{ProbeTimeout: "invalid-timeout"})
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "doesn't look like a valid timeout")
 }
@@ -151,7 +152,7 @@ func TestRunCommand_JoinsArgs(t *testing.T) {
 	require.NoError(t, err)
 
 	// Multiple args should be joined into single command
-	err = runCommand([]string{"make", "test"}, "", "", "", false, false, 0, nil, "", "")
+	err = runCommand([]string{"make", "test"}, runFlags{})
 	require.Error(t, err)
 	// Should fail on no hosts configured
 	assert.Contains(t, err.Error(), "No hosts configured")
@@ -169,20 +170,20 @@ func TestRunCommand_ValidProbeTimeout(t *testing.T) {
 	require.NoError(t, err)
 
 	// Valid probe timeout should not fail on parsing
-	err = runCommand([]string{"echo"}, "", "", "5s", false, false, 0, nil, "", "")
+	err = runCommand([]string{"echo"}, runFlags{ProbeTimeout: "5s"})
 	require.Error(t, err)
 	// Should fail on no hosts configured, not on probe timeout
 	assert.NotContains(t, err.Error(), "timeout")
 }
 
 func TestExecCommand_NoArgs(t *testing.T) {
-	err := execCommand([]string{}, "", "", "", false, false, nil, "", "")
+	err := execCommand([]string{}, execFlags{})
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "What should I run?")
 }
 
 func TestExecCommand_InvalidProbeTimeout(t *testing.T) {
-	err := execCommand([]string{"ls"}, "", "", "bad-duration", false, false, nil, "", "")
+	err := execCommand([]string{"ls"}, execFlags{ProbeTimeout: "bad-duration"})
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "doesn't look like a valid timeout")
 }
@@ -199,7 +200,7 @@ func TestExecCommand_JoinsArgs(t *testing.T) {
 	require.NoError(t, err)
 
 	// Multiple args should be joined
-	err = execCommand([]string{"ls", "-la"}, "", "", "", false, false, nil, "", "")
+	err = execCommand([]string{"ls", "-la"}, execFlags{})
 	require.Error(t, err)
 	// Should fail on no hosts configured
 	assert.Contains(t, err.Error(), "No hosts configured")
@@ -228,7 +229,7 @@ func TestExecCommand_ValidProbeTimeoutFormats(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := execCommand([]string{"ls"}, "", "", tt.timeout, false, false, nil, "", "")
+			err := execCommand([]string{"ls"}, execFlags{ProbeTimeout: tt.timeout})
 			// Should fail with config error, not parse error
 			if err != nil {
 				assert.NotContains(t, err.Error(), "doesn't look like a valid timeout",
@@ -471,7 +472,7 @@ func TestRunOptions_ZeroValues(t *testing.T) {
 }
 
 func TestRunCommand_EmptyArgs(t *testing.T) {
-	err := runCommand([]string{}, "", "", "", false, false, 0, nil, "", "")
+	err := runCommand([]string{}, runFlags{})
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "What should I run?")
 }
@@ -488,7 +489,7 @@ func TestRunCommand_MultipleArgsJoined(t *testing.T) {
 	require.NoError(t, err)
 
 	// Multiple args should be joined with spaces
-	err = runCommand([]string{"make", "test", "-v"}, "", "", "", false, false, 0, nil, "", "")
+	err = runCommand([]string{"make", "test", "-v"}, runFlags{})
 	require.Error(t, err)
 	// Fails on no hosts configured, but args were processed
 	assert.Contains(t, err.Error(), "No hosts configured")
@@ -505,7 +506,7 @@ func TestRunCommand_WithHostAndTag(t *testing.T) {
 	err := os.Chdir(tmpDir)
 	require.NoError(t, err)
 
-	err = runCommand([]string{"echo"}, "myhost", "mytag", "", false, false, 0, nil, "", "")
+	err = runCommand([]string{"echo"}, runFlags{Host: "myhost", Tag: "mytag"})
 	require.Error(t, err)
 	// Should fail on no hosts configured, flags were accepted
 	assert.Contains(t, err.Error(), "No hosts configured")
@@ -522,7 +523,7 @@ func TestExecCommand_MultipleArgsJoined(t *testing.T) {
 	err := os.Chdir(tmpDir)
 	require.NoError(t, err)
 
-	err = execCommand([]string{"ls", "-la", "/tmp"}, "", "", "", false, false, nil, "", "")
+	err = execCommand([]string{"ls", "-la", "/tmp"}, execFlags{})
 	require.Error(t, err)
 	// Fails on no hosts configured, but args were processed
 	assert.Contains(t, err.Error(), "No hosts configured")
@@ -739,7 +740,6 @@ func TestPull_WithTagFlag(t *testing.T) {
 
 // TestRemoteCWD_TraversalGuard verifies the path-prefix check that prevents
 // --cwd from escaping the remote project root via ../ sequences.
-// Mirrors the exact filepath.Join + strings.HasPrefix logic in run.go.
 func TestRemoteCWD_TraversalGuard(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -758,12 +758,48 @@ func TestRemoteCWD_TraversalGuard(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			resolved := path.Join(tt.remoteRoot, tt.cwd)
-			escaped := !strings.HasPrefix(resolved+"/", tt.remoteRoot+"/")
-			assert.Equal(t, tt.wantEscape, escaped, "cwd=%q resolved to %q", tt.cwd, resolved)
+			cmd, err := inRemoteCWD("make test", tt.remoteRoot, tt.cwd)
+			assert.Equal(t, tt.wantEscape, err != nil, "cwd=%q resolved to %q", tt.cwd, resolved)
+			if err == nil {
+				assert.Equal(t, "cd '"+resolved+"' && make test", cmd)
+			}
 		})
 	}
 }
 
+func TestCheckRunDir(t *testing.T) {
+	assert.NoError(t, checkRunDir(""))
+	assert.NoError(t, checkRunDir("backend"))
+	assert.NoError(t, checkRunDir("packages/api"))
+
+	for _, dir := range []string{"/etc", "~", "~/project"} {
+		err := checkRunDir(dir)
+		require.Error(t, err, dir)
+		assert.Contains(t, err.Error(), "isn't relative to the project root")
+	}
+}
+
+func TestInLocalDir(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "backend", "api"), 0755))
+
+	dir, err := inLocalDir(root, "")
+	require.NoError(t, err)
+	assert.Equal(t, root, dir)
+
+	dir, err = inLocalDir(root, "backend/api")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "backend", "api"), dir)
+
+	_, err = inLocalDir(root, "../other")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "escapes the project root")
+
+	_, err = inLocalDir(root, "frontend")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "isn't a directory")
+}
+
 func TestPull_DryRunMode(t *testing.T) {
 	tmpDir := t.TempDir()
 	origDir, _ := os.Getwd()
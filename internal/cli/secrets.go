@@ -0,0 +1,196 @@
+package cli
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/secrets"
+	"github.com/rileyhilliard/rr/internal/ui"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// secretsCmd is the parent command for the secret store
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Store tokens and passphrases that config refers to",
+	Long: `Keep credentials out of config files. A secret is stored under a name, in the
+macOS Keychain, in libsecret on a Linux desktop, or in ~/.rr/secrets.age
+encrypted with age, and config refers to it with keychain:<name>:
+
+  hosts:
+    gpu-box:
+      identity_file: ~/.ssh/gpu_ed25519
+      identity_passphrase: keychain:gpu-key
+      env:
+        HF_TOKEN: keychain:hf-token
+
+keychain: works in host, defaults, and task env values, and rr looks the
+secrets up when a command needs the config. Pick the store with secrets: in
+~/.rr/config.yaml (auto, keychain, libsecret, or file).
+
+Examples:
+  rr secrets set hf-token                     # Type it, without echo
+  op read op://ci/webhook | rr secrets set ci/webhook-token
+  rr secrets rm hf-token`,
+}
+
+// secretsSetCmd stores a secret
+var secretsSetCmd = &cobra.Command{
+	Use:   "set <name>",
+	Short: "Store a secret, typed or read from stdin",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return secretsSetCommand(os.Stdout, os.Stdin, args[0])
+	},
+}
+
+// secretsRmCmd deletes a secret
+var secretsRmCmd = &cobra.Command{
+	Use:     "rm <name>",
+	Aliases: []string{"remove"},
+	Short:   "Delete a secret",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return secretsRmCommand(os.Stdout, args[0])
+	},
+}
+
+func init() {
+	secretsCmd.AddCommand(secretsSetCmd)
+	secretsCmd.AddCommand(secretsRmCmd)
+	rootCmd.AddCommand(secretsCmd)
+}
+
+// SecretOutput is the JSON representation of a stored or deleted secret.
+// The secret itself never is.
+type SecretOutput struct {
+	Name    string `json:"name"`
+	Backend string `json:"backend"`
+	Stored  bool   `json:"stored"`
+	Ref     string `json:"ref,omitempty"`
+}
+
+// openSecretStore opens the store the global config picks. Swappable for
+// tests.
+var openSecretStore = func() (secrets.Store, error) {
+	cfg, err := config.LoadGlobal()
+	if err != nil {
+		return nil, err
+	}
+	store, err := secrets.Open(cfg.Secrets)
+	if err != nil {
+		return nil, errors.WrapWithCode(err, errors.ErrConfig,
+			"Can't open the secret store",
+			"Install what it needs, or pick another store with secrets: in ~/.rr/config.yaml.")
+	}
+	return store, nil
+}
+
+// secretsInteractive reports whether a secret can be typed at a prompt.
+// With --non-interactive it's read from stdin instead. Swappable for tests.
+var secretsInteractive = func() bool {
+	return canPrompt()
+}
+
+// promptSecret asks for a secret's value without echoing what's typed.
+// Swappable for tests.
+var promptSecret = func(name string) (string, error) {
+	fmt.Fprintf(os.Stderr, "Value for %s: ", name)
+	value, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	return string(value), err
+}
+
+// secretsSetCommand stores the secret name, typed at a prompt or, when
+// stdin isn't a terminal or with --non-interactive, read from it. A value read from stdin loses one
+// trailing newline, which echo and most password managers add.
+func secretsSetCommand(w io.Writer, stdin io.Reader, name string) error {
+	if err := checkSecretName(name); err != nil {
+		return err
+	}
+
+	var value string
+	if secretsInteractive() {
+		v, err := promptSecret(name)
+		if err != nil {
+			return errors.WrapWithCode(err, errors.ErrConfig,
+				"Didn't get a value for the secret",
+				fmt.Sprintf("Pipe it in instead: ... | rr secrets set %s", name))
+		}
+		value = v
+	} else {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return errors.WrapWithCode(err, errors.ErrConfig,
+				"Couldn't read the secret from stdin",
+				"Run it from a terminal to type the secret instead.")
+		}
+		value = strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+	}
+	if value == "" {
+		return errors.New(errors.ErrConfig,
+			fmt.Sprintf("No value given for the secret '%s'", name),
+			"Type it at the prompt, or pipe it in on stdin.")
+	}
+
+	store, err := openSecretStore()
+	if err != nil {
+		return err
+	}
+	if err := store.Set(name, value); err != nil {
+		return errors.WrapWithCode(err, errors.ErrConfig,
+			fmt.Sprintf("Couldn't store the secret '%s' in the %s store", name, store.Name()),
+			"Check that your keychain is unlocked.")
+	}
+
+	out := SecretOutput{Name: name, Backend: store.Name(), Stored: true, Ref: config.SecretPrefix + name}
+	if MachineMode() {
+		return WriteJSONSuccess(w, out)
+	}
+	fmt.Fprintf(w, "%s Stored '%s' in the %s store. Use it in config as %s\n", ui.SymbolSuccess, name, out.Backend, out.Ref)
+	return nil
+}
+
+// secretsRmCommand deletes the secret name.
+func secretsRmCommand(w io.Writer, name string) error {
+	if err := checkSecretName(name); err != nil {
+		return err
+	}
+	store, err := openSecretStore()
+	if err != nil {
+		return err
+	}
+	err = store.Delete(name)
+	if stderrors.Is(err, secrets.ErrNotFound) {
+		return errors.New(errors.ErrConfig,
+			fmt.Sprintf("There's no secret named '%s' in the %s store", name, store.Name()),
+			"Check the name after keychain: in your config.")
+	}
+	if err != nil {
+		return errors.WrapWithCode(err, errors.ErrConfig,
+			fmt.Sprintf("Couldn't delete the secret '%s'", name),
+			"Check that your keychain is unlocked.")
+	}
+
+	if MachineMode() {
+		return WriteJSONSuccess(w, SecretOutput{Name: name, Backend: store.Name()})
+	}
+	fmt.Fprintf(w, "%s Deleted '%s' from the %s store\n", ui.SymbolSuccess, name, store.Name())
+	return nil
+}
+
+// checkSecretName rejects names the stores can't hold.
+func checkSecretName(name string) error {
+	if secrets.ValidName(name) {
+		return nil
+	}
+	return errors.New(errors.ErrConfig,
+		fmt.Sprintf("'%s' isn't a valid secret name", name),
+		"Use letters, digits, '.', '_', '-' and '/', like ci/webhook-token.")
+}
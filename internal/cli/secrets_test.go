@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mapStore is a secret store in memory.
+type mapStore map[string]string
+
+func (s mapStore) Name() string { return "test" }
+
+func (s mapStore) Get(name string) (string, error) {
+	v, ok := s[name]
+	if !ok {
+		return "", secrets.ErrNotFound
+	}
+	return v, nil
+}
+
+func (s mapStore) Set(name, value string) error {
+	s[name] = value
+	return nil
+}
+
+func (s mapStore) Delete(name string) error {
+	if _, ok := s[name]; !ok {
+		return secrets.ErrNotFound
+	}
+	delete(s, name)
+	return nil
+}
+
+// withSecretStore swaps in an empty store and a non-interactive stdin.
+func withSecretStore(t *testing.T) mapStore {
+	t.Helper()
+	store := mapStore{}
+	origOpen, origInteractive := openSecretStore, secretsInteractive
+	openSecretStore = func() (secrets.Store, error) { return store, nil }
+	secretsInteractive = func() bool { return false }
+	t.Cleanup(func() { openSecretStore, secretsInteractive = origOpen, origInteractive })
+	return store
+}
+
+func TestSecretsSetCommand_FromStdin(t *testing.T) {
+	store := withSecretStore(t)
+	withVerbosity(t, 0, true)
+
+	var out bytes.Buffer
+	require.NoError(t, secretsSetCommand(&out, strings.NewReader("whk_123\n"), "ci/webhook-token"))
+	assert.Equal(t, "whk_123", store["ci/webhook-token"], "one trailing newline is dropped")
+	assert.Contains(t, out.String(), "keychain:ci/webhook-token")
+	assert.NotContains(t, out.String(), "whk_123")
+
+	err := secretsSetCommand(&out, strings.NewReader(""), "empty")
+	assert.ErrorContains(t, err, "No value given")
+
+	err = secretsSetCommand(&out, strings.NewReader("x"), "../escape")
+	assert.ErrorContains(t, err, "isn't a valid secret name")
+}
+
+func TestSecretsSetCommand_Prompt(t *testing.T) {
+	store := withSecretStore(t)
+	withVerbosity(t, 0, true)
+	secretsInteractive = func() bool { return true }
+	origPrompt := promptSecret
+	promptSecret = func(string) (string, error) { return "typed\n", nil }
+	t.Cleanup(func() { promptSecret = origPrompt })
+
+	var out bytes.Buffer
+	require.NoError(t, secretsSetCommand(&out, strings.NewReader("ignored"), "token"))
+	assert.Equal(t, "typed\n", store["token"], "typed values are kept as typed")
+}
+
+func TestSecretsRmCommand(t *testing.T) {
+	store := withSecretStore(t)
+	withVerbosity(t, 0, true)
+	store["token"] = "s3cret"
+
+	var out bytes.Buffer
+	require.NoError(t, secretsRmCommand(&out, "token"))
+	assert.Empty(t, store)
+	assert.Contains(t, out.String(), "Deleted 'token'")
+
+	assert.ErrorContains(t, secretsRmCommand(&out, "token"), "There's no secret named 'token'")
+}
@@ -2,19 +2,24 @@ package cli
 
 import (
 	"fmt"
+	"slices"
+	"sort"
 	"time"
 
 	"github.com/charmbracelet/huh"
+	"github.com/rileyhilliard/rr/internal/config"
 	"github.com/rileyhilliard/rr/internal/errors"
 	"github.com/rileyhilliard/rr/internal/host"
 	"github.com/rileyhilliard/rr/internal/setup"
 	"github.com/rileyhilliard/rr/internal/ui"
+	"github.com/rileyhilliard/rr/pkg/sshutil"
 )
 
 // SetupOptions holds options for the setup command.
 type SetupOptions struct {
 	Host           string // Host to set up
 	NonInteractive bool   // Skip prompts
+	Embedded       bool   // Run as part of 'rr onboard': skip the next-steps hints
 }
 
 // Setup configures SSH keys and tests connection to a host.
@@ -30,7 +35,6 @@ func Setup(opts SetupOptions) error {
 
 	// Step 1: Check for local SSH keys
 	keys := setup.FindLocalKeys()
-	var selectedKey *setup.KeyInfo
 
 	if len(keys) == 0 {
 		fmt.Printf("%s No SSH keys found\n\n", ui.SymbolPending)
@@ -76,8 +80,13 @@ func Setup(opts SetupOptions) error {
 		fmt.Printf("%s Generated key at %s\n\n", ui.SymbolSuccess, keyPath)
 	}
 
-	// Select the best key
-	selectedKey = setup.GetPreferredKey()
+	// Select the key this host should use: its identity_file from rr config,
+	// then ~/.ssh/config, then the best default key
+	identityFile := configuredIdentityFile(opts.Host)
+	selectedKey, err := setup.KeyForHost(opts.Host, identityFile)
+	if err != nil {
+		return err
+	}
 	if selectedKey == nil {
 		return errors.New(errors.ErrSSH,
 			"Couldn't find any usable SSH keys",
@@ -85,13 +94,20 @@ func Setup(opts SetupOptions) error {
 	}
 
 	fmt.Printf("%s Using SSH key: %s (%s)\n", ui.SymbolSuccess, selectedKey.Path, selectedKey.Type)
+	if selectedKey.HasCert {
+		fmt.Printf("%s Using SSH certificate: %s\n", ui.SymbolSuccess, selectedKey.CertPath)
+	}
 
 	// Step 2: Test connection
 	fmt.Println()
 	spinner := ui.NewSpinner("Testing SSH connection")
 	spinner.Start()
 
-	latency, err := host.Probe(opts.Host, 10*time.Second)
+	client, latency, err := host.ProbeAndConnectWithOptions(opts.Host, 10*time.Second,
+		sshutil.DialOptions{IdentityFile: identityFile})
+	if client != nil {
+		client.Close()
+	}
 	if err != nil {
 		spinner.Fail()
 
@@ -119,14 +135,14 @@ func Setup(opts SetupOptions) error {
 				}
 
 				if copyKey {
-					return copyKeyAndTest(opts.Host, selectedKey)
+					return copyKeyAndTest(opts.Host, selectedKey, identityFile, opts.Embedded)
 				}
 			}
 
 			// Show manual instructions
 			fmt.Println("To copy your key manually:")
 			fmt.Println(setup.CopyKeyManual(opts.Host, selectedKey.PublicPath))
-			return nil
+			return keyNotCopied(opts)
 		}
 
 		return errors.WrapWithCode(err, errors.ErrSSH,
@@ -142,7 +158,7 @@ func Setup(opts SetupOptions) error {
 	spinner = ui.NewSpinner("Testing passwordless authentication")
 	spinner.Start()
 
-	authOk, err := setup.TestPasswordlessAuth(opts.Host)
+	authOk, err := setup.TestPasswordlessAuth(opts.Host, passwordlessTestKey(identityFile, selectedKey))
 	if err != nil {
 		spinner.Fail()
 		return err
@@ -169,14 +185,14 @@ func Setup(opts SetupOptions) error {
 			}
 
 			if copyKey {
-				return copyKeyAndTest(opts.Host, selectedKey)
+				return copyKeyAndTest(opts.Host, selectedKey, identityFile, opts.Embedded)
 			}
 		}
 
 		// Show manual instructions
 		fmt.Println("To enable passwordless login manually:")
 		fmt.Println(setup.CopyKeyManual(opts.Host, selectedKey.PublicPath))
-		return nil
+		return keyNotCopied(opts)
 	}
 
 	spinner.Success()
@@ -184,6 +200,9 @@ func Setup(opts SetupOptions) error {
 	// Success summary
 	fmt.Println()
 	fmt.Printf("%s Setup complete for '%s'\n\n", ui.SymbolSuccess, opts.Host)
+	if opts.Embedded {
+		return nil
+	}
 	fmt.Println("You can now:")
 	fmt.Println("  rr init        - Create a config file using this host")
 	fmt.Println("  rr sync        - Sync files to remote")
@@ -193,7 +212,8 @@ func Setup(opts SetupOptions) error {
 }
 
 // copyKeyAndTest copies the SSH key and verifies it works.
-func copyKeyAndTest(host string, key *setup.KeyInfo) error {
+// embedded skips the next-steps hints, as in Setup.
+func copyKeyAndTest(host string, key *setup.KeyInfo, identityFile string, embedded bool) error {
 	fmt.Println()
 	spinner := ui.NewSpinner("Copying SSH key")
 	spinner.Start()
@@ -214,7 +234,7 @@ func copyKeyAndTest(host string, key *setup.KeyInfo) error {
 	spinner = ui.NewSpinner("Verifying passwordless login")
 	spinner.Start()
 
-	authOk, err := setup.TestPasswordlessAuth(host)
+	authOk, err := setup.TestPasswordlessAuth(host, passwordlessTestKey(identityFile, key))
 	if err != nil || !authOk {
 		spinner.Fail()
 		fmt.Printf("\n%s Key copied but passwordless login still not working\n", ui.SymbolPending)
@@ -227,6 +247,9 @@ func copyKeyAndTest(host string, key *setup.KeyInfo) error {
 	// Success
 	fmt.Println()
 	fmt.Printf("%s Setup complete! Passwordless login to '%s' is working.\n\n", ui.SymbolSuccess, host)
+	if embedded {
+		return nil
+	}
 	fmt.Println("Next steps:")
 	fmt.Println("  rr init        - Create a config file using this host")
 	fmt.Println("  rr sync        - Sync files to remote")
@@ -235,9 +258,58 @@ func copyKeyAndTest(host string, key *setup.KeyInfo) error {
 	return nil
 }
 
+// configuredIdentityFile returns the identity_file of the configured host that
+// target refers to, matched by host name or SSH alias. Returns "" if there's
+// no global config or no host sets one.
+func configuredIdentityFile(target string) string {
+	cfg, err := config.LoadGlobal()
+	if err != nil || cfg == nil {
+		return ""
+	}
+	if h, ok := cfg.Hosts[target]; ok && h.IdentityFile != "" {
+		return h.IdentityFile
+	}
+	names := make([]string, 0, len(cfg.Hosts))
+	for name := range cfg.Hosts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		h := cfg.Hosts[name]
+		if h.IdentityFile != "" && slices.Contains(h.SSH, target) {
+			return h.IdentityFile
+		}
+	}
+	return ""
+}
+
+// keyNotCopied is what Setup returns after printing how to copy the key by
+// hand. Interactively that's the answer the user asked for; with
+// --non-interactive nothing was asked, so the host still isn't set up and
+// the run fails rather than looking like it worked.
+func keyNotCopied(opts SetupOptions) error {
+	if !opts.NonInteractive {
+		return nil
+	}
+	return errors.New(errors.ErrSSH,
+		fmt.Sprintf("Passwordless SSH to %s isn't set up", opts.Host),
+		"Copy your key over as shown above, or run 'rr setup' from a terminal to have rr do it.")
+}
+
+// passwordlessTestKey returns the key to pin when verifying passwordless
+// login. Only a host with an explicit identity_file is pinned; otherwise ssh
+// picks keys as it would for rsync.
+func passwordlessTestKey(identityFile string, key *setup.KeyInfo) string {
+	if identityFile == "" || key == nil {
+		return ""
+	}
+	return key.Path
+}
+
 // setupCommand is the implementation called by the cobra command.
 func setupCommand(host string) error {
 	return Setup(SetupOptions{
-		Host: host,
+		Host:           host,
+		NonInteractive: NonInteractive(),
 	})
 }
@@ -0,0 +1,257 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/exec"
+	"github.com/rileyhilliard/rr/internal/parallel"
+	"github.com/rileyhilliard/rr/internal/ui"
+	"github.com/rileyhilliard/rr/internal/util"
+)
+
+// runSpeculativeTaskCommand runs a task marked speculative: true on the two
+// highest-priority hosts at once and keeps the first successful result.
+// When fewer than two hosts can run the task it runs normally instead.
+// The caller has checked flags doesn't pin the task to a host, locally, or
+// ask to repeat it.
+func runSpeculativeTaskCommand(taskName string, args []string, params map[string]string, flags taskFlags) error {
+	resolved, err := loadResolved(Config())
+	if err != nil {
+		return err
+	}
+	if err := config.ValidateResolved(resolved); err != nil {
+		return err
+	}
+	applyTaskParams(resolved.Project, taskName, params)
+
+	task, env, err := config.GetTaskWithMergedEnv(resolved.Project, taskName, nil)
+	if err != nil {
+		return err
+	}
+
+	hostOrder, hosts, err := config.ResolveHosts(resolved, "")
+	if err != nil {
+		return err
+	}
+	if flags.Tag != "" {
+		hosts, hostOrder = filterHostsByTag(hosts, hostOrder, flags.Tag)
+	}
+	hostOrder = speculativeHosts(task, hostOrder)
+
+	if len(hostOrder) < 2 {
+		return runTaskCommand(taskName, args, params, taskFlags{
+			Tag:          flags.Tag,
+			ProbeTimeout: flags.ProbeTimeout,
+			NoSummary:    flags.NoSummary,
+			Diagnostics:  flags.Diagnostics,
+			Force:        flags.Force,
+			Rebootstrap:  flags.Rebootstrap,
+		})
+	}
+
+	cmd, err := speculativeCommand(task, args)
+	if err != nil {
+		return err
+	}
+
+	parallelCfg := parallel.Config{OutputMode: parallel.OutputQuiet, Force: flags.Force, Rebootstrap: flags.Rebootstrap, SyncProfile: task.SyncProfile}
+	if task.Timeout != "" {
+		d, err := time.ParseDuration(task.Timeout)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: invalid timeout '%s', ignoring: %v\n", task.Timeout, err)
+		} else {
+			parallelCfg.Timeout = d
+		}
+	}
+
+	tasks := []parallel.TaskInfo{{Name: taskName, Command: cmd, Env: env}}
+	orchestrator := parallel.NewOrchestrator(tasks, hosts, hostOrder, resolved, parallelCfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+	go func() {
+		select {
+		case <-sigChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	var spinner *ui.Spinner
+	if PrettyMode() {
+		renderTaskHeader(ui.NewPhaseDisplay(os.Stdout), taskName, task)
+		spinner = ui.NewSpinner(fmt.Sprintf("Racing on %s", strings.Join(hostOrder[:parallel.SpeculativeHosts], " and ")))
+		spinner.Start()
+	}
+
+	result, err := orchestrator.RunSpeculative(ctx)
+	if spinner != nil {
+		spinner.Dismiss()
+	}
+	if err != nil {
+		return err
+	}
+
+	if ctx.Err() != nil && !result.Success() {
+		return errors.NewExitError(130)
+	}
+
+	if flags.Diagnostics {
+		writeSpeculativeDiagnostics(resolved.ProjectRoot, taskName, hosts, result)
+	}
+
+	exitCode := renderSpeculativeResult(result, taskName)
+	if exitCode != 0 {
+		return errors.NewExitError(exitCode)
+	}
+	return nil
+}
+
+// speculativeHosts returns the hosts, in priority order, that the task is
+// allowed to run on.
+func speculativeHosts(task *config.TaskConfig, hostOrder []string) []string {
+	allowed := make([]string, 0, len(hostOrder))
+	for _, name := range hostOrder {
+		if config.IsTaskHostAllowed(task, name) {
+			allowed = append(allowed, name)
+		}
+	}
+	return allowed
+}
+
+// speculativeCommand builds the shell command for a speculative task, with
+// any extra CLI arguments appended.
+func speculativeCommand(task *config.TaskConfig, args []string) (string, error) {
+	if len(task.Steps) > 0 {
+		if len(args) > 0 {
+			return "", errors.New(errors.ErrConfig,
+				"Can't pass arguments to multi-step tasks",
+				"Arguments are only supported for tasks with a single 'run' command.")
+		}
+		steps, err := exec.TaskSteps(task, task.Steps)
+		if err != nil {
+			return "", err
+		}
+		return buildStepsCommand(steps), nil
+	}
+
+	cmd := task.Run
+	if len(args) > 0 {
+		quoted := make([]string, len(args))
+		for i, a := range args {
+			quoted[i] = util.ShellQuote(a)
+		}
+		cmd += " " + strings.Join(quoted, " ")
+	}
+	return exec.TaskCommand(task, cmd)
+}
+
+// speculativeExitCode returns the exit code a speculative run reports.
+func speculativeExitCode(result *parallel.SpeculativeResult) int {
+	outcome := result.Outcome()
+	switch {
+	case outcome == nil:
+		return 1
+	case outcome.Success():
+		return 0
+	case outcome.ExitCode == 0:
+		return 1
+	default:
+		return outcome.ExitCode
+	}
+}
+
+// renderSpeculativeResult prints the deciding attempt's output and how each
+// attempt ended, and returns the exit code.
+func renderSpeculativeResult(result *parallel.SpeculativeResult, taskName string) int {
+	exitCode := speculativeExitCode(result)
+	outcome := result.Outcome()
+
+	if outcome != nil && len(outcome.Output) > 0 {
+		os.Stdout.Write(outcome.Output) //nolint:errcheck // Best-effort output
+	}
+
+	if !PrettyMode() {
+		attempts := make([]map[string]interface{}, 0, len(result.Attempts))
+		for i := range result.Attempts {
+			a := &result.Attempts[i]
+			entry := map[string]interface{}{
+				"host":       a.Host,
+				"exit_code":  a.ExitCode,
+				"duration_s": a.Duration.Seconds(),
+				"cancelled":  result.Cancelled(a),
+			}
+			if a.Error != nil && !result.Cancelled(a) {
+				entry["error"] = a.Error.Error()
+			}
+			if a.OutputFile != "" {
+				entry["log_file"] = a.OutputFile
+			}
+			attempts = append(attempts, entry)
+		}
+		event := PhaseEvent{
+			Type:     "result",
+			Status:   map[bool]string{true: "success", false: "failed"}[exitCode == 0],
+			Duration: result.Duration.Seconds(),
+			ExitCode: &exitCode,
+			Details:  map[string]interface{}{"speculative": true, "attempts": attempts},
+		}
+		if outcome != nil {
+			event.Host = outcome.Host
+		}
+		WritePhaseEvent(event)
+		return exitCode
+	}
+
+	mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
+	if outcome != nil && len(outcome.Output) > 0 {
+		fmt.Println()
+	}
+	if outcome != nil {
+		if note := parallel.OutputSpillNote(outcome); note != "" {
+			fmt.Println(mutedStyle.Render(note))
+		}
+	}
+	if result.Success() {
+		successStyle := lipgloss.NewStyle().Foreground(ui.ColorSuccess)
+		fmt.Printf("%s Task '%s' completed on %s %s\n",
+			successStyle.Render(ui.SymbolSuccess), taskName, result.Winner.Host,
+			mutedStyle.Render(fmt.Sprintf("(%.1fs)", result.Duration.Seconds())))
+	} else {
+		errorStyle := lipgloss.NewStyle().Foreground(ui.ColorError)
+		fmt.Printf("%s Task '%s' failed on every host it raced on\n",
+			errorStyle.Render(ui.SymbolFail), taskName)
+	}
+
+	for i := range result.Attempts {
+		a := &result.Attempts[i]
+		if a == result.Winner || a.Host == "none" {
+			continue
+		}
+		var status string
+		switch {
+		case result.Cancelled(a):
+			status = "cancelled"
+		case a.Error != nil:
+			status = a.Error.Error()
+		default:
+			status = fmt.Sprintf("exit code %d", a.ExitCode)
+		}
+		fmt.Println(mutedStyle.Render(fmt.Sprintf("  %s: %s (%.1fs)", a.Host, status, a.Duration.Seconds())))
+	}
+
+	return exitCode
+}
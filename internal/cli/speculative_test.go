@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/parallel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpeculativeHosts(t *testing.T) {
+	order := []string{"mini", "workstation", "gpu"}
+
+	assert.Equal(t, order, speculativeHosts(&config.TaskConfig{}, order))
+	assert.Equal(t, []string{"workstation", "gpu"},
+		speculativeHosts(&config.TaskConfig{Hosts: []string{"gpu", "workstation"}}, order),
+		"keeps priority order, not the task's order")
+}
+
+func TestSpeculativeCommand(t *testing.T) {
+	cmd, err := speculativeCommand(&config.TaskConfig{Run: "pytest"}, []string{"-k", "bond and not slow"})
+	require.NoError(t, err)
+	assert.Equal(t, "pytest '-k' 'bond and not slow'", cmd)
+
+	steps := &config.TaskConfig{Steps: []config.TaskStep{{Run: "make"}, {Run: "make test"}}}
+	cmd, err = speculativeCommand(steps, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "(make) && (make test)", cmd)
+
+	_, err = speculativeCommand(steps, []string{"-v"})
+	assert.Error(t, err)
+}
+
+func TestSpeculativeExitCode(t *testing.T) {
+	won := &parallel.SpeculativeResult{Attempts: []parallel.TaskResult{
+		{Host: "workstation"},
+		{Host: "mini", ExitCode: 130, Error: context.Canceled},
+	}}
+	won.Winner = &won.Attempts[0]
+	assert.Equal(t, 0, speculativeExitCode(won))
+
+	failed := &parallel.SpeculativeResult{Attempts: []parallel.TaskResult{
+		{Host: "mini", ExitCode: 2},
+		{Host: "workstation", ExitCode: 1},
+	}}
+	assert.Equal(t, 2, speculativeExitCode(failed), "first attempt to fail decides")
+
+	errored := &parallel.SpeculativeResult{Attempts: []parallel.TaskResult{
+		{Host: "mini", Error: assert.AnError},
+	}}
+	assert.Equal(t, 1, speculativeExitCode(errored))
+}
@@ -0,0 +1,211 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/stats"
+	"github.com/rileyhilliard/rr/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statsEnable  bool
+	statsDisable bool
+	statsReset   bool
+)
+
+// statsTopErrors is how many error IDs rr stats shows.
+const statsTopErrors = 10
+
+// statsCmd shows the locally recorded run statistics
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show where run time goes and which errors come up most",
+	Long: `Show statistics rr has recorded about your runs on this machine: how much of
+the total run time each phase (connect, sync, exec, ...) takes, how many runs
+failed, and the error IDs that came up most often.
+
+Recording is off until you turn it on. Only counts, durations, and error IDs
+like RR-SSH-003 are kept, in ~/.rr/stats.json. Commands, hosts, paths, and
+output never are, and nothing is sent anywhere. Look up an error ID with
+'rr explain <id>'.
+
+Examples:
+  rr stats --enable     # Start recording (sets stats: true in ~/.rr/config.yaml)
+  rr stats              # Show what's been recorded
+  rr stats --reset      # Forget everything recorded so far
+  rr stats --disable    # Stop recording`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return statsCommand(os.Stdout, statsEnable, statsDisable, statsReset)
+	},
+}
+
+func init() {
+	statsCmd.Flags().BoolVar(&statsEnable, "enable", false, "start recording run statistics")
+	statsCmd.Flags().BoolVar(&statsDisable, "disable", false, "stop recording run statistics")
+	statsCmd.Flags().BoolVar(&statsReset, "reset", false, "delete the statistics recorded so far")
+	statsCmd.MarkFlagsMutuallyExclusive("enable", "disable")
+	rootCmd.AddCommand(statsCmd)
+}
+
+// StatsPhaseOutput is the JSON representation of one phase's totals.
+type StatsPhaseOutput struct {
+	Name      string  `json:"name"`
+	Count     int     `json:"count"`
+	TotalMs   int64   `json:"total_ms"`
+	AverageMs int64   `json:"average_ms"`
+	Share     float64 `json:"share"`
+}
+
+// StatsErrorOutput is the JSON representation of one error ID's count.
+type StatsErrorOutput struct {
+	ID    string `json:"id"`
+	Count int    `json:"count"`
+}
+
+// StatsOutput is the JSON representation of rr stats.
+type StatsOutput struct {
+	Enabled bool               `json:"enabled"`
+	Since   string             `json:"since,omitempty"`
+	Runs    int                `json:"runs"`
+	Failed  int                `json:"failed"`
+	TotalMs int64              `json:"total_ms"`
+	Phases  []StatsPhaseOutput `json:"phases"`
+	Errors  []StatsErrorOutput `json:"errors"`
+}
+
+// statsCommand turns recording on or off, resets the statistics, or shows
+// them when none of those are asked for.
+func statsCommand(w io.Writer, enable, disable, reset bool) error {
+	if enable || disable {
+		if err := setStatsEnabled(enable); err != nil {
+			return err
+		}
+	}
+	if reset {
+		if err := stats.Reset(); err != nil {
+			return err
+		}
+	}
+	if (enable || disable || reset) && PrettyMode() {
+		switch {
+		case enable:
+			fmt.Fprintf(w, "%s Recording run statistics in ~/.rr/stats.json\n", ui.SymbolSuccess)
+		case disable:
+			fmt.Fprintf(w, "%s Stopped recording run statistics\n", ui.SymbolSuccess)
+		}
+		if reset {
+			fmt.Fprintf(w, "%s Deleted the recorded run statistics\n", ui.SymbolSuccess)
+		}
+		return nil
+	}
+
+	s, err := stats.Load()
+	if err != nil {
+		return err
+	}
+	out := buildStatsOutput(s, stats.Enabled)
+	if MachineMode() {
+		return WriteJSONSuccess(w, out)
+	}
+	renderStats(w, out)
+	return nil
+}
+
+// setStatsEnabled sets stats in the global config and for the rest of this
+// command.
+func setStatsEnabled(on bool) error {
+	cfg, err := config.LoadGlobal()
+	if err != nil {
+		return err
+	}
+	stats.Enabled = on
+	if cfg.Stats == on {
+		return nil
+	}
+	cfg.Stats = on
+	if err := config.SaveGlobal(cfg); err != nil {
+		return errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't save the stats setting",
+			"Set stats: true or false in ~/.rr/config.yaml by hand.")
+	}
+	return nil
+}
+
+// buildStatsOutput turns recorded statistics into their JSON representation.
+func buildStatsOutput(s *stats.Stats, enabled bool) StatsOutput {
+	out := StatsOutput{
+		Enabled: enabled,
+		Runs:    s.Runs,
+		Failed:  s.Failed,
+		TotalMs: s.Total.Milliseconds(),
+		Phases:  []StatsPhaseOutput{},
+		Errors:  []StatsErrorOutput{},
+	}
+	if !s.Since.IsZero() {
+		out.Since = s.Since.UTC().Format(time.RFC3339)
+	}
+	for _, p := range s.PhaseShares() {
+		out.Phases = append(out.Phases, StatsPhaseOutput{
+			Name:      p.Name,
+			Count:     p.Count,
+			TotalMs:   p.Total.Milliseconds(),
+			AverageMs: p.Average.Milliseconds(),
+			Share:     p.Share,
+		})
+	}
+	for _, e := range s.TopErrors(statsTopErrors) {
+		out.Errors = append(out.Errors, StatsErrorOutput{ID: e.ID, Count: e.Count})
+	}
+	return out
+}
+
+// renderStats prints each phase's share of the run time and the most common
+// error IDs.
+func renderStats(w io.Writer, out StatsOutput) {
+	mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
+
+	if !out.Enabled {
+		fmt.Fprintln(w, mutedStyle.Render("Recording is off. Turn it on with 'rr stats --enable'."))
+	}
+	if out.Runs == 0 && len(out.Errors) == 0 {
+		fmt.Fprintln(w, "No runs recorded yet.")
+		return
+	}
+	if !out.Enabled {
+		fmt.Fprintln(w)
+	}
+
+	since := ""
+	if t, err := time.Parse(time.RFC3339, out.Since); err == nil {
+		since = " since " + t.Local().Format("Jan 2, 2006")
+	}
+	fmt.Fprintf(w, "%d runs%s, %d failed, %s total\n", out.Runs, since, out.Failed,
+		ui.FormatElapsed(time.Duration(out.TotalMs)*time.Millisecond))
+
+	if len(out.Phases) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "  %-10s %6s %10s %10s\n", "phase", "share", "total", "average")
+		for _, p := range out.Phases {
+			fmt.Fprintf(w, "  %-10s %5.0f%% %10s %10s\n", p.Name, p.Share*100,
+				ui.FormatElapsed(time.Duration(p.TotalMs)*time.Millisecond),
+				ui.FormatElapsed(time.Duration(p.AverageMs)*time.Millisecond))
+		}
+	}
+
+	if len(out.Errors) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Most common errors:")
+		for _, e := range out.Errors {
+			fmt.Fprintf(w, "  %-14s %d\n", e.ID, e.Count)
+		}
+		fmt.Fprintln(w, mutedStyle.Render("  Look one up with 'rr explain <id>'."))
+	}
+}
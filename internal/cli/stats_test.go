@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withStatsHome gives a test its own ~/.rr and restores recording after.
+func withStatsHome(t *testing.T) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	old := stats.Enabled
+	t.Cleanup(func() { stats.Enabled = old })
+}
+
+func TestStatsCommand_EnableRecordsAndShows(t *testing.T) {
+	withStatsHome(t)
+	withVerbosity(t, 0, true)
+
+	var out bytes.Buffer
+	require.NoError(t, statsCommand(&out, true, false, false))
+	assert.Contains(t, out.String(), "Recording run statistics")
+
+	global, err := config.LoadGlobal()
+	require.NoError(t, err)
+	assert.True(t, global.Stats)
+
+	require.NoError(t, stats.RecordRun(map[string]time.Duration{"sync": 8 * time.Second, "exec": 2 * time.Second}, 10*time.Second, 1))
+	require.NoError(t, stats.RecordError("RR-SSH-003"))
+
+	out.Reset()
+	require.NoError(t, statsCommand(&out, false, false, false))
+	text := out.String()
+	assert.Contains(t, text, "1 runs")
+	assert.Contains(t, text, "1 failed")
+	assert.Contains(t, text, "sync")
+	assert.Contains(t, text, "80%")
+	assert.Contains(t, text, "RR-SSH-003")
+	assert.NotContains(t, text, "Recording is off")
+}
+
+func TestStatsCommand_DisabledHint(t *testing.T) {
+	withStatsHome(t)
+	withVerbosity(t, 0, true)
+	stats.Enabled = false
+
+	var out bytes.Buffer
+	require.NoError(t, statsCommand(&out, false, false, false))
+	assert.Contains(t, out.String(), "rr stats --enable")
+	assert.Contains(t, out.String(), "No runs recorded yet")
+}
+
+func TestStatsCommand_ResetAndDisable(t *testing.T) {
+	withStatsHome(t)
+	withVerbosity(t, 0, false)
+	stats.Enabled = true
+	require.NoError(t, stats.RecordError("RR-LOCK-001"))
+
+	var out bytes.Buffer
+	require.NoError(t, statsCommand(&out, false, true, true))
+	assert.False(t, stats.Enabled)
+
+	var resp struct {
+		Success bool        `json:"success"`
+		Data    StatsOutput `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &resp))
+	assert.True(t, resp.Success)
+	assert.False(t, resp.Data.Enabled)
+	assert.Zero(t, resp.Data.Runs)
+	assert.Empty(t, resp.Data.Errors)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	gosync "sync"
 	"time"
 
@@ -23,8 +24,9 @@ func init() {
 
 // StatusOutput represents the JSON output for status command.
 type StatusOutput struct {
-	Hosts    []HostStatus `json:"hosts"`
-	Selected *Selected    `json:"selected,omitempty"`
+	Hosts    []HostStatus   `json:"hosts"`
+	Selected *Selected      `json:"selected,omitempty"`
+	Project  *ProjectStatus `json:"project,omitempty"`
 }
 
 // HostStatus represents a single host's status.
@@ -50,11 +52,11 @@ type Selected struct {
 
 // statusCommand implements the status command logic.
 func statusCommand() error {
-	// Load global config (hosts are stored globally now)
-	globalCfg, err := config.LoadGlobal()
+	resolved, err := loadResolved(Config())
 	if err != nil {
 		return err
 	}
+	globalCfg := resolved.Global
 
 	if len(globalCfg.Hosts) == 0 {
 		return errors.New(errors.ErrConfig,
@@ -65,15 +67,22 @@ func statusCommand() error {
 	// Probe all hosts in parallel
 	results := probeAllHosts(globalCfg.Hosts)
 
-	// Determine which host would be selected (first healthy host)
-	selected := findSelectedHost(results)
+	// The selected host is the first healthy one in the order a run tries them
+	order, _, _ := config.ResolveHosts(resolved, "")
+	selected := findSelectedHost(results, order)
+
+	// Project state is only shown inside a project
+	var project *ProjectStatus
+	if resolved.ProjectRoot != "" {
+		project = collectProjectStatus(resolved, selected)
+	}
 
 	// JSON output: explicit --json flag, or default structured mode (not --pretty)
 	if statusJSON || MachineMode() {
-		return outputStatusJSON(results, selected)
+		return outputStatusJSON(results, selected, project)
 	}
 
-	return outputStatusText(results, selected)
+	return outputStatusText(results, selected, project)
 }
 
 // probeResult holds the result of probing a single host.
@@ -111,9 +120,20 @@ func probeAllHosts(hosts map[string]config.Host) map[string]probeResult {
 }
 
 // findSelectedHost determines which host/alias would be used for the next command.
-// Returns the first healthy host found.
-func findSelectedHost(results map[string]probeResult) *Selected {
-	for name, result := range results {
+// Returns the first healthy host in order, then any other healthy host by name.
+func findSelectedHost(results map[string]probeResult, order []string) *Selected {
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	names = append(append([]string{}, order...), names...)
+
+	for _, name := range names {
+		result, ok := results[name]
+		if !ok {
+			continue
+		}
 		for _, alias := range result.Aliases {
 			if alias.Success {
 				return &Selected{Host: name, Alias: alias.SSHAlias}
@@ -125,10 +145,11 @@ func findSelectedHost(results map[string]probeResult) *Selected {
 
 // outputStatusJSON outputs status in JSON format.
 // When MachineMode() is enabled, wraps output in the standard JSON envelope.
-func outputStatusJSON(results map[string]probeResult, selected *Selected) error {
+func outputStatusJSON(results map[string]probeResult, selected *Selected, project *ProjectStatus) error {
 	output := StatusOutput{
 		Hosts:    make([]HostStatus, 0, len(results)),
 		Selected: selected,
+		Project:  project,
 	}
 
 	for name, result := range results {
@@ -170,7 +191,7 @@ func outputStatusJSON(results map[string]probeResult, selected *Selected) error
 }
 
 // outputStatusText outputs status in human-readable format using a table.
-func outputStatusText(results map[string]probeResult, selected *Selected) error {
+func outputStatusText(results map[string]probeResult, selected *Selected, project *ProjectStatus) error {
 	mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
 	errorStyle := lipgloss.NewStyle().Foreground(ui.ColorError)
 
@@ -223,6 +244,11 @@ func outputStatusText(results map[string]probeResult, selected *Selected) error
 		fmt.Printf("Selected: %s\n", errorStyle.Render("none (no reachable hosts)"))
 	}
 
+	if project != nil {
+		fmt.Println()
+		fmt.Println(renderProjectStatus(project, time.Now()))
+	}
+
 	return nil
 }
 
@@ -0,0 +1,287 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/history"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/rileyhilliard/rr/internal/lock"
+	rrsync "github.com/rileyhilliard/rr/internal/sync"
+	"github.com/rileyhilliard/rr/internal/ui"
+	"github.com/rileyhilliard/rr/internal/watch"
+)
+
+// ProjectStatus is the state of the current project: its sync and lock state
+// on the selected host, the last run, and anything running in the background.
+type ProjectStatus struct {
+	Root string `json:"root"`
+	Host string `json:"host,omitempty"` // Selected host the sync and lock state are for
+
+	LastSync        *time.Time `json:"last_sync,omitempty"`
+	SyncInterrupted bool       `json:"sync_interrupted,omitempty"`
+	ChangedFiles    *int       `json:"changed_files,omitempty"` // Local files modified since LastSync
+	Lock            *LockState `json:"lock,omitempty"`
+	LastRun         *LastRun   `json:"last_run,omitempty"`
+	SyncDaemon      *Daemon    `json:"sync_daemon,omitempty"`
+	Watchers        []Watcher  `json:"watchers,omitempty"`
+}
+
+// LockState is the lock on the selected host.
+type LockState struct {
+	Locked bool   `json:"locked"`
+	Holder string `json:"holder,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// LastRun is the most recent run recorded in the project's history.
+type LastRun struct {
+	Name     string    `json:"name"` // Command or "task <name>"
+	Host     string    `json:"host"`
+	Time     time.Time `json:"time"`
+	ExitCode int       `json:"exit_code"`
+	Duration string    `json:"duration"`
+}
+
+// Daemon is a running 'rr sync --daemon' for the project.
+type Daemon struct {
+	PID     int    `json:"pid"`
+	Host    string `json:"host"`
+	Current bool   `json:"current"` // Every local change has been pushed
+}
+
+// Watcher is a watch task running for the project.
+type Watcher struct {
+	Task     string    `json:"task"`
+	Host     string    `json:"host"`
+	PID      int       `json:"pid"`
+	Running  bool      `json:"running"` // false while waiting to restart
+	Since    time.Time `json:"since"`   // When the command last started or exited
+	Restarts int       `json:"restarts"`
+	Ports    []string  `json:"ports,omitempty"`
+}
+
+// statusLockHolder connects to a host and returns who holds the project's
+// lock, or "" when it's free. With command-scoped locks that's everyone
+// holding one of the project's command locks. Swapped out in tests.
+var statusLockHolder = func(name, alias string, h config.Host, lockCfg config.LockConfig) (string, error) {
+	h, err := config.ResolveHostSecrets(name, h)
+	if err != nil {
+		return "", err
+	}
+	client, _, err := host.Connect(alias, host.DefaultProbeTimeout, h)
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+	conn := &host.Connection{Name: name, Alias: alias, Client: client, Host: h}
+	var holders []string
+	for _, dir := range lock.Held(conn, lockCfg) {
+		holders = append(holders, lock.Holder(conn, dir))
+	}
+	return strings.Join(holders, ", "), nil
+}
+
+// collectProjectStatus gathers the project's state. Sync and lock state need a
+// selected host; the rest is local.
+func collectProjectStatus(resolved *config.ResolvedConfig, selected *Selected) *ProjectStatus {
+	root := resolved.ProjectRoot
+	status := &ProjectStatus{Root: root}
+
+	project := resolved.Project
+	if project == nil {
+		project = config.DefaultConfig()
+	}
+
+	if selected != nil {
+		status.Host = selected.Host
+		collectSyncStatus(status, root, selected.Host, project.Sync)
+
+		if project.Lock.Enabled {
+			holder, err := statusLockHolder(selected.Host, selected.Alias, resolved.Global.Hosts[selected.Host], project.Lock)
+			switch {
+			case err != nil:
+				status.Lock = &LockState{Error: err.Error()}
+			default:
+				status.Lock = &LockState{Locked: holder != "", Holder: holder}
+			}
+		}
+	}
+
+	if entries, err := history.Load(root); err == nil && len(entries) > 0 {
+		last := entries[len(entries)-1]
+		status.LastRun = &LastRun{
+			Name:     historyKeyName(last.Key),
+			Host:     last.Host,
+			Time:     last.Time,
+			ExitCode: last.ExitCode,
+			Duration: fmt.Sprintf("%.1fs", last.Total.Seconds()),
+		}
+	}
+
+	if state, err := rrsync.ReadDaemonState(root); err == nil && state.Running() {
+		status.SyncDaemon = &Daemon{
+			PID:     state.PID,
+			Host:    state.Host,
+			Current: state.IsCurrent(state.Host, state.RemoteDir),
+		}
+	}
+
+	for _, w := range watch.ForProject(root) {
+		status.Watchers = append(status.Watchers, Watcher{
+			Task:     w.Task,
+			Host:     w.Host,
+			PID:      w.PID,
+			Running:  w.Running,
+			Since:    w.Since,
+			Restarts: w.Restarts,
+			Ports:    w.Ports,
+		})
+	}
+
+	return status
+}
+
+// collectSyncStatus fills in when the project was last synced to a host and
+// how many local files have changed since.
+func collectSyncStatus(status *ProjectStatus, root, hostName string, syncCfg config.SyncConfig) {
+	record := rrsync.ReadSyncRecord(root, hostName)
+	if record == nil || record.Finished.IsZero() {
+		status.SyncInterrupted = record.Interrupted()
+		return
+	}
+	finished := record.Finished
+	status.LastSync = &finished
+	status.SyncInterrupted = record.Interrupted()
+	if changed, err := rrsync.CountChangedSince(root, syncCfg, finished); err == nil {
+		status.ChangedFiles = &changed
+	}
+}
+
+// historyKeyName turns a history key ("run:make test", "task:build") into
+// what the user typed.
+func historyKeyName(key string) string {
+	if name, ok := strings.CutPrefix(key, "task:"); ok {
+		return "task " + name
+	}
+	if cmd, ok := strings.CutPrefix(key, "run:"); ok {
+		return cmd
+	}
+	return key
+}
+
+// renderProjectStatus renders the project section of 'rr status'.
+func renderProjectStatus(p *ProjectStatus, now time.Time) string {
+	mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
+	warnStyle := lipgloss.NewStyle().Foreground(ui.ColorWarning)
+	errorStyle := lipgloss.NewStyle().Foreground(ui.ColorError)
+	successStyle := lipgloss.NewStyle().Foreground(ui.ColorSuccess)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Project: %s\n", p.Root)
+	line := func(label, value string) {
+		fmt.Fprintf(&b, "  %-12s %s\n", label+":", value)
+	}
+
+	// Sync
+	switch {
+	case p.Host == "":
+		line("Last sync", mutedStyle.Render("unknown (no reachable host)"))
+	case p.LastSync == nil:
+		line("Last sync", mutedStyle.Render("never synced to "+p.Host))
+	default:
+		value := fmt.Sprintf("%s to %s", formatAge(now.Sub(*p.LastSync)), p.Host)
+		if p.ChangedFiles != nil {
+			switch *p.ChangedFiles {
+			case 0:
+				value += " " + mutedStyle.Render("(no local changes)")
+			case 1:
+				value += " " + warnStyle.Render("(1 file changed since)")
+			default:
+				value += " " + warnStyle.Render(fmt.Sprintf("(%d files changed since)", *p.ChangedFiles))
+			}
+		}
+		line("Last sync", value)
+	}
+	if p.SyncInterrupted {
+		line("", warnStyle.Render("last sync was interrupted; the next one resumes it"))
+	}
+
+	// Lock
+	if p.Lock != nil {
+		switch {
+		case p.Lock.Error != "":
+			line("Lock", errorStyle.Render("couldn't check: "+p.Lock.Error))
+		case p.Lock.Locked:
+			line("Lock", warnStyle.Render("held by "+p.Lock.Holder))
+		default:
+			line("Lock", successStyle.Render("free"))
+		}
+	}
+
+	// Last run
+	if p.LastRun == nil {
+		line("Last run", mutedStyle.Render("none recorded"))
+	} else {
+		result := successStyle.Render(ui.SymbolSuccess + " passed")
+		if p.LastRun.ExitCode != 0 {
+			result = errorStyle.Render(fmt.Sprintf("%s exit %d", ui.SymbolFail, p.LastRun.ExitCode))
+		}
+		line("Last run", fmt.Sprintf("%s %s %s",
+			p.LastRun.Name, result,
+			mutedStyle.Render(fmt.Sprintf("(on %s, %s, %s)", p.LastRun.Host, p.LastRun.Duration, formatAge(now.Sub(p.LastRun.Time))))))
+	}
+
+	// Background
+	var background []string
+	if p.SyncDaemon != nil {
+		state := "up to date"
+		if !p.SyncDaemon.Current {
+			state = "pushing changes"
+		}
+		background = append(background, fmt.Sprintf("sync daemon to %s %s", p.SyncDaemon.Host,
+			mutedStyle.Render(fmt.Sprintf("(pid %d, %s)", p.SyncDaemon.PID, state))))
+	}
+	for _, w := range p.Watchers {
+		background = append(background, renderWatcher(w, now))
+	}
+	if len(background) == 0 {
+		line("Background", mutedStyle.Render("nothing running"))
+	}
+	for i, entry := range background {
+		label := ""
+		if i == 0 {
+			label = "Background"
+		}
+		line(label, entry)
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// renderWatcher describes a running watch task, e.g.
+// "watch dev on m4 (started 5 minutes ago, 2 restarts, ports 3000)".
+func renderWatcher(w Watcher, now time.Time) string {
+	mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
+	warnStyle := lipgloss.NewStyle().Foreground(ui.ColorWarning)
+
+	state := "started " + formatAge(now.Sub(w.Since))
+	if !w.Running {
+		state = warnStyle.Render("restarting")
+	}
+	details := []string{state}
+	switch w.Restarts {
+	case 0:
+	case 1:
+		details = append(details, "1 restart")
+	default:
+		details = append(details, fmt.Sprintf("%d restarts", w.Restarts))
+	}
+	if len(w.Ports) > 0 {
+		details = append(details, "ports "+strings.Join(w.Ports, ", "))
+	}
+	return fmt.Sprintf("watch %s on %s %s", w.Task, w.Host, mutedStyle.Render("("+strings.Join(details, ", ")+")"))
+}
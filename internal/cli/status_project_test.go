@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/history"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/rileyhilliard/rr/internal/watch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stubStatusLockHolder(t *testing.T, holder string, err error) {
+	t.Helper()
+	orig := statusLockHolder
+	statusLockHolder = func(string, string, config.Host, config.LockConfig) (string, error) {
+		return holder, err
+	}
+	t.Cleanup(func() { statusLockHolder = orig })
+}
+
+func TestCollectProjectStatus(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+	stubStatusLockHolder(t, "alice@laptop (pid 42)", nil)
+
+	require.NoError(t, history.Append(root, history.Entry{
+		Key: history.TaskKey("test"), Host: "mini", Time: time.Now(), ExitCode: 1, Total: 3 * time.Second,
+	}))
+
+	resolved := &config.ResolvedConfig{
+		Global:      &config.GlobalConfig{Hosts: map[string]config.Host{"mini": {SSH: []string{"mini"}}}},
+		Project:     config.DefaultConfig(),
+		ProjectRoot: root,
+	}
+	status := collectProjectStatus(resolved, &Selected{Host: "mini", Alias: "mini"})
+
+	assert.Equal(t, root, status.Root)
+	assert.Equal(t, "mini", status.Host)
+	assert.Nil(t, status.LastSync, "never synced")
+	assert.Nil(t, status.ChangedFiles)
+	require.NotNil(t, status.Lock)
+	assert.True(t, status.Lock.Locked)
+	assert.Equal(t, "alice@laptop (pid 42)", status.Lock.Holder)
+	require.NotNil(t, status.LastRun)
+	assert.Equal(t, "task test", status.LastRun.Name)
+	assert.Equal(t, 1, status.LastRun.ExitCode)
+	assert.Equal(t, "3.0s", status.LastRun.Duration)
+	assert.Nil(t, status.SyncDaemon)
+	assert.Empty(t, status.Watchers)
+}
+
+func TestCollectProjectStatus_Watchers(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+	stubStatusLockHolder(t, "", nil)
+
+	// This process stands in for the rr running the watch task
+	state := watch.NewState("dev", "mini", "npm run dev", root, []string{"3000"})
+	state.Running = true
+	state.Restarts = 2
+	require.NoError(t, watch.WriteState(state))
+	other := watch.NewState("dev", "mini", "npm run dev", t.TempDir(), nil)
+	require.NoError(t, watch.WriteState(other))
+
+	resolved := &config.ResolvedConfig{
+		Global:      &config.GlobalConfig{},
+		Project:     config.DefaultConfig(),
+		ProjectRoot: root,
+	}
+	status := collectProjectStatus(resolved, nil)
+
+	require.Len(t, status.Watchers, 1, "only this project's watchers")
+	w := status.Watchers[0]
+	assert.Equal(t, "dev", w.Task)
+	assert.Equal(t, "mini", w.Host)
+	assert.True(t, w.Running)
+	assert.Equal(t, 2, w.Restarts)
+	assert.Equal(t, []string{"3000"}, w.Ports)
+}
+
+func TestCollectProjectStatus_NoSelectedHost(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	stubStatusLockHolder(t, "", errors.New("should not be called"))
+
+	resolved := &config.ResolvedConfig{
+		Global:      &config.GlobalConfig{},
+		Project:     config.DefaultConfig(),
+		ProjectRoot: t.TempDir(),
+	}
+	status := collectProjectStatus(resolved, nil)
+	assert.Empty(t, status.Host)
+	assert.Nil(t, status.Lock)
+	assert.Nil(t, status.LastRun)
+}
+
+func TestRenderProjectStatus(t *testing.T) {
+	now := time.Now()
+	synced := now.Add(-5 * time.Minute)
+	changed := 3
+	out := renderProjectStatus(&ProjectStatus{
+		Root:         "/work/app",
+		Host:         "mini",
+		LastSync:     &synced,
+		ChangedFiles: &changed,
+		Lock:         &LockState{Locked: true, Holder: "bob@desk"},
+		LastRun:      &LastRun{Name: "make test", Host: "mini", Time: now.Add(-time.Hour), ExitCode: 0, Duration: "12.0s"},
+		SyncDaemon:   &Daemon{PID: 99, Host: "mini", Current: true},
+		Watchers: []Watcher{
+			{Task: "dev", Host: "mini", Running: true, Since: now.Add(-10 * time.Minute), Restarts: 1, Ports: []string{"3000", "9230:9229"}},
+			{Task: "worker", Host: "gpu", Since: now},
+		},
+	}, now)
+
+	assert.Contains(t, out, "Project: /work/app")
+	assert.Contains(t, out, "5 minutes ago to mini")
+	assert.Contains(t, out, "3 files changed since")
+	assert.Contains(t, out, "held by bob@desk")
+	assert.Contains(t, out, "make test")
+	assert.Contains(t, out, "passed")
+	assert.Contains(t, out, "sync daemon to mini")
+	assert.Contains(t, out, "pid 99, up to date")
+	assert.Contains(t, out, "watch dev on mini")
+	assert.Contains(t, out, "started 10 minutes ago, 1 restart, ports 3000, 9230:9229")
+	assert.Contains(t, out, "watch worker on gpu")
+	assert.Contains(t, out, "restarting")
+}
+
+func TestRenderProjectStatus_Empty(t *testing.T) {
+	out := renderProjectStatus(&ProjectStatus{Root: "/work/app", Host: "mini", SyncInterrupted: true,
+		Lock: &LockState{}}, time.Now())
+
+	assert.Contains(t, out, "never synced to mini")
+	assert.Contains(t, out, "interrupted")
+	assert.Contains(t, out, "free")
+	assert.Contains(t, out, "none recorded")
+	assert.Contains(t, out, "nothing running")
+}
+
+func TestFindSelectedHost_FollowsOrder(t *testing.T) {
+	results := map[string]probeResult{
+		"a-box":   {HostName: "a-box", Aliases: []host.ProbeResult{{SSHAlias: "a", Success: true}}},
+		"z-box":   {HostName: "z-box", Aliases: []host.ProbeResult{{SSHAlias: "z", Success: true}}},
+		"offline": {HostName: "offline", Aliases: []host.ProbeResult{{SSHAlias: "o", Success: false}}},
+	}
+
+	assert.Equal(t, &Selected{Host: "z-box", Alias: "z"}, findSelectedHost(results, []string{"offline", "z-box", "a-box"}))
+	// Without an order, the choice is by name so it's stable between runs
+	assert.Equal(t, &Selected{Host: "a-box", Alias: "a"}, findSelectedHost(results, nil))
+}
+
+func TestHistoryKeyName(t *testing.T) {
+	assert.Equal(t, "task build", historyKeyName("task:build"))
+	assert.Equal(t, "make test", historyKeyName("run:make test"))
+	assert.Equal(t, "other", historyKeyName("other"))
+}
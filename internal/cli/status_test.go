@@ -120,7 +120,7 @@ func TestFindSelectedHost(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := findSelectedHost(tt.results)
+			got := findSelectedHost(tt.results, nil)
 			if tt.expectsHost {
 				assert.NotNil(t, got)
 			} else {
@@ -238,7 +238,7 @@ func TestOutputStatusJSON(t *testing.T) {
 			os.Stdout = w
 
 			// Run the function
-			outputErr := outputStatusJSON(tt.results, tt.selected)
+			outputErr := outputStatusJSON(tt.results, tt.selected, nil)
 			require.NoError(t, outputErr)
 
 			// Restore stdout and read captured output
@@ -331,7 +331,7 @@ func TestOutputStatusText(t *testing.T) {
 			os.Stdout = w
 
 			// Run the function
-			outputErr := outputStatusText(tt.results, tt.selected)
+			outputErr := outputStatusText(tt.results, tt.selected, nil)
 			require.NoError(t, outputErr)
 
 			// Restore stdout and read captured output
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/exec"
+	"golang.org/x/term"
+)
+
+// sudoInteractive reports whether a sudo password can be asked for.
+// Swappable for tests.
+var sudoInteractive = func() bool {
+	return canPrompt()
+}
+
+// promptSudoPassword asks for the sudo password on a host, without echoing
+// what's typed. Swappable for tests.
+var promptSudoPassword = func(hostName string, retry bool) (string, error) {
+	if retry {
+		fmt.Fprintln(os.Stderr, "Sorry, try again.")
+	}
+	fmt.Fprintf(os.Stderr, "[sudo] password on %s: ", hostName)
+	password, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	return string(password), err
+}
+
+// sudoPasswords holds the sudo passwords of tasks with sudo_cache, by host,
+// until rr exits. The lock also keeps tasks running side by side from
+// asking at once.
+var sudoPasswords = struct {
+	sync.Mutex
+	byHost map[string]string
+}{byHost: make(map[string]string)}
+
+// sudoPassword returns how a task on hostName gets its sudo password: asked
+// for at each prompt, or with sudo_cache, once per host.
+func sudoPassword(hostName string, task *config.TaskConfig) exec.SudoPassword {
+	if task == nil || !config.UsesSudo(*task) {
+		return nil
+	}
+	return func(retry bool) (string, error) {
+		sudoPasswords.Lock()
+		defer sudoPasswords.Unlock()
+
+		if task.SudoCache && !retry {
+			if password, ok := sudoPasswords.byHost[hostName]; ok {
+				return password, nil
+			}
+		}
+		if !sudoInteractive() {
+			why := "there's no terminal to type it on"
+			if NonInteractive() {
+				why = "prompts are off (--non-interactive)"
+			}
+			return "", errors.New(errors.ErrExec,
+				fmt.Sprintf("sudo on %s asked for a password, and %s", hostName, why),
+				"Give the host passwordless sudo for this command, or run the task from a terminal without --non-interactive.")
+		}
+		password, err := promptSudoPassword(hostName, retry)
+		if err != nil {
+			return "", errors.WrapWithCode(err, errors.ErrExec,
+				"Didn't get the sudo password",
+				"Run the task again and type the password when asked.")
+		}
+		if task.SudoCache {
+			sudoPasswords.byHost[hostName] = password
+		}
+		return password, nil
+	}
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubSudoPrompt makes sudo passwords come from prompt instead of a
+// terminal, or see no terminal at all when prompt is nil. The password
+// cache starts empty.
+func stubSudoPrompt(t *testing.T, prompt func(hostName string, retry bool) (string, error)) {
+	t.Helper()
+	origInteractive, origPrompt := sudoInteractive, promptSudoPassword
+	origCache := sudoPasswords.byHost
+	t.Cleanup(func() {
+		sudoInteractive, promptSudoPassword = origInteractive, origPrompt
+		sudoPasswords.byHost = origCache
+	})
+	sudoInteractive = func() bool { return prompt != nil }
+	promptSudoPassword = prompt
+	sudoPasswords.byHost = make(map[string]string)
+}
+
+func TestSudoPassword_NoSudo(t *testing.T) {
+	assert.Nil(t, sudoPassword("box", &config.TaskConfig{Run: "make"}))
+	assert.Nil(t, sudoPassword("box", nil))
+}
+
+func TestSudoPassword_AsksEachTime(t *testing.T) {
+	asked := 0
+	stubSudoPrompt(t, func(string, bool) (string, error) {
+		asked++
+		return "hunter2", nil
+	})
+	password := sudoPassword("box", &config.TaskConfig{Run: "sudo reboot", Sudo: true})
+
+	for range 2 {
+		pw, err := password(false)
+		require.NoError(t, err)
+		assert.Equal(t, "hunter2", pw)
+	}
+	assert.Equal(t, 2, asked)
+	assert.Empty(t, sudoPasswords.byHost, "nothing is kept without sudo_cache")
+}
+
+func TestSudoPassword_Cache(t *testing.T) {
+	var asked []string
+	stubSudoPrompt(t, func(hostName string, retry bool) (string, error) {
+		asked = append(asked, hostName)
+		if retry {
+			return "right", nil
+		}
+		return "wrong", nil
+	})
+	task := &config.TaskConfig{Run: "sudo reboot", Sudo: true, SudoCache: true}
+
+	pw, err := sudoPassword("box", task)(false)
+	require.NoError(t, err)
+	assert.Equal(t, "wrong", pw)
+
+	pw, err = sudoPassword("box", task)(false)
+	require.NoError(t, err)
+	assert.Equal(t, "wrong", pw, "a later task on the same host reuses it")
+	assert.Equal(t, []string{"box"}, asked)
+
+	pw, err = sudoPassword("box", task)(true)
+	require.NoError(t, err)
+	assert.Equal(t, "right", pw, "a wrong password is asked for again")
+
+	pw, err = sudoPassword("box", task)(false)
+	require.NoError(t, err)
+	assert.Equal(t, "right", pw)
+
+	_, err = sudoPassword("other", task)(false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"box", "box", "other"}, asked, "each host has its own password")
+}
+
+func TestSudoPassword_NoTerminal(t *testing.T) {
+	stubSudoPrompt(t, nil)
+
+	_, err := sudoPassword("box", &config.TaskConfig{Run: "sudo reboot", Sudo: true})(false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no terminal to type it on")
+}
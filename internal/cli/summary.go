@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/history"
+	"github.com/rileyhilliard/rr/internal/stats"
+	"github.com/rileyhilliard/rr/internal/ui"
+	"golang.org/x/term"
+)
+
+// summaryPhases is the order phases appear in the run summary footer.
+var summaryPhases = []string{"build", "connect", "lock", "reserve", "sync", "inputs", "exec", "pull", "stage"}
+
+// comparedPhases are checked against run history for regressions. Connect
+// and lock mostly measure the network and other users, not the project.
+var comparedPhases = []string{"sync", "exec"}
+
+const (
+	// summaryChangeThreshold is how far off the median (as a fraction) a
+	// phase has to be before the summary calls it out.
+	summaryChangeThreshold = 0.2
+
+	// summaryMinDelta keeps tiny phases from being flagged over noise
+	// (a 100ms sync taking 150ms is +50% but nothing to worry about).
+	summaryMinDelta = 250 * time.Millisecond
+)
+
+// finishRun records a completed run in history and, when show is true,
+// prints a one-line phase breakdown comparing sync and exec against the
+// median of recent runs. The captured output (if any) replaces the saved
+// last-run output and is saved as a recording for 'rr replay', and the
+// run's results are saved for 'rr results'. The hook plugins get the run's
+// result. History errors are ignored; they never fail a run.
+func finishRun(wf *WorkflowContext, key string, exitCode int, show bool) {
+	if wf.Conn == nil {
+		return
+	}
+	total := time.Since(wf.StartTime)
+	hostName := wf.Conn.Name
+
+	runHooks(PhaseEvent{
+		Type:     "result",
+		Status:   map[bool]string{true: "success", false: "failed"}[exitCode == 0],
+		Host:     hostName,
+		Duration: total.Seconds(),
+		ExitCode: &exitCode,
+		Details:  map[string]interface{}{"run": key},
+	})
+
+	if show {
+		entries, _ := history.Load(wf.WorkDir)
+		comparisons := make(map[string]history.Comparison)
+		for _, phase := range comparedPhases {
+			d, ok := wf.Phases[phase]
+			if !ok {
+				continue
+			}
+			if c, ok := history.Compare(entries, key, hostName, phase, d); ok && c.Significant(d, summaryChangeThreshold, summaryMinDelta) {
+				comparisons[phase] = c
+			}
+		}
+		fmt.Println(formatRunSummary(wf.Phases, total, comparisons))
+	}
+
+	phases := make(map[string]time.Duration, len(wf.Phases))
+	for k, v := range wf.Phases {
+		phases[k] = v
+	}
+	saveRunResults(wf, key, exitCode)
+	_ = stats.RecordRun(phases, total, exitCode)
+	_ = history.Append(wf.WorkDir, history.Entry{
+		Key:      key,
+		Host:     hostName,
+		Time:     time.Now(),
+		ExitCode: exitCode,
+		Phases:   phases,
+		Total:    total,
+		Steps:    wf.Steps,
+	})
+	if wf.Output != nil {
+		_ = history.SaveOutput(wf.WorkDir, wf.Output.Bytes())
+	}
+	if wf.Cast != nil {
+		width, height := castSize()
+		_, _ = history.SaveCast(wf.WorkDir, history.CastHeader{
+			Width:     width,
+			Height:    height,
+			Timestamp: wf.Cast.Start().Unix(),
+			Duration:  time.Since(wf.Cast.Start()).Round(time.Millisecond).Seconds(),
+			Title:     castTitle(key),
+			Host:      hostName,
+			ExitCode:  &exitCode,
+		}, wf.Cast.Events())
+	}
+}
+
+// castTitle turns a history key into a recording title: the command for
+// 'rr run', or "task <name>".
+func castTitle(key string) string {
+	if cmd, ok := strings.CutPrefix(key, "run:"); ok {
+		return cmd
+	}
+	if name, ok := strings.CutPrefix(key, "task:"); ok {
+		return "task " + name
+	}
+	return key
+}
+
+// castSize returns the terminal size to record, or 80x24 when stdout isn't
+// a terminal.
+func castSize() (int, int) {
+	if width, height, err := term.GetSize(int(os.Stdout.Fd())); err == nil && width > 0 && height > 0 {
+		return width, height
+	}
+	return 80, 24
+}
+
+// formatRunSummary renders the footer, e.g.
+// "connect 0.2s · sync 1.1s [+40% vs median] · exec 34.0s · total 35.5s".
+// Slowdowns are highlighted as warnings; speedups in the success color.
+func formatRunSummary(phases map[string]time.Duration, total time.Duration, comparisons map[string]history.Comparison) string {
+	mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
+	slowerStyle := lipgloss.NewStyle().Foreground(ui.ColorWarning)
+	fasterStyle := lipgloss.NewStyle().Foreground(ui.ColorSuccess)
+
+	var parts []string
+	for _, phase := range summaryPhases {
+		d, ok := phases[phase]
+		if !ok {
+			continue
+		}
+		part := mutedStyle.Render(fmt.Sprintf("%s %s", phase, ui.FormatElapsed(d)))
+		if c, ok := comparisons[phase]; ok {
+			note := fmt.Sprintf("[%+.0f%% vs median]", c.Change*100)
+			if c.Change > 0 {
+				part += " " + slowerStyle.Render(note)
+			} else {
+				part += " " + fasterStyle.Render(note)
+			}
+		}
+		parts = append(parts, part)
+	}
+	parts = append(parts, mutedStyle.Render("total "+ui.FormatElapsed(total)))
+
+	return strings.Join(parts, mutedStyle.Render(" · "))
+}
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/history"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatRunSummary(t *testing.T) {
+	phases := map[string]time.Duration{
+		"exec":    34 * time.Second,
+		"connect": 200 * time.Millisecond,
+		"sync":    1100 * time.Millisecond,
+	}
+	comparisons := map[string]history.Comparison{
+		"sync": {Median: 785 * time.Millisecond, Samples: 5, Change: 0.4},
+	}
+
+	got := formatRunSummary(phases, 35500*time.Millisecond, comparisons)
+	assert.Equal(t, "connect 0.2s · sync 1.1s [+40% vs median] · exec 34.0s · total 35.5s", got)
+}
+
+func TestFormatRunSummary_SkipsMissingPhases(t *testing.T) {
+	got := formatRunSummary(map[string]time.Duration{"exec": 2 * time.Second}, 2*time.Second, nil)
+	assert.Equal(t, "exec 2.0s · total 2.0s", got)
+}
+
+func TestFormatRunSummary_Faster(t *testing.T) {
+	comparisons := map[string]history.Comparison{
+		"exec": {Median: 10 * time.Second, Samples: 3, Change: -0.3},
+	}
+	got := formatRunSummary(map[string]time.Duration{"exec": 7 * time.Second}, 7*time.Second, comparisons)
+	assert.Contains(t, got, "exec 7.0s [-30% vs median]")
+}
+
+func TestFinishRun_RecordsHistory(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	project := t.TempDir()
+
+	wf := &WorkflowContext{
+		Conn:      &host.Connection{Name: "mini"},
+		WorkDir:   project,
+		StartTime: time.Now().Add(-3 * time.Second),
+	}
+	wf.recordPhase("sync", time.Second)
+	wf.recordPhase("exec", 2*time.Second)
+
+	finishRun(wf, history.TaskKey("test"), 1, false)
+
+	entries, err := history.Load(project)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "task:test", entries[0].Key)
+	assert.Equal(t, "mini", entries[0].Host)
+	assert.Equal(t, 1, entries[0].ExitCode)
+	assert.Equal(t, time.Second, entries[0].Phases["sync"])
+	assert.GreaterOrEqual(t, entries[0].Total, 3*time.Second)
+}
+
+func TestFinishRun_SavesOutput(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	project := t.TempDir()
+
+	wf := &WorkflowContext{
+		Conn:      &host.Connection{Name: "mini"},
+		WorkDir:   project,
+		StartTime: time.Now(),
+		Output:    history.NewOutputTail(),
+	}
+	_, _ = wf.Output.Write([]byte("FAIL: TestThing\n"))
+
+	finishRun(wf, history.TaskKey("test"), 1, false)
+
+	output, err := history.LoadOutput(project)
+	require.NoError(t, err)
+	assert.Equal(t, "FAIL: TestThing\n", string(output))
+}
+
+func TestFinishRun_SavesCast(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	project := t.TempDir()
+
+	wf := &WorkflowContext{
+		Conn:      &host.Connection{Name: "mini"},
+		WorkDir:   project,
+		StartTime: time.Now(),
+	}
+	stdout, _ := wf.captureOutput(io.Discard, io.Discard)
+	_, _ = stdout.Write([]byte("FAIL: TestThing\n"))
+
+	finishRun(wf, history.RunKey("go test ./..."), 1, false)
+
+	cast, err := history.LoadCast(project, "")
+	require.NoError(t, err)
+	assert.Equal(t, "go test ./...", cast.Header.Title)
+	assert.Equal(t, "mini", cast.Header.Host)
+	require.NotNil(t, cast.Header.ExitCode)
+	assert.Equal(t, 1, *cast.Header.ExitCode)
+	require.Len(t, cast.Events, 1)
+	assert.Equal(t, "FAIL: TestThing\n", cast.Events[0].Data)
+}
+
+func TestCastTitle(t *testing.T) {
+	assert.Equal(t, "make test", castTitle(history.RunKey("make test")))
+	assert.Equal(t, "task lint", castTitle(history.TaskKey("lint")))
+}
@@ -1,9 +1,15 @@
 package cli
 
 import (
+	"context"
+	stderrors "errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"slices"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/rileyhilliard/rr/internal/config"
@@ -22,6 +28,11 @@ type SyncOptions struct {
 	DryRun       bool          // If true, show what would be synced without syncing
 	SkipLock     bool          // If true, skip locking
 	WorkingDir   string        // Override local working directory
+	Daemon       bool          // If true, keep running and push changes as files are saved
+	Force        bool          // If true, sync even into a git checkout with uncommitted changes
+	Rebootstrap  bool          // If true, run the project's bootstrap script even if it already ran on the host
+	Profile      string        // Sync profile from sync.profiles (empty for the sync section's own settings)
+	Paths        []string      // Only sync these files or directories (empty for the whole project)
 }
 
 // Sync transfers files to the remote host without executing any command.
@@ -30,7 +41,7 @@ func Sync(opts SyncOptions) error {
 	phaseDisplay := ui.NewPhaseDisplay(os.Stdout)
 
 	// Load resolved config (global + project)
-	resolved, err := config.LoadResolved(Config())
+	resolved, err := loadResolved(Config())
 	if err != nil {
 		return err
 	}
@@ -39,6 +50,15 @@ func Sync(opts SyncOptions) error {
 		return err
 	}
 
+	// Use project sync config if available, otherwise use defaults
+	syncCfg := config.DefaultConfig().Sync
+	if resolved.Project != nil {
+		syncCfg = resolved.Project.Sync
+	}
+	if syncCfg, err = syncCfg.WithProfile(opts.Profile); err != nil {
+		return errors.WrapWithCode(err, errors.ErrConfig, err.Error(), "Check sync.profiles in your .rr.yaml.")
+	}
+
 	// Determine working directory
 	workDir := opts.WorkingDir
 	if workDir == "" {
@@ -50,6 +70,23 @@ func Sync(opts SyncOptions) error {
 		}
 	}
 
+	// Paths are relative to where rr was run, so resolve them before
+	// syncing from the project root
+	var paths []string
+	if len(opts.Paths) > 0 {
+		root := workDir
+		if opts.WorkingDir == "" && resolved.ProjectRoot != "" {
+			root = resolved.ProjectRoot
+		}
+		if paths, err = resolveSyncPaths(root, workDir, opts.Paths); err != nil {
+			return err
+		}
+		workDir = root
+		if len(paths) > 0 {
+			syncCfg = sync.PathsConfig(syncCfg, paths)
+		}
+	}
+
 	// Create host selector with proper priority order
 	hostOrder, projectHosts, err := config.ResolveHosts(resolved, opts.Host)
 	if err != nil {
@@ -93,6 +130,12 @@ func Sync(opts SyncOptions) error {
 	}
 	spinner.Success()
 	phaseDisplay.RenderSuccess("Connected to "+conn.Alias, time.Since(connectStart))
+	mirrorCfg := syncCfg // The mirror measures its own clock skew
+	syncCfg = sync.ChecksumForSkew(syncCfg, checkClockSkew(conn))
+
+	if opts.Daemon {
+		return syncDaemon(conn, resolved, workDir, phaseDisplay, opts.Force)
+	}
 
 	// Phase 2: Acquire lock (skip for dry-run and local connections)
 	lockCfg := config.DefaultConfig().Lock
@@ -116,16 +159,25 @@ func Sync(opts SyncOptions) error {
 		phaseDisplay.RenderSuccess("Lock acquired", time.Since(lockStart))
 	}
 
+	// Don't overwrite edits made directly in a git checkout on the host
+	if !opts.DryRun && !opts.Force {
+		if err := sync.CheckRemoteEdits(conn, workDir, syncCfg); err != nil {
+			return err
+		}
+	}
+
 	// Phase 3: Sync
 	syncStart := time.Now()
-	spinner = ui.NewSpinner("Syncing files")
+	label := "Syncing files"
+	if len(paths) > 0 {
+		label = "Syncing " + strings.Join(paths, ", ")
+	}
+	if !opts.DryRun && sync.SyncInterrupted(workDir, conn.Name) {
+		label = "Resuming interrupted sync"
+	}
+	spinner = ui.NewSpinner(label)
 	spinner.Start()
 
-	// Use project sync config if available, otherwise use defaults
-	syncCfg := config.DefaultConfig().Sync
-	if resolved.Project != nil {
-		syncCfg = resolved.Project.Sync
-	}
 	// Add dry-run flag if requested (copy first to avoid mutating shared config slice)
 	if opts.DryRun {
 		syncCfg.Flags = append(slices.Clone(syncCfg.Flags), "--dry-run", "-v")
@@ -140,6 +192,35 @@ func Sync(opts SyncOptions) error {
 	syncDuration := time.Since(syncStart)
 	spinner.Success()
 
+	// Copy the sync to the project's mirror_to host while the bootstrap runs
+	var mirror *mirrorRun
+	if !opts.DryRun && !conn.IsLocal {
+		mirror = startMirror(resolved, conn.Name, workDir, mirrorCfg, opts.Force)
+	}
+
+	// Phase 4: Bootstrap the project if the host hasn't seen it yet
+	if !opts.DryRun && !conn.IsLocal && resolved.Project != nil {
+		if _, err := bootstrapHost(conn, resolved.Project, opts.Rebootstrap, false, phaseDisplay, NewPhaseReporter(phaseDisplay)); err != nil {
+			return err
+		}
+	}
+
+	// Phase 5: Wait for the mirror
+	if mirror != nil {
+		mirrorSpinner := ui.NewSpinner("Mirroring to " + mirror.Host)
+		mirrorSpinner.Start()
+		mirror.Wait()
+		switch {
+		case mirror.err != nil:
+			mirrorSpinner.Fail()
+			ui.PrintWarning(fmt.Sprintf("Couldn't mirror to %s: %v", mirror.Host, mirror.err))
+		case mirror.skipped != "":
+			mirrorSpinner.Skip()
+		default:
+			mirrorSpinner.Success()
+		}
+	}
+
 	totalDuration := time.Since(startTime)
 
 	// Show summary
@@ -155,17 +236,175 @@ func Sync(opts SyncOptions) error {
 	return nil
 }
 
+// resolveSyncPaths turns paths given on the command line, relative to cwd,
+// into paths relative to the project root. Each one has to exist and be
+// inside the project. Returns nil if one of them is the root itself, since
+// that's the whole project.
+func resolveSyncPaths(root, cwd string, args []string) ([]string, error) {
+	var paths []string
+	seen := map[string]bool{}
+	for _, arg := range args {
+		abs := arg
+		if !filepath.IsAbs(abs) {
+			abs = filepath.Join(cwd, arg)
+		}
+		rel, err := filepath.Rel(root, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, errors.New(errors.ErrSync,
+				fmt.Sprintf("'%s' is outside the project", arg),
+				fmt.Sprintf("rr sync only syncs paths inside %s.", root))
+		}
+		if _, err := os.Stat(abs); err != nil {
+			return nil, errors.New(errors.ErrSync,
+				fmt.Sprintf("Nothing to sync at '%s'", arg),
+				"Check the path. It's relative to the current directory.")
+		}
+		if rel == "." {
+			return nil, nil
+		}
+		rel = filepath.ToSlash(rel)
+		if !seen[rel] {
+			seen[rel] = true
+			paths = append(paths, rel)
+		}
+	}
+	return paths, nil
+}
+
+// syncDaemon keeps the remote in step with local edits until interrupted.
+// Each push takes the host lock non-blockingly, so a running command never has
+// files swapped out underneath it; changes made meanwhile are retried once the
+// lock frees up.
+func syncDaemon(conn *host.Connection, resolved *config.ResolvedConfig, workDir string, pd *ui.PhaseDisplay, force bool) error {
+	if conn.IsLocal {
+		return errors.New(errors.ErrSync,
+			"Nothing to sync - the selected host is local",
+			"Pick a remote host with --host or --tag.")
+	}
+
+	opts := daemonOptions(conn, resolved, workDir, NewPhaseReporter(pd))
+	if !force {
+		if err := sync.CheckRemoteEdits(conn, workDir, opts.Config); err != nil {
+			return err
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if PrettyMode() {
+		fmt.Printf("%s Watching for changes (Ctrl+C to stop)\n", ui.SymbolPending)
+	}
+
+	return sync.RunDaemon(ctx, opts)
+}
+
+// daemonOptions returns the options for a sync daemon pushing workDir to
+// conn, reporting each push to reporter. 'rr sync --daemon' and watch tasks
+// both use it.
+func daemonOptions(conn *host.Connection, resolved *config.ResolvedConfig, workDir string, reporter PhaseReporter) sync.DaemonOptions {
+	syncCfg := config.DefaultConfig().Sync
+	lockCfg := config.DefaultConfig().Lock
+	if resolved.Project != nil {
+		syncCfg = resolved.Project.Sync
+		lockCfg = resolved.Project.Lock
+	}
+
+	var invalidationNotify sync.InvalidationNotifyFunc
+	if !PrettyMode() {
+		invalidationNotify = func(dir, lockfile string) {
+			WritePhaseEvent(PhaseEvent{
+				Type:   "phase",
+				Phase:  "sync",
+				Status: "invalidated",
+				Details: map[string]interface{}{
+					"dir":      dir,
+					"lockfile": lockfile,
+				},
+			})
+		}
+	}
+
+	syncFunc := func() error {
+		if lockCfg.Enabled {
+			lck, err := lock.TryAcquire(conn, lockCfg, "sync --daemon")
+			if err != nil {
+				return err
+			}
+			defer lck.Release() //nolint:errcheck // Lock release errors are non-fatal
+		}
+		if err := sync.InvalidateStaleDirectories(conn, workDir, syncCfg.Invalidations, invalidationNotify); err != nil {
+			return err
+		}
+		return sync.Sync(conn, workDir, syncCfg, nil)
+	}
+
+	onSync := func(d time.Duration, err error) {
+		if err == nil {
+			reporter.PhaseComplete("sync", conn.Name, d)
+			return
+		}
+		if stderrors.Is(err, lock.ErrLocked) {
+			reporter.PhaseSkipped("sync", "host busy, will retry")
+			return
+		}
+		reporter.PhaseFailed("sync", err)
+	}
+
+	return sync.DaemonOptions{
+		LocalDir:  workDir,
+		Host:      conn.Name,
+		RemoteDir: config.ExpandRemote(conn.Host.Dir),
+		Config:    syncCfg,
+		SyncFunc:  syncFunc,
+		OnSync:    onSync,
+	}
+}
+
+// syncFlags are the flags of rr sync, as given on the command line.
+type syncFlags struct {
+	Host         string
+	Tag          string
+	ProbeTimeout string
+	DryRun       bool
+	Daemon       bool
+	Force        bool
+	Rebootstrap  bool
+	Profile      string
+}
+
 // syncCommand is the implementation called by the cobra command.
-func syncCommand(hostFlag, tagFlag, probeTimeoutFlag string, dryRun bool) error {
-	probeTimeout, err := ParseProbeTimeout(probeTimeoutFlag)
+func syncCommand(paths []string, flags syncFlags) error {
+	if flags.DryRun && flags.Daemon {
+		return errors.New(errors.ErrConfig,
+			"Can't combine --dry-run with --daemon",
+			"Use --dry-run to preview a single sync, or --daemon to keep syncing.")
+	}
+	if len(paths) > 0 && flags.Daemon {
+		return errors.New(errors.ErrConfig,
+			"Can't combine paths with --daemon",
+			"The daemon keeps hosts current for every task, so it syncs the whole project.")
+	}
+	if flags.Profile != "" && flags.Daemon {
+		return errors.New(errors.ErrConfig,
+			"Can't combine --profile with --daemon",
+			"The daemon keeps hosts current for every task, so it syncs with the sync section's own settings.")
+	}
+
+	probeTimeout, err := ParseProbeTimeout(flags.ProbeTimeout)
 	if err != nil {
 		return err
 	}
 
 	return Sync(SyncOptions{
-		Host:         hostFlag,
-		Tag:          tagFlag,
+		Host:         flags.Host,
+		Tag:          flags.Tag,
 		ProbeTimeout: probeTimeout,
-		DryRun:       dryRun,
+		DryRun:       flags.DryRun,
+		Daemon:       flags.Daemon,
+		Force:        flags.Force,
+		Rebootstrap:  flags.Rebootstrap,
+		Profile:      flags.Profile,
+		Paths:        paths,
 	})
 }
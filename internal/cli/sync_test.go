@@ -43,7 +43,7 @@ func TestSyncOptions_WithValues(t *testing.T) {
 }
 
 func TestSyncCommand_InvalidProbeTimeout(t *testing.T) {
-	err := syncCommand("", "", "invalid-duration", false)
+	err := syncCommand(nil, syncFlags{ProbeTimeout: "invalid-duration"})
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "doesn't look like a valid timeout")
@@ -64,7 +64,7 @@ func TestSyncCommand_ValidProbeTimeoutFormats(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := syncCommand("", "", tt.timeout, false)
+			err := syncCommand(nil, syncFlags{ProbeTimeout: tt.timeout})
 			// Should fail with config error, not parse error
 			if err != nil {
 				assert.NotContains(t, err.Error(), "Invalid probe timeout",
@@ -206,7 +206,7 @@ func TestSyncCommand_PassesDryRunFlag(t *testing.T) {
 	require.NoError(t, err)
 
 	// Test that dry-run flag is passed through syncCommand
-	err = syncCommand("myhost", "gpu", "5s", true)
+	err = syncCommand(nil, syncFlags{Host: "myhost", Tag: "gpu", ProbeTimeout: "5s", DryRun: true})
 	require.Error(t, err)
 	// Should fail on no hosts configured, but all flags were parsed
 	assert.Contains(t, err.Error(), "No hosts configured")
@@ -276,7 +276,7 @@ func TestSyncCommand_AllFlagsEmpty(t *testing.T) {
 	require.NoError(t, err)
 
 	// Test with all flags empty - should use defaults
-	err = syncCommand("", "", "", false)
+	err = syncCommand(nil, syncFlags{})
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "No hosts configured")
 }
@@ -405,7 +405,7 @@ func TestSyncCommand_EmptyFlags(t *testing.T) {
 	require.NoError(t, err)
 
 	// All empty flags should use defaults
-	err = syncCommand("", "", "", false)
+	err = syncCommand(nil, syncFlags{})
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "No hosts configured")
 }
@@ -421,7 +421,7 @@ func TestSyncCommand_WithAllFlags(t *testing.T) {
 	err := os.Chdir(tmpDir)
 	require.NoError(t, err)
 
-	err = syncCommand("myhost", "gpu", "10s", true)
+	err = syncCommand(nil, syncFlags{Host: "myhost", Tag: "gpu", ProbeTimeout: "10s", DryRun: true})
 	require.Error(t, err)
 	// Should fail on no hosts configured
 	assert.Contains(t, err.Error(), "No hosts configured")
@@ -473,7 +473,7 @@ func TestSyncCommand_InvalidProbeTimeoutFormats(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := syncCommand("", "", tt.timeout, false)
+			err := syncCommand(nil, syncFlags{ProbeTimeout: tt.timeout})
 			require.Error(t, err)
 			assert.Contains(t, err.Error(), tt.wantErr)
 		})
@@ -609,3 +609,90 @@ func TestSync_AcquiresLockBeforeSync(t *testing.T) {
 	assert.Contains(t, content, "lck.Release()",
 		"Sync must release the lock after syncing (see issue #181)")
 }
+
+func TestSyncCommand_DaemonRejectsDryRun(t *testing.T) {
+	err := syncCommand(nil, syncFlags{DryRun: true, Daemon: true})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Can't combine --dry-run with --daemon")
+}
+
+func TestSyncCommand_DaemonRejectsProfile(t *testing.T) {
+	err := syncCommand(nil, syncFlags{Daemon: true, Profile: "data"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Can't combine --profile with --daemon")
+}
+
+func TestSyncCommand_DaemonRejectsPaths(t *testing.T) {
+	err := syncCommand([]string{"src/"}, syncFlags{Daemon: true})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Can't combine paths with --daemon")
+}
+
+func TestResolveSyncPaths(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "web", "src"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "assets"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "web", "index.html"), nil, 0o644))
+
+	t.Run("relative to the current directory", func(t *testing.T) {
+		paths, err := resolveSyncPaths(root, filepath.Join(root, "web"), []string{"src/", "index.html", "../assets", "./src"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"web/src", "web/index.html", "assets"}, paths)
+	})
+
+	t.Run("absolute", func(t *testing.T) {
+		paths, err := resolveSyncPaths(root, root, []string{filepath.Join(root, "assets")})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"assets"}, paths)
+	})
+
+	t.Run("the root is the whole project", func(t *testing.T) {
+		paths, err := resolveSyncPaths(root, filepath.Join(root, "web"), []string{"src", ".."})
+		require.NoError(t, err)
+		assert.Nil(t, paths)
+	})
+
+	t.Run("outside the project", func(t *testing.T) {
+		_, err := resolveSyncPaths(root, root, []string{"../elsewhere"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "'../elsewhere' is outside the project")
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		_, err := resolveSyncPaths(root, root, []string{"nope/"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Nothing to sync at 'nope/'")
+	})
+}
+
+func TestSync_UnknownProfile(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	require.NoError(t, os.Chdir(tmpDir))
+
+	globalDir := filepath.Join(tmpDir, ".rr")
+	require.NoError(t, os.MkdirAll(globalDir, 0755))
+	globalContent := `
+version: 1
+hosts:
+  mini:
+    ssh: [mini.local]
+    dir: ~/rr/test
+`
+	require.NoError(t, os.WriteFile(filepath.Join(globalDir, "config.yaml"), []byte(globalContent), 0644))
+	t.Setenv("HOME", tmpDir)
+
+	projectContent := `
+version: 1
+sync:
+  profiles:
+    data:
+      exclude: [.git/]
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".rr.yaml"), []byte(projectContent), 0644))
+
+	err := Sync(SyncOptions{Profile: "models"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "sync profile 'models' isn't defined - defined profiles: data")
+}
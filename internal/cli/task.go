@@ -4,10 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -16,7 +20,9 @@ import (
 	"github.com/rileyhilliard/rr/internal/deps"
 	"github.com/rileyhilliard/rr/internal/errors"
 	"github.com/rileyhilliard/rr/internal/exec"
+	"github.com/rileyhilliard/rr/internal/history"
 	"github.com/rileyhilliard/rr/internal/output"
+	"github.com/rileyhilliard/rr/internal/output/formatters"
 	"github.com/rileyhilliard/rr/internal/parallel"
 	"github.com/rileyhilliard/rr/internal/parallel/logs"
 	"github.com/rileyhilliard/rr/internal/ui"
@@ -27,6 +33,9 @@ import (
 // tasksJSON flag for JSON output
 var tasksJSON bool
 
+// tasksTypeFlag is the tasks command's --type
+var tasksTypeFlag string
+
 // TasksOutput represents the JSON output for tasks command.
 type TasksOutput struct {
 	Tasks []TaskInfo `json:"tasks"`
@@ -36,11 +45,36 @@ type TasksOutput struct {
 type TaskInfo struct {
 	Name        string   `json:"name"`
 	Description string   `json:"description,omitempty"`
-	Type        string   `json:"type"` // "single", "multi-step", "parallel"
+	Type        string   `json:"type"` // "single", "multi-step", "parallel", "watch"
 	Command     string   `json:"command,omitempty"`
 	Steps       []string `json:"steps,omitempty"`
 	Subtasks    []string `json:"subtasks,omitempty"`
 	Hosts       []string `json:"hosts,omitempty"`
+	Shards      int      `json:"shards,omitempty"`
+
+	// Depends are the tasks that run first, in order
+	Depends []TaskDependency `json:"depends,omitempty"`
+
+	// Runs summarizes the task's runs in the project's history, if any
+	Runs *TaskRunStats `json:"runs,omitempty"`
+}
+
+// TaskDependency is one entry in a task's depends: a task, or a group of
+// tasks that run at the same time.
+type TaskDependency struct {
+	Task     string   `json:"task,omitempty"`
+	Parallel []string `json:"parallel,omitempty"`
+}
+
+// TaskRunStats summarizes a task's recorded runs.
+type TaskRunStats struct {
+	Count          int       `json:"count"`
+	Failed         int       `json:"failed"`
+	LastTime       time.Time `json:"last_time"`
+	LastHost       string    `json:"last_host"`
+	LastExitCode   int       `json:"last_exit_code"`
+	LastDuration   string    `json:"last_duration"`
+	MedianDuration string    `json:"median_duration,omitempty"` // Of recent successful runs, once there are enough
 }
 
 // TaskOptions holds options for task execution.
@@ -58,11 +92,38 @@ type TaskOptions struct {
 	Local        bool          // If true, force local execution (skip remote hosts)
 	SkipDeps     bool          // If true, skip dependencies and run only this task
 	From         string        // If set, start from this task in the dependency chain
+	NoSummary    bool          // If true, skip the phase breakdown footer
+	Diagnostics  bool          // If true, write failure locations to .rr/diagnostics.json
+	StageOutputs bool          // If true, stage the task's outputs for the tasks that take them as inputs
+	Force        bool          // If true, sync even into a git checkout with uncommitted changes
+	Rebootstrap  bool          // If true, run the project's bootstrap script even if it already ran on the host
+
+	// Params are the values for the task's params (see taskParamValues)
+	Params map[string]string
+
+	// Resume, if set, is an interrupted run of the task; only the steps it
+	// didn't finish run
+	Resume *history.Checkpoint
+
+	inputsRun map[string]bool // Producer tasks already run in this pipeline
 }
 
 // RunTask executes a named task from the configuration.
 // This handles the full workflow: connect, sync, lock, execute.
 func RunTask(opts TaskOptions) (int, error) {
+	// Run the tasks this one takes inputs from first, each on its own hosts
+	if !opts.SkipDeps {
+		if exitCode, err := runTaskInputs(opts); err != nil || exitCode != 0 {
+			return exitCode, err
+		}
+	}
+
+	var staleLock string
+	var stalePID int
+	if opts.Resume != nil {
+		staleLock, stalePID = opts.Resume.LockDir, opts.Resume.PID
+	}
+
 	// Setup common workflow phases (config, connect, sync, lock)
 	wf, err := SetupWorkflow(WorkflowOptions{
 		Host:         opts.Host,
@@ -75,11 +136,16 @@ func RunTask(opts TaskOptions) (int, error) {
 		Local:        opts.Local,
 		Command:      opts.TaskName,
 		TaskName:     opts.TaskName, // For task-specific requirements
+		Force:        opts.Force,
+		Rebootstrap:  opts.Rebootstrap,
+		StaleLock:    staleLock,
+		StalePID:     stalePID,
 	})
 	if err != nil {
 		return 1, err
 	}
 	defer wf.Close()
+	applyTaskParams(wf.Resolved.Project, opts.TaskName, opts.Params)
 
 	// Get the task from loaded config
 	// Get host config from global hosts if connected to a remote host
@@ -93,6 +159,12 @@ func RunTask(opts TaskOptions) (int, error) {
 	if err != nil {
 		return 1, err
 	}
+	if opts.Resume != nil && opts.Resume.Steps != len(task.Steps) {
+		return 1, errors.New(errors.ErrConfig,
+			fmt.Sprintf("Task '%s' changed since the interrupted run", opts.TaskName),
+			fmt.Sprintf("It had %d steps and now has %d, so rr can't tell which are done. Run it from the start with 'rr %s'.",
+				opts.Resume.Steps, len(task.Steps), opts.TaskName))
+	}
 
 	// Verify task is allowed on the connected host
 	if !config.IsTaskHostAllowed(task, wf.Conn.Name) {
@@ -101,6 +173,12 @@ func RunTask(opts TaskOptions) (int, error) {
 			fmt.Sprintf("This task is restricted to: %s", util.JoinOrNone(task.Hosts)))
 	}
 
+	if len(task.Inputs) > 0 {
+		if err := sendTaskInputs(wf, task); err != nil {
+			return 1, err
+		}
+	}
+
 	// Validate args are only used with single-command tasks
 	if len(opts.Args) > 0 && len(task.Steps) > 0 {
 		return 1, errors.New(errors.ErrConfig,
@@ -113,22 +191,34 @@ func RunTask(opts TaskOptions) (int, error) {
 		return runTaskWithDeps(wf, task, opts)
 	}
 
+	// Save how far the run gets, for 'rr resume'
+	startCheckpoint(wf, opts, task)
+
 	// Phase 4: Execute task
 	wf.Reporter.Divider()
 
 	if PrettyMode() {
 		renderTaskHeader(wf.PhaseDisplay, opts.TaskName, task)
+		renderResumePoint(opts.Resume, task)
 		fmt.Println()
 	} else {
 		wf.Reporter.CommandPrompt(task.Run)
 	}
 
 	// Set up output streaming - in structured mode, pass raw output
-	streamHandler := output.NewStreamHandler(os.Stdout, os.Stderr)
+	outWriter, errWriter, flushOutput := commandOutput()
+	stepProgress := newStepProgress(task, opts.Quiet)
+	if stepProgress != nil {
+		outWriter, errWriter = stepProgress.Writer(outWriter), stepProgress.Writer(errWriter)
+	}
+	streamHandler := output.NewStreamHandler(outWriter, errWriter)
 	if PrettyMode() {
-		streamHandler.SetFormatter(output.NewGenericFormatter())
+		streamHandler.SetFormatter(taskFormatter(wf, task))
 	}
 
+	// Keep the tail of the output for 'rr report', and a recording for 'rr replay'
+	stdout, stderr := wf.captureOutput(streamHandler.Stdout(), streamHandler.Stderr())
+
 	execStart := time.Now()
 
 	// Get remote directory for task execution
@@ -143,42 +233,85 @@ func RunTask(opts TaskOptions) (int, error) {
 	// Create exec options with setup commands and step handler for multi-step tasks
 	execOpts := &exec.TaskExecOptions{
 		SetupCommands: setupCommands,
+		Idle:          idleOptions(wf, task),
+		ProjectRoot:   wf.WorkDir,
+		SudoPassword:  sudoPassword(wf.Conn.Name, task),
+	}
+	if opts.Resume != nil {
+		execOpts.Done = opts.Resume.Done
 	}
 
 	// Add step handler for multi-step tasks to show progress
+	var stepHandler *taskStepHandler
 	if len(task.Steps) > 0 {
-		execOpts.StepHandler = &taskStepHandler{
+		stepHandler = &taskStepHandler{
 			phaseDisplay: wf.PhaseDisplay,
 			quiet:        opts.Quiet,
+			wf:           wf,
+			steps:        task.Steps,
+			durations:    make(map[string]time.Duration),
+			progress:     stepProgress,
 		}
+		if stepProgress != nil {
+			entries, _ := history.Load(wf.WorkDir)
+			stepHandler.medians = history.StepMedians(entries, history.TaskKey(opts.TaskName), wf.Conn.Name)
+		}
+		execOpts.StepHandler = stepHandler
 	}
 
 	// Execute the task
-	result, err := exec.ExecuteTask(wf.Context(), wf.Conn, task, opts.Args, mergedEnv, remoteDir, streamHandler.Stdout(), streamHandler.Stderr(), execOpts)
+	result, err := exec.ExecuteTask(wf.Context(), wf.Conn, task, opts.Args, mergedEnv, remoteDir, stdout, stderr, execOpts)
 	execDuration := time.Since(execStart)
+	if stepProgress != nil {
+		stepProgress.Stop()
+	}
+	flushOutput()
+	if stepHandler != nil {
+		wf.Steps = stepHandler.durations
+	}
 
-	// If cancelled by signal, return standard Ctrl+C exit code
+	// If cancelled by signal, clean up and return standard Ctrl+C exit code
 	if wf.Context().Err() != nil {
+		cancelPhase(wf, task, mergedEnv, remoteDir, setupCommands, stdout, stderr)
+		showResumeHint(wf)
 		return 130, nil
 	}
 
 	if err != nil {
 		return 1, err
 	}
+	wf.recordPhase("exec", execDuration)
+	wf.clearCheckpoint()
 
 	// Release lock early if task completed (wf.Close() will also release, but early release is cleaner)
 	if wf.Lock != nil {
 		wf.Lock.Release() //nolint:errcheck // Lock release errors are non-fatal
 	}
+	collectCache(wf)
 
 	// Pull files if task has pull config
 	ExecutePullPhase(wf, task.Pull, "")
 
+	if opts.StageOutputs && result.ExitCode == 0 {
+		if err := stageTaskOutputs(wf, opts.TaskName, task.Outputs); err != nil {
+			return 1, err
+		}
+	}
+
+	if opts.Diagnostics {
+		writeRunDiagnostics(wf, opts.TaskName, task.Run, result.ExitCode)
+	}
+
+	explainResourceLimits(wf, result.ExitCode)
+	collectCrashEvidence(wf, result.ExitCode)
+
 	if PrettyMode() {
 		wf.PhaseDisplay.ThinDivider()
 		renderTaskSummary(wf.PhaseDisplay, result, opts.TaskName, time.Since(wf.StartTime), execDuration, wf.Conn.Alias)
+		finishRun(wf, history.TaskKey(opts.TaskName), result.ExitCode, !opts.NoSummary && !opts.Quiet)
 	} else {
 		wf.Reporter.CommandComplete(result.ExitCode, wf.Conn.Name, time.Since(wf.StartTime), execDuration)
+		finishRun(wf, history.TaskKey(opts.TaskName), result.ExitCode, false)
 	}
 
 	return result.ExitCode, nil
@@ -229,7 +362,11 @@ func runTaskWithDeps(wf *WorkflowContext, task *config.TaskConfig, opts TaskOpti
 
 	// Set up output streaming
 	streamHandler := output.NewStreamHandler(os.Stdout, os.Stderr)
-	streamHandler.SetFormatter(output.NewGenericFormatter())
+	streamHandler.SetFormatter(taskFormatter(wf, task))
+
+	// Keep the tail of the output for 'rr report' and --diagnostics, and a
+	// recording for 'rr replay'
+	stdout, stderr := wf.captureOutput(streamHandler.Stdout(), streamHandler.Stderr())
 
 	execStart := time.Now()
 
@@ -250,38 +387,106 @@ func runTaskWithDeps(wf *WorkflowContext, task *config.TaskConfig, opts TaskOpti
 	executor := deps.NewExecutor(wf.Resolved, wf.Conn, deps.ExecutorOptions{
 		FailFast:      task.FailFast,
 		Quiet:         opts.Quiet,
-		Stdout:        streamHandler.Stdout(),
-		Stderr:        streamHandler.Stderr(),
+		Stdout:        stdout,
+		Stderr:        stderr,
 		SetupCommands: setupCommands,
 		WorkDir:       remoteDir,
 		StageHandler:  &depStageHandler{quiet: opts.Quiet},
+		// Step progress is shown per task by the stage handler; this one
+		// only pulls each step's files
+		StepHandler: &taskStepHandler{quiet: true, wf: wf},
+		Idle: func(t *config.TaskConfig) *exec.IdleOptions {
+			return idleOptions(wf, t)
+		},
+		SudoPassword: func(t *config.TaskConfig) exec.SudoPassword {
+			return sudoPassword(wf.Conn.Name, t)
+		},
 	})
 
 	result, err := executor.Execute(ctx, plan)
 	execDuration := time.Since(execStart)
 
+	if wf.Context().Err() != nil {
+		_, env, _ := config.GetTaskWithMergedEnv(wf.Resolved.Project, opts.TaskName, hostCfg)
+		cancelPhase(wf, task, env, remoteDir, setupCommands, stdout, stderr)
+		return 130, nil
+	}
 	if err != nil {
 		return 1, err
 	}
+	wf.recordPhase("exec", execDuration)
 
 	// Release lock early
 	if wf.Lock != nil {
 		wf.Lock.Release() //nolint:errcheck // Lock release errors are non-fatal
 	}
+	collectCache(wf)
 
 	// Pull files if task has pull config
 	ExecutePullPhase(wf, task.Pull, "")
 
+	if opts.StageOutputs && result.ExitCode() == 0 {
+		if err := stageTaskOutputs(wf, opts.TaskName, task.Outputs); err != nil {
+			return 1, err
+		}
+	}
+
+	if opts.Diagnostics {
+		writeRunDiagnostics(wf, opts.TaskName, task.Run, result.ExitCode())
+	}
+
+	explainResourceLimits(wf, result.ExitCode())
+	collectCrashEvidence(wf, result.ExitCode())
+	wf.depTasks = depResultTasks(wf.Conn.Name, result, wf.Resolved.Project.Tasks)
+
 	if PrettyMode() {
 		wf.PhaseDisplay.ThinDivider()
 		renderDependencySummary(result, opts.TaskName, time.Since(wf.StartTime), execDuration, wf.Conn.Alias)
+		finishRun(wf, history.TaskKey(opts.TaskName), result.ExitCode(), !opts.NoSummary && !opts.Quiet)
 	} else {
 		wf.Reporter.CommandComplete(result.ExitCode(), wf.Conn.Name, time.Since(wf.StartTime), execDuration)
+		finishRun(wf, history.TaskKey(opts.TaskName), result.ExitCode(), false)
 	}
 
 	return result.ExitCode(), nil
 }
 
+// taskFormatter returns the formatter for a task's streamed output: the one
+// its output.format names, or else the project's output.format, falling back
+// to the generic formatter.
+func taskFormatter(wf *WorkflowContext, task *config.TaskConfig) output.Formatter {
+	format := task.Output.Format
+	if format == "" && wf.Resolved != nil && wf.Resolved.Project != nil {
+		format = wf.Resolved.Project.Output.Format
+	}
+	if f := formatters.ForName(format); f != nil {
+		return f
+	}
+	return output.NewGenericFormatter()
+}
+
+// cancelPhase cleans up after a task interrupted by Ctrl+C, before the lock
+// is released: it runs the task's on_cancel command, then pulls the task's
+// files. A step that was cut short already pulled its pull_on_fail files.
+func cancelPhase(wf *WorkflowContext, task *config.TaskConfig, env map[string]string, remoteDir string, setupCommands []string, stdout, stderr io.Writer) {
+	if task.OnCancel != "" {
+		reporter := wf.GetReporter()
+		reporter.PhaseStart("on_cancel")
+		start := time.Now()
+		exitCode, err := exec.ExecuteOnCancel(wf.Conn, task, env, remoteDir, setupCommands, stdout, stderr)
+		switch {
+		case err != nil:
+			reporter.PhaseFailed("on_cancel", err)
+		case exitCode != 0:
+			reporter.PhaseFailed("on_cancel", fmt.Errorf("on_cancel exited with code %d", exitCode))
+		default:
+			reporter.PhaseComplete("on_cancel", wf.Conn.Name, time.Since(start))
+		}
+	}
+
+	ExecutePullPhase(wf, task.Pull, "")
+}
+
 // renderExecutionPlan displays the execution plan.
 func renderExecutionPlan(plan *deps.ExecutionPlan, task *config.TaskConfig, quiet bool) {
 	if quiet {
@@ -452,13 +657,20 @@ func renderTaskSummary(_ *ui.PhaseDisplay, result *exec.TaskResult, taskName str
 	symbolStyle := lipgloss.NewStyle().Foreground(symbolColor)
 	mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
 
+	skipped := ""
+	if n := skippedStepCount(result); n == 1 {
+		skipped = ", 1 step skipped"
+	} else if n > 1 {
+		skipped = fmt.Sprintf(", %d steps skipped", n)
+	}
+
 	if result.ExitCode == 0 {
 		fmt.Printf("%s Task '%s' completed on %s %s\n",
 			symbolStyle.Render(symbol),
 			taskName,
 			host,
-			mutedStyle.Render(fmt.Sprintf("(%.1fs total, %.1fs exec)",
-				totalTime.Seconds(), execTime.Seconds())),
+			mutedStyle.Render(fmt.Sprintf("(%.1fs total, %.1fs exec%s)",
+				totalTime.Seconds(), execTime.Seconds(), skipped)),
 		)
 	} else {
 		// Show which step failed if it's a multi-step task
@@ -473,67 +685,108 @@ func renderTaskSummary(_ *ui.PhaseDisplay, result *exec.TaskResult, taskName str
 			failInfo,
 			host,
 			result.ExitCode,
-			mutedStyle.Render(fmt.Sprintf("(%.1fs)", totalTime.Seconds())),
+			mutedStyle.Render(fmt.Sprintf("(%.1fs%s)", totalTime.Seconds(), skipped)),
 		)
 	}
 }
 
-// ListTasks displays all available tasks from the configuration.
-func ListTasks() error {
+// skippedStepCount counts the steps whose if: kept them from running.
+func skippedStepCount(result *exec.TaskResult) int {
+	n := 0
+	for _, s := range result.StepResults {
+		if s.Skipped {
+			n++
+		}
+	}
+	return n
+}
+
+// TasksOptions holds options for the tasks command.
+type TasksOptions struct {
+	Filter string // Only tasks whose name or description contains this
+	Type   string // Only tasks of this type ("single", "multi-step", "parallel", "watch")
+}
+
+// taskTypes are the task types TaskInfo.Type can be, for --type.
+var taskTypes = []string{"single", "multi-step", "parallel", "watch"}
+
+// ListTasks displays the tasks from the configuration that match opts.
+func ListTasks(opts TasksOptions) error {
+	jsonOut := tasksJSON || MachineMode()
+	fail := func(err error) error {
+		if jsonOut {
+			return WriteJSONFromError(os.Stdout, err)
+		}
+		return err
+	}
+
+	if opts.Type != "" && !slices.Contains(taskTypes, opts.Type) {
+		return fail(errors.New(errors.ErrConfig,
+			fmt.Sprintf("'%s' isn't a task type", opts.Type),
+			"Use one of: "+strings.Join(taskTypes, ", ")))
+	}
+
 	// Find and load config
 	cfgPath, err := config.Find("")
 	if err != nil {
-		if tasksJSON || MachineMode() {
-			return WriteJSONFromError(os.Stdout, errors.WrapWithCode(err, errors.ErrConfig,
-				"Couldn't find a config file",
-				"Run 'rr init' to create one."))
-		}
-		return errors.WrapWithCode(err, errors.ErrConfig,
+		return fail(errors.WrapWithCode(err, errors.ErrConfig,
 			"Couldn't find a config file",
-			"Run 'rr init' to create one.")
+			"Run 'rr init' to create one."))
+	}
+	if cfgPath == "" {
+		return fail(errors.New(errors.ErrConfig,
+			"No .rr.yaml found in this directory or parent directories",
+			"Run 'rr init' to create one.").
+			WithID(errors.IDConfigNotFound))
 	}
 
 	cfg, err := config.Load(cfgPath)
 	if err != nil {
-		if tasksJSON || MachineMode() {
-			return WriteJSONFromError(os.Stdout, err)
-		}
-		return err
+		return fail(err)
 	}
+	entries, _ := history.Load(filepath.Dir(cfgPath))
+	tasks := buildTaskInfos(cfg, entries, opts)
 
 	// JSON/machine mode output
-	if tasksJSON || MachineMode() {
-		return outputTasksJSON(cfg)
+	if jsonOut {
+		return outputTasksJSON(tasks)
 	}
 
 	// Human-readable output
-	return outputTasksText(cfg)
+	return outputTasksText(cfg, tasks, opts, time.Now())
 }
 
-// outputTasksJSON outputs tasks in JSON format with envelope.
-func outputTasksJSON(cfg *config.Config) error {
-	output := TasksOutput{
-		Tasks: make([]TaskInfo, 0, len(cfg.Tasks)),
-	}
-
-	// Sort task names for consistent output
-	var names []string
+// buildTaskInfos describes the project's tasks that match opts, sorted by
+// name, with their runs from the project's history.
+func buildTaskInfos(cfg *config.Config, entries []history.Entry, opts TasksOptions) []TaskInfo {
+	names := make([]string, 0, len(cfg.Tasks))
 	for name := range cfg.Tasks {
 		names = append(names, name)
 	}
 	sort.Strings(names)
 
+	filter := strings.ToLower(opts.Filter)
+	tasks := make([]TaskInfo, 0, len(names))
 	for _, name := range names {
 		task := cfg.Tasks[name]
+		if filter != "" && !strings.Contains(strings.ToLower(name), filter) &&
+			!strings.Contains(strings.ToLower(task.Description), filter) {
+			continue
+		}
+
 		info := TaskInfo{
 			Name:        name,
 			Description: task.Description,
 			Hosts:       task.Hosts,
+			Shards:      task.Shard.Count,
 		}
 
 		if config.IsParallelTask(&task) {
 			info.Type = "parallel"
 			info.Subtasks = task.Parallel
+		} else if config.IsWatchTask(&task) {
+			info.Type = "watch"
+			info.Command = task.Run
 		} else if len(task.Steps) > 0 {
 			info.Type = "multi-step"
 			steps := make([]string, len(task.Steps))
@@ -545,9 +798,35 @@ func outputTasksJSON(cfg *config.Config) error {
 			info.Type = "single"
 			info.Command = task.Run
 		}
+		if opts.Type != "" && info.Type != opts.Type {
+			continue
+		}
 
-		output.Tasks = append(output.Tasks, info)
+		for _, dep := range task.Depends {
+			info.Depends = append(info.Depends, TaskDependency{Task: dep.Task, Parallel: dep.Parallel})
+		}
+		if stats, ok := history.StatsFor(entries, history.TaskKey(name)); ok {
+			info.Runs = &TaskRunStats{
+				Count:        stats.Runs,
+				Failed:       stats.Failed,
+				LastTime:     stats.Last.Time,
+				LastHost:     stats.Last.Host,
+				LastExitCode: stats.Last.ExitCode,
+				LastDuration: fmt.Sprintf("%.1fs", stats.Last.Total.Seconds()),
+			}
+			if stats.Median > 0 {
+				info.Runs.MedianDuration = fmt.Sprintf("%.1fs", stats.Median.Seconds())
+			}
+		}
+
+		tasks = append(tasks, info)
 	}
+	return tasks
+}
+
+// outputTasksJSON outputs tasks in JSON format with envelope.
+func outputTasksJSON(tasks []TaskInfo) error {
+	output := TasksOutput{Tasks: tasks}
 
 	// Use envelope wrapper in structured mode, plain JSON for --json
 	if MachineMode() {
@@ -561,7 +840,7 @@ func outputTasksJSON(cfg *config.Config) error {
 }
 
 // outputTasksText outputs tasks in human-readable format.
-func outputTasksText(cfg *config.Config) error {
+func outputTasksText(cfg *config.Config, tasks []TaskInfo, opts TasksOptions, now time.Time) error {
 	if len(cfg.Tasks) == 0 {
 		fmt.Println("No tasks defined.")
 		fmt.Println()
@@ -572,53 +851,105 @@ func outputTasksText(cfg *config.Config) error {
 		fmt.Println("      description: Run the test suite")
 		return nil
 	}
-
-	// Sort task names for consistent output
-	var names []string
-	for name := range cfg.Tasks {
-		names = append(names, name)
+	if len(tasks) == 0 {
+		fmt.Printf("No tasks match. Run 'rr tasks' to see all %d.\n", len(cfg.Tasks))
+		return nil
 	}
-	sort.Strings(names)
 
 	mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
 	boldStyle := lipgloss.NewStyle().Bold(true)
 
-	fmt.Printf("Available tasks (%d):\n\n", len(names))
+	if opts.Filter != "" || opts.Type != "" {
+		fmt.Printf("Matching tasks (%d of %d):\n\n", len(tasks), len(cfg.Tasks))
+	} else {
+		fmt.Printf("Available tasks (%d):\n\n", len(tasks))
+	}
 
-	for _, name := range names {
-		task := cfg.Tasks[name]
+	for _, info := range tasks {
+		task := cfg.Tasks[info.Name]
 
 		// Task name (bold)
-		fmt.Printf("  %s", boldStyle.Render(name))
+		fmt.Printf("  %s", boldStyle.Render(info.Name))
 
 		// Description if present
-		if task.Description != "" {
-			fmt.Printf("  %s", mutedStyle.Render(task.Description))
+		if info.Description != "" {
+			fmt.Printf("  %s", mutedStyle.Render(info.Description))
 		}
 		fmt.Println()
 
 		// Command, steps, or parallel
-		if config.IsParallelTask(&task) {
-			fmt.Printf("    %s\n", mutedStyle.Render(fmt.Sprintf("parallel: %d tasks", len(task.Parallel))))
-		} else if task.Run != "" {
-			fmt.Printf("    %s\n", mutedStyle.Render(task.Run))
-		} else if len(task.Steps) > 0 {
-			fmt.Printf("    %s\n", mutedStyle.Render(fmt.Sprintf("(%d steps)", len(task.Steps))))
+		switch info.Type {
+		case "parallel":
+			fmt.Printf("    %s\n", mutedStyle.Render("parallel: "+strings.Join(info.Subtasks, ", ")))
+		case "watch":
+			fmt.Printf("    %s\n", mutedStyle.Render("watch: "+info.Command))
+		case "multi-step":
+			fmt.Printf("    %s\n", mutedStyle.Render(fmt.Sprintf("(%d steps)", len(info.Steps))))
+		default:
+			fmt.Printf("    %s\n", mutedStyle.Render(info.Command))
+		}
+
+		if config.IsShardedTask(&task) {
+			fmt.Printf("    %s\n", mutedStyle.Render(fmt.Sprintf("sharded: %d shards (%s)", task.Shard.Count, config.GetShardStrategy(&task))))
+		}
+
+		if len(info.Depends) > 0 {
+			fmt.Printf("    %s\n", mutedStyle.Render("depends: "+formatTaskDepends(info.Depends)))
 		}
 
 		// Host restrictions if any
-		if len(task.Hosts) > 0 {
-			fmt.Printf("    %s\n", mutedStyle.Render("hosts: "+util.JoinOrNone(task.Hosts)))
+		if len(info.Hosts) > 0 {
+			fmt.Printf("    %s\n", mutedStyle.Render("hosts: "+util.JoinOrNone(info.Hosts)))
+		}
+
+		if info.Runs != nil {
+			fmt.Printf("    %s\n", mutedStyle.Render(formatTaskRuns(info.Runs, now)))
 		}
 	}
 
 	fmt.Println()
 	fmt.Println("Run a task:")
-	fmt.Printf("  rr %s\n", names[0])
+	fmt.Printf("  rr %s\n", tasks[0].Name)
 
 	return nil
 }
 
+// formatTaskDepends shows a task's dependencies in the order they run, with
+// a parallel group's tasks joined by "+".
+func formatTaskDepends(depends []TaskDependency) string {
+	stages := make([]string, len(depends))
+	for i, dep := range depends {
+		if len(dep.Parallel) > 0 {
+			stages[i] = strings.Join(dep.Parallel, " + ")
+		} else {
+			stages[i] = dep.Task
+		}
+	}
+	return strings.Join(stages, " -> ")
+}
+
+// formatTaskRuns shows a task's last run and how its runs have gone, like
+// "last run passed on mini, 12.3s, 2 hours ago (8 runs, 1 failed, median 11.9s)".
+func formatTaskRuns(runs *TaskRunStats, now time.Time) string {
+	result := "passed"
+	if runs.LastExitCode != 0 {
+		result = fmt.Sprintf("failed (exit %d)", runs.LastExitCode)
+	}
+	s := fmt.Sprintf("last run %s on %s, %s, %s", result, runs.LastHost, runs.LastDuration, formatAge(now.Sub(runs.LastTime)))
+
+	summary := []string{fmt.Sprintf("%d runs", runs.Count)}
+	if runs.Count == 1 {
+		summary[0] = "1 run"
+	}
+	if runs.Failed > 0 {
+		summary = append(summary, fmt.Sprintf("%d failed", runs.Failed))
+	}
+	if runs.MedianDuration != "" {
+		summary = append(summary, "median "+runs.MedianDuration)
+	}
+	return s + " (" + strings.Join(summary, ", ") + ")"
+}
+
 // RegisterTaskCommands dynamically registers task commands from config.
 // This should be called after config is loaded.
 func RegisterTaskCommands(cfg *config.Config) {
@@ -641,25 +972,31 @@ func RegisterTaskCommands(cfg *config.Config) {
 
 // createTaskCommand creates a cobra command for a task.
 func createTaskCommand(name string, task config.TaskConfig) *cobra.Command {
-	// Check if this is a parallel task
-	if config.IsParallelTask(&task) {
+	// Parallel and sharded tasks both spread work across hosts
+	if config.IsParallelTask(&task) || config.IsShardedTask(&task) {
 		return createParallelTaskCommand(name, task)
 	}
+	if config.IsWatchTask(&task) {
+		return createWatchTaskCommand(name, task)
+	}
 
-	var hostFlag string
-	var tagFlag string
-	var probeTimeoutFlag string
-	var localFlag bool
-	var skipDepsFlag bool
-	var fromFlag string
-	var repeatFlag int
+	var flags taskFlags
+	var paramFlags []string
 
 	cmd := &cobra.Command{
 		Use:   name + " [args...]",
 		Short: task.Description,
 		Long:  buildTaskLongDescription(name, task),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runTaskCommand(name, args, hostFlag, tagFlag, probeTimeoutFlag, localFlag, skipDepsFlag, fromFlag, repeatFlag)
+			params, err := taskParamValues(name, task, paramFlags)
+			if err != nil {
+				return err
+			}
+			// --host and --local pin the task to one place, so there's nothing to race
+			if task.Speculative && flags.Host == "" && !flags.Local && flags.Repeat <= 1 {
+				return runSpeculativeTaskCommand(name, args, params, flags)
+			}
+			return runTaskCommand(name, args, params, flags)
 		},
 	}
 
@@ -669,16 +1006,24 @@ func createTaskCommand(name string, task config.TaskConfig) *cobra.Command {
 	}
 
 	// Add common flags
-	cmd.Flags().StringVar(&hostFlag, "host", "", "target host name")
-	cmd.Flags().StringVar(&tagFlag, "tag", "", "select host by tag")
-	cmd.Flags().StringVar(&probeTimeoutFlag, "probe-timeout", "", "SSH probe timeout (e.g., 5s, 2m)")
-	cmd.Flags().BoolVar(&localFlag, "local", false, "force local execution (skip remote hosts)")
-	cmd.Flags().IntVar(&repeatFlag, "repeat", 0, "run task N times in parallel across available hosts (for flake detection)")
+	cmd.Flags().StringVar(&flags.Host, "host", "", "target host name")
+	cmd.Flags().StringVar(&flags.Tag, "tag", "", "select host by tag")
+	cmd.Flags().StringVar(&flags.ProbeTimeout, "probe-timeout", "", "SSH probe timeout (e.g., 5s, 2m)")
+	cmd.Flags().BoolVar(&flags.Local, "local", false, "force local execution (skip remote hosts)")
+	cmd.Flags().IntVar(&flags.Repeat, "repeat", 0, "run task N times in parallel across available hosts (for flake detection)")
+	cmd.Flags().BoolVar(&flags.NoSummary, "no-summary", false, "don't print the phase timing summary after the run")
+	cmd.Flags().BoolVar(&flags.Diagnostics, "diagnostics", false, diagnosticsFlagUsage)
+	cmd.Flags().BoolVar(&flags.Force, "force", false, forceFlagUsage)
+	cmd.Flags().BoolVar(&flags.Rebootstrap, "rebootstrap", false, rebootstrapFlagUsage)
+	addNotifyWhenFreeFlag(cmd)
 
 	// Add dependency flags if task has dependencies
 	if config.HasDependencies(&task) {
-		cmd.Flags().BoolVar(&skipDepsFlag, "skip-deps", false, "skip dependencies, run only this task")
-		cmd.Flags().StringVar(&fromFlag, "from", "", "start from this task in the dependency chain")
+		cmd.Flags().BoolVar(&flags.SkipDeps, "skip-deps", false, "skip dependencies, run only this task")
+		cmd.Flags().StringVar(&flags.From, "from", "", "start from this task in the dependency chain")
+	}
+	if len(task.Params) > 0 {
+		cmd.Flags().StringArrayVar(&paramFlags, "param", nil, "set a param instead of being asked for it (name=value, repeatable)")
 	}
 
 	return cmd
@@ -696,9 +1041,16 @@ func createParallelTaskCommand(name string, task config.TaskConfig) *cobra.Comma
 	var maxParallelFlag int
 	var noLogsFlag bool
 	var dryRunFlag bool
-
+	var diagnosticsFlag bool
+	var forceFlag bool
+	var rebootstrapFlag bool
+	var coldFlag bool
+	var shardFlag int
+
+	// Sharded tasks take args like the single task they split up
+	acceptsArgs := task.ForwardArgs || config.IsShardedTask(&task)
 	useStr := name
-	if task.ForwardArgs {
+	if acceptsArgs {
 		useStr = name + " [args...]"
 	}
 
@@ -708,7 +1060,7 @@ func createParallelTaskCommand(name string, task config.TaskConfig) *cobra.Comma
 		Long:  buildParallelTaskLongDescription(name, task),
 		Args:  cobra.ArbitraryArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if len(args) > 0 && !task.ForwardArgs {
+			if len(args) > 0 && !acceptsArgs {
 				return errors.New(errors.ErrConfig,
 					fmt.Sprintf("parallel task '%s' doesn't accept extra arguments (got: %s)", name, strings.Join(args, " ")),
 					fmt.Sprintf("use 'rr run \"<command> %s\"' for ad-hoc runs with custom args", strings.Join(args, " ")))
@@ -726,6 +1078,11 @@ func createParallelTaskCommand(name string, task config.TaskConfig) *cobra.Comma
 				DryRun:      dryRunFlag,
 				Local:       localFlag,
 				Args:        args,
+				Diagnostics: diagnosticsFlag,
+				Force:       forceFlag,
+				Rebootstrap: rebootstrapFlag,
+				Cold:        coldFlag,
+				Shard:       shardFlag,
 			})
 		},
 	}
@@ -748,6 +1105,13 @@ func createParallelTaskCommand(name string, task config.TaskConfig) *cobra.Comma
 	cmd.Flags().IntVar(&maxParallelFlag, "max-parallel", 0, "limit concurrent task execution (0 = unlimited)")
 	cmd.Flags().BoolVar(&noLogsFlag, "no-logs", false, "don't save output to log files")
 	cmd.Flags().BoolVar(&dryRunFlag, "dry-run", false, "show execution plan without running")
+	cmd.Flags().BoolVar(&diagnosticsFlag, "diagnostics", false, diagnosticsFlagUsage)
+	cmd.Flags().BoolVar(&forceFlag, "force", false, forceFlagUsage)
+	cmd.Flags().BoolVar(&rebootstrapFlag, "rebootstrap", false, rebootstrapFlagUsage)
+	cmd.Flags().BoolVar(&coldFlag, "cold", false, "sync and run setup even if the hosts are warm from a recent run")
+	if config.IsShardedTask(&task) {
+		cmd.Flags().IntVar(&shardFlag, "shard", 0, "run only this shard (1 to the shard count)")
+	}
 
 	return cmd
 }
@@ -805,10 +1169,42 @@ func buildTaskLongDescription(name string, task config.TaskConfig) string {
 		desc += "This task orchestrates its dependencies without running its own command.\n"
 	}
 
+	if len(task.Params) > 0 {
+		desc += "\nParams (asked for when run, or pass --param name=value):\n"
+		for _, p := range task.Params {
+			line := "  " + p.Name
+			if len(p.Choices) > 0 {
+				line += ": " + strings.Join(p.Choices, " | ")
+			}
+			if p.Default != "" {
+				line += fmt.Sprintf(" (default %s)", p.Default)
+			}
+			desc += line + "\n"
+		}
+	}
+
 	if len(task.Hosts) > 0 {
 		desc += fmt.Sprintf("\nRestricted to hosts: %s\n", util.JoinOrNone(task.Hosts))
 	}
 
+	if task.Build != "" {
+		desc += fmt.Sprintf("\nBuilds locally first: %s\n", task.Build)
+	}
+	if len(task.Push) > 0 {
+		desc += fmt.Sprintf("\nSyncs only: %s\n", strings.Join(task.Push, ", "))
+	}
+	if len(task.Inputs) > 0 {
+		desc += fmt.Sprintf("\nTakes inputs from: %s (run first; --skip-deps reuses their last outputs)\n", strings.Join(task.Inputs, ", "))
+	}
+	if len(task.Outputs) > 0 {
+		desc += fmt.Sprintf("\nOutputs: %s\n", strings.Join(task.Outputs, ", "))
+	}
+
+	if task.Speculative {
+		desc += "\nSpeculative: runs on the two highest-priority hosts at once and keeps the\n"
+		desc += "first successful result. --host or --local runs it on one host as usual.\n"
+	}
+
 	// Show dependency flags if present
 	if config.HasDependencies(&task) {
 		desc += "\nDependency flags:\n"
@@ -827,9 +1223,18 @@ func buildParallelTaskLongDescription(name string, task config.TaskConfig) strin
 		desc += task.Description + "\n\n"
 	}
 
-	desc += fmt.Sprintf("Runs %d tasks concurrently:\n", len(task.Parallel))
-	for i, subtask := range task.Parallel {
-		desc += fmt.Sprintf("  %d. %s\n", i+1, subtask)
+	if config.IsShardedTask(&task) {
+		desc += fmt.Sprintf("Runs %d shards of this command concurrently, each with %s and %s set:\n",
+			task.Shard.Count, parallel.ShardIndexEnv, parallel.ShardTotalEnv)
+		desc += "  " + shardedCommand(&task) + "\n"
+		if config.GetShardStrategy(&task) == config.ShardDuration {
+			desc += "\nShards that took longest in recent runs start first (strategy: duration)\n"
+		}
+	} else {
+		desc += fmt.Sprintf("Runs %d tasks concurrently:\n", len(task.Parallel))
+		for i, subtask := range task.Parallel {
+			desc += fmt.Sprintf("  %d. %s\n", i+1, subtask)
+		}
 	}
 
 	if task.FailFast {
@@ -838,6 +1243,9 @@ func buildParallelTaskLongDescription(name string, task config.TaskConfig) strin
 	if task.MaxParallel > 0 {
 		desc += fmt.Sprintf("\nMax concurrent tasks: %d\n", task.MaxParallel)
 	}
+	if task.Warm != "" {
+		desc += fmt.Sprintf("\nHosts stay warm for %s: a run that soon after the last one skips an unneeded sync and setup\n", task.Warm)
+	}
 
 	desc += "\nParallel-specific flags:\n"
 	desc += "  --stream       Show real-time interleaved output\n"
@@ -847,25 +1255,52 @@ func buildParallelTaskLongDescription(name string, task config.TaskConfig) strin
 	desc += "  --max-parallel Limit concurrent execution\n"
 	desc += "  --no-logs      Don't save output to log files\n"
 	desc += "  --dry-run      Show plan without executing\n"
+	desc += "  --cold         Sync and run setup even if the hosts are warm\n"
+	if config.IsShardedTask(&task) {
+		desc += "  --shard N      Run only shard N\n"
+	}
 
 	return desc
 }
 
+// shardedCommand describes what each shard of a sharded task runs.
+func shardedCommand(task *config.TaskConfig) string {
+	if task.Run != "" {
+		return task.Run
+	}
+	return fmt.Sprintf("(%d steps)", len(task.Steps))
+}
+
+// taskFlags are the flags of a task's command, as given on the command line.
+type taskFlags struct {
+	Host         string
+	Tag          string
+	ProbeTimeout string
+	Local        bool
+	SkipDeps     bool   // Only for tasks with dependencies
+	From         string // Only for tasks with dependencies
+	Repeat       int    // Run the task this many times in parallel (--repeat)
+	NoSummary    bool
+	Diagnostics  bool
+	Force        bool
+	Rebootstrap  bool
+}
+
 // runTaskCommand is the implementation for task commands.
-func runTaskCommand(taskName string, args []string, hostFlag, tagFlag, probeTimeoutFlag string, localFlag, skipDepsFlag bool, fromFlag string, repeatCount int) error {
-	probeTimeout, err := ParseProbeTimeout(probeTimeoutFlag)
+func runTaskCommand(taskName string, args []string, params map[string]string, flags taskFlags) error {
+	probeTimeout, err := ParseProbeTimeout(flags.ProbeTimeout)
 	if err != nil {
 		return err
 	}
 
 	// If --repeat is specified, use parallel execution
-	if repeatCount > 1 {
+	if flags.Repeat > 1 {
 		if len(args) > 0 {
 			return errors.New(errors.ErrConfig,
 				"Can't use --repeat with task arguments",
 				"Remove extra arguments or run without --repeat.")
 		}
-		exitCode, err := runTaskRepeated(taskName, repeatCount, hostFlag, tagFlag, localFlag)
+		exitCode, err := runTaskRepeated(taskName, params, flags)
 		if err != nil {
 			return err
 		}
@@ -878,13 +1313,18 @@ func runTaskCommand(taskName string, args []string, hostFlag, tagFlag, probeTime
 	exitCode, err := RunTask(TaskOptions{
 		TaskName:     taskName,
 		Args:         args,
-		Host:         hostFlag,
-		Tag:          tagFlag,
+		Params:       params,
+		Host:         flags.Host,
+		Tag:          flags.Tag,
 		ProbeTimeout: probeTimeout,
 		Quiet:        Quiet(),
-		Local:        localFlag,
-		SkipDeps:     skipDepsFlag,
-		From:         fromFlag,
+		Local:        flags.Local,
+		SkipDeps:     flags.SkipDeps,
+		From:         flags.From,
+		NoSummary:    flags.NoSummary,
+		Diagnostics:  flags.Diagnostics,
+		Force:        flags.Force,
+		Rebootstrap:  flags.Rebootstrap,
 	})
 
 	if err != nil {
@@ -900,9 +1340,9 @@ func runTaskCommand(taskName string, args []string, hostFlag, tagFlag, probeTime
 
 // runTaskRepeated runs a task N times in parallel across available hosts.
 // Used for flake detection - run the same task multiple times to surface intermittent failures.
-func runTaskRepeated(taskName string, repeatCount int, hostFlag, tagFlag string, localFlag bool) (int, error) {
+func runTaskRepeated(taskName string, params map[string]string, flags taskFlags) (int, error) {
 	// Load and validate config
-	resolved, err := config.LoadResolved(Config())
+	resolved, err := loadResolved(Config())
 	if err != nil {
 		return 1, err
 	}
@@ -910,18 +1350,31 @@ func runTaskRepeated(taskName string, repeatCount int, hostFlag, tagFlag string,
 	if err := config.ValidateResolved(resolved); err != nil {
 		return 1, err
 	}
+	applyTaskParams(resolved.Project, taskName, params)
 
 	// Get the task to extract its command
 	task, err := config.GetTask(resolved.Project, taskName)
 	if err != nil {
 		return 1, err
 	}
+	env, err := config.ResolveEnvSecrets(task.Env, fmt.Sprintf("task '%s'", taskName))
+	if err != nil {
+		return 1, err
+	}
 
 	// Build the command from task config
 	cmd := task.Run
 	if cmd == "" && len(task.Steps) > 0 {
 		// For multi-step tasks, build a command that runs all steps
-		cmd = buildStepsCommand(task.Steps)
+		steps, err := exec.TaskSteps(task, task.Steps)
+		if err != nil {
+			return 1, err
+		}
+		cmd = buildStepsCommand(steps)
+	} else if cmd != "" {
+		if cmd, err = exec.TaskCommand(task, cmd); err != nil {
+			return 1, err
+		}
 	}
 	if cmd == "" {
 		return 1, errors.New(errors.ErrConfig,
@@ -937,42 +1390,45 @@ func runTaskRepeated(taskName string, repeatCount int, hostFlag, tagFlag string,
 	}
 
 	// Create N synthetic tasks with the same command
-	tasks := make([]parallel.TaskInfo, repeatCount)
-	for i := 0; i < repeatCount; i++ {
+	tasks := make([]parallel.TaskInfo, flags.Repeat)
+	for i := 0; i < flags.Repeat; i++ {
 		tasks[i] = parallel.TaskInfo{
 			Name:    fmt.Sprintf("%s-%d", taskName, i+1),
 			Index:   i,
 			Command: cmd,
-			Env:     task.Env,
+			Env:     env,
 		}
 	}
 
 	// Resolve hosts
-	hostOrder, hosts, err := config.ResolveHosts(resolved, hostFlag)
+	hostOrder, hosts, err := config.ResolveHosts(resolved, flags.Host)
 	if err != nil {
 		return 1, err
 	}
 
 	// Handle --local flag
-	if localFlag {
+	if flags.Local {
 		hosts = make(map[string]config.Host)
 		hostOrder = nil
 	}
 
 	// Filter by tag if specified
-	if tagFlag != "" {
-		hosts, hostOrder = filterHostsByTag(hosts, hostOrder, tagFlag)
+	if flags.Tag != "" {
+		hosts, hostOrder = filterHostsByTag(hosts, hostOrder, flags.Tag)
 		if len(hosts) == 0 {
 			return 1, errors.New(errors.ErrConfig,
-				fmt.Sprintf("No hosts found with tag '%s'", tagFlag),
+				fmt.Sprintf("No hosts found with tag '%s'", flags.Tag),
 				"Check your host tags in ~/.rr/config.yaml.")
 		}
 	}
 
 	// Build parallel config
 	parallelCfg := parallel.Config{
-		OutputMode: parallel.OutputProgress,
-		SaveLogs:   true,
+		OutputMode:  parallel.OutputProgress,
+		SaveLogs:    true,
+		Force:       flags.Force,
+		Rebootstrap: flags.Rebootstrap,
+		SyncProfile: task.SyncProfile,
 	}
 
 	// Set up log writer
@@ -1030,10 +1486,39 @@ func runTaskRepeated(taskName string, repeatCount int, hostFlag, tagFlag string,
 	return 0, nil
 }
 
-// taskStepHandler implements exec.StepHandler to show step progress during multi-step tasks.
+// newStepProgress returns the live line that shows a multi-step task's
+// running step, or nil when there's no terminal to draw it on. Low
+// bandwidth mode goes without, since it redraws several times a second.
+func newStepProgress(task *config.TaskConfig, quiet bool) *ui.StepProgress {
+	if len(task.Steps) == 0 || quiet || !PrettyMode() || config.LowBandwidth() || !ui.IsTerminal(os.Stdout) {
+		return nil
+	}
+	return ui.NewStepProgress(os.Stdout)
+}
+
+// taskStepHandler implements exec.StepHandler to show step progress during
+// multi-step tasks, and pulls each step's files as soon as it finishes.
 type taskStepHandler struct {
 	phaseDisplay *ui.PhaseDisplay
 	quiet        bool
+
+	// wf is used for step pulls. Nil skips them.
+	wf *WorkflowContext
+
+	// progress is the live line under the running step's output (nil if
+	// there isn't one). It estimates the time left from medians, the usual
+	// duration of each step by name.
+	progress *ui.StepProgress
+	steps    []config.TaskStep
+	medians  map[string]time.Duration
+
+	// durations collects how long each passing step took, for run history.
+	// Nil skips it.
+	durations map[string]time.Duration
+
+	// pullMu keeps step pulls from overlapping when the tasks of a
+	// dependency stage run in parallel.
+	pullMu sync.Mutex
 }
 
 // OnStepStart is called before a step begins execution.
@@ -1045,10 +1530,7 @@ func (h *taskStepHandler) OnStepStart(stepNum, totalSteps int, step config.TaskS
 	mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
 	boldStyle := lipgloss.NewStyle().Bold(true)
 
-	stepName := step.Name
-	if stepName == "" {
-		stepName = fmt.Sprintf("step %d", stepNum)
-	}
+	stepName := taskStepName(step, stepNum)
 
 	// Show step header: ━━━ Step 1/3: Sync dependencies ━━━
 	header := fmt.Sprintf("Step %d/%d: %s", stepNum, totalSteps, stepName)
@@ -1057,21 +1539,85 @@ func (h *taskStepHandler) OnStepStart(stepNum, totalSteps int, step config.TaskS
 
 	// Show the command being run
 	fmt.Printf("%s %s\n\n", mutedStyle.Render("$"), step.Run)
+
+	if h.progress != nil {
+		h.progress.Start(header, h.timeLeft(stepNum))
+	}
+}
+
+// timeLeft estimates how long the task has left as stepNum starts: the
+// usual durations of it and every step after it. Returns 0 if one of them
+// hasn't run often enough to have a usual duration.
+func (h *taskStepHandler) timeLeft(stepNum int) time.Duration {
+	var left time.Duration
+	for i := stepNum - 1; i < len(h.steps); i++ {
+		d, ok := h.medians[taskStepName(h.steps[i], i+1)]
+		if !ok {
+			return 0
+		}
+		left += d
+	}
+	return left
 }
 
 // OnStepComplete is called after a step finishes execution.
 func (h *taskStepHandler) OnStepComplete(stepNum, totalSteps int, step config.TaskStep, duration time.Duration, exitCode int) {
+	if h.progress != nil {
+		h.progress.Stop()
+	}
+	if exitCode == 0 && h.durations != nil {
+		h.durations[taskStepName(step, stepNum)] = duration
+	}
+	h.renderStepComplete(stepNum, totalSteps, step, duration, exitCode)
+	if exitCode == 0 && h.wf != nil {
+		h.wf.checkpointStep(stepNum)
+	}
+
+	if items := config.GetStepPulls(step, exitCode); len(items) > 0 && h.wf != nil {
+		h.pullMu.Lock()
+		ExecutePullPhase(h.wf, items, "")
+		h.pullMu.Unlock()
+	}
+}
+
+// OnStepSkipped is called for a step whose if: is false.
+func (h *taskStepHandler) OnStepSkipped(stepNum, totalSteps int, step config.TaskStep) {
+	if h.wf != nil {
+		h.wf.checkpointStep(stepNum)
+	}
+	if h.quiet {
+		return
+	}
+
+	mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
+	stepName := taskStepName(step, stepNum)
+
+	// Show the skip in place of the step: ⊖ Step 2/3: lint skipped (if: ...)
+	fmt.Printf("\n%s\n", mutedStyle.Render(fmt.Sprintf("%s Step %d/%d: %s skipped (if: %s)",
+		ui.SymbolSkipped, stepNum, totalSteps, stepName, step.If)))
+}
+
+// renderStepComplete shows a finished step: ● Step 1/3: build (2.3s). A
+// step with on_fail: continue that failed gets a warning instead, since
+// the task keeps going.
+func (h *taskStepHandler) renderStepComplete(stepNum, totalSteps int, step config.TaskStep, duration time.Duration, exitCode int) {
 	if h.quiet {
 		return
 	}
 
 	var symbol string
 	var symbolColor lipgloss.Color
+	timing := fmt.Sprintf("(%.1fs)", duration.Seconds())
 
-	if exitCode == 0 {
+	switch {
+	case exitCode == 0:
 		symbol = ui.SymbolSuccess
 		symbolColor = ui.ColorSuccess
-	} else {
+	case config.GetStepOnFail(step) == config.OnFailContinue:
+		symbol = ui.SymbolWarning
+		symbolColor = ui.ColorWarning
+		timing = fmt.Sprintf("(%.1fs, exit %d, continuing)", duration.Seconds(), exitCode)
+	default:
 		symbol = ui.SymbolFail
 		symbolColor = ui.ColorError
 	}
@@ -1079,10 +1625,7 @@ func (h *taskStepHandler) OnStepComplete(stepNum, totalSteps int, step config.Ta
 	symbolStyle := lipgloss.NewStyle().Foreground(symbolColor)
 	mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
 
-	stepName := step.Name
-	if stepName == "" {
-		stepName = fmt.Sprintf("step %d", stepNum)
-	}
+	stepName := taskStepName(step, stepNum)
 
 	// Show step completion: ● Step 1/3 complete (2.3s)
 	fmt.Printf("\n%s Step %d/%d: %s %s\n",
@@ -1090,6 +1633,15 @@ func (h *taskStepHandler) OnStepComplete(stepNum, totalSteps int, step config.Ta
 		stepNum,
 		totalSteps,
 		stepName,
-		mutedStyle.Render(fmt.Sprintf("(%.1fs)", duration.Seconds())),
+		mutedStyle.Render(timing),
 	)
 }
+
+// taskStepName returns the step's name, or "step N" if it has none, the
+// same as the name its results are reported under.
+func taskStepName(step config.TaskStep, stepNum int) string {
+	if step.Name != "" {
+		return step.Name
+	}
+	return fmt.Sprintf("step %d", stepNum)
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/history"
+	"github.com/rileyhilliard/rr/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// shouldPickTask reports whether bare 'rr' should open the task picker:
+// the project defines tasks, both stdin and stdout are terminals, and
+// prompts aren't off with --non-interactive. Otherwise bare 'rr' prints
+// help, same as before tasks existed.
+func shouldPickTask() bool {
+	if machineMode || discoveryState == nil || len(discoveryState.TasksAvailable) == 0 {
+		return false
+	}
+	return canPrompt() && ui.IsTerminal(os.Stdout)
+}
+
+// runTaskPicker shows the task picker and runs the selected task as if it
+// had been typed on the command line ('rr <task>'), with default flags.
+func runTaskPicker(cmd *cobra.Command) error {
+	if !shouldPickTask() {
+		return cmd.Help()
+	}
+
+	cfg, err := config.Load(discoveryState.ProjectPath)
+	if err != nil {
+		return err
+	}
+	entries, _ := history.Load(filepath.Dir(discoveryState.ProjectPath))
+
+	selected, err := ui.PickTask(buildTaskPickerItems(cfg, entries))
+	if err != nil {
+		return err
+	}
+	if selected == nil {
+		return nil // Cancelled
+	}
+
+	taskCmd, _, err := cmd.Find([]string{selected.Name})
+	if err != nil || taskCmd == cmd || taskCmd.RunE == nil {
+		return errors.New(errors.ErrConfig,
+			"Task '"+selected.Name+"' isn't available",
+			"Run 'rr tasks' to see what's defined.")
+	}
+
+	// The picker is interactive, so the task runs with human-readable output.
+	prettyMode = true
+	return taskCmd.RunE(taskCmd, nil)
+}
+
+// buildTaskPickerItems lists the project's tasks alphabetically with their
+// most recent run from history.
+func buildTaskPickerItems(cfg *config.Config, entries []history.Entry) []ui.TaskInfo {
+	names := make([]string, 0, len(cfg.Tasks))
+	for name := range cfg.Tasks {
+		if config.IsReservedTaskName(name) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	items := make([]ui.TaskInfo, 0, len(names))
+	for _, name := range names {
+		info := ui.TaskInfo{
+			Name:        name,
+			Description: cfg.Tasks[name].Description,
+		}
+		if last, ok := history.Last(entries, history.TaskKey(name)); ok {
+			info.HasRun = true
+			info.LastRun = last.Total
+			info.LastExit = last.ExitCode
+		}
+		items = append(items, info)
+	}
+	return items
+}
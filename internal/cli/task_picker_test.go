@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/history"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildTaskPickerItems(t *testing.T) {
+	cfg := &config.Config{
+		Tasks: map[string]config.TaskConfig{
+			"test":  {Description: "Run tests", Run: "go test ./..."},
+			"build": {Run: "go build ./..."},
+			"lint":  {Description: "Lint", Run: "golangci-lint run"},
+		},
+	}
+	entries := []history.Entry{
+		{Key: history.TaskKey("test"), Total: 40 * time.Second, ExitCode: 1},
+		{Key: history.RunKey("go test ./..."), Total: time.Second},
+		{Key: history.TaskKey("test"), Total: 34 * time.Second},
+		{Key: history.TaskKey("build"), Total: 5 * time.Second, ExitCode: 2},
+	}
+
+	items := buildTaskPickerItems(cfg, entries)
+	require.Len(t, items, 3)
+
+	// Sorted by name
+	assert.Equal(t, "build", items[0].Name)
+	assert.Equal(t, "lint", items[1].Name)
+	assert.Equal(t, "test", items[2].Name)
+
+	assert.True(t, items[0].HasRun)
+	assert.Equal(t, 5*time.Second, items[0].LastRun)
+	assert.Equal(t, 2, items[0].LastExit)
+
+	assert.False(t, items[1].HasRun)
+	assert.Equal(t, "Lint", items[1].Description)
+
+	// Most recent run wins
+	assert.True(t, items[2].HasRun)
+	assert.Equal(t, 34*time.Second, items[2].LastRun)
+	assert.Equal(t, 0, items[2].LastExit)
+}
+
+func TestShouldPickTask_NoTasks(t *testing.T) {
+	saved := discoveryState
+	defer func() { discoveryState = saved }()
+
+	discoveryState = &configDiscoveryState{}
+	assert.False(t, shouldPickTask())
+
+	discoveryState = nil
+	assert.False(t, shouldPickTask())
+}
@@ -4,11 +4,14 @@ import (
 	"bytes"
 	"io"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/rileyhilliard/rr/internal/config"
 	"github.com/rileyhilliard/rr/internal/exec"
+	"github.com/rileyhilliard/rr/internal/history"
+	"github.com/rileyhilliard/rr/internal/host"
 	"github.com/rileyhilliard/rr/internal/ui"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -119,6 +122,23 @@ func TestCreateTaskCommand_HasExpectedFlags(t *testing.T) {
 	assert.Equal(t, "string", probeFlag.Value.Type())
 }
 
+func TestCreateTaskCommand_WatchTask(t *testing.T) {
+	task := config.TaskConfig{
+		Type:  config.TaskTypeWatch,
+		Run:   "npm run dev",
+		Ports: []string{"3000"},
+	}
+
+	cmd := createTaskCommand("dev", task)
+
+	assert.Equal(t, "dev [args...]", cmd.Use)
+	assert.Contains(t, cmd.Long, "npm run dev")
+	for _, name := range []string{"host", "tag", "probe-timeout", "local", "no-sync", "force"} {
+		assert.NotNil(t, cmd.Flags().Lookup(name), "should have --%s flag", name)
+	}
+	assert.Nil(t, cmd.Flags().Lookup("no-summary"), "watch tasks never finish, so there is no summary")
+}
+
 func TestRegisterTaskCommands_NilConfig(t *testing.T) {
 	// Should not panic
 	RegisterTaskCommands(nil)
@@ -387,6 +407,74 @@ func TestRenderTaskSummary_MultiStepFirstStepFails(t *testing.T) {
 	assert.Contains(t, output, ui.SymbolFail)
 }
 
+func TestRenderTaskSummary_SkippedSteps(t *testing.T) {
+	var buf bytes.Buffer
+	pd := ui.NewPhaseDisplay(&buf)
+
+	result := &exec.TaskResult{
+		FailedStep: -1,
+		StepResults: []exec.StepResult{
+			{Name: "lint", Skipped: true},
+			{Name: "test"},
+		},
+	}
+
+	output := captureStdout(t, func() {
+		renderTaskSummary(pd, result, "check", 5*time.Second, 2*time.Second, "test-host")
+	})
+	assert.Contains(t, output, "1 step skipped")
+}
+
+func TestTaskStepHandler_OnStepSkipped(t *testing.T) {
+	h := &taskStepHandler{}
+	output := captureStdout(t, func() {
+		h.OnStepSkipped(2, 3, config.TaskStep{Name: "lint", If: "files_changed('*.py')"})
+	})
+	assert.Contains(t, output, "Step 2/3: lint skipped (if: files_changed('*.py'))")
+	assert.Contains(t, output, ui.SymbolSkipped)
+}
+
+func TestTaskStepHandler_OnStepComplete(t *testing.T) {
+	h := &taskStepHandler{durations: make(map[string]time.Duration)}
+	output := captureStdout(t, func() {
+		h.OnStepComplete(1, 3, config.TaskStep{Name: "build"}, 2*time.Second, 0)
+		h.OnStepComplete(2, 3, config.TaskStep{Name: "lint", OnFail: config.OnFailContinue}, time.Second, 2)
+		h.OnStepComplete(3, 3, config.TaskStep{}, time.Second, 1)
+	})
+
+	assert.Contains(t, output, ui.SymbolSuccess+" Step 1/3: build")
+	assert.Contains(t, output, ui.SymbolWarning+" Step 2/3: lint")
+	assert.Contains(t, output, "exit 2, continuing", "a step the task carries on past is marked apart from one that stops it")
+	assert.Contains(t, output, ui.SymbolFail+" Step 3/3: step 3")
+	assert.Equal(t, map[string]time.Duration{"build": 2 * time.Second}, h.durations, "only passing steps feed the estimates")
+}
+
+func TestTaskStepHandler_TimeLeft(t *testing.T) {
+	h := &taskStepHandler{
+		steps:   []config.TaskStep{{Name: "build"}, {Name: "test"}, {}},
+		medians: map[string]time.Duration{"build": 10 * time.Second, "test": time.Minute, "step 3": 5 * time.Second},
+	}
+	assert.Equal(t, 75*time.Second, h.timeLeft(1))
+	assert.Equal(t, 65*time.Second, h.timeLeft(2))
+
+	delete(h.medians, "step 3")
+	assert.Zero(t, h.timeLeft(1), "no estimate while a step has no usual time")
+}
+
+func TestRunnableSteps(t *testing.T) {
+	steps := []config.TaskStep{
+		{Name: "a", Run: "echo a", If: "env.MODE == 'full'"},
+		{Name: "b", Run: "echo b"},
+	}
+	got, err := runnableSteps(steps, map[string]string{"MODE": "quick"})
+	require.NoError(t, err)
+	assert.Equal(t, "echo b", buildStepsCommand(got))
+
+	got, err = runnableSteps(steps[:1], nil)
+	require.NoError(t, err)
+	assert.Equal(t, "true", buildStepsCommand(got), "a subtask with every step skipped passes")
+}
+
 func TestCreateParallelTaskCommand_RejectsExtraArgs(t *testing.T) {
 	task := config.TaskConfig{
 		Description: "Run tests in parallel",
@@ -460,3 +548,99 @@ func TestCreateParallelTaskCommand_ForwardArgsUseLine(t *testing.T) {
 	assert.NotContains(t, cmdWithout.Use, "[args...]",
 		"regular parallel task should not show [args...] in Use string")
 }
+
+func TestCancelPhase_RunsOnCancel(t *testing.T) {
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "cleaned")
+	wf := &WorkflowContext{
+		Conn:     &host.Connection{Name: "local", IsLocal: true},
+		Reporter: &StructuredReporter{},
+	}
+	task := &config.TaskConfig{Run: "make e2e", OnCancel: "echo \"$STACK down\" > " + marker}
+
+	var stdout, stderr bytes.Buffer
+	cancelPhase(wf, task, map[string]string{"STACK": "e2e"}, "", nil, &stdout, &stderr)
+
+	data, err := os.ReadFile(marker)
+	require.NoError(t, err)
+	assert.Equal(t, "e2e down\n", string(data))
+}
+
+func TestBuildTaskInfos(t *testing.T) {
+	cfg := &config.Config{
+		Tasks: map[string]config.TaskConfig{
+			"lint":  {Run: "make lint", Description: "Check style"},
+			"test":  {Run: "make test", Description: "Run the suite"},
+			"build": {Steps: []config.TaskStep{{Run: "make deps"}, {Run: "make"}}},
+			"ci":    {Parallel: []string{"lint", "test"}},
+			"deploy": {
+				Run: "./deploy.sh",
+				Depends: []config.DependencyItem{
+					{Parallel: []string{"lint", "test"}},
+					{Task: "build"},
+				},
+			},
+		},
+	}
+	now := time.Now()
+	entries := []history.Entry{
+		{Key: history.TaskKey("test"), Host: "mini", Time: now.Add(-time.Hour), ExitCode: 1, Total: 4 * time.Second},
+		{Key: history.TaskKey("test"), Host: "pc", Time: now, Total: 12300 * time.Millisecond},
+		{Key: history.RunKey("make test"), Host: "pc", Time: now},
+	}
+
+	tasks := buildTaskInfos(cfg, entries, TasksOptions{})
+	names := make([]string, len(tasks))
+	for i, info := range tasks {
+		names[i] = info.Name
+	}
+	assert.Equal(t, []string{"build", "ci", "deploy", "lint", "test"}, names)
+
+	deploy := tasks[2]
+	assert.Equal(t, []TaskDependency{{Parallel: []string{"lint", "test"}}, {Task: "build"}}, deploy.Depends)
+	assert.Equal(t, "lint + test -> build", formatTaskDepends(deploy.Depends))
+	assert.Nil(t, deploy.Runs, "never run")
+
+	test := tasks[4]
+	require.NotNil(t, test.Runs)
+	assert.Equal(t, TaskRunStats{
+		Count:        2,
+		Failed:       1,
+		LastTime:     now,
+		LastHost:     "pc",
+		LastDuration: "12.3s",
+	}, *test.Runs)
+	assert.Equal(t, "last run passed on pc, 12.3s, just now (2 runs, 1 failed)", formatTaskRuns(test.Runs, now))
+}
+
+func TestBuildTaskInfos_Filters(t *testing.T) {
+	cfg := &config.Config{
+		Tasks: map[string]config.TaskConfig{
+			"lint":     {Run: "make lint", Description: "Check style"},
+			"test":     {Run: "make test"},
+			"test-e2e": {Steps: []config.TaskStep{{Run: "make e2e"}}},
+			"ci":       {Parallel: []string{"lint", "test"}, Description: "Everything the TEST server runs"},
+		},
+	}
+	names := func(tasks []TaskInfo) []string {
+		var out []string
+		for _, info := range tasks {
+			out = append(out, info.Name)
+		}
+		return out
+	}
+
+	assert.Equal(t, []string{"ci", "test", "test-e2e"}, names(buildTaskInfos(cfg, nil, TasksOptions{Filter: "Test"})),
+		"matches names and descriptions, ignoring case")
+	assert.Equal(t, []string{"test-e2e"}, names(buildTaskInfos(cfg, nil, TasksOptions{Filter: "test", Type: "multi-step"})))
+	assert.Empty(t, buildTaskInfos(cfg, nil, TasksOptions{Type: "watch"}))
+}
+
+func TestListTasks_RejectsUnknownType(t *testing.T) {
+	prettyMode = true
+	defer func() { prettyMode = false }()
+
+	err := ListTasks(TasksOptions{Type: "serial"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "isn't a task type")
+}
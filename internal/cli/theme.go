@@ -0,0 +1,95 @@
+package cli
+
+import (
+	stderrors "errors"
+	"fmt"
+	"os"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/ui"
+)
+
+// applyConfiguredTheme switches the color palette to the theme from the
+// global config's theme: section. RR_THEME overrides the theme name, which
+// is handy for trying one out. A bad theme is a warning, not an error: the
+// default colors still work, and failing every command over colors would be
+// worse than ugly output.
+func applyConfiguredTheme(global *config.GlobalConfig) {
+	var themeCfg config.ThemeConfig
+	if global != nil {
+		themeCfg = global.Theme
+	}
+	if name := os.Getenv("RR_THEME"); name != "" {
+		themeCfg.Name = name
+	}
+	if themeCfg.Name == "" && len(themeCfg.Colors) == 0 {
+		return
+	}
+
+	theme, err := ui.ResolveTheme(themeCfg.Name, themeCfg.Colors)
+	if err != nil {
+		msg := err.Error()
+		var rrErr *errors.Error
+		if stderrors.As(err, &rrErr) {
+			msg = rrErr.Message + ". " + rrErr.Suggestion
+		}
+		ui.PrintWarning("Ignoring theme: " + msg)
+		return
+	}
+	ui.ApplyTheme(theme)
+}
+
+// applyAccessibleMode turns on accessible output when --accessible is passed
+// or the global config sets accessible: true.
+func applyAccessibleMode(global *config.GlobalConfig) {
+	if !accessibleMode && (global == nil || !global.Accessible) {
+		return
+	}
+	ui.SetAccessible(true)
+	errors.FailSymbol = "[error]"
+}
+
+// colorMode is the --color flag, which takes one of the ui color modes. It's
+// empty when the flag isn't passed.
+type colorMode string
+
+func (m *colorMode) String() string {
+	if *m == "" {
+		return ui.ColorAuto
+	}
+	return string(*m)
+}
+
+func (m *colorMode) Type() string { return "when" }
+
+func (m *colorMode) Set(value string) error {
+	switch value {
+	case ui.ColorAuto, ui.ColorAlways, ui.ColorNever:
+		*m = colorMode(value)
+		return nil
+	}
+	return fmt.Errorf("must be %s, %s, or %s", ui.ColorAuto, ui.ColorAlways, ui.ColorNever)
+}
+
+// applyColorMode sets when output is colored from --no-color, --color, or
+// else the project's output.color. Structured output is never colored, but
+// commands that always draw a TUI, like 'monitor', call ui.EnableColors
+// again for themselves.
+func applyColorMode() {
+	mode := string(colorFlag)
+	switch {
+	case noColor:
+		mode = ui.ColorNever
+	case mode == "" && discoveryState != nil && discoveryState.OutputColor != "":
+		mode = discoveryState.OutputColor
+	case mode == "":
+		mode = ui.ColorAuto
+	}
+	ui.SetColorMode(mode)
+	if prettyMode {
+		ui.EnableColors()
+	} else {
+		ui.DisableColors()
+	}
+}
@@ -78,6 +78,10 @@ func unlockCommand(opts UnlockOptions) error {
 			}
 		} else {
 			// Multiple hosts - show picker
+			if !canPrompt() {
+				return needsInput("Which host should I unlock?",
+					"Pass the host: rr unlock <host>, or --all to unlock every host.")
+			}
 			selectedHost, err := pickHostForUnlock(globalCfg)
 			if err != nil {
 				return err
@@ -144,9 +148,11 @@ func unlockHost(hostName string, hostCfg config.Host, lockCfg config.LockConfig)
 		fmt.Printf("%s %s: no SSH connections configured\n", ui.SymbolFail, hostName)
 		return unlockResultFailed
 	}
-
-	// Get lock directory path
-	lockDir := lock.LockDir(lockCfg)
+	hostCfg, err := config.ResolveHostSecrets(hostName, hostCfg)
+	if err != nil {
+		fmt.Printf("%s %s: %v\n", ui.SymbolFail, hostName, err)
+		return unlockResultFailed
+	}
 
 	// Try to connect using the first available SSH alias
 	spinner := ui.NewSpinner(fmt.Sprintf("Connecting to %s", hostName))
@@ -155,7 +161,7 @@ func unlockHost(hostName string, hostCfg config.Host, lockCfg config.LockConfig)
 	var conn *host.Connection
 	var connErr error
 	for _, sshAlias := range hostCfg.SSH {
-		client, latency, err := host.ProbeAndConnect(sshAlias, 10*time.Second)
+		client, latency, err := host.Connect(sshAlias, 10*time.Second, hostCfg)
 		if err == nil {
 			conn = &host.Connection{
 				Name:    hostName,
@@ -177,24 +183,30 @@ func unlockHost(hostName string, hostCfg config.Host, lockCfg config.LockConfig)
 	defer conn.Close()
 	spinner.Success()
 
-	// Check if lock exists
-	if !lock.IsLocked(conn, lockCfg) {
+	// Find the project's live locks; with command-scoped locks there can be
+	// several
+	lockDirs := lock.Held(conn, lockCfg)
+	if len(lockDirs) == 0 {
 		fmt.Printf("%s %s: no lock held\n", ui.SymbolPending, hostName)
 		return unlockResultNotLocked
 	}
 
-	// Get lock holder info before releasing
-	holder := lock.GetLockHolder(conn, lockCfg)
+	var holders []string
+	for _, lockDir := range lockDirs {
+		// Get lock holder info before releasing
+		holder := lock.Holder(conn, lockDir)
 
-	// Release the lock
-	err := lock.ForceRelease(conn, lockDir)
-	if err != nil {
-		fmt.Printf("%s %s: failed to release lock: %v\n", ui.SymbolFail, hostName, err)
-		return unlockResultFailed
+		if err := lock.ForceRelease(conn, lockDir); err != nil {
+			fmt.Printf("%s %s: failed to release lock: %v\n", ui.SymbolFail, hostName, err)
+			return unlockResultFailed
+		}
+		if holder != "" && holder != "unknown" {
+			holders = append(holders, holder)
+		}
 	}
 
-	if holder != "" && holder != "unknown" {
-		fmt.Printf("%s %s: lock released (was held by %s)\n", ui.SymbolSuccess, hostName, holder)
+	if len(holders) > 0 {
+		fmt.Printf("%s %s: lock released (was held by %s)\n", ui.SymbolSuccess, hostName, strings.Join(holders, ", "))
 	} else {
 		fmt.Printf("%s %s: lock released\n", ui.SymbolSuccess, hostName)
 	}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/logger"
+	"github.com/rileyhilliard/rr/internal/ui"
+)
+
+// applyVerbosity sets the verbosity from -v and sends verbose output where
+// the output mode expects it: muted lines on stderr in pretty mode, or
+// "verbose" events in structured mode so stderr stays JSON lines.
+func applyVerbosity() {
+	logger.SetVerbosity(verbosity)
+	if verbosity == 0 {
+		return
+	}
+	if PrettyMode() {
+		logger.SetSink(prettyVerboseSink)
+	} else {
+		logger.SetSink(structuredVerboseSink)
+	}
+}
+
+// prettyVerboseSink writes a verbose message as a muted line on stderr.
+func prettyVerboseSink(_ int, subsystem, message string) {
+	style := lipgloss.NewStyle().Foreground(ui.ColorMuted)
+	fmt.Fprintln(os.Stderr, style.Render(fmt.Sprintf("[%s] %s", subsystem, message)))
+}
+
+// structuredVerboseSink writes a verbose message as a "verbose" event.
+func structuredVerboseSink(level int, subsystem, message string) {
+	WritePhaseEvent(PhaseEvent{
+		Type:   "verbose",
+		Phase:  subsystem,
+		Status: "info",
+		Details: map[string]interface{}{
+			"level":   level,
+			"message": message,
+		},
+	})
+}
+
+// verboseToFile sends verbose output to ~/.rr/logs/<name>-verbose.log
+// instead of stderr, for full-screen commands that stderr lines would draw
+// over. Returns the file's path, or "" if verbose output is off or the file
+// couldn't be opened, and a function that puts the sink back and closes it.
+func verboseToFile(name string) (string, func()) {
+	if verbosity == 0 {
+		return "", func() {}
+	}
+	dir := config.ExpandTilde("~/.rr/logs")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", func() {}
+	}
+	path := filepath.Join(dir, name+"-verbose.log")
+	f, err := os.Create(path)
+	if err != nil {
+		return "", func() {}
+	}
+
+	var mu sync.Mutex
+	logger.SetSink(func(_ int, subsystem, message string) {
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprintf(f, "%s [%s] %s\n", time.Now().Format("15:04:05.000"), subsystem, message)
+	})
+	return path, func() {
+		applyVerbosity()
+		f.Close()
+	}
+}
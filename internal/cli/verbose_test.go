@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withVerbosity sets the -v count for a test and resets verbose output after.
+func withVerbosity(t *testing.T, level int, pretty bool) {
+	t.Helper()
+	oldVerbosity, oldPretty := verbosity, prettyMode
+	verbosity, prettyMode = level, pretty
+	t.Cleanup(func() {
+		verbosity, prettyMode = oldVerbosity, oldPretty
+		logger.SetVerbosity(0)
+		logger.SetSink(nil)
+	})
+}
+
+func TestApplyVerbosity_StructuredEvents(t *testing.T) {
+	withVerbosity(t, logger.LevelCommands, false)
+
+	out := captureStderr(t, func() {
+		applyVerbosity()
+		logger.Verbosef(logger.LevelCommands, "rsync", "rsync -az src/ box:~/proj")
+		logger.Verbosef(logger.LevelWire, "ssh", "handshake took 12ms")
+	})
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 1, "-vv doesn't show wire timings")
+
+	var event PhaseEvent
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &event))
+	assert.Equal(t, "verbose", event.Type)
+	assert.Equal(t, "rsync", event.Phase)
+	assert.Equal(t, "rsync -az src/ box:~/proj", event.Details["message"])
+	assert.EqualValues(t, logger.LevelCommands, event.Details["level"])
+}
+
+func TestApplyVerbosity_Off(t *testing.T) {
+	withVerbosity(t, 0, true)
+
+	out := captureStderr(t, func() {
+		applyVerbosity()
+		logger.Verbosef(logger.LevelPhases, "sync", "skipped")
+	})
+	assert.Empty(t, out)
+	assert.False(t, Verbose())
+}
+
+func TestVerboseFlag_Counts(t *testing.T) {
+	withVerbosity(t, 0, true)
+
+	require.NoError(t, rootCmd.PersistentFlags().Parse([]string{"-vvv"}))
+	t.Cleanup(func() { _ = rootCmd.PersistentFlags().Set("verbose", "0") })
+	assert.Equal(t, logger.LevelWire, Verbosity())
+}
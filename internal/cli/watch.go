@@ -0,0 +1,348 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	gosync "sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/exec"
+	rrsync "github.com/rileyhilliard/rr/internal/sync"
+	"github.com/rileyhilliard/rr/internal/ui"
+	"github.com/rileyhilliard/rr/internal/util"
+	"github.com/rileyhilliard/rr/internal/watch"
+	"github.com/spf13/cobra"
+)
+
+// WatchTaskOptions holds options for running a watch task.
+type WatchTaskOptions struct {
+	TaskName     string
+	Args         []string      // Extra arguments to append to the task's command
+	Host         string        // Preferred host name
+	Tag          string        // Filter hosts by tag
+	ProbeTimeout time.Duration // Override SSH probe timeout
+	Local        bool          // If true, run on this machine
+	NoSync       bool          // If true, don't push local changes while running
+	Force        bool          // If true, sync even into a git checkout with uncommitted changes
+}
+
+// createWatchTaskCommand creates a cobra command for a watch task.
+func createWatchTaskCommand(name string, task config.TaskConfig) *cobra.Command {
+	var hostFlag string
+	var tagFlag string
+	var probeTimeoutFlag string
+	var localFlag bool
+	var noSyncFlag bool
+	var forceFlag bool
+
+	cmd := &cobra.Command{
+		Use:   name + " [args...]",
+		Short: task.Description,
+		Long:  buildWatchTaskLongDescription(name, task),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			probeTimeout, err := ParseProbeTimeout(probeTimeoutFlag)
+			if err != nil {
+				return err
+			}
+			exitCode, err := RunWatchTask(WatchTaskOptions{
+				TaskName:     name,
+				Args:         args,
+				Host:         hostFlag,
+				Tag:          tagFlag,
+				ProbeTimeout: probeTimeout,
+				Local:        localFlag,
+				NoSync:       noSyncFlag,
+				Force:        forceFlag,
+			})
+			if err != nil {
+				return err
+			}
+			if exitCode != 0 {
+				return errors.NewExitError(exitCode)
+			}
+			return nil
+		},
+	}
+
+	if cmd.Short == "" {
+		cmd.Short = fmt.Sprintf("Watch the '%s' task", name)
+	}
+
+	cmd.Flags().StringVar(&hostFlag, "host", "", "target host name")
+	cmd.Flags().StringVar(&tagFlag, "tag", "", "select host by tag")
+	cmd.Flags().StringVar(&probeTimeoutFlag, "probe-timeout", "", "SSH probe timeout (e.g., 5s, 2m)")
+	cmd.Flags().BoolVar(&localFlag, "local", false, "run on this machine instead of a remote host")
+	cmd.Flags().BoolVar(&noSyncFlag, "no-sync", false, "don't push local changes while the task runs")
+	cmd.Flags().BoolVar(&forceFlag, "force", false, forceFlagUsage)
+	addNotifyWhenFreeFlag(cmd)
+
+	return cmd
+}
+
+// buildWatchTaskLongDescription creates a detailed description for a watch task command.
+func buildWatchTaskLongDescription(name string, task config.TaskConfig) string {
+	desc := fmt.Sprintf("Run the '%s' watch task defined in .rr.yaml.\n\n", name)
+	if task.Description != "" {
+		desc += task.Description + "\n\n"
+	}
+	desc += fmt.Sprintf("Command: %s\n\n", task.Run)
+	desc += "Runs until Ctrl+C. While it runs, local changes are synced to the host\n"
+	desc += fmt.Sprintf("and the command is restarted when it exits (restart: %s).\n", config.GetTaskRestart(&task))
+	if len(task.Ports) > 0 {
+		desc += fmt.Sprintf("\nForwards ports: %s\n", strings.Join(task.Ports, ", "))
+	}
+	if len(task.Hosts) > 0 {
+		desc += fmt.Sprintf("\nRestricted to hosts: %s\n", util.JoinOrNone(task.Hosts))
+	}
+	return desc
+}
+
+// RunWatchTask runs a watch task until it's interrupted, or until its
+// command exits and the restart policy says not to restart it. The host
+// isn't locked while it runs, since the command never finishes; changes are
+// pushed the way 'rr sync --daemon' pushes them.
+func RunWatchTask(opts WatchTaskOptions) (int, error) {
+	wf, err := SetupWorkflow(WorkflowOptions{
+		Host:         opts.Host,
+		Tag:          opts.Tag,
+		ProbeTimeout: opts.ProbeTimeout,
+		SkipLock:     true,
+		Local:        opts.Local,
+		Command:      opts.TaskName,
+		TaskName:     opts.TaskName,
+		Force:        opts.Force,
+	})
+	if err != nil {
+		return 1, err
+	}
+	defer wf.Close()
+
+	var hostCfg *config.Host
+	if !wf.Conn.IsLocal {
+		if h, ok := wf.Resolved.Global.Hosts[wf.Conn.Name]; ok {
+			hostCfg = &h
+		}
+	}
+	task, mergedEnv, err := config.GetTaskWithMergedEnv(wf.Resolved.Project, opts.TaskName, hostCfg)
+	if err != nil {
+		return 1, err
+	}
+	if !config.IsTaskHostAllowed(task, wf.Conn.Name) {
+		return 1, errors.New(errors.ErrConfig,
+			fmt.Sprintf("Task '%s' can't run on host '%s'", opts.TaskName, wf.Conn.Name),
+			fmt.Sprintf("This task is restricted to: %s", util.JoinOrNone(task.Hosts)))
+	}
+
+	if existing, err := watch.ReadState(wf.WorkDir, opts.TaskName); err == nil && existing.Alive() && existing.PID != os.Getpid() {
+		return 1, errors.New(errors.ErrExec,
+			fmt.Sprintf("'%s' is already being watched on %s (pid %d)", opts.TaskName, existing.Host, existing.PID),
+			"Stop that one first, or use it - 'rr status' shows what's running.")
+	}
+
+	command := task.Run
+	if len(opts.Args) > 0 {
+		command += " " + strings.Join(opts.Args, " ")
+	}
+
+	ctx := wf.Context()
+	w := &taskWatcher{
+		wf:    wf,
+		state: watch.NewState(opts.TaskName, wf.Conn.Name, command, wf.WorkDir, task.Ports),
+	}
+	w.save()
+	defer watch.RemoveState(wf.WorkDir, opts.TaskName) //nolint:errcheck // Best-effort cleanup on exit
+
+	wf.Reporter.Divider()
+	if !wf.Conn.IsLocal {
+		if err := w.forwardPorts(ctx, task.Ports); err != nil {
+			return 1, err
+		}
+		if !opts.NoSync {
+			w.syncContinuously(ctx)
+		}
+	}
+
+	remoteDir := ""
+	if !wf.Conn.IsLocal {
+		remoteDir = config.ExpandRemote(wf.Conn.Host.Dir)
+	}
+	execOpts := &exec.TaskExecOptions{
+		SetupCommands: config.GetMergedSetupCommands(wf.Resolved.Project, hostCfg),
+		ProjectRoot:   wf.WorkDir,
+	}
+
+	exitCode, err := watch.Supervise(ctx, watch.SuperviseOptions{
+		Restart: config.GetTaskRestart(task),
+		Run: func(ctx context.Context) (int, error) {
+			result, err := exec.ExecuteTask(ctx, wf.Conn, task, opts.Args, mergedEnv, remoteDir, os.Stdout, os.Stderr, execOpts)
+			if err != nil {
+				return 1, err
+			}
+			return result.ExitCode, nil
+		},
+		OnStart: w.started,
+		OnExit:  w.exited,
+	})
+
+	if ctx.Err() != nil {
+		return 130, nil
+	}
+	return exitCode, err
+}
+
+// taskWatcher reports a running watch task's progress, both on screen and in
+// its state file.
+type taskWatcher struct {
+	wf *WorkflowContext
+
+	mu    gosync.Mutex
+	state *watch.State
+}
+
+// save writes the state file. Errors are ignored: the state only feeds
+// 'rr status' and 'rr monitor'.
+func (w *taskWatcher) save() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_ = watch.WriteState(w.state)
+}
+
+// update changes the state under the lock and saves it.
+func (w *taskWatcher) update(fn func(s *watch.State)) {
+	w.mu.Lock()
+	fn(w.state)
+	w.mu.Unlock()
+	w.save()
+}
+
+// forwardPorts forwards each of the task's ports from this machine to the
+// host. A port that can't be forwarded stops the task before it starts.
+func (w *taskWatcher) forwardPorts(ctx context.Context, ports []string) error {
+	if len(ports) == 0 {
+		return nil
+	}
+	conn := w.wf.Conn
+	dialer, ok := conn.Client.(watch.Dialer)
+	if !ok {
+		return errors.New(errors.ErrSSH,
+			"This connection can't forward ports",
+			"Port forwarding needs a direct SSH connection to the host.")
+	}
+
+	for _, spec := range ports {
+		local, remote, err := config.ParsePortForward(spec)
+		if err != nil {
+			return errors.WrapWithCode(err, errors.ErrConfig,
+				fmt.Sprintf("Bad port '%s'", spec),
+				"Use 'PORT' or 'LOCAL:REMOTE', like 3000 or 8080:3000.")
+		}
+		if err := watch.Forward(ctx, dialer, local, remote); err != nil {
+			return errors.WrapWithCode(err, errors.ErrExec,
+				fmt.Sprintf("Couldn't forward localhost:%d to %s:%d", local, conn.Name, remote),
+				fmt.Sprintf("Something on this machine is probably using port %d already. Stop it, or forward a different local port with '<local>:%d'.", local, remote))
+		}
+		if PrettyMode() {
+			fmt.Printf("%s Forwarding localhost:%d → %s:%d\n", ui.SymbolSuccess, local, conn.Name, remote)
+		} else {
+			WritePhaseEvent(PhaseEvent{
+				Type:    "phase",
+				Phase:   "watch",
+				Status:  "forwarding",
+				Host:    conn.Name,
+				Details: map[string]interface{}{"local_port": local, "remote_port": remote},
+			})
+		}
+	}
+	return nil
+}
+
+// syncContinuously pushes local changes to the host while the task runs,
+// as 'rr sync --daemon' does. If a sync daemon already runs for the
+// project, it's left to do the pushing.
+func (w *taskWatcher) syncContinuously(ctx context.Context) {
+	wf := w.wf
+	if state, err := rrsync.ReadDaemonState(wf.WorkDir); err == nil && state.Running() {
+		if PrettyMode() {
+			fmt.Printf("%s Changes are pushed by the sync daemon already running for this project (pid %d)\n", ui.SymbolPending, state.PID)
+		}
+		return
+	}
+
+	opts := daemonOptions(wf.Conn, wf.Resolved, wf.WorkDir, wf.GetReporter())
+	w.update(func(s *watch.State) { s.Syncing = true })
+	go func() {
+		err := rrsync.RunDaemon(ctx, opts)
+		w.update(func(s *watch.State) { s.Syncing = false })
+		if err != nil && ctx.Err() == nil {
+			w.wf.GetReporter().PhaseFailed("sync", err)
+		}
+	}()
+}
+
+// started records that the command is starting.
+func (w *taskWatcher) started(restarts int) {
+	var command string
+	w.update(func(s *watch.State) {
+		s.Running = true
+		s.Restarts = restarts
+		s.Since = time.Now()
+		command = s.Command
+	})
+
+	if !PrettyMode() {
+		w.wf.Reporter.CommandPrompt(command)
+		return
+	}
+	mutedStyle := lipgloss.NewStyle().Foreground(ui.ColorMuted)
+	if restarts == 0 {
+		fmt.Printf("%s Watching %s on %s %s\n\n", ui.SymbolPending, command, w.wf.Conn.Name, mutedStyle.Render("(Ctrl+C to stop)"))
+	} else {
+		fmt.Printf("\n%s Restarting %s %s\n\n", ui.SymbolPending, command, mutedStyle.Render(fmt.Sprintf("(restart %d)", restarts)))
+	}
+}
+
+// exited records that the command exited, and whether it's restarted.
+func (w *taskWatcher) exited(exitCode int, restartIn time.Duration) {
+	code := exitCode
+	w.update(func(s *watch.State) {
+		s.Running = false
+		s.LastExit = &code
+		s.Since = time.Now()
+	})
+	if w.wf.Context().Err() != nil {
+		return
+	}
+
+	if !PrettyMode() {
+		status := "exited"
+		details := map[string]interface{}{}
+		if restartIn > 0 {
+			status = "restarting"
+			details["restart_in_s"] = restartIn.Seconds()
+		}
+		WritePhaseEvent(PhaseEvent{
+			Type:     "phase",
+			Phase:    "watch",
+			Status:   status,
+			Host:     w.wf.Conn.Name,
+			ExitCode: &code,
+			Details:  details,
+		})
+		return
+	}
+
+	symbol, color := ui.SymbolFail, ui.ColorError
+	if exitCode == 0 {
+		symbol, color = ui.SymbolSuccess, ui.ColorSuccess
+	}
+	msg := fmt.Sprintf("%s Exited with code %d", symbol, exitCode)
+	if restartIn > 0 {
+		msg += fmt.Sprintf(", restarting in %s", restartIn)
+	}
+	fmt.Printf("\n%s\n", lipgloss.NewStyle().Foreground(color).Render(msg))
+}
@@ -3,20 +3,25 @@ package cli
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
 	"github.com/rileyhilliard/rr/internal/config"
 	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/history"
 	"github.com/rileyhilliard/rr/internal/host"
 	"github.com/rileyhilliard/rr/internal/lock"
+	"github.com/rileyhilliard/rr/internal/logger"
 	"github.com/rileyhilliard/rr/internal/require"
+	"github.com/rileyhilliard/rr/internal/results"
 	rrsync "github.com/rileyhilliard/rr/internal/sync"
 	"github.com/rileyhilliard/rr/internal/ui"
-	"golang.org/x/term"
 )
 
 // WorkflowOptions configures workflow setup behavior.
@@ -32,6 +37,10 @@ type WorkflowOptions struct {
 	Local            bool          // Force local execution (skip remote hosts)
 	Command          string        // Command being run (stored in lock for monitoring)
 	TaskName         string        // Task name for task-specific requirements
+	Force            bool          // Sync even into a git checkout with uncommitted changes
+	Rebootstrap      bool          // Run the project's bootstrap script even if it already ran on the host
+	StaleLock        string        // Lock an interrupted run left on the host, released before taking it (see lock.ReleaseAbandoned)
+	StalePID         int           // The PID of the interrupted run that took StaleLock
 }
 
 // WorkflowContext holds state from workflow setup for use during execution.
@@ -39,23 +48,41 @@ type WorkflowContext struct {
 	Resolved     *config.ResolvedConfig
 	Conn         *host.Connection
 	Lock         *lock.Lock
+	Reservation  *lock.Reservation // The task's claim on the host's CPUs and memory (nil if it reserves none)
 	WorkDir      string
 	PhaseDisplay *ui.PhaseDisplay
 	Reporter     PhaseReporter
 	StartTime    time.Time
+	Phases       map[string]time.Duration // How long each completed phase took, for the run summary
+	Steps        map[string]time.Duration // How long each passing step of a multi-step task took, for history
+	Output       *history.OutputTail      // Tail of the command's output, saved for 'rr report' (nil if not captured)
+	Cast         *history.CastRecorder    // Timed recording of the command's output, for 'rr replay' (nil if not captured)
+	Pulled       []results.Artifact       // Files pulled back after the command, for 'rr results'
 
 	// Internal state
 	selector   *host.Selector
 	signalChan chan os.Signal
+	push       []string            // Task push paths; the sync sends only these
+	profile    string              // Task sync profile (empty for the sync section's own settings)
+	clockSkew  time.Duration       // How far the host's clock is ahead of this one, if far enough to matter
+	depTasks   []results.Task      // Each task of a run with dependencies, for 'rr results'
+	checkpoint *history.Checkpoint // How far the task got, for 'rr resume' (nil if not saved)
+	mirror     *mirrorRun          // The sync being copied to the project's mirror_to host (nil if none)
 	ctx        context.Context
 	cancel     context.CancelFunc
 	closeOnce  sync.Once
 }
 
+// cancelCleanupTimeout bounds how long an interrupted run gets to stop its
+// remote command and clean up (on_cancel, pulls) before rr quits anyway.
+const cancelCleanupTimeout = 2 * time.Minute
+
 // setupSignalHandler registers interrupt handlers to ensure cleanup on Ctrl+C.
-// Instead of calling os.Exit, it cancels the workflow context so in-flight
-// commands (like remote SSH sessions) can send SIGINT to the remote process
-// before the connection is torn down.
+// Instead of calling os.Exit, it cancels the workflow context. The run then
+// stops the remote command's whole process group, runs the task's on_cancel
+// command and pulls, and releases the lock through Close on its way out, with
+// the connection still up for all of it. A second signal, or a cleanup that
+// takes longer than cancelCleanupTimeout, releases the lock and quits.
 func (w *WorkflowContext) setupSignalHandler() {
 	w.ctx, w.cancel = context.WithCancel(context.Background())
 	w.signalChan = make(chan os.Signal, 2)
@@ -69,17 +96,21 @@ func (w *WorkflowContext) setupSignalHandler() {
 		}
 		// Cancel context first so in-flight SSH commands can clean up
 		w.cancel()
+		if PrettyMode() {
+			fmt.Fprintf(os.Stderr, "\n%s Interrupted, stopping the remote command and cleaning up (Ctrl+C again to quit now)\n", ui.SymbolWarning)
+		}
 
-		// Second signal force-quits immediately (users expect double Ctrl+C to kill)
-		go func() {
-			_, ok := <-w.signalChan
+		select {
+		case _, ok := <-w.signalChan:
 			if !ok {
+				// The run cleaned up and closed the workflow
 				return
 			}
-			os.Exit(130)
-		}()
-
+			// Second signal force-quits (users expect double Ctrl+C to kill)
+		case <-time.After(cancelCleanupTimeout):
+		}
 		w.Close()
+		os.Exit(130)
 	}()
 }
 
@@ -96,6 +127,42 @@ func (w *WorkflowContext) GetReporter() PhaseReporter {
 	return w.Reporter
 }
 
+// recordPhase stores how long a phase took, for the run summary and history,
+// and tells the hook plugins it completed.
+func (w *WorkflowContext) recordPhase(phase string, d time.Duration) {
+	if w.Phases == nil {
+		w.Phases = make(map[string]time.Duration)
+	}
+	w.Phases[phase] = d
+
+	event := PhaseEvent{Type: "phase", Phase: phase, Status: "complete", Duration: d.Seconds()}
+	if w.Conn != nil {
+		event.Host = w.Conn.Name
+	}
+	runHooks(event)
+}
+
+// captureOutput starts capturing the command's output for 'rr report' and
+// 'rr replay', and returns writers that tee stdout and stderr into it.
+// Both streams go to the same recording, in the order they arrive, as they
+// would on a terminal. With lock.share_output on, they're also shared with
+// runs waiting on the lock.
+func (w *WorkflowContext) captureOutput(stdout, stderr io.Writer) (io.Writer, io.Writer) {
+	w.Output = history.NewOutputTail()
+	w.Cast = history.NewCastRecorder()
+	captures := []io.Writer{w.Output, w.Cast}
+
+	lockCfg := config.DefaultConfig().Lock
+	if w.Resolved != nil && w.Resolved.Project != nil {
+		lockCfg = w.Resolved.Project.Lock
+	}
+	if w.Lock != nil && lockCfg.ShareOutput {
+		captures = append(captures, w.Lock.OutputWriter())
+	}
+	return io.MultiWriter(append([]io.Writer{stdout}, captures...)...),
+		io.MultiWriter(append([]io.Writer{stderr}, captures...)...)
+}
+
 // Context returns the workflow's cancellable context. This context is cancelled
 // when the user sends SIGINT/SIGTERM, allowing callers to propagate cancellation
 // to remote commands.
@@ -109,6 +176,10 @@ func (w *WorkflowContext) Context() context.Context {
 // Close releases workflow resources. Safe to call multiple times.
 func (w *WorkflowContext) Close() {
 	w.closeOnce.Do(func() {
+		// Let the mirror finish, unless the run was interrupted
+		if w.ctx == nil || w.ctx.Err() == nil {
+			reportMirror(w.mirror)
+		}
 		// Cancel context to signal in-flight operations
 		if w.cancel != nil {
 			w.cancel()
@@ -118,6 +189,9 @@ func (w *WorkflowContext) Close() {
 			signal.Stop(w.signalChan)
 			close(w.signalChan)
 		}
+		if w.Reservation != nil {
+			w.Reservation.Release() //nolint:errcheck // Stale reservations expire on their own
+		}
 		if w.Lock != nil {
 			w.Lock.Release() //nolint:errcheck // Lock release errors are non-fatal
 		}
@@ -129,7 +203,7 @@ func (w *WorkflowContext) Close() {
 
 // loadAndValidateConfig loads and validates both global and project config.
 func loadAndValidateConfig(ctx *WorkflowContext) error {
-	resolved, err := config.LoadResolved(Config())
+	resolved, err := loadResolved(Config())
 	if err != nil {
 		return err
 	}
@@ -186,6 +260,9 @@ func setupHostSelector(ctx *WorkflowContext, opts WorkflowOptions) {
 		// Fall back to all global hosts if resolution fails
 		ctx.selector = host.NewSelector(ctx.Resolved.Global.Hosts)
 	} else {
+		if task := workflowTask(ctx, opts); task != nil && opts.Host == "" {
+			hostOrder, projectHosts = restrictToTaskHosts(hostOrder, projectHosts, task.Hosts)
+		}
 		ctx.selector = host.NewSelector(projectHosts)
 		ctx.selector.SetHostOrder(hostOrder)
 	}
@@ -200,9 +277,33 @@ func setupHostSelector(ctx *WorkflowContext, opts WorkflowOptions) {
 	}
 }
 
-// selectHostInteractively shows a host picker if needed.
+// restrictToTaskHosts narrows the hosts to a task's hosts list, keeping
+// their order, so a task restricted to some hosts is sent to one of them.
+// The hosts are left alone when the task isn't restricted or none of its
+// hosts are available to the project.
+func restrictToTaskHosts(hostOrder []string, hosts map[string]config.Host, taskHosts []string) ([]string, map[string]config.Host) {
+	if len(taskHosts) == 0 {
+		return hostOrder, hosts
+	}
+	var order []string
+	allowed := make(map[string]config.Host)
+	for _, name := range hostOrder {
+		if slices.Contains(taskHosts, name) {
+			order = append(order, name)
+			allowed[name] = hosts[name]
+		}
+	}
+	if len(order) == 0 {
+		return hostOrder, hosts
+	}
+	return order, allowed
+}
+
+// selectHostInteractively shows a host picker if needed. Without one (no
+// terminal, or --non-interactive) rr picks the host itself, as it does with
+// load balancing.
 func selectHostInteractively(ctx *WorkflowContext, preferredHost string, quiet bool) (string, error) {
-	if preferredHost != "" || ctx.selector.HostCount() <= 1 || quiet || !term.IsTerminal(int(os.Stdin.Fd())) {
+	if preferredHost != "" || ctx.selector.HostCount() <= 1 || quiet || !canPrompt() {
 		return preferredHost, nil
 	}
 
@@ -218,7 +319,8 @@ func selectHostInteractively(ctx *WorkflowContext, preferredHost string, quiet b
 		}
 	}
 
-	selected, err := ui.PickHost(uiHosts)
+	// Probe the hosts while the picker is up, so it can show which are reachable
+	selected, err := ui.PickHostWithProbe(uiHosts, ctx.selector.Probe)
 	if err != nil {
 		return "", errors.WrapWithCode(err, errors.ErrExec, "Host selection failed", "Try again or use --host flag")
 	}
@@ -241,10 +343,18 @@ func connectPhase(ctx *WorkflowContext, opts WorkflowOptions) error {
 		}
 	}
 
+	var err error
 	if PrettyMode() {
-		return connectPhasePretty(ctx, opts, preferredHost, connectStart)
+		err = connectPhasePretty(ctx, opts, preferredHost, connectStart)
+	} else {
+		err = connectPhaseStructured(ctx, opts, preferredHost, connectStart)
 	}
-	return connectPhaseStructured(ctx, opts, preferredHost, connectStart)
+	if err == nil {
+		ctx.recordPhase("connect", time.Since(connectStart))
+		ctx.clockSkew = checkClockSkew(ctx.Conn)
+		detectLowBandwidth(ctx.Conn)
+	}
+	return err
 }
 
 func connectPhasePretty(ctx *WorkflowContext, opts WorkflowOptions, preferredHost string, _ time.Time) error {
@@ -327,16 +437,67 @@ func syncPhase(ctx *WorkflowContext, opts WorkflowOptions) error {
 		reporter.PhaseSkipped("sync", "skipped")
 		return nil
 	}
+	if task := workflowTask(ctx, opts); task != nil {
+		if len(task.Push) > 0 {
+			if err := rrsync.CheckPushPaths(ctx.WorkDir, task.Push); err != nil {
+				return err
+			}
+			ctx.push = task.Push
+		}
+		ctx.profile = task.SyncProfile
+	}
+	// A running 'rr sync --daemon' has already pushed everything to this host.
+	// It syncs the project with the sync section's own settings, not the
+	// build outputs a push sends or a sync profile.
+	if len(ctx.push) == 0 && ctx.profile == "" && rrsync.DaemonIsCurrent(ctx.WorkDir, ctx.Conn.Name, config.ExpandRemote(ctx.Conn.Host.Dir)) {
+		logger.Verbosef(logger.LevelPhases, "sync", "skipped: 'rr sync --daemon' already pushed everything to %s", ctx.Conn.Name)
+		reporter.PhaseSkipped("sync", "daemon")
+		return nil
+	}
+	if ctx.profile != "" {
+		logger.Verbosef(logger.LevelPhases, "sync", "using sync profile '%s'", ctx.profile)
+	}
+	if len(ctx.push) > 0 {
+		logger.Verbosef(logger.LevelPhases, "sync", "pushing only %s", strings.Join(ctx.push, ", "))
+	}
+
+	// Don't overwrite edits made directly in a git checkout on the host
+	if !opts.Force {
+		if err := rrsync.CheckRemoteEdits(ctx.Conn, ctx.WorkDir, projectSyncConfig(ctx)); err != nil {
+			if !PrettyMode() {
+				reporter.PhaseFailed("sync", err)
+			}
+			return err
+		}
+	}
 
 	syncStart := time.Now()
 
-	if !PrettyMode() {
-		return syncStructured(ctx, syncStart)
-	}
-	if !opts.Quiet {
-		return syncWithProgress(ctx, syncStart)
+	var err error
+	switch {
+	case !PrettyMode():
+		err = syncStructured(ctx, syncStart)
+	case !opts.Quiet && !config.LowBandwidth():
+		// Low-bandwidth mode skips the progress bar and the prescan it measures against
+		err = syncWithProgress(ctx, syncStart)
+	default:
+		err = syncQuiet(ctx, syncStart)
+	}
+	if err == nil {
+		ctx.recordPhase("sync", time.Since(syncStart))
+		ctx.mirror = startMirror(ctx.Resolved, ctx.Conn.Name, ctx.WorkDir, mirrorSyncConfig(ctx), opts.Force)
+	}
+	return err
+}
+
+// mirrorSyncConfig is resolveSyncConfig without the primary's clock skew,
+// which the mirror measures for itself.
+func mirrorSyncConfig(ctx *WorkflowContext) config.SyncConfig {
+	cfg := projectSyncConfig(ctx)
+	if len(ctx.push) > 0 {
+		return rrsync.PushConfig(cfg, ctx.push)
 	}
-	return syncQuiet(ctx, syncStart)
+	return cfg
 }
 
 // syncStructured syncs files without UI and emits structured events.
@@ -362,6 +523,23 @@ func syncStructured(ctx *WorkflowContext, syncStart time.Time) error {
 		return err
 	}
 
+	if rrsync.SyncInterrupted(ctx.WorkDir, ctx.Conn.Name) {
+		WritePhaseEvent(PhaseEvent{
+			Type:    "phase",
+			Phase:   "sync",
+			Status:  "resuming",
+			Details: map[string]interface{}{"host": ctx.Conn.Name},
+		})
+	}
+	if len(ctx.push) > 0 {
+		WritePhaseEvent(PhaseEvent{
+			Type:    "phase",
+			Phase:   "sync",
+			Status:  "push",
+			Details: map[string]interface{}{"paths": ctx.push},
+		})
+	}
+
 	err := rrsync.Sync(ctx.Conn, ctx.WorkDir, syncCfg, nil)
 	if err != nil {
 		reporter.PhaseFailed("sync", err)
@@ -372,14 +550,43 @@ func syncStructured(ctx *WorkflowContext, syncStart time.Time) error {
 	return nil
 }
 
-// resolveSyncConfig returns the sync config to use, falling back to defaults.
-func resolveSyncConfig(ctx *WorkflowContext) config.SyncConfig {
+// projectSyncConfig returns the project's sync config with the task's sync
+// profile applied, falling back to defaults.
+func projectSyncConfig(ctx *WorkflowContext) config.SyncConfig {
 	if ctx.Resolved.Project != nil {
-		return ctx.Resolved.Project.Sync
+		// Validation already checked the profile exists
+		cfg, _ := ctx.Resolved.Project.Sync.WithProfile(ctx.profile)
+		return cfg
 	}
 	return config.DefaultConfig().Sync
 }
 
+// resolveSyncConfig returns the sync config to use, falling back to defaults.
+// A task with push paths syncs only those. A host whose clock is far off gets
+// --checksum.
+func resolveSyncConfig(ctx *WorkflowContext) config.SyncConfig {
+	cfg := rrsync.ChecksumForSkew(projectSyncConfig(ctx), ctx.clockSkew)
+	if rrsync.ExceedsClockSkew(ctx.clockSkew, rrsync.ClockSkewChecksum) {
+		logger.Verbosef(logger.LevelPhases, "sync", "comparing file contents (--checksum): %s's clock is %s", ctx.Conn.Name, rrsync.DescribeClockSkew(ctx.clockSkew))
+	}
+	if len(ctx.push) > 0 {
+		return rrsync.PushConfig(cfg, ctx.push)
+	}
+	return cfg
+}
+
+// syncLabels returns the progress label and success message for a sync,
+// calling out when it picks up where an interrupted one left off.
+func syncLabels(ctx *WorkflowContext) (label, done string) {
+	if rrsync.SyncInterrupted(ctx.WorkDir, ctx.Conn.Name) {
+		return "Resuming interrupted sync", "Files synced (resumed)"
+	}
+	if len(ctx.push) > 0 {
+		return "Pushing " + strings.Join(ctx.push, ", "), "Files pushed"
+	}
+	return "Syncing files", "Files synced"
+}
+
 // syncWithProgress syncs files with progress bar display.
 func syncWithProgress(ctx *WorkflowContext, syncStart time.Time) error {
 	syncCfg := resolveSyncConfig(ctx)
@@ -390,11 +597,20 @@ func syncWithProgress(ctx *WorkflowContext, syncStart time.Time) error {
 		return err
 	}
 
-	syncProgress := ui.NewInlineProgress("Syncing files", os.Stdout)
+	label, done := syncLabels(ctx)
+	syncProgress := ui.NewInlineProgress(label, os.Stdout)
 	syncProgress.SetUseFakeProgress(false) // Use real rsync progress
 	progressWriter := ui.NewProgressWriter(syncProgress, nil)
 	syncProgress.Start()
 
+	// Count what the sync will move first, so the bars measure against real
+	// totals. Without them the sync shows rsync's own percentage.
+	if syncCfg.Prescan {
+		if totals, err := rrsync.Scan(ctx.Conn, ctx.WorkDir, syncCfg); err == nil {
+			syncProgress.SetTotals(totals.Files, totals.Bytes)
+		}
+	}
+
 	err := rrsync.Sync(ctx.Conn, ctx.WorkDir, syncCfg, progressWriter)
 	if err != nil {
 		syncProgress.Fail()
@@ -402,7 +618,7 @@ func syncWithProgress(ctx *WorkflowContext, syncStart time.Time) error {
 	}
 
 	syncProgress.Success()
-	ctx.PhaseDisplay.RenderSuccess("Files synced", time.Since(syncStart))
+	ctx.PhaseDisplay.RenderSuccess(done, time.Since(syncStart))
 	return nil
 }
 
@@ -416,7 +632,8 @@ func syncQuiet(ctx *WorkflowContext, syncStart time.Time) error {
 		return err
 	}
 
-	syncSpinner := ui.NewSpinner("Syncing files")
+	label, done := syncLabels(ctx)
+	syncSpinner := ui.NewSpinner(label)
 	syncSpinner.Start()
 
 	err := rrsync.Sync(ctx.Conn, ctx.WorkDir, syncCfg, nil)
@@ -426,7 +643,7 @@ func syncQuiet(ctx *WorkflowContext, syncStart time.Time) error {
 	}
 
 	syncSpinner.Success()
-	ctx.PhaseDisplay.RenderSuccess("Files synced", time.Since(syncStart))
+	ctx.PhaseDisplay.RenderSuccess(done, time.Since(syncStart))
 	return nil
 }
 
@@ -443,12 +660,41 @@ func lockPhase(ctx *WorkflowContext, opts WorkflowOptions) error {
 
 	lockStart := time.Now()
 
+	// A run that died holding the lock would otherwise keep us waiting on it
+	if opts.StaleLock != "" {
+		if released, err := lock.ReleaseAbandoned(ctx.Conn, opts.StaleLock, opts.StalePID); err == nil && released {
+			logger.Verbosef(logger.LevelPhases, "lock", "released the lock the interrupted run left at %s", opts.StaleLock)
+		}
+	}
+
+	if notifyWhenFree && lock.IsLocked(ctx.Conn, lockCfg, opts.Command) {
+		var holders []string
+		if info := lock.GetLockInfo(ctx.Conn, lockCfg, opts.Command); info != nil {
+			holders = append(holders, info.Badge())
+		}
+		watch := lockWatch{host: ctx.Conn.Name, dir: lock.Dir(lockCfg, ctx.Conn, opts.Command)}
+		return watchLocksInBackground([]lockWatch{watch}, lockCfg.Stale, holders)
+	}
+
 	if PrettyMode() {
 		lockSpinner := ui.NewSpinner("Acquiring lock")
 		lockSpinner.Start()
 
+		// Accessible output can't redraw the spinner, so it gets a line
+		// each time a different run holds the lock
+		lastHolder := ""
+		showHolder := func(wait lock.Wait) {
+			label := lockWaitLabel(wait)
+			lockSpinner.SetLabel(label)
+			lockSpinner.SetDetail(wait.LastOutput)
+			if ui.Accessible() && wait.Holder != nil && wait.Holder.String() != lastHolder {
+				lastHolder = wait.Holder.String()
+				fmt.Println(label)
+			}
+		}
+
 		var err error
-		ctx.Lock, err = lock.Acquire(ctx.Conn, lockCfg, opts.Command)
+		ctx.Lock, err = lock.Acquire(ctx.Conn, lockCfg, opts.Command, lock.WithWaitFunc(showHolder))
 		if err != nil {
 			lockSpinner.Fail()
 			return err
@@ -457,6 +703,7 @@ func lockPhase(ctx *WorkflowContext, opts WorkflowOptions) error {
 		ctx.Lock.StartHeartbeat()
 		lockSpinner.Success()
 		ctx.PhaseDisplay.RenderSuccess("Lock acquired", time.Since(lockStart))
+		ctx.recordPhase("lock", time.Since(lockStart))
 		return nil
 	}
 
@@ -472,8 +719,32 @@ func lockPhase(ctx *WorkflowContext, opts WorkflowOptions) error {
 		})
 	}
 
+	// An event each time a different run holds the lock, or the holder's
+	// output moves on
+	lastHolder, lastOutput := "", ""
+	waitEvent := func(wait lock.Wait) {
+		if wait.Holder == nil || (wait.Holder.String() == lastHolder && wait.LastOutput == lastOutput) {
+			return
+		}
+		lastHolder, lastOutput = wait.Holder.String(), wait.LastOutput
+		details := map[string]interface{}{
+			"holder":            wait.Holder,
+			"message":           wait.Holder.Badge(),
+			"remaining_seconds": int(wait.Remaining.Seconds()),
+		}
+		if wait.LastOutput != "" {
+			details["last_output"] = wait.LastOutput
+		}
+		WritePhaseEvent(PhaseEvent{
+			Type:    "phase",
+			Phase:   "lock",
+			Status:  "waiting",
+			Details: details,
+		})
+	}
+
 	var err error
-	ctx.Lock, err = lock.Acquire(ctx.Conn, lockCfg, opts.Command, lock.WithWarnFunc(stealWarn))
+	ctx.Lock, err = lock.Acquire(ctx.Conn, lockCfg, opts.Command, lock.WithWarnFunc(stealWarn), lock.WithWaitFunc(waitEvent))
 	if err != nil {
 		reporter.PhaseFailed("lock", err)
 		return err
@@ -481,9 +752,36 @@ func lockPhase(ctx *WorkflowContext, opts WorkflowOptions) error {
 
 	ctx.Lock.StartHeartbeat()
 	reporter.PhaseComplete("lock", ctx.Conn.Name, time.Since(lockStart))
+	ctx.recordPhase("lock", time.Since(lockStart))
 	return nil
 }
 
+// lockWaitLabel is the lock spinner's label while another run holds the
+// lock: "Waiting for lock: alice is running pytest on project app, 12m
+// elapsed - gives up in 4m10s".
+func lockWaitLabel(wait lock.Wait) string {
+	label := "Waiting for lock"
+	if wait.Holder != nil {
+		label += ": " + wait.Holder.Badge()
+	}
+	return withCountdown(label, wait.Remaining)
+}
+
+// withCountdown adds how long rr keeps waiting to a wait label, counting
+// down to the second: "- gives up in 4m10s". Nothing is added once it's up.
+func withCountdown(label string, remaining time.Duration) string {
+	remaining = remaining.Round(time.Second)
+	switch {
+	case remaining <= 0:
+		return label
+	case remaining < time.Minute:
+		return fmt.Sprintf("%s - gives up in %ds", label, int(remaining.Seconds()))
+	case remaining < time.Hour:
+		return fmt.Sprintf("%s - gives up in %dm%02ds", label, int(remaining.Minutes()), int(remaining.Seconds())%60)
+	}
+	return label + " - gives up in " + lock.FormatElapsed(remaining)
+}
+
 // SetupWorkflow performs the common workflow phases: load config, connect, lock, and sync.
 // Returns a WorkflowContext that the caller uses for execution, and must Close() when done.
 //
@@ -521,6 +819,12 @@ func SetupWorkflow(opts WorkflowOptions) (*WorkflowContext, error) {
 		return nil, err
 	}
 
+	// Run the task's local build before taking up a host
+	if err := buildPhase(ctx, opts); err != nil {
+		ctx.Close()
+		return nil, err
+	}
+
 	// Create host selector
 	setupHostSelector(ctx, opts)
 
@@ -530,10 +834,14 @@ func SetupWorkflow(opts WorkflowOptions) (*WorkflowContext, error) {
 
 	if useLoadBalancing {
 		// Multi-host: use load-balanced workflow (Connect + Lock combined, then Sync)
+		connectStart := time.Now()
 		if err := setupWorkflowLoadBalanced(ctx, opts); err != nil {
 			ctx.Close()
 			return nil, err
 		}
+		ctx.recordPhase("connect", time.Since(connectStart))
+		ctx.clockSkew = checkClockSkew(ctx.Conn)
+		detectLowBandwidth(ctx.Conn)
 	} else {
 		// Single host or explicit host/tag: use original workflow order
 		// Phase 1: Connect
@@ -549,6 +857,12 @@ func SetupWorkflow(opts WorkflowOptions) (*WorkflowContext, error) {
 		}
 	}
 
+	// Reserve the task's share of the host, once it's ours to use
+	if err := reservePhase(ctx, opts); err != nil {
+		ctx.Close()
+		return nil, err
+	}
+
 	// Phase 3: Check requirements (before sync)
 	if err := requirementsPhase(ctx, opts); err != nil {
 		ctx.Close()
@@ -561,6 +875,12 @@ func SetupWorkflow(opts WorkflowOptions) (*WorkflowContext, error) {
 		return nil, err
 	}
 
+	// Phase 5: Bootstrap the project on hosts that haven't seen it yet
+	if err := bootstrapPhase(ctx, opts); err != nil {
+		ctx.Close()
+		return nil, err
+	}
+
 	return ctx, nil
 }
 
@@ -573,9 +893,11 @@ func ExecutePullPhase(wf *WorkflowContext, pullItems []config.PullItem, dest str
 	}
 
 	pullStart := time.Now()
+	var warnings []string
 	pullOpts := rrsync.PullOptions{
 		Patterns:    pullItems,
 		DefaultDest: dest,
+		Warn:        func(msg string) { warnings = append(warnings, msg) },
 	}
 
 	if !PrettyMode() {
@@ -586,7 +908,10 @@ func ExecutePullPhase(wf *WorkflowContext, pullItems []config.PullItem, dest str
 			reporter.PhaseFailed("pull", pullErr)
 		} else {
 			reporter.PhaseComplete("pull", wf.Conn.Name, time.Since(pullStart))
+			wf.recordPhase("pull", time.Since(pullStart))
+			wf.recordPulled(pullItems, dest)
 		}
+		showPullWarnings(warnings)
 		return
 	}
 
@@ -600,6 +925,31 @@ func ExecutePullPhase(wf *WorkflowContext, pullItems []config.PullItem, dest str
 	} else {
 		spinner.Success()
 		wf.PhaseDisplay.RenderSuccess("Files pulled", time.Since(pullStart))
+		wf.recordPhase("pull", time.Since(pullStart))
+		wf.recordPulled(pullItems, dest)
+	}
+	showPullWarnings(warnings)
+}
+
+// showPullWarnings prints the warnings a pull gave once its spinner is gone.
+// They go to stderr, clear of structured output.
+func showPullWarnings(warnings []string) {
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "%s %s\n", ui.SymbolWarning, w)
+	}
+}
+
+// recordPulled notes what a pull brought back, as artifacts of the run.
+func (w *WorkflowContext) recordPulled(items []config.PullItem, dest string) {
+	for _, item := range items {
+		path := item.Dest
+		if path == "" {
+			path = dest
+		}
+		if path == "" {
+			path = "."
+		}
+		w.Pulled = append(w.Pulled, results.Artifact{Kind: results.ArtifactPull, Src: item.Src, Path: path})
 	}
 }
 
@@ -647,5 +997,6 @@ func requirementsPhase(ctx *WorkflowContext, opts WorkflowOptions) error {
 	missingStr := require.FormatMissing(missing)
 	return errors.New(errors.ErrExec,
 		"Missing required tools: "+missingStr,
-		"Run 'rr provision' to install missing tools, or use --skip-requirements to bypass.")
+		"Run 'rr provision' to install missing tools, or use --skip-requirements to bypass.").
+		WithID(errors.IDExecMissingTools)
 }
@@ -1681,6 +1681,23 @@ func TestWorkflowContext_SetupSignalHandler(t *testing.T) {
 	ctx.Close()
 }
 
+func TestWorkflowContext_SignalCancelsContext(t *testing.T) {
+	ctx := &WorkflowContext{}
+	ctx.setupSignalHandler()
+
+	ctx.signalChan <- os.Interrupt
+
+	select {
+	case <-ctx.Context().Done():
+	case <-time.After(time.Second):
+		t.Fatal("context wasn't canceled by the signal")
+	}
+
+	// The run cleans up and closes the workflow itself, which ends the
+	// handler instead of quitting the process
+	ctx.Close()
+}
+
 func TestWorkflowContext_Close_StopsSignalHandler(t *testing.T) {
 	ctx := &WorkflowContext{}
 	ctx.setupSignalHandler()
@@ -1791,3 +1808,28 @@ func TestExecutePullPhase_NilConnection(t *testing.T) {
 	// Nil connection should be handled gracefully
 	ExecutePullPhase(ctx, []config.PullItem{{Src: "file.txt"}}, "")
 }
+
+func TestLockWaitLabel(t *testing.T) {
+	assert.Equal(t, "Waiting for lock", lockWaitLabel(lock.Wait{}))
+	assert.Equal(t, "Waiting for lock - gives up in 40s", lockWaitLabel(lock.Wait{Remaining: 40 * time.Second}))
+
+	holder := &lock.LockInfo{User: "alice", Command: "pytest", Project: "app", Started: time.Now().Add(-12 * time.Minute)}
+	assert.Equal(t, "Waiting for lock: alice is running pytest on project app, 12m elapsed - gives up in 4m05s",
+		lockWaitLabel(lock.Wait{Holder: holder, Remaining: 4*time.Minute + 5*time.Second}))
+}
+
+func TestWithCountdown(t *testing.T) {
+	assert.Equal(t, "Waiting", withCountdown("Waiting", 0))
+	assert.Equal(t, "Waiting", withCountdown("Waiting", -time.Second))
+	assert.Equal(t, "Waiting - gives up in 9s", withCountdown("Waiting", 9*time.Second))
+	assert.Equal(t, "Waiting - gives up in 1m00s", withCountdown("Waiting", time.Minute))
+	assert.Equal(t, "Waiting - gives up in 1h30m", withCountdown("Waiting", 90*time.Minute))
+}
+
+func TestHostWaitLabel(t *testing.T) {
+	holder := &lock.LockInfo{User: "alice", Command: "pytest", Started: time.Now().Add(-3 * time.Minute)}
+
+	assert.Equal(t, "Waiting for available host", hostWaitLabel([]hostAttempt{{hostName: "mini"}}))
+	assert.Equal(t, "Waiting for available host (gpu-box: alice is running pytest, 3m elapsed, +1 more busy)",
+		hostWaitLabel([]hostAttempt{{hostName: "mini"}, {hostName: "gpu-box", lockInfo: holder}}))
+}
@@ -0,0 +1,246 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/util"
+)
+
+const (
+	// BackupsDir is the directory under ~/.rr/ that holds config backups.
+	BackupsDir = "backups"
+
+	// backupIndexFile lists the backups, oldest first.
+	backupIndexFile = "index.json"
+
+	// maxBackups is how many backups are kept. Older ones are deleted.
+	maxBackups = 50
+)
+
+// Change is a rewrite of a config file.
+type Change struct {
+	Path   string
+	Before []byte
+	After  []byte
+
+	// Created is true when the file didn't exist before the write, and
+	// Removed when the change deleted it.
+	Created bool
+	Removed bool
+}
+
+// Backup records a config file as it was before rr rewrote it.
+type Backup struct {
+	// Path is the config file that was rewritten.
+	Path string `json:"path"`
+
+	// File is the backup's file name under ~/.rr/backups, empty when the
+	// rewrite created the file.
+	File string `json:"file,omitempty"`
+
+	// Written is the SHA-256 of what rr wrote, so undo can tell whether the
+	// file was edited since.
+	Written string `json:"written"`
+
+	Time time.Time `json:"time"`
+}
+
+// OnWrite, when set, is called after rr rewrites a config file with
+// different contents. The CLI uses it to show the diff.
+var OnWrite func(Change)
+
+// backupsPath returns the path to ~/.rr/backups.
+func backupsPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, GlobalConfigDir, BackupsDir), nil
+}
+
+// trackWrite runs write, which rewrites the config file at path, and then
+// backs up what the file held before and reports the change to OnWrite.
+// A write that leaves the file as it was is neither backed up nor reported.
+// Failing to save the backup never fails the write.
+func trackWrite(path string, write func() error) error {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	before, readErr := os.ReadFile(path)
+	created := os.IsNotExist(readErr)
+
+	if err := write(); err != nil {
+		return err
+	}
+
+	after, err := os.ReadFile(path)
+	if err != nil || (!created && bytes.Equal(before, after)) {
+		return nil
+	}
+	if readErr == nil || created {
+		_ = saveBackup(path, before, after, created)
+	}
+	if OnWrite != nil {
+		OnWrite(Change{Path: path, Before: before, After: after, Created: created})
+	}
+	return nil
+}
+
+// WriteFile writes a config file, keeping a backup of what it replaces.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	return trackWrite(path, func() error {
+		return os.WriteFile(path, data, perm)
+	})
+}
+
+// saveBackup stores before under ~/.rr/backups and adds it to the index,
+// dropping the oldest backups past maxBackups.
+func saveBackup(path string, before, after []byte, created bool) error {
+	dir, err := backupsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	entry := Backup{Path: path, Written: checksum(after), Time: now}
+	if !created {
+		entry.File = fmt.Sprintf("%s-%s", now.Format("20060102-150405.000000000"), filepath.Base(path))
+		if err := os.WriteFile(filepath.Join(dir, entry.File), before, 0600); err != nil {
+			return err
+		}
+	}
+
+	backups, _ := ListBackups()
+	backups = append(backups, entry)
+	for len(backups) > maxBackups {
+		if backups[0].File != "" {
+			_ = os.Remove(filepath.Join(dir, backups[0].File))
+		}
+		backups = backups[1:]
+	}
+	return writeBackupIndex(dir, backups)
+}
+
+// ListBackups returns the config backups, oldest first.
+func ListBackups() ([]Backup, error) {
+	dir, err := backupsPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, backupIndexFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var backups []Backup
+	if err := json.Unmarshal(data, &backups); err != nil {
+		return nil, err
+	}
+	return backups, nil
+}
+
+// writeBackupIndex replaces the backup index atomically.
+func writeBackupIndex(dir string, backups []Backup) error {
+	data, err := json.MarshalIndent(backups, "", "  ")
+	if err != nil {
+		return err
+	}
+	return util.WriteFileAtomic(filepath.Join(dir, backupIndexFile), data, 0600)
+}
+
+// Undo reverts the most recent config rewrite: the file gets its backed up
+// contents back, or is deleted if the rewrite created it. Undoing again
+// reverts the rewrite before that. Unless force is set, a file edited since
+// rr wrote it is left alone.
+func Undo(force bool) (*Backup, error) {
+	backups, err := ListBackups()
+	if err != nil {
+		return nil, errors.WrapWithCode(err, errors.ErrConfig,
+			"Can't read the config backup index",
+			"Check ~/.rr/backups/index.json, or restore a backup from ~/.rr/backups by hand.")
+	}
+	if len(backups) == 0 {
+		return nil, errors.New(errors.ErrConfig,
+			"No config changes to undo",
+			"rr keeps a backup each time it rewrites ~/.rr/config.yaml or .rr.yaml; there are none yet.")
+	}
+	last := backups[len(backups)-1]
+	dir, _ := backupsPath()
+
+	current, readErr := os.ReadFile(last.Path)
+	if !force && (readErr != nil || checksum(current) != last.Written) {
+		return nil, errors.New(errors.ErrConfig,
+			fmt.Sprintf("%s changed since rr last wrote it", last.Path),
+			"Undoing would lose those edits. Pass --force to undo anyway.")
+	}
+
+	var before []byte
+	if last.File == "" {
+		if err := os.Remove(last.Path); err != nil && !os.IsNotExist(err) {
+			return nil, errors.WrapWithCode(err, errors.ErrConfig,
+				fmt.Sprintf("Can't remove %s", last.Path),
+				"Check the file's permissions.")
+		}
+	} else {
+		before, err = os.ReadFile(filepath.Join(dir, last.File))
+		if err != nil {
+			return nil, errors.WrapWithCode(err, errors.ErrConfig,
+				fmt.Sprintf("Can't read the backup of %s", last.Path),
+				"It may have been deleted from ~/.rr/backups.")
+		}
+		if err := os.WriteFile(last.Path, before, 0644); err != nil {
+			return nil, errors.WrapWithCode(err, errors.ErrConfig,
+				fmt.Sprintf("Can't restore %s", last.Path),
+				"Check the file's permissions.")
+		}
+		_ = os.Remove(filepath.Join(dir, last.File))
+	}
+	if OnWrite != nil {
+		OnWrite(Change{Path: last.Path, Before: current, After: before, Removed: last.File == ""})
+	}
+
+	_ = writeBackupIndex(dir, backups[:len(backups)-1])
+	return &last, nil
+}
+
+// UnifiedDiff returns a unified diff of a change, or "" if nothing changed.
+func UnifiedDiff(c Change) string {
+	from, to := "a/"+filepath.Base(c.Path), "b/"+filepath.Base(c.Path)
+	if c.Created {
+		from = "/dev/null"
+	}
+	if c.Removed {
+		to = "/dev/null"
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(c.Before)),
+		B:        difflib.SplitLines(string(c.After)),
+		FromFile: from,
+		ToFile:   to,
+		Context:  3,
+	})
+	if err != nil {
+		return ""
+	}
+	return diff
+}
+
+// checksum returns the hex SHA-256 of data.
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
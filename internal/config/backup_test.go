@@ -0,0 +1,100 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFile_BacksUpAndUndoes(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), ".rr.yaml")
+
+	var changes []Change
+	saved := OnWrite
+	t.Cleanup(func() { OnWrite = saved })
+	OnWrite = func(c Change) { changes = append(changes, c) }
+
+	require.NoError(t, WriteFile(path, []byte("version: 1\n"), 0644))
+	require.NoError(t, WriteFile(path, []byte("version: 1\nhost: mini\n"), 0644))
+	require.NoError(t, WriteFile(path, []byte("version: 1\nhost: mini\n"), 0644))
+
+	require.Len(t, changes, 2, "a write that changes nothing isn't reported")
+	assert.True(t, changes[0].Created)
+	assert.Equal(t, "version: 1\n", string(changes[1].Before))
+
+	backups, err := ListBackups()
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	assert.Empty(t, backups[0].File, "creating a file has nothing to back up")
+	assert.NotEmpty(t, backups[1].File)
+
+	undone, err := Undo(false)
+	require.NoError(t, err)
+	assert.Equal(t, path, undone.Path)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "version: 1\n", string(data))
+
+	_, err = Undo(false)
+	require.NoError(t, err)
+	assert.NoFileExists(t, path, "undoing the write that created the file removes it")
+
+	_, err = Undo(false)
+	assert.ErrorContains(t, err, "No config changes to undo")
+}
+
+func TestUndo_RefusesOverEdits(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("a\n"), 0644))
+	require.NoError(t, WriteFile(path, []byte("b\n"), 0644))
+	require.NoError(t, os.WriteFile(path, []byte("edited by hand\n"), 0644))
+
+	_, err := Undo(false)
+	assert.ErrorContains(t, err, "changed since rr last wrote it")
+
+	_, err = Undo(true)
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "a\n", string(data))
+}
+
+func TestSaveBackup_KeepsTheNewest(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("0\n"), 0644))
+	for i := 1; i <= maxBackups+3; i++ {
+		require.NoError(t, WriteFile(path, []byte{byte('0' + i%10), '\n', byte(i)}, 0644))
+	}
+
+	backups, err := ListBackups()
+	require.NoError(t, err)
+	assert.Len(t, backups, maxBackups)
+
+	dir, err := backupsPath()
+	require.NoError(t, err)
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, maxBackups+1, "pruned backups are deleted, leaving the index")
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	diff := UnifiedDiff(Change{
+		Path:   "/home/me/.rr/config.yaml",
+		Before: []byte("hosts:\n  mini:\n    dir: ~/a\n"),
+		After:  []byte("hosts:\n  mini:\n    dir: ~/b\n"),
+	})
+	assert.Contains(t, diff, "--- a/config.yaml\n+++ b/config.yaml\n")
+	assert.Contains(t, diff, "-    dir: ~/a\n+    dir: ~/b\n")
+
+	diff = UnifiedDiff(Change{Path: ".rr.yaml", After: []byte("version: 1\n"), Created: true})
+	assert.Contains(t, diff, "--- /dev/null\n")
+	assert.Contains(t, diff, "+version: 1\n")
+
+	assert.Empty(t, UnifiedDiff(Change{Path: ".rr.yaml", Before: []byte("x\n"), After: []byte("x\n")}))
+}
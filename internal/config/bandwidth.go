@@ -0,0 +1,47 @@
+package config
+
+import (
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// Settings for GlobalConfig.LowBandwidth.
+const (
+	LowBandwidthAuto   = "auto"   // Turn it on when connecting to a host is slow (default)
+	LowBandwidthAlways = "always" // Always on, like --low-bandwidth
+	LowBandwidthNever  = "never"  // Never on, even on a slow connection
+)
+
+// SlowConnectLatency is how long connecting to a host can take before
+// low_bandwidth: auto decides the link is slow. A fast link connects in
+// well under this; a tethered or hotel connection, with its long round
+// trips, takes longer.
+const SlowConnectLatency = time.Second
+
+// lowBandwidth is whether low-bandwidth mode is on. It can be turned on
+// partway through a run, once a slow connection is noticed.
+var lowBandwidth atomic.Bool
+
+// SetLowBandwidth turns low-bandwidth mode on or off.
+func SetLowBandwidth(on bool) {
+	lowBandwidth.Store(on)
+}
+
+// LowBandwidth reports whether low-bandwidth mode is on: SSH traffic is
+// compressed, and progress and output are shown less often.
+func LowBandwidth() bool {
+	return lowBandwidth.Load()
+}
+
+// GetLowBandwidth returns the global config's low_bandwidth setting,
+// defaulting to auto. RR_LOW_BANDWIDTH=1 turns it on for one command.
+func GetLowBandwidth(cfg *GlobalConfig) string {
+	if v := os.Getenv("RR_LOW_BANDWIDTH"); v != "" && v != "0" {
+		return LowBandwidthAlways
+	}
+	if cfg == nil || cfg.LowBandwidth == "" {
+		return LowBandwidthAuto
+	}
+	return cfg.LowBandwidth
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetLowBandwidth(t *testing.T) {
+	t.Setenv("RR_LOW_BANDWIDTH", "")
+	assert.Equal(t, LowBandwidthAuto, GetLowBandwidth(nil))
+	assert.Equal(t, LowBandwidthNever, GetLowBandwidth(&GlobalConfig{LowBandwidth: LowBandwidthNever}))
+
+	t.Setenv("RR_LOW_BANDWIDTH", "1")
+	assert.Equal(t, LowBandwidthAlways, GetLowBandwidth(&GlobalConfig{LowBandwidth: LowBandwidthNever}))
+}
+
+func TestSSHOptions_ArgsLowBandwidth(t *testing.T) {
+	SetLowBandwidth(true)
+	t.Cleanup(func() { SetLowBandwidth(false) })
+
+	assert.Equal(t, []string{"-o", "Compression=yes"}, SSHOptions{}.Args())
+}
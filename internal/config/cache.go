@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/util"
+)
+
+// DefaultCacheDir is where a project's cache lives on a host unless
+// cache.dir says otherwise.
+const DefaultCacheDir = "~/.rr/cache/${PROJECT}"
+
+// DefaultCacheTTL is how long a cached file can go unused before it's
+// deleted, unless cache.ttl says otherwise.
+const DefaultCacheTTL = 7 * 24 * time.Hour
+
+// Path returns the cache directory for the current project, with variables
+// expanded for the remote shell.
+func (c CacheConfig) Path() string {
+	dir := c.Dir
+	if dir == "" {
+		dir = DefaultCacheDir
+	}
+	return strings.TrimSuffix(ExpandRemote(dir), "/")
+}
+
+// TTLDuration returns cache.ttl, or DefaultCacheTTL if it's unset or
+// invalid.
+func (c CacheConfig) TTLDuration() time.Duration {
+	if d, err := time.ParseDuration(c.TTL); err == nil && d > 0 {
+		return d
+	}
+	return DefaultCacheTTL
+}
+
+// MaxSizeBytes returns cache.max_size in bytes, or 0 for no cap.
+func (c CacheConfig) MaxSizeBytes() int64 {
+	if c.MaxSize == "" {
+		return 0
+	}
+	size, err := ParseSize(c.MaxSize)
+	if err != nil {
+		return 0
+	}
+	return size
+}
+
+// CacheSetupCommand returns the command that creates the cache directory
+// and exports it as RR_CACHE, to run before a command so the command (and
+// its env) can use $RR_CACHE.
+func CacheSetupCommand(c CacheConfig) string {
+	return fmt.Sprintf(`export RR_CACHE=%s && mkdir -p "$RR_CACHE"`, QuoteRemotePath(c.Path()))
+}
+
+// QuoteRemotePath shell-quotes a remote path for use anywhere in a command,
+// including assignments, where a bare ~ isn't expanded by every shell: a
+// leading ~ becomes "$HOME".
+func QuoteRemotePath(p string) string {
+	if p == "~" {
+		return `"$HOME"`
+	}
+	if rest, ok := strings.CutPrefix(p, "~/"); ok {
+		return `"$HOME"/` + util.ShellQuote(rest)
+	}
+	return util.ShellQuote(p)
+}
+
+// validateCache checks the cache section. The directory is cleaned out by
+// rr, so it has to be a directory of its own, not a home or root directory.
+func validateCache(c CacheConfig) error {
+	if c.Dir != "" {
+		dir := strings.TrimSuffix(c.Dir, "/")
+		switch {
+		case dir == "~" || dir == "" || dir == "${HOME}":
+			return fmt.Errorf("cache.dir can't be the home or root directory - rr deletes old files from it")
+		case !strings.HasPrefix(dir, "~/") && !strings.HasPrefix(dir, "/") && !strings.HasPrefix(dir, "${HOME}/"):
+			return fmt.Errorf("cache.dir '%s' has to be an absolute path or start with ~/", c.Dir)
+		case strings.Contains("/"+dir+"/", "/../"):
+			return fmt.Errorf("cache.dir '%s' can't contain '..'", c.Dir)
+		}
+	}
+	if c.TTL != "" {
+		d, err := time.ParseDuration(c.TTL)
+		if err != nil {
+			return fmt.Errorf("cache.ttl '%s' doesn't look like a valid duration - try something like '72h' or '720h'", c.TTL)
+		}
+		if d <= 0 {
+			return fmt.Errorf("cache.ttl has to be a positive duration")
+		}
+	}
+	if c.MaxSize != "" {
+		if _, err := ParseSize(c.MaxSize); err != nil {
+			return fmt.Errorf("cache.max_size: %w", err)
+		}
+	}
+	return nil
+}
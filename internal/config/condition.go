@@ -0,0 +1,369 @@
+package config
+
+import (
+	"fmt"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+// A step's if: expression decides whether it runs. Expressions compare
+// strings with == and !=, combine them with &&, ||, !, and parentheses, and
+// can use:
+//
+//   - env.NAME, the value of an environment variable ("" when unset)
+//   - 'text' or "text", a string literal
+//   - true and false
+//   - files_changed('pattern', ...), whether any changed file matches
+//
+// A value is false when it's "", "false", or "0", and true otherwise.
+
+// ConditionEnv is what an if: expression can see.
+type ConditionEnv struct {
+	// Env looks up env.NAME.
+	Env func(name string) string
+
+	// ChangedFiles lists the project's changed files, relative to its root.
+	// known is false when changes can't be worked out (outside a git
+	// repository, say), which makes files_changed true.
+	ChangedFiles func() (files []string, known bool)
+}
+
+// Condition is a parsed if: expression.
+type Condition struct {
+	expr string
+	eval condFunc
+}
+
+type condFunc func(env *ConditionEnv) string
+
+// ParseCondition parses an if: expression.
+func ParseCondition(expr string) (*Condition, error) {
+	tokens, err := tokenizeCondition(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("the expression is empty")
+	}
+	p := &condParser{tokens: tokens}
+	eval, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected '%s'", p.tokens[p.pos].text)
+	}
+	return &Condition{expr: expr, eval: eval}, nil
+}
+
+// Eval reports whether the expression is true.
+func (c *Condition) Eval(env *ConditionEnv) bool {
+	return truthy(c.eval(env))
+}
+
+// String returns the expression as written.
+func (c *Condition) String() string {
+	return c.expr
+}
+
+// truthy turns a value into a boolean.
+func truthy(v string) bool {
+	return v != "" && v != "false" && v != "0"
+}
+
+func boolValue(b bool) string {
+	if b {
+		return "true"
+	}
+	return "false"
+}
+
+type condTokenKind int
+
+const (
+	condIdent condTokenKind = iota
+	condString
+	condOp
+)
+
+type condToken struct {
+	kind condTokenKind
+	text string
+}
+
+// tokenizeCondition splits an expression into identifiers (which may
+// contain dots), quoted strings, and operators.
+func tokenizeCondition(expr string) ([]condToken, error) {
+	var tokens []condToken
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(expr[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("the string starting at %s isn't closed", expr[i:])
+			}
+			tokens = append(tokens, condToken{condString, expr[i+1 : i+1+end]})
+			i += end + 2
+		case strings.HasPrefix(expr[i:], "==") || strings.HasPrefix(expr[i:], "!=") ||
+			strings.HasPrefix(expr[i:], "&&") || strings.HasPrefix(expr[i:], "||"):
+			tokens = append(tokens, condToken{condOp, expr[i : i+2]})
+			i += 2
+		case c == '!' || c == '(' || c == ')' || c == ',':
+			tokens = append(tokens, condToken{condOp, string(c)})
+			i++
+		case isIdentByte(c):
+			start := i
+			for i < len(expr) && (isIdentByte(expr[i]) || expr[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, condToken{condIdent, expr[start:i]})
+		default:
+			return nil, fmt.Errorf("unexpected '%c' - quote strings and patterns, like 'main' or '**/*.py'", c)
+		}
+	}
+	return tokens, nil
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// condParser is a recursive descent parser over condition tokens.
+type condParser struct {
+	tokens []condToken
+	pos    int
+}
+
+func (p *condParser) peekOp(op string) bool {
+	return p.pos < len(p.tokens) && p.tokens[p.pos].kind == condOp && p.tokens[p.pos].text == op
+}
+
+func (p *condParser) parseOr() (condFunc, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekOp("||") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(env *ConditionEnv) string { return boolValue(truthy(l(env)) || truthy(right(env))) }
+	}
+	return left, nil
+}
+
+func (p *condParser) parseAnd() (condFunc, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekOp("&&") {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(env *ConditionEnv) string { return boolValue(truthy(l(env)) && truthy(right(env))) }
+	}
+	return left, nil
+}
+
+func (p *condParser) parseUnary() (condFunc, error) {
+	if p.peekOp("!") {
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(env *ConditionEnv) string { return boolValue(!truthy(operand(env))) }, nil
+	}
+
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!="} {
+		if !p.peekOp(op) {
+			continue
+		}
+		p.pos++
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		equal := op == "=="
+		return func(env *ConditionEnv) string { return boolValue((left(env) == right(env)) == equal) }, nil
+	}
+	return left, nil
+}
+
+func (p *condParser) parsePrimary() (condFunc, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("the expression ends too soon")
+	}
+	tok := p.tokens[p.pos]
+	p.pos++
+
+	switch {
+	case tok.kind == condString:
+		return func(*ConditionEnv) string { return tok.text }, nil
+	case tok.kind == condOp && tok.text == "(":
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peekOp(")") {
+			return nil, fmt.Errorf("a '(' isn't closed")
+		}
+		p.pos++
+		return inner, nil
+	case tok.kind == condOp:
+		return nil, fmt.Errorf("unexpected '%s'", tok.text)
+	case tok.text == "true" || tok.text == "false":
+		return func(*ConditionEnv) string { return tok.text }, nil
+	case strings.HasPrefix(tok.text, "env."):
+		name := strings.TrimPrefix(tok.text, "env.")
+		if !IsEnvName(name) {
+			return nil, fmt.Errorf("'%s' isn't a valid environment variable name", name)
+		}
+		return func(env *ConditionEnv) string {
+			if env == nil || env.Env == nil {
+				return ""
+			}
+			return env.Env(name)
+		}, nil
+	case p.peekOp("("):
+		return p.parseCall(tok.text)
+	}
+	return nil, fmt.Errorf("unknown name '%s' - use env.%s for an environment variable, or quote it for a string", tok.text, tok.text)
+}
+
+// parseCall parses a function's string arguments, after its name.
+func (p *condParser) parseCall(name string) (condFunc, error) {
+	if name != "files_changed" {
+		return nil, fmt.Errorf("unknown function '%s' - the only function is files_changed", name)
+	}
+	p.pos++ // (
+
+	var patterns []string
+	for !p.peekOp(")") {
+		if len(patterns) > 0 {
+			if !p.peekOp(",") {
+				return nil, fmt.Errorf("files_changed's arguments need commas between them")
+			}
+			p.pos++
+		}
+		if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != condString {
+			return nil, fmt.Errorf("files_changed takes quoted patterns, like files_changed('**/*.py')")
+		}
+		patterns = append(patterns, p.tokens[p.pos].text)
+		p.pos++
+	}
+	p.pos++ // )
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("files_changed needs at least one pattern")
+	}
+
+	return func(env *ConditionEnv) string {
+		if env == nil || env.ChangedFiles == nil {
+			return "true"
+		}
+		files, known := env.ChangedFiles()
+		if !known {
+			return "true"
+		}
+		for _, f := range files {
+			for _, pattern := range patterns {
+				if MatchChangedFile(pattern, f) {
+					return "true"
+				}
+			}
+		}
+		return "false"
+	}, nil
+}
+
+// MatchChangedFile reports whether a project-relative file path matches a
+// files_changed pattern. A pattern without a / matches any path segment,
+// like .gitignore, so '*.py' matches every Python file. Otherwise it's
+// matched from the project root a segment at a time, where ** matches any
+// number of directories. A pattern naming a directory matches everything
+// under it.
+func MatchChangedFile(pattern, file string) bool {
+	pattern = strings.TrimPrefix(pattern, "/")
+	parts := strings.Split(file, "/")
+	if !strings.Contains(pattern, "/") {
+		for _, part := range parts {
+			if ok, _ := path.Match(pattern, part); ok {
+				return true
+			}
+		}
+		return false
+	}
+	return matchSegments(strings.Split(strings.TrimSuffix(pattern, "/"), "/"), parts)
+}
+
+func matchSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return true // Everything under a matched directory
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], parts[1:])
+}
+
+// ChangedFiles lists the files under root that git sees as changed: edits
+// to tracked files since the last commit (staged or not), untracked files
+// that aren't ignored, and files changed by commits the branch's upstream
+// doesn't have yet. Paths are relative to root.
+func ChangedFiles(root string) ([]string, error) {
+	if root == "" {
+		root = "."
+	}
+	var files []string
+	seen := map[string]bool{}
+	add := func(out []byte) {
+		for _, line := range strings.Split(string(out), "\n") {
+			if line = strings.TrimSpace(line); line != "" && !seen[line] {
+				seen[line] = true
+				files = append(files, line)
+			}
+		}
+	}
+
+	out, err := exec.Command("git", "-C", root, "diff", "--name-only", "--relative", "HEAD").Output()
+	if err != nil {
+		return nil, err
+	}
+	add(out)
+	out, err = exec.Command("git", "-C", root, "ls-files", "--others", "--exclude-standard").Output()
+	if err != nil {
+		return nil, err
+	}
+	add(out)
+	// No upstream just means there are no unpushed commits to add
+	if out, err := exec.Command("git", "-C", root, "diff", "--name-only", "--relative", "@{upstream}...HEAD").Output(); err == nil {
+		add(out)
+	}
+	return files, nil
+}
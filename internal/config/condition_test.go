@@ -0,0 +1,142 @@
+package config
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCondition_Eval(t *testing.T) {
+	env := &ConditionEnv{
+		Env: func(name string) string {
+			return map[string]string{"CI": "true", "MODE": "fast", "ZERO": "0"}[name]
+		},
+		ChangedFiles: func() ([]string, bool) {
+			return []string{"api/server.py", "docs/guide.md"}, true
+		},
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"true", true},
+		{"false", false},
+		{"env.CI", true},
+		{"env.MISSING", false},
+		{"env.ZERO", false},
+		{"env.CI != 'true'", false},
+		{`env.MODE == "fast"`, true},
+		{"!env.CI", false},
+		{"!!env.CI", true},
+		{"env.CI == 'true' && env.MODE == 'slow'", false},
+		{"env.CI == 'true' && env.MODE == 'slow' || env.MODE == 'fast'", true},
+		{"env.CI == 'true' && (env.MODE == 'slow' || env.MODE == 'fast')", true},
+		{"files_changed('**/*.py')", true},
+		{"files_changed('*.go')", false},
+		{"files_changed('*.go', 'docs/')", true},
+		{"!files_changed('web/**')", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			cond, err := ParseCondition(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, cond.Eval(env))
+			assert.Equal(t, tt.expr, cond.String())
+		})
+	}
+}
+
+func TestCondition_FilesChangedUnknown(t *testing.T) {
+	cond, err := ParseCondition("files_changed('*.py')")
+	require.NoError(t, err)
+	env := &ConditionEnv{ChangedFiles: func() ([]string, bool) { return nil, false }}
+	assert.True(t, cond.Eval(env), "steps run when changes can't be worked out")
+}
+
+func TestParseCondition_Errors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"", "empty"},
+		{"   ", "empty"},
+		{"env.CI ==", "ends too soon"},
+		{"(env.CI", "isn't closed"},
+		{"env.CI == 'true", "isn't closed"},
+		{"CI == 'true'", "unknown name 'CI'"},
+		{"changed('x')", "unknown function"},
+		{"files_changed()", "at least one pattern"},
+		{"files_changed(env.X)", "quoted patterns"},
+		{"files_changed('a' 'b')", "commas"},
+		{"env.CI & true", "unexpected '&'"},
+		{"true true", "unexpected 'true'"},
+		{"env.1X", "valid environment variable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := ParseCondition(tt.expr)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestMatchChangedFile(t *testing.T) {
+	tests := []struct {
+		pattern string
+		file    string
+		want    bool
+	}{
+		{"*.py", "app.py", true},
+		{"*.py", "src/pkg/app.py", true},
+		{"*.py", "src/app.go", false},
+		{"**/*.py", "app.py", true},
+		{"**/*.py", "src/pkg/app.py", true},
+		{"src/*.py", "src/app.py", true},
+		{"src/*.py", "src/pkg/app.py", false},
+		{"src/**/*.py", "src/pkg/app.py", true},
+		{"/src/**", "src/a/b", true},
+		{"docs/", "docs/guide.md", true},
+		{"docs", "api/docs/x.md", true},
+		{"web/src", "web/src/app.ts", true},
+		{"web/src", "web/other.ts", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.file, func(t *testing.T) {
+			assert.Equal(t, tt.want, MatchChangedFile(tt.pattern, tt.file))
+		})
+	}
+}
+
+func TestChangedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@t", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@t")
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	git("init", "-q")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "kept.txt"), []byte("a"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "edited.txt"), []byte("a"), 0644))
+	git("add", ".")
+	git("commit", "-qm", "init")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "edited.txt"), []byte("b"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.py"), []byte("b"), 0644))
+
+	files, err := ChangedFiles(dir)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"edited.txt", "new.py"}, files)
+
+	_, err = ChangedFiles(t.TempDir())
+	assert.Error(t, err, "outside a git repository")
+}
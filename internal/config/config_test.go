@@ -19,6 +19,7 @@ func TestDefaultConfig(t *testing.T) {
 	assert.True(t, cfg.Lock.Enabled)
 	assert.Equal(t, 5*time.Minute, cfg.Lock.Timeout)
 	assert.Equal(t, 3*time.Minute, cfg.Lock.Stale)
+	assert.True(t, cfg.Lock.ShareOutput)
 	assert.Equal(t, "auto", cfg.Output.Color)
 	assert.Equal(t, "auto", cfg.Output.Format)
 	assert.True(t, cfg.Output.Timing)
@@ -128,6 +129,164 @@ defaults:
 	assert.True(t, cfg.Defaults.LocalFallback)
 }
 
+func TestLoadGlobal_Theme(t *testing.T) {
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+
+	configDir := filepath.Join(tmpHome, ".rr")
+	require.NoError(t, os.MkdirAll(configDir, 0755))
+	content := `
+version: 1
+theme:
+  name: light
+  colors:
+    accent: "#D7005F"
+    muted: "245"
+`
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte(content), 0644))
+
+	cfg, err := LoadGlobal()
+	require.NoError(t, err)
+	assert.Equal(t, "light", cfg.Theme.Name)
+	assert.Equal(t, map[string]string{"accent": "#D7005F", "muted": "245"}, cfg.Theme.Colors)
+}
+
+func TestLoadGlobal_Accessible(t *testing.T) {
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+
+	configDir := filepath.Join(tmpHome, ".rr")
+	require.NoError(t, os.MkdirAll(configDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte("version: 1\naccessible: true\n"), 0644))
+
+	cfg, err := LoadGlobal()
+	require.NoError(t, err)
+	assert.True(t, cfg.Accessible)
+}
+
+func TestSaveGlobal_PreservesTheme(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cfg := DefaultGlobalConfig()
+	cfg.Hosts["dev"] = Host{SSH: []string{"dev-lan"}, Dir: "~/projects"}
+	cfg.Theme = ThemeConfig{Name: "ansi", Colors: map[string]string{"accent": "5"}}
+	require.NoError(t, SaveGlobal(cfg))
+
+	loaded, err := LoadGlobal()
+	require.NoError(t, err)
+	assert.Equal(t, cfg.Theme, loaded.Theme)
+
+	// No theme configured: nothing is written for it
+	cfg.Theme = ThemeConfig{}
+	require.NoError(t, SaveGlobal(cfg))
+	path, err := GlobalConfigPath()
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "theme")
+}
+
+func TestSaveGlobal_PreservesSettings(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cfg := DefaultGlobalConfig()
+	cfg.Accessible = true
+	cfg.LowBandwidth = LowBandwidthNever
+	cfg.Stats = true
+	cfg.Secrets = SecretsFile
+	require.NoError(t, SaveGlobal(cfg))
+
+	loaded, err := LoadGlobal()
+	require.NoError(t, err)
+	assert.True(t, loaded.Accessible)
+	assert.Equal(t, LowBandwidthNever, loaded.LowBandwidth)
+	assert.True(t, loaded.Stats)
+	assert.Equal(t, SecretsFile, loaded.Secrets)
+}
+
+func TestSaveGlobal_Conflict(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	first, err := LoadGlobal()
+	require.NoError(t, err)
+	second, err := LoadGlobal()
+	require.NoError(t, err)
+
+	first.Hosts["one"] = Host{SSH: []string{"one-lan"}, Dir: "~/rr"}
+	require.NoError(t, SaveGlobal(first))
+	first.Hosts["two"] = Host{SSH: []string{"two-lan"}, Dir: "~/rr"}
+	require.NoError(t, SaveGlobal(first), "saving again after its own save isn't a conflict")
+
+	second.Hosts["three"] = Host{SSH: []string{"three-lan"}, Dir: "~/rr"}
+	err = SaveGlobal(second)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "changed while this command was running")
+
+	loaded, err := LoadGlobal()
+	require.NoError(t, err)
+	assert.Contains(t, loaded.Hosts, "two")
+	assert.NotContains(t, loaded.Hosts, "three", "the other command's save isn't overwritten")
+}
+
+func TestSaveGlobal_ReplacesFileAtomically(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path, err := GlobalConfigPath()
+	require.NoError(t, err)
+	require.NoError(t, EnsureGlobalConfigDir())
+	require.NoError(t, os.WriteFile(path, []byte("version: 1\nhosts: {}\n"), 0600))
+
+	cfg, err := LoadGlobal()
+	require.NoError(t, err)
+	cfg.Hosts["dev"] = Host{SSH: []string{"dev-lan"}, Dir: "~/rr"}
+	require.NoError(t, SaveGlobal(cfg))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "the file keeps its permissions")
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	for _, e := range entries {
+		assert.NotContains(t, e.Name(), ".tmp-", "no temporary file is left behind")
+	}
+}
+
+func TestSaveGlobal_WaitsForLock(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	require.NoError(t, EnsureGlobalConfigDir())
+	path, err := GlobalConfigPath()
+	require.NoError(t, err)
+
+	unlock, err := lockGlobal(path)
+	require.NoError(t, err)
+	time.AfterFunc(100*time.Millisecond, unlock)
+
+	cfg := DefaultGlobalConfig()
+	cfg.Stats = true
+	require.NoError(t, SaveGlobal(cfg), "saves once the other command lets go")
+}
+
+func TestSaveGlobal_LockTimeout(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	require.NoError(t, EnsureGlobalConfigDir())
+	path, err := GlobalConfigPath()
+	require.NoError(t, err)
+
+	orig := globalLockWait
+	t.Cleanup(func() { globalLockWait = orig })
+	globalLockWait = 100 * time.Millisecond
+
+	unlock, err := lockGlobal(path)
+	require.NoError(t, err)
+	defer unlock()
+
+	err = SaveGlobal(DefaultGlobalConfig())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Another rr command has been saving")
+	_, statErr := os.Stat(path)
+	assert.True(t, os.IsNotExist(statErr), "nothing is written without the lock")
+}
+
 func TestResolveHost(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -424,6 +583,164 @@ output:
 	assert.Len(t, cfg.Tasks, 2)
 	assert.Equal(t, "make build", cfg.Tasks["build"].Run)
 	assert.Equal(t, "always", cfg.Output.Color)
+	assert.True(t, cfg.Sync.Prescan, "prescan is on unless turned off")
+	assert.True(t, cfg.Lock.ShareOutput, "lock output is shared unless turned off")
+}
+
+func TestLoad_SyncPrescanOff(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), ".rr.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("version: 1\nsync:\n  prescan: false\n"), 0644))
+
+	cfg, err := Load(configPath)
+	require.NoError(t, err)
+	assert.False(t, cfg.Sync.Prescan)
+	assert.True(t, cfg.Sync.RespectGitignore, "other sync defaults are kept")
+}
+
+func TestLoad_SyncProfiles(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), ".rr.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`version: 1
+sync:
+  exclude: [.git/, node_modules/]
+  profiles:
+    data:
+      respect_gitignore: false
+      exclude: [.git/]
+      flags: [--compress-level=1]
+tasks:
+  train:
+    run: python train.py
+    sync_profile: data
+`), 0644))
+
+	cfg, err := Load(configPath)
+	require.NoError(t, err)
+	require.NoError(t, Validate(cfg))
+	assert.Equal(t, "data", cfg.Tasks["train"].SyncProfile)
+
+	sync, err := cfg.Sync.WithProfile("data")
+	require.NoError(t, err)
+	assert.False(t, sync.RespectGitignore)
+	assert.Equal(t, []string{".git/"}, sync.Exclude)
+	assert.Equal(t, []string{"--compress-level=1"}, sync.Flags)
+}
+
+func TestLoad_Cache(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), ".rr.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`version: 1
+cache:
+  dir: /scratch/rr-cache
+  ttl: 72h
+  max_size: 20GB
+`), 0644))
+
+	cfg, err := Load(configPath)
+	require.NoError(t, err)
+	require.NoError(t, Validate(cfg))
+	assert.Equal(t, "/scratch/rr-cache", cfg.Cache.Path())
+	assert.Equal(t, 72*time.Hour, cfg.Cache.TTLDuration())
+	assert.Equal(t, int64(20<<30), cfg.Cache.MaxSizeBytes())
+	assert.Equal(t, `export RR_CACHE='/scratch/rr-cache' && mkdir -p "$RR_CACHE"`, CacheSetupCommand(cfg.Cache))
+}
+
+func TestCacheConfig_Defaults(t *testing.T) {
+	var c CacheConfig
+	assert.Equal(t, "~/.rr/cache/"+getProject(), c.Path())
+	assert.Equal(t, DefaultCacheTTL, c.TTLDuration())
+	assert.Zero(t, c.MaxSizeBytes())
+	assert.Equal(t, `"$HOME"/'.rr/cache/x'`, QuoteRemotePath("~/.rr/cache/x"))
+}
+
+func TestLoad_Bootstrap(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), ".rr.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`version: 1
+bootstrap: |
+  python3 -m venv .venv
+  .venv/bin/pip install -r requirements.txt
+`), 0644))
+
+	cfg, err := Load(configPath)
+	require.NoError(t, err)
+	require.NoError(t, Validate(cfg))
+	assert.Equal(t, "python3 -m venv .venv\n.venv/bin/pip install -r requirements.txt\n", cfg.Bootstrap)
+}
+
+func TestSyncConfig_WithProfile(t *testing.T) {
+	off := false
+	base := SyncConfig{
+		Exclude:          []string{".git/", "data/"},
+		Preserve:         []string{"venv/"},
+		Flags:            []string{"-z"},
+		RespectGitignore: true,
+		Profiles: map[string]SyncProfile{
+			"code": {Exclude: []string{".git/", "data/", "*.ckpt"}},
+			"data": {RespectGitignore: &off, Exclude: []string{}, Flags: []string{"--whole-file"}},
+		},
+	}
+
+	got, err := base.WithProfile("")
+	require.NoError(t, err)
+	assert.Equal(t, base, got)
+
+	got, err = base.WithProfile("code")
+	require.NoError(t, err)
+	assert.Equal(t, []string{".git/", "data/", "*.ckpt"}, got.Exclude)
+	assert.Equal(t, []string{"venv/"}, got.Preserve, "unset fields come from the sync section")
+	assert.Equal(t, []string{"-z"}, got.Flags)
+	assert.True(t, got.RespectGitignore)
+
+	got, err = base.WithProfile("data")
+	require.NoError(t, err)
+	assert.Empty(t, got.Exclude, "an empty list replaces the sync section's")
+	assert.Equal(t, []string{"--whole-file"}, got.Flags)
+	assert.False(t, got.RespectGitignore)
+
+	_, err = base.WithProfile("docs")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "sync profile 'docs' isn't defined - defined profiles: code, data")
+
+	_, err = SyncConfig{}.WithProfile("docs")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "add it under sync.profiles")
+}
+
+func TestLoad_TaskOutput(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), ".rr.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`version: 1
+tasks:
+  ci:
+    parallel: [build, test]
+    output: stream
+  build:
+    output:
+      verbosity: quiet
+    steps:
+      - run: make deps
+      - run: make build
+        output: verbose
+  test:
+    run: go test ./...
+    output:
+      format: go
+`), 0644))
+
+	cfg, err := Load(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, TaskOutput{Mode: "stream"}, cfg.Tasks["ci"].Output)
+	assert.Equal(t, TaskOutput{Verbosity: "quiet"}, cfg.Tasks["build"].Output)
+	assert.Equal(t, "verbose", cfg.Tasks["build"].Steps[1].Output)
+	assert.Equal(t, TaskOutput{Format: "go"}, cfg.Tasks["test"].Output)
+
+	// The YAML decoder takes both forms too
+	var task TaskConfig
+	require.NoError(t, yaml.Unmarshal([]byte("run: make\noutput: quiet\n"), &task))
+	assert.Equal(t, TaskOutput{Mode: "quiet"}, task.Output)
+	var quiet TaskConfig
+	require.NoError(t, yaml.Unmarshal([]byte("run: make\noutput: {verbosity: quiet}\n"), &quiet))
+	assert.Equal(t, TaskOutput{Verbosity: "quiet"}, quiet.Output)
+
+	_, err = TaskOutputFromInterface(map[string]interface{}{"verbosity": 1})
+	assert.Error(t, err)
 }
 
 func TestLoadNotFound(t *testing.T) {
@@ -604,6 +921,72 @@ func TestFind_StopsAtGitRoot(t *testing.T) {
 	assert.Empty(t, path)
 }
 
+func TestFind_Workspace(t *testing.T) {
+	// Create workspace structure:
+	// tmpdir/
+	//   .rr.yaml      <- workspace config, opted in
+	//   repo/
+	//     .git/       <- git root
+	//     src/
+	tests := []struct {
+		name   string
+		config string
+		marker bool
+		found  bool
+	}{
+		{name: "discovery: workspace", config: "version: 1\ndiscovery: workspace\n", found: true},
+		{name: ".rr-root marker", config: "version: 1\n", marker: true, found: true},
+		{name: "discovery: git", config: "version: 1\ndiscovery: git\n", found: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpdir := t.TempDir()
+			configPath := filepath.Join(tmpdir, ConfigFileName)
+			require.NoError(t, os.WriteFile(configPath, []byte(tt.config), 0644))
+			if tt.marker {
+				require.NoError(t, os.WriteFile(filepath.Join(tmpdir, WorkspaceMarkerFile), nil, 0644))
+			}
+			subdir := filepath.Join(tmpdir, "repo", "src")
+			require.NoError(t, os.MkdirAll(filepath.Join(tmpdir, "repo", ".git"), 0755))
+			require.NoError(t, os.MkdirAll(subdir, 0755))
+
+			t.Chdir(subdir)
+
+			path, err := Find("")
+			require.NoError(t, err)
+			if !tt.found {
+				assert.Empty(t, path)
+				return
+			}
+			expectedResolved, _ := filepath.EvalSymlinks(configPath)
+			actualResolved, _ := filepath.EvalSymlinks(path)
+			assert.Equal(t, expectedResolved, actualResolved)
+		})
+	}
+}
+
+func TestFind_WorkspaceStopsAtNearestConfig(t *testing.T) {
+	// tmpdir/
+	//   .rr.yaml      <- workspace config (not found: inner config is closer)
+	//   inner/
+	//     .rr.yaml    <- doesn't opt in
+	//     repo/.git/
+	//     repo/src/
+	tmpdir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpdir, ConfigFileName), []byte("version: 1\ndiscovery: workspace\n"), 0644))
+	inner := filepath.Join(tmpdir, "inner")
+	require.NoError(t, os.MkdirAll(filepath.Join(inner, "repo", ".git"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(inner, "repo", "src"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(inner, ConfigFileName), []byte("version: 1\n"), 0644))
+
+	t.Chdir(filepath.Join(inner, "repo", "src"))
+
+	path, err := Find("")
+	require.NoError(t, err)
+	assert.Empty(t, path)
+}
+
 func TestExpand(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -720,6 +1103,23 @@ func TestValidate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "from the future",
 		},
+		{
+			name: "workspace discovery",
+			config: &Config{
+				Version:   1,
+				Discovery: DiscoveryWorkspace,
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown discovery",
+			config: &Config{
+				Version:   1,
+				Discovery: "everywhere",
+			},
+			wantErr: true,
+			errMsg:  "discovery is 'everywhere'",
+		},
 		{
 			name: "host reference looks like SSH string",
 			config: &Config{
@@ -798,6 +1198,15 @@ func TestValidateGlobal(t *testing.T) {
 			wantErr:     true,
 			errContains: "from the future",
 		},
+		{
+			name: "unknown low_bandwidth",
+			config: &GlobalConfig{
+				Version:      1,
+				LowBandwidth: "sometimes",
+			},
+			wantErr:     true,
+			errContains: "low_bandwidth is 'sometimes'",
+		},
 		{
 			name: "invalid host config",
 			config: &GlobalConfig{
@@ -809,6 +1218,63 @@ func TestValidateGlobal(t *testing.T) {
 			wantErr:     true,
 			errContains: "needs at least one SSH",
 		},
+		{
+			name: "empty profile_files entry",
+			config: &GlobalConfig{
+				Version: 1,
+				Hosts: map[string]Host{
+					"dev": {SSH: []string{"dev"}, Dir: "/home", ProfileFiles: []string{"~/.cargo/env", " "}},
+				},
+			},
+			wantErr:     true,
+			errContains: "empty profile_files entry at position 2",
+		},
+		{
+			name: "invalid env var name",
+			config: &GlobalConfig{
+				Version: 1,
+				Hosts: map[string]Host{
+					"dev": {SSH: []string{"dev"}, Dir: "/home", Env: map[string]string{"MY-VAR": "x"}},
+				},
+			},
+			wantErr:     true,
+			errContains: "invalid env var name 'MY-VAR'",
+		},
+		{
+			name: "plugins by name",
+			config: &GlobalConfig{
+				Version: 1,
+				Plugins: PluginsConfig{Parsers: []string{"junit"}, Hooks: []string{"notify"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "plugin given as a path",
+			config: &GlobalConfig{
+				Version: 1,
+				Plugins: PluginsConfig{Hooks: []string{"/usr/local/bin/rr-notify"}},
+			},
+			wantErr:     true,
+			errContains: "invalid plugin name",
+		},
+		{
+			name: "plugin name with a dot",
+			config: &GlobalConfig{
+				Version: 1,
+				Plugins: PluginsConfig{Hooks: []string{"notify.sh"}},
+			},
+			wantErr:     true,
+			errContains: "invalid plugin name",
+		},
+		{
+			name: "plugin given with its prefix",
+			config: &GlobalConfig{
+				Version: 1,
+				Plugins: PluginsConfig{Parsers: []string{"rr-junit"}},
+			},
+			wantErr:     true,
+			errContains: "with its rr- prefix",
+		},
 		{
 			name: "empty hosts is allowed for global config",
 			config: &GlobalConfig{
@@ -917,6 +1383,24 @@ func TestValidateResolved(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "project mirrors to nonexistent host",
+			resolved: &ResolvedConfig{
+				Global: &GlobalConfig{
+					Version: 1,
+					Hosts: map[string]Host{
+						"dev": {SSH: []string{"dev"}, Dir: "/home/dev"},
+					},
+				},
+				Project: &Config{
+					Version:  1,
+					Host:     "dev",
+					MirrorTo: "spare",
+				},
+			},
+			wantErr:     true,
+			errContains: "mirrors to host 'spare'",
+		},
 	}
 
 	for _, tt := range tests {
@@ -1106,7 +1590,7 @@ func TestValidateOutput(t *testing.T) {
 }
 
 func TestIsReservedTaskName(t *testing.T) {
-	reserved := []string{"run", "exec", "sync", "init", "setup", "status", "monitor", "doctor", "help", "version", "completion", "update"}
+	reserved := []string{"run", "exec", "sync", "init", "onboard", "setup", "status", "monitor", "doctor", "help", "version", "completion", "update", "replay"}
 	for _, name := range reserved {
 		assert.True(t, IsReservedTaskName(name), "expected %q to be reserved", name)
 	}
@@ -1117,6 +1601,17 @@ func TestIsReservedTaskName(t *testing.T) {
 	}
 }
 
+func TestValidateSync(t *testing.T) {
+	assert.NoError(t, validateSync(SyncConfig{}))
+	assert.NoError(t, validateSync(SyncConfig{Parallel: MaxSyncParallel}))
+
+	err := validateSync(SyncConfig{Parallel: -1})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "sync.parallel")
+
+	assert.Error(t, validateSync(SyncConfig{Parallel: MaxSyncParallel + 1}))
+}
+
 func TestValidateLock(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -1156,6 +1651,17 @@ func TestValidateLock(t *testing.T) {
 			lock:    LockConfig{Enabled: true, Timeout: 0, Stale: 5 * time.Minute},
 			wantErr: false,
 		},
+		{
+			name:    "project scope",
+			lock:    LockConfig{Enabled: true, Scope: LockScopeProject},
+			wantErr: false,
+		},
+		{
+			name:    "unknown scope",
+			lock:    LockConfig{Enabled: true, Scope: "global"},
+			wantErr: true,
+			errMsg:  "lock.scope",
+		},
 		{
 			name:    "zero stale is allowed",
 			lock:    LockConfig{Enabled: true, Timeout: 5 * time.Minute, Stale: 0},
@@ -1225,6 +1731,33 @@ func TestValidateMonitor(t *testing.T) {
 			wantErr: true,
 			errMsg:  "empty entry",
 		},
+		{
+			name:    "negative concurrency",
+			monitor: MonitorConfig{Interval: "2s", Concurrency: -1},
+			hosts:   validHost,
+			wantErr: true,
+			errMsg:  "monitor.concurrency can't be negative",
+		},
+		{
+			name:    "cards in a custom order",
+			monitor: MonitorConfig{Interval: "2s", Cards: []string{"gpu", "cpu", "net"}},
+			hosts:   validHost,
+			wantErr: false,
+		},
+		{
+			name:    "unknown card section",
+			monitor: MonitorConfig{Interval: "2s", Cards: []string{"cpu", "disk"}},
+			hosts:   validHost,
+			wantErr: true,
+			errMsg:  "monitor.cards has 'disk', which isn't a card section",
+		},
+		{
+			name:    "card section listed twice",
+			monitor: MonitorConfig{Interval: "2s", Cards: []string{"cpu", "ram", "cpu"}},
+			hosts:   validHost,
+			wantErr: true,
+			errMsg:  "monitor.cards lists 'cpu' twice",
+		},
 		{
 			name: "exclude with valid non-existent host (warning only)",
 			monitor: MonitorConfig{
@@ -1688,6 +2221,23 @@ func TestPullItemFromInterface(t *testing.T) {
 			input:   map[string]interface{}{"src": "file.txt", "dest": 123},
 			wantErr: true,
 		},
+		{
+			name:     "map with max_size",
+			input:    map[string]interface{}{"src": "dist/", "max_size": "500MB"},
+			expected: PullItem{Src: "dist/", MaxSize: "500MB"},
+			wantErr:  false,
+		},
+		{
+			name:     "max_size in bytes",
+			input:    map[string]interface{}{"src": "dist/", "max_size": 4096},
+			expected: PullItem{Src: "dist/", MaxSize: "4096"},
+			wantErr:  false,
+		},
+		{
+			name:    "max_size not a string",
+			input:   map[string]interface{}{"src": "dist/", "max_size": []string{"1GB"}},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -1699,6 +2249,7 @@ func TestPullItemFromInterface(t *testing.T) {
 				require.NoError(t, err)
 				assert.Equal(t, tt.expected.Src, result.Src)
 				assert.Equal(t, tt.expected.Dest, result.Dest)
+				assert.Equal(t, tt.expected.MaxSize, result.MaxSize)
 			}
 		})
 	}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 )
 
@@ -41,6 +42,7 @@ func ExpandTilde(path string) string {
 //   - ${PROJECT} - git repo name or directory name
 //   - ${USER}    - current username
 //   - ${HOME}    - user's home directory (LOCAL - use ExpandRemote for remote paths)
+//   - ${BRANCH}  - current git branch, made safe for a directory name
 //
 // Note: Does NOT expand ~ - use ExpandTilde for local paths if needed.
 // For remote paths (like host.Dir), use ExpandRemote instead.
@@ -64,6 +66,10 @@ func Expand(s string) string {
 		result = strings.ReplaceAll(result, "${HOME}", getHome())
 	}
 
+	if strings.Contains(result, "${BRANCH}") {
+		result = strings.ReplaceAll(result, "${BRANCH}", getBranch())
+	}
+
 	return result
 }
 
@@ -73,6 +79,7 @@ func Expand(s string) string {
 //   - ${PROJECT} - git repo name or directory name (from local context)
 //   - ${USER}    - current username (from local context)
 //   - ${HOME}    - expands to ~ (for remote shell to expand)
+//   - ${BRANCH}  - current git branch, made safe for a directory name (from local context)
 //   - ~          - kept as ~ (for remote shell to expand)
 func ExpandRemote(s string) string {
 	if s == "" {
@@ -94,6 +101,10 @@ func ExpandRemote(s string) string {
 		result = strings.ReplaceAll(result, "${HOME}", "~")
 	}
 
+	if strings.Contains(result, "${BRANCH}") {
+		result = strings.ReplaceAll(result, "${BRANCH}", getBranch())
+	}
+
 	return result
 }
 
@@ -104,6 +115,71 @@ func ExpandHost(h Host) Host {
 	return h
 }
 
+// ProjectName returns the name ${PROJECT} expands to: the git repo's name,
+// or the current directory's.
+func ProjectName() string {
+	return getProject()
+}
+
+// NoBranch is what ${BRANCH} expands to outside a git repository.
+const NoBranch = "default"
+
+// BranchName returns the name ${BRANCH} expands to for the current
+// directory: the checked-out branch, "detached-<commit>" on a detached
+// HEAD, or NoBranch outside a git repository.
+func BranchName() string {
+	return getBranch()
+}
+
+// LocalBranches returns the ${BRANCH} names of every local branch in the
+// current repository.
+func LocalBranches() ([]string, error) {
+	out, err := exec.Command("git", "for-each-ref", "--format=%(refname:short)", "refs/heads").Output()
+	if err != nil {
+		return nil, err
+	}
+	var branches []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			branches = append(branches, SanitizeBranch(line))
+		}
+	}
+	return branches, nil
+}
+
+// getBranch returns the branch name for ${BRANCH} expansion.
+func getBranch() string {
+	out, err := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		return NoBranch
+	}
+	branch := strings.TrimSpace(string(out))
+	if branch == "HEAD" {
+		commit, err := exec.Command("git", "rev-parse", "--short", "HEAD").Output()
+		if err != nil {
+			return NoBranch
+		}
+		branch = "detached-" + strings.TrimSpace(string(commit))
+	}
+	return SanitizeBranch(branch)
+}
+
+// unsafeBranchChars are the characters a branch name can have that don't
+// belong in a directory name, or that the remote shell would treat
+// specially.
+var unsafeBranchChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// SanitizeBranch turns a git branch name into a single directory name:
+// slashes and other unsafe characters become "-", so "feature/login"
+// becomes "feature-login".
+func SanitizeBranch(branch string) string {
+	name := strings.Trim(unsafeBranchChars.ReplaceAllString(branch, "-"), "-.")
+	if name == "" {
+		return NoBranch
+	}
+	return name
+}
+
 // getProject returns the project name for ${PROJECT} expansion.
 // Priority: git repo name > directory name.
 func getProject() string {
@@ -195,3 +271,13 @@ func getHome() string {
 
 	return "~"
 }
+
+// DirTemplate returns a host's dir as written, before variables like
+// ${PROJECT} and ${BRANCH} were expanded. Hosts added after loading return
+// their dir as it is.
+func (c *GlobalConfig) DirTemplate(name string) string {
+	if tmpl, ok := c.dirTemplates[name]; ok {
+		return tmpl
+	}
+	return c.Hosts[name].Dir
+}
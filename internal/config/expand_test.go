@@ -2,9 +2,11 @@ package config
 
 import (
 	"os"
+	"os/exec"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestExpandRemote(t *testing.T) {
@@ -68,3 +70,64 @@ func TestExpand_vs_ExpandRemote(t *testing.T) {
 	expandRemoteResult := ExpandRemote("${HOME}/test")
 	assert.Equal(t, "~/test", expandRemoteResult)
 }
+
+func TestSanitizeBranch(t *testing.T) {
+	assert.Equal(t, "main", SanitizeBranch("main"))
+	assert.Equal(t, "feature-login", SanitizeBranch("feature/login"))
+	assert.Equal(t, "fix-a-b-c", SanitizeBranch("fix/a b$c"))
+	assert.Equal(t, "v1.2", SanitizeBranch("v1.2"))
+	assert.Equal(t, NoBranch, SanitizeBranch("../"), "never a parent directory")
+	assert.Equal(t, NoBranch, SanitizeBranch(""))
+}
+
+func TestExpandRemote_Branch(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	assert.Equal(t, "~/rr/"+NoBranch, ExpandRemote("~/rr/${BRANCH}"), "outside a git repo")
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@t"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	git("init", "-q", "-b", "feature/login")
+	git("commit", "-q", "--allow-empty", "-m", "init")
+	git("branch", "old/work")
+
+	assert.Equal(t, "~/rr/feature-login", ExpandRemote("~/rr/${BRANCH}"))
+	assert.Equal(t, "feature-login", BranchName())
+
+	branches, err := LocalBranches()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"feature-login", "old-work"}, branches)
+
+	git("checkout", "-q", "--detach")
+	assert.Regexp(t, `^detached-[0-9a-f]+$`, BranchName())
+}
+
+func TestDirTemplate(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.MkdirAll(home+"/.rr", 0755))
+	content := `
+version: 1
+hosts:
+  gpu:
+    ssh: [gpu]
+    tags: [ci]
+tag_defaults:
+  ci:
+    dir: ~/rr/${BRANCH}
+`
+	require.NoError(t, os.WriteFile(home+"/.rr/config.yaml", []byte(content), 0644))
+
+	cfg, err := LoadGlobal()
+	require.NoError(t, err)
+	assert.Equal(t, "~/rr/"+NoBranch, cfg.Hosts["gpu"].Dir)
+	assert.Equal(t, "~/rr/${BRANCH}", cfg.DirTemplate("gpu"), "template includes tag defaults")
+}
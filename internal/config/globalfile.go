@@ -0,0 +1,101 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/util"
+)
+
+// globalLockWait is how long SaveGlobal waits for another rr command to
+// finish saving the global config. Swappable for tests.
+var globalLockWait = 5 * time.Second
+
+// Retries while waiting for the global config's lock.
+const (
+	// globalLockRetry is the first wait between tries. It doubles each
+	// time, up to globalLockMaxRetry.
+	globalLockRetry    = 25 * time.Millisecond
+	globalLockMaxRetry = 500 * time.Millisecond
+)
+
+// lockGlobal takes the lock on the global config at path, held in
+// path + ".lock" while a command saves it, so two rr commands saving at
+// once take turns. It retries with backoff for up to globalLockWait.
+// Returns the function that releases it.
+func lockGlobal(path string) (func(), error) {
+	lockPath := path + ".lock"
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, errors.WrapWithCode(err, errors.ErrConfig,
+			"Can't open the global config's lock file "+lockPath,
+			"Check your permissions on ~/.rr.")
+	}
+
+	deadline := time.Now().Add(globalLockWait)
+	wait := globalLockRetry
+	for {
+		locked, err := tryLockFile(f)
+		if err != nil {
+			f.Close()
+			return nil, errors.WrapWithCode(err, errors.ErrConfig,
+				"Can't lock "+lockPath,
+				"Check your permissions on ~/.rr.")
+		}
+		if locked {
+			return func() {
+				_ = unlockFile(f)
+				f.Close()
+			}, nil
+		}
+		if time.Now().Add(wait).After(deadline) {
+			f.Close()
+			return nil, errors.New(errors.ErrConfig,
+				fmt.Sprintf("Another rr command has been saving %s for over %s", path, globalLockWait),
+				"Let the other 'rr init' or 'rr host' command finish, then try again.")
+		}
+		time.Sleep(wait)
+		wait = min(wait*2, globalLockMaxRetry)
+	}
+}
+
+// fileHash returns the SHA-256 of a config file's contents, for telling
+// whether it changed. A missing file hashes like an empty one.
+func fileHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// checkGlobalUnchanged returns a conflict error if the global config at path
+// no longer holds what cfg was loaded from, meaning something else saved it
+// in between. A config that wasn't loaded from the file isn't checked.
+func checkGlobalUnchanged(cfg *GlobalConfig, path string) error {
+	if cfg.read == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.WrapWithCode(err, errors.ErrConfig,
+			"Can't read global config "+path,
+			"Check your permissions.")
+	}
+	if fileHash(data) == cfg.read {
+		return nil
+	}
+	return errors.New(errors.ErrConfig,
+		fmt.Sprintf("%s changed while this command was running, so it wasn't saved", path),
+		"Another rr command or an editor saved it first. Run the command again to make your change on top of theirs.")
+}
+
+// writeFileAtomic replaces a file atomically, keeping its permissions, or
+// giving it perm if it's new. write gets the temporary file's path.
+func writeFileAtomic(path string, perm os.FileMode, write func(tmp string) error) error {
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	return util.WriteFileAtomicFunc(path, perm, write)
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rileyhilliard/rr/internal/util"
+)
+
+// envNameRegex matches names that can be exported from a POSIX shell.
+var envNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// IsEnvName reports whether name can be exported from a POSIX shell.
+func IsEnvName(name string) bool {
+	return envNameRegex.MatchString(name)
+}
+
+// HostProfileCommands returns a command for each of the host's profile_files
+// that sources the file if it exists. A missing file is skipped rather than
+// failing the command, since tools like cargo aren't installed everywhere.
+func HostProfileCommands(host *Host) []string {
+	if host == nil {
+		return nil
+	}
+	cmds := make([]string, 0, len(host.ProfileFiles))
+	for _, file := range host.ProfileFiles {
+		quoted := util.ShellQuotePreserveTilde(file)
+		cmds = append(cmds, fmt.Sprintf("if [ -f %s ]; then . %s; fi", quoted, quoted))
+	}
+	return cmds
+}
+
+// HostEnvCommands returns an export command for each of the host's env vars,
+// sorted by name so the generated command is stable. Values are double
+// quoted, so references like $HOME and $PATH expand on the remote.
+func HostEnvCommands(host *Host) []string {
+	if host == nil || len(host.Env) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(host.Env))
+	for name := range host.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	cmds := make([]string, 0, len(names))
+	for _, name := range names {
+		cmds = append(cmds, fmt.Sprintf("export %s=%q", name, host.Env[name]))
+	}
+	return cmds
+}
+
+// WrapHostCommand prefixes cmd with the host's default environment: profile
+// files are sourced, then env is exported. It's used for the commands rr runs
+// on its own behalf (locking, metrics), so they see the same environment as
+// user commands. Returns cmd unchanged when the host sets neither.
+func WrapHostCommand(host *Host, cmd string) string {
+	parts := append(HostProfileCommands(host), HostEnvCommands(host)...)
+	if len(parts) == 0 {
+		return cmd
+	}
+	return strings.Join(append(parts, cmd), " && ")
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostProfileCommands(t *testing.T) {
+	host := &Host{ProfileFiles: []string{"~/.cargo/env", "/opt/my tools/env.sh"}}
+	assert.Equal(t, []string{
+		"if [ -f ~/'.cargo/env' ]; then . ~/'.cargo/env'; fi",
+		"if [ -f '/opt/my tools/env.sh' ]; then . '/opt/my tools/env.sh'; fi",
+	}, HostProfileCommands(host))
+
+	assert.Empty(t, HostProfileCommands(nil))
+	assert.Empty(t, HostProfileCommands(&Host{}))
+}
+
+func TestHostEnvCommands(t *testing.T) {
+	host := &Host{Env: map[string]string{
+		"PATH":      "$HOME/.cargo/bin:$PATH",
+		"CARGO_DIR": "/srv/cargo",
+	}}
+	// Sorted by name, double quoted so $ references expand remotely
+	assert.Equal(t, []string{
+		`export CARGO_DIR="/srv/cargo"`,
+		`export PATH="$HOME/.cargo/bin:$PATH"`,
+	}, HostEnvCommands(host))
+
+	assert.Empty(t, HostEnvCommands(nil))
+}
+
+func TestWrapHostCommand(t *testing.T) {
+	assert.Equal(t, "mkdir /tmp/x", WrapHostCommand(&Host{}, "mkdir /tmp/x"))
+	assert.Equal(t, "mkdir /tmp/x", WrapHostCommand(nil, "mkdir /tmp/x"))
+
+	host := &Host{
+		ProfileFiles: []string{"~/.cargo/env"},
+		Env:          map[string]string{"TMPDIR": "/scratch"},
+	}
+	assert.Equal(t,
+		`if [ -f ~/'.cargo/env' ]; then . ~/'.cargo/env'; fi && export TMPDIR="/scratch" && mkdir /tmp/x`,
+		WrapHostCommand(host, "mkdir /tmp/x"))
+}
+
+func TestGetMergedSetupCommands_ProfileFilesFirst(t *testing.T) {
+	cfg := &Config{Defaults: ProjectDefaults{Setup: []string{"nvm use"}}}
+	host := &Host{
+		ProfileFiles:  []string{"~/.cargo/env"},
+		SetupCommands: []string{"export PATH=/opt/go/bin:$PATH"},
+	}
+	assert.Equal(t, []string{
+		CacheSetupCommand(cfg.Cache),
+		"if [ -f ~/'.cargo/env' ]; then . ~/'.cargo/env'; fi",
+		"export PATH=/opt/go/bin:$PATH",
+		"nvm use",
+	}, GetMergedSetupCommands(cfg, host))
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// inventoryPrefixRegex limits prefixes to characters that are safe in host
+// names, which also end up in lock and log file names.
+var inventoryPrefixRegex = regexp.MustCompile(`^[A-Za-z0-9_.-]*$`)
+
+// AddDiscoveredHost adds a host found by an inventory provider, with tag
+// defaults merged in and its dir expanded like a declared host's. A host
+// already named name wins and nothing is added. Returns whether it was.
+func (c *GlobalConfig) AddDiscoveredHost(name, provider string, h Host) bool {
+	if _, exists := c.Hosts[name]; exists {
+		return false
+	}
+	if c.Hosts == nil {
+		c.Hosts = make(map[string]Host)
+	}
+	if c.discovered == nil {
+		c.discovered = make(map[string]string)
+	}
+	if c.dirTemplates == nil {
+		c.dirTemplates = make(map[string]string)
+	}
+	h = ApplyTagDefaults(h, c.TagDefaults)
+	c.dirTemplates[name] = h.Dir
+	h.Dir = ExpandRemote(h.Dir)
+	c.Hosts[name] = h
+	c.discovered[name] = provider
+	return true
+}
+
+// DiscoveredBy returns the inventory provider that found a host, or "" for
+// a host declared in the config.
+func (c *GlobalConfig) DiscoveredBy(name string) string {
+	return c.discovered[name]
+}
+
+// validateInventory checks the inventory sources. Each setting has to belong
+// to the provider it's given for, so a typo'd provider or a filter on the
+// wrong one is caught instead of quietly matching everything.
+func validateInventory(cfg *GlobalConfig) error {
+	for i, src := range cfg.Inventory {
+		where := fmt.Sprintf("inventory entry %d", i+1)
+		switch src.Provider {
+		case InventoryTailscale, InventoryAWS, InventoryGCP:
+			where += " (" + src.Provider + ")"
+		case "":
+			return fmt.Errorf("%s needs a provider: tailscale, aws, or gcp", where)
+		default:
+			return fmt.Errorf("%s has provider '%s' - use tailscale, aws, or gcp", where, src.Provider)
+		}
+
+		if src.Tag != "" && src.Provider != InventoryTailscale {
+			return fmt.Errorf("%s sets tag, which only tailscale uses - use filters to match %s tags or labels", where, src.Provider)
+		}
+		if len(src.Filters) > 0 && src.Provider == InventoryTailscale {
+			return fmt.Errorf("%s sets filters, which tailscale doesn't use - use tag to pick peers by ACL tag", where)
+		}
+		if src.Region != "" && src.Provider != InventoryAWS {
+			return fmt.Errorf("%s sets region, which only aws uses", where)
+		}
+		if src.Project != "" && src.Provider != InventoryGCP {
+			return fmt.Errorf("%s sets project, which only gcp uses", where)
+		}
+		for key := range src.Filters {
+			if strings.TrimSpace(key) == "" {
+				return fmt.Errorf("%s has a filter with an empty key", where)
+			}
+		}
+		if !inventoryPrefixRegex.MatchString(src.Prefix) {
+			return fmt.Errorf("%s has prefix '%s' - use letters, digits, '.', '_', and '-'", where, src.Prefix)
+		}
+		if src.CacheTTL < 0 {
+			return fmt.Errorf("%s has a negative cache_ttl", where)
+		}
+
+		if src.Dir == "" && !tagsSetDir(src.Tags, cfg.TagDefaults) {
+			return fmt.Errorf("%s needs a 'dir', or a tag whose tag_defaults set one", where)
+		}
+	}
+	return nil
+}
+
+// tagsSetDir reports whether any of tags has tag defaults with a dir.
+func tagsSetDir(tags []string, tagDefaults map[string]HostDefaults) bool {
+	for name, d := range tagDefaults {
+		if d.Dir != "" && slices.ContainsFunc(tags, func(tag string) bool { return strings.EqualFold(tag, name) }) {
+			return true
+		}
+	}
+	return false
+}
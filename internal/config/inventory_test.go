@@ -0,0 +1,113 @@
+package config
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const inventoryConfig = `
+version: 1
+tag_defaults:
+  gpu:
+    dir: /scratch/${USER}/rr
+    setup_commands:
+      - source /opt/conda/bin/activate
+hosts:
+  mini:
+    ssh: [mini-lan]
+    dir: ~/rr
+inventory:
+  - provider: tailscale
+    tag: gpu
+    tags: [gpu]
+    prefix: ts-
+    cache_ttl: 10m
+  - provider: aws
+    region: us-west-2
+    filters:
+      team: ml
+    user: ubuntu
+    dir: ~/rr/${PROJECT}
+`
+
+func TestLoadGlobal_Inventory(t *testing.T) {
+	writeGlobalConfig(t, inventoryConfig)
+
+	cfg, err := LoadGlobal()
+	require.NoError(t, err)
+	require.Len(t, cfg.Inventory, 2)
+	assert.Equal(t, InventoryConfig{
+		Provider: InventoryTailscale,
+		Tag:      "gpu",
+		Tags:     []string{"gpu"},
+		Prefix:   "ts-",
+		CacheTTL: 10 * time.Minute,
+	}, cfg.Inventory[0])
+	assert.Equal(t, map[string]string{"team": "ml"}, cfg.Inventory[1].Filters)
+	require.NoError(t, ValidateGlobal(cfg))
+}
+
+func TestAddDiscoveredHost(t *testing.T) {
+	path := writeGlobalConfig(t, inventoryConfig)
+	cfg, err := LoadGlobal()
+	require.NoError(t, err)
+
+	assert.True(t, cfg.AddDiscoveredHost("ts-a100", InventoryTailscale, Host{SSH: []string{"a100.tailnet.ts.net"}, Tags: []string{"gpu"}}))
+	assert.False(t, cfg.AddDiscoveredHost("mini", InventoryTailscale, Host{SSH: []string{"mini.tailnet.ts.net"}, Dir: "~/other"}),
+		"declared hosts win")
+
+	a100 := cfg.Hosts["ts-a100"]
+	assert.Equal(t, "/scratch/"+getUser()+"/rr", a100.Dir, "tag defaults apply")
+	assert.Equal(t, []string{"source /opt/conda/bin/activate"}, a100.SetupCommands)
+	assert.Equal(t, []string{"mini-lan"}, cfg.Hosts["mini"].SSH)
+	assert.Equal(t, InventoryTailscale, cfg.DiscoveredBy("ts-a100"))
+	assert.Empty(t, cfg.DiscoveredBy("mini"))
+	require.NoError(t, ValidateGlobal(cfg))
+
+	// Saving keeps the inventory but not what it found
+	require.NoError(t, SaveGlobal(cfg))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var saved GlobalConfig
+	require.NoError(t, yaml.Unmarshal(data, &saved))
+	assert.NotContains(t, saved.Hosts, "ts-a100")
+	assert.Contains(t, saved.Hosts, "mini")
+	assert.Len(t, saved.Inventory, 2)
+}
+
+func TestValidateInventory(t *testing.T) {
+	tagDefaults := map[string]HostDefaults{"gpu": {Dir: "/scratch/rr"}}
+	tests := []struct {
+		name    string
+		src     InventoryConfig
+		wantErr string
+	}{
+		{"tailscale with tag", InventoryConfig{Provider: "tailscale", Tag: "gpu", Dir: "~/rr"}, ""},
+		{"dir from tag defaults", InventoryConfig{Provider: "gcp", Tags: []string{"GPU"}}, ""},
+		{"missing provider", InventoryConfig{Dir: "~/rr"}, "needs a provider"},
+		{"unknown provider", InventoryConfig{Provider: "azure", Dir: "~/rr"}, "provider 'azure'"},
+		{"tag on aws", InventoryConfig{Provider: "aws", Tag: "gpu", Dir: "~/rr"}, "only tailscale uses"},
+		{"filters on tailscale", InventoryConfig{Provider: "tailscale", Filters: map[string]string{"a": "b"}, Dir: "~/rr"}, "doesn't use"},
+		{"region on gcp", InventoryConfig{Provider: "gcp", Region: "us-west-2", Dir: "~/rr"}, "only aws uses"},
+		{"project on aws", InventoryConfig{Provider: "aws", Project: "p", Dir: "~/rr"}, "only gcp uses"},
+		{"bad prefix", InventoryConfig{Provider: "aws", Prefix: "a/b", Dir: "~/rr"}, "prefix 'a/b'"},
+		{"negative ttl", InventoryConfig{Provider: "aws", CacheTTL: -time.Second, Dir: "~/rr"}, "negative cache_ttl"},
+		{"no dir", InventoryConfig{Provider: "aws", Tags: []string{"cpu"}}, "needs a 'dir'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateInventory(&GlobalConfig{TagDefaults: tagDefaults, Inventory: []InventoryConfig{tt.src}})
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -10,6 +11,7 @@ import (
 	"github.com/rileyhilliard/rr/internal/errors"
 	"github.com/rileyhilliard/rr/internal/util"
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
 )
 
 const (
@@ -19,6 +21,15 @@ const (
 	GlobalConfigDir = ".rr"
 	// GlobalConfigFile is the global config file name.
 	GlobalConfigFile = "config.yaml"
+	// WorkspaceMarkerFile marks a directory above several git repos whose
+	// .rr.yaml they share, like discovery: workspace does.
+	WorkspaceMarkerFile = ".rr-root"
+)
+
+// Settings for Config.Discovery.
+const (
+	DiscoveryGit       = "git"       // Only found from inside its own git repo (default)
+	DiscoveryWorkspace = "workspace" // Also found from the git repos below it
 )
 
 // Load reads project config from the specified path.
@@ -30,11 +41,13 @@ func Load(path string) (*Config, error) {
 		if os.IsNotExist(err) {
 			return nil, errors.WrapWithCode(err, errors.ErrConfig,
 				"Can't find the config file",
-				"Looks like you haven't set up shop here yet. Run 'rr init' to get started.")
+				"Looks like you haven't set up shop here yet. Run 'rr init' to get started.").
+				WithID(errors.IDConfigNotFound)
 		}
 		return nil, errors.WrapWithCode(err, errors.ErrConfig,
 			"Couldn't read the config file",
-			"Something's off with your .rr.yaml. Check that it's valid YAML.")
+			"Something's off with your .rr.yaml. Check that it's valid YAML.").
+			WithID(errors.IDConfigInvalid)
 	}
 
 	return parseConfig(v, path)
@@ -77,25 +90,42 @@ func LoadGlobal() (*GlobalConfig, error) {
 		return nil, err
 	}
 
-	// Check if file exists
-	if _, err := os.Stat(path); os.IsNotExist(err) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
 		// Return defaults if no global config exists yet
-		return DefaultGlobalConfig(), nil
+		cfg := DefaultGlobalConfig()
+		cfg.read = fileHash(nil)
+		useSecretsBackend(cfg.Secrets)
+		return cfg, nil
 	}
 
 	v := viper.New()
 	v.SetConfigFile(path)
 
-	if err := v.ReadInConfig(); err != nil {
+	if err == nil {
+		err = v.ReadConfig(bytes.NewReader(data))
+	}
+	if err != nil {
 		return nil, errors.WrapWithCode(err, errors.ErrConfig,
 			"Couldn't read global config",
-			"Check your ~/.rr/config.yaml for valid YAML syntax.")
+			"Check your ~/.rr/config.yaml for valid YAML syntax.").
+			WithID(errors.IDConfigInvalid)
 	}
 
-	return parseGlobalConfig(v, path)
+	cfg, err := parseGlobalConfig(v, path)
+	if err != nil {
+		return nil, err
+	}
+	cfg.read = fileHash(data)
+	useSecretsBackend(cfg.Secrets)
+	return cfg, nil
 }
 
-// SaveGlobal writes global config to ~/.rr/config.yaml.
+// SaveGlobal writes global config to ~/.rr/config.yaml. It holds a lock on
+// the file while it saves and replaces it in one rename, so rr commands
+// saving at once can't leave it half written. If cfg came from LoadGlobal
+// and the file has changed since, it returns a conflict error instead of
+// overwriting the other change.
 func SaveGlobal(cfg *GlobalConfig) error {
 	if err := EnsureGlobalConfigDir(); err != nil {
 		return err
@@ -108,15 +138,54 @@ func SaveGlobal(cfg *GlobalConfig) error {
 
 	v := viper.New()
 	v.Set("version", cfg.Version)
-	v.Set("hosts", cfg.Hosts)
+	v.Set("hosts", cfg.hostsToSave())
 	v.Set("defaults", cfg.Defaults)
+	if len(cfg.TagDefaults) > 0 {
+		v.Set("tag_defaults", cfg.TagDefaults)
+	}
+	if cfg.Theme.Name != "" || len(cfg.Theme.Colors) > 0 {
+		v.Set("theme", cfg.Theme)
+	}
+	if len(cfg.Plugins.Parsers) > 0 || len(cfg.Plugins.Hooks) > 0 {
+		v.Set("plugins", cfg.Plugins)
+	}
+	if len(cfg.Inventory) > 0 {
+		v.Set("inventory", cfg.Inventory)
+	}
+	if cfg.Accessible {
+		v.Set("accessible", true)
+	}
+	if cfg.LowBandwidth != "" {
+		v.Set("low_bandwidth", cfg.LowBandwidth)
+	}
+	if cfg.Stats {
+		v.Set("stats", true)
+	}
+	if cfg.Secrets != "" {
+		v.Set("secrets", cfg.Secrets)
+	}
+
+	unlock, err := lockGlobal(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
-	if err := v.WriteConfigAs(path); err != nil {
+	if err := checkGlobalUnchanged(cfg, path); err != nil {
+		return err
+	}
+	write := func() error {
+		return writeFileAtomic(path, 0644, v.WriteConfigAs)
+	}
+	if err := trackWrite(path, write); err != nil {
 		return errors.WrapWithCode(err, errors.ErrConfig,
 			"Can't save global config to "+path,
 			"Check your permissions.")
 	}
 
+	if data, err := os.ReadFile(path); err == nil {
+		cfg.read = fileHash(data)
+	}
 	return nil
 }
 
@@ -131,14 +200,22 @@ func parseGlobalConfig(v *viper.Viper, path string) (*GlobalConfig, error) {
 	if err := v.Unmarshal(cfg); err != nil {
 		return nil, errors.WrapWithCode(err, errors.ErrConfig,
 			"Global config has some issues",
-			"Check the YAML syntax in "+path+" - something's not parsing right.")
+			"Check the YAML syntax in "+path+" - something's not parsing right.").
+			WithID(errors.IDConfigInvalid)
 	}
 
-	// Expand variables in host directories
+	// Merge tag defaults into the hosts, then expand variables in host
+	// directories
+	cfg.declared = make(map[string]Host, len(cfg.Hosts))
+	cfg.loaded = make(map[string]Host, len(cfg.Hosts))
+	cfg.dirTemplates = make(map[string]string, len(cfg.Hosts))
 	for name := range cfg.Hosts {
-		h := cfg.Hosts[name]
+		cfg.declared[name] = cloneHost(cfg.Hosts[name])
+		h := ApplyTagDefaults(cfg.Hosts[name], cfg.TagDefaults)
+		cfg.dirTemplates[name] = h.Dir
 		h.Dir = ExpandRemote(h.Dir)
 		cfg.Hosts[name] = h
+		cfg.loaded[name] = cloneHost(h)
 	}
 
 	return cfg, nil
@@ -148,6 +225,7 @@ func parseGlobalConfig(v *viper.Viper, path string) (*GlobalConfig, error) {
 // 1. Explicit path (from --config flag)
 // 2. .rr.yaml in current directory
 // 3. .rr.yaml in parent directories (stops at git root or home)
+// 4. Past the git root, a workspace .rr.yaml that opts in (see findWorkspace)
 //
 // Returns the path to the config file, or empty string if not found.
 // Note: Global config (~/.rr/config.yaml) is loaded separately via LoadGlobal().
@@ -158,7 +236,8 @@ func Find(explicit string) (string, error) {
 			if os.IsNotExist(err) {
 				return "", errors.WrapWithCode(err, errors.ErrConfig,
 					"Can't find config file at "+explicit,
-					"Double-check that path - it doesn't seem to exist.")
+					"Double-check that path - it doesn't seem to exist.").
+					WithID(errors.IDConfigNotFound)
 			}
 			return "", errors.WrapWithCode(err, errors.ErrConfig,
 				"Can't access config file at "+explicit,
@@ -204,13 +283,57 @@ func Find(explicit string) (string, error) {
 		// Stop at git root (but only after checking for .rr.yaml in this directory)
 		gitPath := filepath.Join(dir, ".git")
 		if _, err := os.Stat(gitPath); err == nil {
-			break
+			return findWorkspace(dir, home), nil
 		}
 	}
 
 	return "", nil
 }
 
+// findWorkspace looks above the git root gitRoot for a workspace config: a
+// .rr.yaml that opts in to being found from the repos below it. The first
+// .rr.yaml or .rr-root up the tree ends the search either way, so a config
+// that doesn't opt in is never picked up from a repo inside its directory.
+func findWorkspace(gitRoot, home string) string {
+	dir := gitRoot
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir || (home != "" && parent == home) {
+			return ""
+		}
+		dir = parent
+
+		configPath := filepath.Join(dir, ConfigFileName)
+		_, err := os.Stat(filepath.Join(dir, WorkspaceMarkerFile))
+		marked := err == nil
+		if _, err := os.Stat(configPath); err == nil {
+			if marked || declaresWorkspace(configPath) {
+				return configPath
+			}
+			return ""
+		}
+		if marked {
+			return ""
+		}
+	}
+}
+
+// declaresWorkspace reports whether the config at path sets
+// discovery: workspace. A config that doesn't parse doesn't.
+func declaresWorkspace(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var cfg struct {
+		Discovery string `yaml:"discovery"`
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return false
+	}
+	return cfg.Discovery == DiscoveryWorkspace
+}
+
 // LoadOrDefault loads config from the found path, or returns defaults if not found.
 // This is useful for commands like 'rr init' that should work without existing config.
 func LoadOrDefault() (*Config, error) {
@@ -249,6 +372,9 @@ type ResolvedConfig struct {
 // LoadResolved loads both global and project configuration.
 // Global config is always loaded (or defaults used).
 // Project config is loaded if found (explicit path or search).
+// keychain: references are left unresolved, so commands that never connect
+// don't touch the keychain: ResolveHostSecrets, ResolveEnvSecrets and
+// GetTaskWithMergedEnv resolve them for the host and task in use.
 func LoadResolved(explicitPath string) (*ResolvedConfig, error) {
 	resolved := &ResolvedConfig{}
 
@@ -371,7 +497,8 @@ func ResolveHosts(resolved *ResolvedConfig, preferred string) ([]string, map[str
 		if !ok {
 			return nil, nil, errors.New(errors.ErrConfig,
 				"Host '"+name+"' not found in global config",
-				"Available hosts: "+util.JoinOrNone(available)+". Check ~/.rr/config.yaml.")
+				"Available hosts: "+util.JoinOrNone(available)+". Check ~/.rr/config.yaml.").
+				WithID(errors.IDConfigHostNotFound)
 		}
 		hosts[name] = host
 	}
@@ -424,11 +551,19 @@ func parseConfig(v *viper.Viper, path string) (*Config, error) {
 			mapstructure.StringToTimeDurationHookFunc(),
 			dependencyItemDecodeHook(),
 			pullItemDecodeHook(),
+			taskOutputDecodeHook(),
 		),
 	)); err != nil {
 		return nil, errors.WrapWithCode(err, errors.ErrConfig,
 			"Config file has some issues",
-			"Check the YAML syntax in "+path+" - something's not parsing right.")
+			"Check the YAML syntax in "+path+" - something's not parsing right.").
+			WithID(errors.IDConfigInvalid)
+	}
+
+	if err := resolveTaskReuse(cfg); err != nil {
+		return nil, errors.WrapWithCode(err, errors.ErrConfig, err.Error(),
+			"Check 'extends' and 'use' in the tasks and steps_lib of "+path+".").
+			WithID(errors.IDConfigInvalid)
 	}
 
 	return cfg, nil
@@ -470,6 +605,20 @@ func pullItemDecodeHook() mapstructure.DecodeHookFunc {
 	}
 }
 
+// taskOutputDecodeHook returns a decode hook that handles TaskOutput from
+// both string and map formats.
+func taskOutputDecodeHook() mapstructure.DecodeHookFunc {
+	return func(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+		if to != reflect.TypeOf(TaskOutput{}) {
+			return data, nil
+		}
+		if from.Kind() == reflect.String || from.Kind() == reflect.Map {
+			return TaskOutputFromInterface(data)
+		}
+		return data, nil
+	}
+}
+
 // setDurationDefaults configures viper to handle duration strings for project config.
 func setDurationDefaults(v *viper.Viper) {
 	// Viper handles duration parsing automatically for time.Duration fields
@@ -480,6 +629,8 @@ func setDurationDefaults(v *viper.Viper) {
 	v.SetDefault("lock.timeout", "5m")
 	v.SetDefault("lock.stale", "90s")
 	v.SetDefault("lock.dir", "/tmp/rr-locks")
+	v.SetDefault("lock.scope", LockScopeHost)
+	v.SetDefault("lock.share_output", true)
 	v.SetDefault("output.color", "auto")
 	v.SetDefault("output.format", "auto")
 	v.SetDefault("output.timing", true)
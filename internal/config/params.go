@@ -0,0 +1,186 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/util"
+)
+
+// paramPlaceholder matches a {{name}} placeholder for a task param. Names
+// are identifiers, so Go templates like {{.Name}} in a command are left
+// alone.
+var paramPlaceholder = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// paramName is what a param's name has to look like.
+var paramName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ParseParamFlags parses --param name=value flags into a map. A later flag
+// for the same name wins.
+func ParseParamFlags(flags []string) (map[string]string, error) {
+	values := make(map[string]string, len(flags))
+	for _, flag := range flags {
+		name, value, ok := strings.Cut(flag, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, errors.New(errors.ErrConfig,
+				fmt.Sprintf("--param '%s' isn't name=value", flag),
+				"Pass each param as --param name=value, e.g. --param env=staging.")
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// CheckParamValues checks values given for a task's params: each has to be
+// one of the task's params, and one of its choices if it has any.
+func CheckParamValues(taskName string, params []TaskParam, values map[string]string) error {
+	names := slices.Sorted(maps.Keys(values))
+	for _, name := range names {
+		i := slices.IndexFunc(params, func(p TaskParam) bool { return p.Name == name })
+		if i < 0 {
+			return errors.New(errors.ErrConfig,
+				fmt.Sprintf("Task '%s' has no param named '%s'", taskName, name),
+				paramsSuggestion(taskName, params))
+		}
+		if p := params[i]; len(p.Choices) > 0 && !slices.Contains(p.Choices, values[name]) {
+			return errors.New(errors.ErrConfig,
+				fmt.Sprintf("'%s' isn't one of the choices for param '%s'", values[name], name),
+				fmt.Sprintf("Pick one of: %s", strings.Join(p.Choices, ", ")))
+		}
+	}
+	return nil
+}
+
+// paramsSuggestion lists a task's params, for errors.
+func paramsSuggestion(taskName string, params []TaskParam) string {
+	if len(params) == 0 {
+		return fmt.Sprintf("Task '%s' doesn't take any params.", taskName)
+	}
+	names := make([]string, len(params))
+	for i, p := range params {
+		names[i] = p.Name
+	}
+	return fmt.Sprintf("Task '%s' takes: %s", taskName, strings.Join(names, ", "))
+}
+
+// ApplyTaskParams returns task with the {{name}} placeholders in its run
+// command, its steps, and its env values replaced by values. In commands,
+// each value is quoted for the shell, so it's passed as one word whatever
+// it contains.
+func ApplyTaskParams(task TaskConfig, values map[string]string) TaskConfig {
+	if len(task.Params) == 0 {
+		return task
+	}
+	command := func(s string) string {
+		return replaceParams(s, values, util.ShellQuote)
+	}
+	literal := func(s string) string {
+		return replaceParams(s, values, func(v string) string { return v })
+	}
+
+	task.Run = command(task.Run)
+	if len(task.Steps) > 0 {
+		steps := slices.Clone(task.Steps)
+		for i := range steps {
+			steps[i].Run = command(steps[i].Run)
+		}
+		task.Steps = steps
+	}
+	if len(task.Env) > 0 {
+		env := make(map[string]string, len(task.Env))
+		for k, v := range task.Env {
+			env[k] = literal(v)
+		}
+		task.Env = env
+	}
+	return task
+}
+
+// replaceParams replaces the placeholders in s for the params in values.
+func replaceParams(s string, values map[string]string, quote func(string) string) string {
+	return paramPlaceholder.ReplaceAllStringFunc(s, func(match string) string {
+		name := paramPlaceholder.FindStringSubmatch(match)[1]
+		if v, ok := values[name]; ok {
+			return quote(v)
+		}
+		return match
+	})
+}
+
+// validateParams checks a task's params: their names, that defaults are
+// among the choices, and that every placeholder names a param.
+func validateParams(name string, task TaskConfig) error {
+	if len(task.Params) == 0 {
+		return nil
+	}
+	switch {
+	case len(task.Parallel) > 0:
+		return fmt.Errorf("task '%s' has both 'params' and 'parallel' - put the params on a task that runs one command", name)
+	case task.Shard.Count > 0:
+		return fmt.Errorf("task '%s' has both 'params' and 'shard' - shards run without asking for anything", name)
+	case task.Type == TaskTypeWatch:
+		return fmt.Errorf("task '%s' has both 'params' and 'type: watch' - restarts run without asking for anything", name)
+	}
+
+	declared := make(map[string]bool, len(task.Params))
+	for i, p := range task.Params {
+		if p.Name == "" {
+			return fmt.Errorf("task '%s' param %d has no name", name, i+1)
+		}
+		if !paramName.MatchString(p.Name) {
+			return fmt.Errorf("task '%s' param '%s' needs a name of letters, digits, and underscores, not starting with a digit", name, p.Name)
+		}
+		if declared[p.Name] {
+			return fmt.Errorf("task '%s' has two params named '%s'", name, p.Name)
+		}
+		declared[p.Name] = true
+		if p.Default != "" && len(p.Choices) > 0 && !slices.Contains(p.Choices, p.Default) {
+			return fmt.Errorf("task '%s' param '%s' defaults to '%s', which isn't one of its choices", name, p.Name, p.Default)
+		}
+	}
+
+	texts := []string{task.Run}
+	for _, step := range task.Steps {
+		texts = append(texts, step.Run)
+	}
+	for _, v := range task.Env {
+		texts = append(texts, v)
+	}
+	for _, text := range texts {
+		for _, m := range paramPlaceholder.FindAllStringSubmatch(text, -1) {
+			if !declared[m[1]] {
+				return fmt.Errorf("task '%s' uses {{%s}} but has no param named '%s'", name, m[1], m[1])
+			}
+		}
+	}
+	return nil
+}
+
+// validateParamRefs checks that no task with params is run by another task,
+// as a dependency, subtask, or input: there'd be nobody to ask for them.
+func validateParamRefs(cfg *Config) error {
+	names := getTaskNames(cfg.Tasks)
+	sort.Strings(names)
+	for _, name := range names {
+		task := cfg.Tasks[name]
+		var refs []string
+		for _, dep := range task.Depends {
+			refs = append(refs, dep.Task)
+			refs = append(refs, dep.Parallel...)
+		}
+		refs = append(refs, task.Parallel...)
+		refs = append(refs, task.Inputs...)
+		for _, ref := range refs {
+			if len(cfg.Tasks[ref].Params) > 0 {
+				return fmt.Errorf("task '%s' runs '%s', which takes params - only a task run from the command line can ask for them", name, ref)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_TaskParams(t *testing.T) {
+	cfg, err := loadProject(t, `
+version: 1
+tasks:
+  deploy:
+    run: ./deploy.sh {{env}} --replicas {{ replicas }}
+    env:
+      DEPLOY_ENV: "{{env}}"
+    params:
+      - name: env
+        prompt: Deploy where?
+        choices: [staging, prod]
+        default: staging
+      - name: replicas
+        default: 3
+`)
+	require.NoError(t, err)
+
+	params := cfg.Tasks["deploy"].Params
+	require.Len(t, params, 2)
+	assert.Equal(t, TaskParam{Name: "env", Prompt: "Deploy where?", Default: "staging", Choices: []string{"staging", "prod"}}, params[0])
+	assert.Equal(t, "3", params[1].Default)
+}
+
+func TestApplyTaskParams(t *testing.T) {
+	task := TaskConfig{
+		Run:    "./deploy.sh {{env}} --tag {{ tag }} --format '{{.Name}}'",
+		Steps:  []TaskStep{{Run: "echo {{env}}"}},
+		Env:    map[string]string{"TARGET": "{{env}}-east"},
+		Params: []TaskParam{{Name: "env"}, {Name: "tag"}},
+	}
+
+	got := ApplyTaskParams(task, map[string]string{"env": "prod", "tag": "it's v2"})
+
+	assert.Equal(t, `./deploy.sh 'prod' --tag 'it'\''s v2' --format '{{.Name}}'`, got.Run)
+	assert.Equal(t, "echo 'prod'", got.Steps[0].Run)
+	assert.Equal(t, "prod-east", got.Env["TARGET"])
+
+	// The original is untouched
+	assert.Equal(t, "echo {{env}}", task.Steps[0].Run)
+	assert.Equal(t, "{{env}}-east", task.Env["TARGET"])
+}
+
+func TestParseParamFlags(t *testing.T) {
+	values, err := ParseParamFlags([]string{"env=prod", "note=a=b", "env=staging"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "staging", "note": "a=b"}, values)
+
+	_, err = ParseParamFlags([]string{"env"})
+	assert.ErrorContains(t, err, "isn't name=value")
+}
+
+func TestCheckParamValues(t *testing.T) {
+	params := []TaskParam{{Name: "env", Choices: []string{"staging", "prod"}}, {Name: "tag"}}
+
+	assert.NoError(t, CheckParamValues("deploy", params, map[string]string{"env": "prod", "tag": "anything"}))
+	assert.ErrorContains(t, CheckParamValues("deploy", params, map[string]string{"region": "us"}), "no param named 'region'")
+	assert.ErrorContains(t, CheckParamValues("deploy", params, map[string]string{"env": "dev"}), "isn't one of the choices")
+}
+
+func TestValidateParams(t *testing.T) {
+	tests := []struct {
+		name   string
+		task   TaskConfig
+		errMsg string
+	}{
+		{
+			name: "valid",
+			task: TaskConfig{Run: "deploy {{env}}", Params: []TaskParam{{Name: "env", Default: "prod", Choices: []string{"prod"}}}},
+		},
+		{
+			name:   "bad name",
+			task:   TaskConfig{Run: "deploy", Params: []TaskParam{{Name: "my-env"}}},
+			errMsg: "letters, digits, and underscores",
+		},
+		{
+			name:   "duplicate",
+			task:   TaskConfig{Run: "deploy", Params: []TaskParam{{Name: "env"}, {Name: "env"}}},
+			errMsg: "two params named 'env'",
+		},
+		{
+			name:   "default not a choice",
+			task:   TaskConfig{Run: "deploy", Params: []TaskParam{{Name: "env", Default: "dev", Choices: []string{"prod"}}}},
+			errMsg: "isn't one of its choices",
+		},
+		{
+			name:   "undeclared placeholder",
+			task:   TaskConfig{Run: "deploy {{region}}", Params: []TaskParam{{Name: "env"}}},
+			errMsg: "uses {{region}}",
+		},
+		{
+			name:   "parallel",
+			task:   TaskConfig{Parallel: []string{"a"}, Params: []TaskParam{{Name: "env"}}},
+			errMsg: "'params' and 'parallel'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateParams("deploy", tt.task)
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
+
+func TestValidate_TaskWithParamsAsDependency(t *testing.T) {
+	cfg := &Config{
+		Version: 1,
+		Tasks: map[string]TaskConfig{
+			"deploy":  {Run: "deploy {{env}}", Params: []TaskParam{{Name: "env"}}},
+			"release": {Depends: []DependencyItem{{Task: "deploy"}}},
+		},
+	}
+	err := Validate(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "task 'release' runs 'deploy', which takes params")
+}
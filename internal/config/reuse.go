@@ -0,0 +1,245 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// resolveTaskReuse applies task inheritance and the steps library to a
+// freshly loaded config, in place. A task with 'extends' takes every field
+// it leaves unset from its base task, and a {use: <name>} step is replaced by
+// the steps_lib sequence it names. Unknown names and cycles are errors.
+func resolveTaskReuse(cfg *Config) error {
+	names := getTaskNames(cfg.Tasks)
+	sort.Strings(names)
+
+	done := make(map[string]bool)
+	for _, name := range names {
+		if err := resolveExtends(cfg.Tasks, name, done, nil); err != nil {
+			return err
+		}
+	}
+
+	// Expand every library entry, used or not, so mistakes in it surface now
+	expanded := make(map[string][]TaskStep)
+	libNames := make([]string, 0, len(cfg.StepsLib))
+	for name := range cfg.StepsLib {
+		libNames = append(libNames, name)
+	}
+	sort.Strings(libNames)
+	for _, name := range libNames {
+		if _, err := expandLib(cfg.StepsLib, name, expanded, nil, "steps_lib"); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range names {
+		task := cfg.Tasks[name]
+		if len(task.Steps) == 0 {
+			continue
+		}
+		steps, err := expandSteps(cfg.StepsLib, task.Steps, expanded, nil, fmt.Sprintf("task '%s'", name))
+		if err != nil {
+			return err
+		}
+		task.Steps = steps
+		cfg.Tasks[name] = task
+	}
+	return nil
+}
+
+// resolveExtends resolves a task's base (and its base's base, and so on) and
+// merges it in. chain is the tasks being resolved on the way here.
+func resolveExtends(tasks map[string]TaskConfig, name string, done map[string]bool, chain []string) error {
+	if done[name] {
+		return nil
+	}
+	if i := slices.Index(chain, name); i >= 0 {
+		return fmt.Errorf("task '%s' extends itself: %s", name, strings.Join(append(chain[i:], name), " -> "))
+	}
+
+	task := tasks[name]
+	if task.Extends == "" {
+		done[name] = true
+		return nil
+	}
+	if _, ok := tasks[task.Extends]; !ok {
+		return fmt.Errorf("task '%s' extends '%s', which isn't a task", name, task.Extends)
+	}
+	if err := resolveExtends(tasks, task.Extends, done, append(chain, name)); err != nil {
+		return err
+	}
+
+	tasks[name] = inheritTask(task, tasks[task.Extends])
+	done[name] = true
+	return nil
+}
+
+// inheritTask fills in the fields child leaves unset from base. What the
+// task does (run, steps, or parallel) is inherited as a unit, so a child that
+// sets run doesn't also pick up its base's steps. Env is merged, with the
+// child's values winning. Boolean flags can be turned on, not off.
+func inheritTask(child, base TaskConfig) TaskConfig {
+	t := child
+	if child.Run == "" && len(child.Steps) == 0 && len(child.Parallel) == 0 {
+		t.Run = base.Run
+		t.Steps = slices.Clone(base.Steps)
+		t.Parallel = slices.Clone(base.Parallel)
+	}
+	if t.Description == "" {
+		t.Description = base.Description
+	}
+	if len(t.Depends) == 0 {
+		t.Depends = slices.Clone(base.Depends)
+	}
+	if len(t.Hosts) == 0 {
+		t.Hosts = slices.Clone(base.Hosts)
+	}
+	if len(base.Env) > 0 {
+		env := maps.Clone(base.Env)
+		maps.Copy(env, child.Env)
+		t.Env = env
+	}
+	if t.Shell == "" {
+		t.Shell = base.Shell
+	}
+	if t.Setup == "" {
+		t.Setup = base.Setup
+	}
+	if t.MaxParallel == 0 {
+		t.MaxParallel = base.MaxParallel
+	}
+	if t.Timeout == "" {
+		t.Timeout = base.Timeout
+	}
+	if t.Warm == "" {
+		t.Warm = base.Warm
+	}
+	if t.IdleTimeout == "" {
+		t.IdleTimeout = base.IdleTimeout
+	}
+	if t.OnConfigChange == "" {
+		t.OnConfigChange = base.OnConfigChange
+	}
+	if t.OnCancel == "" {
+		t.OnCancel = base.OnCancel
+	}
+	if t.Output.IsZero() {
+		t.Output = base.Output
+	}
+	if len(t.Require) == 0 {
+		t.Require = slices.Clone(base.Require)
+	}
+	if len(t.Pull) == 0 {
+		t.Pull = slices.Clone(base.Pull)
+	}
+	if t.Build == "" {
+		t.Build = base.Build
+	}
+	if len(t.Push) == 0 {
+		t.Push = slices.Clone(base.Push)
+	}
+	if t.SyncProfile == "" {
+		t.SyncProfile = base.SyncProfile
+	}
+	if len(t.Outputs) == 0 {
+		t.Outputs = slices.Clone(base.Outputs)
+	}
+	if len(t.Inputs) == 0 {
+		t.Inputs = slices.Clone(base.Inputs)
+	}
+	if t.Reserve.IsZero() {
+		t.Reserve = base.Reserve
+	}
+	if t.Type == "" {
+		t.Type = base.Type
+	}
+	if len(t.Ports) == 0 {
+		t.Ports = slices.Clone(base.Ports)
+	}
+	if t.Restart == "" {
+		t.Restart = base.Restart
+	}
+	if len(t.Params) == 0 {
+		t.Params = slices.Clone(base.Params)
+	}
+	if t.Priority == "" {
+		t.Priority = base.Priority
+	}
+	t.FailFast = t.FailFast || base.FailFast
+	t.ForwardArgs = t.ForwardArgs || base.ForwardArgs
+	t.Speculative = t.Speculative || base.Speculative
+	t.IdleKill = t.IdleKill || base.IdleKill
+	t.Raw = t.Raw || base.Raw
+	t.Sudo = t.Sudo || base.Sudo
+	t.SudoCache = t.SudoCache || base.SudoCache
+	return t
+}
+
+// expandSteps replaces each {use: <name>} step with the steps_lib sequence
+// it names. owner describes where the steps are, for errors.
+func expandSteps(lib map[string][]TaskStep, steps []TaskStep, expanded map[string][]TaskStep, chain []string, owner string) ([]TaskStep, error) {
+	out := make([]TaskStep, 0, len(steps))
+	for _, step := range steps {
+		if step.Use == "" {
+			out = append(out, step)
+			continue
+		}
+		if step.Run != "" {
+			return nil, fmt.Errorf("%s has a step with both 'use' and 'run' - pick one or the other", owner)
+		}
+		if len(step.Pull) > 0 || len(step.PullOnFail) > 0 {
+			return nil, fmt.Errorf("%s has a step with both 'use' and 'pull' - put the pull on the steps in steps_lib '%s'", owner, step.Use)
+		}
+
+		used, err := expandLib(lib, step.Use, expanded, chain, owner)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range used {
+			if u.Dir == "" {
+				u.Dir = step.Dir
+			}
+			if u.OnFail == "" {
+				u.OnFail = step.OnFail
+			}
+			if u.Output == "" {
+				u.Output = step.Output
+			}
+			u.Sudo = u.Sudo || step.Sudo
+			switch {
+			case u.If == "":
+				u.If = step.If
+			case step.If != "":
+				u.If = fmt.Sprintf("(%s) && (%s)", step.If, u.If)
+			}
+			out = append(out, u)
+		}
+	}
+	return out, nil
+}
+
+// expandLib returns a steps_lib entry with its own {use} steps expanded,
+// caching the result in expanded.
+func expandLib(lib map[string][]TaskStep, name string, expanded map[string][]TaskStep, chain []string, owner string) ([]TaskStep, error) {
+	if steps, ok := expanded[name]; ok {
+		return steps, nil
+	}
+	if i := slices.Index(chain, name); i >= 0 {
+		return nil, fmt.Errorf("steps_lib '%s' uses itself: %s", name, strings.Join(append(chain[i:], name), " -> "))
+	}
+	raw, ok := lib[name]
+	if !ok {
+		return nil, fmt.Errorf("%s uses '%s', which isn't in steps_lib", owner, name)
+	}
+
+	steps, err := expandSteps(lib, raw, expanded, append(chain, name), fmt.Sprintf("steps_lib '%s'", name))
+	if err != nil {
+		return nil, err
+	}
+	expanded[name] = steps
+	return steps, nil
+}
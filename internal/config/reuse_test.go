@@ -0,0 +1,316 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadProject(t *testing.T, content string) (*Config, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".rr.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return Load(path)
+}
+
+func TestLoad_StepPulls(t *testing.T) {
+	cfg, err := loadProject(t, `
+version: 1
+steps_lib:
+  e2e:
+    - name: e2e
+      run: npm run e2e
+      pull_on_fail:
+        - src: screenshots/
+          dest: ./artifacts/
+tasks:
+  ci:
+    steps:
+      - name: build
+        run: make build
+        pull: [dist/app.tar.gz]
+      - use: e2e
+`)
+	require.NoError(t, err)
+
+	steps := cfg.Tasks["ci"].Steps
+	require.Len(t, steps, 2)
+	assert.Equal(t, []PullItem{{Src: "dist/app.tar.gz"}}, steps[0].Pull)
+	assert.Empty(t, steps[0].PullOnFail)
+	assert.Equal(t, []PullItem{{Src: "screenshots/", Dest: "./artifacts/"}}, steps[1].PullOnFail)
+}
+
+func TestLoad_WatchTask(t *testing.T) {
+	cfg, err := loadProject(t, `
+version: 1
+tasks:
+  dev:
+    type: watch
+    run: npm run dev
+    ports: [3000, "9230:9229"]
+  dev-debug:
+    extends: dev
+    restart: always
+`)
+	require.NoError(t, err)
+
+	dev := cfg.Tasks["dev"]
+	assert.True(t, IsWatchTask(&dev))
+	assert.Equal(t, []string{"3000", "9230:9229"}, dev.Ports)
+	assert.Equal(t, RestartOnFailure, GetTaskRestart(&dev))
+
+	debug := cfg.Tasks["dev-debug"]
+	assert.True(t, IsWatchTask(&debug), "type is inherited")
+	assert.Equal(t, dev.Ports, debug.Ports)
+	assert.Equal(t, RestartAlways, GetTaskRestart(&debug))
+}
+
+func TestLoad_TaskExtendsAndStepsLib(t *testing.T) {
+	cfg, err := loadProject(t, `
+version: 1
+steps_lib:
+  venv:
+    - name: venv
+      run: python -m venv .venv
+    - name: deps
+      run: .venv/bin/pip install -r requirements.txt
+  bootstrap:
+    - use: venv
+    - name: migrate
+      run: ./manage.py migrate
+tasks:
+  base-test:
+    description: Run the tests
+    env:
+      color: "1"
+      level: info
+    timeout: 10m
+    steps:
+      - use: bootstrap
+      - name: test
+        run: pytest
+  test-verbose:
+    extends: base-test
+    env:
+      level: debug
+  test-api:
+    extends: test-verbose
+    steps:
+      - use: venv
+        dir: services/api
+        output: quiet
+      - name: test
+        run: pytest
+        dir: services/api
+`)
+	require.NoError(t, err)
+
+	base := cfg.Tasks["base-test"]
+	assert.Equal(t, []TaskStep{
+		{Name: "venv", Run: "python -m venv .venv"},
+		{Name: "deps", Run: ".venv/bin/pip install -r requirements.txt"},
+		{Name: "migrate", Run: "./manage.py migrate"},
+		{Name: "test", Run: "pytest"},
+	}, base.Steps)
+
+	verbose := cfg.Tasks["test-verbose"]
+	assert.Equal(t, base.Steps, verbose.Steps)
+	assert.Equal(t, "Run the tests", verbose.Description)
+	assert.Equal(t, "10m", verbose.Timeout)
+	assert.Equal(t, map[string]string{"color": "1", "level": "debug"}, verbose.Env)
+
+	// Inherits through test-verbose, but its own steps replace the base's
+	api := cfg.Tasks["test-api"]
+	assert.Equal(t, "debug", api.Env["level"])
+	assert.Equal(t, []TaskStep{
+		{Name: "venv", Run: "python -m venv .venv", Dir: "services/api", Output: "quiet"},
+		{Name: "deps", Run: ".venv/bin/pip install -r requirements.txt", Dir: "services/api", Output: "quiet"},
+		{Name: "test", Run: "pytest", Dir: "services/api"},
+	}, api.Steps)
+
+	require.NoError(t, Validate(cfg))
+}
+
+func TestLoad_StepsLibIf(t *testing.T) {
+	cfg, err := loadProject(t, `
+version: 1
+steps_lib:
+  frontend:
+    - name: install
+      run: npm ci
+    - name: lint
+      run: npm run lint
+      if: env.CI != 'true'
+tasks:
+  check:
+    steps:
+      - use: frontend
+        if: files_changed('web/')
+`)
+	require.NoError(t, err)
+	assert.Equal(t, []TaskStep{
+		{Name: "install", Run: "npm ci", If: "files_changed('web/')"},
+		{Name: "lint", Run: "npm run lint", If: "(files_changed('web/')) && (env.CI != 'true')"},
+	}, cfg.Tasks["check"].Steps)
+	require.NoError(t, Validate(cfg))
+}
+
+func TestInheritTask_RunReplacesSteps(t *testing.T) {
+	base := TaskConfig{Steps: []TaskStep{{Run: "a"}}, FailFast: true, Hosts: []string{"mini"}}
+	got := inheritTask(TaskConfig{Run: "b"}, base)
+
+	assert.Equal(t, "b", got.Run)
+	assert.Empty(t, got.Steps)
+	assert.True(t, got.FailFast)
+	assert.Equal(t, []string{"mini"}, got.Hosts)
+}
+
+func TestInheritTask_BuildAndPush(t *testing.T) {
+	base := TaskConfig{Run: "./bin/app", Build: "make", Push: []string{"bin/"}}
+
+	got := inheritTask(TaskConfig{Run: "./bin/app --fast"}, base)
+	assert.Equal(t, "make", got.Build)
+	assert.Equal(t, []string{"bin/"}, got.Push)
+
+	got = inheritTask(TaskConfig{Build: "make release", Push: []string{"dist/"}}, base)
+	assert.Equal(t, "make release", got.Build)
+	assert.Equal(t, []string{"dist/"}, got.Push)
+}
+
+func TestInheritTask_InputsAndOutputs(t *testing.T) {
+	base := TaskConfig{Run: "make", Outputs: []string{"dist/"}, Inputs: []string{"codegen"}}
+
+	got := inheritTask(TaskConfig{Run: "make release"}, base)
+	assert.Equal(t, []string{"dist/"}, got.Outputs)
+	assert.Equal(t, []string{"codegen"}, got.Inputs)
+
+	got = inheritTask(TaskConfig{Outputs: []string{"out/"}, Inputs: []string{"fetch"}}, base)
+	assert.Equal(t, []string{"out/"}, got.Outputs)
+	assert.Equal(t, []string{"fetch"}, got.Inputs)
+}
+
+func TestInheritTask_SyncProfile(t *testing.T) {
+	base := TaskConfig{Run: "python train.py", SyncProfile: "data"}
+
+	got := inheritTask(TaskConfig{Run: "python train.py --small"}, base)
+	assert.Equal(t, "data", got.SyncProfile)
+
+	got = inheritTask(TaskConfig{SyncProfile: "code"}, base)
+	assert.Equal(t, "code", got.SyncProfile)
+}
+
+func TestInheritTask_Reserve(t *testing.T) {
+	base := TaskConfig{Run: "make bench", Reserve: ReserveConfig{CPUs: 8, Memory: "16GB"}}
+
+	got := inheritTask(TaskConfig{Run: "make bench-fast"}, base)
+	assert.Equal(t, base.Reserve, got.Reserve)
+
+	got = inheritTask(TaskConfig{Reserve: ReserveConfig{CPUs: 2}}, base)
+	assert.Equal(t, ReserveConfig{CPUs: 2}, got.Reserve)
+}
+
+func TestInheritTask_ShellAndRaw(t *testing.T) {
+	got := inheritTask(TaskConfig{Run: "make lint"}, TaskConfig{Run: "make", Shell: "zsh -i -c"})
+	assert.Equal(t, "zsh -i -c", got.Shell)
+
+	got = inheritTask(TaskConfig{Shell: "bash -c"}, TaskConfig{Run: "make", Shell: "zsh -i -c"})
+	assert.Equal(t, "bash -c", got.Shell)
+
+	got = inheritTask(TaskConfig{Run: "make lint"}, TaskConfig{Run: "make", Raw: true})
+	assert.True(t, got.Raw)
+}
+
+func TestInheritTask_Params(t *testing.T) {
+	base := TaskConfig{Run: "deploy {{env}}", Params: []TaskParam{{Name: "env", Default: "staging"}}}
+
+	got := inheritTask(TaskConfig{Env: map[string]string{"FAST": "1"}}, base)
+	assert.Equal(t, base.Params, got.Params)
+
+	got = inheritTask(TaskConfig{Params: []TaskParam{{Name: "env", Default: "prod"}}}, base)
+	assert.Equal(t, "prod", got.Params[0].Default)
+}
+
+func TestLoad_TaskReuseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		errMsg  string
+	}{
+		{
+			name: "extends cycle",
+			content: `
+tasks:
+  a: {extends: b}
+  b: {extends: c}
+  c: {extends: a}
+`,
+			errMsg: "task 'a' extends itself: a -> b -> c -> a",
+		},
+		{
+			name: "unknown base",
+			content: `
+tasks:
+  a: {extends: nope}
+`,
+			errMsg: "task 'a' extends 'nope', which isn't a task",
+		},
+		{
+			name: "steps_lib cycle",
+			content: `
+steps_lib:
+  x: [{use: y}]
+  y: [{use: x}]
+tasks:
+  a: {run: echo}
+`,
+			errMsg: "steps_lib 'x' uses itself: x -> y -> x",
+		},
+		{
+			name: "unknown steps_lib entry",
+			content: `
+tasks:
+  a:
+    steps:
+      - use: missing
+`,
+			errMsg: "task 'a' uses 'missing', which isn't in steps_lib",
+		},
+		{
+			name: "use and run",
+			content: `
+steps_lib:
+  x: [{run: echo}]
+tasks:
+  a:
+    steps:
+      - use: x
+        run: echo
+`,
+			errMsg: "task 'a' has a step with both 'use' and 'run'",
+		},
+		{
+			name: "use and pull",
+			content: `
+steps_lib:
+  x: [{run: echo}]
+tasks:
+  a:
+    steps:
+      - use: x
+        pull_on_fail: [logs/]
+`,
+			errMsg: "task 'a' has a step with both 'use' and 'pull'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadProject(t, tt.content)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
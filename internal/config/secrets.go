@@ -0,0 +1,178 @@
+package config
+
+import (
+	stderrors "errors"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/secrets"
+)
+
+// SecretPrefix starts a config value that names a secret in rr's secret
+// store instead of holding it, like "keychain:webhook-token". The name is
+// the same whichever backend stores it.
+const SecretPrefix = "keychain:"
+
+// Settings for GlobalConfig.Secrets.
+const (
+	SecretsAuto      = secrets.BackendAuto      // Keychain on macOS, libsecret on a Linux desktop, else file (default)
+	SecretsKeychain  = secrets.BackendKeychain  // The macOS login keychain
+	SecretsLibsecret = secrets.BackendLibsecret // GNOME Keyring or KWallet, through secret-tool
+	SecretsFile      = secrets.BackendFile      // ~/.rr/secrets.age, encrypted with age
+)
+
+// SecretName returns the name of the secret value refers to, if it's a
+// keychain: reference.
+func SecretName(value string) (string, bool) {
+	name, ok := strings.CutPrefix(value, SecretPrefix)
+	return name, ok
+}
+
+// lookupSecret fetches a secret from the backend's store. A variable so
+// tests can stand in for the keychain.
+var lookupSecret = func(backend, name string) (string, error) {
+	store, err := secrets.Open(backend)
+	if err != nil {
+		return "", err
+	}
+	return store.Get(name)
+}
+
+// secretStore caches the secrets looked up in this process, so each one is
+// read from the store at most once however many values name it. backend is
+// the global config's secrets setting, set by LoadGlobal.
+var secretStore = struct {
+	sync.Mutex
+	backend string
+	values  map[string]string
+}{values: make(map[string]string)}
+
+// useSecretsBackend sets the store keychain: references are looked up in.
+// Secrets cached from another store are forgotten.
+func useSecretsBackend(backend string) {
+	secretStore.Lock()
+	defer secretStore.Unlock()
+	if backend != secretStore.backend {
+		secretStore.backend = backend
+		secretStore.values = make(map[string]string)
+	}
+}
+
+// resolveSecret returns v with the secret it names in its place, or v
+// itself if it isn't a reference. where names the setting, for errors.
+func resolveSecret(v, where string) (string, error) {
+	name, ok := SecretName(v)
+	if !ok {
+		return v, nil
+	}
+	if !secrets.ValidName(name) {
+		return "", errors.New(errors.ErrConfig,
+			fmt.Sprintf("%s names the secret '%s', which isn't a valid secret name", where, name),
+			"Secret names are letters, digits, '.', '_', '-' and '/', like keychain:ci/webhook-token.")
+	}
+
+	secretStore.Lock()
+	defer secretStore.Unlock()
+	if secret, ok := secretStore.values[name]; ok {
+		return secret, nil
+	}
+	secret, err := lookupSecret(secretStore.backend, name)
+	if stderrors.Is(err, secrets.ErrNotFound) {
+		return "", errors.New(errors.ErrConfig,
+			fmt.Sprintf("%s names the secret '%s', but it isn't stored", where, name),
+			fmt.Sprintf("Store it with: rr secrets set %s", name))
+	}
+	if err != nil {
+		return "", errors.WrapWithCode(err, errors.ErrConfig,
+			fmt.Sprintf("Couldn't read the secret '%s' for %s", name, where),
+			"Check that your keychain is unlocked, or pick another store with 'secrets' in ~/.rr/config.yaml.")
+	}
+	secretStore.values[name] = secret
+	return secret, nil
+}
+
+// ResolveEnvSecrets returns env with the keychain: references in its values
+// replaced by the secrets they name, or env itself if it has none, so
+// configs without secrets never touch the store. env is never changed.
+// where names the env for errors, like "task 'deploy'".
+func ResolveEnvSecrets(env map[string]string, where string) (map[string]string, error) {
+	var out map[string]string
+	for k, v := range env {
+		if _, ok := SecretName(v); !ok {
+			continue
+		}
+		secret, err := resolveSecret(v, fmt.Sprintf("%s env %s", where, k))
+		if err != nil {
+			return nil, err
+		}
+		if out == nil {
+			out = maps.Clone(env)
+		}
+		out[k] = secret
+	}
+	if out == nil {
+		return env, nil
+	}
+	return out, nil
+}
+
+// ResolveHostSecrets returns h with the keychain: references in its env and
+// identity_passphrase replaced by the secrets they name. It's called for a
+// host just before rr connects to it, so a command only looks up the
+// secrets of the hosts it uses, and a broken reference on another host
+// doesn't stop it. The config itself keeps the references, and SaveGlobal
+// puts them back on a resolved host, so it never writes a secret. A host
+// it already resolved is returned as is.
+func ResolveHostSecrets(name string, h Host) (Host, error) {
+	if h.secretRefs != nil {
+		return h, nil
+	}
+	refs := make(map[string]string)
+	for k, v := range h.Env {
+		if _, ok := SecretName(v); ok {
+			refs[k] = v
+		}
+	}
+	where := fmt.Sprintf("host '%s'", name)
+	env, err := ResolveEnvSecrets(h.Env, where)
+	if err != nil {
+		return h, err
+	}
+	h.Env = env
+
+	if h.IdentityPassphrase != "" {
+		if _, ok := SecretName(h.IdentityPassphrase); !ok {
+			return h, errors.New(errors.ErrConfig,
+				fmt.Sprintf("%s has its identity_passphrase written out in the config", where),
+				"Store it with 'rr secrets set <name>' and set identity_passphrase to keychain:<name> instead.")
+		}
+		refs[""] = h.IdentityPassphrase
+		h.IdentityPassphrase, err = resolveSecret(h.IdentityPassphrase, where+" identity_passphrase")
+		if err != nil {
+			return h, err
+		}
+	}
+	h.secretRefs = refs
+	return h, nil
+}
+
+// withSecretRefs returns h with the references ResolveHostSecrets replaced
+// put back, so a resolved host can be saved without writing its secrets.
+func (h Host) withSecretRefs() Host {
+	if len(h.secretRefs) == 0 {
+		return h
+	}
+	h.Env = maps.Clone(h.Env)
+	for k, ref := range h.secretRefs {
+		if k == "" {
+			h.IdentityPassphrase = ref
+		} else if _, ok := h.Env[k]; ok {
+			h.Env[k] = ref
+		}
+	}
+	h.secretRefs = nil
+	return h
+}
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSecrets stands in for the secret store with values, and returns the
+// names looked up. Secrets cached by earlier tests are forgotten.
+func fakeSecrets(t *testing.T, values map[string]string) *[]string {
+	t.Helper()
+	forgetSecrets := func() {
+		secretStore.Lock()
+		secretStore.values = make(map[string]string)
+		secretStore.Unlock()
+	}
+	forgetSecrets()
+	var lookups []string
+	orig := lookupSecret
+	lookupSecret = func(_, name string) (string, error) {
+		lookups = append(lookups, name)
+		if v, ok := values[name]; ok {
+			return v, nil
+		}
+		return "", secrets.ErrNotFound
+	}
+	t.Cleanup(func() {
+		lookupSecret = orig
+		forgetSecrets()
+	})
+	return &lookups
+}
+
+// writeSecretConfigs writes a global config and a project config that use
+// secrets, and returns the project config's path.
+func writeSecretConfigs(t *testing.T, global string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".rr"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".rr", "config.yaml"), []byte(global), 0644))
+
+	project := filepath.Join(t.TempDir(), ".rr.yaml")
+	require.NoError(t, os.WriteFile(project, []byte(`
+version: 1
+defaults:
+  env:
+    REGISTRY_URL: keychain:registry-url
+tasks:
+  notify:
+    run: ./notify.sh
+    env:
+      WEBHOOK_TOKEN: keychain:ci/webhook-token
+      CHANNEL: builds
+  test:
+    run: go test ./...
+`), 0644))
+	return project
+}
+
+const secretsGlobal = `
+version: 1
+hosts:
+  gpu:
+    ssh: [gpu-box]
+    dir: ~/rr
+    identity_file: ~/.ssh/gpu_ed25519
+    identity_passphrase: keychain:gpu-key
+    env:
+      HF_TOKEN: keychain:hf-token
+      CUDA_VISIBLE_DEVICES: "0"
+  broken:
+    ssh: [old-box]
+    dir: ~/rr
+    env:
+      TOKEN: keychain:missing
+`
+
+func TestLoadResolved_LeavesSecretsAlone(t *testing.T) {
+	lookups := fakeSecrets(t, nil)
+	project := writeSecretConfigs(t, secretsGlobal)
+
+	resolved, err := LoadResolved(project)
+	require.NoError(t, err, "a broken reference only matters to the host that has it")
+
+	// Viper lowercases map keys
+	gpu := resolved.Global.Hosts["gpu"]
+	assert.Equal(t, "keychain:gpu-key", gpu.IdentityPassphrase)
+	assert.Equal(t, "keychain:hf-token", gpu.Env["hf_token"])
+	assert.Equal(t, "keychain:ci/webhook-token", resolved.Project.Tasks["notify"].Env["webhook_token"])
+	assert.Empty(t, *lookups)
+}
+
+func TestResolveHostSecrets(t *testing.T) {
+	lookups := fakeSecrets(t, map[string]string{"gpu-key": "hunter2", "hf-token": "hf_abc"})
+	project := writeSecretConfigs(t, secretsGlobal)
+	resolved, err := LoadResolved(project)
+	require.NoError(t, err)
+
+	gpu, err := ResolveHostSecrets("gpu", resolved.Global.Hosts["gpu"])
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", gpu.IdentityPassphrase)
+	assert.Equal(t, map[string]string{"hf_token": "hf_abc", "cuda_visible_devices": "0"}, gpu.Env)
+	assert.Equal(t, "keychain:hf-token", resolved.Global.Hosts["gpu"].Env["hf_token"], "the config keeps the reference")
+
+	_, err = ResolveHostSecrets("gpu", gpu)
+	require.NoError(t, err)
+	_, err = ResolveHostSecrets("gpu", resolved.Global.Hosts["gpu"])
+	require.NoError(t, err)
+	assert.Len(t, *lookups, 2, "each secret is looked up once")
+}
+
+func TestResolveHostSecrets_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		host Host
+		want string
+	}{
+		{
+			name: "not stored",
+			host: Host{Env: map[string]string{"token": "keychain:missing"}},
+			want: "host 'dev' env token names the secret 'missing', but it isn't stored",
+		},
+		{
+			name: "bad name",
+			host: Host{Env: map[string]string{"token": "keychain:has space"}},
+			want: "isn't a valid secret name",
+		},
+		{
+			name: "passphrase written out",
+			host: Host{IdentityFile: "~/.ssh/id", IdentityPassphrase: "hunter2"},
+			want: "has its identity_passphrase written out",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeSecrets(t, nil)
+			_, err := ResolveHostSecrets("dev", tt.host)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestGetTaskWithMergedEnv_Secrets(t *testing.T) {
+	lookups := fakeSecrets(t, map[string]string{
+		"registry-url":     "https://ci:pw@registry.example.com",
+		"ci/webhook-token": "whk_123",
+	})
+	project := writeSecretConfigs(t, secretsGlobal)
+	resolved, err := LoadResolved(project)
+	require.NoError(t, err)
+
+	task, env, err := GetTaskWithMergedEnv(resolved.Project, "notify", nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"registry_url":  "https://ci:pw@registry.example.com",
+		"webhook_token": "whk_123",
+		"channel":       "builds",
+	}, env)
+	assert.Equal(t, "keychain:ci/webhook-token", task.Env["webhook_token"], "the task keeps the reference")
+	assert.Len(t, *lookups, 2)
+
+	broken := resolved.Global.Hosts["broken"]
+	_, _, err = GetTaskWithMergedEnv(resolved.Project, "test", &broken)
+	assert.ErrorContains(t, err, "names the secret 'missing', but it isn't stored")
+}
+
+func TestSaveGlobal_NeverWritesSecrets(t *testing.T) {
+	fakeSecrets(t, map[string]string{"gpu-key": "hunter2", "hf-token": "hf_abc"})
+	project := writeSecretConfigs(t, secretsGlobal)
+
+	resolved, err := LoadResolved(project)
+	require.NoError(t, err)
+	gpu, err := ResolveHostSecrets("gpu", resolved.Global.Hosts["gpu"])
+	require.NoError(t, err)
+	gpu.Tags = []string{"cuda"}
+	resolved.Global.Hosts["gpu"] = gpu
+	require.NoError(t, SaveGlobal(resolved.Global))
+
+	path, err := GlobalConfigPath()
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "cuda", "the change itself is saved")
+	assert.NotContains(t, string(data), "hunter2")
+	assert.NotContains(t, string(data), "hf_abc")
+}
+
+func TestResolveEnvSecrets_NoSecretsNoLookups(t *testing.T) {
+	lookups := fakeSecrets(t, nil)
+	env := map[string]string{"CI": "1"}
+
+	got, err := ResolveEnvSecrets(env, "task 'test'")
+	require.NoError(t, err)
+	assert.Equal(t, env, got)
+	assert.Empty(t, *lookups)
+}
+
+func TestValidateGlobal_Secrets(t *testing.T) {
+	cfg := DefaultGlobalConfig()
+	cfg.Secrets = "vault"
+	assert.ErrorContains(t, ValidateGlobal(cfg), "secrets is 'vault'")
+
+	cfg.Secrets = SecretsLibsecret
+	cfg.Hosts["dev"] = Host{SSH: []string{"dev"}, Dir: "~/rr", IdentityPassphrase: "keychain:dev-key"}
+	assert.ErrorContains(t, ValidateGlobal(cfg), "identity_passphrase but no identity_file")
+
+	cfg.Hosts["dev"] = Host{SSH: []string{"dev"}, Dir: "~/rr", IdentityFile: "~/.ssh/dev", IdentityPassphrase: "keychain:dev-key"}
+	assert.NoError(t, ValidateGlobal(cfg))
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// sizeUnits maps size suffixes to byte multipliers. Units are binary
+// (1K = 1024 bytes), matching what du reports.
+var sizeUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"k":   1 << 10,
+	"kb":  1 << 10,
+	"kib": 1 << 10,
+	"m":   1 << 20,
+	"mb":  1 << 20,
+	"mib": 1 << 20,
+	"g":   1 << 30,
+	"gb":  1 << 30,
+	"gib": 1 << 30,
+	"t":   1 << 40,
+	"tb":  1 << 40,
+	"tib": 1 << 40,
+}
+
+// ParseSize parses a human-readable size like "500MB", "1.5G", or "2048"
+// (bytes) into a byte count.
+func ParseSize(s string) (int64, error) {
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return 0, fmt.Errorf("size is empty")
+	}
+
+	// Split the number from the unit suffix
+	i := 0
+	for i < len(trimmed) && (trimmed[i] >= '0' && trimmed[i] <= '9' || trimmed[i] == '.') {
+		i++
+	}
+	number, unit := trimmed[:i], strings.ToLower(strings.TrimSpace(trimmed[i:]))
+
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("'%s' isn't a valid size - use something like 500MB or 2GB", s)
+	}
+	multiplier, ok := sizeUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("'%s' has an unknown unit - use B, KB, MB, GB, or TB", s)
+	}
+
+	return int64(value * float64(multiplier)), nil
+}
+
+// FormatSize formats a byte count as a human-readable string ("1.5 GB").
+func FormatSize(bytes int64) string {
+	const (
+		KB = 1 << 10
+		MB = 1 << 20
+		GB = 1 << 30
+	)
+
+	switch {
+	case bytes >= GB:
+		return fmt.Sprintf("%.1f GB", float64(bytes)/GB)
+	case bytes >= MB:
+		return fmt.Sprintf("%.1f MB", float64(bytes)/MB)
+	case bytes >= KB:
+		return fmt.Sprintf("%.1f KB", float64(bytes)/KB)
+	default:
+		return fmt.Sprintf("%d B", bytes)
+	}
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int64
+	}{
+		{"2048", 2048},
+		{"10B", 10},
+		{"1K", 1024},
+		{"1kb", 1024},
+		{"500MB", 500 << 20},
+		{"1.5G", 3 << 29},
+		{"2 GiB", 2 << 30},
+		{"1TB", 1 << 40},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseSize(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseSize_Invalid(t *testing.T) {
+	for _, input := range []string{"", "MB", "-1GB", "0", "10 parsecs", "1.2.3MB"} {
+		t.Run(input, func(t *testing.T) {
+			_, err := ParseSize(input)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	assert.Equal(t, "512 B", FormatSize(512))
+	assert.Equal(t, "1.5 KB", FormatSize(1536))
+	assert.Equal(t, "500.0 MB", FormatSize(500<<20))
+	assert.Equal(t, "2.0 GB", FormatSize(2<<30))
+}
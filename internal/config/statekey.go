@@ -0,0 +1,32 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+)
+
+// NormalizeLocalDir resolves a local project directory to an absolute,
+// symlink-free path, so the same project is the same directory however a
+// command was invoked.
+func NormalizeLocalDir(localDir string) string {
+	dir, err := filepath.Abs(localDir)
+	if err != nil {
+		dir = filepath.Clean(localDir)
+	}
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	return dir
+}
+
+// ProjectStateKey returns the flat, filesystem-safe name rr keeps a local
+// project's state under in ~/.rr/, like its run history or sync records.
+// parts narrow it further, to a host or task. Every kind of project state
+// is keyed this way, so they all agree on which project is which.
+func ProjectStateKey(localDir string, parts ...string) string {
+	key := strings.Join(append([]string{NormalizeLocalDir(localDir)}, parts...), "\x00")
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectStateKey(t *testing.T) {
+	dir := t.TempDir()
+	project := filepath.Join(dir, "project")
+	require.NoError(t, os.Mkdir(project, 0755))
+	link := filepath.Join(dir, "link")
+	require.NoError(t, os.Symlink(project, link))
+
+	key := ProjectStateKey(project)
+	assert.Len(t, key, 16)
+	assert.Equal(t, key, ProjectStateKey(link), "a symlink is the same project")
+	assert.Equal(t, key, ProjectStateKey(project+"/."), "so is an unclean path")
+
+	assert.NotEqual(t, key, ProjectStateKey(project, "gpu"), "parts narrow the key")
+	assert.NotEqual(t, ProjectStateKey(project, "gpu"), ProjectStateKey(project, "cpu"))
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// UsesSudo reports whether a task, or any of its steps, answers sudo
+// password prompts.
+func UsesSudo(task TaskConfig) bool {
+	if task.Sudo {
+		return true
+	}
+	for _, step := range task.Steps {
+		if step.Sudo {
+			return true
+		}
+	}
+	return false
+}
+
+// validateSudo checks that sudo is only set where rr can ask for the
+// password: on a task that runs a command itself, and not in the background.
+func validateSudo(name string, task TaskConfig) error {
+	if !UsesSudo(task) {
+		if task.SudoCache {
+			return fmt.Errorf("task '%s' has 'sudo_cache' but doesn't use sudo - add 'sudo: true' to the task or a step", name)
+		}
+		return nil
+	}
+	switch {
+	case len(task.Parallel) > 0:
+		return fmt.Errorf("task '%s' has both 'sudo' and 'parallel' - put sudo on the subtask that needs it", name)
+	case task.Shard.Count > 0:
+		return fmt.Errorf("task '%s' has both 'sudo' and 'shard' - shards run side by side with nobody to type the password", name)
+	case task.Type == TaskTypeWatch:
+		return fmt.Errorf("task '%s' has both 'sudo' and 'type: watch' - restarts run with nobody to type the password", name)
+	case task.Speculative:
+		return fmt.Errorf("task '%s' has both 'sudo' and 'speculative' - the two runs would both ask for the password", name)
+	}
+	return nil
+}
+
+// validateSudoRefs checks that no task using sudo is a subtask of a parallel
+// task, whose subtasks run without a terminal to answer sudo on.
+func validateSudoRefs(cfg *Config) error {
+	names := getTaskNames(cfg.Tasks)
+	sort.Strings(names)
+	for _, name := range names {
+		for _, sub := range cfg.Tasks[name].Parallel {
+			if UsesSudo(cfg.Tasks[sub]) {
+				return fmt.Errorf("parallel task '%s' runs '%s', which uses sudo - parallel subtasks can't ask for a password", name, sub)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_TaskSudo(t *testing.T) {
+	cfg, err := loadProject(t, `
+version: 1
+steps_lib:
+  install:
+    - name: install
+      run: sudo make install
+tasks:
+  upgrade:
+    run: sudo apt-get upgrade -y
+    sudo: true
+    sudo_cache: true
+  release:
+    steps:
+      - name: build
+        run: make
+      - use: install
+        sudo: true
+`)
+	require.NoError(t, err)
+
+	upgrade := cfg.Tasks["upgrade"]
+	assert.True(t, upgrade.Sudo)
+	assert.True(t, upgrade.SudoCache)
+
+	release := cfg.Tasks["release"]
+	require.Len(t, release.Steps, 2)
+	assert.False(t, release.Steps[0].Sudo)
+	assert.True(t, release.Steps[1].Sudo, "a use step's sudo applies to the steps it pulls in")
+	assert.True(t, UsesSudo(release))
+}
+
+func TestValidateSudo(t *testing.T) {
+	tests := []struct {
+		name   string
+		task   TaskConfig
+		errMsg string
+	}{
+		{
+			name: "valid",
+			task: TaskConfig{Run: "sudo reboot", Sudo: true, SudoCache: true},
+		},
+		{
+			name: "step",
+			task: TaskConfig{Steps: []TaskStep{{Run: "sudo reboot", Sudo: true}}, SudoCache: true},
+		},
+		{
+			name:   "cache without sudo",
+			task:   TaskConfig{Run: "make", SudoCache: true},
+			errMsg: "doesn't use sudo",
+		},
+		{
+			name:   "parallel",
+			task:   TaskConfig{Parallel: []string{"a"}, Sudo: true},
+			errMsg: "'sudo' and 'parallel'",
+		},
+		{
+			name:   "watch",
+			task:   TaskConfig{Run: "serve", Type: TaskTypeWatch, Sudo: true},
+			errMsg: "'sudo' and 'type: watch'",
+		},
+		{
+			name:   "speculative",
+			task:   TaskConfig{Run: "make", Speculative: true, Sudo: true},
+			errMsg: "'sudo' and 'speculative'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSudo("upgrade", tt.task)
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
+
+func TestValidate_SudoTaskAsParallelSubtask(t *testing.T) {
+	cfg := &Config{
+		Version: 1,
+		Tasks: map[string]TaskConfig{
+			"upgrade": {Run: "sudo apt-get upgrade -y", Sudo: true},
+			"lint":    {Run: "make lint"},
+			"all":     {Parallel: []string{"lint", "upgrade"}},
+		},
+	}
+	err := Validate(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "parallel task 'all' runs 'upgrade', which uses sudo")
+}
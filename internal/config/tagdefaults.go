@@ -0,0 +1,127 @@
+package config
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ApplyTagDefaults returns h with the tag_defaults of each of its tags merged
+// in, so adding a tag to a host is enough to give it the tag's setup. The
+// host's own settings win: dir, shell and identity_file are only filled in
+// when the host leaves them empty, and its env values override the tag's.
+// Tag setup_commands and profile_files run before the host's own, and
+// require lists are combined. Tags are applied in the order the host lists
+// them, so when two tags set the same value, the first one wins. Tag names
+// match case-insensitively.
+func ApplyTagDefaults(h Host, tagDefaults map[string]HostDefaults) Host {
+	if len(tagDefaults) == 0 {
+		return h
+	}
+	defaults := make(map[string]HostDefaults, len(tagDefaults))
+	for tag, d := range tagDefaults {
+		defaults[strings.ToLower(tag)] = d
+	}
+
+	var setup, profiles, require []string
+	envCopied := false
+	for _, tag := range h.Tags {
+		d, ok := defaults[strings.ToLower(tag)]
+		if !ok {
+			continue
+		}
+		if h.Dir == "" {
+			h.Dir = d.Dir
+		}
+		if h.Shell == "" {
+			h.Shell = d.Shell
+		}
+		if h.IdentityFile == "" {
+			h.IdentityFile = d.IdentityFile
+		}
+		for name, value := range d.Env {
+			if _, set := h.Env[name]; set {
+				continue
+			}
+			if !envCopied {
+				// Copy before writing, the map is shared with the caller's host
+				h.Env = maps.Clone(h.Env)
+				if h.Env == nil {
+					h.Env = make(map[string]string)
+				}
+				envCopied = true
+			}
+			h.Env[name] = value
+		}
+		setup = append(setup, d.SetupCommands...)
+		profiles = append(profiles, d.ProfileFiles...)
+		require = append(require, d.Require...)
+	}
+
+	h.SetupCommands = mergeLists(setup, h.SetupCommands)
+	h.ProfileFiles = mergeLists(profiles, h.ProfileFiles)
+	h.Require = mergeLists(h.Require, require)
+	return h
+}
+
+// mergeLists returns first followed by then, without duplicates. Returns then
+// unchanged when first is empty.
+func mergeLists(first, then []string) []string {
+	if len(first) == 0 {
+		return then
+	}
+	merged := make([]string, 0, len(first)+len(then))
+	for _, item := range append(slices.Clone(first), then...) {
+		if !slices.Contains(merged, item) {
+			merged = append(merged, item)
+		}
+	}
+	return merged
+}
+
+// cloneHost returns a copy of h that shares no slices or maps with it.
+func cloneHost(h Host) Host {
+	h.SSH = slices.Clone(h.SSH)
+	h.Tags = slices.Clone(h.Tags)
+	h.Env = maps.Clone(h.Env)
+	h.ProfileFiles = slices.Clone(h.ProfileFiles)
+	h.SetupCommands = slices.Clone(h.SetupCommands)
+	h.Require = slices.Clone(h.Require)
+	return h
+}
+
+// hostsToSave returns the hosts to write to the global config file. Fields
+// that haven't changed since LoadGlobal are written as the file declared
+// them, so tag defaults (and expanded variables) aren't copied into every
+// host. Changed fields and new hosts are written as they are, and hosts an
+// inventory source discovered are left out. Hosts whose secrets were
+// looked up are written with the references, never the secrets.
+func (c *GlobalConfig) hostsToSave() map[string]Host {
+	hosts := make(map[string]Host, len(c.Hosts))
+	for name, h := range c.Hosts {
+		if _, ok := c.discovered[name]; ok {
+			continue
+		}
+		h = h.withSecretRefs()
+		declared, ok := c.declared[name]
+		if !ok {
+			hosts[name] = h
+			continue
+		}
+		loaded := c.loaded[name]
+
+		hv := reflect.ValueOf(&h).Elem()
+		lv, dv := reflect.ValueOf(loaded), reflect.ValueOf(declared)
+		for i := 0; i < hv.NumField(); i++ {
+			if !hv.Type().Field(i).IsExported() {
+				continue
+			}
+			if reflect.DeepEqual(hv.Field(i).Interface(), lv.Field(i).Interface()) {
+				hv.Field(i).Set(dv.Field(i))
+			}
+		}
+		hosts[name] = h
+	}
+	return hosts
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestApplyTagDefaults(t *testing.T) {
+	defaults := map[string]HostDefaults{
+		"gpu": {
+			Dir:           "/scratch/${PROJECT}",
+			Env:           map[string]string{"CUDA_VISIBLE_DEVICES": "0", "HF_HOME": "/scratch/hf"},
+			SetupCommands: []string{"source /opt/conda/bin/activate"},
+			Require:       []string{"nvidia-smi"},
+		},
+		"linux": {
+			Dir:           "~/rr/${PROJECT}",
+			Shell:         "bash -l -c",
+			SetupCommands: []string{"source /opt/conda/bin/activate", "ulimit -n 65536"},
+		},
+	}
+
+	t.Run("host without the tag is unchanged", func(t *testing.T) {
+		h := Host{SSH: []string{"mini"}, Tags: []string{"macos"}, Dir: "~/rr"}
+		assert.Equal(t, h, ApplyTagDefaults(h, defaults))
+	})
+
+	t.Run("tags fill in what the host leaves out", func(t *testing.T) {
+		h := Host{
+			SSH:           []string{"gpu-box"},
+			Tags:          []string{"GPU", "linux"},
+			Env:           map[string]string{"CUDA_VISIBLE_DEVICES": "1"},
+			SetupCommands: []string{"export PATH=$PATH:/opt/bin"},
+			Require:       []string{"python3"},
+		}
+		got := ApplyTagDefaults(h, defaults)
+
+		// The first tag wins
+		assert.Equal(t, "/scratch/${PROJECT}", got.Dir)
+		assert.Equal(t, "bash -l -c", got.Shell)
+		// The host's own env wins
+		assert.Equal(t, map[string]string{"CUDA_VISIBLE_DEVICES": "1", "HF_HOME": "/scratch/hf"}, got.Env)
+		// Tag setup runs first, without duplicates
+		assert.Equal(t, []string{"source /opt/conda/bin/activate", "ulimit -n 65536", "export PATH=$PATH:/opt/bin"}, got.SetupCommands)
+		assert.Equal(t, []string{"python3", "nvidia-smi"}, got.Require)
+
+		// The caller's host isn't modified
+		assert.Equal(t, map[string]string{"CUDA_VISIBLE_DEVICES": "1"}, h.Env)
+	})
+
+	t.Run("host settings win", func(t *testing.T) {
+		h := Host{Tags: []string{"linux"}, Dir: "~/code/${PROJECT}", Shell: "zsh -c"}
+		got := ApplyTagDefaults(h, defaults)
+		assert.Equal(t, "~/code/${PROJECT}", got.Dir)
+		assert.Equal(t, "zsh -c", got.Shell)
+	})
+}
+
+// writeGlobalConfig writes ~/.rr/config.yaml under a temporary home.
+func writeGlobalConfig(t *testing.T, content string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".rr"), 0755))
+	path := filepath.Join(home, ".rr", "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+const tagDefaultsConfig = `
+version: 1
+tag_defaults:
+  gpu:
+    dir: /scratch/${USER}/rr
+    setup_commands:
+      - source /opt/conda/bin/activate
+    require:
+      - nvidia-smi
+hosts:
+  a100:
+    ssh: [a100-lan]
+    tags: [gpu]
+  h100:
+    ssh: [h100-lan]
+    tags: [gpu]
+    dir: ~/rr
+  mini:
+    ssh: [mini-lan]
+    dir: ~/rr
+`
+
+func TestLoadGlobal_TagDefaults(t *testing.T) {
+	writeGlobalConfig(t, tagDefaultsConfig)
+
+	cfg, err := LoadGlobal()
+	require.NoError(t, err)
+
+	a100 := cfg.Hosts["a100"]
+	assert.Equal(t, "/scratch/"+getUser()+"/rr", a100.Dir)
+	assert.Equal(t, []string{"source /opt/conda/bin/activate"}, a100.SetupCommands)
+	assert.Equal(t, []string{"nvidia-smi"}, a100.Require)
+	assert.Equal(t, "~/rr", cfg.Hosts["h100"].Dir)
+	assert.Empty(t, cfg.Hosts["mini"].SetupCommands)
+	require.NoError(t, ValidateGlobal(cfg))
+}
+
+func TestSaveGlobal_KeepsTagDefaultsOutOfHosts(t *testing.T) {
+	path := writeGlobalConfig(t, tagDefaultsConfig)
+
+	cfg, err := LoadGlobal()
+	require.NoError(t, err)
+	h := cfg.Hosts["a100"]
+	h.SSH = append(h.SSH, "a100-vpn")
+	cfg.Hosts["a100"] = h
+	cfg.Hosts["l4"] = Host{SSH: []string{"l4-lan"}, Tags: []string{"gpu"}}
+	require.NoError(t, SaveGlobal(cfg))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var saved struct {
+		TagDefaults map[string]HostDefaults `yaml:"tag_defaults"`
+		Hosts       map[string]Host         `yaml:"hosts"`
+	}
+	require.NoError(t, yaml.Unmarshal(data, &saved))
+
+	assert.Equal(t, "/scratch/${USER}/rr", saved.TagDefaults["gpu"].Dir)
+	assert.Equal(t, []string{"a100-lan", "a100-vpn"}, saved.Hosts["a100"].SSH)
+	assert.Empty(t, saved.Hosts["a100"].Dir)
+	assert.Empty(t, saved.Hosts["a100"].SetupCommands)
+	assert.Empty(t, saved.Hosts["l4"].SetupCommands)
+
+	// Reloading merges the defaults in again, including for the new host
+	cfg, err = LoadGlobal()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"source /opt/conda/bin/activate"}, cfg.Hosts["l4"].SetupCommands)
+	assert.Equal(t, []string{"a100-lan", "a100-vpn"}, cfg.Hosts["a100"].SSH)
+}
@@ -2,6 +2,9 @@ package config
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/rileyhilliard/rr/internal/errors"
 	"github.com/rileyhilliard/rr/internal/util"
@@ -13,6 +16,103 @@ const (
 	OnFailContinue = "continue" // Continue to next step on failure
 )
 
+// TaskTypeWatch marks a long-lived task, like a dev server.
+const TaskTypeWatch = "watch"
+
+// Restart constants define when a watch task's command is restarted.
+const (
+	RestartOnFailure = "on-failure" // Default: restart after a non-zero exit
+	RestartAlways    = "always"     // Restart after any exit
+	RestartNever     = "never"      // Stop watching when the command exits
+)
+
+// IsWatchTask reports whether a task is a long-lived watch task.
+func IsWatchTask(task *TaskConfig) bool {
+	return task != nil && task.Type == TaskTypeWatch
+}
+
+// GetTaskRestart returns a watch task's restart policy.
+// Defaults to "on-failure" if not specified.
+func GetTaskRestart(task *TaskConfig) string {
+	if task.Restart == "" {
+		return RestartOnFailure
+	}
+	return task.Restart
+}
+
+// ParsePortForward parses a watch task's port: "3000" forwards local port
+// 3000 to remote port 3000, "8080:3000" forwards local 8080 to remote 3000.
+func ParsePortForward(spec string) (local, remote int, err error) {
+	localPart, remotePart, found := strings.Cut(strings.TrimSpace(spec), ":")
+	if !found {
+		remotePart = localPart
+	}
+	if local, err = parsePort(localPart); err != nil {
+		return 0, 0, err
+	}
+	if remote, err = parsePort(remotePart); err != nil {
+		return 0, 0, err
+	}
+	return local, remote, nil
+}
+
+// parsePort parses a TCP port number.
+func parsePort(s string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || n < 1 || n > 65535 {
+		return 0, fmt.Errorf("'%s' isn't a port - use a number from 1 to 65535", s)
+	}
+	return n, nil
+}
+
+// GetIdleTimeout returns how long a command may go without output, and
+// whether it's stopped then. A task's own idle_timeout wins over the
+// project default; nil task means 'rr run' or 'rr exec'. Zero means no limit.
+func GetIdleTimeout(cfg *Config, task *TaskConfig) (time.Duration, bool) {
+	if task != nil && task.IdleTimeout != "" {
+		d, _ := time.ParseDuration(task.IdleTimeout)
+		return d, task.IdleKill
+	}
+	if cfg == nil || cfg.Defaults.IdleTimeout == "" {
+		return 0, false
+	}
+	d, _ := time.ParseDuration(cfg.Defaults.IdleTimeout)
+	return d, cfg.Defaults.IdleKill
+}
+
+// GetTaskWarm returns how long a parallel task's hosts stay warm between
+// runs. Zero means they don't.
+func GetTaskWarm(task *TaskConfig) time.Duration {
+	if task == nil || task.Warm == "" {
+		return 0
+	}
+	d, _ := time.ParseDuration(task.Warm)
+	return d
+}
+
+// OnConfigChange constants define what a parallel task does when the config
+// changes while it runs.
+const (
+	OnConfigChangeWarn  = "warn"  // Default: warn that subtasks run their old definitions
+	OnConfigChangeApply = "apply" // Run subtasks that haven't started with their new definitions
+)
+
+// GetTaskOnConfigChange returns what a parallel task does when the config
+// changes while it runs. Defaults to "warn" if not specified.
+func GetTaskOnConfigChange(task *TaskConfig) string {
+	if task == nil || task.OnConfigChange == "" {
+		return OnConfigChangeWarn
+	}
+	return task.OnConfigChange
+}
+
+// Priority constants are the priorities a task can run at on its host.
+const (
+	PriorityLow    = "low"
+	PriorityNormal = "normal" // Default: whatever the host gives a new command
+	PriorityHigh   = "high"
+)
+
 // GetTask returns a task by name from the config.
 // Returns an error if the task doesn't exist or is invalid.
 func GetTask(cfg *Config, name string) (*TaskConfig, error) {
@@ -45,6 +145,7 @@ func GetTask(cfg *Config, name string) (*TaskConfig, error) {
 
 // GetTaskWithMergedEnv returns a task with environment variables merged.
 // Merge order (lowest to highest precedence): host env → project defaults env → task env.
+// keychain: references in the merged env are replaced by the secrets they name.
 func GetTaskWithMergedEnv(cfg *Config, taskName string, host *Host) (*TaskConfig, map[string]string, error) {
 	task, err := GetTask(cfg, taskName)
 	if err != nil {
@@ -71,16 +172,24 @@ func GetTaskWithMergedEnv(cfg *Config, taskName string, host *Host) (*TaskConfig
 		mergedEnv[k] = v
 	}
 
+	mergedEnv, err = ResolveEnvSecrets(mergedEnv, fmt.Sprintf("task '%s'", taskName))
+	if err != nil {
+		return nil, nil, err
+	}
 	return task, mergedEnv, nil
 }
 
 // GetMergedSetupCommands returns setup commands merged from host and project defaults.
-// Order: host setup_commands first, then project defaults setup.
-// Both run before the task command.
+// Order: the cache directory's export first, then host profile_files, then host
+// setup_commands, then project defaults setup. All run before the task command.
 func GetMergedSetupCommands(cfg *Config, host *Host) []string {
-	var setup []string
+	// Export $RR_CACHE first so everything after it can use the cache
+	setup := []string{CacheSetupCommand(cfg.Cache)}
+
+	// Source host profile files next so setup commands can use what they set up
+	setup = append(setup, HostProfileCommands(host)...)
 
-	// Add host setup_commands first
+	// Add host setup_commands next
 	if host != nil {
 		setup = append(setup, host.SetupCommands...)
 	}
@@ -131,3 +240,47 @@ func GetStepOnFail(step TaskStep) string {
 	}
 	return step.OnFail
 }
+
+// Verbosity constants for task and step output.
+const (
+	VerbosityQuiet   = "quiet"   // Hold output back unless the step fails
+	VerbosityNormal  = "normal"  // Default: stream output as it runs
+	VerbosityVerbose = "verbose" // Stream output as it runs
+)
+
+// GetTaskVerbosity returns a task's output verbosity.
+// Defaults to "normal" if not specified.
+func GetTaskVerbosity(task *TaskConfig) string {
+	if task == nil || task.Output.Verbosity == "" {
+		return VerbosityNormal
+	}
+	return task.Output.Verbosity
+}
+
+// GetStepVerbosity returns a step's output verbosity: its own output
+// setting, or else the task's.
+func GetStepVerbosity(task *TaskConfig, step TaskStep) string {
+	if step.Output != "" {
+		return step.Output
+	}
+	return GetTaskVerbosity(task)
+}
+
+// GetStepPulls returns what to pull after a step exited with exitCode: its
+// pull items, plus its pull_on_fail items if it failed.
+func GetStepPulls(step TaskStep, exitCode int) []PullItem {
+	if exitCode == 0 || len(step.PullOnFail) == 0 {
+		return step.Pull
+	}
+	return append(append([]PullItem(nil), step.Pull...), step.PullOnFail...)
+}
+
+// stepsPull reports whether any step pulls files.
+func stepsPull(steps []TaskStep) bool {
+	for _, step := range steps {
+		if len(step.Pull) > 0 || len(step.PullOnFail) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -222,5 +223,94 @@ func TestGetStepOnFail(t *testing.T) {
 	}
 }
 
+func TestGetStepPulls(t *testing.T) {
+	step := TaskStep{
+		Run:        "make e2e",
+		Pull:       []PullItem{{Src: "coverage.xml"}},
+		PullOnFail: []PullItem{{Src: "screenshots/", Dest: "./artifacts/"}},
+	}
+
+	assert.Equal(t, []PullItem{{Src: "coverage.xml"}}, GetStepPulls(step, 0))
+	assert.Equal(t, []PullItem{{Src: "coverage.xml"}, {Src: "screenshots/", Dest: "./artifacts/"}}, GetStepPulls(step, 2))
+	assert.Len(t, step.Pull, 1, "the step's own items aren't modified")
+
+	assert.Empty(t, GetStepPulls(TaskStep{Run: "make"}, 1))
+	assert.Equal(t, step.PullOnFail, GetStepPulls(TaskStep{Run: "make", PullOnFail: step.PullOnFail}, 1))
+}
+
+func TestGetStepVerbosity(t *testing.T) {
+	task := &TaskConfig{Output: TaskOutput{Verbosity: VerbosityQuiet}}
+
+	assert.Equal(t, VerbosityNormal, GetTaskVerbosity(&TaskConfig{}))
+	assert.Equal(t, VerbosityNormal, GetTaskVerbosity(&TaskConfig{Output: TaskOutput{Mode: "quiet"}}), "the parallel display mode isn't a verbosity")
+	assert.Equal(t, VerbosityQuiet, GetStepVerbosity(task, TaskStep{Run: "make"}))
+	assert.Equal(t, VerbosityVerbose, GetStepVerbosity(task, TaskStep{Run: "make", Output: VerbosityVerbose}))
+}
+
+func TestGetTaskRestart(t *testing.T) {
+	assert.Equal(t, RestartOnFailure, GetTaskRestart(&TaskConfig{Type: TaskTypeWatch}))
+	assert.Equal(t, RestartNever, GetTaskRestart(&TaskConfig{Type: TaskTypeWatch, Restart: RestartNever}))
+	assert.True(t, IsWatchTask(&TaskConfig{Type: TaskTypeWatch}))
+	assert.False(t, IsWatchTask(&TaskConfig{Run: "make"}))
+	assert.False(t, IsWatchTask(nil))
+}
+
+func TestGetTaskWarm(t *testing.T) {
+	assert.Equal(t, 15*time.Minute, GetTaskWarm(&TaskConfig{Parallel: []string{"a"}, Warm: "15m"}))
+	assert.Zero(t, GetTaskWarm(&TaskConfig{Parallel: []string{"a"}}))
+	assert.Zero(t, GetTaskWarm(nil))
+}
+
+func TestGetIdleTimeout(t *testing.T) {
+	cfg := &Config{Defaults: ProjectDefaults{IdleTimeout: "15m"}}
+
+	timeout, kill := GetIdleTimeout(cfg, nil)
+	assert.Equal(t, 15*time.Minute, timeout, "rr run uses the project default")
+	assert.False(t, kill)
+
+	timeout, kill = GetIdleTimeout(cfg, &TaskConfig{Run: "make"})
+	assert.Equal(t, 15*time.Minute, timeout, "tasks without their own fall back to the default")
+	assert.False(t, kill)
+
+	timeout, kill = GetIdleTimeout(cfg, &TaskConfig{Run: "make", IdleTimeout: "2m", IdleKill: true})
+	assert.Equal(t, 2*time.Minute, timeout)
+	assert.True(t, kill)
+
+	timeout, _ = GetIdleTimeout(&Config{}, &TaskConfig{Run: "make"})
+	assert.Zero(t, timeout, "no limit unless one is set")
+}
+
+func TestParsePortForward(t *testing.T) {
+	tests := []struct {
+		spec    string
+		local   int
+		remote  int
+		wantErr bool
+	}{
+		{"3000", 3000, 3000, false},
+		{"8080:3000", 8080, 3000, false},
+		{" 9230 : 9229 ", 9230, 9229, false},
+		{"", 0, 0, true},
+		{"http", 0, 0, true},
+		{"0", 0, 0, true},
+		{"65536", 0, 0, true},
+		{"8080:", 0, 0, true},
+		{"1:2:3", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			local, remote, err := ParsePortForward(tt.spec)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.local, local)
+			assert.Equal(t, tt.remote, remote)
+		})
+	}
+}
+
 // Note: formatList was removed - use util.JoinOrNone instead.
 // Tests are in internal/util/strings_test.go
@@ -2,6 +2,8 @@ package config
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 	"time"
 
@@ -22,6 +24,134 @@ type GlobalConfig struct {
 	Hosts    map[string]Host `yaml:"hosts" mapstructure:"hosts"`
 	Defaults GlobalDefaults  `yaml:"defaults" mapstructure:"defaults"`
 	Logs     LogsConfig      `yaml:"logs" mapstructure:"logs"`
+	Theme    ThemeConfig     `yaml:"theme,omitempty" mapstructure:"theme"`
+
+	// Accessible turns on accessible output for every command, like
+	// --accessible: plain ASCII symbols and line-by-line status updates
+	// instead of animations.
+	Accessible bool `yaml:"accessible,omitempty" mapstructure:"accessible"`
+
+	// LowBandwidth is when to use low-bandwidth mode, for tethered or hotel
+	// connections: "auto" (default) when connecting to a host is slow,
+	// "always" like --low-bandwidth, or "never".
+	LowBandwidth string `yaml:"low_bandwidth,omitempty" mapstructure:"low_bandwidth"`
+
+	// Stats turns on recording anonymized run statistics for 'rr stats':
+	// phase timings and error IDs, kept in ~/.rr/stats.json and never sent
+	// anywhere. Off unless set.
+	Stats bool `yaml:"stats,omitempty" mapstructure:"stats"`
+
+	// Secrets is where the secrets named by keychain: values are kept:
+	// "auto" (default), "keychain", "libsecret", or "file".
+	Secrets string `yaml:"secrets,omitempty" mapstructure:"secrets"`
+
+	// TagDefaults are settings shared by every host with a tag, keyed by tag
+	// name. They're merged into the hosts by LoadGlobal.
+	TagDefaults map[string]HostDefaults `yaml:"tag_defaults,omitempty" mapstructure:"tag_defaults"`
+
+	// Plugins opts rr-<name> plugins on PATH into output parsing and phase
+	// hooks. Plugin subcommands work without any config.
+	Plugins PluginsConfig `yaml:"plugins,omitempty" mapstructure:"plugins"`
+
+	// Inventory lists sources that discover hosts when rr runs, like
+	// Tailscale peers or cloud instances with a tag. Discovered hosts are
+	// used alongside the ones in Hosts, which win if a name clashes.
+	Inventory []InventoryConfig `yaml:"inventory,omitempty" mapstructure:"inventory"`
+
+	// discovered maps the hosts an inventory source added to the provider
+	// that found them. They're never written back to the file.
+	discovered map[string]string
+
+	// dirTemplates are the hosts' dirs before variables were expanded, for
+	// finding every directory a template like ~/rr/${BRANCH} has made.
+	dirTemplates map[string]string
+
+	// declared and loaded are the hosts as written in the file and as
+	// LoadGlobal returned them, so SaveGlobal can write back what the file
+	// said for fields nobody changed, instead of the merged tag defaults.
+	declared map[string]Host
+	loaded   map[string]Host
+
+	// read is a hash of the file LoadGlobal read, or empty for a config
+	// that didn't come from the file. SaveGlobal checks it to catch another
+	// command saving in between.
+	read string
+}
+
+// ThemeConfig picks the terminal color palette.
+type ThemeConfig struct {
+	// Name is a built-in theme: synthwave (default), light, or ansi.
+	Name string `yaml:"name,omitempty" mapstructure:"name"`
+
+	// Colors overrides individual colors of the theme, keyed by color name
+	// (text, muted, success, accent, ...). Values are hex colors ("#FF2E97"),
+	// ANSI color numbers ("205"), or "default" for the terminal's own color.
+	Colors map[string]string `yaml:"colors,omitempty" mapstructure:"colors"`
+}
+
+// Inventory providers for InventoryConfig.Provider.
+const (
+	InventoryTailscale = "tailscale"
+	InventoryAWS       = "aws"
+	InventoryGCP       = "gcp"
+)
+
+// DefaultInventoryCacheTTL is how long discovered hosts are reused before
+// the provider is asked again.
+const DefaultInventoryCacheTTL = 5 * time.Minute
+
+// InventoryConfig is a source of hosts discovered at run time. Every host it
+// finds gets the same dir and tags, so tag_defaults can fill in the rest.
+type InventoryConfig struct {
+	// Provider is where hosts come from: tailscale, aws, or gcp. Each one
+	// runs its CLI (tailscale, aws, gcloud), so it uses whatever login
+	// that CLI already has.
+	Provider string `yaml:"provider" mapstructure:"provider"`
+
+	// Tag picks Tailscale peers with this ACL tag ("gpu" or "tag:gpu").
+	// Empty takes every online peer.
+	Tag string `yaml:"tag,omitempty" mapstructure:"tag"`
+
+	// Filters pick running AWS instances by tag, or GCP instances by label.
+	// Every key has to match its value.
+	Filters map[string]string `yaml:"filters,omitempty" mapstructure:"filters"`
+
+	// Region is the AWS region to search. Empty uses the AWS CLI's default.
+	Region string `yaml:"region,omitempty" mapstructure:"region"`
+
+	// Project is the GCP project to search. Empty uses gcloud's default.
+	Project string `yaml:"project,omitempty" mapstructure:"project"`
+
+	// User is the SSH user for discovered hosts. Empty leaves it to
+	// ~/.ssh/config or the local user name.
+	User string `yaml:"user,omitempty" mapstructure:"user"`
+
+	// Prefix is put in front of every discovered host's name, to keep
+	// names from different sources apart (e.g., "ts-").
+	Prefix string `yaml:"prefix,omitempty" mapstructure:"prefix"`
+
+	// Dir is the working directory on discovered hosts. Can be left empty
+	// if one of Tags has tag_defaults with a dir.
+	Dir string `yaml:"dir,omitempty" mapstructure:"dir"`
+
+	// Tags are given to every discovered host, for --tag and tag_defaults.
+	Tags []string `yaml:"tags,omitempty" mapstructure:"tags"`
+
+	// CacheTTL is how long discovered hosts are reused before the provider
+	// is asked again. Defaults to DefaultInventoryCacheTTL.
+	CacheTTL time.Duration `yaml:"cache_ttl,omitempty" mapstructure:"cache_ttl"`
+}
+
+// PluginsConfig lists the plugins rr calls during runs, by name (the part
+// after "rr-" in the binary's name).
+type PluginsConfig struct {
+	// Parsers are asked for test failures when no built-in parser
+	// recognizes a command's output, in order, until one finds some.
+	Parsers []string `yaml:"parsers,omitempty" mapstructure:"parsers"`
+
+	// Hooks are sent an event as each phase of a run completes and when
+	// the run finishes.
+	Hooks []string `yaml:"hooks,omitempty" mapstructure:"hooks"`
 }
 
 // GlobalDefaults contains default settings for host selection and connection.
@@ -44,6 +174,11 @@ type ProjectDefaults struct {
 	// Env contains environment variables applied to all tasks.
 	// These override host env but are overridden by task-specific env.
 	Env map[string]string `yaml:"env" mapstructure:"env"`
+
+	// IdleTimeout and IdleKill apply to 'rr run', 'rr exec', and tasks that
+	// don't set their own (see TaskConfig.IdleTimeout).
+	IdleTimeout string `yaml:"idle_timeout,omitempty" mapstructure:"idle_timeout"`
+	IdleKill    bool   `yaml:"idle_kill,omitempty" mapstructure:"idle_kill"`
 }
 
 // Config represents the project-level .rr.yaml configuration file.
@@ -59,10 +194,31 @@ type Config struct {
 	Tasks         map[string]TaskConfig `yaml:"tasks" mapstructure:"tasks"`
 	Output        OutputConfig          `yaml:"output" mapstructure:"output"`
 	Monitor       MonitorConfig         `yaml:"monitor" mapstructure:"monitor"`
+	Cache         CacheConfig           `yaml:"cache,omitempty" mapstructure:"cache"`
+
+	// StepsLib holds named step sequences that task steps pull in with
+	// {use: <name>}, so shared sequences are written once.
+	StepsLib map[string][]TaskStep `yaml:"steps_lib,omitempty" mapstructure:"steps_lib"`
 
 	// Require lists tools that must be available on remote hosts.
 	// Checked before sync; uses built-in installers when available.
 	Require []string `yaml:"require,omitempty" mapstructure:"require"`
+
+	// Bootstrap is a script run in the project's directory on a host the
+	// first time the project syncs there, for one-time setup like creating
+	// a virtualenv. A marker on the host keeps it from running again unless
+	// --rebootstrap is passed.
+	Bootstrap string `yaml:"bootstrap,omitempty" mapstructure:"bootstrap"`
+
+	// MirrorTo is a host that every successful sync is copied to as well,
+	// in the background, so it's ready to take over if the one synced to
+	// goes down. Empty mirrors nowhere.
+	MirrorTo string `yaml:"mirror_to,omitempty" mapstructure:"mirror_to"`
+
+	// Discovery is how far up from the working directory the config is
+	// found: "git" (the default) only from inside its own git repo,
+	// "workspace" also from the git repos in the directories below it.
+	Discovery string `yaml:"discovery,omitempty" mapstructure:"discovery"`
 }
 
 // Host defines a remote machine and its connection settings.
@@ -78,9 +234,45 @@ type Host struct {
 	// Tags for filtering hosts with --tag flag.
 	Tags []string `yaml:"tags" mapstructure:"tags"`
 
-	// Env contains environment variables specific to this host.
+	// Env contains environment variables specific to this host. They're
+	// exported for every command rr runs there: run/exec, task steps, lock
+	// operations and monitor metrics collection.
 	Env map[string]string `yaml:"env" mapstructure:"env"`
 
+	// ProfileFiles are shell files sourced before every command on this host
+	// (e.g., "~/.cargo/env"). Files that don't exist are skipped.
+	ProfileFiles []string `yaml:"profile_files,omitempty" mapstructure:"profile_files"`
+
+	// IdentityFile is the private key to authenticate with (e.g., ~/.ssh/work_ed25519).
+	// Tried before the SSH agent and ~/.ssh/config. Empty uses the usual key search.
+	IdentityFile string `yaml:"identity_file,omitempty" mapstructure:"identity_file"`
+
+	// IdentityPassphrase unlocks an encrypted identity_file that isn't in the
+	// SSH agent. It has to name a stored secret (keychain:<name>), never hold
+	// the passphrase itself.
+	IdentityPassphrase string `yaml:"identity_passphrase,omitempty" mapstructure:"identity_passphrase"`
+
+	// SSHOptions tunes the SSH connections to this host, both rr's own and
+	// the ones rsync opens.
+	SSHOptions SSHOptions `yaml:"ssh_options,omitempty" mapstructure:"ssh_options"`
+
+	// Fallback is how the SSH entries are tried: "order" (the default) tries
+	// them one at a time as listed, "race" tries them all at once and keeps
+	// the first to connect.
+	Fallback string `yaml:"fallback,omitempty" mapstructure:"fallback"`
+
+	// Transport is how rr runs commands on the host: "builtin" (the
+	// default) uses rr's own SSH client, "system-ssh" runs the system's ssh
+	// binary, for setups rr's client can't handle (GSSAPI, key types or
+	// agents it doesn't support, unusual ProxyCommands).
+	Transport string `yaml:"transport,omitempty" mapstructure:"transport"`
+
+	// RsyncPath is the rsync to run on this host, passed to rsync as
+	// --rsync-path, for hosts where PATH finds an old rsync or none (e.g.,
+	// /opt/homebrew/bin/rsync on a Mac). Empty uses the rsync host
+	// detection found there, if it's better than PATH's.
+	RsyncPath string `yaml:"rsync_path,omitempty" mapstructure:"rsync_path"`
+
 	// Shell specifies how to invoke the shell for commands.
 	// Default uses $SHELL -l -c (user's login shell) to ensure PATH is set up.
 	// Use "sh -c" for minimal shell without profile loading.
@@ -94,6 +286,95 @@ type Host struct {
 	// Require lists tools that must be available on this host.
 	// Uses built-in installers when available (go, node, cargo, etc.).
 	Require []string `yaml:"require,omitempty" mapstructure:"require"`
+
+	// secretRefs is set on the copy ResolveHostSecrets returns, and holds
+	// the references it replaced by env key, with identity_passphrase's
+	// under "". SaveGlobal puts them back.
+	secretRefs map[string]string
+}
+
+// Fallback strategies for Host.Fallback.
+const (
+	FallbackOrder = "order"
+	FallbackRace  = "race"
+)
+
+// Transports for Host.Transport.
+const (
+	TransportBuiltin   = "builtin"
+	TransportSystemSSH = "system-ssh"
+)
+
+// SSHOptions are per-host SSH connection settings, named after their
+// ssh_config(5) equivalents.
+type SSHOptions struct {
+	// Compression compresses traffic, which helps on slow links and costs
+	// CPU on fast ones. Only rsync and ssh use it; rr's own connections
+	// don't support compression. Low-bandwidth mode turns it on for every
+	// host.
+	Compression bool `yaml:"compression,omitempty" mapstructure:"compression"`
+
+	// Ciphers replaces the offered ciphers, in order of preference
+	// (e.g., aes128-gcm@openssh.com on hosts with AES hardware).
+	Ciphers []string `yaml:"ciphers,omitempty" mapstructure:"ciphers"`
+
+	// ServerAliveInterval sends a keepalive this often, and drops the
+	// connection once 3 in a row go unanswered. 0 disables keepalives.
+	ServerAliveInterval time.Duration `yaml:"server_alive_interval,omitempty" mapstructure:"server_alive_interval"`
+
+	// ConnectTimeout is how long to wait for the host to answer. It
+	// replaces probe_timeout for this host.
+	ConnectTimeout time.Duration `yaml:"connect_timeout,omitempty" mapstructure:"connect_timeout"`
+}
+
+// Args returns the options as ssh command-line flags, for the ssh and rsync
+// processes rr starts. Durations are rounded up to whole seconds.
+func (o SSHOptions) Args() []string {
+	var args []string
+	if o.Compression || LowBandwidth() {
+		args = append(args, "-o", "Compression=yes")
+	}
+	if len(o.Ciphers) > 0 {
+		args = append(args, "-o", "Ciphers="+strings.Join(o.Ciphers, ","))
+	}
+	if o.ServerAliveInterval > 0 {
+		args = append(args, "-o", fmt.Sprintf("ServerAliveInterval=%d", ceilSeconds(o.ServerAliveInterval)))
+	}
+	if o.ConnectTimeout > 0 {
+		args = append(args, "-o", fmt.Sprintf("ConnectTimeout=%d", ceilSeconds(o.ConnectTimeout)))
+	}
+	return args
+}
+
+// ceilSeconds returns d in whole seconds, rounded up.
+func ceilSeconds(d time.Duration) int64 {
+	return int64((d + time.Second - 1) / time.Second)
+}
+
+// HostDefaults are the host settings a tag can supply (tag_defaults in the
+// global config). A host's own settings take precedence.
+type HostDefaults struct {
+	// Dir is used by hosts that don't set their own. Supports the same
+	// variables as Host.Dir.
+	Dir string `yaml:"dir,omitempty" mapstructure:"dir"`
+
+	// Env is merged into the host's env. The host's values win.
+	Env map[string]string `yaml:"env,omitempty" mapstructure:"env"`
+
+	// ProfileFiles are sourced before the host's own.
+	ProfileFiles []string `yaml:"profile_files,omitempty" mapstructure:"profile_files"`
+
+	// IdentityFile is used by hosts that don't set their own.
+	IdentityFile string `yaml:"identity_file,omitempty" mapstructure:"identity_file"`
+
+	// Shell is used by hosts that don't set their own.
+	Shell string `yaml:"shell,omitempty" mapstructure:"shell"`
+
+	// SetupCommands run before the host's own.
+	SetupCommands []string `yaml:"setup_commands,omitempty" mapstructure:"setup_commands"`
+
+	// Require is added to the host's required tools.
+	Require []string `yaml:"require,omitempty" mapstructure:"require"`
 }
 
 // LockfileInvalidation maps a lockfile to remote directories that should be
@@ -129,8 +410,69 @@ type SyncConfig struct {
 	// lockfile changes. Prevents stale install directories (node_modules, .venv,
 	// etc.) from being used after a lockfile update.
 	Invalidations []LockfileInvalidation `yaml:"invalidations" mapstructure:"invalidations"`
+
+	// Parallel splits the sync of a very large project (100k+ files) into
+	// up to this many concurrent rsync processes, each moving a shard of
+	// its directories. 0 or 1 uses a single rsync.
+	Parallel int `yaml:"parallel,omitempty" mapstructure:"parallel"`
+
+	// Prescan dry-runs each sync first to count the files and bytes it will
+	// transfer, so the progress bar measures against real totals.
+	Prescan bool `yaml:"prescan" mapstructure:"prescan"`
+
+	// Profiles are named alternatives to the settings above, for tasks that
+	// need a different sync, like one that also sends a data directory.
+	// Tasks pick one with sync_profile; 'rr sync --profile' does too.
+	Profiles map[string]SyncProfile `yaml:"profiles,omitempty" mapstructure:"profiles"`
+}
+
+// SyncProfile is a named set of sync settings. Settings a profile leaves
+// out come from the sync section it's defined in.
+type SyncProfile struct {
+	RespectGitignore *bool    `yaml:"respect_gitignore,omitempty" mapstructure:"respect_gitignore"`
+	Exclude          []string `yaml:"exclude,omitempty" mapstructure:"exclude"`
+	Preserve         []string `yaml:"preserve,omitempty" mapstructure:"preserve"`
+	Flags            []string `yaml:"flags,omitempty" mapstructure:"flags"`
 }
 
+// WithProfile returns the sync settings with the named profile applied. An
+// empty name returns them unchanged.
+func (s SyncConfig) WithProfile(name string) (SyncConfig, error) {
+	if name == "" {
+		return s, nil
+	}
+	profile, ok := s.Profiles[name]
+	if !ok {
+		return s, fmt.Errorf("sync profile '%s' isn't defined%s", name, syncProfileList(s.Profiles))
+	}
+	if profile.RespectGitignore != nil {
+		s.RespectGitignore = *profile.RespectGitignore
+	}
+	if profile.Exclude != nil {
+		s.Exclude = profile.Exclude
+	}
+	if profile.Preserve != nil {
+		s.Preserve = profile.Preserve
+	}
+	if profile.Flags != nil {
+		s.Flags = profile.Flags
+	}
+	return s, nil
+}
+
+// syncProfileList describes the defined profiles for an error message.
+func syncProfileList(profiles map[string]SyncProfile) string {
+	if len(profiles) == 0 {
+		return " - add it under sync.profiles"
+	}
+	return " - defined profiles: " + strings.Join(slices.Sorted(maps.Keys(profiles)), ", ")
+}
+
+// MaxSyncParallel caps sync.parallel. Every rsync is an SSH session over the
+// shared ControlMaster connection, and sshd allows 10 per connection by
+// default (MaxSessions).
+const MaxSyncParallel = 8
+
 // LockConfig controls the distributed lock behavior to prevent concurrent executions.
 type LockConfig struct {
 	// Enabled toggles locking on/off.
@@ -149,10 +491,31 @@ type LockConfig struct {
 
 	// Dir is the directory where lock files are stored on the remote.
 	Dir string `yaml:"dir" mapstructure:"dir"`
+
+	// Scope is what a lock keeps from running at the same time: "host" (the
+	// default) allows one rr run per host, "project" one per project, and
+	// "command" one per command of a project.
+	Scope string `yaml:"scope,omitempty" mapstructure:"scope"`
+
+	// ShareOutput shares the last line of the running command's output in
+	// the lock dir, so runs waiting on the lock can show how far along it
+	// is. Anyone who can read the lock dir on the host can read it.
+	ShareOutput bool `yaml:"share_output" mapstructure:"share_output"`
 }
 
+// Lock scopes for LockConfig.Scope.
+const (
+	LockScopeHost    = "host"
+	LockScopeProject = "project"
+	LockScopeCommand = "command"
+)
+
 // TaskConfig defines a named task (command sequence).
 type TaskConfig struct {
+	// Extends names a task this one inherits from. Fields left unset here
+	// are taken from it (see resolveTaskReuse).
+	Extends string `yaml:"extends,omitempty" mapstructure:"extends"`
+
 	// Description shown in rr --help.
 	Description string `yaml:"description" mapstructure:"description"`
 
@@ -174,6 +537,17 @@ type TaskConfig struct {
 	// Env contains environment variables for this task.
 	Env map[string]string `yaml:"env" mapstructure:"env"`
 
+	// Shell runs the task's command, and each of its steps, through this
+	// shell instead of the login shell, e.g. "bash -l -c" or "zsh -i -c".
+	// Like a host's shell, it ends with the flag that takes the command.
+	Shell string `yaml:"shell,omitempty" mapstructure:"shell"`
+
+	// Raw runs the command as a list of arguments, with nothing expanded:
+	// run is split into words like a shell would, but variables, globs,
+	// pipes, and redirects in it are passed on literally. Extra CLI
+	// arguments are each passed as one more word.
+	Raw bool `yaml:"raw,omitempty" mapstructure:"raw"`
+
 	// Parallel is a list of task names to run concurrently.
 	// When set, this task becomes a parallel orchestrator and Run/Steps are ignored.
 	Parallel []string `yaml:"parallel" mapstructure:"parallel"`
@@ -195,9 +569,35 @@ type TaskConfig struct {
 	// Applies to individual tasks and parallel orchestrators.
 	Timeout string `yaml:"timeout" mapstructure:"timeout"`
 
-	// Output controls how task output is displayed: "progress", "stream", "verbose", "quiet".
-	// Overrides the global output settings for this task.
-	Output string `yaml:"output" mapstructure:"output"`
+	// Warm keeps a parallel task's hosts warm between runs (e.g., "15m"):
+	// a run within this long of the last one skips the sync when nothing
+	// changed locally, and skips Setup when the synced files haven't changed
+	// since it last succeeded. Only applies when Parallel is set.
+	Warm string `yaml:"warm,omitempty" mapstructure:"warm"`
+
+	// OnConfigChange is what a parallel task does when .rr.yaml changes
+	// while it runs: "warn" (default) or "apply", which also runs subtasks
+	// that haven't started with their new definitions.
+	OnConfigChange string `yaml:"on_config_change,omitempty" mapstructure:"on_config_change"`
+
+	// IdleTimeout is how long the command may go without printing anything
+	// (e.g., "10m") before rr warns and captures a snapshot of the host's
+	// processes. Catches commands that hang silently.
+	IdleTimeout string `yaml:"idle_timeout,omitempty" mapstructure:"idle_timeout"`
+
+	// IdleKill stops the command once IdleTimeout passes without output,
+	// instead of only warning.
+	IdleKill bool `yaml:"idle_kill,omitempty" mapstructure:"idle_kill"`
+
+	// OnCancel is a command run on the host, in the task's dir and env, after
+	// the task is interrupted (Ctrl+C) and its command has stopped. Useful
+	// for tearing down containers or test databases the command started.
+	OnCancel string `yaml:"on_cancel,omitempty" mapstructure:"on_cancel"`
+
+	// Output overrides the project's output settings for this task. Accepts
+	// a string (the display mode) or an object with mode, format, and
+	// verbosity.
+	Output TaskOutput `yaml:"output,omitempty" mapstructure:"output"`
 
 	// Require lists additional tools needed for this specific task.
 	// Combined with project and host requirements.
@@ -209,10 +609,148 @@ type TaskConfig struct {
 	// With destinations: pull: [{src: dist/*.whl, dest: ./artifacts/}]
 	Pull []PullItem `yaml:"pull,omitempty" mapstructure:"pull"`
 
+	// Build is a command run locally, in the project root, before the sync.
+	// A failed build stops the task before anything is sent to the remote.
+	Build string `yaml:"build,omitempty" mapstructure:"build"`
+
+	// Push limits the sync to these paths, relative to the project root
+	// (usually what Build produces). When set, only they are synced instead
+	// of the whole project.
+	// Example: push: [dist/, bin/server]
+	Push []string `yaml:"push,omitempty" mapstructure:"push"`
+
+	// SyncProfile picks one of sync.profiles for this task's sync instead
+	// of the sync section's own settings.
+	// Example: sync_profile: data
+	SyncProfile string `yaml:"sync_profile,omitempty" mapstructure:"sync_profile"`
+
+	// Outputs are paths this task produces on its host, relative to the
+	// host's dir. Tasks that list this one in Inputs receive them.
+	Outputs []string `yaml:"outputs,omitempty" mapstructure:"outputs"`
+
+	// Inputs names tasks whose outputs this task needs. Each runs first, on
+	// its own hosts, and its outputs are copied to this task's host before
+	// this task runs. This lets a pipeline span hosts.
+	// Example: inputs: [build-linux]
+	Inputs []string `yaml:"inputs,omitempty" mapstructure:"inputs"`
+
 	// ForwardArgs appends extra CLI arguments to each subtask's run command.
 	// Only valid for parallel tasks where all subtasks use a single run command (not steps).
 	// Enables: rr test-backend -k bond  (forwards "-k bond" to each subtask)
 	ForwardArgs bool `yaml:"forward_args" mapstructure:"forward_args"`
+
+	// Speculative runs the task on the two highest-priority hosts at once and
+	// takes the first successful result, cancelling the other. Masks a slow or
+	// flaky host at the cost of running the task twice. Meant for short tasks.
+	Speculative bool `yaml:"speculative,omitempty" mapstructure:"speculative"`
+
+	// Shard runs the task as Count copies at once across the available
+	// hosts, each with SHARD_INDEX (1 to Count) and SHARD_TOTAL set so the
+	// test runner can pick its share of the suite.
+	// Example: shard: {count: 8, strategy: duration}
+	Shard ShardConfig `yaml:"shard,omitempty" mapstructure:"shard"`
+
+	// Reserve claims CPU cores and memory on the task's host while it runs.
+	// Hosts whose free, unreserved capacity is too small are skipped.
+	// Example: reserve: {cpus: 8, memory: 16GB}
+	Reserve ReserveConfig `yaml:"reserve,omitempty" mapstructure:"reserve"`
+
+	// Type is "watch" for long-lived commands like dev servers, which rr
+	// restarts when they crash and keeps synced while they run. Empty for
+	// ordinary tasks that run to completion.
+	Type string `yaml:"type,omitempty" mapstructure:"type"`
+
+	// Ports forwards ports from this machine to the host while a watch task
+	// runs: "3000" forwards localhost:3000 to port 3000 on the host,
+	// "8080:3000" forwards localhost:8080 to it.
+	Ports []string `yaml:"ports,omitempty" mapstructure:"ports"`
+
+	// Restart controls when a watch task's command is restarted after it
+	// exits: "on-failure" (default), "always", or "never".
+	Restart string `yaml:"restart,omitempty" mapstructure:"restart"`
+
+	// Params are inputs the task asks for when it's run, unless they're
+	// passed with --param name=value. Each value replaces {{name}} in the
+	// run command, steps, and env.
+	// Example: params: [{name: env, choices: [staging, prod]}]
+	Params []TaskParam `yaml:"params,omitempty" mapstructure:"params"`
+
+	// Sudo runs the command on a terminal and answers the sudo password
+	// prompts it raises with a password typed locally. Set it on a step
+	// instead when only some steps call sudo.
+	Sudo bool `yaml:"sudo,omitempty" mapstructure:"sudo"`
+
+	// SudoCache keeps the sudo password for the rest of the rr command, so
+	// later steps and tasks on the same host don't ask again. It's only
+	// ever held in memory.
+	SudoCache bool `yaml:"sudo_cache,omitempty" mapstructure:"sudo_cache"`
+
+	// Priority is the CPU and I/O priority the task runs at on the host:
+	// "low", "normal" (default), or "high". Low keeps long jobs like full
+	// test suites from slowing down other people on a shared host.
+	Priority string `yaml:"priority,omitempty" mapstructure:"priority"`
+}
+
+// TaskParam is an input a task asks for when it's run.
+type TaskParam struct {
+	// Name is what {{name}} placeholders and --param name=value refer to.
+	Name string `yaml:"name" mapstructure:"name"`
+
+	// Prompt is the question asked for the value. Defaults to the name.
+	Prompt string `yaml:"prompt,omitempty" mapstructure:"prompt"`
+
+	// Default is the value used when none is given. A param without one
+	// has to be given a value.
+	Default string `yaml:"default,omitempty" mapstructure:"default"`
+
+	// Choices, if set, are the only values the param accepts.
+	Choices []string `yaml:"choices,omitempty" mapstructure:"choices"`
+}
+
+// ShardConfig splits a task into shards.
+type ShardConfig struct {
+	// Count is how many shards to run.
+	Count int `yaml:"count,omitempty" mapstructure:"count"`
+
+	// Strategy is the order shards are handed to hosts: "round-robin"
+	// (default) in shard order, or "duration", slowest first by how long
+	// each took in recent runs, so a long shard doesn't start last.
+	Strategy string `yaml:"strategy,omitempty" mapstructure:"strategy"`
+}
+
+// Shard strategies for ShardConfig.Strategy.
+const (
+	ShardRoundRobin = "round-robin"
+	ShardDuration   = "duration"
+)
+
+// MaxShards caps shard.count. Each shard is a separate run on some host.
+const MaxShards = 256
+
+// ReserveConfig is the share of a host a task reserves.
+type ReserveConfig struct {
+	// CPUs is the number of cores, and may be fractional.
+	CPUs float64 `yaml:"cpus,omitempty" mapstructure:"cpus"`
+
+	// Memory is a size like "8GB" (see ParseSize).
+	Memory string `yaml:"memory,omitempty" mapstructure:"memory"`
+}
+
+// IsZero reports whether nothing is reserved.
+func (r ReserveConfig) IsZero() bool {
+	return r.CPUs == 0 && r.Memory == ""
+}
+
+// MemoryBytes returns Memory in bytes, or 0 if it's unset or invalid.
+func (r ReserveConfig) MemoryBytes() int64 {
+	if r.Memory == "" {
+		return 0
+	}
+	n, err := ParseSize(r.Memory)
+	if err != nil {
+		return 0
+	}
+	return n
 }
 
 // DependencyItem represents a single dependency which can be either
@@ -236,6 +774,10 @@ type PullItem struct {
 	// Dest is the local destination directory.
 	// If empty, files are pulled to the current directory.
 	Dest string `yaml:"dest,omitempty" mapstructure:"dest"`
+
+	// MaxSize caps the total size of what Src matches on the remote
+	// (e.g., "500MB", "2GB"). The pull is aborted if it's exceeded.
+	MaxSize string `yaml:"max_size,omitempty" mapstructure:"max_size"`
 }
 
 // UnmarshalYAML handles both string and object dependency formats.
@@ -340,6 +882,16 @@ func PullItemFromInterface(v interface{}) (PullItem, error) {
 				return PullItem{}, fmt.Errorf("pull dest: expected string, got %T", dest)
 			}
 		}
+		if maxSize, ok := val["max_size"]; ok {
+			switch m := maxSize.(type) {
+			case string:
+				p.MaxSize = m
+			case int:
+				p.MaxSize = fmt.Sprintf("%d", m)
+			default:
+				return PullItem{}, fmt.Errorf("pull max_size: expected string, got %T", maxSize)
+			}
+		}
 		if p.Src == "" {
 			return PullItem{}, fmt.Errorf("pull item must have 'src' field")
 		}
@@ -365,8 +917,99 @@ type TaskStep struct {
 	// Run is the command to execute.
 	Run string `yaml:"run" mapstructure:"run"`
 
+	// Dir is the directory to run this step in, relative to the project root.
+	// Lets multi-package repos run steps in subdirectories without "cd x &&".
+	Dir string `yaml:"dir" mapstructure:"dir"`
+
 	// OnFail controls behavior when step fails: "stop" (default) or "continue".
 	OnFail string `yaml:"on_fail" mapstructure:"on_fail"`
+
+	// If is an expression checked on the local machine before the task
+	// runs, like "files_changed('**/*.py')" or "env.CI != 'true'". The step
+	// is skipped when it's false. See ParseCondition.
+	If string `yaml:"if,omitempty" mapstructure:"if"`
+
+	// Pull lists files to download as soon as this step finishes, before
+	// the next step runs.
+	Pull []PullItem `yaml:"pull,omitempty" mapstructure:"pull"`
+
+	// PullOnFail lists files to download only when this step fails, like
+	// logs or screenshots that explain the failure.
+	PullOnFail []PullItem `yaml:"pull_on_fail,omitempty" mapstructure:"pull_on_fail"`
+
+	// Use replaces this step with the named steps_lib sequence. Dir, OnFail,
+	// and Output, when set, apply to the pulled-in steps that don't set
+	// their own. If applies on top of each pulled-in step's own, and Sudo
+	// turns sudo on for all of them.
+	Use string `yaml:"use,omitempty" mapstructure:"use"`
+
+	// Output overrides the task's output verbosity for this step: "quiet"
+	// holds the step's output back unless it fails, "normal" or "verbose"
+	// stream it.
+	Output string `yaml:"output,omitempty" mapstructure:"output"`
+
+	// Sudo lets this step answer sudo password prompts, as the task's Sudo
+	// does for the whole task.
+	Sudo bool `yaml:"sudo,omitempty" mapstructure:"sudo"`
+}
+
+// TaskOutput overrides how a task's output is shown.
+// Supports two YAML formats:
+//   - Simple string: "quiet" (the display mode)
+//   - Object: {format: "go", verbosity: "quiet"}
+type TaskOutput struct {
+	// Mode is how a parallel task shows its subtasks' output: "progress",
+	// "stream", "verbose", or "quiet".
+	Mode string `yaml:"mode,omitempty" mapstructure:"mode"`
+
+	// Format picks the formatter for the task's output, like output.format:
+	// "auto", "generic", "pytest", "jest", "go", or "cargo".
+	Format string `yaml:"format,omitempty" mapstructure:"format"`
+
+	// Verbosity "quiet" holds each step's output back and only prints it if
+	// the step fails. "normal" and "verbose" stream it as it runs.
+	Verbosity string `yaml:"verbosity,omitempty" mapstructure:"verbosity"`
+}
+
+// IsZero reports whether no output overrides are set.
+func (o TaskOutput) IsZero() bool {
+	return o == TaskOutput{}
+}
+
+// UnmarshalYAML implements custom YAML unmarshaling for TaskOutput.
+// Handles both string and object formats.
+func (o *TaskOutput) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		o.Mode = value.Value
+		return nil
+	}
+	type taskOutput TaskOutput
+	return value.Decode((*taskOutput)(o))
+}
+
+// TaskOutputFromInterface converts a generic interface (from
+// viper/mapstructure) to a TaskOutput. Handles both string and map formats.
+func TaskOutputFromInterface(v interface{}) (TaskOutput, error) {
+	switch val := v.(type) {
+	case string:
+		return TaskOutput{Mode: val}, nil
+	case map[string]interface{}:
+		o := TaskOutput{}
+		for key, field := range map[string]*string{"mode": &o.Mode, "format": &o.Format, "verbosity": &o.Verbosity} {
+			raw, ok := val[key]
+			if !ok {
+				continue
+			}
+			s, ok := raw.(string)
+			if !ok {
+				return TaskOutput{}, fmt.Errorf("output %s: expected string, got %T", key, raw)
+			}
+			*field = s
+		}
+		return o, nil
+	default:
+		return TaskOutput{}, fmt.Errorf("output: expected string or map, got %T", v)
+	}
 }
 
 // OutputConfig controls terminal output formatting.
@@ -385,6 +1028,23 @@ type OutputConfig struct {
 	Verbosity string `yaml:"verbosity" mapstructure:"verbosity"`
 }
 
+// CacheConfig sets up the rr-managed cache directory on each host. Commands
+// get its path as $RR_CACHE, for things like pip and npm caches that should
+// outlive syncs. rr deletes what's gone unused from it after tasks run.
+type CacheConfig struct {
+	// Dir is where the cache lives on each host. Supports ${PROJECT},
+	// ${USER}, and ~. Defaults to DefaultCacheDir.
+	Dir string `yaml:"dir,omitempty" mapstructure:"dir"`
+
+	// TTL is how long a file can go unused before it's deleted (e.g.,
+	// "168h"). Defaults to DefaultCacheTTL.
+	TTL string `yaml:"ttl,omitempty" mapstructure:"ttl"`
+
+	// MaxSize caps the cache's size (e.g., "10GB"). Past it, the least
+	// recently used files are deleted first. Empty for no cap.
+	MaxSize string `yaml:"max_size,omitempty" mapstructure:"max_size"`
+}
+
 // MonitorConfig controls the resource monitoring dashboard.
 type MonitorConfig struct {
 	// Interval between metric updates (e.g., "2s", "5s").
@@ -399,8 +1059,20 @@ type MonitorConfig struct {
 
 	// Exclude lists host names to exclude from the monitor dashboard.
 	Exclude []string `yaml:"exclude" mapstructure:"exclude"`
+
+	// Concurrency is how many hosts metrics are collected from at once.
+	// Zero uses the default, 8.
+	Concurrency int `yaml:"concurrency,omitempty" mapstructure:"concurrency"`
+
+	// Cards lists the sections host cards show, in order, from
+	// MonitorCardSections. Sections left out are hidden. Empty shows them all.
+	Cards []string `yaml:"cards,omitempty" mapstructure:"cards"`
 }
 
+// MonitorCardSections are the sections a monitor card can show, in their
+// default order.
+var MonitorCardSections = []string{"cpu", "gpu", "latency", "ram", "top", "net"}
+
 // ThresholdConfig defines warning and critical thresholds for metrics.
 type ThresholdConfig struct {
 	CPU ThresholdValues `yaml:"cpu" mapstructure:"cpu"`
@@ -459,6 +1131,7 @@ func DefaultConfig() *Config {
 		Host:    "",
 		Sync: SyncConfig{
 			RespectGitignore: true,
+			Prescan:          true,
 			Exclude: []string{
 				".git/",
 				".venv/",
@@ -497,6 +1170,8 @@ func DefaultConfig() *Config {
 			WaitTimeout: 1 * time.Minute,
 			Stale:       3 * time.Minute,
 			Dir:         "/tmp/rr-locks",
+			Scope:       LockScopeHost,
+			ShareOutput: true,
 		},
 		Tasks: make(map[string]TaskConfig),
 		Output: OutputConfig{
@@ -523,6 +1198,20 @@ func IsParallelTask(task *TaskConfig) bool {
 	return len(task.Parallel) > 0
 }
 
+// IsShardedTask returns true if the task is split into shards.
+func IsShardedTask(task *TaskConfig) bool {
+	return task.Shard.Count > 0
+}
+
+// GetShardStrategy returns the task's shard strategy, defaulting to
+// round-robin.
+func GetShardStrategy(task *TaskConfig) string {
+	if task.Shard.Strategy == "" {
+		return ShardRoundRobin
+	}
+	return task.Shard.Strategy
+}
+
 // HasDependencies returns true if the task has dependencies that must run first.
 func HasDependencies(task *TaskConfig) bool {
 	return len(task.Depends) > 0
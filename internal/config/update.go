@@ -91,7 +91,71 @@ func AddSetupCommand(configPath, hostName, setupCommand string) error {
 	}
 	encoder.Close()
 
-	if err := os.WriteFile(configPath, []byte(buf.String()), 0644); err != nil {
+	if err := WriteFile(configPath, []byte(buf.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	return nil
+}
+
+// SetMonitorCards sets monitor.cards in the project config at configPath,
+// adding the monitor section if there isn't one, and keeps the rest of the
+// file as it is.
+func SetMonitorCards(configPath string, cards []string) error {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if root.Kind == 0 {
+		// An empty file has no document yet
+		root = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 {
+		return fmt.Errorf("invalid YAML document structure")
+	}
+
+	docNode := root.Content[0]
+	if docNode.Kind != yaml.MappingNode {
+		return fmt.Errorf("expected mapping at document root")
+	}
+
+	monitorNode := findMapValue(docNode, "monitor")
+	switch {
+	case monitorNode == nil:
+		monitorNode = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		docNode.Content = append(docNode.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "monitor"}, monitorNode)
+	case monitorNode.Kind != yaml.MappingNode:
+		// "monitor:" with nothing under it
+		*monitorNode = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+
+	// One short line reads better than a list of six
+	cardsNode := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle}
+	for _, card := range cards {
+		cardsNode.Content = append(cardsNode.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: card})
+	}
+	if existing := findMapValue(monitorNode, "cards"); existing != nil {
+		*existing = *cardsNode
+	} else {
+		monitorNode.Content = append(monitorNode.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "cards"}, cardsNode)
+	}
+
+	var buf strings.Builder
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&root); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	encoder.Close()
+
+	if err := WriteFile(configPath, []byte(buf.String()), 0644); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
@@ -11,6 +11,7 @@ import (
 )
 
 func TestAddSetupCommand(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // Keep the config backups out of the real ~/.rr
 	tests := []struct {
 		name         string
 		initialYAML  string
@@ -114,6 +115,7 @@ hosts:
 }
 
 func TestAddSetupCommand_DoesNotDuplicate(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
 	tmpDir := t.TempDir()
 	configPath := filepath.Join(tmpDir, ".rr.yaml")
 
@@ -218,3 +220,45 @@ func TestToHomeRelativePath(t *testing.T) {
 		})
 	}
 }
+
+func TestSetMonitorCards(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	tests := []struct {
+		name        string
+		initialYAML string
+		want        string
+	}{
+		{
+			name:        "no monitor section",
+			initialYAML: "version: 1\nhost: gpu-box\n",
+			want:        "version: 1\nhost: gpu-box\nmonitor:\n  cards: [gpu, cpu]\n",
+		},
+		{
+			name:        "replaces existing cards",
+			initialYAML: "version: 1\nmonitor:\n  interval: 2s # fast\n  cards: [cpu, ram, net]\n",
+			want:        "version: 1\nmonitor:\n  interval: 2s # fast\n  cards: [gpu, cpu]\n",
+		},
+		{
+			name:        "empty monitor section",
+			initialYAML: "version: 1\nmonitor:\n",
+			want:        "version: 1\nmonitor:\n  cards: [gpu, cpu]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), ".rr.yaml")
+			require.NoError(t, os.WriteFile(configPath, []byte(tt.initialYAML), 0644))
+
+			require.NoError(t, SetMonitorCards(configPath, []string{"gpu", "cpu"}))
+
+			content, err := os.ReadFile(configPath)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(content))
+
+			cfg, err := Load(configPath)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"gpu", "cpu"}, cfg.Monitor.Cards)
+		})
+	}
+}
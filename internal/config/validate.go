@@ -2,10 +2,16 @@ package config
 
 import (
 	"fmt"
+	"maps"
+	"path"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/plugin"
+	"github.com/rileyhilliard/rr/internal/util"
+	"golang.org/x/crypto/ssh"
 )
 
 // ReservedTaskNames are command names that cannot be used as task names.
@@ -14,6 +20,7 @@ var ReservedTaskNames = map[string]bool{
 	"exec":       true,
 	"sync":       true,
 	"init":       true,
+	"onboard":    true,
 	"setup":      true,
 	"status":     true,
 	"monitor":    true,
@@ -25,6 +32,20 @@ var ReservedTaskNames = map[string]bool{
 	"host":       true,
 	"unlock":     true,
 	"tasks":      true,
+	"explain":    true,
+	"report":     true,
+	"replay":     true,
+	"results":    true,
+	"plugin":     true,
+	"cache":      true,
+	"prune":      true,
+	"env":        true,
+	"config":     true,
+	"cp":         true,
+	"stats":      true,
+	"resume":     true,
+	"probe":      true,
+	"secrets":    true,
 }
 
 // ValidationOption controls validation behavior.
@@ -36,6 +57,19 @@ type validationContext struct {
 
 // Validate checks the project config for errors and returns structured error messages.
 func Validate(cfg *Config, opts ...ValidationOption) error {
+	return withInvalidID(validateProject(cfg, opts...))
+}
+
+// withInvalidID tags validation failures that don't already carry a stable ID
+// with IDConfigInvalid so they can be looked up with 'rr explain'.
+func withInvalidID(err error) error {
+	if rrErr, ok := err.(*errors.Error); ok && rrErr.ID == "" {
+		return rrErr.WithID(errors.IDConfigInvalid)
+	}
+	return err
+}
+
+func validateProject(cfg *Config, opts ...ValidationOption) error {
 	ctx := &validationContext{}
 	for _, opt := range opts {
 		opt(ctx)
@@ -84,11 +118,30 @@ func Validate(cfg *Config, opts ...ValidationOption) error {
 		}
 	}
 
+	// Validate project defaults
+	if err := validateIdle("defaults", cfg.Defaults.IdleTimeout, cfg.Defaults.IdleKill); err != nil {
+		return errors.WrapWithCode(err, errors.ErrConfig, err.Error(), "Check the 'defaults' section in your .rr.yaml.")
+	}
+
 	// Validate output config
 	if err := validateOutput(cfg.Output); err != nil {
 		return errors.WrapWithCode(err, errors.ErrConfig, err.Error(), "Check the 'output' section in your .rr.yaml.")
 	}
 
+	// Validate sync config
+	if err := validateSync(cfg.Sync); err != nil {
+		return errors.WrapWithCode(err, errors.ErrConfig, err.Error(), "Check the 'sync' section in your .rr.yaml.")
+	}
+
+	// Validate the tasks' sync profiles exist
+	if err := validateSyncProfileRefs(cfg); err != nil {
+		return errors.WrapWithCode(err, errors.ErrConfig, err.Error(), "Check sync.profiles and your tasks' sync_profile in .rr.yaml.")
+	}
+
+	if err := validateCache(cfg.Cache); err != nil {
+		return errors.WrapWithCode(err, errors.ErrConfig, err.Error(), "Check the 'cache' section in your .rr.yaml.")
+	}
+
 	// Validate lock config
 	if err := validateLock(cfg.Lock); err != nil {
 		return errors.WrapWithCode(err, errors.ErrConfig, err.Error(), "Check the 'lock' section in your .rr.yaml.")
@@ -113,6 +166,20 @@ func Validate(cfg *Config, opts ...ValidationOption) error {
 	if err := ValidateDependencyGraph(cfg); err != nil {
 		return errors.WrapWithCode(err, errors.ErrConfig, err.Error(), "Check your task dependencies in .rr.yaml.")
 	}
+	if err := validateParamRefs(cfg); err != nil {
+		return errors.WrapWithCode(err, errors.ErrConfig, err.Error(), "Check the 'params' of your tasks in .rr.yaml.")
+	}
+	if err := validateSudoRefs(cfg); err != nil {
+		return errors.WrapWithCode(err, errors.ErrConfig, err.Error(), "Run the sudo task on its own, or from 'depends'.")
+	}
+
+	switch cfg.Discovery {
+	case "", DiscoveryGit, DiscoveryWorkspace:
+	default:
+		return errors.New(errors.ErrConfig,
+			fmt.Sprintf("discovery is '%s', which isn't a setting rr knows", cfg.Discovery),
+			fmt.Sprintf("Use '%s' or '%s' in .rr.yaml.", DiscoveryGit, DiscoveryWorkspace))
+	}
 
 	return nil
 }
@@ -135,6 +202,10 @@ func validateHostReference(host string) error {
 
 // ValidateGlobal checks the global config for errors.
 func ValidateGlobal(cfg *GlobalConfig) error {
+	return withInvalidID(validateGlobal(cfg))
+}
+
+func validateGlobal(cfg *GlobalConfig) error {
 	if cfg == nil {
 		return errors.New(errors.ErrConfig,
 			"Global config is nil",
@@ -155,6 +226,51 @@ func ValidateGlobal(cfg *GlobalConfig) error {
 		}
 	}
 
+	if err := validateInventory(cfg); err != nil {
+		return errors.WrapWithCode(err, errors.ErrConfig, err.Error(), "Check the inventory section of ~/.rr/config.yaml.")
+	}
+
+	switch cfg.LowBandwidth {
+	case "", LowBandwidthAuto, LowBandwidthAlways, LowBandwidthNever:
+	default:
+		return errors.New(errors.ErrConfig,
+			fmt.Sprintf("low_bandwidth is '%s', which isn't a setting rr knows", cfg.LowBandwidth),
+			fmt.Sprintf("Use '%s', '%s', or '%s' in ~/.rr/config.yaml.", LowBandwidthAuto, LowBandwidthAlways, LowBandwidthNever))
+	}
+
+	switch cfg.Secrets {
+	case "", SecretsAuto, SecretsKeychain, SecretsLibsecret, SecretsFile:
+	default:
+		return errors.New(errors.ErrConfig,
+			fmt.Sprintf("secrets is '%s', which isn't a store rr knows", cfg.Secrets),
+			fmt.Sprintf("Use '%s', '%s', '%s', or '%s' in ~/.rr/config.yaml.", SecretsAuto, SecretsKeychain, SecretsLibsecret, SecretsFile))
+	}
+
+	if err := validatePluginNames("parsers", cfg.Plugins.Parsers); err != nil {
+		return err
+	}
+	if err := validatePluginNames("hooks", cfg.Plugins.Hooks); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validatePluginNames checks that plugin names are bare names, not paths
+// or full binary names.
+func validatePluginNames(field string, names []string) error {
+	for _, name := range names {
+		if !plugin.ValidName(name) {
+			return errors.New(errors.ErrConfig,
+				fmt.Sprintf("plugins.%s has an invalid plugin name '%s'", field, name),
+				"Use the part of the binary's name after 'rr-', which can only hold letters, digits, '_' and '-': a plugin installed as rr-junit is listed as junit.")
+		}
+		if strings.HasPrefix(name, "rr-") {
+			return errors.New(errors.ErrConfig,
+				fmt.Sprintf("plugins.%s lists '%s' with its rr- prefix", field, name),
+				fmt.Sprintf("List it as '%s'; rr adds the prefix when it looks the binary up.", strings.TrimPrefix(name, "rr-")))
+		}
+	}
 	return nil
 }
 
@@ -195,7 +311,8 @@ func ValidateResolved(r *ResolvedConfig) error {
 				hostNames := getHostNames(r.Global.Hosts)
 				return errors.New(errors.ErrConfig,
 					fmt.Sprintf("Project references host '%s' which doesn't exist in global config", r.Project.Host),
-					fmt.Sprintf("Available hosts: %s. Add it to ~/.rr/config.yaml or change the host in .rr.yaml.", strings.Join(hostNames, ", ")))
+					fmt.Sprintf("Available hosts: %s. Add it to ~/.rr/config.yaml or change the host in .rr.yaml.", strings.Join(hostNames, ", "))).
+					WithID(errors.IDConfigHostNotFound)
 			}
 		}
 
@@ -205,7 +322,19 @@ func ValidateResolved(r *ResolvedConfig) error {
 				hostNames := getHostNames(r.Global.Hosts)
 				return errors.New(errors.ErrConfig,
 					fmt.Sprintf("Project references host '%s' which doesn't exist in global config", h),
-					fmt.Sprintf("Available hosts: %s. Add it to ~/.rr/config.yaml or remove it from .rr.yaml.", strings.Join(hostNames, ", ")))
+					fmt.Sprintf("Available hosts: %s. Add it to ~/.rr/config.yaml or remove it from .rr.yaml.", strings.Join(hostNames, ", "))).
+					WithID(errors.IDConfigHostNotFound)
+			}
+		}
+
+		// Validate the mirror host exists in global (if set)
+		if r.Project.MirrorTo != "" {
+			if _, ok := r.Global.Hosts[r.Project.MirrorTo]; !ok {
+				hostNames := getHostNames(r.Global.Hosts)
+				return errors.New(errors.ErrConfig,
+					fmt.Sprintf("Project mirrors to host '%s' which doesn't exist in global config", r.Project.MirrorTo),
+					fmt.Sprintf("Available hosts: %s. Add it to ~/.rr/config.yaml or change mirror_to in .rr.yaml.", strings.Join(hostNames, ", "))).
+					WithID(errors.IDConfigHostNotFound)
 			}
 		}
 	}
@@ -246,6 +375,65 @@ func validateHost(name string, host Host) error {
 		return err
 	}
 
+	for i, file := range host.ProfileFiles {
+		if strings.TrimSpace(file) == "" {
+			return fmt.Errorf("host '%s' has an empty profile_files entry at position %d", name, i+1)
+		}
+	}
+
+	for envName := range host.Env {
+		if !envNameRegex.MatchString(envName) {
+			return fmt.Errorf("host '%s' has an invalid env var name '%s' (use letters, digits and underscores)", name, envName)
+		}
+	}
+
+	if host.IdentityPassphrase != "" && host.IdentityFile == "" {
+		return fmt.Errorf("host '%s' has an identity_passphrase but no identity_file for it to unlock", name)
+	}
+
+	switch host.Fallback {
+	case "", FallbackOrder, FallbackRace:
+	default:
+		return fmt.Errorf("host '%s' has fallback '%s' - use order or race", name, host.Fallback)
+	}
+
+	switch host.Transport {
+	case "", TransportBuiltin:
+	case TransportSystemSSH:
+		if host.IdentityPassphrase != "" {
+			return fmt.Errorf("host '%s' has an identity_passphrase, which transport: system-ssh can't use (add the key to your SSH agent instead)", name)
+		}
+	default:
+		return fmt.Errorf("host '%s' has transport '%s' - use builtin or system-ssh", name, host.Transport)
+	}
+
+	return validateSSHOptions(name, host.SSHOptions)
+}
+
+// validateSSHOptions checks a host's ssh_options. Ciphers have to be ones
+// rr's SSH client speaks, or rr couldn't connect even though ssh can.
+// Durations under a second are almost always a bare number meant as seconds.
+func validateSSHOptions(name string, opts SSHOptions) error {
+	supported := ssh.SupportedAlgorithms().Ciphers
+	for _, cipher := range opts.Ciphers {
+		if !slices.Contains(supported, cipher) {
+			return fmt.Errorf("host '%s' ssh_options.ciphers has '%s', which rr doesn't support - use one of: %s",
+				name, cipher, strings.Join(supported, ", "))
+		}
+	}
+
+	durations := []struct {
+		field string
+		value time.Duration
+	}{
+		{"server_alive_interval", opts.ServerAliveInterval},
+		{"connect_timeout", opts.ConnectTimeout},
+	}
+	for _, d := range durations {
+		if d.value != 0 && d.value < time.Second {
+			return fmt.Errorf("host '%s' ssh_options.%s is %s - use a duration of at least 1s, like '30s'", name, d.field, d.value)
+		}
+	}
 	return nil
 }
 
@@ -290,17 +478,144 @@ func validateSteps(name string, steps []TaskStep) error {
 		if step.OnFail != "" && step.OnFail != "stop" && step.OnFail != "continue" {
 			return fmt.Errorf("task '%s' step %d has on_fail='%s' but it needs to be 'stop' or 'continue'", name, i+1, step.OnFail)
 		}
+		if !validVerbosity(step.Output) {
+			return fmt.Errorf("task '%s' step %d has output='%s' but it needs to be 'quiet', 'normal', or 'verbose'", name, i+1, step.Output)
+		}
+		if err := validateStepDir(step.Dir); err != nil {
+			return fmt.Errorf("task '%s' step %d has dir='%s' but %s", name, i+1, step.Dir, err)
+		}
+		if step.If != "" {
+			if _, err := ParseCondition(step.If); err != nil {
+				return fmt.Errorf("task '%s' step %d has if=\"%s\" but %s", name, i+1, step.If, err)
+			}
+		}
+		owner := fmt.Sprintf("task '%s' step %d", name, i+1)
+		if err := validatePull(owner, step.Pull); err != nil {
+			return err
+		}
+		if err := validatePull(owner, step.PullOnFail); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateStepDir checks that a step dir stays inside the project root.
+func validateStepDir(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	if strings.HasPrefix(dir, "/") || strings.HasPrefix(dir, "~") {
+		return fmt.Errorf("it needs to be relative to the project root")
+	}
+	cleaned := path.Clean(dir)
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return fmt.Errorf("it escapes the project root")
+	}
+	return nil
+}
+
+// validatePull checks pull items. owner describes where they are, for errors.
+func validatePull(owner string, items []PullItem) error {
+	for _, item := range items {
+		if item.MaxSize == "" {
+			continue
+		}
+		if _, err := ParseSize(item.MaxSize); err != nil {
+			return fmt.Errorf("%s pull '%s' max_size %s", owner, item.Src, err)
+		}
+	}
+	return nil
+}
+
+// validateProjectPaths checks that a task's push or outputs paths stay
+// inside the project.
+func validateProjectPaths(name, field string, paths []string) error {
+	for _, p := range paths {
+		clean := path.Clean(strings.TrimSpace(p))
+		switch {
+		case strings.TrimSpace(p) == "" || clean == ".":
+			return fmt.Errorf("task '%s' has an empty %s path - list the files or directories, like 'dist/'", name, field)
+		case path.IsAbs(clean):
+			return fmt.Errorf("task '%s' %s path '%s' is absolute - %s paths are relative to the project root", name, field, p, field)
+		case clean == ".." || strings.HasPrefix(clean, "../"):
+			return fmt.Errorf("task '%s' %s path '%s' is outside the project", name, field, p)
+		}
 	}
 	return nil
 }
 
 // validateTask checks a single task configuration.
 func validateTask(name string, task TaskConfig) error {
+	if err := validatePull(fmt.Sprintf("task '%s'", name), task.Pull); err != nil {
+		return err
+	}
+	if err := validateProjectPaths(name, "push", task.Push); err != nil {
+		return err
+	}
+	if err := validateProjectPaths(name, "outputs", task.Outputs); err != nil {
+		return err
+	}
+	if err := validateReserve(name, task.Reserve); err != nil {
+		return err
+	}
+	if err := validateWatch(name, task); err != nil {
+		return err
+	}
+	if err := validateIdle(fmt.Sprintf("task '%s'", name), task.IdleTimeout, task.IdleKill); err != nil {
+		return err
+	}
+	if err := validateWarm(name, task); err != nil {
+		return err
+	}
+	if err := validateOnConfigChange(name, task); err != nil {
+		return err
+	}
+	if err := validatePriority(name, task); err != nil {
+		return err
+	}
+	if err := validateTaskOutput(name, task.Output); err != nil {
+		return err
+	}
+	if err := validateShard(name, task); err != nil {
+		return err
+	}
+	if err := validateTaskShell(name, task); err != nil {
+		return err
+	}
+	if err := validateParams(name, task); err != nil {
+		return err
+	}
+	if err := validateSudo(name, task); err != nil {
+		return err
+	}
+
 	hasRun := task.Run != ""
 	hasSteps := len(task.Steps) > 0
 	hasParallel := len(task.Parallel) > 0
 	hasDepends := len(task.Depends) > 0
 
+	if task.Speculative {
+		switch {
+		case hasParallel:
+			return fmt.Errorf("task '%s' has both 'speculative' and 'parallel' - speculative tasks run a single command on two hosts", name)
+		case hasDepends:
+			return fmt.Errorf("task '%s' has both 'speculative' and 'depends' - speculative tasks can't have dependencies", name)
+		case len(task.Pull) > 0 || stepsPull(task.Steps):
+			return fmt.Errorf("task '%s' has both 'speculative' and 'pull' - speculative tasks can't pull files, since either host might win", name)
+		case task.Build != "" || len(task.Push) > 0:
+			return fmt.Errorf("task '%s' has both 'speculative' and 'build'/'push' - speculative tasks always sync the whole project", name)
+		case len(task.Inputs) > 0 || len(task.Outputs) > 0:
+			return fmt.Errorf("task '%s' has both 'speculative' and 'inputs'/'outputs' - either host might win, so there's no single place for files to come from or go to", name)
+		case !task.Reserve.IsZero():
+			return fmt.Errorf("task '%s' has both 'speculative' and 'reserve' - speculative tasks don't reserve capacity", name)
+		case task.IdleTimeout != "":
+			return fmt.Errorf("task '%s' has both 'speculative' and 'idle_timeout' - use 'timeout' to bound speculative tasks", name)
+		case task.OnCancel != "":
+			return fmt.Errorf("task '%s' has both 'speculative' and 'on_cancel' - the losing host is always canceled, so there's nothing to clean up after", name)
+		}
+	}
+
 	// Parallel tasks are mutually exclusive with run and steps
 	if hasParallel {
 		if hasRun {
@@ -312,6 +627,21 @@ func validateTask(name string, task TaskConfig) error {
 		if hasDepends {
 			return fmt.Errorf("task '%s' has both 'parallel' and 'depends' - parallel tasks can't have dependencies (use depends inside the subtasks instead)", name)
 		}
+		if task.Build != "" || len(task.Push) > 0 {
+			return fmt.Errorf("task '%s' has both 'parallel' and 'build'/'push' - parallel tasks sync the whole project once per host", name)
+		}
+		if len(task.Inputs) > 0 || len(task.Outputs) > 0 {
+			return fmt.Errorf("task '%s' has both 'parallel' and 'inputs'/'outputs' - use them on the subtasks instead", name)
+		}
+		if !task.Reserve.IsZero() {
+			return fmt.Errorf("task '%s' has both 'parallel' and 'reserve' - parallel tasks don't reserve capacity", name)
+		}
+		if task.IdleTimeout != "" {
+			return fmt.Errorf("task '%s' has both 'parallel' and 'idle_timeout' - use 'timeout' to bound parallel subtasks", name)
+		}
+		if task.OnCancel != "" {
+			return fmt.Errorf("task '%s' has both 'parallel' and 'on_cancel' - parallel tasks don't run cancel hooks", name)
+		}
 		// Parallel-specific validation is done separately after all tasks are known
 		return nil
 	}
@@ -348,6 +678,194 @@ func validateTask(name string, task TaskConfig) error {
 	return nil
 }
 
+// validateTaskShell checks a task's shell and raw settings, and that a raw
+// task's commands split into words.
+func validateTaskShell(name string, task TaskConfig) error {
+	if task.Shell == "" && !task.Raw {
+		return nil
+	}
+	if len(task.Parallel) > 0 {
+		return fmt.Errorf("task '%s' has both 'parallel' and 'shell'/'raw' - set them on the subtasks instead", name)
+	}
+	if task.Shell != "" {
+		parts := strings.Fields(task.Shell)
+		if len(parts) == 0 || !strings.HasPrefix(parts[len(parts)-1], "-") {
+			return fmt.Errorf("task '%s' shell should end with a flag like '-c'. Got '%s' - try 'bash -l -c' or 'zsh -i -c'", name, task.Shell)
+		}
+		if task.Raw {
+			return fmt.Errorf("task '%s' has both 'shell' and 'raw' - a raw command's words aren't interpreted by any shell, so pick one", name)
+		}
+	}
+	if task.Raw {
+		if _, err := util.SplitWords(task.Run); err != nil {
+			return fmt.Errorf("task '%s' has raw: true but its run command can't be split into arguments: %v", name, err)
+		}
+		for i, step := range task.Steps {
+			if _, err := util.SplitWords(step.Run); err != nil {
+				return fmt.Errorf("task '%s' has raw: true but step %d can't be split into arguments: %v", name, i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateShard checks a task's shard settings, and the fields a sharded
+// task can't have: shards run like the subtasks of a parallel task.
+func validateShard(name string, task TaskConfig) error {
+	shard := task.Shard
+	if shard.Count == 0 {
+		if shard.Strategy != "" {
+			return fmt.Errorf("task '%s' has shard.strategy but no shard.count", name)
+		}
+		return nil
+	}
+	if shard.Count < 2 || shard.Count > MaxShards {
+		return fmt.Errorf("task '%s' has shard.count=%d, but it has to be between 2 and %d", name, shard.Count, MaxShards)
+	}
+	switch shard.Strategy {
+	case "", ShardRoundRobin, ShardDuration:
+	default:
+		return fmt.Errorf("task '%s' has shard.strategy='%s' but it needs to be '%s' or '%s'", name, shard.Strategy, ShardRoundRobin, ShardDuration)
+	}
+
+	switch {
+	case len(task.Parallel) > 0:
+		return fmt.Errorf("task '%s' has both 'shard' and 'parallel' - shard the subtasks instead", name)
+	case len(task.Depends) > 0:
+		return fmt.Errorf("task '%s' has both 'shard' and 'depends' - run the dependencies from a task that depends on this one", name)
+	case task.Speculative:
+		return fmt.Errorf("task '%s' has both 'shard' and 'speculative' - shards already spread across hosts", name)
+	case task.Type == TaskTypeWatch:
+		return fmt.Errorf("task '%s' has both 'shard' and 'type: watch' - a watch task runs on one host", name)
+	case len(task.Pull) > 0 || stepsPull(task.Steps):
+		return fmt.Errorf("task '%s' has both 'shard' and 'pull' - shards run like parallel subtasks, which don't pull files", name)
+	case task.Build != "" || len(task.Push) > 0:
+		return fmt.Errorf("task '%s' has both 'shard' and 'build'/'push' - sharded tasks sync the whole project once per host", name)
+	case len(task.Inputs) > 0 || len(task.Outputs) > 0:
+		return fmt.Errorf("task '%s' has both 'shard' and 'inputs'/'outputs' - shards run on different hosts, so there's no single place for files to go", name)
+	case !task.Reserve.IsZero():
+		return fmt.Errorf("task '%s' has both 'shard' and 'reserve' - sharded tasks don't reserve capacity", name)
+	case task.IdleTimeout != "":
+		return fmt.Errorf("task '%s' has both 'shard' and 'idle_timeout' - use 'timeout' to bound each shard", name)
+	case task.OnCancel != "":
+		return fmt.Errorf("task '%s' has both 'shard' and 'on_cancel' - sharded tasks don't run cancel hooks", name)
+	}
+	return nil
+}
+
+// validatePriority checks a task's priority.
+func validatePriority(name string, task TaskConfig) error {
+	switch task.Priority {
+	case "", PriorityLow, PriorityNormal, PriorityHigh:
+		return nil
+	}
+	return fmt.Errorf("task '%s' has priority='%s' but it needs to be 'low', 'normal', or 'high'", name, task.Priority)
+}
+
+// validateOnConfigChange checks a task's on_config_change.
+func validateOnConfigChange(name string, task TaskConfig) error {
+	switch task.OnConfigChange {
+	case "":
+		return nil
+	case OnConfigChangeWarn, OnConfigChangeApply:
+	default:
+		return fmt.Errorf("task '%s' has on_config_change='%s' but it needs to be 'warn' or 'apply'", name, task.OnConfigChange)
+	}
+	if len(task.Parallel) == 0 && task.Shard.Count == 0 {
+		return fmt.Errorf("task '%s' has 'on_config_change' but isn't a parallel task - only parallel tasks watch the config while they run", name)
+	}
+	return nil
+}
+
+// validateWarm checks a task's warm window.
+func validateWarm(name string, task TaskConfig) error {
+	if task.Warm == "" {
+		return nil
+	}
+	if len(task.Parallel) == 0 && task.Shard.Count == 0 {
+		return fmt.Errorf("task '%s' has 'warm' but isn't a parallel task - warm keeps a parallel task's hosts synced and set up between runs", name)
+	}
+	if d, err := time.ParseDuration(task.Warm); err != nil || d <= 0 {
+		return fmt.Errorf("task '%s' has warm='%s' - use a positive duration like '15m'", name, task.Warm)
+	}
+	return nil
+}
+
+// validateWatch checks a task's type, and the fields only watch tasks take.
+func validateWatch(name string, task TaskConfig) error {
+	switch task.Type {
+	case "":
+		if len(task.Ports) > 0 || task.Restart != "" {
+			return fmt.Errorf("task '%s' has 'ports' or 'restart' but isn't a watch task - add 'type: watch'", name)
+		}
+		return nil
+	case TaskTypeWatch:
+	default:
+		return fmt.Errorf("task '%s' has type='%s' but the only task type is 'watch'", name, task.Type)
+	}
+
+	switch {
+	case len(task.Steps) > 0 || len(task.Parallel) > 0 || len(task.Depends) > 0:
+		return fmt.Errorf("task '%s' is a watch task with 'steps', 'parallel', or 'depends' - watch tasks run one long-lived command", name)
+	case task.Run == "":
+		return fmt.Errorf("task '%s' is a watch task without a 'run' command - watch tasks run one long-lived command", name)
+	case task.Speculative:
+		return fmt.Errorf("task '%s' has both 'type: watch' and 'speculative' - a watch task runs on one host", name)
+	case len(task.Pull) > 0 || len(task.Inputs) > 0 || len(task.Outputs) > 0:
+		return fmt.Errorf("task '%s' is a watch task with 'pull', 'inputs', or 'outputs' - a watch task never finishes, so there's nothing to collect", name)
+	case task.IdleTimeout != "":
+		return fmt.Errorf("task '%s' has both 'type: watch' and 'idle_timeout' - watch tasks are expected to go quiet", name)
+	case task.OnCancel != "":
+		return fmt.Errorf("task '%s' has both 'type: watch' and 'on_cancel' - Ctrl+C is how a watch task normally stops", name)
+	}
+	switch task.Restart {
+	case "", RestartOnFailure, RestartAlways, RestartNever:
+	default:
+		return fmt.Errorf("task '%s' has restart='%s' but it needs to be 'on-failure', 'always', or 'never'", name, task.Restart)
+	}
+	locals := make(map[int]string)
+	for _, spec := range task.Ports {
+		local, _, err := ParsePortForward(spec)
+		if err != nil {
+			return fmt.Errorf("task '%s' port '%s': %v", name, spec, err)
+		}
+		if other, ok := locals[local]; ok {
+			return fmt.Errorf("task '%s' forwards local port %d twice ('%s' and '%s')", name, local, other, spec)
+		}
+		locals[local] = spec
+	}
+	return nil
+}
+
+// validateIdle checks an idle_timeout and idle_kill pair. owner describes
+// where they are, for errors.
+func validateIdle(owner, timeout string, kill bool) error {
+	if timeout == "" {
+		if kill {
+			return fmt.Errorf("%s has 'idle_kill' without 'idle_timeout' - set how long to wait for output", owner)
+		}
+		return nil
+	}
+	d, err := time.ParseDuration(timeout)
+	if err != nil || d <= 0 {
+		return fmt.Errorf("%s has idle_timeout='%s' - use a positive duration like '10m'", owner, timeout)
+	}
+	return nil
+}
+
+// validateReserve checks a task's reserve block.
+func validateReserve(name string, r ReserveConfig) error {
+	if r.CPUs < 0 {
+		return fmt.Errorf("task '%s' reserves %g CPUs - use a positive number", name, r.CPUs)
+	}
+	if r.Memory != "" {
+		if _, err := ParseSize(r.Memory); err != nil {
+			return fmt.Errorf("task '%s' reserve.memory: %v", name, err)
+		}
+	}
+	return nil
+}
+
 // validateOutput checks output configuration.
 func validateOutput(out OutputConfig) error {
 	validColors := map[string]bool{"auto": true, "always": true, "never": true, "": true}
@@ -355,23 +873,68 @@ func validateOutput(out OutputConfig) error {
 		return fmt.Errorf("output.color '%s' isn't valid - use 'auto', 'always', or 'never'", out.Color)
 	}
 
-	validFormats := map[string]bool{
-		"auto": true, "generic": true, "pytest": true,
-		"jest": true, "go": true, "cargo": true, "": true,
-	}
-	if !validFormats[out.Format] {
+	if !validOutputFormats[out.Format] {
 		return fmt.Errorf("output.format '%s' isn't valid - try: auto, generic, pytest, jest, go, or cargo", out.Format)
 	}
 
-	validVerbosity := map[string]bool{"quiet": true, "normal": true, "verbose": true, "": true}
-	if !validVerbosity[out.Verbosity] {
+	if !validVerbosity(out.Verbosity) {
 		return fmt.Errorf("output.verbosity '%s' isn't valid - use 'quiet', 'normal', or 'verbose'", out.Verbosity)
 	}
 
 	return nil
 }
 
+// validOutputFormats are the formats output.format and a task's
+// output.format accept.
+var validOutputFormats = map[string]bool{
+	"auto": true, "generic": true, "pytest": true,
+	"jest": true, "go": true, "cargo": true, "": true,
+}
+
+// validVerbosity reports whether v is a verbosity level, or unset.
+func validVerbosity(v string) bool {
+	switch v {
+	case "", VerbosityQuiet, VerbosityNormal, VerbosityVerbose:
+		return true
+	}
+	return false
+}
+
+// validateTaskOutput checks a task's output overrides.
+func validateTaskOutput(name string, out TaskOutput) error {
+	switch out.Mode {
+	case "", "progress", "stream", "verbose", "quiet":
+	default:
+		return fmt.Errorf("task '%s' has output mode '%s' - use 'progress', 'stream', 'verbose', or 'quiet'", name, out.Mode)
+	}
+	if !validOutputFormats[out.Format] {
+		return fmt.Errorf("task '%s' has output.format='%s' - try: auto, generic, pytest, jest, go, or cargo", name, out.Format)
+	}
+	if !validVerbosity(out.Verbosity) {
+		return fmt.Errorf("task '%s' has output.verbosity='%s' - use 'quiet', 'normal', or 'verbose'", name, out.Verbosity)
+	}
+	return nil
+}
+
 // validateLock checks lock configuration.
+func validateSync(sync SyncConfig) error {
+	if sync.Parallel < 0 || sync.Parallel > MaxSyncParallel {
+		return fmt.Errorf("sync.parallel is %d, but it has to be between 0 and %d", sync.Parallel, MaxSyncParallel)
+	}
+	return nil
+}
+
+// validateSyncProfileRefs checks every task's sync_profile is one of
+// sync.profiles.
+func validateSyncProfileRefs(cfg *Config) error {
+	for _, name := range slices.Sorted(maps.Keys(cfg.Tasks)) {
+		if _, err := cfg.Sync.WithProfile(cfg.Tasks[name].SyncProfile); err != nil {
+			return fmt.Errorf("task '%s': %w", name, err)
+		}
+	}
+	return nil
+}
+
 func validateLock(lock LockConfig) error {
 	if lock.Timeout < 0 {
 		return fmt.Errorf("lock.timeout can't be negative - that doesn't make sense")
@@ -379,6 +942,11 @@ func validateLock(lock LockConfig) error {
 	if lock.Stale < 0 {
 		return fmt.Errorf("lock.stale can't be negative - that doesn't make sense")
 	}
+	switch lock.Scope {
+	case "", LockScopeHost, LockScopeProject, LockScopeCommand:
+	default:
+		return fmt.Errorf("lock.scope '%s' isn't valid - use host, project, or command", lock.Scope)
+	}
 	return nil
 }
 
@@ -403,6 +971,10 @@ func validateMonitorConfig(monitor MonitorConfig) error {
 		return err
 	}
 
+	if monitor.Concurrency < 0 {
+		return fmt.Errorf("monitor.concurrency can't be negative (got %d) - leave it out for the default of 8", monitor.Concurrency)
+	}
+
 	// Validate exclude entries aren't empty (can't validate against hosts here)
 	for _, excluded := range monitor.Exclude {
 		if strings.TrimSpace(excluded) == "" {
@@ -410,6 +982,17 @@ func validateMonitorConfig(monitor MonitorConfig) error {
 		}
 	}
 
+	seen := make(map[string]bool)
+	for _, section := range monitor.Cards {
+		if !slices.Contains(MonitorCardSections, section) {
+			return fmt.Errorf("monitor.cards has '%s', which isn't a card section - use %s", section, strings.Join(MonitorCardSections, ", "))
+		}
+		if seen[section] {
+			return fmt.Errorf("monitor.cards lists '%s' twice", section)
+		}
+		seen[section] = true
+	}
+
 	return nil
 }
 
@@ -476,7 +1059,7 @@ func ValidateParallelTasks(cfg *Config) error {
 
 		// Check each referenced task exists
 		for _, ref := range task.Parallel {
-			_, ok := cfg.Tasks[ref]
+			refTask, ok := cfg.Tasks[ref]
 			if !ok {
 				available := getTaskNames(cfg.Tasks)
 				return fmt.Errorf("parallel task '%s' references non-existent task '%s'. Available tasks: %s",
@@ -487,6 +1070,10 @@ func ValidateParallelTasks(cfg *Config) error {
 			if ref == name {
 				return fmt.Errorf("parallel task '%s' can't reference itself", name)
 			}
+
+			if IsWatchTask(&refTask) {
+				return fmt.Errorf("parallel task '%s' includes watch task '%s', which never finishes - run it on its own with 'rr %s'", name, ref, ref)
+			}
 		}
 
 		// Check for cycles in parallel task references
@@ -651,6 +1238,9 @@ func ValidateDependencyGraph(cfg *Config) error {
 		if err := validateDependencies(name, task, cfg.Tasks); err != nil {
 			return err
 		}
+		if err := validateInputs(name, task, cfg.Tasks); err != nil {
+			return err
+		}
 	}
 
 	// Second pass: detect circular dependencies using DFS
@@ -696,6 +1286,11 @@ func ValidateDependencyGraph(cfg *Config) error {
 				}
 			}
 		}
+		for _, input := range task.Inputs {
+			if err := detectCycle(input); err != nil {
+				return err
+			}
+		}
 
 		inStack[taskName] = false
 		path = path[:len(path)-1]
@@ -729,11 +1324,34 @@ func validateDependencies(taskName string, task TaskConfig, allTasks map[string]
 			}
 
 			// Check dependency exists
-			if _, ok := allTasks[depName]; !ok {
+			depTask, ok := allTasks[depName]
+			if !ok {
 				available := getTaskNames(allTasks)
 				return fmt.Errorf("task '%s' depends on non-existent task '%s'. Available tasks: %s",
 					taskName, depName, strings.Join(available, ", "))
 			}
+			if IsWatchTask(&depTask) {
+				return fmt.Errorf("task '%s' depends on watch task '%s', which never finishes - run it on its own with 'rr %s'", taskName, depName, depName)
+			}
+		}
+	}
+	return nil
+}
+
+// validateInputs checks that the tasks a task takes inputs from exist and
+// declare outputs to pass along.
+func validateInputs(taskName string, task TaskConfig, allTasks map[string]TaskConfig) error {
+	for _, input := range task.Inputs {
+		if input == taskName {
+			return fmt.Errorf("task '%s' can't take inputs from itself", taskName)
+		}
+		producer, ok := allTasks[input]
+		if !ok {
+			return fmt.Errorf("task '%s' takes inputs from non-existent task '%s'. Available tasks: %s",
+				taskName, input, strings.Join(getTaskNames(allTasks), ", "))
+		}
+		if len(producer.Outputs) == 0 {
+			return fmt.Errorf("task '%s' takes inputs from '%s', which has no outputs - list what it produces under its 'outputs'", taskName, input)
 		}
 	}
 	return nil
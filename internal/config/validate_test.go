@@ -2,6 +2,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -151,6 +152,20 @@ func TestValidateParallelTasks(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "parallel task can't include a watch task",
+			config: &Config{
+				Tasks: map[string]TaskConfig{
+					"dev":  {Type: TaskTypeWatch, Run: "npm run dev"},
+					"test": {Run: "go test ./..."},
+					"all": {
+						Parallel: []string{"dev", "test"},
+					},
+				},
+			},
+			wantErr:     true,
+			errContains: "includes watch task 'dev'",
+		},
 	}
 
 	for _, tt := range tests {
@@ -581,6 +596,560 @@ func TestValidateTask_Depends(t *testing.T) {
 	}
 }
 
+func TestValidateSteps_Dir(t *testing.T) {
+	tests := []struct {
+		name        string
+		dir         string
+		errContains string
+	}{
+		{"empty", "", ""},
+		{"subdirectory", "packages/api", ""},
+		{"dot", ".", ""},
+		{"inner traversal stays inside", "packages/../web", ""},
+		{"absolute", "/opt/app", "relative to the project root"},
+		{"home", "~/app", "relative to the project root"},
+		{"parent", "..", "escapes the project root"},
+		{"escapes via traversal", "packages/../../other", "escapes the project root"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSteps("build", []TaskStep{{Run: "make", Dir: tt.dir}})
+			if tt.errContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "task 'build' step 1")
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
+func TestValidateSteps_If(t *testing.T) {
+	assert.NoError(t, validateSteps("lint", []TaskStep{{Run: "ruff .", If: "files_changed('**/*.py')"}}))
+
+	err := validateSteps("lint", []TaskStep{{Run: "ruff .", If: "files_changed(**/*.py)"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "task 'lint' step 1 has if=")
+	assert.Contains(t, err.Error(), "quote strings and patterns")
+}
+
+func TestValidateTask_PullMaxSize(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxSize     string
+		errContains string
+	}{
+		{"unset", "", ""},
+		{"megabytes", "500MB", ""},
+		{"bytes", "4096", ""},
+		{"unknown unit", "10 parsecs", "unknown unit"},
+		{"not a number", "lots", "isn't a valid size"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTask("build", TaskConfig{
+				Run:  "make",
+				Pull: []PullItem{{Src: "dist/", MaxSize: tt.maxSize}},
+			})
+			if tt.errContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "task 'build' pull 'dist/' max_size")
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
+func TestValidateTask_StepPullMaxSize(t *testing.T) {
+	err := validateTask("e2e", TaskConfig{
+		Steps: []TaskStep{
+			{Run: "make build", Pull: []PullItem{{Src: "dist/", MaxSize: "1GB"}}},
+			{Run: "make e2e", PullOnFail: []PullItem{{Src: "screenshots/", MaxSize: "lots"}}},
+		},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "task 'e2e' step 2 pull 'screenshots/' max_size")
+}
+
+func TestValidateTask_Speculative(t *testing.T) {
+	tests := []struct {
+		name        string
+		task        TaskConfig
+		errContains string
+	}{
+		{"run", TaskConfig{Speculative: true, Run: "pytest -x"}, ""},
+		{"steps", TaskConfig{Speculative: true, Steps: []TaskStep{{Run: "make"}}}, ""},
+		{"parallel", TaskConfig{Speculative: true, Parallel: []string{"a", "b"}}, "'speculative' and 'parallel'"},
+		{"depends", TaskConfig{Speculative: true, Run: "make", Depends: []DependencyItem{{Task: "lint"}}}, "'speculative' and 'depends'"},
+		{"pull", TaskConfig{Speculative: true, Run: "make", Pull: []PullItem{{Src: "dist/"}}}, "'speculative' and 'pull'"},
+		{"step pull", TaskConfig{Speculative: true, Steps: []TaskStep{{Run: "make", PullOnFail: []PullItem{{Src: "logs/"}}}}}, "'speculative' and 'pull'"},
+		{"push", TaskConfig{Speculative: true, Run: "make", Push: []string{"dist/"}}, "'speculative' and 'build'/'push'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTask("test", tt.task)
+			if tt.errContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
+func TestValidateTask_Shard(t *testing.T) {
+	tests := []struct {
+		name        string
+		task        TaskConfig
+		errContains string
+	}{
+		{"count", TaskConfig{Run: "pytest", Shard: ShardConfig{Count: 8}}, ""},
+		{"duration", TaskConfig{Run: "pytest", Shard: ShardConfig{Count: 4, Strategy: ShardDuration}}, ""},
+		{"steps", TaskConfig{Steps: []TaskStep{{Run: "make"}}, Shard: ShardConfig{Count: 2}}, ""},
+		{"one shard", TaskConfig{Run: "pytest", Shard: ShardConfig{Count: 1}}, "between 2 and 256"},
+		{"too many", TaskConfig{Run: "pytest", Shard: ShardConfig{Count: MaxShards + 1}}, "between 2 and 256"},
+		{"strategy only", TaskConfig{Run: "pytest", Shard: ShardConfig{Strategy: ShardDuration}}, "no shard.count"},
+		{"bad strategy", TaskConfig{Run: "pytest", Shard: ShardConfig{Count: 2, Strategy: "random"}}, "shard.strategy='random'"},
+		{"parallel", TaskConfig{Parallel: []string{"a", "b"}, Shard: ShardConfig{Count: 2}}, "'shard' and 'parallel'"},
+		{"speculative", TaskConfig{Run: "pytest", Speculative: true, Shard: ShardConfig{Count: 2}}, "'shard' and 'speculative'"},
+		{"pull", TaskConfig{Run: "pytest", Pull: []PullItem{{Src: "junit.xml"}}, Shard: ShardConfig{Count: 2}}, "'shard' and 'pull'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTask("test", tt.task)
+			if tt.errContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
+func TestValidateTask_Shell(t *testing.T) {
+	tests := []struct {
+		name        string
+		task        TaskConfig
+		errContains string
+	}{
+		{"shell", TaskConfig{Run: "make", Shell: "bash -l -c"}, ""},
+		{"raw", TaskConfig{Run: `grep -r "TODO: fix" src`, Raw: true}, ""},
+		{"raw steps", TaskConfig{Steps: []TaskStep{{Run: "make"}, {Run: "make test"}}, Raw: true}, ""},
+		{"no flag", TaskConfig{Run: "make", Shell: "bash"}, "should end with a flag"},
+		{"both", TaskConfig{Run: "make", Shell: "bash -c", Raw: true}, "'shell' and 'raw'"},
+		{"parallel", TaskConfig{Parallel: []string{"a", "b"}, Raw: true}, "'parallel' and 'shell'/'raw'"},
+		{"unterminated", TaskConfig{Run: "echo 'oops", Raw: true}, "unterminated"},
+		{"unterminated step", TaskConfig{Steps: []TaskStep{{Run: "make"}, {Run: `echo "oops`}}, Raw: true}, "step 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTask("test", tt.task)
+			if tt.errContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
+func TestValidateTask_Reserve(t *testing.T) {
+	tests := []struct {
+		name        string
+		task        TaskConfig
+		errContains string
+	}{
+		{"cpus and memory", TaskConfig{Run: "make bench", Reserve: ReserveConfig{CPUs: 8, Memory: "16GB"}}, ""},
+		{"fractional cpus", TaskConfig{Run: "make", Reserve: ReserveConfig{CPUs: 0.5}}, ""},
+		{"negative cpus", TaskConfig{Run: "make", Reserve: ReserveConfig{CPUs: -1}}, "reserves -1 CPUs"},
+		{"bad memory", TaskConfig{Run: "make", Reserve: ReserveConfig{Memory: "lots"}}, "reserve.memory"},
+		{"parallel", TaskConfig{Parallel: []string{"a", "b"}, Reserve: ReserveConfig{CPUs: 2}}, "'parallel' and 'reserve'"},
+		{"speculative", TaskConfig{Run: "make", Speculative: true, Reserve: ReserveConfig{CPUs: 2}}, "'speculative' and 'reserve'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTask("bench", tt.task)
+			if tt.errContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
+func TestValidateTask_Watch(t *testing.T) {
+	watch := func(task TaskConfig) TaskConfig {
+		task.Type = TaskTypeWatch
+		if task.Run == "" && len(task.Steps) == 0 {
+			task.Run = "npm run dev"
+		}
+		return task
+	}
+	tests := []struct {
+		name        string
+		task        TaskConfig
+		errContains string
+	}{
+		{"plain", watch(TaskConfig{}), ""},
+		{"ports and restart", watch(TaskConfig{Ports: []string{"3000", "9230:9229"}, Restart: RestartAlways}), ""},
+		{"unknown type", TaskConfig{Type: "daemon", Run: "x"}, "the only task type is 'watch'"},
+		{"ports without type", TaskConfig{Run: "x", Ports: []string{"3000"}}, "add 'type: watch'"},
+		{"restart without type", TaskConfig{Run: "x", Restart: RestartNever}, "add 'type: watch'"},
+		{"no run", TaskConfig{Type: TaskTypeWatch}, "without a 'run' command"},
+		{"steps", watch(TaskConfig{Steps: []TaskStep{{Name: "a", Run: "a"}}}), "'steps', 'parallel', or 'depends'"},
+		{"speculative", watch(TaskConfig{Speculative: true}), "'type: watch' and 'speculative'"},
+		{"pull", watch(TaskConfig{Pull: []PullItem{{Src: "out/"}}}), "'pull', 'inputs', or 'outputs'"},
+		{"bad restart", watch(TaskConfig{Restart: "sometimes"}), "restart='sometimes'"},
+		{"bad port", watch(TaskConfig{Ports: []string{"http"}}), "port 'http'"},
+		{"port out of range", watch(TaskConfig{Ports: []string{"70000"}}), "port '70000'"},
+		{"same local port twice", watch(TaskConfig{Ports: []string{"3000", "3000:4000"}}), "forwards local port 3000 twice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTask("dev", tt.task)
+			if tt.errContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
+func TestValidateTask_IdleTimeout(t *testing.T) {
+	tests := []struct {
+		name        string
+		task        TaskConfig
+		errContains string
+	}{
+		{"warn", TaskConfig{Run: "make e2e", IdleTimeout: "10m"}, ""},
+		{"kill", TaskConfig{Run: "make e2e", IdleTimeout: "90s", IdleKill: true}, ""},
+		{"steps", TaskConfig{Steps: []TaskStep{{Name: "a", Run: "a"}}, IdleTimeout: "10m"}, ""},
+		{"bad duration", TaskConfig{Run: "make", IdleTimeout: "ten minutes"}, "idle_timeout='ten minutes'"},
+		{"zero", TaskConfig{Run: "make", IdleTimeout: "0s"}, "positive duration"},
+		{"kill without timeout", TaskConfig{Run: "make", IdleKill: true}, "'idle_kill' without 'idle_timeout'"},
+		{"parallel", TaskConfig{Parallel: []string{"a", "b"}, IdleTimeout: "10m"}, "'parallel' and 'idle_timeout'"},
+		{"speculative", TaskConfig{Run: "make", Speculative: true, IdleTimeout: "10m"}, "'speculative' and 'idle_timeout'"},
+		{"watch", TaskConfig{Type: TaskTypeWatch, Run: "npm run dev", IdleTimeout: "10m"}, "'type: watch' and 'idle_timeout'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTask("e2e", tt.task)
+			if tt.errContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
+func TestValidateTask_OnCancel(t *testing.T) {
+	tests := []struct {
+		name        string
+		task        TaskConfig
+		errContains string
+	}{
+		{"run", TaskConfig{Run: "make e2e", OnCancel: "docker compose down"}, ""},
+		{"steps", TaskConfig{Steps: []TaskStep{{Name: "a", Run: "a"}}, OnCancel: "docker compose down"}, ""},
+		{"depends", TaskConfig{Depends: []DependencyItem{{Task: "build"}}, Run: "make e2e", OnCancel: "docker compose down"}, ""},
+		{"parallel", TaskConfig{Parallel: []string{"a", "b"}, OnCancel: "docker compose down"}, "'parallel' and 'on_cancel'"},
+		{"speculative", TaskConfig{Run: "make", Speculative: true, OnCancel: "docker compose down"}, "'speculative' and 'on_cancel'"},
+		{"watch", TaskConfig{Type: TaskTypeWatch, Run: "npm run dev", OnCancel: "docker compose down"}, "'type: watch' and 'on_cancel'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTask("e2e", tt.task)
+			if tt.errContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
+func TestValidateTask_Output(t *testing.T) {
+	tests := []struct {
+		name        string
+		task        TaskConfig
+		errContains string
+	}{
+		{"mode", TaskConfig{Parallel: []string{"a", "b"}, Output: TaskOutput{Mode: "stream"}}, ""},
+		{"format and verbosity", TaskConfig{Run: "go test ./...", Output: TaskOutput{Format: "go", Verbosity: "quiet"}}, ""},
+		{"step output", TaskConfig{Steps: []TaskStep{{Run: "make", Output: "quiet"}}}, ""},
+		{"bad mode", TaskConfig{Run: "make", Output: TaskOutput{Mode: "loud"}}, "output mode 'loud'"},
+		{"bad format", TaskConfig{Run: "make", Output: TaskOutput{Format: "rspec"}}, "output.format='rspec'"},
+		{"bad verbosity", TaskConfig{Run: "make", Output: TaskOutput{Verbosity: "silent"}}, "output.verbosity='silent'"},
+		{"bad step output", TaskConfig{Steps: []TaskStep{{Run: "make", Output: "silent"}}}, "step 1 has output='silent'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTask("build", tt.task)
+			if tt.errContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
+func TestValidateTask_OnConfigChange(t *testing.T) {
+	tests := []struct {
+		name        string
+		task        TaskConfig
+		errContains string
+	}{
+		{"apply", TaskConfig{Parallel: []string{"a", "b"}, OnConfigChange: "apply"}, ""},
+		{"warn", TaskConfig{Parallel: []string{"a", "b"}, OnConfigChange: "warn"}, ""},
+		{"not parallel", TaskConfig{Run: "make test", OnConfigChange: "apply"}, "'on_config_change' but isn't a parallel task"},
+		{"bad value", TaskConfig{Parallel: []string{"a", "b"}, OnConfigChange: "reload"}, "on_config_change='reload'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTask("ci", tt.task)
+			if tt.errContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
+func TestValidateTask_Priority(t *testing.T) {
+	for _, p := range []string{"", PriorityLow, PriorityNormal, PriorityHigh} {
+		assert.NoError(t, validateTask("test", TaskConfig{Run: "make test", Priority: p}), p)
+	}
+
+	err := validateTask("test", TaskConfig{Run: "make test", Priority: "idle"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "priority='idle'")
+}
+
+func TestLoad_TaskPriorityInherited(t *testing.T) {
+	cfg, err := loadProject(t, `
+version: 1
+tasks:
+  test:
+    run: make test
+    priority: low
+  test-verbose:
+    extends: test
+    run: make test V=1
+  test-now:
+    extends: test
+    priority: high
+`)
+	require.NoError(t, err)
+	assert.Equal(t, PriorityLow, cfg.Tasks["test"].Priority)
+	assert.Equal(t, PriorityLow, cfg.Tasks["test-verbose"].Priority)
+	assert.Equal(t, PriorityHigh, cfg.Tasks["test-now"].Priority)
+}
+
+func TestValidateTask_Warm(t *testing.T) {
+	tests := []struct {
+		name        string
+		task        TaskConfig
+		errContains string
+	}{
+		{"parallel", TaskConfig{Parallel: []string{"a", "b"}, Warm: "15m"}, ""},
+		{"not parallel", TaskConfig{Run: "make test", Warm: "15m"}, "'warm' but isn't a parallel task"},
+		{"sharded", TaskConfig{Run: "make test", Warm: "15m", Shard: ShardConfig{Count: 2}}, ""},
+		{"bad duration", TaskConfig{Parallel: []string{"a"}, Warm: "a while"}, "warm='a while'"},
+		{"zero", TaskConfig{Parallel: []string{"a"}, Warm: "0s"}, "positive duration"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTask("test-all", tt.task)
+			if tt.errContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
+func TestValidate_DefaultsIdleTimeout(t *testing.T) {
+	assert.NoError(t, Validate(&Config{Version: 1, Defaults: ProjectDefaults{IdleTimeout: "15m", IdleKill: true}}))
+
+	err := Validate(&Config{Version: 1, Defaults: ProjectDefaults{IdleKill: true}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "defaults has 'idle_kill' without 'idle_timeout'")
+}
+
+func TestValidate_SyncProfileRefs(t *testing.T) {
+	cfg := &Config{
+		Version: 1,
+		Sync:    SyncConfig{Profiles: map[string]SyncProfile{"data": {Exclude: []string{".git/"}}}},
+		Tasks: map[string]TaskConfig{
+			"train": {Run: "python train.py", SyncProfile: "data"},
+			"test":  {Run: "make test"},
+		},
+	}
+	assert.NoError(t, Validate(cfg))
+
+	cfg.Tasks["lint"] = TaskConfig{Run: "make lint", SyncProfile: "code"}
+	err := Validate(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "task 'lint': sync profile 'code' isn't defined")
+}
+
+func TestValidateCache(t *testing.T) {
+	tests := []struct {
+		name        string
+		cache       CacheConfig
+		errContains string
+	}{
+		{"empty uses defaults", CacheConfig{}, ""},
+		{"home relative", CacheConfig{Dir: "~/.cache/rr", TTL: "48h", MaxSize: "5GB"}, ""},
+		{"HOME variable", CacheConfig{Dir: "${HOME}/rr-cache"}, ""},
+		{"relative dir", CacheConfig{Dir: "cache"}, "has to be an absolute path"},
+		{"home dir", CacheConfig{Dir: "~/"}, "can't be the home or root directory"},
+		{"root dir", CacheConfig{Dir: "/"}, "can't be the home or root directory"},
+		{"parent dir", CacheConfig{Dir: "~/cache/../.."}, "can't contain '..'"},
+		{"bad ttl", CacheConfig{TTL: "7d"}, "doesn't look like a valid duration"},
+		{"negative ttl", CacheConfig{TTL: "-1h"}, "positive duration"},
+		{"bad max_size", CacheConfig{MaxSize: "lots"}, "cache.max_size"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCache(tt.cache)
+			if tt.errContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
+func TestReserveConfig_MemoryBytes(t *testing.T) {
+	assert.Equal(t, int64(16<<30), ReserveConfig{Memory: "16GB"}.MemoryBytes())
+	assert.Zero(t, ReserveConfig{}.MemoryBytes())
+	assert.True(t, ReserveConfig{}.IsZero())
+	assert.False(t, ReserveConfig{CPUs: 1}.IsZero())
+}
+
+func TestValidateTask_BuildAndPush(t *testing.T) {
+	tests := []struct {
+		name        string
+		task        TaskConfig
+		errContains string
+	}{
+		{"build and push", TaskConfig{Run: "./bin/server", Build: "make", Push: []string{"bin/", "./config.yaml"}}, ""},
+		{"push only", TaskConfig{Run: "./dist/app", Push: []string{"dist"}}, ""},
+		{"empty path", TaskConfig{Run: "make", Push: []string{" "}}, "empty push path"},
+		{"project root", TaskConfig{Run: "make", Push: []string{"./"}}, "empty push path"},
+		{"absolute", TaskConfig{Run: "make", Push: []string{"/tmp/dist"}}, "is absolute"},
+		{"outside project", TaskConfig{Run: "make", Push: []string{"dist/../../x"}}, "outside the project"},
+		{"parallel", TaskConfig{Parallel: []string{"a", "b"}, Build: "make"}, "'parallel' and 'build'/'push'"},
+		{"outputs", TaskConfig{Run: "make", Outputs: []string{"dist/", "build/app"}}, ""},
+		{"absolute outputs", TaskConfig{Run: "make", Outputs: []string{"/tmp/dist"}}, "outputs path '/tmp/dist' is absolute"},
+		{"parallel inputs", TaskConfig{Parallel: []string{"a", "b"}, Inputs: []string{"build"}}, "'parallel' and 'inputs'/'outputs'"},
+		{"speculative outputs", TaskConfig{Run: "make", Speculative: true, Outputs: []string{"dist/"}}, "'speculative' and 'inputs'/'outputs'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTask("deploy", tt.task)
+			if tt.errContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
+func TestValidateDependencyGraph_Inputs(t *testing.T) {
+	tests := []struct {
+		name        string
+		tasks       map[string]TaskConfig
+		errContains string
+	}{
+		{
+			name: "producer and consumer",
+			tasks: map[string]TaskConfig{
+				"build": {Run: "make", Hosts: []string{"linux"}, Outputs: []string{"dist/"}},
+				"e2e":   {Run: "./e2e.sh", Hosts: []string{"mac"}, Inputs: []string{"build"}},
+			},
+		},
+		{
+			name: "non-existent producer",
+			tasks: map[string]TaskConfig{
+				"e2e": {Run: "./e2e.sh", Inputs: []string{"build"}},
+			},
+			errContains: "takes inputs from non-existent task 'build'",
+		},
+		{
+			name: "producer without outputs",
+			tasks: map[string]TaskConfig{
+				"build": {Run: "make"},
+				"e2e":   {Run: "./e2e.sh", Inputs: []string{"build"}},
+			},
+			errContains: "which has no outputs",
+		},
+		{
+			name: "inputs from itself",
+			tasks: map[string]TaskConfig{
+				"build": {Run: "make", Outputs: []string{"dist/"}, Inputs: []string{"build"}},
+			},
+			errContains: "can't take inputs from itself",
+		},
+		{
+			name: "cycle through inputs",
+			tasks: map[string]TaskConfig{
+				"a": {Run: "a", Outputs: []string{"a/"}, Inputs: []string{"b"}},
+				"b": {Run: "b", Outputs: []string{"b/"}, Inputs: []string{"a"}},
+			},
+			errContains: "circular dependency",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDependencyGraph(&Config{Tasks: tt.tasks})
+			if tt.errContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
 func TestValidate_DependencyIntegration(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -643,6 +1212,18 @@ func TestValidate_DependencyIntegration(t *testing.T) {
 			wantErr:     true,
 			errContains: "both 'parallel' and 'depends'",
 		},
+		{
+			name: "can't depend on a watch task",
+			config: &Config{
+				Version: 1,
+				Tasks: map[string]TaskConfig{
+					"dev": {Type: TaskTypeWatch, Run: "npm run dev"},
+					"e2e": {Depends: []DependencyItem{{Task: "dev"}}, Run: "npm run e2e"},
+				},
+			},
+			wantErr:     true,
+			errContains: "depends on watch task 'dev'",
+		},
 	}
 
 	for _, tt := range tests {
@@ -819,3 +1400,101 @@ func TestFlattenParallelTasks(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateHost_Fallback(t *testing.T) {
+	for _, fallback := range []string{"", FallbackOrder, FallbackRace} {
+		assert.NoError(t, validateHost("mini", Host{SSH: []string{"mini-lan", "mini-vpn"}, Dir: "~/rr", Fallback: fallback}))
+	}
+
+	err := validateHost("mini", Host{SSH: []string{"mini"}, Dir: "~/rr", Fallback: "parallel"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "fallback 'parallel' - use order or race")
+}
+
+func TestValidateHost_Transport(t *testing.T) {
+	for _, transport := range []string{"", TransportBuiltin, TransportSystemSSH} {
+		assert.NoError(t, validateHost("corp", Host{SSH: []string{"corp"}, Dir: "~/rr", Transport: transport}))
+	}
+
+	err := validateHost("corp", Host{SSH: []string{"corp"}, Dir: "~/rr", Transport: "openssh"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "transport 'openssh' - use builtin or system-ssh")
+
+	err = validateHost("corp", Host{SSH: []string{"corp"}, Dir: "~/rr", Transport: TransportSystemSSH,
+		IdentityFile: "~/.ssh/work", IdentityPassphrase: "keychain:work"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "add the key to your SSH agent")
+}
+
+func TestValidateHost_SSHOptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        SSHOptions
+		errContains string
+	}{
+		{"unset", SSHOptions{}, ""},
+		{"all set", SSHOptions{
+			Compression:         true,
+			Ciphers:             []string{"aes128-gcm@openssh.com", "chacha20-poly1305@openssh.com"},
+			ServerAliveInterval: 30 * time.Second,
+			ConnectTimeout:      10 * time.Second,
+		}, ""},
+		{"unknown cipher", SSHOptions{Ciphers: []string{"blowfish-cbc"}}, "ssh_options.ciphers has 'blowfish-cbc'"},
+		{"bare number interval", SSHOptions{ServerAliveInterval: 30}, "ssh_options.server_alive_interval is 30ns"},
+		{"negative timeout", SSHOptions{ConnectTimeout: -time.Second}, "ssh_options.connect_timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateHost("mini", Host{SSH: []string{"mini"}, Dir: "~/rr", SSHOptions: tt.opts})
+			if tt.errContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
+func TestSSHOptions_Args(t *testing.T) {
+	assert.Empty(t, SSHOptions{}.Args())
+
+	opts := SSHOptions{
+		Compression:         true,
+		Ciphers:             []string{"aes128-gcm@openssh.com", "aes256-ctr"},
+		ServerAliveInterval: time.Minute,
+		ConnectTimeout:      2500 * time.Millisecond,
+	}
+	assert.Equal(t, []string{
+		"-o", "Compression=yes",
+		"-o", "Ciphers=aes128-gcm@openssh.com,aes256-ctr",
+		"-o", "ServerAliveInterval=60",
+		"-o", "ConnectTimeout=3",
+	}, opts.Args())
+}
+
+func TestLoadGlobal_SSHOptions(t *testing.T) {
+	writeGlobalConfig(t, `
+version: 1
+hosts:
+  remote:
+    ssh: [remote.example.com]
+    dir: ~/rr
+    ssh_options:
+      compression: true
+      ciphers: [aes128-gcm@openssh.com]
+      server_alive_interval: 15s
+      connect_timeout: 20s
+`)
+
+	cfg, err := LoadGlobal()
+	require.NoError(t, err)
+	assert.Equal(t, SSHOptions{
+		Compression:         true,
+		Ciphers:             []string{"aes128-gcm@openssh.com"},
+		ServerAliveInterval: 15 * time.Second,
+		ConnectTimeout:      20 * time.Second,
+	}, cfg.Hosts["remote"].SSHOptions)
+	require.NoError(t, ValidateGlobal(cfg))
+}
@@ -40,6 +40,17 @@ type ExecutorOptions struct {
 
 	// StageHandler is called before and after each stage.
 	StageHandler StageHandler
+
+	// StepHandler is called before and after each step of multi-step
+	// tasks. It may be called from several tasks at once.
+	StepHandler exec.StepHandler
+
+	// Idle returns the idle detection for a task, or nil for none.
+	Idle func(task *config.TaskConfig) *exec.IdleOptions
+
+	// SudoPassword returns how a task answers sudo password prompts, or
+	// nil for no way to.
+	SudoPassword func(task *config.TaskConfig) exec.SudoPassword
 }
 
 // StageHandler receives callbacks during execution.
@@ -309,6 +320,14 @@ func (e *Executor) executeTask(ctx context.Context, taskName string) *TaskExecut
 	// Execute the task
 	execOpts := &exec.TaskExecOptions{
 		SetupCommands: e.opts.SetupCommands,
+		StepHandler:   e.opts.StepHandler,
+		ProjectRoot:   e.resolved.ProjectRoot,
+	}
+	if e.opts.Idle != nil {
+		execOpts.Idle = e.opts.Idle(&task)
+	}
+	if e.opts.SudoPassword != nil {
+		execOpts.SudoPassword = e.opts.SudoPassword(&task)
 	}
 
 	taskResult, err := exec.ExecuteTask(ctx, e.conn, &task, nil, mergedEnv, e.opts.WorkDir, e.opts.Stdout, e.opts.Stderr, execOpts)
@@ -0,0 +1,187 @@
+// Package diagnostics writes the test failures of the last run to
+// .rr/diagnostics.json in the project root, so editor plugins can show
+// remote test failures inline, the way a language server publishes
+// diagnostics.
+//
+// The file is rewritten after every run made with --diagnostics, including
+// successful ones (with an empty list), so stale failures disappear. Schema
+// version 1:
+//
+//	{
+//	  "version": 1,
+//	  "generated_at": "2026-01-02T15:04:05Z",
+//	  "command": "pytest tests/",    // command or task name that ran
+//	  "exit_code": 1,
+//	  "diagnostics": [
+//	    {
+//	      "file": "tests/test_api.py", // relative to the project root when rr can tell, else as reported
+//	      "line": 42,                  // 1-based, omitted when unknown
+//	      "severity": "error",
+//	      "test": "test_create_user",
+//	      "message": "AssertionError: expected 201, got 500",
+//	      "host": "mini",              // host the failure happened on
+//	      "task": "test-backend",      // subtask name, for parallel tasks
+//	      "source": "pytest"           // parser that found it: pytest, gotest, jest
+//	    }
+//	  ]
+//	}
+//
+// New fields may be added within a version; a breaking change bumps it.
+package diagnostics
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/output/formatters"
+	"github.com/rileyhilliard/rr/internal/util"
+)
+
+const (
+	// SchemaVersion is the version of the diagnostics.json format.
+	SchemaVersion = 1
+
+	// DirName is the per-project directory rr writes editor files to.
+	DirName = ".rr"
+
+	// FileName is the diagnostics file inside DirName.
+	FileName = "diagnostics.json"
+
+	// SeverityError is the severity of a test failure.
+	SeverityError = "error"
+)
+
+// Report is the contents of diagnostics.json.
+type Report struct {
+	Version     int          `json:"version"`
+	GeneratedAt time.Time    `json:"generated_at"`
+	Command     string       `json:"command"`
+	ExitCode    int          `json:"exit_code"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// Diagnostic is one failure location.
+type Diagnostic struct {
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Severity string `json:"severity"`
+	Test     string `json:"test,omitempty"`
+	Message  string `json:"message,omitempty"`
+	Host     string `json:"host,omitempty"`
+	Task     string `json:"task,omitempty"`
+	Source   string `json:"source,omitempty"`
+}
+
+// NewReport creates an empty report for a run.
+func NewReport(command string, exitCode int, now time.Time) *Report {
+	return &Report{
+		Version:     SchemaVersion,
+		GeneratedAt: now.UTC(),
+		Command:     command,
+		ExitCode:    exitCode,
+		Diagnostics: []Diagnostic{},
+	}
+}
+
+// AddOutput parses a command's output with the matching test framework
+// parser and adds a diagnostic for each failure. remoteDir is the directory
+// the command ran in, used to turn absolute remote paths into project
+// relative ones; task may be empty.
+func (r *Report) AddOutput(command string, out []byte, host, remoteDir, task string) {
+	source, failures := formatters.ExtractFailuresWithSource(command, out)
+	for _, f := range failures {
+		r.Diagnostics = append(r.Diagnostics, Diagnostic{
+			File:     localizePath(f.File, remoteDir),
+			Line:     f.Line,
+			Severity: SeverityError,
+			Test:     f.TestName,
+			Message:  f.Message,
+			Host:     host,
+			Task:     task,
+			Source:   source,
+		})
+	}
+}
+
+// Path returns the diagnostics file path for a project.
+func Path(projectRoot string) string {
+	return filepath.Join(projectRoot, DirName, FileName)
+}
+
+// EnsureDir creates the project's .rr directory and returns its path. It
+// gets a .gitignore so it stays out of git and, through respect_gitignore,
+// out of syncs.
+func EnsureDir(projectRoot string) (string, error) {
+	dir := filepath.Join(projectRoot, DirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", wrapWriteError(err, dir)
+	}
+	ignore := filepath.Join(dir, ".gitignore")
+	if _, err := os.Stat(ignore); os.IsNotExist(err) {
+		_ = os.WriteFile(ignore, []byte("*\n"), 0644)
+	}
+	return dir, nil
+}
+
+// Write saves the report to the project's diagnostics file, replacing it
+// atomically so an editor watching the file never reads half of it.
+func Write(projectRoot string, r *Report) error {
+	dir, err := EnsureDir(projectRoot)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return wrapWriteError(err, dir)
+	}
+
+	if err := util.WriteFileAtomic(Path(projectRoot), append(data, '\n'), 0644); err != nil {
+		return wrapWriteError(err, dir)
+	}
+	return nil
+}
+
+// Load reads a project's diagnostics file.
+func Load(projectRoot string) (*Report, error) {
+	data, err := os.ReadFile(Path(projectRoot))
+	if err != nil {
+		return nil, err
+	}
+	var r Report
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// localizePath makes an absolute remote path relative to the project root
+// by stripping the remote project directory. A remoteDir under ~ matches
+// wherever the remote home is. Other paths are returned unchanged.
+func localizePath(file, remoteDir string) string {
+	if file == "" || remoteDir == "" || !strings.HasPrefix(file, "/") {
+		return file
+	}
+	dir := strings.TrimSuffix(remoteDir, "/")
+	if strings.HasPrefix(dir, "~/") {
+		// "/home/alice/rr/app/x.go" with "~/rr/app": match on "/rr/app/"
+		if i := strings.Index(file, dir[1:]+"/"); i >= 0 {
+			return file[i+len(dir):]
+		}
+		return file
+	}
+	if strings.HasPrefix(file, dir+"/") {
+		return file[len(dir)+1:]
+	}
+	return file
+}
+
+func wrapWriteError(err error, dir string) error {
+	return errors.WrapWithCode(err, errors.ErrConfig,
+		"Couldn't write diagnostics to "+dir,
+		"Check that the project directory is writable.")
+}
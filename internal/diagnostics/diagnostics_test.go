@@ -0,0 +1,107 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const pytestOutput = `
+============================= test session starts ==============================
+collected 2 items
+
+tests/test_api.py::test_ok PASSED [50%]
+tests/test_api.py::test_create FAILED [100%]
+
+=================================== FAILURES ===================================
+_________________________________ test_create ________________________________
+
+    def test_create():
+>       assert 201 == 500
+E       AssertionError: assert 201 == 500
+
+/home/alice/rr/app/tests/test_api.py:12: AssertionError
+=========================== short test summary info ============================
+FAILED tests/test_api.py::test_create - AssertionError: assert 201 == 500
+========================= 1 failed, 1 passed in 0.03s ==========================
+`
+
+func TestReport_AddOutput(t *testing.T) {
+	r := NewReport("test", 1, time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC))
+	r.AddOutput("pytest tests/", []byte(pytestOutput), "mini", "~/rr/app", "")
+
+	require.Len(t, r.Diagnostics, 1)
+	d := r.Diagnostics[0]
+	assert.Equal(t, "tests/test_api.py", d.File)
+	assert.Equal(t, 12, d.Line)
+	assert.Equal(t, SeverityError, d.Severity)
+	assert.Equal(t, "test_create", d.Test)
+	assert.Contains(t, d.Message, "AssertionError")
+	assert.Equal(t, "mini", d.Host)
+	assert.Equal(t, "pytest", d.Source)
+}
+
+func TestReport_AddOutput_UnknownFormat(t *testing.T) {
+	r := NewReport("build", 2, time.Now())
+	r.AddOutput("make", []byte("make: *** [all] Error 2\n"), "mini", "~/rr/app", "")
+	assert.Empty(t, r.Diagnostics)
+}
+
+func TestLocalizePath(t *testing.T) {
+	tests := []struct {
+		file, dir, want string
+	}{
+		{"/home/alice/rr/app/tests/x.py", "~/rr/app", "tests/x.py"},
+		{"/home/alice/rr/app/tests/x.py", "~/rr/app/", "tests/x.py"},
+		{"/srv/app/pkg/x.go", "/srv/app", "pkg/x.go"},
+		{"/srv/other/x.go", "/srv/app", "/srv/other/x.go"},
+		{"/home/alice/rr/application/x.py", "~/rr/app", "/home/alice/rr/application/x.py"},
+		{"tests/x.py", "~/rr/app", "tests/x.py"},
+		{"x_test.go", "", "x_test.go"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, localizePath(tt.file, tt.dir), tt.file)
+	}
+}
+
+func TestWriteAndLoad(t *testing.T) {
+	root := t.TempDir()
+	r := NewReport("test", 1, time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC))
+	r.Diagnostics = append(r.Diagnostics, Diagnostic{File: "a.go", Line: 3, Severity: SeverityError, Test: "TestA"})
+
+	require.NoError(t, Write(root, r))
+
+	loaded, err := Load(root)
+	require.NoError(t, err)
+	assert.Equal(t, r, loaded)
+
+	ignore, err := os.ReadFile(filepath.Join(root, DirName, ".gitignore"))
+	require.NoError(t, err)
+	assert.Equal(t, "*\n", string(ignore))
+
+	// No temp files left behind
+	entries, err := os.ReadDir(filepath.Join(root, DirName))
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestWrite_EmptyReportClearsFailures(t *testing.T) {
+	root := t.TempDir()
+	failing := NewReport("test", 1, time.Now())
+	failing.Diagnostics = append(failing.Diagnostics, Diagnostic{File: "a.go", Severity: SeverityError})
+	require.NoError(t, Write(root, failing))
+
+	require.NoError(t, Write(root, NewReport("test", 0, time.Now())))
+
+	data, err := os.ReadFile(Path(root))
+	require.NoError(t, err)
+	var raw map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &raw))
+	assert.Equal(t, float64(SchemaVersion), raw["version"])
+	assert.Equal(t, []interface{}{}, raw["diagnostics"])
+}
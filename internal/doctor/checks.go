@@ -2,6 +2,7 @@ package doctor
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 )
 
@@ -14,6 +15,30 @@ const (
 	StatusFail
 )
 
+// Categories lists every check category in display order.
+var Categories = []string{"CONFIG", "SSH", "HOSTS", "DEPENDENCIES", "PATH", "ENV", "REMOTE", "REQUIREMENTS"}
+
+// Exit codes returned by 'rr doctor' so CI can gate on diagnostics.
+const (
+	ExitClear    = 0 // No issues at or above the threshold
+	ExitWarnings = 1 // Worst issue is a warning
+	ExitFailures = 2 // At least one check failed
+)
+
+// ParseStatus converts a status name ("pass", "warn", "fail") to a CheckStatus.
+func ParseStatus(s string) (CheckStatus, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "pass":
+		return StatusPass, true
+	case "warn":
+		return StatusWarn, true
+	case "fail":
+		return StatusFail, true
+	default:
+		return StatusPass, false
+	}
+}
+
 // String returns a human-readable status string.
 func (s CheckStatus) String() string {
 	switch s {
@@ -127,6 +152,44 @@ func HasIssues(results []CheckResult) bool {
 	return false
 }
 
+// ExitCode maps results to a doctor exit code. Results below the failOn
+// threshold are ignored, so failOn=StatusFail lets warnings through with 0.
+func ExitCode(results []CheckResult, failOn CheckStatus) int {
+	worst := StatusPass
+	for _, r := range results {
+		if r.Status > worst {
+			worst = r.Status
+		}
+	}
+	if worst < failOn || worst == StatusPass {
+		return ExitClear
+	}
+	if worst == StatusFail {
+		return ExitFailures
+	}
+	return ExitWarnings
+}
+
+// FilterByCategory returns only the checks in the given categories.
+// Matching is case-insensitive. An empty list returns all checks.
+func FilterByCategory(checks []Check, categories []string) []Check {
+	if len(categories) == 0 {
+		return checks
+	}
+	want := make(map[string]bool, len(categories))
+	for _, c := range categories {
+		want[strings.ToUpper(strings.TrimSpace(c))] = true
+	}
+
+	var filtered []Check
+	for _, check := range checks {
+		if want[check.Category()] {
+			filtered = append(filtered, check)
+		}
+	}
+	return filtered
+}
+
 // FixableCount returns the number of issues that can be fixed automatically.
 func FixableCount(results []CheckResult) int {
 	count := 0
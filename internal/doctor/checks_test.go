@@ -123,6 +123,77 @@ func TestGroupByCategory(t *testing.T) {
 	}
 }
 
+func TestFilterByCategory(t *testing.T) {
+	checks := []Check{
+		&mockCheck{name: "c1", category: "SSH"},
+		&mockCheck{name: "c2", category: "HOSTS"},
+		&mockCheck{name: "c3", category: "CONFIG"},
+	}
+
+	if got := FilterByCategory(checks, nil); len(got) != 3 {
+		t.Errorf("expected all 3 checks with no filter, got %d", len(got))
+	}
+
+	got := FilterByCategory(checks, []string{"ssh", " hosts "})
+	if len(got) != 2 {
+		t.Fatalf("expected 2 checks, got %d", len(got))
+	}
+	if got[0].Name() != "c1" || got[1].Name() != "c2" {
+		t.Errorf("expected c1 and c2 in order, got %s and %s", got[0].Name(), got[1].Name())
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	pass := CheckResult{Status: StatusPass}
+	warn := CheckResult{Status: StatusWarn}
+	fail := CheckResult{Status: StatusFail}
+
+	tests := []struct {
+		name     string
+		results  []CheckResult
+		failOn   CheckStatus
+		expected int
+	}{
+		{"empty", nil, StatusWarn, ExitClear},
+		{"all pass", []CheckResult{pass, pass}, StatusWarn, ExitClear},
+		{"warning", []CheckResult{pass, warn}, StatusWarn, ExitWarnings},
+		{"failure beats warning", []CheckResult{warn, fail, pass}, StatusWarn, ExitFailures},
+		{"warning below fail threshold", []CheckResult{pass, warn}, StatusFail, ExitClear},
+		{"failure at fail threshold", []CheckResult{warn, fail}, StatusFail, ExitFailures},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ExitCode(tc.results, tc.failOn); got != tc.expected {
+				t.Errorf("expected exit code %d, got %d", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseStatus(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected CheckStatus
+		ok       bool
+	}{
+		{"pass", StatusPass, true},
+		{"warn", StatusWarn, true},
+		{"FAIL", StatusFail, true},
+		{"error", StatusPass, false},
+		{"", StatusPass, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			got, ok := ParseStatus(tc.input)
+			if got != tc.expected || ok != tc.ok {
+				t.Errorf("ParseStatus(%q) = (%v, %v), want (%v, %v)", tc.input, got, ok, tc.expected, tc.ok)
+			}
+		})
+	}
+}
+
 func TestCountByStatus(t *testing.T) {
 	results := []CheckResult{
 		{Status: StatusPass},
@@ -2,12 +2,12 @@ package doctor
 
 import (
 	"fmt"
-	"os/exec"
 	"regexp"
 	"strings"
 
 	"github.com/rileyhilliard/rr/internal/config"
 	"github.com/rileyhilliard/rr/internal/host"
+	rrsync "github.com/rileyhilliard/rr/internal/sync"
 )
 
 // RsyncLocalCheck verifies rsync is installed locally.
@@ -17,8 +17,8 @@ func (c *RsyncLocalCheck) Name() string     { return "rsync_local" }
 func (c *RsyncLocalCheck) Category() string { return "DEPENDENCIES" }
 
 func (c *RsyncLocalCheck) Run() CheckResult {
-	// Check if rsync is in PATH
-	path, err := exec.LookPath("rsync")
+	// Resolve rsync the same way sync does, including preferred installs
+	path, err := rrsync.FindRsync()
 	if err != nil {
 		return CheckResult{
 			Name:       c.Name(),
@@ -29,22 +29,24 @@ func (c *RsyncLocalCheck) Run() CheckResult {
 		}
 	}
 
-	// Get version
-	cmd := exec.Command(path, "--version")
-	output, err := cmd.Output()
-	if err != nil {
+	rsync := rrsync.DetectRsync(path)
+	if missing := rsync.Missing(); len(missing) > 0 {
+		names := make([]string, len(missing))
+		for i, f := range missing {
+			names[i] = string(f)
+		}
 		return CheckResult{
-			Name:    c.Name(),
-			Status:  StatusPass,
-			Message: "rsync found (version unknown)",
+			Name:       c.Name(),
+			Status:     StatusWarn,
+			Message:    fmt.Sprintf("rsync %s (local) lacks %s", rsync, strings.Join(names, ", ")),
+			Suggestion: "Syncs still work, without a progress bar or nested .gitignore support. For full support: brew install rsync (macOS) or apt install rsync (Linux)",
 		}
 	}
 
-	version := parseRsyncVersion(string(output))
 	return CheckResult{
 		Name:    c.Name(),
 		Status:  StatusPass,
-		Message: fmt.Sprintf("rsync %s (local)", version),
+		Message: fmt.Sprintf("rsync %s (local)", rsync),
 	}
 }
 
@@ -70,8 +72,13 @@ func (c *RsyncRemoteCheck) Run() CheckResult {
 		}
 	}
 
-	// Check rsync on remote
-	stdout, stderr, exitCode, err := c.Conn.Client.Exec("which rsync && rsync --version 2>/dev/null | head -1")
+	// Check the rsync syncs will run on the remote, which may not be PATH's
+	rsync, where := "rsync", ""
+	if path := rrsync.RemoteRsyncPath(c.Conn); strings.TrimSpace(path) != "" {
+		rsync, where = path, " at "+path
+	}
+	checkCmd := fmt.Sprintf("which %s && %s --version 2>/dev/null | head -1", strings.Fields(rsync)[0], rsync)
+	stdout, stderr, exitCode, err := c.Conn.Client.Exec(checkCmd)
 	if err != nil {
 		return CheckResult{
 			Name:       c.Name(),
@@ -85,7 +92,7 @@ func (c *RsyncRemoteCheck) Run() CheckResult {
 		return CheckResult{
 			Name:       c.Name(),
 			Status:     StatusFail,
-			Message:    fmt.Sprintf("rsync not found on %s", c.HostName),
+			Message:    fmt.Sprintf("rsync%s not found on %s", where, c.HostName),
 			Suggestion: fmt.Sprintf("Install rsync on %s: apt install rsync (or equivalent)", c.HostName),
 		}
 	}
@@ -100,7 +107,7 @@ func (c *RsyncRemoteCheck) Run() CheckResult {
 	return CheckResult{
 		Name:    c.Name(),
 		Status:  StatusPass,
-		Message: fmt.Sprintf("rsync %s (%s)", version, c.HostName),
+		Message: fmt.Sprintf("rsync %s%s (%s)", version, where, c.HostName),
 	}
 }
 
@@ -202,6 +209,9 @@ func GetDepsInfo(checks []Check, results []CheckResult) []DependencyInfo {
 
 func extractVersion(msg string) string {
 	// "rsync 3.2.7 (local)" -> "3.2.7"
+	if strings.Contains(msg, "openrsync") {
+		return "openrsync"
+	}
 	re := regexp.MustCompile(`(\d+\.\d+\.?\d*)`)
 	matches := re.FindStringSubmatch(msg)
 	if len(matches) >= 1 {
@@ -0,0 +1,107 @@
+package doctor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/exec"
+	"github.com/rileyhilliard/rr/pkg/sshutil"
+)
+
+// EnvCheck compares tool versions and locale variables between the local
+// machine and what commands see on a host, the same comparison rr env diff
+// shows in full.
+type EnvCheck struct {
+	HostName string
+	Host     config.Host
+	Client   sshutil.SSHClient
+	WorkDir  string // local directory to take the local snapshot in
+}
+
+// Name returns the check identifier.
+func (c *EnvCheck) Name() string {
+	return fmt.Sprintf("env_%s", c.HostName)
+}
+
+// Category returns the check category.
+func (c *EnvCheck) Category() string {
+	return "ENV"
+}
+
+// Run executes the environment comparison.
+func (c *EnvCheck) Run() CheckResult {
+	if c.Client == nil {
+		return CheckResult{
+			Name:    c.Name(),
+			Status:  StatusFail,
+			Message: fmt.Sprintf("Environment check (%s): no connection", c.HostName),
+		}
+	}
+
+	local, err := exec.SnapshotLocal(c.WorkDir, nil)
+	if err == nil {
+		var remote *exec.EnvSnapshot
+		remote, err = exec.SnapshotRemote(c.Client, c.Host, nil)
+		if err == nil {
+			return envResult(c.Name(), c.HostName, exec.CompareEnv(local, remote, c.HostName))
+		}
+	}
+	return CheckResult{
+		Name:       c.Name(),
+		Status:     StatusFail,
+		Message:    fmt.Sprintf("Environment check (%s): failed to compare", c.HostName),
+		Suggestion: fmt.Sprintf("Error: %v", err),
+	}
+}
+
+// envResult turns the differences found into a check result.
+func envResult(name, hostName string, deltas []exec.EnvDelta) CheckResult {
+	if len(deltas) == 0 {
+		return CheckResult{
+			Name:    name,
+			Status:  StatusPass,
+			Message: fmt.Sprintf("Environment matches local (%s)", hostName),
+		}
+	}
+
+	var sb strings.Builder
+	for _, d := range deltas {
+		fmt.Fprintf(&sb, "  - %s: local %s, remote %s\n", d.Name, orNone(d.Local), orNone(d.Remote))
+	}
+	fmt.Fprintf(&sb, "\nSee what to change with: rr env diff --host %s", hostName)
+
+	return CheckResult{
+		Name:       name,
+		Status:     StatusWarn,
+		Message:    fmt.Sprintf("Environment differs (%s): %d differences from local", hostName, len(deltas)),
+		Suggestion: sb.String(),
+	}
+}
+
+// orNone shows an empty side of a difference as "none".
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+// Fix cannot auto-fix environment differences.
+func (c *EnvCheck) Fix() error {
+	return nil
+}
+
+// NewEnvChecks creates environment checks for the given SSH clients.
+func NewEnvChecks(hosts map[string]config.Host, clients map[string]sshutil.SSHClient, workDir string) []Check {
+	var checks []Check
+	for name, client := range clients {
+		checks = append(checks, &EnvCheck{
+			HostName: name,
+			Host:     hosts[name],
+			Client:   client,
+			WorkDir:  workDir,
+		})
+	}
+	return checks
+}
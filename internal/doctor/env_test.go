@@ -0,0 +1,27 @@
+package doctor
+
+import (
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/exec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvResult(t *testing.T) {
+	pass := envResult("env_box", "box", nil)
+	assert.Equal(t, StatusPass, pass.Status)
+
+	warn := envResult("env_box", "box", []exec.EnvDelta{
+		{Kind: exec.EnvDiffTool, Name: "node", Local: "20.11.0"},
+	})
+	assert.Equal(t, StatusWarn, warn.Status)
+	assert.Contains(t, warn.Message, "1 differences")
+	assert.Contains(t, warn.Suggestion, "node: local 20.11.0, remote none")
+	assert.Contains(t, warn.Suggestion, "rr env diff --host box")
+}
+
+func TestEnvCheck_NoClient(t *testing.T) {
+	check := &EnvCheck{HostName: "box"}
+	assert.Equal(t, "ENV", check.Category())
+	assert.Equal(t, StatusFail, check.Run().Status)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/rileyhilliard/rr/internal/config"
 	"github.com/rileyhilliard/rr/internal/host"
 	"github.com/rileyhilliard/rr/internal/lock"
+	rrsync "github.com/rileyhilliard/rr/internal/sync"
 )
 
 // RemoteDirCheck verifies the working directory exists on a remote host.
@@ -238,6 +239,59 @@ func (c *RemoteStaleLockCheck) Fix() error {
 	return nil
 }
 
+// RemoteClockCheck compares the host's clock with the local one. Drift
+// confuses tools that compare mtimes, and past rrsync.ClockSkewChecksum
+// syncs fall back to comparing file contents, which is slower.
+type RemoteClockCheck struct {
+	HostName string
+	Conn     *host.Connection
+}
+
+func (c *RemoteClockCheck) Name() string     { return fmt.Sprintf("remote_clock_%s", c.HostName) }
+func (c *RemoteClockCheck) Category() string { return "REMOTE" }
+
+func (c *RemoteClockCheck) Run() CheckResult {
+	if c.Conn == nil || c.Conn.Client == nil {
+		return CheckResult{
+			Name:    c.Name(),
+			Status:  StatusPass, // Can't check without connection
+			Message: "Clock check: no connection",
+		}
+	}
+
+	skew, err := rrsync.MeasureClockSkew(c.Conn.Client)
+	if err != nil {
+		return CheckResult{
+			Name:    c.Name(),
+			Status:  StatusPass,
+			Message: fmt.Sprintf("Cannot check clock: %v", err),
+		}
+	}
+
+	if rrsync.ExceedsClockSkew(skew, rrsync.ClockSkewWarn) {
+		msg := fmt.Sprintf("Clock is %s of this machine's", rrsync.DescribeClockSkew(skew))
+		if rrsync.ExceedsClockSkew(skew, rrsync.ClockSkewChecksum) {
+			msg += " (syncs use --checksum)"
+		}
+		return CheckResult{
+			Name:       c.Name(),
+			Status:     StatusWarn,
+			Message:    msg,
+			Suggestion: rrsync.ClockSkewHint,
+		}
+	}
+
+	return CheckResult{
+		Name:    c.Name(),
+		Status:  StatusPass,
+		Message: "Clock in sync",
+	}
+}
+
+func (c *RemoteClockCheck) Fix() error {
+	return nil // Setting a host's clock needs root on the host
+}
+
 // formatDuration formats a duration in a human-readable way.
 func formatDuration(d time.Duration) string {
 	if d < time.Minute {
@@ -267,5 +321,9 @@ func NewRemoteChecks(hostName string, hostCfg config.Host, conn *host.Connection
 			Conn:       conn,
 			LockConfig: lockCfg,
 		},
+		&RemoteClockCheck{
+			HostName: hostName,
+			Conn:     conn,
+		},
 	}
 }
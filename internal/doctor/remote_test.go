@@ -1,10 +1,14 @@
 package doctor
 
 import (
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/host"
+	sshtesting "github.com/rileyhilliard/rr/pkg/sshutil/testing"
 )
 
 func TestRemoteDirCheck(t *testing.T) {
@@ -154,8 +158,8 @@ func TestNewRemoteChecks(t *testing.T) {
 
 	checks := NewRemoteChecks("test-host", hostCfg, nil, lockCfg)
 
-	if len(checks) != 3 {
-		t.Errorf("expected 3 remote checks, got %d", len(checks))
+	if len(checks) != 4 {
+		t.Errorf("expected 4 remote checks, got %d", len(checks))
 	}
 
 	// Verify all checks have REMOTE category
@@ -175,6 +179,7 @@ func TestNewRemoteChecks(t *testing.T) {
 		"remote_dir_test-host",
 		"remote_write_test-host",
 		"remote_locks_test-host",
+		"remote_clock_test-host",
 	}
 	for _, name := range expectedNames {
 		if !names[name] {
@@ -182,3 +187,43 @@ func TestNewRemoteChecks(t *testing.T) {
 		}
 	}
 }
+
+func TestRemoteClockCheck(t *testing.T) {
+	newCheck := func(remote time.Time) *RemoteClockCheck {
+		mock := sshtesting.NewMockClient("test-host")
+		mock.SetCommandResponse("^date", sshtesting.CommandResponse{
+			Stdout: []byte(strconv.FormatInt(remote.Unix(), 10) + "\n"),
+		})
+		return &RemoteClockCheck{
+			HostName: "test-host",
+			Conn:     &host.Connection{Name: "test-host", Client: mock},
+		}
+	}
+
+	t.Run("in sync", func(t *testing.T) {
+		result := newCheck(time.Now()).Run()
+		if result.Status != StatusPass {
+			t.Errorf("expected StatusPass, got %v: %s", result.Status, result.Message)
+		}
+	})
+
+	t.Run("far behind", func(t *testing.T) {
+		result := newCheck(time.Now().Add(-10 * time.Minute)).Run()
+		if result.Status != StatusWarn {
+			t.Errorf("expected StatusWarn, got %v", result.Status)
+		}
+		if !strings.Contains(result.Message, "10m behind") || !strings.Contains(result.Message, "--checksum") {
+			t.Errorf("unexpected message %q", result.Message)
+		}
+		if !strings.Contains(result.Suggestion, "NTP") {
+			t.Errorf("expected an NTP suggestion, got %q", result.Suggestion)
+		}
+	})
+
+	t.Run("no connection", func(t *testing.T) {
+		result := (&RemoteClockCheck{HostName: "test-host"}).Run()
+		if result.Status != StatusPass {
+			t.Errorf("expected StatusPass with no connection, got %v", result.Status)
+		}
+	})
+}
@@ -7,6 +7,9 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
+
+	"github.com/rileyhilliard/rr/pkg/sshutil"
 )
 
 // SSHKeyCheck verifies an SSH key exists.
@@ -227,11 +230,142 @@ func (c *SSHKeyPermissionsCheck) Fix() error {
 	return nil
 }
 
+// DefaultCertWarnWithin is how close to expiring an SSH certificate gets
+// before doctor warns about it.
+const DefaultCertWarnWithin = 7 * 24 * time.Hour
+
+// SSHCertCheck verifies the SSH certificates next to the default keys are
+// valid and not about to expire.
+type SSHCertCheck struct {
+	WarnWithin time.Duration // Warn when a certificate expires sooner than this
+}
+
+func (c *SSHCertCheck) Name() string     { return "ssh_cert" }
+func (c *SSHCertCheck) Category() string { return "SSH" }
+
+func (c *SSHCertCheck) Run() CheckResult {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return CheckResult{
+			Name:   c.Name(),
+			Status: StatusPass, // Skip if we can't check
+		}
+	}
+
+	var certPaths []string
+	for _, name := range []string{"id_ed25519", "id_rsa", "id_ecdsa"} {
+		certPaths = append(certPaths, sshutil.CertPath(filepath.Join(home, ".ssh", name)))
+	}
+
+	warnWithin := c.WarnWithin
+	if warnWithin <= 0 {
+		warnWithin = DefaultCertWarnWithin
+	}
+	result := checkCertificates(certPaths, time.Now(), warnWithin)
+	result.Name = c.Name()
+	return result
+}
+
+func (c *SSHCertCheck) Fix() error {
+	// Certificates come from the CA, so there's nothing to fix locally
+	return nil
+}
+
+// checkCertificates checks the certificates at certPaths, skipping ones that
+// don't exist. The worst certificate decides the result.
+func checkCertificates(certPaths []string, now time.Time, warnWithin time.Duration) CheckResult {
+	var expired, expiring, notYetValid, unreadable, valid []string
+	var soonest time.Time
+
+	for _, certPath := range certPaths {
+		if _, err := os.Stat(certPath); err != nil {
+			continue
+		}
+		name := filepath.Base(certPath)
+		cert, err := sshutil.LoadCertificate(certPath)
+		if err != nil {
+			unreadable = append(unreadable, name)
+			continue
+		}
+		if now.Before(time.Unix(int64(cert.ValidAfter), 0)) {
+			notYetValid = append(notYetValid, name)
+			continue
+		}
+		expiry, ok := sshutil.CertExpiry(cert)
+		switch {
+		case !ok:
+			valid = append(valid, name)
+			continue
+		case !now.Before(expiry):
+			expired = append(expired, name)
+		case expiry.Sub(now) < warnWithin:
+			expiring = append(expiring, name)
+		default:
+			valid = append(valid, name)
+		}
+		if soonest.IsZero() || expiry.Before(soonest) {
+			soonest = expiry
+		}
+	}
+
+	switch {
+	case len(expired) > 0:
+		return CheckResult{
+			Status:     StatusFail,
+			Message:    fmt.Sprintf("SSH certificate expired: %s", strings.Join(expired, ", ")),
+			Suggestion: "Get a new certificate from your CA; hosts that require one will reject the bare key",
+		}
+	case len(unreadable) > 0:
+		return CheckResult{
+			Status:     StatusWarn,
+			Message:    fmt.Sprintf("Cannot read SSH certificate: %s", strings.Join(unreadable, ", ")),
+			Suggestion: "Check the file with: ssh-keygen -L -f ~/.ssh/<certfile>",
+		}
+	case len(notYetValid) > 0:
+		return CheckResult{
+			Status:     StatusWarn,
+			Message:    fmt.Sprintf("SSH certificate not valid yet: %s", strings.Join(notYetValid, ", ")),
+			Suggestion: "Check your system clock",
+		}
+	case len(expiring) > 0:
+		return CheckResult{
+			Status:     StatusWarn,
+			Message:    fmt.Sprintf("SSH certificate expires in %s: %s", formatCertRemaining(soonest.Sub(now)), strings.Join(expiring, ", ")),
+			Suggestion: "Renew it with your CA before it expires",
+		}
+	case len(valid) == 0:
+		return CheckResult{
+			Status:  StatusPass,
+			Message: "No SSH certificates to check",
+		}
+	case soonest.IsZero():
+		return CheckResult{
+			Status:  StatusPass,
+			Message: fmt.Sprintf("SSH certificate valid: %s", strings.Join(valid, ", ")),
+		}
+	default:
+		return CheckResult{
+			Status:  StatusPass,
+			Message: fmt.Sprintf("SSH certificate valid for %s: %s", formatCertRemaining(soonest.Sub(now)), strings.Join(valid, ", ")),
+		}
+	}
+}
+
+// formatCertRemaining formats how long a certificate has left, in days once
+// that's the useful unit.
+func formatCertRemaining(d time.Duration) string {
+	if days := int(d.Hours() / 24); days >= 2 {
+		return fmt.Sprintf("%d days", days)
+	}
+	return formatDuration(d)
+}
+
 // NewSSHChecks creates all SSH-related checks.
 func NewSSHChecks() []Check {
 	return []Check{
 		&SSHKeyCheck{},
 		&SSHAgentCheck{},
 		&SSHKeyPermissionsCheck{},
+		&SSHCertCheck{WarnWithin: DefaultCertWarnWithin},
 	}
 }
@@ -1,9 +1,15 @@
 package doctor
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
 )
 
 func TestSSHKeyCheck(t *testing.T) {
@@ -94,8 +100,8 @@ func TestSSHKeyPermissionsCheck(t *testing.T) {
 func TestNewSSHChecks(t *testing.T) {
 	checks := NewSSHChecks()
 
-	if len(checks) != 3 {
-		t.Errorf("expected 3 SSH checks, got %d", len(checks))
+	if len(checks) != 4 {
+		t.Errorf("expected 4 SSH checks, got %d", len(checks))
 	}
 
 	// Verify all checks have SSH category
@@ -111,10 +117,97 @@ func TestNewSSHChecks(t *testing.T) {
 		names[check.Name()] = true
 	}
 
-	expectedNames := []string{"ssh_key", "ssh_agent", "ssh_key_permissions"}
+	expectedNames := []string{"ssh_key", "ssh_agent", "ssh_key_permissions", "ssh_cert"}
 	for _, name := range expectedNames {
 		if !names[name] {
 			t.Errorf("expected check %q not found", name)
 		}
 	}
 }
+
+// writeCert writes a certificate signed by a throwaway CA to dir/name, valid
+// from validAfter until validBefore.
+func writeCert(t *testing.T, dir, name string, validAfter, validBefore uint64) string {
+	t.Helper()
+	_, caPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caSigner, err := ssh.NewSignerFromKey(caPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userPub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sshPub, err := ssh.NewPublicKey(userPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := &ssh.Certificate{
+		Key:         sshPub,
+		CertType:    ssh.UserCert,
+		ValidAfter:  validAfter,
+		ValidBefore: validBefore,
+	}
+	if err := cert.SignCert(rand.Reader, caSigner); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, ssh.MarshalAuthorizedKey(cert), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCheckCertificates(t *testing.T) {
+	now := time.Now()
+	at := func(d time.Duration) uint64 { return uint64(now.Add(d).Unix()) }
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name        string
+		certs       map[string][2]uint64 // file -> validAfter, validBefore
+		garbage     bool
+		wantStatus  CheckStatus
+		wantMessage string
+	}{
+		{"no certificates", nil, false, StatusPass, "No SSH certificates"},
+		{"valid", map[string][2]uint64{"id_ed25519-cert.pub": {0, at(30 * day)}}, false, StatusPass, "valid for 29 days"},
+		{"valid forever", map[string][2]uint64{"id_ed25519-cert.pub": {0, ssh.CertTimeInfinity}}, false, StatusPass, "SSH certificate valid: id_ed25519-cert.pub"},
+		{"expiring soon", map[string][2]uint64{"id_ed25519-cert.pub": {0, at(2 * time.Hour)}}, false, StatusWarn, "expires in 1h59m"},
+		{"not valid yet", map[string][2]uint64{"id_ed25519-cert.pub": {at(time.Hour), ssh.CertTimeInfinity}}, false, StatusWarn, "not valid yet"},
+		{"expired", map[string][2]uint64{
+			"id_ed25519-cert.pub": {0, at(30 * day)},
+			"id_rsa-cert.pub":     {0, at(-time.Hour)},
+		}, false, StatusFail, "expired: id_rsa-cert.pub"},
+		{"unreadable", nil, true, StatusWarn, "Cannot read"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, validity := range tt.certs {
+				writeCert(t, dir, name, validity[0], validity[1])
+			}
+			if tt.garbage {
+				if err := os.WriteFile(filepath.Join(dir, "id_ecdsa-cert.pub"), []byte("not a cert"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var paths []string
+			for _, name := range []string{"id_ed25519", "id_rsa", "id_ecdsa"} {
+				paths = append(paths, filepath.Join(dir, name+"-cert.pub"))
+			}
+			result := checkCertificates(paths, now, DefaultCertWarnWithin)
+			if result.Status != tt.wantStatus {
+				t.Errorf("status = %v, want %v (%s)", result.Status, tt.wantStatus, result.Message)
+			}
+			if !strings.Contains(result.Message, tt.wantMessage) {
+				t.Errorf("message = %q, want it to contain %q", result.Message, tt.wantMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,340 @@
+package doctor
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/pkg/sshutil"
+)
+
+// SSHConfigCheck checks how ~/.ssh/config sets up the configured hosts'
+// SSH aliases, for mistakes ssh won't explain: an alias with no HostName, a
+// Match block whose settings lose to an earlier block, an IdentityFile
+// that doesn't exist, and a ControlPath too long for a socket.
+type SSHConfigCheck struct {
+	Hosts      map[string]config.Host
+	ConfigPath string // Empty for ~/.ssh/config
+}
+
+func (c *SSHConfigCheck) Name() string     { return "ssh_config" }
+func (c *SSHConfigCheck) Category() string { return "SSH" }
+
+func (c *SSHConfigCheck) Run() CheckResult {
+	home, _ := os.UserHomeDir()
+	configPath := c.ConfigPath
+	if configPath == "" {
+		configPath = filepath.Join(home, ".ssh", "config")
+	}
+	display := tildePath(configPath, home)
+
+	aliases := sshAliases(c.Hosts)
+	if len(aliases) == 0 {
+		return CheckResult{
+			Name:    c.Name(),
+			Status:  StatusPass,
+			Message: "No SSH aliases to check",
+		}
+	}
+
+	hosts, err := sshutil.LookupSSHConfigFile(configPath, aliases)
+	if err != nil {
+		return CheckResult{
+			Name:       c.Name(),
+			Status:     StatusWarn,
+			Message:    fmt.Sprintf("Cannot read %s", display),
+			Suggestion: err.Error(),
+		}
+	}
+	if hosts == nil {
+		return CheckResult{
+			Name:    c.Name(),
+			Status:  StatusPass,
+			Message: fmt.Sprintf("No %s to check", display),
+		}
+	}
+
+	issues := sshConfigIssues(hosts, aliases, home)
+	switch len(issues) {
+	case 0:
+		return CheckResult{
+			Name:    c.Name(),
+			Status:  StatusPass,
+			Message: fmt.Sprintf("%s OK for %d alias%s", display, len(aliases), pluralizeES(len(aliases))),
+		}
+	case 1:
+		return CheckResult{
+			Name:       c.Name(),
+			Status:     StatusWarn,
+			Message:    issues[0].Problem(),
+			Suggestion: issues[0].fix,
+		}
+	}
+
+	var lines []string
+	for _, issue := range issues {
+		lines = append(lines, issue.Problem(), "  "+issue.fix)
+	}
+	return CheckResult{
+		Name:       c.Name(),
+		Status:     StatusWarn,
+		Message:    fmt.Sprintf("%d problems in %s", len(issues), display),
+		Suggestion: strings.Join(lines, "\n"),
+	}
+}
+
+func (c *SSHConfigCheck) Fix() error {
+	// Editing the user's SSH config is theirs to do
+	return nil
+}
+
+// NewSSHConfigChecks creates the SSH config checks for the configured hosts.
+func NewSSHConfigChecks(hosts map[string]config.Host) []Check {
+	return []Check{&SSHConfigCheck{Hosts: hosts}}
+}
+
+// sshConfigIssue is one problem with the SSH config. Problems on a line
+// shared by several aliases, like one under "Host *", are reported once.
+type sshConfigIssue struct {
+	key     string
+	problem string
+	fix     string
+	shared  bool // The problem doesn't name the alias, so list the ones it affects
+	aliases []string
+}
+
+// Problem returns the problem, with the aliases it affects.
+func (i *sshConfigIssue) Problem() string {
+	if !i.shared {
+		return i.problem
+	}
+	return fmt.Sprintf("%s (%s)", i.problem, strings.Join(i.aliases, ", "))
+}
+
+// cumulativeKeywords are the keywords every block's values add to, rather
+// than the first value winning, so setting them twice isn't a conflict.
+var cumulativeKeywords = map[string]bool{
+	"identityfile":    true,
+	"certificatefile": true,
+	"localforward":    true,
+	"remoteforward":   true,
+	"dynamicforward":  true,
+	"sendenv":         true,
+	"setenv":          true,
+}
+
+// sshSunPathMax is the longest path a Unix socket can have, including its
+// terminating NUL.
+func sshSunPathMax() int {
+	if runtime.GOOS == "linux" {
+		return 108
+	}
+	return 104
+}
+
+// sshControlPathSuffix is the ".XXXXXXXXXXXXXXXX" ssh adds to a ControlPath
+// while it sets the socket up.
+const sshControlPathSuffix = 17
+
+// sshConfigIssues finds the problems with how the config sets up aliases.
+func sshConfigIssues(hosts map[string]sshutil.SSHConfigHost, aliases []string, home string) []*sshConfigIssue {
+	var issues []*sshConfigIssue
+	byKey := make(map[string]*sshConfigIssue)
+	add := func(alias string, issue *sshConfigIssue) {
+		if existing, ok := byKey[issue.key]; ok {
+			existing.aliases = append(existing.aliases, alias)
+			return
+		}
+		issue.aliases = []string{alias}
+		byKey[issue.key] = issue
+		issues = append(issues, issue)
+	}
+	where := func(v sshutil.SSHConfigValue) string {
+		return fmt.Sprintf("%s:%d", tildePath(v.File, home), v.Line)
+	}
+
+	for _, alias := range aliases {
+		h := hosts[alias]
+
+		// An alias that isn't an address connects to the alias itself
+		if _, ok := h.First("HostName"); !ok && h.Defined.Line > 0 && !looksLikeAddress(alias) {
+			add(alias, &sshConfigIssue{
+				key:     "hostname:" + alias,
+				problem: fmt.Sprintf("Host %s at %s has no HostName, so ssh looks up '%s' as a hostname", alias, where(h.Defined), alias),
+				fix:     fmt.Sprintf("Fix: add 'HostName <address>' under 'Host %s'", h.Defined.Value),
+			})
+		}
+
+		// A Match block that sets something an earlier block already did
+		// changes nothing, since ssh keeps the first value. A later block
+		// for every host is just setting defaults.
+		keywords := make([]string, 0, len(h.Values))
+		for k := range h.Values {
+			keywords = append(keywords, k)
+		}
+		sort.Strings(keywords)
+		for _, k := range keywords {
+			if cumulativeKeywords[k] {
+				continue
+			}
+			values := h.Values[k]
+			first := values[0]
+			for _, v := range values[1:] {
+				if strings.EqualFold(v.Value, first.Value) || (!v.Match && !first.Match) || v.Everyone {
+					continue
+				}
+				add(alias, &sshConfigIssue{
+					key: fmt.Sprintf("match:%s:%d:%s:%d", first.File, first.Line, v.File, v.Line),
+					problem: fmt.Sprintf("%s at %s sets %s %s, but %s already set it to %s, and ssh keeps the first",
+						blockKind(v), where(v), v.Keyword, v.Value, where(first), first.Value),
+					fix:    fmt.Sprintf("Fix: move the block at %s above line %d, or remove one of the two %s lines", where(v), first.Line, v.Keyword),
+					shared: true,
+				})
+			}
+		}
+
+		for _, v := range h.Values["identityfile"] {
+			keyPath, ok := expandSSHTokens(v.Value, alias, h, home)
+			if !ok || strings.EqualFold(v.Value, "none") {
+				continue
+			}
+			if _, err := os.Stat(keyPath); err == nil {
+				continue
+			}
+			add(alias, &sshConfigIssue{
+				key:     fmt.Sprintf("identity:%s:%d", v.File, v.Line),
+				problem: fmt.Sprintf("IdentityFile %s at %s doesn't exist", v.Value, where(v)),
+				fix:     fmt.Sprintf("Fix: correct the path, or create the key: ssh-keygen -t ed25519 -f %s", tildePath(keyPath, home)),
+				shared:  true,
+			})
+		}
+
+		if v, ok := h.First("ControlPath"); ok && !strings.EqualFold(v.Value, "none") {
+			socket, ok := expandSSHTokens(v.Value, alias, h, home)
+			if !ok || len(socket)+sshControlPathSuffix < sshSunPathMax() {
+				continue
+			}
+			add(alias, &sshConfigIssue{
+				key: "controlpath:" + alias,
+				problem: fmt.Sprintf("ControlPath at %s is too long for %s: %s is %d characters, and ssh adds %d more of the %d a socket path allows",
+					where(v), alias, tildePath(socket, home), len(socket), sshControlPathSuffix, sshSunPathMax()-1),
+				fix: "Fix: use a shorter path such as 'ControlPath ~/.ssh/cm-%C' (%C is a short hash of the connection)",
+			})
+		}
+	}
+	return issues
+}
+
+// sshAliases returns the hosts the configured SSH entries connect to, sorted.
+func sshAliases(hosts map[string]config.Host) []string {
+	seen := make(map[string]bool)
+	var aliases []string
+	for _, h := range hosts {
+		for _, target := range h.SSH {
+			alias := sshutil.TargetHost(target)
+			if alias != "" && !seen[alias] {
+				seen[alias] = true
+				aliases = append(aliases, alias)
+			}
+		}
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+// looksLikeAddress reports whether a host is an address or a qualified
+// name, rather than an alias that needs a HostName.
+func looksLikeAddress(host string) bool {
+	return net.ParseIP(host) != nil || strings.Contains(host, ".") || host == "localhost"
+}
+
+func blockKind(v sshutil.SSHConfigValue) string {
+	if v.Match {
+		return "Match"
+	}
+	return "Host"
+}
+
+// expandSSHTokens expands the ~ and % tokens ssh expands in IdentityFile
+// and ControlPath for alias. Returns false for a token it doesn't know.
+func expandSSHTokens(value, alias string, h sshutil.SSHConfigHost, home string) (string, bool) {
+	hostname := alias
+	if v, ok := h.First("HostName"); ok {
+		hostname = strings.ReplaceAll(v.Value, "%h", alias)
+	}
+	port := "22"
+	if v, ok := h.First("Port"); ok {
+		port = v.Value
+	}
+	localUser := os.Getenv("USER")
+	user := localUser
+	if v, ok := h.First("User"); ok {
+		user = v.Value
+	}
+	localHost, _ := os.Hostname()
+
+	if strings.HasPrefix(value, "~/") {
+		value = filepath.Join(home, value[2:])
+	}
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '%' {
+			b.WriteByte(value[i])
+			continue
+		}
+		if i+1 >= len(value) {
+			return "", false
+		}
+		i++
+		switch value[i] {
+		case '%':
+			b.WriteByte('%')
+		case 'h':
+			b.WriteString(hostname)
+		case 'n':
+			b.WriteString(alias)
+		case 'p':
+			b.WriteString(port)
+		case 'r':
+			b.WriteString(user)
+		case 'u':
+			b.WriteString(localUser)
+		case 'd':
+			b.WriteString(home)
+		case 'i':
+			b.WriteString(strconv.Itoa(os.Getuid()))
+		case 'l':
+			b.WriteString(localHost)
+		case 'L':
+			short, _, _ := strings.Cut(localHost, ".")
+			b.WriteString(short)
+		case 'C':
+			// A SHA1 hash of the connection, written in hex
+			b.WriteString(strings.Repeat("0", 40))
+		default:
+			return "", false
+		}
+	}
+	return b.String(), true
+}
+
+// tildePath shortens a path in the home directory to ~/...
+func tildePath(path, home string) string {
+	if home != "" && strings.HasPrefix(path, home+string(filepath.Separator)) {
+		return "~" + path[len(home):]
+	}
+	return path
+}
+
+func pluralizeES(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "es"
+}
@@ -0,0 +1,133 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/pkg/sshutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSSHConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestSSHConfigCheck_Clean(t *testing.T) {
+	key := filepath.Join(t.TempDir(), "id_ed25519")
+	require.NoError(t, os.WriteFile(key, []byte("key"), 0600))
+	path := writeSSHConfig(t, `Host mini
+    HostName 192.168.1.10
+    IdentityFile `+key+`
+    ControlPath /tmp/cm-%C
+`)
+
+	check := &SSHConfigCheck{
+		Hosts:      map[string]config.Host{"mini": {SSH: []string{"riley@mini", "mini.tailnet.ts.net"}}},
+		ConfigPath: path,
+	}
+	result := check.Run()
+
+	assert.Equal(t, "ssh_config", result.Name)
+	assert.Equal(t, StatusPass, result.Status)
+	assert.Contains(t, result.Message, "OK for 2 aliases")
+}
+
+func TestSSHConfigCheck_NoConfig(t *testing.T) {
+	check := &SSHConfigCheck{
+		Hosts:      map[string]config.Host{"mini": {SSH: []string{"mini"}}},
+		ConfigPath: filepath.Join(t.TempDir(), "config"),
+	}
+	assert.Equal(t, StatusPass, check.Run().Status)
+}
+
+func TestSSHConfigCheck_MissingHostName(t *testing.T) {
+	path := writeSSHConfig(t, `Host mini
+    User riley
+
+Host gpu.example.com
+    User ubuntu
+`)
+
+	check := &SSHConfigCheck{
+		Hosts: map[string]config.Host{
+			"mini": {SSH: []string{"mini"}},
+			"gpu":  {SSH: []string{"gpu.example.com"}},
+		},
+		ConfigPath: path,
+	}
+	result := check.Run()
+
+	assert.Equal(t, StatusWarn, result.Status)
+	assert.Contains(t, result.Message, "Host mini at "+path+":1 has no HostName")
+	assert.Equal(t, "Fix: add 'HostName <address>' under 'Host mini'", result.Suggestion)
+}
+
+func TestSSHConfigCheck_Problems(t *testing.T) {
+	long := "/tmp/" + strings.Repeat("x", 90) + "/%h"
+	path := writeSSHConfig(t, `Host mini
+    HostName 192.168.1.10
+    User riley
+    IdentityFile ~/.ssh/does-not-exist-rr-test
+
+Host gpu
+    HostName gpu-box-with-a-long-name.example.com
+    ControlPath `+long+`
+
+Match host mini
+    User deploy
+
+Match all
+    User fallback
+`)
+
+	check := &SSHConfigCheck{
+		Hosts: map[string]config.Host{
+			"mini": {SSH: []string{"mini"}},
+			"gpu":  {SSH: []string{"gpu"}},
+		},
+		ConfigPath: path,
+	}
+	result := check.Run()
+
+	assert.Equal(t, StatusWarn, result.Status)
+	assert.Equal(t, "3 problems in "+path, result.Message)
+	lines := strings.Split(result.Suggestion, "\n")
+	require.Len(t, lines, 6)
+
+	assert.Contains(t, lines[0], "ControlPath at "+path+":8 is too long for gpu")
+	assert.Contains(t, lines[1], "ControlPath ~/.ssh/cm-%C")
+
+	assert.Equal(t, "Match at "+path+":11 sets User deploy, but "+path+":3 already set it to riley, and ssh keeps the first (mini)", lines[2])
+	assert.Equal(t, "  Fix: move the block at "+path+":11 above line 3, or remove one of the two User lines", lines[3])
+
+	assert.Contains(t, lines[4], "IdentityFile ~/.ssh/does-not-exist-rr-test at "+path+":4 doesn't exist (mini)")
+	assert.Contains(t, lines[5], "ssh-keygen -t ed25519 -f ~/.ssh/does-not-exist-rr-test")
+}
+
+func TestExpandSSHTokens(t *testing.T) {
+	path := writeSSHConfig(t, `Host mini
+    HostName %h.lan
+    Port 2222
+    User riley
+`)
+	hosts, err := sshutil.LookupSSHConfigFile(path, []string{"mini"})
+	require.NoError(t, err)
+
+	got, ok := expandSSHTokens("~/.ssh/cm-%r@%h:%p-%n%%", "mini", hosts["mini"], "/home/me")
+	require.True(t, ok)
+	assert.Equal(t, "/home/me/.ssh/cm-riley@mini.lan:2222-mini%", got)
+
+	got, ok = expandSSHTokens("/tmp/%C", "mini", hosts["mini"], "/home/me")
+	require.True(t, ok)
+	assert.Len(t, got, len("/tmp/")+40)
+
+	_, ok = expandSSHTokens("/tmp/%T", "mini", hosts["mini"], "/home/me")
+	assert.False(t, ok, "unknown token")
+}
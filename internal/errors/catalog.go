@@ -0,0 +1,386 @@
+package errors
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// Stable error IDs. These are printed alongside user-facing failures and
+// included in JSON output so people (and automation) can look them up with
+// 'rr explain <ID>'. IDs are never reused or renumbered once published.
+const (
+	IDConfigGeneric      = "RR-CONFIG-001"
+	IDConfigNotFound     = "RR-CONFIG-002"
+	IDConfigInvalid      = "RR-CONFIG-003"
+	IDConfigHostNotFound = "RR-CONFIG-004"
+	IDConfigNeedsInput   = "RR-CONFIG-005"
+
+	IDSSHGeneric     = "RR-SSH-001"
+	IDSSHTimeout     = "RR-SSH-002"
+	IDSSHAuth        = "RR-SSH-003"
+	IDSSHHostKey     = "RR-SSH-004"
+	IDSSHRefused     = "RR-SSH-005"
+	IDSSHDNS         = "RR-SSH-006"
+	IDSSHUnreachable = "RR-SSH-007"
+
+	IDSyncGeneric      = "RR-SYNC-001"
+	IDSyncRsyncMissing = "RR-SYNC-002"
+	IDSyncPullNoMatch  = "RR-SYNC-003"
+	IDSyncPullTooLarge = "RR-SYNC-004"
+	IDSyncRemoteEdits  = "RR-SYNC-005"
+	IDSyncPullNoSpace  = "RR-SYNC-006"
+
+	IDLockGeneric    = "RR-LOCK-001"
+	IDLockTimeout    = "RR-LOCK-002"
+	IDLockNoCapacity = "RR-LOCK-003"
+
+	IDExecGeneric        = "RR-EXEC-001"
+	IDExecMissingTools   = "RR-EXEC-002"
+	IDExecUnknownCommand = "RR-EXEC-003"
+
+	IDInternal = "RR-INTERNAL-001"
+)
+
+// CodeInfo is the long-form documentation for a stable error ID.
+type CodeInfo struct {
+	ID          string
+	Category    string
+	Title       string
+	Explanation string
+	Remediation []string
+}
+
+// catalog holds the documentation for every published error ID.
+var catalog = map[string]CodeInfo{
+	IDConfigGeneric: {
+		ID:          IDConfigGeneric,
+		Category:    ErrConfig,
+		Title:       "Configuration error",
+		Explanation: "rr couldn't use your configuration. This covers config problems that don't have a more specific code.",
+		Remediation: []string{
+			"Read the message above - it names the setting that's off",
+			"Run 'rr doctor' to check both .rr.yaml and ~/.rr/config.yaml",
+		},
+	},
+	IDConfigNotFound: {
+		ID:          IDConfigNotFound,
+		Category:    ErrConfig,
+		Title:       "Config file not found",
+		Explanation: "No .rr.yaml was found in the current directory or its parents (up to the git root or your home directory, or past the git root to a workspace config with discovery: workspace), or the path passed to --config doesn't exist.",
+		Remediation: []string{
+			"Run 'rr init' to create a .rr.yaml for this project",
+			"cd into the project directory before running rr",
+			"Double-check the path passed to --config",
+		},
+	},
+	IDConfigInvalid: {
+		ID:          IDConfigInvalid,
+		Category:    ErrConfig,
+		Title:       "Config file is invalid",
+		Explanation: "The config file was found but couldn't be parsed or failed validation: bad YAML, an unknown field, or a value of the wrong type.",
+		Remediation: []string{
+			"Fix the field named in the message above",
+			"Check indentation - YAML is whitespace sensitive",
+			"See docs/configuration.md for the full list of options",
+		},
+	},
+	IDConfigHostNotFound: {
+		ID:          IDConfigHostNotFound,
+		Category:    ErrConfig,
+		Title:       "Host not found",
+		Explanation: "A host was referenced (by --host, the project 'hosts' list, or a task) that isn't defined in ~/.rr/config.yaml.",
+		Remediation: []string{
+			"Run 'rr host list' to see configured hosts",
+			"Add the host with 'rr host add'",
+			"Fix the spelling in .rr.yaml or on the command line",
+		},
+	},
+	IDConfigNeedsInput: {
+		ID:          IDConfigNeedsInput,
+		Category:    ErrConfig,
+		Title:       "Needs input it can't ask for",
+		Explanation: "rr needed an answer it would normally prompt for - a host to pick, a param value, a confirmation - but prompts are off (--non-interactive or RR_NON_INTERACTIVE=true) or there's no terminal to ask on.",
+		Remediation: []string{
+			"Pass the flag the message above names, e.g. --host, --param or --yes",
+			"Run the command from a terminal without --non-interactive to be asked",
+		},
+	},
+	IDSSHGeneric: {
+		ID:          IDSSHGeneric,
+		Category:    ErrSSH,
+		Title:       "SSH connection failed",
+		Explanation: "rr couldn't talk to the remote host over SSH. This covers SSH problems that don't have a more specific code.",
+		Remediation: []string{
+			"Try connecting directly: ssh <alias>",
+			"Run 'rr doctor' to diagnose connection issues",
+		},
+	},
+	IDSSHTimeout: {
+		ID:          IDSSHTimeout,
+		Category:    ErrSSH,
+		Title:       "SSH connection timed out",
+		Explanation: "The host didn't answer within the probe timeout. It may be asleep, off the network, or behind a firewall that drops packets.",
+		Remediation: []string{
+			"Check the host is powered on and reachable: ping <hostname>",
+			"If you're off the LAN, add a VPN/Tailscale alias to the host's ssh list",
+			"Increase the timeout with --probe-timeout 10s",
+		},
+	},
+	IDSSHAuth: {
+		ID:          IDSSHAuth,
+		Category:    ErrSSH,
+		Title:       "SSH authentication failed",
+		Explanation: "The host was reachable but rejected every key rr offered. Usually the public key isn't in the remote's authorized_keys, or ssh-agent isn't holding the right key.",
+		Remediation: []string{
+			"Deploy your key: ssh-copy-id <alias>",
+			"Check ssh-agent has your key loaded: ssh-add -l",
+			"Run 'rr setup <alias>' to walk through key setup",
+		},
+	},
+	IDSSHHostKey: {
+		ID:          IDSSHHostKey,
+		Category:    ErrSSH,
+		Title:       "Host key verification failed",
+		Explanation: "The host's key isn't in known_hosts yet, or it changed since the last connection. A changed key can mean the machine was reinstalled - or that something is intercepting the connection.",
+		Remediation: []string{
+			"For a new host, accept the key: ssh -o StrictHostKeyChecking=accept-new <alias> exit",
+			"If the host was reinstalled, remove the old key: ssh-keygen -R <hostname>",
+		},
+	},
+	IDSSHRefused: {
+		ID:          IDSSHRefused,
+		Category:    ErrSSH,
+		Title:       "SSH connection refused",
+		Explanation: "The host is up but nothing is listening on the SSH port, or a firewall actively rejected the connection.",
+		Remediation: []string{
+			"Make sure sshd is running on the remote",
+			"On macOS, enable Remote Login in System Settings > General > Sharing",
+			"Check the port in your SSH config",
+		},
+	},
+	IDSSHDNS: {
+		ID:          IDSSHDNS,
+		Category:    ErrSSH,
+		Title:       "Hostname not found",
+		Explanation: "The hostname couldn't be resolved. It may be misspelled, or it's a .local / Tailscale name that only resolves on a particular network.",
+		Remediation: []string{
+			"Check the spelling in ~/.rr/config.yaml and ~/.ssh/config",
+			"Try resolving it yourself: host <hostname>",
+		},
+	},
+	IDSSHUnreachable: {
+		ID:          IDSSHUnreachable,
+		Category:    ErrSSH,
+		Title:       "Host unreachable",
+		Explanation: "The network has no route to the host, or the connection was reset mid-handshake.",
+		Remediation: []string{
+			"Check you're on the right network or VPN",
+			"Add a fallback alias (e.g., Tailscale) to the host's ssh list",
+		},
+	},
+	IDSyncGeneric: {
+		ID:          IDSyncGeneric,
+		Category:    ErrSync,
+		Title:       "File sync failed",
+		Explanation: "rsync couldn't copy files to or from the remote. The rsync output in the message usually says why.",
+		Remediation: []string{
+			"Check disk space and permissions on the remote directory",
+			"Preview the transfer with 'rr sync --dry-run'",
+		},
+	},
+	IDSyncRsyncMissing: {
+		ID:          IDSyncRsyncMissing,
+		Category:    ErrSync,
+		Title:       "rsync not installed",
+		Explanation: "rr uses rsync for file transfer and it must be installed on both your machine and the remote.",
+		Remediation: []string{
+			"macOS: brew install rsync",
+			"Debian/Ubuntu: apt install rsync",
+			"RHEL/Fedora: yum install rsync",
+		},
+	},
+	IDSyncPullNoMatch: {
+		ID:          IDSyncPullNoMatch,
+		Category:    ErrSync,
+		Title:       "Pull pattern matched nothing",
+		Explanation: "A pull path or glob didn't match any files in the remote project directory, so there was nothing to download. Nothing was pulled.",
+		Remediation: []string{
+			"Check the command actually produced the file (it may have failed early)",
+			"Pull paths are relative to the host's dir - fix the pattern in the task's pull list",
+		},
+	},
+	IDSyncPullTooLarge: {
+		ID:          IDSyncPullTooLarge,
+		Category:    ErrSync,
+		Title:       "Pull exceeds max_size",
+		Explanation: "What a pull item matched on the remote is bigger than its max_size, so rr aborted the pull before transferring anything.",
+		Remediation: []string{
+			"Narrow the pattern so it only matches the artifacts you need",
+			"Raise max_size on the pull item if you really want all of it",
+		},
+	},
+	IDSyncRemoteEdits: {
+		ID:          IDSyncRemoteEdits,
+		Category:    ErrSync,
+		Title:       "Remote dir has uncommitted changes",
+		Explanation: "The host's dir is a git checkout rr didn't create, and it has uncommitted changes that don't match your local checkout. Syncing would overwrite them, so rr stopped before transferring anything.",
+		Remediation: []string{
+			"Commit or stash the changes on the host if you want to keep them",
+			"Point the host's dir at a directory rr owns (the default is ~/rr/${PROJECT})",
+			"Pass --force to sync anyway",
+		},
+	},
+	IDSyncPullNoSpace: {
+		ID:          IDSyncPullNoSpace,
+		Category:    ErrSync,
+		Title:       "Not enough local disk for a pull",
+		Explanation: "What a pull matched on the remote, measured before transferring anything, is bigger than the free space (or has more files than the free inodes) on the disk it's being pulled to. rr aborted the pull so it couldn't run out of space partway and leave half-written files behind.",
+		Remediation: []string{
+			"Free up space on this machine and run the pull again",
+			"Set dest on the pull item to a disk with more room",
+			"Narrow the pattern, or cap it with max_size",
+		},
+	},
+	IDLockGeneric: {
+		ID:          IDLockGeneric,
+		Category:    ErrLock,
+		Title:       "Lock error",
+		Explanation: "rr couldn't create, read, or release the project lock on the remote.",
+		Remediation: []string{
+			"Check the lock directory (lock.dir) is writable on the remote",
+			"Release a stuck lock with 'rr unlock'",
+		},
+	},
+	IDLockTimeout: {
+		ID:          IDLockTimeout,
+		Category:    ErrLock,
+		Title:       "Lock wait timed out",
+		Explanation: "Someone else held the lock on the remote for longer than lock.timeout. Locks stop two runs from stepping on each other's files.",
+		Remediation: []string{
+			"Wait for the other run to finish",
+			"Add more hosts so rr can load balance around busy ones",
+			"If the holder crashed, release the lock with 'rr unlock'",
+		},
+	},
+	IDLockNoCapacity: {
+		ID:          IDLockNoCapacity,
+		Category:    ErrLock,
+		Title:       "Host fully reserved",
+		Explanation: "The task reserves CPUs or memory, and the other runs' reservations leave too little of the host free. rr skips such hosts when it can pick another one.",
+		Remediation: []string{
+			"Wait for the runs holding reservations to finish ('rr monitor' shows them)",
+			"Lower the task's reserve, or run it on a bigger host",
+			"Reservations of runs that crashed expire after lock.stale",
+		},
+	},
+	IDExecGeneric: {
+		ID:          IDExecGeneric,
+		Category:    ErrExec,
+		Title:       "Command error",
+		Explanation: "rr couldn't run the requested command. This covers execution problems that don't have a more specific code.",
+		Remediation: []string{
+			"Check the command output above for details",
+			"Run with --pretty for a more readable report",
+		},
+	},
+	IDExecMissingTools: {
+		ID:          IDExecMissingTools,
+		Category:    ErrExec,
+		Title:       "Required tools missing",
+		Explanation: "One or more tools listed under 'require' aren't available on the remote host.",
+		Remediation: []string{
+			"Run 'rr provision' to install them",
+			"Pass --skip-requirements to bypass the check",
+		},
+	},
+	IDExecUnknownCommand: {
+		ID:          IDExecUnknownCommand,
+		Category:    ErrExec,
+		Title:       "Unknown command",
+		Explanation: "The command isn't a built-in rr command or a task defined in .rr.yaml.",
+		Remediation: []string{
+			"Run 'rr --help' for built-in commands",
+			"Run 'rr tasks' to list tasks from .rr.yaml",
+		},
+	},
+	IDInternal: {
+		ID:          IDInternal,
+		Category:    "INTERNAL",
+		Title:       "Unexpected error",
+		Explanation: "Something failed that rr doesn't have a specific explanation for yet.",
+		Remediation: []string{
+			"Re-run with --verbose to see more detail",
+			"If it keeps happening, open an issue at https://github.com/rileyhilliard/rr/issues with the output",
+		},
+	},
+}
+
+// categoryDefaults maps each error category to its generic ID, used when an
+// error doesn't carry a more specific one.
+var categoryDefaults = map[string]string{
+	ErrConfig: IDConfigGeneric,
+	ErrSSH:    IDSSHGeneric,
+	ErrSync:   IDSyncGeneric,
+	ErrLock:   IDLockGeneric,
+	ErrExec:   IDExecGeneric,
+}
+
+// Lookup returns the documentation for an error ID. Matching is case-insensitive.
+func Lookup(id string) (CodeInfo, bool) {
+	info, ok := catalog[strings.ToUpper(strings.TrimSpace(id))]
+	return info, ok
+}
+
+// Catalog returns every documented error ID, sorted by ID.
+func Catalog() []CodeInfo {
+	infos := make([]CodeInfo, 0, len(catalog))
+	for _, info := range catalog {
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ID < infos[j].ID
+	})
+	return infos
+}
+
+// identifier is implemented by error types outside this package (like
+// host.ProbeError) that know their own stable ID.
+type identifier interface {
+	ErrorID() string
+}
+
+// IDOf returns the stable error ID for err. An ID assigned anywhere in the
+// chain wins; otherwise structured errors get their category's generic ID and
+// anything else gets IDInternal.
+func IDOf(err error) string {
+	if err == nil {
+		return ""
+	}
+	if id := explicitID(err); id != "" {
+		return id
+	}
+	var rrErr *Error
+	if errors.As(err, &rrErr) {
+		return rrErr.ErrorID()
+	}
+	return IDInternal
+}
+
+// explicitID walks the error chain and returns the first explicitly assigned ID.
+func explicitID(err error) string {
+	for err != nil {
+		switch e := err.(type) {
+		case *Error:
+			if e.ID != "" {
+				return e.ID
+			}
+		case identifier:
+			if id := e.ErrorID(); id != "" {
+				return id
+			}
+		}
+		err = errors.Unwrap(err)
+	}
+	return ""
+}
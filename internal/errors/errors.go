@@ -25,6 +25,7 @@ const (
 //	  <How to fix it - actionable steps>
 type Error struct {
 	Code       string
+	ID         string // Stable ID like RR-SSH-003; empty means the category's generic ID
 	Message    string
 	Suggestion string
 	Cause      error
@@ -67,12 +68,34 @@ func NewNotImplemented(command string) *Error {
 	}
 }
 
+// WithID sets the stable error ID and returns the error for chaining.
+func (e *Error) WithID(id string) *Error {
+	e.ID = id
+	return e
+}
+
+// ErrorID returns the stable error ID, falling back to the generic ID for the
+// error's category when no specific ID was assigned.
+func (e *Error) ErrorID() string {
+	if e.ID != "" {
+		return e.ID
+	}
+	if id, ok := categoryDefaults[e.Code]; ok {
+		return id
+	}
+	return IDInternal
+}
+
+// FailSymbol starts the first line of every Error. The CLI swaps it for
+// plain text in accessible mode.
+var FailSymbol = "✗"
+
 // Error implements the error interface with formatted output following ARCHITECTURE.md design.
 func (e *Error) Error() string {
 	var b strings.Builder
 
 	// First line: failure symbol + main message
-	b.WriteString(fmt.Sprintf("✗ %s\n", e.Message))
+	b.WriteString(fmt.Sprintf("%s %s\n", FailSymbol, e.Message))
 
 	// Include cause if present (why it failed)
 	if e.Cause != nil {
@@ -346,3 +346,101 @@ func TestGetExitCode_WrappedError(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, 99, code)
 }
+
+func TestErrorID(t *testing.T) {
+	t.Run("category default", func(t *testing.T) {
+		err := New(ErrSSH, "Connection failed", "")
+		assert.Equal(t, IDSSHGeneric, err.ErrorID())
+	})
+
+	t.Run("explicit ID wins", func(t *testing.T) {
+		err := New(ErrSSH, "Auth failed", "").WithID(IDSSHAuth)
+		assert.Equal(t, IDSSHAuth, err.ErrorID())
+	})
+
+	t.Run("unknown category", func(t *testing.T) {
+		err := New("BOGUS", "Something", "")
+		assert.Equal(t, IDInternal, err.ErrorID())
+	})
+}
+
+type fakeIdentified struct{ id string }
+
+func (f *fakeIdentified) Error() string   { return "fake" }
+func (f *fakeIdentified) ErrorID() string { return f.id }
+
+func TestIDOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "nil error",
+			err:  nil,
+			want: "",
+		},
+		{
+			name: "plain error",
+			err:  errors.New("boom"),
+			want: IDInternal,
+		},
+		{
+			name: "structured error uses category default",
+			err:  New(ErrLock, "Lock failed", ""),
+			want: IDLockGeneric,
+		},
+		{
+			name: "explicit ID on cause beats outer category default",
+			err:  WrapWithCode(New(ErrConfig, "missing", "").WithID(IDConfigNotFound), ErrExec, "Run failed", ""),
+			want: IDConfigNotFound,
+		},
+		{
+			name: "explicit ID on outer error wins",
+			err:  WrapWithCode(New(ErrConfig, "missing", "").WithID(IDConfigNotFound), ErrSSH, "Dial failed", "").WithID(IDSSHAuth),
+			want: IDSSHAuth,
+		},
+		{
+			name: "external identifier in chain",
+			err:  Wrap(&fakeIdentified{id: IDSSHTimeout}, "Connect failed"),
+			want: IDSSHTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IDOf(tt.err))
+		})
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	infos := Catalog()
+	require.NotEmpty(t, infos)
+
+	for i, info := range infos {
+		assert.True(t, strings.HasPrefix(info.ID, "RR-"), "ID %q should start with RR-", info.ID)
+		assert.NotEmpty(t, info.Title, "%s should have a title", info.ID)
+		assert.NotEmpty(t, info.Explanation, "%s should have an explanation", info.ID)
+		assert.NotEmpty(t, info.Remediation, "%s should have remediation steps", info.ID)
+		if i > 0 {
+			assert.Less(t, infos[i-1].ID, info.ID, "catalog should be sorted")
+		}
+	}
+
+	// Every category has a generic fallback that's documented
+	for category, id := range categoryDefaults {
+		info, ok := Lookup(id)
+		require.True(t, ok, "default ID for %s should be documented", category)
+		assert.Equal(t, category, info.Category)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	info, ok := Lookup("rr-ssh-003")
+	require.True(t, ok, "lookup should be case-insensitive")
+	assert.Equal(t, IDSSHAuth, info.ID)
+
+	_, ok = Lookup("RR-NOPE-999")
+	assert.False(t, ok)
+}
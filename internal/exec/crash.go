@@ -0,0 +1,176 @@
+package exec
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+)
+
+// crashProbeScript prints what a host recorded about a process that just
+// crashed, as sections headed "==> name <==": the OS, then on Linux the
+// tail of the kernel log, where core dumps go, and recent systemd-coredump
+// entries, or on macOS where core dumps go and the crash reports written
+// since the run started (%[1]d is the minutes to look back). It runs in the
+// project directory, where a plain core_pattern leaves core files.
+const crashProbeScript = `echo "==> system <=="; uname -s; ` +
+	`if [ "$(uname -s)" = Darwin ]; then ` +
+	`echo "==> core_pattern.txt <=="; ` +
+	`echo "kern.corefile: $(sysctl -n kern.corefile 2>/dev/null)"; ` +
+	`echo "ulimit -c: $(ulimit -c)"; ` +
+	`ls -lt core core.* /cores/core.* 2>/dev/null | head -n 5; ` +
+	`find ~/Library/Logs/DiagnosticReports /Library/Logs/DiagnosticReports -maxdepth 1 -type f \( -name '*.ips' -o -name '*.crash' \) -mmin -%[1]d 2>/dev/null | head -n 5 | ` +
+	`while IFS= read -r f; do echo "==> $(basename "$f") <=="; head -c 262144 "$f"; echo; done; ` +
+	`else ` +
+	`echo "==> dmesg.txt <=="; ` +
+	`{ dmesg -T 2>/dev/null || dmesg 2>/dev/null || journalctl -k -n 100 --no-pager -q 2>/dev/null || echo "rr: couldn't read the kernel log (kernel.dmesg_restrict is probably set)"; } | tail -n 100; ` +
+	`echo "==> core_pattern.txt <=="; ` +
+	`echo "core_pattern: $(cat /proc/sys/kernel/core_pattern 2>/dev/null)"; ` +
+	`echo "ulimit -c: $(ulimit -c)"; ` +
+	`ls -lt core core.* 2>/dev/null | head -n 5; ` +
+	`if command -v coredumpctl >/dev/null 2>&1; then ` +
+	`echo "==> coredumpctl.txt <=="; coredumpctl list --no-pager --since "-%[1]dmin" 2>&1 | tail -n 20; ` +
+	`fi; ` +
+	`fi; true`
+
+// crashSectionPattern matches a section header in the crash probe's output.
+var crashSectionPattern = regexp.MustCompile(`^==> (.+) <==$`)
+
+var (
+	// crashOutputPattern matches what shells and runtimes print when a
+	// process dies on a signal.
+	crashOutputPattern = regexp.MustCompile(`(?m)(Segmentation fault|core dumped|^Killed$|Bus error|Illegal instruction|Floating point exception)`)
+
+	// crashLogPattern matches kernel log lines about a crash or an OOM kill.
+	crashLogPattern = regexp.MustCompile(`segfault at|general protection|traps:|Out of memory|oom-kill|Killed process`)
+
+	// unsafeFileChars are replaced in section names before they become
+	// file names.
+	unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+)
+
+// crashProbeFiles are the sections the probe itself names. Other sections
+// are named after files on the host (macOS crash reports), so they get
+// hostFilePrefix to keep them from replacing rr's own files.
+var crashProbeFiles = map[string]bool{
+	"dmesg.txt":        true,
+	"core_pattern.txt": true,
+	"coredumpctl.txt":  true,
+}
+
+// hostFilePrefix starts the name of every file named by the host.
+const hostFilePrefix = "host-"
+
+// crashSignals are the exit codes of processes killed by a signal that
+// points at a crash or an OOM kill: 128 + SIGILL, SIGABRT, SIGBUS, SIGFPE,
+// SIGKILL, and SIGSEGV.
+var crashSignals = map[int]string{
+	132: "SIGILL",
+	134: "SIGABRT",
+	135: "SIGBUS",
+	136: "SIGFPE",
+	137: "SIGKILL",
+	139: "SIGSEGV",
+}
+
+// CrashFile is one piece of crash evidence, saved as its own file.
+type CrashFile struct {
+	Name    string // File name, e.g. "dmesg.txt", or "host-" and a macOS crash report's name
+	Content string
+}
+
+// CrashEvidence is what a host recorded about a crash, as collected by
+// CollectCrashEvidence.
+type CrashEvidence struct {
+	OS         string // uname -s, e.g. "Linux" or "Darwin"
+	Files      []CrashFile
+	Highlights []string // Kernel log lines about the crash, newest last
+}
+
+// CrashSignal returns the signal that an exit code says killed the command,
+// e.g. "SIGSEGV" for 139, or "" if it doesn't look like a crash.
+func CrashSignal(exitCode int) string {
+	return crashSignals[exitCode]
+}
+
+// LooksLikeCrash reports whether a failed command crashed or was killed,
+// from its exit code or what the shell printed, so callers can skip
+// collecting evidence for ordinary failures.
+func LooksLikeCrash(exitCode int, output string) bool {
+	if exitCode == 0 {
+		return false
+	}
+	return CrashSignal(exitCode) != "" || crashOutputPattern.MatchString(output)
+}
+
+// CollectCrashEvidence gathers what the host recorded about a crash in the
+// last since (rounded up to a minute): the kernel log, the core dump
+// location, and on macOS the crash reports. The probe runs through
+// BuildRemoteCommand, so it sees the same ulimits and directory as the
+// command that crashed.
+func CollectCrashEvidence(client SSHExecer, host *config.Host, since time.Duration) (*CrashEvidence, error) {
+	if client == nil {
+		return nil, fmt.Errorf("no SSH client provided")
+	}
+	minutes := int((since+time.Minute-1)/time.Minute) + 1
+	stdout, _, _, err := client.Exec(BuildRemoteCommand(fmt.Sprintf(crashProbeScript, minutes), host))
+	if err != nil {
+		return nil, err
+	}
+	return ParseCrashEvidence(string(stdout)), nil
+}
+
+// ParseCrashEvidence splits the crash probe's output into files. Output
+// before the first section (e.g., from shell startup files) is ignored.
+func ParseCrashEvidence(out string) *CrashEvidence {
+	evidence := &CrashEvidence{}
+	var name string // The file being read, "" for none
+	var content strings.Builder
+	var system bool
+	flush := func() {
+		if name != "" {
+			evidence.Files = append(evidence.Files, CrashFile{Name: name, Content: content.String()})
+		}
+		name = ""
+		content.Reset()
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := crashSectionPattern.FindStringSubmatch(line); m != nil {
+			flush()
+			system = m[1] == "system"
+			if !system {
+				name = crashFileName(m[1])
+			}
+			continue
+		}
+		switch {
+		case system:
+			if evidence.OS == "" {
+				evidence.OS = strings.TrimSpace(line)
+			}
+		case name != "":
+			content.WriteString(line + "\n")
+			if name == "dmesg.txt" && crashLogPattern.MatchString(line) {
+				evidence.Highlights = append(evidence.Highlights, strings.TrimSpace(line))
+			}
+		}
+	}
+	flush()
+	return evidence
+}
+
+// crashFileName turns a section name into a safe file name. Names that
+// came from the host are prefixed with hostFilePrefix.
+func crashFileName(name string) string {
+	if crashProbeFiles[name] {
+		return name
+	}
+	return hostFilePrefix + unsafeFileChars.ReplaceAllString(name, "_")
+}
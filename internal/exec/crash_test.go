@@ -0,0 +1,93 @@
+package exec
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	sshtesting "github.com/rileyhilliard/rr/pkg/sshutil/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const linuxCrashProbeOutput = `Welcome to mini!
+==> system <==
+Linux
+==> dmesg.txt <==
+[Thu Oct 16 15:30:40 2026] usb 1-1: new high-speed USB device
+[Thu Oct 16 15:30:45 2026] python3[4121]: segfault at 0 ip 00007f1c sp 00007ffd error 4 in libfoo.so
+==> core_pattern.txt <==
+core_pattern: |/usr/lib/systemd/systemd-coredump %P %u %g %s %t %c %h
+ulimit -c: unlimited
+==> coredumpctl.txt <==
+TIME                         PID  UID  GID SIG     COREFILE EXE
+Thu 2026-10-16 15:30:45 UTC 4121 1000 1000 SIGSEGV present  /usr/bin/python3
+`
+
+func TestParseCrashEvidence(t *testing.T) {
+	evidence := ParseCrashEvidence(linuxCrashProbeOutput)
+	assert.Equal(t, "Linux", evidence.OS)
+	require.Len(t, evidence.Files, 3)
+	assert.Equal(t, "dmesg.txt", evidence.Files[0].Name)
+	assert.Contains(t, evidence.Files[0].Content, "usb 1-1")
+	assert.NotContains(t, evidence.Files[0].Content, "Welcome", "shell startup output is dropped")
+	assert.Equal(t, "core_pattern.txt", evidence.Files[1].Name)
+	assert.Contains(t, evidence.Files[1].Content, "systemd-coredump")
+	assert.Equal(t, "coredumpctl.txt", evidence.Files[2].Name)
+
+	require.Len(t, evidence.Highlights, 1)
+	assert.Contains(t, evidence.Highlights[0], "python3[4121]: segfault at 0")
+}
+
+func TestParseCrashEvidence_MacReports(t *testing.T) {
+	evidence := ParseCrashEvidence("==> system <==\nDarwin\n==> core_pattern.txt <==\nkern.corefile: /cores/core.%P\n" +
+		"==> python3.12-2026-10-16-153045.ips <==\n{\"bug_type\":\"309\"}\n==> ../../evil <==\nx\n==> run.txt <==\ny\n")
+	assert.Equal(t, "Darwin", evidence.OS)
+	require.Len(t, evidence.Files, 4)
+	assert.Equal(t, "host-run.txt", evidence.Files[3].Name, "host names can't replace rr's own files")
+	assert.Equal(t, "host-python3.12-2026-10-16-153045.ips", evidence.Files[1].Name)
+	assert.Equal(t, "host-.._.._evil", evidence.Files[2].Name, "names can't leave the evidence directory")
+	assert.Empty(t, evidence.Highlights)
+}
+
+func TestLooksLikeCrash(t *testing.T) {
+	assert.True(t, LooksLikeCrash(139, ""))
+	assert.True(t, LooksLikeCrash(137, ""))
+	assert.True(t, LooksLikeCrash(134, ""))
+	assert.True(t, LooksLikeCrash(2, "make: *** [test] Segmentation fault (core dumped)"))
+	assert.False(t, LooksLikeCrash(1, "FAIL: TestSomething"))
+	assert.False(t, LooksLikeCrash(143, ""), "SIGTERM is a stop, not a crash")
+	assert.False(t, LooksLikeCrash(0, "Segmentation fault"))
+
+	assert.Equal(t, "SIGSEGV", CrashSignal(139))
+	assert.Empty(t, CrashSignal(1))
+}
+
+func TestCollectCrashEvidence(t *testing.T) {
+	client := sshtesting.NewMockClient("mini")
+	client.SetCommandResponse("core_pattern", sshtesting.CommandResponse{Stdout: []byte(linuxCrashProbeOutput)})
+
+	evidence, err := CollectCrashEvidence(client, &config.Host{Dir: "~/rr/app", Shell: "bash -c"}, 90*time.Second)
+	require.NoError(t, err)
+	assert.Len(t, evidence.Files, 3)
+
+	_, err = CollectCrashEvidence(nil, &config.Host{}, time.Minute)
+	assert.Error(t, err)
+}
+
+func TestCrashProbeScript_RunsInShell(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	exitCode, err := ExecuteLocal(fmt.Sprintf(crashProbeScript, 5), t.TempDir(), &stdout, &stderr)
+	require.NoError(t, err)
+	assert.Equal(t, 0, exitCode, stderr.String())
+
+	evidence := ParseCrashEvidence(stdout.String())
+	assert.NotEmpty(t, evidence.OS)
+	names := make([]string, 0, len(evidence.Files))
+	for _, f := range evidence.Files {
+		names = append(names, f.Name)
+	}
+	assert.Contains(t, names, "core_pattern.txt")
+}
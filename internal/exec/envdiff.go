@@ -0,0 +1,216 @@
+package exec
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/util"
+)
+
+// envTools are the tools whose versions an environment snapshot records,
+// with the command that prints each one's version.
+var envTools = []struct {
+	Name    string
+	Version string
+}{
+	{"python3", "python3 --version"},
+	{"python", "python --version"},
+	{"node", "node --version"},
+	{"npm", "npm --version"},
+	{"bun", "bun --version"},
+	{"go", "go version"},
+	{"rustc", "rustc --version"},
+	{"cargo", "cargo --version"},
+	{"java", "java -version"},
+	{"ruby", "ruby --version"},
+	{"uv", "uv --version"},
+	{"make", "make --version"},
+	{"gcc", "gcc --version"},
+	{"git", "git --version"},
+}
+
+// envVars are the environment variables a snapshot always records. Locale
+// and timezone differences change sort orders, string encodings, and
+// timestamps in ways that look like test flakes.
+var envVars = []string{"LANG", "LC_ALL", "LC_CTYPE", "TZ", "NODE_ENV"}
+
+// EnvSnapshot is what a shell sees: the version line of each common tool
+// that's installed, and the value of each recorded variable that's set.
+type EnvSnapshot struct {
+	Tools map[string]string
+	Vars  map[string]string
+}
+
+// EnvProbeCommand builds a shell script that prints an EnvSnapshot for
+// ParseEnvSnapshot, recording extraVars alongside the usual variables.
+func EnvProbeCommand(extraVars []string) string {
+	var sb strings.Builder
+	for _, t := range envTools {
+		fmt.Fprintf(&sb, "if command -v %s >/dev/null 2>&1; then printf 'tool\\t%s\\t%%s\\n' \"$(%s 2>&1 | head -n 1)\"; fi; ",
+			t.Name, t.Name, t.Version)
+	}
+	for _, name := range envVarNames(extraVars) {
+		fmt.Fprintf(&sb, "if [ -n \"${%s+x}\" ]; then printf 'var\\t%s\\t%%s\\n' \"$%s\"; fi; ", name, name, name)
+	}
+	return strings.TrimSuffix(sb.String(), " ")
+}
+
+// envVarNames returns the variables to record, skipping extra names that
+// aren't valid shell identifiers.
+func envVarNames(extra []string) []string {
+	names := append([]string{}, envVars...)
+	for _, name := range extra {
+		if config.IsEnvName(name) && !contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ParseEnvSnapshot parses EnvProbeCommand output.
+func ParseEnvSnapshot(output []byte) *EnvSnapshot {
+	snap := &EnvSnapshot{Tools: map[string]string{}, Vars: map[string]string{}}
+	for _, line := range strings.Split(string(output), "\n") {
+		parts := strings.SplitN(line, "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		switch parts[0] {
+		case "tool":
+			snap.Tools[parts[1]] = strings.TrimSpace(parts[2])
+		case "var":
+			snap.Vars[parts[1]] = parts[2]
+		}
+	}
+	return snap
+}
+
+// Kinds of environment difference.
+const (
+	EnvDiffTool = "tool"
+	EnvDiffVar  = "var"
+	EnvDiffPath = "path"
+)
+
+// EnvDelta is one difference between the local and remote environment.
+// Local or Remote is empty when that side doesn't have it at all.
+type EnvDelta struct {
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Local      string `json:"local"`
+	Remote     string `json:"remote"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// CompareEnv lists how remote's tools and variables differ from local's,
+// tools first, each sorted by name. Tools neither side has are left out, as
+// are tools only the remote has, since nothing local can depend on them.
+func CompareEnv(local, remote *EnvSnapshot, hostName string) []EnvDelta {
+	var deltas []EnvDelta
+
+	for _, t := range envTools {
+		l, lok := local.Tools[t.Name]
+		r, rok := remote.Tools[t.Name]
+		if !lok || (rok && versionOf(l) == versionOf(r)) {
+			continue
+		}
+		d := EnvDelta{Kind: EnvDiffTool, Name: t.Name, Local: versionOf(l), Remote: versionOf(r)}
+		if !rok {
+			d.Suggestion = fmt.Sprintf("Install %s on %s, or add its directory to PATH with setup_commands", t.Name, hostName)
+		} else {
+			d.Suggestion = fmt.Sprintf("Install %s %s on %s, or pin the version with a version manager both sides use", t.Name, d.Local, hostName)
+		}
+		deltas = append(deltas, d)
+	}
+
+	names := make([]string, 0, len(local.Vars)+len(remote.Vars))
+	seen := map[string]bool{}
+	for _, vars := range []map[string]string{local.Vars, remote.Vars} {
+		for name := range vars {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		l, r := local.Vars[name], remote.Vars[name]
+		if l == r {
+			continue
+		}
+		d := EnvDelta{Kind: EnvDiffVar, Name: name, Local: l, Remote: r}
+		if l != "" {
+			d.Suggestion = fmt.Sprintf("Set it for commands on %s with env: {%s: %s}", hostName, name, l)
+		} else {
+			d.Suggestion = fmt.Sprintf("It's only set on %s; check the shell profile that sets it there", hostName)
+		}
+		deltas = append(deltas, d)
+	}
+	return deltas
+}
+
+// PathDeltas turns the directories a host's interactive shell adds to PATH,
+// but rr's commands don't see, into environment differences.
+func PathDeltas(diff *PathDifference, hostName string) []EnvDelta {
+	if diff == nil || len(diff.InterOnly) == 0 {
+		return nil
+	}
+	deltas := make([]EnvDelta, 0, len(diff.InterOnly))
+	for _, dir := range diff.InterOnly {
+		deltas = append(deltas, EnvDelta{
+			Kind: EnvDiffPath, Name: dir, Remote: "interactive shells only",
+			Suggestion: fmt.Sprintf("Add it for commands on %s with setup_commands: [export PATH=%s:$PATH]", hostName, toHomeRelative(dir)),
+		})
+	}
+	return deltas
+}
+
+// versionOf picks the version number out of a --version line, like "3.11.4"
+// from "Python 3.11.4", falling back to the whole line.
+func versionOf(line string) string {
+	for _, field := range strings.Fields(line) {
+		field = strings.TrimPrefix(strings.TrimPrefix(field, "go"), "v")
+		field = strings.Trim(field, `"(),`)
+		if len(field) > 0 && field[0] >= '0' && field[0] <= '9' && strings.Contains(field, ".") {
+			return field
+		}
+	}
+	return line
+}
+
+// SnapshotLocal takes an EnvSnapshot of the local shell in workDir.
+func SnapshotLocal(workDir string, extraVars []string) (*EnvSnapshot, error) {
+	stdout, stderr, exitCode, err := ExecuteLocalCapture(EnvProbeCommand(extraVars), workDir)
+	if err != nil {
+		return nil, err
+	}
+	if exitCode != 0 {
+		return nil, fmt.Errorf("exit %d: %s", exitCode, strings.TrimSpace(string(stderr)))
+	}
+	return ParseEnvSnapshot(stdout), nil
+}
+
+// SnapshotRemote takes an EnvSnapshot of what commands see on a host: the
+// same shell, profile files, env, and setup_commands rr runs them with, in
+// the project directory if it exists yet.
+func SnapshotRemote(client SSHExecer, h config.Host, extraVars []string) (*EnvSnapshot, error) {
+	if client == nil {
+		return nil, fmt.Errorf("no SSH client provided")
+	}
+	probe := EnvProbeCommand(extraVars)
+	if h.Dir != "" {
+		probe = fmt.Sprintf("cd %s 2>/dev/null; %s", util.ShellQuotePreserveTilde(config.ExpandRemote(h.Dir)), probe)
+	}
+	h.Dir = ""
+	stdout, stderr, exitCode, err := client.Exec(BuildRemoteCommand(probe, &h))
+	if err != nil {
+		return nil, err
+	}
+	if exitCode != 0 {
+		return nil, fmt.Errorf("exit %d: %s", exitCode, strings.TrimSpace(string(stderr)))
+	}
+	return ParseEnvSnapshot(stdout), nil
+}
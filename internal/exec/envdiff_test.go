@@ -0,0 +1,87 @@
+package exec
+
+import (
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	sshtesting "github.com/rileyhilliard/rr/pkg/sshutil/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionOf(t *testing.T) {
+	assert.Equal(t, "3.11.4", versionOf("Python 3.11.4"))
+	assert.Equal(t, "20.11.0", versionOf("v20.11.0"))
+	assert.Equal(t, "1.22.1", versionOf("go version go1.22.1 linux/amd64"))
+	assert.Equal(t, "17.0.2", versionOf(`openjdk version "17.0.2" 2022-01-18`))
+	assert.Equal(t, "weird", versionOf("weird"))
+}
+
+func TestParseEnvSnapshot(t *testing.T) {
+	snap := ParseEnvSnapshot([]byte("welcome to the box\n" +
+		"tool\tpython3\tPython 3.11.4\n" +
+		"var\tLANG\ten_US.UTF-8\n" +
+		"var\tTZ\t\n"))
+	assert.Equal(t, map[string]string{"python3": "Python 3.11.4"}, snap.Tools)
+	assert.Equal(t, map[string]string{"LANG": "en_US.UTF-8", "TZ": ""}, snap.Vars)
+}
+
+func TestEnvProbeCommand_Local(t *testing.T) {
+	t.Setenv("LANG", "C.UTF-8")
+	t.Setenv("RR_TEST_VAR", "tab\tvalue")
+
+	snap, err := SnapshotLocal(t.TempDir(), []string{"RR_TEST_VAR", "not a name"})
+	require.NoError(t, err)
+	assert.Equal(t, "C.UTF-8", snap.Vars["LANG"])
+	assert.Equal(t, "tab\tvalue", snap.Vars["RR_TEST_VAR"])
+	assert.NotContains(t, EnvProbeCommand([]string{"not a name"}), "not a name")
+}
+
+func TestCompareEnv(t *testing.T) {
+	local := &EnvSnapshot{
+		Tools: map[string]string{"python3": "Python 3.12.1", "node": "v20.11.0", "go": "go version go1.22.1 darwin/arm64"},
+		Vars:  map[string]string{"LANG": "en_US.UTF-8", "TZ": "UTC"},
+	}
+	remote := &EnvSnapshot{
+		Tools: map[string]string{"python3": "Python 3.10.12", "go": "go version go1.22.1 linux/amd64", "ruby": "ruby 3.2"},
+		Vars:  map[string]string{"LANG": "C", "TZ": "UTC", "NODE_ENV": "production"},
+	}
+
+	deltas := CompareEnv(local, remote, "box")
+	require.Len(t, deltas, 4, "same go version, remote-only ruby, and same TZ aren't differences")
+
+	assert.Equal(t, EnvDelta{Kind: EnvDiffTool, Name: "python3", Local: "3.12.1", Remote: "3.10.12",
+		Suggestion: "Install python3 3.12.1 on box, or pin the version with a version manager both sides use"}, deltas[0])
+	assert.Equal(t, "node", deltas[1].Name)
+	assert.Empty(t, deltas[1].Remote)
+	assert.Contains(t, deltas[1].Suggestion, "setup_commands")
+
+	assert.Equal(t, EnvDelta{Kind: EnvDiffVar, Name: "LANG", Local: "en_US.UTF-8", Remote: "C",
+		Suggestion: "Set it for commands on box with env: {LANG: en_US.UTF-8}"}, deltas[2])
+	assert.Equal(t, "NODE_ENV", deltas[3].Name)
+	assert.Contains(t, deltas[3].Suggestion, "only set on box")
+}
+
+func TestPathDeltas(t *testing.T) {
+	assert.Nil(t, PathDeltas(&PathDifference{Common: []string{"/usr/bin"}}, "box"))
+
+	deltas := PathDeltas(&PathDifference{InterOnly: []string{"/home/me/.cargo/bin"}}, "box")
+	require.Len(t, deltas, 1)
+	assert.Equal(t, EnvDiffPath, deltas[0].Kind)
+	assert.Contains(t, deltas[0].Suggestion, "export PATH=$HOME/.cargo/bin:$PATH")
+}
+
+func TestSnapshotRemote(t *testing.T) {
+	mock := sshtesting.NewMockClient("box")
+	mock.SetCommandResponse("command -v python3", sshtesting.CommandResponse{
+		Stdout: []byte("tool\tpython3\tPython 3.10.12\n"),
+	})
+
+	snap, err := SnapshotRemote(mock, config.Host{Dir: "~/rr/app", SetupCommands: []string{"source venv/bin/activate"}}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Python 3.10.12", snap.Tools["python3"])
+
+	mock.SetCommandResponse("command -v python3", sshtesting.CommandResponse{ExitCode: 1, Stderr: []byte("boom")})
+	_, err = SnapshotRemote(mock, config.Host{}, nil)
+	assert.ErrorContains(t, err, "boom")
+}
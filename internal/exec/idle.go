@@ -0,0 +1,189 @@
+package exec
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/host"
+)
+
+// IdleExitCode is the exit code of a command rr killed for going quiet,
+// matching timeout(1).
+const IdleExitCode = 124
+
+// idleSnapshotScript lists the user's processes with their state, CPU time,
+// and what the kernel has them waiting on, as a tree where ps supports it
+// (Linux), or flat otherwise (macOS, BSD).
+const idleSnapshotScript = `uptime; echo; ` +
+	`ps -u "$(id -u)" -o pid,ppid,stat,etime,time,pcpu,rss,wchan:24,args --forest 2>/dev/null || ` +
+	`ps -u "$(id -u)" -o pid,ppid,stat,etime,time,%cpu,rss,wchan,command`
+
+// IdleOptions turns on idle detection for a command.
+type IdleOptions struct {
+	// Timeout is how long the command may go without output.
+	Timeout time.Duration
+
+	// Kill stops the command once it's been quiet for Timeout. Only remote
+	// commands can be stopped; local ones are only reported.
+	Kill bool
+
+	// OnIdle is called when the command goes quiet, after the process
+	// snapshot is taken and before the command is stopped.
+	OnIdle func(IdleEvent)
+}
+
+// IdleEvent describes a command that went quiet.
+type IdleEvent struct {
+	IdleFor     time.Duration
+	Snapshot    []byte // Processes running as the user on the host
+	SnapshotErr error  // Why the snapshot couldn't be taken, if it couldn't
+	Killing     bool   // The command is being stopped
+}
+
+// WatchIdle runs idle detection for a command on conn, per opts. Run the
+// command under the returned context, writing to the returned writers, then
+// call stop: it ends detection and reports whether the command was stopped
+// for being idle. With no opts or no timeout, everything is passed through.
+func WatchIdle(ctx context.Context, conn *host.Connection, opts *IdleOptions, stdout, stderr io.Writer) (context.Context, io.Writer, io.Writer, func() bool) {
+	if opts == nil || opts.Timeout <= 0 {
+		return ctx, stdout, stderr, func() bool { return false }
+	}
+
+	var client SSHExecer
+	kill := opts.Kill
+	if conn != nil && !conn.IsLocal && conn.Client != nil {
+		client = conn.Client
+	} else {
+		kill = false
+	}
+
+	cmdCtx, cancelCmd := context.WithCancel(ctx)
+	watchCtx, stopWatch := context.WithCancel(cmdCtx)
+	var killed bool
+	var mu sync.Mutex
+
+	d := NewIdleDetector(opts.Timeout, func(idleFor time.Duration) {
+		event := IdleEvent{IdleFor: idleFor, Killing: kill}
+		event.Snapshot, event.SnapshotErr = CaptureIdleSnapshot(client)
+		if opts.OnIdle != nil {
+			opts.OnIdle(event)
+		}
+		if kill {
+			mu.Lock()
+			killed = true
+			mu.Unlock()
+			cancelCmd()
+		}
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		d.Watch(watchCtx)
+	}()
+
+	stop := func() bool {
+		stopWatch()
+		<-done
+		cancelCmd()
+		mu.Lock()
+		defer mu.Unlock()
+		return killed
+	}
+	return cmdCtx, d.Writer(stdout), d.Writer(stderr), stop
+}
+
+// IdleDetector notices when a command stops producing output. Wrap the
+// command's writers with Writer, then call Watch while it runs.
+type IdleDetector struct {
+	timeout time.Duration
+	onIdle  func(idleFor time.Duration)
+
+	mu         sync.Mutex
+	lastOutput time.Time
+	fired      bool
+}
+
+// NewIdleDetector returns a detector that calls onIdle once the command has
+// been quiet for timeout. It fires again only after more output followed by
+// another quiet stretch.
+func NewIdleDetector(timeout time.Duration, onIdle func(idleFor time.Duration)) *IdleDetector {
+	return &IdleDetector{
+		timeout:    timeout,
+		onIdle:     onIdle,
+		lastOutput: time.Now(),
+	}
+}
+
+// Writer returns w wrapped so writes to it count as output.
+func (d *IdleDetector) Writer(w io.Writer) io.Writer {
+	return idleWriter{w: w, d: d}
+}
+
+// Watch checks for quiet stretches until ctx is canceled. onIdle runs on
+// Watch's goroutine, so a slow one delays the next check, not the command.
+func (d *IdleDetector) Watch(ctx context.Context) {
+	interval := min(d.timeout/4, 5*time.Second)
+	if interval <= 0 {
+		interval = d.timeout
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if idleFor, ok := d.check(now); ok {
+				d.onIdle(idleFor)
+			}
+		}
+	}
+}
+
+// check reports whether the command has been quiet for the timeout as of
+// now, and for how long, unless that stretch was already reported.
+func (d *IdleDetector) check(now time.Time) (time.Duration, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	idleFor := now.Sub(d.lastOutput)
+	if d.fired || idleFor < d.timeout {
+		return 0, false
+	}
+	d.fired = true
+	return idleFor, true
+}
+
+// touch records output.
+func (d *IdleDetector) touch() {
+	d.mu.Lock()
+	d.lastOutput = time.Now()
+	d.fired = false
+	d.mu.Unlock()
+}
+
+// idleWriter passes writes through and records them as output.
+type idleWriter struct {
+	w io.Writer
+	d *IdleDetector
+}
+
+func (iw idleWriter) Write(p []byte) (int, error) {
+	if len(p) > 0 {
+		iw.d.touch()
+	}
+	return iw.w.Write(p)
+}
+
+// CaptureIdleSnapshot lists the processes running as the user, so a hung
+// command shows what it's stuck on. A nil client captures them locally.
+func CaptureIdleSnapshot(client SSHExecer) ([]byte, error) {
+	if client == nil {
+		stdout, _, _, err := ExecuteLocalCapture(idleSnapshotScript, "")
+		return stdout, err
+	}
+	stdout, _, _, err := client.Exec(idleSnapshotScript)
+	return stdout, err
+}
@@ -0,0 +1,140 @@
+package exec
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/host"
+	sshmock "github.com/rileyhilliard/rr/pkg/sshutil/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdleDetector_Check(t *testing.T) {
+	d := NewIdleDetector(time.Minute, nil)
+	start := d.lastOutput
+
+	_, ok := d.check(start.Add(30 * time.Second))
+	assert.False(t, ok, "not idle before the timeout")
+
+	idleFor, ok := d.check(start.Add(90 * time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, 90*time.Second, idleFor)
+
+	_, ok = d.check(start.Add(3 * time.Minute))
+	assert.False(t, ok, "a quiet stretch is reported once")
+
+	var out bytes.Buffer
+	_, err := d.Writer(&out).Write([]byte("still here\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "still here\n", out.String())
+
+	_, ok = d.check(time.Now().Add(30 * time.Second))
+	assert.False(t, ok, "output resets the clock")
+	_, ok = d.check(time.Now().Add(2 * time.Minute))
+	assert.True(t, ok, "the next quiet stretch is reported")
+}
+
+func TestIdleDetector_Watch(t *testing.T) {
+	var mu sync.Mutex
+	var fired []time.Duration
+	d := NewIdleDetector(20*time.Millisecond, func(idleFor time.Duration) {
+		mu.Lock()
+		fired = append(fired, idleFor)
+		mu.Unlock()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		d.Watch(ctx)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(fired) == 1
+	}, time.Second, 5*time.Millisecond)
+	cancel()
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.GreaterOrEqual(t, fired[0], 20*time.Millisecond)
+}
+
+func TestCaptureIdleSnapshot_Local(t *testing.T) {
+	out, err := CaptureIdleSnapshot(nil)
+	require.NoError(t, err)
+	assert.True(t, strings.Contains(string(out), "PID"), "snapshot should include the ps header, got:\n%s", out)
+}
+
+func TestWatchIdle_Kill(t *testing.T) {
+	client := sshmock.NewMockClient("gpu")
+	client.SetCommandResponse("^uptime", sshmock.CommandResponse{Stdout: []byte("PID COMMAND\n42 make test\n")})
+	conn := &host.Connection{Name: "gpu", Client: client}
+
+	var events []IdleEvent
+	ctx, stdout, _, stop := WatchIdle(context.Background(), conn, &IdleOptions{
+		Timeout: 20 * time.Millisecond,
+		Kill:    true,
+		OnIdle:  func(e IdleEvent) { events = append(events, e) },
+	}, &bytes.Buffer{}, &bytes.Buffer{})
+
+	_, err := stdout.Write([]byte("starting\n"))
+	require.NoError(t, err)
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("the command wasn't stopped")
+	}
+	assert.True(t, stop(), "stop reports the command was killed")
+	require.Len(t, events, 1)
+	assert.True(t, events[0].Killing)
+	assert.NoError(t, events[0].SnapshotErr)
+	assert.Contains(t, string(events[0].Snapshot), "make test")
+}
+
+func TestWatchIdle_WarnOnly(t *testing.T) {
+	fired := make(chan IdleEvent, 1)
+	ctx, _, _, stop := WatchIdle(context.Background(), createLocalConn(), &IdleOptions{
+		Timeout: 20 * time.Millisecond,
+		Kill:    true, // Local commands can't be stopped, so this only warns
+		OnIdle:  func(e IdleEvent) { fired <- e },
+	}, &bytes.Buffer{}, &bytes.Buffer{})
+
+	select {
+	case e := <-fired:
+		assert.False(t, e.Killing)
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnIdle wasn't called")
+	}
+	assert.NoError(t, ctx.Err(), "the command keeps running")
+	assert.False(t, stop())
+}
+
+func TestWatchIdle_Off(t *testing.T) {
+	ctx := context.Background()
+	var out bytes.Buffer
+	gotCtx, stdout, _, stop := WatchIdle(ctx, createLocalConn(), nil, &out, &out)
+	assert.Equal(t, ctx, gotCtx)
+	assert.Equal(t, &out, stdout)
+	assert.False(t, stop())
+}
+
+func TestExecuteTask_IdleFinishesInTime(t *testing.T) {
+	conn := &host.Connection{Name: "gpu", Client: sshmock.NewMockClient("gpu")}
+
+	result, err := ExecuteTask(context.Background(), conn, &config.TaskConfig{Run: "true"}, nil, nil, "", &bytes.Buffer{}, &bytes.Buffer{}, &TaskExecOptions{
+		Idle: &IdleOptions{Timeout: time.Hour, Kill: true},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.ExitCode, "a command that finishes in time isn't touched")
+}
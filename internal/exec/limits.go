@@ -0,0 +1,249 @@
+package exec
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/rileyhilliard/rr/internal/config"
+)
+
+// limitsProbeScript prints the resource limits the remote command ran under:
+// the shell's ulimits, then each cgroup from the session's own up to the root
+// (cgroup v2), or the memory controller's limits (cgroup v1). The memory
+// counters in cgroup v2 are hierarchical, so an ancestor's oom_kill count
+// includes kills in the failed command's session.
+const limitsProbeScript = `echo "ulimit nofile $(ulimit -n)"; ` +
+	`echo "ulimit nproc $(ulimit -u 2>/dev/null)"; ` +
+	`echo "ulimit as $(ulimit -v 2>/dev/null)"; ` +
+	`awk '/^MemTotal:/ {printf "memtotal %.0f\n", $2 * 1024}' /proc/meminfo 2>/dev/null; ` +
+	`if [ -f /sys/fs/cgroup/cgroup.controllers ]; then ` +
+	`d=/sys/fs/cgroup$(sed -n 's/^0:://p' /proc/self/cgroup); ` +
+	`while [ -n "$d" ]; do ` +
+	`[ -r "$d/memory.max" ] && echo "cgroup $d memory.max $(cat "$d/memory.max")"; ` +
+	`[ -r "$d/memory.peak" ] && echo "cgroup $d memory.peak $(cat "$d/memory.peak")"; ` +
+	`[ -r "$d/memory.events" ] && echo "cgroup $d oom_kill $(awk '$1 == "oom_kill" {print $2}' "$d/memory.events")"; ` +
+	`[ -r "$d/pids.max" ] && echo "cgroup $d pids.max $(cat "$d/pids.max")"; ` +
+	`[ -r "$d/pids.events" ] && echo "cgroup $d pids.events.max $(awk '$1 == "max" {print $2}' "$d/pids.events")"; ` +
+	`[ "$d" = /sys/fs/cgroup ] && break; d=${d%/*}; ` +
+	`done; ` +
+	`elif [ -r /sys/fs/cgroup/memory/memory.limit_in_bytes ]; then ` +
+	`d=/sys/fs/cgroup/memory; ` +
+	`echo "cgroup $d memory.max $(cat $d/memory.limit_in_bytes)"; ` +
+	`[ -r $d/memory.max_usage_in_bytes ] && echo "cgroup $d memory.peak $(cat $d/memory.max_usage_in_bytes)"; ` +
+	`[ -r $d/memory.oom_control ] && echo "cgroup $d oom_kill $(awk '$1 == "oom_kill" {print $2}' $d/memory.oom_control)"; ` +
+	`fi; true`
+
+// cgroupV1Unlimited is the smallest memory.limit_in_bytes cgroup v1 reports
+// for "no limit" (the page-aligned max int64).
+const cgroupV1Unlimited = 1 << 62
+
+// ResourceLimits are the limits a remote command ran under, as collected by
+// ProbeResourceLimits. Zero means not limited, or not reported.
+type ResourceLimits struct {
+	NoFile       string // ulimit -n
+	NProc        string // ulimit -u
+	AddressSpace string // ulimit -v, in KiB
+
+	MemTotal     int64  // Host RAM in bytes
+	MemoryMax    int64  // Tightest cgroup memory limit in bytes
+	MemoryCgroup string // The cgroup that sets MemoryMax
+	MemoryPeak   int64  // Peak usage of MemoryCgroup, if the kernel tracks it
+	OOMKills     int    // OOM kills the cgroup hierarchy has recorded
+	PidsMax      int64  // Tightest cgroup process limit
+	PidsCgroup   string // The cgroup that sets PidsMax
+	PidsMaxHits  int    // Forks refused by PidsMax
+}
+
+// LimitDiagnosis explains a failure caused by a resource limit.
+type LimitDiagnosis struct {
+	Reason     string   // One line, e.g. "Killed by the OOM killer"
+	Details    []string // The limits involved, e.g. "cgroup memory limit: 2.00 GB"
+	Suggestion string
+}
+
+// ProbeResourceLimits collects the resource limits on a remote host. The
+// probe runs through BuildRemoteCommand, so it sees the same ulimits as a
+// command that ran there (setup_commands can change them).
+func ProbeResourceLimits(client SSHExecer, host *config.Host) (*ResourceLimits, error) {
+	if client == nil {
+		return nil, fmt.Errorf("no SSH client provided")
+	}
+	stdout, _, _, err := client.Exec(BuildRemoteCommand(limitsProbeScript, host))
+	if err != nil {
+		return nil, err
+	}
+	return ParseResourceLimits(string(stdout)), nil
+}
+
+// ParseResourceLimits parses the output of the limits probe. Lines it doesn't
+// recognize (e.g., from shell startup files) are ignored.
+func ParseResourceLimits(out string) *ResourceLimits {
+	limits := &ResourceLimits{}
+	peaks := make(map[string]int64)
+
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		switch {
+		case len(fields) == 3 && fields[0] == "ulimit":
+			switch fields[1] {
+			case "nofile":
+				limits.NoFile = fields[2]
+			case "nproc":
+				limits.NProc = fields[2]
+			case "as":
+				limits.AddressSpace = fields[2]
+			}
+		case len(fields) == 2 && fields[0] == "memtotal":
+			limits.MemTotal, _ = strconv.ParseInt(fields[1], 10, 64)
+		case len(fields) == 4 && fields[0] == "cgroup":
+			cgroup, key := fields[1], fields[2]
+			n, err := strconv.ParseInt(fields[3], 10, 64)
+			if err != nil || n <= 0 {
+				continue // "max", or nothing recorded
+			}
+			switch key {
+			case "memory.max":
+				if n < cgroupV1Unlimited && (limits.MemoryMax == 0 || n < limits.MemoryMax) {
+					limits.MemoryMax = n
+					limits.MemoryCgroup = cgroup
+				}
+			case "memory.peak":
+				peaks[cgroup] = n
+			case "oom_kill":
+				limits.OOMKills = max(limits.OOMKills, int(n))
+			case "pids.max":
+				if limits.PidsMax == 0 || n < limits.PidsMax {
+					limits.PidsMax = n
+					limits.PidsCgroup = cgroup
+				}
+			case "pids.events.max":
+				limits.PidsMaxHits = max(limits.PidsMaxHits, int(n))
+			}
+		}
+	}
+	limits.MemoryPeak = peaks[limits.MemoryCgroup]
+	return limits
+}
+
+var (
+	// memoryErrorPattern matches out-of-memory errors from common runtimes.
+	memoryErrorPattern = regexp.MustCompile(`(?m)(^Killed$|MemoryError|[Oo]ut of memory|Cannot allocate memory|std::bad_alloc|JavaScript heap out of memory|java\.lang\.OutOfMemoryError)`)
+
+	// openFilesPattern matches running out of file descriptors.
+	openFilesPattern = regexp.MustCompile(`(?i)too many open files|EMFILE`)
+
+	// processLimitPattern matches fork and thread creation failures.
+	processLimitPattern = regexp.MustCompile(`(?i)fork: (retry: )?resource temporarily unavailable|can't start new thread|pthread_create failed|fork failed|cannot fork|EAGAIN.*(fork|thread|clone)`)
+)
+
+// LooksLimitRelated reports whether a failure could be explained by a
+// resource limit, so callers can skip probing for ordinary failures.
+func LooksLimitRelated(exitCode int, output string) bool {
+	return exitCode == 137 ||
+		memoryErrorPattern.MatchString(output) ||
+		openFilesPattern.MatchString(output) ||
+		processLimitPattern.MatchString(output)
+}
+
+// DiagnoseResourceLimits works out whether a failed command hit a resource
+// limit, from its exit code, the tail of its output, and the limits it ran
+// under. Returns nil if the failure doesn't look limit related.
+func DiagnoseResourceLimits(exitCode int, output string, limits *ResourceLimits) *LimitDiagnosis {
+	if exitCode == 0 || limits == nil {
+		return nil
+	}
+
+	switch {
+	case exitCode == 137 || memoryErrorPattern.MatchString(output):
+		return diagnoseMemory(exitCode, limits)
+	case openFilesPattern.MatchString(output):
+		return &LimitDiagnosis{
+			Reason:  "Ran out of file descriptors",
+			Details: []string{"open file limit: " + describeUlimit(limits.NoFile) + " (ulimit -n)"},
+			Suggestion: "Raise it for rr's commands with a setup_commands entry like 'ulimit -n 65536', " +
+				"or raise the hard limit on the host (limits.conf or systemd LimitNOFILE).",
+		}
+	case processLimitPattern.MatchString(output):
+		d := &LimitDiagnosis{
+			Reason: "Hit the process limit",
+			Suggestion: "Run fewer processes or threads at once (e.g., lower make -j, pytest -n, or worker counts), " +
+				"or raise the limit on the host.",
+		}
+		if limits.NProc != "" {
+			d.Details = append(d.Details, "process limit: "+describeUlimit(limits.NProc)+" (ulimit -u)")
+		}
+		if limits.PidsMax > 0 {
+			d.Details = append(d.Details, fmt.Sprintf("cgroup process limit: %d (pids.max in %s)", limits.PidsMax, limits.PidsCgroup))
+		}
+		if limits.PidsMaxHits > 0 {
+			d.Details = append(d.Details, fmt.Sprintf("forks refused by pids.max: %d", limits.PidsMaxHits))
+		}
+		return d
+	}
+	return nil
+}
+
+// diagnoseMemory explains an out-of-memory failure.
+func diagnoseMemory(exitCode int, limits *ResourceLimits) *LimitDiagnosis {
+	d := &LimitDiagnosis{
+		Reason: "Ran out of memory",
+		Suggestion: "Use less memory (fewer parallel workers, smaller batches), raise the memory limit " +
+			"(container or systemd MemoryMax), or run on a host with more RAM (--host).",
+	}
+	switch {
+	case limits.OOMKills > 0:
+		d.Reason = "Killed by the OOM killer"
+	case exitCode == 137:
+		d.Reason = "Killed with SIGKILL, most likely for running out of memory"
+	}
+
+	if limits.MemoryMax > 0 {
+		detail := fmt.Sprintf("cgroup memory limit: %s (memory.max in %s)", formatLimitBytes(limits.MemoryMax), limits.MemoryCgroup)
+		if limits.MemoryPeak > 0 {
+			detail += fmt.Sprintf(", peak usage %s", formatLimitBytes(limits.MemoryPeak))
+		}
+		d.Details = append(d.Details, detail)
+	} else if limits.MemTotal > 0 {
+		d.Details = append(d.Details, "no cgroup memory limit; host RAM: "+formatLimitBytes(limits.MemTotal))
+	}
+	if limits.OOMKills > 0 {
+		d.Details = append(d.Details, fmt.Sprintf("OOM kills recorded: %d", limits.OOMKills))
+	}
+	if limits.AddressSpace != "" && limits.AddressSpace != "unlimited" {
+		if kb, err := strconv.ParseInt(limits.AddressSpace, 10, 64); err == nil {
+			d.Details = append(d.Details, "virtual memory limit: "+formatLimitBytes(kb*1024)+" (ulimit -v)")
+		}
+	}
+	return d
+}
+
+// describeUlimit renders a ulimit value, which is a number or "unlimited".
+func describeUlimit(v string) string {
+	if v == "" {
+		return "unknown"
+	}
+	return v
+}
+
+// formatLimitBytes formats a byte count with binary units, e.g. "2.00 GB".
+func formatLimitBytes(n int64) string {
+	const (
+		kb = 1024
+		mb = kb * 1024
+		gb = mb * 1024
+	)
+	switch {
+	case n >= gb:
+		return fmt.Sprintf("%.2f GB", float64(n)/gb)
+	case n >= mb:
+		return fmt.Sprintf("%.2f MB", float64(n)/mb)
+	case n >= kb:
+		return fmt.Sprintf("%.2f KB", float64(n)/kb)
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
@@ -0,0 +1,138 @@
+package exec
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	sshtesting "github.com/rileyhilliard/rr/pkg/sshutil/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const cgroupV2ProbeOutput = `ulimit nofile 1024
+ulimit nproc 63411
+ulimit as unlimited
+memtotal 17179869184
+cgroup /sys/fs/cgroup/user.slice/user-1000.slice/session-4.scope memory.max max
+cgroup /sys/fs/cgroup/user.slice/user-1000.slice/session-4.scope memory.peak 104857600
+cgroup /sys/fs/cgroup/user.slice/user-1000.slice/session-4.scope oom_kill 0
+cgroup /sys/fs/cgroup/user.slice/user-1000.slice/session-4.scope pids.max max
+cgroup /sys/fs/cgroup/user.slice/user-1000.slice memory.max 2147483648
+cgroup /sys/fs/cgroup/user.slice/user-1000.slice memory.peak 2147479552
+cgroup /sys/fs/cgroup/user.slice/user-1000.slice oom_kill 3
+cgroup /sys/fs/cgroup/user.slice/user-1000.slice pids.max 4096
+cgroup /sys/fs/cgroup/user.slice/user-1000.slice pids.events.max 0
+cgroup /sys/fs/cgroup/user.slice memory.max max
+cgroup /sys/fs/cgroup/user.slice oom_kill 3
+`
+
+func TestParseResourceLimits(t *testing.T) {
+	t.Run("cgroup v2", func(t *testing.T) {
+		limits := ParseResourceLimits("Welcome to mini!\n" + cgroupV2ProbeOutput)
+
+		assert.Equal(t, "1024", limits.NoFile)
+		assert.Equal(t, "63411", limits.NProc)
+		assert.Equal(t, "unlimited", limits.AddressSpace)
+		assert.Equal(t, int64(17179869184), limits.MemTotal)
+		assert.Equal(t, int64(2147483648), limits.MemoryMax)
+		assert.Equal(t, "/sys/fs/cgroup/user.slice/user-1000.slice", limits.MemoryCgroup)
+		assert.Equal(t, int64(2147479552), limits.MemoryPeak)
+		assert.Equal(t, 3, limits.OOMKills)
+		assert.Equal(t, int64(4096), limits.PidsMax)
+		assert.Equal(t, 0, limits.PidsMaxHits)
+	})
+
+	t.Run("cgroup v1 without a limit", func(t *testing.T) {
+		limits := ParseResourceLimits(`ulimit nofile 20000
+ulimit nproc
+memtotal 6305951744
+cgroup /sys/fs/cgroup/memory memory.max 9223372036854771712
+cgroup /sys/fs/cgroup/memory oom_kill 0
+`)
+		assert.Equal(t, "20000", limits.NoFile)
+		assert.Empty(t, limits.NProc)
+		assert.Zero(t, limits.MemoryMax)
+		assert.Zero(t, limits.OOMKills)
+		assert.Equal(t, int64(6305951744), limits.MemTotal)
+	})
+}
+
+func TestDiagnoseResourceLimits(t *testing.T) {
+	limits := ParseResourceLimits(cgroupV2ProbeOutput)
+
+	t.Run("OOM kill", func(t *testing.T) {
+		d := DiagnoseResourceLimits(137, "", limits)
+		require.NotNil(t, d)
+		assert.Equal(t, "Killed by the OOM killer", d.Reason)
+		assert.Equal(t, []string{
+			"cgroup memory limit: 2.00 GB (memory.max in /sys/fs/cgroup/user.slice/user-1000.slice), peak usage 2.00 GB",
+			"OOM kills recorded: 3",
+		}, d.Details)
+	})
+
+	t.Run("SIGKILL with no cgroup limit", func(t *testing.T) {
+		d := DiagnoseResourceLimits(137, "", &ResourceLimits{MemTotal: 8 << 30})
+		require.NotNil(t, d)
+		assert.Contains(t, d.Reason, "SIGKILL")
+		assert.Equal(t, []string{"no cgroup memory limit; host RAM: 8.00 GB"}, d.Details)
+	})
+
+	t.Run("memory error in output", func(t *testing.T) {
+		d := DiagnoseResourceLimits(1, "Traceback (most recent call last):\nMemoryError\n", &ResourceLimits{})
+		require.NotNil(t, d)
+		assert.Equal(t, "Ran out of memory", d.Reason)
+	})
+
+	t.Run("open files", func(t *testing.T) {
+		d := DiagnoseResourceLimits(1, "open /tmp/x: too many open files", limits)
+		require.NotNil(t, d)
+		assert.Equal(t, "Ran out of file descriptors", d.Reason)
+		assert.Equal(t, []string{"open file limit: 1024 (ulimit -n)"}, d.Details)
+	})
+
+	t.Run("process limit", func(t *testing.T) {
+		d := DiagnoseResourceLimits(2, "bash: fork: retry: Resource temporarily unavailable", limits)
+		require.NotNil(t, d)
+		assert.Equal(t, "Hit the process limit", d.Reason)
+		assert.Equal(t, []string{
+			"process limit: 63411 (ulimit -u)",
+			"cgroup process limit: 4096 (pids.max in /sys/fs/cgroup/user.slice/user-1000.slice)",
+		}, d.Details)
+	})
+
+	t.Run("unrelated failure", func(t *testing.T) {
+		assert.Nil(t, DiagnoseResourceLimits(1, "FAIL: TestSomething", limits))
+		assert.Nil(t, DiagnoseResourceLimits(0, "", limits))
+	})
+}
+
+func TestLooksLimitRelated(t *testing.T) {
+	assert.True(t, LooksLimitRelated(137, ""))
+	assert.True(t, LooksLimitRelated(1, "FATAL ERROR: Reached heap limit Allocation failed - JavaScript heap out of memory"))
+	assert.True(t, LooksLimitRelated(1, "accept: too many open files"))
+	assert.True(t, LooksLimitRelated(1, "RuntimeError: can't start new thread"))
+	assert.False(t, LooksLimitRelated(1, "assertion failed"))
+}
+
+func TestProbeResourceLimits(t *testing.T) {
+	client := sshtesting.NewMockClient("mini")
+	client.SetCommandResponse("memory.max", sshtesting.CommandResponse{Stdout: []byte(cgroupV2ProbeOutput)})
+
+	limits, err := ProbeResourceLimits(client, &config.Host{Dir: "~/rr/app", Shell: "bash -c"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2147483648), limits.MemoryMax)
+
+	_, err = ProbeResourceLimits(nil, &config.Host{})
+	assert.Error(t, err)
+}
+
+func TestLimitsProbeScript_RunsInShell(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	exitCode, err := ExecuteLocal(limitsProbeScript, "", &stdout, &stderr)
+	require.NoError(t, err)
+	assert.Equal(t, 0, exitCode, stderr.String())
+
+	limits := ParseResourceLimits(stdout.String())
+	assert.NotEmpty(t, limits.NoFile)
+}
@@ -6,6 +6,7 @@ import (
 	"os/exec"
 
 	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/logger"
 )
 
 // ExecuteLocal runs a command locally, streaming output to the provided writers.
@@ -18,6 +19,7 @@ func ExecuteLocal(cmd string, workDir string, stdout, stderr io.Writer) (exitCod
 		shell = "/bin/sh"
 	}
 
+	logger.Verbosef(logger.LevelCommands, "exec", "%s -c %s", shell, cmd)
 	command := exec.Command(shell, "-c", cmd)
 
 	// Set working directory if specified
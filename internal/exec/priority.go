@@ -0,0 +1,46 @@
+package exec
+
+import "github.com/rileyhilliard/rr/internal/config"
+
+// Priority commands change the priority of the remote shell ($$), which
+// everything it starts afterwards inherits. macOS has taskpolicy instead of
+// renice for this and no ionice. They never fail: a host without the tools,
+// or a user who isn't allowed to raise priority, runs at normal priority.
+const (
+	lowPriorityCommand = `{ if command -v taskpolicy; then taskpolicy -b -p $$; ` +
+		`else renice -n 10 -p $$; ionice -c 2 -n 7 -p $$; fi; } >/dev/null 2>&1 || true`
+	highPriorityCommand = `{ if command -v taskpolicy; then taskpolicy -t 0 -l 0 -p $$; ` +
+		`else renice -n -5 -p $$; ionice -c 2 -n 0 -p $$; fi; } >/dev/null 2>&1 || true`
+)
+
+// PriorityCommand returns the shell command that runs the rest of a remote
+// command at priority, or "" for normal priority.
+func PriorityCommand(priority string) string {
+	switch priority {
+	case config.PriorityLow:
+		return lowPriorityCommand
+	case config.PriorityHigh:
+		return highPriorityCommand
+	}
+	return ""
+}
+
+// WithPriority returns cmd run at priority.
+func WithPriority(priority, cmd string) string {
+	if p := PriorityCommand(priority); p != "" {
+		return p + " && " + cmd
+	}
+	return cmd
+}
+
+// withPriority returns opts with the task's priority command first among
+// its setup commands, so setup runs at the task's priority too.
+func withPriority(task *config.TaskConfig, opts *TaskExecOptions) *TaskExecOptions {
+	p := PriorityCommand(task.Priority)
+	if p == "" {
+		return opts
+	}
+	o := *opts
+	o.SetupCommands = append([]string{p}, opts.SetupCommands...)
+	return &o
+}
@@ -0,0 +1,66 @@
+package exec
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithPriority(t *testing.T) {
+	assert.Equal(t, "make test", WithPriority("", "make test"))
+	assert.Equal(t, "make test", WithPriority(config.PriorityNormal, "make test"))
+	assert.Equal(t, lowPriorityCommand+" && make test", WithPriority(config.PriorityLow, "make test"))
+	assert.Equal(t, highPriorityCommand+" && make test", WithPriority(config.PriorityHigh, "make test"))
+}
+
+// niceness runs a task that prints its niceness, on a fake remote host.
+func niceness(t *testing.T, task *config.TaskConfig) int {
+	t.Helper()
+	conn, _ := createShellConn(t)
+	var stdout, stderr bytes.Buffer
+	result, err := ExecuteTask(context.Background(), conn, task, nil, nil, "", &stdout, &stderr, nil)
+	require.NoError(t, err)
+	require.Equal(t, 0, result.ExitCode, stderr.String())
+	n, err := strconv.Atoi(strings.TrimSpace(stdout.String()))
+	require.NoError(t, err, stdout.String())
+	return n
+}
+
+func TestExecuteTask_LowPriority(t *testing.T) {
+	if runtime.GOOS == "darwin" {
+		t.Skip("macOS lowers priority with taskpolicy, which doesn't change the niceness")
+	}
+	base := niceness(t, &config.TaskConfig{Run: "nice"})
+
+	assert.Equal(t, min(base+10, 19), niceness(t, &config.TaskConfig{Run: "nice", Priority: config.PriorityLow}))
+	assert.Equal(t, min(base+10, 19), niceness(t, &config.TaskConfig{
+		Steps:    []config.TaskStep{{Run: "nice"}},
+		Priority: config.PriorityLow,
+	}), "steps run at the task's priority too")
+}
+
+func TestExecuteTask_HighPriorityNeverFails(t *testing.T) {
+	// Raising priority needs privileges the user may not have; the task
+	// runs anyway
+	niceness(t, &config.TaskConfig{Run: "nice", Priority: config.PriorityHigh})
+}
+
+func TestExecuteTask_PriorityLeavesOptionsAlone(t *testing.T) {
+	conn, client := createShellConn(t)
+	opts := &TaskExecOptions{SetupCommands: []string{"true"}}
+	task := &config.TaskConfig{Run: "true", Priority: config.PriorityLow}
+
+	var stdout, stderr bytes.Buffer
+	_, err := ExecuteTask(context.Background(), conn, task, nil, nil, "", &stdout, &stderr, opts)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"true"}, opts.SetupCommands)
+	require.Len(t, client.commands, 1)
+	assert.True(t, strings.HasPrefix(client.commands[0], lowPriorityCommand+" && true"), client.commands[0])
+}
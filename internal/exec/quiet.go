@@ -0,0 +1,126 @@
+package exec
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/rileyhilliard/rr/internal/config"
+)
+
+// quietOutputLimit caps how much output a quiet step holds back. Past it, the
+// oldest output is dropped, since the end of a failure's output is usually
+// the part that explains it.
+const quietOutputLimit = 4 << 20
+
+// quietGate sits in front of a task's stdout and stderr and holds output back
+// while a quiet step runs. When the step fails its output is written out;
+// when it passes the output is dropped. A nil gate passes everything through.
+type quietGate struct {
+	mu     sync.Mutex
+	task   *config.TaskConfig
+	stdout io.Writer
+	stderr io.Writer
+
+	quiet   bool
+	held    []heldOutput
+	size    int
+	dropped int
+}
+
+// heldOutput is a chunk of output held back by a quiet step, and where it
+// was headed.
+type heldOutput struct {
+	out  io.Writer
+	data []byte
+}
+
+// newQuietGate returns a gate in front of stdout and stderr if any part of
+// task is quiet, or nil if nothing ever needs holding back.
+func newQuietGate(task *config.TaskConfig, stdout, stderr io.Writer) *quietGate {
+	quiet := task.Run != "" && config.GetTaskVerbosity(task) == config.VerbosityQuiet
+	for _, step := range task.Steps {
+		quiet = quiet || config.GetStepVerbosity(task, step) == config.VerbosityQuiet
+	}
+	if !quiet {
+		return nil
+	}
+	return &quietGate{task: task, stdout: stdout, stderr: stderr}
+}
+
+// Stdout returns the writer for the task's stdout.
+func (g *quietGate) Stdout() io.Writer {
+	return gateWriter{gate: g, out: g.stdout}
+}
+
+// Stderr returns the writer for the task's stderr.
+func (g *quietGate) Stderr() io.Writer {
+	return gateWriter{gate: g, out: g.stderr}
+}
+
+// gateWriter is one of a quietGate's streams.
+type gateWriter struct {
+	gate *quietGate
+	out  io.Writer
+}
+
+// Write implements io.Writer.
+func (w gateWriter) Write(p []byte) (int, error) {
+	g := w.gate
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.quiet {
+		return w.out.Write(p)
+	}
+	g.held = append(g.held, heldOutput{out: w.out, data: append([]byte(nil), p...)})
+	g.size += len(p)
+	for g.size > quietOutputLimit && len(g.held) > 1 {
+		g.size -= len(g.held[0].data)
+		g.dropped += len(g.held[0].data)
+		g.held = g.held[1:]
+	}
+	return len(p), nil
+}
+
+// beginRun starts holding output back if the task's single command is quiet.
+func (g *quietGate) beginRun() {
+	if g != nil {
+		g.begin(config.GetTaskVerbosity(g.task) == config.VerbosityQuiet)
+	}
+}
+
+// beginStep starts holding output back if step is quiet.
+func (g *quietGate) beginStep(step config.TaskStep) {
+	if g != nil {
+		g.begin(config.GetStepVerbosity(g.task, step) == config.VerbosityQuiet)
+	}
+}
+
+func (g *quietGate) begin(quiet bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.quiet = quiet
+	g.held, g.size, g.dropped = nil, 0, 0
+}
+
+// end stops holding output back, writing out what was held if the step
+// failed.
+func (g *quietGate) end(failed bool) {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.quiet && failed {
+		if g.dropped > 0 {
+			fmt.Fprintf(g.stderr, "... (first %d bytes of output not shown)\n", g.dropped)
+		}
+		for _, h := range g.held {
+			_, _ = h.out.Write(h.data)
+		}
+	}
+	g.quiet = false
+	g.held, g.size, g.dropped = nil, 0, 0
+}
@@ -0,0 +1,105 @@
+package exec
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewQuietGate_NilWithoutQuietSteps(t *testing.T) {
+	var out bytes.Buffer
+	assert.Nil(t, newQuietGate(&config.TaskConfig{Run: "make"}, &out, &out))
+	assert.Nil(t, newQuietGate(&config.TaskConfig{Steps: []config.TaskStep{{Run: "make", Output: "verbose"}}}, &out, &out))
+	assert.NotNil(t, newQuietGate(&config.TaskConfig{Steps: []config.TaskStep{{Run: "make", Output: "quiet"}}}, &out, &out))
+
+	// A nil gate is safe to use
+	var gate *quietGate
+	gate.beginStep(config.TaskStep{})
+	gate.end(true)
+}
+
+func TestQuietGate_LimitDropsOldestOutput(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	task := &config.TaskConfig{Run: "make", Output: config.TaskOutput{Verbosity: "quiet"}}
+	gate := newQuietGate(task, &stdout, &stderr)
+	require.NotNil(t, gate)
+
+	gate.beginRun()
+	chunk := strings.Repeat("x", quietOutputLimit/4)
+	for i := 0; i < 5; i++ {
+		_, err := gate.Stdout().Write([]byte(chunk))
+		require.NoError(t, err)
+	}
+	_, _ = gate.Stdout().Write([]byte("the end\n"))
+	assert.Zero(t, stdout.Len())
+
+	gate.end(true)
+	assert.Equal(t, 3*len(chunk)+len("the end\n"), stdout.Len())
+	assert.True(t, strings.HasSuffix(stdout.String(), "the end\n"))
+	assert.Contains(t, stderr.String(), "first 2097152 bytes of output not shown")
+
+	// Output after the quiet command goes straight through
+	_, _ = gate.Stdout().Write([]byte("after\n"))
+	assert.True(t, strings.HasSuffix(stdout.String(), "after\n"))
+}
+
+func TestExecuteTask_QuietSteps(t *testing.T) {
+	conn := createLocalConn()
+	task := &config.TaskConfig{
+		Output: config.TaskOutput{Verbosity: "quiet"},
+		Steps: []config.TaskStep{
+			{Name: "build", Run: "echo building; echo build-warning >&2"},
+			{Name: "test", Run: "echo testing", Output: "verbose"},
+			{Name: "lint", Run: "echo linting; echo lint-error >&2; exit 2", OnFail: "continue"},
+		},
+	}
+
+	var stdout, stderr bytes.Buffer
+	result, err := ExecuteTask(context.Background(), conn, task, nil, nil, "", &stdout, &stderr, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.ExitCode)
+	assert.Equal(t, "testing\nlinting\n", stdout.String())
+	assert.Equal(t, "lint-error\n", stderr.String())
+}
+
+func TestExecuteTask_QuietSingleCommand(t *testing.T) {
+	conn := createLocalConn()
+	task := &config.TaskConfig{Run: "echo hidden", Output: config.TaskOutput{Verbosity: "quiet"}}
+
+	var stdout, stderr bytes.Buffer
+	result, err := ExecuteTask(context.Background(), conn, task, nil, nil, "", &stdout, &stderr, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Empty(t, stdout.String())
+
+	task.Run = "echo shown; exit 1"
+	result, err = ExecuteTask(context.Background(), conn, task, nil, nil, "", &stdout, &stderr, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.ExitCode)
+	assert.Equal(t, "shown\n", stdout.String())
+}
+
+func TestStepTracker_QuietSteps(t *testing.T) {
+	task := &config.TaskConfig{Steps: []config.TaskStep{
+		{Name: "build", Run: "make", Output: "quiet"},
+		{Name: "test", Run: "make test", Output: "quiet"},
+	}}
+	var out bytes.Buffer
+	gate := newQuietGate(task, &out, &out)
+	tracker := newStepTracker(task.Steps, nil, gate.Stdout())
+	tracker.gate = gate
+
+	_, err := tracker.Write([]byte("\x1eRR_STEP start 1\nbuilt\n\x1eRR_STEP end 1 0\n\x1eRR_STEP start 2\nFAIL x\n"))
+	require.NoError(t, err)
+	assert.Empty(t, out.String())
+
+	// The script dies mid-step, which counts as the step failing
+	tracker.FinishInterrupted(130)
+	assert.Equal(t, "FAIL x\n", out.String())
+}
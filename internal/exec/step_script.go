@@ -0,0 +1,349 @@
+package exec
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/rileyhilliard/rr/internal/util"
+)
+
+// stepMarker prefixes the progress lines the step script prints to stdout.
+// It starts with an ASCII record separator so it can't be confused with
+// ordinary command output; marker lines are stripped before output is shown.
+const stepMarker = "\x1eRR_STEP "
+
+// executeStepsScript runs all steps of a remote task in a single SSH exec.
+// Running each step as its own exec costs a round trip (and a fresh shell with
+// setup commands) per step; instead the steps are compiled into one script that
+// applies on_fail itself and prints a marker line as each step starts and ends.
+// The markers drive the StepHandler callbacks, so progress display is the same
+// as running steps one by one.
+func executeStepsScript(ctx context.Context, conn *host.Connection, steps []config.TaskStep, skip []bool, env map[string]string, workDir string, opts *TaskExecOptions, stdout, stderr io.Writer, gate *quietGate) (*TaskResult, error) {
+	sudo := stepsUseSudo(steps, skip)
+	var prompt string
+	if sudo {
+		prompt = newSudoPrompt()
+		env = sudoEnv(env, prompt)
+	}
+	script := buildStepsScript(steps, skip, env, workDir, opts.SetupCommands)
+
+	tracker := newStepTracker(steps, opts.StepHandler, stdout)
+	tracker.hostName = conn.Name
+	tracker.workDir = workDir
+	tracker.gate = gate
+	tracker.skip = skip
+	tracker.done = opts.Done
+	var exitCode int
+	var err error
+	if sudo {
+		exitCode, err = execSudo(ctx, conn, script, prompt, opts.SudoPassword, tracker)
+	} else {
+		exitCode, err = conn.Client.ExecStreamContext(ctx, script, tracker, stderr)
+	}
+	tracker.Flush()
+	if err != nil {
+		if ctx.Err() != nil {
+			// Report the step that was running as failed, so its
+			// pull_on_fail files still get pulled
+			tracker.FinishInterrupted(exitCode)
+			return tracker.Result(), errors.WrapWithCode(err, errors.ErrExec,
+				"task execution canceled",
+				"The task was interrupted (e.g. Ctrl+C).")
+		}
+		return nil, err
+	}
+
+	// The script died without reporting a step result: setup failed before
+	// the first step, or the connection dropped mid-step. Charge the exit
+	// code to the step that was running (or the first one).
+	tracker.FinishInterrupted(exitCode)
+
+	return tracker.Result(), nil
+}
+
+// buildStepsScript compiles steps into a single shell script. Each step runs
+// in a subshell so a 'cd' or 'exit' in one step doesn't leak into the next,
+// matching how separately executed steps behave. Steps marked in skip (which
+// may be nil) only report that they were skipped.
+func buildStepsScript(steps []config.TaskStep, skip []bool, env map[string]string, workDir string, setupCommands []string) string {
+	var b strings.Builder
+
+	// Working directory and setup commands must succeed, same as buildCommand
+	var prefix []string
+	if workDir != "" {
+		prefix = append(prefix, fmt.Sprintf("cd %s", util.ShellQuotePreserveTilde(config.ExpandRemote(workDir))))
+	}
+	prefix = append(prefix, setupCommands...)
+	if len(prefix) > 0 {
+		fmt.Fprintf(&b, "%s || exit $?\n", strings.Join(prefix, " && "))
+	}
+	if envPrefix := buildEnvPrefix(env); envPrefix != "" {
+		b.WriteString(envPrefix + "\n")
+	}
+
+	b.WriteString("__rr_rc=0\n")
+	for i, step := range steps {
+		stepNum := i + 1
+		if i < len(skip) && skip[i] {
+			fmt.Fprintf(&b, "printf '\\036RR_STEP skip %d\\n'\n", stepNum)
+			continue
+		}
+		fmt.Fprintf(&b, "printf '\\036RR_STEP start %d\\n'\n", stepNum)
+		if step.Dir != "" {
+			// A missing dir fails the step, leaving on_fail to decide
+			// whether the task goes on
+			fmt.Fprintf(&b, "if [ ! -d %s ]; then printf '\\036RR_STEP nodir %d\\n'; __rr_code=1; else\n", util.ShellQuote(step.Dir), stepNum)
+		}
+		fmt.Fprintf(&b, "(\n%s\n)\n", StepCommand(step))
+		b.WriteString("__rr_code=$?\n")
+		if step.Dir != "" {
+			b.WriteString("fi\n")
+		}
+		fmt.Fprintf(&b, "printf '\\036RR_STEP end %d %%d\\n' \"$__rr_code\"\n", stepNum)
+		if config.GetStepOnFail(step) == config.OnFailStop {
+			b.WriteString("if [ \"$__rr_code\" -ne 0 ]; then exit \"$__rr_code\"; fi\n")
+		} else {
+			b.WriteString("if [ \"$__rr_code\" -ne 0 ]; then __rr_rc=$__rr_code; fi\n")
+		}
+	}
+	b.WriteString("exit \"$__rr_rc\"\n")
+
+	return b.String()
+}
+
+// stepTracker is an io.Writer that sits in front of the task's stdout. It
+// passes command output through, strips step marker lines, and turns them
+// into StepHandler callbacks and step results.
+type stepTracker struct {
+	mu      sync.Mutex
+	out     io.Writer
+	steps   []config.TaskStep
+	handler StepHandler
+	gate    *quietGate // Holds back quiet steps' output (nil if none are quiet)
+	skip    []bool     // Steps whose if: was false, or that finished in a resumed run (nil if none)
+	done    int        // How many steps finished in the run being resumed
+
+	hostName string // Where the steps run, for the missing dir message
+	workDir  string // The remote project root step dirs are relative to
+
+	pending []byte // Partial marker line waiting for its newline
+	current int    // 1-indexed step that's running (0 if none)
+	started time.Time
+	result  *TaskResult
+}
+
+func newStepTracker(steps []config.TaskStep, handler StepHandler, out io.Writer) *stepTracker {
+	return &stepTracker{
+		out:     out,
+		steps:   steps,
+		handler: handler,
+		result: &TaskResult{
+			StepResults: make([]StepResult, 0, len(steps)),
+			FailedStep:  -1,
+		},
+	}
+}
+
+// Write implements io.Writer.
+func (t *stepTracker) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	data := p
+	if len(t.pending) > 0 {
+		data = append(t.pending, p...)
+		t.pending = nil
+	}
+
+	for len(data) > 0 {
+		idx := bytes.IndexByte(data, stepMarker[0])
+		if idx == -1 {
+			t.emit(data)
+			break
+		}
+		t.emit(data[:idx])
+		data = data[idx:]
+
+		end := bytes.IndexByte(data, '\n')
+		if end == -1 {
+			// Wait for the rest of the line, unless it's clearly not a marker
+			if bytes.HasPrefix([]byte(stepMarker), data) || bytes.HasPrefix(data, []byte(stepMarker)) {
+				t.pending = append([]byte(nil), data...)
+			} else {
+				t.emit(data)
+			}
+			break
+		}
+
+		line := data[:end]
+		if !t.handleMarker(string(line)) {
+			t.emit(data[:end+1])
+		}
+		data = data[end+1:]
+	}
+
+	return len(p), nil
+}
+
+// Flush writes out any partial line held back as a possible marker.
+func (t *stepTracker) Flush() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.pending) > 0 {
+		t.emit(t.pending)
+		t.pending = nil
+	}
+}
+
+func (t *stepTracker) emit(data []byte) {
+	if len(data) > 0 && t.out != nil {
+		_, _ = t.out.Write(data)
+	}
+}
+
+// handleMarker processes a marker line. Returns false if line isn't a
+// well-formed marker, in which case it's passed through as output.
+func (t *stepTracker) handleMarker(line string) bool {
+	if !strings.HasPrefix(line, stepMarker) {
+		return false
+	}
+	fields := strings.Fields(strings.TrimPrefix(line, stepMarker))
+	if len(fields) < 2 {
+		return false
+	}
+	stepNum, err := strconv.Atoi(fields[1])
+	if err != nil || stepNum < 1 || stepNum > len(t.steps) {
+		return false
+	}
+
+	switch fields[0] {
+	case "start":
+		t.startStep(stepNum)
+	case "end":
+		if len(fields) != 3 {
+			return false
+		}
+		exitCode, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return false
+		}
+		t.endStep(stepNum, exitCode)
+	case "skip":
+		t.skipStep(stepNum)
+	case "nodir":
+		t.reportMissingDir(stepNum)
+	default:
+		return false
+	}
+	return true
+}
+
+func (t *stepTracker) startStep(stepNum int) {
+	t.current = stepNum
+	t.started = time.Now()
+	if t.handler != nil {
+		t.handler.OnStepStart(stepNum, len(t.steps), t.steps[stepNum-1])
+	}
+	t.gate.beginStep(t.steps[stepNum-1])
+}
+
+func (t *stepTracker) endStep(stepNum, exitCode int) {
+	step := t.steps[stepNum-1]
+	t.current = 0
+	t.gate.end(exitCode != 0)
+
+	t.result.StepResults = append(t.result.StepResults, StepResult{
+		Name:     stepDisplayName(step, stepNum),
+		ExitCode: exitCode,
+		OnFail:   config.GetStepOnFail(step),
+	})
+	if exitCode != 0 {
+		if t.result.FailedStep == -1 {
+			t.result.FailedStep = len(t.result.StepResults) - 1
+		}
+		t.result.ExitCode = exitCode
+	}
+
+	if t.handler != nil {
+		t.handler.OnStepComplete(stepNum, len(t.steps), step, time.Since(t.started), exitCode)
+	}
+}
+
+func (t *stepTracker) skipStep(stepNum int) {
+	step := t.steps[stepNum-1]
+	resumed := stepNum <= t.done
+	t.result.StepResults = append(t.result.StepResults, StepResult{
+		Name:    stepDisplayName(step, stepNum),
+		OnFail:  config.GetStepOnFail(step),
+		Skipped: !resumed,
+		Resumed: resumed,
+	})
+	if t.handler != nil && !resumed {
+		t.handler.OnStepSkipped(stepNum, len(t.steps), step)
+	}
+}
+
+// FinishInterrupted records a failure for a step the script never reported
+// finishing. Does nothing if the script exited cleanly or every started step
+// reported its result.
+func (t *stepTracker) FinishInterrupted(exitCode int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if exitCode == 0 {
+		return
+	}
+	if t.current == 0 {
+		if len(t.result.StepResults) > 0 {
+			return
+		}
+		first := 1
+		for first <= len(t.skip) && t.skip[first-1] {
+			first++
+		}
+		if first > len(t.steps) {
+			return
+		}
+		t.startStep(first)
+	}
+	t.endStep(t.current, exitCode)
+}
+
+// reportMissingDir explains, in the step's output, that its dir doesn't
+// exist on the host. The script fails the step with exit code 1.
+func (t *stepTracker) reportMissingDir(stepNum int) {
+	step := t.steps[stepNum-1]
+	dir := step.Dir
+	if t.workDir != "" {
+		dir = path.Join(config.ExpandRemote(t.workDir), step.Dir)
+	}
+	err := errors.New(errors.ErrExec,
+		fmt.Sprintf("Step '%s' wants to run in '%s', but %s doesn't exist on %s", stepDisplayName(step, stepNum), step.Dir, dir, t.hostName),
+		"Step dirs are relative to the project root. Check the path, and that it isn't excluded from sync.")
+	t.emit([]byte(err.Error() + "\n"))
+}
+
+// Result returns the step results collected so far.
+func (t *stepTracker) Result() *TaskResult {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.result
+}
+
+// stepDisplayName returns the step's name, or "step N" if it has none.
+func stepDisplayName(step config.TaskStep, stepNum int) string {
+	if step.Name != "" {
+		return step.Name
+	}
+	return fmt.Sprintf("step %d", stepNum)
+}
@@ -0,0 +1,334 @@
+package exec
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/host"
+	sshtesting "github.com/rileyhilliard/rr/pkg/sshutil/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// shellClient is a fake SSH client that runs commands in a local shell, so
+// step scripts execute for real. It records every command it's given.
+type shellClient struct {
+	*sshtesting.MockClient
+	commands []string
+}
+
+func (c *shellClient) ExecStreamContext(_ context.Context, cmd string, stdout, stderr io.Writer) (int, error) {
+	c.commands = append(c.commands, cmd)
+	return ExecuteLocal(cmd, "", stdout, stderr)
+}
+
+func createShellConn(t *testing.T) (*host.Connection, *shellClient) {
+	t.Setenv("SHELL", "/bin/sh")
+	client := &shellClient{MockClient: sshtesting.NewMockClient("remote")}
+	return &host.Connection{Name: "remote", Alias: "remote", Client: client}, client
+}
+
+// recordingStepHandler records step callbacks in order.
+type recordingStepHandler struct {
+	events []string
+}
+
+func (h *recordingStepHandler) OnStepStart(stepNum, totalSteps int, step config.TaskStep) {
+	h.events = append(h.events, "start "+step.Name)
+}
+
+func (h *recordingStepHandler) OnStepComplete(stepNum, totalSteps int, step config.TaskStep, duration time.Duration, exitCode int) {
+	state := "ok"
+	if exitCode != 0 {
+		state = "fail"
+	}
+	h.events = append(h.events, "end "+step.Name+" "+state)
+}
+
+func (h *recordingStepHandler) OnStepSkipped(stepNum, totalSteps int, step config.TaskStep) {
+	h.events = append(h.events, "skip "+step.Name)
+}
+
+func TestExecuteTask_RemoteStepsRunAsOneScript(t *testing.T) {
+	conn, client := createShellConn(t)
+	handler := &recordingStepHandler{}
+	task := &config.TaskConfig{
+		Steps: []config.TaskStep{
+			{Name: "first", Run: "echo one"},
+			{Name: "second", Run: "echo $GREETING"},
+			{Name: "third", Run: "echo three"},
+		},
+	}
+
+	var stdout, stderr bytes.Buffer
+	result, err := ExecuteTask(context.Background(), conn, task, nil, map[string]string{"GREETING": "hello"}, "", &stdout, &stderr,
+		&TaskExecOptions{StepHandler: handler})
+
+	require.NoError(t, err)
+	assert.Len(t, client.commands, 1, "all steps should share one exec")
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, -1, result.FailedStep)
+	require.Len(t, result.StepResults, 3)
+
+	// Markers are stripped from output
+	assert.Equal(t, "one\nhello\nthree\n", stdout.String())
+	assert.Equal(t, []string{
+		"start first", "end first ok",
+		"start second", "end second ok",
+		"start third", "end third ok",
+	}, handler.events)
+}
+
+func TestExecuteTask_RemoteStepsOnFail(t *testing.T) {
+	conn, _ := createShellConn(t)
+	handler := &recordingStepHandler{}
+	task := &config.TaskConfig{
+		Steps: []config.TaskStep{
+			{Name: "lint", Run: "exit 3", OnFail: config.OnFailContinue},
+			{Name: "test", Run: "exit 2"},
+			{Name: "never", Run: "echo never"},
+		},
+	}
+
+	var stdout, stderr bytes.Buffer
+	result, err := ExecuteTask(context.Background(), conn, task, nil, nil, "", &stdout, &stderr,
+		&TaskExecOptions{StepHandler: handler})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.ExitCode)
+	assert.Equal(t, 0, result.FailedStep)
+	require.Len(t, result.StepResults, 2)
+	assert.Equal(t, 3, result.StepResults[0].ExitCode)
+	assert.Equal(t, 2, result.StepResults[1].ExitCode)
+	assert.NotContains(t, stdout.String(), "never")
+	assert.Equal(t, []string{"start lint", "end lint fail", "start test", "end test fail"}, handler.events)
+}
+
+func TestExecuteTask_RemoteStepsSkipped(t *testing.T) {
+	conn, client := createShellConn(t)
+	handler := &recordingStepHandler{}
+	task := &config.TaskConfig{
+		Steps: []config.TaskStep{
+			{Name: "ci-only", Run: "echo ci", If: "env.CI == 'true'"},
+			{Name: "always", Run: "echo always"},
+			{Name: "local-only", Run: "echo local", If: "env.CI != 'true'"},
+		},
+	}
+
+	var stdout, stderr bytes.Buffer
+	result, err := ExecuteTask(context.Background(), conn, task, nil, map[string]string{"CI": "true"}, "", &stdout, &stderr,
+		&TaskExecOptions{StepHandler: handler})
+
+	require.NoError(t, err)
+	assert.Len(t, client.commands, 1)
+	assert.NotContains(t, client.commands[0], "echo local", "skipped steps aren't sent")
+	assert.Equal(t, "ci\nalways\n", stdout.String())
+	require.Len(t, result.StepResults, 3)
+	assert.True(t, result.StepResults[2].Skipped)
+	assert.Equal(t, []string{
+		"start ci-only", "end ci-only ok",
+		"start always", "end always ok",
+		"skip local-only",
+	}, handler.events)
+}
+
+func TestExecuteTask_RemoteStepsResumed(t *testing.T) {
+	conn, _ := createShellConn(t)
+	handler := &recordingStepHandler{}
+	task := &config.TaskConfig{
+		Steps: []config.TaskStep{
+			{Name: "build", Run: "echo build"},
+			{Name: "test", Run: "echo test"},
+		},
+	}
+
+	var stdout, stderr bytes.Buffer
+	result, err := ExecuteTask(context.Background(), conn, task, nil, nil, "", &stdout, &stderr,
+		&TaskExecOptions{StepHandler: handler, Done: 1})
+
+	require.NoError(t, err)
+	assert.Equal(t, "test\n", stdout.String())
+	require.Len(t, result.StepResults, 2)
+	assert.True(t, result.StepResults[0].Resumed)
+	assert.Equal(t, []string{"start test", "end test ok"}, handler.events, "finished steps aren't reported again")
+}
+
+func TestExecuteTask_RemoteRawSteps(t *testing.T) {
+	conn, _ := createShellConn(t)
+	task := &config.TaskConfig{
+		Steps: []config.TaskStep{{Name: "literal", Run: `echo '$HOME' "a  b" *`}},
+		Raw:   true,
+	}
+
+	var stdout, stderr bytes.Buffer
+	result, err := ExecuteTask(context.Background(), conn, task, nil, nil, "", &stdout, &stderr, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, "$HOME a  b *\n", stdout.String())
+}
+
+func TestExecuteTask_RemoteStepsIsolated(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "packages", "api"), 0755))
+	conn, _ := createShellConn(t)
+	task := &config.TaskConfig{
+		Steps: []config.TaskStep{
+			{Name: "in-subdir", Run: "pwd", Dir: "packages/api"},
+			{Name: "cd-away", Run: "cd / && pwd"},
+			{Name: "at-root", Run: "pwd"},
+		},
+	}
+
+	var stdout, stderr bytes.Buffer
+	result, err := ExecuteTask(context.Background(), conn, task, nil, nil, root, &stdout, &stderr, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.ExitCode)
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.True(t, strings.HasSuffix(lines[0], filepath.Join("packages", "api")), "got %s", lines[0])
+	assert.Equal(t, "/", lines[1])
+	assert.False(t, strings.HasSuffix(lines[2], "api"), "dir should not leak into the next step")
+	assert.NotEqual(t, "/", lines[2], "cd should not leak into the next step")
+}
+
+func TestExecuteTask_RemoteStepDirMissing(t *testing.T) {
+	conn, _ := createShellConn(t)
+	task := &config.TaskConfig{
+		Steps: []config.TaskStep{
+			{Name: "setup", Run: "echo ready"},
+			{Name: "build", Run: "make", Dir: "does/not/exist", OnFail: config.OnFailContinue},
+			{Name: "after", Run: "echo after"},
+			{Name: "deploy", Run: "make", Dir: "also/missing"},
+			{Name: "never", Run: "echo never"},
+		},
+	}
+
+	var stdout, stderr bytes.Buffer
+	result, err := ExecuteTask(context.Background(), conn, task, nil, nil, t.TempDir(), &stdout, &stderr, nil)
+
+	require.NoError(t, err, "a missing dir fails the step, not the whole task")
+	assert.Equal(t, 1, result.ExitCode)
+	assert.Contains(t, stdout.String(), "ready")
+	assert.Contains(t, stdout.String(), "Step 'build' wants to run in 'does/not/exist'")
+	assert.Contains(t, stdout.String(), "after", "on_fail: continue goes on to the next step")
+	assert.NotContains(t, stdout.String(), "never", "on_fail: stop ends the task")
+	require.Len(t, result.StepResults, 4)
+	assert.Equal(t, 1, result.StepResults[1].ExitCode)
+	assert.Equal(t, 1, result.FailedStep)
+}
+
+func TestExecuteTask_RemoteSetupFails(t *testing.T) {
+	conn, _ := createShellConn(t)
+	handler := &recordingStepHandler{}
+	task := &config.TaskConfig{
+		Steps: []config.TaskStep{
+			{Name: "first", Run: "echo one"},
+			{Name: "second", Run: "echo two"},
+		},
+	}
+
+	var stdout, stderr bytes.Buffer
+	result, err := ExecuteTask(context.Background(), conn, task, nil, nil, "", &stdout, &stderr,
+		&TaskExecOptions{SetupCommands: []string{"exit 4"}, StepHandler: handler})
+
+	require.NoError(t, err)
+	assert.Equal(t, 4, result.ExitCode)
+	assert.Equal(t, 0, result.FailedStep)
+	assert.Empty(t, stdout.String())
+	assert.Equal(t, []string{"start first", "end first fail"}, handler.events)
+}
+
+// interruptedClient is a fake SSH client whose command gets interrupted
+// partway through the first step.
+type interruptedClient struct {
+	*sshtesting.MockClient
+	cancel context.CancelFunc
+}
+
+func (c *interruptedClient) ExecStreamContext(ctx context.Context, _ string, stdout, _ io.Writer) (int, error) {
+	io.WriteString(stdout, "\x1eRR_STEP start 1\nworking\n") //nolint:errcheck // Test fake
+	c.cancel()
+	return 130, ctx.Err()
+}
+
+func TestExecuteTask_RemoteStepsInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	conn := &host.Connection{Name: "remote", Client: &interruptedClient{MockClient: sshtesting.NewMockClient("remote"), cancel: cancel}}
+	handler := &recordingStepHandler{}
+	task := &config.TaskConfig{
+		Steps: []config.TaskStep{
+			{Name: "first", Run: "make e2e"},
+			{Name: "second", Run: "echo two"},
+		},
+	}
+
+	var stdout, stderr bytes.Buffer
+	result, err := ExecuteTask(ctx, conn, task, nil, nil, "", &stdout, &stderr, &TaskExecOptions{StepHandler: handler})
+
+	require.Error(t, err)
+	// The interrupted step still completes, as a failure, so its
+	// pull_on_fail files get pulled
+	assert.Equal(t, []string{"start first", "end first fail"}, handler.events)
+	require.Len(t, result.StepResults, 1)
+	assert.Equal(t, 130, result.StepResults[0].ExitCode)
+	assert.Equal(t, "working\n", stdout.String())
+}
+
+func TestExecuteOnCancel(t *testing.T) {
+	conn, client := createShellConn(t)
+	dir := t.TempDir()
+	task := &config.TaskConfig{Run: "make e2e", OnCancel: "echo \"down $STACK\" > cleaned"}
+
+	var stdout, stderr bytes.Buffer
+	exitCode, err := ExecuteOnCancel(conn, task, map[string]string{"STACK": "e2e"}, dir, []string{"true"}, &stdout, &stderr)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, exitCode)
+	require.Len(t, client.commands, 1)
+	assert.Contains(t, client.commands[0], "true && ")
+
+	data, err := os.ReadFile(filepath.Join(dir, "cleaned"))
+	require.NoError(t, err)
+	assert.Equal(t, "down e2e\n", string(data))
+}
+
+func TestStepTracker_SplitWrites(t *testing.T) {
+	steps := []config.TaskStep{{Name: "only", Run: "true"}}
+	handler := &recordingStepHandler{}
+	var out bytes.Buffer
+	tracker := newStepTracker(steps, handler, &out)
+
+	stream := "before\x1eRR_STEP start 1\nhello\nno newline \x1eRR_STEP end 1 0\nstray \x1e byte\n"
+	for i := 0; i < len(stream); i++ {
+		_, err := tracker.Write([]byte{stream[i]})
+		require.NoError(t, err)
+	}
+	tracker.Flush()
+
+	assert.Equal(t, "beforehello\nno newline stray \x1e byte\n", out.String())
+	assert.Equal(t, []string{"start only", "end only ok"}, handler.events)
+	require.Len(t, tracker.Result().StepResults, 1)
+}
+
+func TestBuildStepsScript(t *testing.T) {
+	script := buildStepsScript([]config.TaskStep{
+		{Run: "make lint", OnFail: config.OnFailContinue},
+		{Run: "make test", Dir: "api"},
+	}, nil, nil, "~/projects/app", []string{"source ~/.env"})
+
+	assert.True(t, strings.HasPrefix(script, "cd ~/'projects/app' && source ~/.env || exit $?\n"))
+	assert.Contains(t, script, "(\nmake lint\n)\n")
+	assert.Contains(t, script, "if [ ! -d 'api' ]; then printf '\\036RR_STEP nodir 2\\n'; __rr_code=1; else\n(\ncd 'api' && make test\n)\n__rr_code=$?\nfi\n")
+	assert.Contains(t, script, `if [ "$__rr_code" -ne 0 ]; then __rr_rc=$__rr_code; fi`)
+	assert.Contains(t, script, `if [ "$__rr_code" -ne 0 ]; then exit "$__rr_code"; fi`)
+}
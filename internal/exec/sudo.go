@@ -0,0 +1,196 @@
+package exec
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"maps"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/rileyhilliard/rr/internal/logger"
+)
+
+// newSudoPrompt returns the password prompt for sudo to show in one command
+// of a task with sudo: set. It's passed in SUDO_PROMPT, so no locale or
+// sudoers setting can change what to look for, and it holds a random nonce,
+// so nothing else the command prints can pass for it and get the password.
+func newSudoPrompt() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return "<rr-sudo-" + hex.EncodeToString(b) + ">"
+}
+
+// SudoPassword returns the password to answer a sudo prompt with. retry is
+// set when sudo asks again during the same command, which means the last
+// password was wrong.
+type SudoPassword func(retry bool) (string, error)
+
+// ptyStreamer is an SSH client that can run a command on a terminal. The
+// real client can; test doubles may not.
+type ptyStreamer interface {
+	ExecPTYContext(ctx context.Context, cmd string, stdin io.Reader, stdout io.Writer) (int, error)
+}
+
+// sudoEnv returns env with SUDO_PROMPT set to prompt.
+func sudoEnv(env map[string]string, prompt string) map[string]string {
+	out := maps.Clone(env)
+	if out == nil {
+		out = make(map[string]string, 1)
+	}
+	out["SUDO_PROMPT"] = prompt
+	return out
+}
+
+// execSudo runs cmd on the connection's host on a terminal, so sudo can ask
+// for a password, and answers each prompt, the SUDO_PROMPT cmd sets, with
+// one from password. The password goes straight to the command and is never
+// written to the output or the log.
+func execSudo(ctx context.Context, conn *host.Connection, cmd, prompt string, password SudoPassword, stdout io.Writer) (int, error) {
+	client, ok := conn.Client.(ptyStreamer)
+	if !ok {
+		return -1, errors.New(errors.ErrExec,
+			"sudo needs a terminal on the host, and this connection can't open one",
+			"Run the task without 'sudo', or give the host passwordless sudo.")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stdin, answers := io.Pipe()
+	defer answers.Close()
+
+	responder := &sudoResponder{out: stdout, prompt: []byte(prompt), answers: answers, password: password, cancel: cancel}
+	exitCode, err := client.ExecPTYContext(ctx, cmd, stdin, responder)
+	responder.Flush()
+	if responder.err != nil {
+		return -1, responder.err
+	}
+	return exitCode, err
+}
+
+// sudoResponder passes a command's output through to out, taking out each
+// prompt and writing the password to the command's input in its place.
+type sudoResponder struct {
+	out      io.Writer
+	prompt   []byte    // The SUDO_PROMPT the command was given
+	answers  io.Writer // The command's input
+	password SudoPassword
+	cancel   context.CancelFunc // Stops the command when there's no password to give
+
+	pending []byte // Output held back since it may be the start of a prompt
+	asked   bool
+	err     error
+}
+
+func (r *sudoResponder) Write(p []byte) (int, error) {
+	r.pending = append(r.pending, p...)
+	for {
+		i := bytes.Index(r.pending, r.prompt)
+		if i < 0 {
+			break
+		}
+		if _, err := r.out.Write(r.pending[:i]); err != nil {
+			return 0, err
+		}
+		r.pending = r.pending[i+len(r.prompt):]
+		if r.err == nil {
+			r.answer()
+		}
+	}
+
+	keep := promptPrefixLen(r.pending, r.prompt)
+	if _, err := r.out.Write(r.pending[:len(r.pending)-keep]); err != nil {
+		return 0, err
+	}
+	r.pending = append(r.pending[:0], r.pending[len(r.pending)-keep:]...)
+	return len(p), nil
+}
+
+// answer writes the password for a prompt to the command, or stops the
+// command if there isn't one.
+func (r *sudoResponder) answer() {
+	if r.password == nil {
+		r.fail(errors.New(errors.ErrExec,
+			"sudo asked for a password, but rr has no way to ask you for it",
+			"Run the task from a terminal, or give the host passwordless sudo."))
+		return
+	}
+	pw, err := r.password(r.asked)
+	r.asked = true
+	if err != nil {
+		r.fail(err)
+		return
+	}
+	if _, err := io.WriteString(r.answers, pw+"\n"); err != nil {
+		r.fail(errors.WrapWithCode(err, errors.ErrExec,
+			"Couldn't send the sudo password",
+			"The connection might have dropped. Try again."))
+	}
+}
+
+func (r *sudoResponder) fail(err error) {
+	r.err = err
+	r.cancel()
+}
+
+// Flush writes out anything held back.
+func (r *sudoResponder) Flush() {
+	if len(r.pending) > 0 {
+		_, _ = r.out.Write(r.pending)
+		r.pending = nil
+	}
+}
+
+// promptPrefixLen returns the length of the longest end of b that's the
+// start of prompt.
+func promptPrefixLen(b, prompt []byte) int {
+	for n := min(len(b), len(prompt)-1); n > 0; n-- {
+		if bytes.HasPrefix(prompt, b[len(b)-n:]) {
+			return n
+		}
+	}
+	return 0
+}
+
+// executeSudoCommand is executeCommand for a remote command that may ask for
+// a sudo password. Its stderr comes out on stdout, through the terminal.
+func executeSudoCommand(ctx context.Context, conn *host.Connection, cmd string, env map[string]string, workDir string, opts *TaskExecOptions, stdout io.Writer) (int, error) {
+	prompt := newSudoPrompt()
+	fullCmd := buildCommand(cmd, sudoEnv(env, prompt), workDir, opts.SetupCommands, false)
+	logger.Verbosef(logger.LevelPhases, "exec", "running %q on %s with sudo", cmd, conn.Name)
+	return execSudo(ctx, conn, fullCmd, prompt, opts.SudoPassword, stdout)
+}
+
+// errLocalSudo is returned for a task with sudo: set that runs locally.
+// rr answers sudo prompts only on remote hosts, where it runs the command
+// on a terminal it controls.
+func errLocalSudo() error {
+	return errors.New(errors.ErrExec,
+		"This task has sudo: set, and rr only answers sudo prompts on remote hosts",
+		"Run the task on a remote host, or remove sudo: and run 'sudo -v' first so sudo doesn't need to ask.")
+}
+
+// withSudo returns steps with sudo set on each of them, for a task with
+// sudo: set.
+func withSudo(steps []config.TaskStep) []config.TaskStep {
+	out := make([]config.TaskStep, len(steps))
+	for i, step := range steps {
+		step.Sudo = true
+		out[i] = step
+	}
+	return out
+}
+
+// stepsUseSudo reports whether any step that will run has sudo set. The
+// whole step script then runs on a terminal.
+func stepsUseSudo(steps []config.TaskStep, skip []bool) bool {
+	for i, step := range steps {
+		if step.Sudo && (skip == nil || !skip[i]) {
+			return true
+		}
+	}
+	return false
+}
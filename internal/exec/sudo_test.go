@@ -0,0 +1,219 @@
+package exec
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ptyClient is a shellClient that can also run commands "on a terminal",
+// passing their input through, so sudo prompts can be answered.
+type ptyClient struct {
+	*shellClient
+	ptyCommands []string
+}
+
+func (c *ptyClient) ExecPTYContext(ctx context.Context, cmd string, stdin io.Reader, stdout io.Writer) (int, error) {
+	c.ptyCommands = append(c.ptyCommands, cmd)
+	// A real file, so the shell exiting doesn't wait on more input. Closing
+	// it on cancel ends a read that's waiting for a password.
+	r, w, err := os.Pipe()
+	if err != nil {
+		return -1, err
+	}
+	defer r.Close()
+	go func() {
+		_, _ = io.Copy(w, stdin)
+		w.Close()
+	}()
+	stop := context.AfterFunc(ctx, func() { w.Close() })
+	defer stop()
+	return ExecuteLocalWithInput(cmd, "", r, stdout, stdout)
+}
+
+func createPTYConn(t *testing.T) (*host.Connection, *ptyClient) {
+	conn, shell := createShellConn(t)
+	client := &ptyClient{shellClient: shell}
+	conn.Client = client
+	return conn, client
+}
+
+// fakeSudo prompts like sudo does with SUDO_PROMPT set, and reads a password.
+const fakeSudo = `printf "%s" "$SUDO_PROMPT"; read pw; `
+
+func TestExecuteTask_SudoAnswersPrompt(t *testing.T) {
+	conn, client := createPTYConn(t)
+	var retries []bool
+	task := &config.TaskConfig{Run: fakeSudo + `echo "got $pw"`, Sudo: true}
+
+	var stdout, stderr bytes.Buffer
+	result, err := ExecuteTask(context.Background(), conn, task, nil, nil, "", &stdout, &stderr, &TaskExecOptions{
+		SudoPassword: func(retry bool) (string, error) {
+			retries = append(retries, retry)
+			return "hunter2", nil
+		},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, "got hunter2\n", stdout.String(), "the prompt is taken out of the output")
+	assert.Equal(t, []bool{false}, retries)
+	assert.Len(t, client.ptyCommands, 1)
+	assert.Empty(t, client.commands, "sudo tasks run on a terminal")
+}
+
+func TestExecuteTask_SudoAsksAgainAfterWrongPassword(t *testing.T) {
+	conn, _ := createPTYConn(t)
+	task := &config.TaskConfig{Run: fakeSudo + `first=$pw; ` + fakeSudo + `echo "$first $pw"`, Sudo: true}
+
+	var stdout, stderr bytes.Buffer
+	_, err := ExecuteTask(context.Background(), conn, task, nil, nil, "", &stdout, &stderr, &TaskExecOptions{
+		SudoPassword: func(retry bool) (string, error) {
+			if retry {
+				return "right", nil
+			}
+			return "wrong", nil
+		},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "wrong right\n", stdout.String())
+}
+
+func TestExecuteTask_SudoWithoutPassword(t *testing.T) {
+	conn, _ := createPTYConn(t)
+	task := &config.TaskConfig{Run: fakeSudo + `echo "got $pw"`, Sudo: true}
+
+	var stdout, stderr bytes.Buffer
+	_, err := ExecuteTask(context.Background(), conn, task, nil, nil, "", &stdout, &stderr, nil)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no way to ask you for it")
+}
+
+func TestExecuteTask_SudoNeedsPTY(t *testing.T) {
+	conn, _ := createShellConn(t)
+	task := &config.TaskConfig{Run: "true", Sudo: true}
+
+	var stdout, stderr bytes.Buffer
+	_, err := ExecuteTask(context.Background(), conn, task, nil, nil, "", &stdout, &stderr, nil)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "can't open one")
+}
+
+func TestExecuteTask_SudoStep(t *testing.T) {
+	conn, client := createPTYConn(t)
+	handler := &recordingStepHandler{}
+	task := &config.TaskConfig{
+		Steps: []config.TaskStep{
+			{Name: "build", Run: "echo built"},
+			{Name: "install", Run: fakeSudo + `echo "installed with $pw"`, Sudo: true},
+		},
+	}
+
+	var stdout, stderr bytes.Buffer
+	result, err := ExecuteTask(context.Background(), conn, task, nil, nil, "", &stdout, &stderr, &TaskExecOptions{
+		StepHandler:  handler,
+		SudoPassword: func(bool) (string, error) { return "hunter2", nil },
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, "built\ninstalled with hunter2\n", stdout.String())
+	assert.Equal(t, []string{"start build", "end build ok", "start install", "end install ok"}, handler.events)
+	assert.Len(t, client.ptyCommands, 1, "the whole script runs on one terminal")
+}
+
+func TestExecuteTask_SkippedSudoStepRunsWithoutTerminal(t *testing.T) {
+	conn, client := createPTYConn(t)
+	task := &config.TaskConfig{
+		Steps: []config.TaskStep{
+			{Name: "build", Run: "echo built"},
+			{Name: "install", Run: "sudo make install", Sudo: true, If: "env.INSTALL == 'yes'"},
+		},
+	}
+
+	var stdout, stderr bytes.Buffer
+	_, err := ExecuteTask(context.Background(), conn, task, nil, nil, "", &stdout, &stderr, nil)
+
+	require.NoError(t, err)
+	assert.Empty(t, client.ptyCommands)
+	assert.Len(t, client.commands, 1)
+}
+
+func TestSudoResponder_PromptSplitAcrossWrites(t *testing.T) {
+	var out, answers bytes.Buffer
+	prompt := newSudoPrompt()
+	r := &sudoResponder{
+		out:      &out,
+		prompt:   []byte(prompt),
+		answers:  &answers,
+		password: func(bool) (string, error) { return "pw", nil },
+		cancel:   func() {},
+	}
+
+	half := len(prompt) / 2
+	_, _ = r.Write([]byte("before " + prompt[:half]))
+	assert.Equal(t, "before ", out.String(), "what may be a prompt is held back")
+	_, _ = r.Write([]byte(prompt[half:] + "after <tag"))
+	r.Flush()
+
+	assert.Equal(t, "before after <tag", out.String())
+	assert.Equal(t, "pw\n", answers.String())
+}
+
+func TestNewSudoPrompt_IsUnique(t *testing.T) {
+	a, b := newSudoPrompt(), newSudoPrompt()
+	assert.Regexp(t, `^<rr-sudo-[0-9a-f]{16}>$`, a)
+	assert.NotEqual(t, a, b)
+}
+
+func TestExecuteTask_SudoIgnoresOtherPrompts(t *testing.T) {
+	conn, _ := createPTYConn(t)
+	// Output that looks like a prompt, but isn't the one this command got
+	task := &config.TaskConfig{Run: `echo "<rr-sudo-0000000000000000>"; ` + fakeSudo + `echo "got $pw"`, Sudo: true}
+
+	var asked int
+	var stdout, stderr bytes.Buffer
+	_, err := ExecuteTask(context.Background(), conn, task, nil, nil, "", &stdout, &stderr, &TaskExecOptions{
+		SudoPassword: func(bool) (string, error) {
+			asked++
+			return "hunter2", nil
+		},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, asked, "only the real prompt is answered")
+	assert.Equal(t, "<rr-sudo-0000000000000000>\ngot hunter2\n", stdout.String())
+}
+
+func TestExecuteTask_SudoLocally(t *testing.T) {
+	tests := []struct {
+		name string
+		task *config.TaskConfig
+	}{
+		{name: "task", task: &config.TaskConfig{Run: "true", Sudo: true}},
+		{name: "step", task: &config.TaskConfig{Steps: []config.TaskStep{
+			{Name: "build", Run: "true"},
+			{Name: "install", Run: "true", Sudo: true},
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			_, err := ExecuteTask(context.Background(), createLocalConn(), tt.task, nil, nil, "", &stdout, &stderr, nil)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "only answers sudo prompts on remote hosts")
+			assert.Empty(t, stdout.String(), "nothing runs")
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/rileyhilliard/rr/internal/config"
 	"github.com/rileyhilliard/rr/internal/errors"
 	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/rileyhilliard/rr/internal/logger"
 	"github.com/rileyhilliard/rr/internal/util"
 )
 
@@ -26,6 +27,8 @@ type StepResult struct {
 	Name     string // Step name (or "run" for single-command tasks)
 	ExitCode int    // Exit code from the step
 	OnFail   string // The on_fail behavior for this step
+	Skipped  bool   // The step's if: was false, so it didn't run
+	Resumed  bool   // The step finished in the run being resumed, so it didn't run again
 }
 
 // TaskExecOptions contains options for task execution.
@@ -36,6 +39,23 @@ type TaskExecOptions struct {
 	// StepHandler is called before and after each step in multi-step tasks.
 	// If nil, steps run silently without progress output.
 	StepHandler StepHandler
+
+	// Idle reports (and optionally stops) a task that goes quiet. If nil,
+	// tasks may run silently for as long as they like.
+	Idle *IdleOptions
+
+	// ProjectRoot is the local project root, where steps' if: expressions
+	// look for changed files. Empty means the current directory.
+	ProjectRoot string
+
+	// Done is how many steps finished in an earlier run that's being resumed
+	// ('rr resume'). They don't run again and aren't reported to the
+	// StepHandler.
+	Done int
+
+	// SudoPassword answers the password prompts of remote tasks and steps
+	// with sudo: set. If nil, a prompt fails the task.
+	SudoPassword SudoPassword
 }
 
 // StepHandler receives callbacks during multi-step task execution.
@@ -47,6 +67,10 @@ type StepHandler interface {
 	// OnStepComplete is called after a step finishes.
 	// duration is how long the step took, exitCode is the result.
 	OnStepComplete(stepNum, totalSteps int, step config.TaskStep, duration time.Duration, exitCode int)
+
+	// OnStepSkipped is called in place of both for a step whose if: is
+	// false.
+	OnStepSkipped(stepNum, totalSteps int, step config.TaskStep)
 }
 
 // ExecuteTask runs a task on the given connection.
@@ -64,14 +88,61 @@ func ExecuteTask(ctx context.Context, conn *host.Connection, task *config.TaskCo
 		opts = &TaskExecOptions{}
 	}
 
+	// Quiet steps hold their output back below idle detection, which still
+	// needs to see it arrive
+	gate := newQuietGate(task, stdout, stderr)
+	if gate != nil {
+		stdout, stderr = gate.Stdout(), gate.Stderr()
+	}
+
+	if opts.Idle != nil {
+		idleCtx, idleStdout, idleStderr, stopIdle := WatchIdle(ctx, conn, opts.Idle, stdout, stderr)
+		result, err := executeTask(idleCtx, conn, task, args, env, workDir, idleStdout, idleStderr, opts, gate)
+		if stopIdle() && ctx.Err() == nil {
+			if result == nil {
+				result = &TaskResult{FailedStep: -1}
+			}
+			result.ExitCode = IdleExitCode
+			return result, nil
+		}
+		return result, err
+	}
+	return executeTask(ctx, conn, task, args, env, workDir, stdout, stderr, opts, gate)
+}
+
+// executeTask is ExecuteTask without idle detection. gate, if not nil, is
+// what stdout and stderr end up writing to.
+func executeTask(ctx context.Context, conn *host.Connection, task *config.TaskConfig, args []string, env map[string]string, workDir string, stdout, stderr io.Writer, opts *TaskExecOptions, gate *quietGate) (*TaskResult, error) {
+	if !conn.IsLocal {
+		opts = withPriority(task, opts)
+	}
+
 	// Single-command task
 	if task.Run != "" {
 		cmd := task.Run
 		// Append extra args if provided
 		if len(args) > 0 {
-			cmd = cmd + " " + strings.Join(args, " ")
+			if task.Raw {
+				cmd = cmd + " " + util.ShellJoin(args)
+			} else {
+				cmd = cmd + " " + strings.Join(args, " ")
+			}
 		}
-		exitCode, err := executeCommand(ctx, conn, cmd, env, workDir, opts.SetupCommands, stdout, stderr)
+		cmd, err := TaskCommand(task, cmd)
+		if err != nil {
+			return nil, err
+		}
+		if task.Sudo && conn.IsLocal {
+			return nil, errLocalSudo()
+		}
+		gate.beginRun()
+		var exitCode int
+		if task.Sudo {
+			exitCode, err = executeSudoCommand(ctx, conn, cmd, env, workDir, opts, stdout)
+		} else {
+			exitCode, err = executeCommand(ctx, conn, cmd, env, workDir, opts.SetupCommands, stdout, stderr)
+		}
+		gate.end(err != nil || exitCode != 0)
 		if err != nil {
 			return nil, err
 		}
@@ -88,11 +159,94 @@ func ExecuteTask(ctx context.Context, conn *host.Connection, task *config.TaskCo
 			"Add a 'run' command or 'steps' to your task config.")
 	}
 
-	return executeSteps(ctx, conn, task.Steps, env, workDir, opts, stdout, stderr)
+	skip, err := SkippedSteps(task.Steps, env, opts.ProjectRoot)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < opts.Done && i < len(skip); i++ {
+		skip[i] = true
+	}
+	steps, err := TaskSteps(task, task.Steps)
+	if err != nil {
+		return nil, err
+	}
+
+	// Remote steps run as one script in a single SSH exec
+	if !conn.IsLocal {
+		if task.Sudo {
+			steps = withSudo(steps)
+		}
+		return executeStepsScript(ctx, conn, steps, skip, env, workDir, opts, stdout, stderr, gate)
+	}
+
+	if task.Sudo || stepsUseSudo(steps, skip) {
+		return nil, errLocalSudo()
+	}
+	return executeSteps(ctx, conn, steps, skip, env, workDir, opts, stdout, stderr, gate)
+}
+
+// SkippedSteps evaluates each step's if: expression on the local machine,
+// reporting which steps to skip. env.NAME sees the task's env over the local
+// environment, and files_changed sees git changes under projectRoot; they're
+// only listed if an expression asks.
+func SkippedSteps(steps []config.TaskStep, env map[string]string, projectRoot string) ([]bool, error) {
+	var changed []string
+	var changedKnown, listed bool
+	condEnv := &config.ConditionEnv{
+		Env: func(name string) string {
+			if v, ok := env[name]; ok {
+				return v
+			}
+			return os.Getenv(name)
+		},
+		ChangedFiles: func() ([]string, bool) {
+			if !listed {
+				listed = true
+				files, err := config.ChangedFiles(projectRoot)
+				if err != nil {
+					logger.Verbosef(logger.LevelPhases, "exec", "can't list changed files, so files_changed is true: %v", err)
+				}
+				changed, changedKnown = files, err == nil
+			}
+			return changed, changedKnown
+		},
+	}
+
+	skip := make([]bool, len(steps))
+	for i, step := range steps {
+		if step.If == "" {
+			continue
+		}
+		cond, err := config.ParseCondition(step.If)
+		if err != nil {
+			return nil, errors.New(errors.ErrConfig,
+				fmt.Sprintf("Step '%s' has an if: rr can't read: %v", stepDisplayName(step, i+1), err),
+				"Check the expression in your task config.")
+		}
+		skip[i] = !cond.Eval(condEnv)
+		if skip[i] {
+			logger.Verbosef(logger.LevelPhases, "exec", "skipping step '%s': %s is false", stepDisplayName(step, i+1), step.If)
+		}
+	}
+	return skip, nil
+}
+
+// OnCancelTimeout bounds a task's on_cancel command, so a cleanup that hangs
+// doesn't keep an interrupted run from exiting.
+const OnCancelTimeout = 30 * time.Second
+
+// ExecuteOnCancel runs a task's on_cancel command after the task was
+// interrupted, in the same dir and env, with the same setup commands, as the
+// task itself. It gets a fresh context, since the task's was canceled.
+func ExecuteOnCancel(conn *host.Connection, task *config.TaskConfig, env map[string]string, workDir string, setupCommands []string, stdout, stderr io.Writer) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), OnCancelTimeout)
+	defer cancel()
+	return executeCommand(ctx, conn, task.OnCancel, env, workDir, setupCommands, stdout, stderr)
 }
 
-// executeSteps runs multiple steps in sequence.
-func executeSteps(ctx context.Context, conn *host.Connection, steps []config.TaskStep, env map[string]string, workDir string, opts *TaskExecOptions, stdout, stderr io.Writer) (*TaskResult, error) {
+// executeSteps runs multiple steps in sequence, one command per step.
+// Used for local execution; remote tasks use executeStepsScript.
+func executeSteps(ctx context.Context, conn *host.Connection, steps []config.TaskStep, skip []bool, env map[string]string, workDir string, opts *TaskExecOptions, stdout, stderr io.Writer, gate *quietGate) (*TaskResult, error) {
 	result := &TaskResult{
 		StepResults: make([]StepResult, 0, len(steps)),
 		FailedStep:  -1,
@@ -112,12 +266,18 @@ func executeSteps(ctx context.Context, conn *host.Connection, steps []config.Tas
 
 		stepNum := i + 1
 		stepResult := StepResult{
-			Name:   step.Name,
+			Name:   stepDisplayName(step, stepNum),
 			OnFail: config.GetStepOnFail(step),
 		}
 
-		if step.Name == "" {
-			stepResult.Name = fmt.Sprintf("step %d", stepNum)
+		if skip[i] {
+			stepResult.Resumed = i < opts.Done
+			stepResult.Skipped = !stepResult.Resumed
+			result.StepResults = append(result.StepResults, stepResult)
+			if opts.StepHandler != nil && !stepResult.Resumed {
+				opts.StepHandler.OnStepSkipped(stepNum, totalSteps, step)
+			}
+			continue
 		}
 
 		// Notify handler that step is starting
@@ -126,12 +286,22 @@ func executeSteps(ctx context.Context, conn *host.Connection, steps []config.Tas
 		}
 
 		stepStart := time.Now()
-		exitCode, err := executeCommand(ctx, conn, step.Run, env, workDir, opts.SetupCommands, stdout, stderr)
-		stepDuration := time.Since(stepStart)
-
-		if err != nil {
-			return nil, err
+		var exitCode int
+		if dirErr := checkStepDir(step, stepResult.Name); dirErr != nil {
+			// A missing dir fails the step like a failed command, so
+			// on_fail still decides whether the task goes on
+			fmt.Fprintln(stderr, dirErr.Error())
+			exitCode = 1
+		} else {
+			var err error
+			gate.beginStep(step)
+			exitCode, err = executeCommand(ctx, conn, StepCommand(step), env, workDir, opts.SetupCommands, stdout, stderr)
+			gate.end(err != nil || exitCode != 0)
+			if err != nil {
+				return nil, err
+			}
 		}
+		stepDuration := time.Since(stepStart)
 
 		stepResult.ExitCode = exitCode
 		result.StepResults = append(result.StepResults, stepResult)
@@ -159,16 +329,83 @@ func executeSteps(ctx context.Context, conn *host.Connection, steps []config.Tas
 	return result, nil
 }
 
+// TaskCommand returns what a task runs for cmd, its run command with any
+// extra arguments, or a step's run command. With raw, cmd is split into
+// words that are each quoted, so the shell passes them on without
+// expanding anything. With shell, cmd runs through the task's shell.
+func TaskCommand(task *config.TaskConfig, cmd string) (string, error) {
+	if task.Raw {
+		words, err := util.SplitWords(cmd)
+		if err != nil {
+			return "", errors.WrapWithCode(err, errors.ErrConfig,
+				"Couldn't split the raw command into arguments",
+				"Check the quotes in the task's run command.")
+		}
+		cmd = util.ShellJoin(words)
+	}
+	if task.Shell != "" {
+		cmd = task.Shell + " " + util.ShellQuote(cmd)
+	}
+	return cmd, nil
+}
+
+// TaskSteps returns steps, the task's steps or some of them, with each run
+// command passed through TaskCommand.
+func TaskSteps(task *config.TaskConfig, steps []config.TaskStep) ([]config.TaskStep, error) {
+	if task.Shell == "" && !task.Raw {
+		return steps, nil
+	}
+	out := make([]config.TaskStep, len(steps))
+	for i, step := range steps {
+		cmd, err := TaskCommand(task, step.Run)
+		if err != nil {
+			return nil, err
+		}
+		step.Run = cmd
+		out[i] = step
+	}
+	return out, nil
+}
+
+// StepCommand returns the shell command for a step, changing into the step's
+// dir first when one is set. The dir is relative to wherever the command
+// starts, which is the project root for both local and remote execution.
+func StepCommand(step config.TaskStep) string {
+	if step.Dir == "" {
+		return step.Run
+	}
+	return fmt.Sprintf("cd %s && %s", util.ShellQuote(step.Dir), step.Run)
+}
+
+// checkStepDir verifies a local step's dir exists before running it, so a
+// typo fails the step with a clear message instead of a bare "cd: no such
+// file or directory". Remote step dirs are checked inside the step script.
+func checkStepDir(step config.TaskStep, stepName string) error {
+	if step.Dir == "" {
+		return nil
+	}
+
+	info, err := os.Stat(step.Dir)
+	if err == nil && info.IsDir() {
+		return nil
+	}
+	return errors.New(errors.ErrExec,
+		fmt.Sprintf("Step '%s' wants to run in '%s', but that directory doesn't exist", stepName, step.Dir),
+		"Step dirs are relative to the project root. Check the path in your task config.")
+}
+
 // executeCommand runs a single command on the connection.
 func executeCommand(ctx context.Context, conn *host.Connection, cmd string, env map[string]string, workDir string, setupCommands []string, stdout, stderr io.Writer) (int, error) {
 	// Build the full command with environment variables, working directory, and setup commands
 	fullCmd := buildCommand(cmd, env, workDir, setupCommands, conn.IsLocal)
 
 	if conn.IsLocal {
+		logger.Verbosef(logger.LevelPhases, "exec", "running %q locally", cmd)
 		return ExecuteLocal(fullCmd, "", stdout, stderr)
 	}
 
 	// Remote execution
+	logger.Verbosef(logger.LevelPhases, "exec", "running %q on %s", cmd, conn.Name)
 	return conn.Client.ExecStreamContext(ctx, fullCmd, stdout, stderr)
 }
 
@@ -238,10 +475,14 @@ const DefaultShell = "${SHELL:-/bin/bash}"
 // The trailing semicolon ensures this is always a successful command that can be followed by &&.
 const rcSourceCommand = `[ -f ~/.bashrc ] && . ~/.bashrc || true; [ -f ~/.zshrc ] && . ~/.zshrc || true;`
 
-// BuildRemoteCommand constructs a remote command with shell config, setup commands, and working directory.
+// BuildRemoteCommand constructs a remote command with shell config, the host's profile files
+// and env, setup commands, and working directory.
 // This is the recommended way to build commands for remote execution with full configuration support.
 func BuildRemoteCommand(cmd string, host *config.Host) string {
-	var parts []string
+	// Source profile files and export host env first, so setup commands
+	// and the command itself see them
+	parts := config.HostProfileCommands(host)
+	parts = append(parts, config.HostEnvCommands(host)...)
 
 	// Add setup commands if configured
 	if len(host.SetupCommands) > 0 {
@@ -3,6 +3,9 @@ package exec
 import (
 	"bytes"
 	"context"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/rileyhilliard/rr/internal/config"
@@ -52,6 +55,56 @@ func TestExecuteTask_SingleCommandWithArgs(t *testing.T) {
 	assert.Contains(t, stdout.String(), "hello world foo")
 }
 
+func TestExecuteTask_RawWithArgs(t *testing.T) {
+	conn := createLocalConn()
+	t.Setenv("RR_RAW_TEST", "expanded")
+	task := &config.TaskConfig{
+		Run: `printf '%s|' "$RR_RAW_TEST" *`,
+		Raw: true,
+	}
+	args := []string{"two words", "$HOME"}
+
+	var stdout, stderr bytes.Buffer
+	result, err := ExecuteTask(context.Background(), conn, task, args, nil, "", &stdout, &stderr, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.ExitCode)
+	// Nothing is expanded, and each arg stays one word
+	assert.Equal(t, "$RR_RAW_TEST|*|two words|$HOME|", stdout.String())
+}
+
+func TestExecuteTask_Shell(t *testing.T) {
+	conn := createLocalConn()
+	task := &config.TaskConfig{
+		Steps: []config.TaskStep{{Name: "which", Run: "echo $0"}},
+		Shell: "sh -c",
+	}
+
+	var stdout, stderr bytes.Buffer
+	result, err := ExecuteTask(context.Background(), conn, task, nil, nil, "", &stdout, &stderr, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, "sh\n", stdout.String())
+}
+
+func TestTaskCommand(t *testing.T) {
+	cmd, err := TaskCommand(&config.TaskConfig{}, "make test")
+	require.NoError(t, err)
+	assert.Equal(t, "make test", cmd)
+
+	cmd, err = TaskCommand(&config.TaskConfig{Shell: "bash -l -c"}, "echo $PATH")
+	require.NoError(t, err)
+	assert.Equal(t, "bash -l -c 'echo $PATH'", cmd)
+
+	cmd, err = TaskCommand(&config.TaskConfig{Raw: true}, `grep -r "TODO: fix" src`)
+	require.NoError(t, err)
+	assert.Equal(t, "'grep' '-r' 'TODO: fix' 'src'", cmd)
+
+	_, err = TaskCommand(&config.TaskConfig{Raw: true}, "echo 'oops")
+	assert.Error(t, err)
+}
+
 func TestExecuteTask_SingleCommandWithEnv(t *testing.T) {
 	conn := createLocalConn()
 	task := &config.TaskConfig{
@@ -109,6 +162,64 @@ func TestExecuteTask_MultiStepAllPassing(t *testing.T) {
 	assert.Contains(t, output, "step3")
 }
 
+func TestExecuteTask_MultiStepSkipped(t *testing.T) {
+	conn := createLocalConn()
+	task := &config.TaskConfig{
+		Steps: []config.TaskStep{
+			{Name: "skipped", Run: "exit 1", If: "false"},
+			{Name: "runs", Run: "echo runs", If: "env.DEPLOY || true"},
+		},
+	}
+
+	var stdout, stderr bytes.Buffer
+	result, err := ExecuteTask(context.Background(), conn, task, nil, nil, "", &stdout, &stderr, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, -1, result.FailedStep)
+	require.Len(t, result.StepResults, 2)
+	assert.True(t, result.StepResults[0].Skipped)
+	assert.False(t, result.StepResults[1].Skipped)
+	assert.Equal(t, "runs\n", stdout.String())
+}
+
+func TestExecuteTask_MultiStepResumed(t *testing.T) {
+	conn := createLocalConn()
+	task := &config.TaskConfig{
+		Steps: []config.TaskStep{
+			{Name: "done", Run: "exit 1"},
+			{Name: "next", Run: "echo next"},
+		},
+	}
+
+	var stdout, stderr bytes.Buffer
+	result, err := ExecuteTask(context.Background(), conn, task, nil, nil, "", &stdout, &stderr, &TaskExecOptions{Done: 1})
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.ExitCode)
+	require.Len(t, result.StepResults, 2)
+	assert.True(t, result.StepResults[0].Resumed)
+	assert.False(t, result.StepResults[0].Skipped, "resumed steps aren't counted as skipped")
+	assert.Equal(t, "next\n", stdout.String())
+}
+
+func TestSkippedSteps(t *testing.T) {
+	t.Setenv("RR_TEST_LOCAL", "yes")
+	steps := []config.TaskStep{
+		{Run: "a"},
+		{Run: "b", If: "env.RR_TEST_LOCAL == 'yes'"},
+		{Run: "c", If: "env.MODE == 'fast'"},
+		{Run: "d", If: "!env.MODE"},
+	}
+
+	skip, err := SkippedSteps(steps, map[string]string{"MODE": "slow"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, []bool{false, false, true, true}, skip, "task env comes before the local environment")
+
+	_, err = SkippedSteps([]config.TaskStep{{Run: "x", If: "nope("}}, nil, "")
+	assert.Error(t, err)
+}
+
 func TestExecuteTask_MultiStepFailureWithStop(t *testing.T) {
 	conn := createLocalConn()
 	task := &config.TaskConfig{
@@ -237,6 +348,62 @@ func TestExecuteTask_OnFailDefaults(t *testing.T) {
 	assert.Equal(t, "continue", result.StepResults[3].OnFail)
 }
 
+func TestExecuteTask_StepDir(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "packages", "api"), 0755))
+	t.Chdir(root)
+
+	conn := createLocalConn()
+	task := &config.TaskConfig{
+		Steps: []config.TaskStep{
+			{Name: "in-subdir", Run: "pwd", Dir: "packages/api"},
+			{Name: "at-root", Run: "pwd"},
+		},
+	}
+
+	var stdout, stderr bytes.Buffer
+	result, err := ExecuteTask(context.Background(), conn, task, nil, nil, "", &stdout, &stderr, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.ExitCode)
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasSuffix(lines[0], filepath.Join("packages", "api")), "got %s", lines[0])
+	assert.False(t, strings.HasSuffix(lines[1], "api"), "dir should not leak into the next step")
+}
+
+func TestExecuteTask_StepDirMissing(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	conn := createLocalConn()
+	task := &config.TaskConfig{
+		Steps: []config.TaskStep{
+			{Name: "build", Run: "make", Dir: "does/not/exist", OnFail: config.OnFailContinue},
+			{Name: "after", Run: "echo after"},
+			{Name: "deploy", Run: "make", Dir: "also/missing"},
+			{Name: "never", Run: "echo never"},
+		},
+	}
+
+	var stdout, stderr bytes.Buffer
+	result, err := ExecuteTask(context.Background(), conn, task, nil, nil, "", &stdout, &stderr, nil)
+
+	require.NoError(t, err, "a missing dir fails the step, not the whole task")
+	assert.Equal(t, 1, result.ExitCode)
+	assert.Contains(t, stderr.String(), "Step 'build' wants to run in 'does/not/exist'")
+	assert.Contains(t, stdout.String(), "after", "on_fail: continue goes on to the next step")
+	assert.NotContains(t, stdout.String(), "never", "on_fail: stop ends the task")
+	require.Len(t, result.StepResults, 3)
+	assert.Equal(t, 0, result.FailedStep)
+}
+
+func TestStepCommand(t *testing.T) {
+	assert.Equal(t, "make test", StepCommand(config.TaskStep{Run: "make test"}))
+	assert.Equal(t, "cd 'packages/my api' && make test",
+		StepCommand(config.TaskStep{Run: "make test", Dir: "packages/my api"}))
+}
+
 func TestExecuteTask_NilTask(t *testing.T) {
 	conn := createLocalConn()
 
@@ -391,3 +558,23 @@ func TestBuildRemoteCommand_SetupCommands(t *testing.T) {
 	assert.Contains(t, result, "export PATH=/opt/go/bin:\\$PATH")
 	assert.Contains(t, result, "go test")
 }
+
+func TestBuildRemoteCommand_HostEnvAndProfileFiles(t *testing.T) {
+	host := &config.Host{
+		Dir:           "/home/user/project",
+		ProfileFiles:  []string{"~/.cargo/env"},
+		Env:           map[string]string{"PATH": "$HOME/.cargo/bin:$PATH"},
+		SetupCommands: []string{"nvm use"},
+	}
+	result := BuildRemoteCommand("cargo test", host)
+
+	// Profile files, then host env, then setup commands, all before the command
+	profile := strings.Index(result, "if [ -f ~/'.cargo/env' ]; then . ~/'.cargo/env'; fi")
+	env := strings.Index(result, `export PATH=\"\$HOME/.cargo/bin:\$PATH\"`)
+	setup := strings.Index(result, "nvm use")
+	cmd := strings.Index(result, "cargo test")
+	require.True(t, profile >= 0 && env >= 0 && setup >= 0 && cmd >= 0, result)
+	assert.Less(t, profile, env)
+	assert.Less(t, env, setup)
+	assert.Less(t, setup, cmd)
+}
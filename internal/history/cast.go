@@ -0,0 +1,403 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/errors"
+)
+
+// MaxCasts caps how many recordings are kept per project. Older ones are
+// deleted when a new one is saved.
+const MaxCasts = 20
+
+// MaxCastBytes caps how much output one recording keeps. As with OutputTail,
+// the oldest output is dropped first.
+const MaxCastBytes = 1024 * 1024
+
+// castCoalesce merges writes that land this close together into one event.
+// Output arrives in small chunks; nobody can see a 5ms gap on replay.
+const castCoalesce = 5 * time.Millisecond
+
+// castExt is the extension of recording files.
+const castExt = ".cast"
+
+// castIDFormat names recordings after the time the run started, so sorting
+// IDs sorts by age.
+const castIDFormat = "20060102-150405"
+
+// CastHeader is the first line of a recording. Recordings are asciicast v2
+// files, so asciinema can play them too; Host and ExitCode are rr extras.
+type CastHeader struct {
+	Version   int     `json:"version"`
+	Width     int     `json:"width"`
+	Height    int     `json:"height"`
+	Timestamp int64   `json:"timestamp"`
+	Duration  float64 `json:"duration,omitempty"`
+	Title     string  `json:"title,omitempty"`
+	Host      string  `json:"host,omitempty"`
+	ExitCode  *int    `json:"exit_code,omitempty"`
+}
+
+// CastEvent is a chunk of output and when it arrived, relative to the start.
+type CastEvent struct {
+	Time time.Duration
+	Data string
+}
+
+// MarshalJSON encodes the event as an asciicast [seconds, "o", data] line.
+func (e CastEvent) MarshalJSON() ([]byte, error) {
+	// Output is full of <, > and &; keep it readable in the file
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode([]interface{}{float64(e.Time.Milliseconds()) / 1000, "o", e.Data}); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// UnmarshalJSON decodes an asciicast event line.
+func (e *CastEvent) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if len(raw) != 3 {
+		return fmt.Errorf("event has %d fields, want 3", len(raw))
+	}
+	var secs float64
+	if err := json.Unmarshal(raw[0], &secs); err != nil {
+		return err
+	}
+	e.Time = time.Duration(secs * float64(time.Second))
+	return json.Unmarshal(raw[2], &e.Data)
+}
+
+// Cast is a recorded run: a header and its output events, oldest first.
+type Cast struct {
+	ID     string
+	Header CastHeader
+	Events []CastEvent
+}
+
+// CastRecorder is an io.Writer that timestamps everything written to it, so
+// a run's output can be replayed later with its original timing.
+type CastRecorder struct {
+	mu        sync.Mutex
+	start     time.Time
+	now       func() time.Time
+	events    []CastEvent
+	size      int
+	truncated bool
+}
+
+// NewCastRecorder creates a recorder whose clock starts now.
+func NewCastRecorder() *CastRecorder {
+	return &CastRecorder{start: time.Now(), now: time.Now}
+}
+
+// Write implements io.Writer. It never fails.
+func (r *CastRecorder) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	at := r.now().Sub(r.start)
+	if n := len(r.events); n > 0 && at-r.events[n-1].Time < castCoalesce {
+		r.events[n-1].Data += string(p)
+	} else {
+		r.events = append(r.events, CastEvent{Time: at, Data: string(p)})
+	}
+	r.size += len(p)
+
+	// Drop whole events from the front until back under the cap
+	for r.size > MaxCastBytes && len(r.events) > 1 {
+		r.size -= len(r.events[0].Data)
+		r.events = r.events[1:]
+		r.truncated = true
+	}
+	return len(p), nil
+}
+
+// Start returns when recording started.
+func (r *CastRecorder) Start() time.Time {
+	return r.start
+}
+
+// Events returns the recorded events, with a marker first if the start was
+// dropped.
+func (r *CastRecorder) Events() []CastEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := make([]CastEvent, 0, len(r.events)+1)
+	if r.truncated && len(r.events) > 0 {
+		events = append(events, CastEvent{Time: r.events[0].Time, Data: truncatedMarker})
+	}
+	return append(events, r.events...)
+}
+
+// CastDir returns the directory holding a local project's recordings.
+func CastDir(projectDir string) (string, error) {
+	return projectFile(projectDir, ".casts")
+}
+
+// SaveCast writes a recording and deletes the oldest beyond MaxCasts.
+// Returns the new recording's ID.
+func SaveCast(projectDir string, header CastHeader, events []CastEvent) (string, error) {
+	dir, err := CastDir(projectDir)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't create recordings directory",
+			"Check permissions on ~/.rr/.")
+	}
+
+	header.Version = 2
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(header); err != nil {
+		return "", errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't encode recording",
+			"This is a bug - please report it.")
+	}
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			return "", errors.WrapWithCode(err, errors.ErrConfig,
+				"Couldn't encode recording",
+				"This is a bug - please report it.")
+		}
+	}
+
+	// Runs that start in the same second get a suffix
+	base := time.Unix(header.Timestamp, 0).Format(castIDFormat)
+	id := base
+	for n := 2; ; n++ {
+		if _, err := os.Stat(filepath.Join(dir, id+castExt)); os.IsNotExist(err) {
+			break
+		}
+		id = fmt.Sprintf("%s-%d", base, n)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, id+castExt), buf.Bytes(), 0600); err != nil {
+		return "", errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't save recording",
+			"Check permissions on ~/.rr/.")
+	}
+
+	ids, err := castIDs(dir)
+	if err == nil && len(ids) > MaxCasts {
+		for _, old := range ids[:len(ids)-MaxCasts] {
+			_ = os.Remove(filepath.Join(dir, old+castExt))
+		}
+	}
+	return id, nil
+}
+
+// castIDs lists the recording IDs in dir, oldest first.
+func castIDs(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, e := range entries {
+		if id, ok := strings.CutSuffix(e.Name(), castExt); ok && !e.IsDir() {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return castLess(ids[i], ids[j])
+	})
+	return ids, nil
+}
+
+// castLess orders IDs by start time, then by same-second suffix
+// ("-2" before "-10").
+func castLess(a, b string) bool {
+	baseA, nA := splitCastID(a)
+	baseB, nB := splitCastID(b)
+	if baseA != baseB {
+		return baseA < baseB
+	}
+	return nA < nB
+}
+
+// splitCastID splits an ID into its start time and same-second suffix
+// (1 if there isn't one).
+func splitCastID(id string) (string, int) {
+	if len(id) > len(castIDFormat)+1 {
+		if n, err := strconv.Atoi(id[len(castIDFormat)+1:]); err == nil {
+			return id[:len(castIDFormat)], n
+		}
+	}
+	return id, 1
+}
+
+// ListCasts returns a project's recordings, oldest first, with their headers
+// (no events). Unreadable recordings are skipped.
+func ListCasts(projectDir string) ([]Cast, error) {
+	dir, err := CastDir(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := castIDs(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't list recordings",
+			"Check permissions on "+dir+".")
+	}
+
+	casts := make([]Cast, 0, len(ids))
+	for _, id := range ids {
+		header, err := readCastHeader(filepath.Join(dir, id+castExt))
+		if err != nil {
+			continue
+		}
+		casts = append(casts, Cast{ID: id, Header: header})
+	}
+	return casts, nil
+}
+
+// readCastHeader reads just the first line of a recording.
+func readCastHeader(path string) (CastHeader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return CastHeader{}, err
+	}
+	defer f.Close()
+
+	line, err := bufio.NewReader(f).ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return CastHeader{}, err
+	}
+	var header CastHeader
+	if err := json.Unmarshal(line, &header); err != nil {
+		return CastHeader{}, err
+	}
+	return header, nil
+}
+
+// LoadCast reads a project's recording by ID, or the latest if id is empty.
+// id can also be the path to a .cast file, such as one someone shared.
+func LoadCast(projectDir, id string) (*Cast, error) {
+	path := id
+	if _, err := os.Stat(id); err != nil || !strings.HasSuffix(id, castExt) {
+		dir, err := CastDir(projectDir)
+		if err != nil {
+			return nil, err
+		}
+		if id == "" {
+			ids, _ := castIDs(dir)
+			if len(ids) == 0 {
+				return nil, errors.New(errors.ErrConfig,
+					"No recordings for this project yet",
+					"Recordings are made by 'rr run', 'rr exec', and tasks. Run something first.")
+			}
+			id = ids[len(ids)-1]
+		}
+		path = filepath.Join(dir, id+castExt)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.New(errors.ErrConfig,
+				fmt.Sprintf("No recording '%s'", id),
+				"Run 'rr replay --list' to see this project's recordings.")
+		}
+		return nil, errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't read recording",
+			"Check permissions on "+path+".")
+	}
+	defer f.Close()
+
+	cast, err := ParseCast(f)
+	if err != nil {
+		return nil, errors.WrapWithCode(err, errors.ErrConfig,
+			fmt.Sprintf("Recording %s is corrupt", path),
+			"Delete it, or pick another with 'rr replay --list'.")
+	}
+	cast.ID = strings.TrimSuffix(filepath.Base(path), castExt)
+	return cast, nil
+}
+
+// ParseCast reads an asciicast v2 recording. Events other than output ("o")
+// are skipped.
+func ParseCast(r io.Reader) (*Cast, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), MaxCastBytes*2)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("empty recording")
+	}
+	cast := &Cast{}
+	if err := json.Unmarshal(scanner.Bytes(), &cast.Header); err != nil {
+		return nil, fmt.Errorf("bad header: %w", err)
+	}
+	if cast.Header.Version != 2 {
+		return nil, fmt.Errorf("unsupported asciicast version %d", cast.Header.Version)
+	}
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var raw []json.RawMessage
+		if err := json.Unmarshal(line, &raw); err != nil || len(raw) != 3 || string(raw[1]) != `"o"` {
+			continue
+		}
+		var e CastEvent
+		if err := e.UnmarshalJSON(line); err != nil {
+			continue
+		}
+		cast.Events = append(cast.Events, e)
+	}
+	return cast, scanner.Err()
+}
+
+// Delays returns how long to wait before writing each event when replaying
+// at speed (2 = twice as fast). Gaps longer than idleLimit (after speeding
+// up) are cut to idleLimit; 0 means no limit.
+func (c *Cast) Delays(speed float64, idleLimit time.Duration) []time.Duration {
+	if speed <= 0 {
+		speed = 1
+	}
+	delays := make([]time.Duration, len(c.Events))
+	var prev time.Duration
+	for i, e := range c.Events {
+		d := time.Duration(float64(e.Time-prev) / speed)
+		if d < 0 {
+			d = 0
+		}
+		if idleLimit > 0 && d > idleLimit {
+			d = idleLimit
+		}
+		delays[i] = d
+		prev = e.Time
+	}
+	return delays
+}
@@ -0,0 +1,186 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClockRecorder returns a recorder and a function that advances its clock.
+func fakeClockRecorder() (*CastRecorder, func(time.Duration)) {
+	now := time.Unix(1700000000, 0)
+	r := &CastRecorder{start: now, now: func() time.Time { return now }}
+	return r, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestCastRecorder(t *testing.T) {
+	r, advance := fakeClockRecorder()
+
+	_, _ = r.Write([]byte("building"))
+	advance(2 * time.Millisecond)
+	_, _ = r.Write([]byte("...\n"))
+	advance(1500 * time.Millisecond)
+	_, _ = r.Write([]byte("done\n"))
+
+	// Writes within the coalesce window share an event
+	assert.Equal(t, []CastEvent{
+		{Time: 0, Data: "building...\n"},
+		{Time: 1502 * time.Millisecond, Data: "done\n"},
+	}, r.Events())
+}
+
+func TestCastRecorder_DropsOldestOutput(t *testing.T) {
+	r, advance := fakeClockRecorder()
+
+	chunk := strings.Repeat("x", MaxCastBytes/2)
+	for i := 0; i < 3; i++ {
+		_, _ = r.Write([]byte(chunk))
+		advance(time.Second)
+	}
+	_, _ = r.Write([]byte("the end\n"))
+
+	events := r.Events()
+	require.Len(t, events, 3)
+	assert.Equal(t, truncatedMarker, events[0].Data)
+	assert.Equal(t, 2*time.Second, events[0].Time)
+	assert.Equal(t, "the end\n", events[2].Data)
+}
+
+func TestSaveAndLoadCast(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	project := t.TempDir()
+	exitCode := 1
+
+	header := CastHeader{Width: 120, Height: 40, Timestamp: 1700000000, Duration: 1.5, Title: "make test", Host: "mini", ExitCode: &exitCode}
+	events := []CastEvent{
+		{Time: 0, Data: "\x1b[32mok\x1b[0m <pkg>\n"},
+		{Time: 1500 * time.Millisecond, Data: "FAIL\n"},
+	}
+	id, err := SaveCast(project, header, events)
+	require.NoError(t, err)
+	assert.Equal(t, time.Unix(1700000000, 0).Format(castIDFormat), id)
+
+	// A second run in the same second gets a suffix
+	id2, err := SaveCast(project, header, events)
+	require.NoError(t, err)
+	assert.Equal(t, id+"-2", id2)
+
+	cast, err := LoadCast(project, id)
+	require.NoError(t, err)
+	assert.Equal(t, id, cast.ID)
+	assert.Equal(t, 2, cast.Header.Version)
+	assert.Equal(t, "make test", cast.Header.Title)
+	require.NotNil(t, cast.Header.ExitCode)
+	assert.Equal(t, 1, *cast.Header.ExitCode)
+	assert.Equal(t, events, cast.Events)
+
+	// No ID means the latest
+	latest, err := LoadCast(project, "")
+	require.NoError(t, err)
+	assert.Equal(t, id2, latest.ID)
+
+	// A path to a .cast file works too
+	dir, err := CastDir(project)
+	require.NoError(t, err)
+	byPath, err := LoadCast(t.TempDir(), filepath.Join(dir, id+castExt))
+	require.NoError(t, err)
+	assert.Equal(t, events, byPath.Events)
+
+	casts, err := ListCasts(project)
+	require.NoError(t, err)
+	require.Len(t, casts, 2)
+	assert.Equal(t, id, casts[0].ID)
+	assert.Equal(t, "mini", casts[1].Header.Host)
+	assert.Empty(t, casts[1].Events)
+}
+
+func TestLoadCast_Missing(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	project := t.TempDir()
+
+	_, err := LoadCast(project, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "No recordings")
+
+	_, err = SaveCast(project, CastHeader{Timestamp: 1700000000}, nil)
+	require.NoError(t, err)
+	_, err = LoadCast(project, "20000101-000000")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "No recording '20000101-000000'")
+}
+
+func TestSaveCast_KeepsNewest(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	project := t.TempDir()
+
+	for i := 0; i < MaxCasts+3; i++ {
+		_, err := SaveCast(project, CastHeader{Timestamp: 1700000000 + int64(i)}, nil)
+		require.NoError(t, err)
+	}
+
+	casts, err := ListCasts(project)
+	require.NoError(t, err)
+	require.Len(t, casts, MaxCasts)
+	assert.Equal(t, int64(1700000003), casts[0].Header.Timestamp)
+}
+
+func TestCastLess(t *testing.T) {
+	assert.True(t, castLess("20240101-000000", "20240101-000000-2"))
+	assert.True(t, castLess("20240101-000000-2", "20240101-000000-10"))
+	assert.True(t, castLess("20240101-000000-10", "20240102-000000"))
+	assert.False(t, castLess("20240102-000000", "20240101-000000"))
+}
+
+func TestParseCast(t *testing.T) {
+	input := `{"version": 2, "width": 80, "height": 24, "title": "demo"}
+[0.5, "o", "hello "]
+[0.7, "i", "typed"]
+[1.25, "o", "world\n"]
+`
+	cast, err := ParseCast(strings.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, "demo", cast.Header.Title)
+	assert.Equal(t, []CastEvent{
+		{Time: 500 * time.Millisecond, Data: "hello "},
+		{Time: 1250 * time.Millisecond, Data: "world\n"},
+	}, cast.Events)
+
+	_, err = ParseCast(strings.NewReader(`{"version": 1}` + "\n"))
+	assert.Error(t, err)
+	_, err = ParseCast(strings.NewReader(""))
+	assert.Error(t, err)
+}
+
+func TestCastDelays(t *testing.T) {
+	cast := &Cast{Events: []CastEvent{
+		{Time: 100 * time.Millisecond},
+		{Time: 300 * time.Millisecond},
+		{Time: 10 * time.Second},
+	}}
+
+	assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 9700 * time.Millisecond}, cast.Delays(1, 0))
+	assert.Equal(t, []time.Duration{50 * time.Millisecond, 100 * time.Millisecond, 4850 * time.Millisecond}, cast.Delays(2, 0))
+	assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, time.Second}, cast.Delays(1, time.Second))
+}
+
+func TestCastFileIsAsciicast(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	project := t.TempDir()
+
+	id, err := SaveCast(project, CastHeader{Width: 80, Height: 24, Timestamp: 1700000000}, []CastEvent{{Time: 1234 * time.Millisecond, Data: "<ok>\n"}})
+	require.NoError(t, err)
+
+	dir, err := CastDir(project)
+	require.NoError(t, err)
+	data, err := os.ReadFile(filepath.Join(dir, id+castExt))
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("%s\n%s\n",
+		`{"version":2,"width":80,"height":24,"timestamp":1700000000}`,
+		`[1.234,"o","<ok>\n"]`), string(data))
+}
@@ -0,0 +1,108 @@
+package history
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/util"
+)
+
+// Checkpoint is how far a task run got, saved as it goes so 'rr resume' can
+// pick up the remaining steps if rr or the machine dies mid-run. Finishing
+// the run deletes it.
+type Checkpoint struct {
+	Task    string    `json:"task"`
+	Args    []string  `json:"args,omitempty"`
+	Host    string    `json:"host"`
+	Local   bool      `json:"local,omitempty"` // The task ran on this machine
+	PID     int       `json:"pid"`             // The rr process running the task
+	Started time.Time `json:"started"`
+	Updated time.Time `json:"updated"`
+
+	Synced  bool   `json:"synced"`             // The project was synced to Host
+	LockDir string `json:"lock_dir,omitempty"` // The lock the run held on Host (empty if none)
+	Steps   int    `json:"steps"`              // How many steps the task has (0 for a single command)
+	Done    int    `json:"done"`               // How many steps finished, in order
+
+	Params map[string]string `json:"params,omitempty"` // The values the task's params were given
+}
+
+// CheckpointPath returns the checkpoint file for a local project directory.
+func CheckpointPath(projectDir string) (string, error) {
+	return projectFile(projectDir, ".checkpoint.json")
+}
+
+// SaveCheckpoint replaces the project's checkpoint, stamping its update time.
+func SaveCheckpoint(projectDir string, cp *Checkpoint) error {
+	path, err := CheckpointPath(projectDir)
+	if err != nil {
+		return err
+	}
+	cp.Updated = time.Now()
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't encode the run checkpoint",
+			"This is a bug - please report it.")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't create run history directory",
+			"Check permissions on ~/.rr/.")
+	}
+	// Written atomically, since the process may die at any moment
+	if err := util.WriteFileAtomic(path, data, 0600); err != nil {
+		return errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't save the run checkpoint",
+			"Check permissions on ~/.rr/.")
+	}
+	return nil
+}
+
+// LoadCheckpoint reads the project's checkpoint.
+// Returns (nil, nil) if there's nothing to resume.
+func LoadCheckpoint(projectDir string) (*Checkpoint, error) {
+	path, err := CheckpointPath(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't read the run checkpoint",
+			"Delete "+path+" to start fresh.")
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't parse the run checkpoint",
+			"Delete "+path+" to start fresh.")
+	}
+	return &cp, nil
+}
+
+// ClearCheckpoint deletes the project's checkpoint, if it has one.
+func ClearCheckpoint(projectDir string) error {
+	path, err := CheckpointPath(projectDir)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't delete the run checkpoint",
+			"Delete "+path+" by hand.")
+	}
+	return nil
+}
+
+// Running reports whether the rr process that saved the checkpoint is still
+// alive, other than this one.
+func (cp *Checkpoint) Running() bool {
+	return cp.PID != os.Getpid() && util.ProcessAlive(cp.PID)
+}
@@ -0,0 +1,42 @@
+package history
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckpoint_SaveLoadClear(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	project := t.TempDir()
+
+	cp, err := LoadCheckpoint(project)
+	require.NoError(t, err)
+	assert.Nil(t, cp, "nothing to resume yet")
+
+	saved := &Checkpoint{Task: "test", Host: "mini", PID: 4242, Synced: true, LockDir: "/tmp/rr.lock", Steps: 3, Done: 1}
+	require.NoError(t, SaveCheckpoint(project, saved))
+	assert.False(t, saved.Updated.IsZero())
+
+	loaded, err := LoadCheckpoint(project)
+	require.NoError(t, err)
+	require.NotNil(t, loaded)
+	assert.Equal(t, "test", loaded.Task)
+	assert.Equal(t, "/tmp/rr.lock", loaded.LockDir)
+	assert.Equal(t, 1, loaded.Done)
+	assert.True(t, loaded.Synced)
+
+	require.NoError(t, ClearCheckpoint(project))
+	require.NoError(t, ClearCheckpoint(project), "clearing twice is fine")
+	loaded, err = LoadCheckpoint(project)
+	require.NoError(t, err)
+	assert.Nil(t, loaded)
+}
+
+func TestCheckpoint_Running(t *testing.T) {
+	assert.False(t, (&Checkpoint{PID: os.Getpid()}).Running(), "this process isn't another run")
+	assert.True(t, (&Checkpoint{PID: os.Getppid()}).Running())
+	assert.False(t, (&Checkpoint{PID: 0}).Running())
+}
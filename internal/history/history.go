@@ -0,0 +1,312 @@
+// Package history records how long each rr run took, per phase, so later runs
+// can spot regressions (e.g., "sync 1.1s [+40% vs median]") and pickers can
+// show last-run durations.
+//
+// History is stored per project as JSON lines under ~/.rr/history/, keyed by
+// a hash of the local project directory. The tail of the most recent run's
+// output is kept next to it for 'rr report', timed recordings of recent runs
+// for 'rr replay', and a checkpoint of an unfinished task run for 'rr resume'.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/util"
+)
+
+// historyDir is the directory under ~/.rr/ that holds run history files.
+const historyDir = "history"
+
+// MaxEntries caps how many runs are kept per project. Older runs are dropped.
+const MaxEntries = 500
+
+// medianWindow is how many recent matching runs feed the median.
+const medianWindow = 20
+
+// MinSamples is how many prior runs a comparison needs before it's shown.
+// Fewer than this and one slow outlier would dominate the median.
+const MinSamples = 3
+
+// Entry records one completed run.
+type Entry struct {
+	Key      string                   `json:"key"` // What ran; see RunKey and TaskKey
+	Host     string                   `json:"host"`
+	Time     time.Time                `json:"time"`
+	ExitCode int                      `json:"exit_code"`
+	Phases   map[string]time.Duration `json:"phases"` // build, connect, lock, sync, inputs, exec, pull, stage
+	Total    time.Duration            `json:"total"`
+	// Steps is how long each step of a multi-step task took, by step name,
+	// for the steps that passed.
+	Steps map[string]time.Duration `json:"steps,omitempty"`
+}
+
+// RunKey returns the history key for an ad-hoc 'rr run' command.
+func RunKey(command string) string {
+	return "run:" + command
+}
+
+// TaskKey returns the history key for a named task.
+func TaskKey(name string) string {
+	return "task:" + name
+}
+
+// Path returns the history file for a local project directory.
+func Path(projectDir string) (string, error) {
+	return projectFile(projectDir, ".jsonl")
+}
+
+// projectFile returns the file under ~/.rr/history/ for a local project
+// directory with the given extension.
+func projectFile(projectDir, ext string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.WrapWithCode(err, errors.ErrConfig,
+			"Can't find your home directory",
+			"This is unusual - check your environment.")
+	}
+	name := config.ProjectStateKey(projectDir) + ext
+	return filepath.Join(home, config.GlobalConfigDir, historyDir, name), nil
+}
+
+// Load reads every recorded run for a project, oldest first.
+// Returns (nil, nil) if nothing has been recorded. Unreadable lines are
+// skipped so one bad write doesn't lose the rest of the history.
+func Load(projectDir string) ([]Entry, error) {
+	path, err := Path(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	return readEntries(path)
+}
+
+// LoadAll reads the recorded runs of every project, in no particular order.
+func LoadAll() ([]Entry, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, errors.WrapWithCode(err, errors.ErrConfig,
+			"Can't find your home directory",
+			"This is unusual - check your environment.")
+	}
+	paths, _ := filepath.Glob(filepath.Join(home, config.GlobalConfigDir, historyDir, "*.jsonl"))
+
+	var all []Entry
+	for _, path := range paths {
+		entries, err := readEntries(path)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, entries...)
+	}
+	return all, nil
+}
+
+// readEntries reads the runs recorded in a history file.
+func readEntries(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't read run history",
+			"Delete "+path+" to start fresh.")
+	}
+
+	var entries []Entry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// Append records a run, dropping the oldest runs beyond MaxEntries.
+// The file is rewritten atomically so readers never see a partial history.
+func Append(projectDir string, entry Entry) error {
+	path, err := Path(projectDir)
+	if err != nil {
+		return err
+	}
+
+	entries, err := Load(projectDir)
+	if err != nil {
+		return err
+	}
+	entries = append(entries, entry)
+	if len(entries) > MaxEntries {
+		entries = entries[len(entries)-MaxEntries:]
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return errors.WrapWithCode(err, errors.ErrConfig,
+				"Couldn't encode run history",
+				"This is a bug - please report it.")
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't create run history directory",
+			"Check permissions on ~/.rr/.")
+	}
+	if err := util.WriteFileAtomic(path, buf.Bytes(), 0644); err != nil {
+		return errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't write run history",
+			"Check permissions on ~/.rr/.")
+	}
+	return nil
+}
+
+// Last returns the most recent run recorded for key.
+func Last(entries []Entry, key string) (Entry, bool) {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Key == key {
+			return entries[i], true
+		}
+	}
+	return Entry{}, false
+}
+
+// Stats summarizes the runs recorded for one key.
+type Stats struct {
+	Runs   int   // Runs still in the history (see MaxEntries)
+	Failed int   // Those that exited non-zero
+	Last   Entry // The most recent run
+	// Median is the median total of recent successful runs, or zero if
+	// there are fewer than MinSamples of them.
+	Median time.Duration
+}
+
+// StatsFor summarizes the runs recorded for key. Returns false if it has
+// none.
+func StatsFor(entries []Entry, key string) (Stats, bool) {
+	var s Stats
+	var totals []time.Duration
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if e.Key != key {
+			continue
+		}
+		if s.Runs == 0 {
+			s.Last = e
+		}
+		s.Runs++
+		if e.ExitCode != 0 {
+			s.Failed++
+		} else if len(totals) < medianWindow {
+			totals = append(totals, e.Total)
+		}
+	}
+	if s.Runs == 0 {
+		return Stats{}, false
+	}
+	if len(totals) >= MinSamples {
+		s.Median = medianOf(totals)
+	}
+	return s, true
+}
+
+// Comparison describes how a phase duration stacks up against prior runs.
+type Comparison struct {
+	Median  time.Duration
+	Samples int
+	Change  float64 // Fractional change vs median: 0.4 means 40% slower
+}
+
+// Compare measures d against the median of the phase across recent successful
+// runs of the same key on the same host. Failed runs are left out since they
+// often stop early. Returns false if there are fewer than MinSamples runs.
+func Compare(entries []Entry, key, host, phase string, d time.Duration) (Comparison, bool) {
+	var samples []time.Duration
+	for i := len(entries) - 1; i >= 0 && len(samples) < medianWindow; i-- {
+		e := entries[i]
+		if e.Key != key || e.Host != host || e.ExitCode != 0 {
+			continue
+		}
+		if v, ok := e.Phases[phase]; ok {
+			samples = append(samples, v)
+		}
+	}
+	if len(samples) < MinSamples {
+		return Comparison{}, false
+	}
+
+	median := medianOf(samples)
+	if median <= 0 {
+		return Comparison{}, false
+	}
+	return Comparison{
+		Median:  median,
+		Samples: len(samples),
+		Change:  float64(d-median) / float64(median),
+	}, true
+}
+
+// StepMedians returns the median duration of each step of the runs of key
+// on host, for the steps with at least MinSamples passing runs. Failed
+// steps aren't recorded, so a run that failed later still counts for the
+// steps before.
+func StepMedians(entries []Entry, key, host string) map[string]time.Duration {
+	samples := make(map[string][]time.Duration)
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if e.Key != key || e.Host != host {
+			continue
+		}
+		for step, d := range e.Steps {
+			if len(samples[step]) < medianWindow {
+				samples[step] = append(samples[step], d)
+			}
+		}
+	}
+
+	medians := make(map[string]time.Duration)
+	for step, s := range samples {
+		if len(s) >= MinSamples {
+			medians[step] = medianOf(s)
+		}
+	}
+	return medians
+}
+
+// Significant reports whether a comparison is worth calling out: at least
+// threshold (fractional) off the median and at least minDelta in absolute
+// terms, so a 50ms phase going to 80ms isn't flagged.
+func (c Comparison) Significant(d time.Duration, threshold float64, minDelta time.Duration) bool {
+	if math.Abs(c.Change) < threshold {
+		return false
+	}
+	delta := d - c.Median
+	if delta < 0 {
+		delta = -delta
+	}
+	return delta >= minDelta
+}
+
+// medianOf returns the median of durations. samples must be non-empty.
+func medianOf(samples []time.Duration) time.Duration {
+	sorted := append([]time.Duration(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
@@ -0,0 +1,227 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPath_StableAndUnderRRDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	project := t.TempDir()
+
+	p1, err := Path(project)
+	require.NoError(t, err)
+	p2, err := Path(project + "/")
+	require.NoError(t, err)
+
+	assert.Equal(t, p1, p2)
+	assert.Equal(t, filepath.Join(home, ".rr", "history"), filepath.Dir(p1))
+	assert.Equal(t, ".jsonl", filepath.Ext(p1))
+}
+
+func TestLoad_NoHistory(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	entries, err := Load(t.TempDir())
+	require.NoError(t, err)
+	assert.Nil(t, entries)
+}
+
+func TestAppendAndLoad(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	project := t.TempDir()
+
+	first := Entry{Key: TaskKey("test"), Host: "mini", ExitCode: 0, Phases: map[string]time.Duration{"sync": time.Second}}
+	second := Entry{Key: RunKey("make build"), Host: "mini", ExitCode: 2}
+	require.NoError(t, Append(project, first))
+	require.NoError(t, Append(project, second))
+
+	entries, err := Load(project)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "task:test", entries[0].Key)
+	assert.Equal(t, time.Second, entries[0].Phases["sync"])
+	assert.Equal(t, "run:make build", entries[1].Key)
+	assert.Equal(t, 2, entries[1].ExitCode)
+}
+
+func TestLoadAll(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	entries, err := LoadAll()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	require.NoError(t, Append(t.TempDir(), Entry{Key: TaskKey("test"), Host: "mini", Total: time.Minute}))
+	require.NoError(t, Append(t.TempDir(), Entry{Key: RunKey("make"), Host: "gpu-box", Total: time.Hour}))
+	require.NoError(t, SaveOutput(t.TempDir(), []byte("not history\n")))
+
+	entries, err = LoadAll()
+	require.NoError(t, err)
+	require.Len(t, entries, 2, "every project's runs, and nothing else")
+	hosts := []string{entries[0].Host, entries[1].Host}
+	assert.ElementsMatch(t, []string{"mini", "gpu-box"}, hosts)
+}
+
+func TestAppend_TrimsToMaxEntries(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	project := t.TempDir()
+
+	for i := 0; i < MaxEntries+5; i++ {
+		require.NoError(t, Append(project, Entry{Key: "k", ExitCode: i}))
+	}
+
+	entries, err := Load(project)
+	require.NoError(t, err)
+	require.Len(t, entries, MaxEntries)
+	assert.Equal(t, 5, entries[0].ExitCode, "oldest entries should be dropped first")
+}
+
+func TestLoad_SkipsCorruptLines(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	project := t.TempDir()
+	require.NoError(t, Append(project, Entry{Key: "good"}))
+
+	path, err := Path(project)
+	require.NoError(t, err)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString("{not json\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	entries, err := Load(project)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "good", entries[0].Key)
+}
+
+func TestLast(t *testing.T) {
+	entries := []Entry{
+		{Key: "task:test", ExitCode: 1},
+		{Key: "task:lint"},
+		{Key: "task:test", ExitCode: 0},
+	}
+
+	e, ok := Last(entries, "task:test")
+	require.True(t, ok)
+	assert.Equal(t, 0, e.ExitCode)
+
+	_, ok = Last(entries, "task:build")
+	assert.False(t, ok)
+}
+
+func TestStatsFor(t *testing.T) {
+	entries := []Entry{
+		{Key: "task:test", Host: "mini", Total: 3 * time.Second},
+		{Key: "task:test", Host: "mini", ExitCode: 1, Total: 30 * time.Second},
+		{Key: "task:lint", Total: time.Second},
+		{Key: "task:test", Host: "mini", Total: 1 * time.Second},
+	}
+
+	s, ok := StatsFor(entries, "task:test")
+	require.True(t, ok)
+	assert.Equal(t, 3, s.Runs)
+	assert.Equal(t, 1, s.Failed)
+	assert.Equal(t, time.Second, s.Last.Total)
+	assert.Zero(t, s.Median, "two successful runs aren't enough for a median")
+
+	entries = append(entries, Entry{Key: "task:test", Host: "pc", Total: 2 * time.Second})
+	s, ok = StatsFor(entries, "task:test")
+	require.True(t, ok)
+	assert.Equal(t, 2*time.Second, s.Median)
+	assert.Equal(t, "pc", s.Last.Host)
+
+	_, ok = StatsFor(entries, "task:build")
+	assert.False(t, ok)
+}
+
+func TestCompare(t *testing.T) {
+	sync := func(key, host string, exit int, d time.Duration) Entry {
+		return Entry{Key: key, Host: host, ExitCode: exit, Phases: map[string]time.Duration{"sync": d}}
+	}
+
+	t.Run("median of matching successful runs", func(t *testing.T) {
+		entries := []Entry{
+			sync("task:test", "mini", 0, 1*time.Second),
+			sync("task:test", "mini", 0, 3*time.Second),
+			sync("task:test", "mini", 0, 2*time.Second),
+			sync("task:test", "mini", 1, 10*time.Second), // failed, ignored
+			sync("task:test", "other", 0, 9*time.Second), // other host, ignored
+			sync("task:lint", "mini", 0, 9*time.Second),  // other key, ignored
+		}
+
+		c, ok := Compare(entries, "task:test", "mini", "sync", 3*time.Second)
+		require.True(t, ok)
+		assert.Equal(t, 2*time.Second, c.Median)
+		assert.Equal(t, 3, c.Samples)
+		assert.InDelta(t, 0.5, c.Change, 0.001)
+	})
+
+	t.Run("even sample count averages the middle pair", func(t *testing.T) {
+		entries := []Entry{
+			sync("k", "h", 0, 1*time.Second),
+			sync("k", "h", 0, 2*time.Second),
+			sync("k", "h", 0, 4*time.Second),
+			sync("k", "h", 0, 5*time.Second),
+		}
+		c, ok := Compare(entries, "k", "h", "sync", 3*time.Second)
+		require.True(t, ok)
+		assert.Equal(t, 3*time.Second, c.Median)
+		assert.InDelta(t, 0, c.Change, 0.001)
+	})
+
+	t.Run("too few samples", func(t *testing.T) {
+		entries := []Entry{
+			sync("k", "h", 0, time.Second),
+			sync("k", "h", 0, time.Second),
+		}
+		_, ok := Compare(entries, "k", "h", "sync", time.Second)
+		assert.False(t, ok)
+	})
+
+	t.Run("runs without the phase are skipped", func(t *testing.T) {
+		entries := []Entry{
+			{Key: "k", Host: "h"},
+			{Key: "k", Host: "h"},
+			{Key: "k", Host: "h"},
+		}
+		_, ok := Compare(entries, "k", "h", "sync", time.Second)
+		assert.False(t, ok)
+	})
+}
+
+func TestStepMedians(t *testing.T) {
+	steps := func(key, host string, d map[string]time.Duration) Entry {
+		return Entry{Key: key, Host: host, ExitCode: 1, Steps: d}
+	}
+	entries := []Entry{
+		steps("task:ci", "mini", map[string]time.Duration{"build": 10 * time.Second, "test": time.Minute}),
+		steps("task:ci", "mini", map[string]time.Duration{"build": 12 * time.Second, "test": 2 * time.Minute}),
+		steps("task:ci", "mini", map[string]time.Duration{"build": 20 * time.Second}),
+		steps("task:ci", "other", map[string]time.Duration{"build": time.Hour, "test": time.Hour}),
+		steps("task:lint", "mini", map[string]time.Duration{"build": time.Hour}),
+	}
+
+	medians := StepMedians(entries, "task:ci", "mini")
+	assert.Equal(t, map[string]time.Duration{"build": 12 * time.Second}, medians,
+		"test has only two samples; runs that failed still count")
+	assert.Empty(t, StepMedians(nil, "task:ci", "mini"))
+}
+
+func TestComparison_Significant(t *testing.T) {
+	c := Comparison{Median: time.Second, Change: 0.4}
+	assert.True(t, c.Significant(1400*time.Millisecond, 0.2, 250*time.Millisecond))
+	assert.False(t, c.Significant(1400*time.Millisecond, 0.5, 250*time.Millisecond), "below threshold")
+
+	small := Comparison{Median: 100 * time.Millisecond, Change: 0.5}
+	assert.False(t, small.Significant(150*time.Millisecond, 0.2, 250*time.Millisecond), "below min delta")
+
+	faster := Comparison{Median: 10 * time.Second, Change: -0.3}
+	assert.True(t, faster.Significant(7*time.Second, 0.2, 250*time.Millisecond))
+}
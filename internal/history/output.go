@@ -0,0 +1,97 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/rileyhilliard/rr/internal/errors"
+)
+
+// MaxOutputBytes caps how much of a run's output is kept. The end of the
+// output is what explains a failure, so older output is dropped first.
+const MaxOutputBytes = 256 * 1024
+
+// truncatedMarker is prepended to saved output when the start was dropped.
+const truncatedMarker = "[... earlier output truncated ...]\n"
+
+// OutputTail is an io.Writer that keeps the last MaxOutputBytes written to
+// it, so the output of the most recent run can be attached to bug reports.
+type OutputTail struct {
+	mu        sync.Mutex
+	buf       []byte
+	truncated bool
+}
+
+// NewOutputTail creates an empty OutputTail.
+func NewOutputTail() *OutputTail {
+	return &OutputTail{}
+}
+
+// Write implements io.Writer. It never fails.
+func (t *OutputTail) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.buf = append(t.buf, p...)
+	if over := len(t.buf) - MaxOutputBytes; over > 0 {
+		t.buf = append(t.buf[:0], t.buf[over:]...)
+		t.truncated = true
+	}
+	return len(p), nil
+}
+
+// Bytes returns the kept output, marked if the start was dropped.
+func (t *OutputTail) Bytes() []byte {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.truncated {
+		return append([]byte(nil), t.buf...)
+	}
+	return append([]byte(truncatedMarker), t.buf...)
+}
+
+// OutputPath returns the file holding the last run's output for a local
+// project directory.
+func OutputPath(projectDir string) (string, error) {
+	return projectFile(projectDir, ".last.log")
+}
+
+// SaveOutput replaces the saved output of the project's last run.
+func SaveOutput(projectDir string, data []byte) error {
+	path, err := OutputPath(projectDir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't create run history directory",
+			"Check permissions on ~/.rr/.")
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't save run output",
+			"Check permissions on ~/.rr/.")
+	}
+	return nil
+}
+
+// LoadOutput reads the saved output of the project's last run.
+// Returns (nil, nil) if nothing has been saved.
+func LoadOutput(projectDir string) ([]byte, error) {
+	path, err := OutputPath(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't read last run output",
+			"Delete "+path+" to start fresh.")
+	}
+	return data, nil
+}
@@ -0,0 +1,54 @@
+package history
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputTail_KeepsEverythingUnderLimit(t *testing.T) {
+	tail := NewOutputTail()
+	_, _ = tail.Write([]byte("hello "))
+	_, _ = tail.Write([]byte("world\n"))
+
+	assert.Equal(t, "hello world\n", string(tail.Bytes()))
+}
+
+func TestOutputTail_DropsOldestOutput(t *testing.T) {
+	tail := NewOutputTail()
+	_, _ = tail.Write(bytes.Repeat([]byte("a"), MaxOutputBytes))
+	n, err := tail.Write([]byte("the end\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 8, n)
+
+	got := string(tail.Bytes())
+	assert.True(t, strings.HasPrefix(got, truncatedMarker))
+	assert.True(t, strings.HasSuffix(got, "the end\n"))
+	assert.Len(t, got, len(truncatedMarker)+MaxOutputBytes)
+}
+
+func TestSaveAndLoadOutput(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	project := t.TempDir()
+
+	data, err := LoadOutput(project)
+	require.NoError(t, err)
+	assert.Nil(t, data, "nothing saved yet")
+
+	require.NoError(t, SaveOutput(project, []byte("first")))
+	require.NoError(t, SaveOutput(project, []byte("second")))
+
+	data, err = LoadOutput(project)
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(data))
+
+	path, err := OutputPath(project)
+	require.NoError(t, err)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
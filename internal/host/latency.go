@@ -0,0 +1,111 @@
+package host
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/util"
+)
+
+// latencyFile is the file under ~/.rr/ that records how each SSH alias did
+// the last time a host with fallback: race connected.
+const latencyFile = "latency.json"
+
+// AliasLatency is the last recorded result of connecting through an alias.
+type AliasLatency struct {
+	Latency time.Duration `json:"latency,omitempty"`
+	Failed  bool          `json:"failed,omitempty"`
+	At      time.Time     `json:"at"`
+}
+
+// latencyMu serializes read-modify-writes of the latency file within a
+// process. A race records its winner right away and its stragglers later.
+var latencyMu sync.Mutex
+
+// latencyPath returns the path to the latency record.
+func latencyPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, config.GlobalConfigDir, latencyFile), nil
+}
+
+// ReadAliasLatencies loads the recorded latency of each alias. Returns an
+// empty map if nothing has been recorded or the record is unreadable.
+func ReadAliasLatencies() map[string]AliasLatency {
+	latencies := make(map[string]AliasLatency)
+	path, err := latencyPath()
+	if err != nil {
+		return latencies
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return latencies
+	}
+	_ = json.Unmarshal(data, &latencies)
+	return latencies
+}
+
+// recordAliasLatencies merges results into the latency record atomically.
+// Failures are ignored: the record only affects the order aliases are
+// tried in, never whether they are.
+func recordAliasLatencies(results map[string]AliasLatency) {
+	if len(results) == 0 {
+		return
+	}
+	latencyMu.Lock()
+	defer latencyMu.Unlock()
+
+	path, err := latencyPath()
+	if err != nil {
+		return
+	}
+	latencies := ReadAliasLatencies()
+	for alias, r := range results {
+		latencies[alias] = r
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	data, err := json.Marshal(latencies)
+	if err != nil {
+		return
+	}
+	_ = util.WriteFileAtomic(path, data, 0644)
+}
+
+// OrderByLatency returns aliases fastest first by their recorded latency.
+// Aliases with no record keep their configured order after the measured
+// ones, and ones that failed last time go last.
+func OrderByLatency(aliases []string, latencies map[string]AliasLatency) []string {
+	rank := func(alias string) int {
+		r, ok := latencies[alias]
+		switch {
+		case !ok:
+			return 1
+		case r.Failed:
+			return 2
+		default:
+			return 0
+		}
+	}
+
+	ordered := append([]string(nil), aliases...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		ri, rj := rank(ordered[i]), rank(ordered[j])
+		if ri != rj {
+			return ri < rj
+		}
+		if ri == 0 {
+			return latencies[ordered[i]].Latency < latencies[ordered[j]].Latency
+		}
+		return false
+	})
+	return ordered
+}
@@ -0,0 +1,45 @@
+package host
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOrderByLatency(t *testing.T) {
+	latencies := map[string]AliasLatency{
+		"vpn":  {Failed: true},
+		"lan":  {Latency: 40 * time.Millisecond},
+		"wifi": {Latency: 8 * time.Millisecond},
+	}
+
+	got := OrderByLatency([]string{"vpn", "new", "lan", "wifi", "other"}, latencies)
+	want := []string{"wifi", "lan", "new", "other", "vpn"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("OrderByLatency() = %v, want %v", got, want)
+	}
+
+	aliases := []string{"a", "b"}
+	if got := OrderByLatency(aliases, nil); !reflect.DeepEqual(got, aliases) {
+		t.Errorf("OrderByLatency() with no record = %v, want configured order %v", got, aliases)
+	}
+}
+
+func TestRecordAliasLatencies(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if got := ReadAliasLatencies(); len(got) != 0 {
+		t.Fatalf("ReadAliasLatencies() with no record = %v, want empty", got)
+	}
+
+	recordAliasLatencies(map[string]AliasLatency{"lan": {Latency: time.Second}, "vpn": {Failed: true}})
+	recordAliasLatencies(map[string]AliasLatency{"vpn": {Latency: 2 * time.Second}})
+
+	got := ReadAliasLatencies()
+	if got["lan"].Latency != time.Second {
+		t.Errorf("lan latency = %v, want 1s", got["lan"].Latency)
+	}
+	if got["vpn"].Failed || got["vpn"].Latency != 2*time.Second {
+		t.Errorf("vpn = %+v, want the newer result", got["vpn"])
+	}
+}
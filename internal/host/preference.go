@@ -0,0 +1,157 @@
+package host
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/util"
+)
+
+// preferenceFile is the file under ~/.rr/ that records which SSH alias last
+// connected to each host, per network.
+const preferenceFile = "preferred.json"
+
+// preferenceMaxAge is how long a remembered alias is kept without being
+// used again, so networks you've left for good don't pile up.
+const preferenceMaxAge = 90 * 24 * time.Hour
+
+// RememberAliases makes hosts that try their SSH aliases in order start with
+// the one that connected last time from the current network. The --fresh
+// flag clears it to probe aliases in their configured order.
+var RememberAliases = true
+
+// preferredAlias is the alias that last connected to a host.
+type preferredAlias struct {
+	Alias string    `json:"alias"`
+	At    time.Time `json:"at"`
+}
+
+// preferenceMu serializes read-modify-writes of the preference file within
+// a process.
+var preferenceMu sync.Mutex
+
+// currentNetwork identifies the network this machine is on. A variable so
+// tests can pin it.
+var currentNetwork = func() string {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return ""
+	}
+	return networkID(addrs)
+}
+
+// networkID fingerprints the networks addrs belong to. Joining a VPN or
+// moving from home Wi-Fi to the office changes the set of networks, and with
+// it the ID; loopback and link-local addresses are ignored.
+func networkID(addrs []net.Addr) string {
+	seen := make(map[string]bool)
+	var networks []string
+	for _, addr := range addrs {
+		ipnet, ok := addr.(*net.IPNet)
+		if !ok || ipnet.IP.IsLoopback() || ipnet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		network := (&net.IPNet{IP: ipnet.IP.Mask(ipnet.Mask), Mask: ipnet.Mask}).String()
+		if !seen[network] {
+			seen[network] = true
+			networks = append(networks, network)
+		}
+	}
+	if len(networks) == 0 {
+		return ""
+	}
+	sort.Strings(networks)
+	sum := sha256.Sum256([]byte(strings.Join(networks, ",")))
+	return hex.EncodeToString(sum[:6])
+}
+
+// preferencePath returns the path to the preference record.
+func preferencePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, config.GlobalConfigDir, preferenceFile), nil
+}
+
+// preferenceKey keys a host's remembered alias by network.
+func preferenceKey(network, hostName string) string {
+	return network + "/" + hostName
+}
+
+// readPreferences loads the preference record. Returns an empty map if
+// nothing has been recorded or the record is unreadable.
+func readPreferences() map[string]preferredAlias {
+	prefs := make(map[string]preferredAlias)
+	path, err := preferencePath()
+	if err != nil {
+		return prefs
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return prefs
+	}
+	_ = json.Unmarshal(data, &prefs)
+	return prefs
+}
+
+// PreferredAlias returns the alias that last connected to hostName from the
+// current network, or "" if there's none.
+func PreferredAlias(hostName string) string {
+	return readPreferences()[preferenceKey(currentNetwork(), hostName)].Alias
+}
+
+// recordPreferredAlias remembers that alias connected to hostName from the
+// current network. Failures are ignored: the record only affects the order
+// aliases are tried in, never whether they are.
+func recordPreferredAlias(hostName, alias string) {
+	preferenceMu.Lock()
+	defer preferenceMu.Unlock()
+
+	path, err := preferencePath()
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	prefs := readPreferences()
+	for key, p := range prefs {
+		if now.Sub(p.At) > preferenceMaxAge {
+			delete(prefs, key)
+		}
+	}
+	prefs[preferenceKey(currentNetwork(), hostName)] = preferredAlias{Alias: alias, At: now}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	data, err := json.Marshal(prefs)
+	if err != nil {
+		return
+	}
+	_ = util.WriteFileAtomic(path, data, 0644)
+}
+
+// preferAlias moves preferred to the front of aliases, keeping the rest in
+// their configured order. Aliases are returned unchanged if preferred isn't
+// one of them (e.g., it was removed from the config since).
+func preferAlias(aliases []string, preferred string) []string {
+	for i, alias := range aliases {
+		if alias != preferred {
+			continue
+		}
+		ordered := make([]string, 0, len(aliases))
+		ordered = append(ordered, alias)
+		ordered = append(ordered, aliases[:i]...)
+		return append(ordered, aliases[i+1:]...)
+	}
+	return aliases
+}
@@ -0,0 +1,170 @@
+package host
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+)
+
+// pinNetwork makes currentNetwork report network for the rest of the test.
+func pinNetwork(t *testing.T, network string) {
+	t.Helper()
+	orig := currentNetwork
+	currentNetwork = func() string { return network }
+	t.Cleanup(func() { currentNetwork = orig })
+}
+
+func mustCIDR(t *testing.T, cidr string) net.Addr {
+	t.Helper()
+	ip, ipnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ipnet.IP = ip
+	return ipnet
+}
+
+func TestNetworkID(t *testing.T) {
+	home := []net.Addr{mustCIDR(t, "127.0.0.1/8"), mustCIDR(t, "192.168.1.20/24"), mustCIDR(t, "fe80::1/64")}
+	homeOtherIP := []net.Addr{mustCIDR(t, "192.168.1.31/24")}
+	homeVPN := []net.Addr{mustCIDR(t, "192.168.1.20/24"), mustCIDR(t, "10.8.0.5/24")}
+	vpnHome := []net.Addr{mustCIDR(t, "10.8.0.5/24"), mustCIDR(t, "192.168.1.20/24")}
+
+	if networkID(home) == "" {
+		t.Fatal("networkID() is empty for a machine on a network")
+	}
+	if networkID(home) != networkID(homeOtherIP) {
+		t.Error("networkID() should only depend on the network, not this machine's address in it")
+	}
+	if networkID(home) == networkID(homeVPN) {
+		t.Error("networkID() should change when a VPN comes up")
+	}
+	if networkID(homeVPN) != networkID(vpnHome) {
+		t.Error("networkID() should not depend on interface order")
+	}
+	if got := networkID([]net.Addr{mustCIDR(t, "127.0.0.1/8")}); got != "" {
+		t.Errorf("networkID() with only loopback = %q, want empty", got)
+	}
+}
+
+func TestPreferAlias(t *testing.T) {
+	aliases := []string{"vpn", "lan", "tailscale"}
+
+	tests := []struct {
+		preferred string
+		want      []string
+	}{
+		{"lan", []string{"lan", "vpn", "tailscale"}},
+		{"tailscale", []string{"tailscale", "vpn", "lan"}},
+		{"vpn", aliases},
+		{"", aliases},
+		{"removed", aliases},
+	}
+	for _, tt := range tests {
+		if got := preferAlias(aliases, tt.preferred); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("preferAlias(%q) = %v, want %v", tt.preferred, got, tt.want)
+		}
+	}
+	if !reflect.DeepEqual(aliases, []string{"vpn", "lan", "tailscale"}) {
+		t.Errorf("preferAlias() modified its input: %v", aliases)
+	}
+}
+
+func TestRecordPreferredAlias(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	pinNetwork(t, "home")
+	if got := PreferredAlias("mini"); got != "" {
+		t.Fatalf("PreferredAlias() with no record = %q, want empty", got)
+	}
+	recordPreferredAlias("mini", "mini.local")
+	recordPreferredAlias("gpu", "gpu-lan")
+
+	pinNetwork(t, "cafe")
+	if got := PreferredAlias("mini"); got != "" {
+		t.Errorf("PreferredAlias() on another network = %q, want empty", got)
+	}
+	recordPreferredAlias("mini", "mini-tailscale")
+
+	pinNetwork(t, "home")
+	if got := PreferredAlias("mini"); got != "mini.local" {
+		t.Errorf("PreferredAlias(mini) at home = %q, want mini.local", got)
+	}
+	if got := PreferredAlias("gpu"); got != "gpu-lan" {
+		t.Errorf("PreferredAlias(gpu) at home = %q, want gpu-lan", got)
+	}
+}
+
+func TestRecordPreferredAlias_DropsOldNetworks(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	pinNetwork(t, "hotel")
+	recordPreferredAlias("mini", "mini-vpn")
+	prefs := readPreferences()
+	key := preferenceKey("hotel", "mini")
+	prefs[key] = preferredAlias{Alias: "mini-vpn", At: time.Now().Add(-preferenceMaxAge - time.Hour)}
+	writeTestPreferences(t, prefs)
+
+	pinNetwork(t, "home")
+	recordPreferredAlias("mini", "mini.local")
+	if _, ok := readPreferences()[key]; ok {
+		t.Error("a remembered alias unused for longer than preferenceMaxAge should be dropped")
+	}
+}
+
+func TestSelector_TriesRememberedAliasFirst(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	pinNetwork(t, "home")
+	recordPreferredAlias("test", "192.0.2.2")
+
+	hosts := map[string]config.Host{
+		"test": {SSH: []string{"192.0.2.1", "192.0.2.2"}, Dir: "/tmp/test"},
+	}
+
+	tried := func() []string {
+		selector := NewSelector(hosts)
+		selector.SetTimeout(100 * time.Millisecond)
+		defer selector.Close()
+		var aliases []string
+		selector.SetEventHandler(func(event ConnectionEvent) {
+			if event.Type == EventTrying {
+				aliases = append(aliases, event.Alias)
+			}
+		})
+		if _, err := selector.Select("test"); err == nil {
+			t.Fatal("Select should fail when all aliases are unreachable")
+		}
+		return aliases
+	}
+
+	if got, want := tried(), []string{"192.0.2.2", "192.0.2.1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("tried %v, want the remembered alias first: %v", got, want)
+	}
+
+	RememberAliases = false
+	defer func() { RememberAliases = true }()
+	if got, want := tried(), []string{"192.0.2.1", "192.0.2.2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("with --fresh tried %v, want the configured order %v", got, want)
+	}
+}
+
+// writeTestPreferences replaces the preference record.
+func writeTestPreferences(t *testing.T, prefs map[string]preferredAlias) {
+	t.Helper()
+	path, err := preferencePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(prefs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/rileyhilliard/rr/internal/config"
 	"github.com/rileyhilliard/rr/internal/errors"
 	"github.com/rileyhilliard/rr/pkg/sshutil"
 )
@@ -64,6 +65,27 @@ func (e *ProbeError) Unwrap() error {
 	return e.Cause
 }
 
+// ErrorID returns the stable error ID for this failure reason, used by
+// 'rr explain' and included in structured error output.
+func (e *ProbeError) ErrorID() string {
+	switch e.Reason {
+	case ProbeFailTimeout:
+		return errors.IDSSHTimeout
+	case ProbeFailAuth:
+		return errors.IDSSHAuth
+	case ProbeFailHostKey:
+		return errors.IDSSHHostKey
+	case ProbeFailRefused:
+		return errors.IDSSHRefused
+	case ProbeFailDNS:
+		return errors.IDSSHDNS
+	case ProbeFailUnreachable, ProbeFailConnReset:
+		return errors.IDSSHUnreachable
+	default:
+		return errors.IDSSHGeneric
+	}
+}
+
 // Probe tests connectivity to an SSH host and returns the connection latency.
 // It performs:
 //  1. A quick TCP connection test to verify the port is open
@@ -81,9 +103,15 @@ func Probe(sshAlias string, timeout time.Duration) (time.Duration, error) {
 // measure latency, close the connection, then the caller would dial again.
 // Returns the connected client, latency, and any error.
 func ProbeAndConnect(sshAlias string, timeout time.Duration) (*sshutil.Client, time.Duration, error) {
+	return ProbeAndConnectWithOptions(sshAlias, timeout, sshutil.DialOptions{})
+}
+
+// ProbeAndConnectWithOptions is ProbeAndConnect with per-host dial overrides,
+// such as the identity_file configured for the host.
+func ProbeAndConnectWithOptions(sshAlias string, timeout time.Duration, opts sshutil.DialOptions) (*sshutil.Client, time.Duration, error) {
 	start := time.Now()
 
-	client, err := sshutil.Dial(sshAlias, timeout)
+	client, err := sshutil.DialWithOptions(sshAlias, timeout, opts)
 	if err != nil {
 		return nil, 0, categorizeProbeError(sshAlias, err)
 	}
@@ -92,6 +120,52 @@ func ProbeAndConnect(sshAlias string, timeout time.Duration) (*sshutil.Client, t
 	return client, latency, nil
 }
 
+// Connect dials one SSH alias of a host with the transport it's configured
+// for: rr's own SSH client, or the system's ssh for transport: system-ssh.
+// Returns the connected client and how long connecting took, or a
+// ProbeError. h should come from config.ResolveHostSecrets, so an
+// identity_passphrase stored as a secret is dialed with the secret.
+func Connect(sshAlias string, timeout time.Duration, h config.Host) (sshutil.SSHClient, time.Duration, error) {
+	if h.Transport != config.TransportSystemSSH {
+		client, latency, err := ProbeAndConnectWithOptions(sshAlias, timeout, DialOptions(h))
+		if err != nil {
+			return nil, 0, err
+		}
+		return client, latency, nil
+	}
+
+	if h.SSHOptions.ConnectTimeout > 0 {
+		timeout = h.SSHOptions.ConnectTimeout
+	}
+	start := time.Now()
+	client, err := sshutil.DialSystem(sshAlias, timeout, SystemSSHArgs(h))
+	if err != nil {
+		return nil, 0, categorizeProbeError(sshAlias, err)
+	}
+	return client, time.Since(start), nil
+}
+
+// SystemSSHArgs returns the ssh flags for a host's identity_file and
+// ssh_options, for transport: system-ssh.
+func SystemSSHArgs(h config.Host) []string {
+	var args []string
+	if h.IdentityFile != "" {
+		args = append(args, "-i", config.ExpandTilde(h.IdentityFile))
+	}
+	return append(args, h.SSHOptions.Args()...)
+}
+
+// DialOptions returns the sshutil dial overrides configured for a host.
+func DialOptions(h config.Host) sshutil.DialOptions {
+	return sshutil.DialOptions{
+		IdentityFile:        h.IdentityFile,
+		IdentityPassphrase:  h.IdentityPassphrase,
+		Ciphers:             h.SSHOptions.Ciphers,
+		ServerAliveInterval: h.SSHOptions.ServerAliveInterval,
+		ConnectTimeout:      h.SSHOptions.ConnectTimeout,
+	}
+}
+
 // ProbeTCP performs only a TCP connection test without SSH handshake.
 // Useful for quick reachability checks before attempting full SSH connection.
 func ProbeTCP(address string, timeout time.Duration) (time.Duration, error) {
@@ -185,8 +259,8 @@ func categorizeProbeError(sshAlias string, err error) *ProbeError {
 		return probeErr
 	}
 
-	// Check for timeout
-	if strings.Contains(errStr, "timeout") || strings.Contains(errStr, "i/o timeout") {
+	// Check for timeout ("timed out" is how the system ssh says it)
+	if strings.Contains(errStr, "timeout") || strings.Contains(errStr, "timed out") {
 		probeErr.Reason = ProbeFailTimeout
 		return probeErr
 	}
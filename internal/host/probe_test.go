@@ -4,6 +4,8 @@ import (
 	"errors"
 	"testing"
 	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
 )
 
 func TestCategorizeProbeError_Timeout(t *testing.T) {
@@ -191,3 +193,39 @@ func TestCategorizeProbeError_ConnReset(t *testing.T) {
 		}
 	}
 }
+
+func TestSystemSSHArgs(t *testing.T) {
+	t.Setenv("HOME", "/home/alice")
+	args := SystemSSHArgs(config.Host{
+		IdentityFile: "~/.ssh/id_work",
+		SSHOptions:   config.SSHOptions{Ciphers: []string{"aes128-gcm@openssh.com"}},
+	})
+	want := []string{"-i", "/home/alice/.ssh/id_work", "-o", "Ciphers=aes128-gcm@openssh.com"}
+	if len(args) != len(want) {
+		t.Fatalf("SystemSSHArgs() = %v, want %v", args, want)
+	}
+	for i := range want {
+		if args[i] != want[i] {
+			t.Errorf("SystemSSHArgs()[%d] = %q, want %q", i, args[i], want[i])
+		}
+	}
+
+	if args := SystemSSHArgs(config.Host{}); len(args) != 0 {
+		t.Errorf("SystemSSHArgs() with no options = %v, want none", args)
+	}
+}
+
+func TestConnect_SystemSSHMissing(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	client, _, err := Connect("dev-box", time.Second, config.Host{Transport: config.TransportSystemSSH})
+	if err == nil {
+		t.Fatal("expected an error with no ssh on PATH")
+	}
+	if client != nil {
+		t.Error("expected no client on error")
+	}
+	var probeErr *ProbeError
+	if !errors.As(err, &probeErr) {
+		t.Errorf("expected a ProbeError, got %T", err)
+	}
+}
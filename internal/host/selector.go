@@ -8,6 +8,7 @@ import (
 
 	"github.com/rileyhilliard/rr/internal/config"
 	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/logger"
 	"github.com/rileyhilliard/rr/internal/util"
 	"github.com/rileyhilliard/rr/pkg/sshutil"
 )
@@ -127,11 +128,28 @@ func (s *Selector) SetHostOrder(order []string) {
 
 // emit sends an event to the handler if one is configured.
 func (s *Selector) emit(event ConnectionEvent) {
+	logEvent(event)
 	if s.eventHandler != nil {
 		s.eventHandler(event)
 	}
 }
 
+// logEvent reports a connection event as verbose output.
+func logEvent(event ConnectionEvent) {
+	switch event.Type {
+	case EventTrying:
+		logger.Verbosef(logger.LevelPhases, "connect", "trying %s", event.Alias)
+	case EventFailed:
+		logger.Verbosef(logger.LevelPhases, "connect", "%s failed after %s: %v", event.Alias, event.Latency, event.Error)
+	case EventConnected:
+		logger.Verbosef(logger.LevelPhases, "connect", "connected to %s in %s", event.Alias, event.Latency)
+	case EventCacheHit:
+		logger.Verbosef(logger.LevelPhases, "connect", "reusing the connection to %s", event.Alias)
+	case EventLocalFallback:
+		logger.Verbosef(logger.LevelPhases, "connect", "no host reachable, running locally")
+	}
+}
+
 // Select chooses and connects to a host, trying each SSH alias in order
 // until one succeeds (fallback behavior).
 // If preferred is specified and exists, it tries that host.
@@ -199,9 +217,9 @@ func (s *Selector) resolveHost(preferred string) (string, config.Host, error) {
 
 // connect establishes an SSH connection to the given alias.
 func (s *Selector) connect(hostName, sshAlias string, host config.Host) (*Connection, error) {
-	// ProbeAndConnect does a single SSH handshake and returns both the client
-	// and the measured latency, avoiding the previous double-handshake overhead.
-	client, latency, err := ProbeAndConnect(sshAlias, s.timeout)
+	// Connect does a single SSH handshake and returns both the client and
+	// the measured latency, avoiding the previous double-handshake overhead.
+	client, latency, err := Connect(sshAlias, s.timeout, host)
 	if err != nil {
 		return nil, err
 	}
@@ -218,11 +236,24 @@ func (s *Selector) connect(hostName, sshAlias string, host config.Host) (*Connec
 // trySSHAliases attempts to connect using each SSH alias in order.
 // Returns the first successful connection, or an error if all fail.
 // This implements the fallback chain pattern: try each alias until one works.
+// The alias that connected last time from this network goes first (see
+// RememberAliases), so a dead VPN address doesn't cost a probe timeout on
+// every run. Hosts with fallback: race try them all at once instead (see
+// raceSSHAliases).
 func (s *Selector) trySSHAliases(hostName string, host config.Host) (*Connection, error) {
+	if host.Fallback == config.FallbackRace && len(host.SSH) > 1 {
+		return s.raceSSHAliases(hostName, host)
+	}
+
+	aliases := host.SSH
+	if RememberAliases && len(aliases) > 1 {
+		aliases = preferAlias(aliases, PreferredAlias(hostName))
+	}
+
 	var lastErr error
 	var failedAliases []string
 
-	for i, sshAlias := range host.SSH {
+	for i, sshAlias := range aliases {
 		s.emit(ConnectionEvent{
 			Type:    EventTrying,
 			Alias:   sshAlias,
@@ -232,9 +263,15 @@ func (s *Selector) trySSHAliases(hostName string, host config.Host) (*Connection
 		conn, err := s.connect(hostName, sshAlias, host)
 		if err == nil {
 			msg := fmt.Sprintf("connected via %s", sshAlias)
-			if i > 0 {
+			switch {
+			case i == 0 && sshAlias != host.SSH[0]:
+				msg = fmt.Sprintf("connected via %s (last used on this network)", sshAlias)
+			case i > 0:
 				msg = fmt.Sprintf("connected via %s (fallback)", sshAlias)
 			}
+			if len(aliases) > 1 {
+				recordPreferredAlias(hostName, sshAlias)
+			}
 			s.emit(ConnectionEvent{
 				Type:    EventConnected,
 				Alias:   sshAlias,
@@ -263,6 +300,110 @@ func (s *Selector) trySSHAliases(hostName string, host config.Host) (*Connection
 		"The remote might be offline, or there could be a network/firewall issue.")
 }
 
+// raceSSHAliases connects through every SSH alias at once and keeps the
+// first to succeed, so a dead address (an unreachable VPN, a LAN IP away from
+// home) costs nothing when another one works. Aliases start fastest first by
+// their recorded latency (see OrderByLatency), which is also the order
+// they're reported in.
+func (s *Selector) raceSSHAliases(hostName string, host config.Host) (*Connection, error) {
+	aliases := OrderByLatency(host.SSH, ReadAliasLatencies())
+	for _, sshAlias := range aliases {
+		s.emit(ConnectionEvent{
+			Type:    EventTrying,
+			Alias:   sshAlias,
+			Message: fmt.Sprintf("racing alias %s", sshAlias),
+		})
+	}
+
+	var failedAliases []string
+	conn, err := raceAliases(aliases,
+		func(sshAlias string) (*Connection, error) {
+			return s.connect(hostName, sshAlias, host)
+		},
+		func(sshAlias string, err error) {
+			errMsg := "connection failed"
+			if probeErr, ok := err.(*ProbeError); ok {
+				errMsg = probeErr.Reason.String()
+			}
+			s.emit(ConnectionEvent{
+				Type:    EventFailed,
+				Alias:   sshAlias,
+				Message: errMsg,
+				Error:   err,
+			})
+			failedAliases = append(failedAliases, sshAlias)
+		})
+	if err != nil {
+		return nil, errors.WrapWithCode(err, errors.ErrSSH,
+			fmt.Sprintf("Couldn't connect to '%s' - tried: %s", hostName, formatFailedAliases(failedAliases)),
+			"The remote might be offline, or there could be a network/firewall issue.")
+	}
+
+	s.emit(ConnectionEvent{
+		Type:    EventConnected,
+		Alias:   conn.Alias,
+		Message: fmt.Sprintf("connected via %s (fastest of %d)", conn.Alias, len(aliases)),
+		Latency: conn.Latency,
+	})
+	return conn, nil
+}
+
+// raceResult is the outcome of one alias in a race.
+type raceResult struct {
+	alias string
+	conn  *Connection
+	err   error
+}
+
+// raceAliases connects through every alias at once and returns the first
+// connection to succeed, calling failed for each alias that fails before
+// then. The other attempts finish in the background, where the connections
+// that lost are closed. How each alias did is recorded for OrderByLatency.
+// Returns the last error when every alias fails.
+func raceAliases(aliases []string, connect func(alias string) (*Connection, error), failed func(alias string, err error)) (*Connection, error) {
+	results := make(chan raceResult, len(aliases))
+	for _, alias := range aliases {
+		go func() {
+			conn, err := connect(alias)
+			results <- raceResult{alias: alias, conn: conn, err: err}
+		}()
+	}
+
+	record := make(map[string]AliasLatency, len(aliases))
+	var lastErr error
+	for remaining := len(aliases); remaining > 0; remaining-- {
+		r := <-results
+		if r.err != nil {
+			record[r.alias] = AliasLatency{Failed: true, At: time.Now()}
+			failed(r.alias, r.err)
+			lastErr = r.err
+			continue
+		}
+		record[r.alias] = AliasLatency{Latency: r.conn.Latency, At: time.Now()}
+		recordAliasLatencies(record)
+		go finishRace(results, remaining-1)
+		return r.conn, nil
+	}
+	recordAliasLatencies(record)
+	return nil, lastErr
+}
+
+// finishRace waits for the rest of a race, closing the connections that
+// lost and recording how their aliases did.
+func finishRace(results <-chan raceResult, remaining int) {
+	record := make(map[string]AliasLatency, remaining)
+	for ; remaining > 0; remaining-- {
+		r := <-results
+		if r.err != nil {
+			record[r.alias] = AliasLatency{Failed: true, At: time.Now()}
+			continue
+		}
+		record[r.alias] = AliasLatency{Latency: r.conn.Latency, At: time.Now()}
+		_ = r.conn.Close()
+	}
+	recordAliasLatencies(record)
+}
+
 // isConnectionAlive checks if the cached connection is still usable.
 //
 // We use SSH's "keepalive@openssh.com" request instead of creating a new session
@@ -453,6 +594,13 @@ func (s *Selector) selectUnlocked(preferred string) (*Connection, error) {
 			"Add something like 'user@hostname' under the 'ssh:' section for this host.")
 	}
 
+	// Look up the host's secrets now that it's the one to use. One that
+	// can't be is a config problem, not an unreachable host, so it doesn't
+	// fall back to local.
+	if host, err = config.ResolveHostSecrets(hostName, host); err != nil {
+		return nil, err
+	}
+
 	// Try each SSH alias in order (fallback chain)
 	conn, err := s.trySSHAliases(hostName, host)
 	if err == nil {
@@ -588,9 +736,52 @@ func (s *Selector) SelectHost(hostName string) (*Connection, error) {
 			"Add something like 'user@hostname' under the 'ssh:' section for this host.")
 	}
 
+	host, err := config.ResolveHostSecrets(hostName, host)
+	if err != nil {
+		return nil, err
+	}
 	return s.trySSHAliases(hostName, host)
 }
 
+// Probe checks that a host is reachable through one of its SSH aliases,
+// trying them in the order a connection would, and returns the first one's
+// connect latency. The probe connection is closed again. Probe doesn't emit
+// events or touch the cached connection, so it can run while something else
+// is using the selector, like the host picker.
+func (s *Selector) Probe(hostName string) (time.Duration, error) {
+	s.mu.Lock()
+	host, ok := s.hosts[hostName]
+	timeout := s.timeout
+	s.mu.Unlock()
+
+	if !ok {
+		return 0, errors.New(errors.ErrConfig,
+			fmt.Sprintf("Host '%s' doesn't exist", hostName),
+			fmt.Sprintf("Available hosts: %s", s.hostNames()))
+	}
+	if len(host.SSH) == 0 {
+		return 0, errors.New(errors.ErrConfig,
+			fmt.Sprintf("Host '%s' needs at least one SSH connection", hostName),
+			"Add something like 'user@hostname' under the 'ssh:' section for this host.")
+	}
+
+	aliases := host.SSH
+	if RememberAliases && len(aliases) > 1 {
+		aliases = preferAlias(aliases, PreferredAlias(hostName))
+	}
+
+	var lastErr error
+	for _, alias := range aliases {
+		client, latency, err := Connect(alias, timeout, host)
+		if err == nil {
+			client.Close()
+			return latency, nil
+		}
+		lastErr = err
+	}
+	return 0, lastErr
+}
+
 // SelectNextHost returns the next available host after skipping the specified hosts.
 // Hosts are tried in alphabetical order for deterministic behavior.
 // Returns an error if all hosts have been skipped.
@@ -552,6 +552,38 @@ func TestSelector_Select_AllAliasesFail(t *testing.T) {
 	}
 }
 
+func TestSelector_Probe(t *testing.T) {
+	hosts := map[string]config.Host{
+		"down":  {SSH: []string{"127.0.0.1:1"}},
+		"empty": {SSH: []string{}},
+	}
+
+	selector := NewSelector(hosts)
+	selector.SetTimeout(1 * time.Second)
+	defer selector.Close()
+
+	var events int
+	selector.SetEventHandler(func(ConnectionEvent) { events++ })
+
+	if _, err := selector.Probe("nonexistent"); err == nil {
+		t.Error("Probe should fail when host not found")
+	}
+	if _, err := selector.Probe("empty"); err == nil {
+		t.Error("Probe should fail when host has no SSH aliases")
+	}
+	if _, err := selector.Probe("down"); err == nil {
+		t.Error("Probe should fail when the host is unreachable")
+	}
+
+	// Probes are silent and leave the connection cache alone
+	if events != 0 {
+		t.Errorf("expected no events, got %d", events)
+	}
+	if selector.GetCached() != nil {
+		t.Error("Probe shouldn't cache a connection")
+	}
+}
+
 func TestSelector_EventHandler_CacheHit(t *testing.T) {
 	skipIfNoSSH(t)
 
@@ -729,6 +761,31 @@ func TestSelector_LocalFallback_Disabled(t *testing.T) {
 	}
 }
 
+func TestSelector_LocalFallback_NotForBrokenSecret(t *testing.T) {
+	hosts := map[string]config.Host{
+		"test": {
+			SSH: []string{"192.0.2.1"},
+			Dir: "/tmp/test",
+			Env: map[string]string{"TOKEN": "keychain:has space"},
+		},
+	}
+
+	selector := NewSelector(hosts)
+	selector.SetTimeout(1 * time.Second)
+	selector.SetLocalFallback(true)
+	defer selector.Close()
+
+	// A secret that can't be looked up is a config problem, so the run
+	// stops instead of quietly going local
+	conn, err := selector.Select("test")
+	if err == nil {
+		t.Fatalf("Select should fail, got connection %q", conn.Name)
+	}
+	if !containsString(err.Error(), "isn't a valid secret name") {
+		t.Errorf("error should name the secret problem: %v", err)
+	}
+}
+
 func TestSelector_LocalFallback_CachesConnection(t *testing.T) {
 	hosts := map[string]config.Host{
 		"test": {
@@ -1515,3 +1572,67 @@ func TestSelector_ResolveHostAndGetHostNames_SameOrdering(t *testing.T) {
 		}
 	})
 }
+
+func TestRaceAliases_FirstSuccessWins(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	release := make(chan struct{})
+	connect := func(alias string) (*Connection, error) {
+		switch alias {
+		case "dead-vpn":
+			<-release // Would otherwise hang until the probe timeout
+			return nil, &ProbeError{SSHAlias: alias, Reason: ProbeFailTimeout}
+		case "lan":
+			return &Connection{Alias: alias, Latency: 5 * time.Millisecond}, nil
+		default:
+			return nil, &ProbeError{SSHAlias: alias, Reason: ProbeFailRefused}
+		}
+	}
+
+	var failed []string
+	conn, err := raceAliases([]string{"dead-vpn", "refused", "lan"}, connect, func(alias string, err error) {
+		failed = append(failed, alias)
+	})
+	if err != nil {
+		t.Fatalf("raceAliases() error = %v", err)
+	}
+	if conn.Alias != "lan" {
+		t.Errorf("winner = %q, want lan", conn.Alias)
+	}
+	if len(failed) > 1 || (len(failed) == 1 && failed[0] != "refused") {
+		t.Errorf("failed = %v, want only aliases that failed before the winner", failed)
+	}
+
+	// The straggler is recorded once it finishes
+	close(release)
+	deadline := time.Now().Add(2 * time.Second)
+	for !ReadAliasLatencies()["dead-vpn"].Failed {
+		if time.Now().After(deadline) {
+			t.Fatal("dead-vpn was never recorded as failed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := ReadAliasLatencies()["lan"].Latency; got != 5*time.Millisecond {
+		t.Errorf("lan latency = %v, want 5ms", got)
+	}
+}
+
+func TestRaceAliases_AllFail(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	connect := func(alias string) (*Connection, error) {
+		return nil, &ProbeError{SSHAlias: alias, Reason: ProbeFailUnreachable}
+	}
+
+	failCount := 0
+	conn, err := raceAliases([]string{"a", "b"}, connect, func(string, error) { failCount++ })
+	if err == nil || conn != nil {
+		t.Fatalf("raceAliases() = %v, %v, want an error", conn, err)
+	}
+	if failCount != 2 {
+		t.Errorf("failed called %d times, want 2", failCount)
+	}
+	if latencies := ReadAliasLatencies(); !latencies["a"].Failed || !latencies["b"].Failed {
+		t.Errorf("latencies = %v, want both recorded as failed", latencies)
+	}
+}
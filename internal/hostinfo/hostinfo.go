@@ -0,0 +1,383 @@
+// Package hostinfo detects what a remote host is (OS, architecture, CPU cores,
+// RAM, GPU, rsync version) so 'rr host list' can show it without the user
+// having to remember which box has the big GPU.
+//
+// Detection costs an SSH round trip, so results are cached in
+// ~/.rr/hostinfo.json keyed by host name and only refreshed when they're
+// missing, older than MaxAge, or the user asks. A failed detection is
+// cached too, for FailureTTL, so an unreachable host isn't retried on
+// every command.
+package hostinfo
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/util"
+	"github.com/rileyhilliard/rr/pkg/sshutil"
+)
+
+// cacheFile is the file under ~/.rr/ that holds detected host info.
+const cacheFile = "hostinfo.json"
+
+// MaxAge is how long detected info is trusted before it's gathered again.
+// Hardware rarely changes, but rsync upgrades and GPU swaps do happen.
+const MaxAge = 7 * 24 * time.Hour
+
+// FailureTTL is how long a failed detection is remembered before the host
+// is tried again.
+const FailureTTL = 10 * time.Minute
+
+// Info describes a host's platform and hardware.
+type Info struct {
+	OS           string    `json:"os"`                   // linux, darwin
+	Arch         string    `json:"arch"`                 // amd64, arm64
+	CPUCores     int       `json:"cpu_cores"`            // Logical cores
+	RAMBytes     int64     `json:"ram_bytes"`            // Total physical memory
+	GPUs         []string  `json:"gpus,omitempty"`       // One entry per GPU, by model name
+	RsyncVersion string    `json:"rsync_version"`        // e.g. "3.2.7", "openrsync"; empty if not installed
+	RsyncPath    string    `json:"rsync_path,omitempty"` // A better rsync than the one on PATH, if one was found
+	CheckedAt    time.Time `json:"checked_at"`
+	FailedAt     time.Time `json:"failed_at,omitempty"` // Last failed detection since CheckedAt
+	Err          string    `json:"error,omitempty"`     // Why it failed
+}
+
+// Platform returns "os/arch", e.g. "linux/arm64".
+func (i Info) Platform() string {
+	if i.OS == "" {
+		return ""
+	}
+	if i.Arch == "" {
+		return i.OS
+	}
+	return i.OS + "/" + i.Arch
+}
+
+// Stale reports whether the info is older than MaxAge.
+func (i Info) Stale(now time.Time) bool {
+	return now.Sub(i.CheckedAt) > MaxAge
+}
+
+// Detected reports whether the host was ever detected, as opposed to only
+// failing.
+func (i Info) Detected() bool {
+	return !i.CheckedAt.IsZero()
+}
+
+// NeedsDetection reports whether the host should be detected again: its
+// info is missing or stale, and it hasn't failed in the last FailureTTL.
+func (i Info) NeedsDetection(now time.Time) bool {
+	if !i.FailedAt.IsZero() && now.Sub(i.FailedAt) < FailureTTL {
+		return false
+	}
+	return !i.Detected() || i.Stale(now)
+}
+
+// WithFailure returns the info with a failed detection recorded at now,
+// keeping whatever was detected before.
+func (i Info) WithFailure(err error, now time.Time) Info {
+	i.FailedAt = now
+	i.Err = err.Error()
+	return i
+}
+
+// Summary returns a one-line description for display:
+// "linux/amd64 · 16 cores · 64 GB RAM · 2x NVIDIA RTX 4090 · rsync 3.2.7".
+func (i Info) Summary() string {
+	var parts []string
+	if p := i.Platform(); p != "" {
+		parts = append(parts, p)
+	}
+	if i.CPUCores > 0 {
+		parts = append(parts, fmt.Sprintf("%d cores", i.CPUCores))
+	}
+	if i.RAMBytes > 0 {
+		parts = append(parts, formatRAM(i.RAMBytes)+" RAM")
+	}
+	if gpu := formatGPUs(i.GPUs); gpu != "" {
+		parts = append(parts, gpu)
+	}
+	if i.RsyncVersion != "" && i.RsyncPath != "" {
+		parts = append(parts, "rsync "+i.RsyncVersion+" at "+i.RsyncPath)
+	} else if i.RsyncVersion != "" {
+		parts = append(parts, "rsync "+i.RsyncVersion)
+	} else {
+		parts = append(parts, "no rsync")
+	}
+	return strings.Join(parts, " · ")
+}
+
+// formatRAM rounds to whole gigabytes, since that's how machines are sold.
+func formatRAM(bytes int64) string {
+	const gb = 1 << 30
+	if bytes < gb {
+		return fmt.Sprintf("%d MB", bytes>>20)
+	}
+	return fmt.Sprintf("%d GB", int64(math.Round(float64(bytes)/gb)))
+}
+
+// formatGPUs collapses identical GPUs: ["A100", "A100"] -> "2x A100".
+func formatGPUs(gpus []string) string {
+	var order []string
+	counts := make(map[string]int)
+	for _, g := range gpus {
+		if counts[g] == 0 {
+			order = append(order, g)
+		}
+		counts[g]++
+	}
+
+	parts := make([]string, 0, len(order))
+	for _, g := range order {
+		if counts[g] > 1 {
+			parts = append(parts, fmt.Sprintf("%dx %s", counts[g], g))
+		} else {
+			parts = append(parts, g)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// alternateRsyncs are where rsync installs that a non-interactive shell's
+// PATH often misses live: Homebrew on Apple Silicon and Intel Macs, and
+// MacPorts. A Mac's own /usr/bin/rsync is openrsync or GNU rsync 2.6.9.
+var alternateRsyncs = []string{"/opt/homebrew/bin/rsync", "/usr/local/bin/rsync", "/opt/local/bin/rsync"}
+
+// detectCommand prints one key=value line per fact. Every probe is allowed to
+// fail so a missing tool (no nvidia-smi, no nproc on macOS) just leaves that
+// field empty. Each of alternateRsyncs that's installed gets an rsync_alt
+// line with its path and version.
+var detectCommand = `echo "os=$(uname -s 2>/dev/null)"
+echo "arch=$(uname -m 2>/dev/null)"
+echo "cores=$(nproc 2>/dev/null || getconf _NPROCESSORS_ONLN 2>/dev/null || sysctl -n hw.ncpu 2>/dev/null)"
+echo "mem_kb=$(awk '/^MemTotal:/ {print $2}' /proc/meminfo 2>/dev/null)"
+echo "mem_bytes=$(sysctl -n hw.memsize 2>/dev/null)"
+nvidia-smi --query-gpu=name --format=csv,noheader 2>/dev/null | sed 's/^/gpu=/'
+echo "chip=$(sysctl -n machdep.cpu.brand_string 2>/dev/null)"
+echo "rsync=$(rsync --version 2>/dev/null | head -n 1)"
+echo "rsync_bin=$(command -v rsync 2>/dev/null)"
+for p in ` + strings.Join(alternateRsyncs, " ") + `; do
+  [ -x "$p" ] && echo "rsync_alt=$p $("$p" --version 2>/dev/null | head -n 1)"
+done
+exit 0`
+
+// Detect gathers host info over an existing SSH connection.
+func Detect(client sshutil.SSHClient) (Info, error) {
+	stdout, stderr, exitCode, err := client.Exec(detectCommand)
+	if err != nil {
+		return Info{}, err
+	}
+	if exitCode != 0 {
+		return Info{}, errors.New(errors.ErrExec,
+			fmt.Sprintf("Host detection exited with code %d: %s", exitCode, strings.TrimSpace(string(stderr))),
+			"Check that the remote login shell is POSIX-compatible (sh, bash, zsh).")
+	}
+	return Parse(string(stdout), time.Now()), nil
+}
+
+// Parse reads the output of the detection command.
+func Parse(output string, now time.Time) Info {
+	info := Info{CheckedAt: now}
+	var chip, rsyncBin string
+	var alternates [][2]string // path, version
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		switch key {
+		case "os":
+			info.OS = strings.ToLower(value)
+		case "arch":
+			info.Arch = normalizeArch(value)
+		case "cores":
+			if n, err := strconv.Atoi(value); err == nil {
+				info.CPUCores = n
+			}
+		case "mem_kb":
+			if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+				info.RAMBytes = n * 1024
+			}
+		case "mem_bytes":
+			if n, err := strconv.ParseInt(value, 10, 64); err == nil && info.RAMBytes == 0 {
+				info.RAMBytes = n
+			}
+		case "gpu":
+			info.GPUs = append(info.GPUs, value)
+		case "chip":
+			chip = value
+		case "rsync":
+			info.RsyncVersion = parseRsyncVersion(value)
+		case "rsync_bin":
+			rsyncBin = value
+		case "rsync_alt":
+			path, line, _ := strings.Cut(value, " ")
+			alternates = append(alternates, [2]string{path, parseRsyncVersion(strings.TrimSpace(line))})
+		}
+	}
+
+	// An rsync PATH misses wins over PATH's when it's newer, like Homebrew's
+	// next to a Mac's openrsync
+	for _, alt := range alternates {
+		if alt[0] != rsyncBin && newerRsync(alt[1], info.RsyncVersion) {
+			info.RsyncPath, info.RsyncVersion = alt[0], alt[1]
+		}
+	}
+
+	// Apple Silicon GPUs are part of the chip, so the chip name is the model
+	if len(info.GPUs) == 0 && info.OS == "darwin" && strings.HasPrefix(chip, "Apple ") {
+		info.GPUs = []string{chip}
+	}
+
+	return info
+}
+
+// normalizeArch maps uname -m output to Go-style architecture names.
+func normalizeArch(arch string) string {
+	switch arch {
+	case "x86_64":
+		return "amd64"
+	case "aarch64", "arm64":
+		return "arm64"
+	case "i386", "i686":
+		return "386"
+	default:
+		return arch
+	}
+}
+
+// parseRsyncVersion extracts the version from the first line of
+// 'rsync --version', e.g. "rsync  version 3.2.7  protocol version 31".
+// macOS ships openrsync, which doesn't print a version number.
+func parseRsyncVersion(line string) string {
+	fields := strings.Fields(line)
+	for i, f := range fields {
+		if f == "version" && i+1 < len(fields) && i > 0 && fields[i-1] == "rsync" {
+			return fields[i+1]
+		}
+	}
+	if strings.HasPrefix(line, "openrsync") {
+		return "openrsync"
+	}
+	return "unknown"
+}
+
+// newerRsync reports whether rsync version a is newer than b, as
+// parseRsyncVersion reports them. Any GNU version beats openrsync, an
+// unknown version, or none.
+func newerRsync(a, b string) bool {
+	av, aok := rsyncVersionParts(a)
+	if !aok {
+		return false
+	}
+	bv, bok := rsyncVersionParts(b)
+	if !bok {
+		return true
+	}
+	for i := 0; i < len(av) || i < len(bv); i++ {
+		var x, y int
+		if i < len(av) {
+			x = av[i]
+		}
+		if i < len(bv) {
+			y = bv[i]
+		}
+		if x != y {
+			return x > y
+		}
+	}
+	return false
+}
+
+// rsyncVersionParts splits a version like "3.2.7" or "v3.2.3" into numbers.
+func rsyncVersionParts(v string) ([]int, bool) {
+	fields := strings.Split(strings.TrimPrefix(v, "v"), ".")
+	parts := make([]int, 0, len(fields))
+	for _, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, false
+		}
+		parts = append(parts, n)
+	}
+	return parts, true
+}
+
+// Path returns the cache file location.
+func Path() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.WrapWithCode(err, errors.ErrConfig,
+			"Can't find your home directory",
+			"This is unusual - check your environment.")
+	}
+	return filepath.Join(home, config.GlobalConfigDir, cacheFile), nil
+}
+
+// Load reads cached info for all hosts. Returns an empty map if nothing has
+// been cached yet. A corrupt cache is treated as empty since it can always
+// be gathered again.
+func Load() (map[string]Info, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	cache := make(map[string]Info)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cache, nil
+		}
+		return nil, errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't read host info cache",
+			"Delete "+path+" to start fresh.")
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return make(map[string]Info), nil
+	}
+	return cache, nil
+}
+
+// Save writes the cache atomically.
+func Save(cache map[string]Info) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't encode host info cache",
+			"This is a bug - please report it.")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't create ~/.rr directory",
+			"Check permissions on ~/.rr/.")
+	}
+	if err := util.WriteFileAtomic(path, data, 0644); err != nil {
+		return errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't write host info cache",
+			"Check permissions on ~/.rr/.")
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package hostinfo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	sshtesting "github.com/rileyhilliard/rr/pkg/sshutil/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_Linux(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	output := `os=Linux
+arch=x86_64
+cores=32
+mem_kb=65841180
+mem_bytes=
+gpu=NVIDIA GeForce RTX 4090
+gpu=NVIDIA GeForce RTX 4090
+chip=
+rsync=rsync  version 3.2.7  protocol version 31
+`
+	info := Parse(output, now)
+
+	assert.Equal(t, "linux", info.OS)
+	assert.Equal(t, "amd64", info.Arch)
+	assert.Equal(t, "linux/amd64", info.Platform())
+	assert.Equal(t, 32, info.CPUCores)
+	assert.Equal(t, int64(65841180*1024), info.RAMBytes)
+	assert.Equal(t, []string{"NVIDIA GeForce RTX 4090", "NVIDIA GeForce RTX 4090"}, info.GPUs)
+	assert.Equal(t, "3.2.7", info.RsyncVersion)
+	assert.Equal(t, now, info.CheckedAt)
+	assert.Equal(t, "linux/amd64 · 32 cores · 63 GB RAM · 2x NVIDIA GeForce RTX 4090 · rsync 3.2.7", info.Summary())
+}
+
+func TestParse_Darwin(t *testing.T) {
+	output := `os=Darwin
+arch=arm64
+cores=12
+mem_kb=
+mem_bytes=34359738368
+chip=Apple M2 Max
+rsync=openrsync: protocol version 29
+`
+	info := Parse(output, time.Now())
+
+	assert.Equal(t, "darwin/arm64", info.Platform())
+	assert.Equal(t, 12, info.CPUCores)
+	assert.Equal(t, int64(32<<30), info.RAMBytes)
+	assert.Equal(t, []string{"Apple M2 Max"}, info.GPUs, "Apple Silicon chip doubles as the GPU model")
+	assert.Equal(t, "openrsync", info.RsyncVersion)
+	assert.Equal(t, "darwin/arm64 · 12 cores · 32 GB RAM · Apple M2 Max · rsync openrsync", info.Summary())
+}
+
+func TestParse_AlternateRsync(t *testing.T) {
+	output := `os=Darwin
+rsync=openrsync: protocol version 29
+rsync_bin=/usr/bin/rsync
+rsync_alt=/opt/homebrew/bin/rsync rsync  version 3.3.0  protocol version 31
+rsync_alt=/usr/local/bin/rsync rsync  version 3.2.7  protocol version 31
+`
+	info := Parse(output, time.Now())
+
+	assert.Equal(t, "/opt/homebrew/bin/rsync", info.RsyncPath, "the newest rsync PATH misses")
+	assert.Equal(t, "3.3.0", info.RsyncVersion)
+	assert.Equal(t, "darwin · rsync 3.3.0 at /opt/homebrew/bin/rsync", info.Summary())
+}
+
+func TestParse_AlternateRsyncOnPath(t *testing.T) {
+	output := `rsync=rsync  version 3.3.0  protocol version 31
+rsync_bin=/opt/homebrew/bin/rsync
+rsync_alt=/opt/homebrew/bin/rsync rsync  version 3.3.0  protocol version 31
+rsync_alt=/usr/local/bin/rsync rsync  version 3.2.7  protocol version 31
+`
+	info := Parse(output, time.Now())
+
+	assert.Empty(t, info.RsyncPath, "PATH already finds the best one")
+	assert.Equal(t, "3.3.0", info.RsyncVersion)
+}
+
+func TestNewerRsync(t *testing.T) {
+	assert.True(t, newerRsync("3.2.7", "openrsync"))
+	assert.True(t, newerRsync("3.2.7", ""))
+	assert.True(t, newerRsync("3.2.7", "2.6.9"))
+	assert.True(t, newerRsync("3.10.0", "3.9.1"))
+	assert.True(t, newerRsync("v3.2.3", "3.2"))
+	assert.False(t, newerRsync("3.2.7", "3.2.7"))
+	assert.False(t, newerRsync("openrsync", "2.6.9"))
+	assert.False(t, newerRsync("unknown", ""))
+}
+
+func TestParse_MissingTools(t *testing.T) {
+	info := Parse("os=Linux\narch=aarch64\ncores=\nrsync=\n", time.Now())
+
+	assert.Equal(t, "linux/arm64", info.Platform())
+	assert.Zero(t, info.CPUCores)
+	assert.Empty(t, info.GPUs)
+	assert.Empty(t, info.RsyncVersion)
+	assert.Equal(t, "linux/arm64 · no rsync", info.Summary())
+}
+
+func TestParseRsyncVersion(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"rsync  version 3.2.7  protocol version 31", "3.2.7"},
+		{"rsync  version v3.2.3  protocol version 31", "v3.2.3"},
+		{"openrsync: protocol version 29", "openrsync"},
+		{"something odd", "unknown"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, parseRsyncVersion(tt.line), tt.line)
+	}
+}
+
+func TestFormatRAM(t *testing.T) {
+	assert.Equal(t, "512 MB", formatRAM(512<<20))
+	assert.Equal(t, "16 GB", formatRAM(16<<30))
+	assert.Equal(t, "16 GB", formatRAM(16<<30-100<<20))
+}
+
+func TestStale(t *testing.T) {
+	now := time.Now()
+	assert.False(t, Info{CheckedAt: now.Add(-time.Hour)}.Stale(now))
+	assert.True(t, Info{CheckedAt: now.Add(-MaxAge - time.Hour)}.Stale(now))
+}
+
+func TestNeedsDetection(t *testing.T) {
+	now := time.Now()
+	stale := Info{OS: "linux", CheckedAt: now.Add(-MaxAge - time.Hour)}
+
+	assert.True(t, Info{}.NeedsDetection(now))
+	assert.False(t, Info{CheckedAt: now.Add(-time.Hour)}.NeedsDetection(now))
+	assert.True(t, stale.NeedsDetection(now))
+
+	failed := stale.WithFailure(errors.New("unreachable"), now.Add(-time.Minute))
+	assert.False(t, failed.NeedsDetection(now), "a recent failure isn't retried")
+	assert.True(t, failed.NeedsDetection(now.Add(FailureTTL)), "a failure is retried after FailureTTL")
+	assert.Equal(t, "linux", failed.OS, "earlier info is kept")
+	assert.Equal(t, "unreachable", failed.Err)
+
+	assert.False(t, Info{}.WithFailure(errors.New("unreachable"), now).Detected())
+}
+
+func TestDetect(t *testing.T) {
+	client := sshtesting.NewMockClient("box")
+	client.SetCommandResponse("uname", sshtesting.CommandResponse{
+		Stdout: []byte("os=Linux\narch=x86_64\ncores=8\nrsync=rsync  version 3.2.7  protocol version 31\n"),
+	})
+
+	info, err := Detect(client)
+	require.NoError(t, err)
+	assert.Equal(t, "linux/amd64", info.Platform())
+	assert.Equal(t, 8, info.CPUCores)
+	assert.Equal(t, "3.2.7", info.RsyncVersion)
+	assert.False(t, info.CheckedAt.IsZero())
+}
+
+func TestDetect_NonZeroExit(t *testing.T) {
+	client := sshtesting.NewMockClient("box")
+	client.SetCommandResponse("uname", sshtesting.CommandResponse{
+		Stderr:   []byte("fish: Unsupported use of '='"),
+		ExitCode: 127,
+	})
+
+	_, err := Detect(client)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "127")
+}
+
+func TestLoad_NoCache(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cache, err := Load()
+	require.NoError(t, err)
+	assert.Empty(t, cache)
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	checked := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	want := map[string]Info{
+		"gpu-box": {OS: "linux", Arch: "amd64", CPUCores: 32, GPUs: []string{"NVIDIA A100"}, RsyncVersion: "3.2.7", CheckedAt: checked},
+	}
+	require.NoError(t, Save(want))
+
+	path, err := Path()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".rr", "hostinfo.json"), path)
+
+	got, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestLoad_CorruptCacheIsEmpty(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".rr"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".rr", "hostinfo.json"), []byte("{not json"), 0644))
+
+	cache, err := Load()
+	require.NoError(t, err)
+	assert.Empty(t, cache)
+}
@@ -0,0 +1,102 @@
+package inventory
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/util"
+)
+
+// cacheFile is the file under ~/.rr/ that holds each source's last results.
+const cacheFile = "inventory.json"
+
+// cacheMaxAge is how long a source's results are kept without being listed
+// again, so sources removed from the config don't pile up.
+const cacheMaxAge = 30 * 24 * time.Hour
+
+// cachedSource is one source's machines and when they were listed.
+type cachedSource struct {
+	At       time.Time `json:"at"`
+	Machines []Machine `json:"machines"`
+}
+
+// cacheMu serializes read-modify-writes of the cache file within a process.
+var cacheMu sync.Mutex
+
+// cachePath returns the path to the cache file.
+func cachePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, config.GlobalConfigDir, cacheFile), nil
+}
+
+// cacheKey identifies a source by what it queries, so changing its dir,
+// tags or prefix reuses the cached machines but changing a filter doesn't.
+func cacheKey(src config.InventoryConfig) string {
+	query, _ := json.Marshal(struct {
+		Provider, Tag, Region, Project string
+		Filters                        map[string]string
+	}{src.Provider, src.Tag, src.Region, src.Project, src.Filters})
+	sum := sha256.Sum256(query)
+	return src.Provider + "-" + hex.EncodeToString(sum[:6])
+}
+
+// readAll loads the cache file. Returns an empty map if there's nothing
+// cached or the file is unreadable.
+func readAll() map[string]cachedSource {
+	entries := make(map[string]cachedSource)
+	path, err := cachePath()
+	if err != nil {
+		return entries
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return entries
+	}
+	_ = json.Unmarshal(data, &entries)
+	return entries
+}
+
+// readCache returns src's cached machines and when they were listed, if
+// there are any.
+func readCache(src config.InventoryConfig) ([]Machine, time.Time, bool) {
+	entry, ok := readAll()[cacheKey(src)]
+	return entry.Machines, entry.At, ok
+}
+
+// writeCache records src's machines. Failures are ignored: the cache only
+// saves asking the provider again.
+func writeCache(src config.InventoryConfig, machines []Machine) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
+	path, err := cachePath()
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	entries := readAll()
+	for key, entry := range entries {
+		if now.Sub(entry.At) > cacheMaxAge {
+			delete(entries, key)
+		}
+	}
+	entries[cacheKey(src)] = cachedSource{At: now, Machines: machines}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return
+	}
+	_ = util.WriteFileAtomic(path, data, 0644)
+}
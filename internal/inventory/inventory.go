@@ -0,0 +1,144 @@
+// Package inventory discovers hosts from outside rr's config, like the peers
+// on a tailnet or cloud instances with a tag, so fleets that come and go
+// don't have to be kept in ~/.rr/config.yaml by hand.
+package inventory
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/logger"
+)
+
+// discoverTimeout bounds each call to a provider's CLI, so an unreachable
+// control plane costs a few seconds rather than hanging the run.
+const discoverTimeout = 15 * time.Second
+
+// Machine is a host a provider found.
+type Machine struct {
+	// Name identifies the machine within its provider, like its Tailscale
+	// host name or its EC2 Name tag.
+	Name string `json:"name"`
+
+	// Addrs are the addresses to SSH to, best first.
+	Addrs []string `json:"addrs"`
+}
+
+// Provider discovers machines from one kind of inventory.
+type Provider interface {
+	// Discover returns the machines src currently matches.
+	Discover(ctx context.Context, src config.InventoryConfig) ([]Machine, error)
+}
+
+// providers are the inventory providers by config name.
+var providers = map[string]Provider{
+	config.InventoryTailscale: tailscale{},
+	config.InventoryAWS:       aws{},
+	config.InventoryGCP:       gcp{},
+}
+
+// runCommand runs a provider's CLI and returns its stdout. A variable so
+// tests can fake the CLIs.
+var runCommand = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+	logger.Verbosef(logger.LevelCommands, "inventory", "%s %s", name, strings.Join(args, " "))
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %w: %s", name, err, msg)
+		}
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return out, nil
+}
+
+// Merge adds the hosts each of cfg's inventory sources finds to cfg.Hosts.
+// Declared hosts win name clashes. When refresh is set, cached results are
+// ignored and every provider is asked again.
+//
+// A source that can't be queried falls back to its last cached hosts, or
+// adds none, and is reported in the returned errors; the other sources
+// still merge, so one logged-out CLI doesn't stop runs on the rest.
+func Merge(cfg *config.GlobalConfig, refresh bool) []error {
+	var errs []error
+	for _, src := range cfg.Inventory {
+		machines, err := discover(src, refresh)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		added := 0
+		for _, m := range machines {
+			if len(m.Addrs) == 0 || hostName(src, m) == src.Prefix {
+				continue
+			}
+			if cfg.AddDiscoveredHost(hostName(src, m), src.Provider, hostFor(src, m)) {
+				added++
+			}
+		}
+		logger.Verbosef(logger.LevelPhases, "inventory", "%s found %d hosts, added %d", src.Provider, len(machines), added)
+	}
+	return errs
+}
+
+// discover returns src's machines, from the cache while it's fresh.
+func discover(src config.InventoryConfig, refresh bool) ([]Machine, error) {
+	ttl := src.CacheTTL
+	if ttl == 0 {
+		ttl = config.DefaultInventoryCacheTTL
+	}
+	cached, at, ok := readCache(src)
+	if ok && !refresh && time.Since(at) < ttl {
+		return cached, nil
+	}
+
+	provider, found := providers[src.Provider]
+	if !found {
+		return nil, fmt.Errorf("unknown inventory provider '%s'", src.Provider)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), discoverTimeout)
+	defer cancel()
+	machines, err := provider.Discover(ctx, src)
+	if err != nil {
+		err = fmt.Errorf("couldn't list %s hosts: %w", src.Provider, err)
+		if ok {
+			return cached, fmt.Errorf("%w (using the hosts found %s ago)", err, time.Since(at).Round(time.Second))
+		}
+		return nil, err
+	}
+	writeCache(src, machines)
+	return machines, nil
+}
+
+// hostFor builds the host config for a discovered machine.
+func hostFor(src config.InventoryConfig, m Machine) config.Host {
+	ssh := make([]string, 0, len(m.Addrs))
+	for _, addr := range m.Addrs {
+		if src.User != "" {
+			addr = src.User + "@" + addr
+		}
+		ssh = append(ssh, addr)
+	}
+	return config.Host{
+		SSH:  ssh,
+		Dir:  src.Dir,
+		Tags: append([]string(nil), src.Tags...),
+	}
+}
+
+// unsafeNameChars are replaced in discovered names, which end up in lock
+// and log file names.
+var unsafeNameChars = regexp.MustCompile(`[^a-z0-9_.-]+`)
+
+// hostName returns the rr host name for a discovered machine.
+func hostName(src config.InventoryConfig, m Machine) string {
+	name := unsafeNameChars.ReplaceAllString(strings.ToLower(m.Name), "-")
+	return src.Prefix + strings.Trim(name, "-")
+}
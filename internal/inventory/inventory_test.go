@@ -0,0 +1,155 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCLI replaces the provider CLIs for the rest of the test. Each call is
+// recorded as "name arg arg..." and answered by respond.
+func fakeCLI(t *testing.T, respond func(call string) (string, error)) *[]string {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	var calls []string
+	orig := runCommand
+	runCommand = func(_ context.Context, name string, args ...string) ([]byte, error) {
+		call := name + " " + strings.Join(args, " ")
+		calls = append(calls, call)
+		out, err := respond(call)
+		return []byte(out), err
+	}
+	t.Cleanup(func() { runCommand = orig })
+	return &calls
+}
+
+const tailscaleStatusJSON = `{
+  "Self": {"HostName": "laptop", "Online": true},
+  "Peer": {
+    "nodekey:1": {"HostName": "A100-Box", "DNSName": "a100-box.tailnet.ts.net.", "TailscaleIPs": ["fd7a:115c::1", "100.64.0.1"], "Online": true, "Tags": ["tag:gpu"]},
+    "nodekey:2": {"HostName": "mini", "DNSName": "mini.tailnet.ts.net.", "TailscaleIPs": ["100.64.0.2"], "Online": true},
+    "nodekey:3": {"HostName": "h100", "DNSName": "h100.tailnet.ts.net.", "TailscaleIPs": ["100.64.0.3"], "Online": false, "Tags": ["tag:gpu"]}
+  }
+}`
+
+func TestTailscale_Discover(t *testing.T) {
+	fakeCLI(t, func(string) (string, error) { return tailscaleStatusJSON, nil })
+
+	machines, err := tailscale{}.Discover(context.Background(), config.InventoryConfig{Tag: "gpu"})
+	require.NoError(t, err)
+	assert.Equal(t, []Machine{{Name: "A100-Box", Addrs: []string{"a100-box.tailnet.ts.net", "100.64.0.1"}}}, machines,
+		"only online peers with the tag")
+
+	machines, err = tailscale{}.Discover(context.Background(), config.InventoryConfig{})
+	require.NoError(t, err)
+	assert.Len(t, machines, 2)
+}
+
+func TestAWS_Discover(t *testing.T) {
+	calls := fakeCLI(t, func(string) (string, error) {
+		return `{"Reservations": [{"Instances": [
+			{"InstanceId": "i-1", "PublicIpAddress": "54.1.1.1", "PrivateIpAddress": "10.0.0.1", "Tags": [{"Key": "Name", "Value": "trainer"}]},
+			{"InstanceId": "i-2", "PrivateIpAddress": "10.0.0.2", "Tags": [{"Key": "Name", "Value": "trainer"}]},
+			{"InstanceId": "i-3", "PrivateIpAddress": "10.0.0.3"}
+		]}]}`, nil
+	})
+
+	machines, err := aws{}.Discover(context.Background(), config.InventoryConfig{
+		Region:  "us-west-2",
+		Filters: map[string]string{"team": "ml", "env": "dev"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aws ec2 describe-instances --output json --filters Name=instance-state-name,Values=running " +
+		"Name=tag:env,Values=dev Name=tag:team,Values=ml --region us-west-2"}, *calls)
+	assert.Equal(t, []Machine{
+		{Name: "i-3", Addrs: []string{"10.0.0.3"}},
+		{Name: "trainer-i-1", Addrs: []string{"54.1.1.1", "10.0.0.1"}},
+		{Name: "trainer-i-2", Addrs: []string{"10.0.0.2"}},
+	}, machines)
+}
+
+func TestGCP_Discover(t *testing.T) {
+	calls := fakeCLI(t, func(string) (string, error) {
+		return `[{"name": "runner-1", "networkInterfaces": [{"networkIP": "10.1.0.5", "accessConfigs": [{"natIP": "35.2.2.2"}]}]}]`, nil
+	})
+
+	machines, err := gcp{}.Discover(context.Background(), config.InventoryConfig{
+		Project: "ci",
+		Filters: map[string]string{"rr": "true"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gcloud compute instances list --format=json --filter=status=RUNNING AND labels.rr=true --project ci"}, *calls)
+	assert.Equal(t, []Machine{{Name: "runner-1", Addrs: []string{"35.2.2.2", "10.1.0.5"}}}, machines)
+}
+
+func TestMerge(t *testing.T) {
+	calls := fakeCLI(t, func(string) (string, error) { return tailscaleStatusJSON, nil })
+	src := config.InventoryConfig{Provider: config.InventoryTailscale, Prefix: "ts-", User: "me", Dir: "~/rr", Tags: []string{"tailnet"}}
+	newConfig := func() *config.GlobalConfig {
+		return &config.GlobalConfig{
+			Hosts:     map[string]config.Host{"ts-mini": {SSH: []string{"mini-lan"}, Dir: "~/code"}},
+			Inventory: []config.InventoryConfig{src},
+		}
+	}
+
+	cfg := newConfig()
+	assert.Empty(t, Merge(cfg, false))
+	assert.Equal(t, config.Host{
+		SSH:  []string{"me@a100-box.tailnet.ts.net", "me@100.64.0.1"},
+		Dir:  "~/rr",
+		Tags: []string{"tailnet"},
+	}, cfg.Hosts["ts-a100-box"])
+	assert.Equal(t, []string{"mini-lan"}, cfg.Hosts["ts-mini"].SSH, "declared hosts win")
+	assert.Equal(t, config.InventoryTailscale, cfg.DiscoveredBy("ts-a100-box"))
+
+	// A second load within the TTL is served from the cache
+	cfg = newConfig()
+	assert.Empty(t, Merge(cfg, false))
+	assert.Contains(t, cfg.Hosts, "ts-a100-box")
+	assert.Len(t, *calls, 1)
+
+	// Refreshing asks again
+	Merge(newConfig(), true)
+	assert.Len(t, *calls, 2)
+}
+
+func TestMerge_ProviderFails(t *testing.T) {
+	fail := false
+	fakeCLI(t, func(string) (string, error) {
+		if fail {
+			return "", errors.New("tailscale: exit status 1: not logged in")
+		}
+		return tailscaleStatusJSON, nil
+	})
+	src := config.InventoryConfig{Provider: config.InventoryTailscale, Dir: "~/rr", CacheTTL: time.Nanosecond}
+
+	// No cache yet: the source adds nothing
+	fail = true
+	cfg := &config.GlobalConfig{Inventory: []config.InventoryConfig{src}}
+	errs := Merge(cfg, false)
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "not logged in")
+	assert.Empty(t, cfg.Hosts)
+
+	// With a stale cache, the last hosts found are used
+	fail = false
+	Merge(&config.GlobalConfig{Inventory: []config.InventoryConfig{src}}, false)
+	fail = true
+	cfg = &config.GlobalConfig{Inventory: []config.InventoryConfig{src}}
+	errs = Merge(cfg, false)
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "using the hosts found")
+	assert.Contains(t, cfg.Hosts, "a100-box")
+}
+
+func TestHostName(t *testing.T) {
+	src := config.InventoryConfig{Prefix: "aws-"}
+	assert.Equal(t, "aws-gpu-trainer-2", hostName(src, Machine{Name: "GPU Trainer #2"}))
+	assert.Equal(t, "mini", hostName(config.InventoryConfig{}, Machine{Name: "mini"}))
+}
@@ -0,0 +1,198 @@
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/rileyhilliard/rr/internal/config"
+)
+
+// tailscale lists online peers on the tailnet from `tailscale status`.
+type tailscale struct{}
+
+// tailscaleStatus is the part of `tailscale status --json` rr reads.
+type tailscaleStatus struct {
+	Peer map[string]struct {
+		HostName     string   `json:"HostName"`
+		DNSName      string   `json:"DNSName"`
+		TailscaleIPs []string `json:"TailscaleIPs"`
+		Online       bool     `json:"Online"`
+		Tags         []string `json:"Tags"`
+	} `json:"Peer"`
+}
+
+func (tailscale) Discover(ctx context.Context, src config.InventoryConfig) ([]Machine, error) {
+	out, err := runCommand(ctx, "tailscale", "status", "--json")
+	if err != nil {
+		return nil, err
+	}
+	var status tailscaleStatus
+	if err := json.Unmarshal(out, &status); err != nil {
+		return nil, fmt.Errorf("unexpected tailscale status output: %w", err)
+	}
+
+	tag := src.Tag
+	if tag != "" && !strings.HasPrefix(tag, "tag:") {
+		tag = "tag:" + tag
+	}
+	var machines []Machine
+	for _, peer := range status.Peer {
+		if !peer.Online || (tag != "" && !slices.Contains(peer.Tags, tag)) {
+			continue
+		}
+		var addrs []string
+		if dns := strings.TrimSuffix(peer.DNSName, "."); dns != "" {
+			addrs = append(addrs, dns)
+		}
+		for _, ip := range peer.TailscaleIPs {
+			// The IPv4 address works everywhere; the IPv6 one adds nothing
+			if parsed := net.ParseIP(ip); parsed != nil && parsed.To4() != nil {
+				addrs = append(addrs, ip)
+				break
+			}
+		}
+		machines = append(machines, Machine{Name: peer.HostName, Addrs: addrs})
+	}
+	return sortMachines(machines), nil
+}
+
+// aws lists running EC2 instances with `aws ec2 describe-instances`.
+type aws struct{}
+
+// awsInstances is the part of `aws ec2 describe-instances` rr reads.
+type awsInstances struct {
+	Reservations []struct {
+		Instances []struct {
+			InstanceID       string `json:"InstanceId"`
+			PublicIPAddress  string `json:"PublicIpAddress"`
+			PrivateIPAddress string `json:"PrivateIpAddress"`
+			Tags             []struct {
+				Key   string `json:"Key"`
+				Value string `json:"Value"`
+			} `json:"Tags"`
+		} `json:"Instances"`
+	} `json:"Reservations"`
+}
+
+func (aws) Discover(ctx context.Context, src config.InventoryConfig) ([]Machine, error) {
+	args := []string{"ec2", "describe-instances", "--output", "json",
+		"--filters", "Name=instance-state-name,Values=running"}
+	for _, key := range sortedKeys(src.Filters) {
+		args = append(args, fmt.Sprintf("Name=tag:%s,Values=%s", key, src.Filters[key]))
+	}
+	if src.Region != "" {
+		args = append(args, "--region", src.Region)
+	}
+	out, err := runCommand(ctx, "aws", args...)
+	if err != nil {
+		return nil, err
+	}
+	var result awsInstances
+	if err := json.Unmarshal(out, &result); err != nil {
+		return nil, fmt.Errorf("unexpected aws ec2 describe-instances output: %w", err)
+	}
+
+	var machines []Machine
+	ids := make(map[int]string)
+	named := make(map[string]int)
+	for _, reservation := range result.Reservations {
+		for _, inst := range reservation.Instances {
+			name := inst.InstanceID
+			for _, tag := range inst.Tags {
+				if tag.Key == "Name" && tag.Value != "" {
+					name = tag.Value
+				}
+			}
+			ids[len(machines)] = inst.InstanceID
+			named[name]++
+			machines = append(machines, Machine{Name: name, Addrs: nonEmpty(inst.PublicIPAddress, inst.PrivateIPAddress)})
+		}
+	}
+	// Instances from one launch template share a Name tag; tell them apart
+	for i := range machines {
+		if named[machines[i].Name] > 1 {
+			machines[i].Name += "-" + ids[i]
+		}
+	}
+	return sortMachines(machines), nil
+}
+
+// gcp lists running Compute Engine instances with `gcloud compute instances list`.
+type gcp struct{}
+
+// gcpInstance is the part of `gcloud compute instances list` rr reads.
+type gcpInstance struct {
+	Name              string `json:"name"`
+	NetworkInterfaces []struct {
+		NetworkIP     string `json:"networkIP"`
+		AccessConfigs []struct {
+			NatIP string `json:"natIP"`
+		} `json:"accessConfigs"`
+	} `json:"networkInterfaces"`
+}
+
+func (gcp) Discover(ctx context.Context, src config.InventoryConfig) ([]Machine, error) {
+	filter := []string{"status=RUNNING"}
+	for _, key := range sortedKeys(src.Filters) {
+		filter = append(filter, fmt.Sprintf("labels.%s=%s", key, src.Filters[key]))
+	}
+	args := []string{"compute", "instances", "list", "--format=json", "--filter=" + strings.Join(filter, " AND ")}
+	if src.Project != "" {
+		args = append(args, "--project", src.Project)
+	}
+	out, err := runCommand(ctx, "gcloud", args...)
+	if err != nil {
+		return nil, err
+	}
+	var instances []gcpInstance
+	if err := json.Unmarshal(out, &instances); err != nil {
+		return nil, fmt.Errorf("unexpected gcloud compute instances list output: %w", err)
+	}
+
+	machines := make([]Machine, 0, len(instances))
+	for _, inst := range instances {
+		var public, private []string
+		for _, iface := range inst.NetworkInterfaces {
+			for _, access := range iface.AccessConfigs {
+				public = append(public, access.NatIP)
+			}
+			private = append(private, iface.NetworkIP)
+		}
+		machines = append(machines, Machine{Name: inst.Name, Addrs: nonEmpty(append(public, private...)...)})
+	}
+	return sortMachines(machines), nil
+}
+
+// nonEmpty returns values without the empty ones.
+func nonEmpty(values ...string) []string {
+	var kept []string
+	for _, v := range values {
+		if v != "" {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+// sortedKeys returns m's keys in order, so provider commands are the same
+// from run to run.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortMachines orders machines by name, since providers list them in no
+// particular order.
+func sortMachines(machines []Machine) []Machine {
+	sort.Slice(machines, func(i, j int) bool { return machines[i].Name < machines[j].Name })
+	return machines
+}
@@ -5,3 +5,7 @@ import "errors"
 // ErrLocked is returned by TryAcquire when the lock is held by another process.
 // This is a sentinel error that can be checked with errors.Is().
 var ErrLocked = errors.New("lock is held by another process")
+
+// ErrNoCapacity is returned by Reserve when the host's reservations leave too
+// little room for the requested one.
+var ErrNoCapacity = errors.New("host doesn't have enough unreserved capacity")
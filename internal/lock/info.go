@@ -3,9 +3,17 @@ package lock
 import (
 	"encoding/json"
 	"os"
+	"strings"
 	"time"
+	"unicode"
+
+	"github.com/rileyhilliard/rr/internal/config"
 )
 
+// activityCommandLimit caps how much of the command Activity shows, so
+// badges stay on one line.
+const activityCommandLimit = 40
+
 // LockInfo contains metadata about who holds a lock.
 type LockInfo struct {
 	User     string    `json:"user"`
@@ -13,9 +21,11 @@ type LockInfo struct {
 	Started  time.Time `json:"started"`
 	PID      int       `json:"pid"`
 	Command  string    `json:"command,omitempty"`
+	Project  string    `json:"project,omitempty"`
 }
 
-// NewLockInfo creates a LockInfo with the current user, hostname, time, PID, and command.
+// NewLockInfo creates a LockInfo with the current user, hostname, time, PID,
+// command, and project.
 func NewLockInfo(command string) (*LockInfo, error) {
 	hostname, err := os.Hostname()
 	if err != nil {
@@ -33,6 +43,7 @@ func NewLockInfo(command string) (*LockInfo, error) {
 		Started:  time.Now(),
 		PID:      os.Getpid(),
 		Command:  command,
+		Project:  config.ProjectName(),
 	}, nil
 }
 
@@ -60,6 +71,68 @@ func (i *LockInfo) String() string {
 	return i.User + "@" + i.Hostname + " (pid " + itoa(i.PID) + ")"
 }
 
+// Activity describes what the lock holder is doing, for busy badges:
+// "alice is running pytest on project app". Locks taken by older versions of
+// rr have no project, so theirs leaves it out.
+func (i *LockInfo) Activity() string {
+	user := i.User
+	if user == "" {
+		user = "someone"
+	}
+	text := user + " holds the lock"
+	if cmd := strings.Join(strings.Fields(i.Command), " "); cmd != "" {
+		if r := []rune(cmd); len(r) > activityCommandLimit {
+			cmd = string(r[:activityCommandLimit-3]) + "..."
+		}
+		text = user + " is running " + cmd
+	}
+	if i.Project != "" {
+		text += " on project " + i.Project
+	}
+	return text
+}
+
+// Badge is Activity with how long the lock has been held:
+// "alice is running pytest on project app, 12m elapsed".
+func (i *LockInfo) Badge() string {
+	return i.Activity() + ", " + FormatElapsed(i.Age()) + " elapsed"
+}
+
+// FormatElapsed formats how long a lock has been held, to the second under a
+// minute and to the minute after: "40s", "12m", "1h5m".
+func FormatElapsed(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return itoa(int(max(d, 0)/time.Second)) + "s"
+	case d < time.Hour:
+		return itoa(int(d/time.Minute)) + "m"
+	}
+	text := itoa(int(d/time.Hour)) + "h"
+	if m := int(d%time.Hour) / int(time.Minute); m > 0 {
+		text += itoa(m) + "m"
+	}
+	return text
+}
+
+// Initials returns up to two letters standing for a user, for avatars:
+// "alice" is "A", "alice.smith" and "alice_smith" are "AS".
+func Initials(user string) string {
+	parts := strings.FieldsFunc(user, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var initials []rune
+	for _, part := range parts {
+		if len(initials) == 2 {
+			break
+		}
+		initials = append(initials, unicode.ToUpper([]rune(part)[0]))
+	}
+	if len(initials) == 0 {
+		return "?"
+	}
+	return string(initials)
+}
+
 // itoa is a simple int-to-string without importing strconv.
 func itoa(n int) string {
 	if n == 0 {
@@ -1,8 +1,11 @@
 package lock
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -20,8 +23,17 @@ import (
 type AcquireOption func(*acquireOptions)
 
 type acquireOptions struct {
-	logger   logger.Logger
-	warnFunc func(msg string)
+	logger     logger.Logger
+	warnFunc   func(msg string)
+	waitFunc   func(w Wait)
+	commandKey string
+}
+
+// Wait is what Acquire knows each time it finds the lock held.
+type Wait struct {
+	Holder     *LockInfo     // Who holds the lock (nil if that can't be read)
+	LastOutput string        // Last line of the holder's output, if it shares it
+	Remaining  time.Duration // Until Acquire gives up
 }
 
 // WithLogger sets the logger for lock operations.
@@ -40,6 +52,23 @@ func WithWarnFunc(fn func(msg string)) AcquireOption {
 	}
 }
 
+// WithWaitFunc sets a callback for each time Acquire finds the lock held and
+// waits, with who holds it and how long Acquire will keep waiting, so
+// callers can show who they're waiting on and for how long.
+func WithWaitFunc(fn func(w Wait)) AcquireOption {
+	return func(o *acquireOptions) {
+		o.waitFunc = fn
+	}
+}
+
+// WithCommandKey sets what a command-scoped lock is keyed on, for callers
+// whose lock covers more than the command they record in the lock info.
+func WithCommandKey(key string) AcquireOption {
+	return func(o *acquireOptions) {
+		o.commandKey = key
+	}
+}
+
 // defaultLogger returns a logger for lock operations.
 // Uses the environment-based logger with [lock] prefix.
 var defaultLogger = logger.NewEnvLogger("[lock]")
@@ -56,9 +85,15 @@ type Lock struct {
 	Info *LockInfo // Info about the lock holder (us)
 	conn *host.Connection
 
+	acquired time.Time // When the lock was taken, for the usage log
+
 	heartbeatStop chan struct{}
 	heartbeatDone chan struct{}
 	heartbeatMu   sync.Mutex
+
+	output     *lastLine // The command's output, shared with waiters (see OutputWriter)
+	outputStop chan struct{}
+	outputDone chan struct{}
 }
 
 // Acquire attempts to acquire a distributed lock on the remote host.
@@ -66,14 +101,17 @@ type Lock struct {
 // If the lock is held, it will wait and retry until timeout.
 // Stale locks (older than config.Stale) are automatically removed.
 //
-// The lock is per-host, not per-project. Only one rr task can run on a host
-// at a time, regardless of which project initiated it. This prevents resource
-// contention since rr tasks typically consume significant CPU/memory.
+// By default the lock is per-host, not per-project. Only one rr task can run
+// on a host at a time, regardless of which project initiated it. This prevents
+// resource contention since rr tasks typically consume significant CPU/memory.
+// lock.scope narrows it to the project or the command (see Dir).
 //
-// The command parameter is stored in the lock info for monitoring purposes.
+// The command parameter is stored in the lock info for monitoring purposes,
+// and keys a command-scoped lock.
 //
 // Options can be passed to configure behavior:
 //   - WithLogger(l): Use a custom logger instead of the default
+//   - WithCommandKey(key): Key a command-scoped lock on key instead of command
 func Acquire(conn *host.Connection, cfg config.LockConfig, command string, opts ...AcquireOption) (*Lock, error) {
 	// Apply options
 	options := &acquireOptions{
@@ -87,14 +125,14 @@ func Acquire(conn *host.Connection, cfg config.LockConfig, command string, opts
 	if err := host.ValidateConnectionForLock(conn); err != nil {
 		return nil, err
 	}
+	client := lockClient(conn)
 
-	// Build lock directory path: /tmp/rr.lock/
-	// Single lock per host - only one rr task can run at a time
+	// Build lock directory path, e.g. /tmp/rr-locks/rr.lock/ for a host lock
 	baseDir := cfg.Dir
 	if baseDir == "" {
 		baseDir = "/tmp"
 	}
-	lockDir := filepath.Join(baseDir, "rr.lock")
+	lockDir := Dir(cfg, conn, options.lockKey(command))
 	infoFile := filepath.Join(lockDir, "info.json")
 
 	log.Debug("attempting to acquire lock: dir=%s, timeout=%s, stale=%s", lockDir, cfg.Timeout, cfg.Stale)
@@ -113,7 +151,7 @@ func Acquire(conn *host.Connection, cfg config.LockConfig, command string, opts
 	if baseDir != "/tmp" {
 		mkdirParentCmd := fmt.Sprintf("mkdir -p %q", baseDir)
 		log.Debug("ensuring parent directory exists: %s", mkdirParentCmd)
-		_, stderr, exitCode, err := conn.Client.Exec(mkdirParentCmd)
+		_, stderr, exitCode, err := client.Exec(mkdirParentCmd)
 		if err != nil {
 			return nil, errors.WrapWithCode(err, errors.ErrLock,
 				"Couldn't create the lock directory",
@@ -135,19 +173,20 @@ func Acquire(conn *host.Connection, cfg config.LockConfig, command string, opts
 		elapsed := time.Since(startTime)
 		if elapsed > cfg.Timeout {
 			// Try to read who holds the lock for a better error message
-			holder := readLockHolder(conn.Client, infoFile)
+			holder := readLockHolder(client, infoFile)
 			log.Debug("timeout after %d iterations, elapsed=%s, holder=%s", iteration, elapsed, holder)
 			return nil, errors.New(errors.ErrLock,
 				fmt.Sprintf("Lock timeout after %s - someone else is using this remote", cfg.Timeout),
-				fmt.Sprintf("Held by: %s. Wait for them to finish or use --force-unlock if it's stale.", holder))
+				fmt.Sprintf("Held by: %s. Wait for them to finish or use --force-unlock if it's stale.", holder)).
+				WithID(errors.IDLockTimeout)
 		}
 
 		// Check for stale lock
-		if isLockStale(conn.Client, infoFile, cfg.Stale) {
+		if isLockStale(client, infoFile, cfg.Stale) {
 			log.Debug("detected stale lock, attempting removal")
-			holder := readLockHolder(conn.Client, infoFile)
+			holder := readLockHolder(client, infoFile)
 			// Remove stale lock
-			if err := forceRemove(conn.Client, lockDir); err == nil {
+			if err := forceRemove(client, lockDir); err == nil {
 				log.Warn("stale lock on %s stolen (holder: %s)", conn.Name, holder)
 				msg := fmt.Sprintf("Warning: stealing stale lock on %s (holder: %s)", conn.Name, holder)
 				if options.warnFunc != nil {
@@ -165,7 +204,7 @@ func Acquire(conn *host.Connection, cfg config.LockConfig, command string, opts
 		// mkdir will fail if the directory already exists
 		mkdirCmd := fmt.Sprintf("mkdir %q", lockDir)
 		log.Debug("iteration %d: executing mkdir command: %s", iteration, mkdirCmd)
-		stdout, stderr, exitCode, err := conn.Client.Exec(mkdirCmd)
+		stdout, stderr, exitCode, err := client.Exec(mkdirCmd)
 		log.Debug("mkdir result: exitCode=%d, stdout=%q, stderr=%q, err=%v", exitCode, string(stdout), string(stderr), err)
 
 		if err != nil {
@@ -180,7 +219,7 @@ func Acquire(conn *host.Connection, cfg config.LockConfig, command string, opts
 			infoJSON, err := info.Marshal()
 			if err != nil {
 				// Clean up the lock dir if we can't write info
-				forceRemove(conn.Client, lockDir)
+				forceRemove(client, lockDir)
 				return nil, errors.WrapWithCode(err, errors.ErrLock,
 					"Couldn't serialize lock info",
 					"This is unexpected - please report this bug!")
@@ -188,25 +227,38 @@ func Acquire(conn *host.Connection, cfg config.LockConfig, command string, opts
 
 			// Write the info file
 			writeCmd := fmt.Sprintf("cat > %q << 'LOCKINFO'\n%s\nLOCKINFO", infoFile, string(infoJSON))
-			_, _, exitCode, err = conn.Client.Exec(writeCmd)
+			_, _, exitCode, err = client.Exec(writeCmd)
 			if err != nil || exitCode != 0 {
 				// Clean up and report error
-				forceRemove(conn.Client, lockDir)
+				forceRemove(client, lockDir)
 				return nil, errors.New(errors.ErrLock,
 					"Couldn't write the lock info file",
 					"Check disk space and permissions on the remote.")
 			}
 
 			log.Debug("lock acquired successfully: %s", lockDir)
+			logger.Verbosef(logger.LevelPhases, "lock", "acquired %s on %s after %s", lockDir, conn.Name, time.Since(startTime).Round(time.Millisecond))
 			return &Lock{
-				Dir:  lockDir,
-				Info: info,
-				conn: conn,
+				Dir:      lockDir,
+				Info:     info,
+				conn:     conn,
+				acquired: time.Now(),
 			}, nil
 		}
 
 		// Lock is held by someone else, wait before retrying
 		log.Debug("mkdir failed (exitCode=%d), lock may be held by another process, waiting 2s before retry", exitCode)
+		if iteration == 1 && logger.V(logger.LevelPhases) {
+			logger.Verbosef(logger.LevelPhases, "lock", "%s on %s is held by %s, waiting up to %s", lockDir, conn.Name, readLockHolder(client, infoFile), cfg.Timeout)
+		}
+		if options.waitFunc != nil {
+			holder, _ := readLockInfo(client, infoFile)
+			options.waitFunc(Wait{
+				Holder:     holder,
+				LastOutput: readOutput(client, lockDir),
+				Remaining:  max(cfg.Timeout-time.Since(startTime), 0),
+			})
+		}
 		time.Sleep(2 * time.Second)
 	}
 }
@@ -219,7 +271,8 @@ func Acquire(conn *host.Connection, cfg config.LockConfig, command string, opts
 //   - (nil, ErrLocked) if the lock is held by another process
 //   - (nil, other error) for SSH/permission issues
 //
-// The command parameter is stored in the lock info for monitoring purposes.
+// The command parameter is stored in the lock info for monitoring purposes,
+// and keys a command-scoped lock.
 //
 // This is useful for load-balancing across multiple hosts - if one host is locked,
 // the caller can immediately try the next host instead of waiting.
@@ -236,14 +289,14 @@ func TryAcquire(conn *host.Connection, cfg config.LockConfig, command string, op
 	if err := host.ValidateConnectionForLock(conn); err != nil {
 		return nil, err
 	}
+	client := lockClient(conn)
 
-	// Build lock directory path: /tmp/rr.lock/
-	// Single lock per host - only one rr task can run at a time
+	// Build lock directory path, e.g. /tmp/rr-locks/rr.lock/ for a host lock
 	baseDir := cfg.Dir
 	if baseDir == "" {
 		baseDir = "/tmp"
 	}
-	lockDir := filepath.Join(baseDir, "rr.lock")
+	lockDir := Dir(cfg, conn, options.lockKey(command))
 	infoFile := filepath.Join(lockDir, "info.json")
 
 	log.Debug("TryAcquire: attempting lock: dir=%s", lockDir)
@@ -260,7 +313,7 @@ func TryAcquire(conn *host.Connection, cfg config.LockConfig, command string, op
 	if baseDir != "/tmp" {
 		mkdirParentCmd := fmt.Sprintf("mkdir -p %q", baseDir)
 		log.Debug("TryAcquire: ensuring parent directory exists: %s", mkdirParentCmd)
-		_, stderr, exitCode, err := conn.Client.Exec(mkdirParentCmd)
+		_, stderr, exitCode, err := client.Exec(mkdirParentCmd)
 		if err != nil {
 			return nil, errors.WrapWithCode(err, errors.ErrLock,
 				"Couldn't create the lock directory",
@@ -274,9 +327,9 @@ func TryAcquire(conn *host.Connection, cfg config.LockConfig, command string, op
 	}
 
 	// Check for stale lock and remove it first
-	if isLockStale(conn.Client, infoFile, cfg.Stale) {
+	if isLockStale(client, infoFile, cfg.Stale) {
 		log.Debug("TryAcquire: detected stale lock, attempting removal")
-		if err := forceRemove(conn.Client, lockDir); err != nil {
+		if err := forceRemove(client, lockDir); err != nil {
 			log.Debug("TryAcquire: failed to remove stale lock: %v", err)
 			// Continue anyway - maybe we can still acquire
 		} else {
@@ -288,7 +341,7 @@ func TryAcquire(conn *host.Connection, cfg config.LockConfig, command string, op
 	// mkdir will fail if the directory already exists
 	mkdirCmd := fmt.Sprintf("mkdir %q", lockDir)
 	log.Debug("TryAcquire: executing mkdir command: %s", mkdirCmd)
-	_, _, exitCode, err := conn.Client.Exec(mkdirCmd)
+	_, _, exitCode, err := client.Exec(mkdirCmd)
 
 	if err != nil {
 		return nil, errors.WrapWithCode(err, errors.ErrLock,
@@ -308,7 +361,7 @@ func TryAcquire(conn *host.Connection, cfg config.LockConfig, command string, op
 	infoJSON, err := info.Marshal()
 	if err != nil {
 		// Clean up the lock dir if we can't write info
-		forceRemove(conn.Client, lockDir)
+		forceRemove(client, lockDir)
 		return nil, errors.WrapWithCode(err, errors.ErrLock,
 			"Couldn't serialize lock info",
 			"This is unexpected - please report this bug!")
@@ -316,10 +369,10 @@ func TryAcquire(conn *host.Connection, cfg config.LockConfig, command string, op
 
 	// Write the info file
 	writeCmd := fmt.Sprintf("cat > %q << 'LOCKINFO'\n%s\nLOCKINFO", infoFile, string(infoJSON))
-	_, writeStderr, writeExitCode, writeErr := conn.Client.Exec(writeCmd)
+	_, writeStderr, writeExitCode, writeErr := client.Exec(writeCmd)
 	if writeErr != nil || writeExitCode != 0 {
 		// Clean up and report error
-		forceRemove(conn.Client, lockDir)
+		forceRemove(client, lockDir)
 		return nil, errors.New(errors.ErrLock,
 			"Couldn't write the lock info file",
 			fmt.Sprintf("Check disk space and permissions on the remote. Error: %s", strings.TrimSpace(string(writeStderr))))
@@ -327,57 +380,72 @@ func TryAcquire(conn *host.Connection, cfg config.LockConfig, command string, op
 
 	log.Debug("TryAcquire: lock acquired successfully: %s", lockDir)
 	return &Lock{
-		Dir:  lockDir,
-		Info: info,
-		conn: conn,
+		Dir:      lockDir,
+		Info:     info,
+		conn:     conn,
+		acquired: time.Now(),
 	}, nil
 }
 
-// IsLocked checks if a lock is currently held without trying to acquire it.
-// Returns true if the lock exists (and is not stale), false otherwise.
-func IsLocked(conn *host.Connection, cfg config.LockConfig) bool {
-	if err := host.ValidateConnectionForLock(conn); err != nil {
-		return false
-	}
+// IsLocked checks if the lock command would take is currently held, without
+// trying to acquire it. Returns true if the lock exists (and is not stale),
+// false otherwise.
+func IsLocked(conn *host.Connection, cfg config.LockConfig, command string) bool {
+	held, _ := CheckHeld(conn, Dir(cfg, conn, command), cfg.Stale)
+	return held
+}
 
-	// Build lock directory path
-	baseDir := cfg.Dir
-	if baseDir == "" {
-		baseDir = "/tmp"
+// CheckHeld checks if the lock at lockDir is held and hasn't gone stale, for
+// callers that know the lock's directory rather than the command taking it.
+// Unlike IsLocked, it tells "not held" apart from not being able to check.
+func CheckHeld(conn *host.Connection, lockDir string, stale time.Duration) (bool, error) {
+	if err := host.ValidateConnectionForLock(conn); err != nil {
+		return false, err
 	}
-	lockDir := filepath.Join(baseDir, "rr.lock")
+	client := lockClient(conn)
 	infoFile := filepath.Join(lockDir, "info.json")
 
 	// Check if lock directory exists
 	testCmd := fmt.Sprintf("test -d %q", lockDir)
-	_, _, exitCode, err := conn.Client.Exec(testCmd)
-	if err != nil || exitCode != 0 {
-		return false // Directory doesn't exist or error checking
+	_, _, exitCode, err := client.Exec(testCmd)
+	if err != nil {
+		return false, err
+	}
+	if exitCode != 0 {
+		return false, nil // Directory doesn't exist
 	}
 
 	// Check if it's stale
-	if isLockStale(conn.Client, infoFile, cfg.Stale) {
-		return false // Stale locks don't count
+	if isLockStale(client, infoFile, stale) {
+		return false, nil // Stale locks don't count
 	}
 
-	return true
+	return true, nil
 }
 
-// GetLockHolder returns information about the current lock holder, if any.
-// Returns empty string if no lock is held.
-func GetLockHolder(conn *host.Connection, cfg config.LockConfig) string {
-	if !IsLocked(conn, cfg) {
+// GetLockHolder returns information about the holder of the lock command
+// would take, if any. Returns empty string if no lock is held.
+func GetLockHolder(conn *host.Connection, cfg config.LockConfig, command string) string {
+	if !IsLocked(conn, cfg, command) {
 		return ""
 	}
 
-	baseDir := cfg.Dir
-	if baseDir == "" {
-		baseDir = "/tmp"
-	}
-	lockDir := filepath.Join(baseDir, "rr.lock")
-	infoFile := filepath.Join(lockDir, "info.json")
+	infoFile := filepath.Join(Dir(cfg, conn, command), "info.json")
 
-	return readLockHolder(conn.Client, infoFile)
+	return readLockHolder(lockClient(conn), infoFile)
+}
+
+// GetLockInfo returns the info of whoever holds the lock command would take,
+// or nil if it isn't held or the info can't be read.
+func GetLockInfo(conn *host.Connection, cfg config.LockConfig, command string) *LockInfo {
+	if !IsLocked(conn, cfg, command) {
+		return nil
+	}
+	info, err := readLockInfo(lockClient(conn), filepath.Join(Dir(cfg, conn, command), "info.json"))
+	if err != nil {
+		return nil
+	}
+	return info
 }
 
 // StartHeartbeat spawns a goroutine that touches the info.json file every 30
@@ -398,36 +466,7 @@ func (l *Lock) StartHeartbeat() {
 
 	l.heartbeatStop = make(chan struct{})
 	l.heartbeatDone = make(chan struct{})
-	stopCh := l.heartbeatStop
-	doneCh := l.heartbeatDone
-	infoFile := filepath.Join(l.Dir, "info.json")
-
-	go func() {
-		defer close(doneCh)
-		ticker := time.NewTicker(30 * time.Second)
-		defer ticker.Stop()
-
-		consecutiveFailures := 0
-		for {
-			select {
-			case <-stopCh:
-				return
-			case <-ticker.C:
-				touchCmd := fmt.Sprintf("touch %q", infoFile)
-				_, _, _, err := l.conn.Client.Exec(touchCmd)
-				if err != nil {
-					consecutiveFailures++
-					debugf("heartbeat touch failed (%d consecutive): %v", consecutiveFailures, err)
-					if consecutiveFailures >= 3 {
-						debugf("heartbeat stopping after %d consecutive failures", consecutiveFailures)
-						return
-					}
-				} else {
-					consecutiveFailures = 0
-				}
-			}
-		}
-	}()
+	go touchLoop(lockClient(l.conn), filepath.Join(l.Dir, "info.json"), l.heartbeatStop, l.heartbeatDone)
 }
 
 // StopHeartbeat stops the heartbeat goroutine. Idempotent and safe for
@@ -451,14 +490,22 @@ func (l *Lock) StopHeartbeat() {
 	<-done
 }
 
-// Release removes the lock, allowing others to acquire it.
+// Release removes the lock, allowing others to acquire it, then adds how
+// long it was held to the host's usage log. The lock is gone before the
+// usage is recorded, so a slow or failing write never holds up the next run.
 func (l *Lock) Release() error {
 	if l == nil || l.conn == nil || l.conn.Client == nil {
 		return nil // Nothing to release
 	}
 
 	l.StopHeartbeat()
-	return forceRemove(l.conn.Client, l.Dir)
+	l.stopSharingOutput()
+	held := l.heldFor()
+	if err := forceRemove(lockClient(l.conn), l.Dir); err != nil {
+		return err
+	}
+	l.recordUsage(held)
+	return nil
 }
 
 // UpdateCommand updates the command field in the lock info file.
@@ -479,7 +526,7 @@ func (l *Lock) UpdateCommand(command string) error {
 	}
 
 	writeCmd := fmt.Sprintf("cat > %q << 'LOCKINFO'\n%s\nLOCKINFO", infoFile, string(infoJSON))
-	_, _, exitCode, err := l.conn.Client.Exec(writeCmd)
+	_, _, exitCode, err := lockClient(l.conn).Exec(writeCmd)
 	if err != nil || exitCode != 0 {
 		return fmt.Errorf("failed to update lock info")
 	}
@@ -494,7 +541,31 @@ func ForceRelease(conn *host.Connection, lockDir string) error {
 		return err
 	}
 
-	return forceRemove(conn.Client, lockDir)
+	return forceRemove(lockClient(conn), lockDir)
+}
+
+// ReleaseAbandoned removes the lock at lockDir if process pid on this
+// machine took it, for a run that died before it could release it. A lock
+// anyone else holds now is left alone. Reports whether the lock was removed.
+func ReleaseAbandoned(conn *host.Connection, lockDir string, pid int) (bool, error) {
+	if err := host.ValidateConnectionForLock(conn); err != nil {
+		return false, err
+	}
+	client := lockClient(conn)
+
+	info, err := readLockInfo(client, filepath.Join(lockDir, "info.json"))
+	if err != nil {
+		// No lock (or nothing we can tell is ours) to release
+		return false, nil
+	}
+	hostname, _ := os.Hostname()
+	if info.PID != pid || info.Hostname != hostname {
+		return false, nil
+	}
+	if err := forceRemove(client, lockDir); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 // Holder returns information about who holds the lock (if readable).
@@ -503,11 +574,11 @@ func Holder(conn *host.Connection, lockDir string) string {
 		return "unknown (no connection)"
 	}
 	infoFile := filepath.Join(lockDir, "info.json")
-	return readLockHolder(conn.Client, infoFile)
+	return readLockHolder(lockClient(conn), infoFile)
 }
 
-// LockDir returns the lock directory path for a given config.
-// This is useful for unlock commands and monitoring.
+// LockDir returns the host-scoped lock directory path for a given config.
+// Project and command locks are named after it (see Dir).
 func LockDir(cfg config.LockConfig) string {
 	baseDir := cfg.Dir
 	if baseDir == "" {
@@ -516,6 +587,91 @@ func LockDir(cfg config.LockConfig) string {
 	return filepath.Join(baseDir, "rr.lock")
 }
 
+// Dir returns the lock directory command takes under cfg.Scope:
+//
+//	host:    <dir>/rr.lock
+//	project: <dir>/rr.lock.<project>
+//	command: <dir>/rr.lock.<project>.<command>
+//
+// <project> is the name of the project's remote directory followed by a hash
+// of its full path, since that directory is what two runs of one project
+// share. <command> is a hash of the command. Both stay single, shell-safe
+// path components.
+func Dir(cfg config.LockConfig, conn *host.Connection, command string) string {
+	switch cfg.Scope {
+	case config.LockScopeProject:
+		return LockDir(cfg) + "." + projectKey(conn)
+	case config.LockScopeCommand:
+		return LockDir(cfg) + "." + projectKey(conn) + "." + shortHash(command)
+	default:
+		return LockDir(cfg)
+	}
+}
+
+// Held returns the directories of the live locks in this project's part of
+// the lock namespace: the host lock, the project lock, or every command lock
+// of the project, depending on cfg.Scope. Stale locks are left out.
+func Held(conn *host.Connection, cfg config.LockConfig) []string {
+	if err := host.ValidateConnectionForLock(conn); err != nil {
+		return nil
+	}
+	client := lockClient(conn)
+
+	pattern := fmt.Sprintf("%q", Dir(cfg, conn, ""))
+	if cfg.Scope == config.LockScopeCommand {
+		pattern = fmt.Sprintf("%q.*", LockDir(cfg)+"."+projectKey(conn))
+	}
+	listCmd := fmt.Sprintf(`for d in %s; do if [ -d "$d" ]; then echo "$d"; fi; done`, pattern)
+	stdout, _, _, err := client.Exec(listCmd)
+	if err != nil {
+		return nil
+	}
+
+	var dirs []string
+	for _, dir := range strings.Split(string(stdout), "\n") {
+		dir = strings.TrimSpace(dir)
+		if dir == "" || isLockStale(client, filepath.Join(dir, "info.json"), cfg.Stale) {
+			continue
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// projectKey names a project in its lock directories.
+func projectKey(conn *host.Connection) string {
+	var dir string
+	if conn != nil {
+		dir = config.ExpandRemote(conn.Host.Dir)
+	}
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, path.Base(strings.TrimSuffix(dir, "/")))
+	if strings.Trim(name, "_") == "" {
+		name = "project"
+	}
+	return name + "-" + shortHash(dir)
+}
+
+// shortHash returns the first 8 hex characters of s's SHA-256.
+func shortHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:4])
+}
+
+// lockKey returns what a command-scoped lock is keyed on.
+func (o *acquireOptions) lockKey(command string) string {
+	if o.commandKey != "" {
+		return o.commandKey
+	}
+	return command
+}
+
 // isLockStale checks if the lock's info file is older than the stale threshold.
 //
 // Stale detection prevents orphaned locks from permanently blocking the remote.
@@ -588,6 +744,18 @@ func readLockHolder(client sshutil.SSHClient, infoFile string) string {
 	return info.String()
 }
 
+// readLockInfo reads and parses the lock info file.
+func readLockInfo(client sshutil.SSHClient, infoFile string) (*LockInfo, error) {
+	stdout, _, exitCode, err := client.Exec(fmt.Sprintf("cat %q 2>/dev/null", infoFile))
+	if err != nil {
+		return nil, err
+	}
+	if exitCode != 0 {
+		return nil, fmt.Errorf("can't read %s", infoFile)
+	}
+	return ParseLockInfo(stdout)
+}
+
 // forceRemove removes a directory and all its contents.
 func forceRemove(client sshutil.SSHClient, dir string) error {
 	rmCmd := fmt.Sprintf("rm -rf %q", dir)
@@ -606,3 +774,25 @@ func forceRemove(client sshutil.SSHClient, dir string) error {
 	}
 	return nil
 }
+
+// hostEnvClient runs lock commands in the host's default environment
+// (profile_files and env), so a lock dir like $TMPDIR/rr-locks resolves the
+// same way it does for the user's commands.
+type hostEnvClient struct {
+	sshutil.SSHClient
+	host *config.Host
+}
+
+// Exec implements sshutil.SSHClient.
+func (c hostEnvClient) Exec(cmd string) ([]byte, []byte, int, error) {
+	return c.SSHClient.Exec(config.WrapHostCommand(c.host, cmd))
+}
+
+// lockClient returns the client to run lock commands with, wrapped to apply
+// the host's default environment when it has one.
+func lockClient(conn *host.Connection) sshutil.SSHClient {
+	if len(conn.Host.Env) == 0 && len(conn.Host.ProfileFiles) == 0 {
+		return conn.Client
+	}
+	return hostEnvClient{SSHClient: conn.Client, host: &conn.Host}
+}
@@ -2,6 +2,8 @@ package lock
 
 import (
 	"encoding/json"
+	"os"
+	"regexp"
 	"strconv"
 	"strings"
 	"testing"
@@ -40,6 +42,50 @@ func TestLockInfo_NewLockInfo(t *testing.T) {
 	if time.Since(info.Started) > time.Second {
 		t.Error("Expected Started to be within the last second")
 	}
+
+	if info.Project != config.ProjectName() {
+		t.Errorf("Project = %q, want %q", info.Project, config.ProjectName())
+	}
+}
+
+func TestLockInfo_Activity(t *testing.T) {
+	tests := []struct {
+		name string
+		info LockInfo
+		want string
+	}{
+		{"full", LockInfo{User: "alice", Command: "pytest", Project: "app"}, "alice is running pytest on project app"},
+		{"no project", LockInfo{User: "alice", Command: "make   test"}, "alice is running make test"},
+		{"no command", LockInfo{User: "bob", Project: "app"}, "bob holds the lock on project app"},
+		{"no user", LockInfo{Command: "sync"}, "someone is running sync"},
+		{"long command", LockInfo{User: "alice", Command: strings.Repeat("x", 50)}, "alice is running " + strings.Repeat("x", 37) + "..."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.info.Activity())
+		})
+	}
+}
+
+func TestLockInfo_Badge(t *testing.T) {
+	info := LockInfo{User: "alice", Command: "pytest", Project: "app", Started: time.Now().Add(-12*time.Minute - 5*time.Second)}
+	assert.Equal(t, "alice is running pytest on project app, 12m elapsed", info.Badge())
+}
+
+func TestFormatElapsed(t *testing.T) {
+	assert.Equal(t, "0s", FormatElapsed(-time.Second))
+	assert.Equal(t, "40s", FormatElapsed(40*time.Second))
+	assert.Equal(t, "12m", FormatElapsed(12*time.Minute+30*time.Second))
+	assert.Equal(t, "2h", FormatElapsed(2*time.Hour))
+	assert.Equal(t, "1h5m", FormatElapsed(time.Hour+5*time.Minute))
+}
+
+func TestInitials(t *testing.T) {
+	assert.Equal(t, "A", Initials("alice"))
+	assert.Equal(t, "AS", Initials("alice.smith"))
+	assert.Equal(t, "AS", Initials("alice_smith-jones"))
+	assert.Equal(t, "É", Initials("élodie"))
+	assert.Equal(t, "?", Initials(""))
 }
 
 func TestLockInfo_Age(t *testing.T) {
@@ -305,6 +351,90 @@ func TestLockDir_PerHost(t *testing.T) {
 	}
 }
 
+func TestDir_Scopes(t *testing.T) {
+	conn := &host.Connection{Name: "box", Host: config.Host{Dir: "/srv/rr/my app"}}
+	cfg := config.LockConfig{Dir: "/tmp/rr-locks"}
+
+	assert.Equal(t, "/tmp/rr-locks/rr.lock", Dir(cfg, conn, "make test"))
+
+	cfg.Scope = config.LockScopeHost
+	assert.Equal(t, "/tmp/rr-locks/rr.lock", Dir(cfg, conn, "make test"))
+
+	cfg.Scope = config.LockScopeProject
+	project := Dir(cfg, conn, "make test")
+	assert.Regexp(t, `^/tmp/rr-locks/rr\.lock\.my_app-[0-9a-f]{8}$`, project)
+	assert.Equal(t, project, Dir(cfg, conn, "make lint"), "one lock per project")
+
+	other := &host.Connection{Name: "box", Host: config.Host{Dir: "/srv/other/my app"}}
+	assert.NotEqual(t, project, Dir(cfg, other, "make test"), "same name, different project")
+
+	cfg.Scope = config.LockScopeCommand
+	test := Dir(cfg, conn, "make test")
+	assert.Regexp(t, `^`+regexp.QuoteMeta(project)+`\.[0-9a-f]{8}$`, test)
+	assert.NotEqual(t, test, Dir(cfg, conn, "make lint"))
+}
+
+func TestAcquire_ProjectScope(t *testing.T) {
+	conn, mock := newMockConnection("testhost")
+	cfg := config.LockConfig{
+		Enabled: true,
+		Timeout: time.Second,
+		Stale:   10 * time.Minute,
+		Dir:     "/tmp",
+		Scope:   config.LockScopeProject,
+	}
+
+	conn.Host.Dir = "/srv/app"
+	first, err := Acquire(conn, cfg, "make test")
+	require.NoError(t, err)
+	assert.True(t, mock.GetFS().IsDir(first.Dir))
+	assert.False(t, mock.GetFS().IsDir("/tmp/rr.lock"), "no host-wide lock")
+
+	// Same project is locked
+	_, err = TryAcquire(conn, cfg, "make lint")
+	assert.ErrorIs(t, err, ErrLocked)
+	assert.NotEmpty(t, GetLockHolder(conn, cfg, "make lint"))
+
+	// A different project on the same host isn't
+	other := *conn
+	other.Host.Dir = "/srv/api"
+	second, err := TryAcquire(&other, cfg, "make test")
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Dir, second.Dir)
+}
+
+func TestAcquire_WithCommandKey(t *testing.T) {
+	conn, _ := newMockConnection("testhost")
+	cfg := config.LockConfig{
+		Enabled: true,
+		Timeout: time.Second,
+		Dir:     "/tmp",
+		Scope:   config.LockScopeCommand,
+	}
+
+	lock, err := Acquire(conn, cfg, "starting...", WithCommandKey("lint\ntest"))
+	require.NoError(t, err)
+	assert.Equal(t, Dir(cfg, conn, "lint\ntest"), lock.Dir)
+	assert.Equal(t, "starting...", lock.Info.Command)
+}
+
+func TestHeld_CommandScope(t *testing.T) {
+	conn, mock := newMockConnection("testhost")
+	cfg := config.LockConfig{Dir: "/tmp", Stale: 10 * time.Minute, Scope: config.LockScopeCommand}
+
+	test, lint := Dir(cfg, conn, "make test"), Dir(cfg, conn, "make lint")
+	for _, dir := range []string{test, lint} {
+		mock.GetFS().Mkdir(dir)
+		info, _ := (&LockInfo{User: "u", Hostname: "h", Started: time.Now()}).Marshal()
+		mock.GetFS().WriteFile(dir+"/info.json", info)
+	}
+	mock.SetCommandResponse("^for d in", sshtesting.CommandResponse{
+		Stdout: []byte(test + "\n" + lint + "\n"),
+	})
+
+	assert.Equal(t, []string{test, lint}, Held(conn, cfg))
+}
+
 func TestLock_Struct(t *testing.T) {
 	info := &LockInfo{
 		User:     "testuser",
@@ -432,6 +562,26 @@ func TestAcquire_AlreadyHeld_TimesOut(t *testing.T) {
 	assert.Contains(t, err.Error(), "other@otherhost")
 }
 
+func TestAcquire_WaitFuncSeesHolder(t *testing.T) {
+	conn, mock := newMockConnection("testhost")
+	mock.GetFS().Mkdir("/tmp/rr.lock")
+	info := &LockInfo{User: "alice", Hostname: "laptop", Started: time.Now(), PID: 7, Command: "pytest", Project: "app"}
+	infoJSON, _ := info.Marshal()
+	mock.GetFS().WriteFile("/tmp/rr.lock/info.json", infoJSON)
+	mock.GetFS().WriteFile("/tmp/rr.lock/output", []byte("epoch 3/10\n"))
+
+	cfg := config.LockConfig{Enabled: true, Timeout: 100 * time.Millisecond, Stale: 10 * time.Minute, Dir: "/tmp"}
+
+	var seen []Wait
+	_, err := Acquire(conn, cfg, "", WithWaitFunc(func(w Wait) { seen = append(seen, w) }))
+	require.Error(t, err)
+	require.NotEmpty(t, seen)
+	require.NotNil(t, seen[0].Holder)
+	assert.Equal(t, "alice is running pytest on project app", seen[0].Holder.Activity())
+	assert.Equal(t, "epoch 3/10", seen[0].LastOutput)
+	assert.LessOrEqual(t, seen[0].Remaining, cfg.Timeout)
+}
+
 func TestAcquire_StaleLockRemoved(t *testing.T) {
 	conn, mock := newMockConnection("testhost")
 
@@ -489,6 +639,38 @@ func TestRelease_Success(t *testing.T) {
 	assert.False(t, mock.GetFS().Exists("/tmp/rr.lock"))
 }
 
+// stallingUsageClient blocks usage log writes until unblock is closed, and
+// records whether the lock directory was gone when one started.
+type stallingUsageClient struct {
+	*sshtesting.MockClient
+	unblock      chan struct{}
+	lockGoneSeen chan bool
+}
+
+func (c *stallingUsageClient) Exec(cmd string) ([]byte, []byte, int, error) {
+	if strings.Contains(cmd, usageDirName) {
+		c.lockGoneSeen <- !c.GetFS().Exists("/tmp/rr.lock")
+		<-c.unblock
+	}
+	return c.MockClient.Exec(cmd)
+}
+
+func TestRelease_RemovesLockBeforeRecordingUsage(t *testing.T) {
+	conn, mock := newMockConnection("testhost")
+	client := &stallingUsageClient{MockClient: mock, unblock: make(chan struct{}), lockGoneSeen: make(chan bool, 1)}
+	conn.Client = client
+	defer close(client.unblock)
+
+	lock, err := Acquire(conn, config.LockConfig{Enabled: true, Timeout: 5 * time.Second, Stale: 10 * time.Minute, Dir: "/tmp"}, "")
+	require.NoError(t, err)
+
+	start := time.Now()
+	require.NoError(t, lock.Release())
+
+	assert.True(t, <-client.lockGoneSeen, "lock should be removed before usage is recorded")
+	assert.Less(t, time.Since(start), usageRecordTimeout+time.Second, "a stalled usage write shouldn't hold up Release")
+}
+
 func TestForceRelease_Success(t *testing.T) {
 	conn, mock := newMockConnection("testhost")
 
@@ -504,6 +686,32 @@ func TestForceRelease_Success(t *testing.T) {
 	assert.False(t, mock.GetFS().Exists("/tmp/rr.lock"))
 }
 
+func TestReleaseAbandoned(t *testing.T) {
+	conn, mock := newMockConnection("testhost")
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+	mine, _ := json.Marshal(LockInfo{User: "me", Hostname: hostname, PID: 4242})
+
+	mock.GetFS().Mkdir("/tmp/rr.lock")
+	mock.GetFS().WriteFile("/tmp/rr.lock/info.json", mine)
+
+	// Another process's lock stays
+	released, err := ReleaseAbandoned(conn, "/tmp/rr.lock", 4243)
+	require.NoError(t, err)
+	assert.False(t, released)
+	assert.True(t, mock.GetFS().Exists("/tmp/rr.lock"))
+
+	released, err = ReleaseAbandoned(conn, "/tmp/rr.lock", 4242)
+	require.NoError(t, err)
+	assert.True(t, released)
+	assert.False(t, mock.GetFS().Exists("/tmp/rr.lock"))
+
+	// Nothing left to release
+	released, err = ReleaseAbandoned(conn, "/tmp/rr.lock", 4242)
+	require.NoError(t, err)
+	assert.False(t, released)
+}
+
 func TestHolder_ReturnsInfo(t *testing.T) {
 	conn, mock := newMockConnection("testhost")
 
@@ -903,7 +1111,7 @@ func TestIsLocked_True(t *testing.T) {
 		Dir:     "/tmp",
 	}
 
-	locked := IsLocked(conn, cfg)
+	locked := IsLocked(conn, cfg, "")
 	assert.True(t, locked)
 }
 
@@ -916,10 +1124,30 @@ func TestIsLocked_False_NoLock(t *testing.T) {
 		Dir:     "/tmp",
 	}
 
-	locked := IsLocked(conn, cfg)
+	locked := IsLocked(conn, cfg, "")
 	assert.False(t, locked)
 }
 
+func TestCheckHeld(t *testing.T) {
+	conn, mock := newMockConnection("testhost")
+
+	held, err := CheckHeld(conn, "/tmp/rr.lock", 10*time.Minute)
+	require.NoError(t, err)
+	assert.False(t, held)
+
+	mock.GetFS().Mkdir("/tmp/rr.lock")
+	infoJSON, _ := (&LockInfo{User: "holder", Started: time.Now()}).Marshal()
+	mock.GetFS().WriteFile("/tmp/rr.lock/info.json", infoJSON)
+	held, err = CheckHeld(conn, "/tmp/rr.lock", 10*time.Minute)
+	require.NoError(t, err)
+	assert.True(t, held)
+
+	// A dropped connection isn't a free lock
+	mock.Close()
+	_, err = CheckHeld(conn, "/tmp/rr.lock", 10*time.Minute)
+	assert.Error(t, err)
+}
+
 func TestIsLocked_False_StaleLock(t *testing.T) {
 	conn, mock := newMockConnection("testhost")
 
@@ -941,7 +1169,7 @@ func TestIsLocked_False_StaleLock(t *testing.T) {
 	}
 
 	// Stale locks should not count as locked
-	locked := IsLocked(conn, cfg)
+	locked := IsLocked(conn, cfg, "")
 	assert.False(t, locked)
 }
 
@@ -953,7 +1181,7 @@ func TestIsLocked_NoConnection(t *testing.T) {
 	}
 
 	// With nil connection, should return false
-	locked := IsLocked(nil, cfg)
+	locked := IsLocked(nil, cfg, "")
 	assert.False(t, locked)
 }
 
@@ -981,7 +1209,7 @@ func TestGetLockHolder_ReturnsHolder(t *testing.T) {
 		Dir:     "/tmp",
 	}
 
-	holder := GetLockHolder(conn, cfg)
+	holder := GetLockHolder(conn, cfg, "")
 	assert.Contains(t, holder, "alice")
 	assert.Contains(t, holder, "wonderland")
 }
@@ -995,7 +1223,7 @@ func TestGetLockHolder_EmptyWhenNoLock(t *testing.T) {
 		Dir:     "/tmp",
 	}
 
-	holder := GetLockHolder(conn, cfg)
+	holder := GetLockHolder(conn, cfg, "")
 	assert.Empty(t, holder)
 }
 
@@ -1020,7 +1248,7 @@ func TestGetLockHolder_EmptyWhenStale(t *testing.T) {
 	}
 
 	// Stale lock should return empty holder
-	holder := GetLockHolder(conn, cfg)
+	holder := GetLockHolder(conn, cfg, "")
 	assert.Empty(t, holder)
 }
 
@@ -1153,3 +1381,17 @@ func TestIsLockStale_FallbackToStarted(t *testing.T) {
 	stale := isLockStale(mock, "/tmp/info.json", 10*time.Minute)
 	assert.True(t, stale)
 }
+
+func TestLockClient_AppliesHostEnv(t *testing.T) {
+	conn, mock := newMockConnection("testhost")
+	assert.Same(t, conn.Client, lockClient(conn), "no env leaves the client alone")
+
+	conn.Host.Env = map[string]string{"TMPDIR": "/scratch"}
+	conn.Host.ProfileFiles = []string{"~/.profile"}
+	mock.SetCommandResponse(`^if \[ -f ~/'\.profile' \]; then \. ~/'\.profile'; fi && export TMPDIR="/scratch" && mkdir -p "/scratch/rr-locks"$`,
+		sshtesting.CommandResponse{ExitCode: 7})
+
+	_, _, exitCode, err := lockClient(conn).Exec(`mkdir -p "/scratch/rr-locks"`)
+	require.NoError(t, err)
+	assert.Equal(t, 7, exitCode)
+}
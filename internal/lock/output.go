@@ -0,0 +1,178 @@
+package lock
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/rileyhilliard/rr/internal/util"
+	"github.com/rileyhilliard/rr/pkg/sshutil"
+)
+
+// outputFile is where a lock holder shares the last line of its command's
+// output, next to info.json, so runs waiting on the lock can show it.
+const outputFile = "output"
+
+// outputInterval is how often the holder writes a changed last line.
+const outputInterval = 2 * time.Second
+
+// maxOutputLine caps the shared line, in runes. Waiters show it under a
+// spinner, where it has to fit on one line.
+const maxOutputLine = 100
+
+// maxPartialLine caps how much of a line without a newline yet is kept.
+const maxPartialLine = 4096
+
+// ansiPattern matches terminal escape sequences, which mean nothing to a
+// waiter printing the line somewhere else.
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)`)
+
+// lastLine is an io.Writer that remembers the last line written to it with
+// anything in it. Carriage returns end lines too, so a progress bar's latest
+// frame counts as a line.
+type lastLine struct {
+	mu      sync.Mutex
+	partial []byte
+	line    string
+}
+
+// Write implements io.Writer.
+func (l *lastLine) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	data := append(l.partial, p...)
+	end := strings.LastIndexAny(string(data), "\r\n")
+	if end >= 0 {
+		lines := strings.FieldsFunc(string(data[:end]), func(r rune) bool { return r == '\r' || r == '\n' })
+		for i := len(lines) - 1; i >= 0; i-- {
+			if line := cleanOutputLine(lines[i]); line != "" {
+				l.line = line
+				break
+			}
+		}
+		data = data[end+1:]
+	}
+	if len(data) > maxPartialLine {
+		data = data[len(data)-maxPartialLine:]
+	}
+	l.partial = append(l.partial[:0], data...)
+	return len(p), nil
+}
+
+// String returns the last line, or what there is of the line being written
+// if nothing has ended a line yet.
+func (l *lastLine) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.line != "" {
+		return l.line
+	}
+	return cleanOutputLine(string(l.partial))
+}
+
+// cleanOutputLine strips escape sequences and control characters from line
+// and trims it to maxOutputLine runes.
+func cleanOutputLine(line string) string {
+	line = ansiPattern.ReplaceAllString(line, "")
+	line = strings.Map(func(r rune) rune {
+		if r == '\t' {
+			return ' '
+		}
+		if r < ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, line)
+	line = strings.TrimSpace(line)
+	if r := []rune(line); len(r) > maxOutputLine {
+		line = string(r[:maxOutputLine-3]) + "..."
+	}
+	return line
+}
+
+// OutputWriter returns a writer to tee the command's output into while the
+// lock is held. rr shares the last line of it in the lock dir every couple
+// of seconds, so runs waiting on the lock can show how far along it is.
+// Release stops sharing it.
+func (l *Lock) OutputWriter() io.Writer {
+	if l == nil || l.conn == nil || l.conn.Client == nil {
+		return io.Discard
+	}
+
+	l.heartbeatMu.Lock()
+	defer l.heartbeatMu.Unlock()
+
+	if l.output == nil {
+		l.output = &lastLine{}
+		l.outputStop = make(chan struct{})
+		l.outputDone = make(chan struct{})
+		go shareOutputLoop(lockClient(l.conn), filepath.Join(l.Dir, outputFile), l.output, l.outputStop, l.outputDone)
+	}
+	return l.output
+}
+
+// stopSharingOutput stops the loop OutputWriter started, if it did.
+func (l *Lock) stopSharingOutput() {
+	l.heartbeatMu.Lock()
+	stop, done := l.outputStop, l.outputDone
+	l.outputStop = nil
+	l.heartbeatMu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+// shareOutputLoop writes out's last line to file whenever it changes, until
+// stop is closed.
+func shareOutputLoop(client sshutil.SSHClient, file string, out *lastLine, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(outputInterval)
+	defer ticker.Stop()
+
+	shared := ""
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			line := out.String()
+			if line == shared {
+				continue
+			}
+			writeCmd := fmt.Sprintf("printf '%%s\\n' %s > %q", util.ShellQuote(line), file)
+			if _, _, exitCode, err := client.Exec(writeCmd); err != nil || exitCode != 0 {
+				debugf("sharing output line failed: exit=%d err=%v", exitCode, err)
+				continue
+			}
+			shared = line
+		}
+	}
+}
+
+// readOutput reads the last output line the holder of the lock at lockDir
+// shared, or "" if it hasn't shared one.
+func readOutput(client sshutil.SSHClient, lockDir string) string {
+	stdout, _, exitCode, err := client.Exec(fmt.Sprintf("cat %q 2>/dev/null", filepath.Join(lockDir, outputFile)))
+	if err != nil || exitCode != 0 {
+		return ""
+	}
+	return cleanOutputLine(string(stdout))
+}
+
+// GetLastOutput returns the last line of output the lock's holder shared,
+// or "" if it hasn't shared any.
+func GetLastOutput(conn *host.Connection, cfg config.LockConfig, command string) string {
+	if !host.HasClient(conn) {
+		return ""
+	}
+	return readOutput(lockClient(conn), Dir(cfg, conn, command))
+}
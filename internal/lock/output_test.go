@@ -0,0 +1,53 @@
+package lock
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLastLine(t *testing.T) {
+	var out lastLine
+	assert.Empty(t, out.String())
+
+	fmt.Fprint(&out, "collecting")
+	assert.Equal(t, "collecting", out.String(), "a line in progress shows until one ends")
+
+	fmt.Fprint(&out, " 12 items\n\n")
+	assert.Equal(t, "collecting 12 items", out.String(), "blank lines don't count")
+
+	fmt.Fprint(&out, "epoch 1/10 [##   ]\repoch 1/10 [#### ]\r")
+	assert.Equal(t, "epoch 1/10 [#### ]", out.String(), "carriage returns end progress frames")
+
+	fmt.Fprint(&out, "\x1b[32mPASSED\x1b[0m\ttest_a\n")
+	assert.Equal(t, "PASSED test_a", out.String())
+}
+
+func TestCleanOutputLine(t *testing.T) {
+	assert.Equal(t, "ok", cleanOutputLine("  \x1b[1mok\x1b[0m\x07 "))
+	assert.Equal(t, "title", cleanOutputLine("\x1b]0;term\x07title"))
+
+	long := cleanOutputLine(strings.Repeat("x", 500))
+	assert.Len(t, []rune(long), maxOutputLine)
+	assert.True(t, strings.HasSuffix(long, "..."))
+}
+
+func TestGetLastOutput(t *testing.T) {
+	conn, mock := newMockConnection("testhost")
+	cfg := config.LockConfig{Dir: "/tmp"}
+	assert.Empty(t, GetLastOutput(conn, cfg, ""), "nothing shared")
+
+	mock.GetFS().Mkdir("/tmp/rr.lock")
+	mock.GetFS().WriteFile("/tmp/rr.lock/output", []byte("step 4 of 9\n"))
+	assert.Equal(t, "step 4 of 9", GetLastOutput(conn, cfg, ""))
+}
+
+func TestOutputWriter_NoConnection(t *testing.T) {
+	var l *Lock
+	n, err := l.OutputWriter().Write([]byte("hi\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+}
@@ -0,0 +1,306 @@
+package lock
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/rileyhilliard/rr/pkg/sshutil"
+)
+
+// ReservationInfo is what a reservation file holds: who reserved how much of
+// the host.
+type ReservationInfo struct {
+	LockInfo
+	CPUs        float64 `json:"cpus,omitempty"`
+	MemoryBytes int64   `json:"memory_bytes,omitempty"`
+}
+
+// Capacity is a host's size and the live reservations against it. Zero CPUs
+// or MemoryBytes means the host's size couldn't be read.
+type Capacity struct {
+	CPUs         float64
+	MemoryBytes  int64
+	Reservations []ReservationInfo
+}
+
+// Reserved returns the CPUs and memory the live reservations hold.
+func (c *Capacity) Reserved() (cpus float64, memoryBytes int64) {
+	for _, r := range c.Reservations {
+		cpus += r.CPUs
+		memoryBytes += r.MemoryBytes
+	}
+	return cpus, memoryBytes
+}
+
+// Fits reports whether a reservation of cpus and memoryBytes fits next to
+// the existing ones. A resource whose size is unknown never blocks.
+func (c *Capacity) Fits(cpus float64, memoryBytes int64) bool {
+	reservedCPUs, reservedMemory := c.Reserved()
+	if cpus > 0 && c.CPUs > 0 && reservedCPUs+cpus > c.CPUs {
+		return false
+	}
+	if memoryBytes > 0 && c.MemoryBytes > 0 && reservedMemory+memoryBytes > c.MemoryBytes {
+		return false
+	}
+	return true
+}
+
+// String describes what's free, e.g. "4 of 16 CPUs and 8.0 GB of 64.0 GB free".
+func (c *Capacity) String() string {
+	reservedCPUs, reservedMemory := c.Reserved()
+	var parts []string
+	if c.CPUs > 0 {
+		parts = append(parts, fmt.Sprintf("%g of %g CPUs", max(c.CPUs-reservedCPUs, 0), c.CPUs))
+	}
+	if c.MemoryBytes > 0 {
+		parts = append(parts, fmt.Sprintf("%s of %s", config.FormatSize(max(c.MemoryBytes-reservedMemory, 0)), config.FormatSize(c.MemoryBytes)))
+	}
+	if len(parts) == 0 {
+		return "capacity unknown"
+	}
+	return strings.Join(parts, " and ") + " free"
+}
+
+// Reservation is a claim on part of a host, held until Release.
+type Reservation struct {
+	File string // The reservation file on the remote
+	Info *ReservationInfo
+	conn *host.Connection
+
+	heartbeatStop chan struct{}
+	heartbeatDone chan struct{}
+	heartbeatMu   sync.Mutex
+}
+
+// ReservationsDir returns the directory holding reservation files, next to
+// the lock directories.
+func ReservationsDir(cfg config.LockConfig) string {
+	return filepath.Join(filepath.Dir(LockDir(cfg)), "rr.reservations")
+}
+
+// ReservationListCommand returns a shell command that prints the remote
+// clock, then a "<mtime> <file> <json>" line per reservation file in dir.
+// dir is double-quoted, so it may reference variables like $TMPDIR.
+func ReservationListCommand(dir string) string {
+	return fmt.Sprintf(`d=%q; date +%%s; for f in "$d"/*.json; do [ -f "$f" ] || continue; `+
+		`m=$(stat -c %%Y "$f" 2>/dev/null || stat -f %%m "$f" 2>/dev/null || echo 0); `+
+		`printf '%%s %%s ' "$m" "$f"; tr -d '\n' < "$f"; echo; done`, dir)
+}
+
+// ParseReservations parses ReservationListCommand's output into the live
+// reservations and the files of the stale ones, whose holders stopped
+// touching them more than stale ago. A zero stale keeps them all.
+func ParseReservations(output string, stale time.Duration) (live []ReservationInfo, staleFiles []string) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	now, err := strconv.ParseInt(strings.TrimSpace(lines[0]), 10, 64)
+	if err != nil {
+		now = time.Now().Unix()
+	}
+
+	for _, line := range lines[1:] {
+		fields := strings.SplitN(strings.TrimSpace(line), " ", 3)
+		if len(fields) < 3 {
+			continue
+		}
+		mtime, _ := strconv.ParseInt(fields[0], 10, 64)
+		if stale > 0 && mtime > 0 && time.Duration(now-mtime)*time.Second > stale {
+			staleFiles = append(staleFiles, fields[1])
+			continue
+		}
+		var info ReservationInfo
+		if err := json.Unmarshal([]byte(fields[2]), &info); err != nil {
+			continue
+		}
+		live = append(live, info)
+	}
+	return live, staleFiles
+}
+
+// capacityCommand prints the host's core count, then its memory as
+// "kb <n>" from /proc/meminfo or "bytes <n>" from sysctl on macOS.
+const capacityCommand = `nproc 2>/dev/null || getconf _NPROCESSORS_ONLN 2>/dev/null || sysctl -n hw.ncpu 2>/dev/null || echo 0; ` +
+	`awk '/^MemTotal:/ { print "kb", $2 }' /proc/meminfo 2>/dev/null || echo "bytes $(sysctl -n hw.memsize 2>/dev/null || echo 0)"`
+
+// parseCapacity parses capacityCommand's output.
+func parseCapacity(output string) (cpus float64, memoryBytes int64) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) > 0 {
+		cpus, _ = strconv.ParseFloat(strings.TrimSpace(lines[0]), 64)
+	}
+	if len(lines) > 1 {
+		fields := strings.Fields(lines[1])
+		if len(fields) == 2 {
+			n, _ := strconv.ParseInt(fields[1], 10, 64)
+			if fields[0] == "kb" {
+				n *= 1024
+			}
+			memoryBytes = n
+		}
+	}
+	return cpus, memoryBytes
+}
+
+// HostCapacity reads the host's size and its live reservations.
+func HostCapacity(conn *host.Connection, cfg config.LockConfig) (*Capacity, error) {
+	c, _, err := hostCapacity(conn, cfg)
+	return c, err
+}
+
+// hostCapacity is HostCapacity, also returning the stale reservation files.
+func hostCapacity(conn *host.Connection, cfg config.LockConfig) (*Capacity, []string, error) {
+	if err := host.ValidateConnectionForLock(conn); err != nil {
+		return nil, nil, err
+	}
+	client := lockClient(conn)
+
+	stdout, _, _, err := client.Exec(capacityCommand)
+	if err != nil {
+		return nil, nil, err
+	}
+	c := &Capacity{}
+	c.CPUs, c.MemoryBytes = parseCapacity(string(stdout))
+
+	stdout, _, _, err = client.Exec(ReservationListCommand(ReservationsDir(cfg)))
+	if err != nil {
+		return nil, nil, err
+	}
+	var staleFiles []string
+	c.Reservations, staleFiles = ParseReservations(string(stdout), cfg.Stale)
+	return c, staleFiles, nil
+}
+
+// Reserve claims cpus and memoryBytes of the host for command until
+// Release. It fails with ErrNoCapacity when the host's live reservations
+// leave too little room. Reservations are advisory: they keep rr runs that
+// reserve from overcommitting a host, and nothing else. Two runs reserving
+// at the same instant can both get in, so pair reservations with a lock when
+// that matters.
+func Reserve(conn *host.Connection, cfg config.LockConfig, command string, cpus float64, memoryBytes int64) (*Reservation, error) {
+	capacity, staleFiles, err := hostCapacity(conn, cfg)
+	if err != nil {
+		return nil, err
+	}
+	client := lockClient(conn)
+	for _, f := range staleFiles {
+		client.Exec(fmt.Sprintf("rm -rf %q", f)) //nolint:errcheck // Stale files are ignored either way
+	}
+	if !capacity.Fits(cpus, memoryBytes) {
+		return nil, fmt.Errorf("%w: %s", ErrNoCapacity, capacity)
+	}
+
+	lockInfo, err := NewLockInfo(command)
+	if err != nil {
+		return nil, err
+	}
+	info := &ReservationInfo{LockInfo: *lockInfo, CPUs: cpus, MemoryBytes: memoryBytes}
+	data, err := json.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := ReservationsDir(cfg)
+	file := filepath.Join(dir, reservationName(info))
+	if _, _, exitCode, err := client.Exec(fmt.Sprintf("mkdir -p %q", dir)); err != nil || exitCode != 0 {
+		return nil, fmt.Errorf("couldn't create the reservations directory %s", dir)
+	}
+	if _, stderr, exitCode, err := client.Exec(fmt.Sprintf("cat > %q << 'RESERVATION'\n%s\nRESERVATION", file, string(data))); err != nil || exitCode != 0 {
+		return nil, fmt.Errorf("couldn't write reservation file %s: %s", file, strings.TrimSpace(string(stderr)))
+	}
+
+	return &Reservation{File: file, Info: info, conn: conn}, nil
+}
+
+// reservationName is the file name of a reservation, unique per process.
+func reservationName(info *ReservationInfo) string {
+	hostname := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, info.Hostname)
+	return fmt.Sprintf("%s-%d-%d.json", hostname, os.Getpid(), info.Started.UnixNano())
+}
+
+// StartHeartbeat touches the reservation file every 30 seconds, like
+// Lock.StartHeartbeat, so it isn't taken for an abandoned one. Idempotent.
+func (r *Reservation) StartHeartbeat() {
+	if r == nil || r.conn == nil || r.conn.Client == nil {
+		return
+	}
+
+	r.heartbeatMu.Lock()
+	defer r.heartbeatMu.Unlock()
+	if r.heartbeatStop != nil {
+		return
+	}
+	r.heartbeatStop = make(chan struct{})
+	r.heartbeatDone = make(chan struct{})
+	go touchLoop(lockClient(r.conn), r.File, r.heartbeatStop, r.heartbeatDone)
+}
+
+// Release stops the heartbeat and removes the reservation file.
+func (r *Reservation) Release() error {
+	if r == nil || r.conn == nil || r.conn.Client == nil {
+		return nil
+	}
+
+	r.heartbeatMu.Lock()
+	stop, done := r.heartbeatStop, r.heartbeatDone
+	r.heartbeatStop = nil
+	r.heartbeatMu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+
+	_, _, exitCode, err := lockClient(r.conn).Exec(fmt.Sprintf("rm -rf %q", r.File))
+	if err != nil {
+		return err
+	}
+	if exitCode != 0 {
+		return fmt.Errorf("couldn't remove reservation file %s", r.File)
+	}
+	return nil
+}
+
+// touchLoop touches file every 30 seconds until stop is closed, giving up
+// after three failures in a row. It closes done when it returns.
+func touchLoop(client sshutil.SSHClient, file string, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	consecutiveFailures := 0
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			touchCmd := fmt.Sprintf("touch %q", file)
+			_, _, _, err := client.Exec(touchCmd)
+			if err != nil {
+				consecutiveFailures++
+				debugf("heartbeat touch failed (%d consecutive): %v", consecutiveFailures, err)
+				if consecutiveFailures >= 3 {
+					debugf("heartbeat stopping after %d consecutive failures", consecutiveFailures)
+					return
+				}
+			} else {
+				consecutiveFailures = 0
+			}
+		}
+	}
+}
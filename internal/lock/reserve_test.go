@@ -0,0 +1,141 @@
+package lock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	sshtesting "github.com/rileyhilliard/rr/pkg/sshutil/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReservationsDir(t *testing.T) {
+	assert.Equal(t, "/tmp/rr.reservations", ReservationsDir(config.LockConfig{}))
+	assert.Equal(t, "/var/rr/rr.reservations", ReservationsDir(config.LockConfig{Dir: "/var/rr"}))
+}
+
+func TestParseCapacity(t *testing.T) {
+	cpus, mem := parseCapacity("16\nkb 65536000\n")
+	assert.Equal(t, 16.0, cpus)
+	assert.Equal(t, int64(65536000*1024), mem)
+
+	cpus, mem = parseCapacity("10\nbytes 34359738368\n")
+	assert.Equal(t, 10.0, cpus)
+	assert.Equal(t, int64(34359738368), mem)
+
+	cpus, mem = parseCapacity("")
+	assert.Zero(t, cpus)
+	assert.Zero(t, mem)
+}
+
+func TestCapacity_Fits(t *testing.T) {
+	c := &Capacity{
+		CPUs:        16,
+		MemoryBytes: 64 << 30,
+		Reservations: []ReservationInfo{
+			{CPUs: 8, MemoryBytes: 32 << 30},
+			{CPUs: 4},
+		},
+	}
+	assert.True(t, c.Fits(4, 32<<30))
+	assert.False(t, c.Fits(5, 0))
+	assert.False(t, c.Fits(0, 33<<30))
+	assert.Equal(t, "4 of 16 CPUs and 32.0 GB of 64.0 GB free", c.String())
+
+	// Unknown sizes never block
+	unknown := &Capacity{Reservations: []ReservationInfo{{CPUs: 100}}}
+	assert.True(t, unknown.Fits(8, 1<<40))
+	assert.Equal(t, "capacity unknown", unknown.String())
+}
+
+func TestParseReservations(t *testing.T) {
+	fresh, _ := json.Marshal(ReservationInfo{LockInfo: LockInfo{User: "alice", Hostname: "laptop", PID: 1}, CPUs: 4})
+	old, _ := json.Marshal(ReservationInfo{LockInfo: LockInfo{User: "bob", Hostname: "desk", PID: 2}, CPUs: 8})
+	output := fmt.Sprintf("1000000\n999990 /tmp/rr.reservations/a.json %s\n990000 /tmp/rr.reservations/b.json %s\n999999 /tmp/rr.reservations/c.json not json\n", fresh, old)
+
+	live, stale := ParseReservations(output, 10*time.Minute)
+	require.Len(t, live, 1)
+	assert.Equal(t, "alice", live[0].User)
+	assert.Equal(t, 4.0, live[0].CPUs)
+	assert.Equal(t, []string{"/tmp/rr.reservations/b.json"}, stale)
+
+	// A zero threshold keeps everything
+	live, stale = ParseReservations(output, 0)
+	assert.Len(t, live, 2)
+	assert.Empty(t, stale)
+
+	live, stale = ParseReservations("", time.Minute)
+	assert.Empty(t, live)
+	assert.Empty(t, stale)
+}
+
+func TestReservationListCommand_RunsLocally(t *testing.T) {
+	dir := t.TempDir()
+	data, _ := json.Marshal(ReservationInfo{LockInfo: LockInfo{User: "alice"}, CPUs: 2, MemoryBytes: 1 << 30})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.json"), append(data, '\n'), 0644))
+
+	out, err := exec.Command("sh", "-c", ReservationListCommand(dir)).Output()
+	require.NoError(t, err)
+
+	live, stale := ParseReservations(string(out), time.Minute)
+	require.Len(t, live, 1)
+	assert.Equal(t, 2.0, live[0].CPUs)
+	assert.Equal(t, int64(1<<30), live[0].MemoryBytes)
+	assert.Empty(t, stale)
+
+	// An empty directory lists nothing
+	out, err = exec.Command("sh", "-c", ReservationListCommand(t.TempDir())).Output()
+	require.NoError(t, err)
+	live, _ = ParseReservations(string(out), time.Minute)
+	assert.Empty(t, live)
+}
+
+func TestReserve_WritesAndReleases(t *testing.T) {
+	conn, mock := newMockConnection("testhost")
+	mock.SetCommandResponse("^nproc", sshtesting.CommandResponse{Stdout: []byte("16\nkb 67108864\n")})
+	mock.SetCommandResponse(`date \+%s`, sshtesting.CommandResponse{Stdout: []byte("1000000\n")})
+
+	cfg := config.LockConfig{Dir: "/tmp", Stale: 10 * time.Minute}
+	r, err := Reserve(conn, cfg, "make bench", 8, 16<<30)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	data, err := mock.GetFS().ReadFile(r.File)
+	require.NoError(t, err)
+	var info ReservationInfo
+	require.NoError(t, json.Unmarshal(data, &info))
+	assert.Equal(t, 8.0, info.CPUs)
+	assert.Equal(t, int64(16<<30), info.MemoryBytes)
+	assert.Equal(t, "make bench", info.Command)
+	assert.Equal(t, "/tmp/rr.reservations", filepath.Dir(r.File))
+
+	require.NoError(t, r.Release())
+	assert.False(t, mock.GetFS().Exists(r.File))
+}
+
+func TestReserve_NoCapacity(t *testing.T) {
+	conn, mock := newMockConnection("testhost")
+	held, _ := json.Marshal(ReservationInfo{LockInfo: LockInfo{User: "alice"}, CPUs: 12})
+	mock.SetCommandResponse("^nproc", sshtesting.CommandResponse{Stdout: []byte("16\nkb 67108864\n")})
+	mock.SetCommandResponse(`date \+%s`, sshtesting.CommandResponse{
+		Stdout: []byte(fmt.Sprintf("1000000\n999990 /tmp/rr.reservations/a.json %s\n", held)),
+	})
+
+	_, err := Reserve(conn, config.LockConfig{Stale: 10 * time.Minute}, "make bench", 8, 0)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrNoCapacity))
+	assert.Contains(t, err.Error(), "4 of 16 CPUs")
+}
+
+func TestReservation_ReleaseNil(t *testing.T) {
+	var r *Reservation
+	assert.NoError(t, r.Release())
+	r.StartHeartbeat()
+}
@@ -0,0 +1,222 @@
+package lock
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/rileyhilliard/rr/internal/util"
+)
+
+// maxUsageRecords caps each user's usage log. Past it the oldest quarter
+// is dropped, so the log isn't rewritten on every run.
+const maxUsageRecords = 2000
+
+// usageDirName is the directory of usage logs, next to the lock directories.
+// It's shared by every user like /tmp, so each user's log sits in a
+// directory of its own, rr.usage/<user>/<user>.jsonl, that only they can
+// write to: nobody else can plant a symlink where their records go.
+const usageDirName = "rr.usage"
+
+// usageRecordTimeout bounds how long Release waits for the usage log to be
+// written. Past it the write is left to finish on its own.
+const usageRecordTimeout = 2 * time.Second
+
+// hogShare is the share of a host's time past which FairShareHint names the
+// user who had it.
+const hogShare = 0.5
+
+// UsageRecord is one run's hold on a host, appended to the host's usage log
+// when the lock is released.
+type UsageRecord struct {
+	User     string        `json:"user"`
+	Hostname string        `json:"hostname"` // Machine the run came from
+	Project  string        `json:"project,omitempty"`
+	Started  time.Time     `json:"started"`
+	Duration time.Duration `json:"duration"` // How long the lock was held
+}
+
+// UserUsage is one user's part of a host's time.
+type UserUsage struct {
+	User    string        `json:"user"`
+	Runs    int           `json:"runs"`
+	Total   time.Duration `json:"-"`
+	Minutes float64       `json:"minutes"`
+	Share   float64       `json:"share"` // Of every user's time on the host, 0 to 1
+}
+
+// UsageDir returns the directory holding the usage logs, one per user, next
+// to the lock directories.
+func UsageDir(cfg config.LockConfig) string {
+	return filepath.Join(filepath.Dir(LockDir(cfg)), usageDirName)
+}
+
+// UsageListCommand returns a shell command that prints every record in the
+// usage logs in dir, including logs older versions of rr kept directly in
+// it. Symlinks are skipped. dir is double-quoted, so it may reference
+// variables like $TMPDIR.
+func UsageListCommand(dir string) string {
+	return fmt.Sprintf(`d=%q; for f in "$d"/*/*.jsonl "$d"/*.jsonl; do [ -f "$f" ] && [ ! -L "$f" ] && cat "$f"; done; true`, dir)
+}
+
+// usageAppendCommand returns a shell command that appends record to its
+// user's log in dir, creating dir open to every user like /tmp so each can
+// add their own directory. The command refuses to write unless dir is
+// sticky and the user's directory is a real directory they own, which it
+// makes writable only by them. It writes from inside that directory, so a
+// directory swapped in afterwards isn't followed, and rotates the log
+// through a mktemp file there instead of a fixed name.
+func usageAppendCommand(dir string, record UsageRecord) (string, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return "", err
+	}
+	user := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '.', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, record.User)
+	if strings.Trim(user, ".") == "" {
+		// "", "." and ".." would name the shared directory or its parent
+		user = strings.Repeat("_", len(user)+1)
+	}
+	return fmt.Sprintf(`d=%q; u=%s; f=%s.jsonl; `+
+		`mkdir -p -m 1777 "$d" && [ ! -L "$d" ] && [ -k "$d" ] && `+
+		`{ [ -d "$d/$u" ] || mkdir -m 755 "$d/$u"; } && [ ! -L "$d/$u" ] && cd "$d/$u" && [ -O . ] && chmod 755 . && `+
+		`[ ! -L "$f" ] && printf '%%s\n' %s >> "$f" && `+
+		`if [ "$(wc -l < "$f")" -gt %d ]; then `+
+		`t=$(mktemp ./.usage.XXXXXX) && chmod 644 "$t" && tail -n %d "$f" > "$t" && mv "$t" "$f" || { rm -f "$t"; false; }; fi`,
+		dir, user, user, util.ShellQuote(string(data)), maxUsageRecords, maxUsageRecords*3/4), nil
+}
+
+// heldFor returns how long the lock has been held, or 0 if it wasn't
+// acquired.
+func (l *Lock) heldFor() time.Duration {
+	if l.acquired.IsZero() {
+		return 0
+	}
+	return time.Since(l.acquired).Round(time.Second)
+}
+
+// recordUsage appends held, how long the lock was held, to the host's usage
+// log. It's bookkeeping, so a failure is only logged, and it waits at most
+// usageRecordTimeout.
+func (l *Lock) recordUsage(held time.Duration) {
+	if l.Info == nil || l.acquired.IsZero() {
+		return
+	}
+	record := UsageRecord{
+		User:     l.Info.User,
+		Hostname: l.Info.Hostname,
+		Project:  l.Info.Project,
+		Started:  l.acquired,
+		Duration: held,
+	}
+	cmd, err := usageAppendCommand(filepath.Join(filepath.Dir(l.Dir), usageDirName), record)
+	if err != nil {
+		return
+	}
+	client := lockClient(l.conn)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, stderr, exitCode, err := client.Exec(cmd); err != nil || exitCode != 0 {
+			debugf("recording usage failed: exit=%d err=%v stderr=%s", exitCode, err, strings.TrimSpace(string(stderr)))
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(usageRecordTimeout):
+		debugf("recording usage timed out after %s", usageRecordTimeout)
+	}
+}
+
+// ParseUsage parses UsageListCommand's output. Lines that aren't records are
+// skipped.
+func ParseUsage(output string) []UsageRecord {
+	var records []UsageRecord
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var r UsageRecord
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil || r.User == "" {
+			continue
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+// ReadUsage reads every record in the host's usage logs.
+func ReadUsage(conn *host.Connection, cfg config.LockConfig) ([]UsageRecord, error) {
+	if err := host.ValidateConnectionForLock(conn); err != nil {
+		return nil, err
+	}
+	stdout, _, _, err := lockClient(conn).Exec(UsageListCommand(UsageDir(cfg)))
+	if err != nil {
+		return nil, err
+	}
+	return ParseUsage(string(stdout)), nil
+}
+
+// SummarizeUsage totals the records that started at or after since by
+// user, most time first.
+func SummarizeUsage(records []UsageRecord, since time.Time) []UserUsage {
+	byUser := make(map[string]*UserUsage)
+	var total time.Duration
+	for _, r := range records {
+		if r.Started.Before(since) {
+			continue
+		}
+		u := byUser[r.User]
+		if u == nil {
+			u = &UserUsage{User: r.User}
+			byUser[r.User] = u
+		}
+		u.Runs++
+		u.Total += r.Duration
+		total += r.Duration
+	}
+
+	usage := make([]UserUsage, 0, len(byUser))
+	for _, u := range byUser {
+		u.Minutes = math.Round(u.Total.Minutes()*10) / 10
+		if total > 0 {
+			u.Share = float64(u.Total) / float64(total)
+		}
+		usage = append(usage, *u)
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Total != usage[j].Total {
+			return usage[i].Total > usage[j].Total
+		}
+		return usage[i].User < usage[j].User
+	})
+	return usage
+}
+
+// FairShareHint names the user who had most of a host's time, when one
+// did: over half of it, and half again their fair share with everyone
+// counted equally. Returns "" when the time is spread evenly enough.
+func FairShareHint(usage []UserUsage) string {
+	if len(usage) < 2 {
+		return ""
+	}
+	top := usage[0]
+	fair := 1 / float64(len(usage))
+	if top.Share <= hogShare || top.Share < 1.5*fair {
+		return ""
+	}
+	return fmt.Sprintf("%s had %.0f%% of the time across %d users (a fair share is %.0f%%)",
+		top.User, top.Share*100, len(usage), fair*100)
+}
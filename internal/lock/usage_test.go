@@ -0,0 +1,183 @@
+package lock
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageDir(t *testing.T) {
+	assert.Equal(t, "/tmp/rr.usage", UsageDir(config.LockConfig{}))
+	assert.Equal(t, "/var/rr/rr.usage", UsageDir(config.LockConfig{Dir: "/var/rr"}))
+}
+
+func TestUsageCommands_RunLocally(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "rr.usage")
+	started := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	for _, r := range []UsageRecord{
+		{User: "alice", Hostname: "laptop", Started: started, Duration: 90 * time.Second},
+		{User: "bob o'neil", Hostname: "desk", Started: started, Duration: time.Minute},
+		{User: "alice", Hostname: "laptop", Started: started.Add(time.Hour), Duration: 30 * time.Second},
+	} {
+		cmd, err := usageAppendCommand(dir, r)
+		require.NoError(t, err)
+		require.NoError(t, exec.Command("sh", "-c", cmd).Run())
+	}
+	assert.FileExists(t, filepath.Join(dir, "alice", "alice.jsonl"))
+	assert.FileExists(t, filepath.Join(dir, "bob_o_neil", "bob_o_neil.jsonl"), "one log per user, with a safe name")
+	info, err := os.Stat(filepath.Join(dir, "alice"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm(), "only the user can write to their directory")
+
+	out, err := exec.Command("sh", "-c", UsageListCommand(dir)).Output()
+	require.NoError(t, err)
+	records := ParseUsage(string(out))
+	require.Len(t, records, 3)
+	assert.Equal(t, "bob o'neil", records[2].User)
+	assert.Equal(t, time.Minute, records[2].Duration)
+
+	// No logs yet lists nothing
+	out, err = exec.Command("sh", "-c", UsageListCommand(filepath.Join(t.TempDir(), "missing"))).Output()
+	require.NoError(t, err)
+	assert.Empty(t, ParseUsage(string(out)))
+}
+
+// usageTestDir returns a shared usage directory, sticky like the one
+// usageAppendCommand creates.
+func usageTestDir(t *testing.T) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "rr.usage")
+	require.NoError(t, os.Mkdir(dir, 0755))
+	require.NoError(t, os.Chmod(dir, 0777|os.ModeSticky))
+	return dir
+}
+
+func TestUsageAppendCommand_TrimsOldRecords(t *testing.T) {
+	dir := usageTestDir(t)
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "alice"), 0755))
+	log := filepath.Join(dir, "alice", "alice.jsonl")
+	line := `{"user":"alice","started":"2026-03-02T09:00:00Z","duration":1000000000}` + "\n"
+	require.NoError(t, os.WriteFile(log, []byte(strings.Repeat(line, maxUsageRecords)), 0644))
+
+	cmd, err := usageAppendCommand(dir, UsageRecord{User: "alice", Duration: time.Second})
+	require.NoError(t, err)
+	require.NoError(t, exec.Command("sh", "-c", cmd).Run())
+
+	data, err := os.ReadFile(log)
+	require.NoError(t, err)
+	assert.Equal(t, maxUsageRecords*3/4, strings.Count(string(data), "\n"))
+	info, err := os.Stat(log)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm(), "others can still read the rotated log")
+	entries, err := os.ReadDir(filepath.Join(dir, "alice"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no rotation file left behind")
+}
+
+func TestUsageAppendCommand_RefusesSymlinks(t *testing.T) {
+	record := UsageRecord{User: "alice", Duration: time.Second}
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, dir, target string)
+	}{
+		{
+			name: "user directory",
+			setup: func(t *testing.T, dir, target string) {
+				require.NoError(t, os.Symlink(filepath.Dir(target), filepath.Join(dir, "alice")))
+			},
+		},
+		{
+			name: "log",
+			setup: func(t *testing.T, dir, target string) {
+				require.NoError(t, os.Mkdir(filepath.Join(dir, "alice"), 0755))
+				require.NoError(t, os.Symlink(target, filepath.Join(dir, "alice", "alice.jsonl")))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := usageTestDir(t)
+			target := filepath.Join(t.TempDir(), "alice.jsonl")
+			require.NoError(t, os.WriteFile(target, []byte("precious\n"), 0644))
+			tt.setup(t, dir, target)
+
+			cmd, err := usageAppendCommand(dir, record)
+			require.NoError(t, err)
+			assert.Error(t, exec.Command("sh", "-c", cmd).Run())
+
+			data, err := os.ReadFile(target)
+			require.NoError(t, err)
+			assert.Equal(t, "precious\n", string(data))
+		})
+	}
+}
+
+func TestUsageAppendCommand_RefusesSharedDirWithoutStickyBit(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "rr.usage")
+	require.NoError(t, os.Mkdir(dir, 0777))
+	require.NoError(t, os.Chmod(dir, 0777))
+
+	cmd, err := usageAppendCommand(dir, UsageRecord{User: "alice"})
+	require.NoError(t, err)
+	assert.Error(t, exec.Command("sh", "-c", cmd).Run())
+	assert.NoDirExists(t, filepath.Join(dir, "alice"))
+}
+
+func TestUsageListCommand_ReadsOldLogs(t *testing.T) {
+	dir := usageTestDir(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bob.jsonl"), []byte(`{"user":"bob","duration":60000000000}`+"\n"), 0644))
+	cmd, err := usageAppendCommand(dir, UsageRecord{User: "alice", Duration: time.Second})
+	require.NoError(t, err)
+	require.NoError(t, exec.Command("sh", "-c", cmd).Run())
+
+	out, err := exec.Command("sh", "-c", UsageListCommand(dir)).Output()
+	require.NoError(t, err)
+	assert.Len(t, ParseUsage(string(out)), 2)
+}
+
+func TestParseUsage_SkipsBadLines(t *testing.T) {
+	records := ParseUsage("not json\n{\"user\":\"alice\",\"duration\":60000000000}\n{}\n")
+	require.Len(t, records, 1)
+	assert.Equal(t, "alice", records[0].User)
+}
+
+func TestSummarizeUsage(t *testing.T) {
+	now := time.Date(2026, 3, 9, 12, 0, 0, 0, time.UTC)
+	records := []UsageRecord{
+		{User: "alice", Started: now.Add(-time.Hour), Duration: 30 * time.Minute},
+		{User: "alice", Started: now.Add(-2 * time.Hour), Duration: 30 * time.Minute},
+		{User: "bob", Started: now.Add(-3 * time.Hour), Duration: 20 * time.Minute},
+		{User: "carol", Started: now.Add(-10 * 24 * time.Hour), Duration: 5 * time.Hour},
+	}
+
+	usage := SummarizeUsage(records, now.Add(-7*24*time.Hour))
+	require.Len(t, usage, 2, "carol's run is older than the window")
+	assert.Equal(t, "alice", usage[0].User)
+	assert.Equal(t, 2, usage[0].Runs)
+	assert.Equal(t, time.Hour, usage[0].Total)
+	assert.Equal(t, 60.0, usage[0].Minutes)
+	assert.InDelta(t, 0.75, usage[0].Share, 0.001)
+	assert.Equal(t, "bob", usage[1].User)
+	assert.InDelta(t, 0.25, usage[1].Share, 0.001)
+
+	assert.Empty(t, SummarizeUsage(nil, now))
+}
+
+func TestFairShareHint(t *testing.T) {
+	assert.Empty(t, FairShareHint(nil))
+	assert.Empty(t, FairShareHint([]UserUsage{{User: "alice", Share: 1}}), "one user has nobody to share with")
+	assert.Empty(t, FairShareHint([]UserUsage{{User: "alice", Share: 0.6}, {User: "bob", Share: 0.4}}))
+
+	assert.Equal(t, "alice had 80% of the time across 2 users (a fair share is 50%)",
+		FairShareHint([]UserUsage{{User: "alice", Share: 0.8}, {User: "bob", Share: 0.2}}))
+	assert.Equal(t, "alice had 55% of the time across 3 users (a fair share is 33%)",
+		FairShareHint([]UserUsage{{User: "alice", Share: 0.55}, {User: "bob", Share: 0.3}, {User: "carol", Share: 0.15}}))
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 )
 
 // Logger defines the interface for logging operations.
@@ -19,7 +20,8 @@ type Logger interface {
 }
 
 // envLogger implements Logger and logs to stdout/stderr based on environment.
-// Debug messages are only printed when RR_DEBUG is set.
+// Debug messages are only printed when RR_DEBUG is set, or as verbose output
+// at -vv and up.
 type envLogger struct {
 	prefix string
 }
@@ -33,7 +35,9 @@ func NewEnvLogger(prefix string) Logger {
 func (l *envLogger) Debug(format string, args ...interface{}) {
 	if os.Getenv("RR_DEBUG") != "" {
 		log.Printf(l.prefix+" "+format, args...)
+		return
 	}
+	Verbosef(LevelCommands, strings.Trim(l.prefix, "[]"), format, args...)
 }
 
 func (l *envLogger) Info(format string, args ...interface{}) {
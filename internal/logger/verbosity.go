@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// Verbosity levels, set with -v, -vv, and -vvv. Each level shows everything
+// the ones below it do.
+const (
+	LevelPhases   = 1 // -v: what each phase decided and why
+	LevelCommands = 2 // -vv: the ssh, rsync, and remote commands rr runs
+	LevelWire     = 3 // -vvv: how long each SSH round trip took
+)
+
+// Sink receives verbose messages that passed the verbosity check.
+type Sink func(level int, subsystem, message string)
+
+var (
+	verbosity atomic.Int32
+
+	sinkMu sync.RWMutex
+	sink   Sink = stderrSink
+)
+
+// SetVerbosity sets how much detail Verbosef lets through. 0 turns verbose
+// output off.
+func SetVerbosity(level int) {
+	verbosity.Store(int32(level))
+}
+
+// Verbosity returns the current verbosity level.
+func Verbosity() int {
+	return int(verbosity.Load())
+}
+
+// V reports whether messages at level are shown. Use it to skip building
+// expensive messages that wouldn't be.
+func V(level int) bool {
+	return level > 0 && Verbosity() >= level
+}
+
+// SetSink routes verbose messages somewhere other than stderr, like
+// structured events or a log file. nil restores the default.
+func SetSink(s Sink) {
+	sinkMu.Lock()
+	defer sinkMu.Unlock()
+	if s == nil {
+		s = stderrSink
+	}
+	sink = s
+}
+
+// Verbosef reports a message from subsystem (like "sync" or "ssh") if the
+// verbosity is at least level.
+func Verbosef(level int, subsystem, format string, args ...interface{}) {
+	if !V(level) {
+		return
+	}
+	sinkMu.RLock()
+	s := sink
+	sinkMu.RUnlock()
+	s(level, subsystem, fmt.Sprintf(format, args...))
+}
+
+// stderrSink writes a message to stderr, prefixed with its subsystem.
+func stderrSink(_ int, subsystem, message string) {
+	fmt.Fprintf(os.Stderr, "[%s] %s\n", subsystem, message)
+}
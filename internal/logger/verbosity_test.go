@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type verboseMessage struct {
+	level     int
+	subsystem string
+	message   string
+}
+
+// captureVerbose sets the verbosity and collects what Verbosef reports
+// until the test ends.
+func captureVerbose(t *testing.T, level int) *[]verboseMessage {
+	t.Helper()
+	var got []verboseMessage
+	SetVerbosity(level)
+	SetSink(func(level int, subsystem, message string) {
+		got = append(got, verboseMessage{level, subsystem, message})
+	})
+	t.Cleanup(func() {
+		SetVerbosity(0)
+		SetSink(nil)
+	})
+	return &got
+}
+
+func TestVerbosef_Levels(t *testing.T) {
+	got := captureVerbose(t, LevelCommands)
+
+	Verbosef(LevelPhases, "sync", "skipped: %s", "daemon")
+	Verbosef(LevelCommands, "ssh", "exec %q", "ls")
+	Verbosef(LevelWire, "ssh", "round trip took %s", "3ms")
+
+	assert.Equal(t, []verboseMessage{
+		{LevelPhases, "sync", "skipped: daemon"},
+		{LevelCommands, "ssh", `exec "ls"`},
+	}, *got)
+}
+
+func TestV(t *testing.T) {
+	captureVerbose(t, 0)
+	assert.False(t, V(LevelPhases))
+
+	SetVerbosity(LevelWire)
+	assert.True(t, V(LevelPhases))
+	assert.True(t, V(LevelWire))
+	assert.False(t, V(0), "level 0 isn't verbose output")
+}
+
+func TestEnvLogger_DebugAtVerbosity(t *testing.T) {
+	os.Unsetenv("RR_DEBUG")
+	got := captureVerbose(t, LevelPhases)
+
+	l := NewEnvLogger("[lock]")
+	l.Debug("mkdir %s", "/tmp/rr.lock")
+	assert.Empty(t, *got, "debug output starts at -vv")
+
+	SetVerbosity(LevelCommands)
+	l.Debug("mkdir %s", "/tmp/rr.lock")
+	assert.Equal(t, []verboseMessage{{LevelCommands, "lock", "mkdir /tmp/rr.lock"}}, *got)
+}
@@ -101,24 +101,35 @@ func truncateWithEllipsis(s string, maxLen int) string {
 	return s
 }
 
-// renderCommandLine renders the command being executed, truncated to fit width.
-// Returns empty string if no command is available.
+// renderCommandLine renders who holds the host's lock and what they're
+// running, behind their avatar, truncated to fit width. Locks without a user
+// show just the command. Returns empty string if there's nothing to show.
 func (m Model) renderCommandLine(host string, width int) string {
 	lockInfo, ok := m.lockInfo[host]
-	if !ok || lockInfo == nil || lockInfo.Command == "" {
+	if !ok || lockInfo == nil {
 		return ""
 	}
+	cmdStyle := lipgloss.NewStyle().Foreground(ColorTextMuted)
 
-	// Truncate command to fit within available width
-	// Account for prefix "  > " (4 chars) and padding (2 chars)
-	maxCmdWidth := width - 6
-	if maxCmdWidth < 10 {
-		return "" // Too narrow to show command
+	if lockInfo.User == "" {
+		if lockInfo.Command == "" {
+			return ""
+		}
+		// Account for prefix "  > " (4 chars) and padding (2 chars)
+		maxCmdWidth := width - 6
+		if maxCmdWidth < 10 {
+			return "" // Too narrow to show command
+		}
+		return cmdStyle.Render("  > " + truncateWithEllipsis(lockInfo.Command, maxCmdWidth))
 	}
 
-	cmd := truncateWithEllipsis(lockInfo.Command, maxCmdWidth)
-	cmdStyle := lipgloss.NewStyle().Foreground(ColorTextMuted)
-	return cmdStyle.Render("  > " + cmd)
+	// Account for the indent, the avatar and the space after it, and padding
+	avatar := RenderAvatar(lockInfo.User)
+	maxWidth := width - 2 - lipgloss.Width(avatar) - 1 - 2
+	if maxWidth < 10 {
+		return "" // Too narrow to show activity
+	}
+	return "  " + avatar + " " + cmdStyle.Render(truncateWithEllipsis(lockInfo.Activity(), maxWidth))
 }
 
 // truncateErrorMsg extracts the most useful part of an error message and truncates to fit.
@@ -279,49 +290,44 @@ func (m Model) renderCard(host string, width int, selected bool) string {
 			lines = append(lines, renderCardLine("", innerWidth))
 		}
 	} else {
-		// Divider after host name
-		lines = append(lines, renderCardDivider(innerWidth))
+		// The sections the layout shows, in its order, each under a divider
+		for _, name := range m.shownCardSections() {
+			if sectionLines := m.renderCardSection(name, host, metrics, innerWidth); len(sectionLines) > 0 {
+				lines = append(lines, renderCardDivider(innerWidth))
+				lines = append(lines, sectionLines...)
+			}
+		}
+	}
 
-		// CPU metrics with braille graph
-		cpuLines := m.renderCardCPUSection(host, metrics.CPU, innerWidth)
-		lines = append(lines, cpuLines...)
+	content := strings.Join(lines, "\n")
+	return style.Render(content)
+}
 
-		// GPU metrics with braille graph (if available)
+// renderCardSection renders the named card section, or nothing if the host
+// has nothing to show in it, like the GPU section of a host without one.
+func (m Model) renderCardSection(name, host string, metrics *HostMetrics, lineWidth int) []string {
+	switch name {
+	case "cpu":
+		// CPU metrics with braille graph
+		return m.renderCardCPUSection(host, metrics.CPU, lineWidth)
+	case "gpu":
 		if metrics.GPU != nil {
-			lines = append(lines, renderCardDivider(innerWidth))
-			gpuLines := m.renderCardGPUSection(host, metrics.GPU, innerWidth)
-			lines = append(lines, gpuLines...)
+			return m.renderCardGPUSection(host, metrics.GPU, lineWidth)
 		}
-
-		// Latency metrics with braille graph (if available)
-		latencyLines := m.renderCardLatencySection(host, innerWidth)
-		if len(latencyLines) > 0 {
-			lines = append(lines, renderCardDivider(innerWidth))
-			lines = append(lines, latencyLines...)
-		}
-
-		// RAM metrics with braille graph
-		lines = append(lines, renderCardDivider(innerWidth))
-		ramLines := m.renderCardRAMSection(host, metrics.RAM, innerWidth)
-		lines = append(lines, ramLines...)
-
-		// Top process (with divider if present)
+	case "latency":
+		return m.renderCardLatencySection(host, lineWidth)
+	case "ram":
+		return m.renderCardRAMSection(host, metrics.RAM, lineWidth)
+	case "top":
 		if len(metrics.Processes) > 0 {
-			lines = append(lines, renderCardDivider(innerWidth))
-			topLine := m.renderCardTopProcess(metrics.Processes, innerWidth)
-			lines = append(lines, renderCardLine(topLine, innerWidth))
+			return []string{renderCardLine(m.renderCardTopProcess(metrics.Processes, lineWidth), lineWidth)}
 		}
-
-		// Network rates (with divider if present)
-		netLine := m.renderCardNetworkLine(host, innerWidth)
-		if netLine != "" {
-			lines = append(lines, renderCardDivider(innerWidth))
-			lines = append(lines, renderCardLine(netLine, innerWidth))
+	case "net":
+		if netLine := m.renderCardNetworkLine(host, lineWidth); netLine != "" {
+			return []string{renderCardLine(netLine, lineWidth)}
 		}
 	}
-
-	content := strings.Join(lines, "\n")
-	return style.Render(content)
+	return nil
 }
 
 // renderHostLine renders the host name with status indicator and status text.
@@ -746,36 +752,37 @@ func (m Model) renderCompactCard(host string, width int, selected bool) string {
 			}
 		}
 	} else {
-		lines = append(lines, renderCardDivider(innerWidth))
-
-		// CPU with single-row sparkline
-		cpuLines := m.renderCompactCPUSection(host, metrics.CPU, innerWidth)
-		lines = append(lines, cpuLines...)
-
-		// GPU with single-row sparkline (if available)
-		if metrics.GPU != nil {
-			lines = append(lines, renderCardDivider(innerWidth))
-			gpuLines := m.renderCompactGPUSection(host, metrics.GPU, innerWidth)
-			lines = append(lines, gpuLines...)
-		}
-
-		// Latency with single-row sparkline (if available)
-		latencyLines := m.renderCompactLatencySection(host, innerWidth)
-		if len(latencyLines) > 0 {
-			lines = append(lines, renderCardDivider(innerWidth))
-			lines = append(lines, latencyLines...)
+		for _, name := range m.shownCardSections() {
+			if sectionLines := m.renderCompactCardSection(name, host, metrics, innerWidth); len(sectionLines) > 0 {
+				lines = append(lines, renderCardDivider(innerWidth))
+				lines = append(lines, sectionLines...)
+			}
 		}
-
-		// RAM with single-row sparkline
-		lines = append(lines, renderCardDivider(innerWidth))
-		ramLines := m.renderCompactRAMSection(host, metrics.RAM, innerWidth)
-		lines = append(lines, ramLines...)
 	}
 
 	content := strings.Join(lines, "\n")
 	return style.Render(content)
 }
 
+// renderCompactCardSection renders the named section with a single-row
+// sparkline. Compact cards have no room for the top process or network
+// sections, so those render nothing.
+func (m Model) renderCompactCardSection(name, host string, metrics *HostMetrics, lineWidth int) []string {
+	switch name {
+	case "cpu":
+		return m.renderCompactCPUSection(host, metrics.CPU, lineWidth)
+	case "gpu":
+		if metrics.GPU != nil {
+			return m.renderCompactGPUSection(host, metrics.GPU, lineWidth)
+		}
+	case "latency":
+		return m.renderCompactLatencySection(host, lineWidth)
+	case "ram":
+		return m.renderCompactRAMSection(host, metrics.RAM, lineWidth)
+	}
+	return nil
+}
+
 // renderCompactCPUSection renders CPU with a single-row braille graph for compact mode.
 func (m Model) renderCompactCPUSection(host string, cpu CPUMetrics, lineWidth int) []string {
 	var lines []string
@@ -999,17 +1006,18 @@ func (m Model) renderMinimalCard(host string, width int, selected bool) string {
 		}
 		lines = append(lines, renderCardLine(LabelStyle.Render(placeholder), innerWidth))
 	} else {
-		lines = append(lines, renderCardDivider(innerWidth))
-
 		// CPU with single-row sparkline
-		cpuLines := m.renderMinimalCPUSection(host, metrics.CPU, innerWidth)
-		lines = append(lines, cpuLines...)
-
-		lines = append(lines, renderCardDivider(innerWidth))
+		if m.cardShows("cpu") {
+			lines = append(lines, renderCardDivider(innerWidth))
+			cpuLines := m.renderMinimalCPUSection(host, metrics.CPU, innerWidth)
+			lines = append(lines, cpuLines...)
+		}
 
 		// RAM as text only (keep it minimal)
-		metricsLine := m.renderMinimalMetricsLine(metrics, contentWidth)
-		lines = append(lines, renderCardLine(metricsLine, innerWidth))
+		if metricsLine := m.renderMinimalMetricsLine(metrics, contentWidth); metricsLine != "" {
+			lines = append(lines, renderCardDivider(innerWidth))
+			lines = append(lines, renderCardLine(metricsLine, innerWidth))
+		}
 	}
 
 	content := strings.Join(lines, "\n")
@@ -1049,45 +1057,50 @@ func (m Model) renderMinimalHostLine(host string, status HostStatus, maxWidth in
 	return indicatorStyle.Render(indicator) + " " + HostNameStyle.Render(displayHost)
 }
 
-// renderMinimalMetricsLine renders a single line with CPU, RAM, and optionally GPU percentages.
+// renderMinimalMetricsLine renders a single line with the CPU, RAM, and GPU
+// percentages the card layout shows. GPU only shows if the host has one.
+// Returns empty string if there's nothing to show.
 func (m Model) renderMinimalMetricsLine(metrics *HostMetrics, width int) string {
-	cpuPct := metrics.CPU.Percent
-
-	var ramPct float64
-	if metrics.RAM.TotalBytes > 0 {
-		ramPct = float64(metrics.RAM.UsedBytes) / float64(metrics.RAM.TotalBytes) * 100
+	type metric struct {
+		label string
+		text  string
 	}
-
-	cpuText := MetricStyle(cpuPct).Render(fmt.Sprintf("%.0f%%", cpuPct))
-	ramText := MetricStyle(ramPct).Render(fmt.Sprintf("%.0f%%", ramPct))
-
-	// Include GPU if available
-	hasGPU := metrics.GPU != nil
-	var gpuText string
-	if hasGPU {
-		gpuText = MetricStyle(metrics.GPU.Percent).Render(fmt.Sprintf("%.0f%%", metrics.GPU.Percent))
+	var parts []metric
+	for _, name := range []string{"cpu", "ram", "gpu"} {
+		if !m.cardShows(name) {
+			continue
+		}
+		switch name {
+		case "cpu":
+			cpuPct := metrics.CPU.Percent
+			parts = append(parts, metric{"CPU", MetricStyle(cpuPct).Render(fmt.Sprintf("%.0f%%", cpuPct))})
+		case "ram":
+			var ramPct float64
+			if metrics.RAM.TotalBytes > 0 {
+				ramPct = float64(metrics.RAM.UsedBytes) / float64(metrics.RAM.TotalBytes) * 100
+			}
+			parts = append(parts, metric{"RAM", MetricStyle(ramPct).Render(fmt.Sprintf("%.0f%%", ramPct))})
+		case "gpu":
+			if metrics.GPU != nil {
+				parts = append(parts, metric{"GPU", MetricStyle(metrics.GPU.Percent).Render(fmt.Sprintf("%.0f%%", metrics.GPU.Percent))})
+			}
+		}
+	}
+	if len(parts) == 0 {
+		return ""
 	}
 
-	// Choose format based on available width and GPU presence
-	if hasGPU {
-		if width >= 42 {
-			// Format: "CPU: 45%  RAM: 67%  GPU: 89%"
-			return fmt.Sprintf("%s %s  %s %s  %s %s",
-				LabelStyle.Render("CPU:"), cpuText,
-				LabelStyle.Render("RAM:"), ramText,
-				LabelStyle.Render("GPU:"), gpuText)
+	// Format: "CPU: 45%  RAM: 67%  GPU: 89%" when there's room (30 columns
+	// for two, 42 for three), else "C:45% R:67% G:89%"
+	var out []string
+	if width >= 12*len(parts)+6 {
+		for _, p := range parts {
+			out = append(out, LabelStyle.Render(p.label+":")+" "+p.text)
 		}
-		// Super compact format with GPU
-		return fmt.Sprintf("C:%s R:%s G:%s", cpuText, ramText, gpuText)
+		return strings.Join(out, "  ")
 	}
-
-	// No GPU - original format
-	if width >= 30 {
-		return fmt.Sprintf("%s %s  %s %s",
-			LabelStyle.Render("CPU:"), cpuText,
-			LabelStyle.Render("RAM:"), ramText)
+	for _, p := range parts {
+		out = append(out, p.label[:1]+":"+p.text)
 	}
-
-	// Super compact format
-	return fmt.Sprintf("C:%s R:%s", cpuText, ramText)
+	return strings.Join(out, " ")
 }
@@ -430,3 +430,22 @@ func TestCardConstants(t *testing.T) {
 	assert.Equal(t, 2, cardGraphHeight)
 	assert.Equal(t, 10, cardMinBarWidth)
 }
+
+func TestModel_renderCommandLine(t *testing.T) {
+	collector := NewCollector(map[string]config.Host{"server1": {SSH: []string{"server1"}}})
+	m := NewModel(collector, time.Second, 0, nil)
+
+	assert.Empty(t, m.renderCommandLine("server1", 60), "no lock, no line")
+
+	m.lockInfo["server1"] = &HostLockInfo{IsLocked: true, User: "alice.smith", Command: "pytest", Project: "app"}
+	line := m.renderCommandLine("server1", 80)
+	assert.Contains(t, line, " AS ")
+	assert.Contains(t, line, "alice.smith is running pytest on project app")
+
+	narrow := m.renderCommandLine("server1", 30)
+	assert.Contains(t, narrow, "...")
+
+	// Locks without a user show just the command
+	m.lockInfo["server1"] = &HostLockInfo{IsLocked: true, Command: "make test"}
+	assert.Contains(t, m.renderCommandLine("server1", 60), "> make test")
+}
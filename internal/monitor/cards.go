@@ -0,0 +1,175 @@
+package monitor
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/config"
+)
+
+// cardSection is one section of a host card and whether cards show it.
+type cardSection struct {
+	name  string
+	shown bool
+}
+
+// newCardSections returns the sections in cards, shown and in that order,
+// followed by the rest, hidden, in their default order. No cards shows
+// every section.
+func newCardSections(cards []string) []cardSection {
+	if len(cards) == 0 {
+		cards = config.MonitorCardSections
+	}
+	sections := make([]cardSection, 0, len(config.MonitorCardSections))
+	for _, name := range cards {
+		if slices.Contains(config.MonitorCardSections, name) {
+			sections = append(sections, cardSection{name: name, shown: true})
+		}
+	}
+	for _, name := range config.MonitorCardSections {
+		if !slices.Contains(cards, name) {
+			sections = append(sections, cardSection{name: name})
+		}
+	}
+	return sections
+}
+
+// cardSectionLabels are how the settings overlay names each section.
+var cardSectionLabels = map[string]string{
+	"cpu":     "CPU",
+	"gpu":     "GPU",
+	"latency": "Latency",
+	"ram":     "RAM",
+	"top":     "Top process",
+	"net":     "Network",
+}
+
+// SetCards sets the sections cards show, in order, as monitor.cards lists
+// them. Empty shows every section.
+func (m *Model) SetCards(cards []string) {
+	m.cardSections = newCardSections(cards)
+}
+
+// Cards returns the sections cards show, in order, and whether they were
+// changed in the settings overlay, so the caller can save them.
+func (m Model) Cards() ([]string, bool) {
+	return m.shownCardSections(), m.cardsChanged
+}
+
+// shownCardSections returns the names of the sections cards show, in order.
+func (m Model) shownCardSections() []string {
+	var names []string
+	for _, s := range m.cardSections {
+		if s.shown {
+			names = append(names, s.name)
+		}
+	}
+	return names
+}
+
+// cardShows reports whether cards show the named section.
+func (m Model) cardShows(name string) bool {
+	for _, s := range m.cardSections {
+		if s.name == name {
+			return s.shown
+		}
+	}
+	return false
+}
+
+// openCardSettings shows the card settings overlay, editing a copy of the
+// layout so esc can throw the edits away.
+func (m *Model) openCardSettings() {
+	m.cardDraft = slices.Clone(m.cardSections)
+	m.cardCursor = 0
+	m.showCardSettings = true
+}
+
+// handleCardSettingsKeys handles keys while the card settings overlay is
+// open: j/k move the cursor, space shows or hides a section, J/K move it up
+// or down the card, enter applies the layout, and esc or c closes it.
+func (m *Model) handleCardSettingsKeys(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, keys.Quit):
+		m.quitting = true
+		return tea.Quit
+	case key.Matches(msg, keys.CardUp):
+		m.moveCardSection(-1)
+	case key.Matches(msg, keys.CardDown):
+		m.moveCardSection(1)
+	case key.Matches(msg, keys.ScrollUp):
+		if m.cardCursor > 0 {
+			m.cardCursor--
+		}
+	case key.Matches(msg, keys.ScrollDown):
+		if m.cardCursor < len(m.cardDraft)-1 {
+			m.cardCursor++
+		}
+	case key.Matches(msg, keys.CardToggle):
+		m.cardDraft[m.cardCursor].shown = !m.cardDraft[m.cardCursor].shown
+	case key.Matches(msg, keys.Expand):
+		m.showCardSettings = false
+		if slices.Equal(m.cardDraft, m.cardSections) {
+			return nil
+		}
+		if !slices.ContainsFunc(m.cardDraft, func(s cardSection) bool { return s.shown }) {
+			m.notice = "Cards need at least one section"
+			return nil
+		}
+		m.cardSections = m.cardDraft
+		m.cardsChanged = true
+		m.notice = "Card layout changed - it's saved to monitor.cards when you quit"
+		m.updateListViewportContent()
+	case key.Matches(msg, keys.Collapse), key.Matches(msg, keys.CardSettings):
+		m.showCardSettings = false
+	}
+	return nil
+}
+
+// moveCardSection moves the section under the cursor by delta places,
+// taking the cursor with it.
+func (m *Model) moveCardSection(delta int) {
+	to := m.cardCursor + delta
+	if to < 0 || to >= len(m.cardDraft) {
+		return
+	}
+	m.cardDraft[m.cardCursor], m.cardDraft[to] = m.cardDraft[to], m.cardDraft[m.cardCursor]
+	m.cardCursor = to
+}
+
+// renderCardSettingsOverlay renders a centered box listing the card
+// sections, in order, with checkboxes for the ones cards show.
+func (m Model) renderCardSettingsOverlay() string {
+	var b strings.Builder
+	b.WriteString(helpTitleStyle.Render("Card Layout"))
+	b.WriteString("\n\n")
+
+	for i, s := range m.cardDraft {
+		box := "[ ]"
+		style := LabelStyle
+		if s.shown {
+			box = "[x]"
+			style = lipgloss.NewStyle().Foreground(ColorTextPrimary)
+		}
+		cursor := "  "
+		if i == m.cardCursor {
+			cursor = lipgloss.NewStyle().Foreground(ColorAccent).Render("> ")
+		}
+		b.WriteString(cursor + style.Render(box+" "+cardSectionLabels[s.name]) + "\n")
+	}
+
+	b.WriteString("\n" + LabelStyle.Render("space show/hide · J/K move · enter apply · esc cancel"))
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		helpBoxStyle.Render(b.String()),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(ColorDarkBg),
+	)
+}
@@ -0,0 +1,122 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCardsTestModel() Model {
+	collector := NewCollector(map[string]config.Host{"server1": {SSH: []string{"server1"}}})
+	m := NewModel(collector, time.Second, 0, nil)
+	m.width = 120
+	m.height = 40
+	m.metrics["server1"] = &HostMetrics{
+		CPU: CPUMetrics{Percent: 50.0},
+		RAM: RAMMetrics{UsedBytes: 4000000000, TotalBytes: 8000000000},
+		GPU: &GPUMetrics{Name: "RTX 4090", Percent: 80.0},
+		Processes: []ProcessInfo{
+			{PID: 1234, User: "root", CPU: 25.0, Memory: 10.0, Command: "/usr/bin/process"},
+		},
+	}
+	m.status["server1"] = StatusIdleState
+	return m
+}
+
+func TestNewCardSections(t *testing.T) {
+	all := newCardSections(nil)
+	assert.Len(t, all, len(config.MonitorCardSections))
+	for _, s := range all {
+		assert.True(t, s.shown, s.name)
+	}
+
+	sections := newCardSections([]string{"gpu", "cpu"})
+	assert.Equal(t, []cardSection{
+		{"gpu", true}, {"cpu", true},
+		{"latency", false}, {"ram", false}, {"top", false}, {"net", false},
+	}, sections)
+}
+
+func TestRenderCard_FollowsLayout(t *testing.T) {
+	m := newCardsTestModel()
+
+	card := m.renderCard("server1", 60, false)
+	assert.Less(t, strings.Index(card, "CPU"), strings.Index(card, "GPU"), "default order")
+	assert.Contains(t, card, "RAM")
+	assert.Contains(t, card, "TOP")
+
+	m.SetCards([]string{"gpu", "cpu"})
+	card = m.renderCard("server1", 60, false)
+	assert.Less(t, strings.Index(card, "GPU"), strings.Index(card, "CPU"), "GPU moved above CPU")
+	assert.NotContains(t, card, "RAM")
+	assert.NotContains(t, card, "TOP")
+
+	compact := m.renderCompactCard("server1", 80, false)
+	assert.Less(t, strings.Index(compact, "GPU"), strings.Index(compact, "CPU"))
+	assert.NotContains(t, compact, "RAM")
+
+	m.SetCards([]string{"ram"})
+	line := m.renderMinimalMetricsLine(m.metrics["server1"], 40)
+	assert.Contains(t, line, "RAM")
+	assert.NotContains(t, line, "CPU")
+	assert.NotContains(t, line, "GPU")
+
+	m.SetCards([]string{"top"})
+	assert.Empty(t, m.renderMinimalMetricsLine(m.metrics["server1"], 40))
+}
+
+func TestCardSettings_Keys(t *testing.T) {
+	m := newCardsTestModel()
+
+	m.HandleKeyMsg(keyPress("c"))
+	assert.True(t, m.showCardSettings)
+	assert.Contains(t, m.renderDashboard(), "Card Layout")
+
+	// Hide CPU, then move GPU to the top
+	m.HandleKeyMsg(tea.KeyMsg{Type: tea.KeySpace})
+	m.HandleKeyMsg(keyPress("j"))
+	m.HandleKeyMsg(keyPress("K"))
+	assert.Equal(t, 0, m.cardCursor, "cursor follows the moved section")
+
+	cards, changed := m.Cards()
+	assert.False(t, changed, "nothing changes until enter")
+	assert.Equal(t, config.MonitorCardSections, cards)
+
+	m.HandleKeyMsg(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.False(t, m.showCardSettings)
+	cards, changed = m.Cards()
+	assert.True(t, changed)
+	assert.Equal(t, []string{"gpu", "latency", "ram", "top", "net"}, cards)
+	assert.Contains(t, m.notice, "monitor.cards")
+}
+
+func TestCardSettings_EscDiscards(t *testing.T) {
+	m := newCardsTestModel()
+
+	m.HandleKeyMsg(keyPress("c"))
+	m.HandleKeyMsg(tea.KeyMsg{Type: tea.KeySpace})
+	m.HandleKeyMsg(tea.KeyMsg{Type: tea.KeyEsc})
+
+	assert.False(t, m.showCardSettings)
+	cards, changed := m.Cards()
+	assert.False(t, changed)
+	assert.Equal(t, config.MonitorCardSections, cards)
+}
+
+func TestCardSettings_KeepsOneSection(t *testing.T) {
+	m := newCardsTestModel()
+	m.SetCards([]string{"cpu"})
+
+	m.HandleKeyMsg(keyPress("c"))
+	m.HandleKeyMsg(tea.KeyMsg{Type: tea.KeySpace})
+	m.HandleKeyMsg(tea.KeyMsg{Type: tea.KeyEnter})
+
+	cards, changed := m.Cards()
+	assert.False(t, changed)
+	assert.Equal(t, []string{"cpu"}, cards)
+	assert.Contains(t, m.notice, "at least one section")
+}
@@ -4,6 +4,9 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"hash/fnv"
+	"io"
+	"math/rand/v2"
 	"regexp"
 	"strconv"
 	"strings"
@@ -12,6 +15,8 @@ import (
 
 	"github.com/rileyhilliard/rr/internal/config"
 	"github.com/rileyhilliard/rr/internal/lock"
+	"github.com/rileyhilliard/rr/internal/logger"
+	"github.com/rileyhilliard/rr/internal/watch"
 	"github.com/rileyhilliard/rr/pkg/sshutil"
 )
 
@@ -21,16 +26,32 @@ type cpuJiffies struct {
 	idle  int64
 }
 
+// DefaultConcurrency is how many hosts a collector collects from at once,
+// unless SetConcurrency says otherwise. The rest wait their turn, so a large
+// fleet doesn't open dozens of SSH sessions in the same instant.
+const DefaultConcurrency = 8
+
 // Collector gathers system metrics from multiple remote hosts.
 type Collector struct {
 	hosts       map[string]config.Host
 	pool        *Pool
 	timeout     time.Duration
 	prevJiffies map[string]cpuJiffies // Previous CPU jiffies per host for delta calculation
-	mu          sync.Mutex            // Protects prevJiffies
+	mu          sync.Mutex            // Protects prevJiffies and legacyHosts
+
+	// legacyHosts are hosts the collector script can't be installed on,
+	// which get the batched metrics command instead.
+	legacyHosts map[string]bool
 
 	// Lock checking configuration (optional)
 	lockConfig *config.LockConfig
+
+	// workers holds a slot for each collection running, bounding how many
+	// hosts are collected from at once.
+	workers chan struct{}
+	// inFlight are the hosts being collected from, which a new collection
+	// skips rather than piling a second one onto a slow host. Protected by mu.
+	inFlight map[string]bool
 }
 
 // NewCollector creates a new metrics collector for the specified hosts.
@@ -40,6 +61,9 @@ func NewCollector(hosts map[string]config.Host) *Collector {
 		pool:        NewPool(hosts, 10*time.Second),
 		timeout:     30 * time.Second,
 		prevJiffies: make(map[string]cpuJiffies),
+		legacyHosts: make(map[string]bool),
+		workers:     make(chan struct{}, DefaultConcurrency),
+		inFlight:    make(map[string]bool),
 	}
 }
 
@@ -54,6 +78,20 @@ func (c *Collector) SetTimeout(timeout time.Duration) {
 	c.timeout = timeout
 }
 
+// SetConcurrency sets how many hosts are collected from at once. Zero or
+// less means DefaultConcurrency. Call it before collecting.
+func (c *Collector) SetConcurrency(n int) {
+	if n <= 0 {
+		n = DefaultConcurrency
+	}
+	c.workers = make(chan struct{}, n)
+}
+
+// Concurrency returns how many hosts are collected from at once.
+func (c *Collector) Concurrency() int {
+	return cap(c.workers)
+}
+
 // Collect gathers metrics from all configured hosts in parallel.
 // Returns a map of alias -> metrics, a map of alias -> error message,
 // and a map of alias -> lock info.
@@ -70,6 +108,9 @@ func (c *Collector) Collect() (map[string]*HostMetrics, map[string]string, map[s
 		go func(alias string) {
 			defer wg.Done()
 
+			c.workers <- struct{}{}
+			defer func() { <-c.workers }()
+
 			ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
 			defer cancel()
 
@@ -122,7 +163,18 @@ func (c *Collector) CollectStreaming(ctx context.Context) <-chan HostResult {
 
 // CollectStreamingHosts collects metrics from only the specified hosts.
 // This is useful for implementing backoff - skip hosts that are in backoff period.
+// Hosts still being collected from by an earlier call are skipped too.
 func (c *Collector) CollectStreamingHosts(ctx context.Context, hostList []string) <-chan HostResult {
+	return c.CollectStreamingStaggered(ctx, hostList, 0)
+}
+
+// CollectStreamingStaggered is CollectStreamingHosts with each host's
+// collection starting at its own point in the first spread of time, so a
+// large fleet's refreshes are spread out rather than all landing at once.
+// A host's offset is the same every call, so it's still refreshed about
+// once per call, plus a little jitter so hosts that land close together
+// drift apart.
+func (c *Collector) CollectStreamingStaggered(ctx context.Context, hostList []string, spread time.Duration) <-chan HostResult {
 	results := make(chan HostResult, len(hostList))
 
 	if len(hostList) == 0 {
@@ -136,9 +188,20 @@ func (c *Collector) CollectStreamingHosts(ctx context.Context, hostList []string
 		if _, ok := c.hosts[alias]; !ok {
 			continue
 		}
+		if !c.startCollecting(alias) {
+			logger.Verbosef(logger.LevelPhases, "monitor", "%s: still collecting from the last refresh, skipping this one", alias)
+			continue
+		}
 		wg.Add(1)
 		go func(alias string) {
 			defer wg.Done()
+			defer c.doneCollecting(alias)
+
+			if err := c.waitTurn(ctx, staggerOffset(alias, spread, len(hostList))); err != nil {
+				results <- HostResult{Alias: alias, Error: err}
+				return
+			}
+			defer func() { <-c.workers }()
 
 			// Use per-host timeout, respecting parent context cancellation
 			hostCtx, cancel := context.WithTimeout(ctx, c.timeout)
@@ -175,27 +238,86 @@ func (c *Collector) CollectStreamingHosts(ctx context.Context, hostList []string
 	return results
 }
 
-// checkLockStatus checks if the rr lock is held on the specified host.
+// startCollecting marks alias as being collected from, unless it already is.
+func (c *Collector) startCollecting(alias string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.inFlight[alias] {
+		return false
+	}
+	c.inFlight[alias] = true
+	return true
+}
+
+func (c *Collector) doneCollecting(alias string) {
+	c.mu.Lock()
+	delete(c.inFlight, alias)
+	c.mu.Unlock()
+}
+
+// waitTurn waits out delay and then for a free worker, which the caller
+// gives back when it's done. Returns the context's error if it ends first.
+func (c *Collector) waitTurn(ctx context.Context, delay time.Duration) error {
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case c.workers <- struct{}{}:
+		return nil
+	}
+}
+
+// staggerOffset returns when in spread a host's collection starts: a fixed
+// point picked from its name, plus up to one host's share of spread at
+// random.
+func staggerOffset(alias string, spread time.Duration, hosts int) time.Duration {
+	if spread <= 0 {
+		return 0
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(alias))
+	offset := time.Duration(float64(spread) * float64(h.Sum32()) / (1 << 32))
+	if share := spread / time.Duration(max(hosts, 1)); share > 0 {
+		offset += rand.N(share)
+	}
+	return min(offset, spread)
+}
+
+// checkLockStatus checks if any rr lock is held on the specified host.
 // Returns lock info if locked, nil otherwise.
-// With per-host locking, there's a single lock at /tmp/rr.lock per host.
+// Locks live in the lock directory as rr.lock (host scope) or rr.lock.<...>
+// (project and command scope, see lock.Dir). Other projects may use a
+// different scope than this one, so every kind is checked. When several are
+// held, the longest-held one is reported along with the count.
 func (c *Collector) checkLockStatus(alias string) *HostLockInfo {
 	client, err := c.pool.Get(alias)
 	if err != nil {
 		return nil
 	}
 
-	// Check for the per-host lock at /tmp/rr.lock
 	baseDir := "/tmp"
 	if c.lockConfig != nil && c.lockConfig.Dir != "" {
 		baseDir = c.lockConfig.Dir
 	}
-	lockDir := baseDir + "/rr.lock"
 
-	// Check if lock directory exists and read its info
+	// Print the info.json of each lock directory, one per line
 	findCmd := fmt.Sprintf(
-		`if [ -d %q ] && [ -f %q/info.json ]; then cat %q/info.json; else exit 1; fi`,
-		lockDir, lockDir, lockDir,
+		`for d in %q %q.*; do if [ -f "$d/info.json" ]; then cat "$d/info.json"; echo; fi; done`,
+		baseDir+"/rr.lock", baseDir+"/rr.lock",
 	)
+	// Same environment as the lock itself, so a lock dir using host env resolves
+	findCmd = c.wrapHostCommand(alias, findCmd)
 
 	// Use embedded ssh.Client's NewSession directly
 	session, err := client.Client.NewSession()
@@ -204,14 +326,7 @@ func (c *Collector) checkLockStatus(alias string) *HostLockInfo {
 	}
 	defer session.Close()
 
-	output, err := session.Output(findCmd)
-	if err != nil {
-		// No locks found or error reading
-		return nil
-	}
-
-	// Parse the lock info
-	info, err := lock.ParseLockInfo(output)
+	output, err := session.Output(sshutil.WithCLocale(findCmd))
 	if err != nil {
 		return nil
 	}
@@ -221,16 +336,54 @@ func (c *Collector) checkLockStatus(alias string) *HostLockInfo {
 	if c.lockConfig != nil && c.lockConfig.Stale > 0 {
 		staleThreshold = c.lockConfig.Stale
 	}
-	if info.Age() > staleThreshold {
-		return nil // Stale locks don't count
+	return parseLockInfos(output, staleThreshold)
+}
+
+// parseLockInfos reads checkLockStatus output: one lock info JSON per line.
+// Stale and unreadable locks don't count. Returns nil when none are held.
+func parseLockInfos(output []byte, staleThreshold time.Duration) *HostLockInfo {
+	var held *HostLockInfo
+	count := 0
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		info, err := lock.ParseLockInfo([]byte(line))
+		if err != nil || info.Age() > staleThreshold {
+			continue
+		}
+		count++
+		if held == nil || info.Started.Before(held.Started) {
+			held = &HostLockInfo{
+				IsLocked: true,
+				Holder:   info.String(),
+				Started:  info.Started,
+				Command:  info.Command,
+				User:     info.User,
+				Project:  info.Project,
+			}
+		}
+	}
+	if held != nil {
+		held.Count = count
 	}
+	return held
+}
 
-	return &HostLockInfo{
-		IsLocked: true,
-		Holder:   info.String(),
-		Started:  info.Started,
-		Command:  info.Command,
+// wrapHostCommand applies a host's profile_files and env to a command.
+func (c *Collector) wrapHostCommand(alias, cmd string) string {
+	h, ok := c.hosts[alias]
+	if !ok {
+		return cmd
 	}
+	// The pool won't have connected to a host whose secrets don't resolve,
+	// so there's nothing to run the command on in that case
+	h, err := config.ResolveHostSecrets(alias, h)
+	if err != nil {
+		return cmd
+	}
+	return config.WrapHostCommand(&h, cmd)
 }
 
 // collectOneWithContext gathers metrics from a single host with context for timeout.
@@ -258,18 +411,96 @@ func (c *Collector) collectOneWithContext(ctx context.Context, alias string) (*H
 		probeLatency = 0
 	}
 
-	// Build and execute the batched metrics command
-	cmd := BuildMetricsCommand(platform)
+	collectStart := time.Now()
+	metrics, err := c.collectMetrics(ctx, alias, client, platform)
+	if metrics != nil {
+		metrics.Watchers = localWatchers(alias)
+	}
+	if err != nil {
+		logger.Verbosef(logger.LevelPhases, "monitor", "%s: collection failed: %v", alias, err)
+	} else {
+		logger.Verbosef(logger.LevelPhases, "monitor", "%s: collected in %s (latency %s)", alias, time.Since(collectStart).Round(time.Millisecond), probeLatency)
+	}
+	return metrics, probeLatency, err
+}
+
+// localWatchers returns the watch tasks this machine runs on a host. They're
+// read from rr's local state, so watchers started from other machines don't
+// show up.
+func localWatchers(hostName string) []WatcherMetrics {
+	var out []WatcherMetrics
+	for _, s := range watch.ForHost(hostName) {
+		out = append(out, WatcherMetrics{
+			Task:     s.Task,
+			Command:  s.Command,
+			Running:  s.Running,
+			Since:    s.Since,
+			Restarts: s.Restarts,
+			Ports:    s.Ports,
+		})
+	}
+	return out
+}
+
+// collectMetrics runs the collector script on a host, installing it first
+// if the host doesn't have this version. Hosts it can't be installed on get
+// the batched metrics command, which needs no install.
+func (c *Collector) collectMetrics(ctx context.Context, alias string, client *sshutil.Client, platform Platform) (*HostMetrics, error) {
+	c.mu.Lock()
+	legacy := c.legacyHosts[alias]
+	c.mu.Unlock()
+
+	// Host profile files and env put tools like nvidia-smi on PATH
+	if !legacy {
+		output, err := c.runCommand(ctx, alias, client, c.wrapHostCommand(alias, collectorRunCommand(c.reservationsDir())), nil)
+		if err == nil && isCollectorMissing(output) {
+			if _, err = c.runCommand(ctx, alias, client, collectorInstallCommand(), strings.NewReader(collectorScript)); err == nil {
+				output, err = c.runCommand(ctx, alias, client, c.wrapHostCommand(alias, collectorRunCommand(c.reservationsDir())), nil)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !isCollectorMissing(output) {
+			return c.parseCollectorOutput(alias, output)
+		}
+
+		// Installed, but the checksum still doesn't match: the host has no
+		// sha256sum or shasum, or can't write to ~/.rr.
+		logger.Verbosef(logger.LevelPhases, "monitor", "%s: collector script can't be installed, using the batched metrics command", alias)
+		c.mu.Lock()
+		c.legacyHosts[alias] = true
+		c.mu.Unlock()
+	}
+
+	output, err := c.runCommand(ctx, alias, client, c.wrapHostCommand(alias, BuildMetricsCommand(platform)), nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.parseOutput(alias, platform, string(output))
+}
 
+// runCommand runs a command in a new session on the client, in the C locale
+// so the parsers see dot decimals, feeding it stdin if set, and returns its
+// combined output. Canceling ctx closes the
+// session; failing to open one drops the host's pooled connection.
+func (c *Collector) runCommand(ctx context.Context, alias string, client *sshutil.Client, cmd string, stdin io.Reader) ([]byte, error) {
 	// Use embedded ssh.Client's NewSession directly for full session capabilities
 	session, err := client.Client.NewSession()
 	if err != nil {
 		c.pool.CloseOne(alias)
-		return nil, probeLatency, err
+		return nil, err
 	}
 	defer session.Close()
+	logger.Verbosef(logger.LevelCommands, "monitor", "%s: exec %s", alias, cmd)
+	start := time.Now()
+	defer func() {
+		logger.Verbosef(logger.LevelWire, "monitor", "%s: command finished after %s", alias, time.Since(start))
+	}()
+	if stdin != nil {
+		session.Stdin = stdin
+	}
 
-	// Run metrics command with timeout (we don't track this time since it's not network latency)
 	type result struct {
 		output []byte
 		err    error
@@ -277,20 +508,16 @@ func (c *Collector) collectOneWithContext(ctx context.Context, alias string) (*H
 	resultCh := make(chan result, 1)
 
 	go func() {
-		out, err := session.CombinedOutput(cmd)
+		out, err := session.CombinedOutput(sshutil.WithCLocale(cmd))
 		resultCh <- result{out, err}
 	}()
 
 	select {
 	case <-ctx.Done():
 		_ = session.Close()
-		return nil, probeLatency, ctx.Err()
+		return nil, ctx.Err()
 	case r := <-resultCh:
-		if r.err != nil {
-			return nil, probeLatency, r.err
-		}
-		metrics, err := c.parseOutput(alias, platform, string(r.output))
-		return metrics, probeLatency, err
+		return r.output, r.err
 	}
 }
 
@@ -348,7 +575,7 @@ func (c *Collector) parseOutput(alias string, platform Platform, output string)
 }
 
 // parseLinuxOutput parses Linux metrics from the batched command output.
-// Sections: 0=/proc/stat, 1=/proc/loadavg, 2=/proc/meminfo, 3=/proc/net/dev, 4=nvidia-smi, 5=ps aux
+// Sections: 0=/proc/stat, 1=/proc/loadavg, 2=/proc/meminfo, 3=/proc/net/dev, 4=nvidia-smi, 5=ps aux, 6=containers
 func (c *Collector) parseLinuxOutput(alias string, metrics *HostMetrics, sections []string) (*HostMetrics, error) {
 	if len(sections) >= 2 {
 		procStat := strings.TrimSpace(sections[0])
@@ -392,11 +619,15 @@ func (c *Collector) parseLinuxOutput(alias string, metrics *HostMetrics, section
 		}
 	}
 
+	if len(sections) >= 7 {
+		metrics.Containers = parseContainers(sections[6])
+	}
+
 	return metrics, nil
 }
 
 // parseDarwinOutput parses macOS metrics from the batched command output.
-// Sections: 0=top, 1=vm_stat, 2=netstat, 3=ioreg GPU, 4=ps aux
+// Sections: 0=top, 1=vm_stat, 2=netstat, 3=ioreg GPU, 4=ps aux, 5=containers
 func (c *Collector) parseDarwinOutput(metrics *HostMetrics, sections []string) (*HostMetrics, error) {
 	if len(sections) >= 1 {
 		topOutput := strings.TrimSpace(sections[0])
@@ -438,6 +669,10 @@ func (c *Collector) parseDarwinOutput(metrics *HostMetrics, sections []string) (
 		}
 	}
 
+	if len(sections) >= 6 {
+		metrics.Containers = parseContainers(sections[5])
+	}
+
 	return metrics, nil
 }
 
@@ -892,14 +1127,15 @@ func parseDarwinCPU(topOutput string) (*CPUMetrics, error) {
 }
 
 func parseDarwinCPUUsage(line string) float64 {
-	parts := strings.Split(line, ",")
+	// Values are split on ", " so a comma decimal ("84,21% idle") stays whole
+	parts := strings.Split(line, ", ")
 	for _, part := range parts {
 		part = strings.TrimSpace(part)
 		if strings.Contains(part, "idle") {
 			fields := strings.Fields(part)
 			if len(fields) >= 1 {
 				pctStr := strings.TrimSuffix(fields[0], "%")
-				idle, err := strconv.ParseFloat(pctStr, 64)
+				idle, err := parseDecimal(pctStr)
 				if err == nil {
 					return 100 - idle
 				}
@@ -918,10 +1154,10 @@ func parseDarwinLoadAvg(line string) [3]float64 {
 	}
 
 	valuesStr := strings.TrimSpace(line[colonIdx+1:])
-	parts := strings.Split(valuesStr, ",")
+	parts := strings.Split(valuesStr, ", ")
 
 	for i := 0; i < 3 && i < len(parts); i++ {
-		val, err := strconv.ParseFloat(strings.TrimSpace(parts[i]), 64)
+		val, err := parseDecimal(strings.TrimSpace(parts[i]))
 		if err == nil {
 			loadAvg[i] = val
 		}
@@ -930,6 +1166,17 @@ func parseDarwinLoadAvg(line string) [3]float64 {
 	return loadAvg
 }
 
+// parseDecimal parses a number that may use a comma as its decimal
+// separator ("1,5"), as tools print it in many locales. rr runs its commands
+// in the C locale, but a host whose shell startup files set LC_ALL
+// themselves can still print localized numbers.
+func parseDecimal(s string) (float64, error) {
+	if strings.Count(s, ",") == 1 && !strings.Contains(s, ".") {
+		s = strings.Replace(s, ",", ".", 1)
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
 // parseDarwinMemory parses memory metrics from macOS vm_stat and sysctl hw.memsize output.
 func parseDarwinMemory(vmStatOutput string) (*RAMMetrics, error) {
 	metrics := &RAMMetrics{}
@@ -1107,10 +1354,12 @@ func parseDarwinNetwork(netstatOutput string) ([]NetworkInterface, error) {
 }
 
 // parseProcesses parses ps aux output into a slice of ProcessInfo.
-// Works for both Linux and macOS ps aux output formats.
+// Works for both Linux and macOS ps aux output formats. A process listed
+// twice (the busiest by CPU and by memory) is kept once, where it first appears.
 // ps aux columns: USER PID %CPU %MEM VSZ RSS TTY STAT START TIME COMMAND
 func parseProcesses(output string) ([]ProcessInfo, error) {
 	var procs []ProcessInfo
+	seen := make(map[int]bool)
 	scanner := bufio.NewScanner(strings.NewReader(output))
 
 	// Skip header line (USER PID %CPU %MEM ...)
@@ -1124,16 +1373,17 @@ func parseProcesses(output string) ([]ProcessInfo, error) {
 		}
 
 		pid, err := strconv.Atoi(fields[1])
-		if err != nil {
+		if err != nil || seen[pid] {
 			continue
 		}
+		seen[pid] = true
 
-		cpu, err := strconv.ParseFloat(fields[2], 64)
+		cpu, err := parseDecimal(fields[2])
 		if err != nil {
 			cpu = 0
 		}
 
-		mem, err := strconv.ParseFloat(fields[3], 64)
+		mem, err := parseDecimal(fields[3])
 		if err != nil {
 			mem = 0
 		}
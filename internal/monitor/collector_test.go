@@ -1,6 +1,7 @@
 package monitor
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -185,6 +186,59 @@ root                 1   0.0  0.1  5134736  21456   ??  Ss   Mon09AM  12:34.56 /
 	assert.Equal(t, int64(635551744), result.GPU.MemoryTotal)
 }
 
+func TestParseDarwinOutput_LocalizedNumbers(t *testing.T) {
+	collector := &Collector{}
+	metrics := &HostMetrics{}
+
+	// top and ps under a de_DE locale print comma decimals
+	topOutput := `Load Avg: 2,45, 3,12, 3,56
+CPU usage: 5,26% user, 10,52% sys, 84,21% idle`
+
+	vmStatOutput := `Mach Virtual Memory Statistics: (page size of 16384 bytes)
+Pages free:                              123456.`
+
+	netstatOutput := `Name  Mtu   Network       Address            Ipkts Ierrs     Ibytes    Opkts Oerrs     Obytes  Coll`
+
+	psOutput := `USER               PID  %CPU %MEM      VSZ    RSS   TT  STAT STARTED      TIME COMMAND
+root                 1  12,5  0,1  5134736  21456   ??  Ss   Mon09AM  12:34.56 /sbin/launchd`
+
+	sections := []string{topOutput, vmStatOutput, netstatOutput, "", psOutput}
+
+	result, err := collector.parseDarwinOutput(metrics, sections)
+	require.NoError(t, err)
+
+	assert.InDelta(t, 15.79, result.CPU.Percent, 0.01)
+	assert.Equal(t, [3]float64{2.45, 3.12, 3.56}, result.CPU.LoadAvg)
+	require.Len(t, result.Processes, 1)
+	assert.InDelta(t, 12.5, result.Processes[0].CPU, 0.01)
+	assert.InDelta(t, 0.1, result.Processes[0].Memory, 0.01)
+}
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+		ok   bool
+	}{
+		{"1.5", 1.5, true},
+		{"1,5", 1.5, true},
+		{"12", 12, true},
+		{"1,234.5", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseDecimal(tt.in)
+			if !tt.ok {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want, got, 0.001)
+		})
+	}
+}
+
 func TestParseDarwinOutput_NoGPU(t *testing.T) {
 	collector := &Collector{}
 	metrics := &HostMetrics{}
@@ -589,6 +643,73 @@ func TestCollector_SetTimeout(t *testing.T) {
 	assert.Equal(t, 5*time.Second, c.timeout)
 }
 
+func TestCollector_SetConcurrency(t *testing.T) {
+	c := NewCollector(map[string]config.Host{})
+	assert.Equal(t, DefaultConcurrency, c.Concurrency())
+
+	c.SetConcurrency(3)
+	assert.Equal(t, 3, c.Concurrency())
+
+	c.SetConcurrency(0)
+	assert.Equal(t, DefaultConcurrency, c.Concurrency())
+}
+
+func TestCollector_waitTurnBoundsWorkers(t *testing.T) {
+	c := NewCollector(map[string]config.Host{})
+	c.SetConcurrency(2)
+
+	require.NoError(t, c.waitTurn(context.Background(), 0))
+	require.NoError(t, c.waitTurn(context.Background(), 0))
+
+	// Both workers are busy, so a third waits until its context ends
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, c.waitTurn(ctx, 0), context.DeadlineExceeded)
+
+	// Once one is given back, the next gets it
+	<-c.workers
+	assert.NoError(t, c.waitTurn(context.Background(), 0))
+}
+
+func TestCollector_SkipsHostsInFlight(t *testing.T) {
+	hosts := map[string]config.Host{
+		"slow": {SSH: []string{"slow"}},
+		"fast": {SSH: []string{"fast"}},
+	}
+	c := NewCollector(hosts)
+	require.True(t, c.startCollecting("slow"))
+
+	// A context that's already done reports every host it collects from as
+	// failed, without connecting
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var got []string
+	for result := range c.CollectStreamingHosts(ctx, []string{"slow", "fast"}) {
+		got = append(got, result.Alias)
+		assert.ErrorIs(t, result.Error, context.Canceled)
+	}
+	assert.Equal(t, []string{"fast"}, got, "slow is still being collected from")
+
+	c.doneCollecting("slow")
+	assert.True(t, c.startCollecting("slow"))
+}
+
+func TestStaggerOffset(t *testing.T) {
+	assert.Zero(t, staggerOffset("a", 0, 10))
+
+	spread := 10 * time.Second
+	for _, alias := range []string{"a", "b", "gpu-box-17", "mini"} {
+		offset := staggerOffset(alias, spread, 40)
+		assert.GreaterOrEqual(t, offset, time.Duration(0))
+		assert.LessOrEqual(t, offset, spread)
+
+		// The fixed part doesn't move; only one host's share of jitter does
+		again := staggerOffset(alias, spread, 40)
+		assert.InDelta(t, float64(offset), float64(again), float64(spread/40))
+	}
+}
+
 func TestCollector_parseOutput(t *testing.T) {
 	c := NewCollector(map[string]config.Host{})
 
@@ -609,3 +730,38 @@ func TestCollector_parseOutput(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, result)
 }
+
+func TestParseLockInfos(t *testing.T) {
+	now := time.Now()
+	older := now.Add(-5 * time.Minute).UTC().Format(time.RFC3339Nano)
+	newer := now.Add(-1 * time.Minute).UTC().Format(time.RFC3339Nano)
+	stale := now.Add(-2 * time.Hour).UTC().Format(time.RFC3339Nano)
+
+	output := `{"user":"a","hostname":"mac","started":"` + newer + `","pid":1,"command":"make lint"}
+{"user":"b","hostname":"mbp","started":"` + older + `","pid":2,"command":"make test","project":"app"}
+{"user":"c","hostname":"old","started":"` + stale + `","pid":3}
+not json
+`
+	info := parseLockInfos([]byte(output), 30*time.Minute)
+	require.NotNil(t, info)
+	assert.True(t, info.IsLocked)
+	assert.Equal(t, "make test", info.Command, "longest-held lock is reported")
+	assert.Equal(t, "b@mbp (pid 2)", info.Holder)
+	assert.Equal(t, "b is running make test on project app", info.Activity())
+	assert.Equal(t, 2, info.Count)
+
+	assert.Nil(t, parseLockInfos([]byte(""), 30*time.Minute))
+}
+
+func TestParseProcesses_SkipsDuplicates(t *testing.T) {
+	// Top by CPU, then top by memory without a header; PID 42 is in both
+	output := `USER       PID %CPU %MEM    VSZ   RSS TTY      STAT START   TIME COMMAND
+me          42 90.0 30.0 100000 50000 ?        R    10:00   5:00 python train.py
+me          43 10.0  1.0  10000  5000 ?        S    10:00   0:10 node server.js
+me          42 90.0 30.0 100000 50000 ?        R    10:00   5:00 python train.py
+root        44  0.0 20.0 200000 90000 ?        S    09:00   0:01 postgres`
+
+	procs, err := parseProcesses(output)
+	require.NoError(t, err)
+	assert.Equal(t, []int{42, 43, 44}, pids(procs))
+}
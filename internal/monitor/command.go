@@ -36,9 +36,10 @@ func BuildMetricsCommand(platform Platform) string {
 // 2. /proc/meminfo - Memory information
 // 3. /proc/net/dev - Network interface statistics
 // 4. nvidia-smi output - GPU metrics (optional, fails silently if not available)
-// 5. ps aux - Process list sorted by CPU (top 16 including header)
+// 5. ps aux - Process list: top 15 by CPU (after the header), then top 15 by memory
+// 6. Virtualization type and running containers (docker, podman, lxc)
 func buildLinuxCommand() string {
-	return `cat /proc/stat; echo "---"; cat /proc/loadavg; echo "---"; cat /proc/meminfo; echo "---"; cat /proc/net/dev; echo "---"; nvidia-smi --query-gpu=name,utilization.gpu,memory.used,memory.total,temperature.gpu,power.draw --format=csv,noheader,nounits 2>/dev/null || true; echo "---"; ps aux --sort=-%cpu 2>/dev/null | head -16 || ps aux 2>/dev/null | head -16`
+	return `cat /proc/stat; echo "---"; cat /proc/loadavg; echo "---"; cat /proc/meminfo; echo "---"; cat /proc/net/dev; echo "---"; nvidia-smi --query-gpu=name,utilization.gpu,memory.used,memory.total,temperature.gpu,power.draw --format=csv,noheader,nounits 2>/dev/null || true; echo "---"; ps aux --sort=-%cpu 2>/dev/null | head -16 || ps aux 2>/dev/null | head -16; ps aux --sort=-%mem 2>/dev/null | sed 1d | head -15; echo "---"; ` + linuxContainerCommand
 }
 
 // buildDarwinCommand returns the batched metrics command for macOS hosts.
@@ -47,9 +48,10 @@ func buildLinuxCommand() string {
 // 1. vm_stat + sysctl hw.memsize - Memory statistics with total memory
 // 2. netstat output - Network interface statistics
 // 3. ioreg GPU output - Apple Silicon GPU metrics (optional, fails silently)
-// 4. ps aux - Process list sorted by CPU (top 16 including header)
+// 4. ps aux - Process list: top 15 by CPU (after the header), then top 15 by memory
+// 5. Running containers (docker, podman)
 func buildDarwinCommand() string {
-	return `top -l 1 -n 0 2>/dev/null; echo "---"; vm_stat; sysctl hw.memsize 2>/dev/null; echo "---"; netstat -ib; echo "---"; ioreg -r -c AGXAccelerator 2>/dev/null | grep -E '"(model|gpu-core-count|PerformanceStatistics)"' || true; echo "---"; ps aux -r 2>/dev/null | head -16`
+	return `top -l 1 -n 0 2>/dev/null; echo "---"; vm_stat; sysctl hw.memsize 2>/dev/null; echo "---"; netstat -ib; echo "---"; ioreg -r -c AGXAccelerator 2>/dev/null | grep -E '"(model|gpu-core-count|PerformanceStatistics)"' || true; echo "---"; ps aux -r 2>/dev/null | head -16; ps aux -m 2>/dev/null | sed 1d | head -15; echo "---"; ` + darwinContainerCommand
 }
 
 // PlatformDetectCommand returns the command to detect the platform type.
@@ -112,17 +112,17 @@ func TestBuildLinuxCommand_SectionCount(t *testing.T) {
 	cmd := BuildMetricsCommand(PlatformLinux)
 
 	// Count the number of sections by counting separators
-	// Linux command should have 5 separators (6 sections)
+	// Linux command should have 6 separators (7 sections)
 	separatorCount := strings.Count(cmd, `echo "---"`)
-	assert.Equal(t, 5, separatorCount, "Linux command should have 5 separators for 6 sections")
+	assert.Equal(t, 6, separatorCount, "Linux command should have 6 separators for 7 sections")
 }
 
 func TestBuildDarwinCommand_SectionCount(t *testing.T) {
 	cmd := BuildMetricsCommand(PlatformDarwin)
 
-	// Darwin command should have 4 separators (5 sections: top, vm_stat, netstat, ioreg GPU, ps)
+	// Darwin command should have 5 separators (6 sections: top, vm_stat, netstat, ioreg GPU, ps, containers)
 	separatorCount := strings.Count(cmd, `echo "---"`)
-	assert.Equal(t, 4, separatorCount, "Darwin command should have 4 separators for 5 sections")
+	assert.Equal(t, 5, separatorCount, "Darwin command should have 5 separators for 6 sections")
 }
 
 func TestBuildMetricsCommand_GracefulGPUFailure(t *testing.T) {
@@ -133,6 +133,18 @@ func TestBuildMetricsCommand_GracefulGPUFailure(t *testing.T) {
 	assert.Contains(t, cmd, "2>/dev/null || true")
 }
 
+func TestBuildMetricsCommand_Containers(t *testing.T) {
+	linux := BuildMetricsCommand(PlatformLinux)
+	assert.Contains(t, linux, "systemd-detect-virt")
+	assert.Contains(t, linux, "docker stats --no-stream")
+	assert.Contains(t, linux, "podman stats --no-stream")
+	assert.Contains(t, linux, "lxc-ls --running")
+
+	darwin := BuildMetricsCommand(PlatformDarwin)
+	assert.Contains(t, darwin, "docker stats --no-stream")
+	assert.NotContains(t, darwin, "systemd-detect-virt")
+}
+
 func TestBuildMetricsCommand_ProcessLimit(t *testing.T) {
 	cmd := BuildMetricsCommand(PlatformLinux)
 
@@ -0,0 +1,146 @@
+package monitor
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// containerStatsFormat is the Go template passed to docker/podman stats.
+// Fields are pipe-separated: name, CPU percent, memory usage ("12MiB / 2GiB").
+const containerStatsFormat = `'{{.Name}}|{{.CPUPerc}}|{{.MemUsage}}'`
+
+// linuxContainerCommand reports the host's virtualization type and running
+// containers for each detected runtime. Every line is prefixed with its source
+// so one section can carry all of them. Runtimes that aren't installed, or that
+// the user can't query, are skipped silently.
+const linuxContainerCommand = `echo "virt $(systemd-detect-virt 2>/dev/null || echo none)"; ` +
+	`command -v docker >/dev/null 2>&1 && timeout 5 docker stats --no-stream --format ` + containerStatsFormat + ` 2>/dev/null | sed 's/^/docker /'; ` +
+	`command -v podman >/dev/null 2>&1 && timeout 5 podman stats --no-stream --format ` + containerStatsFormat + ` 2>/dev/null | sed 's/^/podman /'; ` +
+	`command -v lxc-ls >/dev/null 2>&1 && lxc-ls --running -1 2>/dev/null | sed 's/^/lxc /'; true`
+
+// darwinTimeout runs the command after it for at most 5 seconds. macOS has
+// no timeout command, but it ships perl, and the alarm outlives the exec.
+const darwinTimeout = `perl -e 'alarm shift; exec @ARGV' 5`
+
+// darwinContainerCommand reports running containers on macOS hosts, where
+// Docker Desktop, Podman machine, and similar run inside a Linux VM. The
+// stats are cut off like on Linux, since a stopped VM can leave them
+// hanging.
+const darwinContainerCommand = `command -v docker >/dev/null 2>&1 && ` + darwinTimeout + ` docker stats --no-stream --format ` + containerStatsFormat + ` 2>/dev/null | sed 's/^/docker /'; ` +
+	`command -v podman >/dev/null 2>&1 && ` + darwinTimeout + ` podman stats --no-stream --format ` + containerStatsFormat + ` 2>/dev/null | sed 's/^/podman /'; true`
+
+// parseContainers parses the container section of the metrics output.
+// Returns nil when the host isn't virtualized and has no running containers,
+// so the detail view can skip the section entirely.
+func parseContainers(output string) *ContainerMetrics {
+	cm := &ContainerMetrics{}
+	runtimes := make(map[string]bool)
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if virt, ok := strings.CutPrefix(line, "virt "); ok {
+			virt = strings.TrimSpace(virt)
+			if virt != "none" && virt != "" {
+				cm.Virtualization = virt
+			}
+			continue
+		}
+
+		runtime, rest, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		fields := strings.Split(rest, "|")
+		name := strings.TrimSpace(fields[0])
+		if name == "" {
+			continue
+		}
+
+		info := ContainerInfo{
+			Runtime: runtime,
+			Name:    name,
+		}
+		if len(fields) >= 2 {
+			info.CPUPercent = parseContainerPercent(fields[1])
+		}
+		if len(fields) >= 3 {
+			info.MemoryBytes = parseContainerMemory(fields[2])
+		}
+
+		runtimes[runtime] = true
+		cm.Containers = append(cm.Containers, info)
+		cm.CPUPercent += info.CPUPercent
+		cm.MemoryBytes += info.MemoryBytes
+	}
+
+	if cm.Virtualization == "" && len(cm.Containers) == 0 {
+		return nil
+	}
+
+	for r := range runtimes {
+		cm.Runtimes = append(cm.Runtimes, r)
+	}
+	sort.Strings(cm.Runtimes)
+
+	// Busiest first, using memory as the tie-breaker so idle containers still rank
+	sort.SliceStable(cm.Containers, func(i, j int) bool {
+		a, b := cm.Containers[i], cm.Containers[j]
+		if a.CPUPercent != b.CPUPercent {
+			return a.CPUPercent > b.CPUPercent
+		}
+		return a.MemoryBytes > b.MemoryBytes
+	})
+
+	return cm
+}
+
+// parseContainerPercent parses a percentage like "12.34%".
+func parseContainerPercent(s string) float64 {
+	s = strings.TrimSuffix(strings.TrimSpace(s), "%")
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// containerMemoryUnits maps docker/podman memory suffixes to byte multipliers.
+// Ordered longest-first so "MiB" is matched before "B".
+var containerMemoryUnits = []struct {
+	suffix string
+	mult   float64
+}{
+	{"KiB", 1 << 10},
+	{"MiB", 1 << 20},
+	{"GiB", 1 << 30},
+	{"TiB", 1 << 40},
+	{"kB", 1e3},
+	{"KB", 1e3},
+	{"MB", 1e6},
+	{"GB", 1e9},
+	{"TB", 1e12},
+	{"B", 1},
+}
+
+// parseContainerMemory parses the used half of a memory usage string like
+// "12.5MiB / 1.944GiB" into bytes.
+func parseContainerMemory(s string) int64 {
+	used, _, _ := strings.Cut(s, "/")
+	used = strings.TrimSpace(used)
+
+	for _, u := range containerMemoryUnits {
+		if num, ok := strings.CutSuffix(used, u.suffix); ok {
+			v, err := strconv.ParseFloat(strings.TrimSpace(num), 64)
+			if err != nil {
+				return 0
+			}
+			return int64(v * u.mult)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,90 @@
+package monitor
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseContainers(t *testing.T) {
+	output := `virt kvm
+docker web|1.50%|120MiB / 1.944GiB
+docker worker|45.25%|1.5GiB / 1.944GiB
+podman db|3.00%|512MB / 8GB
+lxc builder`
+
+	cm := parseContainers(output)
+	require.NotNil(t, cm)
+
+	assert.Equal(t, "kvm", cm.Virtualization)
+	assert.Equal(t, 4, cm.Count())
+	assert.Equal(t, []string{"docker", "lxc", "podman"}, cm.Runtimes)
+	assert.InDelta(t, 49.75, cm.CPUPercent, 0.001)
+	assert.Equal(t, int64(120<<20+1.5*(1<<30)+512e6), cm.MemoryBytes)
+
+	top := cm.Top()
+	require.NotNil(t, top)
+	assert.Equal(t, "worker", top.Name)
+	assert.Equal(t, "docker", top.Runtime)
+
+	// lxc has no stats, so it sorts last
+	assert.Equal(t, "builder", cm.Containers[3].Name)
+	assert.Equal(t, int64(0), cm.Containers[3].MemoryBytes)
+}
+
+func TestParseContainers_BareMetalNoContainers(t *testing.T) {
+	assert.Nil(t, parseContainers("virt none\n"))
+	assert.Nil(t, parseContainers(""))
+}
+
+func TestParseContainers_VirtualizedNoContainers(t *testing.T) {
+	cm := parseContainers("virt vmware\n")
+	require.NotNil(t, cm)
+	assert.Equal(t, "vmware", cm.Virtualization)
+	assert.Equal(t, 0, cm.Count())
+	assert.Nil(t, cm.Top())
+}
+
+func TestParseContainerMemory(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect int64
+	}{
+		{"12MiB / 1.944GiB", 12 << 20},
+		{"1.5GiB / 8GiB", 3 << 29},
+		{"512kB / 1GB", 512000},
+		{"100B / 1GB", 100},
+		{"2GB / 8GB", 2e9},
+		{"--", 0},
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.expect, parseContainerMemory(tt.input))
+		})
+	}
+}
+
+func TestContainerMetrics_NilSafe(t *testing.T) {
+	var cm *ContainerMetrics
+	assert.Equal(t, 0, cm.Count())
+	assert.Nil(t, cm.Top())
+}
+
+func TestDarwinTimeout_CutsOffHungCommands(t *testing.T) {
+	if _, err := exec.LookPath("perl"); err != nil {
+		t.Skip("perl not installed")
+	}
+	assert.Contains(t, darwinContainerCommand, darwinTimeout+" docker stats")
+	assert.Contains(t, darwinContainerCommand, darwinTimeout+" podman stats")
+
+	start := time.Now()
+	out, _ := exec.Command("sh", "-c", strings.Replace(darwinTimeout, " 5", " 1", 1)+" sh -c 'echo started; exec sleep 30'").Output()
+	assert.Less(t, time.Since(start), 10*time.Second, "the alarm should stop the command")
+	assert.Equal(t, "started\n", string(out))
+}
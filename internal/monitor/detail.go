@@ -2,7 +2,6 @@ package monitor
 
 import (
 	"fmt"
-	"sort"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
@@ -13,11 +12,8 @@ var (
 	detailContainerStyle = lipgloss.NewStyle().
 				Padding(1, 2)
 
-	detailSectionStyle = lipgloss.NewStyle().
-				Border(lipgloss.RoundedBorder()).
-				BorderForeground(ColorBorder).
-				Padding(0, 1).
-				MarginBottom(1)
+	// detailSectionStyle depends on the palette; see buildStyles
+	detailSectionStyle lipgloss.Style
 )
 
 // ProcSortOrder determines how processes are sorted in the table.
@@ -65,11 +61,15 @@ func (m Model) renderDetailHeader(host string, status HostStatus) string {
 
 	// Add command line for running hosts
 	if status == StatusRunningState {
-		if lockInfo, ok := m.lockInfo[host]; ok && lockInfo != nil && lockInfo.Command != "" {
+		if lockInfo, ok := m.lockInfo[host]; ok && lockInfo != nil {
 			cmdStyle := lipgloss.NewStyle().Foreground(ColorTextMuted)
-			// Show full command in detail view (more space available)
-			cmdLine := cmdStyle.Render("> " + lockInfo.Command)
-			return headerLine + "\n" + cmdLine
+			if lockInfo.User != "" {
+				// Full badge in detail view (more space available)
+				return headerLine + "\n" + RenderAvatar(lockInfo.User) + " " + cmdStyle.Render(lockInfo.Badge())
+			}
+			if lockInfo.Command != "" {
+				return headerLine + "\n" + cmdStyle.Render("> "+lockInfo.Command)
+			}
 		}
 	}
 
@@ -487,33 +487,21 @@ func (m Model) renderDetailNetworkSection(host string, width int) string {
 }
 
 // renderDetailProcessSection renders the process table with consistent styling.
+// Collapsed, it shows the top few processes; expanded (p), it shows more and
+// highlights the selected row.
 func (m Model) renderDetailProcessSection(procs []ProcessInfo, width int) string {
 	var lines []string
 
 	// Section header
-	lines = append(lines, SectionHeader("Processes", "by CPU", width))
-
-	// Sort by CPU (already should be, but ensure)
-	sorted := make([]ProcessInfo, len(procs))
-	copy(sorted, procs)
-	sort.Slice(sorted, func(i, j int) bool {
-		return sorted[i].CPU > sorted[j].CPU
-	})
+	lines = append(lines, SectionHeader("Processes", "by "+m.procSort.String(), width))
 
 	// Table header
 	headerStyle := lipgloss.NewStyle().Foreground(ColorTextMuted)
-	header := fmt.Sprintf("%-6s %-10s %6s %6s  %s", "PID", "USER", "CPU%", "MEM%", "COMMAND")
+	header := fmt.Sprintf("  %-6s %-10s %6s %6s  %s", "PID", "USER", "CPU%", "MEM%", "COMMAND")
 	lines = append(lines, SectionContentLine(headerStyle.Render(header), width))
 
-	// Show up to 5 processes (reduced from 10 for cleaner layout)
-	maxProcs := 5
-	if len(sorted) < maxProcs {
-		maxProcs = len(sorted)
-	}
-
-	for i := 0; i < maxProcs; i++ {
-		proc := sorted[i]
-
+	selectedStyle := lipgloss.NewStyle().Foreground(ColorAccent).Bold(true)
+	for _, proc := range m.processRows(procs) {
 		// Truncate user and command
 		user := proc.User
 		if len(user) > 10 {
@@ -521,7 +509,7 @@ func (m Model) renderDetailProcessSection(procs []ProcessInfo, width int) string
 		}
 
 		cmd := proc.Command
-		cmdWidth := width - 40
+		cmdWidth := width - 42
 		if cmdWidth < 10 {
 			cmdWidth = 10
 		}
@@ -535,9 +523,17 @@ func (m Model) renderDetailProcessSection(procs []ProcessInfo, width int) string
 		cpuStyle := lipgloss.NewStyle().Foreground(cpuColor)
 		memStyle := lipgloss.NewStyle().Foreground(memColor)
 
-		line := fmt.Sprintf("%-6d %-10s %s %s  %s",
-			proc.PID,
-			user,
+		marker := "  "
+		pidUser := fmt.Sprintf("%-6d %-10s", proc.PID, user)
+		if m.procsExpanded && proc.PID == m.procSelected {
+			marker = selectedStyle.Render("▸ ")
+			pidUser = selectedStyle.Render(pidUser)
+			cmd = selectedStyle.Render(cmd)
+		}
+
+		line := fmt.Sprintf("%s%s %s %s  %s",
+			marker,
+			pidUser,
 			cpuStyle.Render(fmt.Sprintf("%5.1f%%", proc.CPU)),
 			memStyle.Render(fmt.Sprintf("%5.1f%%", proc.Memory)),
 			cmd)
@@ -550,12 +546,128 @@ func (m Model) renderDetailProcessSection(procs []ProcessInfo, width int) string
 	return strings.Join(lines, "\n")
 }
 
+// renderDetailContainerSection renders container count, aggregate usage, and the
+// busiest container. Also notes when the host itself is a VM or container.
+func (m Model) renderDetailContainerSection(c *ContainerMetrics, width int) string {
+	var lines []string
+
+	// Section header with running container count
+	countText := fmt.Sprintf("%d running", c.Count())
+	lines = append(lines, SectionHeader("Containers", countText, width))
+
+	if c.Virtualization != "" {
+		lines = append(lines, SectionContentLine(LabelStyle.Render("Host virtualization: "+c.Virtualization), width))
+	}
+
+	if c.Count() == 0 {
+		lines = append(lines, SectionContentLine(LabelStyle.Render("No running containers"), width))
+		lines = append(lines, SectionFooter(width))
+		return strings.Join(lines, "\n")
+	}
+
+	// Aggregate usage across all containers
+	cpuStyle := lipgloss.NewStyle().Foreground(MetricColor(c.CPUPercent))
+	totalText := fmt.Sprintf("%s %s  ·  %s %s  ·  %s",
+		LabelStyle.Render("CPU:"), cpuStyle.Render(fmt.Sprintf("%.1f%%", c.CPUPercent)),
+		LabelStyle.Render("Mem:"), formatBytes(c.MemoryBytes),
+		LabelStyle.Render(strings.Join(c.Runtimes, ", ")))
+	lines = append(lines, SectionContentLine(totalText, width))
+
+	// Busiest container
+	top := c.Top()
+	name := top.Name
+	nameWidth := width - 40
+	if nameWidth < 10 {
+		nameWidth = 10
+	}
+	if len(name) > nameWidth {
+		name = name[:nameWidth-3] + "..."
+	}
+	topText := fmt.Sprintf("%s %s (%s)  %.1f%%  %s",
+		LabelStyle.Render("Top:"), name, top.Runtime, top.CPUPercent, formatBytes(top.MemoryBytes))
+	lines = append(lines, SectionContentLine(topText, width))
+
+	// Section footer
+	lines = append(lines, SectionFooter(width))
+
+	return strings.Join(lines, "\n")
+}
+
+// renderDetailReservationSection renders what rr runs have reserved on the
+// host against its size, and who holds each reservation.
+func (m Model) renderDetailReservationSection(r *ReservationMetrics, metrics *HostMetrics, width int) string {
+	var lines []string
+
+	lines = append(lines, SectionHeader("Reservations", fmt.Sprintf("%d active", len(r.Holders)), width))
+
+	cpuText := fmt.Sprintf("%g", r.CPUs)
+	if metrics.CPU.Cores > 0 {
+		cpuText = fmt.Sprintf("%g of %d", r.CPUs, metrics.CPU.Cores)
+	}
+	memText := formatBytes(r.MemoryBytes)
+	if metrics.RAM.TotalBytes > 0 {
+		memText = fmt.Sprintf("%s of %s", formatBytes(r.MemoryBytes), formatBytes(metrics.RAM.TotalBytes))
+	}
+	lines = append(lines, SectionContentLine(fmt.Sprintf("%s %s  ·  %s %s",
+		LabelStyle.Render("CPUs:"), cpuText,
+		LabelStyle.Render("Mem:"), memText), width))
+
+	for _, holder := range r.Holders {
+		lines = append(lines, SectionContentLine(LabelStyle.Render(holder), width))
+	}
+
+	lines = append(lines, SectionFooter(width))
+	return strings.Join(lines, "\n")
+}
+
+// renderDetailWatcherSection renders the watch tasks running on the host,
+// with how long each has been up and how often it restarted.
+func (m Model) renderDetailWatcherSection(watchers []WatcherMetrics, width int) string {
+	var lines []string
+
+	lines = append(lines, SectionHeader("Watch tasks", fmt.Sprintf("%d running", len(watchers)), width))
+
+	for _, w := range watchers {
+		state := "up " + w.FormatUptime()
+		if !w.Running {
+			state = "restarting"
+		}
+		details := []string{state}
+		if w.Restarts > 0 {
+			details = append(details, fmt.Sprintf("%d restarts", w.Restarts))
+		}
+		if len(w.Ports) > 0 {
+			details = append(details, "ports "+strings.Join(w.Ports, ", "))
+		}
+		lines = append(lines, SectionContentLine(fmt.Sprintf("%s %s  %s",
+			w.Task, LabelStyle.Render(w.Command), LabelStyle.Render("("+strings.Join(details, ", ")+")")), width))
+	}
+
+	lines = append(lines, SectionFooter(width))
+	return strings.Join(lines, "\n")
+}
+
 // renderDetailFooter renders navigation hints for the detail view.
 func (m Model) renderDetailFooter() string {
-	hints := []string{"Esc:back", "?:help", "q:quit"}
+	if m.pendingKill != nil {
+		return FooterStyle.Render(m.pendingKill.prompt())
+	}
+	if m.notice != "" {
+		return FooterStyle.Render(m.notice)
+	}
+	hints := append([]string{"Esc:back"}, m.processHints()...)
+	hints = append(hints, "s:ssh", "?:help", "q:quit")
 	return FooterStyle.Render(strings.Join(hints, "  "))
 }
 
+// processHints returns the footer hints for the process table.
+func (m Model) processHints() []string {
+	if m.procsExpanded {
+		return []string{"j/k:select", "x/X:kill", "o:sort", "p:collapse"}
+	}
+	return []string{"p:processes", "o:sort"}
+}
+
 // generateDetailContent builds the scrollable content for the detail view.
 // This is separated so it can be called from Update to set viewport content.
 func (m Model) generateDetailContent() string {
@@ -606,8 +718,14 @@ func (m Model) generateDetailContent() string {
 		}
 	}
 
-	// 2. Processes and Latency side by side
-	if contentWidth >= 80 {
+	// 2. Processes and Latency side by side (the expanded process table
+	// takes the full width)
+	if m.procsExpanded && len(metrics.Processes) > 0 {
+		content.WriteString(m.renderDetailProcessSection(metrics.Processes, contentWidth))
+		content.WriteString("\n")
+		content.WriteString(m.renderDetailLatencySection(host, contentWidth))
+		content.WriteString("\n")
+	} else if contentWidth >= 80 {
 		procSection := ""
 		if len(metrics.Processes) > 0 {
 			procSection = m.renderDetailProcessSection(metrics.Processes, halfWidth)
@@ -642,6 +760,24 @@ func (m Model) generateDetailContent() string {
 		content.WriteString("\n")
 	}
 
+	// 4. Containers full width (only when the host is virtualized or runs containers)
+	if metrics.Containers != nil {
+		content.WriteString(m.renderDetailContainerSection(metrics.Containers, contentWidth))
+		content.WriteString("\n")
+	}
+
+	// 5. Reservations full width (only when rr runs have reserved part of the host)
+	if metrics.Reservations != nil {
+		content.WriteString(m.renderDetailReservationSection(metrics.Reservations, metrics, contentWidth))
+		content.WriteString("\n")
+	}
+
+	// 6. Watch tasks full width (only when this machine runs some on the host)
+	if len(metrics.Watchers) > 0 {
+		content.WriteString(m.renderDetailWatcherSection(metrics.Watchers, contentWidth))
+		content.WriteString("\n")
+	}
+
 	return content.String()
 }
 
@@ -686,15 +822,28 @@ func (m *Model) updateDetailViewportContent() {
 
 // renderDetailFooterWithScroll renders the footer with scroll position indicator.
 func (m Model) renderDetailFooterWithScroll() string {
+	if m.pendingKill != nil {
+		return FooterStyle.Render(m.pendingKill.prompt())
+	}
+	if m.notice != "" {
+		return FooterStyle.Render(m.notice)
+	}
+
 	var hints []string
 
 	// Add scroll indicator if viewport is ready and content is scrollable
 	if m.viewportReady && m.detailViewport.TotalLineCount() > m.detailViewport.Height {
 		scrollPercent := m.detailViewport.ScrollPercent() * 100
 		hints = append(hints, fmt.Sprintf("%.0f%%", scrollPercent))
-		hints = append(hints, "j/k:scroll")
+		if m.procsExpanded {
+			hints = append(hints, "pgup/pgdn:scroll")
+		} else {
+			hints = append(hints, "j/k:scroll")
+		}
 	}
 
-	hints = append(hints, "Esc:back", "?:help", "q:quit")
+	hints = append(hints, "Esc:back")
+	hints = append(hints, m.processHints()...)
+	hints = append(hints, "s:ssh", "?:help", "q:quit")
 	return FooterStyle.Render(strings.Join(hints, "  "))
 }
@@ -296,6 +296,40 @@ func TestModel_renderDetailProcessSection(t *testing.T) {
 	}
 }
 
+func TestModel_renderDetailContainerSection(t *testing.T) {
+	hosts := map[string]config.Host{
+		"server1": {SSH: []string{"server1"}},
+	}
+	collector := NewCollector(hosts)
+	m := NewModel(collector, time.Second, 0, nil)
+	m.width = 120
+
+	t.Run("with containers", func(t *testing.T) {
+		cm := &ContainerMetrics{
+			Runtimes: []string{"docker"},
+			Containers: []ContainerInfo{
+				{Runtime: "docker", Name: "worker", CPUPercent: 42.0, MemoryBytes: 1 << 30},
+				{Runtime: "docker", Name: "web", CPUPercent: 1.0, MemoryBytes: 64 << 20},
+			},
+			CPUPercent:  43.0,
+			MemoryBytes: 1<<30 + 64<<20,
+		}
+		result := m.renderDetailContainerSection(cm, 80)
+		assert.Contains(t, result, "Containers")
+		assert.Contains(t, result, "2 running")
+		assert.Contains(t, result, "worker")
+		assert.Contains(t, result, "43.0%")
+	})
+
+	t.Run("virtualized without containers", func(t *testing.T) {
+		cm := &ContainerMetrics{Virtualization: "kvm"}
+		result := m.renderDetailContainerSection(cm, 80)
+		assert.Contains(t, result, "0 running")
+		assert.Contains(t, result, "kvm")
+		assert.Contains(t, result, "No running containers")
+	})
+}
+
 func TestModel_renderDetailFooter(t *testing.T) {
 	hosts := map[string]config.Host{
 		"server1": {SSH: []string{"server1"}},
@@ -385,3 +419,19 @@ func TestModel_renderDetailViewWithViewport_NoHost(t *testing.T) {
 	result := m.renderDetailViewWithViewport()
 	assert.Contains(t, result, "No host selected")
 }
+
+func TestModel_renderDetailWatcherSection(t *testing.T) {
+	m := NewModel(NewCollector(map[string]config.Host{}), time.Second, 0, nil)
+
+	result := m.renderDetailWatcherSection([]WatcherMetrics{
+		{Task: "dev", Command: "npm run dev", Running: true, Since: time.Now().Add(-5 * time.Minute), Restarts: 2, Ports: []string{"3000"}},
+		{Task: "api", Command: "cargo watch -x run"},
+	}, 100)
+
+	assert.Contains(t, result, "Watch tasks")
+	assert.Contains(t, result, "2 running")
+	assert.Contains(t, result, "npm run dev")
+	assert.Contains(t, result, "2 restarts")
+	assert.Contains(t, result, "ports 3000")
+	assert.Contains(t, result, "restarting")
+}
@@ -25,7 +25,9 @@
 // The dashboard operates on a tick-based refresh cycle:
 //
 //  1. tickMsg fires at the configured interval (default 1s)
-//  2. collectCmd() launches parallel SSH commands to gather metrics
+//  2. refreshCmd() launches parallel SSH commands to gather metrics, at most
+//     Collector.Concurrency() at a time and, for large fleets, staggered
+//     over half the interval
 //  3. metricsMsg arrives with results, updating Model.metrics
 //  4. View() re-renders the dashboard with new data
 //
@@ -47,6 +49,15 @@
 //   - Platform detection (Linux vs macOS) for parser selection
 //   - Automatic reconnection on connection failure
 //
+// # Metrics Collection
+//
+// Each refresh runs one collector script per host, which prints every metric
+// as a single versioned JSON object of named sections (collectorScript in
+// script.go). The script is uploaded to ~/.rr/monitor the first time and
+// verified by checksum on every run, so a stale or edited copy is replaced.
+// Hosts that can't verify it fall back to the batched command from
+// BuildMetricsCommand, split on "---".
+//
 // # History and Sparklines
 //
 // The History type stores metric values in ring buffers for sparkline
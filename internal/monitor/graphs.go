@@ -1,9 +1,11 @@
 package monitor
 
 import (
+	"math/bits"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/ui"
 )
 
 // Braille character rendering for high-resolution terminal graphs.
@@ -25,6 +27,33 @@ const brailleBase = '\u2800'
 // sparklineBlocks are block characters for 8-level vertical resolution (lowest to highest).
 var sparklineBlocks = []rune{'▁', '▂', '▃', '▄', '▅', '▆', '▇', '█'}
 
+// asciiSparklineBlocks stand in for sparklineBlocks on terminals without
+// Unicode (see ui.Unicode).
+var asciiSparklineBlocks = []rune{'_', '.', '-', '~', '=', '+', '*', '#'}
+
+// sparklineRunes returns the characters to draw block sparklines with.
+func sparklineRunes() []rune {
+	if ui.Unicode() {
+		return sparklineBlocks
+	}
+	return asciiSparklineBlocks
+}
+
+// asciiCell stands in for a braille character on terminals without
+// Unicode, by how many of its dots are set.
+func asciiCell(r rune) rune {
+	switch n := bits.OnesCount32(uint32(r - brailleBase)); {
+	case n == 0:
+		return ' '
+	case n <= 2:
+		return '.'
+	case n <= 5:
+		return ':'
+	default:
+		return '#'
+	}
+}
+
 // findMinMax returns the minimum and maximum values in a slice.
 // For percentage data (all values 0-100), returns fixed range 0-100.
 func findMinMax(data []float64) (minVal, maxVal float64, isPercentage bool) {
@@ -179,10 +208,14 @@ func RenderBrailleSparklineWithOptions(data []float64, width, height int, baseCo
 	}
 
 	// Convert grid to string with per-column coloring based on data values
+	unicode := ui.Unicode()
 	var lines []string
 	for _, row := range grid {
 		var lineBuilder strings.Builder
 		for colIdx, char := range row {
+			if !unicode {
+				char = asciiCell(char)
+			}
 			// Determine color based on max value at this column
 			var color lipgloss.Color
 			if colorFunc != nil {
@@ -220,11 +253,12 @@ func RenderMiniSparkline(data []float64, width int) string {
 	minVal, maxVal, _ := findMinMax(data)
 	resampled := resampleData(data, width)
 
+	blocks := sparklineRunes()
 	var result strings.Builder
 	for _, val := range resampled {
 		normalized := normalizeValue(val, minVal, maxVal)
-		idx := clampInt(int(normalized*float64(len(sparklineBlocks)-1)), len(sparklineBlocks)-1)
-		result.WriteRune(sparklineBlocks[idx])
+		idx := clampInt(int(normalized*float64(len(blocks)-1)), len(blocks)-1)
+		result.WriteRune(blocks[idx])
 	}
 
 	return result.String()
@@ -255,11 +289,12 @@ func RenderCleanSparkline(data []float64, width int, color lipgloss.Color) strin
 	minVal, maxVal := 0.0, 100.0
 	resampled := resampleData(data, width)
 
+	blocks := sparklineRunes()
 	var result strings.Builder
 	for _, val := range resampled {
 		normalized := normalizeValue(val, minVal, maxVal)
-		idx := clampInt(int(normalized*float64(len(sparklineBlocks)-1)), len(sparklineBlocks)-1)
-		result.WriteRune(sparklineBlocks[idx])
+		idx := clampInt(int(normalized*float64(len(blocks)-1)), len(blocks)-1)
+		result.WriteRune(blocks[idx])
 	}
 
 	return lipgloss.NewStyle().Foreground(color).Render(result.String())
@@ -350,6 +385,11 @@ func RenderGradientBar(width int, percent float64, _ lipgloss.Color) string {
 		filled = width
 	}
 
+	full, empty := "▰", "▱"
+	if !ui.Unicode() {
+		full, empty = "#", "-"
+	}
+
 	var result strings.Builder
 	for i := 0; i < width; i++ {
 		if i < filled {
@@ -357,11 +397,11 @@ func RenderGradientBar(width int, percent float64, _ lipgloss.Color) string {
 			posPercent := float64(i+1) / float64(width) * 100
 			color := MetricColor(posPercent)
 			style := lipgloss.NewStyle().Foreground(color).Background(ColorSurfaceBg)
-			result.WriteString(style.Render("▰"))
+			result.WriteString(style.Render(full))
 		} else {
 			// Empty portion - use muted color
 			style := lipgloss.NewStyle().Foreground(ColorTextMuted).Background(ColorSurfaceBg)
-			result.WriteString(style.Render("▱"))
+			result.WriteString(style.Render(empty))
 		}
 	}
 
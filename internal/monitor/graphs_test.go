@@ -460,6 +460,26 @@ func TestRenderGradientBar(t *testing.T) {
 	}
 }
 
+func TestGraphs_ASCIIWithoutUnicode(t *testing.T) {
+	t.Setenv("LC_ALL", "C")
+	data := []float64{0, 25, 50, 75, 100}
+
+	outputs := map[string]string{
+		"braille":  RenderBrailleSparkline(data, 5, 2, ColorGraph),
+		"mini":     RenderMiniSparkline(data, 5),
+		"clean":    RenderCleanSparkline(data, 5, ColorGraph),
+		"gradient": RenderGradientBar(10, 50, ColorGraph),
+	}
+	for name, out := range outputs {
+		plain := stripAnsi(out)
+		assert.NotEmpty(t, strings.TrimSpace(plain), name)
+		for _, r := range plain {
+			assert.Less(t, r, rune(0x80), "%s graph has %q", name, r)
+		}
+	}
+	assert.Equal(t, "_.~+#", RenderMiniSparkline(data, 5))
+}
+
 func TestRenderCleanSparkline(t *testing.T) {
 	data := []float64{0, 25, 50, 75, 100}
 	result := RenderCleanSparkline(data, 5, ColorGraph)
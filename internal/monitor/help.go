@@ -6,17 +6,10 @@ import (
 )
 
 // Help overlay styles
+// Help overlay styles, built from the palette by buildStyles
 var (
-	helpBoxStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(ColorAccent).
-			Background(ColorSurfaceBg).
-			Padding(1, 2)
-
-	helpTitleStyle = lipgloss.NewStyle().
-			Foreground(ColorAccent).
-			Bold(true).
-			MarginBottom(1)
+	helpBoxStyle   lipgloss.Style
+	helpTitleStyle lipgloss.Style
 )
 
 // renderHelpOverlay renders a centered help box with keyboard shortcuts.
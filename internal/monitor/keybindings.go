@@ -58,7 +58,17 @@ type keyMap struct {
 	SelectLast  key.Binding
 	Expand      key.Binding
 	Collapse    key.Binding
+	SSH         key.Binding
 	ToggleHelp  key.Binding
+	// Card settings overlay
+	CardSettings key.Binding
+	CardToggle   key.Binding
+	CardUp       key.Binding
+	CardDown     key.Binding
+	// Detail view process table
+	ToggleProcs key.Binding
+	Kill        key.Binding
+	ForceKill   key.Binding
 	// Detail view scrolling
 	ScrollUp   key.Binding
 	ScrollDown key.Binding
@@ -75,8 +85,8 @@ func (k keyMap) ShortHelp() []key.Binding {
 func (k keyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.SelectPrev, k.SelectNext, k.SelectFirst, k.SelectLast},
-		{k.Expand, k.Collapse},
-		{k.Quit, k.Refresh, k.CycleSort, k.ToggleHelp},
+		{k.Expand, k.Collapse, k.SSH, k.ToggleProcs, k.Kill, k.ForceKill},
+		{k.Quit, k.Refresh, k.CycleSort, k.CardSettings, k.ToggleHelp},
 	}
 }
 
@@ -91,8 +101,8 @@ var keys = keyMap{
 		key.WithHelp("r", "refresh"),
 	),
 	CycleSort: key.NewBinding(
-		key.WithKeys("s"),
-		key.WithHelp("s", "sort"),
+		key.WithKeys("o"),
+		key.WithHelp("o", "sort"),
 	),
 	SelectPrev: key.NewBinding(
 		key.WithKeys("up", "k", "left", "h"),
@@ -118,10 +128,44 @@ var keys = keyMap{
 		key.WithKeys("esc"),
 		key.WithHelp("esc", "collapse"),
 	),
+	SSH: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "ssh to host"),
+	),
 	ToggleHelp: key.NewBinding(
 		key.WithKeys("?"),
 		key.WithHelp("?", "help"),
 	),
+	// Card settings overlay
+	CardSettings: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "card layout"),
+	),
+	CardToggle: key.NewBinding(
+		key.WithKeys(" "),
+		key.WithHelp("space", "show/hide section"),
+	),
+	CardUp: key.NewBinding(
+		key.WithKeys("K", "shift+up"),
+		key.WithHelp("K", "move section up"),
+	),
+	CardDown: key.NewBinding(
+		key.WithKeys("J", "shift+down"),
+		key.WithHelp("J", "move section down"),
+	),
+	// Detail view process table
+	ToggleProcs: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "processes"),
+	),
+	Kill: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "kill process (SIGTERM)"),
+	),
+	ForceKill: key.NewBinding(
+		key.WithKeys("X"),
+		key.WithHelp("X", "kill process (SIGKILL)"),
+	),
 	// Detail view scrolling
 	ScrollUp: key.NewBinding(
 		key.WithKeys("up", "k"),
@@ -144,6 +188,21 @@ var keys = keyMap{
 // HandleKeyMsg processes keyboard input and returns updated model state and command.
 // Returns true if the key was handled, false otherwise.
 func (m *Model) HandleKeyMsg(msg tea.KeyMsg) (bool, tea.Cmd) {
+	// Any key dismisses a notice
+	m.notice = ""
+
+	// A pending kill takes the next key as its answer
+	if m.pendingKill != nil {
+		cmd := m.confirmKill(msg.String())
+		m.updateDetailViewportContent()
+		return true, cmd
+	}
+
+	// The card settings overlay takes every key while it's open
+	if m.showCardSettings {
+		return true, m.handleCardSettingsKeys(msg)
+	}
+
 	// Help toggle takes priority
 	if key.Matches(msg, keys.ToggleHelp) {
 		m.showHelp = !m.showHelp
@@ -159,6 +218,8 @@ func (m *Model) HandleKeyMsg(msg tea.KeyMsg) (bool, tea.Cmd) {
 	// Detail view: Esc returns to list
 	if m.viewMode == ViewDetail && key.Matches(msg, keys.Collapse) {
 		m.viewMode = ViewList
+		m.procsExpanded = false
+		m.procSelected = 0
 		// Reset viewport position when leaving detail view
 		m.detailViewport.GotoTop()
 		// Update list viewport content in case it changed
@@ -166,6 +227,11 @@ func (m *Model) HandleKeyMsg(msg tea.KeyMsg) (bool, tea.Cmd) {
 		return true, nil
 	}
 
+	// Detail view: process table keys
+	if m.viewMode == ViewDetail && m.handleProcessKeys(msg) {
+		return true, nil
+	}
+
 	// Handle viewport scrolling
 	if handled, cmd := m.handleScrollKeys(msg); handled {
 		return true, cmd
@@ -225,11 +291,45 @@ func (m *Model) HandleKeyMsg(msg tea.KeyMsg) (bool, tea.Cmd) {
 	case key.Matches(msg, keys.Collapse):
 		m.viewMode = ViewList
 		return true, nil
+
+	case key.Matches(msg, keys.SSH):
+		return true, m.sshCmd()
+
+	case key.Matches(msg, keys.CardSettings):
+		if m.viewMode == ViewList {
+			m.showHelp = false
+			m.openCardSettings()
+		}
+		return true, nil
 	}
 
 	return false, nil
 }
 
+// handleProcessKeys handles the detail view's process table: p expands it,
+// o cycles its sort order, j/k move the selection while it's expanded, and
+// x/X ask to kill the selected process. Returns true if the key was handled.
+func (m *Model) handleProcessKeys(msg tea.KeyMsg) bool {
+	switch {
+	case key.Matches(msg, keys.ToggleProcs):
+		m.toggleProcesses()
+	case key.Matches(msg, keys.CycleSort):
+		m.procSort = m.procSort.Next()
+	case m.procsExpanded && key.Matches(msg, keys.ScrollUp):
+		m.moveProcessSelection(-1)
+	case m.procsExpanded && key.Matches(msg, keys.ScrollDown):
+		m.moveProcessSelection(1)
+	case key.Matches(msg, keys.Kill):
+		m.requestKill("TERM")
+	case key.Matches(msg, keys.ForceKill):
+		m.requestKill("KILL")
+	default:
+		return false
+	}
+	m.updateDetailViewportContent()
+	return true
+}
+
 // handleScrollKeys handles viewport scrolling based on current view mode.
 // Returns true if the key was handled, false otherwise.
 func (m *Model) handleScrollKeys(msg tea.KeyMsg) (bool, tea.Cmd) {
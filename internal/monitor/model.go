@@ -3,6 +3,7 @@ package monitor
 import (
 	"context"
 	"sort"
+	"strconv"
 	"time"
 
 	"github.com/charmbracelet/bubbles/viewport"
@@ -89,6 +90,20 @@ type Model struct {
 	sortOrder  SortOrder
 	viewMode   ViewMode
 	showHelp   bool
+	notice     string // One-line message shown in place of the footer until the next key
+
+	// Process table in the detail view
+	procsExpanded bool          // Full table with a selectable row
+	procSort      ProcSortOrder // Row order
+	procSelected  int           // PID of the selected process (0 for none)
+	pendingKill   *killRequest  // Kill waiting for y/N confirmation
+
+	// Card layout and its settings overlay
+	cardSections     []cardSection // Every section, in order, shown or not
+	cardsChanged     bool          // Layout was changed in the overlay
+	showCardSettings bool
+	cardDraft        []cardSection // The overlay's edits, until enter applies them
+	cardCursor       int
 
 	// Streaming collection state
 	resultsChan <-chan HostResult // Channel for receiving streaming results
@@ -214,6 +229,8 @@ func NewModel(collector *Collector, interval, timeout time.Duration, hostOrder [
 		interval:  interval,
 		timeout:   timeout,
 		sortOrder: SortByDefault, // Start with default sort (online first, config order)
+
+		cardSections: newCardSections(nil),
 	}
 
 	// Apply initial sort
@@ -295,7 +312,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case tickMsg:
-		return m, tea.Batch(m.tickCmd(), m.collectCmd())
+		return m, tea.Batch(m.tickCmd(), m.refreshCmd())
+
+	case sshDoneMsg:
+		return m, m.handleSSHDone(msg)
+
+	case killDoneMsg:
+		return m, m.handleKillDone(msg)
 
 	case spinnerTickMsg:
 		// Advance spinner animation frame (use large cycle to allow text animation to complete)
@@ -381,6 +404,23 @@ func (m Model) spinnerTickCmd() tea.Cmd {
 // Hosts that are in backoff (unreachable with NextRetry in future) are skipped.
 // Returns a collectStartedMsg so Update can set up state safely (no data races).
 func (m Model) collectCmd() tea.Cmd {
+	return m.collectHostsCmd(0)
+}
+
+// refreshCmd is collectCmd for the refresh on each tick. With more hosts
+// than the collector works on at once, their collections are spread over
+// the first half of the interval instead of all starting on the tick.
+func (m Model) refreshCmd() tea.Cmd {
+	var spread time.Duration
+	if len(m.hosts) > m.collector.Concurrency() {
+		spread = m.interval / 2
+	}
+	return m.collectHostsCmd(spread)
+}
+
+// collectHostsCmd starts collection from the hosts not in backoff, spread
+// over spread (see Collector.CollectStreamingStaggered).
+func (m Model) collectHostsCmd(spread time.Duration) tea.Cmd {
 	// Capture values locally to avoid reading Model fields in goroutine
 	timeout := m.timeout
 	collector := m.collector
@@ -415,14 +455,14 @@ func (m Model) collectCmd() tea.Cmd {
 		// Note: We don't defer cancel() here because CollectStreamingHosts spawns
 		// goroutines that need the context to remain valid. The context will be
 		// garbage collected after the timeout expires. This is acceptable because:
-		// 1. The timeout is bounded (timeout * numHosts+1)
+		// 1. The timeout is bounded (timeout * numHosts+1, plus the spread)
 		// 2. Collection typically completes well before the timeout
 		// 3. The timer overhead is minimal
-		ctx, cancel := context.WithTimeout(context.Background(), timeout*time.Duration(numHosts+1))
+		ctx, cancel := context.WithTimeout(context.Background(), timeout*time.Duration(numHosts+1)+spread)
 		_ = cancel // Context cleanup happens via timeout; see comment above
 
 		// Start streaming collection for only the non-backoff hosts
-		resultsChan := collector.CollectStreamingHosts(ctx, hostsToCollect)
+		resultsChan := collector.CollectStreamingStaggered(ctx, hostsToCollect, spread)
 
 		// Return the channel to Update for safe state setup
 		return collectStartedMsg{results: resultsChan}
@@ -627,12 +667,17 @@ func (m Model) RunningSpinner() (string, lipgloss.Style) {
 }
 
 // renderRunningStatusText returns the status text for a running host.
-// Shows "- running" with the task duration if lock info is available.
+// Shows "- running" with the task duration if lock info is available, and
+// how many more are running alongside it under project or command scoped
+// locks.
 func (m Model) renderRunningStatusText(host string) string {
 	// Check if we have lock info with duration
 	if lockInfo, ok := m.lockInfo[host]; ok && lockInfo != nil && lockInfo.IsLocked {
-		duration := lockInfo.FormatDuration()
-		return StatusRunningTextStyle.Render(" - running " + duration)
+		text := " - running " + lockInfo.FormatDuration()
+		if lockInfo.Count > 1 {
+			text += " (+" + strconv.Itoa(lockInfo.Count-1) + ")"
+		}
+		return StatusRunningTextStyle.Render(text)
 	}
 
 	// Fallback: show "- running" with animated dots
@@ -45,7 +45,8 @@ func parseDarwinCPUUsage(line string) float64 {
 	// Format: "CPU usage: 5.26% user, 10.52% sys, 84.21% idle"
 	// We want to return (100 - idle)
 
-	parts := strings.Split(line, ",")
+	// Split on ", " so a comma decimal ("84,21% idle") stays whole
+	parts := strings.Split(line, ", ")
 	for _, part := range parts {
 		part = strings.TrimSpace(part)
 		if strings.Contains(part, "idle") {
@@ -53,7 +54,7 @@ func parseDarwinCPUUsage(line string) float64 {
 			fields := strings.Fields(part)
 			if len(fields) >= 1 {
 				pctStr := strings.TrimSuffix(fields[0], "%")
-				idle, err := strconv.ParseFloat(pctStr, 64)
+				idle, err := parseDecimal(pctStr)
 				if err == nil {
 					return 100 - idle
 				}
@@ -74,10 +75,10 @@ func parseDarwinLoadAvg(line string) [3]float64 {
 	}
 
 	valuesStr := strings.TrimSpace(line[colonIdx+1:])
-	parts := strings.Split(valuesStr, ",")
+	parts := strings.Split(valuesStr, ", ")
 
 	for i := 0; i < 3 && i < len(parts); i++ {
-		val, err := strconv.ParseFloat(strings.TrimSpace(parts[i]), 64)
+		val, err := parseDecimal(strings.TrimSpace(parts[i]))
 		if err == nil {
 			loadAvg[i] = val
 		}
@@ -86,6 +87,15 @@ func parseDarwinLoadAvg(line string) [3]float64 {
 	return loadAvg
 }
 
+// parseDecimal parses a number that may use a comma as its decimal
+// separator ("1,23"), as top prints it under many locales.
+func parseDecimal(s string) (float64, error) {
+	if strings.Count(s, ",") == 1 && !strings.Contains(s, ".") {
+		s = strings.Replace(s, ",", ".", 1)
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
 // ParseDarwinMemory parses memory metrics from macOS vm_stat and sysctl output.
 // Expected input is from: vm_stat; sysctl hw.memsize
 func ParseDarwinMemory(vmStatOutput string) (*monitor.RAMMetrics, error) {
@@ -260,6 +260,8 @@ func TestParseDarwinCPUUsage(t *testing.T) {
 		{"CPU usage: 50.00% user, 30.00% sys, 20.00% idle", 80.0},
 		{"CPU usage: 0.00% user, 0.00% sys, 100.00% idle", 0.0},
 		{"CPU usage: 100.00% user, 0.00% sys, 0.00% idle", 100.0},
+		// Comma decimals, as under a de_DE or fr_FR locale
+		{"CPU usage: 5,26% user, 10,52% sys, 84,21% idle", 15.79},
 	}
 
 	for _, tt := range tests {
@@ -278,6 +280,8 @@ func TestParseDarwinLoadAvg(t *testing.T) {
 		{"Load Avg: 1.23, 2.34, 3.45", [3]float64{1.23, 2.34, 3.45}},
 		{"Load Avg: 0.00, 0.00, 0.00", [3]float64{0.00, 0.00, 0.00}},
 		{"Load Avg: 10.50, 8.25, 6.75", [3]float64{10.50, 8.25, 6.75}},
+		// Comma decimals must not split into "1", "23", "2"
+		{"Load Avg: 1,23, 2,34, 3,45", [3]float64{1.23, 2.34, 3.45}},
 	}
 
 	for _, tt := range tests {
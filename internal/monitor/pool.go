@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/rileyhilliard/rr/internal/config"
+	rrhost "github.com/rileyhilliard/rr/internal/host"
 	"github.com/rileyhilliard/rr/pkg/sshutil"
 )
 
@@ -64,6 +65,12 @@ func (p *Pool) Get(alias string) (*sshutil.Client, error) {
 
 	// Look up the host config to get SSH addresses
 	host, ok := p.hosts[alias]
+	if ok {
+		var err error
+		if host, err = config.ResolveHostSecrets(alias, host); err != nil {
+			return nil, err
+		}
+	}
 	if !ok || len(host.SSH) == 0 {
 		// Fall back to using alias directly (for backwards compatibility or simple configs)
 		client, err := sshutil.Dial(alias, p.timeout)
@@ -82,7 +89,7 @@ func (p *Pool) Get(alias string) (*sshutil.Client, error) {
 
 	// Single address - no need for parallel logic
 	if len(host.SSH) == 1 {
-		client, err := sshutil.Dial(host.SSH[0], p.timeout)
+		client, err := sshutil.DialWithOptions(host.SSH[0], p.timeout, rrhost.DialOptions(host))
 		if err != nil {
 			return nil, err
 		}
@@ -97,20 +104,20 @@ func (p *Pool) Get(alias string) (*sshutil.Client, error) {
 	}
 
 	// Multiple addresses - try in parallel, prefer earlier ones
-	return p.connectParallel(alias, host.SSH)
+	return p.connectParallel(alias, host.SSH, rrhost.DialOptions(host))
 }
 
 // connectParallel tries multiple SSH addresses concurrently.
 // It prefers earlier addresses in the list (e.g., LAN over VPN) but won't block
 // waiting for them if a later address connects first. If a preferred address
 // connects within 500ms of a less-preferred one, the preferred one wins.
-func (p *Pool) connectParallel(alias string, addresses []string) (*sshutil.Client, error) {
+func (p *Pool) connectParallel(alias string, addresses []string, opts sshutil.DialOptions) (*sshutil.Client, error) {
 	results := make(chan connectionResult, len(addresses))
 
 	// Start all connection attempts in parallel
 	for i, addr := range addresses {
 		go func(idx int, sshAddr string) {
-			client, err := sshutil.Dial(sshAddr, p.timeout)
+			client, err := sshutil.DialWithOptions(sshAddr, p.timeout, opts)
 			results <- connectionResult{
 				client:  client,
 				sshAddr: sshAddr,
@@ -315,7 +322,7 @@ func (p *Pool) detectPlatform(client *sshutil.Client) (Platform, error) {
 	}
 	defer session.Close()
 
-	output, err := session.Output(PlatformDetectCommand())
+	output, err := session.Output(sshutil.WithCLocale(PlatformDetectCommand()))
 	if err != nil {
 		return PlatformUnknown, err
 	}
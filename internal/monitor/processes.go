@@ -0,0 +1,219 @@
+package monitor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rileyhilliard/rr/pkg/sshutil"
+)
+
+// Rows shown in the detail view's process table.
+const (
+	collapsedProcRows = 5
+	expandedProcRows  = 15
+)
+
+// String returns the label shown in the process table header.
+func (s ProcSortOrder) String() string {
+	switch s {
+	case ProcSortByMemory:
+		return "MEM"
+	case ProcSortByPID:
+		return "PID"
+	default:
+		return "CPU"
+	}
+}
+
+// Next cycles to the next process sort order.
+func (s ProcSortOrder) Next() ProcSortOrder {
+	return ProcSortOrder((int(s) + 1) % 3)
+}
+
+// sortProcesses returns a copy of procs in the given order: busiest first
+// for CPU and memory, ascending for PID.
+func sortProcesses(procs []ProcessInfo, order ProcSortOrder) []ProcessInfo {
+	sorted := make([]ProcessInfo, len(procs))
+	copy(sorted, procs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		switch order {
+		case ProcSortByMemory:
+			return sorted[i].Memory > sorted[j].Memory
+		case ProcSortByPID:
+			return sorted[i].PID < sorted[j].PID
+		default:
+			return sorted[i].CPU > sorted[j].CPU
+		}
+	})
+	return sorted
+}
+
+// visibleProcesses returns the rows of the selected host's process table,
+// in display order.
+func (m Model) visibleProcesses() []ProcessInfo {
+	metrics := m.metrics[m.SelectedHost()]
+	if metrics == nil {
+		return nil
+	}
+	return m.processRows(metrics.Processes)
+}
+
+// processRows returns the rows of the process table for procs: sorted, and
+// cut to the table's size.
+func (m Model) processRows(procs []ProcessInfo) []ProcessInfo {
+	procs = sortProcesses(procs, m.procSort)
+	rows := collapsedProcRows
+	if m.procsExpanded {
+		rows = expandedProcRows
+	}
+	if len(procs) > rows {
+		procs = procs[:rows]
+	}
+	return procs
+}
+
+// toggleProcesses expands or collapses the process table. Expanding selects
+// the first row.
+func (m *Model) toggleProcesses() {
+	m.procsExpanded = !m.procsExpanded
+	m.procSelected = 0
+	if procs := m.visibleProcesses(); m.procsExpanded && len(procs) > 0 {
+		m.procSelected = procs[0].PID
+	}
+}
+
+// moveProcessSelection moves the selection delta rows through the process
+// table. The selection follows the PID, so it stays on the same process when
+// the table reorders; if that process is gone, it starts over at the top.
+func (m *Model) moveProcessSelection(delta int) {
+	procs := m.visibleProcesses()
+	if len(procs) == 0 {
+		m.procSelected = 0
+		return
+	}
+	idx := -1
+	for i, p := range procs {
+		if p.PID == m.procSelected {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		m.procSelected = procs[0].PID
+		return
+	}
+	idx = max(0, min(len(procs)-1, idx+delta))
+	m.procSelected = procs[idx].PID
+}
+
+// selectedProcess returns the selected row of the process table, or nil if
+// nothing is selected or the process is no longer listed.
+func (m Model) selectedProcess() *ProcessInfo {
+	if !m.procsExpanded || m.procSelected == 0 {
+		return nil
+	}
+	for _, p := range m.visibleProcesses() {
+		if p.PID == m.procSelected {
+			return &p
+		}
+	}
+	return nil
+}
+
+// killRequest is a signal to send to a process, waiting for confirmation.
+type killRequest struct {
+	host    string
+	pid     int
+	command string
+	signal  string // TERM or KILL
+}
+
+// killDoneMsg is sent when a kill from the dashboard has run.
+type killDoneMsg struct {
+	req killRequest
+	err error
+}
+
+// requestKill asks for confirmation to send signal to the selected process.
+func (m *Model) requestKill(signal string) {
+	proc := m.selectedProcess()
+	if proc == nil {
+		m.notice = "Press p to open the process table and pick a process first"
+		return
+	}
+	m.pendingKill = &killRequest{
+		host:    m.SelectedHost(),
+		pid:     proc.PID,
+		command: proc.Command,
+		signal:  signal,
+	}
+}
+
+// confirmKill handles the key pressed while a kill waits for confirmation:
+// y sends the signal, anything else cancels.
+func (m *Model) confirmKill(key string) tea.Cmd {
+	req := *m.pendingKill
+	m.pendingKill = nil
+	if key != "y" && key != "Y" {
+		m.notice = "Kill cancelled"
+		return nil
+	}
+	if m.collector == nil {
+		return nil
+	}
+	collector := m.collector
+	return func() tea.Msg {
+		return killDoneMsg{req: req, err: collector.KillProcess(req.host, req.pid, req.signal)}
+	}
+}
+
+// prompt is the confirmation shown in the footer for a pending kill.
+func (k killRequest) prompt() string {
+	cmd := k.command
+	if len(cmd) > 40 {
+		cmd = cmd[:37] + "..."
+	}
+	return fmt.Sprintf("Send SIG%s to %d (%s) on %s? y/N", k.signal, k.pid, cmd, k.host)
+}
+
+// handleKillDone reports the result of a kill and refreshes right away so
+// the process table shows whether it exited.
+func (m *Model) handleKillDone(msg killDoneMsg) tea.Cmd {
+	if msg.err != nil {
+		m.notice = fmt.Sprintf("Couldn't signal %d on %s: %v", msg.req.pid, msg.req.host, msg.err)
+		return nil
+	}
+	m.notice = fmt.Sprintf("Sent SIG%s to %d on %s", msg.req.signal, msg.req.pid, msg.req.host)
+	return m.collectCmd()
+}
+
+// killCommand returns the shell command that sends signal to pid.
+func killCommand(pid int, signal string) string {
+	return fmt.Sprintf("kill -%s %d", signal, pid)
+}
+
+// KillProcess sends a signal (TERM or KILL) to a process on a host. The error
+// carries kill's own message, e.g. "Operation not permitted" for another
+// user's process.
+func (c *Collector) KillProcess(alias string, pid int, signal string) error {
+	client, err := c.pool.Get(alias)
+	if err != nil {
+		return err
+	}
+	session, err := client.Client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	out, err := session.CombinedOutput(sshutil.WithCLocale(killCommand(pid, signal)))
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%s", msg)
+		}
+		return err
+	}
+	return nil
+}
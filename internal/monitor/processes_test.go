@@ -0,0 +1,155 @@
+package monitor
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newProcessTestModel returns a model in the detail view of a host with a
+// few processes.
+func newProcessTestModel() Model {
+	hosts := map[string]config.Host{"server1": {SSH: []string{"server1"}}}
+	m := NewModel(NewCollector(hosts), time.Second, 0, nil)
+	m.viewMode = ViewDetail
+	m.metrics["server1"] = &HostMetrics{Processes: []ProcessInfo{
+		{PID: 300, User: "me", CPU: 95.0, Memory: 2.0, Command: "python train.py"},
+		{PID: 100, User: "root", CPU: 10.0, Memory: 40.0, Command: "postgres"},
+		{PID: 200, User: "me", CPU: 50.0, Memory: 1.0, Command: "node server.js"},
+	}}
+	return m
+}
+
+func keyPress(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func pids(procs []ProcessInfo) []int {
+	out := make([]int, len(procs))
+	for i, p := range procs {
+		out[i] = p.PID
+	}
+	return out
+}
+
+func TestSortProcesses(t *testing.T) {
+	m := newProcessTestModel()
+	procs := m.metrics["server1"].Processes
+
+	assert.Equal(t, []int{300, 200, 100}, pids(sortProcesses(procs, ProcSortByCPU)))
+	assert.Equal(t, []int{100, 300, 200}, pids(sortProcesses(procs, ProcSortByMemory)))
+	assert.Equal(t, []int{100, 200, 300}, pids(sortProcesses(procs, ProcSortByPID)))
+	assert.Equal(t, 300, procs[0].PID, "input is not reordered")
+}
+
+func TestProcSortOrder_Next(t *testing.T) {
+	assert.Equal(t, ProcSortByMemory, ProcSortByCPU.Next())
+	assert.Equal(t, ProcSortByPID, ProcSortByMemory.Next())
+	assert.Equal(t, ProcSortByCPU, ProcSortByPID.Next())
+	assert.Equal(t, "MEM", ProcSortByMemory.String())
+}
+
+func TestProcessTable_SelectAndSort(t *testing.T) {
+	m := newProcessTestModel()
+
+	// p expands the table and selects the top row
+	handled, _ := m.HandleKeyMsg(keyPress("p"))
+	require.True(t, handled)
+	assert.True(t, m.procsExpanded)
+	assert.Equal(t, 300, m.procSelected)
+
+	m.HandleKeyMsg(keyPress("j"))
+	assert.Equal(t, 200, m.procSelected)
+	m.HandleKeyMsg(keyPress("j"))
+	m.HandleKeyMsg(keyPress("j"))
+	assert.Equal(t, 100, m.procSelected, "selection stops at the last row")
+
+	// The selection follows the process when the order changes
+	m.HandleKeyMsg(keyPress("o"))
+	assert.Equal(t, ProcSortByMemory, m.procSort)
+	assert.Equal(t, 100, m.selectedProcess().PID)
+	m.HandleKeyMsg(keyPress("j"))
+	assert.Equal(t, 300, m.procSelected)
+
+	// Leaving the detail view collapses the table
+	m.HandleKeyMsg(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.False(t, m.procsExpanded)
+	assert.Nil(t, m.selectedProcess())
+}
+
+func TestProcessTable_KillConfirmation(t *testing.T) {
+	t.Run("needs a selection", func(t *testing.T) {
+		m := newProcessTestModel()
+		m.HandleKeyMsg(keyPress("x"))
+		assert.Nil(t, m.pendingKill)
+		assert.Contains(t, m.notice, "Press p")
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		m := newProcessTestModel()
+		m.HandleKeyMsg(keyPress("p"))
+		m.HandleKeyMsg(keyPress("x"))
+		require.NotNil(t, m.pendingKill)
+		assert.Equal(t, killRequest{host: "server1", pid: 300, command: "python train.py", signal: "TERM"}, *m.pendingKill)
+		assert.Contains(t, m.renderDetailFooter(), "Send SIGTERM to 300 (python train.py) on server1? y/N")
+
+		_, cmd := m.HandleKeyMsg(keyPress("n"))
+		assert.Nil(t, cmd)
+		assert.Nil(t, m.pendingKill)
+		assert.Equal(t, "Kill cancelled", m.notice)
+	})
+
+	t.Run("confirm", func(t *testing.T) {
+		m := newProcessTestModel()
+		m.HandleKeyMsg(keyPress("p"))
+		m.HandleKeyMsg(keyPress("X"))
+		require.NotNil(t, m.pendingKill)
+		assert.Equal(t, "KILL", m.pendingKill.signal)
+
+		_, cmd := m.HandleKeyMsg(keyPress("y"))
+		assert.NotNil(t, cmd)
+		assert.Nil(t, m.pendingKill)
+	})
+}
+
+func TestHandleKillDone(t *testing.T) {
+	m := newProcessTestModel()
+	req := killRequest{host: "server1", pid: 300, signal: "TERM"}
+
+	assert.NotNil(t, m.handleKillDone(killDoneMsg{req: req}))
+	assert.Equal(t, "Sent SIGTERM to 300 on server1", m.notice)
+
+	assert.Nil(t, m.handleKillDone(killDoneMsg{req: req, err: errors.New("kill: (300) - Operation not permitted")}))
+	assert.Equal(t, "Couldn't signal 300 on server1: kill: (300) - Operation not permitted", m.notice)
+}
+
+func TestKillCommand(t *testing.T) {
+	assert.Equal(t, "kill -TERM 1234", killCommand(1234, "TERM"))
+	assert.Equal(t, "kill -KILL 1234", killCommand(1234, "KILL"))
+}
+
+func TestRenderDetailProcessSection_Expanded(t *testing.T) {
+	m := newProcessTestModel()
+	procs := m.metrics["server1"].Processes
+	many := make([]ProcessInfo, 0, 20)
+	for i := 0; i < 20; i++ {
+		many = append(many, ProcessInfo{PID: 1000 + i, User: "me", CPU: float64(20 - i), Command: "worker"})
+	}
+
+	collapsed := m.renderDetailProcessSection(many, 100)
+	assert.NotContains(t, collapsed, "1005")
+
+	m.toggleProcesses()
+	expanded := m.renderDetailProcessSection(many, 100)
+	assert.Contains(t, expanded, "1014")
+	assert.NotContains(t, expanded, "1015")
+
+	m.procSort = ProcSortByMemory
+	assert.Contains(t, m.renderDetailProcessSection(procs, 100), "by MEM")
+	assert.Contains(t, m.renderDetailProcessSection(procs, 100), "▸")
+}
@@ -0,0 +1,212 @@
+package monitor
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/lock"
+)
+
+// CollectorVersion is the version of the collector script's output format.
+// Bump it when the script changes in a way the parser has to know about;
+// each version installs to its own file, so hosts shared by different rr
+// versions keep working.
+const CollectorVersion = 1
+
+// collectorMissing is what the run command prints when the host doesn't
+// have this exact collector script, so rr knows to upload it.
+const collectorMissing = "RR_COLLECTOR_MISSING"
+
+// collectorDir is where the collector script is installed on each host.
+const collectorDir = "$HOME/.rr/monitor"
+
+// linuxSections and darwinSections name the collector script's sections, in
+// the order the section parsers take them.
+var (
+	linuxSections  = []string{"stat", "loadavg", "meminfo", "netdev", "gpu", "processes", "containers"}
+	darwinSections = []string{"top", "memory", "netstat", "gpu", "processes", "containers"}
+)
+
+// reservationsSection lists the reservation files rr runs hold on the host
+// (see lock.Reserve). The script reads them from the directory passed as its
+// first argument.
+const reservationsSection = "reservations"
+
+// collectorScript gathers every metric in one run and prints it as a single
+// JSON object: {"version":1,"platform":"linux","sections":{"stat":"...",...}}.
+// Each section is the raw output of the command that collects it, so the
+// parsers are the same as for the batched command. Only POSIX sh, awk, and
+// tr are needed.
+var collectorScript = fmt.Sprintf(`#!/bin/sh
+# rr monitor metrics collector, format version %d.
+# Installed and run by rr monitor; safe to delete.
+# Usage: collector.sh [reservations-dir]
+
+RR_RESERVATIONS=$1
+
+# esc turns stdin into the body of a JSON string. Tabs become spaces and
+# other control characters are dropped, which no parser depends on.
+esc() {
+	tr '\011' ' ' | tr -d '\000-\010\013-\037' | sed 's/\\/\\\\/g; s/"/\\"/g' |
+		awk 'BEGIN { ORS = "" } NR > 1 { print "\\n" } { print }'
+}
+
+sep=
+section() {
+	name=$1
+	shift
+	printf '%%s"%%s":"' "$sep" "$name"
+	"$@" 2>/dev/null | esc
+	printf '"'
+	sep=,
+}
+
+reservations() { [ -n "$RR_RESERVATIONS" ] || return 0; %s; }
+linux_reservations() { reservations; }
+darwin_reservations() { reservations; }
+
+linux_stat() { cat /proc/stat; }
+linux_loadavg() { cat /proc/loadavg; }
+linux_meminfo() { cat /proc/meminfo; }
+linux_netdev() { cat /proc/net/dev; }
+linux_gpu() { nvidia-smi --query-gpu=name,utilization.gpu,memory.used,memory.total,temperature.gpu,power.draw --format=csv,noheader,nounits; }
+linux_processes() { ps aux --sort=-%%cpu | head -16 || ps aux | head -16; ps aux --sort=-%%mem | sed 1d | head -15; }
+linux_containers() { %s; }
+
+darwin_top() { top -l 1 -n 0; }
+darwin_memory() { vm_stat; sysctl hw.memsize; }
+darwin_netstat() { netstat -ib; }
+darwin_gpu() { ioreg -r -c AGXAccelerator | grep -E '"(model|gpu-core-count|PerformanceStatistics)"'; }
+darwin_processes() { ps aux -r | head -16; ps aux -m | sed 1d | head -15; }
+darwin_containers() { %s; }
+
+case "$(uname -s)" in
+Darwin) platform=darwin; sections="%s" ;;
+*) platform=linux; sections="%s" ;;
+esac
+
+printf '{"version":%d,"platform":"%%s","sections":{' "$platform"
+for s in $sections; do
+	section "$s" "${platform}_$s"
+done
+printf '}}\n'
+`,
+	CollectorVersion,
+	lock.ReservationListCommand("$RR_RESERVATIONS"),
+	linuxContainerCommand,
+	darwinContainerCommand,
+	strings.Join(append(slices.Clone(darwinSections), reservationsSection), " "),
+	strings.Join(append(slices.Clone(linuxSections), reservationsSection), " "),
+	CollectorVersion,
+)
+
+// collectorChecksum is the SHA-256 of collectorScript. The run command
+// checks it so a stale or damaged script is replaced rather than trusted.
+var collectorChecksum = func() string {
+	sum := sha256.Sum256([]byte(collectorScript))
+	return hex.EncodeToString(sum[:])
+}()
+
+// collectorPath is the installed script's path on the host.
+func collectorPath() string {
+	return fmt.Sprintf("%s/collector-v%d.sh", collectorDir, CollectorVersion)
+}
+
+// collectorRunCommand runs the installed collector script if its checksum
+// matches, and prints collectorMissing otherwise, including on hosts with
+// neither sha256sum nor shasum. reservationsDir is where the script looks
+// for reservations; empty skips them.
+func collectorRunCommand(reservationsDir string) string {
+	return fmt.Sprintf(`f="%s"; s=$( (sha256sum "$f" || shasum -a 256 "$f") 2>/dev/null | cut -d' ' -f1); `+
+		`if [ "$s" = "%s" ]; then sh "$f" %q; else echo %s; fi`,
+		collectorPath(), collectorChecksum, reservationsDir, collectorMissing)
+}
+
+// collectorInstallCommand writes the script from stdin to its install path.
+// Writing to a temp file first keeps a concurrent run from reading half of it.
+func collectorInstallCommand() string {
+	return fmt.Sprintf(`mkdir -p "%s" && cat > "%s.tmp.$$" && mv "%s.tmp.$$" "%s"`,
+		collectorDir, collectorPath(), collectorPath(), collectorPath())
+}
+
+// isCollectorMissing reports whether the run command found no usable
+// collector script. Only the last line counts, since the host's profile
+// files may print first.
+func isCollectorMissing(output []byte) bool {
+	return bytes.HasSuffix(bytes.TrimSpace(output), []byte(collectorMissing))
+}
+
+// collectorOutput is the JSON the collector script prints.
+type collectorOutput struct {
+	Version  int               `json:"version"`
+	Platform Platform          `json:"platform"`
+	Sections map[string]string `json:"sections"`
+}
+
+// parseCollectorOutput parses the collector script's JSON into HostMetrics.
+// Sections are matched by name, so a missing section just leaves its metrics
+// empty and an unknown one is ignored.
+func (c *Collector) parseCollectorOutput(alias string, output []byte) (*HostMetrics, error) {
+	// Skip anything the host's profile files printed before the JSON.
+	if i := bytes.LastIndex(output, []byte(`{"version":`)); i > 0 {
+		output = output[i:]
+	}
+
+	var out collectorOutput
+	if err := json.Unmarshal(output, &out); err != nil {
+		return nil, fmt.Errorf("unexpected output from the metrics collector: %w", err)
+	}
+
+	metrics := &HostMetrics{
+		Timestamp:    time.Now(),
+		Reservations: c.parseReservations(out.Sections[reservationsSection]),
+	}
+	if out.Platform == PlatformDarwin {
+		return c.parseDarwinOutput(metrics, namedSections(out.Sections, darwinSections))
+	}
+	return c.parseLinuxOutput(alias, metrics, namedSections(out.Sections, linuxSections))
+}
+
+// reservationsDir is where the collector script looks for reservations, or
+// "" when lock checking isn't configured.
+func (c *Collector) reservationsDir() string {
+	if c.lockConfig == nil {
+		return ""
+	}
+	return lock.ReservationsDir(*c.lockConfig)
+}
+
+// parseReservations totals the live reservations in the reservations
+// section. Returns nil when there are none.
+func (c *Collector) parseReservations(section string) *ReservationMetrics {
+	if strings.TrimSpace(section) == "" || c.lockConfig == nil {
+		return nil
+	}
+	live, _ := lock.ParseReservations(section, c.lockConfig.Stale)
+	if len(live) == 0 {
+		return nil
+	}
+	rm := &ReservationMetrics{}
+	for _, r := range live {
+		rm.CPUs += r.CPUs
+		rm.MemoryBytes += r.MemoryBytes
+		rm.Holders = append(rm.Holders, r.LockInfo.String())
+	}
+	return rm
+}
+
+// namedSections puts named sections in the order the section parsers take
+// them. Missing ones are empty.
+func namedSections(sections map[string]string, names []string) []string {
+	ordered := make([]string, len(names))
+	for i, name := range names {
+		ordered[i] = sections[name]
+	}
+	return ordered
+}
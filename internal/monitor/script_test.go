@@ -0,0 +1,170 @@
+package monitor
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectorScript_RunsLocally(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("collector script output is checked against Linux /proc")
+	}
+
+	out, err := exec.Command("sh", "-c", collectorScript).Output()
+	require.NoError(t, err)
+
+	var parsed collectorOutput
+	require.NoError(t, json.Unmarshal(out, &parsed), string(out))
+	assert.Equal(t, CollectorVersion, parsed.Version)
+	assert.Equal(t, PlatformLinux, parsed.Platform)
+	for _, name := range linuxSections {
+		assert.Contains(t, parsed.Sections, name)
+	}
+	assert.True(t, strings.HasPrefix(parsed.Sections["stat"], "cpu "))
+
+	c := NewCollector(map[string]config.Host{})
+	metrics, err := c.parseCollectorOutput("local", out)
+	require.NoError(t, err)
+	assert.Greater(t, metrics.CPU.Cores, 0)
+	assert.Greater(t, metrics.RAM.TotalBytes, int64(0))
+}
+
+func TestCollectorScript_EscapesOutput(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "collector.sh")
+	// Replace the Linux stat section with awkward output to check escaping.
+	body := strings.Replace(collectorScript, "linux_stat() { cat /proc/stat; }",
+		`linux_stat() { printf 'a "quoted" \\ back\tslash\r\nline\001two\n'; }`, 1)
+	require.NoError(t, os.WriteFile(script, []byte(body), 0644))
+
+	out, err := exec.Command("sh", script).Output()
+	require.NoError(t, err)
+
+	var parsed collectorOutput
+	require.NoError(t, json.Unmarshal(out, &parsed), string(out))
+	if parsed.Platform == PlatformLinux {
+		assert.Equal(t, "a \"quoted\" \\ back slash\nlinetwo", parsed.Sections["stat"])
+	}
+}
+
+func TestCollectorRunCommand(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	run := func() string {
+		out, err := exec.Command("sh", "-c", collectorRunCommand("")).CombinedOutput()
+		require.NoError(t, err)
+		return string(out)
+	}
+
+	// Nothing installed yet.
+	assert.True(t, isCollectorMissing([]byte(run())))
+
+	install := exec.Command("sh", "-c", collectorInstallCommand())
+	install.Stdin = strings.NewReader(collectorScript)
+	require.NoError(t, install.Run())
+	assert.FileExists(t, filepath.Join(home, ".rr", "monitor", "collector-v1.sh"))
+
+	if _, err := exec.LookPath("sha256sum"); err != nil {
+		if _, err := exec.LookPath("shasum"); err != nil {
+			t.Skip("no sha256sum or shasum")
+		}
+	}
+	assert.False(t, isCollectorMissing([]byte(run())))
+
+	// A modified script fails the checksum and counts as missing.
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".rr", "monitor", "collector-v1.sh"), []byte("echo tampered\n"), 0644))
+	assert.True(t, isCollectorMissing([]byte(run())))
+}
+
+func TestParseCollectorOutput(t *testing.T) {
+	c := NewCollector(map[string]config.Host{})
+
+	t.Run("linux sections by name", func(t *testing.T) {
+		out := `{"version":1,"platform":"linux","sections":{` +
+			`"loadavg":"1.23 2.34 3.45 1/234 5678",` +
+			`"stat":"cpu  1000 0 1000 8000 0 0 0 0 0 0\ncpu0 1000 0 1000 8000 0 0 0 0 0 0",` +
+			`"meminfo":"MemTotal:       16384000 kB\nMemFree:         1234567 kB\nMemAvailable:    8765432 kB\nBuffers:          123456 kB\nCached:          4567890 kB",` +
+			`"future":"ignored"}}`
+		metrics, err := c.parseCollectorOutput("host", []byte(out))
+		require.NoError(t, err)
+		assert.Equal(t, 1, metrics.CPU.Cores)
+		assert.InDelta(t, 1.23, metrics.CPU.LoadAvg[0], 0.01)
+		assert.Equal(t, int64(16384000*1024), metrics.RAM.TotalBytes)
+		assert.Nil(t, metrics.GPU)
+	})
+
+	t.Run("darwin", func(t *testing.T) {
+		out := `{"version":1,"platform":"darwin","sections":{"top":"Load Avg: 1.50, 2.00, 2.50\nCPU usage: 10.0% user, 5.0% sys, 85.0% idle"}}`
+		metrics, err := c.parseCollectorOutput("host", []byte(out))
+		require.NoError(t, err)
+		assert.InDelta(t, 1.5, metrics.CPU.LoadAvg[0], 0.01)
+	})
+
+	t.Run("skips profile output before the JSON", func(t *testing.T) {
+		out := "welcome to the host\n" + `{"version":1,"platform":"linux","sections":{"loadavg":"0.5 1.0 1.5"}}`
+		_, err := c.parseCollectorOutput("host", []byte(out))
+		require.NoError(t, err)
+	})
+
+	t.Run("not JSON", func(t *testing.T) {
+		_, err := c.parseCollectorOutput("host", []byte("sh: 1: syntax error"))
+		assert.Error(t, err)
+	})
+}
+
+func TestParseCollectorOutput_Reservations(t *testing.T) {
+	c := NewCollector(map[string]config.Host{})
+	c.SetLockConfig(config.LockConfig{Stale: 10 * time.Minute})
+
+	reservations := "1000000\n" +
+		`999990 /tmp/rr.reservations/a.json {"user":"alice","hostname":"laptop","pid":12,"cpus":8,"memory_bytes":17179869184}` + "\n" +
+		`999800 /tmp/rr.reservations/b.json {"user":"bob","hostname":"desk","pid":34,"cpus":4}` + "\n" +
+		`100 /tmp/rr.reservations/c.json {"user":"carol","hostname":"old","pid":56,"cpus":16}`
+	section, err := json.Marshal(reservations)
+	require.NoError(t, err)
+
+	out := `{"version":1,"platform":"linux","sections":{"reservations":` + string(section) + `}}`
+	metrics, err := c.parseCollectorOutput("host", []byte(out))
+	require.NoError(t, err)
+	require.NotNil(t, metrics.Reservations)
+	assert.Equal(t, 12.0, metrics.Reservations.CPUs)
+	assert.Equal(t, int64(16<<30), metrics.Reservations.MemoryBytes)
+	assert.Equal(t, []string{"alice@laptop (pid 12)", "bob@desk (pid 34)"}, metrics.Reservations.Holders)
+
+	// No section, or no lock config, means no reservations
+	metrics, err = c.parseCollectorOutput("host", []byte(`{"version":1,"platform":"linux","sections":{}}`))
+	require.NoError(t, err)
+	assert.Nil(t, metrics.Reservations)
+}
+
+func TestCollectorScript_ListsReservations(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.json"), []byte(`{"user":"alice","cpus":2}`+"\n"), 0644))
+
+	out, err := exec.Command("sh", "-c", collectorScript, "collector.sh", dir).Output()
+	require.NoError(t, err)
+
+	c := NewCollector(map[string]config.Host{})
+	c.SetLockConfig(config.LockConfig{Stale: 10 * time.Minute})
+	metrics, err := c.parseCollectorOutput("local", out)
+	require.NoError(t, err)
+	require.NotNil(t, metrics.Reservations)
+	assert.Equal(t, 2.0, metrics.Reservations.CPUs)
+}
+
+func TestIsCollectorMissing(t *testing.T) {
+	assert.True(t, isCollectorMissing([]byte(collectorMissing+"\n")))
+	assert.True(t, isCollectorMissing([]byte("motd\n"+collectorMissing+"\n")))
+	assert.False(t, isCollectorMissing([]byte(`{"version":1}`)))
+}
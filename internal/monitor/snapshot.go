@@ -0,0 +1,215 @@
+package monitor
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// snapshotCPUSample is how long Snapshot waits between its two readings of a
+// Linux host's CPU counters. Linux CPU usage comes from the difference
+// between readings, so a single one would report 0%.
+var snapshotCPUSample = time.Second
+
+// SnapshotHost is one host's row in a monitor snapshot.
+type SnapshotHost struct {
+	Host          string       `json:"host"`
+	Reachable     bool         `json:"reachable"`
+	Error         string       `json:"error,omitempty"`
+	OS            string       `json:"os,omitempty"`
+	LatencyMS     float64      `json:"latency_ms,omitempty"`
+	CPUPercent    float64      `json:"cpu_percent"`
+	Cores         int          `json:"cores,omitempty"`
+	LoadAvg       [3]float64   `json:"load_avg"`
+	RAMUsedBytes  int64        `json:"ram_used_bytes"`
+	RAMTotalBytes int64        `json:"ram_total_bytes"`
+	RAMPercent    float64      `json:"ram_percent"`
+	GPU           *SnapshotGPU `json:"gpu,omitempty"`
+	Locked        bool         `json:"locked"`
+	LockHolder    string       `json:"lock_holder,omitempty"`
+	LockUser      string       `json:"lock_user,omitempty"`
+	LockProject   string       `json:"lock_project,omitempty"`
+	LockCommand   string       `json:"lock_command,omitempty"`
+	lockActivity  string       // Badge for the table, like "alice is running pytest on project app, 12m elapsed"
+}
+
+// SnapshotGPU is a host's GPU in a monitor snapshot.
+type SnapshotGPU struct {
+	Name             string  `json:"name"`
+	Percent          float64 `json:"percent"`
+	MemoryUsedBytes  int64   `json:"memory_used_bytes"`
+	MemoryTotalBytes int64   `json:"memory_total_bytes"`
+	Temperature      int     `json:"temperature_c,omitempty"`
+}
+
+// Snapshot collects one round of metrics from the hosts in order and returns
+// a row per host, in that order. Linux hosts read for the first time are read
+// twice, snapshotCPUSample apart, so their CPU usage is current. Later
+// snapshots from the same collector measure CPU usage since the last one.
+func (c *Collector) Snapshot(ctx context.Context, order []string) []SnapshotHost {
+	c.mu.Lock()
+	hadReading := make(map[string]bool, len(order))
+	for _, alias := range order {
+		_, hadReading[alias] = c.prevJiffies[alias]
+	}
+	c.mu.Unlock()
+
+	results := make(map[string]HostResult, len(order))
+	for result := range c.CollectStreamingHosts(ctx, order) {
+		results[result.Alias] = result
+	}
+
+	// Hosts that left their first CPU reading behind need a second one
+	c.mu.Lock()
+	var resample []string
+	for _, alias := range order {
+		if _, ok := c.prevJiffies[alias]; ok && !hadReading[alias] && results[alias].Metrics != nil {
+			resample = append(resample, alias)
+		}
+	}
+	c.mu.Unlock()
+
+	if len(resample) > 0 {
+		select {
+		case <-ctx.Done():
+		case <-time.After(snapshotCPUSample):
+			for result := range c.CollectStreamingHosts(ctx, resample) {
+				// Keep the first reading if the second one fails
+				if result.Metrics != nil {
+					results[result.Alias] = result
+				}
+			}
+		}
+	}
+
+	rows := make([]SnapshotHost, 0, len(order))
+	for _, alias := range order {
+		result, ok := results[alias]
+		if !ok {
+			result = HostResult{Alias: alias, Error: ctx.Err()}
+		}
+		rows = append(rows, snapshotRow(result))
+	}
+	return rows
+}
+
+// snapshotRow turns a host's collection result into its snapshot row.
+func snapshotRow(result HostResult) SnapshotHost {
+	row := SnapshotHost{Host: result.Alias}
+	m := result.Metrics
+	if m == nil {
+		row.Error = "no metrics collected"
+		if result.Error != nil {
+			row.Error = result.Error.Error()
+		}
+		return row
+	}
+
+	row.Reachable = true
+	row.OS = m.System.OS
+	row.LatencyMS = float64(result.Latency.Microseconds()) / 1000
+	row.CPUPercent = m.CPU.Percent
+	row.Cores = m.CPU.Cores
+	row.LoadAvg = m.CPU.LoadAvg
+	row.RAMUsedBytes = m.RAM.UsedBytes
+	row.RAMTotalBytes = m.RAM.TotalBytes
+	if m.RAM.TotalBytes > 0 {
+		row.RAMPercent = float64(m.RAM.UsedBytes) / float64(m.RAM.TotalBytes) * 100
+	}
+	if m.GPU != nil {
+		row.GPU = &SnapshotGPU{
+			Name:             m.GPU.Name,
+			Percent:          m.GPU.Percent,
+			MemoryUsedBytes:  m.GPU.MemoryUsed,
+			MemoryTotalBytes: m.GPU.MemoryTotal,
+			Temperature:      m.GPU.Temperature,
+		}
+	}
+	if result.LockInfo != nil && result.LockInfo.IsLocked {
+		row.Locked = true
+		row.LockHolder = result.LockInfo.Holder
+		row.LockUser = result.LockInfo.User
+		row.LockProject = result.LockInfo.Project
+		row.LockCommand = result.LockInfo.Command
+		if result.LockInfo.User != "" {
+			row.lockActivity = result.LockInfo.Badge()
+		}
+	}
+	return row
+}
+
+// WriteSnapshotTable writes a snapshot as an aligned plain-text table.
+func WriteSnapshotTable(w io.Writer, rows []SnapshotHost) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "HOST\tCPU\tLOAD\tRAM\tGPU\tLATENCY\tLOCK")
+	for _, r := range rows {
+		if !r.Reachable {
+			fmt.Fprintf(tw, "%s\tunreachable: %s\t\t\t\t\t\n", r.Host, r.Error)
+			continue
+		}
+		gpu := "-"
+		if r.GPU != nil {
+			gpu = fmt.Sprintf("%.0f%%", r.GPU.Percent)
+		}
+		lock := "-"
+		if r.Locked {
+			lock = r.lockActivity
+			if lock == "" {
+				lock = r.LockHolder
+			}
+			if lock == "" {
+				lock = "locked"
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%.0f%%\t%.2f\t%.0f%% (%s / %s)\t%s\t%.0fms\t%s\n",
+			r.Host, r.CPUPercent, r.LoadAvg[0],
+			r.RAMPercent, formatBytes(r.RAMUsedBytes), formatBytes(r.RAMTotalBytes),
+			gpu, r.LatencyMS, lock)
+	}
+	return tw.Flush()
+}
+
+// snapshotCSVHeader names the columns WriteSnapshotCSV writes.
+var snapshotCSVHeader = []string{
+	"host", "reachable", "error", "os", "latency_ms",
+	"cpu_percent", "cores", "load_1", "load_5", "load_15",
+	"ram_used_bytes", "ram_total_bytes", "ram_percent",
+	"gpu_name", "gpu_percent", "gpu_memory_used_bytes", "gpu_memory_total_bytes", "gpu_temperature_c",
+	"locked", "lock_holder", "lock_user", "lock_project", "lock_command",
+}
+
+// WriteSnapshotCSV writes a snapshot as CSV with a header row, one row per
+// host. GPU columns are empty for hosts without a GPU.
+func WriteSnapshotCSV(w io.Writer, rows []SnapshotHost) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(snapshotCSVHeader); err != nil {
+		return err
+	}
+	float := func(f float64) string { return strconv.FormatFloat(f, 'f', 2, 64) }
+	for _, r := range rows {
+		var gpu [5]string
+		if r.GPU != nil {
+			gpu = [5]string{
+				r.GPU.Name, float(r.GPU.Percent),
+				strconv.FormatInt(r.GPU.MemoryUsedBytes, 10), strconv.FormatInt(r.GPU.MemoryTotalBytes, 10),
+				strconv.Itoa(r.GPU.Temperature),
+			}
+		}
+		record := []string{
+			r.Host, strconv.FormatBool(r.Reachable), r.Error, r.OS, float(r.LatencyMS),
+			float(r.CPUPercent), strconv.Itoa(r.Cores), float(r.LoadAvg[0]), float(r.LoadAvg[1]), float(r.LoadAvg[2]),
+			strconv.FormatInt(r.RAMUsedBytes, 10), strconv.FormatInt(r.RAMTotalBytes, 10), float(r.RAMPercent),
+			gpu[0], gpu[1], gpu[2], gpu[3], gpu[4],
+			strconv.FormatBool(r.Locked), r.LockHolder, r.LockUser, r.LockProject, r.LockCommand,
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
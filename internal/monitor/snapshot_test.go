@@ -0,0 +1,123 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func snapshotRows() []SnapshotHost {
+	return []SnapshotHost{
+		snapshotRow(HostResult{
+			Alias:   "gpu-box",
+			Latency: 12 * time.Millisecond,
+			Metrics: &HostMetrics{
+				CPU:    CPUMetrics{Percent: 42.5, Cores: 16, LoadAvg: [3]float64{3.5, 2.1, 1.0}},
+				RAM:    RAMMetrics{UsedBytes: 8 << 30, TotalBytes: 32 << 30},
+				GPU:    &GPUMetrics{Name: "RTX 4090", Percent: 90, MemoryUsed: 20 << 30, MemoryTotal: 24 << 30, Temperature: 70},
+				System: SystemInfo{OS: "linux"},
+			},
+			LockInfo: &HostLockInfo{
+				IsLocked: true, Holder: "alice@laptop (pid 42)", User: "alice", Project: "app", Command: "pytest",
+				Started: time.Now().Add(-12 * time.Minute),
+			},
+		}),
+		snapshotRow(HostResult{Alias: "mini", Error: errors.New("connection refused")}),
+	}
+}
+
+func TestSnapshotRow(t *testing.T) {
+	rows := snapshotRows()
+
+	up := rows[0]
+	assert.True(t, up.Reachable)
+	assert.Equal(t, 12.0, up.LatencyMS)
+	assert.Equal(t, 25.0, up.RAMPercent)
+	require.NotNil(t, up.GPU)
+	assert.Equal(t, "RTX 4090", up.GPU.Name)
+	assert.True(t, up.Locked)
+	assert.Equal(t, "alice@laptop (pid 42)", up.LockHolder)
+	assert.Equal(t, "alice", up.LockUser)
+	assert.Equal(t, "app", up.LockProject)
+	assert.Equal(t, "pytest", up.LockCommand)
+
+	down := rows[1]
+	assert.False(t, down.Reachable)
+	assert.Equal(t, "connection refused", down.Error)
+	assert.Nil(t, down.GPU)
+}
+
+func TestWriteSnapshotTable(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteSnapshotTable(&buf, snapshotRows()))
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 3)
+	assert.True(t, strings.HasPrefix(lines[0], "HOST"))
+	assert.Contains(t, lines[1], "gpu-box")
+	assert.Contains(t, lines[1], "42%")
+	assert.Contains(t, lines[1], "25% (8.0 GB / 32.0 GB)")
+	assert.Contains(t, lines[1], "90%")
+	assert.Contains(t, lines[1], "12ms")
+	assert.Contains(t, lines[1], "alice is running pytest on project app, 12m elapsed")
+	assert.Contains(t, lines[2], "unreachable: connection refused")
+}
+
+func TestWriteSnapshotCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteSnapshotCSV(&buf, snapshotRows()))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, snapshotCSVHeader, records[0])
+	for _, record := range records[1:] {
+		assert.Len(t, record, len(snapshotCSVHeader))
+	}
+
+	col := func(record []string, name string) string {
+		for i, h := range snapshotCSVHeader {
+			if h == name {
+				return record[i]
+			}
+		}
+		t.Fatalf("no column %q", name)
+		return ""
+	}
+	assert.Equal(t, "gpu-box", col(records[1], "host"))
+	assert.Equal(t, "42.50", col(records[1], "cpu_percent"))
+	assert.Equal(t, "RTX 4090", col(records[1], "gpu_name"))
+	assert.Equal(t, "true", col(records[1], "locked"))
+	assert.Equal(t, "alice", col(records[1], "lock_user"))
+	assert.Equal(t, "false", col(records[2], "reachable"))
+	assert.Equal(t, "connection refused", col(records[2], "error"))
+	assert.Equal(t, "", col(records[2], "gpu_name"))
+}
+
+func TestSnapshot_CanceledReportsEveryHost(t *testing.T) {
+	c := NewCollector(map[string]config.Host{
+		"a": {SSH: []string{"a.invalid"}},
+		"b": {SSH: []string{"b.invalid"}},
+	})
+	defer c.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rows := c.Snapshot(ctx, []string{"b", "a"})
+
+	require.Len(t, rows, 2)
+	assert.Equal(t, "b", rows[0].Host)
+	assert.Equal(t, "a", rows[1].Host)
+	for _, r := range rows {
+		assert.False(t, r.Reachable)
+		assert.NotEmpty(t, r.Error)
+	}
+}
@@ -0,0 +1,61 @@
+package monitor
+
+import (
+	"fmt"
+	"os/exec"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rileyhilliard/rr/internal/config"
+)
+
+// sshDoneMsg is sent when an SSH session started from the dashboard exits.
+type sshDoneMsg struct {
+	host string
+	err  error
+}
+
+// sshCommand builds the ssh invocation for an interactive shell on a host,
+// going through the alias the dashboard is connected with so it lands on the
+// same machine over the same network path, with the host's ssh_options.
+func sshCommand(alias string, h config.Host) *exec.Cmd {
+	args := []string{}
+	if h.IdentityFile != "" {
+		args = append(args, "-i", config.ExpandTilde(h.IdentityFile))
+	}
+	args = append(args, h.SSHOptions.Args()...)
+	args = append(args, alias)
+	return exec.Command("ssh", args...)
+}
+
+// sshCmd suspends the dashboard and opens an SSH shell on the selected host.
+// The dashboard comes back when the shell exits.
+func (m *Model) sshCmd() tea.Cmd {
+	if len(m.hosts) == 0 || m.selected >= len(m.hosts) {
+		return nil
+	}
+	name := m.hosts[m.selected]
+
+	alias := m.sshAlias[name]
+	if alias == "" {
+		m.notice = fmt.Sprintf("Can't SSH to %s: not connected yet", name)
+		return nil
+	}
+
+	var h config.Host
+	if m.collector != nil {
+		h = m.collector.hosts[name]
+	}
+	return tea.ExecProcess(sshCommand(alias, h), func(err error) tea.Msg {
+		return sshDoneMsg{host: name, err: err}
+	})
+}
+
+// handleSSHDone runs when the dashboard resumes after an SSH session. It
+// reports a session that failed and refreshes right away, since metrics went
+// stale while the dashboard was suspended.
+func (m *Model) handleSSHDone(msg sshDoneMsg) tea.Cmd {
+	if msg.err != nil {
+		m.notice = fmt.Sprintf("SSH to %s ended: %v", msg.host, msg.err)
+	}
+	return m.collectCmd()
+}
@@ -0,0 +1,48 @@
+package monitor
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSHCommand(t *testing.T) {
+	cmd := sshCommand("m4-tailscale", config.Host{})
+	assert.Equal(t, []string{"ssh", "m4-tailscale"}, cmd.Args)
+
+	cmd = sshCommand("m4-lan", config.Host{IdentityFile: "/keys/id_ed25519"})
+	assert.Equal(t, []string{"ssh", "-i", "/keys/id_ed25519", "m4-lan"}, cmd.Args)
+
+	cmd = sshCommand("m4-wan", config.Host{SSHOptions: config.SSHOptions{Compression: true}})
+	assert.Equal(t, []string{"ssh", "-o", "Compression=yes", "m4-wan"}, cmd.Args)
+}
+
+func TestSSHCmd_NotConnected(t *testing.T) {
+	hosts := map[string]config.Host{"server1": {SSH: []string{"user@server1"}}}
+	m := NewModel(NewCollector(hosts), time.Second, 0, nil)
+
+	assert.Nil(t, m.sshCmd())
+	assert.Contains(t, m.notice, "not connected yet")
+}
+
+func TestSSHCmd_Connected(t *testing.T) {
+	hosts := map[string]config.Host{"server1": {SSH: []string{"user@server1"}}}
+	m := NewModel(NewCollector(hosts), time.Second, 0, nil)
+	m.sshAlias["server1"] = "user@server1"
+
+	assert.NotNil(t, m.sshCmd())
+	assert.Empty(t, m.notice)
+}
+
+func TestHandleSSHDone(t *testing.T) {
+	m := NewModel(NewCollector(map[string]config.Host{}), time.Second, 0, nil)
+
+	m.handleSSHDone(sshDoneMsg{host: "server1"})
+	assert.Empty(t, m.notice)
+
+	m.handleSSHDone(sshDoneMsg{host: "server1", err: errors.New("exit status 255")})
+	assert.Equal(t, "SSH to server1 ended: exit status 255", m.notice)
+}
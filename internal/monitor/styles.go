@@ -1,13 +1,17 @@
 package monitor
 
 import (
+	"hash/fnv"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/lock"
+	"github.com/rileyhilliard/rr/internal/ui"
 )
 
-// Dashboard color palette - Gen Z Electric Synthwave
-const (
+// Dashboard color palette - Gen Z Electric Synthwave. These are the
+// synthwave defaults; applyTheme reassigns them from the active ui theme.
+var (
 	// Background colors (glassmorphism-inspired)
 	ColorDarkBg    = lipgloss.Color("#0A0A0F") // Deep void
 	ColorSurfaceBg = lipgloss.Color("#12121A") // Dark surface
@@ -29,8 +33,57 @@ const (
 
 	// Graph colors
 	ColorGraph = lipgloss.Color("#00FFFF") // Neon cyan
+
+	// Latency colors between healthy and critical
+	ColorLatencySlow   = synthwaveLatencySlow
+	ColorLatencyNormal = synthwaveLatencyNormal
 )
 
+// Latency colors for the synthwave theme, which has no equivalent in other
+// themes' palettes.
+const (
+	synthwaveLatencySlow   = lipgloss.Color("#FFCC00") // Yellow for slow (was orange)
+	synthwaveLatencyNormal = lipgloss.Color("#00D7FF") // Cyan for normal (still good, no concern)
+)
+
+func init() {
+	buildStyles()
+	ui.OnThemeChange(applyTheme)
+}
+
+// applyTheme rebuilds the dashboard palette and styles from the active ui
+// theme. The synthwave theme keeps the dashboard's own tuned colors.
+func applyTheme() {
+	t := ui.CurrentTheme()
+	if synthwave, _ := ui.ResolveTheme(ui.DefaultThemeName, nil); t == synthwave {
+		ColorLatencySlow = synthwaveLatencySlow
+		ColorLatencyNormal = synthwaveLatencyNormal
+		SpinnerColorFrames = synthwaveSpinnerColors
+	} else {
+		ColorLatencySlow = t.Warning
+		ColorLatencyNormal = t.Info
+		SpinnerColorFrames = []lipgloss.Color{t.Warning}
+	}
+
+	ColorDarkBg = t.Background
+	ColorSurfaceBg = t.Surface
+	ColorBorder = t.Border
+
+	ColorHealthy = t.AccentAlt
+	ColorWarning = t.Highlight
+	ColorCritical = t.Accent
+
+	ColorTextPrimary = t.Text
+	ColorTextSecondary = t.TextSecondary
+	ColorTextMuted = t.Muted
+
+	ColorAccent = t.Accent
+	ColorAccentDim = t.Highlight
+	ColorGraph = t.AccentAlt
+
+	buildStyles()
+}
+
 // Thresholds for metric severity levels
 const (
 	WarningThreshold  = 70.0
@@ -51,67 +104,104 @@ const (
 	GPUTempCritical = 80 // >= 80C is hot
 )
 
-// Base styles for the dashboard
+// Base styles for the dashboard, built from the palette by buildStyles
 var (
+	DashboardStyle         lipgloss.Style
+	HeaderStyle            lipgloss.Style
+	FooterStyle            lipgloss.Style
+	CardStyle              lipgloss.Style
+	CardSelectedStyle      lipgloss.Style
+	HostNameStyle          lipgloss.Style
+	LabelStyle             lipgloss.Style
+	ValueStyle             lipgloss.Style
+	StatusConnectingStyle  lipgloss.Style
+	StatusIdleStyle        lipgloss.Style
+	StatusRunningStyle     lipgloss.Style
+	StatusSlowStyle        lipgloss.Style
+	StatusUnreachableStyle lipgloss.Style
+	StatusTextStyle        lipgloss.Style
+	StatusRunningTextStyle lipgloss.Style
+)
+
+// buildStyles (re)creates the package-level styles from the current palette.
+func buildStyles() {
 	// Container styles
 	DashboardStyle = lipgloss.NewStyle().
-			Background(ColorDarkBg)
+		Background(ColorDarkBg)
 
 	HeaderStyle = lipgloss.NewStyle().
-			Foreground(ColorTextPrimary).
-			Background(ColorSurfaceBg).
-			Bold(true).
-			Padding(0, 1)
+		Foreground(ColorTextPrimary).
+		Background(ColorSurfaceBg).
+		Bold(true).
+		Padding(0, 1)
 
 	FooterStyle = lipgloss.NewStyle().
-			Foreground(ColorTextMuted).
-			Padding(0, 1)
+		Foreground(ColorTextMuted).
+		Padding(0, 1)
 
 	// Card styles - no background set here, each line handles its own
 	// Note: No horizontal padding here - renderCardLine handles symmetric padding
 	CardStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(ColorBorder).
-			MarginRight(1).
-			MarginBottom(1)
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorBorder).
+		MarginRight(1).
+		MarginBottom(1)
 
 	CardSelectedStyle = CardStyle.
-				BorderForeground(ColorAccent)
+		BorderForeground(ColorAccent)
 
 	// Text styles
 	HostNameStyle = lipgloss.NewStyle().
-			Foreground(ColorTextPrimary).
-			Bold(true)
+		Foreground(ColorTextPrimary).
+		Bold(true)
 
 	LabelStyle = lipgloss.NewStyle().
-			Foreground(ColorTextSecondary)
+		Foreground(ColorTextSecondary)
 
 	ValueStyle = lipgloss.NewStyle().
-			Foreground(ColorTextPrimary)
+		Foreground(ColorTextPrimary)
 
 	// Status indicator styles
 	StatusConnectingStyle = lipgloss.NewStyle().
-				Foreground(ColorTextSecondary)
+		Foreground(ColorTextSecondary)
 
 	StatusIdleStyle = lipgloss.NewStyle().
-			Foreground(ColorHealthy)
+		Foreground(ColorHealthy)
 
 	StatusRunningStyle = lipgloss.NewStyle().
-				Foreground(ColorWarning)
+		Foreground(ColorWarning)
 
 	StatusSlowStyle = lipgloss.NewStyle().
-			Foreground(ColorWarning)
+		Foreground(ColorWarning)
 
 	StatusUnreachableStyle = lipgloss.NewStyle().
-				Foreground(ColorCritical)
+		Foreground(ColorCritical)
 
 	// Status text styles (for the "- idle", "- running" suffix)
 	StatusTextStyle = lipgloss.NewStyle().
-			Foreground(ColorTextMuted)
+		Foreground(ColorTextMuted)
 
 	StatusRunningTextStyle = lipgloss.NewStyle().
-				Foreground(ColorWarning)
-)
+		Foreground(ColorWarning)
+
+	// Detail view and help overlay
+	detailSectionStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorBorder).
+		Padding(0, 1).
+		MarginBottom(1)
+
+	helpBoxStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorAccent).
+		Background(ColorSurfaceBg).
+		Padding(1, 2)
+
+	helpTitleStyle = lipgloss.NewStyle().
+		Foreground(ColorAccent).
+		Bold(true).
+		MarginBottom(1)
+}
 
 // Status indicator characters - cyber glyphs
 const (
@@ -132,7 +222,10 @@ var RunningSpinnerFrames = []string{"⣾", "⣽", "⣻", "⢿", "⡿", "⣟", "
 
 // SpinnerColorFrames defines the gen-z color cycling for animated spinners
 // Cycles through neon colors for a vibrant effect
-var SpinnerColorFrames = []lipgloss.Color{
+var SpinnerColorFrames = synthwaveSpinnerColors
+
+// synthwaveSpinnerColors are the spinner colors for the default theme.
+var synthwaveSpinnerColors = []lipgloss.Color{
 	lipgloss.Color("#FFAA00"), // Electric amber
 	lipgloss.Color("#FF8800"), // Orange
 	lipgloss.Color("#FFCC00"), // Gold
@@ -207,9 +300,9 @@ func LatencyColor(ms float64) lipgloss.Color {
 	case ms >= LatencyDegraded:
 		return ColorCritical
 	case ms >= LatencyNormal:
-		return lipgloss.Color("#FFCC00") // Yellow for slow (was orange)
+		return ColorLatencySlow
 	case ms >= LatencyFast:
-		return lipgloss.Color("#00D7FF") // Cyan for normal (still good, no concern)
+		return ColorLatencyNormal
 	default:
 		return ColorHealthy
 	}
@@ -388,7 +481,7 @@ func SectionHeader(title, value string, width int) string {
 	// Style the parts - neon pink title, cyan value
 	borderStyle := lipgloss.NewStyle().Foreground(ColorBorder)
 	titleStyle := lipgloss.NewStyle().Foreground(ColorAccent).Bold(true)
-	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#00FFFF")).Bold(true)
+	valueStyle := lipgloss.NewStyle().Foreground(ColorGraph).Bold(true)
 
 	return borderStyle.Render("╭─ ") +
 		titleStyle.Render(title) +
@@ -439,3 +532,22 @@ func SectionContentLine(content string, width int) string {
 
 	return borderStyle.Render("│") + " " + content + strings.Repeat(" ", padding) + " " + borderStyle.Render("│")
 }
+
+// avatarColor picks the color of a user's avatar from the palette. It's
+// derived from the name, so each user keeps one color across hosts and
+// refreshes.
+func avatarColor(user string) lipgloss.Color {
+	colors := []lipgloss.Color{ColorHealthy, ColorWarning, ColorCritical, ColorLatencySlow, ColorLatencyNormal}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(user))
+	return colors[h.Sum32()%uint32(len(colors))]
+}
+
+// RenderAvatar renders a user's initials as a small colored badge.
+func RenderAvatar(user string) string {
+	return lipgloss.NewStyle().
+		Foreground(ColorDarkBg).
+		Background(avatarColor(user)).
+		Bold(true).
+		Render(" " + lock.Initials(user) + " ")
+}
@@ -4,7 +4,10 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/ui"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // stripAnsi removes ANSI escape codes from a string for test assertions.
@@ -327,3 +330,26 @@ func TestGetRunningSpinner(t *testing.T) {
 	char8, _ := GetRunningSpinner(8)
 	assert.Equal(t, char0, char8)
 }
+
+func TestApplyTheme_RebuildsStyles(t *testing.T) {
+	synthwave, err := ui.ResolveTheme("", nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { ui.ApplyTheme(synthwave) })
+
+	light, err := ui.ResolveTheme("light", nil)
+	require.NoError(t, err)
+	ui.ApplyTheme(light)
+
+	assert.Equal(t, light.AccentAlt, ColorHealthy)
+	assert.Equal(t, light.Accent, ColorCritical)
+	assert.Equal(t, light.Text, ColorTextPrimary)
+	assert.Equal(t, lipgloss.Color(light.Border), CardStyle.GetBorderTopForeground())
+	assert.Equal(t, light.Warning, LatencyColor(LatencyNormal+1))
+	assert.Equal(t, []lipgloss.Color{light.Warning}, SpinnerColorFrames)
+
+	// Back to synthwave restores the dashboard's own tuned colors
+	ui.ApplyTheme(synthwave)
+	assert.Equal(t, synthwaveLatencySlow, LatencyColor(LatencyNormal+1))
+	assert.Equal(t, synthwaveSpinnerColors, SpinnerColorFrames)
+	assert.Equal(t, lipgloss.Color("#2A2A4A"), CardStyle.GetBorderTopForeground())
+}
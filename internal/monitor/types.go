@@ -1,16 +1,45 @@
 package monitor
 
-import "time"
+import (
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/lock"
+)
 
 // HostMetrics contains all collected metrics from a remote host.
 type HostMetrics struct {
-	Timestamp time.Time
-	CPU       CPUMetrics
-	RAM       RAMMetrics
-	GPU       *GPUMetrics // nil if no GPU
-	Network   []NetworkInterface
-	Processes []ProcessInfo
-	System    SystemInfo
+	Timestamp  time.Time
+	CPU        CPUMetrics
+	RAM        RAMMetrics
+	GPU        *GPUMetrics // nil if no GPU
+	Network    []NetworkInterface
+	Processes  []ProcessInfo
+	System     SystemInfo
+	Containers *ContainerMetrics // nil if not virtualized and no containers running
+
+	// Reservations are the CPUs and memory rr runs have reserved on the
+	// host (nil if none)
+	Reservations *ReservationMetrics
+
+	// Watchers are the watch tasks this machine runs on the host
+	Watchers []WatcherMetrics
+}
+
+// WatcherMetrics is a watch task running on a host.
+type WatcherMetrics struct {
+	Task     string
+	Command  string
+	Running  bool      // false while waiting to restart
+	Since    time.Time // When the command last started or exited
+	Restarts int
+	Ports    []string
+}
+
+// ReservationMetrics totals the live reservations rr runs hold on a host.
+type ReservationMetrics struct {
+	CPUs        float64
+	MemoryBytes int64
+	Holders     []string // Who holds each reservation, e.g. "alice@laptop (pid 123)"
 }
 
 // CPUMetrics contains CPU usage information.
@@ -38,6 +67,41 @@ type GPUMetrics struct {
 	PowerWatts  int
 }
 
+// ContainerMetrics summarizes containers running on a host and whether the
+// host itself is a VM or container. Containers often explain load that
+// doesn't show up clearly in the host process list.
+type ContainerMetrics struct {
+	Virtualization string          // e.g. "kvm", "vmware", "lxc" (empty on bare metal or if unknown)
+	Runtimes       []string        // Runtimes with running containers (docker, podman, lxc)
+	Containers     []ContainerInfo // Running containers, busiest first
+	CPUPercent     float64         // Aggregate CPU across containers (100% = one core)
+	MemoryBytes    int64           // Aggregate memory used by containers
+}
+
+// ContainerInfo describes a single running container.
+type ContainerInfo struct {
+	Runtime     string
+	Name        string
+	CPUPercent  float64 // 0 when the runtime doesn't report stats (lxc)
+	MemoryBytes int64
+}
+
+// Count returns the number of running containers.
+func (c *ContainerMetrics) Count() int {
+	if c == nil {
+		return 0
+	}
+	return len(c.Containers)
+}
+
+// Top returns the busiest container, or nil if none are running.
+func (c *ContainerMetrics) Top() *ContainerInfo {
+	if c.Count() == 0 {
+		return nil
+	}
+	return &c.Containers[0]
+}
+
 // NetworkInterface contains network I/O statistics for a single interface.
 type NetworkInterface struct {
 	Name       string
@@ -71,6 +135,22 @@ type HostLockInfo struct {
 	Holder   string    // Description of who holds the lock (user@host)
 	Started  time.Time // When the lock was acquired
 	Command  string    // Command being executed (if available)
+	User     string    // User who took the lock
+	Project  string    // Project the lock was taken for (empty for locks from older rr versions)
+	Count    int       // Locks held on the host; more than 1 with project or command scoped locks
+}
+
+// Activity describes what the lock holder is doing, like "alice is running
+// pytest on project app".
+func (l HostLockInfo) Activity() string {
+	info := lock.LockInfo{User: l.User, Command: l.Command, Project: l.Project}
+	return info.Activity()
+}
+
+// Badge is Activity with how long the lock has been held, like "alice is
+// running pytest on project app, 12m elapsed".
+func (l HostLockInfo) Badge() string {
+	return l.Activity() + ", " + lock.FormatElapsed(l.Duration()) + " elapsed"
 }
 
 // HostResult is the result of collecting metrics from a single host.
@@ -110,6 +190,12 @@ func (l HostLockInfo) FormatDuration() string {
 	return formatInt(seconds) + "s"
 }
 
+// FormatUptime returns how long the watcher's command has been up or down,
+// in the same format as HostLockInfo.FormatDuration.
+func (w WatcherMetrics) FormatUptime() string {
+	return HostLockInfo{Started: w.Since}.FormatDuration()
+}
+
 // formatInt converts an integer to a string without importing strconv.
 func formatInt(n int) string {
 	if n == 0 {
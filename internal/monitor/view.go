@@ -44,6 +44,10 @@ func (m Model) renderDashboard() string {
 
 	content := b.String()
 
+	if m.showCardSettings {
+		return m.renderCardSettingsOverlay()
+	}
+
 	// If help is showing, overlay the help box
 	if m.showHelp {
 		return m.renderHelpOverlay(content)
@@ -238,6 +242,10 @@ func (m Model) cardsPerRow(cardWidth int) int {
 
 // renderFooter renders the keyboard help footer.
 func (m Model) renderFooter() string {
+	if m.notice != "" {
+		return FooterStyle.Render(m.notice)
+	}
+
 	layout := m.LayoutMode()
 
 	var hints []string
@@ -247,15 +255,17 @@ func (m Model) renderFooter() string {
 		hints = []string{"q quit", "? help"}
 	case LayoutCompact:
 		// Compact hints
-		hints = []string{"q quit", "r refresh", "s sort", "? help"}
+		hints = []string{"q quit", "r refresh", "s ssh", "? help"}
 	default:
 		// Full hints for wider terminals
 		hints = []string{
 			"q quit",
 			"r refresh",
-			"s sort",
+			"o sort",
+			"s ssh",
 			"\u2191\u2193 select",
 			"Enter expand",
+			"c cards",
 			"? help",
 		}
 	}
@@ -308,6 +318,10 @@ func (m *Model) updateListViewportContent() {
 
 // renderListFooterWithScroll renders the footer with scroll position indicator for list view.
 func (m Model) renderListFooterWithScroll() string {
+	if m.notice != "" {
+		return FooterStyle.Render(m.notice)
+	}
+
 	layout := m.LayoutMode()
 
 	var hints []string
@@ -329,7 +343,7 @@ func (m Model) renderListFooterWithScroll() string {
 		if isScrollable {
 			hints = append(hints, "pgup/dn scroll")
 		}
-		hints = append(hints, "q quit", "r refresh", "s sort", "? help")
+		hints = append(hints, "q quit", "r refresh", "s ssh", "? help")
 	default:
 		if isScrollable {
 			hints = append(hints, "pgup/dn scroll")
@@ -337,9 +351,11 @@ func (m Model) renderListFooterWithScroll() string {
 		hints = append(hints,
 			"q quit",
 			"r refresh",
-			"s sort",
+			"o sort",
+			"s ssh",
 			"\u2191\u2193 select",
 			"Enter expand",
+			"c cards",
 			"? help",
 		)
 	}
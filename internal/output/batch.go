@@ -0,0 +1,65 @@
+package output
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"time"
+)
+
+// BatchWriter holds what's written to it and passes it on in one write at
+// most once per interval, instead of a write per line. Flush passes on
+// whatever is left.
+type BatchWriter struct {
+	w        io.Writer
+	interval time.Duration
+
+	mu    sync.Mutex
+	buf   bytes.Buffer
+	last  time.Time
+	timer *time.Timer
+}
+
+// NewBatchWriter creates a writer that passes writes on to w in batches.
+func NewBatchWriter(w io.Writer, interval time.Duration) *BatchWriter {
+	return &BatchWriter{w: w, interval: interval}
+}
+
+// Write holds p until the interval since the last batch has passed.
+func (b *BatchWriter) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.buf.Write(p)
+	wait := b.interval - time.Since(b.last)
+	if wait <= 0 {
+		return len(p), b.flushLocked()
+	}
+	// Nothing else may be written for a while, so send this batch on time
+	if b.timer == nil {
+		b.timer = time.AfterFunc(wait, func() { _ = b.Flush() })
+	}
+	return len(p), nil
+}
+
+// Flush passes on everything held so far.
+func (b *BatchWriter) Flush() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.flushLocked()
+}
+
+// flushLocked writes out the batch. Must be called with b.mu held.
+func (b *BatchWriter) flushLocked() error {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	b.last = time.Now()
+	if b.buf.Len() == 0 {
+		return nil
+	}
+	_, err := b.w.Write(b.buf.Bytes())
+	b.buf.Reset()
+	return err
+}
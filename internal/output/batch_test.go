@@ -0,0 +1,56 @@
+package output
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lockedBuffer is a bytes.Buffer safe to write from a timer goroutine.
+type lockedBuffer struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	writes int
+}
+
+func (l *lockedBuffer) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.writes++
+	return l.buf.Write(p)
+}
+
+func (l *lockedBuffer) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.buf.String()
+}
+
+func TestBatchWriter_Batches(t *testing.T) {
+	out := &lockedBuffer{}
+	b := NewBatchWriter(out, time.Hour)
+
+	for _, line := range []string{"one\n", "two\n", "three\n"} {
+		_, err := b.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, "one\n", out.String(), "the first write goes out at once, the rest wait")
+
+	require.NoError(t, b.Flush())
+	assert.Equal(t, "one\ntwo\nthree\n", out.String())
+	assert.Equal(t, 2, out.writes)
+}
+
+func TestBatchWriter_SendsOnTime(t *testing.T) {
+	out := &lockedBuffer{}
+	b := NewBatchWriter(out, 20*time.Millisecond)
+
+	_, _ = b.Write([]byte("first\n"))
+	_, _ = b.Write([]byte("second\n"))
+	assert.Eventually(t, func() bool { return out.String() == "first\nsecond\n" },
+		time.Second, 5*time.Millisecond, "a held batch goes out without another write")
+}
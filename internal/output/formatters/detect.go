@@ -12,27 +12,72 @@ type Detector interface {
 	Detect(command string, output []byte) int
 }
 
+// Extractor pulls test failures out of output no built-in formatter
+// recognized. It returns nil when it doesn't recognize the output either.
+type Extractor func(command string, rawOutput []byte) []output.TestFailure
+
+// namedExtractor is an Extractor and the source name its failures get.
+type namedExtractor struct {
+	name string
+	fn   Extractor
+}
+
+// extractors are the fallbacks registered with RegisterExtractor, in order.
+var extractors []namedExtractor
+
+// RegisterExtractor adds a fallback for output the built-in formatters
+// don't recognize. Fallbacks are tried in the order they were registered
+// until one finds failures; name is reported as their source.
+func RegisterExtractor(name string, fn Extractor) {
+	extractors = append(extractors, namedExtractor{name: name, fn: fn})
+}
+
 // ExtractFailures detects the test framework from command/output and extracts
 // structured failure information. Returns nil if no failures found or format unknown.
 func ExtractFailures(command string, rawOutput []byte) []output.TestFailure {
+	_, failures := ExtractFailuresWithSource(command, rawOutput)
+	return failures
+}
+
+// ExtractFailuresWithSource is ExtractFailures, also returning the name of
+// the formatter that parsed the output ("pytest", "gotest", "jest", or a
+// registered extractor's name), or "" if the format is unknown.
+func ExtractFailuresWithSource(command string, rawOutput []byte) (string, []output.TestFailure) {
+	formatter := ParseOutput(command, rawOutput)
+	if formatter == nil {
+		return extractWithFallbacks(command, rawOutput)
+	}
+
+	// Extract failures if formatter supports it
+	if provider, ok := formatter.(output.TestSummaryProvider); ok {
+		return formatter.Name(), provider.GetTestFailures()
+	}
+
+	return formatter.Name(), nil
+}
+
+// ParseOutput runs a command's captured output through the formatter for
+// its test framework and returns the formatter, so its test counts and
+// failures can be read. Returns nil if the format is unknown.
+func ParseOutput(command string, rawOutput []byte) output.Formatter {
 	formatter := detectFormatter(command, rawOutput)
 	if formatter == nil {
 		return nil
 	}
-
-	// Process all output through the formatter
-	outputStr := string(rawOutput)
-	lines := strings.Split(outputStr, "\n")
-	for _, line := range lines {
+	for _, line := range strings.Split(string(rawOutput), "\n") {
 		formatter.ProcessLine(line)
 	}
+	return formatter
+}
 
-	// Extract failures if formatter supports it
-	if provider, ok := formatter.(output.TestSummaryProvider); ok {
-		return provider.GetTestFailures()
+// extractWithFallbacks tries the registered extractors in order.
+func extractWithFallbacks(command string, rawOutput []byte) (string, []output.TestFailure) {
+	for _, e := range extractors {
+		if failures := e.fn(command, rawOutput); len(failures) > 0 {
+			return e.name, failures
+		}
 	}
-
-	return nil
+	return "", nil
 }
 
 // detectorFormatter is a formatter that also implements detection.
@@ -41,6 +86,21 @@ type detectorFormatter interface {
 	Detector
 }
 
+// ForName returns a new formatter for an output format name from config
+// ("pytest", "jest", or "go"). Returns nil for names without a dedicated
+// formatter, like "auto", "generic", and "cargo".
+func ForName(name string) output.Formatter {
+	switch name {
+	case "pytest":
+		return NewPytestFormatter()
+	case "jest":
+		return NewJestFormatter()
+	case "go":
+		return NewGoTestFormatter()
+	}
+	return nil
+}
+
 // detectFormatter returns the best matching formatter for the command/output.
 // Returns nil if no specific formatter matches well.
 func detectFormatter(command string, rawOutput []byte) output.Formatter {
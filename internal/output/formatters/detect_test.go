@@ -3,6 +3,7 @@ package formatters
 import (
 	"testing"
 
+	"github.com/rileyhilliard/rr/internal/output"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -38,6 +39,26 @@ FAILED tests/test_example.py::test_fail - AssertionError: assert 1 == 2
 	assert.Contains(t, failures[0].Message, "AssertionError")
 }
 
+func TestExtractFailuresWithSource(t *testing.T) {
+	output := []byte(`
+=== RUN   TestFail
+    example_test.go:15: Expected 1, got 2
+--- FAIL: TestFail (0.00s)
+FAIL
+FAIL	example	0.005s
+`)
+
+	source, failures := ExtractFailuresWithSource("go test ./...", output)
+	assert.Equal(t, "gotest", source)
+	assert.Len(t, failures, 1)
+	assert.Equal(t, "example_test.go", failures[0].File)
+	assert.Equal(t, 15, failures[0].Line)
+
+	source, failures = ExtractFailuresWithSource("make build", []byte("compiling...\ndone\n"))
+	assert.Empty(t, source)
+	assert.Nil(t, failures)
+}
+
 func TestExtractFailures_GoTest(t *testing.T) {
 	command := "go test ./..."
 	output := []byte(`
@@ -67,6 +88,14 @@ func TestExtractFailures_UnknownFormat(t *testing.T) {
 	assert.Nil(t, failures)
 }
 
+func TestForName(t *testing.T) {
+	assert.Equal(t, "pytest", ForName("pytest").Name())
+	assert.Equal(t, "jest", ForName("jest").Name())
+	assert.Equal(t, "gotest", ForName("go").Name())
+	assert.Nil(t, ForName("auto"))
+	assert.Nil(t, ForName("cargo"))
+}
+
 func TestFormatFailureSummary_LimitFailures(t *testing.T) {
 	command := "pytest tests/"
 	output := []byte(`
@@ -135,3 +164,19 @@ FAILED tests/test_example.py::test_e
 	// Should indicate more failures exist
 	assert.Contains(t, summary, "and 2 more failures")
 }
+
+func TestExtractFailuresWithSource_FallsBackToExtractors(t *testing.T) {
+	t.Cleanup(func() { extractors = nil })
+	RegisterExtractor("silent", func(string, []byte) []output.TestFailure { return nil })
+	RegisterExtractor("junit", func(command string, _ []byte) []output.TestFailure {
+		return []output.TestFailure{{TestName: "LoginTest", Message: command}}
+	})
+
+	source, failures := ExtractFailuresWithSource("./gradlew test", []byte("BUILD FAILED\n"))
+	assert.Equal(t, "junit", source)
+	assert.Equal(t, []output.TestFailure{{TestName: "LoginTest", Message: "./gradlew test"}}, failures)
+
+	// Output a built-in formatter recognizes never reaches the fallbacks
+	source, _ = ExtractFailuresWithSource("go test ./...", []byte("--- FAIL: TestX (0.00s)\nFAIL\n"))
+	assert.Equal(t, "gotest", source)
+}
@@ -44,6 +44,7 @@ type GoTestFormatter struct {
 	currentTest  string
 	currentPkg   string
 	failureLines []string
+	failureLoc   string // First file:line reported by the current test
 }
 
 // NewGoTestFormatter creates a new go test output formatter.
@@ -120,6 +121,7 @@ func (f *GoTestFormatter) ProcessLine(line string) string {
 	// === RUN TestName
 	if matches := runPattern.FindStringSubmatch(line); matches != nil {
 		f.currentTest = matches[1]
+		f.failureLoc = ""
 		return f.mutedStyle.Render(line)
 	}
 
@@ -132,6 +134,7 @@ func (f *GoTestFormatter) ProcessLine(line string) string {
 			Status:   "PASS",
 			Duration: duration,
 		})
+		f.failureLoc = ""
 		f.currentTest = ""
 		return f.passStyle.Render(line)
 	}
@@ -144,10 +147,12 @@ func (f *GoTestFormatter) ProcessLine(line string) string {
 			Name:     testName,
 			Status:   "FAIL",
 			Duration: duration,
+			Location: f.failureLoc,
 			Output:   append([]string{}, f.failureLines...),
 		}
 		f.tests = append(f.tests, result)
 		f.failureLines = nil
+		f.failureLoc = ""
 		f.currentTest = ""
 		return f.failStyle.Render(line)
 	}
@@ -220,6 +225,9 @@ func (f *GoTestFormatter) ProcessLine(line string) string {
 		if f.currentTest != "" {
 			location := matches[1]
 			message := matches[2]
+			if f.failureLoc == "" {
+				f.failureLoc = location
+			}
 			// Store for when the test result comes in
 			f.failureLines = append(f.failureLines, fmt.Sprintf("%s: %s", location, message))
 		}
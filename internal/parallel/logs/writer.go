@@ -97,6 +97,29 @@ func (w *LogWriter) WriteTask(taskName string, taskIndex int, output []byte) err
 	return nil
 }
 
+// MoveTask moves a task's output from the file it spilled to during the run
+// into <taskname>_<index>.log, and returns the new path.
+func (w *LogWriter) MoveTask(taskName string, taskIndex int, outputFile string) (string, error) {
+	if w.closed {
+		return "", errors.New(errors.ErrExec,
+			"Log writer is closed",
+			"This is unexpected - create a new LogWriter.")
+	}
+
+	logPath := filepath.Join(w.taskDir, taskLogFilename(taskName, taskIndex))
+	if err := os.Rename(outputFile, logPath); err != nil {
+		return "", errors.WrapWithCode(err, errors.ErrExec,
+			"Can't move task log to "+logPath,
+			"The full output is still in "+outputFile+".")
+	}
+	return logPath, nil
+}
+
+// TaskPath returns the path of a task's log file.
+func (w *LogWriter) TaskPath(taskName string, taskIndex int) string {
+	return filepath.Join(w.taskDir, taskLogFilename(taskName, taskIndex))
+}
+
 // taskLogFilename returns the log filename for a task with its index.
 func taskLogFilename(taskName string, taskIndex int) string {
 	return fmt.Sprintf("%s_%d.log", sanitizeFilename(taskName), taskIndex)
@@ -67,6 +67,28 @@ func TestLogWriter_WriteTask(t *testing.T) {
 	assert.Equal(t, output, content)
 }
 
+func TestLogWriter_MoveTask(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	writer, err := NewLogWriter(tmpDir, "test-task")
+	require.NoError(t, err)
+
+	spilled := filepath.Join(writer.Dir(), "my-task-123.log")
+	require.NoError(t, os.WriteFile(spilled, []byte("lots of output\n"), 0600))
+
+	path, err := writer.MoveTask("my-task", 2, spilled)
+	require.NoError(t, err)
+	assert.Equal(t, writer.TaskPath("my-task", 2), path)
+	assert.NoFileExists(t, spilled)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "lots of output\n", string(content))
+
+	_, err = writer.MoveTask("my-task", 3, spilled)
+	assert.Error(t, err)
+}
+
 func TestLogWriter_WriteTask_DifferentIndices(t *testing.T) {
 	tmpDir := t.TempDir()
 
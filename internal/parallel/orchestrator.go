@@ -3,6 +3,8 @@ package parallel
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -395,6 +397,7 @@ func (o *Orchestrator) hostWorkerWithRequeue(
 		}
 
 		// Execute the task
+		task = o.refresh(task)
 		taskStart := time.Now()
 		result, requeue := worker.executeTaskWithRequeue(ctx, task)
 
@@ -444,6 +447,16 @@ func (o *Orchestrator) hostWorkerWithRequeue(
 	}
 }
 
+// refresh returns the task as it should run now, per Config.Refresh.
+func (o *Orchestrator) refresh(task TaskInfo) TaskInfo {
+	if o.config.Refresh == nil {
+		return task
+	}
+	refreshed := o.config.Refresh(task)
+	refreshed.Name, refreshed.Index = task.Name, task.Index
+	return refreshed
+}
+
 // buildResult constructs the final Result from collected task results.
 func (o *Orchestrator) buildResult(duration time.Duration, hostsUsed map[string]bool) *Result {
 	o.resultsMu.Lock()
@@ -470,6 +483,18 @@ func (o *Orchestrator) buildResult(duration time.Duration, hostsUsed map[string]
 	return result
 }
 
+// lockKey identifies this run for a command-scoped lock: the sorted names of
+// its subtasks, so rerunning the same parallel task waits for itself while
+// a different one on the same project doesn't.
+func (o *Orchestrator) lockKey() string {
+	names := make([]string, len(o.tasks))
+	for i, t := range o.tasks {
+		names[i] = t.Name
+	}
+	sort.Strings(names)
+	return strings.Join(names, "\n")
+}
+
 // markHostSynced marks a host as synced and returns whether it was already synced.
 func (o *Orchestrator) markHostSynced(hostName string) bool {
 	o.syncMu.Lock()
@@ -651,7 +676,7 @@ func (o *Orchestrator) runLocal(ctx context.Context) (*Result, error) {
 			break
 		}
 
-		result := worker.executeTask(ctx, task)
+		result := worker.executeTask(ctx, o.refresh(task))
 		o.results = append(o.results, result)
 
 		// Check fail-fast
@@ -88,6 +88,16 @@ func TestOrchestrator_HostPriorityOrder(t *testing.T) {
 	})
 }
 
+func TestOrchestrator_LockKey(t *testing.T) {
+	hosts := map[string]config.Host{"dev": {SSH: []string{"dev"}}}
+	a := NewOrchestrator([]TaskInfo{{Name: "test"}, {Name: "lint"}}, hosts, nil, nil, Config{})
+	b := NewOrchestrator([]TaskInfo{{Name: "lint"}, {Name: "test"}}, hosts, nil, nil, Config{})
+	c := NewOrchestrator([]TaskInfo{{Name: "lint"}}, hosts, nil, nil, Config{})
+
+	assert.Equal(t, a.lockKey(), b.lockKey(), "order doesn't matter")
+	assert.NotEqual(t, a.lockKey(), c.lockKey())
+}
+
 func TestOrchestrator_EmptyTasks(t *testing.T) {
 	orch := NewOrchestrator(nil, map[string]config.Host{
 		"dev": {SSH: []string{"dev"}, Dir: "~/projects"},
@@ -128,6 +138,23 @@ func TestOrchestrator_EmptyHostMap_RunsLocally(t *testing.T) {
 	assert.Contains(t, result.HostsUsed, "local")
 }
 
+func TestOrchestrator_Refresh(t *testing.T) {
+	tasks := []TaskInfo{{Name: "test", Command: "exit 1"}}
+	orch := NewOrchestrator(tasks, nil, nil, nil, Config{
+		Refresh: func(task TaskInfo) TaskInfo {
+			return TaskInfo{Name: "renamed", Index: 7, Command: "true"}
+		},
+	})
+
+	result, err := orch.Run(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Passed, "the refreshed command ran")
+	require.Len(t, result.TaskResults, 1)
+	assert.Equal(t, "test", result.TaskResults[0].TaskName, "refresh can't rename a task")
+	assert.Equal(t, 0, result.TaskResults[0].TaskIndex)
+}
+
 func TestOrchestrator_LocalExecution_FailedTask(t *testing.T) {
 	tasks := []TaskInfo{{Name: "fail", Command: "exit 1"}}
 	orch := NewOrchestrator(tasks, nil, nil, nil, Config{})
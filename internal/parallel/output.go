@@ -14,7 +14,8 @@ import (
 	"golang.org/x/term"
 )
 
-// maxOutputBufferSize limits memory usage for buffered task output (1MB per task)
+// maxOutputBufferSize limits memory usage for buffered task output (1MB per task).
+// Past it the oldest output is dropped, keeping a rolling tail.
 const maxOutputBufferSize = 1 << 20
 
 // OutputManager handles output display for parallel task execution.
@@ -189,14 +190,18 @@ func (m *OutputManager) TaskOutput(taskName string, taskIndex int, line []byte,
 
 	tid := taskID(taskName, taskIndex)
 
-	// Buffer output for later display (with size limit to prevent unbounded growth)
+	// Buffer output for later display, keeping only the last
+	// maxOutputBufferSize so it can't grow without bound
 	if buf, ok := m.taskOutput[tid]; ok {
-		if buf.Len() < maxOutputBufferSize {
-			buf.Write(line)
-			buf.WriteByte('\n')
-		} else if !m.taskTruncated[tid] {
+		buf.Write(line)
+		buf.WriteByte('\n')
+		if over := buf.Len() - maxOutputBufferSize; over > 0 {
+			buf.Next(over)
+			// Start at a line boundary
+			if i := bytes.IndexByte(buf.Bytes(), '\n'); i >= 0 {
+				buf.Next(i + 1)
+			}
 			m.taskTruncated[tid] = true
-			buf.WriteString("\n... output truncated (exceeded 1MB) ...\n")
 		}
 	}
 
@@ -244,6 +249,18 @@ func (m *OutputManager) TaskRequeued(taskName string, taskIndex int, unavailable
 	}
 }
 
+// Warn shows a warning about the run as a whole, in every mode.
+func (m *OutputManager) Warn(msg string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.progress != nil {
+		m.progress.Warn(msg)
+		return
+	}
+	fmt.Fprintf(m.w, "%s %s\n", ui.SymbolWarning, msg)
+}
+
 // TaskCompleted is called when a task finishes execution.
 func (m *OutputManager) TaskCompleted(result TaskResult) {
 	m.mu.Lock()
@@ -324,6 +341,9 @@ func (m *OutputManager) renderVerboseCompletion(result TaskResult, status TaskSt
 	tid := result.ID()
 	if buf, ok := m.taskOutput[tid]; ok && buf.Len() > 0 {
 		fmt.Fprintf(m.w, "%s\n", m.mutedStyle.Render(strings.Repeat("-", 40)))
+		if m.taskTruncated[tid] || result.OutputTruncated() {
+			fmt.Fprintf(m.w, "%s\n", m.mutedStyle.Render("... earlier output not shown ..."))
+		}
 		fmt.Fprintf(m.w, "%s\n", buf.String())
 	}
 }
@@ -2,6 +2,7 @@ package parallel
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 	"time"
 
@@ -187,6 +188,29 @@ func TestOutputManager_BuffersOutput(t *testing.T) {
 	assert.Contains(t, buf.String(), "second line")
 }
 
+func TestOutputManager_BuffersTail(t *testing.T) {
+	var buf bytes.Buffer
+	mgr := NewOutputManager(OutputVerbose, true)
+	mgr.SetWriter(&buf)
+
+	mgr.TaskSyncing("test", 0, "dev")
+	line := bytes.Repeat([]byte("x"), 1023)
+	for i := 0; i < 2*maxOutputBufferSize/1024; i++ {
+		mgr.TaskOutput("test", 0, line, false)
+	}
+	mgr.TaskOutput("test", 0, []byte("last line"), false)
+
+	mgr.mu.Lock()
+	held := mgr.taskOutput["test#0"].String()
+	mgr.mu.Unlock()
+	assert.LessOrEqual(t, len(held), maxOutputBufferSize)
+	assert.True(t, strings.HasSuffix(held, "last line\n"))
+	assert.True(t, strings.HasPrefix(held, string(line)), "the tail starts at a line")
+
+	mgr.TaskCompleted(TaskResult{TaskName: "test", Host: "dev"})
+	assert.Contains(t, buf.String(), "earlier output not shown")
+}
+
 func TestOutputManager_VerboseMode(t *testing.T) {
 	var buf bytes.Buffer
 	mgr := NewOutputManager(OutputVerbose, true)
@@ -390,6 +414,17 @@ func TestOutputManager_TaskRequeued_QuietMode(t *testing.T) {
 	assert.Contains(t, output, "unavailable")
 }
 
+func TestOutputManager_Warn(t *testing.T) {
+	for _, mode := range []OutputMode{OutputStream, OutputVerbose, OutputQuiet} {
+		var buf bytes.Buffer
+		mgr := NewOutputManager(mode, false)
+		mgr.SetWriter(&buf)
+
+		mgr.Warn(".rr.yaml changed")
+		assert.Contains(t, buf.String(), ".rr.yaml changed", "mode %s", mode)
+	}
+}
+
 func TestOutputManager_TaskRequeued_ResetsState(t *testing.T) {
 	mgr := NewOutputManager(OutputQuiet, true)
 
@@ -0,0 +1,88 @@
+package parallel
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Environment variables each shard of a sharded task runs with.
+const (
+	ShardIndexEnv = "SHARD_INDEX" // 1 to SHARD_TOTAL
+	ShardTotalEnv = "SHARD_TOTAL"
+)
+
+// shardNameRegex matches the names ShardName gives shards.
+var shardNameRegex = regexp.MustCompile(`^(.+)\[(\d+)/(\d+)\]$`)
+
+// ShardName names shard index (1-based) of total of a task, like "test[3/8]".
+func ShardName(task string, index, total int) string {
+	return fmt.Sprintf("%s[%d/%d]", task, index, total)
+}
+
+// ParseShardName splits a name from ShardName into the task and shard index.
+// ok is false for names that aren't a shard's.
+func ParseShardName(name string) (task string, index int, ok bool) {
+	m := shardNameRegex.FindStringSubmatch(name)
+	if m == nil {
+		return "", 0, false
+	}
+	index, _ = strconv.Atoi(m[2])
+	return m[1], index, true
+}
+
+// ShardTasks returns count copies of task, one per shard, each named with
+// ShardName and given its shard's environment on top of task.Env.
+func ShardTasks(task TaskInfo, count int) []TaskInfo {
+	tasks := make([]TaskInfo, count)
+	for i := range tasks {
+		env := make(map[string]string, len(task.Env)+2)
+		for k, v := range task.Env {
+			env[k] = v
+		}
+		env[ShardIndexEnv] = strconv.Itoa(i + 1)
+		env[ShardTotalEnv] = strconv.Itoa(count)
+
+		tasks[i] = task
+		tasks[i].Name = ShardName(task.Name, i+1, count)
+		tasks[i].Env = env
+	}
+	return tasks
+}
+
+// OrderByDuration sorts tasks so the ones expected to take longest are
+// handed to hosts first, which keeps a slow task from starting last and
+// holding up the run. Tasks with no expected duration go first, in their
+// original order, since nothing says they're quick.
+func OrderByDuration(tasks []TaskInfo, expected map[string]time.Duration) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		di, iok := expected[tasks[i].Name]
+		dj, jok := expected[tasks[j].Name]
+		if iok != jok {
+			return !iok
+		}
+		return di > dj
+	})
+}
+
+// lessTaskName orders task names alphabetically, except that shards of the
+// same task go in shard order, so "test[2/12]" comes before "test[10/12]".
+func lessTaskName(a, b string) bool {
+	ta, ia, aok := ParseShardName(a)
+	tb, ib, bok := ParseShardName(b)
+	if aok && bok && ta == tb {
+		return ia < ib
+	}
+	return a < b
+}
+
+// RetryCommand returns the command that reruns one task of a run: the task
+// itself, or for a shard, its task limited to that shard.
+func RetryCommand(name string) string {
+	if task, index, ok := ParseShardName(name); ok {
+		return fmt.Sprintf("rr %s --shard %d", task, index)
+	}
+	return "rr " + name
+}
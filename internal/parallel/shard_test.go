@@ -0,0 +1,60 @@
+package parallel
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShardTasks(t *testing.T) {
+	base := TaskInfo{Name: "test", Index: 4, Command: "pytest", Env: map[string]string{"CI": "1"}}
+	shards := ShardTasks(base, 3)
+
+	require.Len(t, shards, 3)
+	assert.Equal(t, "test[1/3]", shards[0].Name)
+	assert.Equal(t, "pytest", shards[2].Command)
+	assert.Equal(t, map[string]string{"CI": "1", ShardIndexEnv: "3", ShardTotalEnv: "3"}, shards[2].Env)
+	assert.Equal(t, map[string]string{"CI": "1"}, base.Env, "the task's own env is left alone")
+}
+
+func TestParseShardName(t *testing.T) {
+	task, index, ok := ParseShardName(ShardName("unit-tests", 12, 16))
+	assert.True(t, ok)
+	assert.Equal(t, "unit-tests", task)
+	assert.Equal(t, 12, index)
+
+	for _, name := range []string{"test", "test[1]", "test[a/2]"} {
+		_, _, ok := ParseShardName(name)
+		assert.False(t, ok, name)
+	}
+}
+
+func TestOrderByDuration(t *testing.T) {
+	tasks := ShardTasks(TaskInfo{Name: "test"}, 4)
+	OrderByDuration(tasks, map[string]time.Duration{
+		"test[1/4]": 10 * time.Second,
+		"test[2/4]": 90 * time.Second,
+		"test[4/4]": 30 * time.Second,
+	})
+
+	var names []string
+	for _, task := range tasks {
+		names = append(names, task.Name)
+	}
+	assert.Equal(t, []string{"test[3/4]", "test[2/4]", "test[4/4]", "test[1/4]"}, names,
+		"shards with no history go first, then slowest first")
+}
+
+func TestLessTaskName(t *testing.T) {
+	names := []string{"test[10/12]", "lint", "test[2/12]", "test[1/12]"}
+	sort.Slice(names, func(i, j int) bool { return lessTaskName(names[i], names[j]) })
+	assert.Equal(t, []string{"lint", "test[1/12]", "test[2/12]", "test[10/12]"}, names)
+}
+
+func TestRetryCommand(t *testing.T) {
+	assert.Equal(t, "rr test --shard 3", RetryCommand("test[3/8]"))
+	assert.Equal(t, "rr lint", RetryCommand("lint"))
+}
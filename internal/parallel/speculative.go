@@ -0,0 +1,186 @@
+package parallel
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// SpeculativeHosts is how many hosts a speculative task runs on at once.
+const SpeculativeHosts = 2
+
+// SpeculativeResult holds the outcome of a speculative run.
+type SpeculativeResult struct {
+	Winner   *TaskResult   // First attempt that succeeded; nil if none did
+	Attempts []TaskResult  // Every attempt in completion order, including cancelled ones
+	Duration time.Duration // Wall-clock time until the winner finished (or every attempt did)
+}
+
+// Success returns true if any attempt succeeded.
+func (r *SpeculativeResult) Success() bool {
+	return r.Winner != nil
+}
+
+// Outcome returns the attempt that decides the run: the winner, or if every
+// attempt failed, the first one that actually ran on a host. Returns nil if
+// there were no attempts.
+func (r *SpeculativeResult) Outcome() *TaskResult {
+	if r.Winner != nil {
+		return r.Winner
+	}
+	for i := range r.Attempts {
+		if r.Attempts[i].Host != "none" {
+			return &r.Attempts[i]
+		}
+	}
+	if len(r.Attempts) > 0 {
+		return &r.Attempts[0]
+	}
+	return nil
+}
+
+// Cancelled reports whether an attempt was stopped because another one won.
+func (r *SpeculativeResult) Cancelled(attempt *TaskResult) bool {
+	return r.Winner != nil && attempt.TaskIndex != r.Winner.TaskIndex &&
+		stderrors.Is(attempt.Error, context.Canceled)
+}
+
+// RunSpeculative runs the orchestrator's single task on the first
+// SpeculativeHosts hosts in priority order at the same time, and takes the
+// first successful result. As soon as one attempt succeeds the others are
+// cancelled, which masks a slow or flaky host at the cost of running the task
+// twice.
+//
+// An attempt whose host can't be reached moves on to the next untried host,
+// so the task still races on two hosts when one is down. A failed attempt
+// doesn't stop the others: the run only fails once every attempt has.
+func (o *Orchestrator) RunSpeculative(ctx context.Context) (*SpeculativeResult, error) {
+	if len(o.tasks) != 1 {
+		return nil, fmt.Errorf("speculative execution needs exactly one task, got %d", len(o.tasks))
+	}
+	if len(o.hostList) == 0 {
+		return nil, fmt.Errorf("speculative execution needs at least one host")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	o.cancelFunc = cancel
+	defer cancel()
+
+	racers := SpeculativeHosts
+	if racers > len(o.hostList) {
+		racers = len(o.hostList)
+	}
+
+	attempts := make([]TaskInfo, racers)
+	for i := range attempts {
+		attempts[i] = o.tasks[0]
+		attempts[i].Index = i
+	}
+
+	o.outputMgr = NewOutputManager(o.config.OutputMode, isTerminal())
+	defer o.outputMgr.Close()
+	o.outputMgr.InitTasks(attempts)
+
+	// Hosts are handed out in priority order, each to at most one attempt
+	var nextMu sync.Mutex
+	next := 0
+	nextHost := func() (string, bool) {
+		nextMu.Lock()
+		defer nextMu.Unlock()
+		if next >= len(o.hostList) {
+			return "", false
+		}
+		next++
+		return o.hostList[next-1], true
+	}
+
+	startTime := time.Now()
+	resultChan := make(chan TaskResult, racers)
+
+	var wg sync.WaitGroup
+	for _, attempt := range attempts {
+		wg.Add(1)
+		go func(task TaskInfo) {
+			defer wg.Done()
+			resultChan <- o.runAttempt(ctx, task, nextHost)
+		}(attempt)
+	}
+	go func() {
+		wg.Wait()
+		close(resultChan)
+	}()
+
+	result := &SpeculativeResult{}
+	winner := -1
+	for attempt := range resultChan {
+		result.Attempts = append(result.Attempts, attempt)
+		if winner < 0 && attempt.Success() {
+			winner = len(result.Attempts) - 1
+			result.Duration = time.Since(startTime)
+			cancel()
+		}
+	}
+	if winner >= 0 {
+		// Point into Attempts only once it has stopped growing
+		result.Winner = &result.Attempts[winner]
+	} else {
+		result.Duration = time.Since(startTime)
+	}
+
+	// Nobody looks at the output of an attempt that lost the race
+	for i := range result.Attempts {
+		if a := &result.Attempts[i]; a.OutputFile != "" && result.Cancelled(a) {
+			_ = os.Remove(a.OutputFile)
+			a.OutputFile = ""
+		}
+	}
+
+	return result, nil
+}
+
+// runAttempt runs one speculative attempt, moving on to the next untried
+// host whenever the current one is unreachable.
+func (o *Orchestrator) runAttempt(ctx context.Context, task TaskInfo, nextHost func() (string, bool)) TaskResult {
+	var failed bool
+	var failedMu sync.Mutex
+
+	for {
+		hostName, ok := nextHost()
+		if !ok || ctx.Err() != nil {
+			err := ctx.Err()
+			if err == nil {
+				err = fmt.Errorf("no more hosts to try")
+			}
+			now := time.Now()
+			return TaskResult{
+				TaskName:  task.Name,
+				TaskIndex: task.Index,
+				Command:   task.Command,
+				Host:      "none",
+				ExitCode:  1,
+				Error:     err,
+				StartTime: now,
+				EndTime:   now,
+			}
+		}
+
+		worker := &hostWorker{
+			orchestrator: o,
+			hostName:     hostName,
+			host:         o.hosts[hostName],
+			failed:       &failed,
+			failedMu:     &failedMu,
+		}
+		result, requeue := worker.executeTaskWithRequeue(ctx, task)
+		_ = worker.Close()
+
+		if !requeue {
+			return result
+		}
+		o.markHostUnavailable(hostName)
+		o.outputMgr.TaskRequeued(task.Name, task.Index, hostName)
+	}
+}
@@ -0,0 +1,65 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpeculativeResult_Outcome(t *testing.T) {
+	winner := TaskResult{TaskName: "test", TaskIndex: 1, Host: "b"}
+	loser := TaskResult{TaskName: "test", TaskIndex: 0, Host: "a", ExitCode: 130, Error: context.Canceled}
+
+	won := &SpeculativeResult{Attempts: []TaskResult{winner, loser}}
+	won.Winner = &won.Attempts[0]
+	assert.True(t, won.Success())
+	assert.Equal(t, "b", won.Outcome().Host)
+	assert.True(t, won.Cancelled(&won.Attempts[1]))
+	assert.False(t, won.Cancelled(&won.Attempts[0]))
+
+	lost := &SpeculativeResult{Attempts: []TaskResult{
+		{TaskIndex: 1, Host: "none", ExitCode: 1, Error: errors.New("no more hosts to try")},
+		{TaskIndex: 0, Host: "a", ExitCode: 2},
+	}}
+	assert.False(t, lost.Success())
+	assert.Equal(t, "a", lost.Outcome().Host, "prefers an attempt that actually ran")
+	assert.False(t, lost.Cancelled(&lost.Attempts[1]), "nothing is cancelled without a winner")
+
+	assert.Nil(t, (&SpeculativeResult{}).Outcome())
+}
+
+func TestOrchestrator_RunSpeculative_NeedsOneTask(t *testing.T) {
+	hosts := map[string]config.Host{"a": {SSH: []string{"fake-a"}}}
+	tasks := []TaskInfo{{Name: "a", Command: "true"}, {Name: "b", Index: 1, Command: "true"}}
+
+	_, err := NewOrchestrator(tasks, hosts, nil, nil, Config{}).RunSpeculative(context.Background())
+	assert.Error(t, err)
+}
+
+func TestOrchestrator_RunSpeculative_UnreachableHosts(t *testing.T) {
+	tasks := []TaskInfo{{Name: "test", Command: "echo test"}}
+	hosts := map[string]config.Host{
+		"a": {SSH: []string{"nonexistent-host-a"}, Dir: "~"},
+		"b": {SSH: []string{"nonexistent-host-b"}, Dir: "~"},
+		"c": {SSH: []string{"nonexistent-host-c"}, Dir: "~"},
+	}
+	orch := NewOrchestrator(tasks, hosts, []string{"a", "b", "c"}, nil, Config{OutputMode: OutputQuiet})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := orch.RunSpeculative(ctx)
+	require.NoError(t, err)
+	require.NoError(t, ctx.Err(), "RunSpeculative should finish once every host has been tried")
+
+	assert.False(t, result.Success())
+	assert.Len(t, result.Attempts, SpeculativeHosts, "one result per attempt")
+	for _, host := range []string{"a", "b", "c"} {
+		assert.True(t, orch.isHostUnavailable(host), "host %s should have been tried", host)
+	}
+}
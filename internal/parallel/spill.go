@@ -0,0 +1,135 @@
+package parallel
+
+import (
+	"bytes"
+	"os"
+	"sync"
+)
+
+// spillThreshold is how much of a task's output is kept in memory. Past it,
+// the output goes to a file instead, so a task that prints hundreds of MB
+// can't run rr out of memory.
+const spillThreshold = 8 << 20
+
+// spillTailSize is how much of the end of spilled output stays in memory,
+// for the summary and for parsing test failures. The end of the output is
+// the part that explains a failure.
+const spillTailSize = 1 << 20
+
+// spillBuffer collects a task's stdout and stderr. It's an in-memory buffer
+// until the output passes spillThreshold; from then on everything, including
+// what came before, is written to a file in dir and only the last
+// spillTailSize bytes stay in memory.
+type spillBuffer struct {
+	mu   sync.Mutex
+	dir  string // Where the file goes; empty for the system temp directory
+	name string // Task name, for the file name
+
+	buf  []byte
+	size int64
+	file *os.File
+	path string // The file's path, once the output spilled
+	// dropped is set when the output couldn't be written to a file, so
+	// only its tail was kept.
+	dropped bool
+}
+
+// newSpillBuffer returns an empty spillBuffer for the named task.
+func newSpillBuffer(dir, name string) *spillBuffer {
+	return &spillBuffer{dir: dir, name: name}
+}
+
+// Write implements io.Writer. It never fails: if the output can't be
+// written to a file, its start is dropped instead.
+func (b *spillBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.size += int64(len(p))
+	spilled := b.path != "" || b.dropped
+	if !spilled && len(b.buf)+len(p) > spillThreshold {
+		b.spill()
+		spilled = true
+	}
+	if b.file != nil {
+		if _, err := b.file.Write(p); err != nil {
+			b.closeFile()
+			b.dropped = true
+		}
+	}
+
+	b.buf = append(b.buf, p...)
+	// Trimming only once the tail has doubled keeps the copying linear
+	if spilled && len(b.buf) > 2*spillTailSize {
+		b.buf = append(b.buf[:0], b.buf[len(b.buf)-spillTailSize:]...)
+	}
+	return len(p), nil
+}
+
+// spill moves the buffered output to a new file.
+func (b *spillBuffer) spill() {
+	f, err := os.CreateTemp(b.dir, sanitizeTaskName(b.name)+"-*.log")
+	if err != nil {
+		b.dropped = true
+		return
+	}
+	if _, err := f.Write(b.buf); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		b.dropped = true
+		return
+	}
+	b.file, b.path = f, f.Name()
+}
+
+// closeFile gives up on the file, keeping only the tail in memory.
+func (b *spillBuffer) closeFile() {
+	_ = b.file.Close()
+	_ = os.Remove(b.path)
+	b.file, b.path = nil, ""
+}
+
+// Bytes returns the output, or once it spilled, its last spillTailSize
+// bytes starting at a line boundary.
+func (b *spillBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.path == "" && !b.dropped {
+		return b.buf
+	}
+	tail := b.buf
+	if len(tail) > spillTailSize {
+		tail = tail[len(tail)-spillTailSize:]
+	}
+	if i := bytes.IndexByte(tail, '\n'); i >= 0 && i < len(tail)-1 {
+		tail = tail[i+1:]
+	}
+	return append([]byte(nil), tail...)
+}
+
+// Size returns how many bytes were written in all.
+func (b *spillBuffer) Size() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.size
+}
+
+// Close finishes the file the output spilled to, and returns its path, or
+// "" if the output stayed in memory.
+func (b *spillBuffer) Close() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.file == nil {
+		return b.path
+	}
+	if err := b.file.Close(); err != nil {
+		b.file = nil
+		_ = os.Remove(b.path)
+		b.path, b.dropped = "", true
+		return ""
+	}
+	b.file = nil
+	return b.path
+}
@@ -0,0 +1,75 @@
+package parallel
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpillBuffer_StaysInMemory(t *testing.T) {
+	dir := t.TempDir()
+	b := newSpillBuffer(dir, "unit")
+
+	fmt.Fprintln(b, "ok")
+
+	assert.Equal(t, "", b.Close())
+	assert.Equal(t, "ok\n", string(b.Bytes()))
+	assert.Equal(t, int64(3), b.Size())
+	entries, _ := os.ReadDir(dir)
+	assert.Empty(t, entries)
+}
+
+func TestSpillBuffer_Spills(t *testing.T) {
+	dir := t.TempDir()
+	b := newSpillBuffer(dir, "e2e/chrome")
+
+	line := bytes.Repeat([]byte("x"), 1023)
+	var total int64
+	for i := 0; int64(i)*1024 <= spillThreshold+3*spillTailSize; i++ {
+		fmt.Fprintf(b, "%s\n", line)
+		total += 1024
+	}
+	fmt.Fprintln(b, "the end")
+	total += 8
+
+	path := b.Close()
+	require.NotEmpty(t, path)
+	assert.Equal(t, dir, filepath.Dir(path))
+	assert.Contains(t, path, "e2e-chrome-")
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, total, info.Size(), "the file has everything")
+	assert.Equal(t, total, b.Size())
+
+	tail := b.Bytes()
+	assert.LessOrEqual(t, len(tail), spillTailSize)
+	assert.True(t, bytes.HasSuffix(tail, []byte("the end\n")))
+	assert.True(t, bytes.HasPrefix(tail, line), "the tail starts at a line")
+}
+
+func TestSpillBuffer_NoFile(t *testing.T) {
+	b := newSpillBuffer("/nonexistent/dir", "unit")
+
+	b.Write(make([]byte, spillThreshold+1)) //nolint:errcheck // Never fails
+	fmt.Fprintln(b, "\nlast")
+
+	assert.Equal(t, "", b.Close())
+	assert.Equal(t, "last\n", string(b.Bytes()))
+	assert.Equal(t, int64(spillThreshold+7), b.Size())
+}
+
+func TestOutputSpillNote(t *testing.T) {
+	assert.Empty(t, OutputSpillNote(&TaskResult{Output: []byte("ok\n"), OutputSize: 3}))
+
+	tr := &TaskResult{Output: []byte("end\n"), OutputSize: 300 << 20, OutputFile: "/logs/unit_0.log"}
+	assert.Equal(t, "300.00 MB of output, full log: /logs/unit_0.log", OutputSpillNote(tr))
+
+	tr.OutputFile = ""
+	assert.Equal(t, "300.00 MB of output, only the last 4 B was kept", OutputSpillNote(tr))
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/rileyhilliard/rr/internal/output"
 	"github.com/rileyhilliard/rr/internal/output/formatters"
+	rrsync "github.com/rileyhilliard/rr/internal/sync"
 	"github.com/rileyhilliard/rr/internal/ui"
 )
 
@@ -68,7 +69,7 @@ func RenderSummaryTo(w io.Writer, result *Result, cfg SummaryConfig) {
 	sortedResults := make([]TaskResult, len(result.TaskResults))
 	copy(sortedResults, result.TaskResults)
 	sort.Slice(sortedResults, func(i, j int) bool {
-		return sortedResults[i].TaskName < sortedResults[j].TaskName
+		return lessTaskName(sortedResults[i].TaskName, sortedResults[j].TaskName)
 	})
 
 	// Per-task results
@@ -100,6 +101,9 @@ func RenderSummaryTo(w io.Writer, result *Result, cfg SummaryConfig) {
 			fmt.Fprintf(w, "    %s\n", mutedStyle.Render(statusText))
 			renderTaskFailures(w, tr, cfg.MaxOutputLines, errorStyle, mutedStyle)
 		}
+		if msg := OutputSpillNote(tr); msg != "" {
+			fmt.Fprintf(w, "    %s\n", mutedStyle.Render(msg))
+		}
 	}
 
 	fmt.Fprintln(w)
@@ -164,9 +168,9 @@ func RenderSummaryTo(w io.Writer, result *Result, cfg SummaryConfig) {
 
 		for i := range sortedResults {
 			if !sortedResults[i].Success() {
-				fmt.Fprintf(w, "  %s rr %s\n",
+				fmt.Fprintf(w, "  %s %s\n",
 					mutedStyle.Render("$"),
-					sortedResults[i].TaskName,
+					RetryCommand(sortedResults[i].TaskName),
 				)
 			}
 		}
@@ -207,6 +211,19 @@ func sanitizeTaskName(name string) string {
 	return string(result)
 }
 
+// OutputSpillNote says where a task's full output is when there was too
+// much of it to keep in memory, or "" if Output is all of it.
+func OutputSpillNote(tr *TaskResult) string {
+	if !tr.OutputTruncated() {
+		return ""
+	}
+	if tr.OutputFile == "" {
+		return fmt.Sprintf("%s of output, only the last %s was kept",
+			rrsync.FormatBytes(tr.OutputSize), rrsync.FormatBytes(int64(len(tr.Output))))
+	}
+	return fmt.Sprintf("%s of output, full log: %s", rrsync.FormatBytes(tr.OutputSize), tr.OutputFile)
+}
+
 // renderTaskFailures displays failure details for a failed task.
 func renderTaskFailures(w io.Writer, tr *TaskResult, maxLines int, errorStyle, mutedStyle lipgloss.Style) {
 	if len(tr.Output) == 0 {
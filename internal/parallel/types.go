@@ -28,6 +28,16 @@ type Config struct {
 	SaveLogs    bool          // Write output to log files
 	LogDir      string        // Directory for log files
 	Setup       string        // Command to run once per host before subtasks
+	Force       bool          // Sync even into a git checkout with uncommitted changes
+	Rebootstrap bool          // Run the project's bootstrap script even if it already ran on the host
+	Warm        time.Duration // Skip sync and setup left current by a run this recent (0 = off)
+	SyncProfile string        // Sync profile from sync.profiles (empty for the sync section's own settings)
+
+	// Refresh, if set, is called as a task is about to start and returns
+	// the task to run in its place, so changes to the config since the run
+	// started can reach tasks that haven't started. Name and Index must not
+	// change.
+	Refresh func(TaskInfo) TaskInfo
 }
 
 // DefaultConfig returns a Config with sensible defaults.
@@ -65,9 +75,14 @@ type TaskResult struct {
 	ExitCode  int
 	Duration  time.Duration
 	Error     error
-	Output    []byte // Captured stdout+stderr for summary
+	Output    []byte // Captured stdout+stderr for summary (just the end, if it spilled to OutputFile)
 	StartTime time.Time
 	EndTime   time.Time
+
+	// OutputFile holds the full output when there was too much of it to
+	// keep in memory. Empty if Output is all of it.
+	OutputFile string
+	OutputSize int64 // Bytes of output in all, kept or not
 }
 
 // ID returns a unique identifier for this task result.
@@ -80,6 +95,11 @@ func (r *TaskResult) Success() bool {
 	return r.ExitCode == 0 && r.Error == nil
 }
 
+// OutputTruncated reports whether Output is only the end of the task's output.
+func (r *TaskResult) OutputTruncated() bool {
+	return r.OutputSize > int64(len(r.Output))
+}
+
 // TaskStatus represents the current state of a task.
 type TaskStatus int
 
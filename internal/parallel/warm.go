@@ -0,0 +1,122 @@
+package parallel
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	rrsync "github.com/rileyhilliard/rr/internal/sync"
+	"github.com/rileyhilliard/rr/internal/util"
+)
+
+// warmStateDir is the directory under ~/.rr/ that holds warm host state.
+const warmStateDir = "warm"
+
+// warmState is what the last parallel run left behind on a host, so the next
+// run within the task's warm window can skip work that would redo it. It's
+// keyed on the local project directory and host.
+type warmState struct {
+	Host      string    `json:"host"`
+	LocalDir  string    `json:"local_dir"`
+	RemoteDir string    `json:"remote_dir"`
+	SyncKey   string    `json:"sync_key"` // Hash of the sync config the files were synced with
+	Synced    time.Time `json:"synced"`   // When that sync finished (the sync record's Finished)
+	Setup     string    `json:"setup,omitempty"`
+	SetupAt   time.Time `json:"setup_at,omitempty"` // When Setup last succeeded
+	Used      time.Time `json:"used"`               // When a run last used the host
+}
+
+// warmStatePath returns the state file for a local directory and host.
+func warmStatePath(localDir, hostName string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, config.GlobalConfigDir, warmStateDir, config.ProjectStateKey(localDir, hostName)+".json"), nil
+}
+
+// readWarmState loads the warm state of a host. Returns nil if there is none
+// or it's unreadable.
+func readWarmState(localDir, hostName string) *warmState {
+	path, err := warmStatePath(localDir, hostName)
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var s warmState
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil
+	}
+	return &s
+}
+
+// writeWarmState persists state atomically. Failures are ignored: without
+// it, the next run just syncs and runs setup again.
+func writeWarmState(s *warmState) {
+	path, err := warmStatePath(s.LocalDir, s.Host)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return
+	}
+	_ = util.WriteFileAtomic(path, data, 0644)
+}
+
+// syncKey hashes the sync config, so changing what gets synced invalidates
+// the warm state.
+func syncKey(cfg config.SyncConfig) string {
+	data, _ := json.Marshal(cfg)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// loadWarm returns the host's warm state if the files it synced are still
+// current, so this run can skip the sync: the state is usable (see usable)
+// and nothing under localDir changed since that sync started. Returns nil
+// otherwise, or when window is zero.
+func loadWarm(localDir, hostName, remoteDir string, syncCfg config.SyncConfig, window time.Duration) *warmState {
+	if window <= 0 {
+		return nil
+	}
+	s := readWarmState(localDir, hostName)
+	record := rrsync.ReadSyncRecord(localDir, hostName)
+	if !s.usable(record, remoteDir, syncKey(syncCfg), window, time.Now()) {
+		return nil
+	}
+	if changed, err := rrsync.ChangedSince(localDir, syncCfg, record.Started); err != nil || changed {
+		return nil
+	}
+	return s
+}
+
+// usable reports whether the warm state still describes the host at now:
+// used within window, for the same remote dir and sync config, and record
+// (the project's last sync to the host) is the sync it made, so no other rr
+// process or the sync daemon has synced since, and nothing was cut short.
+func (s *warmState) usable(record *rrsync.SyncRecord, remoteDir, key string, window time.Duration, now time.Time) bool {
+	if s == nil || record == nil || now.Sub(s.Used) > window {
+		return false
+	}
+	if s.RemoteDir != remoteDir || s.SyncKey != key {
+		return false
+	}
+	return !record.Interrupted() && record.Finished.Equal(s.Synced)
+}
+
+// canSkipSetup reports whether setup already succeeded on the files now on
+// the host.
+func (s *warmState) canSkipSetup(setup string) bool {
+	return s != nil && setup != "" && s.Setup == setup && !s.SetupAt.IsZero() && !s.SetupAt.Before(s.Synced)
+}
@@ -0,0 +1,91 @@
+package parallel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	rrsync "github.com/rileyhilliard/rr/internal/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarmState_RoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	localDir := t.TempDir()
+
+	assert.Nil(t, readWarmState(localDir, "mini"))
+
+	s := &warmState{Host: "mini", LocalDir: localDir, RemoteDir: "~/rr/app", SyncKey: "k", Synced: time.Now(), Used: time.Now()}
+	writeWarmState(s)
+
+	got := readWarmState(localDir, "mini")
+	require.NotNil(t, got)
+	assert.Equal(t, "~/rr/app", got.RemoteDir)
+	assert.True(t, got.Synced.Equal(s.Synced))
+	assert.Nil(t, readWarmState(localDir, "other"), "state is per host")
+}
+
+func TestWarmState_Usable(t *testing.T) {
+	now := time.Now()
+	synced := now.Add(-5 * time.Minute)
+	record := &rrsync.SyncRecord{Host: "mini", Started: synced.Add(-time.Second), Finished: synced}
+	state := &warmState{Host: "mini", RemoteDir: "~/rr/app", SyncKey: "k", Synced: synced, Used: now.Add(-2 * time.Minute)}
+
+	tests := []struct {
+		name   string
+		state  *warmState
+		record *rrsync.SyncRecord
+		remote string
+		key    string
+		want   bool
+	}{
+		{"current", state, record, "~/rr/app", "k", true},
+		{"no state", nil, record, "~/rr/app", "k", false},
+		{"never synced", state, nil, "~/rr/app", "k", false},
+		{"other remote dir", state, record, "~/rr/other", "k", false},
+		{"sync config changed", state, record, "~/rr/app", "k2", false},
+		{"synced since by another run", state, &rrsync.SyncRecord{Started: now.Add(-time.Minute), Finished: now}, "~/rr/app", "k", false},
+		{"sync cut short since", state, &rrsync.SyncRecord{Started: now, Finished: synced}, "~/rr/app", "k", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.state.usable(tt.record, tt.remote, tt.key, 15*time.Minute, now))
+		})
+	}
+
+	assert.False(t, state.usable(record, "~/rr/app", "k", time.Minute, now), "window passed")
+}
+
+func TestWarmState_CanSkipSetup(t *testing.T) {
+	synced := time.Now().Add(-time.Minute)
+	s := &warmState{Synced: synced, Setup: "npm ci", SetupAt: synced.Add(10 * time.Second)}
+
+	assert.True(t, s.canSkipSetup("npm ci"))
+	assert.False(t, s.canSkipSetup("npm install"), "setup command changed")
+	assert.False(t, s.canSkipSetup(""))
+	assert.False(t, (&warmState{Synced: synced, Setup: "npm ci", SetupAt: synced.Add(-time.Second)}).canSkipSetup("npm ci"), "files synced after setup ran")
+	assert.False(t, (*warmState)(nil).canSkipSetup("npm ci"))
+}
+
+func TestSyncKey(t *testing.T) {
+	a := syncKey(config.SyncConfig{Exclude: []string{".git/"}})
+	assert.Equal(t, a, syncKey(config.SyncConfig{Exclude: []string{".git/"}}))
+	assert.NotEqual(t, a, syncKey(config.SyncConfig{Exclude: []string{".git/", "dist/"}}))
+}
+
+func TestHostWorker_EnsureSetup_Warm(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	synced := time.Now().Add(-time.Minute)
+	orchestrator := NewOrchestrator(nil, nil, nil, nil, Config{Setup: "npm ci", Warm: 15 * time.Minute})
+
+	// No connection: running setup would fail, so success means it was skipped
+	w := &hostWorker{orchestrator: orchestrator, hostName: "mini", warm: &warmState{
+		Host: "mini", LocalDir: t.TempDir(), Synced: synced, Setup: "npm ci", SetupAt: synced.Add(time.Second),
+	}}
+	require.NoError(t, w.ensureSetup(context.Background()))
+
+	cold := &hostWorker{orchestrator: NewOrchestrator(nil, nil, nil, nil, Config{Setup: "npm ci"}), hostName: "mini"}
+	assert.Error(t, cold.ensureSetup(context.Background()))
+}
@@ -5,12 +5,15 @@ import (
 	"context"
 	stderrors "errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/rileyhilliard/rr/internal/bootstrap"
+	"github.com/rileyhilliard/rr/internal/cache"
 	"github.com/rileyhilliard/rr/internal/config"
 	"github.com/rileyhilliard/rr/internal/errors"
 	"github.com/rileyhilliard/rr/internal/host"
@@ -29,6 +32,10 @@ type hostWorker struct {
 	resultChan   chan<- TaskResult
 	failed       *bool
 	failedMu     *sync.Mutex
+
+	// warm is the host's warm state once ensureSync has synced or found the
+	// host current; nil when the task has no warm window.
+	warm *warmState
 }
 
 // executeTaskWithRequeue runs a single task on the host, returning whether the task
@@ -119,9 +126,8 @@ func (w *hostWorker) executeTaskInternal(ctx context.Context, task TaskInfo, res
 		defer cancel()
 	}
 
-	// Capture output
-	var outputBuf bytes.Buffer
-	var stderrBuf bytes.Buffer
+	// Capture output, spilling to a file if there's a lot of it
+	outputBuf := newSpillBuffer(w.orchestrator.config.LogDir, task.Name)
 
 	// Build the command
 	cmd := task.Command
@@ -131,27 +137,24 @@ func (w *hostWorker) executeTaskInternal(ctx context.Context, task TaskInfo, res
 	}
 
 	// Execute the command
-	exitCode, err := w.execCommand(execCtx, cmd, task.Env, workDir, &outputBuf, &stderrBuf)
+	exitCode, err := w.execCommand(execCtx, cmd, task.Env, workDir, outputBuf, outputBuf)
 
 	result.ExitCode = exitCode
 	result.Error = err
-	result.Output = append(outputBuf.Bytes(), stderrBuf.Bytes()...)
+	result.OutputFile = outputBuf.Close()
+	result.Output = outputBuf.Bytes()
+	result.OutputSize = outputBuf.Size()
 	result.EndTime = time.Now()
 	result.Duration = result.EndTime.Sub(result.StartTime)
 
 	// Stream output if in stream mode
 	if w.orchestrator.outputMgr != nil {
 		// Output line by line for stream mode
-		for _, line := range bytes.Split(outputBuf.Bytes(), []byte("\n")) {
+		for _, line := range bytes.Split(result.Output, []byte("\n")) {
 			if len(line) > 0 {
 				w.orchestrator.outputMgr.TaskOutput(task.Name, task.Index, line, false)
 			}
 		}
-		for _, line := range bytes.Split(stderrBuf.Bytes(), []byte("\n")) {
-			if len(line) > 0 {
-				w.orchestrator.outputMgr.TaskOutput(task.Name, task.Index, line, true)
-			}
-		}
 	}
 
 	w.notifyComplete(result)
@@ -195,16 +198,9 @@ func (w *hostWorker) ensureSync(_ context.Context) error {
 		return nil
 	}
 
-	// Use project root if available, otherwise fall back to cwd.
-	// This ensures parallel tasks sync from the correct directory when
-	// run from a subdirectory (matching the single-task workflow behavior).
-	workDir := resolveWorkDir(w.orchestrator.resolved)
-	if workDir == "" {
-		var err error
-		workDir, err = os.Getwd()
-		if err != nil {
-			return errors.Wrap(err, "resolve working directory for sync")
-		}
+	workDir, err := w.localDir()
+	if err != nil {
+		return err
 	}
 
 	// Acquire lock before syncing
@@ -214,8 +210,9 @@ func (w *hostWorker) ensureSync(_ context.Context) error {
 	}
 
 	if lockCfg.Enabled && w.conn != nil {
-		// Acquire lock with placeholder - UpdateCommand is called per-task with actual task name
-		hostLock, err := lock.Acquire(w.conn, lockCfg, "starting...")
+		// Acquire lock with placeholder - UpdateCommand is called per-task with actual task name.
+		// A command-scoped lock is keyed on the whole set of subtasks it covers.
+		hostLock, err := lock.Acquire(w.conn, lockCfg, "starting...", lock.WithCommandKey(w.orchestrator.lockKey()))
 		if err != nil {
 			return err
 		}
@@ -223,14 +220,120 @@ func (w *hostWorker) ensureSync(_ context.Context) error {
 		w.hostLock = hostLock
 	}
 
-	// Get sync config
+	if err := w.syncFiles(workDir); err != nil {
+		return err
+	}
+	return w.ensureBootstrap()
+}
+
+// syncFiles syncs the project from workDir to the host, unless a recent run
+// or the sync daemon already left it current.
+func (w *hostWorker) syncFiles(workDir string) error {
+	// Get sync config, with the run's sync profile applied. Validation
+	// already checked the profile exists.
 	syncCfg := config.DefaultConfig().Sync
 	if w.orchestrator.resolved != nil && w.orchestrator.resolved.Project != nil {
-		syncCfg = w.orchestrator.resolved.Project.Sync
+		syncCfg, _ = w.orchestrator.resolved.Project.Sync.WithProfile(w.orchestrator.config.SyncProfile)
+	}
+
+	// Skip the sync when the last run (within the warm window) left the
+	// host current, or the sync daemon is keeping it current
+	remoteDir := config.ExpandRemote(w.host.Dir)
+	window := w.orchestrator.config.Warm
+	if warm := loadWarm(workDir, w.hostName, remoteDir, syncCfg, window); warm != nil {
+		w.keepWarm(warm)
+		return nil
+	}
+	if w.orchestrator.config.SyncProfile == "" && rrsync.DaemonIsCurrent(workDir, w.hostName, remoteDir) {
+		w.recordWarmSync(workDir, remoteDir, syncCfg)
+		return nil
+	}
+
+	if !w.orchestrator.config.Force {
+		if err := rrsync.CheckRemoteEdits(w.conn, workDir, syncCfg); err != nil {
+			return err
+		}
+	}
+
+	// Compare file contents if the host's clock is too far off to trust mtimes
+	pushCfg := syncCfg
+	if w.conn != nil && w.conn.Client != nil {
+		if skew, err := rrsync.MeasureClockSkew(w.conn.Client); err == nil {
+			pushCfg = rrsync.ChecksumForSkew(syncCfg, skew)
+		}
+	}
+
+	if err := rrsync.Sync(w.conn, workDir, pushCfg, nil); err != nil {
+		return err
+	}
+	w.recordWarmSync(workDir, remoteDir, syncCfg)
+	return nil
+}
+
+// ensureBootstrap runs the project's bootstrap script if it hasn't run on
+// the host yet, or the run asked for it again. The script's output is only
+// shown if it fails.
+func (w *hostWorker) ensureBootstrap() error {
+	project := w.project()
+	if project == nil || project.Bootstrap == "" || w.conn == nil {
+		return nil
+	}
+	if !w.orchestrator.config.Rebootstrap {
+		needed, err := bootstrap.Needed(w.conn)
+		if err != nil {
+			return fmt.Errorf("bootstrap check failed: %w", err)
+		}
+		if !needed {
+			return nil
+		}
 	}
 
-	// Perform sync
-	return rrsync.Sync(w.conn, workDir, syncCfg, nil)
+	var output bytes.Buffer
+	if err := bootstrap.Run(w.conn, project.Bootstrap, &output, &output); err != nil {
+		return fmt.Errorf("%w: %s", err, output.String())
+	}
+	return nil
+}
+
+// recordWarmSync starts a new warm state for the files just synced to the
+// host (or pushed by the sync daemon), if the task keeps hosts warm.
+func (w *hostWorker) recordWarmSync(localDir, remoteDir string, syncCfg config.SyncConfig) {
+	if w.orchestrator.config.Warm <= 0 {
+		return
+	}
+	record := rrsync.ReadSyncRecord(localDir, w.hostName)
+	if record == nil {
+		return
+	}
+	w.keepWarm(&warmState{
+		Host:      w.hostName,
+		LocalDir:  localDir,
+		RemoteDir: remoteDir,
+		SyncKey:   syncKey(syncCfg),
+		Synced:    record.Finished,
+	})
+}
+
+// keepWarm marks the host's warm state as used now and saves it.
+func (w *hostWorker) keepWarm(s *warmState) {
+	s.Used = time.Now()
+	writeWarmState(s)
+	w.warm = s
+}
+
+// localDir returns the directory to sync from: the project root if
+// available, otherwise cwd. This ensures parallel tasks sync from the
+// correct directory when run from a subdirectory (matching the single-task
+// workflow behavior).
+func (w *hostWorker) localDir() (string, error) {
+	if dir := resolveWorkDir(w.orchestrator.resolved); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", errors.Wrap(err, "resolve working directory for sync")
+	}
+	return dir, nil
 }
 
 // ensureSetup runs the setup command once per host after sync.
@@ -248,6 +351,13 @@ func (w *hostWorker) ensureSetup(ctx context.Context) error {
 		return previousErr
 	}
 
+	// Setup already succeeded on the files now on the host in an earlier run
+	setup := w.orchestrator.config.Setup
+	if w.warm.canSkipSetup(setup) {
+		w.orchestrator.recordHostSetup(w.hostName, nil)
+		return nil
+	}
+
 	// Get working directory for setup command
 	workDir := ""
 	if w.host.Dir != "" {
@@ -269,6 +379,12 @@ func (w *hostWorker) ensureSetup(ctx context.Context) error {
 	// Record result so subsequent tasks on this host get the same outcome
 	w.orchestrator.recordHostSetup(w.hostName, setupErr)
 
+	if setupErr == nil && w.warm != nil {
+		w.warm.Setup = setup
+		w.warm.SetupAt = time.Now()
+		writeWarmState(w.warm)
+	}
+
 	return setupErr
 }
 
@@ -278,10 +394,24 @@ func (w *hostWorker) execCommand(
 	cmd string,
 	env map[string]string,
 	workDir string,
-	stdout, stderr *bytes.Buffer,
+	stdout, stderr io.Writer,
 ) (int, error) {
 	// Build full command with env and workdir
-	fullCmd := buildFullCommand(cmd, env, workDir, w.host.SetupCommands)
+	// $RR_CACHE, host profile files, and env come first; task env is
+	// exported after them, so it wins
+	var setup []string
+	if project := w.project(); project != nil {
+		setup = append(setup, config.CacheSetupCommand(project.Cache))
+	}
+	// The connection's copy of the host has its secrets looked up
+	h := w.host
+	if w.conn != nil && !w.conn.IsLocal {
+		h = w.conn.Host
+	}
+	setup = append(setup, config.HostProfileCommands(&h)...)
+	setup = append(setup, config.HostEnvCommands(&h)...)
+	setup = append(setup, h.SetupCommands...)
+	fullCmd := buildFullCommand(cmd, env, workDir, setup)
 
 	// Check for context cancellation
 	select {
@@ -346,7 +476,16 @@ func (w *hostWorker) notifyComplete(result TaskResult) {
 	}
 }
 
-// Close releases the lock and closes the worker's connection.
+// project returns the project config of the run, or nil without one.
+func (w *hostWorker) project() *config.Config {
+	if w.orchestrator.resolved == nil {
+		return nil
+	}
+	return w.orchestrator.resolved.Project
+}
+
+// Close releases the lock and closes the worker's connection, collecting
+// the host's cache first if it's due.
 // Holds connMu to synchronize with executeTask's access to hostLock.
 func (w *hostWorker) Close() error {
 	w.connMu.Lock()
@@ -358,6 +497,10 @@ func (w *hostWorker) Close() error {
 		w.hostLock = nil
 	}
 
+	if project := w.project(); project != nil && w.conn != nil && w.conn.Client != nil {
+		cache.Collect(w.conn.Client, w.hostName, project.Cache)
+	}
+
 	if w.conn != nil {
 		err := w.conn.Close()
 		w.conn = nil
@@ -411,13 +554,13 @@ func (w *localWorker) executeTask(ctx context.Context, task TaskInfo) TaskResult
 		defer cancel()
 	}
 
-	// Capture output
-	var outputBuf bytes.Buffer
+	// Capture output, spilling to a file if there's a lot of it
+	outputBuf := newSpillBuffer(w.orchestrator.config.LogDir, task.Name)
 
 	// Run the command locally
 	cmd := exec.CommandContext(execCtx, "sh", "-c", task.Command)
-	cmd.Stdout = &outputBuf
-	cmd.Stderr = &outputBuf
+	cmd.Stdout = outputBuf
+	cmd.Stderr = outputBuf
 
 	// Set working directory if specified
 	if task.WorkDir != "" {
@@ -431,7 +574,9 @@ func (w *localWorker) executeTask(ctx context.Context, task TaskInfo) TaskResult
 	}
 
 	err := cmd.Run()
+	result.OutputFile = outputBuf.Close()
 	result.Output = outputBuf.Bytes()
+	result.OutputSize = outputBuf.Size()
 	result.EndTime = time.Now()
 	result.Duration = result.EndTime.Sub(result.StartTime)
 
@@ -446,7 +591,7 @@ func (w *localWorker) executeTask(ctx context.Context, task TaskInfo) TaskResult
 
 	// Stream output if in stream mode
 	if w.orchestrator.outputMgr != nil {
-		for _, line := range bytes.Split(outputBuf.Bytes(), []byte("\n")) {
+		for _, line := range bytes.Split(result.Output, []byte("\n")) {
 			if len(line) > 0 {
 				w.orchestrator.outputMgr.TaskOutput(task.Name, task.Index, line, false)
 			}
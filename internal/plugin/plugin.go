@@ -0,0 +1,188 @@
+// Package plugin finds and calls rr plugins: executables named rr-<name> on
+// PATH. Any plugin can be run as 'rr <name>'. Plugins listed in the global
+// config are also asked to parse test output rr doesn't recognize and are
+// told about each phase of a run.
+//
+// Parsers and hooks are called with a reserved first argument:
+//
+//	rr-<name> __rr_parse   output on stdin, the command in $RR_COMMAND;
+//	                       prints a JSON array of failures
+//	rr-<name> __rr_hook    one JSON event on stdin
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/output"
+)
+
+const (
+	// Prefix is what a binary's name starts with to be an rr plugin.
+	Prefix = "rr-"
+
+	// ParseArg and HookArg are the reserved first arguments rr calls
+	// parsers and hooks with.
+	ParseArg = "__rr_parse"
+	HookArg  = "__rr_hook"
+
+	// CallTimeout bounds each parser and hook call so a stuck plugin can't
+	// hold up a run.
+	CallTimeout = 10 * time.Second
+)
+
+// namePattern matches a valid plugin name. Anything else could reach
+// another binary through the lookup, like "../bin/sh".
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Plugin is an rr-<name> executable found on PATH.
+type Plugin struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// Failure is one test failure as a parser plugin reports it.
+type Failure struct {
+	Test    string `json:"test"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// ValidName reports whether name can be a plugin's name: letters, digits,
+// underscores, and dashes.
+func ValidName(name string) bool {
+	return namePattern.MatchString(name)
+}
+
+// Find returns the path of the rr-<name> plugin on PATH.
+func Find(name string) (string, error) {
+	if !ValidName(name) {
+		return "", fmt.Errorf("invalid plugin name %q", name)
+	}
+	return exec.LookPath(Prefix + name)
+}
+
+// List returns every plugin on PATH, sorted by name. When two PATH entries
+// hold a plugin with the same name, the first one wins, as it would for a
+// command.
+func List() []Plugin {
+	seen := make(map[string]bool)
+	var plugins []Plugin
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := strings.CutPrefix(entry.Name(), Prefix)
+			if !ok || !ValidName(name) || seen[name] {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+			seen[name] = true
+			plugins = append(plugins, Plugin{Name: name, Path: path})
+		}
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins
+}
+
+// isExecutable reports whether path is a regular file anyone may execute.
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	return info.Mode().Perm()&0111 != 0
+}
+
+// Env returns the environment plugins run with: rr's own, plus RR_BIN so a
+// plugin can call back into the rr that ran it.
+func Env(extra ...string) []string {
+	env := os.Environ()
+	if self, err := os.Executable(); err == nil {
+		env = append(env, "RR_BIN="+self)
+	}
+	return append(env, extra...)
+}
+
+// Parse asks the named parser plugin for the test failures in a command's
+// output. An empty result means the plugin didn't recognize the output.
+func Parse(name, command string, out []byte) ([]output.TestFailure, error) {
+	stdout, err := call(name, ParseArg, out, "RR_COMMAND="+command)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(stdout)) == 0 {
+		return nil, nil
+	}
+
+	var reported []Failure
+	if err := json.Unmarshal(stdout, &reported); err != nil {
+		return nil, fmt.Errorf("rr-%s printed something that isn't a JSON array of failures: %w", name, err)
+	}
+	failures := make([]output.TestFailure, 0, len(reported))
+	for _, f := range reported {
+		failures = append(failures, output.TestFailure{
+			TestName: f.Test,
+			File:     f.File,
+			Line:     f.Line,
+			Message:  f.Message,
+		})
+	}
+	return failures, nil
+}
+
+// Hook sends an event to the named hook plugin. event is marshaled to JSON.
+func Hook(name string, event any) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = call(name, HookArg, data)
+	return err
+}
+
+// call runs rr-<name> with arg, feeding it stdin, and returns its stdout.
+func call(name, arg string, stdin []byte, env ...string) ([]byte, error) {
+	path, err := Find(name)
+	if err != nil {
+		return nil, fmt.Errorf("plugin rr-%s isn't on PATH", name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), CallTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, path, arg)
+	cmd.Env = Env(env...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("rr-%s %s took longer than %s", name, arg, CallTimeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("rr-%s %s failed: %s", name, arg, msg)
+		}
+		return nil, fmt.Errorf("rr-%s %s failed: %w", name, arg, err)
+	}
+	return stdout.Bytes(), nil
+}
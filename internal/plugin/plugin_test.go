@@ -0,0 +1,102 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePlugin writes an executable shell script named rr-<name> into dir.
+func writePlugin(t *testing.T, dir, name, script string) string {
+	t.Helper()
+	path := filepath.Join(dir, Prefix+name)
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0755))
+	return path
+}
+
+// usePath puts dir first on PATH, keeping the rest for the tools the
+// scripts call.
+func usePath(t *testing.T, dir string) {
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestList(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	junit := writePlugin(t, first, "junit", "exit 0")
+	writePlugin(t, second, "junit", "exit 0")
+	notify := writePlugin(t, second, "notify", "exit 0")
+	require.NoError(t, os.WriteFile(filepath.Join(first, "rr-notes"), []byte("not a plugin"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(first, "other"), []byte("#!/bin/sh\n"), 0755))
+	t.Setenv("PATH", first+string(os.PathListSeparator)+second)
+
+	assert.Equal(t, []Plugin{
+		{Name: "junit", Path: junit},
+		{Name: "notify", Path: notify},
+	}, List())
+}
+
+func TestParse(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "junit", `grep -q FAIL || exit 0
+echo '[{"test":"TestLogin","file":"auth/login.go","line":42,"message":"'"$RR_COMMAND"'"}]'`)
+	usePath(t, dir)
+
+	failures, err := Parse("junit", "make test", []byte("FAIL TestLogin\n"))
+	require.NoError(t, err)
+	require.Len(t, failures, 1)
+	assert.Equal(t, "TestLogin", failures[0].TestName)
+	assert.Equal(t, "auth/login.go", failures[0].File)
+	assert.Equal(t, 42, failures[0].Line)
+	assert.Equal(t, "make test", failures[0].Message)
+
+	failures, err = Parse("junit", "make test", []byte("ok\n"))
+	require.NoError(t, err)
+	assert.Empty(t, failures)
+}
+
+func TestParse_Errors(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "garbled", "echo 'not json'")
+	writePlugin(t, dir, "broken", "echo 'bad input' >&2; exit 3")
+	usePath(t, dir)
+
+	_, err := Parse("garbled", "make test", nil)
+	assert.ErrorContains(t, err, "isn't a JSON array")
+
+	_, err = Parse("broken", "make test", nil)
+	assert.ErrorContains(t, err, "bad input")
+
+	_, err = Parse("missing", "make test", nil)
+	assert.ErrorContains(t, err, "isn't on PATH")
+}
+
+func TestHook(t *testing.T) {
+	dir := t.TempDir()
+	events := filepath.Join(dir, "events")
+	writePlugin(t, dir, "notify", `[ "$1" = "`+HookArg+`" ] || exit 1
+cat >> "`+events+`"`)
+	usePath(t, dir)
+
+	require.NoError(t, Hook("notify", map[string]string{"phase": "sync"}))
+
+	data, err := os.ReadFile(events)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"phase":"sync"}`, string(data))
+}
+
+func TestFind_RejectsInvalidNames(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "ok_name-2", "exit 0")
+	usePath(t, dir)
+
+	_, err := Find("ok_name-2")
+	assert.NoError(t, err)
+
+	for _, name := range []string{"", "../ok_name-2", "a/b", "notify.sh", "two words"} {
+		_, err := Find(name)
+		assert.ErrorContains(t, err, "invalid plugin name", name)
+	}
+}
@@ -0,0 +1,126 @@
+// Package report builds the failure report bundle behind 'rr report': the
+// last run's output, redacted config, doctor results, and environment info,
+// packaged as a tarball and as a single markdown file that can be pasted
+// into (or uploaded as) a gist.
+//
+// Everything added to a bundle goes through Redact, so secrets in env
+// values, URLs, and private keys don't leave the machine by accident.
+package report
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/errors"
+)
+
+// File is one file in a bundle.
+type File struct {
+	Name    string // Slash-separated path inside the bundle, e.g. "config/project.yaml"
+	Content []byte
+}
+
+// Bundle collects the files of a report.
+type Bundle struct {
+	Created time.Time
+	Files   []File
+
+	home string // Home directory, replaced with ~ when redacting
+}
+
+// NewBundle creates an empty bundle. home is replaced with "~" in every
+// file so reports don't leak the local username via paths.
+func NewBundle(created time.Time, home string) *Bundle {
+	return &Bundle{Created: created, home: home}
+}
+
+// Add redacts content and adds it to the bundle.
+func (b *Bundle) Add(name string, content []byte) {
+	b.Files = append(b.Files, File{
+		Name:    name,
+		Content: []byte(Redact(string(content), b.home)),
+	})
+}
+
+// AddConfig redacts a YAML config (see RedactConfig) and adds it.
+func (b *Bundle) AddConfig(name string, content []byte) {
+	b.Add(name, RedactConfig(content))
+}
+
+// Name returns the bundle's base name, e.g. "rr-report-20260102-150405".
+func (b *Bundle) Name() string {
+	return "rr-report-" + b.Created.Format("20060102-150405")
+}
+
+// Markdown renders every file into one document, each under its own
+// heading, for pasting into an issue or uploading as a gist.
+func (b *Bundle) Markdown() []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# rr report\n\nGenerated %s. Secrets were redacted automatically; review before sharing.\n",
+		b.Created.Format(time.RFC3339))
+
+	for _, f := range b.Files {
+		fence := "```"
+		for strings.Contains(string(f.Content), fence) {
+			fence += "`"
+		}
+		lang := ""
+		if strings.HasSuffix(f.Name, ".yaml") {
+			lang = "yaml"
+		} else if strings.HasSuffix(f.Name, ".json") {
+			lang = "json"
+		}
+		content := strings.TrimRight(string(f.Content), "\n")
+		fmt.Fprintf(&buf, "\n## %s\n\n%s%s\n%s\n%s\n", f.Name, fence, lang, content, fence)
+	}
+	return buf.Bytes()
+}
+
+// WriteTarball writes the bundle as a gzipped tarball at dest. Files live
+// under a top-level directory named after the bundle, alongside report.md.
+func (b *Bundle) WriteTarball(dest string) error {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	files := append([]File{{Name: "report.md", Content: b.Markdown()}}, b.Files...)
+	for _, f := range files {
+		hdr := &tar.Header{
+			Name:    path.Join(b.Name(), f.Name),
+			Mode:    0600,
+			Size:    int64(len(f.Content)),
+			ModTime: b.Created,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return wrapTarError(err)
+		}
+		if _, err := tw.Write(f.Content); err != nil {
+			return wrapTarError(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return wrapTarError(err)
+	}
+	if err := gz.Close(); err != nil {
+		return wrapTarError(err)
+	}
+
+	if err := os.WriteFile(dest, buf.Bytes(), 0600); err != nil {
+		return errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't write report to "+dest,
+			"Check that the directory exists and is writable, or pick another path with --output.")
+	}
+	return nil
+}
+
+func wrapTarError(err error) error {
+	return errors.WrapWithCode(err, errors.ErrConfig,
+		"Couldn't build the report archive",
+		"This is a bug - please report it.")
+}
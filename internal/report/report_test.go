@@ -0,0 +1,84 @@
+package report
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testBundle() *Bundle {
+	b := NewBundle(time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC), "/home/alice")
+	b.Add("environment.txt", []byte("shell: /bin/zsh\nproject: /home/alice/app\n"))
+	b.AddConfig("config/project.yaml", []byte("host: mini\nenv:\n  SECRET: abc\n"))
+	return b
+}
+
+func TestBundle_Name(t *testing.T) {
+	assert.Equal(t, "rr-report-20260102-150405", testBundle().Name())
+}
+
+func TestBundle_AddRedacts(t *testing.T) {
+	b := testBundle()
+
+	assert.Equal(t, "shell: /bin/zsh\nproject: ~/app\n", string(b.Files[0].Content))
+	assert.Equal(t, "host: mini\nenv:\n  SECRET: '[REDACTED]'\n", string(b.Files[1].Content), "stays valid YAML")
+}
+
+func TestBundle_Markdown(t *testing.T) {
+	b := testBundle()
+	b.Add("output.txt", []byte("```\nfenced\n```\n"))
+	md := string(b.Markdown())
+
+	assert.Contains(t, md, "# rr report")
+	assert.Contains(t, md, "review before sharing")
+	assert.Contains(t, md, "## environment.txt\n\n```\nshell: /bin/zsh")
+	assert.Contains(t, md, "## config/project.yaml\n\n```yaml\n")
+	assert.Contains(t, md, "````\n```\nfenced\n```\n````", "fence is longer than any fence in the content")
+}
+
+func TestBundle_WriteTarball(t *testing.T) {
+	b := testBundle()
+	dest := filepath.Join(t.TempDir(), "report.tar.gz")
+	require.NoError(t, b.WriteTarball(dest))
+
+	info, err := os.Stat(dest)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	f, err := os.Open(dest)
+	require.NoError(t, err)
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+
+	contents := make(map[string]string)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		contents[hdr.Name] = string(data)
+	}
+
+	assert.Len(t, contents, 3)
+	assert.Equal(t, string(b.Markdown()), contents["rr-report-20260102-150405/report.md"])
+	assert.Equal(t, string(b.Files[0].Content), contents["rr-report-20260102-150405/environment.txt"])
+	assert.Contains(t, contents, "rr-report-20260102-150405/config/project.yaml")
+}
+
+func TestBundle_WriteTarballBadPath(t *testing.T) {
+	err := testBundle().WriteTarball(filepath.Join(t.TempDir(), "missing", "report.tar.gz"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Couldn't write report")
+}
@@ -0,0 +1,327 @@
+// Package results records the outcome of each run to
+// .rr/results/<run-id>.json in the project root, so CI jobs and trend
+// reports can read what passed, how long it took, and how many tests ran
+// without scraping rr's output. 'rr results' reads them back.
+//
+// One file is written per run, and the newest MaxRuns are kept. Schema
+// version 1:
+//
+//	{
+//	  "version": 1,
+//	  "id": "20261016-153045-123",   // sorts by start time
+//	  "name": "test",                // task name, or the command for 'rr run'
+//	  "kind": "task",                // run, task, or parallel
+//	  "status": "failed",            // passed or failed
+//	  "exit_code": 1,
+//	  "started_at": "2026-10-16T15:30:45Z",
+//	  "finished_at": "2026-10-16T15:31:20Z",
+//	  "duration_s": 35.2,
+//	  "tasks": [
+//	    {
+//	      "name": "test-backend",
+//	      "command": "pytest tests/",
+//	      "host": "mini",
+//	      "status": "failed",
+//	      "exit_code": 1,
+//	      "duration_s": 34.1,
+//	      "tests": {"passed": 120, "failed": 2, "skipped": 3, "errors": 0, "source": "pytest"},
+//	      "artifacts": [{"kind": "pull", "src": "coverage.xml", "path": "."}],
+//	      "error": ""                // why the task couldn't run, if it couldn't
+//	    }
+//	  ]
+//	}
+//
+// New fields may be added within a version; a breaking change bumps it.
+package results
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/diagnostics"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/output"
+	"github.com/rileyhilliard/rr/internal/output/formatters"
+	"github.com/rileyhilliard/rr/internal/util"
+)
+
+const (
+	// SchemaVersion is the version of the results file format.
+	SchemaVersion = 1
+
+	// DirName is the directory under the project's .rr directory that
+	// holds results.
+	DirName = "results"
+
+	// MaxRuns caps how many runs are kept per project. Older ones are
+	// deleted when a new one is written.
+	MaxRuns = 100
+
+	// idFormat names runs after the time they started, so sorting IDs
+	// sorts by age. Milliseconds keep runs started in the same second
+	// apart.
+	idFormat = "20060102-150405.000"
+)
+
+// Run kinds.
+const (
+	KindRun      = "run"
+	KindTask     = "task"
+	KindParallel = "parallel"
+)
+
+// Statuses of runs and tasks.
+const (
+	StatusPassed = "passed"
+	StatusFailed = "failed"
+)
+
+// Run is the contents of one results file.
+type Run struct {
+	Version    int       `json:"version"`
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Kind       string    `json:"kind"`
+	Status     string    `json:"status"`
+	ExitCode   int       `json:"exit_code"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Duration   float64   `json:"duration_s"`
+	Tasks      []Task    `json:"tasks"`
+}
+
+// Task is the outcome of one task (or command) within a run.
+type Task struct {
+	Name      string      `json:"name"`
+	Command   string      `json:"command,omitempty"`
+	Host      string      `json:"host,omitempty"`
+	Status    string      `json:"status"`
+	ExitCode  int         `json:"exit_code"`
+	Duration  float64     `json:"duration_s"`
+	Tests     *TestCounts `json:"tests,omitempty"`
+	Artifacts []Artifact  `json:"artifacts,omitempty"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// TestCounts are the test totals parsed from a task's output.
+type TestCounts struct {
+	Passed  int    `json:"passed"`
+	Failed  int    `json:"failed"`
+	Skipped int    `json:"skipped"`
+	Errors  int    `json:"errors"`
+	Source  string `json:"source"` // parser that counted them: pytest, gotest, jest
+}
+
+// Artifact kinds.
+const (
+	ArtifactPull = "pull" // files pulled back from the host
+	ArtifactLog  = "log"  // the task's saved output
+)
+
+// Artifact is a file a task left behind on this machine.
+type Artifact struct {
+	Kind string `json:"kind"`
+	Src  string `json:"src,omitempty"` // remote path or pattern, for pulls
+	Path string `json:"path"`          // local file or directory
+}
+
+// NewRun starts the record of a run that began at start.
+func NewRun(name, kind string, start time.Time) *Run {
+	return &Run{
+		Version:   SchemaVersion,
+		ID:        RunID(start),
+		Name:      name,
+		Kind:      kind,
+		StartedAt: start.UTC(),
+		Tasks:     []Task{},
+	}
+}
+
+// RunID returns the ID of a run that began at start. Other per-run files,
+// like crash evidence, are named after it too.
+func RunID(start time.Time) string {
+	return strings.Replace(start.Format(idFormat), ".", "-", 1)
+}
+
+// Finish sets the run's exit code and end time.
+func (r *Run) Finish(exitCode int, end time.Time) {
+	r.ExitCode = exitCode
+	r.Status = StatusOf(exitCode)
+	r.FinishedAt = end.UTC()
+	r.Duration = seconds(end.Sub(r.StartedAt))
+}
+
+// StatusOf returns the status for an exit code.
+func StatusOf(exitCode int) string {
+	if exitCode == 0 {
+		return StatusPassed
+	}
+	return StatusFailed
+}
+
+// NewTask returns a task's record, with test counts parsed from its output
+// when rr recognizes the test framework.
+func NewTask(name, command, host string, exitCode int, d time.Duration, out []byte) Task {
+	return Task{
+		Name:     name,
+		Command:  command,
+		Host:     host,
+		Status:   StatusOf(exitCode),
+		ExitCode: exitCode,
+		Duration: seconds(d),
+		Tests:    CountTests(command, out),
+	}
+}
+
+// CountTests parses test totals out of a command's output. Returns nil when
+// the output isn't from a test framework rr knows, or reports no tests.
+func CountTests(command string, out []byte) *TestCounts {
+	if len(out) == 0 {
+		return nil
+	}
+	formatter := formatters.ParseOutput(command, out)
+	provider, ok := formatter.(output.TestSummaryProvider)
+	if !ok {
+		return nil
+	}
+	passed, failed, skipped, errs := provider.GetTestCounts()
+	if passed+failed+skipped+errs == 0 {
+		return nil
+	}
+	return &TestCounts{Passed: passed, Failed: failed, Skipped: skipped, Errors: errs, Source: formatter.Name()}
+}
+
+// Dir returns the results directory for a project.
+func Dir(projectRoot string) string {
+	return filepath.Join(projectRoot, diagnostics.DirName, DirName)
+}
+
+// Write saves a run's results and deletes the oldest runs past MaxRuns.
+func Write(projectRoot string, r *Run) error {
+	if _, err := diagnostics.EnsureDir(projectRoot); err != nil {
+		return err
+	}
+	dir := Dir(projectRoot)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return wrapWriteError(err, dir)
+	}
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return wrapWriteError(err, dir)
+	}
+	path := filepath.Join(dir, r.ID+".json")
+	if err := util.WriteFileAtomic(path, append(data, '\n'), 0644); err != nil {
+		return wrapWriteError(err, dir)
+	}
+
+	ids, err := listIDs(dir)
+	if err == nil && len(ids) > MaxRuns {
+		for _, id := range ids[:len(ids)-MaxRuns] {
+			_ = os.Remove(filepath.Join(dir, id+".json"))
+		}
+	}
+	return nil
+}
+
+// List returns a project's runs, oldest first. Files that can't be read
+// are skipped.
+func List(projectRoot string) ([]*Run, error) {
+	ids, err := listIDs(Dir(projectRoot))
+	if err != nil {
+		return nil, err
+	}
+	runs := make([]*Run, 0, len(ids))
+	for _, id := range ids {
+		if r, err := Load(projectRoot, id); err == nil {
+			runs = append(runs, r)
+		}
+	}
+	return runs, nil
+}
+
+// TaskDurations returns how long each task of the runs named name took,
+// averaged over its last samples passing runs. runs are oldest first, as
+// List returns them. Tasks that never passed are left out.
+func TaskDurations(runs []*Run, name string, samples int) map[string]time.Duration {
+	total := make(map[string]float64)
+	counts := make(map[string]int)
+	for i := len(runs) - 1; i >= 0; i-- {
+		if runs[i].Name != name {
+			continue
+		}
+		for _, t := range runs[i].Tasks {
+			if t.Status != StatusPassed || counts[t.Name] >= samples {
+				continue
+			}
+			total[t.Name] += t.Duration
+			counts[t.Name]++
+		}
+	}
+	durations := make(map[string]time.Duration, len(total))
+	for task, sum := range total {
+		durations[task] = time.Duration(sum / float64(counts[task]) * float64(time.Second))
+	}
+	return durations
+}
+
+// Load reads one run's results by ID.
+func Load(projectRoot, id string) (*Run, error) {
+	path := filepath.Join(Dir(projectRoot), id+".json")
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, errors.New(errors.ErrConfig,
+			fmt.Sprintf("No results for run '%s'", id),
+			"List this project's runs with: rr results")
+	}
+	if err != nil {
+		return nil, errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't read "+path,
+			"Check the file's permissions.")
+	}
+	var r Run
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, errors.WrapWithCode(err, errors.ErrConfig,
+			"Results file "+path+" is damaged",
+			"Delete it; the next run writes a new one.")
+	}
+	return &r, nil
+}
+
+// listIDs returns the IDs of the runs in dir, oldest first. A missing
+// directory has none.
+func listIDs(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't read "+dir,
+			"Check the directory's permissions.")
+	}
+	var ids []string
+	for _, e := range entries {
+		if id, ok := strings.CutSuffix(e.Name(), ".json"); ok && !e.IsDir() {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// seconds converts a duration to seconds, to the millisecond.
+func seconds(d time.Duration) float64 {
+	return d.Round(time.Millisecond).Seconds()
+}
+
+func wrapWriteError(err error, dir string) error {
+	return errors.WrapWithCode(err, errors.ErrConfig,
+		"Couldn't write results to "+dir,
+		"Check that the project directory is writable.")
+}
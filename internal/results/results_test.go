@@ -0,0 +1,97 @@
+package results
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const goTestOutput = "=== RUN   TestAdd\n--- PASS: TestAdd (0.00s)\n" +
+	"=== RUN   TestSub\n--- PASS: TestSub (0.00s)\n" +
+	"=== RUN   TestDiv\n    calc_test.go:15: Expected 1, got 2\n--- FAIL: TestDiv (0.00s)\n" +
+	"=== RUN   TestSkip\n--- SKIP: TestSkip (0.00s)\nFAIL\nFAIL\texample\t0.005s\n"
+
+func TestNewRun(t *testing.T) {
+	start := time.Date(2026, 10, 16, 15, 30, 45, 123456789, time.UTC)
+	r := NewRun("test", KindTask, start)
+	assert.Equal(t, "20261016-153045-123", r.ID)
+	assert.Equal(t, SchemaVersion, r.Version)
+
+	r.Finish(2, start.Add(1500*time.Millisecond))
+	assert.Equal(t, StatusFailed, r.Status)
+	assert.Equal(t, 2, r.ExitCode)
+	assert.Equal(t, 1.5, r.Duration)
+}
+
+func TestNewTask_CountsTests(t *testing.T) {
+	task := NewTask("unit", "go test ./...", "mini", 1, 3*time.Second, []byte(goTestOutput))
+	assert.Equal(t, StatusFailed, task.Status)
+	assert.Equal(t, 3.0, task.Duration)
+	assert.Equal(t, &TestCounts{Passed: 2, Failed: 1, Skipped: 1, Source: "gotest"}, task.Tests)
+
+	assert.Nil(t, NewTask("lint", "make lint", "mini", 0, time.Second, []byte("all good\n")).Tests,
+		"output that isn't from a test framework has no counts")
+}
+
+func TestWriteAndList(t *testing.T) {
+	root := t.TempDir()
+	start := time.Date(2026, 10, 16, 15, 30, 45, 0, time.UTC)
+
+	for i := 0; i < MaxRuns+2; i++ {
+		r := NewRun("test", KindTask, start.Add(time.Duration(i)*time.Second))
+		r.Tasks = append(r.Tasks, NewTask("test", "go test ./...", "mini", 0, time.Second, nil))
+		r.Finish(0, start.Add(time.Duration(i)*time.Second+time.Second))
+		require.NoError(t, Write(root, r))
+	}
+
+	runs, err := List(root)
+	require.NoError(t, err)
+	require.Len(t, runs, MaxRuns, "the oldest runs are dropped")
+	assert.Equal(t, "20261016-153047-000", runs[0].ID)
+	assert.Equal(t, "mini", runs[0].Tasks[0].Host)
+
+	// The .rr directory stays out of git
+	_, err = os.Stat(filepath.Join(root, ".rr", ".gitignore"))
+	assert.NoError(t, err)
+
+	r, err := Load(root, runs[len(runs)-1].ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPassed, r.Status)
+
+	_, err = Load(root, "19990101-000000-000")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "No results for run")
+}
+
+func TestTaskDurations(t *testing.T) {
+	run := func(name string, tasks ...Task) *Run {
+		r := NewRun(name, KindParallel, time.Now())
+		r.Tasks = tasks
+		return r
+	}
+	shard := func(name string, exitCode int, secs int) Task {
+		return NewTask(name, "pytest", "mini", exitCode, time.Duration(secs)*time.Second, nil)
+	}
+	runs := []*Run{
+		run("test", shard("test[1/2]", 0, 100), shard("test[2/2]", 0, 10)),
+		run("test", shard("test[1/2]", 0, 30), shard("test[2/2]", 0, 20)),
+		run("lint", shard("test[1/2]", 0, 1)),
+		run("test", shard("test[1/2]", 0, 50), shard("test[2/2]", 1, 500)),
+	}
+
+	got := TaskDurations(runs, "test", 2)
+	assert.Equal(t, 40*time.Second, got["test[1/2]"], "only the last 2 runs count")
+	assert.Equal(t, 15*time.Second, got["test[2/2]"], "failed runs don't count")
+
+	assert.Empty(t, TaskDurations(runs, "build", 5))
+}
+
+func TestList_NoResults(t *testing.T) {
+	runs, err := List(t.TempDir())
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+}
@@ -0,0 +1,202 @@
+package secrets
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rileyhilliard/rr/internal/util"
+)
+
+// keychain stores secrets in the macOS login keychain, through security(1).
+type keychain struct{}
+
+// keychainNotFound is the exit code security uses for an item it can't find.
+const keychainNotFound = 44
+
+func (keychain) Name() string { return BackendKeychain }
+
+func (keychain) Get(name string) (string, error) {
+	out, err := runCommand(nil, "security", "find-generic-password", "-s", Service, "-a", name, "-w")
+	if err != nil {
+		if exitCode(err) == keychainNotFound {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// Set runs security in interactive mode and gives it the command on stdin,
+// since add-generic-password only takes the password as an argument.
+func (keychain) Set(name, value string) error {
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("keychain secrets have to fit on one line")
+	}
+	cmd := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n",
+		keychainQuote(Service), keychainQuote(name), keychainQuote(value))
+	_, err := runCommand([]byte(cmd), "security", "-i")
+	return err
+}
+
+func (keychain) Delete(name string) error {
+	_, err := runCommand(nil, "security", "delete-generic-password", "-s", Service, "-a", name)
+	if exitCode(err) == keychainNotFound {
+		return ErrNotFound
+	}
+	return err
+}
+
+// keychainQuote quotes s for a line of security's interactive mode.
+func keychainQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// libsecret stores secrets in the desktop keyring over the Secret Service
+// API, through secret-tool(1).
+type libsecret struct{}
+
+func (libsecret) Name() string { return BackendLibsecret }
+
+func (libsecret) Get(name string) (string, error) {
+	out, err := runCommand(nil, "secret-tool", "lookup", "service", Service, "account", name)
+	if err != nil {
+		// secret-tool exits 1 without a word when nothing matches
+		var cmdErr *commandError
+		if stderrors.As(err, &cmdErr) && cmdErr.code == 1 && cmdErr.stderr == "" {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	return string(out), nil
+}
+
+func (libsecret) Set(name, value string) error {
+	_, err := runCommand([]byte(value), "secret-tool", "store", "--label", Service+": "+name,
+		"service", Service, "account", name)
+	return err
+}
+
+// Delete looks the secret up first, since secret-tool clear succeeds
+// whether or not there was anything to clear.
+func (s libsecret) Delete(name string) error {
+	if _, err := s.Get(name); err != nil {
+		return err
+	}
+	_, err := runCommand(nil, "secret-tool", "clear", "service", Service, "account", name)
+	return err
+}
+
+// Files the file backend keeps in ~/.rr.
+const (
+	fileKey     = "secrets.key" // The age identity, made on first use
+	fileSecrets = "secrets.age" // The secrets as a JSON object, encrypted to the identity
+)
+
+// fileStore keeps secrets in one file encrypted with age, for machines
+// without a keyring. Anyone who can read the identity next to it can read
+// them, so both are private to the user.
+type fileStore struct {
+	dir string
+}
+
+func (s *fileStore) Name() string { return BackendFile }
+
+func (s *fileStore) Get(name string) (string, error) {
+	secrets, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	value, ok := secrets[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+func (s *fileStore) Set(name, value string) error {
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	secrets[name] = value
+	return s.save(secrets)
+}
+
+func (s *fileStore) Delete(name string) error {
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[name]; !ok {
+		return ErrNotFound
+	}
+	delete(secrets, name)
+	return s.save(secrets)
+}
+
+// load decrypts the secrets file. No file yet holds no secrets.
+func (s *fileStore) load() (map[string]string, error) {
+	path := filepath.Join(s.dir, fileSecrets)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	out, err := runCommand(nil, "age", "--decrypt", "--identity", filepath.Join(s.dir, fileKey), path)
+	if err != nil {
+		return nil, err
+	}
+	secrets := map[string]string{}
+	if err := json.Unmarshal(out, &secrets); err != nil {
+		return nil, fmt.Errorf("%s doesn't hold rr secrets: %w", path, err)
+	}
+	return secrets, nil
+}
+
+// save encrypts secrets to the identity, making it first if there isn't
+// one, and replaces the secrets file with them.
+func (s *fileStore) save(secrets map[string]string) error {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return err
+	}
+	keyPath := filepath.Join(s.dir, fileKey)
+	if _, err := os.Stat(keyPath); os.IsNotExist(err) {
+		if _, err := runCommand(nil, "age-keygen", "-o", keyPath); err != nil {
+			return err
+		}
+		if err := os.Chmod(keyPath, 0600); err != nil {
+			return err
+		}
+	}
+	recipient, err := runCommand(nil, "age-keygen", "-y", keyPath)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	encrypted, err := runCommand(data, "age", "--encrypt", "--armor", "--recipient", strings.TrimSpace(string(recipient)))
+	if err != nil {
+		return err
+	}
+	return writePrivate(filepath.Join(s.dir, fileSecrets), encrypted)
+}
+
+// writePrivate replaces path with data, readable only by the user, in one
+// rename so a crash can't lose the secrets already there.
+func writePrivate(path string, data []byte) error {
+	return util.WriteFileAtomic(path, data, 0600)
+}
+
+// defaultDir is where the file backend keeps its files.
+func defaultDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("can't find your home directory for the secrets file: %w", err)
+	}
+	return filepath.Join(home, ".rr"), nil
+}
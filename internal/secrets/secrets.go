@@ -0,0 +1,165 @@
+// Package secrets keeps credentials like tokens and key passphrases out of
+// rr's config files. They're stored in the macOS Keychain, in libsecret
+// (GNOME Keyring, KWallet) on Linux desktops, or in a file encrypted with
+// age, and config refers to them by name with a keychain: value.
+package secrets
+
+import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/logger"
+)
+
+// Backends, as named by the global config's secrets setting.
+const (
+	BackendAuto      = "auto"
+	BackendKeychain  = "keychain"
+	BackendLibsecret = "libsecret"
+	BackendFile      = "file"
+)
+
+// Service is what secrets are filed under in the keychain and libsecret.
+// Each secret's name is its account.
+const Service = "rr"
+
+// commandTimeout bounds each call to a backend's CLI. The keychain can ask
+// to be unlocked, which takes a person a few seconds.
+const commandTimeout = time.Minute
+
+// ErrNotFound is returned by Get and Delete for a name with no secret.
+var ErrNotFound = stderrors.New("secret not found")
+
+// nameRegex is what a secret's name has to look like. Slashes let names be
+// grouped, like "ci/webhook-token".
+var nameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_./-]*$`)
+
+// ValidName reports whether name can name a secret.
+func ValidName(name string) bool {
+	return nameRegex.MatchString(name) && !strings.Contains(name, "..")
+}
+
+// Store holds secrets by name.
+type Store interface {
+	// Name is the backend's name, like "keychain".
+	Name() string
+	// Get returns the named secret, or ErrNotFound.
+	Get(name string) (string, error)
+	// Set stores value under name, replacing any secret already there.
+	Set(name, value string) error
+	// Delete removes the named secret, or returns ErrNotFound.
+	Delete(name string) error
+}
+
+// Open returns the store for backend. Auto picks the keychain on macOS,
+// libsecret on a Linux desktop session, and the age file anywhere else.
+// A backend whose CLI isn't installed is an error.
+func Open(backend string) (Store, error) {
+	if backend == "" || backend == BackendAuto {
+		backend = detect()
+	}
+	switch backend {
+	case BackendKeychain:
+		if _, err := lookPath("security"); err != nil {
+			return nil, fmt.Errorf("the keychain backend needs the 'security' command, which only macOS has")
+		}
+		return keychain{}, nil
+	case BackendLibsecret:
+		if _, err := lookPath("secret-tool"); err != nil {
+			return nil, fmt.Errorf("the libsecret backend needs 'secret-tool' (the libsecret-tools package)")
+		}
+		return libsecret{}, nil
+	case BackendFile:
+		if _, err := lookPath("age"); err != nil {
+			return nil, fmt.Errorf("the file backend needs age (https://age-encryption.org) to encrypt secrets")
+		}
+		dir, err := defaultDir()
+		if err != nil {
+			return nil, err
+		}
+		return &fileStore{dir: dir}, nil
+	default:
+		return nil, fmt.Errorf("unknown secrets backend '%s' - use auto, keychain, libsecret, or file", backend)
+	}
+}
+
+// detect picks the backend for auto.
+func detect() string {
+	if goos == "darwin" {
+		if _, err := lookPath("security"); err == nil {
+			return BackendKeychain
+		}
+	}
+	// secret-tool can't reach a keyring without a session bus, as over SSH
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") != "" {
+		if _, err := lookPath("secret-tool"); err == nil {
+			return BackendLibsecret
+		}
+	}
+	return BackendFile
+}
+
+// goos and lookPath are variables so tests can pretend to be elsewhere.
+var (
+	goos     = runtime.GOOS
+	lookPath = exec.LookPath
+)
+
+// commandError is a backend CLI that exited with an error.
+type commandError struct {
+	name   string
+	code   int
+	stderr string
+	err    error
+}
+
+func (e *commandError) Error() string {
+	if e.stderr != "" {
+		return fmt.Sprintf("%s: %v: %s", e.name, e.err, e.stderr)
+	}
+	return fmt.Sprintf("%s: %v", e.name, e.err)
+}
+
+func (e *commandError) Unwrap() error { return e.err }
+
+// exitCode returns the exit code of a commandError, or -1 for other errors.
+func exitCode(err error) int {
+	var cmdErr *commandError
+	if stderrors.As(err, &cmdErr) {
+		return cmdErr.code
+	}
+	return -1
+}
+
+// runCommand runs a backend's CLI with stdin as its input and returns its
+// stdout. Secrets only ever go through stdin and stdout, never the
+// arguments, which other users can see. A variable so tests can fake the
+// CLIs.
+var runCommand = func(stdin []byte, name string, args ...string) ([]byte, error) {
+	logger.Verbosef(logger.LevelCommands, "secrets", "%s %s", name, strings.Join(args, " "))
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		code := -1
+		var exitErr *exec.ExitError
+		if stderrors.As(err, &exitErr) {
+			code = exitErr.ExitCode()
+		}
+		return nil, &commandError{name: name, code: code, stderr: strings.TrimSpace(stderr.String()), err: err}
+	}
+	return out, nil
+}
@@ -0,0 +1,217 @@
+package secrets
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// call is one run of a backend's CLI.
+type call struct {
+	args  string
+	stdin string
+}
+
+// fakeCLI replaces runCommand with respond, and returns the calls made.
+func fakeCLI(t *testing.T, respond func(args string, stdin []byte) ([]byte, error)) *[]call {
+	t.Helper()
+	var calls []call
+	orig := runCommand
+	runCommand = func(stdin []byte, name string, args ...string) ([]byte, error) {
+		c := call{args: name + " " + strings.Join(args, " "), stdin: string(stdin)}
+		calls = append(calls, c)
+		return respond(c.args, stdin)
+	}
+	t.Cleanup(func() { runCommand = orig })
+	return &calls
+}
+
+// fakeTools makes lookPath find only the named commands.
+func fakeTools(t *testing.T, system string, tools ...string) {
+	t.Helper()
+	origGOOS, origLookPath := goos, lookPath
+	goos = system
+	lookPath = func(file string) (string, error) {
+		for _, tool := range tools {
+			if tool == file {
+				return "/usr/bin/" + file, nil
+			}
+		}
+		return "", errors.New("not found")
+	}
+	t.Cleanup(func() { goos, lookPath = origGOOS, origLookPath })
+}
+
+func TestValidName(t *testing.T) {
+	for _, name := range []string{"webhook-token", "ci/registry_url", "gpu.box.passphrase", "A1"} {
+		assert.True(t, ValidName(name), name)
+	}
+	for _, name := range []string{"", "-flag", "/abs", "has space", "a/../b", "quote\""} {
+		assert.False(t, ValidName(name), name)
+	}
+}
+
+func TestOpen_Auto(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	tests := []struct {
+		name  string
+		goos  string
+		dbus  string
+		tools []string
+		want  string
+	}{
+		{"macOS", "darwin", "", []string{"security", "age"}, BackendKeychain},
+		{"Linux desktop", "linux", "unix:path=/run/user/1000/bus", []string{"secret-tool", "age"}, BackendLibsecret},
+		{"no session bus", "linux", "", []string{"secret-tool", "age"}, BackendFile},
+		{"no secret-tool", "linux", "unix:path=/run/user/1000/bus", []string{"age"}, BackendFile},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeTools(t, tt.goos, tt.tools...)
+			t.Setenv("DBUS_SESSION_BUS_ADDRESS", tt.dbus)
+
+			store, err := Open(BackendAuto)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, store.Name())
+		})
+	}
+}
+
+func TestOpen_MissingTool(t *testing.T) {
+	fakeTools(t, "linux")
+
+	for _, backend := range []string{BackendKeychain, BackendLibsecret, BackendFile} {
+		_, err := Open(backend)
+		assert.Error(t, err, backend)
+	}
+	_, err := Open("vault")
+	assert.ErrorContains(t, err, "unknown secrets backend 'vault'")
+}
+
+func TestKeychain(t *testing.T) {
+	calls := fakeCLI(t, func(args string, _ []byte) ([]byte, error) {
+		switch {
+		case strings.Contains(args, "-a missing"):
+			return nil, &commandError{name: "security", code: keychainNotFound, err: errors.New("exit status 44")}
+		case strings.HasPrefix(args, "security find-generic-password"):
+			return []byte("s3cret\n"), nil
+		}
+		return nil, nil
+	})
+	store := keychain{}
+
+	value, err := store.Get("token")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", value)
+
+	_, err = store.Get("missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, store.Delete("missing"), ErrNotFound)
+
+	require.NoError(t, store.Set("token", `pa"ss\word`))
+	set := (*calls)[len(*calls)-1]
+	assert.Equal(t, "security -i", set.args, "the value stays off the command line")
+	assert.Equal(t, `add-generic-password -U -s "rr" -a "token" -w "pa\"ss\\word"`+"\n", set.stdin)
+
+	assert.Error(t, store.Set("token", "two\nlines"))
+}
+
+func TestLibsecret(t *testing.T) {
+	calls := fakeCLI(t, func(args string, _ []byte) ([]byte, error) {
+		switch {
+		case strings.Contains(args, "account missing"):
+			return nil, &commandError{name: "secret-tool", code: 1, err: errors.New("exit status 1")}
+		case strings.Contains(args, "account locked"):
+			return nil, &commandError{name: "secret-tool", code: 1, stderr: "Cannot create an item in a locked collection", err: errors.New("exit status 1")}
+		case strings.HasPrefix(args, "secret-tool lookup"):
+			return []byte("s3cret"), nil
+		}
+		return nil, nil
+	})
+	store := libsecret{}
+
+	value, err := store.Get("token")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", value)
+
+	_, err = store.Get("missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = store.Get("locked")
+	assert.ErrorContains(t, err, "locked collection")
+	assert.NotErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, store.Set("token", "s3cret"))
+	set := (*calls)[len(*calls)-1]
+	assert.Equal(t, "secret-tool store --label rr: token service rr account token", set.args)
+	assert.Equal(t, "s3cret", set.stdin)
+
+	assert.ErrorIs(t, store.Delete("missing"), ErrNotFound)
+}
+
+// fakeAge stands in for age and age-keygen, "encrypting" by prefixing the
+// recipient.
+func fakeAge(t *testing.T) *[]call {
+	t.Helper()
+	return fakeCLI(t, func(args string, stdin []byte) ([]byte, error) {
+		fields := strings.Fields(args)
+		switch {
+		case strings.HasPrefix(args, "age-keygen -o"):
+			return nil, os.WriteFile(fields[len(fields)-1], []byte("AGE-SECRET-KEY-1FAKE\n"), 0644)
+		case strings.HasPrefix(args, "age-keygen -y"):
+			return []byte("age1fake\n"), nil
+		case strings.HasPrefix(args, "age --encrypt"):
+			return append([]byte(fields[len(fields)-1]+":"), stdin...), nil
+		case strings.HasPrefix(args, "age --decrypt"):
+			data, err := os.ReadFile(fields[len(fields)-1])
+			if err != nil {
+				return nil, err
+			}
+			return []byte(strings.TrimPrefix(string(data), "age1fake:")), nil
+		}
+		return nil, errors.New("unexpected " + args)
+	})
+}
+
+func TestFileStore(t *testing.T) {
+	calls := fakeAge(t)
+	dir := filepath.Join(t.TempDir(), ".rr")
+	store := &fileStore{dir: dir}
+
+	_, err := store.Get("token")
+	assert.ErrorIs(t, err, ErrNotFound, "no file yet")
+
+	require.NoError(t, store.Set("token", "s3cret"))
+	require.NoError(t, store.Set("registry", "https://user:pw@registry.example.com"))
+
+	value, err := store.Get("token")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", value)
+
+	for _, name := range []string{fileKey, fileSecrets} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), name)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, fileSecrets))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "age1fake:"), "written as age encrypted it")
+
+	for _, c := range *calls {
+		assert.NotContains(t, c.args, "s3cret", "secrets only go through stdin")
+	}
+
+	require.NoError(t, store.Delete("token"))
+	_, err = store.Get("token")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, store.Delete("token"), ErrNotFound)
+
+	value, err = store.Get("registry")
+	require.NoError(t, err)
+	assert.Equal(t, "https://user:pw@registry.example.com", value)
+}
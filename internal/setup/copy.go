@@ -10,10 +10,14 @@ import (
 
 // CopyKey copies an SSH public key to a remote host using ssh-copy-id.
 // This enables passwordless authentication to the host.
+// If keyPath is empty, the key is picked with KeyForHost so the one deployed
+// matches the IdentityFile ~/.ssh/config uses for host.
 func CopyKey(host string, keyPath string) error {
 	if keyPath == "" {
-		// Find the best available key
-		key := GetPreferredKey()
+		key, err := KeyForHost(host, "")
+		if err != nil {
+			return err
+		}
 		if key == nil {
 			return errors.New(errors.ErrSSH,
 				"No SSH keys on this machine",
@@ -94,15 +98,20 @@ ssh %s "mkdir -p ~/.ssh && chmod 700 ~/.ssh && echo '%s' >> ~/.ssh/authorized_ke
 
 // TestPasswordlessAuth tests if passwordless authentication works for a host.
 // Returns true if we can connect without password prompts.
-func TestPasswordlessAuth(host string) (bool, error) {
+// If keyPath is set, only that key is offered, so a pass means that specific
+// key is authorized rather than some other key in the agent.
+func TestPasswordlessAuth(host string, keyPath string) (bool, error) {
 	// Use SSH with batch mode to disable password prompts
-	cmd := exec.Command("ssh",
+	args := []string{
 		"-o", "BatchMode=yes",
 		"-o", "ConnectTimeout=5",
 		"-o", "StrictHostKeyChecking=accept-new",
-		host,
-		"echo ok",
-	)
+	}
+	if keyPath != "" {
+		args = append(args, "-i", keyPath, "-o", "IdentitiesOnly=yes")
+	}
+	args = append(args, host, "echo ok")
+	cmd := exec.Command("ssh", args...)
 
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -40,6 +40,9 @@
 // This is equivalent to the ssh-copy-id command and enables passwordless
 // authentication to the remote host.
 //
+// KeyForHost() picks which key to deploy: the host's identity_file from rr
+// config, then the IdentityFile from ~/.ssh/config, then the preferred default.
+//
 // If ssh-copy-id is unavailable, CopyKeyManual() returns instructions
 // for manual key deployment.
 //
@@ -47,11 +50,11 @@
 //
 // TestPasswordlessAuth() verifies that passwordless authentication works:
 //
-//	ok, err := setup.TestPasswordlessAuth("user@hostname")
+//	ok, err := setup.TestPasswordlessAuth("user@hostname", "~/.ssh/id_ed25519")
 //
-// It uses SSH batch mode to prevent password prompts, returning false
-// if authentication fails (but connection succeeded) or an error for
-// network/connectivity issues.
+// Pass an empty key path to let ssh pick keys as usual. It uses SSH batch
+// mode to prevent password prompts, returning false if authentication fails
+// (but connection succeeded) or an error for network/connectivity issues.
 //
 // # Security Notes
 //
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/pkg/sshutil"
 )
 
 // KeyInfo contains information about an SSH key.
@@ -16,6 +17,8 @@ type KeyInfo struct {
 	Type       string // Key type (ed25519, rsa, ecdsa)
 	PublicPath string // Path to public key
 	HasPublic  bool   // Whether public key file exists
+	CertPath   string // Path to the key's SSH certificate (<key>-cert.pub)
+	HasCert    bool   // Whether a certificate file exists
 }
 
 // DefaultKeyPaths returns the standard locations for SSH keys.
@@ -37,17 +40,8 @@ func FindLocalKeys() []KeyInfo {
 	var keys []KeyInfo
 
 	for _, path := range DefaultKeyPaths() {
-		if _, err := os.Stat(path); err == nil {
-			keyType := inferKeyType(path)
-			pubPath := path + ".pub"
-			_, pubErr := os.Stat(pubPath)
-
-			keys = append(keys, KeyInfo{
-				Path:       path,
-				Type:       keyType,
-				PublicPath: pubPath,
-				HasPublic:  pubErr == nil,
-			})
+		if key := keyInfoForPath(path); key != nil {
+			keys = append(keys, *key)
 		}
 	}
 
@@ -59,13 +53,21 @@ func HasAnyKey() bool {
 	return len(FindLocalKeys()) > 0
 }
 
-// GetPreferredKey returns the best available key (prefers ed25519).
+// GetPreferredKey returns the best available key. A key with an SSH
+// certificate wins, since hosts that trust a CA accept it without the key
+// being deployed. After that, prefers ed25519 > ecdsa > rsa.
 func GetPreferredKey() *KeyInfo {
 	keys := FindLocalKeys()
 	if len(keys) == 0 {
 		return nil
 	}
 
+	for _, key := range keys {
+		if key.HasCert {
+			return &key
+		}
+	}
+
 	// Prefer ed25519 > ecdsa > rsa
 	for _, key := range keys {
 		if key.Type == "ed25519" && key.HasPublic {
@@ -87,6 +89,65 @@ func GetPreferredKey() *KeyInfo {
 	return &keys[0]
 }
 
+// KeyForHost picks the key to use for a host. In order of preference:
+//  1. identityFile, usually the host's identity_file from rr config
+//  2. the IdentityFile ~/.ssh/config sets for host
+//  3. the preferred default key (see GetPreferredKey)
+//
+// Returns an error if identityFile is set but doesn't exist, since silently
+// falling back would deploy a different key than the one rr will log in with.
+// Returns nil with no error if there are no keys at all.
+func KeyForHost(host, identityFile string) (*KeyInfo, error) {
+	if identityFile != "" {
+		key := keyInfoForPath(expandHome(identityFile))
+		if key == nil {
+			return nil, errors.New(errors.ErrSSH,
+				fmt.Sprintf("identity_file %s doesn't exist", identityFile),
+				"Fix the identity_file path for this host in ~/.rr/config.yaml, or generate it: ssh-keygen -t ed25519 -f "+identityFile)
+		}
+		return key, nil
+	}
+
+	if path := sshutil.SSHConfigIdentityFile(host); path != "" {
+		if key := keyInfoForPath(path); key != nil {
+			return key, nil
+		}
+	}
+
+	return GetPreferredKey(), nil
+}
+
+// keyInfoForPath describes the private key at path, or returns nil if it doesn't exist.
+func keyInfoForPath(path string) *KeyInfo {
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	pubPath := path + ".pub"
+	_, pubErr := os.Stat(pubPath)
+	certPath := sshutil.CertPath(path)
+	_, certErr := os.Stat(certPath)
+	return &KeyInfo{
+		Path:       path,
+		Type:       inferKeyType(path),
+		PublicPath: pubPath,
+		HasPublic:  pubErr == nil,
+		CertPath:   certPath,
+		HasCert:    certErr == nil,
+	}
+}
+
+// expandHome expands a leading ~/ to the user's home directory.
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
+
 // GenerateKey creates a new SSH key pair using ssh-keygen.
 // Returns the path to the created private key.
 func GenerateKey(path string, keyType string) error {
@@ -651,3 +651,80 @@ func TestInferKeyType_PathVariations(t *testing.T) {
 		})
 	}
 }
+
+func TestKeyForHost(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	sshDir := filepath.Join(home, ".ssh")
+	require.NoError(t, os.MkdirAll(sshDir, 0700))
+
+	writeKey := func(name string) string {
+		path := filepath.Join(sshDir, name)
+		require.NoError(t, os.WriteFile(path, []byte("private"), 0600))
+		require.NoError(t, os.WriteFile(path+".pub", []byte("public"), 0644))
+		return path
+	}
+	defaultKey := writeKey("id_ed25519")
+	workKey := writeKey("work_ed25519")
+	rrKey := writeKey("rr_ecdsa")
+
+	sshConfig := "Host work-box\n    IdentityFile ~/.ssh/work_ed25519\n"
+	require.NoError(t, os.WriteFile(filepath.Join(sshDir, "config"), []byte(sshConfig), 0600))
+
+	t.Run("identity_file wins", func(t *testing.T) {
+		key, err := KeyForHost("work-box", "~/.ssh/rr_ecdsa")
+		require.NoError(t, err)
+		require.NotNil(t, key)
+		assert.Equal(t, rrKey, key.Path)
+		assert.Equal(t, "ecdsa", key.Type)
+		assert.True(t, key.HasPublic)
+	})
+
+	t.Run("missing identity_file is an error", func(t *testing.T) {
+		_, err := KeyForHost("work-box", "~/.ssh/nope")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "doesn't exist")
+	})
+
+	t.Run("ssh config IdentityFile", func(t *testing.T) {
+		key, err := KeyForHost("work-box", "")
+		require.NoError(t, err)
+		require.NotNil(t, key)
+		assert.Equal(t, workKey, key.Path)
+	})
+
+	t.Run("preferred default", func(t *testing.T) {
+		key, err := KeyForHost("other-box", "")
+		require.NoError(t, err)
+		require.NotNil(t, key)
+		assert.Equal(t, defaultKey, key.Path)
+	})
+}
+
+func TestGetPreferredKey_PrefersCertificate(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	sshDir := filepath.Join(home, ".ssh")
+	require.NoError(t, os.MkdirAll(sshDir, 0700))
+
+	for _, name := range []string{"id_ed25519", "id_rsa"} {
+		path := filepath.Join(sshDir, name)
+		require.NoError(t, os.WriteFile(path, []byte("private"), 0600))
+		require.NoError(t, os.WriteFile(path+".pub", []byte("public"), 0644))
+	}
+
+	key := GetPreferredKey()
+	require.NotNil(t, key)
+	assert.Equal(t, "ed25519", key.Type)
+	assert.False(t, key.HasCert)
+
+	// The org signed the RSA key, so that's the one hosts will accept
+	rsaCert := filepath.Join(sshDir, "id_rsa-cert.pub")
+	require.NoError(t, os.WriteFile(rsaCert, []byte("cert"), 0644))
+
+	key = GetPreferredKey()
+	require.NotNil(t, key)
+	assert.Equal(t, "rsa", key.Type)
+	assert.True(t, key.HasCert)
+	assert.Equal(t, rsaCert, key.CertPath)
+}
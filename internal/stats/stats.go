@@ -0,0 +1,227 @@
+// Package stats aggregates anonymized statistics about rr runs on this
+// machine, for 'rr stats': how long each phase takes in total, how many runs
+// failed, and which error IDs come up most. It helps spot patterns like "sync
+// is 80% of my runtime" and decide what to report upstream.
+//
+// Recording is opt-in (stats: true in the global config) and nothing leaves
+// the machine. Only counts, durations, and error IDs like RR-SSH-003 are
+// kept, never commands, hosts, paths, or output. The aggregate lives in
+// ~/.rr/stats.json.
+package stats
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/util"
+)
+
+// statsFile is the file under ~/.rr/ that holds the aggregate.
+const statsFile = "stats.json"
+
+// Enabled is whether RecordRun and RecordError record anything. It's set
+// from the global config's stats setting before each command runs.
+var Enabled bool
+
+// Phase totals one phase (build, connect, lock, sync, exec, ...) across runs.
+type Phase struct {
+	Count int           `json:"count"`
+	Total time.Duration `json:"total"`
+}
+
+// Stats is the aggregate of every run recorded since Since.
+type Stats struct {
+	Since  time.Time        `json:"since"`
+	Runs   int              `json:"runs"`
+	Failed int              `json:"failed"`
+	Total  time.Duration    `json:"total"`
+	Phases map[string]Phase `json:"phases"`
+	Errors map[string]int   `json:"errors"` // Error ID to how often it came up
+}
+
+// PhaseShare is a phase's part of the total run time.
+type PhaseShare struct {
+	Name    string
+	Count   int
+	Total   time.Duration
+	Average time.Duration
+	Share   float64 // Fraction of all recorded run time, 0 to 1
+}
+
+// ErrorCount is how often one error ID came up.
+type ErrorCount struct {
+	ID    string
+	Count int
+}
+
+// Path returns the stats file, ~/.rr/stats.json.
+func Path() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.WrapWithCode(err, errors.ErrConfig,
+			"Can't find your home directory",
+			"This is unusual - check your environment.")
+	}
+	return filepath.Join(home, config.GlobalConfigDir, statsFile), nil
+}
+
+// Load reads the aggregate. Returns empty stats if nothing has been recorded.
+func Load() (*Stats, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	s := &Stats{Phases: map[string]Phase{}, Errors: map[string]int{}}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't read run stats",
+			"Reset them with 'rr stats --reset'.")
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't parse run stats",
+			"Reset them with 'rr stats --reset'.")
+	}
+	if s.Phases == nil {
+		s.Phases = map[string]Phase{}
+	}
+	if s.Errors == nil {
+		s.Errors = map[string]int{}
+	}
+	return s, nil
+}
+
+// save writes the aggregate atomically.
+func save(s *Stats) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't encode run stats",
+			"This is a bug - please report it.")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't create the stats directory",
+			"Check permissions on ~/.rr/.")
+	}
+	if err := util.WriteFileAtomic(path, data, 0644); err != nil {
+		return errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't write run stats",
+			"Check permissions on ~/.rr/.")
+	}
+	return nil
+}
+
+// update loads the aggregate, applies fn, and saves it, starting fresh if
+// the file can't be read.
+func update(fn func(*Stats)) error {
+	s, err := Load()
+	if err != nil {
+		s = &Stats{Phases: map[string]Phase{}, Errors: map[string]int{}}
+	}
+	if s.Since.IsZero() {
+		s.Since = time.Now().UTC()
+	}
+	fn(s)
+	return save(s)
+}
+
+// RecordRun adds a finished run's phase timings and total to the aggregate.
+// Does nothing unless Enabled.
+func RecordRun(phases map[string]time.Duration, total time.Duration, exitCode int) error {
+	if !Enabled {
+		return nil
+	}
+	return update(func(s *Stats) {
+		s.Runs++
+		if exitCode != 0 {
+			s.Failed++
+		}
+		s.Total += total
+		for name, d := range phases {
+			p := s.Phases[name]
+			p.Count++
+			p.Total += d
+			s.Phases[name] = p
+		}
+	})
+}
+
+// RecordError counts an error ID, like RR-SSH-003. Does nothing unless
+// Enabled or if id is empty.
+func RecordError(id string) error {
+	if !Enabled || id == "" {
+		return nil
+	}
+	return update(func(s *Stats) {
+		s.Errors[id]++
+	})
+}
+
+// Reset deletes everything recorded so far.
+func Reset() error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return errors.WrapWithCode(err, errors.ErrConfig,
+			"Couldn't reset run stats",
+			"Delete "+path+" by hand.")
+	}
+	return nil
+}
+
+// PhaseShares returns each phase's part of the total recorded run time,
+// biggest first.
+func (s *Stats) PhaseShares() []PhaseShare {
+	shares := make([]PhaseShare, 0, len(s.Phases))
+	for name, p := range s.Phases {
+		share := PhaseShare{Name: name, Count: p.Count, Total: p.Total}
+		if p.Count > 0 {
+			share.Average = p.Total / time.Duration(p.Count)
+		}
+		if s.Total > 0 {
+			share.Share = float64(p.Total) / float64(s.Total)
+		}
+		shares = append(shares, share)
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].Total != shares[j].Total {
+			return shares[i].Total > shares[j].Total
+		}
+		return shares[i].Name < shares[j].Name
+	})
+	return shares
+}
+
+// TopErrors returns the error IDs that came up most, at most n of them.
+func (s *Stats) TopErrors(n int) []ErrorCount {
+	counts := make([]ErrorCount, 0, len(s.Errors))
+	for id, c := range s.Errors {
+		counts = append(counts, ErrorCount{ID: id, Count: c})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].ID < counts[j].ID
+	})
+	if n > 0 && len(counts) > n {
+		counts = counts[:n]
+	}
+	return counts
+}
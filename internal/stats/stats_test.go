@@ -0,0 +1,96 @@
+package stats
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withEnabled turns recording on for a test with a fresh home directory.
+func withEnabled(t *testing.T, on bool) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	old := Enabled
+	Enabled = on
+	t.Cleanup(func() { Enabled = old })
+	return home
+}
+
+func TestRecord_DisabledWritesNothing(t *testing.T) {
+	home := withEnabled(t, false)
+
+	require.NoError(t, RecordRun(map[string]time.Duration{"sync": time.Second}, time.Second, 0))
+	require.NoError(t, RecordError("RR-SSH-003"))
+
+	_, err := os.Stat(filepath.Join(home, ".rr", "stats.json"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRecordRunAndError(t *testing.T) {
+	withEnabled(t, true)
+
+	require.NoError(t, RecordRun(map[string]time.Duration{"sync": 8 * time.Second, "exec": 2 * time.Second}, 10*time.Second, 0))
+	require.NoError(t, RecordRun(map[string]time.Duration{"sync": 4 * time.Second}, 5*time.Second, 1))
+	require.NoError(t, RecordError("RR-SSH-003"))
+	require.NoError(t, RecordError("RR-SSH-003"))
+	require.NoError(t, RecordError("RR-SYNC-001"))
+	require.NoError(t, RecordError(""))
+
+	s, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, 2, s.Runs)
+	assert.Equal(t, 1, s.Failed)
+	assert.Equal(t, 15*time.Second, s.Total)
+	assert.Equal(t, Phase{Count: 2, Total: 12 * time.Second}, s.Phases["sync"])
+	assert.False(t, s.Since.IsZero())
+
+	shares := s.PhaseShares()
+	require.Len(t, shares, 2)
+	assert.Equal(t, "sync", shares[0].Name)
+	assert.InDelta(t, 0.8, shares[0].Share, 0.001)
+	assert.Equal(t, 6*time.Second, shares[0].Average)
+
+	assert.Equal(t, []ErrorCount{{"RR-SSH-003", 2}, {"RR-SYNC-001", 1}}, s.TopErrors(5))
+	assert.Len(t, s.TopErrors(1), 1)
+}
+
+func TestLoad_NothingRecorded(t *testing.T) {
+	withEnabled(t, true)
+
+	s, err := Load()
+	require.NoError(t, err)
+	assert.Zero(t, s.Runs)
+	assert.Empty(t, s.PhaseShares())
+	assert.Empty(t, s.TopErrors(5))
+}
+
+func TestReset(t *testing.T) {
+	withEnabled(t, true)
+
+	require.NoError(t, RecordError("RR-SSH-003"))
+	require.NoError(t, Reset())
+	require.NoError(t, Reset(), "resetting twice is fine")
+
+	s, err := Load()
+	require.NoError(t, err)
+	assert.Empty(t, s.Errors)
+}
+
+func TestRecord_StartsOverOnCorruptFile(t *testing.T) {
+	home := withEnabled(t, true)
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".rr"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".rr", "stats.json"), []byte("{nope"), 0644))
+
+	_, err := Load()
+	assert.Error(t, err)
+
+	require.NoError(t, RecordError("RR-LOCK-001"))
+	s, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, 1, s.Errors["RR-LOCK-001"])
+}
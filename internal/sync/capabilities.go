@@ -0,0 +1,182 @@
+package sync
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	gosync "sync"
+)
+
+// Variant identifies an rsync implementation.
+type Variant string
+
+const (
+	// VariantGNU is the rsync from rsync.samba.org that Linux distros and
+	// Homebrew ship.
+	VariantGNU Variant = "gnu"
+
+	// VariantOpenRsync is the BSD rewrite macOS ships as /usr/bin/rsync
+	// since Sequoia. It speaks protocol 29 but only supports a subset of
+	// GNU rsync's flags.
+	VariantOpenRsync Variant = "openrsync"
+)
+
+// Feature is an rsync capability that rr's argv depends on.
+type Feature string
+
+const (
+	// FeatureProgress2 is --info=progress2, the whole-transfer progress
+	// line the sync progress bar parses.
+	FeatureProgress2 Feature = "progress2"
+
+	// FeatureFilter is --filter rules: "P" to protect preserved paths from
+	// --delete and ":- .gitignore" to read ignore files per directory.
+	FeatureFilter Feature = "filter"
+
+	// FeatureForce is --force, which deletes non-empty directories that
+	// were replaced by files.
+	FeatureForce Feature = "force"
+
+	// FeaturePartialDir is --partial-dir, which keeps partially transferred
+	// files so an interrupted sync resumes instead of starting over.
+	FeaturePartialDir Feature = "partial-dir"
+
+	// FeatureShards is "dir/***" include patterns, which match a directory
+	// and everything under it. sync.parallel uses them to give each rsync
+	// its own shard of the project.
+	FeatureShards Feature = "shards"
+)
+
+// featureSupport is one row of the capability matrix: the oldest GNU rsync
+// with the feature, and whether openrsync has it.
+type featureSupport struct {
+	gnu       string
+	openrsync bool
+}
+
+// capabilityMatrix maps each feature to the rsync variants and versions
+// that support it. Features not listed here are assumed universal.
+var capabilityMatrix = map[Feature]featureSupport{
+	FeatureProgress2:  {gnu: "3.1.0", openrsync: false},
+	FeatureFilter:     {gnu: "2.6.0", openrsync: false},
+	FeatureForce:      {gnu: "2.6.0", openrsync: false},
+	FeaturePartialDir: {gnu: "2.6.0", openrsync: false},
+	FeatureShards:     {gnu: "2.6.7", openrsync: false},
+}
+
+// Rsync describes a local rsync installation.
+type Rsync struct {
+	Path    string
+	Variant Variant
+	Version string // e.g. "3.2.7"; empty when unknown or for openrsync
+}
+
+// ModernRsync is a current GNU rsync that supports every feature. It's
+// assumed when the installed version can't be determined, so an unusual
+// version string doesn't quietly degrade syncs.
+var ModernRsync = Rsync{Variant: VariantGNU}
+
+// Supports reports whether this rsync has the given feature.
+func (r Rsync) Supports(f Feature) bool {
+	support, ok := capabilityMatrix[f]
+	if !ok {
+		return true
+	}
+	switch r.Variant {
+	case VariantOpenRsync:
+		return support.openrsync
+	default:
+		return r.Version == "" || versionAtLeast(r.Version, support.gnu)
+	}
+}
+
+// String returns a short description like "3.2.7" or "openrsync".
+func (r Rsync) String() string {
+	switch {
+	case r.Variant == VariantOpenRsync:
+		return "openrsync"
+	case r.Version != "":
+		return r.Version
+	default:
+		return "unknown"
+	}
+}
+
+// Missing returns the features this rsync lacks, in a stable order.
+func (r Rsync) Missing() []Feature {
+	var missing []Feature
+	for _, f := range []Feature{FeatureProgress2, FeatureFilter, FeatureForce, FeaturePartialDir, FeatureShards} {
+		if !r.Supports(f) {
+			missing = append(missing, f)
+		}
+	}
+	return missing
+}
+
+// gnuVersionRegex matches "rsync  version 3.2.7  protocol version 31" and
+// "rsync  version v3.2.3  protocol version 31".
+var gnuVersionRegex = regexp.MustCompile(`rsync\s+version\s+v?(\d+\.\d+(?:\.\d+)?)`)
+
+// ParseRsyncVersion identifies the variant and version from the output of
+// 'rsync --version'. openrsync prints "openrsync: protocol version 29" and
+// then claims "rsync version 2.6.9 compatible", so it's checked first.
+func ParseRsyncVersion(output string) Rsync {
+	if strings.HasPrefix(strings.TrimSpace(output), "openrsync") {
+		return Rsync{Variant: VariantOpenRsync}
+	}
+	if m := gnuVersionRegex.FindStringSubmatch(output); m != nil {
+		return Rsync{Variant: VariantGNU, Version: m[1]}
+	}
+	return ModernRsync
+}
+
+// rsyncVersionOutput runs 'rsync --version'. Swapped out in tests.
+var rsyncVersionOutput = func(path string) (string, error) {
+	out, err := rsyncCommand(path, "--version").Output()
+	return string(out), err
+}
+
+var (
+	detectedMu gosync.Mutex
+	detected   = map[string]Rsync{}
+)
+
+// DetectRsync identifies the rsync at path, caching the result for the
+// life of the process. If the version can't be read it returns ModernRsync,
+// leaving handleRsyncError to explain any flag rsync rejects.
+func DetectRsync(path string) Rsync {
+	detectedMu.Lock()
+	defer detectedMu.Unlock()
+
+	if r, ok := detected[path]; ok {
+		return r
+	}
+
+	r := ModernRsync
+	if out, err := rsyncVersionOutput(path); err == nil {
+		r = ParseRsyncVersion(out)
+	}
+	r.Path = path
+	detected[path] = r
+	return r
+}
+
+// versionAtLeast reports whether dotted version v is at least min.
+func versionAtLeast(v, min string) bool {
+	a, b := strings.Split(v, "."), strings.Split(min, ".")
+	for i := 0; i < len(a) || i < len(b); i++ {
+		x, y := versionPart(a, i), versionPart(b, i)
+		if x != y {
+			return x > y
+		}
+	}
+	return true
+}
+
+func versionPart(parts []string, i int) int {
+	if i >= len(parts) {
+		return 0
+	}
+	n, _ := strconv.Atoi(parts[i])
+	return n
+}
@@ -0,0 +1,253 @@
+package sync
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	openRsync  = Rsync{Variant: VariantOpenRsync}
+	appleRsync = Rsync{Variant: VariantGNU, Version: "2.6.9"}
+)
+
+func TestParseRsyncVersion(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   Rsync
+	}{
+		{
+			name:   "gnu",
+			output: "rsync  version 3.2.7  protocol version 31\nCopyright (C) 1996-2022 by Andrew Tridgell",
+			want:   Rsync{Variant: VariantGNU, Version: "3.2.7"},
+		},
+		{
+			name:   "gnu with v prefix",
+			output: "rsync  version v3.2.3  protocol version 31",
+			want:   Rsync{Variant: VariantGNU, Version: "3.2.3"},
+		},
+		{
+			name:   "apple gnu 2.6.9",
+			output: "rsync  version 2.6.9  protocol version 29",
+			want:   appleRsync,
+		},
+		{
+			name:   "openrsync",
+			output: "openrsync: protocol version 29\nrsync version 2.6.9 compatible",
+			want:   openRsync,
+		},
+		{
+			name:   "unrecognized",
+			output: "something else entirely",
+			want:   ModernRsync,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseRsyncVersion(tt.output))
+		})
+	}
+}
+
+func TestRsyncSupports(t *testing.T) {
+	tests := []struct {
+		rsync   Rsync
+		feature Feature
+		want    bool
+	}{
+		{Rsync{Variant: VariantGNU, Version: "3.2.7"}, FeatureProgress2, true},
+		{Rsync{Variant: VariantGNU, Version: "3.1.0"}, FeatureProgress2, true},
+		{Rsync{Variant: VariantGNU, Version: "3.0.9"}, FeatureProgress2, false},
+		{appleRsync, FeatureProgress2, false},
+		{appleRsync, FeatureFilter, true},
+		{appleRsync, FeatureForce, true},
+		{openRsync, FeatureProgress2, false},
+		{openRsync, FeatureFilter, false},
+		{openRsync, FeatureForce, false},
+		{ModernRsync, FeatureProgress2, true},
+		{openRsync, Feature("compress"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%s", tt.rsync, tt.feature), func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.rsync.Supports(tt.feature))
+		})
+	}
+}
+
+func TestRsyncMissing(t *testing.T) {
+	assert.Empty(t, ModernRsync.Missing())
+	assert.Equal(t, []Feature{FeatureProgress2}, appleRsync.Missing())
+	assert.Equal(t, []Feature{FeatureProgress2, FeatureFilter, FeatureForce, FeaturePartialDir, FeatureShards}, openRsync.Missing())
+}
+
+func TestVersionAtLeast(t *testing.T) {
+	assert.True(t, versionAtLeast("3.1.0", "3.1.0"))
+	assert.True(t, versionAtLeast("3.10", "3.1.0"))
+	assert.True(t, versionAtLeast("3.1", "3.1.0"))
+	assert.False(t, versionAtLeast("3.0.9", "3.1.0"))
+	assert.False(t, versionAtLeast("2.6.9", "3.1.0"))
+}
+
+func TestDetectRsync_CachesByPath(t *testing.T) {
+	calls := 0
+	orig := rsyncVersionOutput
+	rsyncVersionOutput = func(path string) (string, error) {
+		calls++
+		return "openrsync: protocol version 29\n", nil
+	}
+	t.Cleanup(func() { rsyncVersionOutput = orig })
+
+	path := filepath.Join(t.TempDir(), "rsync")
+	first := DetectRsync(path)
+	second := DetectRsync(path)
+
+	assert.Equal(t, VariantOpenRsync, first.Variant)
+	assert.Equal(t, path, first.Path)
+	assert.Equal(t, first, second)
+	assert.Equal(t, 1, calls)
+}
+
+func TestDetectRsync_VersionFailsAssumesModern(t *testing.T) {
+	orig := rsyncVersionOutput
+	rsyncVersionOutput = func(path string) (string, error) {
+		return "", fmt.Errorf("exec failed")
+	}
+	t.Cleanup(func() { rsyncVersionOutput = orig })
+
+	r := DetectRsync(filepath.Join(t.TempDir(), "rsync"))
+	assert.True(t, r.Supports(FeatureProgress2))
+}
+
+func TestFindRsync_PrefersPerOSInstall(t *testing.T) {
+	dir := t.TempDir()
+	preferred := filepath.Join(dir, "rsync")
+	require.NoError(t, os.WriteFile(preferred, []byte("#!/bin/sh\n"), 0755))
+
+	origOS, origPreferred := goos, preferredRsync
+	goos = "testos"
+	preferredRsync = map[string][]string{"testos": {filepath.Join(dir, "missing"), preferred}}
+	t.Cleanup(func() { goos, preferredRsync = origOS, origPreferred })
+
+	// Nothing on PATH: the preferred install is found anyway
+	t.Setenv("PATH", t.TempDir())
+	path, err := FindRsync()
+	require.NoError(t, err)
+	assert.Equal(t, preferred, path)
+
+	// Another OS has no preferred installs
+	goos = "otheros"
+	_, err = FindRsync()
+	assert.Error(t, err)
+}
+
+func TestBuildArgs_Capabilities(t *testing.T) {
+	localDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(localDir, ".gitignore"), []byte("dist/\n"), 0644))
+
+	conn := &host.Connection{
+		Name:  "test",
+		Alias: "test-host",
+		Host:  config.Host{Dir: "/remote/dir"},
+	}
+	cfg := config.SyncConfig{
+		Preserve:         []string{".venv/"},
+		Exclude:          []string{"*.log"},
+		RespectGitignore: true,
+	}
+
+	t.Run("modern gnu", func(t *testing.T) {
+		args, err := buildArgs(Rsync{Variant: VariantGNU, Version: "3.2.7"}, conn, localDir, cfg)
+		require.NoError(t, err)
+		assert.Contains(t, args, "--force")
+		assert.Contains(t, args, "--info=progress2")
+		assert.Contains(t, args, "--filter=P .venv/")
+		assert.Contains(t, args, "--filter=:- .gitignore")
+		assert.Contains(t, args, "--partial-dir=.rr-partial")
+	})
+
+	t.Run("apple gnu 2.6.9", func(t *testing.T) {
+		args, err := buildArgs(appleRsync, conn, localDir, cfg)
+		require.NoError(t, err)
+		assert.Contains(t, args, "--force")
+		assert.NotContains(t, args, "--info=progress2")
+		assert.Contains(t, args, "--filter=P .venv/")
+	})
+
+	t.Run("openrsync", func(t *testing.T) {
+		args, err := buildArgs(openRsync, conn, localDir, cfg)
+		require.NoError(t, err)
+		for _, arg := range args {
+			assert.NotContains(t, arg, "--filter")
+		}
+		assert.NotContains(t, args, "--force")
+		assert.NotContains(t, args, "--info=progress2")
+		assert.NotContains(t, args, "--partial-dir=.rr-partial")
+		assert.Contains(t, args, "--exclude=.venv/")
+		assert.Contains(t, args, "--exclude=*.log")
+		assert.Contains(t, args, "--exclude-from="+filepath.Join(localDir, ".gitignore"))
+		assert.Equal(t, "test-host:/remote/dir/", args[len(args)-1])
+	})
+
+	t.Run("openrsync without gitignore", func(t *testing.T) {
+		args, err := buildArgs(openRsync, conn, t.TempDir(), cfg)
+		require.NoError(t, err)
+		for _, arg := range args {
+			assert.NotContains(t, arg, "--exclude-from")
+		}
+	})
+}
+
+func TestBuildPullArgs_Capabilities(t *testing.T) {
+	conn := &host.Connection{
+		Name:  "test",
+		Alias: "test-host",
+		Host:  config.Host{Dir: "/remote/dir"},
+	}
+
+	args, err := buildPullArgs(openRsync, conn, []string{"out/"}, ".", nil)
+	require.NoError(t, err)
+	assert.NotContains(t, args, "--info=progress2")
+	assert.Equal(t, "-az", args[0])
+}
+
+func TestRemoteRsyncPath(t *testing.T) {
+	orig := detectedRsyncPath
+	t.Cleanup(func() { detectedRsyncPath = orig })
+	detected := map[string]string{"mac": "/opt/homebrew/bin/rsync"}
+	detectedRsyncPath = func(hostName string) string { return detected[hostName] }
+
+	assert.Empty(t, RemoteRsyncPath(nil))
+	assert.Empty(t, RemoteRsyncPath(&host.Connection{Name: "linux"}), "PATH's rsync")
+	assert.Equal(t, "/opt/homebrew/bin/rsync", RemoteRsyncPath(&host.Connection{Name: "mac"}), "found by host detection")
+	assert.Equal(t, "/usr/local/bin/rsync",
+		RemoteRsyncPath(&host.Connection{Name: "mac", Host: config.Host{RsyncPath: "/usr/local/bin/rsync"}}),
+		"rsync_path wins")
+
+	conn := &host.Connection{Name: "mac", Alias: "mac-host", Host: config.Host{Dir: "/remote/dir"}}
+	args, err := buildArgs(ModernRsync, conn, t.TempDir(), config.SyncConfig{})
+	require.NoError(t, err)
+	assert.Contains(t, args, "--rsync-path=/opt/homebrew/bin/rsync")
+
+	args, err = buildPullArgs(ModernRsync, conn, []string{"out/"}, ".", nil)
+	require.NoError(t, err)
+	assert.Contains(t, args, "--rsync-path=/opt/homebrew/bin/rsync")
+
+	args = buildCopyArgs(ModernRsync, conn, CopyOptions{Sources: []string{"a"}, Dest: "b"})
+	assert.Contains(t, args, "--rsync-path=/opt/homebrew/bin/rsync")
+
+	conn.Name = "linux"
+	args, err = buildArgs(ModernRsync, conn, t.TempDir(), config.SyncConfig{})
+	require.NoError(t, err)
+	for _, arg := range args {
+		assert.NotContains(t, arg, "--rsync-path")
+	}
+}
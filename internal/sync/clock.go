@@ -0,0 +1,109 @@
+package sync
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/pkg/sshutil"
+)
+
+const (
+	// ClockSkewWarn is how far a host's clock can drift from the local one
+	// before rr warns about it. Build tools that compare mtimes start
+	// rebuilding too much, or too little, past a couple of seconds.
+	ClockSkewWarn = 2 * time.Second
+
+	// ClockSkewChecksum is the drift past which syncs compare file contents
+	// (--checksum) instead of trusting sizes and mtimes.
+	ClockSkewChecksum = time.Minute
+)
+
+// clockCommand prints the host's time in seconds since the epoch. It's the
+// one format both GNU and BSD date support.
+const clockCommand = "date +%s"
+
+// MeasureClockSkew returns how far the host's clock is ahead of the local
+// one (negative if it's behind), to the nearest second. The remote reading
+// is compared against the local time halfway through the round trip, so
+// latency doesn't count as skew.
+func MeasureClockSkew(client sshutil.SSHClient) (time.Duration, error) {
+	before := time.Now()
+	stdout, stderr, exitCode, err := client.Exec(clockCommand)
+	after := time.Now()
+	if err != nil {
+		return 0, err
+	}
+	if exitCode != 0 {
+		return 0, fmt.Errorf("date exited %d: %s", exitCode, strings.TrimSpace(string(stderr)))
+	}
+	secs, err := strconv.ParseInt(strings.TrimSpace(string(stdout)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't parse the host's time %q", strings.TrimSpace(string(stdout)))
+	}
+	return clockSkew(secs, before, after), nil
+}
+
+// clockSkew works out the skew from a remote reading in whole seconds taken
+// between before and after. The reading was truncated, so the remote time
+// is taken to be halfway through that second.
+func clockSkew(remoteSecs int64, before, after time.Time) time.Duration {
+	remote := time.Unix(remoteSecs, int64(500*time.Millisecond))
+	local := before.Add(after.Sub(before) / 2)
+	return remote.Sub(local).Round(time.Second)
+}
+
+// DescribeClockSkew says which way and how far a host's clock is off, like
+// "5m ahead" or "40s behind".
+func DescribeClockSkew(skew time.Duration) string {
+	if skew < 0 {
+		return formatSkew(-skew) + " behind"
+	}
+	return formatSkew(skew) + " ahead"
+}
+
+// formatSkew formats a skew to the precision that matters at its size.
+func formatSkew(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%dh%dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
+
+// ClockSkewHint suggests how to get a host's clock back in step.
+const ClockSkewHint = "Turn on NTP on the host: 'sudo timedatectl set-ntp true' on Linux, " +
+	"'sudo sntp -sS time.apple.com' on macOS. Check the local clock too."
+
+// ExceedsClockSkew reports whether skew is at least limit in either
+// direction.
+func ExceedsClockSkew(skew, limit time.Duration) bool {
+	return skew >= limit || skew <= -limit
+}
+
+// ChecksumForSkew returns cfg with --checksum added when skew is large
+// enough that mtimes can't be trusted. cfg's flags aren't modified.
+func ChecksumForSkew(cfg config.SyncConfig, skew time.Duration) config.SyncConfig {
+	if !ExceedsClockSkew(skew, ClockSkewChecksum) || hasChecksumFlag(cfg.Flags) {
+		return cfg
+	}
+	cfg.Flags = append(slices.Clone(cfg.Flags), "--checksum")
+	return cfg
+}
+
+// hasChecksumFlag reports whether the flags already ask rsync to compare
+// file contents.
+func hasChecksumFlag(flags []string) bool {
+	for _, f := range flags {
+		if f == "--checksum" || f == "-c" {
+			return true
+		}
+	}
+	return false
+}
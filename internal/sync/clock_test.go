@@ -0,0 +1,52 @@
+package sync
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	sshtesting "github.com/rileyhilliard/rr/pkg/sshutil/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClockSkew(t *testing.T) {
+	before := time.Unix(1000, 100*int64(time.Millisecond))
+	after := before.Add(800 * time.Millisecond)
+
+	assert.Equal(t, time.Duration(0), clockSkew(1000, before, after), "same second")
+	assert.Equal(t, 5*time.Minute, clockSkew(1300, before, after))
+	assert.Equal(t, -40*time.Second, clockSkew(960, before, after))
+}
+
+func TestMeasureClockSkew(t *testing.T) {
+	mock := sshtesting.NewMockClient("box")
+	ahead := strconv.FormatInt(time.Now().Add(10*time.Minute).Unix(), 10)
+	mock.SetCommandResponse("^date", sshtesting.CommandResponse{Stdout: []byte(ahead + "\n")})
+
+	skew, err := MeasureClockSkew(mock)
+	require.NoError(t, err)
+	assert.InDelta(t, (10 * time.Minute).Seconds(), skew.Seconds(), 2)
+
+	mock.SetCommandResponse("^date", sshtesting.CommandResponse{Stdout: []byte("not a time\n")})
+	_, err = MeasureClockSkew(mock)
+	assert.Error(t, err)
+}
+
+func TestDescribeClockSkew(t *testing.T) {
+	assert.Equal(t, "5m ahead", DescribeClockSkew(5*time.Minute))
+	assert.Equal(t, "40s behind", DescribeClockSkew(-40*time.Second))
+	assert.Equal(t, "2h5m ahead", DescribeClockSkew(2*time.Hour+5*time.Minute))
+}
+
+func TestChecksumForSkew(t *testing.T) {
+	cfg := config.SyncConfig{Flags: []string{"--compress"}}
+
+	assert.Equal(t, []string{"--compress"}, ChecksumForSkew(cfg, 10*time.Second).Flags, "small skew keeps mtimes")
+	assert.Equal(t, []string{"--compress", "--checksum"}, ChecksumForSkew(cfg, -2*time.Minute).Flags)
+	assert.Equal(t, []string{"--compress"}, cfg.Flags, "original flags untouched")
+
+	already := config.SyncConfig{Flags: []string{"-c"}}
+	assert.Equal(t, []string{"-c"}, ChecksumForSkew(already, time.Hour).Flags)
+}
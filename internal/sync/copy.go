@@ -0,0 +1,152 @@
+package sync
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/rileyhilliard/rr/internal/util"
+)
+
+// CopyOptions configures a one-off copy between this machine and a host.
+type CopyOptions struct {
+	// Sources are local paths when uploading, or remote paths when
+	// downloading. Remote paths can be globs, expanded on the host.
+	Sources []string
+
+	// Dest is the remote path when uploading, or the local path when
+	// downloading. A trailing / makes it a directory to copy into.
+	Dest string
+
+	// Upload copies Sources from this machine to Dest on the host.
+	Upload bool
+
+	// Flags are extra rsync flags to pass.
+	Flags []string
+}
+
+// RemotePath resolves a path on a host: absolute and ~ paths are used as
+// given, and anything else is relative to the host's dir (or the home
+// directory if it has none).
+func RemotePath(h config.Host, p string) string {
+	if strings.HasPrefix(p, "/") || p == "~" || strings.HasPrefix(p, "~/") {
+		return p
+	}
+	dir := strings.TrimSuffix(config.ExpandRemote(h.Dir), "/")
+	if dir == "" {
+		dir = "~"
+	}
+	if p == "" || p == "." {
+		return dir + "/"
+	}
+	return dir + "/" + p
+}
+
+// Copy transfers files between this machine and the host with rsync, the
+// way scp would but with the host's SSH settings and paths relative to its
+// dir. Directories are copied whole. Progress output is streamed to the
+// progress writer if provided.
+func Copy(conn *host.Connection, opts CopyOptions, progress io.Writer) error {
+	if conn == nil || conn.IsLocal || conn.Client == nil {
+		return errors.New(errors.ErrSync,
+			"No SSH connection available",
+			"Connect to the remote host first.")
+	}
+	if len(opts.Sources) == 0 {
+		return errors.New(errors.ErrSync,
+			"Nothing to copy",
+			"Give at least one source path.")
+	}
+
+	rsyncPath, err := FindRsync()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(controlSocketDir, 0700); err != nil {
+		return errors.WrapWithCode(err, errors.ErrSync,
+			fmt.Sprintf("creating SSH control socket dir %s", controlSocketDir),
+			"Check directory permissions and disk space")
+	}
+
+	if opts.Upload {
+		for _, src := range opts.Sources {
+			if _, err := os.Stat(src); err != nil {
+				return errors.New(errors.ErrSync,
+					fmt.Sprintf("Can't copy %s: it doesn't exist", src),
+					"Check the path. Local paths are relative to the current directory.")
+			}
+		}
+		if err := ensureCopyDestDir(conn, RemotePath(conn.Host, opts.Dest)); err != nil {
+			return err
+		}
+	} else if strings.HasSuffix(opts.Dest, "/") {
+		if err := os.MkdirAll(opts.Dest, 0755); err != nil {
+			return errors.WrapWithCode(err, errors.ErrSync,
+				fmt.Sprintf("Couldn't create destination directory %s", opts.Dest),
+				"Check file permissions.")
+		}
+	}
+
+	args := buildCopyArgs(DetectRsync(rsyncPath), conn, opts)
+	return runRsyncPull(rsyncPath, args, conn.Name, progress)
+}
+
+// buildCopyArgs constructs the rsync arguments for a copy, using only flags
+// the given rsync supports.
+func buildCopyArgs(rsync Rsync, conn *host.Connection, opts CopyOptions) []string {
+	args := []string{
+		"-az", // archive mode, compress
+		"-e", buildSSHCmd(conn.Host),
+	}
+	args = append(args, rsyncPathArgs(conn)...)
+	if rsync.Supports(FeatureProgress2) {
+		args = append(args, "--info=progress2")
+	}
+	if rsync.Supports(FeaturePartialDir) {
+		args = append(args, "--partial-dir="+PartialDir)
+	}
+	args = append(args, opts.Flags...)
+
+	remote := func(p string) string {
+		return conn.Alias + ":" + RemotePath(conn.Host, p)
+	}
+	if opts.Upload {
+		args = append(args, opts.Sources...)
+		return append(args, remote(opts.Dest))
+	}
+	for _, src := range opts.Sources {
+		args = append(args, remote(src))
+	}
+	dest := opts.Dest
+	if dest == "" {
+		dest = "."
+	}
+	return append(args, dest)
+}
+
+// ensureCopyDestDir creates the remote directory an upload goes into: dest
+// itself if it ends in /, otherwise its parent.
+func ensureCopyDestDir(conn *host.Connection, dest string) error {
+	dir := dest
+	if !strings.HasSuffix(dest, "/") {
+		dir = path.Dir(dest)
+	}
+	mkdirCmd := fmt.Sprintf("mkdir -p %s", util.ShellQuotePreserveTilde(dir))
+	_, stderr, exitCode, err := conn.Client.Exec(mkdirCmd)
+	if err != nil {
+		return errors.WrapWithCode(err, errors.ErrSync,
+			"Couldn't create remote directory",
+			"Check your SSH connection.")
+	}
+	if exitCode != 0 {
+		return errors.New(errors.ErrSync,
+			fmt.Sprintf("Couldn't create remote directory %s", dir),
+			fmt.Sprintf("Remote error: %s", strings.TrimSpace(string(stderr))))
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package sync
+
+import (
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemotePath(t *testing.T) {
+	h := config.Host{Dir: "~/rr/app/"}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"results/out.json", "~/rr/app/results/out.json"},
+		{"", "~/rr/app/"},
+		{".", "~/rr/app/"},
+		{"/tmp/data", "/tmp/data"},
+		{"~/scratch", "~/scratch"},
+		{"~", "~"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, RemotePath(h, tt.path))
+		})
+	}
+
+	assert.Equal(t, "~/notes.txt", RemotePath(config.Host{}, "notes.txt"), "no dir means the home directory")
+}
+
+func TestBuildCopyArgs(t *testing.T) {
+	conn := &host.Connection{
+		Name:  "gpu-box",
+		Alias: "gpu",
+		Host:  config.Host{Dir: "~/rr/app"},
+	}
+
+	t.Run("upload", func(t *testing.T) {
+		args := buildCopyArgs(ModernRsync, conn, CopyOptions{
+			Sources: []string{"model.bin", "data/"},
+			Dest:    "weights/",
+			Upload:  true,
+		})
+		assert.Contains(t, args, "-az")
+		assert.Contains(t, args, "--info=progress2")
+		assert.Equal(t, []string{"model.bin", "data/", "gpu:~/rr/app/weights/"}, args[len(args)-3:])
+		assert.NotContains(t, args, "--delete")
+	})
+
+	t.Run("download", func(t *testing.T) {
+		args := buildCopyArgs(ModernRsync, conn, CopyOptions{
+			Sources: []string{"results/*.json", "/var/log/app.log"},
+			Dest:    "out/",
+			Flags:   []string{"--dry-run"},
+		})
+		assert.Contains(t, args, "--dry-run")
+		assert.Equal(t, []string{"gpu:~/rr/app/results/*.json", "gpu:/var/log/app.log", "out/"}, args[len(args)-3:])
+	})
+
+	t.Run("download to current directory", func(t *testing.T) {
+		args := buildCopyArgs(ModernRsync, conn, CopyOptions{Sources: []string{"a.txt"}})
+		assert.Equal(t, ".", args[len(args)-1])
+	})
+
+	t.Run("old rsync", func(t *testing.T) {
+		args := buildCopyArgs(Rsync{Variant: VariantOpenRsync}, conn, CopyOptions{Sources: []string{"a.txt"}, Dest: "b/", Upload: true})
+		assert.NotContains(t, args, "--info=progress2")
+	})
+}
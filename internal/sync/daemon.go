@@ -0,0 +1,323 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	gosync "sync"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/util"
+)
+
+// daemonStateDir is the directory under ~/.rr/ that holds sync daemon state files.
+const daemonStateDir = "daemon"
+
+// DefaultDaemonDebounce is how long the daemon waits for file activity to
+// settle before pushing. Editors often write several files (or the same file
+// several times) per save, so this batches them into one rsync.
+const DefaultDaemonDebounce = 100 * time.Millisecond
+
+// daemonRetryInterval is how often the daemon retries a failed sync while
+// there are still unsynced changes (e.g., host locked by a running command).
+const daemonRetryInterval = 5 * time.Second
+
+// DaemonState records what a running sync daemon has pushed. It's written to
+// ~/.rr/daemon/ so other rr processes can tell whether the remote is current
+// and skip their own sync phase.
+type DaemonState struct {
+	PID       int       `json:"pid"`
+	Host      string    `json:"host"`
+	RemoteDir string    `json:"remote_dir"`
+	LocalDir  string    `json:"local_dir"`
+	Dirty     bool      `json:"dirty"` // true when local changes haven't been pushed yet
+	LastSync  time.Time `json:"last_sync,omitempty"`
+}
+
+// IsCurrent reports whether the daemon has pushed every local change to the
+// given host and remote directory, and is still running to catch new ones.
+func (s *DaemonState) IsCurrent(hostName, remoteDir string) bool {
+	if s == nil || s.Dirty || s.LastSync.IsZero() {
+		return false
+	}
+	if s.Host != hostName || s.RemoteDir != remoteDir {
+		return false
+	}
+	return util.ProcessAlive(s.PID)
+}
+
+// Running reports whether the daemon process that wrote the state is alive.
+func (s *DaemonState) Running() bool {
+	return s != nil && util.ProcessAlive(s.PID)
+}
+
+// DaemonStatePath returns the state file path for a local project directory.
+// The directory is hashed so nested paths map to a flat, filesystem-safe name.
+func DaemonStatePath(localDir string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.WrapWithCode(err, errors.ErrSync,
+			"Can't find your home directory",
+			"This is unusual - check your environment.")
+	}
+	name := config.ProjectStateKey(localDir) + ".json"
+	return filepath.Join(home, config.GlobalConfigDir, daemonStateDir, name), nil
+}
+
+// ReadDaemonState loads the daemon state for a local directory.
+// Returns (nil, nil) if no daemon has run there.
+func ReadDaemonState(localDir string) (*DaemonState, error) {
+	path, err := DaemonStatePath(localDir)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.WrapWithCode(err, errors.ErrSync,
+			"Couldn't read sync daemon state",
+			"Delete "+path+" and restart 'rr sync --daemon'.")
+	}
+
+	var state DaemonState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, errors.WrapWithCode(err, errors.ErrSync,
+			"Sync daemon state is corrupted",
+			"Delete "+path+" and restart 'rr sync --daemon'.")
+	}
+	return &state, nil
+}
+
+// WriteDaemonState persists daemon state atomically so readers never see a
+// half-written file.
+func WriteDaemonState(state *DaemonState) error {
+	path, err := DaemonStatePath(state.LocalDir)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.WrapWithCode(err, errors.ErrSync,
+			"Couldn't create sync daemon state directory",
+			"Check permissions on ~/.rr/.")
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return errors.WrapWithCode(err, errors.ErrSync,
+			"Couldn't encode sync daemon state",
+			"This is a bug - please report it.")
+	}
+
+	if err := util.WriteFileAtomic(path, data, 0644); err != nil {
+		return errors.WrapWithCode(err, errors.ErrSync,
+			"Couldn't write sync daemon state",
+			"Check permissions on ~/.rr/.")
+	}
+	return nil
+}
+
+// RemoveDaemonState deletes the state file for a local directory.
+// Missing files are not an error.
+func RemoveDaemonState(localDir string) error {
+	path, err := DaemonStatePath(localDir)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return errors.WrapWithCode(err, errors.ErrSync,
+			"Couldn't remove sync daemon state",
+			"Delete "+path+" manually.")
+	}
+	return nil
+}
+
+// DaemonIsCurrent reports whether a running sync daemon has already pushed the
+// latest local changes in localDir to hostName:remoteDir. Any error reading
+// state is treated as "not current" so callers fall back to a normal sync.
+func DaemonIsCurrent(localDir, hostName, remoteDir string) bool {
+	state, err := ReadDaemonState(localDir)
+	if err != nil || state == nil {
+		return false
+	}
+	return state.IsCurrent(hostName, remoteDir)
+}
+
+// DaemonOptions configures RunDaemon.
+type DaemonOptions struct {
+	LocalDir  string            // Local project directory to watch
+	Host      string            // Host name being synced to (recorded in state)
+	RemoteDir string            // Expanded remote directory (recorded in state)
+	Config    config.SyncConfig // Sync config; excludes are also skipped by the watcher
+	Debounce  time.Duration     // Quiet period before pushing (0 uses DefaultDaemonDebounce)
+
+	// SyncFunc performs one sync pass. Called once at startup and again after
+	// each batch of changes settles.
+	SyncFunc func() error
+
+	// OnSync is called after every sync attempt with its duration and result.
+	OnSync func(duration time.Duration, err error)
+}
+
+// RunDaemon watches LocalDir and calls SyncFunc whenever files change, keeping
+// the daemon state file up to date so other rr commands know whether they can
+// skip syncing. Runs until ctx is canceled.
+//
+// Changes are tracked with a generation counter: the state is only marked
+// clean if no new change arrived while a sync was in flight, so a file saved
+// mid-rsync is never reported as pushed.
+func RunDaemon(ctx context.Context, opts DaemonOptions) error {
+	if opts.SyncFunc == nil {
+		return errors.New(errors.ErrSync,
+			"Sync daemon has nothing to run",
+			"This is a bug - please report it.")
+	}
+	debounce := opts.Debounce
+	if debounce <= 0 {
+		debounce = DefaultDaemonDebounce
+	}
+
+	localDir := config.NormalizeLocalDir(opts.LocalDir)
+
+	if existing, err := ReadDaemonState(localDir); err == nil && existing != nil &&
+		existing.PID != os.Getpid() && util.ProcessAlive(existing.PID) {
+		return errors.New(errors.ErrSync,
+			fmt.Sprintf("A sync daemon is already running for this directory (pid %d)", existing.PID),
+			"Stop it first, or just let it keep syncing.")
+	}
+
+	watcher, err := NewWatcher(localDir, opts.Config)
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	d := &daemon{
+		state: DaemonState{
+			PID:       os.Getpid(),
+			Host:      opts.Host,
+			RemoteDir: opts.RemoteDir,
+			LocalDir:  localDir,
+			Dirty:     true,
+		},
+		kick: make(chan struct{}, 1),
+	}
+	if err := WriteDaemonState(&d.state); err != nil {
+		return err
+	}
+	defer RemoveDaemonState(localDir) //nolint:errcheck // Best-effort cleanup on exit
+
+	// Watch in the background; every change marks state dirty immediately,
+	// then pokes the sync loop.
+	watchErr := make(chan error, 1)
+	go func() {
+		watchErr <- watcher.Run(ctx, d.markDirty)
+	}()
+
+	// Initial sync brings the remote current before we start watching for deltas
+	d.syncOnce(opts)
+
+	retry := time.NewTicker(daemonRetryInterval)
+	defer retry.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-watchErr:
+			return err
+		case <-retry.C:
+			if d.isDirty() {
+				d.syncOnce(opts)
+			}
+		case <-d.kick:
+			// Wait for activity to settle so one save triggers one rsync
+			if !waitQuiet(ctx, d.kick, debounce) {
+				return nil
+			}
+			d.syncOnce(opts)
+		}
+	}
+}
+
+// daemon holds the mutable state shared between the watcher and sync loop.
+type daemon struct {
+	mu    gosync.Mutex
+	state DaemonState
+	gen   uint64
+	kick  chan struct{}
+}
+
+// markDirty records a local change and wakes the sync loop.
+func (d *daemon) markDirty() {
+	d.mu.Lock()
+	d.gen++
+	if !d.state.Dirty {
+		d.state.Dirty = true
+		_ = WriteDaemonState(&d.state)
+	}
+	d.mu.Unlock()
+
+	select {
+	case d.kick <- struct{}{}:
+	default:
+	}
+}
+
+func (d *daemon) isDirty() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.state.Dirty
+}
+
+// syncOnce runs one sync pass and marks state clean if nothing changed meanwhile.
+func (d *daemon) syncOnce(opts DaemonOptions) {
+	d.mu.Lock()
+	startGen := d.gen
+	d.mu.Unlock()
+
+	start := time.Now()
+	err := opts.SyncFunc()
+	duration := time.Since(start)
+
+	if err == nil {
+		d.mu.Lock()
+		if d.gen == startGen {
+			d.state.Dirty = false
+			d.state.LastSync = time.Now()
+			_ = WriteDaemonState(&d.state)
+		}
+		d.mu.Unlock()
+	}
+
+	if opts.OnSync != nil {
+		opts.OnSync(duration, err)
+	}
+}
+
+// waitQuiet blocks until no kick arrives for the debounce period.
+// Returns false if ctx is canceled first.
+func waitQuiet(ctx context.Context, kick <-chan struct{}, debounce time.Duration) bool {
+	timer := time.NewTimer(debounce)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-kick:
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(debounce)
+		case <-timer.C:
+			return true
+		}
+	}
+}
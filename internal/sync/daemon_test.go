@@ -0,0 +1,156 @@
+package sync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	gosync "sync"
+	"testing"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDaemonState_RoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	localDir := t.TempDir()
+
+	state, err := ReadDaemonState(localDir)
+	require.NoError(t, err)
+	assert.Nil(t, state, "no state before a daemon has run")
+
+	written := &DaemonState{
+		PID:       os.Getpid(),
+		Host:      "dev",
+		RemoteDir: "~/rr/project",
+		LocalDir:  localDir,
+		LastSync:  time.Now().Truncate(time.Second),
+	}
+	require.NoError(t, WriteDaemonState(written))
+
+	state, err = ReadDaemonState(localDir)
+	require.NoError(t, err)
+	require.NotNil(t, state)
+	assert.Equal(t, "dev", state.Host)
+	assert.True(t, state.LastSync.Equal(written.LastSync))
+
+	require.NoError(t, RemoveDaemonState(localDir))
+	require.NoError(t, RemoveDaemonState(localDir), "removing twice is fine")
+
+	state, err = ReadDaemonState(localDir)
+	require.NoError(t, err)
+	assert.Nil(t, state)
+}
+
+func TestDaemonState_IsCurrent(t *testing.T) {
+	base := DaemonState{
+		PID:       os.Getpid(),
+		Host:      "dev",
+		RemoteDir: "~/rr/project",
+		LastSync:  time.Now(),
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(s *DaemonState)
+		host   string
+		expect bool
+	}{
+		{"clean and running", func(s *DaemonState) {}, "dev", true},
+		{"dirty", func(s *DaemonState) { s.Dirty = true }, "dev", false},
+		{"never synced", func(s *DaemonState) { s.LastSync = time.Time{} }, "dev", false},
+		{"different host", func(s *DaemonState) {}, "other", false},
+		{"different remote dir", func(s *DaemonState) { s.RemoteDir = "~/elsewhere" }, "dev", false},
+		{"dead process", func(s *DaemonState) { s.PID = 0 }, "dev", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := base
+			tt.mutate(&s)
+			assert.Equal(t, tt.expect, s.IsCurrent(tt.host, "~/rr/project"))
+		})
+	}
+
+	var nilState *DaemonState
+	assert.False(t, nilState.IsCurrent("dev", "~/rr/project"))
+}
+
+func TestDaemonIsCurrent_NoState(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	assert.False(t, DaemonIsCurrent(t.TempDir(), "dev", "~/rr/project"))
+}
+
+func TestRunDaemon_SyncsOnChange(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	localDir := t.TempDir()
+
+	var mu gosync.Mutex
+	syncs := 0
+	synced := make(chan struct{}, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- RunDaemon(ctx, DaemonOptions{
+			LocalDir:  localDir,
+			Host:      "dev",
+			RemoteDir: "~/rr/project",
+			Config:    config.SyncConfig{Exclude: []string{".git/"}},
+			Debounce:  50 * time.Millisecond,
+			SyncFunc: func() error {
+				mu.Lock()
+				syncs++
+				mu.Unlock()
+				return nil
+			},
+			OnSync: func(time.Duration, error) { synced <- struct{}{} },
+		})
+	}()
+
+	waitSynced := func() {
+		select {
+		case <-synced:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for sync")
+		}
+	}
+
+	// Initial sync
+	waitSynced()
+	assert.True(t, DaemonIsCurrent(localDir, "dev", "~/rr/project"))
+
+	// A file save triggers another sync
+	require.NoError(t, os.WriteFile(filepath.Join(localDir, "main.go"), []byte("package main"), 0644))
+	waitSynced()
+	assert.True(t, DaemonIsCurrent(localDir, "dev", "~/rr/project"))
+
+	cancel()
+	require.NoError(t, <-done)
+
+	mu.Lock()
+	assert.GreaterOrEqual(t, syncs, 2)
+	mu.Unlock()
+
+	// State is cleaned up on exit
+	state, err := ReadDaemonState(localDir)
+	require.NoError(t, err)
+	assert.Nil(t, state)
+}
+
+func TestRunDaemon_RefusesSecondDaemon(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	localDir := t.TempDir()
+
+	// Pretend another live process (our parent) owns this directory
+	require.NoError(t, WriteDaemonState(&DaemonState{PID: os.Getppid(), LocalDir: localDir}))
+
+	err := RunDaemon(context.Background(), DaemonOptions{
+		LocalDir: localDir,
+		SyncFunc: func() error { return nil },
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already running")
+}
@@ -0,0 +1,104 @@
+package sync
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+)
+
+// lowDiskFree is how much free space a pull should leave on the local disk
+// before rr warns about it. Small disks warn at 5% free instead.
+const lowDiskFree = 1 << 30
+
+// diskSpace is what's free on a filesystem.
+type diskSpace struct {
+	Free        uint64 // Bytes available to this user
+	Total       uint64 // Bytes in all
+	FreeInodes  uint64
+	TotalInodes uint64 // 0 when the filesystem doesn't have a fixed number
+}
+
+// localDiskSpace returns what's free on the filesystem holding path.
+// Swappable for tests.
+var localDiskSpace = statDiskSpace
+
+// checkLocalSpace checks that what each pull destination is about to get,
+// as measured on the remote, fits on the local disk, in both bytes and
+// files. A pull that runs out of space partway leaves half-written
+// directories behind, so it's refused before anything is transferred.
+// Returns a warning for each destination the pull would leave nearly full.
+// Destinations whose free space can't be read aren't checked.
+func checkLocalSpace(items []config.PullItem, defaultDest string, sizes, files map[int]int64) ([]string, error) {
+	type need struct{ bytes, files int64 }
+	needs := make(map[string]*need)
+	var dests []string
+	for i, item := range items {
+		dest := item.Dest
+		if dest == "" {
+			dest = defaultDest
+		}
+		if dest == "" {
+			dest = "."
+		}
+		n, ok := needs[dest]
+		if !ok {
+			n = &need{}
+			needs[dest] = n
+			dests = append(dests, dest)
+		}
+		if sizes[i] > 0 {
+			n.bytes += sizes[i]
+		}
+		n.files += files[i]
+	}
+
+	var warnings []string
+	for _, dest := range dests {
+		n := needs[dest]
+		space, err := localDiskSpace(existingAncestor(dest))
+		if err != nil {
+			continue
+		}
+		if uint64(n.bytes) > space.Free {
+			return nil, errors.New(errors.ErrSync,
+				fmt.Sprintf("Pull to %s needs %s, but only %s is free on this machine", dest, config.FormatSize(n.bytes), config.FormatSize(int64(space.Free))),
+				"Nothing was pulled. Free up some space, or pull to another disk with dest.").
+				WithID(errors.IDSyncPullNoSpace)
+		}
+		if space.TotalInodes > 0 && uint64(n.files) > space.FreeInodes {
+			return nil, errors.New(errors.ErrSync,
+				fmt.Sprintf("Pull to %s is %d files, but this machine's disk only has room for %d more", dest, n.files, space.FreeInodes),
+				"Nothing was pulled. Delete some files (caches and node_modules hold a lot), or pull to another disk with dest.").
+				WithID(errors.IDSyncPullNoSpace)
+		}
+		left := space.Free - uint64(n.bytes)
+		low := uint64(lowDiskFree)
+		if small := space.Total / 20; small < low {
+			low = small
+		}
+		if left < low {
+			warnings = append(warnings, fmt.Sprintf("Pulling to %s will leave only %s free on this machine",
+				dest, config.FormatSize(int64(left))))
+		}
+	}
+	return warnings, nil
+}
+
+// existingAncestor returns path, or the nearest directory above it that
+// exists, since a pull creates its destination.
+func existingAncestor(path string) string {
+	path = filepath.Clean(path)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
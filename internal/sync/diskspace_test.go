@@ -0,0 +1,89 @@
+package sync
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withDiskSpace makes every local disk report space.
+func withDiskSpace(t *testing.T, space diskSpace) *[]string {
+	t.Helper()
+	orig := localDiskSpace
+	t.Cleanup(func() { localDiskSpace = orig })
+	var paths []string
+	localDiskSpace = func(path string) (diskSpace, error) {
+		paths = append(paths, path)
+		return space, nil
+	}
+	return &paths
+}
+
+func TestCheckLocalSpace(t *testing.T) {
+	const gb = 1 << 30
+	items := []config.PullItem{
+		{Src: "coverage/"},
+		{Src: "checkpoints/", Dest: "models"},
+		{Src: "missing"},
+	}
+	sizes := map[int]int64{0: 2 * gb, 1: 10 * gb, 2: -1}
+	files := map[int]int64{0: 3000, 1: 12}
+
+	t.Run("fits", func(t *testing.T) {
+		withDiskSpace(t, diskSpace{Free: 100 * gb, Total: 500 * gb, FreeInodes: 1e6, TotalInodes: 1e7})
+		warnings, err := checkLocalSpace(items, "out", sizes, files)
+		require.NoError(t, err)
+		assert.Empty(t, warnings)
+	})
+
+	t.Run("too big", func(t *testing.T) {
+		withDiskSpace(t, diskSpace{Free: 5 * gb, Total: 500 * gb, FreeInodes: 1e6, TotalInodes: 1e7})
+		_, err := checkLocalSpace(items, "out", sizes, files)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Pull to models needs 10.0 GB, but only 5.0 GB is free")
+		assert.Equal(t, errors.IDSyncPullNoSpace, errors.IDOf(err))
+	})
+
+	t.Run("too many files", func(t *testing.T) {
+		withDiskSpace(t, diskSpace{Free: 100 * gb, Total: 500 * gb, FreeInodes: 100, TotalInodes: 1e7})
+		_, err := checkLocalSpace(items, "out", sizes, files)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Pull to out is 3000 files, but this machine's disk only has room for 100 more")
+	})
+
+	t.Run("no fixed inodes", func(t *testing.T) {
+		withDiskSpace(t, diskSpace{Free: 100 * gb, Total: 500 * gb})
+		_, err := checkLocalSpace(items, "out", sizes, files)
+		assert.NoError(t, err)
+	})
+
+	t.Run("nearly full", func(t *testing.T) {
+		withDiskSpace(t, diskSpace{Free: 10*gb + gb/2, Total: 500 * gb, FreeInodes: 1e6, TotalInodes: 1e7})
+		warnings, err := checkLocalSpace(items, "out", sizes, files)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Pulling to models will leave only 512.0 MB free on this machine"}, warnings)
+	})
+}
+
+func TestCheckLocalSpace_NearestExistingDir(t *testing.T) {
+	dir := t.TempDir()
+	paths := withDiskSpace(t, diskSpace{Free: 1 << 30, Total: 1 << 40})
+
+	_, err := checkLocalSpace([]config.PullItem{{Src: "a"}}, filepath.Join(dir, "not", "yet"), map[int]int64{0: 1}, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{dir}, *paths)
+}
+
+func TestStatDiskSpace(t *testing.T) {
+	space, err := localDiskSpace(t.TempDir())
+	if err != nil {
+		t.Skip("disk space isn't available here")
+	}
+	assert.Positive(t, space.Total)
+	assert.LessOrEqual(t, space.Free, space.Total)
+}
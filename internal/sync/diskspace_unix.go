@@ -0,0 +1,20 @@
+//go:build !windows
+
+package sync
+
+import "syscall"
+
+// statDiskSpace reads what's free on the filesystem holding path.
+func statDiskSpace(path string) (diskSpace, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return diskSpace{}, err
+	}
+	bsize := uint64(st.Bsize) //nolint:unconvert // int64 on Linux, uint32 on macOS
+	return diskSpace{
+		Free:        st.Bavail * bsize,
+		Total:       st.Blocks * bsize,
+		FreeInodes:  st.Ffree,
+		TotalInodes: st.Files,
+	}, nil
+}
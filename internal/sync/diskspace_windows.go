@@ -0,0 +1,10 @@
+//go:build windows
+
+package sync
+
+import "fmt"
+
+// statDiskSpace isn't supported on Windows, so pulls there aren't checked.
+func statDiskSpace(string) (diskSpace, error) {
+	return diskSpace{}, fmt.Errorf("disk space isn't checked on Windows")
+}
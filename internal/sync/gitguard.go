@@ -0,0 +1,113 @@
+package sync
+
+import (
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/rileyhilliard/rr/internal/util"
+)
+
+// maxListedEdits caps how many changed paths the guard error names.
+const maxListedEdits = 5
+
+// gitState is a checkout's HEAD and its 'git status --porcelain' lines.
+type gitState struct {
+	head    string
+	changes []string
+}
+
+// CheckRemoteEdits refuses to sync into a host dir that is a real git
+// checkout with uncommitted changes, since the sync would overwrite them.
+//
+// Only checkouts rr didn't create are guarded: when the sync sends .git
+// itself the remote repo is rr's copy. Changes that match the local
+// checkout's (same HEAD, same status) are the ones an earlier sync made, so
+// they don't count. A host without git, or a dir that isn't a checkout, passes.
+func CheckRemoteEdits(conn *host.Connection, localDir string, cfg config.SyncConfig) error {
+	if conn == nil || conn.IsLocal || conn.Client == nil || !excludesGitDir(cfg) {
+		return nil
+	}
+
+	dir := config.ExpandRemote(conn.Host.Dir)
+	cmd := fmt.Sprintf("cd %s 2>/dev/null && [ -e .git ] && git rev-parse HEAD 2>/dev/null && git status --porcelain 2>/dev/null",
+		util.ShellQuotePreserveTilde(dir))
+	stdout, _, exitCode, err := conn.Client.Exec(cmd)
+	if err != nil || exitCode != 0 {
+		return nil // Not a checkout, or no git on the host
+	}
+
+	remote := parseGitState(string(stdout))
+	if len(remote.changes) == 0 {
+		return nil
+	}
+	if remote.matches(localGitState(localDir)) {
+		return nil
+	}
+
+	listed := remote.changes
+	if len(listed) > maxListedEdits {
+		listed = append(slices.Clone(listed[:maxListedEdits]), fmt.Sprintf("and %d more", len(remote.changes)-maxListedEdits))
+	}
+	return errors.New(errors.ErrSync,
+		fmt.Sprintf("%s on %s is a git checkout with %d uncommitted %s", dir, conn.Name,
+			len(remote.changes), util.Pluralize(len(remote.changes), "change", "changes")),
+		fmt.Sprintf("Syncing would overwrite them: %s. Commit or stash them on the host, point the host's dir at a directory rr owns (e.g., ~/rr/${PROJECT}), or pass --force to sync anyway.",
+			strings.Join(listed, ", "))).
+		WithID(errors.IDSyncRemoteEdits)
+}
+
+// excludesGitDir reports whether the sync leaves .git behind, which makes
+// any .git in the host's dir one rr didn't put there.
+func excludesGitDir(cfg config.SyncConfig) bool {
+	return slices.ContainsFunc(cfg.Exclude, func(pattern string) bool {
+		return strings.Trim(pattern, "/") == ".git"
+	})
+}
+
+// parseGitState parses 'git rev-parse HEAD' output followed by
+// 'git status --porcelain' output. Rsync's partial files are left out.
+func parseGitState(output string) gitState {
+	var state gitState
+	for i, line := range strings.Split(output, "\n") {
+		if i == 0 {
+			state.head = strings.TrimSpace(line)
+			continue
+		}
+		if strings.TrimSpace(line) == "" || strings.Contains(line, PartialDir) {
+			continue
+		}
+		state.changes = append(state.changes, strings.TrimRight(line, "\r"))
+	}
+	return state
+}
+
+// localGitState returns the git state of localDir, or an empty state if it
+// isn't a checkout.
+func localGitState(localDir string) gitState {
+	head, err := exec.Command("git", "-C", localDir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return gitState{}
+	}
+	status, err := exec.Command("git", "-C", localDir, "status", "--porcelain").Output()
+	if err != nil {
+		return gitState{}
+	}
+	return parseGitState(string(head) + string(status))
+}
+
+// matches reports whether two checkouts are at the same commit with the same
+// changes.
+func (s gitState) matches(other gitState) bool {
+	if s.head == "" || s.head != other.head || len(s.changes) != len(other.changes) {
+		return false
+	}
+	a, b := slices.Clone(s.changes), slices.Clone(other.changes)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
@@ -0,0 +1,109 @@
+package sync
+
+import (
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/host"
+	sshtesting "github.com/rileyhilliard/rr/pkg/sshutil/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testHead = "3f2c9a1e8b7d6c5f4e3d2c1b0a9f8e7d6c5b4a39"
+
+func TestCheckRemoteEdits(t *testing.T) {
+	excludeGit := config.SyncConfig{Exclude: []string{".git/", "node_modules/"}}
+
+	tests := []struct {
+		name    string
+		cfg     config.SyncConfig
+		resp    sshtesting.CommandResponse
+		wantErr string
+	}{
+		{
+			name:    "dirty checkout",
+			cfg:     excludeGit,
+			resp:    sshtesting.CommandResponse{Stdout: []byte(testHead + "\n M src/main.go\n?? notes.txt\n")},
+			wantErr: "~/code/app on box is a git checkout with 2 uncommitted changes",
+		},
+		{
+			name: "clean checkout",
+			cfg:  excludeGit,
+			resp: sshtesting.CommandResponse{Stdout: []byte(testHead + "\n")},
+		},
+		{
+			name: "not a checkout",
+			cfg:  excludeGit,
+			resp: sshtesting.CommandResponse{ExitCode: 1},
+		},
+		{
+			name: "only rsync partial files",
+			cfg:  excludeGit,
+			resp: sshtesting.CommandResponse{Stdout: []byte(testHead + "\n?? .rr-partial/\n")},
+		},
+		{
+			name: "sync sends .git itself",
+			cfg:  config.SyncConfig{Exclude: []string{"node_modules/"}},
+			resp: sshtesting.CommandResponse{Stdout: []byte(testHead + "\n M src/main.go\n")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := sshtesting.NewMockClient("box")
+			client.SetCommandResponse("git status --porcelain", tt.resp)
+			conn := &host.Connection{Name: "box", Client: client, Host: config.Host{Dir: "~/code/app"}}
+
+			err := CheckRemoteEdits(conn, t.TempDir(), tt.cfg)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+			assert.Contains(t, err.Error(), "M src/main.go")
+			assert.Contains(t, err.Error(), "--force")
+		})
+	}
+}
+
+func TestCheckRemoteEdits_LocalConnection(t *testing.T) {
+	assert.NoError(t, CheckRemoteEdits(&host.Connection{IsLocal: true}, t.TempDir(), config.SyncConfig{Exclude: []string{".git"}}))
+	assert.NoError(t, CheckRemoteEdits(nil, t.TempDir(), config.SyncConfig{Exclude: []string{".git"}}))
+}
+
+func TestExcludesGitDir(t *testing.T) {
+	for _, pattern := range []string{".git", ".git/", "/.git", "/.git/"} {
+		assert.True(t, excludesGitDir(config.SyncConfig{Exclude: []string{pattern}}), pattern)
+	}
+	assert.False(t, excludesGitDir(config.SyncConfig{Exclude: []string{".github/", "*.git"}}))
+	assert.True(t, excludesGitDir(config.DefaultConfig().Sync), "the default excludes .git")
+}
+
+func TestGitStateMatches(t *testing.T) {
+	remote := parseGitState(testHead + "\n M a.go\n?? b.go\n")
+	assert.Equal(t, testHead, remote.head)
+	assert.Equal(t, []string{" M a.go", "?? b.go"}, remote.changes)
+
+	assert.True(t, remote.matches(parseGitState(testHead+"\n?? b.go\n M a.go\n")), "order doesn't matter")
+	assert.False(t, remote.matches(parseGitState(testHead+"\n M a.go\n")), "remote has an extra change")
+	assert.False(t, remote.matches(parseGitState("0000000000000000000000000000000000000000\n M a.go\n?? b.go\n")), "different commit")
+	assert.False(t, remote.matches(gitState{}), "local isn't a checkout")
+}
+
+func TestCheckRemoteEdits_ListsFirstFew(t *testing.T) {
+	client := sshtesting.NewMockClient("box")
+	client.SetCommandResponse("git status --porcelain", sshtesting.CommandResponse{
+		Stdout: []byte(testHead + "\n M 1\n M 2\n M 3\n M 4\n M 5\n M 6\n M 7\n"),
+	})
+	conn := &host.Connection{Name: "box", Client: client, Host: config.Host{Dir: "/srv/app"}}
+
+	err := CheckRemoteEdits(conn, t.TempDir(), config.SyncConfig{Exclude: []string{".git/"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "7 uncommitted changes")
+	assert.Contains(t, err.Error(), "M 5, and 2 more")
+	assert.NotContains(t, err.Error(), "M 6")
+	assert.Equal(t, errors.IDSyncRemoteEdits, errors.IDOf(err))
+}
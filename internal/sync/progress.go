@@ -13,6 +13,7 @@ type Progress struct {
 	Speed            string // Transfer speed (e.g., "1.23MB/s")
 	TimeRemaining    string // Estimated time remaining (e.g., "0:01:23")
 	FileCount        int    // Number of files transferred (when available)
+	FilesToCheck     int    // Files left to check (when available)
 	TotalFiles       int    // Total files to transfer (when available)
 }
 
@@ -62,6 +63,11 @@ func ParseProgress(line string) *Progress {
 
 	// Parse total files from to-chk info
 	// Format: to-chk=remaining/total, so total files = total
+	if len(matches) > 6 && matches[6] != "" {
+		if remaining, err := strconv.Atoi(matches[6]); err == nil {
+			p.FilesToCheck = remaining
+		}
+	}
 	if len(matches) > 7 && matches[7] != "" {
 		if total, err := strconv.Atoi(matches[7]); err == nil {
 			p.TotalFiles = total
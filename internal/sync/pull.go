@@ -7,12 +7,17 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/rileyhilliard/rr/internal/config"
 	"github.com/rileyhilliard/rr/internal/errors"
 	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/rileyhilliard/rr/internal/logger"
+	"github.com/rileyhilliard/rr/internal/util"
+	"github.com/rileyhilliard/rr/pkg/sshutil"
 )
 
 // PullOptions configures a pull operation.
@@ -26,6 +31,10 @@ type PullOptions struct {
 
 	// Flags are extra rsync flags to pass.
 	Flags []string
+
+	// Warn, if set, is called with each warning about the pull, like one
+	// that will leave the local disk nearly full.
+	Warn func(msg string)
 }
 
 // Pull downloads files from the remote host to the local machine using rsync.
@@ -58,6 +67,30 @@ func Pull(conn *host.Connection, opts PullOptions, progress io.Writer) error {
 	if err != nil {
 		return err
 	}
+	rsync := DetectRsync(rsyncPath)
+
+	// Measure what each item matches before transferring anything, and
+	// make sure it fits here
+	if conn.Client != nil {
+		warn := func(msg string) {
+			if opts.Warn != nil {
+				opts.Warn(msg)
+			}
+		}
+		sizes, files, err := checkPullItems(conn.Client, config.ExpandRemote(conn.Host.Dir), conn.Name, opts.Patterns, warn)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(opts.Flags, "--dry-run") {
+			warnings, err := checkLocalSpace(opts.Patterns, opts.DefaultDest, sizes, files)
+			if err != nil {
+				return err
+			}
+			for _, w := range warnings {
+				warn(w)
+			}
+		}
+	}
 
 	// Ensure the SSH control socket directory exists for ControlMaster
 	if err := os.MkdirAll(controlSocketDir, 0700); err != nil {
@@ -79,7 +112,7 @@ func Pull(conn *host.Connection, opts PullOptions, progress io.Writer) error {
 	// Execute one rsync per destination group
 	for _, dest := range dests {
 		patterns := groups[dest]
-		args, err := BuildPullArgs(conn, patterns, dest, opts.Flags)
+		args, err := buildPullArgs(rsync, conn, patterns, dest, opts.Flags)
 		if err != nil {
 			return err
 		}
@@ -92,6 +125,103 @@ func Pull(conn *host.Connection, opts PullOptions, progress io.Writer) error {
 	return nil
 }
 
+// checkPullItems measures each pull item on the remote with du before
+// anything is transferred. It fails if a pattern matches nothing, so a typo
+// or a command that died early is reported clearly, and if an item is
+// bigger than its max_size, so a stray glob can't drag gigabytes back.
+// Returns the size and file count of each item, by index. If the check
+// itself can't run, the pull goes ahead and rsync reports any real problem,
+// but warn is told when that leaves a max_size unchecked.
+func checkPullItems(client sshutil.SSHClient, remoteDir, hostName string, items []config.PullItem, warn func(msg string)) (sizes, files map[int]int64, err error) {
+	stdout, stderr, exitCode, err := client.Exec(buildPullCheckCmd(remoteDir, items))
+	if err != nil || exitCode != 0 {
+		if err == nil {
+			err = fmt.Errorf("exit code %d: %s", exitCode, strings.TrimSpace(string(stderr)))
+		}
+		logger.Verbosef(logger.LevelPhases, "pull", "couldn't measure pull items on %s: %v", hostName, err)
+		if slices.ContainsFunc(items, func(item config.PullItem) bool { return item.MaxSize != "" }) {
+			warn(fmt.Sprintf("Couldn't measure what to pull from %s, so max_size limits weren't checked", hostName))
+		}
+		return nil, nil, nil
+	}
+	sizes, files = parsePullCheckOutput(stdout)
+
+	for i, item := range items {
+		size, ok := sizes[i]
+		if !ok {
+			continue
+		}
+		if size < 0 {
+			return nil, nil, errors.New(errors.ErrSync,
+				fmt.Sprintf("Nothing on %s matches pull pattern '%s'", hostName, item.Src),
+				fmt.Sprintf("Pull paths are relative to %s. Check the command produced it, or fix the pattern.", remoteDir)).
+				WithID(errors.IDSyncPullNoMatch)
+		}
+		if item.MaxSize == "" {
+			continue
+		}
+		limit, err := config.ParseSize(item.MaxSize)
+		if err != nil {
+			continue // Caught by config validation
+		}
+		if size > limit {
+			return nil, nil, errors.New(errors.ErrSync,
+				fmt.Sprintf("Pull '%s' is %s on %s, over its max_size of %s", item.Src, config.FormatSize(size), hostName, item.MaxSize),
+				"Nothing was pulled. Narrow the pattern, or raise max_size if you really want all of it.").
+				WithID(errors.IDSyncPullTooLarge)
+		}
+	}
+
+	return sizes, files, nil
+}
+
+// buildPullCheckCmd builds a remote script that prints one line per pull
+// item: "<index> <kilobytes> <files>", or "<index> none" if the pattern
+// matches nothing. Patterns are left unquoted so the remote shell expands globs,
+// the same way rsync's remote side does.
+func buildPullCheckCmd(remoteDir string, items []config.PullItem) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "cd %s || exit 1\n", util.ShellQuotePreserveTilde(remoteDir))
+	for i, item := range items {
+		fmt.Fprintf(&b, "set -- %s; if [ -e \"$1\" ] || [ -L \"$1\" ]; then echo \"%d $(du -sk -- \"$@\" | awk '{s+=$1} END {print s}') $(find \"$@\" 2>/dev/null | wc -l)\"; else echo \"%d none\"; fi\n",
+			item.Src, i, i)
+	}
+	return b.String()
+}
+
+// parsePullCheckOutput parses buildPullCheckCmd output into sizes in bytes
+// and file counts by item index. Items that matched nothing get a size of
+// -1. Lines that don't parse are skipped, leaving that item unchecked.
+func parsePullCheckOutput(output []byte) (sizes, files map[int]int64) {
+	sizes = make(map[int]int64)
+	files = make(map[int]int64)
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 && len(fields) != 3 {
+			continue
+		}
+		index, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		if fields[1] == "none" {
+			sizes[index] = -1
+			continue
+		}
+		kb, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		sizes[index] = kb * 1024
+		if len(fields) == 3 {
+			if n, err := strconv.ParseInt(fields[2], 10, 64); err == nil {
+				files[index] = n
+			}
+		}
+	}
+	return sizes, files
+}
+
 // groupByDest groups pull items by their destination directory.
 func groupByDest(items []config.PullItem, defaultDest string) map[string][]string {
 	groups := make(map[string][]string)
@@ -110,9 +240,16 @@ func groupByDest(items []config.PullItem, defaultDest string) map[string][]strin
 	return groups
 }
 
-// BuildPullArgs constructs the rsync command arguments for pulling files.
+// BuildPullArgs constructs the rsync command arguments for pulling files
+// with a current GNU rsync.
 // Exported for testing command construction without running rsync.
 func BuildPullArgs(conn *host.Connection, patterns []string, localDest string, extraFlags []string) ([]string, error) {
+	return buildPullArgs(ModernRsync, conn, patterns, localDest, extraFlags)
+}
+
+// buildPullArgs constructs the rsync command arguments for pulling files,
+// using only flags the given rsync supports.
+func buildPullArgs(rsync Rsync, conn *host.Connection, patterns []string, localDest string, extraFlags []string) ([]string, error) {
 	if conn == nil {
 		return nil, errors.New(errors.ErrSync,
 			"No connection provided",
@@ -153,10 +290,13 @@ func BuildPullArgs(conn *host.Connection, patterns []string, localDest string, e
 
 	// Use SSH with ControlMaster for connection reuse and user's SSH config
 	// for ProxyCommand, IdentityFile, and other host-specific settings.
-	args = append(args, "-e", buildSSHCmd())
+	args = append(args, "-e", buildSSHCmd(conn.Host))
+	args = append(args, rsyncPathArgs(conn)...)
 
 	// Add progress info flag for parsing
-	args = append(args, "--info=progress2")
+	if rsync.Supports(FeatureProgress2) {
+		args = append(args, "--info=progress2")
+	}
 
 	// Add extra flags
 	args = append(args, extraFlags...)
@@ -175,7 +315,7 @@ func BuildPullArgs(conn *host.Connection, patterns []string, localDest string, e
 
 // runRsyncPull executes rsync with the given arguments and handles output.
 func runRsyncPull(rsyncPath string, args []string, hostName string, progress io.Writer) error {
-	cmd := exec.Command(rsyncPath, args...)
+	cmd := rsyncCommand(rsyncPath, args...)
 
 	// Set up progress output if provided
 	if progress != nil {
@@ -10,6 +10,7 @@ import (
 
 	"github.com/rileyhilliard/rr/internal/config"
 	"github.com/rileyhilliard/rr/internal/host"
+	sshtesting "github.com/rileyhilliard/rr/pkg/sshutil/testing"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -564,3 +565,95 @@ func TestPull_WithFlags(t *testing.T) {
 		assert.NotContains(t, err.Error(), "No connection provided")
 	}
 }
+
+func TestBuildPullCheckCmd(t *testing.T) {
+	cmd := buildPullCheckCmd("~/projects/myapp", []config.PullItem{
+		{Src: "dist/*.whl"},
+		{Src: "coverage.xml"},
+	})
+
+	assert.Contains(t, cmd, "cd ~/'projects/myapp' || exit 1")
+	// Patterns stay unquoted so the remote shell expands globs
+	assert.Contains(t, cmd, "set -- dist/*.whl;")
+	assert.Contains(t, cmd, `echo "0 none"`)
+	assert.Contains(t, cmd, "set -- coverage.xml;")
+	assert.Contains(t, cmd, `echo "1 none"`)
+}
+
+func TestParsePullCheckOutput(t *testing.T) {
+	sizes, files := parsePullCheckOutput([]byte("0 304\n1 none\ngarbage\n2 \n3 12\n4 8       17\n"))
+
+	assert.Equal(t, map[int]int64{0: 304 * 1024, 1: -1, 3: 12 * 1024, 4: 8 * 1024}, sizes)
+	assert.Equal(t, map[int]int64{4: 17}, files)
+}
+
+func TestCheckPullItems(t *testing.T) {
+	items := []config.PullItem{
+		{Src: "dist/*.whl", MaxSize: "1MB"},
+		{Src: "coverage.xml"},
+	}
+
+	tests := []struct {
+		name        string
+		resp        sshtesting.CommandResponse
+		errContains string
+		warning     string
+	}{
+		{
+			name: "within limits",
+			resp: sshtesting.CommandResponse{Stdout: []byte("0 512\n1 4\n")},
+		},
+		{
+			name:        "over max_size",
+			resp:        sshtesting.CommandResponse{Stdout: []byte("0 2048\n1 4\n")},
+			errContains: "Pull 'dist/*.whl' is 2.0 MB on test-host, over its max_size of 1MB",
+		},
+		{
+			name:        "no match",
+			resp:        sshtesting.CommandResponse{Stdout: []byte("0 512\n1 none\n")},
+			errContains: "Nothing on test-host matches pull pattern 'coverage.xml'",
+		},
+		{
+			name:    "check failed to run",
+			resp:    sshtesting.CommandResponse{ExitCode: 127},
+			warning: "Couldn't measure what to pull from test-host, so max_size limits weren't checked",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := sshtesting.NewMockClient("test-host")
+			mock.SetCommandResponse(`^cd `, tt.resp)
+
+			var warnings []string
+			_, _, err := checkPullItems(mock, "~/projects/myapp", "test-host", items, func(msg string) {
+				warnings = append(warnings, msg)
+			})
+			if tt.warning != "" {
+				assert.Equal(t, []string{tt.warning}, warnings)
+			} else {
+				assert.Empty(t, warnings)
+			}
+			if tt.errContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
+func TestCheckPullItems_FailedCheckWithoutMaxSizeIsQuiet(t *testing.T) {
+	mock := sshtesting.NewMockClient("test-host")
+	mock.SetCommandResponse(`^cd `, sshtesting.CommandResponse{ExitCode: 1})
+
+	var warnings []string
+	sizes, _, err := checkPullItems(mock, "~/projects/myapp", "test-host", []config.PullItem{{Src: "coverage.xml"}}, func(msg string) {
+		warnings = append(warnings, msg)
+	})
+
+	assert.NoError(t, err)
+	assert.Nil(t, sizes)
+	assert.Empty(t, warnings)
+}
@@ -0,0 +1,106 @@
+package sync
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+)
+
+// PushConfig narrows a sync to the given paths, for tasks that build locally
+// and push only what they built (task.push). Everything else on the remote
+// is left alone: rsync's --delete skips excluded paths, so it only removes
+// stale files inside the pushed ones.
+//
+// sync.exclude and respect_gitignore are dropped, since build outputs are
+// usually ignored, and so are sharding and lockfile invalidations, which are
+// about syncing the whole project. Preserve rules, flags and prescan still
+// apply, except --delete-excluded.
+func PushConfig(cfg config.SyncConfig, paths []string) config.SyncConfig {
+	return config.SyncConfig{
+		Preserve: cfg.Preserve,
+		Flags:    narrowedFlags(paths, cfg.Flags),
+		Prescan:  cfg.Prescan,
+	}
+}
+
+// PathsConfig narrows a sync to the given paths, for 'rr sync <path>...'
+// after touching one corner of a large project. Unlike PushConfig it keeps
+// sync.exclude and respect_gitignore, so ignored files inside the paths still
+// stay local. Sharding and lockfile invalidations are dropped, since they're
+// about syncing the whole project.
+func PathsConfig(cfg config.SyncConfig, paths []string) config.SyncConfig {
+	cfg.Flags = narrowedFlags(paths, cfg.Flags)
+	cfg.Parallel = 0
+	cfg.Invalidations = nil
+	return cfg
+}
+
+// narrowedFlags returns the rsync flags for a sync narrowed to paths: the
+// filters from pushFilters, then the configured flags. --delete-excluded is
+// dropped, since with those filters it would delete everything on the remote
+// outside paths.
+func narrowedFlags(paths, flags []string) []string {
+	return append(pushFilters(paths), slices.DeleteFunc(slices.Clone(flags), func(f string) bool {
+		return f == "--delete-excluded"
+	})...)
+}
+
+// pushFilters returns the filter rules that limit a transfer to paths, which
+// can be files or directories. The excludes are anchored and one level deep,
+// so nothing inside a pushed directory matches them, and no "dir/***"
+// patterns are needed (openrsync lacks them). For "build/out" that's:
+//
+//	--include=/build/ --include=/build/out --exclude=/build/* --exclude=/*
+func pushFilters(paths []string) []string {
+	var parents []string
+	seen := map[string]bool{}
+	clean := make([]string, 0, len(paths))
+	for _, p := range paths {
+		p = cleanPushPath(p)
+		clean = append(clean, p)
+		for d := path.Dir(p); d != "."; d = path.Dir(d) {
+			if !seen[d] {
+				seen[d] = true
+				parents = append(parents, d)
+			}
+		}
+	}
+	sort.Strings(parents)
+
+	filters := make([]string, 0, 2*len(parents)+len(clean)+1)
+	for _, d := range parents {
+		filters = append(filters, "--include=/"+d+"/")
+	}
+	for _, p := range clean {
+		filters = append(filters, "--include=/"+p)
+	}
+	for i := len(parents) - 1; i >= 0; i-- {
+		filters = append(filters, "--exclude=/"+parents[i]+"/*")
+	}
+	return append(filters, "--exclude=/*")
+}
+
+// cleanPushPath normalizes a push path to the form rsync filters use:
+// relative to the project root, without "./" or a trailing slash.
+func cleanPushPath(p string) string {
+	return strings.TrimPrefix(path.Clean(filepath.ToSlash(strings.TrimSpace(p))), "/")
+}
+
+// CheckPushPaths returns an error naming the first push path that doesn't
+// exist under localDir, which usually means the build didn't produce it.
+func CheckPushPaths(localDir string, paths []string) error {
+	for _, p := range paths {
+		if _, err := os.Stat(filepath.Join(localDir, filepath.FromSlash(cleanPushPath(p)))); err != nil {
+			return errors.New(errors.ErrSync,
+				"Nothing to push at '"+p+"'",
+				"Check that the task's build command creates it, or fix the path in the task's push list.")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPushFilters(t *testing.T) {
+	tests := []struct {
+		name  string
+		paths []string
+		want  []string
+	}{
+		{
+			name:  "top-level directory",
+			paths: []string{"dist/"},
+			want:  []string{"--include=/dist", "--exclude=/*"},
+		},
+		{
+			name:  "nested paths share parents",
+			paths: []string{"./build/out/", "build/bin/server", "config.yaml"},
+			want: []string{
+				"--include=/build/", "--include=/build/bin/",
+				"--include=/build/out", "--include=/build/bin/server", "--include=/config.yaml",
+				"--exclude=/build/bin/*", "--exclude=/build/*",
+				"--exclude=/*",
+			},
+		},
+		{
+			name:  "deep path",
+			paths: []string{"target/release/app"},
+			want: []string{
+				"--include=/target/", "--include=/target/release/",
+				"--include=/target/release/app",
+				"--exclude=/target/release/*", "--exclude=/target/*",
+				"--exclude=/*",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, pushFilters(tt.paths))
+		})
+	}
+}
+
+func TestPushConfig(t *testing.T) {
+	cfg := config.SyncConfig{
+		RespectGitignore: true,
+		Exclude:          []string{"dist/", ".git/"},
+		Preserve:         []string{".venv/"},
+		Flags:            []string{"--checksum"},
+		Parallel:         4,
+		Invalidations:    []config.LockfileInvalidation{{Lockfile: "uv.lock", Dirs: []string{".venv"}}},
+	}
+
+	got := PushConfig(cfg, []string{"dist/"})
+
+	assert.False(t, got.RespectGitignore, "build outputs are usually gitignored")
+	assert.Empty(t, got.Exclude)
+	assert.Zero(t, got.Parallel)
+	assert.Empty(t, got.Invalidations)
+	assert.Equal(t, []string{".venv/"}, got.Preserve)
+	assert.Equal(t, []string{"--include=/dist", "--exclude=/*", "--checksum"}, got.Flags)
+	assert.Equal(t, []string{"--checksum"}, cfg.Flags, "the project config is left alone")
+
+	conn := &host.Connection{Name: "m1", Alias: "m1", Host: config.Host{Dir: "~/app"}}
+	args, err := BuildArgs(conn, "/home/user/app", got)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/home/user/app/", "m1:~/app/"}, args[len(args)-2:])
+	assert.Contains(t, args, "--include=/dist")
+	assert.Contains(t, args, "--delete")
+}
+
+func TestPushConfig_DropsDeleteExcluded(t *testing.T) {
+	cfg := config.SyncConfig{Flags: []string{"--checksum", "--delete-excluded"}}
+
+	got := PushConfig(cfg, []string{"dist/"})
+
+	conn := &host.Connection{Name: "m1", Alias: "m1", Host: config.Host{Dir: "~/app"}}
+	args, err := BuildArgs(conn, "/home/user/app", got)
+	require.NoError(t, err)
+	assert.NotContains(t, args, "--delete-excluded", "it would delete everything but dist/ on the remote")
+	assert.Contains(t, args, "--checksum")
+}
+
+func TestPathsConfig(t *testing.T) {
+	cfg := config.SyncConfig{
+		RespectGitignore: true,
+		Exclude:          []string{"node_modules/", ".git/"},
+		Preserve:         []string{".venv/"},
+		Flags:            []string{"--checksum"},
+		Parallel:         4,
+		Invalidations:    []config.LockfileInvalidation{{Lockfile: "uv.lock", Dirs: []string{".venv"}}},
+	}
+
+	got := PathsConfig(cfg, []string{"web/src", "assets/"})
+
+	assert.True(t, got.RespectGitignore)
+	assert.Equal(t, cfg.Exclude, got.Exclude)
+	assert.Equal(t, cfg.Preserve, got.Preserve)
+	assert.Zero(t, got.Parallel)
+	assert.Empty(t, got.Invalidations)
+	assert.Equal(t, []string{"--checksum"}, cfg.Flags, "the project config is left alone")
+
+	conn := &host.Connection{Name: "m1", Alias: "m1", Host: config.Host{Dir: "~/app"}}
+	args, err := BuildArgs(conn, "/home/user/app", got)
+	require.NoError(t, err)
+
+	// Excludes come first so they still win inside the synced paths
+	exclude := indexOf(args, "--exclude=node_modules/")
+	include := indexOf(args, "--include=/web/src")
+	require.GreaterOrEqual(t, exclude, 0)
+	require.GreaterOrEqual(t, include, 0)
+	assert.Less(t, exclude, include)
+	assert.Less(t, indexOf(args, "--filter=:- .gitignore"), include)
+	assert.Contains(t, args, "--include=/assets")
+	assert.Contains(t, args, "--exclude=/*")
+}
+
+func TestPathsConfig_DropsDeleteExcluded(t *testing.T) {
+	cfg := config.SyncConfig{Flags: []string{"--delete-excluded", "--checksum"}}
+
+	got := PathsConfig(cfg, []string{"web/src"})
+
+	conn := &host.Connection{Name: "m1", Alias: "m1", Host: config.Host{Dir: "~/app"}}
+	args, err := BuildArgs(conn, "/home/user/app", got)
+	require.NoError(t, err)
+	assert.NotContains(t, args, "--delete-excluded", "it would delete everything outside web/src")
+	assert.Contains(t, args, "--checksum")
+	assert.Equal(t, []string{"--delete-excluded", "--checksum"}, cfg.Flags, "the project config is left alone")
+}
+
+func TestCheckPushPaths(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "dist"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.jar"), nil, 0o644))
+
+	assert.NoError(t, CheckPushPaths(dir, []string{"dist/", "./app.jar"}))
+
+	err := CheckPushPaths(dir, []string{"dist/", "target/"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Nothing to push at 'target/'")
+}
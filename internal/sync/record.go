@@ -0,0 +1,214 @@
+package sync
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/util"
+)
+
+// PartialDir is where rsync keeps partially transferred files on the remote,
+// relative to each file's directory. A relative --partial-dir is excluded
+// from the transfer and protected from --delete by rsync itself, so an
+// interrupted sync leaves its progress in place for the next one to resume.
+const PartialDir = ".rr-partial"
+
+// syncRecordDir is the directory under ~/.rr/ that holds sync records.
+const syncRecordDir = "sync"
+
+// SyncRecord tracks the most recent sync of a local directory to a host. It's
+// written to ~/.rr/sync/ when a sync starts and again when it finishes, so a
+// sync that was cut short shows up as started after it last finished.
+type SyncRecord struct {
+	Host     string    `json:"host"`
+	LocalDir string    `json:"local_dir"`
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished,omitempty"`
+}
+
+// Interrupted reports whether the last sync started but never finished
+// (Ctrl+C, dropped connection, rsync error).
+func (r *SyncRecord) Interrupted() bool {
+	return r != nil && !r.Started.IsZero() && r.Finished.Before(r.Started)
+}
+
+// syncRecordPath returns the record file path for a local directory and
+// host. Like daemon state, the key is hashed into a flat, filesystem-safe name.
+func syncRecordPath(localDir, hostName string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.WrapWithCode(err, errors.ErrSync,
+			"Can't find your home directory",
+			"This is unusual - check your environment.")
+	}
+	name := config.ProjectStateKey(localDir, hostName) + ".json"
+	return filepath.Join(home, config.GlobalConfigDir, syncRecordDir, name), nil
+}
+
+// ReadSyncRecord loads the sync record for a local directory and host.
+// Returns nil if it has never been synced there or the record is unreadable.
+func ReadSyncRecord(localDir, hostName string) *SyncRecord {
+	path, err := syncRecordPath(localDir, hostName)
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var r SyncRecord
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil
+	}
+	return &r
+}
+
+// SyncInterrupted reports whether the last sync of localDir to hostName
+// started but never finished. The next sync picks up the partial files it
+// left behind.
+func SyncInterrupted(localDir, hostName string) bool {
+	return ReadSyncRecord(localDir, hostName).Interrupted()
+}
+
+// writeSyncRecord persists a record atomically. Failures are ignored: the
+// record only affects what's displayed, never what's synced.
+func writeSyncRecord(r *SyncRecord) {
+	path, err := syncRecordPath(r.LocalDir, r.Host)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return
+	}
+	_ = util.WriteFileAtomic(path, data, 0644)
+}
+
+// ForgetSyncRecord deletes the sync record for a local directory and host,
+// for syncs of throwaway directories that shouldn't leave one behind.
+func ForgetSyncRecord(localDir, hostName string) {
+	if path, err := syncRecordPath(localDir, hostName); err == nil {
+		_ = os.Remove(path)
+	}
+}
+
+// markSyncStarted records that a sync is in progress. A process killed
+// mid-transfer never gets to markSyncFinished, which is how an interrupted
+// sync is detected.
+func markSyncStarted(localDir, hostName string) {
+	r := ReadSyncRecord(localDir, hostName)
+	if r == nil {
+		r = &SyncRecord{Host: hostName, LocalDir: config.NormalizeLocalDir(localDir)}
+	}
+	r.Started = time.Now()
+	writeSyncRecord(r)
+}
+
+// markSyncFinished records that a sync completed.
+func markSyncFinished(localDir, hostName string) {
+	r := ReadSyncRecord(localDir, hostName)
+	if r == nil {
+		r = &SyncRecord{Host: hostName, LocalDir: config.NormalizeLocalDir(localDir)}
+	}
+	r.Finished = time.Now()
+	if r.Started.IsZero() {
+		r.Started = r.Finished
+	}
+	writeSyncRecord(r)
+}
+
+// isDryRun reports whether custom flags turn the sync into a dry run, which
+// transfers nothing and so neither starts nor finishes a resumable sync.
+func isDryRun(flags []string) bool {
+	return slices.Contains(flags, "--dry-run") || slices.Contains(flags, "-n")
+}
+
+// CountChangedSince counts the files under root modified after since,
+// skipping the sync exclude patterns. It's how many files the next sync will
+// (at least) have to look at again. Deletions aren't counted, since nothing is
+// left locally to stat.
+func CountChangedSince(root string, cfg config.SyncConfig, since time.Time) (int, error) {
+	root = filepath.Clean(root)
+	count := 0
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable or vanished entries aren't worth failing over
+			if d != nil && d.IsDir() && path != root {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if path != root && excludedPath(root, cfg.Exclude, path) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err == nil && info.ModTime().After(since) {
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		return count, errors.WrapWithCode(err, errors.ErrSync,
+			"Couldn't scan "+root+" for changes",
+			"Check that the project directory is readable.")
+	}
+	return count, nil
+}
+
+// errChanged stops ChangedSince's walk at the first change.
+var errChanged = stderrors.New("changed")
+
+// ChangedSince reports whether anything under root, skipping the sync
+// exclude patterns, was modified after since. Unlike CountChangedSince it
+// also looks at directories, whose modification time moves when an entry in
+// them is created, renamed or deleted, so deletions count too. Excluded
+// entries moving a directory's time make this err on the side of "changed".
+func ChangedSince(root string, cfg config.SyncConfig, since time.Time) (bool, error) {
+	root = filepath.Clean(root)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if d != nil && d.IsDir() && path != root {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if path != root && excludedPath(root, cfg.Exclude, path) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() && !d.Type().IsRegular() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.ModTime().After(since) {
+			return errChanged
+		}
+		return nil
+	})
+	if err == errChanged {
+		return true, nil
+	}
+	if err != nil {
+		return true, errors.WrapWithCode(err, errors.ErrSync,
+			"Couldn't scan "+root+" for changes",
+			"Check that the project directory is readable.")
+	}
+	return false, nil
+}
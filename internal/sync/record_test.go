@@ -0,0 +1,116 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncRecord_Interrupted(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	localDir := t.TempDir()
+
+	assert.Nil(t, ReadSyncRecord(localDir, "mini"))
+	assert.False(t, SyncInterrupted(localDir, "mini"))
+
+	// A sync that never finishes leaves the record started but not finished
+	markSyncStarted(localDir, "mini")
+	assert.True(t, SyncInterrupted(localDir, "mini"))
+	assert.False(t, SyncInterrupted(localDir, "other"), "records are per host")
+
+	markSyncFinished(localDir, "mini")
+	assert.False(t, SyncInterrupted(localDir, "mini"))
+	r := ReadSyncRecord(localDir, "mini")
+	require.NotNil(t, r)
+	assert.False(t, r.Finished.IsZero())
+	assert.Equal(t, "mini", r.Host)
+
+	// The next sync starting after the last finish is interrupted again
+	markSyncStarted(localDir, "mini")
+	assert.True(t, SyncInterrupted(localDir, "mini"))
+}
+
+func TestIsDryRun(t *testing.T) {
+	assert.True(t, isDryRun([]string{"-v", "--dry-run"}))
+	assert.True(t, isDryRun([]string{"-n"}))
+	assert.False(t, isDryRun([]string{"--delete-excluded"}))
+	assert.False(t, isDryRun(nil))
+}
+
+func TestCountChangedSince(t *testing.T) {
+	root := t.TempDir()
+	old := time.Now().Add(-time.Hour)
+	since := time.Now().Add(-time.Minute)
+
+	write := func(rel string, mtime time.Time) {
+		path := filepath.Join(root, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte("x"), 0644))
+		require.NoError(t, os.Chtimes(path, mtime, mtime))
+	}
+	write("main.go", time.Now())
+	write("pkg/util.go", time.Now())
+	write("README.md", old)
+	write(".git/index", time.Now())
+	write("app.pyc", time.Now())
+
+	count, err := CountChangedSince(root, config.SyncConfig{Exclude: []string{".git/", "*.pyc"}}, since)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestChangedSince(t *testing.T) {
+	root := t.TempDir()
+	old := time.Now().Add(-time.Hour)
+	since := time.Now().Add(-time.Minute)
+	cfg := config.SyncConfig{Exclude: []string{".git/"}}
+
+	touch := func(rel string, mtime time.Time) {
+		path := filepath.Join(root, rel)
+		require.NoError(t, os.Chtimes(path, mtime, mtime))
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "pkg"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".git"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "pkg", "util.go"), []byte("x"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "pkg", "old.go"), []byte("x"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".git", "index"), []byte("x"), 0644))
+	touch("pkg/util.go", old)
+	touch("pkg/old.go", old)
+	touch("pkg", old)
+	touch(".", old)
+
+	changed, err := ChangedSince(root, cfg, since)
+	require.NoError(t, err)
+	assert.False(t, changed, "only excluded files are new")
+
+	// A deleted file moves its directory's modification time
+	require.NoError(t, os.Remove(filepath.Join(root, "pkg", "old.go")))
+	changed, err = ChangedSince(root, cfg, since)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	touch("pkg", old)
+	touch("pkg/util.go", time.Now())
+	changed, err = ChangedSince(root, cfg, since)
+	require.NoError(t, err)
+	assert.True(t, changed)
+}
+
+func TestForgetSyncRecord(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	localDir := t.TempDir()
+
+	markSyncFinished(localDir, "mini")
+	require.NotNil(t, ReadSyncRecord(localDir, "mini"))
+
+	ForgetSyncRecord(localDir, "mini")
+	assert.Nil(t, ReadSyncRecord(localDir, "mini"))
+
+	// Forgetting a record that doesn't exist is a no-op
+	ForgetSyncRecord(localDir, "other")
+}
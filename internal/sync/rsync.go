@@ -2,25 +2,98 @@ package sync
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
+	"runtime"
 	"strings"
 
 	"github.com/rileyhilliard/rr/internal/errors"
 	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/rileyhilliard/rr/internal/hostinfo"
+	"github.com/rileyhilliard/rr/internal/util"
 )
 
+// systemRsync is where the OS-provided rsync lives.
+const systemRsync = "/usr/bin/rsync"
+
+// preferredRsync lists, per OS, rsync installs to use instead of the system
+// one. macOS's /usr/bin/rsync is openrsync (or GNU rsync 2.6.9 before
+// Sequoia), which lacks flags rr uses; Homebrew's rsync is current GNU rsync
+// and is often installed but behind /usr/bin in PATH.
+var preferredRsync = map[string][]string{
+	"darwin": {"/opt/homebrew/bin/rsync", "/usr/local/bin/rsync"},
+}
+
+// goos is runtime.GOOS, swapped out in tests.
+var goos = runtime.GOOS
+
 // FindRsync locates the rsync binary on the local system.
 // Returns the full path to rsync or an error if not found.
+//
+// When PATH resolves to the system rsync, or to nothing, an OS-specific
+// preferred install (see preferredRsync) is used if one exists.
 func FindRsync() (string, error) {
 	path, err := exec.LookPath("rsync")
+	if err != nil || path == systemRsync {
+		for _, candidate := range preferredRsync[goos] {
+			if info, statErr := os.Stat(candidate); statErr == nil && !info.IsDir() && info.Mode()&0111 != 0 {
+				return candidate, nil
+			}
+		}
+	}
 	if err != nil {
 		return "", errors.New(errors.ErrSync,
 			"rsync isn't installed locally",
-			"Grab it with: brew install rsync (macOS) or apt install rsync (Linux)")
+			"Grab it with: brew install rsync (macOS) or apt install rsync (Linux)").
+			WithID(errors.IDSyncRsyncMissing)
 	}
 	return path, nil
 }
 
+// detectedRsyncPath returns the rsync host detection found on a host that's
+// better than the one its PATH finds (see hostinfo.Info.RsyncPath), or "".
+// Reads the host info cache rather than asking the host, so it costs no
+// round trip. Swapped out in tests.
+var detectedRsyncPath = func(hostName string) string {
+	cache, err := hostinfo.Load()
+	if err != nil {
+		return ""
+	}
+	return cache[hostName].RsyncPath
+}
+
+// RemoteRsyncPath returns the rsync to run on conn's host: the host's
+// rsync_path, else a better rsync host detection found there, else "" for
+// whichever rsync the host's PATH finds.
+func RemoteRsyncPath(conn *host.Connection) string {
+	if conn == nil {
+		return ""
+	}
+	if conn.Host.RsyncPath != "" {
+		return conn.Host.RsyncPath
+	}
+	return detectedRsyncPath(conn.Name)
+}
+
+// rsyncPathArgs returns the --rsync-path flag for conn's host, if it needs
+// one.
+func rsyncPathArgs(conn *host.Connection) []string {
+	if path := RemoteRsyncPath(conn); path != "" {
+		return []string{"--rsync-path=" + path}
+	}
+	return nil
+}
+
+// rsyncCommand returns a command running rsync in the C locale, so the
+// progress lines, stats and error messages rr parses come out in English with
+// dot decimals. ssh passes LC_ALL on to the remote rsync where the host
+// accepts it (SendEnv/AcceptEnv LC_*, the default on most distros).
+func rsyncCommand(rsyncPath string, args ...string) *exec.Cmd {
+	cmd := exec.Command(rsyncPath, args...)
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	return cmd
+}
+
 // Version returns the rsync version string from the local installation.
 func Version() (string, error) {
 	rsyncPath, err := FindRsync()
@@ -28,7 +101,7 @@ func Version() (string, error) {
 		return "", err
 	}
 
-	cmd := exec.Command(rsyncPath, "--version")
+	cmd := rsyncCommand(rsyncPath, "--version")
 	out, err := cmd.Output()
 	if err != nil {
 		return "", errors.WrapWithCode(err, errors.ErrSync,
@@ -55,7 +128,11 @@ func CheckRemote(conn *host.Connection) error {
 	}
 
 	// Check if rsync exists on remote using Exec
-	_, _, exitCode, err := conn.Client.Exec("which rsync")
+	checkCmd := "which rsync"
+	if fields := strings.Fields(RemoteRsyncPath(conn)); len(fields) > 0 {
+		checkCmd = "which " + util.ShellQuote(fields[0])
+	}
+	_, _, exitCode, err := conn.Client.Exec(checkCmd)
 	if err != nil {
 		return errors.WrapWithCode(err, errors.ErrSSH,
 			"Couldn't check for rsync on the remote",
@@ -64,7 +141,8 @@ func CheckRemote(conn *host.Connection) error {
 	if exitCode != 0 {
 		return errors.New(errors.ErrSync,
 			fmt.Sprintf("rsync isn't installed on %s", conn.Name),
-			"Install it on the remote: apt install rsync (Debian/Ubuntu) or yum install rsync (RHEL)")
+			"Install it on the remote: apt install rsync (Debian/Ubuntu) or yum install rsync (RHEL)").
+			WithID(errors.IDSyncRsyncMissing)
 	}
 
 	return nil
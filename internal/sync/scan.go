@@ -0,0 +1,88 @@
+package sync
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/host"
+)
+
+// Totals is how much a sync will transfer: the files it sends and their
+// combined size. Unchanged files aren't counted.
+type Totals struct {
+	Files int
+	Bytes int64
+}
+
+// statsFilesRegex matches the transferred file count in rsync --stats
+// output: "Number of regular files transferred: 1,234" in rsync 3.1+,
+// "Number of files transferred: 1234" in older rsync and openrsync.
+var statsFilesRegex = regexp.MustCompile(`(?m)^Number of (?:regular )?files transferred:\s*([\d,]+)`)
+
+// statsBytesRegex matches the transferred size in rsync --stats output,
+// like "Total transferred file size: 12,345,678 bytes".
+var statsBytesRegex = regexp.MustCompile(`(?m)^Total transferred file size:\s*([\d,]+)`)
+
+// Scan dry-runs a sync with --stats to find its Totals up front, since
+// rsync's own percentage only covers the files it has found so far. It's
+// one rsync over the shared SSH connection that compares file lists and
+// moves no data.
+//
+// Without --info=progress2 the sync reports no progress to measure against
+// the totals, so Scan returns zero Totals and skips the dry run.
+func Scan(conn *host.Connection, localDir string, cfg config.SyncConfig) (Totals, error) {
+	if conn == nil || conn.IsLocal {
+		return Totals{}, nil
+	}
+
+	rsyncPath, err := FindRsync()
+	if err != nil {
+		return Totals{}, err
+	}
+	rsync := DetectRsync(rsyncPath)
+	if !rsync.Supports(FeatureProgress2) {
+		return Totals{}, nil
+	}
+
+	args, err := buildArgs(rsync, conn, localDir, cfg)
+	if err != nil {
+		return Totals{}, err
+	}
+	args = scanArgs(args)
+
+	output, err := rsyncCommand(rsyncPath, args...).CombinedOutput()
+	if err != nil {
+		return Totals{}, errors.WrapWithCode(err, errors.ErrSync,
+			"Couldn't count the files to sync: "+strings.TrimSpace(string(output)),
+			"The sync itself will still report progress.")
+	}
+	return ParseStats(string(output)), nil
+}
+
+// scanArgs turns a sync's rsync arguments into a dry run that prints
+// --stats, keeping the source and destination last.
+func scanArgs(args []string) []string {
+	n := len(args)
+	scan := slices.DeleteFunc(slices.Clone(args[:n-2]), func(arg string) bool {
+		return arg == "--info=progress2"
+	})
+	scan = append(scan, "--dry-run", "--stats")
+	return append(scan, args[n-2:]...)
+}
+
+// ParseStats reads the transferred file count and size from rsync --stats
+// output. Missing lines count as zero.
+func ParseStats(output string) Totals {
+	var t Totals
+	if m := statsFilesRegex.FindStringSubmatch(output); m != nil {
+		t.Files, _ = strconv.Atoi(strings.ReplaceAll(m[1], ",", ""))
+	}
+	if m := statsBytesRegex.FindStringSubmatch(output); m != nil {
+		t.Bytes, _ = strconv.ParseInt(strings.ReplaceAll(m[1], ",", ""), 10, 64)
+	}
+	return t
+}
@@ -0,0 +1,76 @@
+package sync
+
+import (
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStats(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   Totals
+	}{
+		{
+			name: "rsync 3.1+",
+			output: `
+Number of files: 1,234 (reg: 1,000, dir: 234)
+Number of created files: 5 (reg: 5)
+Number of deleted files: 0
+Number of regular files transferred: 12
+Total file size: 45,678,901 bytes
+Total transferred file size: 1,234,567 bytes
+Literal data: 0 bytes
+`,
+			want: Totals{Files: 12, Bytes: 1234567},
+		},
+		{
+			name: "older rsync",
+			output: `Number of files: 1234
+Number of files transferred: 3
+Total file size: 45678901 bytes
+Total transferred file size: 2048 bytes
+`,
+			want: Totals{Files: 3, Bytes: 2048},
+		},
+		{
+			name:   "nothing to transfer",
+			output: "Number of regular files transferred: 0\nTotal transferred file size: 0 bytes\n",
+			want:   Totals{},
+		},
+		{
+			name:   "no stats",
+			output: "rsync: connection unexpectedly closed\n",
+			want:   Totals{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseStats(tt.output))
+		})
+	}
+}
+
+func TestScanArgs(t *testing.T) {
+	conn := &host.Connection{Name: "m1", Alias: "m1", Host: config.Host{Dir: "~/app"}}
+	args, err := BuildArgs(conn, "/home/user/app", config.SyncConfig{Exclude: []string{".git/"}})
+	require.NoError(t, err)
+
+	scan := scanArgs(args)
+
+	assert.NotContains(t, scan, "--info=progress2")
+	assert.Contains(t, scan, "--exclude=.git/", "the dry run matches the real sync")
+	assert.Equal(t, []string{"--dry-run", "--stats", "/home/user/app/", "m1:~/app/"}, scan[len(scan)-4:])
+	assert.Contains(t, args, "--info=progress2", "the sync's args are left alone")
+}
+
+func TestScan_LocalConnection(t *testing.T) {
+	totals, err := Scan(&host.Connection{IsLocal: true}, t.TempDir(), config.SyncConfig{})
+	require.NoError(t, err)
+	assert.Zero(t, totals)
+}
@@ -0,0 +1,451 @@
+package sync
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	gosync "sync"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/host"
+)
+
+// shardMinFiles is the smallest project sync.parallel splits. Below it a
+// single rsync is done before extra processes would pay for themselves.
+var shardMinFiles = 100000
+
+// maxShardDepth is how many levels down planShards goes looking for
+// subdirectories to split a directory that's too big for one shard.
+const maxShardDepth = 3
+
+// rsyncJob is one rsync process of a sync.
+type rsyncJob struct {
+	args  []string
+	files int // files in the job's part of the project, weights its progress
+}
+
+// shard is a set of project directories one rsync transfers, as
+// slash-separated paths relative to the project root.
+type shard struct {
+	dirs  []string
+	files int
+}
+
+// syncJobs returns the rsync processes a sync runs. That's one for the whole
+// project unless planShards splits it, in which case each shard gets one and
+// a last one covers everything outside the shards: top-level files, files
+// beside split directories, and deleting what's gone locally.
+func syncJobs(rsync Rsync, conn *host.Connection, localDir string, cfg config.SyncConfig) ([]rsyncJob, error) {
+	shards, rest := planShards(rsync, localDir, cfg)
+	if len(shards) == 0 {
+		args, err := buildArgs(rsync, conn, localDir, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return []rsyncJob{{args: args}}, nil
+	}
+
+	jobs := make([]rsyncJob, 0, len(shards)+1)
+	var sharded []string
+	for _, sh := range shards {
+		args, err := buildShardArgs(rsync, conn, localDir, cfg, shardFilters(sh.dirs))
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, rsyncJob{args: args, files: sh.files})
+		sharded = append(sharded, sh.dirs...)
+	}
+
+	args, err := buildShardArgs(rsync, conn, localDir, cfg, restFilters(sharded))
+	if err != nil {
+		return nil, err
+	}
+	return append(jobs, rsyncJob{args: args, files: rest}), nil
+}
+
+// dirCount is the file count of one local directory.
+type dirCount struct {
+	files    int      // regular files anywhere under the directory
+	children []string // subdirectories that can be shard units
+}
+
+// planShards splits a project of at least shardMinFiles files into up to
+// sync.parallel-1 shards of roughly equal file counts, leaving room for the
+// rsync that covers the rest. Shards are built from top-level directories;
+// one holding more than its share is split into its subdirectories, up to
+// maxShardDepth deep. It also returns the number of files left outside the
+// shards.
+//
+// Returns no shards when sync.parallel is unset, the project is small, or
+// the local rsync can't express shards (see FeatureShards).
+func planShards(rsync Rsync, localDir string, cfg config.SyncConfig) ([]shard, int) {
+	if cfg.Parallel < 2 || !rsync.Supports(FeatureShards) {
+		return nil, 0
+	}
+
+	tree := countFiles(localDir, cfg.Exclude)
+	total := tree["."].files
+	if total < shardMinFiles {
+		return nil, 0
+	}
+
+	// Split directories bigger than a shard's share until none are left or
+	// they can't be split further
+	share := total / cfg.Parallel
+	units := append([]string(nil), tree["."].children...)
+	for {
+		largest := -1
+		for i, u := range units {
+			n := tree[u]
+			if n.files <= share || len(n.children) == 0 || strings.Count(u, "/")+1 >= maxShardDepth {
+				continue
+			}
+			if largest < 0 || n.files > tree[units[largest]].files {
+				largest = i
+			}
+		}
+		if largest < 0 {
+			break
+		}
+		split := units[largest]
+		units = append(units[:largest], units[largest+1:]...)
+		units = append(units, tree[split].children...)
+	}
+
+	// Largest first into whichever shard is smallest so far. The rest job
+	// counts as a shard for balancing, since it runs alongside the others.
+	sort.Slice(units, func(i, j int) bool {
+		if tree[units[i]].files != tree[units[j]].files {
+			return tree[units[i]].files > tree[units[j]].files
+		}
+		return units[i] < units[j]
+	})
+	shards := make([]shard, cfg.Parallel-1)
+	rest := total
+	for _, u := range units {
+		rest -= tree[u].files
+	}
+	for _, u := range units {
+		if tree[u].files == 0 {
+			continue
+		}
+		smallest := 0
+		for i := range shards {
+			if shards[i].files < shards[smallest].files {
+				smallest = i
+			}
+		}
+		if shards[smallest].files >= rest+tree[u].files {
+			// Cheaper to leave it with the rest
+			rest += tree[u].files
+			continue
+		}
+		shards[smallest].dirs = append(shards[smallest].dirs, u)
+		shards[smallest].files += tree[u].files
+	}
+
+	var planned []shard
+	for _, sh := range shards {
+		if len(sh.dirs) > 0 {
+			sort.Strings(sh.dirs)
+			planned = append(planned, sh)
+		}
+	}
+	return planned, rest
+}
+
+// countFiles counts the regular files under every directory of root,
+// skipping the sync exclude patterns. Keys are slash-separated paths
+// relative to root, with "." for root itself.
+func countFiles(root string, excludes []string) map[string]*dirCount {
+	root = filepath.Clean(root)
+	tree := map[string]*dirCount{".": {}}
+	_ = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable or vanished entries aren't worth failing over
+			if d != nil && d.IsDir() && p != root {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if p == root {
+			return nil
+		}
+		if excludedPath(root, excludes, p) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		parent := path.Dir(rel)
+
+		if d.IsDir() {
+			tree[rel] = &dirCount{}
+			if isShardable(d.Name()) {
+				tree[parent].children = append(tree[parent].children, rel)
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		for dir := parent; ; dir = path.Dir(dir) {
+			tree[dir].files++
+			if dir == "." {
+				break
+			}
+		}
+		return nil
+	})
+	return tree
+}
+
+// isShardable reports whether a directory name can be used in a shard's
+// filter rules as is. Names with rsync wildcard characters would need
+// escaping, so those directories just stay with their parent.
+func isShardable(name string) bool {
+	return !strings.ContainsAny(name, `*?[\`)
+}
+
+// shardFilters returns the filter rules that limit an rsync to the given
+// directories. Each directory's parents are included without their other
+// contents so rsync can descend to it, and everything else is excluded,
+// which also keeps --delete out of it.
+//
+// For "pkg/a" that's:
+//
+//	--include=/pkg/ --include=/pkg/a/*** --exclude=/pkg/* --exclude=/*
+func shardFilters(dirs []string) []string {
+	var parents []string
+	seen := map[string]bool{}
+	for _, d := range dirs {
+		for p := path.Dir(d); p != "."; p = path.Dir(p) {
+			if !seen[p] {
+				seen[p] = true
+				parents = append(parents, p)
+			}
+		}
+	}
+	sort.Strings(parents)
+
+	filters := make([]string, 0, 2*len(parents)+len(dirs)+1)
+	for _, p := range parents {
+		filters = append(filters, "--include=/"+p+"/")
+	}
+	for _, d := range dirs {
+		filters = append(filters, "--include=/"+d+"/***")
+	}
+	// Deepest parents first, so the order reads from the inside out
+	for i := len(parents) - 1; i >= 0; i-- {
+		filters = append(filters, "--exclude=/"+parents[i]+"/*")
+	}
+	return append(filters, "--exclude=/*")
+}
+
+// restFilters returns the filter rules for the rsync that covers everything
+// outside the shards. Excluding the sharded directories also keeps its
+// --delete away from them.
+func restFilters(sharded []string) []string {
+	dirs := append([]string(nil), sharded...)
+	sort.Strings(dirs)
+	filters := make([]string, len(dirs))
+	for i, d := range dirs {
+		filters[i] = "--exclude=/" + d + "/"
+	}
+	return filters
+}
+
+// runShards runs a sharded sync's rsync processes at once, merging their
+// progress into one line. There are never more than sync.parallel of them.
+// It waits for all of them and returns the first error.
+func runShards(rsyncPath, hostName string, jobs []rsyncJob, progress io.Writer) error {
+	var merger *progressMerger
+	if progress != nil {
+		merger = newProgressMerger(progress, jobs)
+	}
+
+	errs := make([]error, len(jobs))
+	var wg gosync.WaitGroup
+	for i, job := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var w io.Writer
+			if merger != nil {
+				w = merger.writer(i)
+			}
+			errs[i] = runRsync(rsyncPath, job.args, hostName, w)
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// progressMerger combines the --info=progress2 lines of several rsync
+// processes into one progress line for the whole sync. Each process's
+// percentage is weighted by how many files it has, speeds add up, and the
+// time remaining is the longest one. File counts add up, so the totals from
+// Scan still apply. Other output passes through.
+type progressMerger struct {
+	mu      gosync.Mutex
+	out     io.Writer
+	weights []int
+	latest  []*Progress
+	speeds  []float64
+	etas    []int
+}
+
+func newProgressMerger(out io.Writer, jobs []rsyncJob) *progressMerger {
+	m := &progressMerger{
+		out:     out,
+		weights: make([]int, len(jobs)),
+		latest:  make([]*Progress, len(jobs)),
+		speeds:  make([]float64, len(jobs)),
+		etas:    make([]int, len(jobs)),
+	}
+	for i, job := range jobs {
+		m.weights[i] = job.files
+	}
+	return m
+}
+
+// writer returns the writer for job i's output.
+func (m *progressMerger) writer(i int) io.Writer {
+	return &mergedWriter{merger: m, job: i}
+}
+
+// update records a progress line from job i and writes the merged line.
+func (m *progressMerger) update(i int, p *Progress) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.latest[i] = p
+	m.speeds[i] = parseSpeed(p.Speed)
+	m.etas[i] = parseDuration(p.TimeRemaining)
+
+	var bytes int64
+	var speed float64
+	var weighted, weight, eta int
+	var files, toCheck, totalFiles int
+	for j, lp := range m.latest {
+		w := max(m.weights[j], 1)
+		weight += w
+		if lp == nil {
+			continue
+		}
+		bytes += lp.BytesTransferred
+		speed += m.speeds[j]
+		weighted += lp.Percentage * w
+		eta = max(eta, m.etas[j])
+		files += lp.FileCount
+		toCheck += lp.FilesToCheck
+		totalFiles += lp.TotalFiles
+	}
+
+	line := fmt.Sprintf("%15d %3d%% %s %s", bytes, weighted/weight, formatSpeed(speed), formatDuration(eta))
+	if totalFiles > 0 {
+		line += fmt.Sprintf(" (xfr#%d, to-chk=%d/%d)", files, toCheck, totalFiles)
+	}
+	fmt.Fprintln(m.out, line)
+}
+
+// passthrough writes a non-progress line.
+func (m *progressMerger) passthrough(line string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	fmt.Fprintln(m.out, line)
+}
+
+// mergedWriter feeds one rsync process's output into a progressMerger.
+// streamOutput writes it a line at a time.
+type mergedWriter struct {
+	merger *progressMerger
+	job    int
+}
+
+func (w *mergedWriter) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		if prog := ParseProgress(line); prog != nil {
+			w.merger.update(w.job, prog)
+		} else {
+			w.merger.passthrough(line)
+		}
+	}
+	return len(p), nil
+}
+
+// speedRegex matches rsync speeds like "1.23MB/s" and "512.00kB/s".
+var speedRegex = regexp.MustCompile(`^([\d.]+)([kMG]?)B/s$`)
+
+// parseSpeed converts an rsync speed to bytes per second, or 0 if it can't
+// be read.
+func parseSpeed(s string) float64 {
+	m := speedRegex.FindStringSubmatch(s)
+	if m == nil {
+		return 0
+	}
+	v, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0
+	}
+	switch m[2] {
+	case "k":
+		v *= 1024
+	case "M":
+		v *= 1024 * 1024
+	case "G":
+		v *= 1024 * 1024 * 1024
+	}
+	return v
+}
+
+// formatSpeed formats bytes per second the way rsync does.
+func formatSpeed(v float64) string {
+	units := []string{"", "k", "M", "G"}
+	i := 0
+	for v >= 1024 && i < len(units)-1 {
+		v /= 1024
+		i++
+	}
+	return fmt.Sprintf("%.2f%sB/s", v, units[i])
+}
+
+// parseDuration converts an rsync time like "0:01:23" to seconds, or 0 if
+// it can't be read.
+func parseDuration(s string) int {
+	secs := 0
+	for _, part := range strings.Split(s, ":") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return 0
+		}
+		secs = secs*60 + n
+	}
+	return secs
+}
+
+// formatDuration formats seconds the way rsync does, as h:mm:ss.
+func formatDuration(secs int) string {
+	return fmt.Sprintf("%d:%02d:%02d", secs/3600, secs/60%60, secs%60)
+}
@@ -0,0 +1,211 @@
+package sync
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFiles creates n empty files in dir under root.
+func writeFiles(t *testing.T, root, dir string, n int) {
+	t.Helper()
+	full := filepath.Join(root, dir)
+	require.NoError(t, os.MkdirAll(full, 0755))
+	for i := 0; i < n; i++ {
+		require.NoError(t, os.WriteFile(filepath.Join(full, fmt.Sprintf("f%d", i)), nil, 0644))
+	}
+}
+
+// withShardMinFiles lowers the sharding threshold for a test.
+func withShardMinFiles(t *testing.T, n int) {
+	t.Helper()
+	orig := shardMinFiles
+	shardMinFiles = n
+	t.Cleanup(func() { shardMinFiles = orig })
+}
+
+func shardProject(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	writeFiles(t, root, ".", 2)
+	writeFiles(t, root, "docs", 10)
+	writeFiles(t, root, "pkg", 4)
+	writeFiles(t, root, "pkg/api", 40)
+	writeFiles(t, root, "pkg/web", 30)
+	writeFiles(t, root, "tools", 14)
+	writeFiles(t, root, "node_modules/x", 500)
+	return root
+}
+
+func TestPlanShards(t *testing.T) {
+	withShardMinFiles(t, 50)
+	root := shardProject(t)
+
+	shards, rest := planShards(ModernRsync, root, config.SyncConfig{
+		Parallel: 3,
+		Exclude:  []string{"node_modules/"},
+	})
+
+	// pkg holds most of the files, so it's split into its subdirectories
+	// and its own files are left to the rest job
+	assert.Equal(t, []shard{
+		{dirs: []string{"pkg/api"}, files: 40},
+		{dirs: []string{"pkg/web"}, files: 30},
+	}, shards)
+	assert.Equal(t, 2+10+4+14, rest)
+}
+
+func TestPlanShards_NotSharded(t *testing.T) {
+	withShardMinFiles(t, 50)
+	root := shardProject(t)
+	cfg := config.SyncConfig{Parallel: 4, Exclude: []string{"node_modules/"}}
+
+	shards, _ := planShards(ModernRsync, root, config.SyncConfig{Exclude: cfg.Exclude})
+	assert.Empty(t, shards, "sync.parallel unset")
+
+	shards, _ = planShards(openRsync, root, cfg)
+	assert.Empty(t, shards, "rsync without *** patterns")
+
+	shardMinFiles = 1000
+	shards, _ = planShards(ModernRsync, root, cfg)
+	assert.Empty(t, shards, "project too small")
+}
+
+func TestShardFilters(t *testing.T) {
+	assert.Equal(t, []string{
+		"--include=/pkg/",
+		"--include=/pkg/a/***",
+		"--include=/docs/***",
+		"--exclude=/pkg/*",
+		"--exclude=/*",
+	}, shardFilters([]string{"pkg/a", "docs"}))
+
+	assert.Equal(t, []string{
+		"--include=/a/",
+		"--include=/a/b/",
+		"--include=/a/b/c/***",
+		"--exclude=/a/b/*",
+		"--exclude=/a/*",
+		"--exclude=/*",
+	}, shardFilters([]string{"a/b/c"}))
+}
+
+func TestRestFilters(t *testing.T) {
+	assert.Equal(t, []string{"--exclude=/docs/", "--exclude=/pkg/a/"}, restFilters([]string{"pkg/a", "docs"}))
+}
+
+func TestSyncJobs(t *testing.T) {
+	withShardMinFiles(t, 50)
+	root := shardProject(t)
+	conn := &host.Connection{Name: "box", Alias: "box", Host: config.Host{Dir: "~/rr/app"}}
+	cfg := config.SyncConfig{
+		Parallel: 3,
+		Exclude:  []string{"node_modules/"},
+		Flags:    []string{"--checksum"},
+	}
+
+	jobs, err := syncJobs(ModernRsync, conn, root, cfg)
+	require.NoError(t, err)
+	require.Len(t, jobs, 3)
+
+	for _, job := range jobs {
+		// Configured excludes come before the shard rules so they still
+		// win, and custom flags stay just before source and destination
+		n := len(job.args)
+		assert.Equal(t, []string{"--checksum", root + "/", "box:~/rr/app/"}, job.args[n-3:])
+		assert.Less(t, indexOf(job.args, "--exclude=node_modules/"), n-3)
+	}
+	assert.Contains(t, jobs[0].args, "--include=/pkg/api/***")
+	assert.Contains(t, jobs[1].args, "--include=/pkg/web/***")
+	assert.Contains(t, jobs[2].args, "--exclude=/pkg/api/")
+	assert.Contains(t, jobs[2].args, "--exclude=/pkg/web/")
+	assert.Equal(t, 30, jobs[2].files)
+
+	// Without sync.parallel it's a plain sync
+	cfg.Parallel = 0
+	jobs, err = syncJobs(ModernRsync, conn, root, cfg)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	args, err := BuildArgs(conn, root, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, args, jobs[0].args)
+}
+
+func indexOf(args []string, want string) int {
+	for i, a := range args {
+		if a == want {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestProgressMerger(t *testing.T) {
+	var out bytes.Buffer
+	m := newProgressMerger(&out, []rsyncJob{{files: 300}, {files: 100}})
+
+	_, _ = m.writer(0).Write([]byte("      1,000,000  50%    1.00MB/s    0:00:10\n"))
+	_, _ = m.writer(1).Write([]byte("        500,000 100%  512.00kB/s    0:00:02\n"))
+	_, _ = m.writer(1).Write([]byte("rsync: some warning\n"))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+
+	// Job 1 hasn't reported yet, so it counts as 0%
+	first := ParseProgress(lines[0])
+	require.NotNil(t, first)
+	assert.Equal(t, int64(1000000), first.BytesTransferred)
+	assert.Equal(t, 37, first.Percentage)
+
+	merged := ParseProgress(lines[1])
+	require.NotNil(t, merged)
+	assert.Equal(t, int64(1500000), merged.BytesTransferred)
+	assert.Equal(t, 62, merged.Percentage) // (50*300 + 100*100) / 400
+	assert.Equal(t, "1.50MB/s", merged.Speed)
+	assert.Equal(t, "0:00:10", merged.TimeRemaining)
+
+	assert.Equal(t, "rsync: some warning", lines[2])
+}
+
+func TestProgressMerger_FileCounts(t *testing.T) {
+	var out bytes.Buffer
+	m := newProgressMerger(&out, []rsyncJob{{files: 300}, {files: 100}})
+
+	_, _ = m.writer(0).Write([]byte("      1,000,000  50%    1.00MB/s    0:00:10\n"))
+	assert.NotContains(t, out.String(), "xfr#", "no counts until a job reports them")
+
+	_, _ = m.writer(0).Write([]byte("      2,000,000  60%    1.00MB/s    0:00:08 (xfr#12, to-chk=180/300)\n"))
+	_, _ = m.writer(1).Write([]byte("        500,000 100%  512.00kB/s    0:00:00 (xfr#5, to-chk=0/100)\n"))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	merged := ParseProgress(lines[len(lines)-1])
+	require.NotNil(t, merged)
+	assert.Equal(t, 17, merged.FileCount)
+	assert.Equal(t, 180, merged.FilesToCheck)
+	assert.Equal(t, 400, merged.TotalFiles)
+}
+
+func TestParseSpeed(t *testing.T) {
+	assert.Equal(t, 512.0, parseSpeed("512.00B/s"))
+	assert.Equal(t, 1536.0, parseSpeed("1.50kB/s"))
+	assert.Equal(t, 2.0*1024*1024, parseSpeed("2.00MB/s"))
+	assert.Equal(t, 0.0, parseSpeed("fast"))
+
+	assert.Equal(t, "1.50kB/s", formatSpeed(1536))
+	assert.Equal(t, "2.00GB/s", formatSpeed(2*1024*1024*1024))
+}
+
+func TestParseDuration(t *testing.T) {
+	assert.Equal(t, 83, parseDuration("0:01:23"))
+	assert.Equal(t, 3725, parseDuration("1:02:05"))
+	assert.Equal(t, 0, parseDuration("soon"))
+	assert.Equal(t, "1:02:05", formatDuration(3725))
+}
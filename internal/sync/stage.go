@@ -0,0 +1,97 @@
+package sync
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/host"
+)
+
+// stageRoot is the directory under ~/.rr/ that holds task outputs on their
+// way from one host to another (task inputs/outputs).
+const stageRoot = "pipeline"
+
+// StageDir returns where a task's outputs are kept locally between hosts,
+// per project. The outputs keep their paths relative to the project root.
+func StageDir(localDir, task string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.WrapWithCode(err, errors.ErrSync,
+			"Can't find your home directory",
+			"This is unusual - check your environment.")
+	}
+	return filepath.Join(home, config.GlobalConfigDir, stageRoot, config.ProjectStateKey(localDir), task), nil
+}
+
+// StageOutputs replaces stageDir with a task's outputs: pulled from the
+// host's dir, or copied from localDir when the task ran locally. A pattern
+// that matches nothing is an error (see Pull).
+func StageOutputs(conn *host.Connection, localDir, stageDir string, outputs []string) error {
+	if err := os.RemoveAll(stageDir); err != nil {
+		return errors.WrapWithCode(err, errors.ErrSync,
+			"Couldn't clear the staged outputs in "+stageDir,
+			"Check permissions on ~/.rr/.")
+	}
+	if err := os.MkdirAll(stageDir, 0755); err != nil {
+		return errors.WrapWithCode(err, errors.ErrSync,
+			"Couldn't create "+stageDir,
+			"Check permissions on ~/.rr/.")
+	}
+
+	if conn == nil || conn.IsLocal {
+		return CopyLocal(localDir, stageDir, outputs)
+	}
+
+	items := make([]config.PullItem, len(outputs))
+	for i, out := range outputs {
+		out = cleanPushPath(out)
+		items[i] = config.PullItem{Src: out, Dest: filepath.Join(stageDir, filepath.FromSlash(path.Dir(out)))}
+	}
+	return Pull(conn, PullOptions{Patterns: items}, nil)
+}
+
+// SendStaged copies staged outputs into a host's dir (or localDir for local
+// runs), replacing what's at those paths and leaving everything else alone.
+func SendStaged(conn *host.Connection, localDir, stageDir string, outputs []string) error {
+	if _, err := os.Stat(stageDir); err != nil {
+		return errors.New(errors.ErrSync,
+			"Nothing staged in "+stageDir,
+			"Run the task that produces these outputs first (drop --skip-deps).")
+	}
+	if conn == nil || conn.IsLocal {
+		return CopyLocal(stageDir, localDir, outputs)
+	}
+	return Sync(conn, stageDir, PushConfig(config.SyncConfig{}, outputs), nil)
+}
+
+// CopyLocal mirrors paths from one local directory to another with rsync.
+// Deletions are limited to the paths themselves (see pushFilters).
+func CopyLocal(src, dst string, paths []string) error {
+	rsyncPath, err := FindRsync()
+	if err != nil {
+		return err
+	}
+	args := append([]string{"-a", "--delete"}, pushFilters(paths)...)
+	args = append(args, withSlash(src), withSlash(dst))
+
+	output, err := rsyncCommand(rsyncPath, args...).CombinedOutput()
+	if err != nil {
+		return errors.WrapWithCode(err, errors.ErrSync,
+			"Couldn't copy "+strings.Join(paths, ", ")+" to "+dst+": "+strings.TrimSpace(string(output)),
+			"Check that the paths exist and the directories are writable.")
+	}
+	return nil
+}
+
+// withSlash returns dir with a trailing slash, so rsync copies its contents.
+func withSlash(dir string) string {
+	dir = filepath.Clean(dir)
+	if !strings.HasSuffix(dir, "/") {
+		dir += "/"
+	}
+	return dir
+}
@@ -0,0 +1,36 @@
+package sync
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStageDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	build, err := StageDir("/home/user/app", "build")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".rr", "pipeline"), filepath.Dir(filepath.Dir(build)))
+	assert.Equal(t, "build", filepath.Base(build))
+
+	same, err := StageDir("/home/user/app/", "build")
+	require.NoError(t, err)
+	assert.Equal(t, build, same, "trailing slashes don't change the project")
+
+	other, err := StageDir("/home/user/other", "build")
+	require.NoError(t, err)
+	assert.NotEqual(t, build, other, "projects are staged apart")
+}
+
+func TestSendStaged_NothingStaged(t *testing.T) {
+	conn := &host.Connection{Name: "m1", Alias: "m1"}
+
+	err := SendStaged(conn, t.TempDir(), filepath.Join(t.TempDir(), "build"), []string{"dist/"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Nothing staged in")
+}
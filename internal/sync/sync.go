@@ -10,10 +10,12 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/rileyhilliard/rr/internal/config"
 	"github.com/rileyhilliard/rr/internal/errors"
 	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/rileyhilliard/rr/internal/logger"
 	"github.com/rileyhilliard/rr/internal/util"
 )
 
@@ -30,7 +32,10 @@ var SSHConfigFile string
 // buildSSHCmd returns the SSH command string for rsync's -e flag.
 // It includes ControlMaster for connection reuse and loads the user's SSH config
 // so rsync inherits ProxyCommand, IdentityFile, and other host-specific settings.
-func buildSSHCmd() string {
+// A host's identity_file from rr config is passed with -i, which ssh tries
+// ahead of any IdentityFile from the config file, and its ssh_options with -o.
+func buildSSHCmd(h config.Host) string {
+	identityFile := h.IdentityFile
 	cmd := fmt.Sprintf("ssh -o ControlMaster=auto -o ControlPath=%s/%%h-%%p -o ControlPersist=60 -o BatchMode=yes",
 		controlSocketDir)
 	configFile := SSHConfigFile
@@ -45,6 +50,17 @@ func buildSSHCmd() string {
 	if configFile != "" {
 		cmd = fmt.Sprintf("%s -F %q", cmd, configFile)
 	}
+	if identityFile != "" {
+		if strings.HasPrefix(identityFile, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				identityFile = filepath.Join(home, identityFile[2:])
+			}
+		}
+		cmd = fmt.Sprintf("%s -i %q", cmd, identityFile)
+	}
+	if args := h.SSHOptions.Args(); len(args) > 0 {
+		cmd += " " + strings.Join(args, " ")
+	}
 	return cmd
 }
 
@@ -58,6 +74,17 @@ func buildSSHCmd() string {
 // - Preserve patterns prevent deletion of specified paths on remote
 // - Exclude patterns prevent files from being synced
 // - Custom flags from config are appended
+//
+// --force, --info=progress2, --partial-dir and filter rules are only passed
+// when the local rsync supports them (see capabilityMatrix), so macOS's
+// openrsync works.
+//
+// Each sync is recorded in ~/.rr/sync/ (see SyncRecord). One that doesn't
+// finish is marked interrupted and leaves its partial files in PartialDir on
+// the remote, so the next one resumes.
+//
+// With sync.parallel set, a very large project is split into shards that
+// several rsync processes transfer at once (see planShards).
 func Sync(conn *host.Connection, localDir string, cfg config.SyncConfig, progress io.Writer) error {
 	// Skip sync for local connections - we're already working with local files
 	if conn != nil && conn.IsLocal {
@@ -78,61 +105,108 @@ func Sync(conn *host.Connection, localDir string, cfg config.SyncConfig, progres
 		return err
 	}
 
-	args, err := BuildArgs(conn, localDir, cfg)
+	jobs, err := syncJobs(DetectRsync(rsyncPath), conn, localDir, cfg)
 	if err != nil {
 		return err
 	}
 
-	cmd := exec.Command(rsyncPath, args...)
+	if !isDryRun(cfg.Flags) {
+		markSyncStarted(localDir, conn.Name)
+	}
 
-	// Set up progress output if provided
-	if progress != nil {
-		stdout, err := cmd.StdoutPipe()
-		if err != nil {
-			return errors.WrapWithCode(err, errors.ErrSync,
-				"Couldn't capture rsync output",
-				"Try running rsync manually to see what's happening.")
-		}
+	logger.Verbosef(logger.LevelPhases, "sync", "syncing %s to %s:%s", localDir, conn.Name, conn.Host.Dir)
+	if len(jobs) == 1 {
+		err = runRsync(rsyncPath, jobs[0].args, conn.Name, progress)
+	} else {
+		logger.Verbosef(logger.LevelPhases, "sync", "split into %d shards run by parallel rsyncs", len(jobs))
+		err = runShards(rsyncPath, conn.Name, jobs, progress)
+	}
+	if err != nil {
+		return err
+	}
 
-		stderr, err := cmd.StderrPipe()
-		if err != nil {
-			return errors.WrapWithCode(err, errors.ErrSync,
-				"Couldn't capture rsync stderr",
-				"Try running rsync manually to see what's happening.")
-		}
+	if !isDryRun(cfg.Flags) {
+		markSyncFinished(localDir, conn.Name)
+	}
+	return nil
+}
 
-		if err := cmd.Start(); err != nil {
-			return errors.WrapWithCode(err, errors.ErrSync,
-				"Couldn't start rsync",
-				"Make sure rsync is installed and the paths are valid.")
+// runRsync runs one rsync process, streaming its output to progress if
+// provided.
+func runRsync(rsyncPath string, args []string, hostName string, progress io.Writer) error {
+	cmd := rsyncCommand(rsyncPath, args...)
+	if logger.V(logger.LevelCommands) {
+		quoted := make([]string, len(args))
+		for i, arg := range args {
+			quoted[i] = util.ShellQuote(arg)
 		}
+		logger.Verbosef(logger.LevelCommands, "sync", "%s %s", rsyncPath, strings.Join(quoted, " "))
+	}
+	start := time.Now()
+	defer func() {
+		logger.Verbosef(logger.LevelWire, "sync", "rsync to %s finished after %s", hostName, time.Since(start))
+	}()
 
-		// Capture stderr for error analysis while also streaming to progress
-		var stderrBuf bytes.Buffer
-		stderrWriter := io.MultiWriter(&stderrBuf, progress)
-
-		// Stream stdout (progress info)
-		go streamOutput(stdout, progress)
-		// Stream stderr (errors/warnings) to both buffer and progress
-		go streamOutput(stderr, stderrWriter)
-
-		if err := cmd.Wait(); err != nil {
-			return handleRsyncError(err, conn.Name, stderrBuf.String())
-		}
-	} else {
-		// No progress output, just run and wait
+	// No progress output, just run and wait
+	if progress == nil {
 		output, err := cmd.CombinedOutput()
 		if err != nil {
-			return handleRsyncError(err, conn.Name, string(output))
+			return handleRsyncError(err, hostName, string(output))
 		}
+		return nil
 	}
 
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return errors.WrapWithCode(err, errors.ErrSync,
+			"Couldn't capture rsync output",
+			"Try running rsync manually to see what's happening.")
+	}
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return errors.WrapWithCode(err, errors.ErrSync,
+			"Couldn't capture rsync stderr",
+			"Try running rsync manually to see what's happening.")
+	}
+
+	if err := cmd.Start(); err != nil {
+		return errors.WrapWithCode(err, errors.ErrSync,
+			"Couldn't start rsync",
+			"Make sure rsync is installed and the paths are valid.")
+	}
+
+	// Capture stderr for error analysis while also streaming to progress
+	var stderrBuf bytes.Buffer
+	stderrWriter := io.MultiWriter(&stderrBuf, progress)
+
+	// Stream stdout (progress info)
+	go streamOutput(stdout, progress)
+	// Stream stderr (errors/warnings) to both buffer and progress
+	go streamOutput(stderr, stderrWriter)
+
+	if err := cmd.Wait(); err != nil {
+		return handleRsyncError(err, hostName, stderrBuf.String())
+	}
 	return nil
 }
 
-// BuildArgs constructs the rsync command arguments.
+// BuildArgs constructs the rsync command arguments for a current GNU rsync.
 // Exported for testing command construction without running rsync.
 func BuildArgs(conn *host.Connection, localDir string, cfg config.SyncConfig) ([]string, error) {
+	return buildArgs(ModernRsync, conn, localDir, cfg)
+}
+
+// buildArgs constructs the rsync command arguments, using only flags the
+// given rsync supports (see capabilityMatrix).
+func buildArgs(rsync Rsync, conn *host.Connection, localDir string, cfg config.SyncConfig) ([]string, error) {
+	return buildShardArgs(rsync, conn, localDir, cfg, nil)
+}
+
+// buildShardArgs constructs the rsync arguments with extra filter rules that
+// limit the transfer to one shard of the project (see shardFilters). They go
+// after the configured excludes so those still win.
+func buildShardArgs(rsync Rsync, conn *host.Connection, localDir string, cfg config.SyncConfig, filters []string) ([]string, error) {
 	if conn == nil {
 		return nil, errors.New(errors.ErrSync,
 			"No connection provided",
@@ -156,19 +230,35 @@ func BuildArgs(conn *host.Connection, localDir string, cfg config.SyncConfig) ([
 	args := []string{
 		"-az",      // archive mode, compress
 		"--delete", // delete files on remote not in source
-		"--force",  // force deletion of non-empty dirs
+	}
+	if rsync.Supports(FeatureForce) {
+		args = append(args, "--force") // force deletion of non-empty dirs
 	}
 
 	// Use SSH with ControlMaster for connection reuse and user's SSH config
 	// for ProxyCommand, IdentityFile, and other host-specific settings.
-	args = append(args, "-e", buildSSHCmd())
+	args = append(args, "-e", buildSSHCmd(conn.Host))
+	args = append(args, rsyncPathArgs(conn)...)
 
 	// Add progress info flag for parsing
-	args = append(args, "--info=progress2")
+	if rsync.Supports(FeatureProgress2) {
+		args = append(args, "--info=progress2")
+	}
+
+	// Keep partially transferred files so an interrupted sync resumes
+	if rsync.Supports(FeaturePartialDir) {
+		args = append(args, "--partial-dir="+PartialDir)
+	}
 
 	// Add preserve patterns as filters (P = protect from deletion)
 	// These go BEFORE excludes so they protect paths that might otherwise be deleted
 	for _, pattern := range cfg.Preserve {
+		if !rsync.Supports(FeatureFilter) {
+			// Excluded paths are also left alone by --delete, at the cost
+			// of not syncing local copies of them
+			args = append(args, fmt.Sprintf("--exclude=%s", pattern))
+			continue
+		}
 		// Handle both simple patterns and patterns with subdirs
 		args = append(args, fmt.Sprintf("--filter=P %s", pattern))
 		// Also protect the pattern in any subdirectory
@@ -184,10 +274,17 @@ func BuildArgs(conn *host.Connection, localDir string, cfg config.SyncConfig) ([
 
 	// Respect .gitignore patterns as additional excludes. Placed after explicit
 	// preserves and excludes so .rr.yaml rules take precedence (rsync is first-match-wins).
+	// Without filter rules only the top-level .gitignore can be read.
 	if cfg.RespectGitignore {
-		args = append(args, "--filter=:- .gitignore")
+		if rsync.Supports(FeatureFilter) {
+			args = append(args, "--filter=:- .gitignore")
+		} else if _, err := os.Stat(localDir + ".gitignore"); err == nil {
+			args = append(args, "--exclude-from="+localDir+".gitignore")
+		}
 	}
 
+	args = append(args, filters...)
+
 	// Add custom flags from config
 	args = append(args, cfg.Flags...)
 
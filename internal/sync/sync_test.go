@@ -32,6 +32,21 @@ func TestFindRsync(t *testing.T) {
 	}
 }
 
+func TestRsyncCommand_CLocale(t *testing.T) {
+	t.Setenv("LC_ALL", "de_DE.UTF-8")
+	cmd := rsyncCommand("rsync", "--version")
+
+	assert.Equal(t, []string{"rsync", "--version"}, cmd.Args)
+	// The last assignment of a variable wins
+	var lcAll string
+	for _, kv := range cmd.Env {
+		if v, ok := strings.CutPrefix(kv, "LC_ALL="); ok {
+			lcAll = v
+		}
+	}
+	assert.Equal(t, "C", lcAll)
+}
+
 func TestVersion(t *testing.T) {
 	version, err := Version()
 
@@ -421,7 +436,7 @@ func TestBuildSSHCmd(t *testing.T) {
 
 	t.Run("includes ControlMaster options", func(t *testing.T) {
 		SSHConfigFile = ""
-		cmd := buildSSHCmd()
+		cmd := buildSSHCmd(config.Host{})
 		assert.Contains(t, cmd, "ControlMaster=auto")
 		assert.Contains(t, cmd, "ControlPath=")
 		assert.Contains(t, cmd, "ControlPersist=60")
@@ -430,17 +445,40 @@ func TestBuildSSHCmd(t *testing.T) {
 
 	t.Run("custom config file", func(t *testing.T) {
 		SSHConfigFile = "/tmp/custom-ssh-config"
-		cmd := buildSSHCmd()
+		cmd := buildSSHCmd(config.Host{})
 		assert.Contains(t, cmd, `-F "/tmp/custom-ssh-config"`)
 	})
 
+	t.Run("identity file", func(t *testing.T) {
+		SSHConfigFile = ""
+		cmd := buildSSHCmd(config.Host{IdentityFile: "/keys/work_ed25519"})
+		assert.Contains(t, cmd, `-i "/keys/work_ed25519"`)
+
+		home, err := os.UserHomeDir()
+		require.NoError(t, err)
+		cmd = buildSSHCmd(config.Host{IdentityFile: "~/.ssh/work_ed25519"})
+		assert.Contains(t, cmd, fmt.Sprintf("-i %q", filepath.Join(home, ".ssh", "work_ed25519")))
+	})
+
+	t.Run("ssh options", func(t *testing.T) {
+		SSHConfigFile = ""
+		cmd := buildSSHCmd(config.Host{SSHOptions: config.SSHOptions{
+			Compression:         true,
+			Ciphers:             []string{"aes128-gcm@openssh.com", "chacha20-poly1305@openssh.com"},
+			ServerAliveInterval: 30 * time.Second,
+			ConnectTimeout:      1500 * time.Millisecond,
+		}})
+		assert.True(t, strings.HasSuffix(cmd, " -o Compression=yes -o Ciphers=aes128-gcm@openssh.com,chacha20-poly1305@openssh.com"+
+			" -o ServerAliveInterval=30 -o ConnectTimeout=2"), cmd)
+	})
+
 	t.Run("default config when file exists", func(t *testing.T) {
 		SSHConfigFile = ""
 		home, err := os.UserHomeDir()
 		require.NoError(t, err)
 		defaultConfig := filepath.Join(home, ".ssh", "config")
 		if _, err := os.Stat(defaultConfig); err == nil {
-			cmd := buildSSHCmd()
+			cmd := buildSSHCmd(config.Host{})
 			assert.Contains(t, cmd, "-F")
 			assert.Contains(t, cmd, defaultConfig)
 		} else {
@@ -484,6 +522,7 @@ func TestParseProgress(t *testing.T) {
 				Speed:            "1.23MB/s",
 				TimeRemaining:    "0:00:01",
 				FileCount:        1,
+				FilesToCheck:     99,
 				TotalFiles:       100,
 			},
 		},
@@ -496,6 +535,7 @@ func TestParseProgress(t *testing.T) {
 				Speed:            "2.50MB/s",
 				TimeRemaining:    "0:00:30",
 				FileCount:        25,
+				FilesToCheck:     50,
 				TotalFiles:       200,
 			},
 		},
@@ -538,6 +578,7 @@ func TestParseProgress(t *testing.T) {
 			assert.Equal(t, tt.expected.Speed, result.Speed)
 			assert.Equal(t, tt.expected.TimeRemaining, result.TimeRemaining)
 			assert.Equal(t, tt.expected.FileCount, result.FileCount)
+			assert.Equal(t, tt.expected.FilesToCheck, result.FilesToCheck)
 			assert.Equal(t, tt.expected.TotalFiles, result.TotalFiles)
 		})
 	}
@@ -0,0 +1,140 @@
+package sync
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+)
+
+// Watcher reports file changes under a project directory, skipping paths that
+// match the sync exclude patterns (so .git/ or node_modules/ churn doesn't
+// trigger pushes).
+type Watcher struct {
+	root     string
+	excludes []string
+	fsw      *fsnotify.Watcher
+}
+
+// NewWatcher creates a recursive watcher rooted at root.
+func NewWatcher(root string, cfg config.SyncConfig) (*Watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, errors.WrapWithCode(err, errors.ErrSync,
+			"Couldn't start the file watcher",
+			"Your OS may be out of file watches. On Linux, raise fs.inotify.max_user_watches.")
+	}
+
+	w := &Watcher{
+		root:     filepath.Clean(root),
+		excludes: cfg.Exclude,
+		fsw:      fsw,
+	}
+	if err := w.addTree(w.root); err != nil {
+		fsw.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Run delivers changes to onChange until ctx is canceled. New directories are
+// watched as they appear.
+func (w *Watcher) Run(ctx context.Context, onChange func()) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-w.fsw.Events:
+			if !ok {
+				return nil
+			}
+			if w.excluded(event.Name) {
+				continue
+			}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					_ = w.addTree(event.Name)
+				}
+			}
+			// Chmod alone doesn't change content rsync cares about (-a does carry
+			// perms, but editors touch-chmod constantly); skip it to avoid noise.
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			onChange()
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return nil
+			}
+			return errors.WrapWithCode(err, errors.ErrSync,
+				"File watcher failed",
+				"Restart 'rr sync --daemon'. On Linux, check fs.inotify.max_user_watches.")
+		}
+	}
+}
+
+// Close stops watching.
+func (w *Watcher) Close() error {
+	return w.fsw.Close()
+}
+
+// addTree watches dir and every non-excluded directory beneath it.
+// fsnotify isn't recursive, so each directory needs its own watch.
+func (w *Watcher) addTree(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Directory vanished or is unreadable; skip it rather than abort
+			if d != nil && d.IsDir() && path != dir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != w.root && w.excluded(path) {
+			return filepath.SkipDir
+		}
+		if err := w.fsw.Add(path); err != nil {
+			return errors.WrapWithCode(err, errors.ErrSync,
+				"Couldn't watch "+path,
+				"Your OS may be out of file watches. On Linux, raise fs.inotify.max_user_watches.")
+		}
+		return nil
+	})
+}
+
+// excluded reports whether path (or any parent below root) matches an exclude
+// pattern.
+func (w *Watcher) excluded(path string) bool {
+	return excludedPath(w.root, w.excludes, path)
+}
+
+// excludedPath reports whether path (or any parent below root) matches an
+// exclude pattern. Patterns are matched against each path component the same
+// way rsync matches unanchored patterns, which covers the defaults like
+// ".git/" and "*.pyc".
+func excludedPath(root string, excludes []string, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return false
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	for _, pattern := range excludes {
+		pattern = strings.TrimSuffix(strings.TrimPrefix(pattern, "/"), "/")
+		if pattern == "" {
+			continue
+		}
+		for _, part := range parts {
+			if ok, _ := filepath.Match(pattern, part); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package sync
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatcher_Excluded(t *testing.T) {
+	root := t.TempDir()
+	w := &Watcher{
+		root:     root,
+		excludes: []string{".git/", "node_modules/", "*.pyc", "/build/"},
+	}
+
+	tests := []struct {
+		path   string
+		expect bool
+	}{
+		{".", false},
+		{"main.go", false},
+		{"src/app.py", false},
+		{".git", true},
+		{".git/HEAD", true},
+		{"web/node_modules/react/index.js", true},
+		{"pkg/__init__.pyc", true},
+		{"build", true},
+		{"builder/main.go", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.expect, w.excluded(filepath.Join(root, tt.path)))
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// accessible is whether accessible output is on. See SetAccessible.
+var accessible bool
+
+// symbolSet pairs each status symbol with its glyph and its plain ASCII
+// stand-in for accessible output.
+var symbolSet = []struct {
+	symbol       *string
+	glyph, ascii string
+}{
+	{&SymbolSuccess, SymbolSuccess, "[ok]"},
+	{&SymbolFail, SymbolFail, "[fail]"},
+	{&SymbolPending, SymbolPending, "[waiting]"},
+	{&SymbolSyncing, SymbolSyncing, "[syncing]"},
+	{&SymbolProgress, SymbolProgress, "[running]"},
+	{&SymbolComplete, SymbolComplete, "[done]"},
+	{&SymbolSkipped, SymbolSkipped, "[skipped]"},
+	{&SymbolConnected, SymbolConnected, "[up]"},
+	{&SymbolUnreachable, SymbolUnreachable, "[down]"},
+	{&SymbolSlow, SymbolSlow, "[slow]"},
+	{&SymbolWarning, SymbolWarning, "[warning]"},
+}
+
+// SetAccessible turns accessible output on or off. When on, status symbols
+// are plain ASCII words, and spinners, progress bars, and the parallel task
+// list print a new line for each change instead of animating in place, so
+// screen readers can follow along.
+func SetAccessible(on bool) {
+	accessible = on
+	for _, s := range symbolSet {
+		if on {
+			*s.symbol = s.ascii
+		} else {
+			*s.symbol = s.glyph
+		}
+	}
+}
+
+// Accessible reports whether accessible output is on.
+func Accessible() bool {
+	return accessible
+}
+
+// clearLine erases a line drawn in place, like a spinner's, leaving the
+// cursor at its start. Accessible output never draws in place, so there's
+// nothing to clear.
+func clearLine(w io.Writer, width int) {
+	if accessible {
+		return
+	}
+	fmt.Fprint(w, "\r"+strings.Repeat(" ", width)+"\r")
+}
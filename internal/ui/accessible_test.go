@@ -0,0 +1,106 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// useAccessible turns accessible output on for the rest of the test.
+func useAccessible(t *testing.T) {
+	t.Helper()
+	SetAccessible(true)
+	t.Cleanup(func() { SetAccessible(false) })
+}
+
+func TestSetAccessible_SwapsSymbols(t *testing.T) {
+	useAccessible(t)
+	assert.Equal(t, "[ok]", SymbolSuccess)
+	assert.Equal(t, "[fail]", SymbolFail)
+	assert.Equal(t, "[warning]", SymbolWarning)
+	assert.True(t, Accessible())
+
+	SetAccessible(false)
+	assert.Equal(t, "◉", SymbolSuccess)
+	assert.Equal(t, "✕", SymbolFail)
+	assert.Equal(t, "⚠", SymbolWarning)
+	assert.False(t, Accessible())
+}
+
+func TestSpinner_Accessible(t *testing.T) {
+	useAccessible(t)
+
+	var out strings.Builder
+	s := NewSpinner("Connecting")
+	s.SetOutput(func(str string) { out.WriteString(str) })
+
+	// Restarting, like the connection display does between attempts,
+	// doesn't announce the label again
+	s.Start()
+	s.Stop()
+	s.Start()
+	s.Success()
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	assert.Len(t, lines, 2)
+	assert.Equal(t, "Connecting...", lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "[done] Connecting "), lines[1])
+	assert.NotContains(t, out.String(), "\r")
+}
+
+func TestInlineProgress_AccessibleAnnouncesQuarters(t *testing.T) {
+	useAccessible(t)
+
+	var buf bytes.Buffer
+	p := NewInlineProgress("Syncing", &buf)
+
+	p.mu.Lock()
+	p.percent = 0.3
+	p.announceLocked()
+	p.percent = 0.4
+	p.announceLocked()
+	p.percent = 0.8
+	p.announceLocked()
+	p.percent = 1
+	p.announceLocked()
+	p.mu.Unlock()
+	p.renderFinal(true)
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Equal(t, []string{"Syncing: 25%", "Syncing: 75%"}, lines[:2])
+	assert.True(t, strings.HasPrefix(lines[2], "[done] Syncing "), lines[2])
+	assert.NotContains(t, buf.String(), "\r")
+}
+
+func TestParallelProgress_AccessiblePrintsEachChange(t *testing.T) {
+	useAccessible(t)
+
+	var buf bytes.Buffer
+	p := NewParallelProgress(true)
+	p.SetWriter(&buf)
+	p.InitTasks([]TaskInit{{Name: "build", Index: 0}, {Name: "test", Index: 1}})
+	p.Start()
+
+	p.TaskSyncing("build", 0, "mini")
+	p.TaskExecuting("build", 0)
+	p.Warn("config changed")
+	p.TaskCompleted("build", 0, false)
+	p.Stop()
+
+	assert.Equal(t, "[syncing] build [mini]\n[running] build [mini]\n[warning] config changed\n[fail] build [mini]\n", buf.String())
+}
+
+func TestPhaseDisplay_Accessible(t *testing.T) {
+	useAccessible(t)
+
+	var buf bytes.Buffer
+	pd := NewPhaseDisplay(&buf)
+	pd.RenderProgress("Syncing")
+	pd.RenderSkipped("Syncing", "no changes")
+	pd.Divider()
+
+	assert.Equal(t, "[running] Syncing...\n[skipped] Syncing (no changes)\n\n", buf.String())
+	assert.Empty(t, FormatDivider(10))
+}
@@ -3,26 +3,112 @@ package ui
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/muesli/termenv"
 )
 
+// Color modes, for --color.
+const (
+	ColorAuto   = "auto"   // Color on a terminal, following NO_COLOR and CLICOLOR_FORCE
+	ColorAlways = "always" // Color even when piped
+	ColorNever  = "never"
+)
+
+// colorMode is when output is colored. See SetColorMode.
+var colorMode = ColorAuto
+
+// stderrRenderer styles text written to stderr, which can be piped when
+// stdout isn't, or the other way around.
+var stderrRenderer = lipgloss.NewRenderer(os.Stderr)
+
+// SetColorMode sets when EnableColors colors output: ColorAuto, ColorAlways,
+// or ColorNever.
+func SetColorMode(mode string) {
+	colorMode = mode
+}
+
+// ColorsWanted reports whether output written to f should be colored. In
+// auto mode that's when f is a terminal, except that NO_COLOR turns color
+// off, CLICOLOR_FORCE turns it on even when f is piped, and CLICOLOR=0 or
+// TERM=dumb turn it off.
+func ColorsWanted(f *os.File) bool {
+	switch colorMode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if force := os.Getenv("CLICOLOR_FORCE"); force != "" && force != "0" {
+		return true
+	}
+	if os.Getenv("CLICOLOR") == "0" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return IsTerminal(f)
+}
+
 // DisableColors disables all color output by setting the color profile to Ascii.
 // This should be called early in the program if --no-color flag is set.
 func DisableColors() {
 	lipgloss.SetColorProfile(termenv.Ascii)
+	stderrRenderer.SetColorProfile(termenv.Ascii)
 }
 
-// EnableColors re-enables color output by detecting the terminal's color profile.
-// Used by commands like 'monitor' that are always interactive TUI.
+// EnableColors turns color output on, for stdout and stderr each, if
+// ColorsWanted says so. Used by commands like 'monitor' that are always
+// interactive TUI.
 func EnableColors() {
-	lipgloss.SetColorProfile(termenv.ColorProfile())
+	lipgloss.SetColorProfile(colorProfile(os.Stdout))
+	stderrRenderer.SetColorProfile(colorProfile(os.Stderr))
+}
+
+// colorProfile returns the colors f can show, or Ascii if it shouldn't be
+// colored.
+func colorProfile(f *os.File) termenv.Profile {
+	if !ColorsWanted(f) {
+		return termenv.Ascii
+	}
+	// Unsafe skips the terminal check, since color may be forced onto a pipe
+	profile := termenv.NewOutput(f, termenv.WithUnsafe()).ColorProfile()
+	if profile == termenv.Ascii {
+		profile = termenv.ANSI
+	}
+	return profile
+}
+
+// StderrStyle returns a style for text written to stderr, colored only when
+// stderr should be.
+func StderrStyle() lipgloss.Style {
+	return stderrRenderer.NewStyle()
+}
+
+// Unicode reports whether to draw with Unicode beyond simple symbols, like
+// braille graphs and spinners. It's off in accessible mode, on the Linux
+// console, whose font lacks them, and under a locale that isn't UTF-8.
+func Unicode() bool {
+	if accessible || os.Getenv("TERM") == "linux" {
+		return false
+	}
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if v := strings.ToLower(os.Getenv(name)); v != "" {
+			return strings.Contains(v, "utf-8") || strings.Contains(v, "utf8")
+		}
+	}
+	return true
 }
 
+// The active color palette. These start out as the synthwave theme and are
+// reassigned by ApplyTheme, so read them at render time rather than caching
+// them in package-level styles (or register an OnThemeChange hook).
+
 // Electric Synthwave color palette - Gen Z dopamine-inducing neons
 // Primary accent colors
-const (
+var (
 	ColorNeonPink   lipgloss.Color = "#FF2E97" // Primary accent, selected states
 	ColorNeonCyan   lipgloss.Color = "#00FFFF" // Secondary accent, info, values
 	ColorNeonPurple lipgloss.Color = "#BF40FF" // Tertiary, gradient midpoint
@@ -32,14 +118,14 @@ const (
 )
 
 // Background colors (glassmorphism-inspired)
-const (
+var (
 	ColorDeepVoid    lipgloss.Color = "#0A0A0F" // Main background
 	ColorDarkSurface lipgloss.Color = "#12121A" // Card backgrounds
 	ColorGlassBorder lipgloss.Color = "#2A2A4A" // Borders (purple tint)
 )
 
 // Semantic colors for status indication
-const (
+var (
 	ColorSuccess lipgloss.Color = "#39FF14" // Neon Green
 	ColorError   lipgloss.Color = "#FF0055" // Hot Red-Pink
 	ColorWarning lipgloss.Color = "#FFAA00" // Electric Amber
@@ -47,7 +133,7 @@ const (
 )
 
 // Text colors for content hierarchy
-const (
+var (
 	ColorPrimary   lipgloss.Color = "#FFFFFF" // Pure white
 	ColorSecondary lipgloss.Color = "#B4B4D0" // Lavender gray
 	ColorMuted     lipgloss.Color = "#6B6B8D" // Purple-gray
@@ -89,10 +175,10 @@ func MutedStyle() lipgloss.Style {
 }
 
 // SymbolWarning is the warning symbol (⚠)
-const SymbolWarning = "⚠"
+var SymbolWarning = "⚠"
 
 // PrintWarning prints a styled warning message to stderr.
 func PrintWarning(message string) {
-	style := lipgloss.NewStyle().Foreground(ColorWarning)
+	style := StderrStyle().Foreground(ColorWarning)
 	fmt.Fprintf(os.Stderr, "%s %s\n", style.Render(SymbolWarning), style.Render(message))
 }
@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clearColorEnv unsets the variables ColorsWanted and Unicode read, for the
+// rest of the test.
+func clearColorEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{"NO_COLOR", "CLICOLOR_FORCE", "CLICOLOR", "TERM", "LC_ALL", "LC_CTYPE", "LANG"} {
+		t.Setenv(name, "")
+	}
+}
+
+func TestColorsWanted(t *testing.T) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		r.Close()
+		w.Close()
+		SetColorMode(ColorAuto)
+	})
+
+	tests := []struct {
+		name string
+		mode string
+		env  map[string]string
+		want bool
+	}{
+		{name: "auto on a pipe", mode: ColorAuto, want: false},
+		{name: "always on a pipe", mode: ColorAlways, want: true},
+		{name: "never", mode: ColorNever, env: map[string]string{"CLICOLOR_FORCE": "1"}, want: false},
+		{name: "CLICOLOR_FORCE colors a pipe", mode: ColorAuto, env: map[string]string{"CLICOLOR_FORCE": "1"}, want: true},
+		{name: "CLICOLOR_FORCE=0 is ignored", mode: ColorAuto, env: map[string]string{"CLICOLOR_FORCE": "0"}, want: false},
+		{name: "NO_COLOR beats CLICOLOR_FORCE", mode: ColorAuto, env: map[string]string{"NO_COLOR": "1", "CLICOLOR_FORCE": "1"}, want: false},
+		{name: "always beats NO_COLOR", mode: ColorAlways, env: map[string]string{"NO_COLOR": "1"}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearColorEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			SetColorMode(tt.mode)
+			assert.Equal(t, tt.want, ColorsWanted(w))
+		})
+	}
+}
+
+func TestColorsWanted_TerminalOptOuts(t *testing.T) {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		t.Skip("no terminal to test with")
+	}
+	t.Cleanup(func() { tty.Close() })
+	clearColorEnv(t)
+
+	assert.True(t, ColorsWanted(tty))
+	t.Setenv("CLICOLOR", "0")
+	assert.False(t, ColorsWanted(tty))
+	t.Setenv("CLICOLOR", "")
+	t.Setenv("TERM", "dumb")
+	assert.False(t, ColorsWanted(tty))
+}
+
+func TestUnicode(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want bool
+	}{
+		{name: "no locale", want: true},
+		{name: "UTF-8 locale", env: map[string]string{"LANG": "en_US.UTF-8"}, want: true},
+		{name: "utf8 spelling", env: map[string]string{"LANG": "C.utf8"}, want: true},
+		{name: "C locale", env: map[string]string{"LANG": "C"}, want: false},
+		{name: "LC_ALL wins over LANG", env: map[string]string{"LC_ALL": "POSIX", "LANG": "en_US.UTF-8"}, want: false},
+		{name: "LC_CTYPE wins over LANG", env: map[string]string{"LC_CTYPE": "en_US.UTF-8", "LANG": "C"}, want: true},
+		{name: "Linux console", env: map[string]string{"TERM": "linux", "LANG": "en_US.UTF-8"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearColorEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			assert.Equal(t, tt.want, Unicode())
+		})
+	}
+}
+
+func TestUnicode_OffWhenAccessible(t *testing.T) {
+	clearColorEnv(t)
+	useAccessible(t)
+	assert.False(t, Unicode())
+}
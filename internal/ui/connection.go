@@ -216,7 +216,7 @@ func (cd *ConnectionDisplay) Success(hostName, alias string) {
 	// Clear the spinner line if there are no attempts shown (quiet mode)
 	if cd.quiet && len(cd.attempts) > 0 {
 		// Clear line (carriage return + spaces + carriage return)
-		fmt.Fprint(cd.w, "\r"+strings.Repeat(" ", 80)+"\r")
+		clearLine(cd.w, 80)
 	}
 
 	// Render final success line
@@ -243,7 +243,7 @@ func (cd *ConnectionDisplay) SuccessLocal() {
 	totalDuration := time.Since(cd.started)
 
 	// Clear spinner line
-	fmt.Fprint(cd.w, "\r"+strings.Repeat(" ", 80)+"\r")
+	clearLine(cd.w, 80)
 
 	// Render local fallback message
 	symbolStyle := lipgloss.NewStyle().Foreground(ColorWarning)
@@ -267,7 +267,7 @@ func (cd *ConnectionDisplay) Fail(errMsg string) {
 	totalDuration := time.Since(cd.started)
 
 	// Clear spinner line
-	fmt.Fprint(cd.w, "\r"+strings.Repeat(" ", 80)+"\r")
+	clearLine(cd.w, 80)
 
 	// Render failure line
 	symbolStyle := lipgloss.NewStyle().Foreground(ColorError)
@@ -24,6 +24,9 @@
 //	ColorMuted     (gray)   - Secondary text, timing info
 //	ColorSecondary (blue)   - In-progress indicators
 //
+// The palette comes from the active Theme (synthwave by default). ApplyTheme
+// switches it, e.g. to the light or ansi theme from the user's config.
+//
 // Use DisableColors() to switch to monochrome output (for --no-color flag).
 //
 // # Symbols
@@ -37,6 +40,10 @@
 //	SymbolComplete (filled)     - Task done (alternative)
 //	SymbolSkipped  (slashed)    - Task skipped
 //
+// SetAccessible swaps the symbols for plain ASCII words like "[ok]", and
+// makes spinners and progress displays print a line per change instead of
+// animating in place.
+//
 // # Spinner Usage
 //
 // The Spinner type provides an animated indicator for operations:
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
@@ -21,15 +23,57 @@ type HostInfo struct {
 	Tags []string // Tags for filtering
 }
 
+// HostProbe checks whether the named host is reachable and returns its
+// connect latency.
+type HostProbe func(name string) (time.Duration, error)
+
+// hostProbeState is where a host's probe stands in the picker.
+type hostProbeState int
+
+const (
+	probeNone        hostProbeState = iota // Not probing hosts
+	probePending                           // Probe still running
+	probeReachable                         // Probe connected
+	probeUnreachable                       // Probe failed
+)
+
+// hostProbeMsg reports a finished probe to the picker.
+type hostProbeMsg struct {
+	name    string
+	latency time.Duration
+	err     error
+}
+
 // hostItem implements list.Item for the Bubbles list component.
 type hostItem struct {
-	host HostInfo
+	host    HostInfo
+	probe   hostProbeState
+	latency time.Duration
 }
 
 func (i hostItem) Title() string {
+	switch i.probe {
+	case probePending:
+		return i.host.Name + "  " + MutedStyle().Render(SymbolPending+" probing")
+	case probeReachable:
+		return i.host.Name + "  " + SuccessStyle().Render(SymbolSuccess+" "+formatProbeLatency(i.latency))
+	case probeUnreachable:
+		return i.host.Name + "  " + ErrorStyle().Render(SymbolFail+" unreachable")
+	}
 	return i.host.Name
 }
 
+// formatProbeLatency formats a probe latency: "850µs", "42ms", "1.3s".
+func formatProbeLatency(d time.Duration) string {
+	switch {
+	case d < time.Millisecond:
+		return d.Round(time.Microsecond).String()
+	case d < time.Second:
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
+
 func (i hostItem) Description() string {
 	var parts []string
 
@@ -71,6 +115,11 @@ type HostPickerModel struct {
 	quitting bool
 	width    int
 	height   int
+
+	// With a probe, every host is probed while the picker is open, and the
+	// list shows each one's status and keeps reachable hosts at the top.
+	probe   HostProbe
+	results map[string]hostProbeMsg
 }
 
 // hostPickerKeyMap defines key bindings for the host picker.
@@ -123,14 +172,94 @@ func NewHostPickerModel(hosts []HostInfo) HostPickerModel {
 	}
 }
 
-// Init implements tea.Model.
+// WithProbe returns the model set to probe every host when it starts, using
+// probe.
+func (m HostPickerModel) WithProbe(probe HostProbe) HostPickerModel {
+	m.probe = probe
+	m.results = make(map[string]hostProbeMsg, len(m.hosts))
+	m.list.SetItems(m.items())
+	return m
+}
+
+// Init implements tea.Model. With a probe, it starts probing all hosts at
+// once.
 func (m HostPickerModel) Init() tea.Cmd {
-	return nil
+	if m.probe == nil {
+		return nil
+	}
+	cmds := make([]tea.Cmd, len(m.hosts))
+	for i, h := range m.hosts {
+		name, probe := h.Name, m.probe
+		cmds[i] = func() tea.Msg {
+			latency, err := probe(name)
+			return hostProbeMsg{name: name, latency: latency, err: err}
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// items builds the list items from the hosts and their probe results:
+// reachable hosts first, then ones still being probed, then unreachable
+// ones, each group in the hosts' order.
+func (m HostPickerModel) items() []list.Item {
+	items := make([]hostItem, len(m.hosts))
+	for i, h := range m.hosts {
+		items[i] = hostItem{host: h}
+		if m.probe == nil {
+			continue
+		}
+		result, done := m.results[h.Name]
+		switch {
+		case !done:
+			items[i].probe = probePending
+		case result.err != nil:
+			items[i].probe = probeUnreachable
+		default:
+			items[i].probe = probeReachable
+			items[i].latency = result.latency
+		}
+	}
+
+	rank := map[hostProbeState]int{probeReachable: 0, probePending: 1, probeUnreachable: 2}
+	sort.SliceStable(items, func(a, b int) bool {
+		return rank[items[a].probe] < rank[items[b].probe]
+	})
+
+	listItems := make([]list.Item, len(items))
+	for i, item := range items {
+		listItems[i] = item
+	}
+	return listItems
+}
+
+// applyProbe records a probe result and re-sorts the list, keeping the
+// cursor on the host it was on.
+func (m HostPickerModel) applyProbe(msg hostProbeMsg) (HostPickerModel, tea.Cmd) {
+	m.results[msg.name] = msg
+
+	current := ""
+	if item, ok := m.list.SelectedItem().(hostItem); ok {
+		current = item.host.Name
+	}
+	cmd := m.list.SetItems(m.items())
+	for i, item := range m.list.VisibleItems() {
+		if item.(hostItem).host.Name == current {
+			m.list.Select(i)
+			break
+		}
+	}
+	return m, cmd
 }
 
 // Update implements tea.Model.
 func (m HostPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case hostProbeMsg:
+		if m.probe != nil {
+			return m.applyProbe(msg)
+		}
+		return m, nil
+
 	case tea.KeyMsg:
 		switch {
 		case key.Matches(msg, hostPickerKeys.Enter):
@@ -175,8 +304,20 @@ func PickHost(hosts []HostInfo) (*HostInfo, error) {
 	return PickHostWithOutput(hosts, os.Stdout, os.Stdin)
 }
 
+// PickHostWithProbe displays the host picker, probing every host while it's
+// open to show whether it's reachable and how fast it connects. Reachable
+// hosts move to the top as their probes finish.
+func PickHostWithProbe(hosts []HostInfo, probe HostProbe) (*HostInfo, error) {
+	return pickHost(hosts, probe, os.Stdout, os.Stdin)
+}
+
 // PickHostWithOutput displays the host picker using custom I/O.
 func PickHostWithOutput(hosts []HostInfo, output io.Writer, input io.Reader) (*HostInfo, error) {
+	return pickHost(hosts, nil, output, input)
+}
+
+// pickHost runs the host picker, probing hosts if probe is set.
+func pickHost(hosts []HostInfo, probe HostProbe, output io.Writer, input io.Reader) (*HostInfo, error) {
 	if len(hosts) == 0 {
 		return nil, errors.New(errors.ErrConfig, "No hosts to pick from", "Add hosts to your .rr.yaml or run 'rr init' to set one up.")
 	}
@@ -187,6 +328,9 @@ func PickHostWithOutput(hosts []HostInfo, output io.Writer, input io.Reader) (*H
 	}
 
 	model := NewHostPickerModel(hosts)
+	if probe != nil {
+		model = model.WithProbe(probe)
+	}
 
 	p := tea.NewProgram(
 		model,
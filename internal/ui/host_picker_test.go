@@ -1,9 +1,12 @@
 package ui
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHostItem(t *testing.T) {
@@ -95,3 +98,74 @@ func TestHostPickerModelSelected(t *testing.T) {
 	assert.NotNil(t, selected)
 	assert.Equal(t, "host1", selected.Name)
 }
+
+func TestHostItemProbeStatus(t *testing.T) {
+	host := HostInfo{Name: "gpu-box", SSH: []string{"gpu"}}
+
+	assert.Contains(t, hostItem{host: host, probe: probePending}.Title(), "probing")
+	assert.Contains(t, hostItem{host: host, probe: probeReachable, latency: 42 * time.Millisecond}.Title(), "42ms")
+	assert.Contains(t, hostItem{host: host, probe: probeUnreachable}.Title(), "unreachable")
+
+	// The name still filters on its own
+	assert.NotContains(t, hostItem{host: host, probe: probePending}.FilterValue(), "probing")
+}
+
+func TestFormatProbeLatency(t *testing.T) {
+	assert.Equal(t, "850µs", formatProbeLatency(850*time.Microsecond))
+	assert.Equal(t, "42ms", formatProbeLatency(42*time.Millisecond+300*time.Microsecond))
+	assert.Equal(t, "1.3s", formatProbeLatency(1300*time.Millisecond))
+}
+
+func pickerHostNames(m HostPickerModel) []string {
+	var names []string
+	for _, item := range m.list.Items() {
+		names = append(names, item.(hostItem).host.Name)
+	}
+	return names
+}
+
+func TestHostPickerModel_Probe(t *testing.T) {
+	hosts := []HostInfo{{Name: "alpha"}, {Name: "bravo"}, {Name: "charlie"}}
+	model := NewHostPickerModel(hosts).WithProbe(func(name string) (time.Duration, error) {
+		return 0, nil
+	})
+	require.NotNil(t, model.Init(), "Init should start the probes")
+
+	for _, item := range model.list.Items() {
+		assert.Equal(t, probePending, item.(hostItem).probe)
+	}
+
+	// Reachable hosts move to the top, unreachable ones to the bottom
+	updated, _ := model.Update(hostProbeMsg{name: "alpha", err: errors.New("connection refused")})
+	model = updated.(HostPickerModel)
+	updated, _ = model.Update(hostProbeMsg{name: "charlie", latency: 12 * time.Millisecond})
+	model = updated.(HostPickerModel)
+	assert.Equal(t, []string{"charlie", "bravo", "alpha"}, pickerHostNames(model))
+
+	updated, _ = model.Update(hostProbeMsg{name: "bravo", latency: 30 * time.Millisecond})
+	model = updated.(HostPickerModel)
+	assert.Equal(t, []string{"bravo", "charlie", "alpha"}, pickerHostNames(model))
+
+	first := model.list.Items()[0].(hostItem)
+	assert.Equal(t, probeReachable, first.probe)
+	assert.Equal(t, 30*time.Millisecond, first.latency)
+}
+
+func TestHostPickerModel_ProbeKeepsCursor(t *testing.T) {
+	hosts := []HostInfo{{Name: "alpha"}, {Name: "bravo"}, {Name: "charlie"}}
+	model := NewHostPickerModel(hosts).WithProbe(func(string) (time.Duration, error) { return 0, nil })
+	model.list.Select(1) // bravo
+
+	updated, _ := model.Update(hostProbeMsg{name: "charlie", latency: time.Millisecond})
+	model = updated.(HostPickerModel)
+
+	selected, ok := model.list.SelectedItem().(hostItem)
+	require.True(t, ok)
+	assert.Equal(t, "bravo", selected.host.Name)
+}
+
+func TestHostPickerModel_NoProbe(t *testing.T) {
+	model := NewHostPickerModel([]HostInfo{{Name: "alpha"}, {Name: "bravo"}})
+	assert.Nil(t, model.Init())
+	assert.Equal(t, probeNone, model.list.Items()[0].(hostItem).probe)
+}
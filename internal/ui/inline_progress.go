@@ -13,20 +13,28 @@ import (
 // InlineProgress displays an animated progress bar for CLI use (outside Bubble Tea).
 // It uses a goroutine for animation, similar to the Spinner implementation.
 type InlineProgress struct {
-	mu           sync.Mutex
-	label        string
-	percent      float64 // Real progress from rsync
-	speed        string
-	eta          string
-	bytes        int64
-	startTime    time.Time
-	stopChan     chan struct{}
-	doneChan     chan struct{}
-	output       io.Writer
-	running      bool
-	lastRendered string
-	width        int
-	useFake      bool // Whether to use fake progress animation
+	mu        sync.Mutex
+	label     string
+	percent   float64 // Real progress from rsync
+	speed     string
+	eta       string
+	bytes     int64
+	startTime time.Time
+	stopChan  chan struct{}
+	doneChan  chan struct{}
+	output    io.Writer
+	running   bool
+	region    liveRegion // The lines the progress draws in place
+	width     int
+	useFake   bool // Whether to use fake progress animation
+
+	// Totals from a pre-scan (see SetTotals), and the files sent so far
+	totalFiles   int
+	totalBytes   int64
+	files        int
+	measureStart time.Time
+
+	quarters int // Quarters of the way done accessible output has announced
 }
 
 // NewInlineProgress creates a new inline progress display.
@@ -86,6 +94,9 @@ func (p *InlineProgress) Start() {
 	p.startTime = time.Now()
 	p.stopChan = make(chan struct{})
 	p.doneChan = make(chan struct{})
+	if accessible {
+		fmt.Fprintf(p.output, "%s...\n", p.label)
+	}
 	p.mu.Unlock()
 
 	p.render()
@@ -103,6 +114,25 @@ func (p *InlineProgress) Update(percent float64, speed, eta string, bytes int64)
 	p.bytes = bytes
 }
 
+// SetTotals sets how many files and bytes the transfer will move, from a
+// pre-scan. The progress then shows a bar for each, measured against these
+// totals instead of the percentage rsync reports, with an ETA from the
+// transfer rate since this call.
+func (p *InlineProgress) SetTotals(files int, bytes int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.totalFiles = files
+	p.totalBytes = bytes
+	p.measureStart = time.Now()
+}
+
+// setFiles updates how many files have been sent so far.
+func (p *InlineProgress) setFiles(files int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.files = files
+}
+
 // Stop halts the progress animation.
 func (p *InlineProgress) Stop() {
 	p.mu.Lock()
@@ -130,18 +160,8 @@ func (p *InlineProgress) Fail() {
 }
 
 func (p *InlineProgress) animate() {
-	ticker := time.NewTicker(100 * time.Millisecond)
-	defer ticker.Stop()
 	defer close(p.doneChan)
-
-	for {
-		select {
-		case <-p.stopChan:
-			return
-		case <-ticker.C:
-			p.render()
-		}
-	}
+	animateFrames(100*time.Millisecond, p.stopChan, p.render)
 }
 
 // effectiveProgress returns the progress to display.
@@ -162,6 +182,16 @@ func (p *InlineProgress) render() {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
+	if accessible {
+		p.announceLocked()
+		return
+	}
+
+	if p.totalFiles > 0 || p.totalBytes > 0 {
+		p.writeLinesLocked(p.totalsLinesLocked())
+		return
+	}
+
 	// Calculate effective progress (max of real and fake)
 	effectivePercent := p.effectiveProgressLocked()
 
@@ -195,7 +225,7 @@ func (p *InlineProgress) render() {
 		}
 	}
 
-	line := fmt.Sprintf("\r%s %s %s %s%s",
+	line := fmt.Sprintf("%s %s %s %s%s",
 		symbolStyle.Render(frame),
 		p.label,
 		bar,
@@ -203,14 +233,106 @@ func (p *InlineProgress) render() {
 		stats,
 	)
 
-	// Clear previous line if needed
-	if p.lastRendered != "" {
-		clearLen := len([]rune(stripAnsi(p.lastRendered)))
-		fmt.Fprintf(p.output, "\r%s\r", strings.Repeat(" ", clearLen))
+	p.writeLinesLocked([]string{line})
+}
+
+// totalsLinesLocked renders progress against the pre-scan totals: the label
+// with a bar for bytes, then a bar for files under it. Must be called with
+// lock held.
+func (p *InlineProgress) totalsLinesLocked() []string {
+	frame := spinnerFrames[int(time.Since(p.startTime).Milliseconds()/100)%len(spinnerFrames)]
+	symbolStyle := lipgloss.NewStyle().Foreground(ColorSecondary)
+	pctStyle := lipgloss.NewStyle().Foreground(ColorPrimary)
+	statsStyle := lipgloss.NewStyle().Foreground(ColorMuted)
+
+	filesDone := fraction(int64(p.files), int64(p.totalFiles))
+	bytesDone := fraction(p.bytes, p.totalBytes)
+	if p.totalBytes == 0 {
+		bytesDone = filesDone
+	}
+
+	var parts []string
+	if p.totalBytes > 0 {
+		parts = append(parts, formatBytes(p.bytes)+"/"+formatBytes(p.totalBytes))
+	}
+	if p.speed != "" {
+		parts = append(parts, p.speed)
+	}
+	if eta := p.etaLocked(); eta != "" {
+		parts = append(parts, "ETA "+eta)
 	}
 
-	fmt.Fprint(p.output, line)
-	p.lastRendered = line
+	lines := []string{fmt.Sprintf("%s %s %s %s %s",
+		symbolStyle.Render(frame),
+		p.label,
+		p.renderBarWithPercent(bytesDone),
+		pctStyle.Render(fmt.Sprintf("%3.0f%%", bytesDone*100)),
+		statsStyle.Render(strings.Join(parts, " | ")),
+	)}
+
+	if p.totalFiles > 0 {
+		// Right-align "files" under the label so the bars line up
+		lines = append(lines, fmt.Sprintf("  %*s %s %s %s",
+			len([]rune(p.label)), "files",
+			p.renderBarWithPercent(filesDone),
+			pctStyle.Render(fmt.Sprintf("%3.0f%%", filesDone*100)),
+			statsStyle.Render(fmt.Sprintf("%d/%d", min(p.files, p.totalFiles), p.totalFiles)),
+		))
+	}
+	return lines
+}
+
+// announceLocked prints a line each time the transfer passes another
+// quarter of the way, for accessible output. Only real progress counts, not
+// the fake animation. Must be called with lock held.
+func (p *InlineProgress) announceLocked() {
+	done := p.percent
+	if p.totalBytes > 0 {
+		done = fraction(p.bytes, p.totalBytes)
+	} else if p.totalFiles > 0 {
+		done = fraction(int64(p.files), int64(p.totalFiles))
+	}
+	quarters := int(done * 4)
+	if quarters <= p.quarters || quarters >= 4 {
+		return
+	}
+	p.quarters = quarters
+	fmt.Fprintf(p.output, "%s: %d%%\n", p.label, quarters*25)
+}
+
+// etaLocked estimates the time left from the bytes moved since SetTotals,
+// falling back to rsync's estimate until any have. Must be called with lock
+// held.
+func (p *InlineProgress) etaLocked() string {
+	elapsed := time.Since(p.measureStart).Seconds()
+	if p.bytes > 0 && p.totalBytes > p.bytes && elapsed > 0 {
+		secs := int(float64(p.totalBytes-p.bytes) / (float64(p.bytes) / elapsed))
+		return fmt.Sprintf("%d:%02d:%02d", secs/3600, secs/60%60, secs%60)
+	}
+	if p.eta == "0:00:00" {
+		return ""
+	}
+	return p.eta
+}
+
+// fraction returns done/total clamped to 0-1, or 0 without a total.
+func fraction(done, total int64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return ClampPercent(float64(done)/float64(total)*100) / 100
+}
+
+// writeLinesLocked replaces the last render with lines, rewriting only the
+// ones that changed. Must be called with lock held.
+func (p *InlineProgress) writeLinesLocked(lines []string) {
+	fmt.Fprint(p.output, p.region.Frame(lines))
+}
+
+// clearLocked erases the last render, leaving the cursor at the start of
+// its first line. Must be called with lock held.
+func (p *InlineProgress) clearLocked() {
+	fmt.Fprint(p.output, p.region.Clear())
 }
 
 func (p *InlineProgress) renderBarWithPercent(percent float64) string {
@@ -232,11 +354,7 @@ func (p *InlineProgress) renderFinal(success bool) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	// Clear current line
-	if p.lastRendered != "" {
-		clearLen := len([]rune(stripAnsi(p.lastRendered)))
-		fmt.Fprintf(p.output, "\r%s\r", strings.Repeat(" ", clearLen))
-	}
+	p.clearLocked()
 
 	var symbol string
 	var style lipgloss.Style
@@ -253,11 +371,15 @@ func (p *InlineProgress) renderFinal(success bool) {
 	timing := formatDuration(elapsed)
 	timingStyle := lipgloss.NewStyle().Foreground(ColorMuted)
 
-	// Include bytes transferred in final output
+	// Include bytes (and files, when pre-scanned) transferred in final output
 	var bytesInfo string
 	if p.bytes > 0 {
+		info := formatBytes(p.bytes)
+		if p.totalFiles > 0 {
+			info += fmt.Sprintf(", %d files", min(p.files, p.totalFiles))
+		}
 		bytesStyle := lipgloss.NewStyle().Foreground(ColorMuted)
-		bytesInfo = " " + bytesStyle.Render("("+formatBytes(p.bytes)+")")
+		bytesInfo = " " + bytesStyle.Render("("+info+")")
 	}
 
 	fmt.Fprintf(p.output, "%s %s%s %s\n",
@@ -317,6 +439,9 @@ func (w *ProgressWriter) Write(p []byte) (n int, err error) {
 	for _, line := range lines {
 		if prog := parseRsyncProgress(line); prog != nil {
 			w.progress.Update(prog.Percent, prog.Speed, prog.ETA, prog.BytesTransferred)
+			if prog.FilesTransferred > 0 {
+				w.progress.setFiles(prog.FilesTransferred)
+			}
 		}
 	}
 
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewInlineProgress(t *testing.T) {
@@ -223,3 +224,58 @@ func TestFakeProgressDisabled(t *testing.T) {
 
 	assert.Equal(t, 0.1, effective, "With fake disabled, should only use real progress")
 }
+
+func TestInlineProgressTotals(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewInlineProgress("Syncing files", &buf)
+	p.SetUseFakeProgress(false)
+	p.SetTotals(40, 4*1024*1024)
+
+	// rsync's own percentage is ignored once the totals are known
+	w := NewProgressWriter(p, nil)
+	_, _ = w.Write([]byte("      1,048,576  90%    1.00MB/s    0:00:01 (xfr#10, ir-chk=5/20)\n"))
+
+	p.mu.Lock()
+	lines := p.totalsLinesLocked()
+	p.mu.Unlock()
+
+	require.Len(t, lines, 2)
+	assert.Contains(t, stripAnsi(lines[0]), " 25% 1.0MB/4.0MB | 1.00MB/s")
+	assert.Contains(t, stripAnsi(lines[1]), "files")
+	assert.Contains(t, stripAnsi(lines[1]), " 25% 10/40")
+}
+
+func TestInlineProgressTotalsRenders(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewInlineProgress("Syncing files", &buf)
+	p.SetTotals(3, 0)
+	p.setFiles(3)
+
+	p.Start()
+	time.Sleep(20 * time.Millisecond)
+	p.Update(1.0, "", "", 512)
+	p.Success()
+
+	output := buf.String()
+	assert.Contains(t, output, "3/3")
+	assert.Contains(t, output, "\x1b[1A", "the two-line render is cleared before the result")
+	assert.Contains(t, output, "512B, 3 files")
+}
+
+func TestInlineProgressETA(t *testing.T) {
+	p := NewInlineProgress("Sync", &bytes.Buffer{})
+	p.totalBytes = 300
+	p.eta = "0:05:00"
+
+	assert.Equal(t, "0:05:00", p.etaLocked(), "rsync's estimate until bytes move")
+
+	p.measureStart = time.Now().Add(-10 * time.Second)
+	p.bytes = 100
+	assert.Equal(t, "0:00:20", p.etaLocked(), "200 bytes left at 10 bytes/s")
+}
+
+func TestFraction(t *testing.T) {
+	assert.Equal(t, 0.0, fraction(5, 0))
+	assert.Equal(t, 0.5, fraction(5, 10))
+	assert.Equal(t, 1.0, fraction(15, 10))
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"strings"
 	"sync"
 	"time"
 
@@ -17,9 +16,9 @@ import (
 type ParallelProgress struct {
 	mu sync.Mutex
 
-	tasks     []taskEntry // Ordered list of tasks
-	lineCount int         // Number of lines currently rendered
-	frame     int         // Current animation frame
+	tasks  []taskEntry // Ordered list of tasks
+	region liveRegion  // The task lines currently rendered
+	frame  int         // Current animation frame
 
 	running  bool
 	stopChan chan struct{}
@@ -64,6 +63,7 @@ const (
 func NewParallelProgress(isTTY bool) *ParallelProgress {
 	return &ParallelProgress{
 		tasks:    make([]taskEntry, 0),
+		region:   liveRegion{newlines: true},
 		output:   os.Stdout,
 		isTTY:    isTTY,
 		stopChan: make(chan struct{}),
@@ -157,6 +157,7 @@ func (p *ParallelProgress) TaskSyncing(name string, index int, host string) {
 			p.tasks[i].Host = host
 			p.tasks[i].StartTime = time.Now()
 			p.renderLocked()
+			p.announceLocked(p.tasks[i])
 			return
 		}
 	}
@@ -172,6 +173,7 @@ func (p *ParallelProgress) TaskSyncing(name string, index int, host string) {
 
 	// Immediate render for responsiveness
 	p.renderLocked()
+	p.announceLocked(p.tasks[len(p.tasks)-1])
 }
 
 // TaskExecuting transitions a task from syncing to running (command execution started).
@@ -183,6 +185,7 @@ func (p *ParallelProgress) TaskExecuting(name string, index int) {
 		if p.tasks[i].Index == index {
 			p.tasks[i].Status = TaskStatusRunning
 			p.renderLocked()
+			p.announceLocked(p.tasks[i])
 			return
 		}
 	}
@@ -207,6 +210,7 @@ func (p *ParallelProgress) TaskCompleted(name string, index int, success bool) {
 			} else {
 				p.tasks[i].Status = TaskStatusFailed
 			}
+			p.announceLocked(p.tasks[i])
 			break
 		}
 	}
@@ -230,32 +234,32 @@ func (p *ParallelProgress) TaskRequeued(name string, index int, unavailableHost
 		}
 	}
 
-	// Print warning line (not part of the animated display)
+	p.warnLocked(fmt.Sprintf("%s unavailable, re-queuing %s", unavailableHost, name))
+}
+
+// Warn prints a warning line above the task list.
+func (p *ParallelProgress) Warn(msg string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.warnLocked(msg)
+}
+
+// warnLocked prints a warning line (not part of the animated display) and
+// re-renders the task list below it. Must be called with p.mu held.
+func (p *ParallelProgress) warnLocked(msg string) {
 	// We print above the animated area by clearing current display,
 	// printing warning, then re-rendering the task list
+	if accessible {
+		fmt.Fprintf(p.output, "%s %s\n", SymbolWarning, msg)
+		return
+	}
 	if p.isTTY {
 		warningStyle := lipgloss.NewStyle().Foreground(ColorWarning)
-		warning := fmt.Sprintf("%s %s unavailable, re-queuing %s\n",
-			warningStyle.Render(SymbolWarning),
-			unavailableHost,
-			name)
-
-		// Move cursor up, clear lines, print warning, then re-render
-		if p.lineCount > 0 {
-			fmt.Fprintf(p.output, "\x1b[%dA", p.lineCount)
-		}
-		for i := 0; i < p.lineCount; i++ {
-			fmt.Fprint(p.output, "\x1b[K\n") // Clear each line
-		}
-		if p.lineCount > 0 {
-			fmt.Fprintf(p.output, "\x1b[%dA", p.lineCount)
-		}
+		warning := fmt.Sprintf("%s %s\n", warningStyle.Render(SymbolWarning), msg)
 
-		// Print warning (permanent line)
+		// Clear the task list, print the warning, then re-render below it
+		fmt.Fprint(p.output, p.region.Clear())
 		fmt.Fprint(p.output, warning)
-
-		// Reset line count since we printed new content
-		p.lineCount = 0
 	}
 
 	// Re-render task list
@@ -276,51 +280,49 @@ func (p *ParallelProgress) HasRunningTasks() bool {
 }
 
 func (p *ParallelProgress) animate() {
-	ticker := time.NewTicker(80 * time.Millisecond) // Smooth animation
-	defer ticker.Stop()
 	defer close(p.doneChan)
 
-	for {
-		select {
-		case <-p.stopChan:
-			// Final render with all tasks in their final state
-			p.mu.Lock()
-			p.renderLocked()
-			p.mu.Unlock()
-			return
-		case <-ticker.C:
-			p.mu.Lock()
-			p.frame = (p.frame + 1) % len(spinnerFrames)
-			p.renderLocked()
-			p.mu.Unlock()
-		}
+	// Accessible output announces each change as it happens instead
+	if accessible {
+		<-p.stopChan
+		return
 	}
+
+	animateFrames(80*time.Millisecond, p.stopChan, func() {
+		p.mu.Lock()
+		p.frame = (p.frame + 1) % len(spinnerFrames)
+		p.renderLocked()
+		p.mu.Unlock()
+	})
+
+	// Final render with all tasks in their final state
+	p.mu.Lock()
+	p.renderLocked()
+	p.mu.Unlock()
 }
 
 // renderLocked renders all task lines in-place. Must be called with lock held.
 func (p *ParallelProgress) renderLocked() {
-	if !p.isTTY || len(p.tasks) == 0 {
+	if !p.isTTY || accessible || len(p.tasks) == 0 {
 		return
 	}
 
-	var sb strings.Builder
-
-	// Move cursor up to overwrite previous lines
-	if p.lineCount > 0 {
-		sb.WriteString(fmt.Sprintf("\x1b[%dA", p.lineCount))
+	// Rewrite only the task lines that changed
+	lines := make([]string, len(p.tasks))
+	for i, task := range p.tasks {
+		lines[i] = p.renderTaskLine(task)
 	}
-
-	// Render each task line
-	for _, task := range p.tasks {
-		line := p.renderTaskLine(task)
-		// Clear line and write new content
-		sb.WriteString("\x1b[K") // Clear to end of line
-		sb.WriteString(line)
-		sb.WriteString("\n")
+	if out := p.region.Frame(lines); out != "" {
+		fmt.Fprint(p.output, out)
 	}
+}
 
-	fmt.Fprint(p.output, sb.String())
-	p.lineCount = len(p.tasks)
+// announceLocked prints a task's new status on a line of its own, for
+// accessible output. Must be called with lock held.
+func (p *ParallelProgress) announceLocked(task taskEntry) {
+	if accessible {
+		fmt.Fprintln(p.output, p.renderTaskLine(task))
+	}
 }
 
 // renderTaskLine renders a single task with appropriate symbol and style.
@@ -341,6 +343,9 @@ func (p *ParallelProgress) renderTaskLine(task taskEntry) string {
 	case TaskStatusRunning:
 		// Animated spinner with color cycling
 		symbol = spinnerFrames[p.frame]
+		if accessible {
+			symbol = SymbolProgress
+		}
 		colorIdx := (p.frame / 2) % len(GradientColors)
 		style = lipgloss.NewStyle().Foreground(GradientColors[colorIdx])
 	case TaskStatusPassed:
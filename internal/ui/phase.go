@@ -48,6 +48,10 @@ func NewPhaseDisplay(w io.Writer) *PhaseDisplay {
 // Shows: ◐ Connecting... (animated symbol in blue)
 func (pd *PhaseDisplay) RenderProgress(name string) {
 	style := lipgloss.NewStyle().Foreground(ColorSecondary)
+	if accessible {
+		fmt.Fprintf(pd.w, "%s %s...\n", style.Render(SymbolProgress), name)
+		return
+	}
 	fmt.Fprintf(pd.w, "\r%s %s...", style.Render(SymbolProgress), name)
 }
 
@@ -118,6 +122,10 @@ func (pd *PhaseDisplay) RenderSubStatus(symbol string, name string, status strin
 // Divider renders a horizontal line to separate phases from command output.
 // Uses thick box-drawing characters: ━━━━━━━━━━━━━━━━━
 func (pd *PhaseDisplay) Divider() {
+	if accessible {
+		fmt.Fprintln(pd.w)
+		return
+	}
 	style := lipgloss.NewStyle().Foreground(ColorMuted)
 	fmt.Fprintf(pd.w, "\n%s\n\n", style.Render(strings.Repeat("━", DividerWidth)))
 }
@@ -125,6 +133,10 @@ func (pd *PhaseDisplay) Divider() {
 // ThinDivider renders a thin horizontal line.
 // Uses thin box-drawing characters: ────────────────
 func (pd *PhaseDisplay) ThinDivider() {
+	if accessible {
+		fmt.Fprintln(pd.w)
+		return
+	}
 	style := lipgloss.NewStyle().Foreground(ColorMuted)
 	fmt.Fprintf(pd.w, "\n%s\n\n", style.Render(strings.Repeat("─", DividerWidth)))
 }
@@ -145,7 +157,7 @@ func (pd *PhaseDisplay) Newline() {
 func (pd *PhaseDisplay) clearLine() {
 	// Write carriage return and spaces to clear any spinner output
 	// This is a simple approach; more sophisticated clearing could be added
-	fmt.Fprint(pd.w, "\r"+strings.Repeat(" ", 80)+"\r")
+	clearLine(pd.w, 80)
 }
 
 // FormatPhase returns a formatted phase line as a string.
@@ -160,8 +172,22 @@ func FormatPhase(symbol string, symbolColor lipgloss.Color, name string, timing
 	return fmt.Sprintf("%s %s %s", symbolStyle.Render(symbol), name, timingStyle.Render(timing))
 }
 
-// FormatDivider returns a divider line as a string.
+// FormatElapsed formats how long a run, phase, or task took: "0.2s",
+// "34.0s", "2m05s".
+func FormatElapsed(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	}
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+// FormatDivider returns a divider line as a string. It's empty in
+// accessible output, where a row of box-drawing characters is just noise.
 func FormatDivider(width int) string {
+	if accessible {
+		return ""
+	}
 	style := lipgloss.NewStyle().Foreground(ColorMuted)
 	return style.Render(strings.Repeat("━", width))
 }
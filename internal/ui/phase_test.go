@@ -212,3 +212,9 @@ func TestFormatDivider(t *testing.T) {
 func TestDividerWidth(t *testing.T) {
 	assert.Equal(t, 64, DividerWidth)
 }
+
+func TestFormatElapsed(t *testing.T) {
+	assert.Equal(t, "0.2s", FormatElapsed(210*time.Millisecond))
+	assert.Equal(t, "59.0s", FormatElapsed(59*time.Second))
+	assert.Equal(t, "2m05s", FormatElapsed(125*time.Second))
+}
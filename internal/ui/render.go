@@ -0,0 +1,195 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// renderRate is how many times a second animated output redraws, or 0 to
+// adapt to the terminal. See SetRenderRate.
+var renderRate int
+
+// SetRenderRate caps how many times a second spinners, progress bars, and
+// the parallel task list redraw. Zero (the default) adapts instead: they
+// redraw less often while writes to the terminal are slow, as over
+// SSH-in-SSH or a busy tmux, and speed back up once writes are quick again.
+func SetRenderRate(perSecond int) {
+	renderRate = max(perSecond, 0)
+}
+
+// RenderRate returns the rate set with SetRenderRate.
+func RenderRate() int {
+	return renderRate
+}
+
+// Adaptive redraw intervals.
+const (
+	// remoteFrameInterval is the shortest interval when rr itself runs in
+	// an SSH session, where every frame crosses the network.
+	remoteFrameInterval = 200 * time.Millisecond
+	// maxFrameInterval is as slow as adaptive redraws get.
+	maxFrameInterval = time.Second
+	// quickFrames is how many quick writes in a row it takes to speed up.
+	quickFrames = 10
+)
+
+// framePacer decides how long to wait between frames of an animation. It
+// starts at the animation's own interval and backs off when a frame's
+// write takes a good part of it, which means the terminal or the link to
+// it isn't keeping up.
+type framePacer struct {
+	base     time.Duration // Fastest interval
+	interval time.Duration
+	fixed    bool // Set with --render-rate, so it doesn't adapt
+	quick    int  // Quick writes in a row
+}
+
+// newFramePacer returns a pacer for an animation that redraws every base.
+func newFramePacer(base time.Duration) *framePacer {
+	if renderRate > 0 {
+		d := time.Second / time.Duration(renderRate)
+		return &framePacer{base: d, interval: d, fixed: true}
+	}
+	if os.Getenv("SSH_TTY") != "" {
+		base = max(base, remoteFrameInterval)
+	}
+	return &framePacer{base: base, interval: base}
+}
+
+// Interval returns how long to wait before the next frame.
+func (p *framePacer) Interval() time.Duration {
+	return p.interval
+}
+
+// Observe adapts the interval to how long the last frame took to write.
+func (p *framePacer) Observe(took time.Duration) {
+	if p.fixed {
+		return
+	}
+	switch {
+	case took > p.interval/4:
+		p.interval = min(p.interval*2, maxFrameInterval)
+		p.quick = 0
+	case took < p.base/20:
+		p.quick++
+		if p.quick >= quickFrames && p.interval > p.base {
+			p.interval = max(p.interval/2, p.base)
+			p.quick = 0
+		}
+	default:
+		p.quick = 0
+	}
+}
+
+// animateFrames calls frame at the pacer's interval until stop is closed.
+func animateFrames(base time.Duration, stop <-chan struct{}, frame func()) {
+	pacer := newFramePacer(base)
+	timer := time.NewTimer(pacer.Interval())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-timer.C:
+			start := time.Now()
+			frame()
+			pacer.Observe(time.Since(start))
+			timer.Reset(pacer.Interval())
+		}
+	}
+}
+
+// liveRegion tracks lines drawn in place, so a redraw rewrites only the
+// lines that changed. Each changed line is written over the old one and
+// then cleared to its end, never blanked first, so a slow terminal doesn't
+// flash an empty frame.
+type liveRegion struct {
+	lines []string // What's on screen
+	// newlines is whether each line ends in a newline, leaving the cursor
+	// under the region rather than at the end of its last line.
+	newlines bool
+}
+
+// Frame returns what to write to turn the screen into lines, or "" if
+// nothing changed.
+func (r *liveRegion) Frame(lines []string) string {
+	if len(lines) < len(r.lines) {
+		// Rare enough to just start over
+		return r.Clear() + r.Frame(lines)
+	}
+	first := 0
+	for first < len(r.lines) && lines[first] == r.lines[first] {
+		first++
+	}
+	if first == len(lines) {
+		return ""
+	}
+
+	var sb strings.Builder
+	r.moveUp(&sb, first)
+	last := len(lines) - 1
+	for i := first; i <= last; i++ {
+		if i > first && !r.newlines {
+			sb.WriteString("\n")
+		}
+		// Without newlines the cursor has to end up after the last line,
+		// so that one is always written
+		if i >= len(r.lines) || lines[i] != r.lines[i] || (i == last && !r.newlines) {
+			sb.WriteString(lines[i] + "\x1b[K")
+		}
+		if r.newlines {
+			sb.WriteString("\n")
+		}
+	}
+
+	r.lines = append(r.lines[:0], lines...)
+	return sb.String()
+}
+
+// Clear returns what to write to erase the region, leaving the cursor at
+// the start of its first line.
+func (r *liveRegion) Clear() string {
+	if len(r.lines) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	r.moveUp(&sb, 0)
+	for i := range r.lines {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\x1b[K")
+	}
+	if n := len(r.lines) - 1; n > 0 {
+		fmt.Fprintf(&sb, "\x1b[%dA", n)
+	}
+	sb.WriteString("\r")
+	r.lines = nil
+	return sb.String()
+}
+
+// Forget drops what the region drew, for when other output has moved on
+// past it.
+func (r *liveRegion) Forget() {
+	r.lines = nil
+}
+
+// moveUp writes the cursor movement from where the last frame left it to
+// the start of row.
+func (r *liveRegion) moveUp(sb *strings.Builder, row int) {
+	if len(r.lines) == 0 {
+		sb.WriteString("\r")
+		return
+	}
+	up := len(r.lines) - 1 - row
+	if r.newlines {
+		up++
+	}
+	if up > 0 {
+		fmt.Fprintf(sb, "\x1b[%dA", up)
+	}
+	sb.WriteString("\r")
+}
@@ -0,0 +1,95 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLiveRegionFrame(t *testing.T) {
+	r := liveRegion{newlines: true}
+
+	assert.Equal(t, "\ra\x1b[K\nb\x1b[K\n", r.Frame([]string{"a", "b"}))
+	assert.Empty(t, r.Frame([]string{"a", "b"}), "unchanged lines write nothing")
+
+	// Only the second line changed: move up to it and rewrite it
+	assert.Equal(t, "\x1b[1A\rc\x1b[K\n", r.Frame([]string{"a", "c"}))
+
+	// A new line is appended after the unchanged ones
+	assert.Equal(t, "\rd\x1b[K\n", r.Frame([]string{"a", "c", "d"}))
+}
+
+func TestLiveRegionFrameWithoutNewlines(t *testing.T) {
+	r := liveRegion{}
+
+	assert.Equal(t, "\rspin\x1b[K", r.Frame([]string{"spin"}))
+	assert.Equal(t, "\rspun\x1b[K", r.Frame([]string{"spun"}))
+	assert.Empty(t, r.Frame([]string{"spun"}))
+}
+
+func TestLiveRegionShrinkRedraws(t *testing.T) {
+	r := liveRegion{newlines: true}
+	r.Frame([]string{"a", "b", "c"})
+
+	out := r.Frame([]string{"a"})
+	assert.Contains(t, out, "a\x1b[K")
+	assert.Equal(t, []string{"a"}, r.lines)
+}
+
+func TestLiveRegionClear(t *testing.T) {
+	r := liveRegion{newlines: true}
+	assert.Empty(t, r.Clear())
+
+	r.Frame([]string{"a", "b"})
+	assert.Equal(t, "\x1b[2A\r\x1b[K\n\x1b[K\x1b[1A\r", r.Clear())
+	assert.Nil(t, r.lines)
+
+	// After a clear, the next frame draws from scratch
+	assert.Equal(t, "\ra\x1b[K\n", r.Frame([]string{"a"}))
+}
+
+func TestFramePacerBacksOffAndRecovers(t *testing.T) {
+	t.Setenv("SSH_TTY", "")
+	p := newFramePacer(80 * time.Millisecond)
+	assert.Equal(t, 80*time.Millisecond, p.Interval())
+
+	p.Observe(50 * time.Millisecond)
+	assert.Equal(t, 160*time.Millisecond, p.Interval())
+	p.Observe(100 * time.Millisecond)
+	p.Observe(200 * time.Millisecond)
+	p.Observe(400 * time.Millisecond)
+	assert.Equal(t, maxFrameInterval, p.Interval())
+
+	for range quickFrames {
+		p.Observe(time.Millisecond)
+	}
+	assert.Equal(t, maxFrameInterval/2, p.Interval())
+
+	for range 10 * quickFrames {
+		p.Observe(time.Millisecond)
+	}
+	assert.Equal(t, 80*time.Millisecond, p.Interval(), "never faster than the base interval")
+}
+
+func TestFramePacerOverSSH(t *testing.T) {
+	t.Setenv("SSH_TTY", "/dev/pts/0")
+	p := newFramePacer(80 * time.Millisecond)
+	assert.Equal(t, remoteFrameInterval, p.Interval())
+}
+
+func TestFramePacerFixedRate(t *testing.T) {
+	SetRenderRate(4)
+	t.Cleanup(func() { SetRenderRate(0) })
+
+	p := newFramePacer(80 * time.Millisecond)
+	assert.Equal(t, 250*time.Millisecond, p.Interval())
+	p.Observe(time.Second)
+	assert.Equal(t, 250*time.Millisecond, p.Interval(), "a set rate doesn't adapt")
+}
+
+func TestSetRenderRateClampsNegative(t *testing.T) {
+	SetRenderRate(-3)
+	t.Cleanup(func() { SetRenderRate(0) })
+	assert.Equal(t, 0, RenderRate())
+}
@@ -2,7 +2,6 @@ package ui
 
 import (
 	"fmt"
-	"strings"
 	"sync"
 	"time"
 
@@ -25,16 +24,18 @@ var spinnerFrames = []string{"⣾", "⣽", "⣻", "⢿", "⡿", "⣟", "⣯", "
 
 // Spinner displays an animated status indicator with a label.
 type Spinner struct {
-	mu           sync.Mutex
-	label        string
-	state        SpinnerState
-	frame        int
-	startTime    time.Time
-	stopChan     chan struct{}
-	doneChan     chan struct{}
-	output       func(string)
-	running      bool
-	lastRendered string
+	mu        sync.Mutex
+	label     string
+	detail    string // A muted line under the label, if set
+	state     SpinnerState
+	frame     int
+	startTime time.Time
+	stopChan  chan struct{}
+	doneChan  chan struct{}
+	output    func(string)
+	running   bool
+	region    liveRegion // The line the spinner draws in place
+	announced bool       // Accessible output printed the label line already
 }
 
 // NewSpinner creates a new spinner with the given label.
@@ -67,6 +68,17 @@ func (s *Spinner) Start() {
 	s.startTime = time.Now()
 	s.stopChan = make(chan struct{})
 	s.doneChan = make(chan struct{})
+
+	// Accessible output announces the label once instead of animating
+	if accessible {
+		if !s.announced {
+			s.announced = true
+			s.output(s.label + "...\n")
+		}
+		close(s.doneChan)
+		s.mu.Unlock()
+		return
+	}
 	s.mu.Unlock()
 
 	s.render()
@@ -88,6 +100,17 @@ func (s *Spinner) Stop() {
 	<-s.doneChan
 }
 
+// Dismiss stops the spinner and erases its line without printing a final
+// status, for callers that print their own results in its place.
+func (s *Spinner) Dismiss() {
+	s.Stop()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !accessible {
+		s.output(s.region.Clear())
+	}
+}
+
 // Success stops the spinner and marks it as successful.
 func (s *Spinner) Success() {
 	s.Stop()
@@ -146,22 +169,24 @@ func (s *Spinner) SetLabel(label string) {
 	s.label = label
 }
 
+// SetDetail sets a muted line shown under the label while the spinner
+// runs, like the latest output of what it's waiting on. Empty hides it.
+func (s *Spinner) SetDetail(detail string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.detail = detail
+}
+
 func (s *Spinner) animate() {
-	ticker := time.NewTicker(60 * time.Millisecond) // Faster for more energy
-	defer ticker.Stop()
 	defer close(s.doneChan)
 
-	for {
-		select {
-		case <-s.stopChan:
-			return
-		case <-ticker.C:
-			s.mu.Lock()
-			s.frame = (s.frame + 1) % len(spinnerFrames)
-			s.mu.Unlock()
-			s.render()
-		}
-	}
+	// Fast frames for more energy, slowed down on a slow terminal
+	animateFrames(60*time.Millisecond, s.stopChan, func() {
+		s.mu.Lock()
+		s.frame = (s.frame + 1) % len(spinnerFrames)
+		s.mu.Unlock()
+		s.render()
+	})
 }
 
 func (s *Spinner) render() {
@@ -173,16 +198,13 @@ func (s *Spinner) render() {
 	colorIndex := (s.frame / 2) % len(GradientColors)
 	style := lipgloss.NewStyle().Foreground(GradientColors[colorIndex])
 
-	line := fmt.Sprintf("\r%s %s...", style.Render(symbol), s.label)
-
-	if s.lastRendered != "" {
-		// Clear the previous line
-		clearLen := len([]rune(s.lastRendered))
-		s.output("\r" + strings.Repeat(" ", clearLen) + "\r")
+	lines := []string{fmt.Sprintf("%s %s...", style.Render(symbol), s.label)}
+	if s.detail != "" {
+		lines = append(lines, "  "+MutedStyle().Render(s.detail))
+	}
+	if out := s.region.Frame(lines); out != "" {
+		s.output(out)
 	}
-
-	s.output(line)
-	s.lastRendered = line
 }
 
 func (s *Spinner) renderFinal() {
@@ -212,9 +234,8 @@ func (s *Spinner) renderFinal() {
 	timingStyle := lipgloss.NewStyle().Foreground(ColorMuted)
 
 	// Clear the current line and render final state
-	if s.lastRendered != "" {
-		clearLen := len([]rune(s.lastRendered))
-		s.output("\r" + strings.Repeat(" ", clearLen) + "\r")
+	if out := s.region.Clear(); out != "" {
+		s.output(out)
 	}
 
 	line := fmt.Sprintf("%s %s %s\n",
@@ -137,6 +137,17 @@ func TestSpinnerSetLabel(t *testing.T) {
 	assert.Equal(t, "Updated", s.Label())
 }
 
+func TestSpinnerSetDetail(t *testing.T) {
+	var output strings.Builder
+	s := NewSpinner("Waiting")
+	s.SetOutput(func(out string) { output.WriteString(out) })
+	s.SetDetail("epoch 3/10")
+
+	s.render()
+	assert.Contains(t, output.String(), "Waiting...")
+	assert.Contains(t, output.String(), "epoch 3/10")
+}
+
 func TestSpinnerDoubleStart(t *testing.T) {
 	s := NewSpinner("Test")
 	s.SetOutput(func(_ string) {})
@@ -0,0 +1,156 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// StepProgress draws a live line for the running step of a multi-step task
+// under the step's output, e.g. "⣾ Step 2/3: test 12.4s · ~1m05s left".
+// The step's output goes through its writers, which take the line down
+// before writing and put it back once the output is at the start of a line,
+// so the line never splits a line of output.
+type StepProgress struct {
+	mu       sync.Mutex
+	out      io.Writer // Where the line is drawn
+	region   liveRegion
+	label    string
+	eta      time.Duration // Expected time from the step's start to the task's end (0 if unknown)
+	started  time.Time
+	frame    int
+	midLine  bool // The last output didn't end its line, so the line stays down
+	running  bool
+	stopChan chan struct{}
+	doneChan chan struct{}
+}
+
+// NewStepProgress returns a step progress line drawn on out, usually the
+// terminal's stdout.
+func NewStepProgress(out io.Writer) *StepProgress {
+	return &StepProgress{out: out}
+}
+
+// Writer returns a writer that passes output on to w, keeping it clear of
+// the live line. Wrap both stdout and stderr when they share a terminal.
+func (p *StepProgress) Writer(w io.Writer) io.Writer {
+	return &stepProgressWriter{p: p, w: w}
+}
+
+// Start shows the live line for a step. eta is how long the step and the
+// steps after it usually take, from run history, or 0 if that's not known.
+// Accessible output has no live line; the step headers say the same.
+func (p *StepProgress) Start(label string, eta time.Duration) {
+	p.Stop()
+	if accessible {
+		return
+	}
+
+	p.mu.Lock()
+	p.label = label
+	p.eta = eta
+	p.started = time.Now()
+	p.midLine = false // The step header ended its line
+	p.running = true
+	p.stopChan = make(chan struct{})
+	p.doneChan = make(chan struct{})
+	p.renderLocked()
+	p.mu.Unlock()
+
+	go func(stop, done chan struct{}) {
+		defer close(done)
+		animateFrames(100*time.Millisecond, stop, func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.frame = (p.frame + 1) % len(spinnerFrames)
+			p.renderLocked()
+		})
+	}(p.stopChan, p.doneChan)
+}
+
+// Stop takes the live line down. It's a no-op if the line isn't up.
+func (p *StepProgress) Stop() {
+	p.mu.Lock()
+	if !p.running {
+		p.mu.Unlock()
+		return
+	}
+	p.running = false
+	close(p.stopChan)
+	done := p.doneChan
+	p.mu.Unlock()
+
+	<-done
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if out := p.region.Clear(); out != "" {
+		_, _ = io.WriteString(p.out, out)
+	}
+}
+
+// renderLocked draws the live line. p.mu must be held.
+func (p *StepProgress) renderLocked() {
+	if !p.running || p.midLine {
+		return
+	}
+	if out := p.region.Frame([]string{p.lineLocked()}); out != "" {
+		_, _ = io.WriteString(p.out, out)
+	}
+}
+
+// lineLocked renders the live line. p.mu must be held.
+func (p *StepProgress) lineLocked() string {
+	elapsed := time.Since(p.started)
+	colorIndex := (p.frame / 2) % len(GradientColors)
+	symbol := lipgloss.NewStyle().Foreground(GradientColors[colorIndex]).Render(spinnerFrames[p.frame])
+
+	line := fmt.Sprintf("%s %s %s", symbol, p.label, MutedStyle().Render(formatDuration(elapsed)))
+	if p.eta > 0 {
+		line += MutedStyle().Render(" · " + formatETA(p.eta-elapsed))
+	}
+	return line
+}
+
+// formatETA describes how much of an estimate is left, e.g. "~1m05s left",
+// or "running long" once it's used up.
+func formatETA(left time.Duration) string {
+	if left <= 0 {
+		return "running long"
+	}
+	left = left.Round(time.Second)
+	if left < time.Minute {
+		return fmt.Sprintf("~%ds left", max(int(left.Seconds()), 1))
+	}
+	if left < time.Hour {
+		return fmt.Sprintf("~%dm%02ds left", int(left.Minutes()), int(left.Seconds())%60)
+	}
+	return fmt.Sprintf("~%dh%02dm left", int(left.Hours()), int(left.Minutes())%60)
+}
+
+// stepProgressWriter is one of StepProgress's output writers.
+type stepProgressWriter struct {
+	p *StepProgress
+	w io.Writer
+}
+
+// Write implements io.Writer.
+func (w *stepProgressWriter) Write(b []byte) (int, error) {
+	p := w.p
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if out := p.region.Clear(); out != "" {
+		_, _ = io.WriteString(p.out, out)
+	}
+	n, err := w.w.Write(b)
+	if len(b) > 0 {
+		p.midLine = !bytes.HasSuffix(b, []byte("\n"))
+	}
+	p.renderLocked()
+	return n, err
+}
@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStepProgress_KeepsLineUnderOutput(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewStepProgress(&buf)
+	out := p.Writer(&buf)
+
+	p.Start("Step 2/3: test", time.Minute)
+	assert.Contains(t, buf.String(), "Step 2/3: test")
+	assert.Contains(t, buf.String(), "~1m00s left")
+
+	buf.Reset()
+	_, _ = out.Write([]byte("ok 1\n"))
+	got := buf.String()
+	assert.True(t, strings.HasPrefix(got, "\r\x1b[K"), "the line comes down before the output: %q", got)
+	assert.Contains(t, got, "ok 1\n")
+	assert.Contains(t, got[strings.Index(got, "ok 1\n"):], "Step 2/3: test", "and goes back up under it")
+
+	buf.Reset()
+	_, _ = out.Write([]byte("Password: "))
+	assert.NotContains(t, buf.String(), "Step 2/3", "a partial line isn't split by the live line")
+
+	p.Stop()
+	p.Stop()
+	buf.Reset()
+	_, _ = out.Write([]byte("done\n"))
+	assert.Equal(t, "done\n", buf.String(), "output passes straight through once stopped")
+}
+
+func TestStepProgress_Accessible(t *testing.T) {
+	SetAccessible(true)
+	t.Cleanup(func() { SetAccessible(false) })
+
+	var buf bytes.Buffer
+	p := NewStepProgress(&buf)
+	p.Start("Step 1/2: build", 0)
+	_, _ = p.Writer(&buf).Write([]byte("compiling\n"))
+	p.Stop()
+	assert.Equal(t, "compiling\n", buf.String())
+}
+
+func TestFormatETA(t *testing.T) {
+	assert.Equal(t, "~45s left", formatETA(45*time.Second))
+	assert.Equal(t, "~1s left", formatETA(200*time.Millisecond))
+	assert.Equal(t, "~3m05s left", formatETA(3*time.Minute+5*time.Second))
+	assert.Equal(t, "~1h20m left", formatETA(80*time.Minute))
+	assert.Equal(t, "running long", formatETA(-time.Second))
+}
@@ -1,7 +1,8 @@
 package ui
 
-// Cyber glyph symbols for status indicators - Gen Z aesthetic
-const (
+// Cyber glyph symbols for status indicators - Gen Z aesthetic. These are
+// variables so SetAccessible can swap them for plain ASCII.
+var (
 	SymbolSuccess  = "◉" // Task completed successfully (filled target)
 	SymbolFail     = "✕" // Task failed (clean X)
 	SymbolPending  = "◇" // Task not yet started (empty diamond)
@@ -12,7 +13,7 @@ const (
 )
 
 // Connection status symbols
-const (
+var (
 	SymbolConnected   = "◉" // Connected (solid signal)
 	SymbolUnreachable = "◌" // Unreachable (dashed circle)
 	SymbolSlow        = "◔" // Slow connection (partially filled)
@@ -0,0 +1,187 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/errors"
+)
+
+// TaskInfo contains information about a task for display in the picker.
+type TaskInfo struct {
+	Name        string        // Task name from config (e.g., "test")
+	Description string        // Task description from config
+	HasRun      bool          // Whether the task has a recorded run
+	LastRun     time.Duration // Duration of the most recent run
+	LastExit    int           // Exit code of the most recent run
+}
+
+// taskItem implements list.Item for the Bubbles list component.
+type taskItem struct {
+	task TaskInfo
+}
+
+func (i taskItem) Title() string {
+	return i.task.Name
+}
+
+func (i taskItem) Description() string {
+	var parts []string
+
+	if i.task.Description != "" {
+		parts = append(parts, i.task.Description)
+	}
+
+	if i.task.HasRun {
+		last := "last run " + FormatElapsed(i.task.LastRun)
+		if i.task.LastExit != 0 {
+			last += fmt.Sprintf(" (exit %d)", i.task.LastExit)
+		}
+		parts = append(parts, last)
+	} else {
+		parts = append(parts, "never run")
+	}
+
+	return strings.Join(parts, " | ")
+}
+
+func (i taskItem) FilterValue() string {
+	// Allow searching by name and description
+	return i.task.Name + " " + i.task.Description
+}
+
+// TaskPickerModel is a Bubble Tea model for selecting a task.
+type TaskPickerModel struct {
+	list     list.Model
+	tasks    []TaskInfo
+	selected *TaskInfo
+	quitting bool
+	width    int
+	height   int
+}
+
+// taskPickerKeys uses the same bindings as the host picker.
+var taskPickerKeys = hostPickerKeys
+
+// NewTaskPickerModel creates a new task picker model.
+func NewTaskPickerModel(tasks []TaskInfo) TaskPickerModel {
+	items := make([]list.Item, len(tasks))
+	for i, t := range tasks {
+		items[i] = taskItem{task: t}
+	}
+
+	// Create list with custom delegate for styling
+	delegate := list.NewDefaultDelegate()
+	delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.
+		Foreground(lipgloss.Color(string(ColorPrimary))).
+		BorderForeground(lipgloss.Color(string(ColorSecondary)))
+	delegate.Styles.SelectedDesc = delegate.Styles.SelectedDesc.
+		Foreground(lipgloss.Color(string(ColorMuted)))
+
+	l := list.New(items, delegate, 0, 0)
+	l.Title = "Select a task"
+	l.SetShowStatusBar(false)
+	l.SetFilteringEnabled(true)
+	l.Styles.Title = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(string(ColorPrimary))).
+		Bold(true).
+		Padding(0, 0, 1, 0)
+	l.Styles.HelpStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(string(ColorMuted)))
+
+	return TaskPickerModel{
+		list:   l,
+		tasks:  tasks,
+		width:  80,
+		height: 15,
+	}
+}
+
+// Init implements tea.Model.
+func (m TaskPickerModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model.
+func (m TaskPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		// While typing a filter, let the list handle keys so "q" and
+		// enter go to the filter input instead of quitting or selecting.
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
+		switch {
+		case key.Matches(msg, taskPickerKeys.Enter):
+			if item, ok := m.list.SelectedItem().(taskItem); ok {
+				m.selected = &item.task
+			}
+			m.quitting = true
+			return m, tea.Quit
+
+		case key.Matches(msg, taskPickerKeys.Quit):
+			m.quitting = true
+			return m, tea.Quit
+		}
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.list.SetSize(msg.Width, msg.Height-2)
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+// View implements tea.Model.
+func (m TaskPickerModel) View() string {
+	if m.quitting {
+		return ""
+	}
+	return m.list.View()
+}
+
+// Selected returns the selected task, or nil if cancelled.
+func (m TaskPickerModel) Selected() *TaskInfo {
+	return m.selected
+}
+
+// PickTask displays an interactive task picker and returns the selected task.
+// Returns nil if the user cancels (ESC/q/Ctrl+C).
+func PickTask(tasks []TaskInfo) (*TaskInfo, error) {
+	return PickTaskWithOutput(tasks, os.Stdout, os.Stdin)
+}
+
+// PickTaskWithOutput displays the task picker using custom I/O.
+func PickTaskWithOutput(tasks []TaskInfo, output io.Writer, input io.Reader) (*TaskInfo, error) {
+	if len(tasks) == 0 {
+		return nil, errors.New(errors.ErrConfig, "No tasks to pick from", "Add tasks to your .rr.yaml to run them by name.")
+	}
+
+	model := NewTaskPickerModel(tasks)
+
+	p := tea.NewProgram(
+		model,
+		tea.WithOutput(output),
+		tea.WithInput(input),
+	)
+
+	finalModel, err := p.Run()
+	if err != nil {
+		return nil, errors.WrapWithCode(err, errors.ErrConfig, "Task picker failed", "Try running again or run the task directly with 'rr <task>'.")
+	}
+
+	if m, ok := finalModel.(TaskPickerModel); ok {
+		return m.Selected(), nil
+	}
+
+	return nil, nil
+}
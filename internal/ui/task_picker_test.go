@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskItem(t *testing.T) {
+	task := TaskInfo{
+		Name:        "test",
+		Description: "Run the test suite",
+		HasRun:      true,
+		LastRun:     34 * time.Second,
+	}
+
+	item := taskItem{task: task}
+
+	t.Run("Title", func(t *testing.T) {
+		assert.Equal(t, "test", item.Title())
+	})
+
+	t.Run("Description", func(t *testing.T) {
+		desc := item.Description()
+		assert.Contains(t, desc, "Run the test suite")
+		assert.Contains(t, desc, "last run 34.0s")
+		assert.NotContains(t, desc, "exit")
+	})
+
+	t.Run("FilterValue", func(t *testing.T) {
+		filter := item.FilterValue()
+		assert.Contains(t, filter, "test")
+		assert.Contains(t, filter, "Run the test suite")
+	})
+}
+
+func TestTaskItemFailedLastRun(t *testing.T) {
+	item := taskItem{task: TaskInfo{
+		Name:     "build",
+		HasRun:   true,
+		LastRun:  125 * time.Second,
+		LastExit: 2,
+	}}
+
+	assert.Equal(t, "last run 2m05s (exit 2)", item.Description())
+}
+
+func TestTaskItemNeverRun(t *testing.T) {
+	item := taskItem{task: TaskInfo{Name: "lint"}}
+
+	assert.Equal(t, "never run", item.Description())
+}
+
+func TestNewTaskPickerModel(t *testing.T) {
+	tasks := []TaskInfo{
+		{Name: "build"},
+		{Name: "test"},
+	}
+
+	model := NewTaskPickerModel(tasks)
+
+	assert.Len(t, model.tasks, 2)
+	assert.Nil(t, model.selected)
+	assert.False(t, model.quitting)
+}
+
+func TestPickTaskWithOutput_NoTasks(t *testing.T) {
+	selected, err := PickTaskWithOutput(nil, nil, nil)
+
+	assert.Error(t, err)
+	assert.Nil(t, selected)
+}
@@ -0,0 +1,269 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rileyhilliard/rr/internal/errors"
+)
+
+// DefaultThemeName is the theme used when none is configured.
+const DefaultThemeName = "synthwave"
+
+// Theme is a named color palette. ApplyTheme copies it into the package
+// color variables (ColorPrimary, ColorSuccess, ...) used by every command and
+// the monitor dashboard.
+type Theme struct {
+	Name string
+
+	// Text colors
+	Text          lipgloss.Color // Primary text, selected items
+	TextSecondary lipgloss.Color // Labels, secondary text
+	Muted         lipgloss.Color // Timing info, hints, de-emphasized text
+
+	// Status colors
+	Success lipgloss.Color
+	Error   lipgloss.Color
+	Warning lipgloss.Color
+	Info    lipgloss.Color
+
+	// Accents. In the monitor, accent_alt marks healthy metrics, highlight
+	// elevated ones, and accent critical ones.
+	Accent    lipgloss.Color // Titles, selection, spinners
+	AccentAlt lipgloss.Color // Values, graphs
+	Highlight lipgloss.Color // Gradient midpoint, secondary accent
+
+	// Surfaces. "default" leaves the terminal's own background alone.
+	Background lipgloss.Color
+	Surface    lipgloss.Color
+	Border     lipgloss.Color
+}
+
+// ThemeColorKeys lists the color names a theme config can override, in
+// display order.
+var ThemeColorKeys = []string{
+	"text", "text_secondary", "muted",
+	"success", "error", "warning", "info",
+	"accent", "accent_alt", "highlight",
+	"background", "surface", "border",
+}
+
+// color returns a pointer to the theme color named key, or nil if key isn't
+// one of ThemeColorKeys.
+func (t *Theme) color(key string) *lipgloss.Color {
+	switch key {
+	case "text":
+		return &t.Text
+	case "text_secondary":
+		return &t.TextSecondary
+	case "muted":
+		return &t.Muted
+	case "success":
+		return &t.Success
+	case "error":
+		return &t.Error
+	case "warning":
+		return &t.Warning
+	case "info":
+		return &t.Info
+	case "accent":
+		return &t.Accent
+	case "accent_alt":
+		return &t.AccentAlt
+	case "highlight":
+		return &t.Highlight
+	case "background":
+		return &t.Background
+	case "surface":
+		return &t.Surface
+	case "border":
+		return &t.Border
+	default:
+		return nil
+	}
+}
+
+// builtinThemes are the themes selectable by name.
+var builtinThemes = map[string]Theme{
+	// Electric synthwave neons, tuned for dark terminals
+	"synthwave": {
+		Name:          "synthwave",
+		Text:          "#FFFFFF",
+		TextSecondary: "#B4B4D0",
+		Muted:         "#6B6B8D",
+		Success:       "#39FF14",
+		Error:         "#FF0055",
+		Warning:       "#FFAA00",
+		Info:          "#00FFFF",
+		Accent:        "#FF2E97",
+		AccentAlt:     "#00FFFF",
+		Highlight:     "#BF40FF",
+		Background:    "#0A0A0F",
+		Surface:       "#12121A",
+		Border:        "#2A2A4A",
+	},
+	// Darker, saturated colors that stay readable on white and light backgrounds
+	"light": {
+		Name:          "light",
+		Text:          "#1B1B2F",
+		TextSecondary: "#4A4A68",
+		Muted:         "#75758F",
+		Success:       "#007A33",
+		Error:         "#C4002F",
+		Warning:       "#B25E00",
+		Info:          "#006E8C",
+		Accent:        "#C2185B",
+		AccentAlt:     "#00838F",
+		Highlight:     "#7B1FA2",
+		Background:    "",
+		Surface:       "#EEEEF4",
+		Border:        "#C4C4D6",
+	},
+	// The terminal's own 16-color palette, so colors follow whatever scheme
+	// the terminal is set to
+	"ansi": {
+		Name:          "ansi",
+		Text:          "",
+		TextSecondary: "",
+		Muted:         "8",
+		Success:       "2",
+		Error:         "1",
+		Warning:       "3",
+		Info:          "6",
+		Accent:        "5",
+		AccentAlt:     "6",
+		Highlight:     "4",
+		Background:    "",
+		Surface:       "",
+		Border:        "8",
+	},
+}
+
+var (
+	themeMu      sync.Mutex
+	currentTheme = builtinThemes[DefaultThemeName]
+	themeHooks   []func()
+)
+
+// ThemeNames returns the built-in theme names, sorted.
+func ThemeNames() []string {
+	names := make([]string, 0, len(builtinThemes))
+	for name := range builtinThemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// hexColorPattern matches #RGB and #RRGGBB.
+var hexColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// parseThemeColor validates a color value from config: a hex color
+// ("#FF2E97"), an ANSI color number ("0"-"255"), or "default" for the
+// terminal's own color.
+func parseThemeColor(value string) (lipgloss.Color, bool) {
+	value = strings.TrimSpace(value)
+	if strings.EqualFold(value, "default") {
+		return "", true
+	}
+	if hexColorPattern.MatchString(value) {
+		return lipgloss.Color(value), true
+	}
+	if n, err := strconv.Atoi(value); err == nil && n >= 0 && n <= 255 {
+		return lipgloss.Color(value), true
+	}
+	return "", false
+}
+
+// ResolveTheme returns the named built-in theme with colors overridden.
+// An empty name means DefaultThemeName.
+func ResolveTheme(name string, colors map[string]string) (Theme, error) {
+	if name == "" {
+		name = DefaultThemeName
+	}
+	theme, ok := builtinThemes[strings.ToLower(name)]
+	if !ok {
+		return Theme{}, errors.New(errors.ErrConfig,
+			fmt.Sprintf("Unknown theme '%s'", name),
+			"Built-in themes: "+strings.Join(ThemeNames(), ", "))
+	}
+
+	keys := make([]string, 0, len(colors))
+	for key := range colors {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		target := theme.color(key)
+		if target == nil {
+			return Theme{}, errors.New(errors.ErrConfig,
+				fmt.Sprintf("Unknown theme color '%s'", key),
+				"Colors you can set: "+strings.Join(ThemeColorKeys, ", "))
+		}
+		c, ok := parseThemeColor(colors[key])
+		if !ok {
+			return Theme{}, errors.New(errors.ErrConfig,
+				fmt.Sprintf("Theme color %s: '%s' isn't a color", key, colors[key]),
+				"Use a hex color like \"#FF2E97\", an ANSI color number (0-255), or \"default\".")
+		}
+		*target = c
+	}
+
+	return theme, nil
+}
+
+// ApplyTheme makes t the active palette and notifies OnThemeChange hooks.
+func ApplyTheme(t Theme) {
+	themeMu.Lock()
+	currentTheme = t
+
+	ColorPrimary = t.Text
+	ColorSecondary = t.TextSecondary
+	ColorMuted = t.Muted
+
+	ColorSuccess = t.Success
+	ColorError = t.Error
+	ColorWarning = t.Warning
+	ColorInfo = t.Info
+
+	ColorNeonPink = t.Accent
+	ColorNeonCyan = t.AccentAlt
+	ColorNeonPurple = t.Highlight
+	ColorNeonGreen = t.Success
+	ColorNeonOrange = t.Warning
+	ColorNeonAmber = t.Warning
+
+	ColorDeepVoid = t.Background
+	ColorDarkSurface = t.Surface
+	ColorGlassBorder = t.Border
+
+	GradientColors = []lipgloss.Color{t.Accent, t.Highlight, t.AccentAlt, t.Success}
+
+	hooks := append([]func(){}, themeHooks...)
+	themeMu.Unlock()
+
+	for _, hook := range hooks {
+		hook()
+	}
+}
+
+// CurrentTheme returns the active theme.
+func CurrentTheme() Theme {
+	themeMu.Lock()
+	defer themeMu.Unlock()
+	return currentTheme
+}
+
+// OnThemeChange registers fn to run after every ApplyTheme. Packages that
+// build styles once (like the monitor's package-level styles) use it to
+// rebuild them from the new palette.
+func OnThemeChange(fn func()) {
+	themeMu.Lock()
+	defer themeMu.Unlock()
+	themeHooks = append(themeHooks, fn)
+}
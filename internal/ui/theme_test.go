@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// restoreTheme puts the default theme back after a test that applies one.
+func restoreTheme(t *testing.T) {
+	t.Helper()
+	t.Cleanup(func() {
+		def, err := ResolveTheme("", nil)
+		require.NoError(t, err)
+		ApplyTheme(def)
+	})
+}
+
+func TestThemeNames(t *testing.T) {
+	assert.Equal(t, []string{"ansi", "light", "synthwave"}, ThemeNames())
+}
+
+func TestResolveTheme_DefaultMatchesPalette(t *testing.T) {
+	theme, err := ResolveTheme("", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, DefaultThemeName, theme.Name)
+	assert.Equal(t, ColorPrimary, theme.Text)
+	assert.Equal(t, ColorSuccess, theme.Success)
+	assert.Equal(t, ColorNeonPink, theme.Accent)
+	assert.Equal(t, ColorGlassBorder, theme.Border)
+}
+
+func TestResolveTheme_EveryKeyOverridable(t *testing.T) {
+	for _, key := range ThemeColorKeys {
+		theme, err := ResolveTheme("synthwave", map[string]string{key: "#123456"})
+		require.NoError(t, err, key)
+		assert.Equal(t, lipgloss.Color("#123456"), *theme.color(key), key)
+	}
+}
+
+func TestResolveTheme_Overrides(t *testing.T) {
+	theme, err := ResolveTheme("Light", map[string]string{
+		"accent":     "#D7005F",
+		"muted":      "245",
+		"background": "default",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "light", theme.Name)
+	assert.Equal(t, lipgloss.Color("#D7005F"), theme.Accent)
+	assert.Equal(t, lipgloss.Color("245"), theme.Muted)
+	assert.Equal(t, lipgloss.Color(""), theme.Background)
+	assert.Equal(t, lipgloss.Color("#007A33"), theme.Success, "untouched colors come from the base theme")
+}
+
+func TestResolveTheme_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		theme    string
+		colors   map[string]string
+		contains string
+	}{
+		{"unknown theme", "solarized", nil, "Unknown theme 'solarized'"},
+		{"unknown color key", "", map[string]string{"acent": "#fff"}, "Unknown theme color 'acent'"},
+		{"bad hex", "", map[string]string{"accent": "#GGGGGG"}, "isn't a color"},
+		{"color name", "", map[string]string{"accent": "pink"}, "isn't a color"},
+		{"ansi out of range", "", map[string]string{"accent": "256"}, "isn't a color"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ResolveTheme(tt.theme, tt.colors)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.contains)
+		})
+	}
+}
+
+func TestApplyTheme(t *testing.T) {
+	restoreTheme(t)
+
+	var hookCalls int
+	OnThemeChange(func() { hookCalls++ })
+
+	light, err := ResolveTheme("light", nil)
+	require.NoError(t, err)
+	ApplyTheme(light)
+
+	assert.Equal(t, "light", CurrentTheme().Name)
+	assert.Equal(t, light.Text, ColorPrimary)
+	assert.Equal(t, light.Muted, ColorMuted)
+	assert.Equal(t, light.Error, ColorError)
+	assert.Equal(t, light.Accent, ColorNeonPink)
+	assert.Equal(t, []lipgloss.Color{light.Accent, light.Highlight, light.AccentAlt, light.Success}, GradientColors)
+	assert.Equal(t, 1, hookCalls)
+}
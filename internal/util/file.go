@@ -0,0 +1,54 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic replaces path with data, with permissions perm, by
+// writing a temporary file beside it and renaming it into place. A reader
+// or a crash never sees the file half written, and two processes writing
+// it at once each write their own temporary file, so the last rename wins
+// whole.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	return WriteFileAtomicFunc(path, perm, func(tmp string) error {
+		return os.WriteFile(tmp, data, perm)
+	})
+}
+
+// WriteFileAtomicFunc is WriteFileAtomic for writers that want a path to
+// write to rather than bytes. write gets the temporary file's path.
+func WriteFileAtomicFunc(path string, perm os.FileMode, write func(tmp string) error) error {
+	// Same directory so the rename can't cross filesystems; same extension
+	// so writers that go by it know the format
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*"+filepath.Ext(path))
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	tmp.Close()
+	defer os.Remove(tmpPath) // No-op after the rename
+
+	if err := write(tmpPath); err != nil {
+		return err
+	}
+	// CreateTemp makes the file 0600, and os.WriteFile keeps an existing
+	// file's mode
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return err
+	}
+	if err := syncFile(tmpPath); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// syncFile flushes a file to disk.
+func syncFile(path string) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return f.Sync()
+}
@@ -0,0 +1,18 @@
+package util
+
+import (
+	"os"
+	"syscall"
+)
+
+// ProcessAlive reports whether a process with the given PID exists.
+func ProcessAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return proc.Signal(syscall.Signal(0)) == nil
+}
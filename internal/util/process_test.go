@@ -0,0 +1,17 @@
+package util
+
+import (
+	"os"
+	"testing"
+)
+
+func TestProcessAlive(t *testing.T) {
+	if !ProcessAlive(os.Getpid()) {
+		t.Error("this process should be alive")
+	}
+	for _, pid := range []int{0, -1} {
+		if ProcessAlive(pid) {
+			t.Errorf("ProcessAlive(%d) = true, want false", pid)
+		}
+	}
+}
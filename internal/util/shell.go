@@ -1,7 +1,10 @@
 // Package util provides common utility functions used across the codebase.
 package util
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+)
 
 // ShellQuote wraps a string in single quotes, escaping any existing single quotes.
 // This is safe for use in shell commands where the string should be treated literally.
@@ -27,3 +30,69 @@ func ShellQuotePreserveTilde(path string) string {
 	}
 	return ShellQuote(path)
 }
+
+// SplitWords splits a command line into words the way a POSIX shell does,
+// without expanding anything: words are separated by unquoted whitespace,
+// single quotes keep everything literal, and inside double quotes or bare a
+// backslash escapes the next character. Returns an error for an unterminated
+// quote or a trailing backslash.
+func SplitWords(s string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		case c == '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated ' in %q", s)
+			}
+			word.WriteString(s[i+1 : i+1+end])
+			i += end + 1
+			inWord = true
+		case c == '"':
+			i++
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) && strings.IndexByte("\\\"$`", s[i+1]) >= 0 {
+					i++
+				}
+				word.WriteByte(s[i])
+			}
+			if i >= len(s) {
+				return nil, fmt.Errorf("unterminated \" in %q", s)
+			}
+			inWord = true
+		case c == '\\':
+			if i+1 >= len(s) {
+				return nil, fmt.Errorf("trailing \\ in %q", s)
+			}
+			i++
+			word.WriteByte(s[i])
+			inWord = true
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// ShellJoin quotes each word with ShellQuote and joins them with spaces, so
+// a shell passes them on as the same words, with nothing expanded.
+func ShellJoin(words []string) string {
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		quoted[i] = ShellQuote(w)
+	}
+	return strings.Join(quoted, " ")
+}
@@ -52,3 +52,52 @@ func TestShellQuotePreserveTilde(t *testing.T) {
 		})
 	}
 }
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"pytest -x tests/", []string{"pytest", "-x", "tests/"}},
+		{"  spaced   out  ", []string{"spaced", "out"}},
+		{"echo '$HOME *'", []string{"echo", "$HOME *"}},
+		{`echo "a \"b\" \$c \d"`, []string{"echo", `a "b" $c \d`}},
+		{`echo a\ b`, []string{"echo", "a b"}},
+		{`grep -e '' x`, []string{"grep", "-e", "", "x"}},
+		{`--name='it'"s"`, []string{"--name=its"}},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := SplitWords(tt.input)
+			if err != nil {
+				t.Fatalf("SplitWords(%q) error = %v", tt.input, err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("SplitWords(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("SplitWords(%q) = %q, want %q", tt.input, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitWords_Unterminated(t *testing.T) {
+	for _, input := range []string{"echo 'oops", `echo "oops`, `echo oops\`} {
+		if _, err := SplitWords(input); err == nil {
+			t.Errorf("SplitWords(%q) should fail", input)
+		}
+	}
+}
+
+func TestShellJoin(t *testing.T) {
+	got := ShellJoin([]string{"echo", "$HOME", "it's"})
+	want := `'echo' '$HOME' 'it'\''s'`
+	if got != want {
+		t.Errorf("ShellJoin() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,76 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+)
+
+// Dialer opens connections from the host's side, like *ssh.Client.
+type Dialer interface {
+	Dial(network, addr string) (net.Conn, error)
+}
+
+// Forward listens on localhost:localPort and relays each connection to
+// localhost:remotePort as seen by dialer, until ctx is canceled. It returns
+// once the listener is up, so a port that's already taken fails right away.
+func Forward(ctx context.Context, dialer Dialer, localPort, remotePort int) error {
+	var lc net.ListenConfig
+	ln, err := lc.Listen(ctx, "tcp", fmt.Sprintf("127.0.0.1:%d", localPort))
+	if err != nil {
+		return err
+	}
+	remoteAddr := fmt.Sprintf("127.0.0.1:%d", remotePort)
+
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+	go func() {
+		for {
+			local, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go relay(local, dialer, remoteAddr)
+		}
+	}()
+	return nil
+}
+
+// relay copies between a local connection and a new one to remoteAddr until
+// either side closes.
+func relay(local net.Conn, dialer Dialer, remoteAddr string) {
+	defer local.Close()
+	remote, err := dialer.Dial("tcp", remoteAddr)
+	if err != nil {
+		return
+	}
+	defer remote.Close()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		io.Copy(remote, local) //nolint:errcheck // Either side closing ends the relay
+		closeWrite(remote)
+	}()
+	go func() {
+		defer wg.Done()
+		io.Copy(local, remote) //nolint:errcheck // Either side closing ends the relay
+		closeWrite(local)
+	}()
+	wg.Wait()
+}
+
+// closeWrite half-closes conn so the other side sees EOF, or closes it if
+// it can't be half-closed.
+func closeWrite(conn net.Conn) {
+	if cw, ok := conn.(interface{ CloseWrite() error }); ok {
+		cw.CloseWrite() //nolint:errcheck // Best effort
+		return
+	}
+	conn.Close()
+}
@@ -0,0 +1,92 @@
+package watch
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// echoServer answers each line with "echo: <line>" and returns its port.
+func echoServer(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					fmt.Fprintf(conn, "echo: %s\n", scanner.Text())
+				}
+			}()
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+// freePort returns a local port nothing is listening on.
+func freePort(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+	return port
+}
+
+// localDialer dials on this machine, standing in for the SSH client.
+type localDialer struct{ net.Dialer }
+
+func (d *localDialer) Dial(network, addr string) (net.Conn, error) {
+	return d.Dialer.Dial(network, addr)
+}
+
+func TestForward(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	remote := echoServer(t)
+	local := freePort(t)
+	require.NoError(t, Forward(ctx, &localDialer{}, local, remote))
+
+	for i := 0; i < 2; i++ {
+		conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", local))
+		require.NoError(t, err)
+		fmt.Fprintf(conn, "hello %d\n", i)
+		reply, err := bufio.NewReader(conn).ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("echo: hello %d", i), strings.TrimSpace(reply))
+		conn.Close()
+	}
+
+	cancel()
+	assert.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", local))
+		if err == nil {
+			conn.Close()
+		}
+		return err != nil
+	}, time.Second, 10*time.Millisecond, "the port is released when ctx is canceled")
+}
+
+func TestForward_PortTaken(t *testing.T) {
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer taken.Close()
+
+	err = Forward(context.Background(), &localDialer{}, taken.Addr().(*net.TCPAddr).Port, 80)
+	assert.Error(t, err)
+}
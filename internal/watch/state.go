@@ -0,0 +1,213 @@
+// Package watch runs watch tasks: long-lived commands like dev servers that
+// rr restarts when they crash, with ports forwarded from this machine while
+// they run.
+//
+// Each running watch task records its state in ~/.rr/watch/, so 'rr status'
+// and 'rr monitor' can show what's running where.
+package watch
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/util"
+)
+
+// stateDir is the directory under ~/.rr/ that holds watch task state files.
+const stateDir = "watch"
+
+// State is what a running watch task reports about itself.
+type State struct {
+	PID      int       `json:"pid"`
+	Task     string    `json:"task"`
+	Host     string    `json:"host"`
+	Command  string    `json:"command"`
+	LocalDir string    `json:"local_dir"`
+	Ports    []string  `json:"ports,omitempty"`
+	Started  time.Time `json:"started"`
+
+	Running   bool      `json:"running"`             // The command is up (false while waiting to restart)
+	Since     time.Time `json:"since"`               // When the command last started or exited
+	Restarts  int       `json:"restarts"`            // Times the command was restarted
+	LastExit  *int      `json:"last_exit,omitempty"` // Exit code of the last run
+	Syncing   bool      `json:"syncing,omitempty"`   // Local changes are pushed as they happen
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// NewState returns the state of a watch task that's starting now.
+func NewState(task, hostName, command, localDir string, ports []string) *State {
+	now := time.Now()
+	return &State{
+		PID:      os.Getpid(),
+		Task:     task,
+		Host:     hostName,
+		Command:  command,
+		LocalDir: config.NormalizeLocalDir(localDir),
+		Ports:    ports,
+		Started:  now,
+		Since:    now,
+	}
+}
+
+// Alive reports whether the rr process that wrote the state is still running.
+func (s *State) Alive() bool {
+	return s != nil && util.ProcessAlive(s.PID)
+}
+
+// StatePath returns the state file path for a task in a local project
+// directory. The directory and task are hashed into a flat, filesystem-safe
+// name.
+func StatePath(localDir, task string) (string, error) {
+	dir, err := statesDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, config.ProjectStateKey(localDir, task)+".json"), nil
+}
+
+// statesDir returns ~/.rr/watch.
+func statesDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.WrapWithCode(err, errors.ErrExec,
+			"Can't find your home directory",
+			"This is unusual - check your environment.")
+	}
+	return filepath.Join(home, config.GlobalConfigDir, stateDir), nil
+}
+
+// WriteState persists state atomically so readers never see a half-written
+// file.
+func WriteState(state *State) error {
+	path, err := StatePath(state.LocalDir, state.Task)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.WrapWithCode(err, errors.ErrExec,
+			"Couldn't create the watch state directory",
+			"Check permissions on ~/.rr/.")
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return errors.WrapWithCode(err, errors.ErrExec,
+			"Couldn't encode watch state",
+			"This is a bug - please report it.")
+	}
+
+	if err := util.WriteFileAtomic(path, data, 0644); err != nil {
+		return errors.WrapWithCode(err, errors.ErrExec,
+			"Couldn't write watch state",
+			"Check permissions on ~/.rr/.")
+	}
+	return nil
+}
+
+// ReadState loads the state of a task in a local project directory.
+// Returns (nil, nil) if it has never been watched there.
+func ReadState(localDir, task string) (*State, error) {
+	path, err := StatePath(localDir, task)
+	if err != nil {
+		return nil, err
+	}
+	return readStateFile(path)
+}
+
+// RemoveState deletes the state file of a task. Missing files are not an
+// error.
+func RemoveState(localDir, task string) error {
+	path, err := StatePath(localDir, task)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return errors.WrapWithCode(err, errors.ErrExec,
+			"Couldn't remove watch state",
+			"Delete "+path+" manually.")
+	}
+	return nil
+}
+
+// List returns the watch tasks running on this machine, sorted by task name.
+// State left behind by rr processes that died is removed.
+func List() ([]State, error) {
+	dir, err := statesDir()
+	if err != nil {
+		return nil, err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, nil
+	}
+
+	var states []State
+	for _, f := range files {
+		state, err := readStateFile(f)
+		if err != nil || state == nil {
+			continue
+		}
+		if !state.Alive() {
+			_ = os.Remove(f)
+			continue
+		}
+		states = append(states, *state)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		if states[i].Task != states[j].Task {
+			return states[i].Task < states[j].Task
+		}
+		return states[i].Host < states[j].Host
+	})
+	return states, nil
+}
+
+// ForProject returns the running watch tasks of a local project directory.
+func ForProject(localDir string) []State {
+	states, _ := List()
+	dir := config.NormalizeLocalDir(localDir)
+	var out []State
+	for _, s := range states {
+		if s.LocalDir == dir {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// ForHost returns the running watch tasks on a host, from any project.
+func ForHost(hostName string) []State {
+	states, _ := List()
+	var out []State
+	for _, s := range states {
+		if s.Host == hostName {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// readStateFile loads one state file. Returns (nil, nil) if it doesn't exist.
+func readStateFile(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.WrapWithCode(err, errors.ErrExec,
+			"Couldn't read watch state",
+			"Delete "+path+" and restart the watch task.")
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, errors.WrapWithCode(err, errors.ErrExec,
+			"Watch state is corrupted",
+			"Delete "+path+" and restart the watch task.")
+	}
+	return &state, nil
+}
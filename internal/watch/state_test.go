@@ -0,0 +1,81 @@
+package watch
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestState_RoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	localDir := t.TempDir()
+
+	state, err := ReadState(localDir, "dev")
+	require.NoError(t, err)
+	assert.Nil(t, state, "no state before the task has run")
+
+	written := NewState("dev", "mini", "npm run dev", localDir, []string{"3000"})
+	written.Running = true
+	require.NoError(t, WriteState(written))
+
+	state, err = ReadState(localDir, "dev")
+	require.NoError(t, err)
+	require.NotNil(t, state)
+	assert.Equal(t, os.Getpid(), state.PID)
+	assert.Equal(t, "mini", state.Host)
+	assert.Equal(t, "npm run dev", state.Command)
+	assert.Equal(t, []string{"3000"}, state.Ports)
+	assert.True(t, state.Running)
+	assert.True(t, state.Alive())
+
+	other, err := ReadState(localDir, "worker")
+	require.NoError(t, err)
+	assert.Nil(t, other, "each task has its own state")
+
+	require.NoError(t, RemoveState(localDir, "dev"))
+	require.NoError(t, RemoveState(localDir, "dev"), "removing twice is fine")
+	state, err = ReadState(localDir, "dev")
+	require.NoError(t, err)
+	assert.Nil(t, state)
+}
+
+func TestList(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	app, api := t.TempDir(), t.TempDir()
+
+	require.NoError(t, WriteState(NewState("web", "mini", "npm run dev", app, nil)))
+	require.NoError(t, WriteState(NewState("api", "gpu", "cargo watch -x run", api, nil)))
+	require.NoError(t, WriteState(NewState("docs", "mini", "mkdocs serve", app, nil)))
+
+	// Left behind by an rr that died
+	dead := NewState("stale", "mini", "sleep 1000", app, nil)
+	dead.PID = 1 << 30
+	require.NoError(t, WriteState(dead))
+
+	states, err := List()
+	require.NoError(t, err)
+	var tasks []string
+	for _, s := range states {
+		tasks = append(tasks, s.Task)
+	}
+	assert.Equal(t, []string{"api", "docs", "web"}, tasks)
+
+	path, err := StatePath(app, "stale")
+	require.NoError(t, err)
+	assert.NoFileExists(t, path, "dead watchers' state is removed")
+
+	assert.Len(t, ForProject(app), 2)
+	assert.Len(t, ForProject(api), 1)
+	assert.Len(t, ForHost("mini"), 2)
+	assert.Equal(t, "api", ForHost("gpu")[0].Task)
+	assert.Empty(t, ForHost("nope"))
+}
+
+func TestList_NothingWatched(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	states, err := List()
+	require.NoError(t, err)
+	assert.Empty(t, states)
+}
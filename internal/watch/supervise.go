@@ -0,0 +1,98 @@
+package watch
+
+import (
+	"context"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+)
+
+// Restart backoff: the first restart waits minBackoff, and each crash in a
+// row doubles the wait up to maxBackoff. A run that stays up for
+// stableRun resets it, so a server that crashes once a day restarts at once.
+const (
+	minBackoff = time.Second
+	maxBackoff = 30 * time.Second
+	stableRun  = time.Minute
+)
+
+// SuperviseOptions configures Supervise.
+type SuperviseOptions struct {
+	// Restart is the restart policy: config.RestartOnFailure (the default),
+	// config.RestartAlways, or config.RestartNever.
+	Restart string
+
+	// Run runs the command once and returns its exit code. An error means
+	// the command couldn't be run at all (e.g., the connection dropped), and
+	// stops supervision.
+	Run func(ctx context.Context) (int, error)
+
+	// OnStart is called before each run; restarts counts the runs before it.
+	OnStart func(restarts int)
+
+	// OnExit is called after each run. restartIn is how long Supervise waits
+	// before restarting, or 0 if it won't.
+	OnExit func(exitCode int, restartIn time.Duration)
+
+	// minBackoff overrides minBackoff in tests.
+	minBackoff time.Duration
+}
+
+// Supervise runs opts.Run until ctx is canceled, restarting it after it
+// exits according to opts.Restart. It returns the exit code of the last run,
+// and the error that stopped it, if any.
+func Supervise(ctx context.Context, opts SuperviseOptions) (int, error) {
+	first := opts.minBackoff
+	if first <= 0 {
+		first = minBackoff
+	}
+	backoff := first
+
+	for restarts := 0; ; restarts++ {
+		if opts.OnStart != nil {
+			opts.OnStart(restarts)
+		}
+		start := time.Now()
+		exitCode, err := opts.Run(ctx)
+		if err != nil || ctx.Err() != nil {
+			if opts.OnExit != nil {
+				opts.OnExit(exitCode, 0)
+			}
+			return exitCode, err
+		}
+
+		if time.Since(start) >= stableRun {
+			backoff = first
+		}
+		if !shouldRestart(opts.Restart, exitCode) {
+			if opts.OnExit != nil {
+				opts.OnExit(exitCode, 0)
+			}
+			return exitCode, nil
+		}
+		if opts.OnExit != nil {
+			opts.OnExit(exitCode, backoff)
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return exitCode, nil
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// shouldRestart applies a restart policy to an exit code.
+func shouldRestart(policy string, exitCode int) bool {
+	switch policy {
+	case config.RestartAlways:
+		return true
+	case config.RestartNever:
+		return false
+	default:
+		return exitCode != 0
+	}
+}
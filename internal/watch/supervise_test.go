@@ -0,0 +1,102 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupervise_RestartsOnFailure(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Crashes twice, then stays up until Ctrl+C
+	calls := 0
+	run := func(ctx context.Context) (int, error) {
+		calls++
+		if calls <= 2 {
+			return calls, nil
+		}
+		cancel()
+		return 130, nil
+	}
+	var starts []int
+	var exits []int
+	var waits []time.Duration
+
+	_, err := Supervise(ctx, SuperviseOptions{
+		Run:        run,
+		OnStart:    func(restarts int) { starts = append(starts, restarts) },
+		OnExit:     func(code int, restartIn time.Duration) { exits = append(exits, code); waits = append(waits, restartIn) },
+		minBackoff: time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, []int{0, 1, 2}, starts)
+	assert.Equal(t, []int{1, 2, 130}, exits)
+	assert.Equal(t, []time.Duration{time.Millisecond, 2 * time.Millisecond, 0}, waits, "backoff doubles, and there's no restart after Ctrl+C")
+}
+
+func TestSupervise_Policies(t *testing.T) {
+	tests := []struct {
+		policy    string
+		exitCode  int
+		wantCalls int
+	}{
+		{config.RestartOnFailure, 0, 1},
+		{"", 0, 1},
+		{config.RestartNever, 3, 1},
+		{config.RestartAlways, 0, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			calls := 0
+			exitCode, err := Supervise(ctx, SuperviseOptions{
+				Restart: tt.policy,
+				Run: func(context.Context) (int, error) {
+					calls++
+					if calls == 2 {
+						cancel()
+					}
+					return tt.exitCode, nil
+				},
+				minBackoff: time.Millisecond,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.exitCode, exitCode)
+			assert.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
+
+func TestSupervise_ErrorStops(t *testing.T) {
+	calls := 0
+	dropped := errors.New("connection lost")
+	_, err := Supervise(context.Background(), SuperviseOptions{
+		Restart: config.RestartAlways,
+		Run: func(context.Context) (int, error) {
+			calls++
+			return -1, dropped
+		},
+		minBackoff: time.Millisecond,
+	})
+	assert.ErrorIs(t, err, dropped)
+	assert.Equal(t, 1, calls)
+}
+
+func TestShouldRestart(t *testing.T) {
+	assert.True(t, shouldRestart(config.RestartOnFailure, 1))
+	assert.False(t, shouldRestart(config.RestartOnFailure, 0))
+	assert.True(t, shouldRestart(config.RestartAlways, 0))
+	assert.False(t, shouldRestart(config.RestartNever, 1))
+}
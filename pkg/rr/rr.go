@@ -0,0 +1,112 @@
+// Package rr drives rr from Go: load a project's config, pick and connect to
+// one of its hosts, sync the project there, and run commands on it, the way
+// 'rr run' does.
+//
+//	cfg, err := rr.LoadConfig("")
+//	if err != nil {
+//		return err
+//	}
+//	sess, err := rr.Connect(cfg, rr.ConnectOptions{Tag: "gpu"})
+//	if err != nil {
+//		return err
+//	}
+//	defer sess.Close()
+//
+//	if err := sess.Lock("make test"); err != nil {
+//		return err
+//	}
+//	if err := sess.Sync(rr.SyncOptions{}); err != nil {
+//		return err
+//	}
+//	code, err := sess.Run(ctx, "make test", os.Stdout, os.Stderr)
+//
+// The package is the stable API for programs that embed rr; the rest of
+// rr's Go code lives in internal packages and changes freely. Config files
+// are the same ones the rr CLI reads, so hosts added with 'rr host add' and
+// the project's .rr.yaml apply as they do on the command line.
+//
+// Errors carry rr's usual message and suggestion in their Error() text.
+package rr
+
+import (
+	"sort"
+
+	"github.com/rileyhilliard/rr/internal/config"
+)
+
+// Config is a project's resolved configuration: the global hosts from
+// ~/.rr/config.yaml combined with the project's .rr.yaml.
+type Config struct {
+	resolved *config.ResolvedConfig
+}
+
+// Host describes a configured host.
+type Host struct {
+	Name string
+
+	// SSH lists the connection strings tried for the host, in order.
+	SSH []string
+
+	// Dir is the directory on the host the project syncs to, with
+	// variables like ${PROJECT} expanded.
+	Dir string
+
+	Tags []string
+}
+
+// LoadConfig loads and validates the global config and the project config.
+// path is the project's .rr.yaml; empty searches the current directory and
+// its parents, as the CLI does. A directory without a project config uses
+// the global hosts and project defaults.
+func LoadConfig(path string) (*Config, error) {
+	resolved, err := config.LoadResolved(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.ValidateResolved(resolved); err != nil {
+		return nil, err
+	}
+	return &Config{resolved: resolved}, nil
+}
+
+// ProjectRoot returns the directory holding .rr.yaml, or "" if there is no
+// project config.
+func (c *Config) ProjectRoot() string {
+	return c.resolved.ProjectRoot
+}
+
+// Hosts returns the hosts the project may use, in the order rr tries them.
+func (c *Config) Hosts() ([]Host, error) {
+	names, hosts, err := config.ResolveHosts(c.resolved, "")
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Host, 0, len(names))
+	for _, name := range names {
+		out = append(out, publicHost(name, hosts[name]))
+	}
+	return out, nil
+}
+
+// Tasks returns the names of the project's tasks, sorted.
+func (c *Config) Tasks() []string {
+	if c.resolved.Project == nil {
+		return nil
+	}
+	names := make([]string, 0, len(c.resolved.Project.Tasks))
+	for name := range c.resolved.Project.Tasks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// publicHost converts a host config to the public Host.
+func publicHost(name string, h config.Host) Host {
+	return Host{
+		Name: name,
+		SSH:  append([]string(nil), h.SSH...),
+		Dir:  config.ExpandRemote(h.Dir),
+		Tags: append([]string(nil), h.Tags...),
+	}
+}
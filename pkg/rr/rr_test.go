@@ -0,0 +1,92 @@
+package rr
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeConfigs sets up a global config and a project config in temp dirs,
+// returning the project config's path.
+func writeConfigs(t *testing.T, global, project string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if global != "" {
+		require.NoError(t, os.MkdirAll(filepath.Join(home, ".rr"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(home, ".rr", "config.yaml"), []byte(global), 0644))
+	}
+	path := filepath.Join(t.TempDir(), ".rr.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(project), 0644))
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := writeConfigs(t, `version: 1
+hosts:
+  mini:
+    ssh: [mini.local, mini-ts]
+    dir: ~/rr/app
+    tags: [macos]
+  big:
+    ssh: [big]
+    dir: ~/rr/app
+`, `version: 1
+hosts: [mini, big]
+tasks:
+  test:
+    run: make test
+  lint:
+    run: make lint
+`)
+
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Dir(path), cfg.ProjectRoot())
+	assert.Equal(t, []string{"lint", "test"}, cfg.Tasks())
+
+	hosts, err := cfg.Hosts()
+	require.NoError(t, err)
+	require.Len(t, hosts, 2)
+	assert.Equal(t, "mini", hosts[0].Name)
+	assert.Equal(t, []string{"mini.local", "mini-ts"}, hosts[0].SSH)
+	assert.Equal(t, []string{"macos"}, hosts[0].Tags)
+	assert.Equal(t, "big", hosts[1].Name)
+}
+
+func TestLoadConfig_Invalid(t *testing.T) {
+	path := writeConfigs(t, "", "version: 99\n")
+
+	_, err := LoadConfig(path)
+	assert.Error(t, err)
+}
+
+func TestSession_Local(t *testing.T) {
+	path := writeConfigs(t, "version: 1\nhosts:\n  mini:\n    ssh: [mini]\n    dir: ~/rr/app\n", "version: 1\n")
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+
+	sess, err := Connect(cfg, ConnectOptions{Local: true})
+	require.NoError(t, err)
+	defer sess.Close()
+
+	assert.True(t, sess.IsLocal())
+	assert.Equal(t, Host{}, sess.Host())
+	require.NoError(t, sess.Lock("pwd"))
+	require.NoError(t, sess.Sync(SyncOptions{}))
+
+	var stdout, stderr bytes.Buffer
+	code, err := sess.Run(context.Background(), "pwd; exit 3", &stdout, &stderr)
+	require.NoError(t, err)
+	assert.Equal(t, 3, code)
+	wantDir, err := filepath.EvalSymlinks(cfg.ProjectRoot())
+	require.NoError(t, err)
+	gotDir, err := filepath.EvalSymlinks(string(bytes.TrimSpace(stdout.Bytes())))
+	require.NoError(t, err)
+	assert.Equal(t, wantDir, gotDir, "commands run in the project root")
+}
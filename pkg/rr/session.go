@@ -0,0 +1,201 @@
+package rr
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/config"
+	"github.com/rileyhilliard/rr/internal/exec"
+	"github.com/rileyhilliard/rr/internal/host"
+	"github.com/rileyhilliard/rr/internal/lock"
+	rrsync "github.com/rileyhilliard/rr/internal/sync"
+)
+
+// ConnectOptions picks the host Connect uses. With nothing set, Connect
+// tries the project's hosts in order, like 'rr run' without flags.
+type ConnectOptions struct {
+	// Host is the name of the host to use.
+	Host string
+
+	// Tag limits the choice to hosts with this tag.
+	Tag string
+
+	// Local runs everything on this machine instead of a host.
+	Local bool
+
+	// ProbeTimeout overrides how long each SSH connection attempt may take.
+	ProbeTimeout time.Duration
+
+	// WorkDir is the local project directory. It defaults to the
+	// project root, or the current directory without a project config.
+	WorkDir string
+}
+
+// SyncOptions controls Session.Sync.
+type SyncOptions struct {
+	// Progress receives rsync's progress output. Nil discards it.
+	Progress io.Writer
+
+	// Force syncs even when the host's directory is a git checkout with
+	// uncommitted changes, which the sync would overwrite.
+	Force bool
+}
+
+// Session is a connection to the host Connect picked. It isn't safe for
+// concurrent use.
+type Session struct {
+	cfg      *Config
+	selector *host.Selector
+	conn     *host.Connection
+	workDir  string
+	lock     *lock.Lock
+}
+
+// Connect picks a host for the project and connects to it, falling back
+// through the hosts (and to local execution, if the config allows it) the
+// way the CLI does.
+func Connect(cfg *Config, opts ConnectOptions) (*Session, error) {
+	resolved := cfg.resolved
+
+	workDir := opts.WorkDir
+	if workDir == "" {
+		workDir = resolved.ProjectRoot
+	}
+	if workDir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		workDir = wd
+	}
+
+	var selector *host.Selector
+	if opts.Local {
+		selector = host.NewSelector(make(map[string]config.Host))
+		selector.SetLocalFallback(true)
+	} else {
+		order, hosts, err := config.ResolveHosts(resolved, opts.Host)
+		if err != nil {
+			return nil, err
+		}
+		selector = host.NewSelector(hosts)
+		selector.SetHostOrder(order)
+		selector.SetLocalFallback(config.ResolveLocalFallback(resolved))
+	}
+
+	timeout := resolved.Global.Defaults.ProbeTimeout
+	if opts.ProbeTimeout > 0 {
+		timeout = opts.ProbeTimeout
+	}
+	if timeout > 0 {
+		selector.SetTimeout(timeout)
+	}
+
+	var conn *host.Connection
+	var err error
+	if opts.Tag != "" && !opts.Local {
+		conn, err = selector.SelectByTag(opts.Tag)
+	} else {
+		conn, err = selector.Select(opts.Host)
+	}
+	if err != nil {
+		selector.Close() //nolint:errcheck // Nothing connected to close
+		return nil, err
+	}
+
+	return &Session{cfg: cfg, selector: selector, conn: conn, workDir: workDir}, nil
+}
+
+// Host returns the host the session is connected to. It's empty when the
+// session runs locally.
+func (s *Session) Host() Host {
+	if s.conn.IsLocal {
+		return Host{}
+	}
+	return publicHost(s.conn.Name, s.conn.Host)
+}
+
+// IsLocal reports whether commands run on this machine rather than a host.
+func (s *Session) IsLocal() bool {
+	return s.conn.IsLocal
+}
+
+// Lock takes the host's lock so other rr users wait for this session, and
+// keeps it alive until Unlock or Close. command is shown to whoever is
+// waiting. It does nothing when locking is disabled or the session is
+// local.
+func (s *Session) Lock(command string) error {
+	lockCfg := s.project().Lock
+	if !lockCfg.Enabled || s.conn.IsLocal || s.lock != nil {
+		return nil
+	}
+	l, err := lock.Acquire(s.conn, lockCfg, command)
+	if err != nil {
+		return err
+	}
+	l.StartHeartbeat()
+	s.lock = l
+	return nil
+}
+
+// Unlock releases the lock taken by Lock.
+func (s *Session) Unlock() error {
+	if s.lock == nil {
+		return nil
+	}
+	err := s.lock.Release()
+	s.lock = nil
+	return err
+}
+
+// Sync copies the project to the host with the project's sync rules. It
+// does nothing when the session is local.
+func (s *Session) Sync(opts SyncOptions) error {
+	if s.conn.IsLocal {
+		return nil
+	}
+	syncCfg := s.project().Sync
+	if !opts.Force {
+		if err := rrsync.CheckRemoteEdits(s.conn, s.workDir, syncCfg); err != nil {
+			return err
+		}
+	}
+	if err := rrsync.InvalidateStaleDirectories(s.conn, s.workDir, syncCfg.Invalidations, func(string, string) {}); err != nil {
+		return err
+	}
+	return rrsync.Sync(s.conn, s.workDir, syncCfg, opts.Progress)
+}
+
+// Run runs a shell command in the project directory on the host, streaming
+// its output, and returns its exit code. The project's defaults.setup
+// commands and the host's env and shell apply as they do for 'rr run'.
+// Canceling ctx stops a remote command; a local one runs to completion.
+func (s *Session) Run(ctx context.Context, command string, stdout, stderr io.Writer) (int, error) {
+	if s.conn.IsLocal {
+		return exec.ExecuteLocal(command, s.workDir, stdout, stderr)
+	}
+	if setup := s.project().Defaults.Setup; len(setup) > 0 {
+		command = strings.Join(setup, " && ") + " && " + command
+	}
+	return s.conn.Client.ExecStreamContext(ctx, exec.BuildRemoteCommand(command, &s.conn.Host), stdout, stderr)
+}
+
+// Close releases the lock, if held, and closes the connection.
+func (s *Session) Close() error {
+	err := s.Unlock()
+	if closeErr := s.selector.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// project returns the project config, or the defaults without one.
+func (s *Session) project() *config.Config {
+	if s.cfg.resolved.Project != nil {
+		return s.cfg.resolved.Project
+	}
+	return config.DefaultConfig()
+}
@@ -0,0 +1,120 @@
+package sshutil
+
+import (
+	"io"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Auth methods a client can authenticate with.
+const (
+	AuthAgent       = "agent"       // A key held by the SSH agent
+	AuthKey         = "key"         // A private key file
+	AuthCertificate = "certificate" // A certificate for a private key file
+	AuthSystemSSH   = "system-ssh"  // Whatever the system's ssh used (see SystemClient)
+)
+
+// Auth describes how a client authenticated.
+type Auth struct {
+	Method string // AuthAgent, AuthKey, AuthCertificate or AuthSystemSSH; empty if unknown
+	File   string // The key or certificate file, for AuthKey and AuthCertificate
+}
+
+// authRecorder remembers which key last signed an auth request. The SSH
+// library only signs with keys the server has accepted, so once the
+// handshake succeeds that's the one the client authenticated with.
+type authRecorder struct {
+	mu   sync.Mutex
+	used Auth
+}
+
+func (r *authRecorder) record(a Auth) {
+	r.mu.Lock()
+	r.used = a
+	r.mu.Unlock()
+}
+
+// Used returns how the client authenticated. A nil recorder knows nothing.
+func (r *authRecorder) Used() Auth {
+	if r == nil {
+		return Auth{}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.used
+}
+
+// wrap returns signer, recording a whenever it signs. The wrapper keeps the
+// signature algorithms signer supports. A nil recorder returns signer as is.
+func (r *authRecorder) wrap(signer ssh.Signer, a Auth) ssh.Signer {
+	if r == nil {
+		return signer
+	}
+	switch s := signer.(type) {
+	case ssh.MultiAlgorithmSigner:
+		return &recordingMultiSigner{MultiAlgorithmSigner: s, rec: r, auth: a}
+	case ssh.AlgorithmSigner:
+		return &recordingAlgorithmSigner{AlgorithmSigner: s, rec: r, auth: a}
+	default:
+		return &recordingSigner{Signer: s, rec: r, auth: a}
+	}
+}
+
+// wrapCallback is wrap for every signer a callback returns.
+func (r *authRecorder) wrapCallback(signers func() ([]ssh.Signer, error), a Auth) func() ([]ssh.Signer, error) {
+	return func() ([]ssh.Signer, error) {
+		list, err := signers()
+		if err != nil {
+			return nil, err
+		}
+		wrapped := make([]ssh.Signer, len(list))
+		for i, s := range list {
+			wrapped[i] = r.wrap(s, a)
+		}
+		return wrapped, nil
+	}
+}
+
+type recordingSigner struct {
+	ssh.Signer
+	rec  *authRecorder
+	auth Auth
+}
+
+func (s *recordingSigner) Sign(rand io.Reader, data []byte) (*ssh.Signature, error) {
+	s.rec.record(s.auth)
+	return s.Signer.Sign(rand, data)
+}
+
+type recordingAlgorithmSigner struct {
+	ssh.AlgorithmSigner
+	rec  *authRecorder
+	auth Auth
+}
+
+func (s *recordingAlgorithmSigner) Sign(rand io.Reader, data []byte) (*ssh.Signature, error) {
+	s.rec.record(s.auth)
+	return s.AlgorithmSigner.Sign(rand, data)
+}
+
+func (s *recordingAlgorithmSigner) SignWithAlgorithm(rand io.Reader, data []byte, algorithm string) (*ssh.Signature, error) {
+	s.rec.record(s.auth)
+	return s.AlgorithmSigner.SignWithAlgorithm(rand, data, algorithm)
+}
+
+type recordingMultiSigner struct {
+	ssh.MultiAlgorithmSigner
+	rec  *authRecorder
+	auth Auth
+}
+
+func (s *recordingMultiSigner) Sign(rand io.Reader, data []byte) (*ssh.Signature, error) {
+	s.rec.record(s.auth)
+	return s.MultiAlgorithmSigner.Sign(rand, data)
+}
+
+func (s *recordingMultiSigner) SignWithAlgorithm(rand io.Reader, data []byte, algorithm string) (*ssh.Signature, error) {
+	s.rec.record(s.auth)
+	return s.MultiAlgorithmSigner.SignWithAlgorithm(rand, data, algorithm)
+}
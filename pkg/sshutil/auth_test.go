@@ -0,0 +1,100 @@
+package sshutil
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	stderrors "errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestKeyFileSigners_RecordsWhichKeySigned(t *testing.T) {
+	now := uint64(time.Now().Unix())
+	keyPath := writeCertKey(t, t.TempDir(), now-60, now+3600)
+
+	rec := &authRecorder{}
+	signers, err := keyFileSigners(keyPath, CertPath(keyPath), "", rec)
+	if err != nil {
+		t.Fatalf("keyFileSigners() error = %v", err)
+	}
+	if len(signers) != 2 {
+		t.Fatalf("keyFileSigners() = %d signers, want the certificate and the key", len(signers))
+	}
+	if got := rec.Used(); got != (Auth{}) {
+		t.Errorf("Used() before signing = %+v, want nothing", got)
+	}
+
+	// The wrappers keep the signature algorithms the SSH library negotiates with
+	for i, s := range signers {
+		if _, ok := s.(ssh.AlgorithmSigner); !ok {
+			t.Errorf("signer %d lost ssh.AlgorithmSigner", i)
+		}
+	}
+
+	if _, err := signers[0].(ssh.AlgorithmSigner).SignWithAlgorithm(rand.Reader, []byte("data"), ssh.KeyAlgoED25519); err != nil {
+		t.Fatalf("SignWithAlgorithm() error = %v", err)
+	}
+	if want := (Auth{Method: AuthCertificate, File: CertPath(keyPath)}); rec.Used() != want {
+		t.Errorf("Used() = %+v, want %+v", rec.Used(), want)
+	}
+
+	if _, err := signers[1].Sign(rand.Reader, []byte("data")); err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if want := (Auth{Method: AuthKey, File: keyPath}); rec.Used() != want {
+		t.Errorf("Used() = %+v, want %+v", rec.Used(), want)
+	}
+}
+
+func TestAuthRecorder_Nil(t *testing.T) {
+	var rec *authRecorder
+	if got := rec.Used(); got != (Auth{}) {
+		t.Errorf("Used() = %+v, want nothing", got)
+	}
+
+	now := uint64(time.Now().Unix())
+	keyPath := writeCertKey(t, t.TempDir(), now-60, now+3600)
+	signers, err := keyFileSigners(keyPath, "", "", rec)
+	if err != nil {
+		t.Fatalf("keyFileSigners() error = %v", err)
+	}
+	switch signers[0].(type) {
+	case *recordingSigner, *recordingAlgorithmSigner, *recordingMultiSigner:
+		t.Error("a nil recorder shouldn't wrap signers")
+	}
+}
+
+func TestKeyFileSigners_Passphrase(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(priv, "", []byte("hunter2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "id_ed25519")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := keyFileSigners(keyPath, "", "hunter2", nil); err != nil {
+		t.Errorf("keyFileSigners() with the passphrase error = %v", err)
+	}
+
+	_, err = keyFileSigners(keyPath, "", "", nil)
+	var encErr *EncryptedKeyError
+	if !stderrors.As(err, &encErr) {
+		t.Errorf("keyFileSigners() without a passphrase error = %v, want EncryptedKeyError", err)
+	}
+
+	_, err = keyFileSigners(keyPath, "", "wrong", nil)
+	if err == nil || stderrors.As(err, &encErr) {
+		t.Errorf("keyFileSigners() with the wrong passphrase error = %v, want a decryption error", err)
+	}
+}
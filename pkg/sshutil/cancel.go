@@ -0,0 +1,89 @@
+package sshutil
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Canceling a remote command has to stop everything it started, not just the
+// shell sshd ran it in. The SSH "signal" request only reaches that shell (and
+// OpenSSH ignores it for sessions without a PTY), and closing the session
+// leaves the command running with nobody reading its output. So
+// ExecStreamContext has the shell record its PID, which is also the ID of the
+// process group sshd put it in, and on cancel signals that whole group from a
+// second session.
+
+// CancelGrace is how long a canceled command gets to exit after SIGINT before
+// it's sent SIGTERM.
+var CancelGrace = 5 * time.Second
+
+// cancelKillWait is how long to wait after SIGTERM before SIGKILL, and after
+// SIGKILL before giving up on the command.
+const cancelKillWait = 2 * time.Second
+
+// newPGIDFile returns a unique remote path for a command's process group ID.
+func newPGIDFile() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return "/tmp/rr-" + hex.EncodeToString(b) + ".pgid"
+}
+
+// withProcessGroup prefixes cmd so the shell running it writes its PID to
+// file, and removes the file when it exits. sshd starts every session in a
+// new process group led by that shell, so the PID is the group's ID. A failed
+// write only costs the group kill on cancel, so it stays quiet.
+func withProcessGroup(cmd, file string) string {
+	return fmt.Sprintf("{ echo $$ > %s; } 2>/dev/null; trap 'rm -f %s' EXIT; %s", file, file, cmd)
+}
+
+// signalGroupCommand sends sig to the process group recorded in file, or to
+// just the process if it isn't a group leader.
+func signalGroupCommand(file, sig string) string {
+	return fmt.Sprintf("pid=$(cat %s 2>/dev/null) && { kill -%s -$pid 2>/dev/null || kill -%s $pid 2>/dev/null; }", file, sig, sig)
+}
+
+// stopRemote stops a canceled command. It sends SIGINT to the command's
+// process group and gives it CancelGrace to shut down, then SIGTERM, then
+// SIGKILL. Returns the command's exit code, or 130 if it never exited.
+func (c *Client) stopRemote(session *ssh.Session, done <-chan error, file string) int {
+	// Reaches the shell on servers that honor signal requests
+	_ = session.Signal(ssh.SIGINT)
+
+	return stopProcessGroup(c, done, file, exitCodeFromError, func() { session.Close() })
+}
+
+// remoteExecer runs rr's own commands on a host, for stopProcessGroup.
+type remoteExecer interface {
+	Exec(cmd string) (stdout, stderr []byte, exitCode int, err error)
+}
+
+// stopProcessGroup signals the process group recorded in file through
+// client, escalating from SIGINT to SIGKILL while waiting on done for the
+// command to exit. exitCode turns done's result into an exit code, and
+// abandon gives up on a command that outlived SIGKILL, returning 130.
+func stopProcessGroup(client remoteExecer, done <-chan error, file string, exitCode func(error) int, abandon func()) int {
+	defer client.Exec("rm -f " + file) //nolint:errcheck // Best effort; /tmp gets cleaned eventually
+
+	for _, step := range []struct {
+		sig  string
+		wait time.Duration
+	}{
+		{"INT", CancelGrace},
+		{"TERM", cancelKillWait},
+		{"KILL", cancelKillWait},
+	} {
+		client.Exec(signalGroupCommand(file, step.sig)) //nolint:errcheck // The wait below tells whether it worked
+		select {
+		case runErr := <-done:
+			return exitCode(runErr)
+		case <-time.After(step.wait):
+		}
+	}
+
+	abandon()
+	return 130
+}
@@ -0,0 +1,113 @@
+//go:build !windows
+
+package sshutil
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestNewPGIDFile(t *testing.T) {
+	a, b := newPGIDFile(), newPGIDFile()
+	if a == b {
+		t.Errorf("newPGIDFile() returned %q twice", a)
+	}
+	if !strings.HasPrefix(a, "/tmp/rr-") || !strings.HasSuffix(a, ".pgid") {
+		t.Errorf("newPGIDFile() = %q, want /tmp/rr-*.pgid", a)
+	}
+}
+
+func TestWithProcessGroup(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "cmd.pgid")
+	seen := filepath.Join(dir, "seen")
+
+	cmd := exec.Command("sh", "-c", withProcessGroup("cat "+file+" > "+seen+"; exit 3", file))
+	err := cmd.Run()
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok || exitErr.ExitCode() != 3 {
+		t.Fatalf("Run() = %v, want exit code 3 to pass through", err)
+	}
+
+	data, err := os.ReadFile(seen)
+	if err != nil {
+		t.Fatalf("command didn't see the pgid file: %v", err)
+	}
+	if got := strings.TrimSpace(string(data)); got != strconv.Itoa(cmd.Process.Pid) {
+		t.Errorf("pgid file = %q, want the shell's PID %d", got, cmd.Process.Pid)
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("pgid file still exists after the shell exited")
+	}
+}
+
+func TestWithProcessGroup_UnwritableFile(t *testing.T) {
+	cmd := exec.Command("sh", "-c", withProcessGroup("echo ok", "/nonexistent/dir/cmd.pgid"))
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+	if string(out) != "ok\n" {
+		t.Errorf("output = %q, want only the command's output", out)
+	}
+}
+
+func TestSignalGroupCommand(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "cmd.pgid")
+
+	// A shell leading its own process group, like sshd starts it, with a
+	// child that would outlive it if only the shell were signaled
+	cmd := exec.Command("sh", "-c", withProcessGroup("sleep 30 & wait", file))
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(file); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("pgid file never appeared")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if out, err := exec.Command("sh", "-c", signalGroupCommand(file, "TERM")).CombinedOutput(); err != nil {
+		t.Fatalf("signal command failed: %v: %s", err, out)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		t.Fatal("process group didn't exit after SIGTERM")
+	}
+	// The background sleep gets reaped by init, so give it a moment
+	deadline = time.Now().Add(5 * time.Second)
+	for syscall.Kill(-cmd.Process.Pid, 0) == nil {
+		if time.Now().After(deadline) {
+			_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+			t.Fatal("a process in the group survived SIGTERM")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSignalGroupCommand_NoFile(t *testing.T) {
+	cmd := exec.Command("sh", "-c", signalGroupCommand(filepath.Join(t.TempDir(), "missing.pgid"), "INT"))
+	out, _ := cmd.CombinedOutput()
+	if len(out) != 0 {
+		t.Errorf("output = %q, want nothing", out)
+	}
+}
@@ -0,0 +1,80 @@
+package sshutil
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// CertPath returns where OpenSSH looks for the certificate of the private key
+// at keyPath: the key's path with "-cert.pub" appended.
+func CertPath(keyPath string) string {
+	return keyPath + "-cert.pub"
+}
+
+// LoadCertificate reads an OpenSSH certificate file, like
+// ~/.ssh/id_ed25519-cert.pub.
+func LoadCertificate(path string) (*ssh.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pubKey, _, _, _, err := ssh.ParseAuthorizedKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse %s: %w", path, err)
+	}
+	cert, ok := pubKey.(*ssh.Certificate)
+	if !ok {
+		return nil, fmt.Errorf("%s isn't a certificate", path)
+	}
+	return cert, nil
+}
+
+// CertExpiry returns when a certificate stops being valid, or false if it
+// never expires.
+func CertExpiry(cert *ssh.Certificate) (time.Time, bool) {
+	if cert.ValidBefore == ssh.CertTimeInfinity || cert.ValidBefore > uint64(1<<63-1) {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(cert.ValidBefore), 0), true
+}
+
+// certValidAt reports whether cert's validity window includes now.
+func certValidAt(cert *ssh.Certificate, now time.Time) bool {
+	unix := now.Unix()
+	if unix < 0 {
+		return false
+	}
+	return uint64(unix) >= cert.ValidAfter && uint64(unix) < cert.ValidBefore
+}
+
+// certSigner pairs signer with the certificate at certPath, if there is one
+// and it's usable now. Returns nil otherwise. A certificate that's there but
+// can't be used gets a warning, since the server will likely reject the bare
+// key it falls back to.
+func certSigner(signer ssh.Signer, certPath string) ssh.Signer {
+	if _, err := os.Stat(certPath); err != nil {
+		return nil
+	}
+	cert, err := LoadCertificate(certPath)
+	if err != nil {
+		emitWarning(fmt.Sprintf("Found certificate %s but couldn't read it: %v", certPath, err))
+		return nil
+	}
+	if now := time.Now(); !certValidAt(cert, now) {
+		if expiry, ok := CertExpiry(cert); ok && !now.Before(expiry) {
+			emitWarning(fmt.Sprintf("Certificate %s expired %s - get a new one from your CA", certPath, expiry.Local().Format(time.RFC1123)))
+		} else {
+			emitWarning(fmt.Sprintf("Certificate %s isn't valid yet - check your clock", certPath))
+		}
+		return nil
+	}
+	s, err := ssh.NewCertSigner(cert, signer)
+	if err != nil {
+		emitWarning(fmt.Sprintf("Found certificate %s but couldn't use it: %v", certPath, err))
+		return nil
+	}
+	return s
+}
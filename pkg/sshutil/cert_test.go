@@ -0,0 +1,179 @@
+package sshutil
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// writeCertKey writes a fresh ed25519 key to dir, plus a certificate for it
+// signed by a throwaway CA and valid from validAfter until validBefore.
+// Returns the key's path.
+func writeCertKey(t *testing.T, dir string, validAfter, validBefore uint64) string {
+	t.Helper()
+
+	_, caPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caSigner, err := ssh.NewSignerFromKey(caPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userPub, userPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sshPub, err := ssh.NewPublicKey(userPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert := &ssh.Certificate{
+		Key:             sshPub,
+		CertType:        ssh.UserCert,
+		KeyId:           "test",
+		ValidPrincipals: []string{"dev"},
+		ValidAfter:      validAfter,
+		ValidBefore:     validBefore,
+	}
+	if err := cert.SignCert(rand.Reader, caSigner); err != nil {
+		t.Fatal(err)
+	}
+
+	block, err := ssh.MarshalPrivateKey(userPriv, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(dir, "id_ed25519")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(CertPath(keyPath), ssh.MarshalAuthorizedKey(cert), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return keyPath
+}
+
+// captureWarnings collects emitted warnings for the rest of the test.
+func captureWarnings(t *testing.T) *[]string {
+	t.Helper()
+	var warnings []string
+	original := WarningHandler
+	WarningHandler = func(msg string) { warnings = append(warnings, msg) }
+	t.Cleanup(func() { WarningHandler = original })
+	return &warnings
+}
+
+func TestCertPath(t *testing.T) {
+	if got := CertPath("/home/dev/.ssh/id_ed25519"); got != "/home/dev/.ssh/id_ed25519-cert.pub" {
+		t.Errorf("CertPath() = %q", got)
+	}
+}
+
+func TestLoadCertificate(t *testing.T) {
+	dir := t.TempDir()
+	before := uint64(time.Now().Add(time.Hour).Unix())
+	keyPath := writeCertKey(t, dir, 0, before)
+
+	cert, err := LoadCertificate(CertPath(keyPath))
+	if err != nil {
+		t.Fatalf("LoadCertificate() failed: %v", err)
+	}
+	if cert.KeyId != "test" {
+		t.Errorf("KeyId = %q, want test", cert.KeyId)
+	}
+	expiry, ok := CertExpiry(cert)
+	if !ok || expiry.Unix() != int64(before) {
+		t.Errorf("CertExpiry() = %v, %v, want %v", expiry, ok, time.Unix(int64(before), 0))
+	}
+
+	// A plain public key isn't a certificate
+	pubPath := filepath.Join(dir, "plain.pub")
+	if err := os.WriteFile(pubPath, ssh.MarshalAuthorizedKey(cert.Key), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCertificate(pubPath); err == nil || !strings.Contains(err.Error(), "isn't a certificate") {
+		t.Errorf("LoadCertificate(public key) error = %v, want isn't a certificate", err)
+	}
+
+	if _, err := LoadCertificate(filepath.Join(dir, "missing")); err == nil {
+		t.Error("LoadCertificate(missing) should fail")
+	}
+}
+
+func TestCertExpiry_Forever(t *testing.T) {
+	if _, ok := CertExpiry(&ssh.Certificate{ValidBefore: ssh.CertTimeInfinity}); ok {
+		t.Error("CertExpiry() should report no expiry for a certificate valid forever")
+	}
+}
+
+func TestKeyFileAuthWithCert(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name         string
+		validAfter   uint64
+		validBefore  uint64
+		wantWarning  string
+		wantUsesCert bool
+	}{
+		{"valid", 0, uint64(now.Add(time.Hour).Unix()), "", true},
+		{"forever", 0, ssh.CertTimeInfinity, "", true},
+		{"expired", 0, uint64(now.Add(-time.Hour).Unix()), "expired", false},
+		{"not yet valid", uint64(now.Add(time.Hour).Unix()), ssh.CertTimeInfinity, "isn't valid yet", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings := captureWarnings(t)
+			keyPath := writeCertKey(t, t.TempDir(), tt.validAfter, tt.validBefore)
+
+			key, err := os.ReadFile(keyPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			signer, err := ssh.ParsePrivateKey(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := certSigner(signer, CertPath(keyPath)) != nil; got != tt.wantUsesCert {
+				t.Errorf("certSigner() used cert = %v, want %v", got, tt.wantUsesCert)
+			}
+
+			switch {
+			case tt.wantWarning == "" && len(*warnings) > 0:
+				t.Errorf("unexpected warnings: %v", *warnings)
+			case tt.wantWarning != "" && (len(*warnings) != 1 || !strings.Contains((*warnings)[0], tt.wantWarning)):
+				t.Errorf("warnings = %v, want one containing %q", *warnings, tt.wantWarning)
+			}
+
+			auth, err := keyFileAuthWithCert(keyPath, CertPath(keyPath))
+			if err != nil || auth == nil {
+				t.Errorf("keyFileAuthWithCert() = %v, %v, want an auth method", auth, err)
+			}
+		})
+	}
+}
+
+func TestKeyFileAuthWithCert_NoCert(t *testing.T) {
+	warnings := captureWarnings(t)
+	keyPath := writeCertKey(t, t.TempDir(), 0, ssh.CertTimeInfinity)
+	if err := os.Remove(CertPath(keyPath)); err != nil {
+		t.Fatal(err)
+	}
+
+	auth, err := keyFileAuth(keyPath)
+	if err != nil || auth == nil {
+		t.Errorf("keyFileAuth() = %v, %v, want an auth method", auth, err)
+	}
+	if len(*warnings) > 0 {
+		t.Errorf("a key without a certificate shouldn't warn, got %v", *warnings)
+	}
+}
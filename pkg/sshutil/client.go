@@ -7,6 +7,7 @@ import (
 	"log"
 	"net"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -16,6 +17,7 @@ import (
 
 	"github.com/kevinburke/ssh_config"
 	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/logger"
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/crypto/ssh/agent"
 	"golang.org/x/crypto/ssh/knownhosts"
@@ -26,6 +28,7 @@ type Client struct {
 	*ssh.Client
 	Host    string // The original host/alias used to connect
 	Address string // The resolved address (host:port)
+	Auth    Auth   // How the client authenticated
 }
 
 // matchWarningOnce ensures the SSH config Match directive warning is only shown once per process.
@@ -53,8 +56,44 @@ func emitWarning(message string) {
 //
 // Connection settings are resolved from ~/.ssh/config when available.
 func Dial(host string, timeout time.Duration) (*Client, error) {
+	return DialWithOptions(host, timeout, DialOptions{})
+}
+
+// DialOptions holds per-host overrides that don't come from ~/.ssh/config.
+type DialOptions struct {
+	// IdentityFile is a private key to authenticate with before trying the
+	// agent, the ssh_config IdentityFile, or the default keys. Usually set from
+	// a host's identity_file in rr config.
+	IdentityFile string
+
+	// IdentityPassphrase decrypts IdentityFile when it's encrypted. Without
+	// it an encrypted key is skipped, and only works through the agent.
+	IdentityPassphrase string
+
+	// Ciphers replaces the default cipher preference list when set.
+	Ciphers []string
+
+	// ServerAliveInterval sends keepalives this often once connected (see
+	// keepAlive). 0 disables them.
+	ServerAliveInterval time.Duration
+
+	// ConnectTimeout replaces the timeout passed to DialWithOptions when set.
+	ConnectTimeout time.Duration
+}
+
+// DialWithOptions is Dial with per-host overrides applied on top of the
+// settings resolved from ~/.ssh/config.
+func DialWithOptions(host string, timeout time.Duration, opts DialOptions) (*Client, error) {
 	// Resolve connection settings from SSH config
 	settings := resolveSSHSettings(host)
+	settings.auth = &authRecorder{}
+	if opts.IdentityFile != "" {
+		settings.configIdentity = expandPath(opts.IdentityFile)
+		settings.configPassphrase = opts.IdentityPassphrase
+	}
+	if opts.ConnectTimeout > 0 {
+		timeout = opts.ConnectTimeout
+	}
 
 	// Build SSH client config
 	config, err := buildSSHConfig(settings)
@@ -68,11 +107,16 @@ func Dial(host string, timeout time.Duration) (*Client, error) {
 			fmt.Sprintf("Couldn't set up SSH for '%s'", host),
 			"Check your keys are loaded: ssh-add -l")
 	}
+	if len(opts.Ciphers) > 0 {
+		config.Ciphers = opts.Ciphers
+	}
 
 	// Dial with timeout, using ProxyCommand if configured
 	address := settings.address()
+	dialStart := time.Now()
 	var conn net.Conn
 	if settings.proxyCommand != "" {
+		logger.Verbosef(logger.LevelCommands, "ssh", "%s: connecting via ProxyCommand %q", host, settings.proxyCommand)
 		conn, err = dialViaProxy(settings.proxyCommand, host, settings)
 		if err != nil {
 			return nil, errors.WrapWithCode(err, errors.ErrSSH,
@@ -80,6 +124,7 @@ func Dial(host string, timeout time.Duration) (*Client, error) {
 				"Check your ProxyCommand in ~/.ssh/config and verify it works: ssh "+host)
 		}
 	} else {
+		logger.Verbosef(logger.LevelCommands, "ssh", "%s: connecting to %s (timeout %s)", host, address, timeout)
 		conn, err = net.DialTimeout("tcp", address, timeout)
 		if err != nil {
 			return nil, errors.WrapWithCode(err, errors.ErrSSH,
@@ -100,6 +145,8 @@ func Dial(host string, timeout time.Duration) (*Client, error) {
 		})
 		defer timer.Stop()
 	}
+	logger.Verbosef(logger.LevelWire, "ssh", "%s: TCP connected in %s", host, time.Since(dialStart))
+	handshakeStart := time.Now()
 	sshConn, chans, reqs, err := ssh.NewClientConn(conn, address, config)
 	if err != nil {
 		conn.Close()
@@ -128,11 +175,22 @@ func Dial(host string, timeout time.Duration) (*Client, error) {
 			suggestion)
 	}
 
+	logger.Verbosef(logger.LevelWire, "ssh", "%s: handshake took %s", host, time.Since(handshakeStart))
+
 	client := ssh.NewClient(sshConn, chans, reqs)
+	if opts.ServerAliveInterval > 0 {
+		done := make(chan struct{})
+		go func() {
+			_ = client.Wait()
+			close(done)
+		}()
+		go keepAlive(client, opts.ServerAliveInterval, done)
+	}
 	return &Client{
 		Client:  client,
 		Host:    host,
 		Address: address,
+		Auth:    settings.auth.Used(),
 	}, nil
 }
 
@@ -175,13 +233,17 @@ func (c *Client) newSSHSession() (*ssh.Session, error) {
 
 // sshSettings holds resolved SSH connection parameters.
 type sshSettings struct {
-	hostname      string
-	port          string
-	user          string
-	identityFile  string
-	proxyCommand  string   // ProxyCommand from SSH config (if any)
-	identityAgent string   // IdentityAgent socket path from SSH config (if any)
-	encryptedKeys []string // Keys that exist but are encrypted
+	hostname         string
+	port             string
+	user             string
+	identityFile     string   // IdentityFile from SSH config (if any)
+	certFile         string   // CertificateFile from SSH config (if any)
+	configIdentity   string   // identity_file from rr config (if any), tried first
+	configPassphrase string   // Decrypts configIdentity, if set
+	proxyCommand     string   // ProxyCommand from SSH config (if any)
+	identityAgent    string   // IdentityAgent socket path from SSH config (if any)
+	encryptedKeys    []string // Keys that exist but are encrypted
+	auth             *authRecorder
 }
 
 // address returns the host:port string for dialing.
@@ -233,9 +295,9 @@ func resolveSSHSettings(host string) *sshSettings {
 	// Try to load from SSH config
 	sshConfigPath := filepath.Join(homeDir(), ".ssh", "config")
 
-	// First, try to preprocess the config to handle Match directives
-	// The kevinburke/ssh_config library doesn't support Match, so we need to
-	// strip them out and only parse content before the first Match block
+	// Preprocess the config to expand Include directives and handle Match
+	// blocks, neither of which the kevinburke/ssh_config library resolves
+	// the way OpenSSH does
 	content, matchLine, err := preprocessSSHConfig(sshConfigPath)
 	if err != nil {
 		// Config doesn't exist or can't be read, that's fine
@@ -275,6 +337,13 @@ func resolveSSHSettings(host string) *sshSettings {
 		hostFound = true
 	}
 
+	// Get certificate file, for hosts that sign in with an SSH certificate
+	// kept somewhere other than next to the key
+	if certFile, _ := cfg.Get(host, "CertificateFile"); certFile != "" {
+		settings.certFile = expandPath(certFile)
+		hostFound = true
+	}
+
 	// Get ProxyCommand
 	if proxyCmd, _ := cfg.Get(host, "ProxyCommand"); proxyCmd != "" {
 		settings.proxyCommand = proxyCmd
@@ -311,6 +380,12 @@ func resolveSSHSettings(host string) *sshSettings {
 	return settings
 }
 
+// SSHConfigIdentityFile returns the IdentityFile ~/.ssh/config sets for host,
+// with ~ expanded. Returns "" if the config doesn't set one.
+func SSHConfigIdentityFile(host string) string {
+	return resolveSSHSettings(host).identityFile
+}
+
 // StrictHostKeyChecking controls host key verification behavior.
 // When true (default), host keys are verified against ~/.ssh/known_hosts.
 // When false, host key verification is skipped (insecure, for CI/automation).
@@ -321,10 +396,24 @@ var StrictHostKeyChecking = true
 func buildSSHConfig(settings *sshSettings) (*ssh.ClientConfig, error) {
 	var authMethods []ssh.AuthMethod
 
-	// Helper to try loading a key and track encrypted keys
+	// Helper to try loading a key and track encrypted keys. The ssh config's
+	// CertificateFile, if any, goes with its IdentityFile. A passphrase that
+	// doesn't decrypt the rr config's key is kept to report.
+	var decryptErr error
 	tryKeyFile := func(keyPath string) {
-		keyAuth, err := keyFileAuth(keyPath)
+		certPath := CertPath(keyPath)
+		if settings.certFile != "" && keyPath == settings.identityFile {
+			certPath = settings.certFile
+		}
+		var passphrase string
+		if keyPath == settings.configIdentity {
+			passphrase = settings.configPassphrase
+		}
+		signers, err := keyFileSigners(keyPath, certPath, passphrase, settings.auth)
 		if err != nil {
+			if passphrase != "" {
+				decryptErr = err
+			}
 			var encErr *EncryptedKeyError
 			if stderrors.As(err, &encErr) {
 				settings.encryptedKeys = append(settings.encryptedKeys, keyPath)
@@ -332,29 +421,47 @@ func buildSSHConfig(settings *sshSettings) (*ssh.ClientConfig, error) {
 			// Other errors (file not found, etc.) are silently ignored
 			return
 		}
-		authMethods = append(authMethods, keyAuth)
+		authMethods = append(authMethods, ssh.PublicKeys(signers...))
 	}
 
 	// Check for test key override (for CI environments)
 	// When set, ONLY use this key - do NOT fall back to agent or other keys
 	testKey := os.Getenv("RR_TEST_SSH_KEY")
 	if testKey != "" {
-		keyAuth, err := keyFileAuth(testKey)
+		signers, err := keyFileSigners(testKey, CertPath(testKey), "", settings.auth)
 		if err != nil {
 			return nil, errors.WrapWithCode(err, errors.ErrSSH,
 				fmt.Sprintf("failed to load RR_TEST_SSH_KEY: %s", testKey),
 				"Check that the key file exists and is valid")
 		}
-		authMethods = append(authMethods, keyAuth)
+		authMethods = append(authMethods, ssh.PublicKeys(signers...))
 	} else {
 		// Normal mode: try multiple auth methods
-		// Try SSH agent first (most common and convenient)
-		if agentAuth := sshAgentAuth(settings.identityAgent); agentAuth != nil {
-			authMethods = append(authMethods, agentAuth)
+		// A key picked explicitly for this host in rr config goes first, so it
+		// wins over whatever the agent offers. A missing file is a config
+		// mistake worth stopping on; an encrypted one may still be in the agent.
+		if settings.configIdentity != "" {
+			if _, err := os.Stat(settings.configIdentity); err != nil {
+				return nil, errors.WrapWithCode(err, errors.ErrSSH,
+					fmt.Sprintf("identity_file %s doesn't exist", settings.configIdentity),
+					"Fix the identity_file path for this host in ~/.rr/config.yaml, or remove it to use your default keys.")
+			}
+			tryKeyFile(settings.configIdentity)
+			if decryptErr != nil {
+				return nil, errors.WrapWithCode(decryptErr, errors.ErrSSH,
+					fmt.Sprintf("identity_passphrase doesn't unlock %s", settings.configIdentity),
+					"Store the right passphrase with 'rr secrets set <name>', using the name identity_passphrase refers to.")
+			}
+		}
+
+		// Try SSH agent (most common and convenient)
+		if signers := agentSigners(settings.identityAgent); signers != nil {
+			signers = settings.auth.wrapCallback(signers, Auth{Method: AuthAgent})
+			authMethods = append(authMethods, ssh.PublicKeysCallback(signers))
 		}
 
 		// Try specific identity file from SSH config
-		if settings.identityFile != "" {
+		if settings.identityFile != "" && settings.identityFile != settings.configIdentity {
 			tryKeyFile(settings.identityFile)
 		}
 
@@ -366,7 +473,7 @@ func buildSSHConfig(settings *sshSettings) (*ssh.ClientConfig, error) {
 		}
 
 		for _, keyPath := range defaultKeys {
-			if keyPath == settings.identityFile {
+			if keyPath == settings.identityFile || keyPath == settings.configIdentity {
 				continue // Already tried this one
 			}
 			tryKeyFile(keyPath)
@@ -434,6 +541,15 @@ var (
 // If identityAgent is "none", agent auth is explicitly disabled.
 // The default SSH_AUTH_SOCK connection is cached; per-host connections are tracked for cleanup.
 func sshAgentAuth(identityAgent string) ssh.AuthMethod {
+	if signers := agentSigners(identityAgent); signers != nil {
+		return ssh.PublicKeysCallback(signers)
+	}
+	return nil
+}
+
+// agentSigners returns the SSH agent's key list for sshAgentAuth, or nil if
+// there's no agent to use or it holds no keys.
+func agentSigners(identityAgent string) func() ([]ssh.Signer, error) {
 	if identityAgent == "none" {
 		return nil
 	}
@@ -458,7 +574,7 @@ func sshAgentAuth(identityAgent string) ssh.AuthMethod {
 		perHostAgentConns = append(perHostAgentConns, conn)
 		perHostAgentConnsMu.Unlock()
 
-		return ssh.PublicKeysCallback(client.Signers)
+		return client.Signers
 	}
 
 	// Default: use SSH_AUTH_SOCK with cached connection
@@ -487,7 +603,7 @@ func sshAgentAuth(identityAgent string) ssh.AuthMethod {
 		return nil
 	}
 
-	return ssh.PublicKeysCallback(agentClient.Signers)
+	return agentClient.Signers
 }
 
 // CloseAgent closes all SSH agent connections (both default and per-host).
@@ -504,15 +620,43 @@ func CloseAgent() {
 	perHostAgentConnsMu.Unlock()
 }
 
-// keyFileAuth returns an auth method using a private key file.
+// keyFileAuth returns an auth method using a private key file, and the
+// certificate next to it if there is one.
 // Returns EncryptedKeyError if the key requires a passphrase.
 func keyFileAuth(keyPath string) (ssh.AuthMethod, error) {
+	return keyFileAuthWithCert(keyPath, CertPath(keyPath))
+}
+
+// keyFileAuthWithCert returns an auth method using a private key file. When
+// certPath holds a valid certificate for the key, the certificate is offered
+// first and the bare key after it, like OpenSSH does.
+// Returns EncryptedKeyError if the key requires a passphrase.
+func keyFileAuthWithCert(keyPath, certPath string) (ssh.AuthMethod, error) {
+	signers, err := keyFileSigners(keyPath, certPath, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	return ssh.PublicKeys(signers...), nil
+}
+
+// keyFileSigners loads the keys keyFileAuthWithCert offers: the certificate
+// first if there's a valid one, then the bare key. An encrypted key is
+// decrypted with passphrase if there is one. rec, if set, records which one
+// the server accepts.
+func keyFileSigners(keyPath, certPath, passphrase string, rec *authRecorder) ([]ssh.Signer, error) {
 	key, err := os.ReadFile(keyPath)
 	if err != nil {
 		return nil, err
 	}
 
 	signer, err := ssh.ParsePrivateKey(key)
+	var missing *ssh.PassphraseMissingError
+	if passphrase != "" && stderrors.As(err, &missing) {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(key, []byte(passphrase))
+		if err != nil {
+			return nil, fmt.Errorf("can't decrypt %s with its identity_passphrase: %w", keyPath, err)
+		}
+	}
 	if err != nil {
 		// Check if key is encrypted (requires passphrase)
 		// This can be detected either from the error message or by checking PEM headers
@@ -524,23 +668,11 @@ func keyFileAuth(keyPath string) (ssh.AuthMethod, error) {
 		return nil, err
 	}
 
-	// Check for an accompanying SSH certificate file (e.g. id_ed25519-cert.pub).
-	// If found, combine it with the private key for certificate-based auth.
-	certPath := keyPath + "-cert.pub"
-	if certData, err := os.ReadFile(certPath); err == nil {
-		pubKey, _, _, _, parseErr := ssh.ParseAuthorizedKey(certData)
-		if parseErr != nil {
-			emitWarning(fmt.Sprintf("Found certificate %s but couldn't parse it: %v", certPath, parseErr))
-		} else if cert, ok := pubKey.(*ssh.Certificate); !ok {
-			emitWarning(fmt.Sprintf("Found %s but it's not a certificate", certPath))
-		} else if certSigner, certErr := ssh.NewCertSigner(cert, signer); certErr != nil {
-			emitWarning(fmt.Sprintf("Found certificate %s but couldn't use it: %v", certPath, certErr))
-		} else {
-			return ssh.PublicKeys(certSigner), nil
-		}
+	keySigner := rec.wrap(signer, Auth{Method: AuthKey, File: keyPath})
+	if cs := certSigner(signer, certPath); cs != nil {
+		return []ssh.Signer{rec.wrap(cs, Auth{Method: AuthCertificate, File: certPath}), keySigner}, nil
 	}
-
-	return ssh.PublicKeys(signer), nil
+	return []ssh.Signer{keySigner}, nil
 }
 
 // Helper functions
@@ -655,48 +787,208 @@ func (e *HostKeyMismatchError) Suggestion() string {
 		wantStr, e.ReceivedType, host, e.KnownHosts, host)
 }
 
-// preprocessSSHConfig reads the SSH config and strips out Match blocks.
-// Match blocks are not supported by the ssh_config library, so we skip them
-// but continue parsing Host blocks that come after.
-// Returns the line number of the first Match directive found (0 if none).
+// maxIncludeDepth caps nested Include directives, matching OpenSSH's limit.
+// It also stops a config that includes itself from recursing forever.
+const maxIncludeDepth = 16
+
+// preprocessSSHConfig reads the SSH config and rewrites it into something the
+// ssh_config library can enumerate and query:
+//   - Include directives are expanded inline, so hosts defined in included
+//     files (e.g., "Include ~/.ssh/config.d/*") show up like any other host.
+//   - Match blocks with criteria we can evaluate (all, host, originalhost,
+//     localuser) become equivalent Host blocks, or are dropped if they can
+//     never match. Other Match blocks (exec, user, canonical, ...) are
+//     skipped, but Host blocks after them are still parsed.
+//
+// Returns the line number of the first skipped Match directive (0 if none).
+// For a Match inside an included file, that's the line of the Include that
+// pulled it in.
 func preprocessSSHConfig(configPath string) ([]byte, int, error) {
 	content, err := os.ReadFile(configPath)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	lines := strings.Split(string(content), "\n")
-	var result []string
-	matchLine := 0
-	inMatchBlock := false
+	p := &sshConfigPreprocessor{localUser: currentUser()}
+	p.process(content, 0, 0)
 
-	for i, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		lower := strings.ToLower(trimmed)
+	return []byte(strings.Join(p.result, "\n")), p.matchLine, nil
+}
+
+// sshConfigPreprocessor carries state while flattening an SSH config and
+// the files it includes.
+type sshConfigPreprocessor struct {
+	result    []string
+	matchLine int
+	localUser string
+	header    string // Host line of the block being emitted ("" before the first one)
+	skipping  bool   // Inside a Match block that's skipped
+}
 
-		// Match directive starts a block we need to skip
-		if strings.HasPrefix(lower, "match ") {
-			if matchLine == 0 {
-				matchLine = i + 1 // Record first Match line (1-indexed)
+// process appends one file's lines to the result. includeLine is the line of
+// the top-level Include this file came from (0 for the top-level file).
+func (p *sshConfigPreprocessor) process(content []byte, depth, includeLine int) {
+	for i, line := range strings.Split(string(content), "\n") {
+		lineNum := i + 1
+		if includeLine > 0 {
+			lineNum = includeLine
+		}
+
+		keyword, args := splitSSHConfigLine(line)
+		switch strings.ToLower(keyword) {
+		case "match":
+			header, ok := translateMatch(args, p.localUser)
+			if !ok {
+				if p.matchLine == 0 {
+					p.matchLine = lineNum
+				}
+				p.skipping = true
+				continue
+			}
+			if header == "" {
+				// Evaluated, and it can never match this machine
+				p.skipping = true
+				continue
+			}
+			p.skipping = false
+			p.header = header
+			p.result = append(p.result, header)
+			continue
+
+		case "host":
+			p.skipping = false
+			p.header = line
+
+		case "include":
+			if p.skipping || depth >= maxIncludeDepth {
+				continue
+			}
+			header := p.header
+			for _, path := range resolveIncludes(args) {
+				data, err := os.ReadFile(path)
+				if err != nil {
+					continue // OpenSSH ignores includes it can't read
+				}
+				p.process(data, depth+1, lineNum)
+			}
+			// An included file that opens its own Host blocks doesn't end
+			// the block the Include sits in, so reopen it.
+			if p.header != header || p.skipping {
+				p.skipping = false
+				p.header = header
+				if header == "" {
+					p.result = append(p.result, "Host *")
+				} else {
+					p.result = append(p.result, header)
+				}
 			}
-			inMatchBlock = true
 			continue
 		}
 
-		// Host directive ends a Match block
-		if strings.HasPrefix(lower, "host ") {
-			inMatchBlock = false
+		if p.skipping {
+			continue
 		}
+		p.result = append(p.result, line)
+	}
+}
 
-		// Skip lines inside Match blocks
-		if inMatchBlock {
+// splitSSHConfigLine splits a config line into its keyword and arguments.
+// Keywords are separated from arguments by whitespace or "=".
+func splitSSHConfigLine(line string) (string, string) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return "", ""
+	}
+	idx := strings.IndexAny(trimmed, " \t=")
+	if idx == -1 {
+		return trimmed, ""
+	}
+	args := strings.TrimSpace(trimmed[idx:])
+	args = strings.TrimSpace(strings.TrimPrefix(args, "="))
+	return trimmed[:idx], args
+}
+
+// resolveIncludes expands the paths of an Include directive. Relative paths
+// are relative to ~/.ssh, as they are for user configs in OpenSSH. Globs are
+// expanded in lexical order; paths that match nothing are ignored.
+func resolveIncludes(args string) []string {
+	var paths []string
+	for _, arg := range strings.Fields(args) {
+		arg = strings.Trim(arg, `"`)
+		path := expandPath(arg)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(homeDir(), ".ssh", path)
+		}
+		matches, err := filepath.Glob(path)
+		if err != nil {
+			continue
+		}
+		paths = append(paths, matches...)
+	}
+	return paths
+}
+
+// translateMatch evaluates a Match directive's criteria. It returns the Host
+// line the block is equivalent to, "" if the block can never match, or
+// ok=false if a criterion can't be evaluated without connecting (exec, user,
+// canonical, ...).
+func translateMatch(args, localUser string) (header string, ok bool) {
+	tokens := strings.Fields(args)
+	if len(tokens) == 0 {
+		return "", false
+	}
+
+	hostPatterns := ""
+	matches := true
+	for i := 0; i < len(tokens); i++ {
+		switch strings.ToLower(tokens[i]) {
+		case "all":
 			continue
+		case "host", "originalhost":
+			// Two host criteria would need both pattern lists to match,
+			// which a single Host line can't express.
+			if i+1 >= len(tokens) || hostPatterns != "" {
+				return "", false
+			}
+			i++
+			hostPatterns = tokens[i]
+		case "localuser":
+			if i+1 >= len(tokens) {
+				return "", false
+			}
+			i++
+			if !matchPatternList(localUser, tokens[i]) {
+				matches = false
+			}
+		default:
+			return "", false
 		}
+	}
 
-		result = append(result, line)
+	if !matches {
+		return "", true
 	}
+	if hostPatterns == "" {
+		return "Host *", true
+	}
+	return "Host " + strings.ReplaceAll(hostPatterns, ",", " "), true
+}
 
-	return []byte(strings.Join(result, "\n")), matchLine, nil
+// matchPatternList reports whether value matches an ssh_config pattern list
+// ("alice,b*,!bob"). A matching negated pattern always wins.
+func matchPatternList(value, list string) bool {
+	found := false
+	for _, pattern := range strings.Split(list, ",") {
+		negated := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimPrefix(pattern, "!")
+		if ok, _ := path.Match(pattern, value); ok {
+			if negated {
+				return false
+			}
+			found = true
+		}
+	}
+	return found
 }
 
 // isEncryptedPEM checks if PEM data contains encryption markers.
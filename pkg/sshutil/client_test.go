@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -507,7 +508,7 @@ func TestPreprocessSSHConfig_WithMatch(t *testing.T) {
     HostName 192.168.1.100
     User admin
 
-Match host *.example.com
+Match exec "test -f /tmp/on-vpn"
     User special
 
 Host aftermatch
@@ -548,7 +549,7 @@ func TestPreprocessSSHConfig_MatchCaseInsensitive(t *testing.T) {
 	configContent := `Host myserver
     HostName 192.168.1.100
 
-MATCH host *.example.com
+MATCH exec "test -f /tmp/on-vpn"
     User special
 `
 	if err := os.WriteFile(configPath, []byte(configContent), 0600); err != nil {
@@ -565,6 +566,135 @@ MATCH host *.example.com
 	}
 }
 
+func TestPreprocessSSHConfig_EvaluatesMatch(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config")
+	configContent := `Match host *.example.com,!skip.example.com
+    User hostmatch
+
+Match localuser ` + currentUser() + `
+    ForwardAgent yes
+
+Match localuser nobody-like-this
+    User never
+
+Match all
+    ServerAliveInterval 30
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0600))
+
+	content, matchLine, err := preprocessSSHConfig(configPath)
+	require.NoError(t, err)
+
+	// Everything was evaluable, so nothing was skipped
+	assert.Equal(t, 0, matchLine)
+	assert.NotContains(t, string(content), "Match")
+	assert.Contains(t, string(content), "Host *.example.com !skip.example.com\n    User hostmatch")
+	assert.Contains(t, string(content), "ForwardAgent yes")
+	assert.NotContains(t, string(content), "never")
+	assert.Contains(t, string(content), "Host *\n    ServerAliveInterval 30")
+}
+
+func TestPreprocessSSHConfig_ExpandsInclude(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	sshDir := filepath.Join(home, ".ssh")
+	require.NoError(t, os.MkdirAll(filepath.Join(sshDir, "config.d"), 0700))
+
+	require.NoError(t, os.WriteFile(filepath.Join(sshDir, "config.d", "b-work"), []byte(`Host work-box
+    HostName work.example.com
+`), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(sshDir, "config.d", "a-home"), []byte(`Host home-box
+    HostName home.example.com
+`), 0600))
+
+	configPath := filepath.Join(sshDir, "config")
+	configContent := `Host gpu
+    HostName gpu.local
+    Include config.d/*
+    User gpuuser
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0600))
+
+	content, _, err := preprocessSSHConfig(configPath)
+	require.NoError(t, err)
+
+	text := string(content)
+	assert.NotContains(t, text, "Include")
+	// Glob matches are expanded in lexical order
+	assert.Less(t, strings.Index(text, "home-box"), strings.Index(text, "work-box"))
+	// The enclosing Host block is reopened after the included hosts
+	assert.Contains(t, text, "Host gpu\n    User gpuuser")
+}
+
+func TestPreprocessSSHConfig_IncludeLoop(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	sshDir := filepath.Join(home, ".ssh")
+	require.NoError(t, os.MkdirAll(sshDir, 0700))
+
+	configPath := filepath.Join(sshDir, "config")
+	configContent := `Host loop
+    HostName loop.local
+Include config
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0600))
+
+	content, _, err := preprocessSSHConfig(configPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "loop.local")
+}
+
+func TestPreprocessSSHConfig_SkippedMatchInInclude(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	sshDir := filepath.Join(home, ".ssh")
+	require.NoError(t, os.MkdirAll(sshDir, 0700))
+
+	require.NoError(t, os.WriteFile(filepath.Join(sshDir, "extra"), []byte(`Match exec "true"
+    User special
+`), 0600))
+
+	configPath := filepath.Join(sshDir, "config")
+	require.NoError(t, os.WriteFile(configPath, []byte("Host a\n    HostName a.local\n\nInclude extra\n"), 0600))
+
+	content, matchLine, err := preprocessSSHConfig(configPath)
+	require.NoError(t, err)
+
+	// Reported at the Include line in the top-level config
+	assert.Equal(t, 4, matchLine)
+	assert.NotContains(t, string(content), "special")
+}
+
+func TestTranslateMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   string
+		header string
+		ok     bool
+	}{
+		{"all", "all", "Host *", true},
+		{"host list", "host a,b*", "Host a b*", true},
+		{"originalhost", "originalhost gpu", "Host gpu", true},
+		{"localuser matches", "localuser alice host gpu", "Host gpu", true},
+		{"localuser negated", "localuser !alice", "", true},
+		{"localuser other", "localuser bob", "", true},
+		{"exec", `exec "test -f x"`, "", false},
+		{"remote user", "user git", "", false},
+		{"two host criteria", "host a host b", "", false},
+		{"missing argument", "host", "", false},
+		{"empty", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, ok := translateMatch(tt.args, "alice")
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.header, header)
+		})
+	}
+}
+
 // Tests for encrypted key error
 
 func TestEncryptedKeyError(t *testing.T) {
@@ -1248,7 +1378,7 @@ func TestPreprocessSSHConfig_IndentedMatch(t *testing.T) {
 	configContent := `Host myserver
     HostName 192.168.1.100
 
-    Match host *.example.com
+    Match canonical host *.example.com
     User special
 `
 	err := os.WriteFile(configPath, []byte(configContent), 0600)
@@ -1600,3 +1730,55 @@ func TestClient_Methods(t *testing.T) {
 func init() {
 	// Empty init - imports are needed for the tests
 }
+
+func TestBuildSSHConfig_ConfigIdentityMissing(t *testing.T) {
+	t.Setenv("RR_TEST_SSH_KEY", "")
+
+	settings := &sshSettings{
+		hostname:       "example.com",
+		port:           "22",
+		user:           "testuser",
+		configIdentity: "/nonexistent/work_ed25519",
+	}
+
+	_, err := buildSSHConfig(settings)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "identity_file /nonexistent/work_ed25519 doesn't exist")
+}
+
+func TestBuildSSHConfig_ConfigIdentityUsed(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not available")
+	}
+	t.Setenv("RR_TEST_SSH_KEY", "")
+	t.Setenv("SSH_AUTH_SOCK", "")
+	t.Setenv("HOME", t.TempDir()) // No default keys to fall back on
+
+	keyPath := filepath.Join(t.TempDir(), "work_ed25519")
+	require.NoError(t, exec.Command("ssh-keygen", "-t", "ed25519", "-f", keyPath, "-N", "", "-C", "test").Run())
+
+	originalValue := StrictHostKeyChecking
+	StrictHostKeyChecking = false
+	defer func() { StrictHostKeyChecking = originalValue }()
+
+	settings := &sshSettings{
+		hostname:       "example.com",
+		port:           "22",
+		user:           "testuser",
+		configIdentity: keyPath,
+	}
+
+	config, err := buildSSHConfig(settings)
+	require.NoError(t, err)
+	assert.Len(t, config.Auth, 1)
+}
+
+func TestWithCLocale(t *testing.T) {
+	cmd := WithCLocale("top -l 1 -n 0")
+	assert.Equal(t, "export LC_ALL=C; top -l 1 -n 0", cmd)
+
+	// The command's own output really comes out in the C locale
+	out, err := exec.Command("sh", "-c", WithCLocale("echo $LC_ALL")).Output()
+	require.NoError(t, err)
+	assert.Equal(t, "C\n", string(out))
+}
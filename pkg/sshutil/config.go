@@ -43,7 +43,8 @@ func (h SSHHostEntry) Description() string {
 }
 
 // ParseSSHConfig parses ~/.ssh/config and returns all host entries.
-// It filters out wildcards and includes hosts, returning only concrete host aliases.
+// Hosts from Include'd files and evaluable Match blocks are included; wildcard
+// and negated patterns are filtered out, leaving only concrete host aliases.
 func ParseSSHConfig() ([]SSHHostEntry, error) {
 	configPath := filepath.Join(homeDir(), ".ssh", "config")
 	return ParseSSHConfigFile(configPath)
@@ -71,8 +72,8 @@ func ParseSSHConfigFile(configPath string) ([]SSHHostEntry, error) {
 		for _, pattern := range host.Patterns {
 			alias := pattern.String()
 
-			// Skip wildcards and special patterns
-			if strings.Contains(alias, "*") || strings.Contains(alias, "?") {
+			// Skip wildcards, negations, and special patterns
+			if strings.Contains(alias, "*") || strings.Contains(alias, "?") || strings.HasPrefix(alias, "!") {
 				continue
 			}
 
@@ -374,3 +374,44 @@ Host secure-server
 	assert.Len(t, hosts, 1)
 	assert.Contains(t, hosts[0].IdentityFile, "special_key")
 }
+
+func TestParseSSHConfigFile_IncludeAndMatch(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	sshDir := filepath.Join(home, ".ssh")
+	require.NoError(t, os.MkdirAll(filepath.Join(sshDir, "config.d"), 0700))
+
+	err := os.WriteFile(filepath.Join(sshDir, "config.d", "work"), []byte(`
+Host work-box
+    HostName work.example.com
+    User worker
+`), 0600)
+	require.NoError(t, err)
+
+	configPath := filepath.Join(sshDir, "config")
+	configContent := `
+Include ~/.ssh/config.d/*
+
+Match host match-box,!excluded
+    HostName match.example.com
+
+Match exec "test -f /tmp/on-vpn"
+    User vpnuser
+
+Host main-box
+    HostName main.example.com
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0600))
+
+	hosts, err := ParseSSHConfigFile(configPath)
+	require.NoError(t, err)
+
+	// Included and Match-defined hosts are listed; the negation is not
+	require.Len(t, hosts, 3)
+	assert.Equal(t, "main-box", hosts[0].Alias)
+	assert.Equal(t, "match-box", hosts[1].Alias)
+	assert.Equal(t, "match.example.com", hosts[1].Hostname)
+	assert.Equal(t, "work-box", hosts[2].Alias)
+	assert.Equal(t, "work.example.com", hosts[2].Hostname)
+	assert.Equal(t, "worker", hosts[2].User)
+}
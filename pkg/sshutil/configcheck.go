@@ -0,0 +1,208 @@
+package sshutil
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// SSHConfigValue is one value an SSH config gives a keyword, and where.
+type SSHConfigValue struct {
+	Keyword string // As the config spells it
+	Value   string
+	File    string // The config file, or a file it includes
+	Line    int
+	Match   bool // Set in a Match block rather than a Host block
+	// Everyone is set for every host, by "Host *", "Match all" or a line
+	// before the first block.
+	Everyone bool
+}
+
+// SSHConfigHost is what an SSH config sets for one alias, in the order ssh
+// reads it. For most keywords ssh uses the first value it finds.
+type SSHConfigHost struct {
+	Alias string
+	// Defined is the Host line that names the alias itself rather than
+	// matching it with a wildcard. Its Line is zero if no block does.
+	Defined SSHConfigValue
+	Values  map[string][]SSHConfigValue // Keyed by lowercase keyword
+}
+
+// First returns the value ssh uses for keyword, if the config sets it.
+func (h SSHConfigHost) First(keyword string) (SSHConfigValue, bool) {
+	values := h.Values[strings.ToLower(keyword)]
+	if len(values) == 0 {
+		return SSHConfigValue{}, false
+	}
+	return values[0], true
+}
+
+// TargetHost returns the host part of an SSH target like "user@host:2222",
+// which is what ssh matches Host blocks against.
+func TargetHost(target string) string {
+	if at := strings.LastIndex(target, "@"); at != -1 {
+		target = target[at+1:]
+	}
+	if colon := strings.LastIndex(target, ":"); colon != -1 && !strings.Contains(target[:colon], ":") {
+		port := target[colon+1:]
+		if port != "" && strings.Trim(port, "0123456789") == "" {
+			target = target[:colon]
+		}
+	}
+	return target
+}
+
+// LookupSSHConfig reads ~/.ssh/config and returns what it sets for each
+// alias. A missing config returns no hosts.
+func LookupSSHConfig(aliases []string) (map[string]SSHConfigHost, error) {
+	return LookupSSHConfigFile(filepath.Join(homeDir(), ".ssh", "config"), aliases)
+}
+
+// LookupSSHConfigFile is LookupSSHConfig for the config at configPath.
+// Includes are followed, and Match blocks are applied where they can be
+// evaluated without connecting, as preprocessSSHConfig does. Unlike the
+// ssh_config library, it keeps every value and where it came from, so
+// callers can point at the line to fix.
+func LookupSSHConfigFile(configPath string, aliases []string) (map[string]SSHConfigHost, error) {
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	r := &sshConfigReader{localUser: currentUser()}
+	r.read(configPath, content, 0)
+
+	hosts := make(map[string]SSHConfigHost, len(aliases))
+	for _, alias := range aliases {
+		h := SSHConfigHost{Alias: alias, Values: make(map[string][]SSHConfigValue)}
+		for _, b := range r.blocks {
+			if b.skip || !hostPatternsMatch(alias, b.patterns) {
+				continue
+			}
+			if h.Defined.Line == 0 && !b.match && namesHost(alias, b.patterns) {
+				h.Defined = SSHConfigValue{Value: strings.Join(b.patterns, " "), File: b.file, Line: b.line}
+			}
+			for _, kv := range b.values {
+				key := strings.ToLower(kv.keyword)
+				h.Values[key] = append(h.Values[key], SSHConfigValue{
+					Keyword: kv.keyword, Value: kv.value, File: kv.file, Line: kv.line,
+					Match: b.match, Everyone: len(b.patterns) == 1 && b.patterns[0] == "*",
+				})
+			}
+		}
+		hosts[alias] = h
+	}
+	return hosts, nil
+}
+
+// sshConfigBlock is a Host or Match block, or the lines before the first one.
+type sshConfigBlock struct {
+	patterns []string // Host patterns the block applies to
+	match    bool
+	skip     bool // A Match block that can't be evaluated, or never matches
+	file     string
+	line     int
+	values   []sshConfigKeyword
+}
+
+type sshConfigKeyword struct {
+	keyword string
+	value   string
+	file    string // Lines an Include pulls into a block come from another file
+	line    int
+}
+
+// sshConfigReader splits an SSH config and the files it includes into blocks.
+type sshConfigReader struct {
+	blocks    []*sshConfigBlock
+	localUser string
+}
+
+func (r *sshConfigReader) current(file string) *sshConfigBlock {
+	if len(r.blocks) == 0 {
+		// Lines before the first Host or Match apply to every host
+		r.blocks = append(r.blocks, &sshConfigBlock{patterns: []string{"*"}, file: file})
+	}
+	return r.blocks[len(r.blocks)-1]
+}
+
+func (r *sshConfigReader) read(file string, content []byte, depth int) {
+	for i, line := range strings.Split(string(content), "\n") {
+		lineNum := i + 1
+		keyword, args := splitSSHConfigLine(line)
+		switch strings.ToLower(keyword) {
+		case "":
+			continue
+
+		case "host":
+			r.blocks = append(r.blocks, &sshConfigBlock{patterns: strings.Fields(args), file: file, line: lineNum})
+
+		case "match":
+			b := &sshConfigBlock{match: true, file: file, line: lineNum}
+			header, ok := translateMatch(args, r.localUser)
+			if !ok || header == "" {
+				b.skip = true
+			} else {
+				b.patterns = strings.Fields(strings.TrimPrefix(header, "Host "))
+			}
+			r.blocks = append(r.blocks, b)
+
+		case "include":
+			enclosing := r.current(file)
+			if enclosing.skip || depth >= maxIncludeDepth {
+				continue
+			}
+			for _, p := range resolveIncludes(args) {
+				data, err := os.ReadFile(p)
+				if err != nil {
+					continue // OpenSSH ignores includes it can't read
+				}
+				r.read(p, data, depth+1)
+			}
+			// An included file that opens its own blocks doesn't end the
+			// block the Include sits in
+			if r.current(file) != enclosing {
+				reopened := *enclosing
+				reopened.values = nil
+				r.blocks = append(r.blocks, &reopened)
+			}
+
+		default:
+			b := r.current(file)
+			b.values = append(b.values, sshConfigKeyword{keyword: keyword, value: strings.Trim(args, `"`), file: file, line: lineNum})
+		}
+	}
+}
+
+// hostPatternsMatch reports whether host matches a Host line's patterns. A
+// matching negated pattern always wins. ssh compares host names without
+// regard to case.
+func hostPatternsMatch(host string, patterns []string) bool {
+	host = strings.ToLower(host)
+	found := false
+	for _, pattern := range patterns {
+		negated := strings.HasPrefix(pattern, "!")
+		pattern = strings.ToLower(strings.TrimPrefix(pattern, "!"))
+		if ok, _ := path.Match(pattern, host); ok {
+			if negated {
+				return false
+			}
+			found = true
+		}
+	}
+	return found
+}
+
+// namesHost reports whether a Host line names host itself.
+func namesHost(host string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if strings.EqualFold(pattern, host) {
+			return true
+		}
+	}
+	return false
+}
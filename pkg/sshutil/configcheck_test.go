@@ -0,0 +1,86 @@
+package sshutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupSSHConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config")
+	extra := filepath.Join(dir, "extra")
+	require.NoError(t, os.WriteFile(extra, []byte(`Host gpu
+    HostName gpu.example.com
+`), 0600))
+	require.NoError(t, os.WriteFile(configPath, []byte(`ServerAliveInterval 30
+
+Host mini mini-lan
+    User riley
+    Include `+extra+`
+    Port 2222
+
+Match host mini exec "true"
+    User ignored
+
+Match host mini
+    User deploy
+
+Host *
+    User fallback
+`), 0600))
+
+	hosts, err := LookupSSHConfigFile(configPath, []string{"mini", "gpu", "other"})
+	require.NoError(t, err)
+
+	mini := hosts["mini"]
+	assert.Equal(t, 3, mini.Defined.Line)
+	assert.Equal(t, "mini mini-lan", mini.Defined.Value)
+
+	users := mini.Values["user"]
+	require.Len(t, users, 3, "skips the Match it can't evaluate")
+	assert.Equal(t, SSHConfigValue{Keyword: "User", Value: "riley", File: configPath, Line: 4}, users[0])
+	assert.Equal(t, SSHConfigValue{Keyword: "User", Value: "deploy", File: configPath, Line: 12, Match: true}, users[1])
+	assert.True(t, users[2].Everyone)
+
+	port, ok := mini.First("port")
+	require.True(t, ok)
+	assert.Equal(t, 6, port.Line, "the block goes on after an Include")
+
+	interval, ok := mini.First("ServerAliveInterval")
+	require.True(t, ok)
+	assert.True(t, interval.Everyone)
+
+	gpuName, ok := hosts["gpu"].First("HostName")
+	require.True(t, ok)
+	assert.Equal(t, extra, gpuName.File)
+	assert.Equal(t, 2, gpuName.Line)
+
+	assert.Zero(t, hosts["other"].Defined.Line)
+	_, ok = hosts["other"].First("HostName")
+	assert.False(t, ok)
+}
+
+func TestLookupSSHConfigFile_Missing(t *testing.T) {
+	hosts, err := LookupSSHConfigFile(filepath.Join(t.TempDir(), "config"), []string{"mini"})
+	require.NoError(t, err)
+	assert.Nil(t, hosts)
+}
+
+func TestTargetHost(t *testing.T) {
+	tests := map[string]string{
+		"mini":                "mini",
+		"riley@mini":          "mini",
+		"riley@mini.lan:2222": "mini.lan",
+		"10.0.0.5:22":         "10.0.0.5",
+		"fe80::1":             "fe80::1",
+		"user@host:notaport":  "host:notaport",
+		"a@b@gpu.example.com": "gpu.example.com",
+	}
+	for target, want := range tests {
+		assert.Equal(t, want, TargetHost(target), target)
+	}
+}
@@ -8,13 +8,23 @@ import (
 	"time"
 
 	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/logger"
 	"golang.org/x/crypto/ssh"
 )
 
 // Exec runs a command on the remote host and returns the output.
 // Returns stdout, stderr, exit code, and any error.
 // Exit code is -1 if the command couldn't be executed at all.
+//
+// Exec is for rr's own probes, whose output gets parsed, so the command runs
+// in the C locale (see WithCLocale).
 func (c *Client) Exec(cmd string) (stdout, stderr []byte, exitCode int, err error) {
+	logger.Verbosef(logger.LevelCommands, "ssh", "%s: exec %s", c.Host, cmd)
+	start := time.Now()
+	defer func() {
+		logger.Verbosef(logger.LevelWire, "ssh", "%s: exit %d after %s", c.Host, exitCode, time.Since(start))
+	}()
+
 	session, err := c.newSSHSession()
 	if err != nil {
 		return nil, nil, -1, errors.WrapWithCode(err, errors.ErrSSH,
@@ -28,7 +38,7 @@ func (c *Client) Exec(cmd string) (stdout, stderr []byte, exitCode int, err erro
 	session.Stderr = &stderrBuf
 
 	exitCode = 0
-	err = session.Run(cmd)
+	err = session.Run(WithCLocale(cmd))
 	if err != nil {
 		if exitErr, ok := err.(*ssh.ExitError); ok {
 			exitCode = exitErr.ExitStatus()
@@ -51,10 +61,17 @@ func (c *Client) ExecStream(cmd string, stdout, stderr io.Writer) (exitCode int,
 }
 
 // ExecStreamContext runs a command with context cancellation support.
-// When the context is cancelled, SIGINT is sent to the remote process.
+// When the context is cancelled, the command and everything it started get
+// SIGINT, then SIGTERM and SIGKILL if they don't exit (see stopRemote).
 // Returns the exit code and any error.
 // Exit code is -1 if the command couldn't be executed at all.
 func (c *Client) ExecStreamContext(ctx context.Context, cmd string, stdout, stderr io.Writer) (exitCode int, err error) {
+	logger.Verbosef(logger.LevelCommands, "ssh", "%s: exec %s", c.Host, cmd)
+	start := time.Now()
+	defer func() {
+		logger.Verbosef(logger.LevelWire, "ssh", "%s: exit %d after %s", c.Host, exitCode, time.Since(start))
+	}()
+
 	session, err := c.newSSHSession()
 	if err != nil {
 		return -1, errors.WrapWithCode(err, errors.ErrSSH,
@@ -66,8 +83,15 @@ func (c *Client) ExecStreamContext(ctx context.Context, cmd string, stdout, stde
 	session.Stdout = stdout
 	session.Stderr = stderr
 
+	return c.runContext(ctx, session, cmd)
+}
+
+// runContext starts cmd in session and waits for it, stopping it if ctx is
+// canceled first.
+func (c *Client) runContext(ctx context.Context, session *ssh.Session, cmd string) (int, error) {
 	// Start the command (non-blocking)
-	if err := session.Start(cmd); err != nil {
+	pgidFile := newPGIDFile()
+	if err := session.Start(withProcessGroup(cmd, pgidFile)); err != nil {
 		return -1, errors.WrapWithCode(err, errors.ErrExec,
 			fmt.Sprintf("Couldn't start: %s", cmd),
 			"Make sure the command exists on the remote.")
@@ -81,16 +105,7 @@ func (c *Client) ExecStreamContext(ctx context.Context, cmd string, stdout, stde
 
 	select {
 	case <-ctx.Done():
-		// Context cancelled - send SIGINT to the remote process
-		_ = session.Signal(ssh.SIGINT)
-		// Give the remote process time to handle SIGINT before force-closing
-		select {
-		case runErr := <-done:
-			return exitCodeFromError(runErr), ctx.Err()
-		case <-time.After(3 * time.Second):
-			session.Close()
-			return 130, ctx.Err()
-		}
+		return c.stopRemote(session, done, pgidFile), ctx.Err()
 	case runErr := <-done:
 		return exitCodeFromError(runErr), nil
 	}
@@ -150,6 +165,45 @@ func (c *Client) ExecPTY(cmd string, stdout, stderr io.Writer) (exitCode int, er
 	return exitCode, nil
 }
 
+// ExecPTYContext runs a command with a pseudo-terminal allocated, feeding it
+// stdin, with the same cancellation as ExecStreamContext. A terminal has no
+// separate stderr, so all output goes to stdout. Newlines aren't turned into
+// "\r\n", so output reads the same as without a terminal.
+// Exit code is -1 if the command couldn't be executed at all.
+func (c *Client) ExecPTYContext(ctx context.Context, cmd string, stdin io.Reader, stdout io.Writer) (exitCode int, err error) {
+	logger.Verbosef(logger.LevelCommands, "ssh", "%s: exec (pty) %s", c.Host, cmd)
+	start := time.Now()
+	defer func() {
+		logger.Verbosef(logger.LevelWire, "ssh", "%s: exit %d after %s", c.Host, exitCode, time.Since(start))
+	}()
+
+	session, err := c.newSSHSession()
+	if err != nil {
+		return -1, errors.WrapWithCode(err, errors.ErrSSH,
+			"Couldn't create an SSH session",
+			"The connection might have dropped. Try reconnecting.")
+	}
+	defer session.Close()
+
+	modes := ssh.TerminalModes{
+		ssh.ECHO:          0, // Keep what's typed, like passwords, out of the output
+		ssh.ONLCR:         0,
+		ssh.TTY_OP_ISPEED: 14400,
+		ssh.TTY_OP_OSPEED: 14400,
+	}
+	if err := session.RequestPty("xterm", 40, 200, modes); err != nil {
+		return -1, errors.WrapWithCode(err, errors.ErrSSH,
+			"Couldn't allocate a PTY",
+			"The remote might not support pseudo-terminals.")
+	}
+
+	session.Stdin = stdin
+	session.Stdout = stdout
+	session.Stderr = stdout
+
+	return c.runContext(ctx, session, cmd)
+}
+
 // ExecInteractive runs a command with full stdin/stdout/stderr handling.
 // This allows for interactive commands where input is needed.
 func (c *Client) ExecInteractive(cmd string, stdin io.Reader, stdout, stderr io.Writer) (exitCode int, err error) {
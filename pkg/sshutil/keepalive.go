@@ -0,0 +1,53 @@
+package sshutil
+
+import "time"
+
+// serverAliveCountMax is how many keepalives in a row can go unanswered
+// before the connection is dropped (OpenSSH's ServerAliveCountMax default).
+const serverAliveCountMax = 3
+
+// keepAliveConn is the part of an SSH connection keepAlive uses.
+type keepAliveConn interface {
+	SendRequest(name string, wantReply bool, payload []byte) (bool, []byte, error)
+	Close() error
+}
+
+// keepAlive sends a keepalive request every interval until done is closed,
+// like OpenSSH's ServerAliveInterval. The connection is closed once
+// serverAliveCountMax in a row go unanswered, so commands on a dead link
+// fail instead of hanging, and NAT or firewall state stays fresh on idle ones.
+func keepAlive(conn keepAliveConn, interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	missed := 0
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		reply := make(chan error, 1)
+		go func() {
+			_, _, err := conn.SendRequest("keepalive@openssh.com", true, nil)
+			reply <- err
+		}()
+
+		select {
+		case <-done:
+			return
+		case err := <-reply:
+			if err != nil {
+				return // The connection is already gone
+			}
+			missed = 0
+		case <-time.After(interval):
+			missed++
+			if missed >= serverAliveCountMax {
+				conn.Close() //nolint:errcheck // Dropping a dead connection
+				return
+			}
+		}
+	}
+}
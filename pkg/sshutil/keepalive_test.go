@@ -0,0 +1,71 @@
+package sshutil
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeKeepAliveConn answers keepalives after delay, or fails them with err.
+type fakeKeepAliveConn struct {
+	delay    time.Duration
+	err      error
+	requests atomic.Int32
+	closed   atomic.Bool
+}
+
+func (c *fakeKeepAliveConn) SendRequest(name string, _ bool, _ []byte) (bool, []byte, error) {
+	c.requests.Add(1)
+	time.Sleep(c.delay)
+	return true, nil, c.err
+}
+
+func (c *fakeKeepAliveConn) Close() error {
+	c.closed.Store(true)
+	return nil
+}
+
+func TestKeepAlive_StopsWhenDone(t *testing.T) {
+	conn := &fakeKeepAliveConn{}
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		keepAlive(conn, 5*time.Millisecond, done)
+		close(finished)
+	}()
+
+	assert.Eventually(t, func() bool { return conn.requests.Load() >= 3 }, time.Second, time.Millisecond)
+	close(done)
+	assert.Eventually(t, func() bool {
+		select {
+		case <-finished:
+			return true
+		default:
+			return false
+		}
+	}, time.Second, time.Millisecond)
+	assert.False(t, conn.closed.Load(), "an answering connection is left open")
+}
+
+func TestKeepAlive_ClosesUnresponsiveConnection(t *testing.T) {
+	conn := &fakeKeepAliveConn{delay: time.Hour}
+	done := make(chan struct{})
+	defer close(done)
+
+	keepAlive(conn, 5*time.Millisecond, done)
+
+	assert.True(t, conn.closed.Load())
+	assert.Equal(t, int32(serverAliveCountMax), conn.requests.Load())
+}
+
+func TestKeepAlive_StopsWhenConnectionFails(t *testing.T) {
+	conn := &fakeKeepAliveConn{err: errors.New("connection lost")}
+
+	keepAlive(conn, 5*time.Millisecond, make(chan struct{}))
+
+	assert.False(t, conn.closed.Load())
+	assert.Equal(t, int32(1), conn.requests.Load())
+}
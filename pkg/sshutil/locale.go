@@ -0,0 +1,14 @@
+package sshutil
+
+// cLocalePrefix exports the C locale ahead of a command. Works in sh, bash,
+// zsh and fish.
+const cLocalePrefix = "export LC_ALL=C; "
+
+// WithCLocale prefixes cmd so it runs in the C locale. rr parses the output
+// of the commands it runs for itself (top, vm_stat, ps, stat, rsync), and a
+// remote with a localized locale would otherwise print translated messages,
+// comma decimals and thousands separators that parse into the wrong numbers.
+// User commands are left alone; they run in whatever locale the host has.
+func WithCLocale(cmd string) string {
+	return cLocalePrefix + cmd
+}
@@ -0,0 +1,305 @@
+package sshutil
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/rileyhilliard/rr/internal/errors"
+	"github.com/rileyhilliard/rr/internal/logger"
+)
+
+// systemSSHBinary is the ssh that SystemClient runs. Swappable for tests.
+var systemSSHBinary = "ssh"
+
+// systemControlDir holds the master connections SystemClient's commands
+// share. It's the directory rsync's connections use too (see internal/sync),
+// so a sync and the commands around it go over one connection.
+var systemControlDir = fmt.Sprintf("/tmp/rr-ssh-%d", os.Getuid())
+
+// systemDialGrace is how long past the connect timeout the first connection
+// may take to authenticate, which can be slow with Kerberos or a hardware
+// key, before DialSystem gives up on it.
+const systemDialGrace = 15 * time.Second
+
+// SystemClient runs commands through the system's ssh binary rather than
+// rr's own SSH client, for hosts with transport: system-ssh. It works with
+// anything ssh does (GSSAPI, key types and agents rr doesn't support,
+// unusual ProxyCommands), at the cost of an ssh process per command. The
+// processes share a master connection (ControlMaster), so only the first
+// one pays for the handshake.
+type SystemClient struct {
+	Host    string   // The original host/alias used to connect
+	Address string   // host:port as ssh resolves it
+	Args    []string // Extra ssh flags, like -i and -o options
+
+	binary  string
+	timeout time.Duration
+}
+
+// DialSystem connects to host with the system's ssh, passing it args ahead
+// of rr's own options. ssh keeps the first value it's given for an option,
+// so args win. ssh runs in batch mode, like it does for rsync: it can use
+// the agent, Kerberos tickets and keys without passphrases, but it can't
+// ask for a password.
+func DialSystem(host string, timeout time.Duration, args []string) (*SystemClient, error) {
+	binary, err := exec.LookPath(systemSSHBinary)
+	if err != nil {
+		return nil, errors.WrapWithCode(err, errors.ErrSSH,
+			fmt.Sprintf("'%s' uses transport: system-ssh, but there's no ssh on your PATH", host),
+			"Install OpenSSH, or remove 'transport: system-ssh' from the host to use rr's built-in client.")
+	}
+	if runtime.GOOS != "windows" {
+		_ = os.MkdirAll(systemControlDir, 0700)
+	}
+
+	c := &SystemClient{Host: host, Address: host, Args: args, binary: binary, timeout: timeout}
+	if out, err := exec.Command(binary, append(append([]string{}, args...), "-G", host)...).Output(); err == nil {
+		c.Address = resolvedAddress(out, host)
+	}
+
+	logger.Verbosef(logger.LevelCommands, "ssh", "%s: connecting with %s (timeout %s)", host, binary, timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout+systemDialGrace)
+	defer cancel()
+	var stderr bytes.Buffer
+	cmd := c.command(ctx, false, "true")
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		cause := strings.TrimSpace(stderr.String())
+		if ctx.Err() != nil {
+			cause = fmt.Sprintf("ssh timed out after %s", timeout+systemDialGrace)
+		}
+		if cause == "" {
+			cause = err.Error()
+		}
+		return nil, errors.WrapWithCode(stderrors.New(cause), errors.ErrSSH,
+			fmt.Sprintf("ssh couldn't connect to '%s'", host),
+			fmt.Sprintf("rr runs the same thing as 'ssh %s', so check that works on its own (without a password prompt).", host))
+	}
+	return c, nil
+}
+
+// resolvedAddress picks host:port out of 'ssh -G' output, falling back to
+// host.
+func resolvedAddress(out []byte, host string) string {
+	var hostname, port string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		key, value, _ := strings.Cut(scanner.Text(), " ")
+		switch key {
+		case "hostname":
+			hostname = value
+		case "port":
+			port = value
+		}
+	}
+	if hostname == "" || port == "" {
+		return host
+	}
+	return net.JoinHostPort(hostname, port)
+}
+
+// command returns the ssh invocation that runs cmd on the host, on a
+// terminal when tty is set. ssh gets its own process group, so a Ctrl+C
+// meant for rr doesn't kill it before rr can stop the remote command.
+func (c *SystemClient) command(ctx context.Context, tty bool, cmd string) *exec.Cmd {
+	args := append([]string{}, c.Args...)
+	if runtime.GOOS != "windows" {
+		args = append(args,
+			"-o", "ControlMaster=auto",
+			"-o", "ControlPath="+systemControlDir+"/%h-%p",
+			"-o", "ControlPersist=60")
+	}
+	args = append(args, "-o", "BatchMode=yes")
+	if c.timeout > 0 {
+		args = append(args, "-o", fmt.Sprintf("ConnectTimeout=%d", int64((c.timeout+time.Second-1)/time.Second)))
+	}
+	if tty {
+		args = append(args, "-tt")
+	} else {
+		args = append(args, "-T")
+	}
+	args = append(args, c.Host, cmd)
+
+	var command *exec.Cmd
+	if ctx != nil {
+		command = exec.CommandContext(ctx, c.binary, args...)
+	} else {
+		command = exec.Command(c.binary, args...)
+	}
+	setProcessGroup(command)
+	return command
+}
+
+// Exec runs a command on the remote host and returns the output, like
+// Client.Exec. Exit code is -1 if the command couldn't be executed at all.
+func (c *SystemClient) Exec(cmd string) (stdout, stderr []byte, exitCode int, err error) {
+	logger.Verbosef(logger.LevelCommands, "ssh", "%s: exec %s", c.Host, cmd)
+	start := time.Now()
+	defer func() {
+		logger.Verbosef(logger.LevelWire, "ssh", "%s: exit %d after %s", c.Host, exitCode, time.Since(start))
+	}()
+
+	var stdoutBuf, stderrBuf bytes.Buffer
+	command := c.command(nil, false, WithCLocale(cmd))
+	command.Stdout = &stdoutBuf
+	command.Stderr = &stderrBuf
+
+	exitCode, err = systemExitCode(command.Run())
+	if err == nil && exitCode == 255 && sshFailed(stderrBuf.Bytes()) {
+		err = stderrors.New(strings.TrimSpace(stderrBuf.String()))
+	}
+	if err != nil {
+		return nil, nil, -1, errors.WrapWithCode(err, errors.ErrExec,
+			fmt.Sprintf("Couldn't run: %s", cmd),
+			"The connection might have dropped. Try reconnecting.")
+	}
+	return stdoutBuf.Bytes(), stderrBuf.Bytes(), exitCode, nil
+}
+
+// ExecStream runs a command and streams output to the provided writers.
+// Exit code is -1 if the command couldn't be executed at all.
+func (c *SystemClient) ExecStream(cmd string, stdout, stderr io.Writer) (exitCode int, err error) {
+	return c.ExecStreamContext(context.Background(), cmd, stdout, stderr)
+}
+
+// ExecStreamContext runs a command with the same cancellation as
+// Client.ExecStreamContext: the command and everything it started get
+// SIGINT, then SIGTERM and SIGKILL if they don't exit.
+// Exit code is -1 if the command couldn't be executed at all.
+func (c *SystemClient) ExecStreamContext(ctx context.Context, cmd string, stdout, stderr io.Writer) (exitCode int, err error) {
+	logger.Verbosef(logger.LevelCommands, "ssh", "%s: exec %s", c.Host, cmd)
+	start := time.Now()
+	defer func() {
+		logger.Verbosef(logger.LevelWire, "ssh", "%s: exit %d after %s", c.Host, exitCode, time.Since(start))
+	}()
+	return c.runContext(ctx, false, cmd, nil, stdout, stderr)
+}
+
+// ExecPTYContext runs a command on a terminal, feeding it stdin, like
+// Client.ExecPTYContext. The terminal is set not to echo input or turn
+// newlines into "\r\n", as Client's is.
+// Exit code is -1 if the command couldn't be executed at all.
+func (c *SystemClient) ExecPTYContext(ctx context.Context, cmd string, stdin io.Reader, stdout io.Writer) (exitCode int, err error) {
+	logger.Verbosef(logger.LevelCommands, "ssh", "%s: exec (pty) %s", c.Host, cmd)
+	start := time.Now()
+	defer func() {
+		logger.Verbosef(logger.LevelWire, "ssh", "%s: exit %d after %s", c.Host, exitCode, time.Since(start))
+	}()
+	return c.runContext(ctx, true, "stty -echo -onlcr 2>/dev/null; "+cmd, stdin, stdout, stdout)
+}
+
+// runContext runs cmd and waits for it, stopping it if ctx is canceled
+// first.
+func (c *SystemClient) runContext(ctx context.Context, tty bool, cmd string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	pgidFile := newPGIDFile()
+	command := c.command(nil, tty, withProcessGroup(cmd, pgidFile))
+	command.Stdin = stdin
+	command.Stdout = stdout
+	command.Stderr = stderr
+	if err := command.Start(); err != nil {
+		return -1, errors.WrapWithCode(err, errors.ErrExec,
+			fmt.Sprintf("Couldn't start ssh for: %s", cmd),
+			"Check that ssh runs: "+c.binary+" -V")
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- command.Wait()
+	}()
+
+	select {
+	case <-ctx.Done():
+		exitCode := func(err error) int {
+			code, _ := systemExitCode(err)
+			return code
+		}
+		return stopProcessGroup(c, done, pgidFile, exitCode, func() { _ = command.Process.Kill() }), ctx.Err()
+	case runErr := <-done:
+		return systemExitCode(runErr)
+	}
+}
+
+// systemExitCode turns what running ssh returned into the remote command's
+// exit code. ssh exits with the command's status, or 255 if ssh itself
+// failed.
+func systemExitCode(err error) (int, error) {
+	if err == nil {
+		return 0, nil
+	}
+	var exitErr *exec.ExitError
+	if stderrors.As(err, &exitErr) {
+		return exitErr.ExitCode(), nil
+	}
+	return -1, err
+}
+
+// sshFailed reports whether stderr holds ssh's own error, telling an ssh
+// failure apart from a command that exited 255.
+func sshFailed(stderr []byte) bool {
+	for _, line := range strings.Split(string(stderr), "\n") {
+		if strings.HasPrefix(line, "ssh: ") || strings.HasPrefix(line, "Connection ") || strings.HasPrefix(line, "kex_exchange_identification") {
+			return true
+		}
+	}
+	return false
+}
+
+// Close does nothing: the master connection closes on its own a minute
+// after the last command (ControlPersist), which leaves it up for rsync.
+func (c *SystemClient) Close() error {
+	return nil
+}
+
+// GetHost returns the original host/alias used to connect.
+func (c *SystemClient) GetHost() string {
+	return c.Host
+}
+
+// GetAddress returns the resolved host:port address.
+func (c *SystemClient) GetAddress() string {
+	return c.Address
+}
+
+// NewSession checks the host still answers. There's no session to keep
+// with the system ssh, so the one returned does nothing.
+func (c *SystemClient) NewSession() (Session, error) {
+	if err := c.ping(); err != nil {
+		return nil, err
+	}
+	return systemSession{}, nil
+}
+
+// SendRequest checks the host still answers, standing in for a keepalive.
+func (c *SystemClient) SendRequest(name string, wantReply bool, payload []byte) (bool, []byte, error) {
+	if err := c.ping(); err != nil {
+		return false, nil, err
+	}
+	return true, nil, nil
+}
+
+// ping runs a no-op command on the host.
+func (c *SystemClient) ping() error {
+	_, _, exitCode, err := c.Exec("true")
+	if err != nil {
+		return err
+	}
+	if exitCode != 0 {
+		return fmt.Errorf("ssh to %s exited %d", c.Host, exitCode)
+	}
+	return nil
+}
+
+// systemSession is the Session SystemClient.NewSession returns.
+type systemSession struct{}
+
+func (systemSession) Close() error { return nil }
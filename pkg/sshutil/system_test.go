@@ -0,0 +1,149 @@
+//go:build !windows
+
+package sshutil
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSSH stands in for ssh: it answers -G, records its arguments, and
+// otherwise runs the last one (the command) locally.
+const fakeSSH = `#!/bin/sh
+for a in "$@"; do
+  if [ "$a" = "-G" ]; then printf 'user dev\nhostname build.internal\nport 2222\n'; exit 0; fi
+done
+[ -n "$FAKE_SSH_ARGS" ] && printf '%s\n' "$@" > "$FAKE_SSH_ARGS"
+if [ -n "$FAKE_SSH_FAIL" ]; then echo "ssh: connect to host build.internal port 2222: Connection refused" >&2; exit 255; fi
+for a in "$@"; do last=$a; done
+exec sh -c "$last"
+`
+
+// useFakeSSH swaps the ssh SystemClient runs for fakeSSH.
+func useFakeSSH(t *testing.T) {
+	t.Helper()
+	bin := filepath.Join(t.TempDir(), "ssh")
+	require.NoError(t, os.WriteFile(bin, []byte(fakeSSH), 0755))
+	origBinary, origDir := systemSSHBinary, systemControlDir
+	t.Cleanup(func() { systemSSHBinary, systemControlDir = origBinary, origDir })
+	systemSSHBinary = bin
+	systemControlDir = t.TempDir()
+}
+
+func TestDialSystem(t *testing.T) {
+	useFakeSSH(t)
+	argsFile := filepath.Join(t.TempDir(), "args")
+	t.Setenv("FAKE_SSH_ARGS", argsFile)
+
+	c, err := DialSystem("build", 5*time.Second, []string{"-i", "/keys/work", "-o", "ConnectTimeout=3"})
+	require.NoError(t, err)
+	assert.Equal(t, "build", c.GetHost())
+	assert.Equal(t, "build.internal:2222", c.GetAddress(), "resolved with ssh -G")
+
+	data, err := os.ReadFile(argsFile)
+	require.NoError(t, err)
+	args := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Equal(t, []string{"-i", "/keys/work", "-o", "ConnectTimeout=3"}, args[:4], "the host's flags go first, so they win")
+	assert.Contains(t, args, "ControlMaster=auto")
+	assert.Contains(t, args, "BatchMode=yes")
+	assert.Equal(t, []string{"-T", "build", "true"}, args[len(args)-3:])
+}
+
+func TestDialSystem_Fails(t *testing.T) {
+	useFakeSSH(t)
+	t.Setenv("FAKE_SSH_FAIL", "1")
+
+	_, err := DialSystem("build", time.Second, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ssh couldn't connect to 'build'")
+	assert.Contains(t, err.Error(), "Connection refused", "ssh's own message explains why")
+
+	systemSSHBinary = "rr-no-such-ssh"
+	_, err = DialSystem("build", time.Second, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "there's no ssh on your PATH")
+}
+
+func TestSystemClient_Exec(t *testing.T) {
+	useFakeSSH(t)
+	c, err := DialSystem("build", time.Second, nil)
+	require.NoError(t, err)
+
+	stdout, stderr, exitCode, err := c.Exec("echo out; echo err >&2; exit 3")
+	require.NoError(t, err)
+	assert.Equal(t, "out\n", string(stdout))
+	assert.Equal(t, "err\n", string(stderr))
+	assert.Equal(t, 3, exitCode)
+
+	stdout, _, _, err = c.Exec("echo $LC_ALL")
+	require.NoError(t, err)
+	assert.Equal(t, "C\n", string(stdout), "rr's own commands run in the C locale")
+
+	_, _, exitCode, err = c.Exec("exit 255")
+	require.NoError(t, err)
+	assert.Equal(t, 255, exitCode, "a command may exit 255 itself")
+
+	_, _, exitCode, err = c.Exec("echo 'Connection to build.internal closed by remote host.' >&2; exit 255")
+	require.Error(t, err, "but when ssh says so, the connection failed")
+	assert.Equal(t, -1, exitCode)
+
+	_, err = c.NewSession()
+	assert.NoError(t, err)
+	ok, _, err := c.SendRequest("keepalive@openssh.com", true, nil)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.NoError(t, c.Close())
+}
+
+func TestSystemClient_ExecStreamContext(t *testing.T) {
+	useFakeSSH(t)
+	c, err := DialSystem("build", time.Second, nil)
+	require.NoError(t, err)
+
+	var stdout, stderr bytes.Buffer
+	exitCode, err := c.ExecStream("echo one; echo two >&2; exit 4", &stdout, &stderr)
+	require.NoError(t, err)
+	assert.Equal(t, 4, exitCode)
+	assert.Equal(t, "one\n", stdout.String())
+	assert.Equal(t, "two\n", stderr.String())
+}
+
+func TestSystemClient_ExecStreamContext_Cancel(t *testing.T) {
+	useFakeSSH(t)
+	c, err := DialSystem("build", time.Second, nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(300*time.Millisecond, cancel)
+	start := time.Now()
+	exitCode, err := c.ExecStreamContext(ctx, "sleep 30", &bytes.Buffer{}, &bytes.Buffer{})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NotZero(t, exitCode)
+	assert.Less(t, time.Since(start), CancelGrace, "SIGINT reached the command")
+}
+
+func TestSystemClient_ExecPTYContext(t *testing.T) {
+	useFakeSSH(t)
+	c, err := DialSystem("build", time.Second, nil)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	exitCode, err := c.ExecPTYContext(context.Background(), "read x; echo got $x; echo oops >&2", strings.NewReader("secret\n"), &out)
+	require.NoError(t, err)
+	assert.Equal(t, 0, exitCode)
+	assert.Equal(t, "got secret\noops\n", out.String(), "stderr shares the terminal")
+}
+
+func TestResolvedAddress(t *testing.T) {
+	assert.Equal(t, "10.0.0.5:22", resolvedAddress([]byte("hostname 10.0.0.5\nport 22\n"), "box"))
+	assert.Equal(t, "[::1]:22", resolvedAddress([]byte("hostname ::1\nport 22\n"), "box"))
+	assert.Equal(t, "box", resolvedAddress(nil, "box"))
+}
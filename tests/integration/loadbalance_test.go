@@ -199,7 +199,7 @@ func TestLoadBalancing_IsLocked(t *testing.T) {
 			Dir:     "/tmp",
 		}
 
-		assert.True(t, lock.IsLocked(conn, cfg))
+		assert.True(t, lock.IsLocked(conn, cfg, ""))
 	})
 
 	t.Run("returns false when no lock", func(t *testing.T) {
@@ -211,7 +211,7 @@ func TestLoadBalancing_IsLocked(t *testing.T) {
 			Dir:     "/tmp",
 		}
 
-		assert.False(t, lock.IsLocked(conn, cfg))
+		assert.False(t, lock.IsLocked(conn, cfg, ""))
 	})
 
 	t.Run("returns false for stale lock", func(t *testing.T) {
@@ -234,7 +234,7 @@ func TestLoadBalancing_IsLocked(t *testing.T) {
 			Dir:     "/tmp",
 		}
 
-		assert.False(t, lock.IsLocked(conn, cfg))
+		assert.False(t, lock.IsLocked(conn, cfg, ""))
 	})
 }
 
@@ -263,7 +263,7 @@ func TestLoadBalancing_GetLockHolder(t *testing.T) {
 			Dir:     "/tmp",
 		}
 
-		holder := lock.GetLockHolder(conn, cfg)
+		holder := lock.GetLockHolder(conn, cfg, "")
 		assert.Contains(t, holder, "alice")
 		assert.Contains(t, holder, "workstation")
 	})
@@ -277,7 +277,7 @@ func TestLoadBalancing_GetLockHolder(t *testing.T) {
 			Dir:     "/tmp",
 		}
 
-		holder := lock.GetLockHolder(conn, cfg)
+		holder := lock.GetLockHolder(conn, cfg, "")
 		assert.Empty(t, holder)
 	})
 }
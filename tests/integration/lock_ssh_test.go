@@ -94,7 +94,7 @@ func TestLockIsLocked(t *testing.T) {
 	}
 
 	// Initially should not be locked
-	assert.False(t, lock.IsLocked(conn, cfg))
+	assert.False(t, lock.IsLocked(conn, cfg, ""))
 
 	// Acquire lock
 	lck, err := lock.TryAcquire(conn, cfg, "")
@@ -102,14 +102,14 @@ func TestLockIsLocked(t *testing.T) {
 	require.NotNil(t, lck)
 
 	// Now should be locked
-	assert.True(t, lock.IsLocked(conn, cfg))
+	assert.True(t, lock.IsLocked(conn, cfg, ""))
 
 	// Release
 	err = lck.Release()
 	require.NoError(t, err)
 
 	// Should no longer be locked
-	assert.False(t, lock.IsLocked(conn, cfg))
+	assert.False(t, lock.IsLocked(conn, cfg, ""))
 }
 
 // TestLockGetLockHolder tests getting lock holder information.
@@ -123,7 +123,7 @@ func TestLockGetLockHolder(t *testing.T) {
 	}
 
 	// No holder when not locked
-	holder := lock.GetLockHolder(conn, cfg)
+	holder := lock.GetLockHolder(conn, cfg, "")
 	assert.Empty(t, holder)
 
 	// Acquire lock
@@ -132,7 +132,7 @@ func TestLockGetLockHolder(t *testing.T) {
 	defer lck.Release()
 
 	// Should have holder info
-	holder = lock.GetLockHolder(conn, cfg)
+	holder = lock.GetLockHolder(conn, cfg, "")
 	assert.NotEmpty(t, holder)
 	// Holder string should contain user info
 	assert.Contains(t, holder, "@")
@@ -161,7 +161,7 @@ func TestLockForceRelease(t *testing.T) {
 	assert.False(t, RemoteDirExists(t, conn, lockDir))
 
 	// IsLocked should return false
-	assert.False(t, lock.IsLocked(conn, cfg))
+	assert.False(t, lock.IsLocked(conn, cfg, ""))
 }
 
 // TestLockStaleDetectionSSH tests that stale locks are automatically removed via SSH.